@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/siemforward"
+
+	_ "modernc.org/sqlite"
+)
+
+// runAudit 是 audit 子命令路由：
+// - audit forward：按游标增量转发审计日志到 SIEM（--follow 持续运行）
+// - audit replay：按时间范围回填历史审计日志（不影响游标）
+func runAudit(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printAuditUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "forward":
+		return runAuditForward(ctx, args[1:])
+	case "replay":
+		return runAuditReplay(ctx, args[1:])
+	default:
+		printAuditUsage()
+		return fmt.Errorf("unknown audit command: %s", args[0])
+	}
+}
+
+func printAuditUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli audit forward --endpoint udp://host:514 [--format cef|syslog] [--state data/siem_forward_state.json] [--follow] [--case-id CASE_ID] [--event-types a,b] [--statuses failed]")
+	fmt.Println("  inspector-cli audit replay --endpoint udp://host:514 [--format cef|syslog] [--since 2024-01-01] [--until 2024-12-31] [--case-id CASE_ID] [--event-types a,b] [--statuses failed]")
+}
+
+// auditFlags 是 forward/replay 共用的参数。
+type auditFlags struct {
+	dbPath     *string
+	endpoint   *string
+	format     *string
+	hostname   *string
+	caseID     *string
+	eventTypes *string
+	statuses   *string
+	batchSize  *int
+}
+
+func bindAuditFlags(fs *flag.FlagSet) auditFlags {
+	cfg := app.DefaultConfig()
+	return auditFlags{
+		dbPath:     fs.String("db", cfg.DBPath, "sqlite database path"),
+		endpoint:   fs.String("endpoint", "", "siem endpoint: udp://host:514 | tcp://host:514 | file:///path (required)"),
+		format:     fs.String("format", siemforward.FormatCEF, "message format: cef|syslog"),
+		hostname:   fs.String("hostname", "", "syslog hostname field (default: os hostname)"),
+		caseID:     fs.String("case-id", "", "only forward this case"),
+		eventTypes: fs.String("event-types", "", "comma separated event_type filter"),
+		statuses:   fs.String("statuses", "", "comma separated status filter (started,success,failed,skipped)"),
+		batchSize:  fs.Int("batch-size", 200, "audit logs per batch"),
+	}
+}
+
+func (f auditFlags) options() siemforward.Options {
+	return siemforward.Options{
+		Endpoint:  strings.TrimSpace(*f.endpoint),
+		Format:    strings.TrimSpace(*f.format),
+		Hostname:  strings.TrimSpace(*f.hostname),
+		BatchSize: *f.batchSize,
+		Filter: siemforward.Filter{
+			CaseID:     strings.TrimSpace(*f.caseID),
+			EventTypes: splitCSV(*f.eventTypes),
+			Statuses:   splitCSV(*f.statuses),
+		},
+	}
+}
+
+func runAuditForward(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("audit forward", flag.ContinueOnError)
+	af := bindAuditFlags(fs)
+	statePath := fs.String("state", "data/siem_forward_state.json", "cursor state file")
+	follow := fs.Bool("follow", false, "keep running and forward new audit logs in near-real-time")
+	interval := fs.Duration("interval", 5*time.Second, "poll interval in --follow mode")
+	verify := fs.Bool("verify-integrity", true, "verify audit chains and forward integrity alerts")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*af.endpoint) == "" {
+		return fmt.Errorf("--endpoint is required")
+	}
+
+	db, err := openAuditDB(ctx, *af.dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	store := sqliteadapter.NewStore(db)
+
+	opts := af.options()
+	opts.StateFile = strings.TrimSpace(*statePath)
+	opts.PollInterval = *interval
+	opts.VerifyIntegrity = *verify
+	opts.Logf = func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+	}
+
+	fwd, err := siemforward.New(store, opts)
+	if err != nil {
+		return err
+	}
+	defer fwd.Close()
+
+	if *follow {
+		sigCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		fmt.Printf("siem forwarding: endpoint=%s format=%s last_seq=%d (Ctrl+C to stop)\n", opts.Endpoint, opts.Format, fwd.State().LastSeq)
+		if err := fwd.Run(sigCtx); err != nil {
+			return err
+		}
+		st := fwd.State()
+		fmt.Printf("siem forwarding stopped: last_seq=%d forwarded_total=%d\n", st.LastSeq, st.Forwarded)
+		return nil
+	}
+
+	stats, err := fwd.RunOnce(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println("audit forward completed")
+	fmt.Printf("scanned=%d forwarded=%d filtered=%d alerts=%d last_seq=%d\n", stats.Scanned, stats.Forwarded, stats.Filtered, stats.Alerts, fwd.State().LastSeq)
+	return nil
+}
+
+func runAuditReplay(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("audit replay", flag.ContinueOnError)
+	af := bindAuditFlags(fs)
+	since := fs.String("since", "", "start time: unix seconds or YYYY-MM-DD (inclusive)")
+	until := fs.String("until", "", "end time: unix seconds or YYYY-MM-DD (inclusive, whole day)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*af.endpoint) == "" {
+		return fmt.Errorf("--endpoint is required")
+	}
+	sinceTS, err := parseAuditTime(*since, false)
+	if err != nil {
+		return fmt.Errorf("--since: %w", err)
+	}
+	untilTS, err := parseAuditTime(*until, true)
+	if err != nil {
+		return fmt.Errorf("--until: %w", err)
+	}
+
+	db, err := openAuditDB(ctx, *af.dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	stats, err := siemforward.Replay(ctx, sqliteadapter.NewStore(db), af.options(), siemforward.ReplayOptions{
+		Since: sinceTS,
+		Until: untilTS,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println("audit replay completed")
+	fmt.Printf("scanned=%d forwarded=%d filtered=%d last_seq=%d\n", stats.Scanned, stats.Forwarded, stats.Filtered, stats.LastSeq)
+	return nil
+}
+
+func openAuditDB(ctx context.Context, dbPath string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.ExecContext(ctx, `PRAGMA busy_timeout = 5000`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("set busy_timeout: %w", err)
+	}
+	migrator := sqliteadapter.NewMigrator(db)
+	if err := migrator.Up(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("apply migrations: %w", err)
+	}
+	return db, nil
+}
+
+// parseAuditTime 解析 unix 秒或 YYYY-MM-DD（本地时区）；endOfDay=true 时日期取当天 23:59:59。
+func parseAuditTime(v string, endOfDay bool) (int64, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, nil
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return n, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (want unix seconds or YYYY-MM-DD)", v)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Second)
+	}
+	return t.Unix(), nil
+}
+
+func splitCSV(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
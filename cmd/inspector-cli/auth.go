@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/authdoc"
+)
+
+// runAuth 是 auth 子命令路由（执法授权文书）：
+// - auth attach：上传扫描版授权文书 PDF 作为案件附件
+// - auth list：查看案件已上传的授权文书
+func runAuth(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printAuthUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "attach":
+		return runAuthAttach(ctx, args[1:])
+	case "list":
+		return runAuthList(ctx, args[1:])
+	default:
+		printAuthUsage()
+		return fmt.Errorf("unknown auth command: %s", args[0])
+	}
+}
+
+func printAuthUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli auth attach --case-id CASE_ID --file warrant.pdf [--order TICKET] [--agency name] [--operator name] [--db path] [--evidence-dir path]")
+	fmt.Println("  inspector-cli auth list --case-id CASE_ID [--db path]")
+}
+
+func runAuthAttach(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("auth attach", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory")
+	caseID := fs.String("case-id", "", "case id (required)")
+	file := fs.String("file", "", "scanned authorization document (PDF, required)")
+	order := fs.String("order", "", "authorization order number printed on the document")
+	agency := fs.String("agency", "", "issuing agency (selects the order number format)")
+	operator := fs.String("operator", "system", "operator name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" || strings.TrimSpace(*file) == "" {
+		return fmt.Errorf("--case-id and --file are required")
+	}
+	content, err := os.ReadFile(*file)
+	if err != nil {
+		return fmt.Errorf("read document: %w", err)
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	doc, err := authdoc.Attach(ctx, sqliteadapter.NewStore(db), authdoc.Input{
+		CaseID:             *caseID,
+		FileName:           filepath.Base(*file),
+		Content:            content,
+		AuthorizationOrder: *order,
+		Agency:             *agency,
+		Operator:           *operator,
+		EvidenceRoot:       *evidenceRoot,
+	})
+	if err != nil {
+		return err
+	}
+	return printJSON(doc)
+}
+
+func runAuthList(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("auth list", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	docs, err := sqliteadapter.NewStore(db).ListCaseAttachments(ctx, strings.TrimSpace(*caseID), authdoc.KindAuthorizationDocument)
+	if err != nil {
+		return err
+	}
+	return printJSON(docs)
+}
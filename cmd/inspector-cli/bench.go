@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"crypto-inspector/internal/services/storebench"
+)
+
+// runBench 执行存储层基准：按给定规模向全新的基准库写入浏览记录与命中，
+// 输出各组 SQLite 设置下写入 / 查询 / 导出读取的耗时（不触碰业务数据库）。
+func runBench(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	visits := fs.Int("visits", storebench.DefaultVisits, "browser history records to insert")
+	hits := fs.Int("hits", storebench.DefaultHits, "rule hits to insert")
+	perArtifact := fs.Int("visits-per-artifact", storebench.DefaultVisitsPerArtifact, "history records per browser_history artifact")
+	repeat := fs.Int("repeat", storebench.DefaultQueryRepeat, "repetitions of each query phase")
+	settings := fs.String("settings", "default,wal,wal_pool4", "comma separated SQLite presets: default|wal|wal_pool4")
+	dir := fs.String("dir", "", "directory for bench databases (default: a temp dir removed afterwards)")
+	keep := fs.Bool("keep", false, "keep bench databases in --dir")
+	asJSON := fs.Bool("json", false, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	sts, err := storebench.ParseSettings(*settings)
+	if err != nil {
+		return err
+	}
+
+	rep, err := storebench.Run(ctx, storebench.Options{
+		Dir:               *dir,
+		Keep:              *keep,
+		Visits:            *visits,
+		Hits:              *hits,
+		VisitsPerArtifact: *perArtifact,
+		QueryRepeat:       *repeat,
+		Settings:          sts,
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(rep)
+	}
+	fmt.Printf("store bench: visits=%d hits=%d artifacts=%d\n", rep.Visits, rep.Hits, rep.Artifacts)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "setting\tphase\tops\trows\ttotal_ms\tp50_ms\tp95_ms\tmax_ms\trows/s\t")
+	for _, r := range rep.Results {
+		for _, p := range r.Phases {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.1f\t%.1f\t%.1f\t%.1f\t%.0f\t\n", r.Setting.Name, p.Name, p.Ops, p.Rows, p.TotalMS, p.P50MS, p.P95MS, p.MaxMS, p.RowsPerSec)
+		}
+		fmt.Fprintf(tw, "%s\tdb_bytes\t\t%d\t\t\t\t\t\t\n", r.Setting.Name, r.DBBytes)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,499 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/anchor"
+	"crypto-inspector/internal/services/casemeta"
+	"crypto-inspector/internal/services/casemgmt"
+	"crypto-inspector/internal/services/checklist"
+	"crypto-inspector/internal/services/devicecustody"
+)
+
+// runCase 是 case 子命令路由（案件负责人与交接）：
+// - case list：列出案件，--owner 只看某操作员负责的案件
+// - case handover：把案件交接给另一名操作员（必须填写交接说明）
+// - case history：案件交接历史，或某操作员的交接班日志
+// - case push：把案件摘要、命中与报告哈希推送到外部案件管理系统（--cms-config）
+// - case anchor：把导出包 sha256 登记到外部锚定服务（--anchor-config），case anchors 列出已保存的回执
+// - case checklist：查看/勾选案件侦查清单（--template 导入清单模板）
+// - case close：结案（external profile 要求必需清单项全部完成）
+// - case update：修改标题 / 案件编号 / 状态（open → closed → archived），每个变化字段写一条审计
+// - case device：查看/修改设备保管信息（实物标签、IMEI、扣押地点、保管人、备注），case device-photo 上传设备照片
+func runCase(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printCaseUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "list":
+		return runCaseList(ctx, args[1:])
+	case "handover":
+		return runCaseHandover(ctx, args[1:])
+	case "history":
+		return runCaseHistory(ctx, args[1:])
+	case "push":
+		return runCasePush(ctx, args[1:])
+	case "anchor":
+		return runCaseAnchor(ctx, args[1:])
+	case "anchors":
+		return runCaseAnchors(ctx, args[1:])
+	case "checklist":
+		return runCaseChecklist(ctx, args[1:])
+	case "close":
+		return runCaseClose(ctx, args[1:])
+	case "update":
+		return runCaseUpdate(ctx, args[1:])
+	case "device":
+		return runCaseDevice(ctx, args[1:])
+	case "device-photo":
+		return runCaseDevicePhoto(ctx, args[1:])
+	default:
+		printCaseUsage()
+		return fmt.Errorf("unknown case command: %s", args[0])
+	}
+}
+
+func printCaseUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli case list [--owner name] [--limit 50] [--db path]")
+	fmt.Println("  inspector-cli case handover --case-id CASE_ID --to name --note TEXT [--operator name] [--db path]")
+	fmt.Println("  inspector-cli case history (--case-id CASE_ID | --operator name) [--db path]")
+	fmt.Println("  inspector-cli case push --case-id CASE_ID --cms-config rules/case_management.template.yaml [--operator name] [--db path]")
+	fmt.Println("  inspector-cli case anchor --case-id CASE_ID --anchor-config rules/evidence_anchor.template.yaml [--report-id REPORT_ID] [--operator name] [--db path]")
+	fmt.Println("  inspector-cli case anchors --case-id CASE_ID [--report-id REPORT_ID] [--db path]")
+	fmt.Println("  inspector-cli case checklist --case-id CASE_ID [--item ITEM_ID --status pending|done|not_applicable --responsible name --note TEXT] [--template rules/case_checklist.template.yaml] [--operator name] [--db path]")
+	fmt.Println("  inspector-cli case close --case-id CASE_ID [--profile internal|external] [--operator name] [--db path]")
+	fmt.Println("  inspector-cli case update --case-id CASE_ID [--title TEXT] [--case-no NO] [--status closed|archived [--profile internal|external]] [--operator name] [--db path]")
+	fmt.Println("  inspector-cli case device --case-id CASE_ID --device-id DEVICE_ID [--label TEXT] [--imei IMEI] [--seizure-location TEXT] [--custody-officer name] [--note TEXT] [--operator name] [--db path]")
+	fmt.Println("  inspector-cli case device-photo --case-id CASE_ID --device-id DEVICE_ID --file photo.jpg [--caption TEXT] [--evidence-dir data/evidence] [--operator name] [--db path]")
+}
+
+func runCaseList(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("case list", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	owner := fs.String("owner", "", "only cases currently owned by this operator")
+	limit := fs.Int("limit", 50, "max cases")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := sqliteadapter.NewStore(db).ListCases(ctx, strings.TrimSpace(*owner), *limit, 0)
+	if err != nil {
+		return err
+	}
+	return printJSON(rows)
+}
+
+func runCaseHandover(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("case handover", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	to := fs.String("to", "", "operator taking over the case (required)")
+	note := fs.String("note", "", "handover note: open items, evidence state (required)")
+	operator := fs.String("operator", "system", "operator performing the handover")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id := strings.TrimSpace(*caseID)
+	if id == "" || strings.TrimSpace(*to) == "" || strings.TrimSpace(*note) == "" {
+		return fmt.Errorf("--case-id, --to and --note are required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	store := sqliteadapter.NewStore(db)
+
+	a, err := store.HandoverCase(ctx, id, strings.TrimSpace(*to), strings.TrimSpace(*note), *operator)
+	if err != nil {
+		return err
+	}
+	if a == nil {
+		return fmt.Errorf("case not found: %s", id)
+	}
+	_ = store.AppendAudit(ctx, id, "", "case", "handover", "success", *operator, "cli.case.handover", map[string]any{
+		"assignment_id": a.AssignmentID,
+		"from_operator": a.FromOperator,
+		"to_operator":   a.ToOperator,
+		"note":          a.Note,
+	})
+	return printJSON(a)
+}
+
+func runCaseHistory(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("case history", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "handover history of this case")
+	operator := fs.String("operator", "", "handovers given or received by this operator (shift log)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" && strings.TrimSpace(*operator) == "" {
+		return fmt.Errorf("--case-id or --operator is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := sqliteadapter.NewStore(db).ListCaseAssignments(ctx, strings.TrimSpace(*caseID), strings.TrimSpace(*operator), 0)
+	if err != nil {
+		return err
+	}
+	return printJSON(rows)
+}
+
+func runCasePush(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("case push", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	cmsConfig := fs.String("cms-config", "", "case management integration config yaml (required)")
+	operator := fs.String("operator", "system", "operator id or name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id := strings.TrimSpace(*caseID)
+	if id == "" || strings.TrimSpace(*cmsConfig) == "" {
+		return fmt.Errorf("--case-id and --cms-config are required")
+	}
+	cms, err := casemgmt.LoadConfig(*cmsConfig)
+	if err != nil {
+		return err
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rec, err := casemgmt.Push(ctx, sqliteadapter.NewStore(db), cms, id, casemgmt.Trigger{Kind: "manual", Operator: strings.TrimSpace(*operator)})
+	if err != nil {
+		return err
+	}
+	return printJSON(rec)
+}
+
+// runCaseAnchor 把报告（默认最新 forensic_zip）的 sha256 登记到外部锚定服务并保存回执。
+func runCaseAnchor(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("case anchor", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	reportID := fs.String("report-id", "", "report to anchor (default: latest forensic_zip of the case)")
+	anchorConfig := fs.String("anchor-config", "", "evidence anchor config yaml (required)")
+	operator := fs.String("operator", "system", "operator id or name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id := strings.TrimSpace(*caseID)
+	if id == "" || strings.TrimSpace(*anchorConfig) == "" {
+		return fmt.Errorf("--case-id and --anchor-config are required")
+	}
+	ac, err := anchor.LoadConfig(*anchorConfig)
+	if err != nil {
+		return err
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	row, err := anchor.Anchor(ctx, sqliteadapter.NewStore(db), ac, id, strings.TrimSpace(*reportID), strings.TrimSpace(*operator))
+	if err != nil {
+		return err
+	}
+	return printJSON(row)
+}
+
+// runCaseAnchors 列出案件已保存的锚定回执。
+func runCaseAnchors(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("case anchors", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	reportID := fs.String("report-id", "", "only anchors of this report (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id := strings.TrimSpace(*caseID)
+	if id == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := sqliteadapter.NewStore(db).ListEvidenceAnchors(ctx, id, strings.TrimSpace(*reportID))
+	if err != nil {
+		return err
+	}
+	return printJSON(rows)
+}
+
+// runCaseChecklist 查看案件侦查清单；指定 --item 时修改该项，指定 --template 时先导入清单模板。
+func runCaseChecklist(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("case checklist", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	template := fs.String("template", "", "case checklist template yaml to import first (optional)")
+	item := fs.String("item", "", "checklist item id to update (optional)")
+	status := fs.String("status", "", "pending|done|not_applicable")
+	responsible := fs.String("responsible", "", "responsible operator")
+	note := fs.String("note", "", "note (required for not_applicable)")
+	operator := fs.String("operator", "system", "operator name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id := strings.TrimSpace(*caseID)
+	if id == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	store := sqliteadapter.NewStore(db)
+
+	if p := strings.TrimSpace(*template); p != "" {
+		_, raw, err := checklist.ParseFile(p)
+		if err != nil {
+			return err
+		}
+		if _, err := checklist.Save(ctx, store, raw); err != nil {
+			return err
+		}
+	}
+	if itemID := strings.TrimSpace(*item); itemID != "" {
+		if _, err := checklist.SetItem(ctx, store, id, itemID, checklist.Update{
+			Status:      *status,
+			Responsible: *responsible,
+			Note:        *note,
+			Operator:    *operator,
+		}); err != nil {
+			return err
+		}
+	}
+	items, err := checklist.Get(ctx, store, id)
+	if err != nil {
+		return err
+	}
+	return printJSON(items)
+}
+
+func runCaseClose(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("case close", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	profile := fs.String("profile", checklist.ProfileInternal, "internal|external (external requires all required checklist items)")
+	operator := fs.String("operator", "system", "operator name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id := strings.TrimSpace(*caseID)
+	if id == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := checklist.Close(ctx, sqliteadapter.NewStore(db), id, *profile, *operator)
+	if err != nil {
+		return err
+	}
+	return printJSON(res)
+}
+
+func runCaseUpdate(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("case update", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	title := fs.String("title", "", "new case title")
+	caseNo := fs.String("case-no", "", "new case number (empty string clears it)")
+	status := fs.String("status", "", "new status: closed|archived (open -> closed -> archived)")
+	profile := fs.String("profile", checklist.ProfileInternal, "close profile when --status closed: internal|external")
+	operator := fs.String("operator", "system", "operator name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id := strings.TrimSpace(*caseID)
+	if id == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	// 只提交显式给出的字段（--case-no "" 表示清除编号）。
+	patch := casemeta.Patch{Profile: *profile, Operator: *operator}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "title":
+			patch.Title = title
+		case "case-no":
+			patch.CaseNo = caseNo
+		case "status":
+			patch.Status = status
+		}
+	})
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := casemeta.Update(ctx, sqliteadapter.NewStore(db), id, patch)
+	if err != nil {
+		return err
+	}
+	return printJSON(res)
+}
+
+func runCaseDevice(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("case device", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	deviceID := fs.String("device-id", "", "device id (required)")
+	label := fs.String("label", "", "physical label / exhibit tag (empty string clears it)")
+	imei := fs.String("imei", "", "IMEI (15 digits, or 16 for IMEISV; empty string clears it)")
+	location := fs.String("seizure-location", "", "where the device was seized")
+	officer := fs.String("custody-officer", "", "officer holding custody")
+	note := fs.String("note", "", "custody note")
+	operator := fs.String("operator", "system", "operator name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" || strings.TrimSpace(*deviceID) == "" {
+		return fmt.Errorf("--case-id and --device-id are required")
+	}
+
+	// 只提交显式给出的字段；一个都没给时只显示设备信息。
+	patch := devicecustody.Patch{Operator: *operator}
+	changed := false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "label":
+			patch.PhysicalLabel = label
+		case "imei":
+			patch.IMEI = imei
+		case "seizure-location":
+			patch.SeizureLocation = location
+		case "custody-officer":
+			patch.CustodyOfficer = officer
+		case "note":
+			patch.Note = note
+		default:
+			return
+		}
+		changed = true
+	})
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	store := sqliteadapter.NewStore(db)
+
+	if !changed {
+		d, err := devicecustody.Get(ctx, store, strings.TrimSpace(*caseID), strings.TrimSpace(*deviceID))
+		if err != nil {
+			return err
+		}
+		return printJSON(d)
+	}
+	res, err := devicecustody.Update(ctx, store, strings.TrimSpace(*caseID), strings.TrimSpace(*deviceID), patch)
+	if err != nil {
+		return err
+	}
+	return printJSON(res)
+}
+
+func runCaseDevicePhoto(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("case device-photo", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory")
+	caseID := fs.String("case-id", "", "case id (required)")
+	deviceID := fs.String("device-id", "", "device id (required)")
+	file := fs.String("file", "", "PNG/JPEG photo of the device (required)")
+	caption := fs.String("caption", "", "photo caption")
+	operator := fs.String("operator", "system", "operator name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" || strings.TrimSpace(*deviceID) == "" || strings.TrimSpace(*file) == "" {
+		return fmt.Errorf("--case-id, --device-id and --file are required")
+	}
+	content, err := os.ReadFile(*file)
+	if err != nil {
+		return fmt.Errorf("read photo: %w", err)
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	photo, err := devicecustody.AttachPhoto(ctx, sqliteadapter.NewStore(db), devicecustody.PhotoInput{
+		CaseID:       *caseID,
+		DeviceID:     *deviceID,
+		FileName:     filepath.Base(*file),
+		Content:      content,
+		Caption:      *caption,
+		Operator:     *operator,
+		EvidenceRoot: *evidenceRoot,
+	})
+	if err != nil {
+		return err
+	}
+	return printJSON(photo)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"crypto-inspector/internal/adapters/rules"
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/devicesim"
+	"crypto-inspector/internal/services/devseed"
+)
+
+// runDev 是 dev 子命令路由（开发辅助，不用于真实取证）：
+// - dev seed：生成确定性的合成案件（设备 / 证据 / 命中 / 审计 / 报告），供界面开发、演示与压测使用
+// - dev simulate：按 JSON 夹具模拟 adb / idevice / hdc 输出与主机浏览器 profile，跑完整的扫描 → 匹配 → 入库 → 导出流程，供规则作者验证规则
+func runDev(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printDevUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "seed":
+		return runDevSeed(ctx, args[1:])
+	case "simulate":
+		return runDevSimulate(ctx, args[1:])
+	default:
+		printDevUsage()
+		return fmt.Errorf("unknown dev command: %s", args[0])
+	}
+}
+
+func printDevUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli dev seed [--profile demo|load] [--seed N] [--case-id CASE_ID] [--db path] [--evidence-dir path] [--wallet path --exchange path] [--json]")
+	fmt.Println("  inspector-cli dev simulate --fixture fixture.json [--case-id CASE_ID] [--db path] [--evidence-dir path] [--wallet path] [--exchange path] [--regex-rules path] [--work-dir path] [--expect rule_id,...] [--no-export] [--json]")
+}
+
+func runDevSeed(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("dev seed", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory")
+	profile := fs.String("profile", devseed.ProfileDemo, "demo|load")
+	seed := fs.Int64("seed", 1, "random seed; the same seed and case id generate the same data")
+	caseID := fs.String("case-id", "", "case id (default: case_seed_<profile>_<seed>)")
+	walletPath := fs.String("wallet", "", "wallet rule file to draw wallet traces from (default: built-in sample rules)")
+	exchangePath := fs.String("exchange", "", "exchange rule file to draw exchange visits from (default: built-in sample rules)")
+	operator := fs.String("operator", "dev_seed", "operator name")
+	asJSON := fs.Bool("json", false, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var loaded *rules.LoadedRules
+	if strings.TrimSpace(*walletPath) != "" || strings.TrimSpace(*exchangePath) != "" {
+		if strings.TrimSpace(*walletPath) == "" || strings.TrimSpace(*exchangePath) == "" {
+			return fmt.Errorf("--wallet and --exchange must be given together")
+		}
+		var err error
+		if loaded, err = rules.NewLoader(*walletPath, *exchangePath).Load(ctx); err != nil {
+			return err
+		}
+	}
+
+	// 合成案件常用于全新的数据库，先确保目录存在。
+	if err := os.MkdirAll(filepath.Dir(*dbPath), 0o755); err != nil {
+		return fmt.Errorf("create db directory: %w", err)
+	}
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := devseed.Seed(ctx, sqliteadapter.NewStore(db), devseed.Options{
+		Profile:      *profile,
+		Seed:         *seed,
+		CaseID:       *caseID,
+		EvidenceRoot: *evidenceRoot,
+		ReportDir:    filepath.Join(filepath.Dir(*dbPath), "reports"),
+		Operator:     *operator,
+		Rules:        loaded,
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(res)
+	}
+	fmt.Printf("synthetic case seeded: case_id=%s profile=%s seed=%d\n", res.CaseID, res.Profile, res.Seed)
+	fmt.Printf("devices=%d artifacts=%d hits=%d reports=%d\n", res.Devices, res.Artifacts, res.Hits, len(res.ReportIDs))
+	return nil
+}
+
+func runDevSimulate(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("dev simulate", flag.ContinueOnError)
+	fixturePath := fs.String("fixture", "", "device fixture json (android/ios/harmony devices and hosts)")
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory")
+	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
+	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
+	regexPath := fs.String("regex-rules", cfg.RegexRulePath, "regex rule file (optional)")
+	caseID := fs.String("case-id", "", "case id (default: create a new case)")
+	workDir := fs.String("work-dir", "", "keep generated host profile trees in this directory (default: temporary directory)")
+	expect := fs.String("expect", "", "comma-separated rule ids that must be hit; exit with error when any is missing")
+	noExport := fs.Bool("no-export", false, "skip forensic zip export")
+	operator := fs.String("operator", "devicesim", "operator name")
+	asJSON := fs.Bool("json", false, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*fixturePath) == "" {
+		return fmt.Errorf("--fixture is required")
+	}
+	fixture, err := devicesim.LoadFixture(*fixturePath)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(*dbPath), 0o755); err != nil {
+		return fmt.Errorf("create db directory: %w", err)
+	}
+
+	res, err := devicesim.Run(ctx, fixture, devicesim.Options{
+		DBPath:           *dbPath,
+		EvidenceRoot:     *evidenceRoot,
+		WalletRulePath:   *walletPath,
+		ExchangeRulePath: *exchangePath,
+		RegexRulePath:    *regexPath,
+		CaseID:           *caseID,
+		Operator:         *operator,
+		WorkDir:          *workDir,
+		Export:           !*noExport,
+	})
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, ruleID := range strings.Split(*expect, ",") {
+		if ruleID = strings.TrimSpace(ruleID); ruleID != "" && !res.HasHit("", ruleID) {
+			missing = append(missing, ruleID)
+		}
+	}
+	if *asJSON {
+		if err := printJSON(res); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("simulated scan completed: case_id=%s hits=%d\n", res.CaseID, len(res.Hits))
+		for _, h := range res.Hits {
+			fmt.Printf("  %-24s %-28s %s\n", h.HitType, h.RuleID, h.MatchedValue)
+		}
+		if res.Export != nil {
+			fmt.Printf("export: %s (sha256 %s)\n", res.Export.ZipPath, res.Export.ZipSHA256)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("expected rules not hit: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"crypto-inspector/internal/adapters/host"
+)
+
+// relaunchElevatedIfNeeded 在 --elevate 且存在需要提权的缺口时，以管理员/root 重新运行同一命令
+// （Windows 弹出 UAC，macOS 弹出 osascript 授权框；追加 --elevate=false 防止循环）。
+// 返回 true 表示已交给提权后的进程，当前进程不再扫描。提权无法解决的 macOS 完全磁盘访问权限缺口只打印提示。
+func relaunchElevatedIfNeeded(ctx context.Context, command []string, args []string, elevate bool) (bool, error) {
+	if !elevate {
+		return false, nil
+	}
+	device, err := host.DetectHostDevice()
+	if err != nil {
+		return false, nil
+	}
+	if fda := host.CheckFullDiskAccess(); fda.Status == host.FullDiskAccessDenied {
+		fmt.Printf("permission required: full disk access - %s\n", fda.Hint)
+	}
+	if !host.CheckElevation(ctx, device).NeedsRelaunch() {
+		return false, nil
+	}
+	fmt.Println("relaunching with elevated privileges ...")
+	relaunchArgs := append(append(append([]string{}, command...), args...), "--elevate=false")
+	if err := host.RelaunchElevated(ctx, relaunchArgs); err != nil {
+		return false, fmt.Errorf("elevated relaunch failed (rerun as administrator/root manually): %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/artifactverify"
+	"crypto-inspector/internal/services/evidencerelocate"
+)
+
+// runEvidence 是 evidence 子命令路由：
+// - evidence relocate：证据目录迁移到新位置后改写库内路径并复核哈希
+func runEvidence(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printEvidenceUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "relocate":
+		return runEvidenceRelocate(ctx, args[1:])
+	default:
+		printEvidenceUsage()
+		return fmt.Errorf("unknown evidence command: %s", args[0])
+	}
+}
+
+func printEvidenceUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli evidence relocate --from OLD_ROOT --to NEW_ROOT [--case-id CASE_ID] [--dry-run] [--force] [--workers N] [--db path] [--json]")
+}
+
+func runEvidenceRelocate(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("evidence relocate", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	from := fs.String("from", "", "old evidence root as stored in the database (required)")
+	to := fs.String("to", "", "new evidence root the files were moved to (required)")
+	caseID := fs.String("case-id", "", "only relocate this case (default: all cases)")
+	dryRun := fs.Bool("dry-run", false, "only report what would be rewritten")
+	force := fs.Bool("force", false, "rewrite paths even if some files are missing under --to")
+	workers := fs.Int("workers", artifactverify.DefaultWorkers(), "parallel hashing workers for the post-move verification")
+	operator := fs.String("operator", "system", "operator name")
+	asJSON := fs.Bool("json", false, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*from) == "" || strings.TrimSpace(*to) == "" {
+		return fmt.Errorf("--from and --to are required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := evidencerelocate.Run(ctx, sqliteadapter.NewStore(db), evidencerelocate.Options{
+		From:     *from,
+		To:       *to,
+		CaseID:   *caseID,
+		Operator: *operator,
+		DryRun:   *dryRun,
+		Force:    *force,
+		Workers:  *workers,
+	})
+	if res != nil {
+		if *asJSON {
+			if perr := printJSON(res); perr != nil {
+				return perr
+			}
+		} else {
+			printRelocateResult(*res)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if res.VerifyFailed > 0 {
+		return fmt.Errorf("evidence relocated but %d files failed hash verification", res.VerifyFailed)
+	}
+	return nil
+}
+
+func printRelocateResult(r evidencerelocate.Result) {
+	switch {
+	case r.Applied:
+		fmt.Println("evidence relocate completed")
+	case r.DryRun:
+		fmt.Println("evidence relocate dry run")
+	default:
+		fmt.Println("evidence relocate not applied")
+	}
+	fmt.Printf("from=%s to=%s cases=%d artifacts=%d reports=%d attachments=%d missing_at_target=%d\n",
+		r.From, r.To, len(r.CaseIDs), r.Artifacts, r.Reports, r.Attachments, r.MissingCount)
+	for _, m := range r.Missing {
+		fmt.Printf("MISSING %s %s %s\n", m.Kind, m.ID, m.NewPath)
+	}
+	if r.Applied {
+		fmt.Printf("verify ok=%d failed=%d\n", r.VerifyOK, r.VerifyFailed)
+		for _, f := range r.Failures {
+			fmt.Printf("FAIL id=%s status=%s expected=%s actual=%s path=%s\n", f.ArtifactID, f.Status, f.ExpectedSHA256, f.ActualSHA256, f.SnapshotPath)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crypto-inspector/internal/services/exporter"
+)
+
+func TestExportEncryptRejectsUnencryptedKinds(t *testing.T) {
+	t.Setenv("CRYPTO_INSPECTOR_EXPORT_PASSWORD", "s3cret-pass")
+	e, ok := exporter.Lookup("graph-zip")
+	if !ok {
+		t.Fatal("graph-zip not registered")
+	}
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "inspector.db")
+	err := runExportKind(context.Background(), e, []string{"--db", dbPath, "--case-id", "case_1", "--out-dir", dir, "--encrypt"})
+	if err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Fatalf("err=%v", err)
+	}
+	// 拒绝发生在打开数据库与生成文件之前。
+	if _, err := os.Stat(dbPath); !os.IsNotExist(err) {
+		t.Fatalf("db should not be created: %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/manualhit"
+)
+
+// runHits 是 hits 子命令路由：
+// - hits add-manual：人工录入命中（例如现场发现的纸质助记词）
+func runHits(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printHitsUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "add-manual":
+		return runHitsAddManual(ctx, args[1:])
+	default:
+		printHitsUsage()
+		return fmt.Errorf("unknown hits command: %s", args[0])
+	}
+}
+
+func printHitsUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli hits add-manual --case-id CASE_ID --value VALUE --justification TEXT [--type manual_finding] [--file PATH] [--device-id id] [--operator name] [--confidence 1.0] [--verdict confirmed|suspected|unsupported] [--db path] [--evidence-dir path] [--json=true]")
+}
+
+// runHitsAddManual 写入一条人工命中；--file 指定的附件原样落库为 manual_evidence 证据。
+func runHitsAddManual(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("hits add-manual", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory")
+	caseID := fs.String("case-id", "", "case id (required)")
+	deviceID := fs.String("device-id", "", "device id (default: local device of the case)")
+	hitType := fs.String("type", "manual_finding", "hit type")
+	value := fs.String("value", "", "matched value (required)")
+	justification := fs.String("justification", "", "why/where the evidence was found (required)")
+	ruleName := fs.String("rule-name", "", "display label (default: 人工录入)")
+	filePath := fs.String("file", "", "attachment file, e.g. a photo (optional)")
+	operator := fs.String("operator", "system", "operator name")
+	confidence := fs.Float64("confidence", 0, "confidence in [0,1] (default 1.0)")
+	verdict := fs.String("verdict", "", "confirmed|suspected|unsupported (default confirmed)")
+	asJSON := fs.Bool("json", true, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+	if strings.TrimSpace(*justification) == "" {
+		return fmt.Errorf("--justification is required")
+	}
+
+	in := manualhit.Input{
+		CaseID:        *caseID,
+		DeviceID:      *deviceID,
+		HitType:       *hitType,
+		Value:         *value,
+		Justification: *justification,
+		Operator:      *operator,
+		RuleName:      *ruleName,
+		Confidence:    *confidence,
+		Verdict:       *verdict,
+		EvidenceRoot:  *evidenceRoot,
+	}
+	if p := strings.TrimSpace(*filePath); p != "" {
+		st, err := os.Stat(p)
+		if err != nil {
+			return fmt.Errorf("stat attachment: %w", err)
+		}
+		if st.Size() > manualhit.MaxAttachmentBytes {
+			return fmt.Errorf("attachment too large: max=%d bytes", manualhit.MaxAttachmentBytes)
+		}
+		raw, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("read attachment: %w", err)
+		}
+		in.Attachment = raw
+		in.AttachmentName = filepath.Base(p)
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := manualhit.Create(ctx, sqliteadapter.NewStore(db), in)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(res)
+	}
+	fmt.Printf("hit_id=%s case_id=%s device_id=%s type=%s\n", res.HitID, res.CaseID, res.DeviceID, res.HitType)
+	if res.ArtifactID != "" {
+		fmt.Printf("artifact_id=%s sha256=%s\n", res.ArtifactID, res.SHA256)
+	}
+	return nil
+}
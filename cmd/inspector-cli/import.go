@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/forensicimport"
+)
+
+// runImport 导入第三方取证工具（Cellebrite UFED / Magnet AXIOM / Plaso / Autopsy）的导出报告：
+// 已安装应用与浏览记录登记为案件证据，并执行钱包/交易所规则匹配。
+func runImport(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory")
+	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
+	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
+	regexPath := fs.String("regex-rules", cfg.RegexRulePath, "custom regex rule file (optional; skipped when missing)")
+	caseID := fs.String("case-id", "", "case id (required)")
+	filePath := fs.String("file", "", "exported report: UFED report.xml / AXIOM xml / csv / Plaso psort csv / Autopsy report (required)")
+	format := fs.String("format", "", "ufed_xml|axiom_xml|csv|plaso_csv|autopsy_csv (default: auto detect)")
+	osType := fs.String("os", "", "android|ios|harmonyos|windows|macos (required when the report has no device info)")
+	deviceID := fs.String("device-id", "", "attach to an existing device of the case")
+	deviceName := fs.String("device-name", "", "device display name for a new imported device")
+	operator := fs.String("operator", "system", "operator name")
+	note := fs.String("note", "", "import note")
+	asJSON := fs.Bool("json", true, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+	if strings.TrimSpace(*filePath) == "" {
+		return fmt.Errorf("--file is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := forensicimport.Run(ctx, sqliteadapter.NewStore(db), forensicimport.Input{
+		CaseID:           *caseID,
+		SourcePath:       *filePath,
+		Format:           *format,
+		OS:               *osType,
+		DeviceID:         *deviceID,
+		DeviceName:       *deviceName,
+		Operator:         *operator,
+		Note:             *note,
+		EvidenceRoot:     *evidenceRoot,
+		WalletRulePath:   *walletPath,
+		ExchangeRulePath: *exchangePath,
+		RegexRulePath:    *regexPath,
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(res)
+	}
+	fmt.Printf("import completed: tool=%s format=%s case_id=%s device_id=%s\n", res.Tool, res.Format, res.CaseID, res.DeviceID)
+	fmt.Printf("apps=%d visits=%d skipped=%d hits=%d\n", res.AppCount, res.VisitCount, res.Skipped, len(res.HitIDs))
+	fmt.Printf("snapshot=%s sha256=%s\n", res.SnapshotPath, res.SourceSHA256)
+	return nil
+}
@@ -9,31 +9,76 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"crypto-inspector/internal/adapters/rules"
 	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
 	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
 	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/budget"
+	"crypto-inspector/internal/platform/i18n"
+	"crypto-inspector/internal/platform/progress"
+	"crypto-inspector/internal/platform/toolbox"
+	"crypto-inspector/internal/services/anchor"
+	"crypto-inspector/internal/services/casemgmt"
 	"crypto-inspector/internal/services/caseview"
-	"crypto-inspector/internal/services/forensicexport"
-	"crypto-inspector/internal/services/forensicpdf"
+	"crypto-inspector/internal/services/dbreplica"
+	"crypto-inspector/internal/services/exporter"
+	_ "crypto-inspector/internal/services/exporter/builtin"
+	"crypto-inspector/internal/services/extsync"
+	"crypto-inspector/internal/services/hitrollup"
 	"crypto-inspector/internal/services/hostscan"
 	"crypto-inspector/internal/services/mobilescan"
+	"crypto-inspector/internal/services/privacy"
+	"crypto-inspector/internal/services/siemforward"
+	"crypto-inspector/internal/services/training"
+	"crypto-inspector/internal/services/vmscan"
 	"crypto-inspector/internal/services/webapp"
 
 	_ "modernc.org/sqlite"
 )
 
+// cliLocale 是本次命令的输出语言（main 按 --lang / CRYPTO_INSPECTOR_LANG 设置），用于帮助文本等不经过 context 的输出。
+var cliLocale = i18n.New(i18n.EN)
+
 // CLI 入口。所有子命令错误都统一输出到 stderr 并返回非 0 状态码。
+// 错误文本按 --lang 渲染，error_code 行保持稳定，脚本应依赖错误码而不是文本。
 func main() {
-	if err := run(context.Background(), os.Args[1:]); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	args, lang, err := splitLangFlag(os.Args[1:])
+	cliLocale = i18n.New(lang)
+	if err == nil {
+		err = run(i18n.WithLocalizer(context.Background(), cliLocale), args)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, cliLocale.T("cli.error", apperr.Localize(cliLocale, err)))
+		fmt.Fprintf(os.Stderr, "error_code=%s\n", apperr.CodeOf(err))
 		os.Exit(1)
 	}
 }
 
+// splitLangFlag 取出命令前的全局 --lang（--lang zh-CN / --lang=zh-CN），未指定时读 CRYPTO_INSPECTOR_LANG。
+func splitLangFlag(args []string) ([]string, i18n.Lang, error) {
+	explicit := ""
+	for len(args) > 0 {
+		if v, ok := strings.CutPrefix(args[0], "--lang="); ok {
+			explicit, args = v, args[1:]
+		} else if args[0] == "--lang" && len(args) > 1 {
+			explicit, args = args[1], args[2:]
+		} else {
+			break
+		}
+	}
+	lang, err := i18n.Resolve(explicit)
+	if err != nil {
+		return args, lang, apperr.Wrap(apperr.CodeInvalidArgument, err, "")
+	}
+	return args, lang, nil
+}
+
 // run 是一级命令路由：migrate / rules / scan。
 func run(ctx context.Context, args []string) error {
 	if len(args) == 0 {
@@ -54,11 +99,41 @@ func run(ctx context.Context, args []string) error {
 		return runExport(ctx, args[1:])
 	case "verify":
 		return runVerify(ctx, args[1:])
+	case "audit":
+		return runAudit(ctx, args[1:])
+	case "report":
+		return runReport(ctx, args[1:])
+	case "hits":
+		return runHits(ctx, args[1:])
+	case "import":
+		return runImport(ctx, args[1:])
+	case "storage":
+		return runStorage(ctx, args[1:])
+	case "evidence":
+		return runEvidence(ctx, args[1:])
+	case "policy":
+		return runPolicy(ctx, args[1:])
+	case "auth":
+		return runAuth(ctx, args[1:])
+	case "case":
+		return runCase(ctx, args[1:])
+	case "watchlist":
+		return runWatchlist(ctx, args[1:])
+	case "replica":
+		return runReplica(ctx, args[1:])
+	case "tools":
+		return runTools(ctx, args[1:])
 	case "serve":
 		return runServe(ctx, args[1:])
+	case "dev":
+		return runDev(ctx, args[1:])
+	case "training":
+		return runTraining(ctx, args[1:])
+	case "bench":
+		return runBench(ctx, args[1:])
 	default:
 		printUsage()
-		return fmt.Errorf("unknown command: %s", args[0])
+		return apperr.T(apperr.CodeInvalidArgument, "cli.unknown_command", args[0])
 	}
 }
 
@@ -68,9 +143,15 @@ func runMigrate(ctx context.Context, args []string) error {
 
 	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
 	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	offloadOver := fs.Int64("offload-payloads-over", 0, "move artifact payloads larger than N bytes out of the database (lazy-loaded from snapshot files); 0 = keep inline")
+	maxPayload := fs.Int64("max-payload-bytes", -1, "split JSON array artifacts larger than N bytes into linked parts on later scans; 0 = never split; -1 = keep the current setting (64MiB on new databases)")
+	vacuum := fs.Bool("vacuum", false, "run VACUUM after migration to reclaim free pages")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if *offloadOver < 0 {
+		return fmt.Errorf("--offload-payloads-over must be >= 0")
+	}
 
 	if err := os.MkdirAll(filepath.Dir(*dbPath), 0o755); err != nil {
 		return fmt.Errorf("create db directory: %w", err)
@@ -92,10 +173,36 @@ func runMigrate(ctx context.Context, args []string) error {
 	}
 
 	fmt.Printf("migrations applied successfully: db=%s\n", *dbPath)
+
+	if *maxPayload >= 0 {
+		if err := sqliteadapter.NewStore(db).SetArtifactPayloadMaxBytes(ctx, *maxPayload); err != nil {
+			return fmt.Errorf("set artifact payload max bytes: %w", err)
+		}
+		fmt.Printf("artifact payload max bytes: %d\n", *maxPayload)
+	}
+
+	if *offloadOver > 0 {
+		store := sqliteadapter.NewStore(db)
+		if err := store.SetPayloadInlineLimit(ctx, *offloadOver); err != nil {
+			return fmt.Errorf("set payload inline limit: %w", err)
+		}
+		stats, err := store.OffloadArtifactPayloads(ctx, *offloadOver)
+		if err != nil {
+			return fmt.Errorf("offload artifact payloads: %w", err)
+		}
+		fmt.Printf("payload offload: threshold=%d scanned=%d offloaded=%d skipped=%d bytes_freed=%d\n",
+			*offloadOver, stats.Scanned, stats.Offloaded, stats.Skipped, stats.BytesFreed)
+	}
+	if *vacuum {
+		if _, err := db.ExecContext(ctx, "VACUUM"); err != nil {
+			return fmt.Errorf("vacuum sqlite: %w", err)
+		}
+		fmt.Println("vacuum completed")
+	}
 	return nil
 }
 
-// runRules 是二级命令路由，目前支持 rules validate。
+// runRules 是二级命令路由，目前支持 rules validate / rules sync-extensions。
 func runRules(ctx context.Context, args []string) error {
 	if len(args) == 0 {
 		printRulesUsage()
@@ -105,13 +212,15 @@ func runRules(ctx context.Context, args []string) error {
 	switch args[0] {
 	case "validate":
 		return runRulesValidate(ctx, args[1:])
+	case "sync-extensions":
+		return runRulesSyncExtensions(ctx, args[1:])
 	default:
 		printRulesUsage()
 		return fmt.Errorf("unknown rules command: %s", args[0])
 	}
 }
 
-// runScan 是二级命令路由，目前支持 scan host / scan mobile。
+// runScan 是二级命令路由，目前支持 scan host / scan mobile / scan offline / scan vm / scan all。
 func runScan(ctx context.Context, args []string) error {
 	if len(args) == 0 {
 		printScanUsage()
@@ -123,6 +232,10 @@ func runScan(ctx context.Context, args []string) error {
 		return runScanHost(ctx, args[1:])
 	case "mobile":
 		return runScanMobile(ctx, args[1:])
+	case "offline":
+		return runScanOffline(ctx, args[1:])
+	case "vm":
+		return runScanVM(ctx, args[1:])
 	case "all":
 		return runScanAll(ctx, args[1:])
 	default:
@@ -140,6 +253,107 @@ func runScanHost(ctx context.Context, args []string) error {
 	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory")
 	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
 	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
+	regexPath := fs.String("regex-rules", cfg.RegexRulePath, "custom regex rule file (optional; skipped when missing)")
+	caseID := fs.String("case-id", "", "existing case id (optional)")
+	operator := fs.String("operator", "system", "operator id or name")
+	note := fs.String("note", "", "case note")
+	authOrder := fs.String("auth-order", "", "authorization order/work ticket id (optional in internal mode)")
+	authBasis := fs.String("auth-basis", "", "authorization legal basis reference (optional)")
+	requireAuthOrder := fs.Bool("require-auth-order", false, "require auth order in this run (recommended for external mode)")
+	privacyMode := fs.String("privacy-mode", "off", "privacy mode switch (reserved): off|masked")
+	snapshotCompression := fs.String("snapshot-compression", "none", "compress JSON evidence snapshots: none|gzip (sha256 covers the stored bytes)")
+	ethRPC := fs.String("eth-rpc", "", "ethereum rpc url for resolving .eth names in history (empty = record only)")
+	bnbRPC := fs.String("bnb-rpc", "", "bnb chain rpc url for resolving .bnb names in history (empty = record only)")
+	scanVMImages := fs.Bool("scan-vm-images", false, "after the host scan, extract discovered vm disk images read-only and scan them as child devices")
+	vmExtractor := fs.String("vm-extractor", "auto", "vm image extractor: auto|guestmount|7z")
+	scanMessengers := fs.Bool("scan-messengers", false, "also collect telegram desktop/discord installs and channel names from readable local caches")
+	dryRun := fs.Bool("dry-run", false, "only run prechecks and print what would be collected as json (no artifacts, no case)")
+	maxBytes := fs.Int64("max-bytes", 0, "collection budget for raw history db copies in bytes; sources over budget are skipped, parsed records are kept (0 = unlimited)")
+	skipHistoryDB := fs.Bool("skip-history-db", false, "do not copy raw browser history databases (visit records are still parsed)")
+	elevate := fs.Bool("elevate", false, "when key sources need admin/root, relaunch elevated (UAC on windows, authorization dialog on macos)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	scanOpts := hostscan.Options{
+		DBPath:              *dbPath,
+		EvidenceRoot:        *evidenceRoot,
+		WalletRulePath:      *walletPath,
+		ExchangeRulePath:    *exchangePath,
+		RegexRulePath:       *regexPath,
+		CaseID:              *caseID,
+		Operator:            *operator,
+		Note:                *note,
+		AuthorizationOrder:  *authOrder,
+		AuthorizationBasis:  *authBasis,
+		RequireAuthOrder:    *requireAuthOrder,
+		PrivacyMode:         *privacyMode,
+		SnapshotCompression: *snapshotCompression,
+		ETHRPCURL:           *ethRPC,
+		BNBRPCURL:           *bnbRPC,
+		ScanMessengers:      *scanMessengers,
+		Budget:              newScanBudget(*maxBytes, *skipHistoryDB),
+		SkipHistoryDB:       *skipHistoryDB,
+	}
+	if *dryRun {
+		preview, err := hostscan.Preview(ctx, scanOpts)
+		if err != nil {
+			return err
+		}
+		return printJSON(preview)
+	}
+	if relaunched, err := relaunchElevatedIfNeeded(ctx, []string{"scan", "host"}, args, *elevate); relaunched || err != nil {
+		return err
+	}
+	result, err := hostscan.Run(ctx, scanOpts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("host scan completed")
+	fmt.Printf("case_id=%s trace_id=%s\n", result.CaseID, result.TraceID)
+	fmt.Printf("device=%s (%s)\n", result.DeviceName, result.DeviceOS)
+	fmt.Printf("artifacts=%d hits=%d wallet_hits=%d exchange_hits=%d\n",
+		result.ArtifactCount, result.HitCount, result.WalletHits, result.ExchangeHits,
+	)
+	if result.ReportPath != "" {
+		fmt.Printf("report=%s\n", result.ReportPath)
+	}
+	if len(result.Warnings) > 0 {
+		fmt.Printf("warnings=%s\n", strings.Join(result.Warnings, " | "))
+	}
+	printDuplicateCases(result.DuplicateCases)
+	printBudget(result.Budget)
+
+	if *scanVMImages {
+		scanOpts.CaseID = result.CaseID
+		scanOpts.Note = ""
+		vms, warnings := vmscan.ScanDiscovered(ctx, vmscan.Options{Scan: scanOpts, Extractor: *vmExtractor}, result.DeviceID)
+		fmt.Printf("vm_images_scanned=%d\n", len(vms))
+		for _, vm := range vms {
+			printVMScanResult(vm)
+		}
+		if len(warnings) > 0 {
+			fmt.Printf("vm_warnings=%s\n", strings.Join(warnings, " | "))
+		}
+	}
+	return nil
+}
+
+// runScanOffline 对其他团队拷贝来的目录（浏览器 profile / 注册表 hive）执行离线扫描：
+// 解析器与匹配规则同 scan host，证据 acquisition_method=offline_import，并记录输入目录摘要。
+func runScanOffline(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("scan offline", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory")
+	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
+	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
+	regexPath := fs.String("regex-rules", cfg.RegexRulePath, "custom regex rule file (optional; skipped when missing)")
+	input := fs.String("input", "", "directory of copied browser profiles / registry hives (required)")
+	osType := fs.String("os", "", "source os: windows|macos (default: detect from input)")
+	deviceName := fs.String("device-name", "", "device display name (default: input directory name)")
 	caseID := fs.String("case-id", "", "existing case id (optional)")
 	operator := fs.String("operator", "system", "operator id or name")
 	note := fs.String("note", "", "case note")
@@ -147,30 +361,44 @@ func runScanHost(ctx context.Context, args []string) error {
 	authBasis := fs.String("auth-basis", "", "authorization legal basis reference (optional)")
 	requireAuthOrder := fs.Bool("require-auth-order", false, "require auth order in this run (recommended for external mode)")
 	privacyMode := fs.String("privacy-mode", "off", "privacy mode switch (reserved): off|masked")
+	snapshotCompression := fs.String("snapshot-compression", "none", "compress JSON evidence snapshots: none|gzip (sha256 covers the stored bytes)")
+	ethRPC := fs.String("eth-rpc", "", "ethereum rpc url for resolving .eth names in history (empty = record only)")
+	bnbRPC := fs.String("bnb-rpc", "", "bnb chain rpc url for resolving .bnb names in history (empty = record only)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if strings.TrimSpace(*input) == "" {
+		return fmt.Errorf("--input is required")
+	}
 
 	result, err := hostscan.Run(ctx, hostscan.Options{
-		DBPath:             *dbPath,
-		EvidenceRoot:       *evidenceRoot,
-		WalletRulePath:     *walletPath,
-		ExchangeRulePath:   *exchangePath,
-		CaseID:             *caseID,
-		Operator:           *operator,
-		Note:               *note,
-		AuthorizationOrder: *authOrder,
-		AuthorizationBasis: *authBasis,
-		RequireAuthOrder:   *requireAuthOrder,
-		PrivacyMode:        *privacyMode,
+		DBPath:              *dbPath,
+		EvidenceRoot:        *evidenceRoot,
+		WalletRulePath:      *walletPath,
+		ExchangeRulePath:    *exchangePath,
+		RegexRulePath:       *regexPath,
+		CaseID:              *caseID,
+		Operator:            *operator,
+		Note:                *note,
+		AuthorizationOrder:  *authOrder,
+		AuthorizationBasis:  *authBasis,
+		RequireAuthOrder:    *requireAuthOrder,
+		PrivacyMode:         *privacyMode,
+		SnapshotCompression: *snapshotCompression,
+		ETHRPCURL:           *ethRPC,
+		BNBRPCURL:           *bnbRPC,
+		OfflineInputDir:     *input,
+		OfflineOS:           *osType,
+		DeviceName:          *deviceName,
 	})
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("host scan completed")
-	fmt.Printf("case_id=%s\n", result.CaseID)
+	fmt.Println("offline scan completed")
+	fmt.Printf("case_id=%s trace_id=%s\n", result.CaseID, result.TraceID)
 	fmt.Printf("device=%s (%s)\n", result.DeviceName, result.DeviceOS)
+	fmt.Printf("source=%s sha256=%s files=%d\n", result.SourceDir, result.SourceSHA256, result.SourceFileCount)
 	fmt.Printf("artifacts=%d hits=%d wallet_hits=%d exchange_hits=%d\n",
 		result.ArtifactCount, result.HitCount, result.WalletHits, result.ExchangeHits,
 	)
@@ -180,10 +408,11 @@ func runScanHost(ctx context.Context, args []string) error {
 	if len(result.Warnings) > 0 {
 		fmt.Printf("warnings=%s\n", strings.Join(result.Warnings, " | "))
 	}
+	printDuplicateCases(result.DuplicateCases)
 	return nil
 }
 
-// runScanMobile 执行移动端扫描（Android + iOS 骨架）。
+// runScanMobile 执行移动端扫描（Android + iOS 骨架 + HarmonyOS hdc）。
 func runScanMobile(ctx context.Context, args []string) error {
 	cfg := app.DefaultConfig()
 
@@ -193,18 +422,23 @@ func runScanMobile(ctx context.Context, args []string) error {
 	iosBackupDir := fs.String("ios-backup-dir", "data/evidence/ios_backups", "ios backup root directory")
 	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
 	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
+	regexPath := fs.String("regex-rules", cfg.RegexRulePath, "custom regex rule file (optional; skipped when missing)")
 	caseID := fs.String("case-id", "", "existing case id (optional)")
 	operator := fs.String("operator", "system", "operator id or name")
 	note := fs.String("note", "", "case note")
 	authOrder := fs.String("auth-order", "", "authorization order/work ticket id (optional in internal mode)")
 	authBasis := fs.String("auth-basis", "", "authorization legal basis reference (optional)")
 	requireAuthOrder := fs.Bool("require-auth-order", false, "require auth order in this run (recommended for external mode)")
-	requireAuthorized := fs.Bool("require-authorized", false, "require at least one authorized device (Android 调试授权 / iOS 配对授权)")
+	requireAuthorized := fs.Bool("require-authorized", false, "require at least one authorized device (Android 调试授权 / iOS 配对授权 / HarmonyOS 调试授权)")
 	enableIOSFullBackup := fs.Bool("ios-full-backup", true, "try full iOS backup when idevicebackup2 is available")
 	privacyMode := fs.String("privacy-mode", "off", "privacy mode switch (reserved): off|masked")
+	snapshotCompression := fs.String("snapshot-compression", "none", "compress JSON evidence snapshots: none|gzip (sha256 covers the stored bytes)")
+	toolsDir := fs.String("tools-dir", toolbox.DefaultDir, "managed adb/libimobiledevice/hdc tools directory (preferred over PATH)")
+	maxBytes := fs.Int64("max-bytes", 0, "collection budget for ios full backups in bytes (estimated from device data usage); devices over budget get metadata only (0 = unlimited)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	toolbox.SetDir(*toolsDir)
 
 	result, err := mobilescan.Run(ctx, mobilescan.Options{
 		DBPath:              *dbPath,
@@ -212,6 +446,7 @@ func runScanMobile(ctx context.Context, args []string) error {
 		IOSBackupDir:        *iosBackupDir,
 		WalletRulePath:      *walletPath,
 		ExchangeRulePath:    *exchangePath,
+		RegexRulePath:       *regexPath,
 		CaseID:              *caseID,
 		Operator:            *operator,
 		Note:                *note,
@@ -221,15 +456,17 @@ func runScanMobile(ctx context.Context, args []string) error {
 		RequireAuthorized:   *requireAuthorized,
 		EnableIOSFullBackup: *enableIOSFullBackup,
 		PrivacyMode:         *privacyMode,
+		SnapshotCompression: *snapshotCompression,
+		Budget:              newScanBudget(*maxBytes, !*enableIOSFullBackup),
 	})
 	if err != nil {
 		return err
 	}
 
 	fmt.Println("mobile scan completed")
-	fmt.Printf("case_id=%s\n", result.CaseID)
-	fmt.Printf("devices=%d android=%d ios=%d artifacts=%d hits=%d wallet_hits=%d\n",
-		result.DeviceCount, result.AndroidCount, result.IOSCount, result.ArtifactCount, result.HitCount, result.WalletHits,
+	fmt.Printf("case_id=%s trace_id=%s\n", result.CaseID, result.TraceID)
+	fmt.Printf("devices=%d android=%d ios=%d harmonyos=%d artifacts=%d hits=%d wallet_hits=%d\n",
+		result.DeviceCount, result.AndroidCount, result.IOSCount, result.HarmonyCount, result.ArtifactCount, result.HitCount, result.WalletHits,
 	)
 	if result.ReportPath != "" {
 		fmt.Printf("report=%s\n", result.ReportPath)
@@ -237,6 +474,8 @@ func runScanMobile(ctx context.Context, args []string) error {
 	if len(result.Warnings) > 0 {
 		fmt.Printf("warnings=%s\n", strings.Join(result.Warnings, " | "))
 	}
+	printDuplicateCases(result.DuplicateCases)
+	printBudget(result.Budget)
 	return nil
 }
 
@@ -250,6 +489,7 @@ func runScanAll(ctx context.Context, args []string) error {
 	iosBackupDir := fs.String("ios-backup-dir", "data/evidence/ios_backups", "ios backup root directory")
 	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
 	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
+	regexPath := fs.String("regex-rules", cfg.RegexRulePath, "custom regex rule file (optional; skipped when missing)")
 	caseID := fs.String("case-id", "", "existing case id (optional)")
 	operator := fs.String("operator", "system", "operator id or name")
 	note := fs.String("note", "", "case note")
@@ -259,9 +499,21 @@ func runScanAll(ctx context.Context, args []string) error {
 	continueOnError := fs.Bool("continue-on-error", true, "continue mobile scan even if host scan fails")
 	enableIOSFullBackup := fs.Bool("ios-full-backup", true, "try full iOS backup when idevicebackup2 is available")
 	privacyMode := fs.String("privacy-mode", "off", "privacy mode switch (reserved): off|masked")
+	snapshotCompression := fs.String("snapshot-compression", "none", "compress JSON evidence snapshots: none|gzip (sha256 covers the stored bytes)")
+	ethRPC := fs.String("eth-rpc", "", "ethereum rpc url for resolving .eth names in history (empty = record only)")
+	bnbRPC := fs.String("bnb-rpc", "", "bnb chain rpc url for resolving .bnb names in history (empty = record only)")
+	toolsDir := fs.String("tools-dir", toolbox.DefaultDir, "managed adb/libimobiledevice tools directory (preferred over PATH)")
+	maxBytes := fs.Int64("max-bytes", 0, "collection budget shared by raw history db copies and ios full backups in bytes (0 = unlimited)")
+	skipHistoryDB := fs.Bool("skip-history-db", false, "do not copy raw browser history databases (visit records are still parsed)")
+	elevate := fs.Bool("elevate", false, "when key sources need admin/root, relaunch elevated (UAC on windows, authorization dialog on macos)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if relaunched, err := relaunchElevatedIfNeeded(ctx, []string{"scan", "all"}, args, *elevate); relaunched || err != nil {
+		return err
+	}
+	toolbox.SetDir(*toolsDir)
+	scanBudget := newScanBudget(*maxBytes, *skipHistoryDB || !*enableIOSFullBackup)
 
 	mode := strings.ToLower(strings.TrimSpace(*profile))
 	requireAuthOrder := false
@@ -282,17 +534,24 @@ func runScanAll(ctx context.Context, args []string) error {
 	var mobileErr error
 
 	hostRes, hostErr = hostscan.Run(ctx, hostscan.Options{
-		DBPath:             *dbPath,
-		EvidenceRoot:       *evidenceRoot,
-		WalletRulePath:     *walletPath,
-		ExchangeRulePath:   *exchangePath,
-		CaseID:             *caseID,
-		Operator:           *operator,
-		Note:               *note,
-		AuthorizationOrder: *authOrder,
-		AuthorizationBasis: *authBasis,
-		RequireAuthOrder:   requireAuthOrder,
-		PrivacyMode:        *privacyMode,
+		DBPath:              *dbPath,
+		EvidenceRoot:        *evidenceRoot,
+		WalletRulePath:      *walletPath,
+		ExchangeRulePath:    *exchangePath,
+		RegexRulePath:       *regexPath,
+		CaseID:              *caseID,
+		Operator:            *operator,
+		Note:                *note,
+		AuthorizationOrder:  *authOrder,
+		AuthorizationBasis:  *authBasis,
+		RequireAuthOrder:    requireAuthOrder,
+		Profile:             mode,
+		PrivacyMode:         *privacyMode,
+		SnapshotCompression: *snapshotCompression,
+		ETHRPCURL:           *ethRPC,
+		BNBRPCURL:           *bnbRPC,
+		Budget:              scanBudget,
+		SkipHistoryDB:       *skipHistoryDB,
 	})
 	if hostErr != nil && !*continueOnError {
 		return fmt.Errorf("scan all host failed: %w", hostErr)
@@ -308,15 +567,19 @@ func runScanAll(ctx context.Context, args []string) error {
 		IOSBackupDir:        *iosBackupDir,
 		WalletRulePath:      *walletPath,
 		ExchangeRulePath:    *exchangePath,
+		RegexRulePath:       *regexPath,
 		CaseID:              sharedCaseID,
 		Operator:            *operator,
 		Note:                *note,
 		AuthorizationOrder:  *authOrder,
 		AuthorizationBasis:  *authBasis,
 		RequireAuthOrder:    requireAuthOrder,
+		Profile:             mode,
 		RequireAuthorized:   requireAuthorized,
 		EnableIOSFullBackup: *enableIOSFullBackup,
 		PrivacyMode:         *privacyMode,
+		SnapshotCompression: *snapshotCompression,
+		Budget:              scanBudget,
 	})
 
 	fmt.Printf("scan all completed profile=%s\n", mode)
@@ -337,6 +600,7 @@ func runScanAll(ctx context.Context, args []string) error {
 	if mobileErr != nil {
 		fmt.Printf("mobile_error=%v\n", mobileErr)
 	}
+	printBudget(scanBudget.Since(0))
 
 	if hostErr != nil && mobileErr != nil {
 		return fmt.Errorf("scan all failed: host=%v; mobile=%v", hostErr, mobileErr)
@@ -367,21 +631,19 @@ func runExport(ctx context.Context, args []string) error {
 		printExportUsage()
 		return nil
 	}
-	switch args[0] {
-	case "forensic-zip":
-		return runExportForensicZip(ctx, args[1:])
-	case "forensic-pdf":
-		return runExportForensicPDF(ctx, args[1:])
-	default:
+	e, ok := exporter.Lookup(args[0])
+	if !ok {
 		printExportUsage()
 		return fmt.Errorf("unknown export command: %s", args[0])
 	}
+	return runExportKind(ctx, e, args[1:])
 }
 
-func runExportForensicZip(ctx context.Context, args []string) error {
+// runExportKind 执行一种已注册的导出格式（参数对所有格式通用，格式不需要的参数会被忽略）。
+func runExportKind(ctx context.Context, e exporter.Exporter, args []string) error {
 	cfg := app.DefaultConfig()
 
-	fs := flag.NewFlagSet("export forensic-zip", flag.ContinueOnError)
+	fs := flag.NewFlagSet("export "+e.Kind(), flag.ContinueOnError)
 	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
 	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory")
 	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
@@ -390,34 +652,62 @@ func runExportForensicZip(ctx context.Context, args []string) error {
 	operator := fs.String("operator", "system", "operator id or name")
 	note := fs.String("note", "", "export note")
 	outDir := fs.String("out-dir", "", "export output directory (optional)")
+	uiScreenshots := fs.Bool("ui-screenshots", false, "forensic-pdf: append headless-browser screenshots of the internal html hit tables")
+	browser := fs.String("browser", "", "forensic-pdf: chrome/chromium/edge executable for --ui-screenshots (auto-detect when empty)")
+	includeComments := fs.Bool("include-comments", false, "forensic-pdf: include analyst comments under hits and artifacts")
+	cmsConfig := fs.String("cms-config", "", "case management integration config; pushes to it when this kind is listed in push_on_export")
+	anchorConfig := fs.String("anchor-config", "", "evidence anchor config; anchors the package sha256 when this kind is listed in anchor_on_export")
+	showProgress := fs.Bool("progress", false, "print generation progress to stderr")
+	maxDevices := fs.Int("max-devices", 0, "forensic-pdf: devices listed in the report body (0 = default 100, negative = no limit); the full list is attached as CSV")
+	maxPrechecks := fs.Int("max-prechecks", 0, "forensic-pdf: prechecks listed in the report body (0 = default 200, negative = no limit)")
+	maxHits := fs.Int("max-hits", 0, "forensic-pdf: rule hits listed in the report body (0 = default 300, negative = no limit)")
+	maxArtifacts := fs.Int("max-artifacts", 0, "forensic-pdf: artifacts listed in the report body (0 = default 200, negative = no limit)")
+	encrypt := fs.Bool("encrypt", false, "forensic-zip/disclosure-zip: encrypt every entry with AES-256 (password read from --password-env)")
+	passwordEnv := fs.String("password-env", "CRYPTO_INSPECTOR_EXPORT_PASSWORD", "env var holding the export password for --encrypt")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 	if strings.TrimSpace(*caseID) == "" {
 		return fmt.Errorf("--case-id is required")
 	}
-
-	if err := os.MkdirAll(filepath.Dir(*dbPath), 0o755); err != nil {
-		return fmt.Errorf("create db directory: %w", err)
+	cms, err := casemgmt.LoadConfig(*cmsConfig)
+	if err != nil {
+		return err
+	}
+	ac, err := anchor.LoadConfig(*anchorConfig)
+	if err != nil {
+		return err
+	}
+	// 导出密码只从环境变量读取，避免出现在命令行/进程列表中。
+	password := ""
+	if *encrypt {
+		if !exporter.SupportsEncryption(e) {
+			return fmt.Errorf("--encrypt is not supported for %s exports", e.Kind())
+		}
+		password = os.Getenv(strings.TrimSpace(*passwordEnv))
+		if password == "" {
+			return fmt.Errorf("--encrypt requires the password in env var %s", *passwordEnv)
+		}
 	}
 
-	db, err := sql.Open("sqlite", *dbPath)
+	db, err := openAuditDB(ctx, *dbPath)
 	if err != nil {
-		return fmt.Errorf("open sqlite: %w", err)
+		return err
 	}
 	defer db.Close()
-	db.SetMaxOpenConns(1)
-	if _, err := db.ExecContext(ctx, `PRAGMA busy_timeout = 5000`); err != nil {
-		return fmt.Errorf("set busy_timeout: %w", err)
-	}
+	store := sqliteadapter.NewStore(db)
 
-	migrator := sqliteadapter.NewMigrator(db)
-	if err := migrator.Up(ctx); err != nil {
-		return fmt.Errorf("apply migrations: %w", err)
+	// Ctrl+C 时中止导出：生成函数删除半成品文件并记录失败审计，不留下报告记录。
+	sigCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	exportCtx := sigCtx
+	if *showProgress {
+		exportCtx = progress.WithReporter(sigCtx, func(u progress.Update) {
+			fmt.Fprintf(os.Stderr, "progress stage=%s %d/%d (%d%%)\n", u.Stage, u.Done, u.Total, u.Percent)
+		})
 	}
 
-	store := sqliteadapter.NewStore(db)
-	res, err := forensicexport.GenerateForensicZip(ctx, store, forensicexport.ZipOptions{
+	res, err := e.Export(exportCtx, store, exporter.Request{
 		CaseID:           strings.TrimSpace(*caseID),
 		DBPath:           *dbPath,
 		EvidenceRoot:     *evidenceRoot,
@@ -426,70 +716,64 @@ func runExportForensicZip(ctx context.Context, args []string) error {
 		Operator:         strings.TrimSpace(*operator),
 		Note:             strings.TrimSpace(*note),
 		ExportDir:        strings.TrimSpace(*outDir),
+		UIScreenshots:    *uiScreenshots,
+		BrowserPath:      strings.TrimSpace(*browser),
+		IncludeComments:  *includeComments,
+		MaxDevices:       *maxDevices,
+		MaxPrechecks:     *maxPrechecks,
+		MaxHits:          *maxHits,
+		MaxArtifacts:     *maxArtifacts,
+		Password:         password,
 	})
 	if err != nil {
+		if sigCtx.Err() != nil && ctx.Err() == nil {
+			return fmt.Errorf("%s export interrupted: %w", e.Kind(), err)
+		}
 		return err
 	}
 
-	fmt.Println("forensic zip export completed")
-	fmt.Printf("case_id=%s report_id=%s\n", res.CaseID, res.ReportID)
-	fmt.Printf("zip=%s\n", res.ZipPath)
-	fmt.Printf("zip_sha256=%s\n", res.ZipSHA256)
-	if len(res.Warnings) > 0 {
-		fmt.Printf("warnings=%s\n", strings.Join(res.Warnings, " | "))
-	}
-	return nil
-}
-
-func runExportForensicPDF(ctx context.Context, args []string) error {
-	cfg := app.DefaultConfig()
-
-	fs := flag.NewFlagSet("export forensic-pdf", flag.ContinueOnError)
-	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
-	caseID := fs.String("case-id", "", "case id (required)")
-	operator := fs.String("operator", "system", "operator id or name")
-	note := fs.String("note", "", "export note")
-	if err := fs.Parse(args); err != nil {
-		return err
-	}
-	if strings.TrimSpace(*caseID) == "" {
-		return fmt.Errorf("--case-id is required")
-	}
-
-	if err := os.MkdirAll(filepath.Dir(*dbPath), 0o755); err != nil {
-		return fmt.Errorf("create db directory: %w", err)
-	}
-
-	db, err := sql.Open("sqlite", *dbPath)
-	if err != nil {
-		return fmt.Errorf("open sqlite: %w", err)
-	}
-	defer db.Close()
-	db.SetMaxOpenConns(1)
-	if _, err := db.ExecContext(ctx, `PRAGMA busy_timeout = 5000`); err != nil {
-		return fmt.Errorf("set busy_timeout: %w", err)
+	fmt.Printf("%s export completed\n", strings.ReplaceAll(e.Kind(), "-", " "))
+	fmt.Printf("case_id=%s report_id=%s\n", strings.TrimSpace(*caseID), res.ReportID)
+	if res.ReportNo != "" {
+		fmt.Printf("report_no=%s\n", res.ReportNo)
+	}
+	fmt.Printf("%s=%s\n", res.FileKey, res.Path)
+	fmt.Printf("%s_sha256=%s\n", res.FileKey, res.SHA256)
+	extraKeys := make([]string, 0, len(res.Extra))
+	for k := range res.Extra {
+		extraKeys = append(extraKeys, k)
+	}
+	sort.Strings(extraKeys)
+	for _, k := range extraKeys {
+		switch v := res.Extra[k].(type) {
+		case string, bool, int, int64, float64:
+			fmt.Printf("%s=%v\n", k, v)
+		default:
+			// 结构化字段（如 forensic-pdf 的 truncated 截断清单）按 JSON 输出。
+			raw, _ := json.Marshal(v)
+			fmt.Printf("%s=%s\n", k, raw)
+		}
 	}
-
-	migrator := sqliteadapter.NewMigrator(db)
-	if err := migrator.Up(ctx); err != nil {
-		return fmt.Errorf("apply migrations: %w", err)
+	if cms.PushOnExportKind(e.Kind()) {
+		// 推送失败不影响已完成的导出，只输出告警（可稍后用 case push 重试）。
+		rec, err := casemgmt.Push(ctx, store, cms, strings.TrimSpace(*caseID), casemgmt.Trigger{
+			Kind: "export", ExportKind: e.Kind(), ReportID: res.ReportID, Operator: strings.TrimSpace(*operator),
+		})
+		if err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("case management push failed: %v", err))
+		} else {
+			fmt.Printf("case_management_pushed=%s external_id=%s\n", rec.Endpoint, rec.ExternalID)
+		}
 	}
-
-	store := sqliteadapter.NewStore(db)
-	res, err := forensicpdf.GenerateForensicPDF(ctx, store, forensicpdf.Options{
-		CaseID:   strings.TrimSpace(*caseID),
-		DBPath:   *dbPath,
-		Operator: strings.TrimSpace(*operator),
-		Note:     strings.TrimSpace(*note),
-	})
-	if err != nil {
-		return err
+	if ac.AnchorOnExportKind(e.Kind()) {
+		// 锚定失败同样只告警（可稍后用 case anchor --report-id 重试）。
+		row, err := anchor.Anchor(ctx, store, ac, strings.TrimSpace(*caseID), res.ReportID, strings.TrimSpace(*operator))
+		if err != nil {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("evidence anchor failed: %v", err))
+		} else {
+			fmt.Printf("anchor_id=%s anchor_receipt_id=%s anchored_at=%d\n", row.AnchorID, row.ReceiptID, row.AnchoredAt)
+		}
 	}
-
-	fmt.Println("forensic pdf export completed")
-	fmt.Printf("case_id=%s report_id=%s\n", strings.TrimSpace(*caseID), res.ReportID)
-	fmt.Printf("pdf=%s\n", res.PDFPath)
-	fmt.Printf("pdf_sha256=%s\n", res.PDFSHA256)
 	if len(res.Warnings) > 0 {
 		fmt.Printf("warnings=%s\n", strings.Join(res.Warnings, " | "))
 	}
@@ -506,26 +790,107 @@ func runServe(ctx context.Context, args []string) error {
 	iosBackupDir := fs.String("ios-backup-dir", "data/evidence/ios_backups", "ios backup root directory")
 	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
 	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
+	chainProviders := fs.String("chain-providers", "", "chain provider config yaml (optional, adds query kinds)")
 	listen := fs.String("listen", "127.0.0.1:8787", "listen address")
 	enableIOSFullBackup := fs.Bool("ios-full-backup", true, "try full iOS backup when idevicebackup2 is available")
-	privacyMode := fs.String("privacy-mode", "off", "privacy mode switch (reserved): off|masked")
+	privacyMode := fs.String("privacy-mode", "off", "privacy mode: off|masked|partial (partial: ui shows masked urls/addresses, exports/downloads need an unmask token)")
+	unmaskGrantsEnv := fs.String("unmask-grants-env", "CRYPTO_INSPECTOR_UNMASK_GRANTS", "env var holding unmask grants for --privacy-mode partial: operator:token[,operator:token]")
+	snapshotCompression := fs.String("snapshot-compression", "none", "compress JSON evidence snapshots: none|gzip (sha256 covers the stored bytes)")
+	traceLog := fs.Bool("trace-log", false, "print request/service span timings to stderr")
+	noRateLimit := fs.Bool("no-rate-limit", false, "disable api rate limiting (single-user local use only)")
+	rateIP := fs.Float64("rate-ip", 0, "per-ip api requests per second (0=default 10)")
+	burstIP := fs.Int("burst-ip", 0, "per-ip api burst size (0=default 40)")
+	rateToken := fs.Float64("rate-token", 0, "per-token api requests per second (0=default 5)")
+	burstToken := fs.Int("burst-token", 0, "per-token api burst size (0=default 20)")
+	maxExports := fs.Int("max-concurrent-exports", 0, "max concurrent forensic exports (0=default 2)")
+	maxChain := fs.Int("max-concurrent-chain", 0, "max concurrent chain balance queries (0=default 4)")
+	csrfStrict := fs.Bool("csrf-strict", false, "require X-CSRF-Token on every browser write request")
+	forceHSTS := fs.Bool("hsts", false, "always send Strict-Transport-Security (when behind a TLS terminator)")
+	siemEndpoint := fs.String("siem-endpoint", "", "forward audit logs to SIEM: udp://host:514 | tcp://host:514 | file:///path")
+	siemFormat := fs.String("siem-format", "cef", "siem message format: cef|syslog")
+	siemState := fs.String("siem-state", "data/siem_forward_state.json", "siem forward cursor state file")
+	monitor := fs.Bool("monitor", false, "poll adb/idevice_id and push device connect/authorization events to the UI (SSE)")
+	monitorInterval := fs.Duration("monitor-interval", 2*time.Second, "device monitor polling interval")
+	cmsConfig := fs.String("cms-config", "", "case management integration config yaml (optional, enables push to the bureau case system)")
+	anchorConfig := fs.String("anchor-config", "", "evidence anchor config yaml (optional, enables anchoring export hashes with a timestamping/notary service)")
+	toolsDir := fs.String("tools-dir", toolbox.DefaultDir, "managed adb/libimobiledevice tools directory (preferred over PATH)")
+	replicaPath := fs.String("replica", "", "warm standby copy of the db on another disk, e.g. /Volumes/SSD/inspector.db (synced periodically)")
+	replicaInterval := fs.Duration("replica-interval", dbreplica.DefaultInterval, "db replica sync interval (max data loss window)")
+	trainingMode := fs.Bool("training", false, "training mode: separate db (default "+training.DefaultDBPath+"), host scans use synthetic sources, outputs watermarked "+model.TrainingWatermark)
+	lang := fs.String("lang", "", "default language of api error messages: en|zh-CN (default: global --lang / "+i18n.EnvVar+"); requests override with ?lang= or Accept-Language")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if *trainingMode {
+		// 未显式指定 --db / --evidence-dir 时改用演练目录，避免演练数据写入正式库。
+		set := map[string]bool{}
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if !set["db"] {
+			*dbPath = training.DefaultDBPath
+		}
+		if !set["evidence-dir"] {
+			*evidenceRoot = training.DefaultEvidenceRoot
+		}
+		if !set["ios-backup-dir"] {
+			*iosBackupDir = ""
+		}
+	}
+	toolbox.SetDir(*toolsDir)
+	// unmask 令牌只从环境变量读取，避免出现在命令行/进程列表中。
+	grants, err := privacy.ParseGrants(os.Getenv(strings.TrimSpace(*unmaskGrantsEnv)))
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", *unmaskGrantsEnv, err)
+	}
 
 	// 支持 Ctrl+C 优雅退出。
 	sigCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	if *lang == "" {
+		*lang = string(i18n.FromContext(ctx).Lang())
+	}
 	return webapp.Run(sigCtx, webapp.Options{
 		DBPath:              *dbPath,
 		EvidenceRoot:        *evidenceRoot,
 		IOSBackupDir:        *iosBackupDir,
 		WalletRulePath:      *walletPath,
 		ExchangeRulePath:    *exchangePath,
+		ChainProvidersPath:  strings.TrimSpace(*chainProviders),
+		CaseMgmtConfigPath:  strings.TrimSpace(*cmsConfig),
+		AnchorConfigPath:    strings.TrimSpace(*anchorConfig),
 		ListenAddr:          *listen,
 		EnableIOSFullBackup: *enableIOSFullBackup,
 		PrivacyMode:         *privacyMode,
+		UnmaskGrants:        grants,
+		SnapshotCompression: *snapshotCompression,
+		TraceLog:            *traceLog,
+		RateLimit: webapp.RateLimitOptions{
+			Disabled:        *noRateLimit,
+			PerIPRate:       *rateIP,
+			PerIPBurst:      *burstIP,
+			PerTokenRate:    *rateToken,
+			PerTokenBurst:   *burstToken,
+			MaxExports:      *maxExports,
+			MaxChainQueries: *maxChain,
+		},
+		Security: webapp.SecurityOptions{
+			CSRFStrict: *csrfStrict,
+			ForceHSTS:  *forceHSTS,
+		},
+		SIEM: siemforward.Options{
+			Endpoint:        strings.TrimSpace(*siemEndpoint),
+			Format:          strings.TrimSpace(*siemFormat),
+			StateFile:       strings.TrimSpace(*siemState),
+			VerifyIntegrity: true,
+		},
+		Monitor:         *monitor,
+		MonitorInterval: *monitorInterval,
+		Replica: dbreplica.Options{
+			Path:     strings.TrimSpace(*replicaPath),
+			Interval: *replicaInterval,
+		},
+		Training: *trainingMode,
+		Lang:     *lang,
 	})
 }
 
@@ -537,6 +902,7 @@ func runQueryHostHits(ctx context.Context, args []string) error {
 	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
 	caseID := fs.String("case-id", "", "case id (required)")
 	hitType := fs.String("hit-type", "", "optional hit type filter")
+	rollup := fs.Bool("rollup", false, "merge hits by rule + matched value across devices (case-level summary)")
 	asJSON := fs.Bool("json", true, "print as json")
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -549,6 +915,18 @@ func runQueryHostHits(ctx context.Context, args []string) error {
 	if err != nil {
 		return err
 	}
+	if *rollup {
+		rows := hitrollup.Build(view.Hits)
+		if *asJSON {
+			return printJSON(map[string]any{"case_id": *caseID, "rollup": rows})
+		}
+		fmt.Printf("case_id=%s hit_count=%d rollup_count=%d\n", *caseID, len(view.Hits), len(rows))
+		for _, r := range rows {
+			fmt.Printf("type=%s rule=%s matched=%s devices=%d hits=%d max_confidence=%.2f\n",
+				r.HitType, r.RuleID, r.MatchedValue, r.DeviceCount, r.HitCount, r.MaxConfidence)
+		}
+		return nil
+	}
 	if *asJSON {
 		return printJSON(view)
 	}
@@ -606,11 +984,12 @@ func runRulesValidate(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("rules validate", flag.ContinueOnError)
 	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
 	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
+	regexPath := fs.String("regex-rules", cfg.RegexRulePath, "custom regex rule file (optional; skipped when missing)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
-	loader := rules.NewLoader(*walletPath, *exchangePath)
+	loader := rules.NewLoader(*walletPath, *exchangePath).WithRegexFile(*regexPath)
 	loaded, err := loader.Load(ctx)
 	if err != nil {
 		return err
@@ -629,7 +1008,89 @@ func runRulesValidate(ctx context.Context, args []string) error {
 		countEnabledExchanges(loaded.Exchange.Exchanges),
 		loaded.ExchangeSHA256,
 	)
+	if loaded.RegexSHA256 != "" {
+		fmt.Printf("regex: version=%s total=%d enabled=%d sha256=%s\n",
+			loaded.Regex.Version,
+			len(loaded.Regex.Rules),
+			len(loaded.RegexRules),
+			loaded.RegexSHA256,
+		)
+	} else {
+		fmt.Printf("regex: not loaded (%s not found)\n", *regexPath)
+	}
+
+	return nil
+}
+
+// runRulesSyncExtensions 汇总扫描中出现但未收录的扩展 ID，查询商店元数据并生成候选钱包规则。
+func runRulesSyncExtensions(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("rules sync-extensions", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
+	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
+	caseID := fs.String("case-id", "", "only collect extensions from this case (optional)")
+	outPath := fs.String("out", "", "candidate rule output path (default rules/staging/wallet_candidates_<ts>.yaml)")
+	includeAll := fs.Bool("all", false, "stage all unknown extensions, not only wallet-like ones")
+	maxLookups := fs.Int("max-lookups", 200, "max extensions to query from web stores (0 = unlimited)")
+	chromeURL := fs.String("chrome-store-url", extsync.DefaultChromeWebStoreURL, "chrome web store detail base url")
+	edgeURL := fs.String("edge-store-url", extsync.DefaultEdgeAddonsURL, "edge add-ons product detail base url")
+	asJSON := fs.Bool("json", false, "print candidates as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite", *dbPath)
+	if err != nil {
+		return fmt.Errorf("open sqlite: %w", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.ExecContext(ctx, `PRAGMA busy_timeout = 5000`); err != nil {
+		return fmt.Errorf("set busy_timeout: %w", err)
+	}
+
+	migrator := sqliteadapter.NewMigrator(db)
+	if err := migrator.Up(ctx); err != nil {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
 
+	res, err := extsync.Run(ctx, sqliteadapter.NewStore(db), extsync.Options{
+		CaseID:           strings.TrimSpace(*caseID),
+		WalletRulePath:   *walletPath,
+		ExchangeRulePath: *exchangePath,
+		OutPath:          strings.TrimSpace(*outPath),
+		IncludeAll:       *includeAll,
+		MaxLookups:       *maxLookups,
+		Client:           extsync.NewWebStoreClient(*chromeURL, *edgeURL),
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(res)
+	}
+
+	fmt.Println("extension reputation sync completed")
+	fmt.Printf("unknown=%d looked_up=%d staged=%d\n", res.UnknownCount, res.LookupCount, res.StagedCount)
+	for _, c := range res.Candidates {
+		name := strings.Join(c.LocalNames, ",")
+		var users int64
+		if c.Chrome != nil {
+			name, users = c.Chrome.Name, c.Chrome.UserCount
+		} else if c.Edge != nil {
+			name, users = c.Edge.Name, c.Edge.UserCount
+		}
+		fmt.Printf("ext=%s browsers=%s seen=%d name=%q users=%d wallet_like=%t staged=%t\n",
+			c.ExtensionID, strings.Join(c.Browsers, ","), c.SeenCount, name, users, c.LooksLikeWallet, c.Staged)
+	}
+	if res.OutPath != "" {
+		fmt.Printf("candidates=%s\n", res.OutPath)
+	}
+	if len(res.Warnings) > 0 {
+		fmt.Printf("warnings=%s\n", strings.Join(res.Warnings, " | "))
+	}
 	return nil
 }
 
@@ -657,46 +1118,109 @@ func countEnabledExchanges(exchanges []model.ExchangeDomain) int {
 
 // printUsage 输出一级命令帮助。
 func printUsage() {
-	fmt.Println("Usage:")
-	fmt.Println("  inspector-cli migrate [--db data/inspector.db]")
-	fmt.Println("  inspector-cli rules validate [--wallet rules/wallet_signatures.template.yaml] [--exchange rules/exchange_domains.template.yaml]")
-	fmt.Println("  inspector-cli scan host [--db data/inspector.db] [--evidence-dir data/evidence] [--case-id CASE_ID] [--auth-order TICKET]")
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli [--lang en|zh-CN] <command> ...   (or CRYPTO_INSPECTOR_LANG; localizes errors, error_code stays stable)")
+	fmt.Println("  inspector-cli migrate [--db data/inspector.db] [--offload-payloads-over BYTES] [--max-payload-bytes BYTES] [--vacuum]")
+	fmt.Println("  inspector-cli rules validate [--wallet rules/wallet_signatures.template.yaml] [--exchange rules/exchange_domains.template.yaml] [--regex-rules rules/regex_rules.template.yaml]")
+	fmt.Println("  inspector-cli rules sync-extensions [--db data/inspector.db] [--case-id CASE_ID] [--out rules/staging/candidates.yaml]")
+	fmt.Println("  inspector-cli scan host [--db data/inspector.db] [--evidence-dir data/evidence] [--case-id CASE_ID] [--auth-order TICKET] [--scan-vm-images]")
+	fmt.Println("  inspector-cli scan vm --image disk.vmdk --case-id CASE_ID [--parent-device-id DEVICE_ID] [--extractor auto|guestmount|7z]")
 	fmt.Println("  inspector-cli scan mobile [--db data/inspector.db] [--evidence-dir data/evidence] [--ios-backup-dir data/evidence/ios_backups] [--case-id CASE_ID] [--auth-order TICKET]")
-	fmt.Println("  inspector-cli scan all [--db data/inspector.db] [--evidence-dir data/evidence] [--profile internal|external] [--privacy-mode off|masked]")
-	fmt.Println("  inspector-cli query host-hits --case-id CASE_ID [--hit-type wallet_installed|exchange_visited]")
+	fmt.Println("  inspector-cli scan all [--db data/inspector.db] [--evidence-dir data/evidence] [--profile internal|external] [--privacy-mode off|masked] [--snapshot-compression none|gzip]")
+	fmt.Println("  inspector-cli query host-hits --case-id CASE_ID [--hit-type wallet_installed|exchange_visited|wallet_suspected_unknown] [--rollup]")
 	fmt.Println("  inspector-cli query report --case-id CASE_ID [--report-id REPORT_ID]")
+	fmt.Println("  inspector-cli report diff --case-id CASE_ID [--report-a REPORT_ID --report-b REPORT_ID]")
+	fmt.Println("  inspector-cli report correlate --case-id CASE_ID [--db data/inspector.db] [--evidence-dir data/evidence]")
+	fmt.Println("  inspector-cli report holdings --case-id CASE_ID [--price-source rules/price_source.template.yaml] [--db data/inspector.db]")
+	fmt.Println("  inspector-cli report phishing --case-id CASE_ID [--exchange rules/exchange_domains.template.yaml] [--db data/inspector.db]")
+	fmt.Println("  inspector-cli hits add-manual --case-id CASE_ID --value VALUE --justification TEXT [--type manual_finding] [--file PATH]")
+	fmt.Println("  inspector-cli import --case-id CASE_ID --file report.xml [--format ufed_xml|axiom_xml|csv|plaso_csv|autopsy_csv] [--os android|ios|harmonyos|windows|macos] [--device-id id]")
+	fmt.Println("  inspector-cli storage usage --case-id CASE_ID [--db data/inspector.db] [--json]")
+	fmt.Println("  inspector-cli evidence relocate --from OLD_ROOT --to NEW_ROOT [--case-id CASE_ID] [--dry-run] [--force]")
+	fmt.Println("  inspector-cli storage quota (--case-id CASE_ID --bytes N | --default-bytes N [--mode warn|block]) [--db data/inspector.db]")
+	fmt.Println("  inspector-cli policy show|set --file rules/precheck_policy.template.yaml|reset [--db data/inspector.db]")
+	fmt.Println("  inspector-cli auth attach --case-id CASE_ID --file warrant.pdf [--order TICKET] [--agency name] [--db data/inspector.db] [--evidence-dir data/evidence]")
+	fmt.Println("  inspector-cli case list [--owner name] | handover --case-id CASE_ID --to name --note TEXT | history (--case-id CASE_ID | --operator name) [--db data/inspector.db]")
+	fmt.Println("  inspector-cli case push --case-id CASE_ID --cms-config rules/case_management.template.yaml [--db data/inspector.db]")
+	fmt.Println("  inspector-cli case anchor --case-id CASE_ID --anchor-config rules/evidence_anchor.template.yaml [--report-id REPORT_ID] | anchors --case-id CASE_ID [--db data/inspector.db]")
+	fmt.Println("  inspector-cli case checklist --case-id CASE_ID [--item ITEM_ID --status done] | close --case-id CASE_ID [--profile internal|external] [--db data/inspector.db]")
+	fmt.Println("  inspector-cli case device --case-id CASE_ID --device-id DEVICE_ID [--label TEXT] [--imei IMEI] [--seizure-location TEXT] [--custody-officer name] | device-photo --case-id CASE_ID --device-id DEVICE_ID --file photo.jpg [--db data/inspector.db]")
+	fmt.Println("  inspector-cli watchlist add --case-id CASE_ID --term TEXT [--type keyword|alias|address|phone] | list --case-id CASE_ID | remove --case-id CASE_ID --term-id ID [--db data/inspector.db]")
 	fmt.Println("  inspector-cli export forensic-zip --case-id CASE_ID [--db data/inspector.db] [--evidence-dir data/evidence]")
 	fmt.Println("  inspector-cli export forensic-pdf --case-id CASE_ID [--db data/inspector.db]")
+	fmt.Println("  inspector-cli export disclosure-zip --case-id CASE_ID [--db data/inspector.db] [--evidence-dir data/evidence]")
+	fmt.Println("  inspector-cli export graph-zip --case-id CASE_ID [--db data/inspector.db] [--out-dir path]")
 	fmt.Println("  inspector-cli verify forensic-zip --zip PATH_TO_ZIP")
-	fmt.Println("  inspector-cli verify artifacts --case-id CASE_ID [--db data/inspector.db] [--artifact-id ART_ID]")
-	fmt.Println("  inspector-cli serve [--listen 127.0.0.1:8787] [--db data/inspector.db]")
+	fmt.Println("  inspector-cli verify artifacts --case-id CASE_ID [--db data/inspector.db] [--artifact-id ART_ID] [--workers N] [--resume] [--marker PATH]")
+	fmt.Println("  inspector-cli verify xref --case-id CASE_ID [--db data/inspector.db]")
+	fmt.Println("  inspector-cli serve [--listen 127.0.0.1:8787] [--db data/inspector.db] [--rate-ip 10] [--max-concurrent-exports 2] [--no-rate-limit] [--csrf-strict] [--siem-endpoint udp://host:514] [--chain-providers rules/chain_providers.template.yaml] [--cms-config rules/case_management.template.yaml] [--anchor-config rules/evidence_anchor.template.yaml] [--snapshot-compression none|gzip] [--privacy-mode off|masked|partial [--unmask-grants-env NAME]] [--monitor [--monitor-interval 2s]] [--replica /mnt/ssd/inspector.db [--replica-interval 30s]] [--training] [--lang en|zh-CN]")
+	fmt.Println("  inspector-cli tools list|verify [--tools-dir data/tools] | install --bundle platform-tools [--catalog rules/tool_bundles.template.yaml] [--from archive.zip]")
+	fmt.Println("  inspector-cli replica sync|status|failover --replica /mnt/ssd/inspector.db [--db data/inspector.db] [--force]")
+	fmt.Println("  inspector-cli audit forward --endpoint udp://host:514 [--format cef|syslog] [--follow] [--case-id CASE_ID]")
+	fmt.Println("  inspector-cli audit replay --endpoint udp://host:514 [--since 2024-01-01] [--until 2024-12-31] [--case-id CASE_ID]")
+	fmt.Println("  inspector-cli dev seed [--profile demo|load] [--seed 1] [--case-id CASE_ID] [--db data/inspector.db] [--evidence-dir data/evidence]")
+	fmt.Println("  inspector-cli training scan [--db data/training/inspector.db] [--evidence-dir data/training/evidence] [--case-id CASE_ID] [--operator name]")
+	fmt.Println("  inspector-cli bench [--visits 1000000] [--hits 100000] [--settings default,wal,wal_pool4] [--dir path [--keep]] [--json]")
 }
 
 // printRulesUsage 输出 rules 子命令帮助。
 func printRulesUsage() {
-	fmt.Println("Usage:")
-	fmt.Println("  inspector-cli rules validate [--wallet path] [--exchange path]")
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli rules validate [--wallet path] [--exchange path] [--regex-rules path]")
+	fmt.Println("  inspector-cli rules sync-extensions [--db path] [--wallet path] [--exchange path] [--case-id id] [--out path] [--all] [--max-lookups N] [--json=true]")
 }
 
 // printScanUsage 输出 scan 子命令帮助。
 func printScanUsage() {
-	fmt.Println("Usage:")
-	fmt.Println("  inspector-cli scan host [--db path] [--evidence-dir path] [--wallet path] [--exchange path] [--case-id id] [--operator name] [--note text] [--auth-order TICKET] [--auth-basis text] [--require-auth-order] [--privacy-mode off|masked]")
-	fmt.Println("  inspector-cli scan mobile [--db path] [--evidence-dir path] [--ios-backup-dir path] [--wallet path] [--exchange path] [--case-id id] [--operator name] [--note text] [--auth-order TICKET] [--auth-basis text] [--require-auth-order] [--require-authorized] [--ios-full-backup] [--privacy-mode off|masked]")
-	fmt.Println("  inspector-cli scan all [--db path] [--evidence-dir path] [--ios-backup-dir path] [--wallet path] [--exchange path] [--case-id id] [--operator name] [--note text] [--auth-order TICKET] [--auth-basis text] [--profile internal|external] [--continue-on-error] [--ios-full-backup] [--privacy-mode off|masked]")
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli scan host [--db path] [--evidence-dir path] [--wallet path] [--exchange path] [--regex-rules path] [--case-id id] [--operator name] [--note text] [--auth-order TICKET] [--auth-basis text] [--require-auth-order] [--privacy-mode off|masked] [--snapshot-compression none|gzip] [--eth-rpc url] [--bnb-rpc url] [--scan-vm-images] [--vm-extractor auto|guestmount|7z] [--scan-messengers] [--max-bytes N] [--skip-history-db] [--elevate] [--dry-run]")
+	fmt.Println("  inspector-cli scan offline --input DIR [--os windows|macos] [--device-name name] [--db path] [--evidence-dir path] [--wallet path] [--exchange path] [--regex-rules path] [--case-id id] [--operator name] [--note text] [--auth-order TICKET] [--auth-basis text] [--require-auth-order] [--privacy-mode off|masked] [--snapshot-compression none|gzip] [--eth-rpc url] [--bnb-rpc url]")
+	fmt.Println("  inspector-cli scan vm --image PATH --case-id id [--parent-device-id id] [--guest-os windows|macos] [--extractor auto|guestmount|7z] [--db path] [--evidence-dir path] [--wallet path] [--exchange path] [--regex-rules path] [--operator name] [--auth-order TICKET] [--auth-basis text] [--require-auth-order] [--privacy-mode off|masked] [--snapshot-compression none|gzip]")
+	fmt.Println("  inspector-cli scan mobile [--db path] [--evidence-dir path] [--ios-backup-dir path] [--wallet path] [--exchange path] [--regex-rules path] [--case-id id] [--operator name] [--note text] [--auth-order TICKET] [--auth-basis text] [--require-auth-order] [--require-authorized] [--ios-full-backup] [--privacy-mode off|masked] [--snapshot-compression none|gzip] [--tools-dir path] [--max-bytes N]")
+	fmt.Println("  inspector-cli scan all [--db path] [--evidence-dir path] [--ios-backup-dir path] [--wallet path] [--exchange path] [--regex-rules path] [--case-id id] [--operator name] [--note text] [--auth-order TICKET] [--auth-basis text] [--profile internal|external] [--continue-on-error] [--ios-full-backup] [--privacy-mode off|masked] [--snapshot-compression none|gzip] [--eth-rpc url] [--bnb-rpc url] [--tools-dir path] [--max-bytes N] [--skip-history-db] [--elevate]")
 }
 
 // printQueryUsage 输出 query 子命令帮助。
 func printQueryUsage() {
-	fmt.Println("Usage:")
-	fmt.Println("  inspector-cli query host-hits --case-id id [--db path] [--hit-type type] [--json=true]")
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli query host-hits --case-id id [--db path] [--hit-type type] [--rollup] [--json=true]")
 	fmt.Println("  inspector-cli query report --case-id id [--report-id id] [--db path] [--content=true] [--json=true]")
 }
 
+// printDuplicateCases 输出同一设备已登记的其他未结案件（可用 --case-id 改用已有案件继续采集）。
+func printDuplicateCases(refs []model.DeviceCaseRef) {
+	for _, r := range refs {
+		fmt.Printf("duplicate_case=%s case_no=%s device=%s (%s) last_seen=%s\n",
+			r.CaseID, r.CaseNo, r.DeviceName, r.DeviceID, time.Unix(r.LastSeenAt, 0).Format(time.RFC3339))
+	}
+}
+
+// newScanBudget 按 --max-bytes 与取消勾选的重型来源创建采集预算；都未设置时返回 nil（不限且不记录）。
+func newScanBudget(maxBytes int64, deselected bool) *budget.Budget {
+	if maxBytes <= 0 && !deselected {
+		return nil
+	}
+	return budget.New(maxBytes)
+}
+
+func printBudget(sum *budget.Summary) {
+	if sum == nil {
+		return
+	}
+	fmt.Printf("budget used=%d limit=%d skipped=%d\n", sum.UsedBytes, sum.LimitBytes, len(sum.Skipped))
+	for _, it := range sum.Skipped {
+		fmt.Printf("budget_skipped kind=%s ref=%s size=%d reason=%s path=%s\n", it.Kind, it.Ref, it.SizeBytes, it.Reason, it.Path)
+	}
+}
+
+// printExportUsage 按导出格式注册表输出 export 子命令帮助。
 func printExportUsage() {
-	fmt.Println("Usage:")
-	fmt.Println("  inspector-cli export forensic-zip --case-id CASE_ID [--db path] [--evidence-dir path] [--wallet path] [--exchange path] [--out-dir path]")
-	fmt.Println("  inspector-cli export forensic-pdf --case-id CASE_ID [--db path] [--operator name] [--note text]")
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli export <kind> --case-id CASE_ID [--db path] [--evidence-dir path] [--wallet path] [--exchange path] [--operator name] [--note text] [--out-dir path] [--ui-screenshots] [--browser path] [--include-comments] [--cms-config path] [--anchor-config path]")
+	fmt.Println("Kinds:")
+	for _, info := range exporter.List() {
+		fmt.Printf("  %-16s %s\n", info.Kind, info.Description)
+	}
 }
 
 func printJSON(v any) error {
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/precheckpolicy"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runPolicy 是 policy 子命令路由（前置检查策略）：
+// - policy show：查看生效策略
+// - policy set --file：校验并导入策略 YAML
+// - policy reset：清除策略，恢复内置行为
+func runPolicy(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printPolicyUsage()
+		return nil
+	}
+
+	cfg := app.DefaultConfig()
+	fs := flag.NewFlagSet("policy "+args[0], flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	file := fs.String("file", "", "precheck policy yaml (policy set)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "show", "set", "reset":
+	default:
+		printPolicyUsage()
+		return fmt.Errorf("unknown policy command: %s", args[0])
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	store := sqliteadapter.NewStore(db)
+
+	switch args[0] {
+	case "set":
+		if strings.TrimSpace(*file) == "" {
+			return fmt.Errorf("--file is required")
+		}
+		_, raw, err := precheckpolicy.ParseFile(*file)
+		if err != nil {
+			return err
+		}
+		if _, err := precheckpolicy.Save(ctx, store, raw); err != nil {
+			return err
+		}
+	case "reset":
+		if err := precheckpolicy.Reset(ctx, store); err != nil {
+			return err
+		}
+	}
+
+	policy, err := precheckpolicy.Load(ctx, store)
+	if err != nil {
+		return err
+	}
+	if !policy.Configured() {
+		fmt.Println("# no precheck policy configured; built-in checks apply")
+		return nil
+	}
+	out, err := yaml.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(out))
+	return nil
+}
+
+func printPolicyUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli policy show [--db path]")
+	fmt.Println("  inspector-cli policy set --file rules/precheck_policy.template.yaml [--db path]")
+	fmt.Println("  inspector-cli policy reset [--db path]")
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/dbreplica"
+)
+
+// runReplica 是 replica 子命令路由：
+// - replica sync：把主库同步到热备副本（--follow 按间隔持续同步）
+// - replica status：查看副本同步时间与哈希是否一致
+// - replica failover：主盘故障后把副本提升为主库（原文件改名保留）
+func runReplica(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printReplicaUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "sync":
+		return runReplicaSync(ctx, args[1:])
+	case "status":
+		return runReplicaStatus(ctx, args[1:])
+	case "failover":
+		return runReplicaFailover(ctx, args[1:])
+	default:
+		printReplicaUsage()
+		return fmt.Errorf("unknown replica command: %s", args[0])
+	}
+}
+
+func printReplicaUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli replica sync --replica PATH [--db path] [--follow [--interval 30s]]")
+	fmt.Println("  inspector-cli replica status --replica PATH [--json]")
+	fmt.Println("  inspector-cli replica failover --replica PATH [--db path] [--force] [--json]")
+}
+
+func runReplicaSync(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("replica sync", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "primary sqlite database path")
+	replica := fs.String("replica", "", "replica path on another disk (required)")
+	follow := fs.Bool("follow", false, "keep syncing whenever the primary changes")
+	interval := fs.Duration("interval", dbreplica.DefaultInterval, "sync interval with --follow")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*replica) == "" {
+		return fmt.Errorf("--replica is required")
+	}
+	if _, err := os.Stat(*dbPath); err != nil {
+		return fmt.Errorf("primary db not found: %w", err)
+	}
+
+	db, err := sql.Open("sqlite", *dbPath)
+	if err != nil {
+		return fmt.Errorf("open sqlite: %w", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.ExecContext(ctx, `PRAGMA busy_timeout = 5000`); err != nil {
+		return fmt.Errorf("set busy_timeout: %w", err)
+	}
+
+	rep, err := dbreplica.New(db, *dbPath, dbreplica.Options{
+		Path:     *replica,
+		Interval: *interval,
+		Logf: func(format string, args ...any) {
+			fmt.Fprintf(os.Stderr, format+"\n", args...)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if *follow {
+		sigCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer cancel()
+		rep.Run(sigCtx)
+		return nil
+	}
+
+	meta, err := rep.SyncNow(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("replica=%s size=%d sha256=%s schema_version=%s\n", *replica, meta.SizeBytes, meta.SHA256, meta.SchemaVersion)
+	return nil
+}
+
+func runReplicaStatus(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("replica status", flag.ContinueOnError)
+	replica := fs.String("replica", "", "replica path (required)")
+	asJSON := fs.Bool("json", false, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*replica) == "" {
+		return fmt.Errorf("--replica is required")
+	}
+
+	st, err := dbreplica.ReadStatus(strings.TrimSpace(*replica))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(st)
+	}
+	if !st.Exists {
+		fmt.Printf("replica=%s exists=false\n", st.Path)
+		return nil
+	}
+	if st.Meta == nil {
+		fmt.Printf("replica=%s exists=true meta=missing\n", st.Path)
+		return nil
+	}
+	fmt.Printf("replica=%s source=%s synced_at=%s age=%s schema_version=%s hash_match=%t\n",
+		st.Path, st.Meta.Source, time.Unix(st.Meta.SyncedAt, 0).Format(time.RFC3339),
+		time.Duration(st.AgeSeconds)*time.Second, st.Meta.SchemaVersion, st.HashMatch)
+	return nil
+}
+
+func runReplicaFailover(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("replica failover", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "primary db path to restore the replica into")
+	replica := fs.String("replica", "", "replica path (required)")
+	force := fs.Bool("force", false, "replace a primary that still passes integrity check / promote a replica with a hash mismatch")
+	asJSON := fs.Bool("json", false, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*replica) == "" {
+		return fmt.Errorf("--replica is required")
+	}
+
+	res, err := dbreplica.Failover(ctx, *replica, *dbPath, *force)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(res)
+	}
+	fmt.Printf("promoted replica=%s -> db=%s schema_version=%s synced_at=%s\n",
+		res.Replica, res.Target, res.SchemaVersion, time.Unix(res.SyncedAt, 0).Format(time.RFC3339))
+	for _, p := range res.MovedAside {
+		fmt.Printf("moved_aside=%s\n", p)
+	}
+	fmt.Println("writes after synced_at are not in the replica; rescan affected devices if needed")
+	return nil
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"crypto-inspector/internal/adapters/rules"
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/correlation"
+	"crypto-inspector/internal/services/holdings"
+	"crypto-inspector/internal/services/phishcheck"
+	"crypto-inspector/internal/services/reportdiff"
+)
+
+// runReport 是 report 子命令路由：
+// - report diff：对比两份 internal_json 报告（初查 vs 复查）
+// - report correlate：多设备关联分析（落库为 analysis 证据，PDF 报告中独立成节）
+// - report holdings：已识别持有汇总（token_balance 命中按价格来源折算合计，PDF/ZIP 报告同样输出）
+// - report phishing：形近/拼写仿冒交易所域名的 TLS 证书比对（证书不属于交易所时输出 phishing_suspected 命中）
+func runReport(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printReportUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "diff":
+		return runReportDiff(ctx, args[1:])
+	case "correlate":
+		return runReportCorrelate(ctx, args[1:])
+	case "holdings":
+		return runReportHoldings(ctx, args[1:])
+	case "phishing":
+		return runReportPhishing(ctx, args[1:])
+	default:
+		printReportUsage()
+		return fmt.Errorf("unknown report command: %s", args[0])
+	}
+}
+
+func printReportUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli report diff --case-id CASE_ID [--report-a REPORT_ID --report-b REPORT_ID] [--db path] [--out diff.json] [--json=true]")
+	fmt.Println("  inspector-cli report correlate --case-id CASE_ID [--db path] [--evidence-dir path] [--operator name] [--json=true]")
+	fmt.Println("  inspector-cli report holdings --case-id CASE_ID [--price-source rules/price_source.template.yaml] [--db path] [--evidence-dir path] [--operator name] [--json=true]")
+	fmt.Println("  inspector-cli report phishing --case-id CASE_ID [--wallet path] [--exchange path] [--db path] [--operator name] [--json=true]")
+}
+
+// runReportDiff 输出两份报告之间的结构化差异；未指定报告时对比最近两次扫描。
+func runReportDiff(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("report diff", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	reportA := fs.String("report-a", "", "baseline internal_json report id (default: second latest)")
+	reportB := fs.String("report-b", "", "follow-up internal_json report id (default: latest)")
+	outPath := fs.String("out", "", "write diff json to this file (optional)")
+	asJSON := fs.Bool("json", true, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := reportdiff.Run(ctx, sqliteadapter.NewStore(db), *caseID, *reportA, *reportB)
+	if err != nil {
+		return err
+	}
+	if p := strings.TrimSpace(*outPath); p != "" {
+		raw, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, raw, 0o644); err != nil {
+			return fmt.Errorf("write diff: %w", err)
+		}
+	}
+	if *asJSON {
+		return printJSON(res)
+	}
+
+	fmt.Printf("case_id=%s report_a=%s report_b=%s\n", res.CaseID, res.A.ReportID, res.B.ReportID)
+	fmt.Printf("new_hits=%d removed_hits=%d changed_balances=%d new_artifacts=%d removed_artifacts=%d\n",
+		res.Summary.NewHits, res.Summary.RemovedHits, res.Summary.ChangedBalances, res.Summary.NewArtifacts, res.Summary.RemovedArtifacts)
+	for _, h := range res.NewHits {
+		fmt.Printf("+ hit type=%s rule=%s matched=%s\n", h.HitType, h.RuleID, h.MatchedValue)
+	}
+	for _, h := range res.RemovedHits {
+		fmt.Printf("- hit type=%s rule=%s matched=%s\n", h.HitType, h.RuleID, h.MatchedValue)
+	}
+	for _, c := range res.ChangedBalances {
+		fmt.Printf("~ balance matched=%s before=%s after=%s\n", c.MatchedValue, c.Before, c.After)
+	}
+	for _, a := range res.NewArtifacts {
+		fmt.Printf("+ artifact type=%s source=%s sha256=%s\n", a.ArtifactType, a.SourceRef, a.SHA256)
+	}
+	for _, a := range res.RemovedArtifacts {
+		fmt.Printf("- artifact type=%s source=%s sha256=%s\n", a.ArtifactType, a.SourceRef, a.SHA256)
+	}
+	if len(res.Warnings) > 0 {
+		fmt.Printf("warnings=%s\n", strings.Join(res.Warnings, " | "))
+	}
+	if p := strings.TrimSpace(*outPath); p != "" {
+		fmt.Printf("diff=%s\n", p)
+	}
+	return nil
+}
+
+// runReportCorrelate 按案件当前命中计算多设备关联，并落库为 analysis 证据。
+func runReportCorrelate(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("report correlate", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory")
+	caseID := fs.String("case-id", "", "case id (required)")
+	operator := fs.String("operator", "system", "operator name")
+	asJSON := fs.Bool("json", true, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := correlation.RunForCase(ctx, sqliteadapter.NewStore(db), *evidenceRoot, *caseID, *operator)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(res)
+	}
+
+	fmt.Printf("case_id=%s artifact_id=%s devices=%d\n", res.CaseID, res.ArtifactID, len(res.Devices))
+	fmt.Printf("shared_exchanges=%d shared_wallets=%d shared_addresses=%d window_overlaps=%d\n",
+		len(res.SharedExchanges), len(res.SharedWallets), len(res.SharedAddresses), len(res.WindowOverlaps))
+	return nil
+}
+
+// runReportHoldings 按生效价格来源汇总案件持有；指定 --price-source 时先校验并保存为生效配置。
+func runReportHoldings(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("report holdings", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory (price_snapshot artifact)")
+	caseID := fs.String("case-id", "", "case id (required)")
+	priceSource := fs.String("price-source", "", "price source yaml to import before aggregating (optional)")
+	operator := fs.String("operator", "system", "operator name")
+	asJSON := fs.Bool("json", true, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	store := sqliteadapter.NewStore(db)
+
+	if p := strings.TrimSpace(*priceSource); p != "" {
+		_, raw, err := holdings.ParseFile(p)
+		if err != nil {
+			return err
+		}
+		if _, err := holdings.Save(ctx, store, raw); err != nil {
+			return err
+		}
+	}
+	sum, err := holdings.ForCase(ctx, store, *caseID, holdings.Options{
+		Operator:     *operator,
+		Source:       "inspector-cli.report.holdings",
+		EvidenceRoot: *evidenceRoot,
+	})
+	if err != nil {
+		return err
+	}
+	if sum == nil {
+		return fmt.Errorf("no price source configured (use --price-source)")
+	}
+	if *asJSON {
+		return printJSON(sum)
+	}
+
+	for _, h := range sum.Holdings {
+		if h.Value == "" {
+			fmt.Printf("%s amount=%s value=(unpriced) addresses=%d\n", h.Symbol, h.Amount, h.AddressCount)
+			continue
+		}
+		fmt.Printf("%s amount=%s price=%s price_at=%d value=%s addresses=%d\n", h.Symbol, h.Amount, h.Price, h.PriceAt, h.Value, h.AddressCount)
+	}
+	fmt.Printf("total=%s %s\n", sum.Total, sum.ReferenceCurrency)
+	if ref := sum.PriceSnapshot; ref != nil {
+		fmt.Printf("price_snapshot=%s sha256=%s endpoint=%s\n", ref.ArtifactID, ref.SHA256, ref.Endpoint)
+	}
+	if len(sum.Warnings) > 0 {
+		fmt.Printf("warnings=%s\n", strings.Join(sum.Warnings, " | "))
+	}
+	return nil
+}
+
+// runReportPhishing 对案件中形近/拼写仿冒交易所域名读取 TLS 证书并比对交易所主体（需要访问网络）。
+func runReportPhishing(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("report phishing", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
+	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file (official domains and cert_orgs)")
+	caseID := fs.String("case-id", "", "case id (required)")
+	operator := fs.String("operator", "system", "operator name")
+	asJSON := fs.Bool("json", true, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	loaded, err := rules.NewLoader(*walletPath, *exchangePath).Load(ctx)
+	if err != nil {
+		return err
+	}
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := phishcheck.Run(ctx, sqliteadapter.NewStore(db), *caseID, phishcheck.Options{
+		Exchange: loaded.Exchange,
+		Operator: *operator,
+		Source:   "inspector-cli.report.phishing",
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(res)
+	}
+
+	for _, c := range res.Checks {
+		if c.Skipped != "" {
+			fmt.Printf("%s lookalike_of=%s skipped=%s\n", c.Domain, c.LookalikeOf, c.Skipped)
+			continue
+		}
+		fmt.Printf("%s lookalike_of=%s cert=%s hit_id=%s\n", c.Domain, c.LookalikeOf, c.CertStatus, c.PhishingHitID)
+	}
+	fmt.Printf("case_id=%s checked=%d flagged=%d\n", res.CaseID, res.Checked, res.Flagged)
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/casestorage"
+)
+
+// runStorage 是 storage 子命令路由：
+// - storage usage：查看案件存储占用与配额状态
+// - storage quota：设置案件级配额或全局默认配额/超额策略
+func runStorage(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printStorageUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "usage":
+		return runStorageUsage(ctx, args[1:])
+	case "quota":
+		return runStorageQuota(ctx, args[1:])
+	default:
+		printStorageUsage()
+		return fmt.Errorf("unknown storage command: %s", args[0])
+	}
+}
+
+func printStorageUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli storage usage --case-id CASE_ID [--db path] [--json]")
+	fmt.Println("  inspector-cli storage quota --case-id CASE_ID (--bytes N | --clear) [--db path] [--operator name]")
+	fmt.Println("  inspector-cli storage quota --default-bytes N [--mode warn|block] [--db path]")
+}
+
+func runStorageUsage(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("storage usage", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	asJSON := fs.Bool("json", false, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	usage, err := casestorage.Usage(ctx, sqliteadapter.NewStore(db), strings.TrimSpace(*caseID))
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return printJSON(usage)
+	}
+	fmt.Printf("case_id=%s total_bytes=%d evidence_bytes=%d report_bytes=%d payload_bytes=%d\n",
+		usage.CaseID, usage.TotalBytes, usage.EvidenceBytes, usage.ReportBytes, usage.PayloadBytes)
+	fmt.Printf("artifacts=%d reports=%d missing_reports=%d\n", usage.ArtifactCount, usage.ReportCount, usage.MissingReports)
+	fmt.Printf("quota: status=%s limit_bytes=%d mode=%s source=%s\n", usage.Quota.Status, usage.Quota.LimitBytes, usage.Quota.Mode, usage.Quota.Source)
+	return nil
+}
+
+func runStorageQuota(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("storage quota", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "set the quota of this case")
+	limit := fs.Int64("bytes", -1, "case quota in bytes (0 = unlimited for this case)")
+	clear := fs.Bool("clear", false, "clear the case quota (fall back to the default)")
+	defaultLimit := fs.Int64("default-bytes", -1, "default quota in bytes for cases without their own quota (0 = unlimited)")
+	mode := fs.String("mode", "", "what to do when a case exceeds its quota: warn|block")
+	operator := fs.String("operator", "system", "operator name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	store := sqliteadapter.NewStore(db)
+
+	id := strings.TrimSpace(*caseID)
+	switch {
+	case id != "":
+		if *clear == (*limit >= 0) {
+			return fmt.Errorf("exactly one of --bytes or --clear is required with --case-id")
+		}
+		var v *int64
+		if !*clear {
+			v = limit
+		}
+		found, err := store.SetCaseQuota(ctx, id, v)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("case not found: %s", id)
+		}
+		_ = store.AppendAudit(ctx, id, "", "case_storage", "set_quota", "success", *operator, "cli.storage.quota", map[string]any{"quota_bytes": v})
+	case *defaultLimit >= 0 || strings.TrimSpace(*mode) != "":
+		cur, err := store.GetCaseQuota(ctx, "")
+		if err != nil {
+			return err
+		}
+		if *defaultLimit >= 0 {
+			cur.LimitBytes = *defaultLimit
+		}
+		if m := strings.TrimSpace(*mode); m != "" {
+			cur.Mode = m
+		}
+		if err := store.SetDefaultCaseQuota(ctx, cur.LimitBytes, cur.Mode); err != nil {
+			return err
+		}
+		fmt.Printf("default quota: limit_bytes=%d mode=%s\n", cur.LimitBytes, cur.Mode)
+		return nil
+	default:
+		printStorageUsage()
+		return fmt.Errorf("--case-id or --default-bytes/--mode is required")
+	}
+
+	usage, err := casestorage.Usage(ctx, store, id)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("case_id=%s quota: status=%s limit_bytes=%d source=%s total_bytes=%d\n", id, usage.Quota.Status, usage.Quota.LimitBytes, usage.Quota.Source, usage.TotalBytes)
+	return nil
+}
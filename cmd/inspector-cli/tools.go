@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"crypto-inspector/internal/platform/toolbox"
+)
+
+// runTools 是 tools 子命令路由（adb / libimobiledevice 受管工具）：
+// - tools list：各工具实际使用的路径、来源（managed|system）与版本
+// - tools install：从清单安装经核验的工具包（在线下载或 --from 本地归档）
+// - tools verify：按 manifest 重新校验受管工具哈希
+func runTools(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printToolsUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "list":
+		return runToolsList(ctx, args[1:])
+	case "install":
+		return runToolsInstall(ctx, args[1:])
+	case "verify":
+		return runToolsVerify(ctx, args[1:])
+	default:
+		printToolsUsage()
+		return fmt.Errorf("unknown tools command: %s", args[0])
+	}
+}
+
+func printToolsUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli tools list [--tools-dir path] [--json]")
+	fmt.Println("  inspector-cli tools install --bundle NAME [--catalog rules/tool_bundles.template.yaml] [--from archive.zip|tar.gz] [--tools-dir path]")
+	fmt.Println("  inspector-cli tools verify [--tools-dir path]")
+}
+
+func runToolsList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tools list", flag.ContinueOnError)
+	toolsDir := fs.String("tools-dir", toolbox.DefaultDir, "managed tools directory")
+	asJSON := fs.Bool("json", false, "print as json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	toolbox.SetDir(*toolsDir)
+
+	type row struct {
+		*toolbox.Resolved
+		Version string `json:"version,omitempty"`
+		Error   string `json:"error,omitempty"`
+	}
+	rows := make([]row, 0, len(toolbox.Known))
+	for _, name := range toolbox.Known {
+		r, err := toolbox.Resolve(name)
+		if err != nil {
+			rows = append(rows, row{Resolved: &toolbox.Resolved{Name: name}, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, row{Resolved: r, Version: toolbox.Version(ctx, r)})
+	}
+	if *asJSON {
+		return printJSON(rows)
+	}
+	for _, r := range rows {
+		if r.Error != "" {
+			fmt.Printf("%-18s unavailable: %s\n", r.Name, r.Error)
+			continue
+		}
+		fmt.Printf("%-18s source=%s version=%q path=%s\n", r.Name, r.Source, r.Version, r.Path)
+	}
+	return nil
+}
+
+func runToolsInstall(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tools install", flag.ContinueOnError)
+	toolsDir := fs.String("tools-dir", toolbox.DefaultDir, "managed tools directory")
+	catalog := fs.String("catalog", "rules/tool_bundles.template.yaml", "vetted tool bundle catalog (pinned sha256)")
+	bundle := fs.String("bundle", "", "bundle name from the catalog (required)")
+	from := fs.String("from", "", "install from a local archive instead of downloading (offline machines)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*bundle) == "" {
+		return fmt.Errorf("--bundle is required")
+	}
+
+	bundles, err := toolbox.LoadCatalog(*catalog)
+	if err != nil {
+		return err
+	}
+	b, err := toolbox.FindBundle(bundles, strings.TrimSpace(*bundle))
+	if err != nil {
+		return err
+	}
+	entries, err := toolbox.Install(ctx, *b, toolbox.InstallOptions{Dir: *toolsDir, Archive: strings.TrimSpace(*from)})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("installed bundle=%s version=%s dir=%s\n", b.Name, b.Version, *toolsDir)
+	for _, e := range entries {
+		fmt.Printf("tool=%s path=%s sha256=%s\n", e.Name, e.Path, e.SHA256)
+	}
+	return nil
+}
+
+func runToolsVerify(_ context.Context, args []string) error {
+	fs := flag.NewFlagSet("tools verify", flag.ContinueOnError)
+	toolsDir := fs.String("tools-dir", toolbox.DefaultDir, "managed tools directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	res, err := toolbox.Verify(*toolsDir)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(res))
+	for name := range res {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	failed := 0
+	for _, name := range names {
+		if err := res[name]; err != nil {
+			failed++
+			fmt.Printf("%-18s FAILED %v\n", name, err)
+			continue
+		}
+		fmt.Printf("%-18s ok\n", name)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d managed tool(s) failed verification; reinstall with tools install", failed)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/training"
+)
+
+// runTraining 是 training 子命令路由（操作员培训，不用于真实取证）：
+// - training scan：在独立的演练库中对内置合成数据源执行完整扫描，报告与导出均带“演练/TRAINING”水印
+//
+// 演练库生成的案件可以继续用 export / report 等命令（传同一个 --db）练习导出，输出同样带水印。
+func runTraining(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printTrainingUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "scan":
+		return runTrainingScan(ctx, args[1:])
+	default:
+		printTrainingUsage()
+		return fmt.Errorf("unknown training command: %s", args[0])
+	}
+}
+
+func printTrainingUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli training scan [--db " + training.DefaultDBPath + "] [--evidence-dir " + training.DefaultEvidenceRoot + "] [--wallet path] [--exchange path] [--regex-rules path] [--case-id id] [--operator name] [--note text]")
+}
+
+func runTrainingScan(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("training scan", flag.ContinueOnError)
+	dbPath := fs.String("db", training.DefaultDBPath, "training sqlite database path (kept apart from the case database)")
+	evidenceRoot := fs.String("evidence-dir", training.DefaultEvidenceRoot, "training evidence output directory")
+	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
+	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
+	regexPath := fs.String("regex-rules", cfg.RegexRulePath, "custom regex rule file (optional; skipped when missing)")
+	caseID := fs.String("case-id", "", "existing training case id (optional)")
+	operator := fs.String("operator", "trainee", "operator id or name")
+	note := fs.String("note", "", "case note")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	result, err := training.Scan(ctx, training.Options{
+		DBPath:           *dbPath,
+		EvidenceRoot:     *evidenceRoot,
+		WalletRulePath:   *walletPath,
+		ExchangeRulePath: *exchangePath,
+		RegexRulePath:    *regexPath,
+		CaseID:           *caseID,
+		Operator:         *operator,
+		Note:             *note,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("training scan completed (%s)\n", model.TrainingWatermark)
+	fmt.Printf("case_id=%s trace_id=%s db=%s\n", result.CaseID, result.TraceID, *dbPath)
+	fmt.Printf("device=%s (%s) sources=%s\n", result.DeviceName, result.DeviceOS, training.SourcesDir(*dbPath))
+	fmt.Printf("artifacts=%d hits=%d wallet_hits=%d exchange_hits=%d\n",
+		result.ArtifactCount, result.HitCount, result.WalletHits, result.ExchangeHits,
+	)
+	if result.ReportPath != "" {
+		fmt.Printf("report=%s\n", result.ReportPath)
+	}
+	if len(result.Warnings) > 0 {
+		fmt.Printf("warnings=%s\n", strings.Join(result.Warnings, " | "))
+	}
+	return nil
+}
@@ -12,20 +12,27 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
 	"crypto-inspector/internal/app"
 	"crypto-inspector/internal/domain/model"
-	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/i18n"
+	"crypto-inspector/internal/platform/zipaes"
+	"crypto-inspector/internal/services/artifactverify"
 	"crypto-inspector/internal/services/auditverify"
+	"crypto-inspector/internal/services/xrefverify"
 
 	_ "modernc.org/sqlite"
 )
 
 // runVerify 是 verify 子命令路由：
 // - verify forensic-zip：校验司法导出包 ZIP 内的 hashes.sha256
-// - verify artifacts：复核 artifacts.snapshot_path 文件哈希（与入库 sha256 对比）
+// - verify artifacts：复核 artifacts.snapshot_path 文件哈希（与入库 sha256 对比），整案复核时附带命中/证据交叉引用校验
+// - verify xref：只做命中/证据交叉引用校验（引用的证据记录与快照文件是否存在、孤立关联）
 func runVerify(ctx context.Context, args []string) error {
 	if len(args) == 0 {
 		printVerifyUsage()
@@ -39,6 +46,8 @@ func runVerify(ctx context.Context, args []string) error {
 		return runVerifyArtifacts(ctx, args[1:])
 	case "audits":
 		return runVerifyAudits(ctx, args[1:])
+	case "xref":
+		return runVerifyXref(ctx, args[1:])
 	default:
 		printVerifyUsage()
 		return fmt.Errorf("unknown verify command: %s", args[0])
@@ -46,10 +55,11 @@ func runVerify(ctx context.Context, args []string) error {
 }
 
 func printVerifyUsage() {
-	fmt.Println("Usage:")
-	fmt.Println("  inspector-cli verify forensic-zip --zip PATH_TO_ZIP")
-	fmt.Println("  inspector-cli verify artifacts --case-id CASE_ID [--db data/inspector.db] [--artifact-id ART_ID]")
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli verify forensic-zip --zip PATH_TO_ZIP [--password-env CRYPTO_INSPECTOR_EXPORT_PASSWORD]")
+	fmt.Println("  inspector-cli verify artifacts --case-id CASE_ID [--db data/inspector.db] [--artifact-id ART_ID] [--workers N] [--resume] [--marker PATH]")
 	fmt.Println("  inspector-cli verify audits --case-id CASE_ID [--db data/inspector.db] [--limit 5000]")
+	fmt.Println("  inspector-cli verify xref --case-id CASE_ID [--db data/inspector.db]")
 }
 
 type zipVerifyItem struct {
@@ -65,6 +75,7 @@ func runVerifyForensicZip(ctx context.Context, args []string) error {
 
 	fs := flag.NewFlagSet("verify forensic-zip", flag.ContinueOnError)
 	zipPath := fs.String("zip", "", "path to forensic zip (required)")
+	passwordEnv := fs.String("password-env", "CRYPTO_INSPECTOR_EXPORT_PASSWORD", "env var holding the password of an encrypted export")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -72,13 +83,16 @@ func runVerifyForensicZip(ctx context.Context, args []string) error {
 		return fmt.Errorf("--zip is required")
 	}
 
-	total, okCount, failedCount, items, auditRes, err := verifyForensicZip(*zipPath)
+	total, okCount, failedCount, items, auditRes, xrefRes, encrypted, err := verifyForensicZip(*zipPath, os.Getenv(strings.TrimSpace(*passwordEnv)))
 	if err != nil {
 		return err
 	}
 
 	fmt.Println("forensic zip verify completed")
 	fmt.Printf("zip=%s\n", *zipPath)
+	if encrypted {
+		fmt.Println("encryption=zip_aes256")
+	}
 	fmt.Printf("files_total=%d ok=%d failed=%d\n", total, okCount, failedCount)
 
 	if failedCount > 0 {
@@ -106,13 +120,20 @@ func runVerifyForensicZip(ctx context.Context, args []string) error {
 			return fmt.Errorf("forensic zip verify failed: audit chain mismatch")
 		}
 	}
+	if xrefRes != nil {
+		printXrefResult(ctx, xrefRes)
+		if !xrefRes.OK {
+			return fmt.Errorf("forensic zip verify failed: %d hit/evidence cross-reference issues", xrefRes.Failed)
+		}
+	}
 	return nil
 }
 
-func verifyForensicZip(path string) (total int, okCount int, failedCount int, items []zipVerifyItem, auditRes *auditverify.Result, err error) {
+// verifyForensicZip 校验导出包；加密导出包（WinZip AES）需提供 password，encrypted 表示包内条目已加密。
+func verifyForensicZip(path, password string) (total int, okCount int, failedCount int, items []zipVerifyItem, auditRes *auditverify.Result, xrefRes *xrefverify.Result, encrypted bool, err error) {
 	r, err := zip.OpenReader(path)
 	if err != nil {
-		return 0, 0, 0, nil, nil, fmt.Errorf("open zip: %w", err)
+		return 0, 0, 0, nil, nil, nil, false, fmt.Errorf("open zip: %w", err)
 	}
 	defer r.Close()
 
@@ -124,11 +145,15 @@ func verifyForensicZip(path string) (total int, okCount int, failedCount int, it
 
 	hashListFile, ok := files["hashes.sha256"]
 	if !ok {
-		return 0, 0, 0, nil, nil, fmt.Errorf("hashes.sha256 not found in zip")
+		return 0, 0, 0, nil, nil, nil, false, fmt.Errorf("hashes.sha256 not found in zip")
 	}
-	rc, err := hashListFile.Open()
+	encrypted = zipaes.IsEncrypted(hashListFile)
+	if encrypted && password == "" {
+		return 0, 0, 0, nil, nil, nil, encrypted, fmt.Errorf("zip is encrypted: set the password in the env var named by --password-env")
+	}
+	rc, err := openZipEntry(hashListFile, password)
 	if err != nil {
-		return 0, 0, 0, nil, nil, fmt.Errorf("open hashes.sha256: %w", err)
+		return 0, 0, 0, nil, nil, nil, encrypted, fmt.Errorf("open hashes.sha256: %w", err)
 	}
 	defer rc.Close()
 
@@ -167,7 +192,7 @@ func verifyForensicZip(path string) (total int, okCount int, failedCount int, it
 		}{SHA: sha, Path: p})
 	}
 	if err := sc.Err(); err != nil {
-		return 0, 0, 0, nil, nil, fmt.Errorf("read hashes.sha256: %w", err)
+		return 0, 0, 0, nil, nil, nil, encrypted, fmt.Errorf("read hashes.sha256: %w", err)
 	}
 
 	items = make([]zipVerifyItem, 0, len(expected))
@@ -185,7 +210,7 @@ func verifyForensicZip(path string) (total int, okCount int, failedCount int, it
 			continue
 		}
 
-		sum, err := sha256OfZipFile(f)
+		sum, err := sha256OfZipFile(f, password)
 		if err != nil {
 			failedCount++
 			items = append(items, zipVerifyItem{
@@ -218,25 +243,46 @@ func verifyForensicZip(path string) (total int, okCount int, failedCount int, it
 		})
 	}
 
-	// 额外强校验：manifest.json 内 audit 链（best effort；不影响 hashes.sha256 的校验结果统计）。
+	// 额外强校验：manifest.json 内 audit 链与命中/证据交叉引用（best effort；不影响 hashes.sha256 的校验结果统计）。
 	if mf, ok := files["manifest.json"]; ok {
-		data, readErr := readZipFileAll(mf)
+		data, readErr := readZipFileAll(mf, password)
 		if readErr == nil {
 			var payload struct {
-				Audits []model.AuditLog `json:"audits"`
+				Audits    []model.AuditLog  `json:"audits"`
+				Hits      []model.HitDetail `json:"hits"`
+				Artifacts []struct {
+					Artifact model.ArtifactInfo `json:"artifact"`
+					ZipPath  string             `json:"zip_path"`
+				} `json:"artifacts"`
 			}
 			if err := json.Unmarshal(data, &payload); err == nil {
 				r := auditverify.VerifyAuditLogs(payload.Audits)
 				auditRes = &r
+				arts := make([]xrefverify.ManifestArtifact, 0, len(payload.Artifacts))
+				for _, a := range payload.Artifacts {
+					arts = append(arts, xrefverify.ManifestArtifact{ArtifactID: a.Artifact.ArtifactID, CaseID: a.Artifact.CaseID, ZipPath: a.ZipPath})
+				}
+				xrefRes = xrefverify.VerifyManifest(payload.Hits, arts, func(p string) bool {
+					_, ok := files[p]
+					return ok
+				})
 			}
 		}
 	}
 
-	return total, okCount, failedCount, items, auditRes, nil
+	return total, okCount, failedCount, items, auditRes, xrefRes, encrypted, nil
 }
 
-func sha256OfZipFile(f *zip.File) (string, error) {
-	rc, err := f.Open()
+// openZipEntry 打开 ZIP 条目；WinZip AES 加密条目用 password 解密（读到末尾时校验 HMAC）。
+func openZipEntry(f *zip.File, password string) (io.ReadCloser, error) {
+	if zipaes.IsEncrypted(f) {
+		return zipaes.Open(f, []byte(password))
+	}
+	return f.Open()
+}
+
+func sha256OfZipFile(f *zip.File, password string) (string, error) {
+	rc, err := openZipEntry(f, password)
 	if err != nil {
 		return "", err
 	}
@@ -249,8 +295,8 @@ func sha256OfZipFile(f *zip.File) (string, error) {
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
-func readZipFileAll(f *zip.File) ([]byte, error) {
-	rc, err := f.Open()
+func readZipFileAll(f *zip.File, password string) ([]byte, error) {
+	rc, err := openZipEntry(f, password)
 	if err != nil {
 		return nil, err
 	}
@@ -258,17 +304,6 @@ func readZipFileAll(f *zip.File) ([]byte, error) {
 	return io.ReadAll(rc)
 }
 
-type artifactVerifyItem struct {
-	ArtifactID     string
-	SnapshotPath   string
-	ExpectedSHA256 string
-	ActualSHA256   string
-	ExpectedSize   int64
-	ActualSize     int64
-	Status         string // ok|missing|mismatch|error
-	Error          string
-}
-
 func runVerifyArtifacts(ctx context.Context, args []string) error {
 	cfg := app.DefaultConfig()
 
@@ -276,6 +311,10 @@ func runVerifyArtifacts(ctx context.Context, args []string) error {
 	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
 	caseID := fs.String("case-id", "", "case id (required)")
 	artifactID := fs.String("artifact-id", "", "verify a single artifact id (optional)")
+	workers := fs.Int("workers", artifactverify.DefaultWorkers(), "parallel hashing workers")
+	resume := fs.Bool("resume", false, "continue an interrupted verification (skip files recorded as verified and unchanged)")
+	markerPath := fs.String("marker", "", "resume marker file (default: <db dir>/verify/<case_id>_artifacts.resume.jsonl)")
+	progressEvery := fs.Duration("progress-interval", 5*time.Second, "progress output interval (0 disables progress lines)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
@@ -305,12 +344,8 @@ func runVerifyArtifacts(ctx context.Context, args []string) error {
 	store := sqliteadapter.NewStore(db)
 
 	// 取需要校验的 artifact 列表
-	var targets []struct {
-		ID           string
-		SnapshotPath string
-		SHA256       string
-		SizeBytes    int64
-	}
+	var targets []model.ArtifactInfo
+	marker := strings.TrimSpace(*markerPath)
 	if strings.TrimSpace(*artifactID) != "" {
 		info, err := store.GetArtifactInfo(ctx, strings.TrimSpace(*artifactID))
 		if err != nil {
@@ -319,76 +354,45 @@ func runVerifyArtifacts(ctx context.Context, args []string) error {
 		if info == nil {
 			return fmt.Errorf("artifact not found: %s", strings.TrimSpace(*artifactID))
 		}
-		targets = append(targets, struct {
-			ID           string
-			SnapshotPath string
-			SHA256       string
-			SizeBytes    int64
-		}{
-			ID:           info.ArtifactID,
-			SnapshotPath: info.SnapshotPath,
-			SHA256:       info.SHA256,
-			SizeBytes:    info.SizeBytes,
-		})
+		targets = append(targets, *info)
 	} else {
 		rows, err := store.ListArtifactsByCase(ctx, strings.TrimSpace(*caseID))
 		if err != nil {
 			return err
 		}
-		for _, r := range rows {
-			targets = append(targets, struct {
-				ID           string
-				SnapshotPath string
-				SHA256       string
-				SizeBytes    int64
-			}{
-				ID:           r.ArtifactID,
-				SnapshotPath: r.SnapshotPath,
-				SHA256:       r.SHA256,
-				SizeBytes:    r.SizeBytes,
-			})
+		targets = rows
+		if marker == "" {
+			marker = artifactverify.DefaultMarkerPath(*dbPath, strings.TrimSpace(*caseID))
 		}
 	}
 
-	// 逐个复算
-	results := make([]artifactVerifyItem, 0, len(targets))
-	okCount := 0
-	failCount := 0
-	for _, t := range targets {
-		item := artifactVerifyItem{
-			ArtifactID:     t.ID,
-			SnapshotPath:   t.SnapshotPath,
-			ExpectedSHA256: t.SHA256,
-			ExpectedSize:   t.SizeBytes,
-		}
+	// Ctrl+C 时停止派发，已通过的文件留在标记中，下次带 --resume 续跑。
+	sigCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
-		sum, size, err := hash.File(t.SnapshotPath)
-		if err != nil {
-			// 常见：文件被删除/移动；权限不足
-			item.Status = "missing"
-			item.Error = err.Error()
-			failCount++
-			results = append(results, item)
-			continue
-		}
-		item.ActualSHA256 = sum
-		item.ActualSize = size
-
-		if !strings.EqualFold(strings.TrimSpace(sum), strings.TrimSpace(t.SHA256)) || size != t.SizeBytes {
-			item.Status = "mismatch"
-			failCount++
-			results = append(results, item)
-			continue
-		}
-
-		item.Status = "ok"
-		okCount++
-		results = append(results, item)
+	opts := artifactverify.Options{
+		Workers:          *workers,
+		MarkerPath:       marker,
+		Resume:           *resume,
+		ProgressInterval: *progressEvery,
+	}
+	if *progressEvery > 0 {
+		opts.Progress = printArtifactVerifyProgress
+	}
+	res, runErr := artifactverify.Run(sigCtx, targets, opts)
+	if res == nil {
+		return runErr
 	}
 
-	fmt.Println("artifact sha256 verify completed")
-	fmt.Printf("case_id=%s total=%d ok=%d failed=%d\n", strings.TrimSpace(*caseID), len(results), okCount, failCount)
-	for _, r := range results {
+	if res.Interrupted {
+		fmt.Println("artifact sha256 verify interrupted")
+	} else {
+		fmt.Println("artifact sha256 verify completed")
+	}
+	fmt.Printf("case_id=%s total=%d ok=%d failed=%d resumed=%d workers=%d elapsed=%.1fs\n",
+		strings.TrimSpace(*caseID), res.Total, res.OKCount, res.Failed(), res.ResumedCount, res.Workers, res.ElapsedSec,
+	)
+	for _, r := range res.Items {
 		if r.Status == "ok" {
 			continue
 		}
@@ -398,11 +402,46 @@ func runVerifyArtifacts(ctx context.Context, args []string) error {
 			fmt.Printf("FAIL artifact_id=%s status=%s expected=%s actual=%s path=%s\n", r.ArtifactID, r.Status, r.ExpectedSHA256, r.ActualSHA256, r.SnapshotPath)
 		}
 	}
+	for _, t := range targets {
+		if t.CompatWarning != "" {
+			fmt.Printf("WARN artifact_id=%s collector=%s parser_version=%s %s\n", t.ArtifactID, t.CollectorName, t.ParserVersion, t.CompatWarning)
+		}
+	}
+	if res.Interrupted {
+		if marker != "" {
+			fmt.Printf("resume with: inspector-cli verify artifacts --case-id %s --resume --marker %s\n", strings.TrimSpace(*caseID), marker)
+		}
+		return fmt.Errorf("artifact sha256 verify interrupted: %d/%d items processed", len(res.Items), res.Total)
+	}
+	if runErr != nil {
+		return runErr
+	}
+	// 整案复核时附带交叉引用校验：命中引用的证据记录/快照文件缺失同样视为失败。
+	var xrefRes *xrefverify.Result
+	if strings.TrimSpace(*artifactID) == "" {
+		if xrefRes, err = xrefverify.VerifyCase(ctx, store, strings.TrimSpace(*caseID)); err != nil {
+			return err
+		}
+		printXrefResult(ctx, xrefRes)
+	}
+	if res.Failed() > 0 {
+		return fmt.Errorf("artifact sha256 verify failed: %d items mismatch/missing", res.Failed())
+	}
+	return xrefRes.Err()
+}
 
-	if failCount > 0 {
-		return fmt.Errorf("artifact sha256 verify failed: %d items mismatch/missing", failCount)
+// printArtifactVerifyProgress 输出一行复核进度（files/sec、MB/s、ETA）。
+func printArtifactVerifyProgress(p artifactverify.Progress) {
+	if p.Final {
+		return
 	}
-	return nil
+	eta := "n/a"
+	if p.ETASeconds >= 0 {
+		eta = (time.Duration(p.ETASeconds) * time.Second).String()
+	}
+	fmt.Printf("progress files=%d/%d bytes=%d/%d (%d%%) rate=%.1f files/s %.1f MB/s eta=%s failed=%d resumed=%d\n",
+		p.Done, p.Total, p.BytesDone, p.BytesTotal, p.Percent(), p.FilesPerSec, p.BytesPerSec/(1<<20), eta, p.Failed, p.Resumed,
+	)
 }
 
 func runVerifyAudits(ctx context.Context, args []string) error {
@@ -459,3 +498,46 @@ func filepathDir(p string) string {
 	}
 	return "."
 }
+
+func runVerifyXref(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("verify xref", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	res, err := xrefverify.VerifyCase(ctx, sqliteadapter.NewStore(db), strings.TrimSpace(*caseID))
+	if err != nil {
+		return err
+	}
+	fmt.Println("hit/evidence cross-reference verify completed")
+	printXrefResult(ctx, res)
+	if !res.OK {
+		return res.Err()
+	}
+	return nil
+}
+
+// printXrefResult 输出交叉引用校验汇总与每条问题/告警（告警文本按 --lang 渲染）。
+func printXrefResult(ctx context.Context, res *xrefverify.Result) {
+	res = res.Localize(i18n.FromContext(ctx))
+	fmt.Printf("xref hits=%d links=%d artifacts=%d failed=%d warnings=%d\n", res.Hits, res.Links, res.Artifacts, res.Failed, len(res.Warnings))
+	for _, it := range res.Issues {
+		fmt.Printf("FAIL xref kind=%s hit_id=%s artifact_id=%s path=%s\n", it.Kind, it.HitID, it.ArtifactID, it.Path)
+	}
+	for _, it := range res.Warnings {
+		fmt.Printf("WARN xref kind=%s hit_id=%s %s\n", it.Kind, it.HitID, it.Message)
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/hostscan"
+	"crypto-inspector/internal/services/vmscan"
+)
+
+// runScanVM 对单个 VM 磁盘镜像执行二次扫描：只读取出用户数据目录后按离线模式扫描，设备登记为子设备。
+func runScanVM(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("scan vm", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	evidenceRoot := fs.String("evidence-dir", "data/evidence", "evidence output directory")
+	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
+	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
+	regexPath := fs.String("regex-rules", cfg.RegexRulePath, "custom regex rule file (optional; skipped when missing)")
+	image := fs.String("image", "", "vm disk image path: vmdk|vdi|vhd|vhdx|qcow2 (required)")
+	caseID := fs.String("case-id", "", "existing case id (required)")
+	parentDeviceID := fs.String("parent-device-id", "", "host device the image was found on (optional)")
+	guestOS := fs.String("guest-os", "", "guest os: windows|macos (default: detect from extracted files)")
+	extractor := fs.String("extractor", "auto", "image extractor: auto|guestmount|7z")
+	operator := fs.String("operator", "system", "operator id or name")
+	authOrder := fs.String("auth-order", "", "authorization order/work ticket id (optional in internal mode)")
+	authBasis := fs.String("auth-basis", "", "authorization legal basis reference (optional)")
+	requireAuthOrder := fs.Bool("require-auth-order", false, "require auth order in this run (recommended for external mode)")
+	privacyMode := fs.String("privacy-mode", "off", "privacy mode switch (reserved): off|masked")
+	snapshotCompression := fs.String("snapshot-compression", "none", "compress JSON evidence snapshots: none|gzip (sha256 covers the stored bytes)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*image) == "" {
+		return fmt.Errorf("--image is required")
+	}
+	if strings.TrimSpace(*caseID) == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	result, err := vmscan.Run(ctx, vmscan.Options{
+		Scan: hostscan.Options{
+			DBPath:              *dbPath,
+			EvidenceRoot:        *evidenceRoot,
+			WalletRulePath:      *walletPath,
+			ExchangeRulePath:    *exchangePath,
+			RegexRulePath:       *regexPath,
+			CaseID:              *caseID,
+			Operator:            *operator,
+			AuthorizationOrder:  *authOrder,
+			AuthorizationBasis:  *authBasis,
+			RequireAuthOrder:    *requireAuthOrder,
+			PrivacyMode:         *privacyMode,
+			SnapshotCompression: *snapshotCompression,
+		},
+		ImagePath:      *image,
+		ParentDeviceID: *parentDeviceID,
+		GuestOS:        *guestOS,
+		Extractor:      *extractor,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Println("vm image scan completed")
+	printVMScanResult(*result)
+	return nil
+}
+
+func printVMScanResult(r vmscan.Result) {
+	fmt.Printf("image=%s format=%s extractor=%s staging=%s\n", r.ImagePath, r.Format, r.Extractor, r.StagingDir)
+	if r.Scan == nil {
+		return
+	}
+	fmt.Printf("  device=%s (%s) device_id=%s\n", r.Scan.DeviceName, r.Scan.DeviceOS, r.Scan.DeviceID)
+	fmt.Printf("  artifacts=%d hits=%d wallet_hits=%d exchange_hits=%d\n",
+		r.Scan.ArtifactCount, r.Scan.HitCount, r.Scan.WalletHits, r.Scan.ExchangeHits,
+	)
+	if r.Scan.ReportPath != "" {
+		fmt.Printf("  report=%s\n", r.Scan.ReportPath)
+	}
+	if len(r.Scan.Warnings) > 0 {
+		fmt.Printf("  warnings=%s\n", strings.Join(r.Scan.Warnings, " | "))
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/matcher"
+)
+
+// runWatchlist 是 watchlist 子命令路由（案件关注词，扫描/导入时检索并生成 watchlist_match 命中）：
+// - watchlist add：登记关注词（别名/地址/手机号/关键词）
+// - watchlist list：列出案件关注词
+// - watchlist remove：删除关注词（已产生的命中保留）
+func runWatchlist(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		printWatchlistUsage()
+		return nil
+	}
+
+	switch args[0] {
+	case "add":
+		return runWatchlistAdd(ctx, args[1:])
+	case "list":
+		return runWatchlistList(ctx, args[1:])
+	case "remove":
+		return runWatchlistRemove(ctx, args[1:])
+	default:
+		printWatchlistUsage()
+		return fmt.Errorf("unknown watchlist command: %s", args[0])
+	}
+}
+
+func printWatchlistUsage() {
+	fmt.Println(cliLocale.T("cli.usage"))
+	fmt.Println("  inspector-cli watchlist add --case-id CASE_ID --term TEXT [--type keyword|alias|address|phone] [--note text] [--operator name] [--db path]")
+	fmt.Println("  inspector-cli watchlist list --case-id CASE_ID [--db path]")
+	fmt.Println("  inspector-cli watchlist remove --case-id CASE_ID --term-id ID [--operator name] [--db path]")
+}
+
+func runWatchlistAdd(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("watchlist add", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	term := fs.String("term", "", "watchlist term (required)")
+	termType := fs.String("type", "keyword", "term type: keyword|alias|address|phone")
+	note := fs.String("note", "", "why this term is watched")
+	operator := fs.String("operator", "system", "operator id or name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id := strings.TrimSpace(*caseID)
+	if id == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+	t, typ, err := matcher.NormalizeWatchlistTerm(*term, *termType)
+	if err != nil {
+		return err
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	store := sqliteadapter.NewStore(db)
+
+	ov, err := store.GetCaseOverview(ctx, id)
+	if err != nil {
+		return err
+	}
+	if ov == nil {
+		return fmt.Errorf("case not found: %s", id)
+	}
+	saved, err := store.AddWatchlistTerm(ctx, model.WatchlistTerm{
+		CaseID:   id,
+		Term:     t,
+		TermType: typ,
+		Note:     strings.TrimSpace(*note),
+		Operator: *operator,
+	})
+	if err != nil {
+		return err
+	}
+	_ = store.AppendAudit(ctx, id, "", "watchlist", "add", "success", *operator, "cli.watchlist.add", map[string]any{
+		"term_id":   saved.TermID,
+		"term":      saved.Term,
+		"term_type": saved.TermType,
+	})
+	return printJSON(saved)
+}
+
+func runWatchlistList(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("watchlist list", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if strings.TrimSpace(*caseID) == "" {
+		return fmt.Errorf("--case-id is required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := sqliteadapter.NewStore(db).ListWatchlistTerms(ctx, strings.TrimSpace(*caseID))
+	if err != nil {
+		return err
+	}
+	return printJSON(rows)
+}
+
+func runWatchlistRemove(ctx context.Context, args []string) error {
+	cfg := app.DefaultConfig()
+
+	fs := flag.NewFlagSet("watchlist remove", flag.ContinueOnError)
+	dbPath := fs.String("db", cfg.DBPath, "sqlite database path")
+	caseID := fs.String("case-id", "", "case id (required)")
+	termID := fs.String("term-id", "", "watchlist term id (required)")
+	operator := fs.String("operator", "system", "operator id or name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	id := strings.TrimSpace(*caseID)
+	tid := strings.TrimSpace(*termID)
+	if id == "" || tid == "" {
+		return fmt.Errorf("--case-id and --term-id are required")
+	}
+
+	db, err := openAuditDB(ctx, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	store := sqliteadapter.NewStore(db)
+
+	found, err := store.DeleteWatchlistTerm(ctx, id, tid)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("watchlist term not found: %s", tid)
+	}
+	_ = store.AppendAudit(ctx, id, "", "watchlist", "remove", "success", *operator, "cli.watchlist.remove", map[string]any{
+		"term_id": tid,
+	})
+	fmt.Printf("removed watchlist term %s\n", tid)
+	return nil
+}
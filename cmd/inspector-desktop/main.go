@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/services/privacy"
 	"crypto-inspector/internal/services/webapp"
 )
 
@@ -44,13 +45,19 @@ func run(ctx context.Context, args []string) error {
 	walletPath := fs.String("wallet", cfg.WalletRulePath, "wallet rule file")
 	exchangePath := fs.String("exchange", cfg.ExchangeRulePath, "exchange rule file")
 	enableIOSFullBackup := fs.Bool("ios-full-backup", true, "try full iOS backup when idevicebackup2 is available")
-	privacyMode := fs.String("privacy-mode", "off", "privacy mode switch (reserved): off|masked")
+	privacyMode := fs.String("privacy-mode", "off", "privacy mode: off|masked|partial (partial: ui masked, exports need an unmask token from CRYPTO_INSPECTOR_UNMASK_GRANTS)")
 	uiMode := fs.String("ui", "browser", "ui mode: browser|webview|none (webview only on macOS+cgo)")
 	noOpen := fs.Bool("no-open", false, "do not auto-open browser")
+	lang := fs.String("lang", "", "default language of api error messages: en|zh-CN (default: CRYPTO_INSPECTOR_LANG, else en)")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
+	grants, err := privacy.ParseGrants(os.Getenv("CRYPTO_INSPECTOR_UNMASK_GRANTS"))
+	if err != nil {
+		return fmt.Errorf("parse CRYPTO_INSPECTOR_UNMASK_GRANTS: %w", err)
+	}
+
 	// Ctrl+C 优雅退出：给 http.Server.Shutdown 一个机会释放端口、刷完日志。
 	sigCtx, cancel := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
 	defer cancel()
@@ -66,6 +73,8 @@ func run(ctx context.Context, args []string) error {
 			ListenAddr:          *listen,
 			EnableIOSFullBackup: *enableIOSFullBackup,
 			PrivacyMode:         *privacyMode,
+			UnmaskGrants:        grants,
+			Lang:                *lang,
 		})
 	}()
 
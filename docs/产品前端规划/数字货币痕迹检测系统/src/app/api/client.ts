@@ -1,4 +1,11 @@
 import type {
+  AddressCluster,
+  CaseAddress,
+  NameResolution,
+  ArtifactPreviewResponse,
+  SQLiteDatabase,
+  SQLiteRowPage,
+  SQLiteTable,
   ArtifactResponse,
   CaseDevice,
   CaseOverview,
@@ -6,11 +13,37 @@ import type {
   ChainBTCBalancesResponse,
   ChainEVMBalancesResponse,
   ChainEVMERC20BalancesResponse,
+  ChainHistoryResponse,
+  ChainName,
+  ChainProvidersResponse,
+  ChainTokensResponse,
+  CaseReportDiff,
+  DeviceCorrelation,
+  ExportKind,
+  ExportResponse,
+  Redaction,
+  Comment,
+  DeviceCaseRef,
+  CaseMgmtReceipt,
+  CaseMgmtStatus,
+  WatchlistTerm,
   CaseArtifactVerifyResponse,
   CaseAuditVerifyResponse,
   MetaResponse,
   PrecheckResult,
+  ScanRun,
+  CaseBundle,
+  CaseBundleKind,
+  ChecklistItem,
+  ChecklistStatus,
+  CaseCloseResult,
   HitDetail,
+  HitRollup,
+  HoldingsSummary,
+  PhishingCheckResult,
+  ManualHitResult,
+  ThirdPartyImportResult,
+  CaseStorageUsage,
   AuditLog,
   ArtifactInfo,
   ReportContentResponse,
@@ -18,20 +51,57 @@ import type {
   ScanAllJob,
   CaseChainBalancePersistResponse,
   RulesListResponse,
+  RulesCacheStats,
+  PrecheckPolicyResponse,
+  CaseAttachment,
+  CaseAssignment,
+  DeviceSnapshot,
+  DeviceEvent,
 } from "./types";
 
-type ApiErrorBody = { error?: string };
+type ApiErrorBody = { error?: string; code?: string };
+
+// CSRF：服务端下发 ci_csrf cookie（SameSite=Strict），写请求需通过 X-CSRF-Token 回传。
+function csrfToken(): string {
+  const m = document.cookie.match(/(?:^|;\s*)ci_csrf=([0-9a-f]{64})/);
+  return m ? m[1] : "";
+}
+
+// 隐私模式 partial：unmask 令牌只保存在当前标签页（sessionStorage），随请求以 X-Unmask-Token 发送；
+// 未设置时界面看到的是脱敏数据，导出/下载会被拒绝（ERR_UNMASK_DENIED）。
+const unmaskStorageKey = "ci_unmask_token";
+
+export function getUnmaskToken(): string {
+  return sessionStorage.getItem(unmaskStorageKey) || "";
+}
+
+export function setUnmaskToken(token: string) {
+  if (token.trim()) {
+    sessionStorage.setItem(unmaskStorageKey, token.trim());
+  } else {
+    sessionStorage.removeItem(unmaskStorageKey);
+  }
+}
+
+function unmaskHeaders(): Record<string, string> {
+  const t = getUnmaskToken();
+  return t ? { "X-Unmask-Token": t } : {};
+}
 
 async function requestJSON<T>(
   path: string,
   init?: RequestInit
 ): Promise<T> {
+  const method = (init?.method ?? "GET").toUpperCase();
+  const token = method === "GET" || method === "HEAD" ? "" : csrfToken();
   const res = await fetch(path, {
+    ...init,
     headers: {
       "Content-Type": "application/json",
+      ...(token ? { "X-CSRF-Token": token } : {}),
+      ...unmaskHeaders(),
       ...(init?.headers ?? {}),
     },
-    ...init,
   });
 
   // 约定：后端出错时返回 {error:"..."}，成功返回业务 JSON。
@@ -46,6 +116,27 @@ async function requestJSON<T>(
   return data as T;
 }
 
+// downloadFile 以 fetch 携带 unmask 令牌下载文件（普通链接无法附带请求头），再交给浏览器保存。
+export async function downloadFile(path: string, fileName: string) {
+  const res = await fetch(path, { headers: unmaskHeaders() });
+  if (!res.ok) {
+    const text = await res.text();
+    let msg = `HTTP ${res.status} ${res.statusText}`;
+    try {
+      msg = (JSON.parse(text) as ApiErrorBody)?.error || msg;
+    } catch {
+      // 非 JSON 错误体
+    }
+    throw new Error(msg);
+  }
+  const url = URL.createObjectURL(await res.blob());
+  const a = document.createElement("a");
+  a.href = url;
+  a.download = fileName;
+  a.click();
+  URL.revokeObjectURL(url);
+}
+
 export const api = {
   getMeta: () => requestJSON<MetaResponse>("/api/meta"),
 
@@ -56,6 +147,21 @@ export const api = {
       method: "POST",
       body: JSON.stringify(payload),
     }),
+  // 前置检查策略：yaml 为空字符串时清除策略（恢复内置检查）
+  getPrecheckPolicy: () => requestJSON<PrecheckPolicyResponse>("/api/precheck-policy"),
+  setPrecheckPolicy: (yaml: string) =>
+    requestJSON<PrecheckPolicyResponse>("/api/precheck-policy", {
+      method: "POST",
+      body: JSON.stringify({ yaml }),
+    }),
+  // 案件清单模板：yaml 为空字符串时恢复内置默认模板
+  getCaseChecklistTemplate: () =>
+    requestJSON<{ ok: boolean; builtin: boolean; yaml: string }>("/api/settings/case-checklist"),
+  setCaseChecklistTemplate: (yaml: string) =>
+    requestJSON<{ ok: boolean; builtin: boolean; yaml: string }>("/api/settings/case-checklist", {
+      method: "POST",
+      body: JSON.stringify({ yaml }),
+    }),
   activateRules: (payload: { wallet_path?: string; exchange_path?: string }) =>
     requestJSON<{ ok: boolean; active: { wallet_path: string; exchange_path: string } }>(
       `/api/rules?action=activate`,
@@ -64,10 +170,16 @@ export const api = {
         body: JSON.stringify(payload),
       }
     ),
+  // 清空服务端规则加载缓存（规则文件被外部覆盖且 mtime 未变时使用）
+  invalidateRulesCache: () =>
+    requestJSON<{ ok: boolean; cleared: number; cache: RulesCacheStats }>(`/api/rules/cache/invalidate`, {
+      method: "POST",
+    }),
 
-  listCases: (limit = 50, offset = 0) =>
+  // owner 非空时只看该操作员当前负责的案件（“我的案件”）
+  listCases: (limit = 50, offset = 0, owner?: string) =>
     requestJSON<{ cases: CaseSummary[] }>(
-      `/api/cases?limit=${limit}&offset=${offset}`
+      `/api/cases?limit=${limit}&offset=${offset}` + (owner ? `&owner=${encodeURIComponent(owner)}` : "")
     ),
 
   createOrUpdateCase: (payload: {
@@ -96,6 +208,141 @@ export const api = {
     return requestJSON<{ hits: HitDetail[] }>(`/api/cases/${caseId}/hits${q}`);
   },
 
+  // 案件级命中汇总（按规则 + 命中值跨设备合并）
+  listCaseHitRollup: (caseId: string, hitType?: string) => {
+    const q = hitType ? `?hit_type=${encodeURIComponent(hitType)}` : "";
+    return requestJSON<{ rollup: HitRollup[] }>(`/api/cases/${caseId}/hits/rollup${q}`);
+  },
+
+  // 已识别持有汇总（未配置价格来源时 configured=false；每次调用都会查询价格来源并写审计）
+  getCaseHoldings: (caseId: string) =>
+    requestJSON<{ configured: boolean; holdings: HoldingsSummary | null }>(`/api/cases/${caseId}/holdings`),
+
+  // 人工录入命中（justification 必填；附件以 base64 上传，落库为 manual_evidence 证据）
+  createManualHit: (
+    caseId: string,
+    payload: {
+      operator?: string;
+      device_id?: string;
+      hit_type?: string; // 默认 manual_finding
+      value: string;
+      justification: string;
+      rule_name?: string;
+      confidence?: number;
+      verdict?: "confirmed" | "suspected" | "unsupported";
+      observed_at?: number;
+      attachment_name?: string;
+      attachment_base64?: string;
+    }
+  ) =>
+    requestJSON<{ ok: boolean; hit: ManualHitResult }>(`/api/cases/${caseId}/hits/manual`, {
+      method: "POST",
+      body: JSON.stringify(payload),
+    }),
+
+  // 导入第三方取证报告（请求体为原始文件；导入后自动执行钱包/交易所规则匹配）
+  importThirdPartyReport: (
+    caseId: string,
+    file: File | Blob,
+    params: {
+      file_name?: string;
+      format?: "ufed_xml" | "axiom_xml" | "csv" | "plaso_csv" | "autopsy_csv"; // 缺省自动识别
+      os?: "android" | "ios" | "windows" | "macos"; // 报告不含设备信息时必填
+      device_id?: string;
+      device_name?: string;
+      operator?: string;
+      note?: string;
+    } = {}
+  ) => {
+    const qs = new URLSearchParams();
+    for (const [k, v] of Object.entries(params)) {
+      if (v) qs.set(k, v);
+    }
+    if (!qs.has("file_name") && file instanceof File) qs.set("file_name", file.name);
+    return requestJSON<{ ok: boolean; import: ThirdPartyImportResult }>(
+      `/api/cases/${caseId}/imports?${qs.toString()}`,
+      {
+        method: "POST",
+        headers: { "Content-Type": "application/octet-stream" },
+        body: file,
+      }
+    );
+  },
+
+  // 案件存储占用与配额；quota_bytes 为 null 时清除案件级配额（回退到全局默认）
+  getCaseStorage: (caseId: string) =>
+    requestJSON<{ storage: CaseStorageUsage }>(`/api/cases/${caseId}/storage`),
+
+  setCaseStorageQuota: (caseId: string, quotaBytes: number | null, operator?: string) =>
+    requestJSON<{ ok: boolean; storage: CaseStorageUsage }>(`/api/cases/${caseId}/storage`, {
+      method: "POST",
+      body: JSON.stringify({ quota_bytes: quotaBytes, operator }),
+    }),
+
+  // 执法授权文书：PDF 以 base64 上传；填写工单号时按签发单位格式校验
+  listAuthorizationDocuments: (caseId: string) =>
+    requestJSON<{ documents: CaseAttachment[] }>(`/api/cases/${caseId}/authorization`),
+  uploadAuthorizationDocument: (
+    caseId: string,
+    payload: { file_name: string; content_base64: string; authorization_order?: string; agency?: string; operator?: string },
+  ) =>
+    requestJSON<{ ok: boolean; document: CaseAttachment }>(`/api/cases/${caseId}/authorization`, {
+      method: "POST",
+      body: JSON.stringify(payload),
+    }),
+
+  // 案件交接：note 为交接说明（必填）
+  getCaseHandover: (caseId: string) =>
+    requestJSON<{ owner: string; assignments: CaseAssignment[] }>(`/api/cases/${caseId}/handover`),
+  handoverCase: (caseId: string, toOperator: string, note: string, operator?: string) =>
+    requestJSON<{ ok: boolean; assignment: CaseAssignment }>(`/api/cases/${caseId}/handover`, {
+      method: "POST",
+      body: JSON.stringify({ to_operator: toOperator, note, operator }),
+    }),
+  listHandovers: (operator: string) =>
+    requestJSON<{ assignments: CaseAssignment[] }>(`/api/handovers?operator=${encodeURIComponent(operator)}`),
+
+  // 多设备关联分析：GET 返回最近一次结果（可能为 null），POST 重新计算并落库
+  getDeviceCorrelation: (caseId: string) =>
+    requestJSON<{ correlation: DeviceCorrelation | null }>(`/api/cases/${caseId}/device-correlation`),
+
+  runDeviceCorrelation: (caseId: string, operator?: string) =>
+    requestJSON<{ correlation: DeviceCorrelation }>(`/api/cases/${caseId}/device-correlation`, {
+      method: "POST",
+      body: JSON.stringify({ operator }),
+    }),
+
+  // 钓鱼登录页检测：读取形近域名站点的 TLS 证书（需要访问网络），证书不属于交易所时输出 phishing_suspected 命中
+  runPhishingCheck: (caseId: string, operator?: string) =>
+    requestJSON<{ phishing: PhishingCheckResult }>(`/api/cases/${caseId}/phishing-check`, {
+      method: "POST",
+      body: JSON.stringify({ operator }),
+    }),
+
+  listCaseAddressClusters: (caseId: string) =>
+    requestJSON<{ clusters: AddressCluster[] }>(`/api/cases/${caseId}/address-clusters`),
+
+  rebuildCaseAddressClusters: (caseId: string, payload?: { operator?: string; window_seconds?: number }) =>
+    requestJSON<{ clusters: AddressCluster[] }>(`/api/cases/${caseId}/address-clusters`, {
+      method: "POST",
+      body: JSON.stringify(payload || {}),
+    }),
+
+  // 报告对比（report_a/report_b 都不传时对比最近两次扫描）
+  getCaseReportDiff: (caseId: string, reportA?: string, reportB?: string) => {
+    const qs = new URLSearchParams();
+    if (reportA) qs.set("report_a", reportA);
+    if (reportB) qs.set("report_b", reportB);
+    const suffix = qs.toString() ? `?${qs.toString()}` : "";
+    return requestJSON<CaseReportDiff>(`/api/cases/${caseId}/report-diff${suffix}`);
+  },
+
+  listCaseAddresses: (caseId: string) =>
+    requestJSON<{ addresses: CaseAddress[] }>(`/api/cases/${caseId}/addresses`),
+
+  listCaseNameResolutions: (caseId: string) =>
+    requestJSON<{ resolutions: NameResolution[] }>(`/api/cases/${caseId}/name-resolutions`),
+
   listCaseArtifacts: (caseId: string) =>
     requestJSON<{ artifacts: ArtifactInfo[] }>(`/api/cases/${caseId}/artifacts`),
 
@@ -104,11 +351,70 @@ export const api = {
     return requestJSON<ArtifactResponse>(`/api/artifacts/${artifactId}${q}`);
   },
 
+  getArtifactPreview: (artifactId: string) =>
+    requestJSON<ArtifactPreviewResponse>(`/api/artifacts/${artifactId}/preview`),
+
+  listArtifactSQLiteDBs: (artifactId: string) =>
+    requestJSON<{ artifact_id: string; databases: SQLiteDatabase[] }>(
+      `/api/artifacts/${artifactId}/sqlite`
+    ),
+
+  listArtifactSQLiteTables: (artifactId: string, db: string) =>
+    requestJSON<{ artifact_id: string; db: string; tables: SQLiteTable[]; truncated: boolean }>(
+      `/api/artifacts/${artifactId}/sqlite/tables?db=${encodeURIComponent(db)}`
+    ),
+
+  queryArtifactSQLiteRows: (
+    artifactId: string,
+    db: string,
+    params: { table: string; offset?: number; limit?: number; order_by?: string; desc?: boolean }
+  ) => {
+    const qs = new URLSearchParams({ db, table: params.table });
+    if (params.offset) qs.set("offset", String(params.offset));
+    if (params.limit) qs.set("limit", String(params.limit));
+    if (params.order_by) qs.set("order_by", params.order_by);
+    if (params.desc) qs.set("desc", "true");
+    return requestJSON<{ artifact_id: string; db: string; page: SQLiteRowPage }>(
+      `/api/artifacts/${artifactId}/sqlite/rows?${qs.toString()}`
+    );
+  },
+
+  // 案件侦查清单：not_applicable 必须填写 note
+  getCaseChecklist: (caseId: string) =>
+    requestJSON<{ checklist: ChecklistItem[]; pending_required: number }>(
+      `/api/cases/${caseId}/checklist`
+    ),
+  updateChecklistItem: (
+    caseId: string,
+    itemId: string,
+    payload: { status?: ChecklistStatus; responsible?: string; note?: string; operator?: string }
+  ) =>
+    requestJSON<{ ok: boolean; item: ChecklistItem }>(
+      `/api/cases/${caseId}/checklist/${encodeURIComponent(itemId)}`,
+      { method: "POST", body: JSON.stringify(payload) }
+    ),
+  // external profile 下清单未完成时返回 409（ERR_CHECKLIST_INCOMPLETE）
+  closeCase: (caseId: string, payload: { profile?: "internal" | "external"; operator?: string }) =>
+    requestJSON<{ ok: boolean; close: CaseCloseResult }>(`/api/cases/${caseId}/close`, {
+      method: "POST",
+      body: JSON.stringify(payload),
+    }),
+
   listCasePrechecks: (caseId: string) =>
     requestJSON<{ prechecks: PrecheckResult[] }>(
       `/api/cases/${caseId}/prechecks`
     ),
 
+  // 每次扫描的规范化参数快照（取证 ZIP manifest 原样收录）
+  listCaseScanRuns: (caseId: string) =>
+    requestJSON<{ scan_runs: ScanRun[] }>(`/api/cases/${caseId}/scan-runs`),
+
+  // 案件证据与报告打包信息（整包 sha256 / 文件数 / 缺失文件告警）；下载用 downloadFile(`/api/cases/${caseId}/download?kind=...`)
+  getCaseBundleInfo: (caseId: string, kind: CaseBundleKind = "all") =>
+    requestJSON<{ bundle: CaseBundle }>(
+      `/api/cases/${caseId}/download?kind=${kind}&info=1`
+    ),
+
   listCaseAudits: (caseId: string, limit = 500) =>
     requestJSON<{ audits: AuditLog[] }>(
       `/api/cases/${caseId}/audits?limit=${limit}`
@@ -124,6 +430,17 @@ export const api = {
     return requestJSON<ReportContentResponse>(`/api/cases/${caseId}/report${q}`);
   },
 
+  // 已注册的导出格式（后端 exporter 注册表）
+  listExportKinds: (caseId: string) =>
+    requestJSON<{ exports: ExportKind[] }>(`/api/cases/${caseId}/exports`),
+
+  // 通用导出：结果文件字段为 {file_key}_path / {file_key}_sha256（例如 zip_path、pdf_sha256）
+  runExport: (caseId: string, kind: string, payload?: { operator?: string; note?: string }) =>
+    requestJSON<ExportResponse>(`/api/cases/${caseId}/exports/${encodeURIComponent(kind)}`, {
+      method: "POST",
+      body: JSON.stringify(payload ?? {}),
+    }),
+
   // 司法导出包（ZIP + manifest + hashes.sha256）
   generateForensicZip: (
     caseId: string,
@@ -142,10 +459,102 @@ export const api = {
       body: JSON.stringify(payload ?? {}),
     }),
 
+  // 对外披露导出包（按遮盖标记生成遮盖副本 + redactions.json，原始证据不变）
+  generateDisclosureZip: (
+    caseId: string,
+    payload?: { operator?: string; note?: string }
+  ) =>
+    requestJSON<{
+      ok: boolean;
+      case_id: string;
+      report_id: string;
+      zip_path: string;
+      zip_sha256: string;
+      redaction_count: number;
+      applied_count: number;
+      warnings?: string[];
+      report: ReportInfo | null;
+    }>(`/api/cases/${caseId}/exports/disclosure-zip`, {
+      method: "POST",
+      body: JSON.stringify(payload ?? {}),
+    }),
+
+  // 遮盖标记（pointer 为 JSON Pointer，空串表示整条证据/命中）
+  listRedactions: (caseId: string) =>
+    requestJSON<{ redactions: Redaction[] }>(`/api/cases/${caseId}/redactions`),
+
+  addRedaction: (
+    caseId: string,
+    payload: {
+      operator?: string;
+      target_type: "artifact" | "hit";
+      target_id: string;
+      pointer?: string;
+      reason?: string;
+    }
+  ) =>
+    requestJSON<{ ok: boolean; redaction: Redaction }>(`/api/cases/${caseId}/redactions`, {
+      method: "POST",
+      body: JSON.stringify(payload),
+    }),
+
+  deleteRedaction: (caseId: string, redactionId: string, operator?: string) =>
+    requestJSON<{ ok: boolean }>(
+      `/api/cases/${caseId}/redactions/${redactionId}${operator ? `?operator=${encodeURIComponent(operator)}` : ""}`,
+      { method: "DELETE" }
+    ),
+
+  // 分析评论（证据/命中下的复核讨论，只追加）
+  listComments: (caseId: string, target?: { target_type: Comment["target_type"]; target_id: string }) => {
+    const q = target
+      ? `?target_type=${encodeURIComponent(target.target_type)}&target_id=${encodeURIComponent(target.target_id)}`
+      : "";
+    return requestJSON<{ comments: Comment[] }>(`/api/cases/${caseId}/comments${q}`);
+  },
+
+  addComment: (
+    caseId: string,
+    payload: {
+      target_type?: Comment["target_type"];
+      target_id?: string;
+      parent_id?: string;
+      author?: string;
+      text: string;
+    }
+  ) =>
+    requestJSON<{ ok: boolean; comment: Comment }>(`/api/cases/${caseId}/comments`, {
+      method: "POST",
+      body: JSON.stringify(payload),
+    }),
+
+  // 案件关注词（下次扫描/导入时检索全部文本类证据，生成 watchlist_match 命中）
+  listWatchlist: (caseId: string) =>
+    requestJSON<{ terms: WatchlistTerm[] }>(`/api/cases/${caseId}/watchlist`),
+
+  addWatchlistTerm: (
+    caseId: string,
+    payload: {
+      term: string;
+      term_type?: WatchlistTerm["term_type"];
+      note?: string;
+      operator?: string;
+    }
+  ) =>
+    requestJSON<{ ok: boolean; term: WatchlistTerm }>(`/api/cases/${caseId}/watchlist`, {
+      method: "POST",
+      body: JSON.stringify(payload),
+    }),
+
+  deleteWatchlistTerm: (caseId: string, termId: string, operator?: string) =>
+    requestJSON<{ ok: boolean }>(
+      `/api/cases/${caseId}/watchlist/${termId}${operator ? `?operator=${encodeURIComponent(operator)}` : ""}`,
+      { method: "DELETE" }
+    ),
+
   // 取证 PDF 报告（forensic_pdf）
   generateForensicPdf: (
     caseId: string,
-    payload?: { operator?: string; note?: string }
+    payload?: { operator?: string; note?: string; ui_screenshots?: boolean; include_comments?: boolean }
   ) =>
     requestJSON<{
       ok: boolean;
@@ -160,9 +569,19 @@ export const api = {
       body: JSON.stringify(payload ?? {}),
     }),
 
+  // 外部案件管理系统对接（serve --cms-config）
+  getCaseManagement: (caseId: string) =>
+    requestJSON<CaseMgmtStatus>(`/api/cases/${caseId}/case-management`),
+  pushCaseManagement: (caseId: string, operator?: string) =>
+    requestJSON<{ ok: boolean; receipt: CaseMgmtReceipt }>(`/api/cases/${caseId}/case-management/push`, {
+      method: "POST",
+      body: JSON.stringify({ operator }),
+    }),
+
   // 链上余额查询（EVM 原生币余额，eth_getBalance）
   queryEVMBalances: (payload: {
     rpc_url?: string;
+    fallback_rpc_urls?: string[];
     symbol?: string;
     addresses: string[];
   }) =>
@@ -171,9 +590,35 @@ export const api = {
       body: JSON.stringify(payload),
     }),
 
+  // 预置代币清单（稳定币合约/decimals）
+  listChainTokens: (chain?: ChainName) =>
+    requestJSON<ChainTokensResponse>(
+      `/api/chain/tokens${chain ? `?chain=${encodeURIComponent(chain)}` : ""}`
+    ),
+
+  // 链上查询提供方注册表（persistCaseChainBalance 的 kind 取值来源）
+  listChainProviders: () =>
+    requestJSON<ChainProvidersResponse>("/api/chain/providers"),
+
+  // 地址交易历史（仅 history=true 的 kind，直接查询不留痕）
+  queryChainHistory: (payload: {
+    kind: string;
+    address: string;
+    endpoint?: string;
+    fallback_endpoints?: string[];
+    symbol?: string;
+  }) =>
+    requestJSON<ChainHistoryResponse>("/api/chain/history", {
+      method: "POST",
+      body: JSON.stringify(payload),
+    }),
+
   // 链上余额查询（EVM ERC20，eth_call balanceOf）
   queryEVMERC20Balances: (payload: {
     rpc_url?: string;
+    fallback_rpc_urls?: string[];
+    // chain + symbol：从预置代币清单选择合约/decimals（不填 contract 时生效）
+    chain?: ChainName;
     symbol?: string;
     contract?: string;
     decimals?: number;
@@ -187,6 +632,7 @@ export const api = {
   // 链上余额查询（BTC，HTTP API）
   queryBTCBalances: (payload: {
     base_url?: string;
+    fallback_base_urls?: string[];
     symbol?: string;
     addresses: string[];
   }) =>
@@ -195,18 +641,26 @@ export const api = {
       body: JSON.stringify(payload),
     }),
 
-  // 案件链上余额查询（查询并落库为证据 + token_balance 命中）
+  // 案件链上余额查询（查询并落库为证据 + token_balance / nft_holdings 命中）
   persistCaseChainBalance: (
     caseId: string,
     payload: {
       operator?: string;
       note?: string;
-      kind?: "evm_native" | "evm_erc20" | "btc";
+      kind?: string; // evm_native | evm_erc20 | evm_nft | btc | listChainProviders 返回的其他 kind
       rpc_url?: string;
+      fallback_rpc_urls?: string[];
+      chain?: ChainName; // evm_erc20：按预置清单选择合约
       symbol?: string;
       contract?: string;
       decimals?: number;
+      // evm_nft：查询指定合约的 NFT 持有（erc1155 需给出 token_ids）
+      standard?: "erc721" | "erc1155";
+      contracts?: string[];
+      token_ids?: string[];
+      max_tokens?: number;
       base_url?: string;
+      fallback_base_urls?: string[];
       addresses: string[];
     }
   ) =>
@@ -250,10 +704,15 @@ export const api = {
     auth_basis?: string;
     privacy_mode?: "off" | "masked";
     ios_full_backup?: boolean;
+    max_bytes?: number;
+    skip_history_db?: boolean;
+    eth_rpc_url?: string;
+    bnb_rpc_url?: string;
     enable_host?: boolean;
     enable_mobile?: boolean;
     enable_android?: boolean;
     enable_ios?: boolean;
+    enable_harmony?: boolean;
   }) =>
     requestJSON<ScanAllJob>("/api/jobs/scan-all", {
       method: "POST",
@@ -261,4 +720,27 @@ export const api = {
     }),
 
   getJob: (jobId: string) => requestJSON<ScanAllJob>(`/api/jobs/${jobId}`),
+
+  // 移动设备实时监测（需 serve --monitor；未启用时 404）
+  getDevices: () => requestJSON<DeviceSnapshot>("/api/devices"),
+
+  // 同一设备标识已登记的其他未结案件（开始扫描前提示改用已有案件）
+  findDeviceCases: (identifier: string, excludeCaseId?: string) =>
+    requestJSON<{ identifier: string; cases: DeviceCaseRef[] }>(
+      `/api/devices/cases?identifier=${encodeURIComponent(identifier)}${
+        excludeCaseId ? `&exclude_case_id=${encodeURIComponent(excludeCaseId)}` : ""
+      }`
+    ),
+  // SSE：先推送 snapshot，之后推送设备接入/拔出/授权变化；返回关闭函数
+  watchDevices: (
+    onSnapshot: (s: DeviceSnapshot) => void,
+    onEvent: (e: DeviceEvent) => void
+  ) => {
+    const es = new EventSource("/api/devices/events");
+    es.addEventListener("snapshot", (m) => onSnapshot(JSON.parse((m as MessageEvent).data)));
+    for (const t of ["device_connected", "device_disconnected", "authorization_changed"]) {
+      es.addEventListener(t, (m) => onEvent(JSON.parse((m as MessageEvent).data)));
+    }
+    return () => es.close();
+  },
 };
@@ -8,6 +8,13 @@ export type MetaResponse = {
     version: string;
     commit: string;
     build_time: string;
+    /** 采集组件版本登记（collector_name 对应的 collector/parser 版本） */
+    components?: { name: string; collector_version: string; parser_version: string }[];
+  };
+  // off|masked|partial；partial 时界面默认脱敏，导出/下载需携带 unmask 令牌
+  privacy?: {
+    mode: string;
+    unmask_header: string;
   };
   db: {
     schema_version: string;
@@ -29,15 +36,25 @@ export type MetaResponse = {
       enabled: number;
       sha256: string;
     };
+    /** 服务端规则加载缓存统计 */
+    cache?: RulesCacheStats;
   };
 };
 
+export type RulesCacheStats = {
+  entries: number;
+  hits: number;
+  misses: number;
+  invalidated_at?: number;
+};
+
 export type CaseSummary = {
   case_id: string;
   case_no?: string;
   title?: string;
   status: string;
   created_by?: string;
+  owner?: string; // 当前负责人（交接后变化）
   note?: string;
   created_at: number;
   updated_at: number;
@@ -49,6 +66,7 @@ export type CaseOverview = {
   title?: string;
   status: string;
   created_by?: string;
+  owner?: string; // 当前负责人（交接后变化）
   note?: string;
   created_at: number;
   updated_at: number;
@@ -56,6 +74,35 @@ export type CaseOverview = {
   artifact_count: number;
   hit_count: number;
   report_count: number;
+  /** 案件侦查清单（external profile 结案前 required 项必须全部完成） */
+  checklist?: ChecklistItem[];
+};
+
+export type ChecklistStatus = "pending" | "done" | "not_applicable";
+
+export type ChecklistItem = {
+  case_id: string;
+  item_id: string;
+  label: string;
+  required: boolean;
+  /** 自动完成条件：host_scan / mobile_scan / balance_query / forensic_export */
+  auto?: string;
+  position: number;
+  status: ChecklistStatus;
+  responsible?: string;
+  /** 自动完成时为 system */
+  completed_by?: string;
+  completed_at?: number;
+  note?: string;
+  created_at: number;
+  updated_at: number;
+};
+
+export type CaseCloseResult = {
+  case_id: string;
+  status: string;
+  profile: "internal" | "external";
+  pending?: ChecklistItem[];
 };
 
 export type CaseDevice = {
@@ -69,6 +116,8 @@ export type CaseDevice = {
   auth_note?: string;
   first_seen_at: number;
   last_seen_at: number;
+  /** 非空表示该设备来自父设备上发现的 VM 磁盘镜像（二次扫描） */
+  parent_device_id?: string;
 };
 
 export type HitDetail = {
@@ -86,6 +135,145 @@ export type HitDetail = {
   verdict: string;
   detail_json?: string;
   artifact_ids?: string[];
+  cluster_id?: string;
+  manual?: boolean; // 人工录入（rule_id=manual）
+};
+
+// 案件级命中汇总：同一规则 + 命中值跨设备合并（逐设备明细仍见 HitDetail）
+export type HitRollup = {
+  hit_type: string;
+  rule_id: string;
+  rule_name: string;
+  matched_value: string;
+  hit_count: number;
+  device_count: number;
+  device_ids: string[];
+  hit_ids: string[];
+  artifact_count: number;
+  first_seen_at: number;
+  last_seen_at: number;
+  max_confidence: number;
+  verdicts: string[];
+  manual?: boolean;
+};
+
+// 已识别持有汇总（token_balance 命中按价格来源折算；未定价代币 value 为空且不计入 total）
+export type Holding = {
+  symbol: string;
+  networks: string[];
+  address_count: number;
+  amount: string;
+  pair?: string;
+  price?: string;
+  price_at?: number;
+  price_source?: string;
+  value?: string;
+  hit_ids: string[];
+};
+
+export type HoldingsSummary = {
+  reference_currency: string;
+  price_source: string;
+  generated_at: number;
+  holdings: Holding[];
+  total: string;
+  unpriced?: string[];
+  warnings?: string[];
+  // 报告导出时固化的报价快照证据（界面查看不生成）
+  price_snapshot?: { artifact_id: string; sha256: string; endpoint: string; fetched_at: number };
+};
+
+export type ManualHitResult = {
+  hit_id: string;
+  case_id: string;
+  device_id: string;
+  hit_type: string;
+  artifact_id?: string; // 附件证据（manual_evidence）
+  sha256?: string;
+  created_at: number;
+};
+
+// 第三方取证工具报告导入（Cellebrite UFED / Magnet AXIOM）
+export type ThirdPartyImportResult = {
+  case_id: string;
+  device_id: string;
+  format: "ufed_xml" | "axiom_xml" | "csv" | "plaso_csv" | "autopsy_csv";
+  tool: string; // cellebrite_ufed | magnet_axiom | csv_export | plaso | autopsy
+  os: string;
+  source_sha256: string;
+  snapshot_path: string;
+  artifact_ids: string[];
+  app_count: number;
+  visit_count: number;
+  event_count?: number; // 时间线导入（Plaso/Autopsy）读取的事件总数
+  skipped: number;
+  hit_ids: string[];
+  warnings?: string[];
+};
+
+// 案件存储占用与配额（GET /api/cases/{id}/storage）
+export type CaseStorageUsage = {
+  case_id: string;
+  evidence_bytes: number; // 证据快照存储字节（压缩快照按压缩后计）
+  artifact_count: number;
+  payload_bytes: number; // 库内 payload_json 字节
+  report_bytes: number;
+  report_count: number;
+  missing_reports?: number;
+  db_rows: Record<string, number>;
+  total_bytes: number; // evidence_bytes + report_bytes（配额按此计算）
+  quota: {
+    limit_bytes: number; // 0 = 不限制
+    source: "case" | "default";
+    mode: "warn" | "block";
+    used_ratio?: number;
+    status: "unlimited" | "ok" | "near_limit" | "exceeded";
+  };
+};
+
+export type AddressCluster = {
+  cluster_id: string;
+  case_id: string;
+  size: number;
+  score: number;
+  addresses: string[];
+  hit_ids?: string[];
+  reasons: Record<string, number>; // same_page / same_url / temporal / same_artifact
+  first_seen_at: number;
+  last_seen_at: number;
+  created_at?: number;
+};
+
+export type NameResolution = {
+  resolution_id: string;
+  case_id: string;
+  device_id?: string;
+  name: string; // alice.eth / bob.bnb
+  service: string; // ens | space_id
+  namehash: string;
+  status: string; // resolved | unresolved | failed | skipped
+  resolved_address?: string;
+  resolver?: string;
+  registry?: string;
+  block_number?: number;
+  block_timestamp?: number;
+  rpc_url?: string;
+  error?: string;
+  artifact_ids?: string[];
+  first_seen_at?: number;
+  last_seen_at?: number;
+  resolved_at: number;
+};
+
+export type CaseAddress = {
+  case_id: string;
+  address: string;
+  chain?: string;
+  source: string; // extracted | ens | space_id
+  label?: string;
+  first_seen_at: number;
+  last_seen_at: number;
+  detail?: Record<string, unknown>;
 };
 
 export type ArtifactInfo = {
@@ -100,7 +288,20 @@ export type ArtifactInfo = {
   collected_at: number;
   collector_name?: string;
   collector_version?: string;
+  parser_version?: string;
   acquisition_method?: string;
+  mime_type?: string;
+  /** 快照压缩方式（none|gzip）；sha256/size_bytes 针对压缩后的存储字节 */
+  snapshot_compression?: "none" | "gzip";
+  /** 检材编号（首次导出时分配，之后不变）；exhibit 为显示标签，如 检材-001 */
+  exhibit_no?: number;
+  exhibit?: string;
+  /** parser_version 高于当前程序支持的版本时的兼容性提示 */
+  compat_warning?: string;
+  /** 超大证据分片：part_group 为第 1 个分片的 artifact_id */
+  part_group?: string;
+  part_no?: number;
+  part_count?: number;
 };
 
 export type ArtifactResponse = {
@@ -109,6 +310,59 @@ export type ArtifactResponse = {
   content_length?: number;
 };
 
+export type ArtifactPreview = {
+  mime_type: string;
+  kind: "zip" | "sqlite" | "image" | "json" | "text" | "binary";
+  render: "file_list" | "table_list" | "image" | "code" | "text" | "hex";
+  size_bytes: number;
+  compression?: "gzip";
+  zip?: {
+    entries: Array<{
+      name: string;
+      size_bytes: number;
+      compressed_bytes: number;
+      modified?: number;
+      mime_type: string;
+    }>;
+    total: number;
+    truncated: boolean;
+  };
+  sqlite?: {
+    tables: Array<{ name: string; type: string; rows: number; columns: string[] }>;
+    truncated: boolean;
+  };
+  image?: {
+    width: number;
+    height: number;
+    thumb_width?: number;
+    thumb_height?: number;
+    data_url?: string;
+    note?: string;
+  };
+  text?: { content: string; language: "json" | "text"; truncated: boolean };
+  hex?: string;
+};
+
+export type ArtifactPreviewResponse = {
+  artifact: ArtifactInfo;
+  preview: ArtifactPreview;
+};
+
+export type SQLiteDatabase = { entry: string; size_bytes: number; has_wal: boolean };
+
+export type SQLiteTable = { name: string; type: string; rows: number; columns: string[] };
+
+export type SQLiteRowPage = {
+  table: string;
+  columns: string[];
+  rows: unknown[][];
+  total: number;
+  offset: number;
+  limit: number;
+  order_by?: string;
+  desc?: boolean;
+};
+
 export type PrecheckResult = {
   check_id?: string;
   case_id: string;
@@ -124,6 +378,34 @@ export type PrecheckResult = {
   record_hash?: string;
 };
 
+export type CaseBundleKind = "all" | "evidence" | "reports";
+
+// 案件下载包（tar，末尾条目 SHA256SUMS 为逐文件校验和）
+export type CaseBundle = {
+  case_id: string;
+  kind: CaseBundleKind;
+  fingerprint: string;
+  sha256: string;
+  size_bytes: number;
+  file_count: number;
+  warnings?: string[];
+  mod_time: number;
+  cached: boolean;
+};
+
+export type ScanRun = {
+  run_id: string;
+  case_id: string;
+  device_id?: string;
+  scan_type: string;
+  operator?: string;
+  /** 规范化扫描参数（profile/privacy_mode/snapshot_compression/host/mobile 等），原样保存 */
+  options: Record<string, any>;
+  options_sha256: string;
+  started_at: number;
+  created_at: number;
+};
+
 export type AuditLog = {
   event_id: string;
   case_id: string;
@@ -159,6 +441,37 @@ export type ReportContentResponse = {
   content_omitted_reason?: string;
 };
 
+// 同一设备标识在其他未结案件中的登记记录（重复建案提示）
+export type DeviceCaseRef = {
+  case_id: string;
+  case_no?: string;
+  title?: string;
+  device_id: string;
+  device_name?: string;
+  os_type: string;
+  identifier: string;
+  last_seen_at: number;
+};
+
+// 外部案件管理系统推送回执
+export type CaseMgmtReceipt = {
+  adapter: string;
+  endpoint: string;
+  status_code: number;
+  external_id?: string;
+  payload_sha256: string;
+  hit_count: number;
+  report_count: number;
+  pushed_at: number;
+};
+
+export type CaseMgmtStatus = {
+  enabled: boolean;
+  adapter?: string;
+  endpoint?: string;
+  push_on_export?: string[];
+};
+
 export type ScanAllJob = {
   job_id: string;
   kind: string;
@@ -184,6 +497,7 @@ export type ScanAllJob = {
     report_path?: string;
     started_at: number;
     finished_at: number;
+    duplicate_cases?: DeviceCaseRef[];
   };
   host_error?: string;
   mobile?: {
@@ -191,6 +505,7 @@ export type ScanAllJob = {
     device_count: number;
     android_count: number;
     ios_count: number;
+    harmony_count?: number;
     artifact_count: number;
     hit_count: number;
     wallet_hits: number;
@@ -199,6 +514,7 @@ export type ScanAllJob = {
     report_path?: string;
     started_at: number;
     finished_at: number;
+    duplicate_cases?: DeviceCaseRef[];
   };
   mobile_error?: string;
   error?: string;
@@ -222,10 +538,95 @@ export type RulesListResponse = {
   rules_dir: string;
   wallet: RuleFileInfo[];
   exchange: RuleFileInfo[];
+  // 当前生效规则 + 全部案件的命中统计（加载失败时给出 rules_error）
+  rules?: {
+    wallet_version: string;
+    exchange_version: string;
+    wallet: RuleHitSummary[];
+    exchange: RuleHitSummary[];
+    dead_wallet: number;
+    dead_exchange: number;
+  };
+  rules_error?: string;
+};
+
+// 前置检查策略：profile（host_scan/offline_scan/mobile_scan）→ 检查码 + 失败处理（block/warn）
+export type PrecheckPolicyResponse = {
+  ok: boolean;
+  configured: boolean;
+  policy: {
+    version: string;
+    bundle_type: "precheck_policy";
+    profiles: {
+      name: "host_scan" | "offline_scan" | "mobile_scan";
+      checks: { code: string; on_fail: "block" | "warn"; note?: string }[] | null;
+    }[] | null;
+    // 按签发单位的授权工单号格式（agency="*" 为默认）
+    authorization_order_formats?: { agency: string; pattern: string; note?: string }[];
+  };
+  yaml: string;
+};
+
+// 案件交接记录（GET /api/cases/{id}/handover；GET /api/handovers?operator= 为交接班日志）
+export type CaseAssignment = {
+  assignment_id: string;
+  case_id: string;
+  from_operator?: string;
+  to_operator: string;
+  note?: string;
+  assigned_by?: string;
+  assigned_at: number;
+};
+
+// 执法授权文书（扫描版 PDF，GET/POST /api/cases/{id}/authorization）
+export type CaseAttachment = {
+  attachment_id: string;
+  case_id: string;
+  kind: "authorization_document";
+  original_name: string;
+  file_path: string;
+  sha256: string;
+  size_bytes: number;
+  mime_type: string;
+  authorization_order?: string;
+  agency?: string;
+  uploaded_by?: string;
+  uploaded_at: number;
+};
+
+// 规则浏览：单条规则的命中统计（dead = 启用但从未命中）
+export type RuleHitSummary = {
+  id: string;
+  name: string;
+  enabled: boolean;
+  aliases?: string[];
+  hit_count: number;
+  case_count: number;
+  last_hit_at?: number;
+  dead: boolean;
 };
 
 // 链上余额查询（当前仅 EVM 原生币余额）
-export type ChainEVMBalancesResponse = {
+// 链上余额查询：单地址失败原因（重试/切换备用端点后仍失败）
+export type ChainAddressError = {
+  address: string;
+  error: string;
+  attempts: number;
+  tried_endpoints?: string[];
+  skipped_endpoints?: string[]; // 因熔断被跳过的端点
+};
+
+// 链上余额查询：部分结果字段（允许部分地址失败）
+export type ChainPartialFields = {
+  partial?: boolean;
+  errors?: Record<string, ChainAddressError>; // address -> error
+  endpoints?: Record<string, string>; // address -> 实际使用的端点
+  breakers?: Record<string, "closed" | "open" | "half_open">; // endpoint -> 熔断状态
+  error?: string; // 全部失败时返回
+  code?: string;
+};
+
+export type ChainEVMBalancesResponse = ChainPartialFields & {
   ok: boolean;
   chain: "evm";
   rpc_url: string;
@@ -236,7 +637,23 @@ export type ChainEVMBalancesResponse = {
 };
 
 // 链上余额查询：EVM ERC20（eth_call balanceOf）
-export type ChainEVMERC20BalancesResponse = {
+// 预置代币清单
+export type ChainName = "ethereum" | "bsc" | "polygon" | "tron";
+
+export type TokenPreset = {
+  chain: ChainName;
+  symbol: string;
+  name: string;
+  contract: string; // EVM 链为 0x 地址；TRON 为 base58 地址
+  decimals: number;
+};
+
+export type ChainTokensResponse = {
+  version: string;
+  tokens: TokenPreset[];
+};
+
+export type ChainEVMERC20BalancesResponse = ChainPartialFields & {
   ok: boolean;
   chain: "evm";
   token_type: "erc20";
@@ -244,13 +661,55 @@ export type ChainEVMERC20BalancesResponse = {
   symbol: string;
   contract: string;
   decimals: number;
+  network?: ChainName; // 指定 chain 时返回
+  token_registry_version?: string; // 使用预置清单时返回
+  token_preset?: TokenPreset;
   balances: Record<string, Record<string, string>>; // address -> { USDT_RAW, USDT, ... }
   warnings?: string[];
   addr_count: number;
 };
 
+// 链上查询提供方注册表（内置 kind + serve --chain-providers 配置文件新增的 kind）
+export type ChainProviderInfo = {
+  name: string; // 即 kind，例如 evm_native / bsc_native / ltc
+  driver: "evm" | "erc20" | "nft" | "btc";
+  chain?: string;
+  endpoint?: string;
+  fallback_endpoints?: string[];
+  symbol?: string;
+  description?: string;
+  history: boolean; // 是否支持地址交易历史
+};
+
+export type ChainProvidersResponse = {
+  providers: ChainProviderInfo[];
+};
+
+export type ChainTransfer = {
+  txid: string;
+  block_height?: number;
+  block_time?: number;
+  confirmed: boolean;
+  delta: string; // 最小单位净变化（转出为负）
+  amount: string;
+  fee?: number;
+};
+
+export type ChainHistoryResponse = {
+  ok: boolean;
+  kind: string;
+  result: {
+    address: string;
+    symbol: string;
+    endpoint: string;
+    transfers: ChainTransfer[];
+    query: Record<string, unknown>;
+    warnings?: string[];
+  };
+};
+
 // 链上余额查询：BTC（HTTP API）
-export type ChainBTCBalancesResponse = {
+export type ChainBTCBalancesResponse = ChainPartialFields & {
   ok: boolean;
   chain: "btc";
   base_url: string;
@@ -310,6 +769,18 @@ export type CaseAuditVerifyResponse = {
 };
 
 // 案件链上余额查询（查询并落库为证据 + token_balance 命中）
+export type NFTHolding = {
+  contract: string;
+  standard: string; // erc721 | erc1155
+  name?: string;
+  symbol?: string;
+  balance: string;
+  token_ids?: string[];
+  enumerable: boolean;
+  truncated?: boolean;
+  amounts?: Record<string, string>; // erc1155: token_id -> amount
+};
+
 export type CaseChainBalancePersistResponse = {
   ok: boolean;
   case_id: string;
@@ -320,6 +791,216 @@ export type CaseChainBalancePersistResponse = {
   sha256: string;
   size_bytes: number;
   balances: Record<string, Record<string, string>>;
+  holdings?: Record<string, NFTHolding[]>; // kind=evm_nft 时返回
+  errors?: Record<string, ChainAddressError>;
+  partial?: boolean;
+  hit_ids: string[];
+  warnings?: string[];
+};
+
+// 报告对比（A 为基准，B 为复查）
+export type ReportDiffSide = {
+  report_id: string;
+  file_path: string;
+  sha256: string;
+  generated_at: number;
+  privacy_mode?: string;
+  hit_count: number;
+  artifact_count: number;
+};
+
+export type ReportDiffHit = {
+  hit_id: string;
+  hit_type: string;
+  rule_id: string;
+  rule_name?: string;
+  matched_value: string;
+  confidence: number;
+  verdict: string;
+};
+
+export type ReportDiffBalanceChange = {
+  hit_type: string;
+  rule_id: string;
+  matched_value: string;
+  before_hit_id: string;
+  after_hit_id: string;
+  before: Record<string, unknown>;
+  after: Record<string, unknown>;
+};
+
+export type ReportDiffArtifact = {
+  artifact_id: string;
+  artifact_type: string;
+  source_ref: string;
+  sha256: string;
+  collected_at: number;
+};
+
+export type CaseReportDiff = {
+  case_id: string;
+  a: ReportDiffSide;
+  b: ReportDiffSide;
+  summary: {
+    new_hits: number;
+    removed_hits: number;
+    changed_balances: number;
+    new_artifacts: number;
+    removed_artifacts: number;
+  };
+  new_hits: ReportDiffHit[];
+  removed_hits: ReportDiffHit[];
+  changed_balances: ReportDiffBalanceChange[];
+  new_artifacts: ReportDiffArtifact[];
+  removed_artifacts: ReportDiffArtifact[];
+  warnings: string[];
+};
+
+// 对外披露遮盖标记
+export type Redaction = {
+  redaction_id: string;
+  case_id: string;
+  target_type: "artifact" | "hit";
+  target_id: string;
+  pointer: string; // JSON Pointer；空串表示整条证据/命中
+  reason?: string;
+  operator?: string;
+  created_at: number;
+};
+
+// 分析评论（只追加；parent_id 指向主题首条评论，回复只有一层）
+export type Comment = {
+  comment_id: string;
+  case_id: string;
+  target_type: "artifact" | "hit";
+  target_id: string;
+  parent_id?: string;
+  author: string;
+  text: string;
+  created_at: number;
+};
+
+// 案件关注词（命中类型 watchlist_match，matched_value 为关注词原文）
+export type WatchlistTerm = {
+  term_id: string;
+  case_id: string;
+  term: string;
+  term_type: "keyword" | "alias" | "address" | "phone";
+  note?: string;
+  operator?: string;
+  created_at: number;
+};
+
+// 多设备关联分析（落库为 analysis 证据；PDF 报告中独立成节）
+export type CorrelationSighting = {
+  device_id: string;
+  first_seen_at?: number;
+  last_seen_at?: number;
   hit_ids: string[];
+};
+
+export type CorrelationSharedItem = {
+  kind: "exchange" | "wallet" | "address";
+  key: string;
+  label?: string;
+  values: string[];
+  devices: CorrelationSighting[];
+  overlap: boolean; // 各设备出现时间段存在共同交集
+  overlap_start?: number;
+  overlap_end?: number;
+};
+
+export type DeviceCorrelation = {
+  analysis: "device_correlation";
+  version: string;
+  case_id: string;
+  generated_at: number;
+  artifact_id?: string;
+  devices: {
+    device_id: string;
+    device_name?: string;
+    os_type: string;
+    hit_count: number;
+    first_seen_at?: number;
+    last_seen_at?: number;
+  }[];
+  shared_exchanges: CorrelationSharedItem[];
+  shared_wallets: CorrelationSharedItem[];
+  shared_addresses: CorrelationSharedItem[];
+  window_overlaps: { device_a: string; device_b: string; start: number; end: number; seconds: number }[];
+};
+
+// 钓鱼登录页检测（形近/拼写仿冒交易所域名的 TLS 证书比对；证书不属于交易所时输出 phishing_suspected 命中）
+export type PhishingCert = {
+  host: string;
+  subject_cn: string;
+  organizations?: string[];
+  issuer: string;
+  dns_names?: string[];
+  not_before: number;
+  not_after: number;
+  sha256: string;
+  trusted: boolean;
+  verify_error?: string;
+};
+
+export type PhishingCheck = {
+  source_hit_id: string;
+  device_id: string;
+  exchange_id: string;
+  domain: string;
+  lookalike_of: string;
+  cert_status: "matched" | "mismatch" | "unavailable";
+  matched_by?: string;
+  cert?: PhishingCert;
+  cert_error?: string;
+  phishing_hit_id?: string;
+  skipped?: string;
+};
+
+export type PhishingCheckResult = {
+  case_id: string;
+  checked: number;
+  flagged: number;
+  checks: PhishingCheck[];
+};
+
+// 导出格式（exporter 注册表）
+export type ExportKind = {
+  kind: string; // 例如 forensic-zip / forensic-pdf / disclosure-zip
+  description: string;
+};
+
+export type ExportResponse = {
+  ok: boolean;
+  kind: string;
+  case_id: string;
+  report_id: string;
   warnings?: string[];
+  report: ReportInfo | null;
+  // {file_key}_path / {file_key}_sha256 以及格式特有的统计字段
+  [key: string]: unknown;
+};
+
+// 移动设备实时监测（serve --monitor）
+export type MonitoredDevice = {
+  os: "android" | "ios";
+  identifier: string;
+  state: string; // android: device/unauthorized/offline...；ios: paired/unpaired
+  authorized: boolean;
+  auth_note?: string;
+};
+
+export type DeviceSnapshot = {
+  devices: MonitoredDevice[];
+  warnings: string[];
+  polled_at: number;
+  interval_ms: number;
+};
+
+export type DeviceEvent = {
+  type: "device_connected" | "device_disconnected" | "authorization_changed";
+  device: MonitoredDevice;
+  prev_state?: string;
+  at: number;
 };
@@ -0,0 +1,117 @@
+import { useEffect, useState } from "react";
+import { api } from "../api/client";
+import type { Comment } from "../api/types";
+
+function formatTime(ts: number) {
+  if (!ts) return "-";
+  return new Date(ts * 1000).toLocaleString("zh-CN", { hour12: false });
+}
+
+// 证据/命中下的分析评论：主题 + 一层回复，只追加（写入同时记审计）。
+export function CommentThread(props: {
+  caseId: string;
+  targetType: Comment["target_type"];
+  targetId: string;
+  author: string;
+}) {
+  const { caseId, targetType, targetId, author } = props;
+  const [rows, setRows] = useState<Comment[]>([]);
+  const [text, setText] = useState<string>("");
+  const [replyTo, setReplyTo] = useState<string>("");
+  const [saving, setSaving] = useState(false);
+  const [msg, setMsg] = useState<string>("");
+
+  const load = async () => {
+    const res = await api.listComments(caseId, { target_type: targetType, target_id: targetId });
+    setRows(res.comments || []);
+  };
+
+  useEffect(() => {
+    setText("");
+    setReplyTo("");
+    setMsg("");
+    (async () => {
+      try {
+        await load();
+      } catch {
+        setRows([]);
+      }
+    })();
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [caseId, targetType, targetId]);
+
+  const submit = async () => {
+    if (!text.trim()) return;
+    setSaving(true);
+    setMsg("");
+    try {
+      await api.addComment(
+        caseId,
+        replyTo
+          ? { parent_id: replyTo, author, text }
+          : { target_type: targetType, target_id: targetId, author, text }
+      );
+      setText("");
+      setReplyTo("");
+      await load();
+    } catch (e: any) {
+      setMsg(`ERROR: ${e?.message || String(e)}`);
+    } finally {
+      setSaving(false);
+    }
+  };
+
+  const threads = rows.filter((c) => !c.parent_id);
+  const repliesOf = (id: string) => rows.filter((c) => c.parent_id === id);
+
+  const renderOne = (c: Comment) => (
+    <div className="text-xs">
+      <span className="text-[#4fc3f7]">{c.author}</span>
+      <span className="text-[#7a7f8a] ml-2">{formatTime(c.created_at)}</span>
+      <div className="text-[#e8e8e8] whitespace-pre-wrap break-all">{c.text}</div>
+    </div>
+  );
+
+  return (
+    <div className="bg-[#252931] border border-[#3a3f4a] rounded p-3 space-y-3">
+      {threads.length === 0 ? (
+        <div className="text-xs text-[#7a7f8a]">暂无评论</div>
+      ) : (
+        threads.map((t) => (
+          <div key={t.comment_id} className="space-y-1">
+            {renderOne(t)}
+            {repliesOf(t.comment_id).map((r) => (
+              <div key={r.comment_id} className="ml-4 pl-2 border-l border-[#3a3f4a]">
+                {renderOne(r)}
+              </div>
+            ))}
+            <button
+              onClick={() => setReplyTo(replyTo === t.comment_id ? "" : t.comment_id)}
+              className="text-[#7a7f8a] hover:text-[#4fc3f7] text-xs"
+            >
+              {replyTo === t.comment_id ? "取消回复" : "回复"}
+            </button>
+          </div>
+        ))
+      )}
+
+      <textarea
+        rows={2}
+        value={text}
+        onChange={(e) => setText(e.target.value)}
+        placeholder={replyTo ? "回复该主题..." : "添加评论..."}
+        className="w-full bg-[#1e2127] border border-[#3a3f4a] px-2 py-1 text-xs text-[#e8e8e8] rounded focus:outline-none focus:border-[#4fc3f7] resize-none"
+      />
+      <div className="flex items-center gap-3">
+        <button
+          disabled={saving || !text.trim()}
+          onClick={submit}
+          className="bg-[#2b5278] hover:bg-[#365f8a] disabled:opacity-50 border border-[#4fc3f7] text-[#4fc3f7] px-4 py-1.5 text-xs rounded transition-colors"
+        >
+          {saving ? "提交中..." : replyTo ? "提交回复" : "提交评论"}
+        </button>
+        {msg ? <span className="text-xs text-[#ff6b6b]">{msg}</span> : null}
+      </div>
+    </div>
+  );
+}
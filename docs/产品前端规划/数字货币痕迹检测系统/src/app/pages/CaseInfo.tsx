@@ -1,6 +1,6 @@
 import { useEffect, useMemo, useState } from "react";
 import { api } from "../api/client";
-import type { AuditLog } from "../api/types";
+import type { AuditLog, ChecklistItem } from "../api/types";
 import { useApp } from "../state/AppContext";
 
 function formatTime(ts: number) {
@@ -22,11 +22,14 @@ export default function CaseInfo() {
   const [note, setNote] = useState("");
   const [audits, setAudits] = useState<AuditLog[]>([]);
   const [saving, setSaving] = useState(false);
+  const [checklist, setChecklist] = useState<ChecklistItem[]>([]);
+  const [checklistMsg, setChecklistMsg] = useState("");
 
   const statusLabel = useMemo(() => {
     const s = selectedCaseOverview?.status || "";
     if (!s) return "未知";
     if (s === "open") return "进行中";
+    if (s === "closed") return "已结案";
     return s;
   }, [selectedCaseOverview?.status]);
 
@@ -51,6 +54,53 @@ export default function CaseInfo() {
     })();
   }, [selectedCaseId]);
 
+  const loadChecklist = async () => {
+    if (!selectedCaseId) {
+      setChecklist([]);
+      return;
+    }
+    try {
+      const res = await api.getCaseChecklist(selectedCaseId);
+      setChecklist(res.checklist || []);
+    } catch {
+      // ignore：不阻断页面
+    }
+  };
+
+  useEffect(() => {
+    setChecklistMsg("");
+    loadChecklist();
+  }, [selectedCaseId]);
+
+  const onToggleItem = async (it: ChecklistItem) => {
+    if (!selectedCaseId) return;
+    let note: string | undefined;
+    let status: ChecklistItem["status"] = it.status === "pending" ? "done" : "pending";
+    if (status === "done" && it.required && window.confirm("该项是否不适用于本案？（确定=标记不适用，取消=标记完成）")) {
+      note = window.prompt("请填写不适用说明") || "";
+      if (!note.trim()) return;
+      status = "not_applicable";
+    }
+    try {
+      await api.updateChecklistItem(selectedCaseId, it.item_id, { status, note, responsible: operator, operator });
+      setChecklistMsg("");
+      await loadChecklist();
+    } catch (e: any) {
+      setChecklistMsg(e?.message || String(e));
+    }
+  };
+
+  const onCloseCase = async () => {
+    if (!selectedCaseId) return;
+    try {
+      await api.closeCase(selectedCaseId, { profile: "external", operator });
+      setChecklistMsg("已结案");
+    } catch (e: any) {
+      // 409 ERR_CHECKLIST_INCOMPLETE：清单未完成
+      setChecklistMsg(e?.message || String(e));
+    }
+  };
+
   const onCreateNew = async () => {
     setSaving(true);
     try {
@@ -203,6 +253,52 @@ export default function CaseInfo() {
           </div>
         </div>
       </div>
+
+      {/* 案件侦查清单：external 结案前必需项须全部完成 */}
+      <div className="mt-6 bg-[#1e2127]/80 backdrop-blur-sm border border-[#3a3f4a] rounded p-4 shadow-lg">
+        <h3 className="text-sm font-bold text-[#4fc3f7] mb-4 border-b border-[#3a3f4a] pb-2">
+          案件侦查清单
+        </h3>
+        <div className="space-y-2">
+          {checklist.length === 0 ? (
+            <div className="text-xs text-[#7a7f8a]">暂无清单</div>
+          ) : (
+            checklist.map((it) => (
+              <div key={it.item_id} className="flex items-center gap-3 text-xs">
+                <button
+                  onClick={() => onToggleItem(it)}
+                  className={
+                    it.status === "pending"
+                      ? "w-16 border border-[#5a5f6a] text-[#b8bcc4] rounded"
+                      : "w-16 border border-[#66bb6a] text-[#66bb6a] rounded"
+                  }
+                >
+                  {it.status === "done" ? "已完成" : it.status === "not_applicable" ? "不适用" : "待完成"}
+                </button>
+                <span className="text-[#e8e8e8]">
+                  {it.label}
+                  {it.required ? <span className="text-[#ef5350]"> *</span> : null}
+                </span>
+                <span className="text-[#7a7f8a]">
+                  {it.responsible ? `负责人 ${it.responsible}` : ""}
+                  {it.completed_by ? ` · ${it.completed_by} ${formatTime(it.completed_at || 0)}` : ""}
+                  {it.note ? ` · ${it.note}` : ""}
+                </span>
+              </div>
+            ))
+          )}
+        </div>
+        <div className="flex items-center gap-3 mt-4 pt-4 border-t border-[#3a3f4a]">
+          <button
+            disabled={!selectedCaseId || selectedCaseOverview?.status === "closed"}
+            onClick={onCloseCase}
+            className="bg-[#1e2127] hover:bg-[#252931] disabled:opacity-50 border border-[#ffa726] text-[#ffa726] px-4 py-1.5 text-xs rounded transition-colors"
+          >
+            [结案（对外）]
+          </button>
+          <span className="text-xs text-[#7a7f8a]">{checklistMsg}</span>
+        </div>
+      </div>
     </div>
   );
 }
@@ -40,21 +40,30 @@ function statusTextColor(s: NodeStatus) {
 }
 
 export default function DataCollection() {
-  const { currentJob, startScanAll } = useApp();
+  const { currentJob, startScanAll, selectCase } = useApp();
 
   const [mode, setMode] = useState<"quick" | "full" | "custom">("full");
   const [enableHost, setEnableHost] = useState(true);
   const [enableAndroid, setEnableAndroid] = useState(true);
   const [enableIOS, setEnableIOS] = useState(false);
+  const [enableHarmony, setEnableHarmony] = useState(false);
   const [authOrder, setAuthOrder] = useState("");
   const [authBasis, setAuthBasis] = useState("");
   const [note, setNote] = useState("");
+  // 采集预算：复制原始历史库 / iOS 完整备份前估算大小，超出预算的来源跳过（元数据照常采集）
+  const [maxMB, setMaxMB] = useState("");
+  const [copyHistoryDB, setCopyHistoryDB] = useState(true);
+  const [iosFullBackup, setIOSFullBackup] = useState(true);
 
   const running = currentJob?.status === "running";
 
   const walletHits =
     (currentJob?.host?.wallet_hits || 0) + (currentJob?.mobile?.wallet_hits || 0);
   const exchangeHits = currentJob?.host?.exchange_hits || 0;
+  const duplicateCases = [
+    ...(currentJob?.host?.duplicate_cases || []),
+    ...(currentJob?.mobile?.duplicate_cases || []),
+  ];
 
   const collectionStages = useMemo(() => {
     const stage = currentJob?.stage || "";
@@ -75,7 +84,7 @@ export default function DataCollection() {
               ? "complete"
               : "waiting";
 
-    const mobileEnabled = enableAndroid || enableIOS;
+    const mobileEnabled = enableAndroid || enableIOS || enableHarmony;
     const mobileStatus: NodeStatus = !mobileEnabled
       ? "skipped"
       : mobileFailed
@@ -103,8 +112,12 @@ export default function DataCollection() {
         name: "iOS 设备",
         children: [{ name: "备份接入（骨架）", status: enableIOS ? mobileStatus : "skipped" }],
       },
+      {
+        name: "HarmonyOS 设备",
+        children: [{ name: "应用清单（hdc）", status: enableHarmony ? mobileStatus : "skipped" }],
+      },
     ];
-  }, [currentJob, enableHost, enableAndroid, enableIOS]);
+  }, [currentJob, enableHost, enableAndroid, enableIOS, enableHarmony]);
 
   const logs = useMemo(() => {
     const rows = currentJob?.logs || [];
@@ -192,6 +205,50 @@ export default function DataCollection() {
                   />
                   <span className="text-xs text-[#b8bcc4]">iOS</span>
                 </label>
+                <label className="flex items-center gap-1.5 cursor-pointer">
+                  <input
+                    type="checkbox"
+                    checked={enableHarmony}
+                    onChange={(e) => setEnableHarmony(e.target.checked)}
+                    className="w-3 h-3"
+                  />
+                  <span className="text-xs text-[#b8bcc4]">HarmonyOS</span>
+                </label>
+              </div>
+            </div>
+
+            <div className="flex items-center gap-4">
+              <label className="text-[#b8bcc4] text-xs w-20">重型来源：</label>
+              <div className="flex items-center gap-4">
+                <label className="flex items-center gap-1.5 cursor-pointer">
+                  <input
+                    type="checkbox"
+                    checked={copyHistoryDB}
+                    onChange={(e) => setCopyHistoryDB(e.target.checked)}
+                    className="w-3 h-3"
+                  />
+                  <span className="text-xs text-[#b8bcc4]">原始历史库副本</span>
+                </label>
+                <label className="flex items-center gap-1.5 cursor-pointer">
+                  <input
+                    type="checkbox"
+                    checked={iosFullBackup}
+                    onChange={(e) => setIOSFullBackup(e.target.checked)}
+                    className="w-3 h-3"
+                  />
+                  <span className="text-xs text-[#b8bcc4]">iOS 完整备份</span>
+                </label>
+                <label className="flex items-center gap-1.5">
+                  <span className="text-xs text-[#b8bcc4]">预算(MB)</span>
+                  <input
+                    type="number"
+                    min={0}
+                    value={maxMB}
+                    onChange={(e) => setMaxMB(e.target.value)}
+                    placeholder="不限"
+                    className="w-20 bg-[#252931] border border-[#3a3f4a] px-2 py-0.5 text-xs text-[#e8e8e8] rounded focus:outline-none focus:border-[#4fc3f7]"
+                  />
+                </label>
               </div>
             </div>
           </div>
@@ -240,10 +297,13 @@ export default function DataCollection() {
               profile: "internal",
               auth_order: authOrder,
               auth_basis: authBasis,
-              ios_full_backup: true,
+              ios_full_backup: iosFullBackup,
+              max_bytes: Math.max(0, Math.floor(Number(maxMB) || 0)) * 1024 * 1024,
+              skip_history_db: !copyHistoryDB,
               enable_host: enableHost,
               enable_android: enableAndroid,
               enable_ios: enableIOS,
+              enable_harmony: enableHarmony,
             })
           }
           className="mt-4 bg-[#2b5278] hover:bg-[#365f8a] disabled:opacity-50 border border-[#4fc3f7] text-[#4fc3f7] px-6 py-2 text-xs rounded transition-colors"
@@ -347,6 +407,31 @@ export default function DataCollection() {
             </div>
           </div>
 
+          {/* 重复建案提示：同一设备已登记在其他未结案件中 */}
+          {duplicateCases.length > 0 ? (
+            <div className="bg-[#3d2f1f] border border-[#ffa726] rounded p-3 mb-4">
+              <h4 className="text-xs font-bold text-[#ffa726] mb-2">设备已登记在其他未结案件中</h4>
+              <div className="space-y-1">
+                {duplicateCases.map((d) => (
+                  <div key={d.case_id + d.device_id} className="flex items-center justify-between text-xs">
+                    <span className="text-[#e8e8e8]">
+                      {d.device_name || d.identifier} → {d.case_no || d.title || d.case_id}
+                    </span>
+                    <button
+                      onClick={() => selectCase(d.case_id)}
+                      className="text-[#4fc3f7] hover:underline"
+                    >
+                      切换到该案件
+                    </button>
+                  </div>
+                ))}
+              </div>
+              <div className="text-[10px] text-[#b8bcc4] mt-2">
+                本次采集已写入当前案件；如属同一案件，请切换后在已有案件中继续采集，避免并行记录。
+              </div>
+            </div>
+          ) : null}
+
           {/* 运行日志 */}
           <div className="bg-[#252931] border border-[#3a3f4a] rounded p-3">
             <h4 className="text-xs font-bold text-[#b8bcc4] mb-2">运行日志</h4>
@@ -62,6 +62,10 @@ export default function DeviceConnection() {
     () => devices.filter((d) => d.os_type === "android"),
     [devices]
   );
+  const harmonyDevices = useMemo(
+    () => devices.filter((d) => d.os_type === "harmonyos"),
+    [devices]
+  );
   const iosDevices = useMemo(
     () => devices.filter((d) => d.os_type === "ios"),
     [devices]
@@ -94,6 +98,9 @@ export default function DeviceConnection() {
           <span className="text-[#b8bcc4]">
             Android：<span className="text-[#4fc3f7]">{androidDevices.length} 台</span>
           </span>
+          <span className="text-[#b8bcc4]">
+            HarmonyOS：<span className="text-[#4fc3f7]">{harmonyDevices.length} 台</span>
+          </span>
           <span className="text-[#b8bcc4]">
             iOS：
             <span className={iosDevices.length > 0 ? "text-[#4fc3f7]" : "text-[#7a7f8a]"}>
@@ -174,12 +181,12 @@ export default function DeviceConnection() {
             移动设备列表
           </h3>
           
-          {/* Android 设备 */}
-          {androidDevices.map((d) => (
+          {/* Android / HarmonyOS 设备（HarmonyOS 通过 hdc 采集） */}
+          {[...androidDevices, ...harmonyDevices].map((d) => (
             <div key={d.device_id} className="bg-[#252931] border border-[#3a3f4a] rounded p-3 mb-3">
               <div className="flex items-center justify-between mb-2">
                 <span className="text-[#4fc3f7] text-xs font-bold">
-                  {d.device_name || "Android"}
+                  {d.device_name || (d.os_type === "harmonyos" ? "HarmonyOS" : "Android")}
                 </span>
                 <span className={d.authorized ? "text-green-500 text-xs" : "text-[#ffa726] text-xs"}>
                   {d.authorized ? "已授权" : "未授权"}
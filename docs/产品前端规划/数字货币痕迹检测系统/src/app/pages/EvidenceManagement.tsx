@@ -1,8 +1,9 @@
 import { useEffect, useMemo, useState } from "react";
 import { ChevronDown, ChevronRight, Download, File, ShieldCheck } from "lucide-react";
-import { api } from "../api/client";
+import { api, downloadFile } from "../api/client";
 import type { ArtifactInfo, CaseArtifactVerifyResponse, CaseDevice } from "../api/types";
 import { useApp } from "../state/AppContext";
+import { CommentThread } from "../components/CommentThread";
 
 function formatTime(ts: number) {
   if (!ts) return "-";
@@ -21,7 +22,9 @@ function formatBytes(bytes: number) {
 }
 
 export default function EvidenceManagement() {
-  const { selectedCaseId, operator } = useApp();
+  const { selectedCaseId, operator, meta } = useApp();
+  const partialPrivacy = meta?.privacy?.mode === "partial";
+  const [downloadMsg, setDownloadMsg] = useState<string>("");
   const [devices, setDevices] = useState<CaseDevice[]>([]);
   const [artifacts, setArtifacts] = useState<ArtifactInfo[]>([]);
   const [expandedDevices, setExpandedDevices] = useState<string[]>([]);
@@ -302,6 +305,16 @@ export default function EvidenceManagement() {
                   href={`/api/artifacts/${selectedArtifact.artifact_id}/download`}
                   target="_blank"
                   rel="noreferrer"
+                  onClick={(e) => {
+                    if (!partialPrivacy) return;
+                    // 部分隐私模式下下载需携带 unmask 令牌（在“报告生成”页填写）
+                    e.preventDefault();
+                    setDownloadMsg("");
+                    downloadFile(
+                      `/api/artifacts/${selectedArtifact.artifact_id}/download`,
+                      `artifact_${selectedArtifact.artifact_id}`
+                    ).catch((err: any) => setDownloadMsg(`ERROR: ${err?.message || String(err)}`));
+                  }}
                 >
                   <span className="inline-flex items-center gap-1">
                     <Download className="w-4 h-4" />
@@ -315,6 +328,17 @@ export default function EvidenceManagement() {
                   {contentLoading ? "加载中..." : "刷新内容"}
                 </button>
               </div>
+              {downloadMsg ? <div className="mt-2 text-xs text-[#ff6b6b]">{downloadMsg}</div> : null}
+
+              <div className="mt-4">
+                <div className="text-xs text-[#b8bcc4] mb-2">分析评论</div>
+                <CommentThread
+                  caseId={selectedArtifact.case_id}
+                  targetType="artifact"
+                  targetId={selectedArtifact.artifact_id}
+                  author={operator}
+                />
+              </div>
             </>
           )}
         </div>
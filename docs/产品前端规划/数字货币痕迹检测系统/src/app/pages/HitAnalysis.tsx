@@ -1,7 +1,8 @@
 import { useEffect, useMemo, useState } from "react";
-import { api } from "../api/client";
+import { api, downloadFile } from "../api/client";
 import type { CaseDevice, HitDetail } from "../api/types";
 import { useApp } from "../state/AppContext";
+import { CommentThread } from "../components/CommentThread";
 
 function toPercent(conf: number) {
   const v = Math.round((conf || 0) * 100);
@@ -21,6 +22,8 @@ function typeLabel(hitType: string) {
       return "钱包";
     case "exchange_visited":
       return "交易所";
+    case "phishing_suspected":
+      return "疑似钓鱼";
     case "wallet_address":
       return "地址";
     case "token_balance":
@@ -33,6 +36,7 @@ function typeLabel(hitType: string) {
 function riskLevel(h: HitDetail): "high" | "medium" | "low" {
   const p = toPercent(h.confidence);
   if (h.hit_type === "wallet_installed" && p >= 70) return "high";
+  if (h.hit_type === "phishing_suspected" && p >= 70) return "high";
   if (h.hit_type === "exchange_visited" && p >= 70) return "medium";
   if (p >= 70) return "medium";
   return "low";
@@ -55,7 +59,9 @@ function parseAddresses(text: string): string[] {
 }
 
 export default function HitAnalysis() {
-  const { selectedCaseId, operator } = useApp();
+  const { selectedCaseId, operator, meta } = useApp();
+  const partialPrivacy = meta?.privacy?.mode === "partial";
+  const [downloadMsg, setDownloadMsg] = useState<string>("");
   const [hits, setHits] = useState<HitDetail[]>([]);
   const [devices, setDevices] = useState<CaseDevice[]>([]);
   const [selectedHitId, setSelectedHitId] = useState<string>("");
@@ -346,14 +352,34 @@ export default function HitAnalysis() {
                           href={`/api/artifacts/${id}/download`}
                           target="_blank"
                           rel="noreferrer"
+                          onClick={(e) => {
+                            if (!partialPrivacy) return;
+                            // 部分隐私模式下下载需携带 unmask 令牌（在“报告生成”页填写）
+                            e.preventDefault();
+                            setDownloadMsg("");
+                            downloadFile(`/api/artifacts/${id}/download`, `artifact_${id}`).catch((err: any) =>
+                              setDownloadMsg(`ERROR: ${err?.message || String(err)}`)
+                            );
+                          }}
                         >
                           {id}
                         </a>
                       </div>
                     ))
                   )}
+                  {downloadMsg ? <div className="text-xs text-[#ff6b6b]">{downloadMsg}</div> : null}
                 </div>
               </div>
+
+              <div>
+                <div className="text-xs text-[#b8bcc4] mb-2">分析评论</div>
+                <CommentThread
+                  caseId={selectedHit.case_id}
+                  targetType="hit"
+                  targetId={selectedHit.hit_id}
+                  author={operator}
+                />
+              </div>
             </div>
           )}
         </div>
@@ -1,7 +1,7 @@
 import { useEffect, useMemo, useState } from "react";
 import { Download, FileText } from "lucide-react";
-import { api } from "../api/client";
-import type { ReportInfo } from "../api/types";
+import { api, downloadFile, getUnmaskToken, setUnmaskToken } from "../api/client";
+import type { CaseMgmtStatus, ReportInfo } from "../api/types";
 import { useApp } from "../state/AppContext";
 
 function formatTime(ts: number) {
@@ -10,7 +10,10 @@ function formatTime(ts: number) {
 }
 
 export default function ReportGeneration() {
-  const { selectedCaseId, operator } = useApp();
+  const { selectedCaseId, operator, meta } = useApp();
+  const partialPrivacy = meta?.privacy?.mode === "partial";
+  const [unmaskToken, setUnmaskTokenState] = useState<string>(getUnmaskToken());
+  const [downloadMsg, setDownloadMsg] = useState<string>("");
   const [reports, setReports] = useState<ReportInfo[]>([]);
   const [selectedReportId, setSelectedReportId] = useState<string>("");
   const [content, setContent] = useState<string>("");
@@ -19,6 +22,10 @@ export default function ReportGeneration() {
   const [exportZipMsg, setExportZipMsg] = useState<string>("");
   const [exportingPdf, setExportingPdf] = useState(false);
   const [exportPdfMsg, setExportPdfMsg] = useState<string>("");
+  const [pdfComments, setPdfComments] = useState(false);
+  const [cms, setCms] = useState<CaseMgmtStatus | null>(null);
+  const [pushing, setPushing] = useState(false);
+  const [pushMsg, setPushMsg] = useState<string>("");
 
   const loadReports = async (caseId: string) => {
     const res = await api.listCaseReports(caseId);
@@ -50,6 +57,18 @@ export default function ReportGeneration() {
     })();
   }, [selectedCaseId]);
 
+  useEffect(() => {
+    setPushMsg("");
+    if (!selectedCaseId) {
+      setCms(null);
+      return;
+    }
+    api
+      .getCaseManagement(selectedCaseId)
+      .then(setCms)
+      .catch(() => setCms(null));
+  }, [selectedCaseId]);
+
   const selectedReport = useMemo(() => {
     return reports.find((r) => r.report_id === selectedReportId) || null;
   }, [reports, selectedReportId]);
@@ -112,6 +131,32 @@ export default function ReportGeneration() {
         </div>
       </div>
 
+      {/* 隐私模式 partial：unmask 令牌 */}
+      {partialPrivacy ? (
+        <div className="bg-[#1e2127]/80 backdrop-blur-sm border border-[#3a3f4a] rounded p-4 mb-6 shadow-lg">
+          <h3 className="text-sm font-bold text-[#4fc3f7] mb-4">解除脱敏（导出权限）</h3>
+          <div className="text-xs text-[#7a7f8a] mb-3">
+            当前为部分隐私模式：界面展示脱敏后的 URL/地址；导出与下载需填写 unmask 令牌，每次使用都会记入审计日志。令牌仅保存在当前标签页。
+          </div>
+          <div className="flex items-center gap-3">
+            <input
+              type="password"
+              value={unmaskToken}
+              onChange={(e) => setUnmaskTokenState(e.target.value)}
+              placeholder="unmask 令牌"
+              className="bg-[#14161a] border border-[#3a3f4a] text-[#e8e8e8] text-xs px-3 py-2 rounded w-72"
+            />
+            <button
+              onClick={() => setUnmaskToken(unmaskToken)}
+              className="bg-[#2b5278] hover:bg-[#365f8a] border border-[#4fc3f7] text-[#4fc3f7] px-4 py-2 text-xs rounded transition-colors"
+            >
+              [{unmaskToken ? "保存令牌" : "清除令牌"}]
+            </button>
+            {downloadMsg ? <span className="text-xs text-[#ff6b6b]">{downloadMsg}</span> : null}
+          </div>
+        </div>
+      ) : null}
+
       {/* 司法导出包 */}
       <div className="bg-[#1e2127]/80 backdrop-blur-sm border border-[#3a3f4a] rounded p-4 mb-6 shadow-lg">
         <h3 className="text-sm font-bold text-[#4fc3f7] mb-4">司法导出包（ZIP）</h3>
@@ -175,6 +220,7 @@ export default function ReportGeneration() {
                 await api.generateForensicPdf(selectedCaseId, {
                   operator,
                   note: "ui_generate_forensic_pdf",
+                  include_comments: pdfComments,
                 });
                 await loadReports(selectedCaseId);
                 setExportPdfMsg("已生成（请在“历史报告”列表中下载 forensic_pdf）");
@@ -188,6 +234,14 @@ export default function ReportGeneration() {
           >
             [{exportingPdf ? "生成中..." : "生成取证 PDF"}]
           </button>
+          <label className="inline-flex items-center gap-1 text-xs text-[#b8bcc4]">
+            <input
+              type="checkbox"
+              checked={pdfComments}
+              onChange={(e) => setPdfComments(e.target.checked)}
+            />
+            收录分析评论
+          </label>
           {exportPdfMsg ? (
             <div className="text-xs text-[#b8bcc4]">
               {exportPdfMsg.startsWith("ERROR") ? (
@@ -200,6 +254,57 @@ export default function ReportGeneration() {
         </div>
       </div>
 
+      {/* 案件管理系统推送 */}
+      {cms?.enabled ? (
+        <div className="bg-[#1e2127]/80 backdrop-blur-sm border border-[#3a3f4a] rounded p-4 mb-6 shadow-lg">
+          <h3 className="text-sm font-bold text-[#4fc3f7] mb-4">推送到案件管理系统</h3>
+
+          <div className="text-xs text-[#7a7f8a] mb-3">
+            推送内容：案件摘要 + 命中列表 + 报告哈希（字段按对接配置映射）。端点：
+            <span className="font-mono text-[#b8bcc4]">{cms.endpoint}</span>
+            {cms.push_on_export && cms.push_on_export.length > 0
+              ? `；导出 ${cms.push_on_export.join(" / ")} 完成后自动推送`
+              : ""}
+          </div>
+
+          <div className="flex items-center gap-3">
+            <button
+              disabled={!selectedCaseId || pushing}
+              onClick={async () => {
+                if (!selectedCaseId) return;
+                setPushMsg("");
+                setPushing(true);
+                try {
+                  const res = await api.pushCaseManagement(selectedCaseId, operator);
+                  const r = res.receipt;
+                  setPushMsg(
+                    `已推送：命中 ${r.hit_count} 条，报告 ${r.report_count} 份${
+                      r.external_id ? `，对方编号 ${r.external_id}` : ""
+                    }`
+                  );
+                } catch (e: any) {
+                  setPushMsg(`ERROR: ${e?.message || String(e)}`);
+                } finally {
+                  setPushing(false);
+                }
+              }}
+              className="bg-[#2b5278] hover:bg-[#365f8a] disabled:opacity-50 border border-[#4fc3f7] text-[#4fc3f7] px-6 py-2 text-xs rounded transition-colors"
+            >
+              [{pushing ? "推送中..." : "立即推送"}]
+            </button>
+            {pushMsg ? (
+              <div className="text-xs text-[#b8bcc4]">
+                {pushMsg.startsWith("ERROR") ? (
+                  <span className="text-[#ff6b6b]">{pushMsg}</span>
+                ) : (
+                  <span className="text-green-500">{pushMsg}</span>
+                )}
+              </div>
+            ) : null}
+          </div>
+        </div>
+      ) : null}
+
       {/* 历史报告 */}
       <div className="bg-[#1e2127]/80 backdrop-blur-sm border border-[#3a3f4a] rounded p-4 mb-6 shadow-lg">
         <h3 className="text-sm font-bold text-[#4fc3f7] mb-4">历史报告</h3>
@@ -243,7 +348,16 @@ export default function ReportGeneration() {
                         href={`/api/reports/${report.report_id}/download`}
                         target="_blank"
                         rel="noreferrer"
-                        onClick={(e) => e.stopPropagation()}
+                        onClick={(e) => {
+                          e.stopPropagation();
+                          if (!partialPrivacy) return;
+                          // 部分隐私模式下下载需携带 unmask 令牌
+                          e.preventDefault();
+                          setDownloadMsg("");
+                          downloadFile(`/api/reports/${report.report_id}/download`, `report_${report.report_id}`).catch(
+                            (err: any) => setDownloadMsg(`ERROR: ${err?.message || String(err)}`)
+                          );
+                        }}
                       >
                         <Download className="w-3.5 h-3.5" />
                         <span>下载</span>
@@ -35,9 +35,12 @@ type AppContextValue = {
     auth_order?: string;
     auth_basis?: string;
     ios_full_backup?: boolean;
+    max_bytes?: number;
+    skip_history_db?: boolean;
     enable_host?: boolean;
     enable_android?: boolean;
     enable_ios?: boolean;
+    enable_harmony?: boolean;
   }) => Promise<void>;
 };
 
@@ -158,16 +161,20 @@ export function AppProvider(props: { children: React.ReactNode }) {
     auth_order?: string;
     auth_basis?: string;
     ios_full_backup?: boolean;
+    max_bytes?: number;
+    skip_history_db?: boolean;
     enable_host?: boolean;
     enable_android?: boolean;
     enable_ios?: boolean;
+    enable_harmony?: boolean;
   }) => {
     setError(null);
 
-    // enable_mobile 由 Android/iOS 勾选推导：两者都关就认为不跑 mobile
+    // enable_mobile 由 Android/iOS/HarmonyOS 勾选推导：全部关闭就认为不跑 mobile
     const enable_android = payload.enable_android ?? true;
     const enable_ios = payload.enable_ios ?? true;
-    const enable_mobile = enable_android || enable_ios;
+    const enable_harmony = payload.enable_harmony ?? true;
+    const enable_mobile = enable_android || enable_ios || enable_harmony;
 
     const job = await api.startScanAll({
       operator,
@@ -177,10 +184,13 @@ export function AppProvider(props: { children: React.ReactNode }) {
       auth_order: payload.auth_order,
       auth_basis: payload.auth_basis,
       ios_full_backup: payload.ios_full_backup ?? true,
+      max_bytes: payload.max_bytes || undefined,
+      skip_history_db: payload.skip_history_db || undefined,
       enable_host: payload.enable_host ?? true,
       enable_mobile,
       enable_android,
       enable_ios,
+      enable_harmony,
       privacy_mode: "off",
     });
 
@@ -0,0 +1,133 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"runtime"
+	"sync"
+)
+
+// 权限不足导致的来源缺失记录
+//
+// 历史库、注册表等来源因权限不足（或被其他进程独占锁定）无法读取时，采集器会跳过该来源继续扫描；
+// 这里把被跳过的来源与原因留痕，由 hostscan 转为前置检查（skipped）与告警，使覆盖缺口在报告中可见。
+
+// 被拒绝来源的类别。
+const (
+	AccessCategoryBrowserDB    = "browser_db"
+	AccessCategoryRegistry     = "registry"
+	AccessCategoryRegistryHive = "registry_hive"
+	AccessCategoryDirectory    = "directory"
+)
+
+// 拒绝原因。
+const (
+	AccessReasonPermission = "permission_denied"
+	AccessReasonLocked     = "locked"
+)
+
+// AccessDenial 是一个因权限不足/被锁定而未能读取的来源。
+type AccessDenial struct {
+	Category string `json:"category"`
+	Path     string `json:"path"`
+	Reason   string `json:"reason"`
+	Error    string `json:"error"`
+	// Hint 是补救建议（如以管理员身份重新运行）。
+	Hint string `json:"hint"`
+}
+
+// AccessLog 收集一次扫描中被拒绝的来源（并发安全；nil 表示不记录）。
+type AccessLog struct {
+	mu    sync.Mutex
+	items []AccessDenial
+	seen  map[string]struct{}
+}
+
+func NewAccessLog() *AccessLog {
+	return &AccessLog{seen: map[string]struct{}{}}
+}
+
+// Record 在 err 属于权限不足/锁定时记录 path 并返回 true；其他错误返回 false。
+// 同一路径只记录一次（浏览记录解析与原始库快照会先后读取同一个库）。
+func (l *AccessLog) Record(category, path string, err error) bool {
+	reason := AccessDeniedReason(err)
+	if reason == "" {
+		return false
+	}
+	if l == nil {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[path]; ok {
+		return true
+	}
+	if l.seen == nil {
+		l.seen = map[string]struct{}{}
+	}
+	l.seen[path] = struct{}{}
+	l.items = append(l.items, AccessDenial{
+		Category: category,
+		Path:     path,
+		Reason:   reason,
+		Error:    err.Error(),
+		Hint:     accessHint(reason),
+	})
+	return true
+}
+
+// Items 返回已记录的来源（按记录顺序）。
+func (l *AccessLog) Items() []AccessDenial {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]AccessDenial(nil), l.items...)
+}
+
+// AccessDeniedReason 判断 err 是否为权限不足（permission_denied）或文件被独占锁定（locked，仅 Windows），否则返回空串。
+func AccessDeniedReason(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, fs.ErrPermission):
+		return AccessReasonPermission
+	case isLockViolation(err):
+		return AccessReasonLocked
+	default:
+		return ""
+	}
+}
+
+// accessHint 按平台给出补救建议。
+func accessHint(reason string) string {
+	if reason == AccessReasonLocked {
+		return "close the application holding the file (e.g. the browser) and rerun the scan"
+	}
+	switch runtime.GOOS {
+	case "windows":
+		return "rerun the scan from an elevated (Run as administrator) session"
+	case "darwin":
+		return "rerun with sudo and grant the inspector Full Disk Access in System Settings > Privacy & Security"
+	default:
+		return "rerun the scan with elevated privileges"
+	}
+}
+
+type accessLogKey struct{}
+
+// withAccessLog 把 l 挂到 ctx 上，供不持有 Scanner 的采集函数记录被拒绝的来源。
+func withAccessLog(ctx context.Context, l *AccessLog) context.Context {
+	if l == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, accessLogKey{}, l)
+}
+
+// recordAccess 把 err 记到 ctx 上的 AccessLog（没有时只做判断）。
+func recordAccess(ctx context.Context, category, path string, err error) bool {
+	l, _ := ctx.Value(accessLogKey{}).(*AccessLog)
+	return l.Record(category, path, err)
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package host
+
+// isLockViolation 在非 Windows 平台上不适用（文件锁为建议锁，不阻止读取）。
+func isLockViolation(err error) bool {
+	return false
+}
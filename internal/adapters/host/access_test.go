@@ -0,0 +1,57 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestAccessLog_RecordsPermissionDenialsOnce(t *testing.T) {
+	l := NewAccessLog()
+	ctx := withAccessLog(context.Background(), l)
+	denied := &fs.PathError{Op: "open", Path: "/x/History", Err: fs.ErrPermission}
+
+	if !recordAccess(ctx, AccessCategoryBrowserDB, "/x/History", denied) {
+		t.Fatal("permission error should be recorded")
+	}
+	if !l.Record(AccessCategoryBrowserDB, "/x/History", denied) {
+		t.Fatal("duplicate path still reports denial")
+	}
+	if recordAccess(ctx, AccessCategoryBrowserDB, "/x/Other", fs.ErrNotExist) {
+		t.Fatal("not-exist is not an access denial")
+	}
+	items := l.Items()
+	if len(items) != 1 || items[0].Reason != AccessReasonPermission || items[0].Hint == "" {
+		t.Fatalf("items=%+v", items)
+	}
+
+	// 未挂 AccessLog 的 ctx / nil log 只做判断，不 panic。
+	var nilLog *AccessLog
+	if !nilLog.Record(AccessCategoryRegistry, "HKLM", denied) || nilLog.Items() != nil {
+		t.Fatal("nil log should still classify the error")
+	}
+	_ = recordAccess(context.Background(), AccessCategoryBrowserDB, "/x/History", denied)
+}
+
+func TestQuerySQLite_RecordsUnreadableDB(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("file mode permissions are not enforced here")
+	}
+	dir := t.TempDir()
+	db := filepath.Join(dir, "History")
+	if err := os.WriteFile(db, []byte("x"), 0o000); err != nil {
+		t.Fatal(err)
+	}
+	l := NewAccessLog()
+	_, err := querySQLite(withAccessLog(context.Background(), l), db, "SELECT 1")
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("err=%v", err)
+	}
+	if items := l.Items(); len(items) != 1 || items[0].Path != db {
+		t.Fatalf("items=%+v", items)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build windows
+
+package host
+
+import (
+	"errors"
+
+	"golang.org/x/sys/windows"
+)
+
+// isLockViolation 识别“文件被其他进程占用/锁定”（浏览器运行时常见）。
+func isLockViolation(err error) bool {
+	return errors.Is(err, windows.ERROR_SHARING_VIOLATION) || errors.Is(err, windows.ERROR_LOCK_VIOLATION)
+}
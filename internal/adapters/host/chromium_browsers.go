@@ -0,0 +1,355 @@
+package host
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/toolbox"
+)
+
+// Chromium 系浏览器（含小众/便携版）的扩展目录定位
+//
+// 嫌疑人常把钱包扩展装在 Brave、Opera、Vivaldi、360 极速浏览器或 U 盘/桌面上的便携版 Chromium 里，
+// 只扫 Chrome/Edge 默认目录会漏掉。用户数据目录（User Data）按三种途径收集，按路径去重：
+// - known：各浏览器的默认安装位置（Opera 的用户数据目录本身就是唯一的 profile）
+// - process：正在运行的浏览器进程，取命令行 --user-data-dir，或按可执行文件位置推断便携版布局
+// - portable：在桌面/下载/文档/PortableApps 等常用目录中浅层查找含 "Local State" 的目录
+// 扩展记录的 source 字段标明来源，便于报告说明“在便携浏览器中发现”。
+
+// 扩展来源。
+const (
+	ChromiumSourceKnown    = "known_path"
+	ChromiumSourceProcess  = "running_process"
+	ChromiumSourcePortable = "portable_dir"
+)
+
+// chromiumLocalState 是 Chromium 用户数据目录根部的标志文件。
+const chromiumLocalState = "Local State"
+
+// portableSearchDepth / portableSearchMaxEntries 限制便携版查找的递归深度与遍历量。
+const (
+	portableSearchDepth      = 4
+	portableSearchMaxEntries = 50000
+)
+
+// chromiumRoot 是一个 Chromium 系浏览器的用户数据目录。
+type chromiumRoot struct {
+	Browser string
+	Path    string
+	Source  string
+}
+
+// chromiumExeBrowsers 是已知 Chromium 内核浏览器的可执行文件名（小写，不含 .exe）。
+var chromiumExeBrowsers = map[string]string{
+	"chrome":        "chrome",
+	"google chrome": "chrome",
+	"chromium":      "chromium",
+	"msedge":        "edge",
+	"brave":         "brave",
+	"brave browser": "brave",
+	"vivaldi":       "vivaldi",
+	"opera":         "opera",
+	"360chrome":     "360chrome",
+	"360chromex":    "360chrome",
+	"360se":         "360se",
+}
+
+// windowsChromiumRoots 返回 Windows 上各 Chromium 系浏览器的默认用户数据目录。
+func windowsChromiumRoots(local, roaming string) []chromiumRoot {
+	var out []chromiumRoot
+	add := func(browser string, parts ...string) {
+		out = append(out, chromiumRoot{Browser: browser, Path: filepath.Join(parts...), Source: ChromiumSourceKnown})
+	}
+	if local != "" {
+		add("chrome", local, "Google", "Chrome", "User Data")
+		add("edge", local, "Microsoft", "Edge", "User Data")
+		add("brave", local, "BraveSoftware", "Brave-Browser", "User Data")
+		add("vivaldi", local, "Vivaldi", "User Data")
+		add("chromium", local, "Chromium", "User Data")
+		add("360chrome", local, "360Chrome", "Chrome", "User Data")
+		add("360chrome", local, "360ChromeX", "Chrome", "User Data")
+	}
+	if roaming != "" {
+		add("opera", roaming, "Opera Software", "Opera Stable")
+		add("opera", roaming, "Opera Software", "Opera GX Stable")
+		add("360se", roaming, "360se6", "User Data")
+	}
+	return out
+}
+
+// macChromiumRoots 返回 macOS 上各 Chromium 系浏览器的默认用户数据目录。
+func macChromiumRoots(home string) []chromiumRoot {
+	support := filepath.Join(home, "Library", "Application Support")
+	var out []chromiumRoot
+	for _, r := range []struct{ browser, dir string }{
+		{"chrome", filepath.Join("Google", "Chrome")},
+		{"edge", "Microsoft Edge"},
+		{"brave", filepath.Join("BraveSoftware", "Brave-Browser")},
+		{"vivaldi", "Vivaldi"},
+		{"chromium", "Chromium"},
+		{"opera", "com.operasoftware.Opera"},
+		{"opera", "com.operasoftware.OperaGX"},
+	} {
+		out = append(out, chromiumRoot{Browser: r.browser, Path: filepath.Join(support, r.dir), Source: ChromiumSourceKnown})
+	}
+	return out
+}
+
+// browserProcess 是一个运行中进程的可执行文件路径与命令行。
+type browserProcess struct {
+	Exe         string
+	CommandLine string
+}
+
+// listBrowserProcesses 列出运行中的进程（best effort：命令失败时返回空）。
+func listBrowserProcesses(ctx context.Context, osType model.OSType) []browserProcess {
+	switch osType {
+	case model.OSWindows:
+		raw, err := toolbox.Output(ctx, "powershell", "-NoProfile", "-Command", `
+$ErrorActionPreference = 'SilentlyContinue'
+Get-CimInstance Win32_Process |
+  Where-Object { $_.ExecutablePath } |
+  Select-Object ExecutablePath,CommandLine |
+  ConvertTo-Json -Depth 2
+`)
+		if err != nil {
+			return nil
+		}
+		return parseWindowsProcesses(raw)
+	case model.OSMacOS:
+		raw, err := toolbox.Output(ctx, "ps", "-axww", "-o", "args=")
+		if err != nil {
+			return nil
+		}
+		return parsePSArgs(raw)
+	default:
+		return nil
+	}
+}
+
+// parseWindowsProcesses 解析 Win32_Process 的 JSON 输出（单个对象或数组）。
+func parseWindowsProcesses(raw []byte) []browserProcess {
+	type row struct {
+		ExecutablePath string `json:"ExecutablePath"`
+		CommandLine    string `json:"CommandLine"`
+	}
+	raw = bytes.TrimSpace(raw)
+	var rows []row
+	if err := json.Unmarshal(raw, &rows); err != nil {
+		var one row
+		if json.Unmarshal(raw, &one) != nil {
+			return nil
+		}
+		rows = []row{one}
+	}
+	out := make([]browserProcess, 0, len(rows))
+	for _, r := range rows {
+		if exe := strings.TrimSpace(r.ExecutablePath); exe != "" {
+			out = append(out, browserProcess{Exe: exe, CommandLine: r.CommandLine})
+		}
+	}
+	return out
+}
+
+// parsePSArgs 解析 `ps -o args=` 输出：可执行文件路径可能含空格，macOS 应用取到 .app/Contents/MacOS/<name> 为止。
+func parsePSArgs(raw []byte) []browserProcess {
+	var out []browserProcess
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		exe := line
+		if i := strings.Index(line, ".app/Contents/MacOS/"); i >= 0 {
+			rest := line[i+len(".app/Contents/MacOS/"):]
+			if j := strings.Index(rest, " -"); j >= 0 {
+				rest = rest[:j]
+			}
+			exe = line[:i] + ".app/Contents/MacOS/" + rest
+		} else if j := strings.IndexByte(line, ' '); j >= 0 {
+			exe = line[:j]
+		}
+		out = append(out, browserProcess{Exe: exe, CommandLine: line})
+	}
+	return out
+}
+
+// processBrowser 按可执行文件名识别 Chromium 系浏览器；不认识时返回空。
+func processBrowser(exe string) string {
+	return chromiumExeBrowsers[exeBaseName(exe)]
+}
+
+// exeBaseName 返回可执行文件名（小写，不含 .exe；Windows/Unix 路径分隔符均可）。
+func exeBaseName(exe string) string {
+	base := exe
+	if i := strings.LastIndexAny(base, `/\`); i >= 0 {
+		base = base[i+1:]
+	}
+	return strings.TrimSuffix(strings.ToLower(base), ".exe")
+}
+
+// userDataDirFromCmdline 取命令行中的 --user-data-dir（支持引号；ps 输出不保留引号时取到下一个 " --" 为止）。
+func userDataDirFromCmdline(cmdline string) string {
+	const flag = "--user-data-dir="
+	i := strings.Index(cmdline, flag)
+	if i < 0 {
+		return ""
+	}
+	v := cmdline[i+len(flag):]
+	if strings.HasPrefix(v, `"`) {
+		if j := strings.IndexByte(v[1:], '"'); j >= 0 {
+			return v[1 : j+1]
+		}
+		return strings.TrimPrefix(v, `"`)
+	}
+	if j := strings.Index(v, " --"); j >= 0 {
+		v = v[:j]
+	}
+	return strings.TrimSpace(v)
+}
+
+// rootsFromProcesses 从运行中的浏览器进程推断用户数据目录：
+// 命令行带 --user-data-dir 的直接采用（不限浏览器，套壳浏览器同样适用）；
+// 否则对已知浏览器按便携版常见布局（exe 同级/上级的 User Data、PortableApps 的 Data/profile）查找 Local State。
+func rootsFromProcesses(procs []browserProcess) []chromiumRoot {
+	var out []chromiumRoot
+	for _, p := range procs {
+		browser := processBrowser(p.Exe)
+		if dir := userDataDirFromCmdline(p.CommandLine); dir != "" {
+			if isChromiumUserData(dir) {
+				if browser == "" {
+					browser = chromiumBrowserFromPath(p.Exe)
+				}
+				out = append(out, chromiumRoot{Browser: browser, Path: filepath.Clean(dir), Source: ChromiumSourceProcess})
+			}
+			continue
+		}
+		if browser == "" {
+			continue
+		}
+		exeDir := filepath.Dir(p.Exe)
+		for _, c := range []string{
+			filepath.Join(exeDir, "User Data"),
+			filepath.Join(exeDir, "..", "User Data"),
+			filepath.Join(exeDir, "Data", "profile"),
+			filepath.Join(exeDir, "..", "..", "Data", "profile"),
+		} {
+			if isChromiumUserData(c) {
+				out = append(out, chromiumRoot{Browser: browser, Path: filepath.Clean(c), Source: ChromiumSourceProcess})
+			}
+		}
+	}
+	return out
+}
+
+// portableSearchDirs 返回便携版浏览器的常用存放目录。
+func portableSearchDirs(osType model.OSType, home string) []string {
+	var dirs []string
+	if home != "" {
+		for _, d := range []string{"Desktop", "Downloads", "Documents", "PortableApps"} {
+			dirs = append(dirs, filepath.Join(home, d))
+		}
+		if osType == model.OSMacOS {
+			dirs = append(dirs, filepath.Join(home, "Applications"))
+		}
+	}
+	if osType == model.OSWindows && runtime.GOOS == "windows" {
+		for c := 'C'; c <= 'Z'; c++ {
+			dirs = append(dirs, string(c)+`:\PortableApps`)
+		}
+	}
+	return dirs
+}
+
+// findPortableChromiumRoots 在 dirs 下浅层查找 Chromium 用户数据目录（含 Local State 的目录）。
+func findPortableChromiumRoots(ctx context.Context, dirs []string) []chromiumRoot {
+	var out []chromiumRoot
+	walkSearchDirs(ctx, dirs, portableSearchDepth, func(path string) bool {
+		if !isChromiumUserData(path) {
+			return false
+		}
+		out = append(out, chromiumRoot{Browser: chromiumBrowserFromPath(path), Path: path, Source: ChromiumSourcePortable})
+		return true
+	})
+	return out
+}
+
+// walkSearchDirs 在 dirs 下按深度限制遍历子目录（跳过隐藏目录与 node_modules，总遍历量受 portableSearchMaxEntries 限制）；
+// visit 返回 true 表示已命中，不再深入该目录。
+func walkSearchDirs(ctx context.Context, dirs []string, depth int, visit func(path string) bool) {
+	n := 0
+	for _, dir := range dirs {
+		if st, err := os.Stat(dir); err != nil || !st.IsDir() {
+			continue
+		}
+		base := strings.Count(filepath.Clean(dir), string(filepath.Separator))
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				recordAccess(ctx, AccessCategoryDirectory, path, err)
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if n++; n > portableSearchMaxEntries {
+				return filepath.SkipAll
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			if visit(path) {
+				return filepath.SkipDir
+			}
+			if strings.Count(filepath.Clean(path), string(filepath.Separator))-base >= depth {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+	}
+}
+
+// isChromiumUserData 判断目录是否为 Chromium 用户数据目录（存在 Local State 文件）。
+func isChromiumUserData(dir string) bool {
+	st, err := os.Stat(filepath.Join(dir, chromiumLocalState))
+	return err == nil && st.Mode().IsRegular()
+}
+
+// scanChromiumRoots 按路径去重后扫描各用户数据目录下的扩展（先出现的来源优先：known > process > portable）。
+func scanChromiumRoots(roots []chromiumRoot) []model.ExtensionRecord {
+	seen := map[string]bool{}
+	var out []model.ExtensionRecord
+	for _, r := range roots {
+		key := browserRootKey(r.Path)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		for _, ext := range scanChromiumExtensions(r.Path, r.Browser) {
+			ext.Source = r.Source
+			out = append(out, ext)
+		}
+	}
+	return out
+}
+
+// browserRootKey 是浏览器数据目录的去重键（Windows/macOS 文件系统大小写不敏感）。
+func browserRootKey(path string) string {
+	key := filepath.Clean(path)
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		key = strings.ToLower(key)
+	}
+	return key
+}
@@ -0,0 +1,84 @@
+package host
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChromiumRootDiscovery(t *testing.T) {
+	dir := t.TempDir()
+	mk := func(parts ...string) string {
+		p := filepath.Join(append([]string{dir}, parts...)...)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(`{"name":"MetaMask","version":"11.0"}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	// 便携版 Chrome（PortableApps 布局）、Opera（单 profile）、命令行指定 --user-data-dir 的 Brave。
+	mk("Desktop", "GoogleChromePortable", "Data", "profile", chromiumLocalState)
+	mk("Desktop", "GoogleChromePortable", "Data", "profile", "Default", "Extensions", "nkbihfbeogaeaoehlefnkodbefgpgknn", "11.0_0", "manifest.json")
+	exe := mk("Desktop", "GoogleChromePortable", "App", "Chrome-bin", "chrome.exe")
+	mk("Opera Stable", chromiumLocalState)
+	mk("Opera Stable", "Extensions", "bfnaelmomeimhlpmgjnjophhpkkoljpa", "1.0_0", "manifest.json")
+	mk("usb", "brave data", chromiumLocalState)
+	mk("usb", "brave data", "Profile 1", "Extensions", "aholpfdialjgjfhomihkjbmgjidlcdno", "2.0_0", "manifest.json")
+
+	procs := parseWindowsProcesses([]byte(`[
+		{"ExecutablePath": "` + filepath.ToSlash(exe) + `", "CommandLine": "chrome.exe --type=renderer"},
+		{"ExecutablePath": "C:/Tools/brave.exe", "CommandLine": "\"C:/Tools/brave.exe\" --user-data-dir=\"` + filepath.ToSlash(filepath.Join(dir, "usb", "brave data")) + `\" --no-first-run"},
+		{"ExecutablePath": "C:/Windows/notepad.exe", "CommandLine": "notepad.exe"}
+	]`))
+	if len(procs) != 3 {
+		t.Fatalf("procs=%+v", procs)
+	}
+	fromProcs := rootsFromProcesses(procs)
+	if len(fromProcs) != 2 || fromProcs[0].Browser != "chrome" || fromProcs[1].Browser != "brave" {
+		t.Fatalf("process roots=%+v", fromProcs)
+	}
+
+	portable := findPortableChromiumRoots(context.Background(), []string{filepath.Join(dir, "Desktop")})
+	if len(portable) != 1 || portable[0].Source != ChromiumSourcePortable {
+		t.Fatalf("portable roots=%+v", portable)
+	}
+
+	roots := append([]chromiumRoot{{Browser: "opera", Path: filepath.Join(dir, "Opera Stable"), Source: ChromiumSourceKnown}}, fromProcs...)
+	exts := scanChromiumRoots(append(roots, portable...))
+	bySource := map[string]string{}
+	for _, e := range exts {
+		bySource[e.ExtensionID] = e.Browser + "/" + e.Profile + "/" + e.Source
+	}
+	want := map[string]string{
+		"bfnaelmomeimhlpmgjnjophhpkkoljpa": "opera/Opera Stable/known_path",
+		"nkbihfbeogaeaoehlefnkodbefgpgknn": "chrome/Default/running_process", // 进程与目录查找到同一目录，只扫一次
+		"aholpfdialjgjfhomihkjbmgjidlcdno": "brave/Profile 1/running_process",
+	}
+	if len(exts) != len(want) {
+		t.Fatalf("extensions=%+v", exts)
+	}
+	for id, w := range want {
+		if bySource[id] != w {
+			t.Fatalf("%s: got %q want %q", id, bySource[id], w)
+		}
+	}
+}
+
+func TestParseProcessArgs(t *testing.T) {
+	procs := parsePSArgs([]byte("/Applications/Brave Browser.app/Contents/MacOS/Brave Browser --user-data-dir=/Volumes/USB/Brave Data --no-first-run\n/usr/sbin/cfprefsd agent\n"))
+	if len(procs) != 2 || procs[0].Exe != "/Applications/Brave Browser.app/Contents/MacOS/Brave Browser" || procs[1].Exe != "/usr/sbin/cfprefsd" {
+		t.Fatalf("procs=%+v", procs)
+	}
+	if b := processBrowser(procs[0].Exe); b != "brave" {
+		t.Fatalf("browser=%q", b)
+	}
+	if d := userDataDirFromCmdline(procs[0].CommandLine); d != "/Volumes/USB/Brave Data" {
+		t.Fatalf("user data dir=%q", d)
+	}
+	if b := processBrowser(`D:\Apps\360Chrome\Chrome\Application\360chrome.EXE`); b != "360chrome" {
+		t.Fatalf("360 browser=%q", b)
+	}
+}
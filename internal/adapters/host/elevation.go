@@ -0,0 +1,107 @@
+package host
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 扫描开始前的权限能力检查
+//
+// 部分关键来源需要更高权限才能读取，例如其他用户的 profile 目录需要管理员/root。
+// CheckElevation 列出这些缺口，由 hostscan 记为前置检查，CLI 可据此提权重启。
+// macOS 的完全磁盘访问权限（TCC）提权也无法解决，单独由 CheckFullDiskAccess 探测（见 tcc.go）。
+
+// 所需权限。
+const (
+	PermissionAdministrator = "administrator"
+	PermissionRoot          = "root"
+)
+
+// ElevationRequirement 是一个当前权限下无法读取的关键来源。
+type ElevationRequirement struct {
+	Source     string `json:"source"`
+	Path       string `json:"path"`
+	Permission string `json:"permission"`
+	Hint       string `json:"hint"`
+}
+
+// ElevationStatus 是当前进程的权限状态与缺口。
+type ElevationStatus struct {
+	Elevated     bool                   `json:"elevated"`
+	User         string                 `json:"user,omitempty"`
+	Executable   string                 `json:"executable,omitempty"`
+	Requirements []ElevationRequirement `json:"requirements,omitempty"`
+}
+
+// NeedsRelaunch 表示未提权且存在需要提权的缺口。
+func (s ElevationStatus) NeedsRelaunch() bool {
+	return !s.Elevated && len(s.Requirements) > 0
+}
+
+// CheckElevation 检查当前进程能否读取 device 上的关键来源（只做目录探测，不读取内容）。
+func CheckElevation(_ context.Context, device model.Device) ElevationStatus {
+	st := ElevationStatus{Elevated: isElevated()}
+	st.Executable, _ = os.Executable()
+	home, _ := os.UserHomeDir()
+	if home != "" {
+		st.User = filepath.Base(home)
+	}
+	switch device.OS {
+	case model.OSWindows:
+		drive := os.Getenv("SystemDrive")
+		if drive == "" {
+			drive = "C:"
+		}
+		st.Requirements = otherUserProfileRequirements(filepath.Join(drive+`\`, "Users"), st.User, PermissionAdministrator,
+			"rerun with --elevate or from an elevated (Run as administrator) session")
+	case model.OSMacOS:
+		st.Requirements = otherUserProfileRequirements("/Users", st.User, PermissionRoot, "rerun with --elevate or with sudo")
+	}
+	return st
+}
+
+// nonUserProfileDirs 是用户目录根下不属于具体用户的条目（小写比较）。
+var nonUserProfileDirs = map[string]bool{
+	"public": true, "default": true, "default user": true, "all users": true,
+	"shared": true, "guest": true, "deleted users": true,
+}
+
+// otherUserProfileRequirements 列出 usersRoot 下当前用户无法读取的其他用户目录。
+func otherUserProfileRequirements(usersRoot, currentUser, permission, hint string) []ElevationRequirement {
+	entries, err := os.ReadDir(usersRoot)
+	if err != nil {
+		return nil
+	}
+	var out []ElevationRequirement
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || strings.HasPrefix(name, ".") || nonUserProfileDirs[strings.ToLower(name)] || strings.EqualFold(name, currentUser) {
+			continue
+		}
+		dir := filepath.Join(usersRoot, name)
+		if _, err := os.ReadDir(dir); AccessDeniedReason(err) == "" {
+			continue
+		}
+		out = append(out, ElevationRequirement{
+			Source:     "other_user_profile",
+			Path:       dir,
+			Permission: permission,
+			Hint:       hint,
+		})
+	}
+	return out
+}
+
+// RelaunchElevated 以管理员/root 身份重新运行当前程序（Windows：UAC；macOS：osascript 授权对话框）。
+// args 不含程序名；Windows 上新进程在独立窗口运行，本函数在启动后即返回。
+func RelaunchElevated(ctx context.Context, args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	return relaunchElevated(ctx, exe, args)
+}
@@ -0,0 +1,47 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestElevationStatus_NeedsRelaunch(t *testing.T) {
+	admin := ElevationRequirement{Source: "other_user_profile", Permission: PermissionAdministrator}
+
+	if (ElevationStatus{}).NeedsRelaunch() {
+		t.Fatal("nothing to elevate for")
+	}
+	if !(ElevationStatus{Requirements: []ElevationRequirement{admin}}).NeedsRelaunch() {
+		t.Fatal("unreadable profile should ask for relaunch")
+	}
+	if (ElevationStatus{Elevated: true, Requirements: []ElevationRequirement{admin}}).NeedsRelaunch() {
+		t.Fatal("already elevated")
+	}
+}
+
+func TestOtherUserProfileRequirements(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"me", "Public", "alice", "bob"} {
+		if err := os.Mkdir(filepath.Join(root, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if reqs := otherUserProfileRequirements(root, "me", PermissionRoot, "hint"); len(reqs) != 0 {
+		t.Fatalf("readable profiles need no elevation: %+v", reqs)
+	}
+
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		return
+	}
+	locked := filepath.Join(root, "bob")
+	if err := os.Chmod(locked, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(locked, 0o755)
+	reqs := otherUserProfileRequirements(root, "me", PermissionRoot, "hint")
+	if len(reqs) != 1 || reqs[0].Path != locked {
+		t.Fatalf("reqs=%+v", reqs)
+	}
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+
+package host
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// isElevated 判断当前进程是否以 root 运行。
+func isElevated() bool {
+	return os.Geteuid() == 0
+}
+
+// relaunchElevated 在 macOS 上通过 osascript 弹出管理员授权对话框，以 root 同步运行并转发输出。
+func relaunchElevated(ctx context.Context, exe string, args []string) error {
+	if runtime.GOOS != "darwin" {
+		return errors.New("elevated relaunch is not supported on " + runtime.GOOS)
+	}
+	cwd, _ := os.Getwd()
+	parts := []string{"cd", shellQuote(cwd), "&&", shellQuote(exe)}
+	for _, a := range args {
+		parts = append(parts, shellQuote(a))
+	}
+	script := `do shell script "` + appleScriptEscape(strings.Join(parts, " ")) + `" with administrator privileges`
+	cmd := exec.CommandContext(ctx, "osascript", "-e", script)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// shellQuote 用单引号包裹参数（内部单引号转义为 '\”）。
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// appleScriptEscape 转义 AppleScript 双引号字符串中的反斜杠与双引号。
+func appleScriptEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package host
+
+import (
+	"context"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// isElevated 判断当前进程令牌是否已提升（UAC）。
+func isElevated() bool {
+	return windows.GetCurrentProcessToken().IsElevated()
+}
+
+// relaunchElevated 通过 ShellExecute "runas" 触发 UAC 提权并启动新进程。
+func relaunchElevated(_ context.Context, exe string, args []string) error {
+	quoted := make([]string, 0, len(args))
+	for _, a := range args {
+		quoted = append(quoted, syscall.EscapeArg(a))
+	}
+	cwd, _ := os.Getwd()
+	verb, _ := windows.UTF16PtrFromString("runas")
+	file, _ := windows.UTF16PtrFromString(exe)
+	params, _ := windows.UTF16PtrFromString(strings.Join(quoted, " "))
+	dir, _ := windows.UTF16PtrFromString(cwd)
+	return windows.ShellExecute(0, verb, file, params, dir, windows.SW_SHOWNORMAL)
+}
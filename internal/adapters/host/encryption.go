@@ -0,0 +1,258 @@
+package host
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/toolbox"
+)
+
+// 加密卷 / 加密容器识别
+//
+// 全盘加密与已挂载的加密卷在断电后无法再读取，调查人员需要在关机前知道它们的存在：
+// - 系统卷：Windows BitLocker（Get-BitLockerVolume）、macOS FileVault（fdesetup status）
+// - 已挂载卷：macOS 上 VeraCrypt/TrueCrypt 的挂载项（mount 输出）
+// - 容器文件：用户常用目录下的 LUKS / BitLocker 卷头，以及 .hc/.tc 扩展名的 VeraCrypt/TrueCrypt 容器
+// - 软件：VeraCrypt/TrueCrypt 安装位置
+// 全部为只读探测；命令不可用（例如非管理员运行）时跳过对应项。
+
+// encContainerMinBytes 是容器文件的最小体积（小于该值的同名文件不视为容器）。
+const encContainerMinBytes = 1 << 20
+
+// encMaxDepth 限制容器文件查找的递归深度。
+const encMaxDepth = 3
+
+var (
+	luksMagic      = []byte{'L', 'U', 'K', 'S', 0xba, 0xbe}
+	bitlockerMagic = []byte("-FVE-FS-") // BitLocker 卷引导扇区 OEM ID（偏移 3）
+)
+
+// encSoftwareHint 是一个加密软件的已知安装位置。
+type encSoftwareHint struct {
+	Kind string
+	Name string
+	Path string
+}
+
+// DetectEncryption 探测当前主机上的加密卷、加密容器与加密软件（结果按 scope、path 排序）。
+func DetectEncryption(ctx context.Context, osType model.OSType) []model.EncryptionFinding {
+	var out []model.EncryptionFinding
+	var roots []string
+	var hints []encSoftwareHint
+	switch osType {
+	case model.OSWindows:
+		if raw, err := toolbox.Output(ctx, "powershell", "-NoProfile", "-Command", `
+$ErrorActionPreference = 'SilentlyContinue'
+Get-BitLockerVolume |
+  Select-Object MountPoint,@{n='VolumeStatus';e={"$($_.VolumeStatus)"}},@{n='ProtectionStatus';e={"$($_.ProtectionStatus)"}},@{n='LockStatus';e={"$($_.LockStatus)"}} |
+  ConvertTo-Json -Depth 2
+`); err == nil {
+			out = append(out, parseBitLockerVolumes(raw)...)
+		}
+		for _, pf := range []string{os.Getenv("ProgramFiles"), os.Getenv("ProgramFiles(x86)")} {
+			if pf == "" {
+				continue
+			}
+			hints = append(hints,
+				encSoftwareHint{Kind: "veracrypt", Name: "VeraCrypt", Path: filepath.Join(pf, "VeraCrypt")},
+				encSoftwareHint{Kind: "truecrypt", Name: "TrueCrypt", Path: filepath.Join(pf, "TrueCrypt")},
+			)
+		}
+		if profile := os.Getenv("USERPROFILE"); profile != "" {
+			roots = append(roots, filepath.Join(profile, "Documents"), filepath.Join(profile, "Desktop"), filepath.Join(profile, "Downloads"))
+		}
+	case model.OSMacOS:
+		if raw, err := toolbox.Output(ctx, "fdesetup", "status"); err == nil {
+			if f, ok := parseFdesetupStatus(string(raw)); ok {
+				out = append(out, f)
+			}
+		}
+		if raw, err := toolbox.Output(ctx, "mount"); err == nil {
+			out = append(out, parseEncryptedMounts(string(raw))...)
+		}
+		hints = append(hints,
+			encSoftwareHint{Kind: "veracrypt", Name: "VeraCrypt", Path: "/Applications/VeraCrypt.app"},
+			encSoftwareHint{Kind: "truecrypt", Name: "TrueCrypt", Path: "/Applications/TrueCrypt.app"},
+		)
+		if home, err := os.UserHomeDir(); err == nil && home != "" {
+			roots = append(roots, filepath.Join(home, "Documents"), filepath.Join(home, "Desktop"), filepath.Join(home, "Downloads"))
+		}
+	}
+	for _, h := range hints {
+		if _, err := os.Stat(h.Path); err == nil {
+			out = append(out, model.EncryptionFinding{Kind: h.Kind, Scope: "software", Path: h.Path, Note: h.Name + " installed"})
+		}
+	}
+	for _, root := range roots {
+		out = append(out, findEncryptedContainers(ctx, root)...)
+	}
+	sortEncryptionFindings(out)
+	return out
+}
+
+// parseBitLockerVolumes 解析 Get-BitLockerVolume 的 JSON 输出（单个对象或数组），只保留非“完全解密”的卷。
+func parseBitLockerVolumes(raw []byte) []model.EncryptionFinding {
+	type row struct {
+		MountPoint       string `json:"MountPoint"`
+		VolumeStatus     string `json:"VolumeStatus"`
+		ProtectionStatus string `json:"ProtectionStatus"`
+		LockStatus       string `json:"LockStatus"`
+	}
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil
+	}
+	var many []row
+	if err := json.Unmarshal(raw, &many); err != nil {
+		var one row
+		if err := json.Unmarshal(raw, &one); err != nil {
+			return nil
+		}
+		many = []row{one}
+	}
+	var out []model.EncryptionFinding
+	for _, r := range many {
+		if r.MountPoint == "" || r.VolumeStatus == "" || r.VolumeStatus == "FullyDecrypted" {
+			continue
+		}
+		out = append(out, model.EncryptionFinding{
+			Kind:   "bitlocker",
+			Scope:  "system_volume",
+			Path:   r.MountPoint,
+			Status: strings.Join([]string{r.VolumeStatus, "Protection" + r.ProtectionStatus, r.LockStatus}, "; "),
+		})
+	}
+	return out
+}
+
+// parseFdesetupStatus 解析 `fdesetup status` 输出；FileVault 开启或正在加密时返回记录。
+func parseFdesetupStatus(raw string) (model.EncryptionFinding, bool) {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(raw), "\n", 2)[0])
+	if !strings.HasPrefix(line, "FileVault is On") && !strings.Contains(raw, "Encryption in progress") {
+		return model.EncryptionFinding{}, false
+	}
+	status := line
+	for _, l := range strings.Split(raw, "\n") {
+		if l = strings.TrimSpace(l); strings.HasPrefix(l, "Encryption in progress") {
+			status = l
+		}
+	}
+	return model.EncryptionFinding{Kind: "filevault", Scope: "system_volume", Path: "/", Status: status}, true
+}
+
+// parseEncryptedMounts 从 macOS `mount` 输出中找出 VeraCrypt/TrueCrypt 挂载的卷。
+//
+//	/dev/disk4 on /Volumes/NO NAME (msdos, local, nodev, nosuid, noowners, mounted by alice)
+//	veracrypt1 on /Volumes/secret (osxfuse, nodev, nosuid, synchronous, mounted by alice)
+func parseEncryptedMounts(raw string) []model.EncryptionFinding {
+	var out []model.EncryptionFinding
+	s := bufio.NewScanner(strings.NewReader(raw))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		dev, rest, ok := strings.Cut(line, " on ")
+		if !ok {
+			continue
+		}
+		mnt := rest
+		if i := strings.LastIndex(rest, " ("); i >= 0 {
+			mnt = rest[:i]
+		}
+		lower := strings.ToLower(line)
+		kind := ""
+		switch {
+		case strings.Contains(lower, "veracrypt"):
+			kind = "veracrypt"
+		case strings.Contains(lower, "truecrypt"):
+			kind = "truecrypt"
+		default:
+			continue
+		}
+		out = append(out, model.EncryptionFinding{Kind: kind, Scope: "mounted_volume", Path: mnt, Status: "mounted", Note: "device " + dev})
+	}
+	return out
+}
+
+// findEncryptedContainers 在 root 下（限定深度）按卷头特征或扩展名查找加密容器文件。
+func findEncryptedContainers(ctx context.Context, root string) []model.EncryptionFinding {
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil
+	}
+	var out []model.EncryptionFinding
+	base := strings.Count(filepath.Clean(root), string(os.PathSeparator))
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if path != root && strings.Count(path, string(os.PathSeparator))-base >= encMaxDepth {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil || info.Size() < encContainerMinBytes {
+			return nil
+		}
+		if f, ok := classifyEncryptedContainer(path); ok {
+			out = append(out, f)
+		}
+		return nil
+	})
+	return out
+}
+
+// classifyEncryptedContainer 识别单个文件：LUKS / BitLocker 卷头优先，其次按 .hc/.tc 扩展名
+// （VeraCrypt/TrueCrypt 容器没有明文卷头，只能按扩展名提示）。
+func classifyEncryptedContainer(path string) (model.EncryptionFinding, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return model.EncryptionFinding{}, false
+	}
+	defer f.Close()
+	head := make([]byte, 16)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+	switch {
+	case bytes.HasPrefix(head, luksMagic):
+		return model.EncryptionFinding{Kind: "luks", Scope: "container_file", Path: path, Note: "LUKS header magic"}, true
+	case len(head) >= 3+len(bitlockerMagic) && bytes.Equal(head[3:3+len(bitlockerMagic)], bitlockerMagic):
+		return model.EncryptionFinding{Kind: "bitlocker", Scope: "container_file", Path: path, Note: "BitLocker volume header (-FVE-FS-)"}, true
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".hc":
+		return model.EncryptionFinding{Kind: "veracrypt", Scope: "container_file", Path: path, Note: "file extension .hc"}, true
+	case ".tc":
+		return model.EncryptionFinding{Kind: "truecrypt", Scope: "container_file", Path: path, Note: "file extension .tc"}, true
+	}
+	return model.EncryptionFinding{}, false
+}
+
+// encScopeOrder 决定结果排序：越靠前越需要在关机前处理。
+var encScopeOrder = map[string]int{"system_volume": 0, "mounted_volume": 1, "container_file": 2, "software": 3}
+
+func sortEncryptionFindings(list []model.EncryptionFinding) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Scope != list[j].Scope {
+			return encScopeOrder[list[i].Scope] < encScopeOrder[list[j].Scope]
+		}
+		return list[i].Path < list[j].Path
+	})
+}
@@ -0,0 +1,71 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEncryptionStatusOutputs(t *testing.T) {
+	vols := parseBitLockerVolumes([]byte(`[
+  {"MountPoint":"C:","VolumeStatus":"FullyEncrypted","ProtectionStatus":"On","LockStatus":"Unlocked"},
+  {"MountPoint":"D:","VolumeStatus":"FullyDecrypted","ProtectionStatus":"Off","LockStatus":"Unlocked"}
+]`))
+	if len(vols) != 1 || vols[0].Kind != "bitlocker" || vols[0].Path != "C:" || vols[0].Status != "FullyEncrypted; ProtectionOn; Unlocked" {
+		t.Fatalf("bitlocker=%+v", vols)
+	}
+	if one := parseBitLockerVolumes([]byte(`{"MountPoint":"E:","VolumeStatus":"EncryptionInProgress","ProtectionStatus":"Off","LockStatus":"Unlocked"}`)); len(one) != 1 {
+		t.Fatalf("single object=%+v", one)
+	}
+
+	if f, ok := parseFdesetupStatus("FileVault is On.\n"); !ok || f.Kind != "filevault" || f.Status != "FileVault is On." {
+		t.Fatalf("filevault=%+v ok=%v", f, ok)
+	}
+	if _, ok := parseFdesetupStatus("FileVault is Off.\n"); ok {
+		t.Fatalf("FileVault off must not be reported")
+	}
+
+	mounts := parseEncryptedMounts(`/dev/disk1s1 on / (apfs, local, journaled)
+/dev/disk4 on /Volumes/NO NAME (msdos, local, nodev, nosuid, noowners, mounted by alice)
+veracrypt1 on /Volumes/secret stuff (osxfuse, nodev, nosuid, synchronous, mounted by alice)
+`)
+	if len(mounts) != 1 || mounts[0].Kind != "veracrypt" || mounts[0].Path != "/Volumes/secret stuff" {
+		t.Fatalf("mounts=%+v", mounts)
+	}
+}
+
+func TestFindEncryptedContainers(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel string, head []byte, size int) {
+		p := filepath.Join(root, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, size)
+		copy(buf, head)
+		if err := os.WriteFile(p, buf, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("luks.img", luksMagic, encContainerMinBytes)
+	write("sub/usb.bin", append([]byte{0xeb, 0x58, 0x90}, bitlockerMagic...), encContainerMinBytes)
+	write("sub/vault.hc", nil, encContainerMinBytes)
+	write("small.hc", nil, 1024)
+	write("plain.bin", []byte("hello"), encContainerMinBytes)
+
+	got := findEncryptedContainers(t.Context(), root)
+	sortEncryptionFindings(got)
+	want := map[string]string{
+		filepath.Join(root, "luks.img"):        "luks",
+		filepath.Join(root, "sub", "usb.bin"):  "bitlocker",
+		filepath.Join(root, "sub", "vault.hc"): "veracrypt",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("findings=%+v", got)
+	}
+	for _, f := range got {
+		if want[f.Path] != f.Kind || f.Scope != "container_file" {
+			t.Fatalf("unexpected finding %+v", f)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+)
+
+// 命中扩展目录快照
+//
+// 扩展命中原先只记录扩展 ID 字符串，嫌疑人事后卸载扩展后就无法复核。命中后把扩展的本地文件固化为 zip：
+//   - Chromium 系：各版本目录下的 manifest.json 与 manifest 中引用的图标
+//   - Firefox：.xpi 扩展包原样复制（解包安装的扩展同 Chromium，取 manifest 与图标）
+//   - listing.json：扩展目录下全部文件的相对路径、大小、修改时间与 sha256（版本目录清单）
+// 复制的文件计入采集预算（--max-bytes）。
+
+const (
+	// extSnapshotMaxListing 是 listing.json 最多记录的文件数，超出部分只计数。
+	extSnapshotMaxListing = 5000
+	// extSnapshotMaxHashBytes 是 listing.json 中计算 sha256 的单文件大小上限。
+	extSnapshotMaxHashBytes = 32 << 20
+)
+
+// ExtensionListingEntry 是 listing.json 中的一个文件。
+type ExtensionListingEntry struct {
+	Path       string `json:"path"`
+	SizeBytes  int64  `json:"size_bytes"`
+	ModifiedAt int64  `json:"modified_at"`
+	SHA256     string `json:"sha256,omitempty"`
+}
+
+// SnapshotExtension 把一个扩展的 manifest、图标与目录清单打包为 browser_extension_snapshot 证据。
+func (s *Scanner) SnapshotExtension(caseID, deviceID string, ex model.ExtensionRecord) (model.Artifact, error) {
+	src := strings.TrimSpace(ex.Path)
+	if src == "" {
+		return model.Artifact{}, fmt.Errorf("extension %s has no local path", ex.ExtensionID)
+	}
+	st, err := os.Stat(src)
+	if err != nil {
+		return model.Artifact{}, fmt.Errorf("stat extension path: %w", err)
+	}
+
+	files := map[string]string{}
+	var listing []ExtensionListingEntry
+	total := 0
+	if st.IsDir() {
+		collectExtensionManifestFiles(src, files)
+		listing, total = listExtensionDir(src)
+	} else {
+		// 单文件扩展包（Firefox .xpi）：原样复制。
+		files[filepath.Base(src)] = src
+		listing, total = []ExtensionListingEntry{extensionListingEntry(src, filepath.Base(src), st)}, 1
+	}
+
+	var size int64
+	for _, p := range files {
+		if fi, err := os.Stat(p); err == nil {
+			size += fi.Size()
+		}
+	}
+	sourceRef := fmt.Sprintf("%s_%s_%s", ex.Browser, ex.Profile, strings.ToLower(strings.TrimSpace(ex.ExtensionID)))
+	if !s.Budget.Take(string(model.ArtifactBrowserExtSnapshot), sourceRef, src, size) {
+		return model.Artifact{}, fmt.Errorf("extension snapshot %s skipped: over collection budget", sourceRef)
+	}
+
+	tmp, err := os.CreateTemp("", "ext-listing-*.json")
+	if err != nil {
+		return model.Artifact{}, fmt.Errorf("create listing file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	enc := json.NewEncoder(tmp)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(listing); err != nil {
+		tmp.Close()
+		return model.Artifact{}, fmt.Errorf("write listing file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return model.Artifact{}, fmt.Errorf("write listing file: %w", err)
+	}
+	entries := sortedKeys(files)
+	files["listing.json"] = tmp.Name()
+
+	payload := map[string]any{
+		"kind":          string(model.ArtifactBrowserExtSnapshot),
+		"browser":       ex.Browser,
+		"profile":       ex.Profile,
+		"extension_id":  ex.ExtensionID,
+		"name":          ex.Name,
+		"version":       ex.Version,
+		"origin_path":   src,
+		"files":         entries,
+		"listing_count": total,
+	}
+	if total > len(listing) {
+		payload["listing_truncated"] = true
+	}
+	return s.makeZipArtifact(caseID, deviceID, model.ArtifactBrowserExtSnapshot, sourceRef, "extension_snapshot_zip", files, payload)
+}
+
+// collectExtensionManifestFiles 收集扩展目录中的 manifest.json 与其引用的图标（zip 内名称 -> 源路径）。
+//
+// Chromium 系扩展目录下是各版本子目录（<extensionID>/<version>/manifest.json），每个版本都取；
+// 解包安装的 Firefox 扩展 manifest.json 直接位于扩展目录下。
+func collectExtensionManifestFiles(extDir string, files map[string]string) {
+	roots := []string{extDir}
+	if _, err := os.Stat(filepath.Join(extDir, "manifest.json")); err != nil {
+		roots = roots[:0]
+		entries, _ := os.ReadDir(extDir)
+		for _, e := range entries {
+			if e.IsDir() && !strings.HasPrefix(e.Name(), ".") {
+				roots = append(roots, filepath.Join(extDir, e.Name()))
+			}
+		}
+	}
+	for _, root := range roots {
+		manifest := filepath.Join(root, "manifest.json")
+		raw, err := os.ReadFile(manifest)
+		if err != nil {
+			continue
+		}
+		files[zipRelName(extDir, manifest)] = manifest
+		for _, rel := range manifestIconPaths(raw) {
+			p := filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(rel, "/")))
+			// 图标路径来自扩展自身，限制在版本目录内，防止 ../ 引用到目录外的文件。
+			if r, err := filepath.Rel(root, p); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+				continue
+			}
+			if fi, err := os.Stat(p); err == nil && fi.Mode().IsRegular() {
+				files[zipRelName(extDir, p)] = p
+			}
+		}
+	}
+}
+
+// manifestIconPaths 返回 manifest 中 icons 与 action/browser_action/page_action.default_icon 引用的图标相对路径。
+func manifestIconPaths(raw []byte) []string {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil
+	}
+	set := map[string]struct{}{}
+	addIcons := func(v json.RawMessage) {
+		var one string
+		if json.Unmarshal(v, &one) == nil {
+			if one = strings.TrimSpace(one); one != "" {
+				set[one] = struct{}{}
+			}
+			return
+		}
+		var many map[string]string
+		if json.Unmarshal(v, &many) == nil {
+			for _, p := range many {
+				if p = strings.TrimSpace(p); p != "" {
+					set[p] = struct{}{}
+				}
+			}
+		}
+	}
+	if v, ok := m["icons"]; ok {
+		addIcons(v)
+	}
+	for _, key := range []string{"action", "browser_action", "page_action"} {
+		var action struct {
+			DefaultIcon json.RawMessage `json:"default_icon"`
+		}
+		if v, ok := m[key]; ok && json.Unmarshal(v, &action) == nil && len(action.DefaultIcon) > 0 {
+			addIcons(action.DefaultIcon)
+		}
+	}
+	out := make([]string, 0, len(set))
+	for p := range set {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// listExtensionDir 遍历扩展目录生成文件清单（最多 extSnapshotMaxListing 条），并返回文件总数。
+func listExtensionDir(extDir string) ([]ExtensionListingEntry, int) {
+	var out []ExtensionListingEntry
+	total := 0
+	_ = filepath.WalkDir(extDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		total++
+		if len(out) >= extSnapshotMaxListing {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		out = append(out, extensionListingEntry(p, zipRelName(extDir, p), fi))
+		return nil
+	})
+	return out, total
+}
+
+func extensionListingEntry(path, rel string, fi os.FileInfo) ExtensionListingEntry {
+	e := ExtensionListingEntry{Path: rel, SizeBytes: fi.Size(), ModifiedAt: fi.ModTime().Unix()}
+	if fi.Mode().IsRegular() && fi.Size() <= extSnapshotMaxHashBytes {
+		if sum, _, err := hash.File(path); err == nil {
+			e.SHA256 = sum
+		}
+	}
+	return e
+}
+
+// zipRelName 返回 p 相对 base 的 zip 内名称（统一使用 /）。
+func zipRelName(base, p string) string {
+	rel, err := filepath.Rel(base, p)
+	if err != nil {
+		rel = filepath.Base(p)
+	}
+	return filepath.ToSlash(rel)
+}
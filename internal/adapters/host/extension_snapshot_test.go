@@ -0,0 +1,69 @@
+package host
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestSnapshotExtension(t *testing.T) {
+	dir := t.TempDir()
+	extDir := filepath.Join(dir, "Default", "Extensions", "nkbihfbeogaeaoehlefnkodbefgpgknn")
+	write := func(rel, content string) {
+		p := filepath.Join(extDir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("11.16.0_0/manifest.json", `{"name":"MetaMask","version":"11.16.0","icons":{"16":"images/icon-16.png","128":"../../secret.txt"},"action":{"default_icon":"images/icon-16.png"}}`)
+	write("11.16.0_0/images/icon-16.png", "png")
+	write("11.16.0_0/scripts/background.js", "console.log(1)")
+	if err := os.WriteFile(filepath.Join(dir, "Default", "secret.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewScanner(filepath.Join(dir, "evidence"))
+	art, err := s.SnapshotExtension("case_1", "dev_1", model.ExtensionRecord{Browser: "chrome", Profile: "Default", ExtensionID: "nkbihfbeogaeaoehlefnkodbefgpgknn", Path: extDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if art.Type != model.ArtifactBrowserExtSnapshot || art.SHA256 == "" {
+		t.Fatalf("artifact=%+v", art)
+	}
+
+	zr, err := zip.OpenReader(art.SnapshotPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	var names []string
+	var listing []ExtensionListingEntry
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name == "listing.json" {
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.NewDecoder(rc).Decode(&listing); err != nil {
+				t.Fatal(err)
+			}
+			rc.Close()
+		}
+	}
+	want := []string{"11.16.0_0/images/icon-16.png", "11.16.0_0/manifest.json", "listing.json"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("zip entries=%v", names)
+	}
+	if len(listing) != 3 || listing[0].SHA256 == "" {
+		t.Fatalf("listing=%+v", listing)
+	}
+}
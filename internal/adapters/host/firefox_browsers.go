@@ -0,0 +1,262 @@
+package host
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// Firefox 系浏览器（分支版与 Tor Browser）的 profile 定位
+//
+// Waterfox、LibreWolf、Floorp、Pale Moon、SeaMonkey 沿用 Firefox 的 profile 结构（{root}/{profile}/places.sqlite、
+// extensions.json），只是 profile 根目录不同；Tor Browser 的 profile 随程序目录分发：
+// Windows 为 {解压目录}/Browser/TorBrowser/Data/Browser/profile.default（默认解压到桌面），
+// macOS 新版在 ~/Library/Application Support/TorBrowser-Data/Browser，旧版在 Tor Browser.app/TorBrowser/Data/Browser。
+// profile 根目录与 Chromium 一样按三种途径收集（默认位置 / 运行中进程 / 常用目录浅层查找），按路径去重后
+// 由历史、扩展与 places.sqlite 快照共用。browser 字段标明具体分支（tor_browser / waterfox ...），profile 字段为 profile 目录名；
+// 扩展记录的 source 沿用 Chromium 的取值（known_path / running_process / portable_dir）。
+
+// firefoxSearchDepth 是常用目录下查找 profile 的递归深度：
+// Tor Browser 的 profile 位于解压目录下第 6 层（Tor Browser/Browser/TorBrowser/Data/Browser/profile.default）。
+const firefoxSearchDepth = 6
+
+// firefoxRoot 是一个 Firefox 系浏览器的 profile 根目录（其子目录为各 profile）。
+type firefoxRoot struct {
+	Browser string
+	Path    string
+	Source  string
+}
+
+// firefoxExeBrowsers 是已知 Firefox 内核浏览器的可执行文件名（小写，不含 .exe）。
+// Tor Browser 的可执行文件也叫 firefox，按目录布局识别。
+var firefoxExeBrowsers = map[string]string{
+	"firefox":   "firefox",
+	"waterfox":  "waterfox",
+	"librewolf": "librewolf",
+	"floorp":    "floorp",
+	"palemoon":  "palemoon",
+	"seamonkey": "seamonkey",
+}
+
+// windowsFirefoxRoots 返回 Windows 上各 Firefox 系浏览器的默认 profile 根目录（均在 %APPDATA% 下）。
+func windowsFirefoxRoots(roaming string) []firefoxRoot {
+	if roaming == "" {
+		return nil
+	}
+	var out []firefoxRoot
+	for _, r := range []struct{ browser, dir string }{
+		{"firefox", filepath.Join("Mozilla", "Firefox", "Profiles")},
+		{"waterfox", filepath.Join("Waterfox", "Profiles")},
+		{"librewolf", filepath.Join("librewolf", "Profiles")},
+		{"floorp", filepath.Join("Floorp", "Profiles")},
+		{"palemoon", filepath.Join("Moonchild Productions", "Pale Moon", "Profiles")},
+		{"seamonkey", filepath.Join("Mozilla", "SeaMonkey", "Profiles")},
+	} {
+		out = append(out, firefoxRoot{Browser: r.browser, Path: filepath.Join(roaming, r.dir), Source: ChromiumSourceKnown})
+	}
+	return out
+}
+
+// macFirefoxRoots 返回 macOS 上各 Firefox 系浏览器与 Tor Browser 的默认 profile 根目录。
+func macFirefoxRoots(home string) []firefoxRoot {
+	support := filepath.Join(home, "Library", "Application Support")
+	var out []firefoxRoot
+	for _, r := range []struct{ browser, dir string }{
+		{"firefox", filepath.Join(support, "Firefox", "Profiles")},
+		{"waterfox", filepath.Join(support, "Waterfox", "Profiles")},
+		{"librewolf", filepath.Join(support, "librewolf", "Profiles")},
+		{"floorp", filepath.Join(support, "Floorp", "Profiles")},
+		{"seamonkey", filepath.Join(support, "SeaMonkey", "Profiles")},
+		{"tor_browser", filepath.Join(support, "TorBrowser-Data", "Browser")},
+		{"tor_browser", filepath.Join("/Applications", "Tor Browser.app", "TorBrowser", "Data", "Browser")},
+	} {
+		out = append(out, firefoxRoot{Browser: r.browser, Path: r.dir, Source: ChromiumSourceKnown})
+	}
+	return out
+}
+
+// firefoxBrowserFromPath 按路径中的特征识别 Firefox 分支；识别不出时按 firefox 处理。
+func firefoxBrowserFromPath(path string) string {
+	p := strings.ToLower(filepath.ToSlash(path))
+	for _, c := range []struct{ marker, browser string }{
+		{"torbrowser", "tor_browser"},
+		{"tor browser", "tor_browser"},
+		{"tor-browser", "tor_browser"},
+		{"waterfox", "waterfox"},
+		{"librewolf", "librewolf"},
+		{"floorp", "floorp"},
+		{"pale moon", "palemoon"},
+		{"palemoon", "palemoon"},
+		{"seamonkey", "seamonkey"},
+	} {
+		if strings.Contains(p, c.marker) {
+			return c.browser
+		}
+	}
+	return "firefox"
+}
+
+// profileFromCmdline 取命令行中的 -profile / --profile 参数（支持引号；ps 输出不保留引号时取到下一个 " -" 为止）。
+func profileFromCmdline(cmdline string) string {
+	const flag = "-profile "
+	i := strings.Index(cmdline, flag)
+	if i < 0 {
+		return ""
+	}
+	v := strings.TrimSpace(cmdline[i+len(flag):])
+	if strings.HasPrefix(v, `"`) {
+		if j := strings.IndexByte(v[1:], '"'); j >= 0 {
+			return v[1 : j+1]
+		}
+		return strings.TrimPrefix(v, `"`)
+	}
+	if j := strings.Index(v, " -"); j >= 0 {
+		v = v[:j]
+	}
+	return strings.TrimSpace(v)
+}
+
+// firefoxRootsFromProcesses 从运行中的 Firefox 系进程推断 profile 根目录：
+// 命令行带 -profile 的取其上级目录；否则按 Tor Browser（exe 同级或 .app 内的 TorBrowser/Data/Browser）
+// 与 PortableApps（Data/profile）布局查找。
+func firefoxRootsFromProcesses(procs []browserProcess) []firefoxRoot {
+	var out []firefoxRoot
+	add := func(browser, root string) {
+		if browser == "firefox" {
+			browser = firefoxBrowserFromPath(root)
+		}
+		out = append(out, firefoxRoot{Browser: browser, Path: filepath.Clean(root), Source: ChromiumSourceProcess})
+	}
+	for _, p := range procs {
+		browser := firefoxExeBrowsers[exeBaseName(p.Exe)]
+		if browser == "" {
+			continue
+		}
+		if dir := profileFromCmdline(p.CommandLine); dir != "" {
+			if isFirefoxProfile(dir) {
+				add(browser, filepath.Dir(filepath.Clean(dir)))
+			}
+			continue
+		}
+		exeDir := filepath.Dir(p.Exe)
+		for _, c := range []string{
+			filepath.Join(exeDir, "TorBrowser", "Data", "Browser"),
+			filepath.Join(exeDir, "..", "..", "TorBrowser", "Data", "Browser"),
+			filepath.Join(exeDir, "..", "..", "Data"),
+		} {
+			if hasFirefoxProfiles(c) {
+				add(browser, c)
+			}
+		}
+	}
+	return out
+}
+
+// findPortableFirefoxRoots 在 dirs 下浅层查找 Firefox profile（Tor Browser 解压目录、Firefox Portable 等），返回其上级目录。
+func findPortableFirefoxRoots(ctx context.Context, dirs []string) []firefoxRoot {
+	var out []firefoxRoot
+	walkSearchDirs(ctx, dirs, firefoxSearchDepth, func(path string) bool {
+		if !isFirefoxProfile(path) {
+			return false
+		}
+		root := filepath.Dir(path)
+		out = append(out, firefoxRoot{Browser: firefoxBrowserFromPath(root), Path: root, Source: ChromiumSourcePortable})
+		return true
+	})
+	return out
+}
+
+// isFirefoxProfile 判断目录是否为 Firefox profile（存在 prefs.js 或 places.sqlite）。
+func isFirefoxProfile(dir string) bool {
+	for _, name := range []string{"prefs.js", "places.sqlite"} {
+		if st, err := os.Stat(filepath.Join(dir, name)); err == nil && st.Mode().IsRegular() {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFirefoxProfiles 判断目录下是否有 Firefox profile 子目录。
+func hasFirefoxProfiles(root string) bool {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		if e.IsDir() && isFirefoxProfile(filepath.Join(root, e.Name())) {
+			return true
+		}
+	}
+	return false
+}
+
+// discoverFirefoxRoots 收集在线扫描的 Firefox 系 profile 根目录（先出现的来源优先：known > process > portable）。
+// 进程列举与目录查找较慢，扫描时只调用一次，结果供扩展、历史与快照共用。
+func discoverFirefoxRoots(ctx context.Context, osType model.OSType) []firefoxRoot {
+	var roots []firefoxRoot
+	var home string
+	switch osType {
+	case model.OSWindows:
+		roots = windowsFirefoxRoots(os.Getenv("APPDATA"))
+		home = os.Getenv("USERPROFILE")
+	case model.OSMacOS:
+		home, _ = os.UserHomeDir()
+		if home == "" {
+			return nil
+		}
+		roots = macFirefoxRoots(home)
+	default:
+		return nil
+	}
+	roots = append(roots, firefoxRootsFromProcesses(listBrowserProcesses(ctx, osType))...)
+	roots = append(roots, findPortableFirefoxRoots(ctx, portableSearchDirs(osType, home))...)
+	return dedupeFirefoxRoots(roots)
+}
+
+// dedupeFirefoxRoots 按路径去重，保留先出现的记录。
+func dedupeFirefoxRoots(roots []firefoxRoot) []firefoxRoot {
+	seen := map[string]bool{}
+	var out []firefoxRoot
+	for _, r := range roots {
+		key := browserRootKey(r.Path)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, r)
+	}
+	return out
+}
+
+// scanFirefoxRoots 扫描各 profile 根目录下的扩展，并标明来源。
+func scanFirefoxRoots(roots []firefoxRoot) []model.ExtensionRecord {
+	var out []model.ExtensionRecord
+	for _, r := range roots {
+		for _, ext := range scanFirefoxExtensions(r.Path, r.Browser) {
+			ext.Source = r.Source
+			out = append(out, ext)
+		}
+	}
+	return out
+}
+
+// collectFirefoxRootsHistory 采集各 profile 根目录下的 places.sqlite 访问记录。
+func collectFirefoxRootsHistory(ctx context.Context, roots []firefoxRoot) []model.VisitRecord {
+	var out []model.VisitRecord
+	for _, r := range roots {
+		out = append(out, collectFirefoxHistory(ctx, r.Path, r.Browser)...)
+	}
+	return out
+}
+
+// firefoxRootsPlacesDBSpecs 返回各 profile 根目录下 places.sqlite 的快照描述。
+func firefoxRootsPlacesDBSpecs(roots []firefoxRoot) []historyDBSpec {
+	var out []historyDBSpec
+	for _, r := range roots {
+		out = append(out, firefoxPlacesDBSpecs(r.Path, r.Browser)...)
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package host
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFirefoxRootDiscovery(t *testing.T) {
+	dir := t.TempDir()
+	mk := func(content string, parts ...string) string {
+		p := filepath.Join(append([]string{dir}, parts...)...)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	addons := func(id string) string {
+		return `{"addons":[{"id":"` + id + `","type":"extension","version":"1.0","defaultLocale":{"name":"Wallet"}}]}`
+	}
+	// 解压在桌面的 Tor Browser、命令行 -profile 指定的 Waterfox、Firefox 默认目录。
+	torProfile := filepath.Join("Desktop", "Tor Browser", "Browser", "TorBrowser", "Data", "Browser", "profile.default")
+	mk("", filepath.Join(torProfile, "prefs.js"))
+	mk("x", filepath.Join(torProfile, "places.sqlite"))
+	mk(addons("webextension@metamask.io"), filepath.Join(torProfile, "extensions.json"))
+	torExe := mk("", "Desktop", "Tor Browser", "Browser", "firefox.exe")
+	mk("", "usb", "wf", "Profiles", "abc.default", "prefs.js")
+	mk(addons("{phantom}"), "usb", "wf", "Profiles", "abc.default", "extensions.json")
+	mk("x", "Mozilla", "Firefox", "Profiles", "xyz.default-release", "places.sqlite")
+
+	procs := parseWindowsProcesses([]byte(`[
+		{"ExecutablePath": "` + filepath.ToSlash(torExe) + `", "CommandLine": "firefox.exe -contentproc"},
+		{"ExecutablePath": "C:/Tools/Waterfox/waterfox.exe", "CommandLine": "\"C:/Tools/Waterfox/waterfox.exe\" -profile \"` + filepath.ToSlash(filepath.Join(dir, "usb", "wf", "Profiles", "abc.default")) + `\" -no-remote"},
+		{"ExecutablePath": "C:/Tools/brave.exe", "CommandLine": "brave.exe"}
+	]`))
+	fromProcs := firefoxRootsFromProcesses(procs)
+	if len(fromProcs) != 2 || fromProcs[0].Browser != "tor_browser" || fromProcs[1].Browser != "waterfox" || fromProcs[1].Source != ChromiumSourceProcess {
+		t.Fatalf("process roots=%+v", fromProcs)
+	}
+
+	portable := findPortableFirefoxRoots(context.Background(), []string{filepath.Join(dir, "Desktop")})
+	if len(portable) != 1 || portable[0].Browser != "tor_browser" || portable[0].Source != ChromiumSourcePortable {
+		t.Fatalf("portable roots=%+v", portable)
+	}
+
+	known := windowsFirefoxRoots(dir)
+	roots := dedupeFirefoxRoots(append(append(known, fromProcs...), portable...))
+	if len(roots) != len(known)+2 {
+		t.Fatalf("roots=%+v", roots)
+	}
+
+	bySource := map[string]string{}
+	for _, e := range scanFirefoxRoots(roots) {
+		bySource[e.ExtensionID] = e.Browser + "/" + e.Profile + "/" + e.Source
+	}
+	want := map[string]string{
+		"webextension@metamask.io": "tor_browser/profile.default/running_process", // 进程与目录查找到同一目录，只扫一次
+		"{phantom}":                "waterfox/abc.default/running_process",
+	}
+	if len(bySource) != len(want) {
+		t.Fatalf("extensions=%+v", bySource)
+	}
+	for id, w := range want {
+		if bySource[id] != w {
+			t.Fatalf("%s: got %q want %q", id, bySource[id], w)
+		}
+	}
+
+	labels := map[string]bool{}
+	for _, s := range firefoxRootsPlacesDBSpecs(roots) {
+		labels[s.Browser+"/"+s.Profile] = true
+	}
+	if len(labels) != 2 || !labels["tor_browser/profile.default"] || !labels["firefox/xyz.default-release"] {
+		t.Fatalf("places specs=%v", labels)
+	}
+}
+
+func TestFirefoxBrowserFromPath(t *testing.T) {
+	for path, want := range map[string]string{
+		"/Users/a/Library/Application Support/TorBrowser-Data/Browser": "tor_browser",
+		`C:\Users\a\AppData\Roaming\Moonchild Productions\Pale Moon`:   "palemoon",
+		"export/librewolf/Profiles":                                    "librewolf",
+		"export/Mozilla/Firefox/Profiles":                              "firefox",
+	} {
+		if got := firefoxBrowserFromPath(path); got != want {
+			t.Fatalf("%s: got %q want %q", path, got, want)
+		}
+	}
+	if p := profileFromCmdline("/Applications/Waterfox.app/Contents/MacOS/waterfox -profile /Volumes/USB/wf/abc.default -foreground"); p != "/Volumes/USB/wf/abc.default" {
+		t.Fatalf("profile=%q", p)
+	}
+}
@@ -0,0 +1,139 @@
+package host
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 浏览器表单来源 / 自动填充元数据
+//
+// 浏览记录只能说明“访问过”，而保存过登录表单或自动填充资料说明在站点上有过交互（登录、填写充值/提现表单）。
+// 这里只读取 Chromium 的两个库，并且只取来源与统计信息：
+// - Login Data.logins：origin_url / action_url / 字段名 / 使用次数与时间（不读取 username_value、password_value）
+// - Web Data 自动填充资料（autofill_profiles，新版为 local_addresses）：使用次数与时间，旧版本另有 origin
+// 表结构随版本变化，逐级降级查询；查询失败的库跳过。
+
+// formDataLimit 是单个库读取的最大行数。
+const formDataLimit = 2000
+
+var loginFormQueries = []string{
+	`SELECT COALESCE(origin_url, ''), COALESCE(action_url, ''), COALESCE(username_element, ''), COALESCE(password_element, ''),
+       COALESCE(times_used, 0), COALESCE(date_created, 0), COALESCE(date_last_used, 0)
+FROM logins ORDER BY date_created DESC LIMIT 2000`,
+	`SELECT COALESCE(origin_url, ''), COALESCE(action_url, ''), COALESCE(username_element, ''), COALESCE(password_element, ''),
+       0, COALESCE(date_created, 0), 0
+FROM logins LIMIT 2000`,
+}
+
+var autofillProfileQueries = []string{
+	`SELECT COALESCE(origin, ''), COALESCE(use_count, 0), COALESCE(use_date, 0), COALESCE(date_modified, 0) FROM autofill_profiles LIMIT 2000`,
+	`SELECT '', COALESCE(use_count, 0), COALESCE(use_date, 0), COALESCE(date_modified, 0) FROM autofill_profiles LIMIT 2000`,
+	`SELECT '', COALESCE(use_count, 0), COALESCE(use_date, 0), COALESCE(date_modified, 0) FROM local_addresses LIMIT 2000`,
+}
+
+// collectWindowsFormData 采集 Windows 下 Chrome/Edge 的表单来源与自动填充元数据。
+func collectWindowsFormData(ctx context.Context) []model.FormDataRecord {
+	local := os.Getenv("LOCALAPPDATA")
+	if local == "" {
+		return []model.FormDataRecord{}
+	}
+	out := collectChromiumFormData(ctx, filepath.Join(local, "Google", "Chrome", "User Data"), "chrome")
+	return append(out, collectChromiumFormData(ctx, filepath.Join(local, "Microsoft", "Edge", "User Data"), "edge")...)
+}
+
+// collectMacFormData 采集 macOS 下 Chrome/Edge 的表单来源与自动填充元数据。
+func collectMacFormData(ctx context.Context) []model.FormDataRecord {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return []model.FormDataRecord{}
+	}
+	out := collectChromiumFormData(ctx, filepath.Join(home, "Library", "Application Support", "Google", "Chrome"), "chrome")
+	return append(out, collectChromiumFormData(ctx, filepath.Join(home, "Library", "Application Support", "Microsoft Edge"), "edge")...)
+}
+
+// collectChromiumFormData 读取 profileRoot 下各 profile 的 Login Data 与 Web Data。
+func collectChromiumFormData(ctx context.Context, profileRoot, browser string) []model.FormDataRecord {
+	out := []model.FormDataRecord{}
+	logins, _ := filepath.Glob(filepath.Join(profileRoot, "*", "Login Data"))
+	for _, f := range logins {
+		profile := filepath.Base(filepath.Dir(f))
+		for _, r := range queryFirst(ctx, f, loginFormQueries) {
+			if len(r) < 7 {
+				continue
+			}
+			origin := strings.TrimSpace(r[0])
+			var fields []string
+			for _, name := range r[2:4] {
+				if name = strings.TrimSpace(name); name != "" {
+					fields = append(fields, name)
+				}
+			}
+			count, _ := parseInt64(r[4])
+			out = append(out, model.FormDataRecord{
+				Browser:    browser,
+				Profile:    profile,
+				Source:     "login_form",
+				Origin:     origin,
+				ActionURL:  strings.TrimSpace(r[1]),
+				Domain:     extractDomain(origin),
+				FieldNames: fields,
+				UseCount:   count,
+				CreatedAt:  chromeTimeOrZero(r[5]),
+				LastUsedAt: chromeTimeOrZero(r[6]),
+			})
+		}
+	}
+
+	webData, _ := filepath.Glob(filepath.Join(profileRoot, "*", "Web Data"))
+	for _, f := range webData {
+		profile := filepath.Base(filepath.Dir(f))
+		for _, r := range queryFirst(ctx, f, autofillProfileQueries) {
+			if len(r) < 4 {
+				continue
+			}
+			origin := strings.TrimSpace(r[0])
+			// Chromium 内置来源标记（如 "Chrome settings"）不是网址。
+			domain := ""
+			if strings.Contains(origin, "://") {
+				domain = extractDomain(origin)
+			}
+			count, _ := parseInt64(r[1])
+			used, _ := parseInt64(r[2])
+			modified, _ := parseInt64(r[3])
+			out = append(out, model.FormDataRecord{
+				Browser:    browser,
+				Profile:    profile,
+				Source:     "autofill_profile",
+				Origin:     origin,
+				Domain:     domain,
+				UseCount:   count,
+				CreatedAt:  modified,
+				LastUsedAt: used,
+			})
+		}
+	}
+	return out
+}
+
+// queryFirst 依次尝试 queries，返回第一条成功执行的结果（用于兼容不同版本的表结构）。
+func queryFirst(ctx context.Context, dbPath string, queries []string) [][]string {
+	for _, q := range queries {
+		rows, err := querySQLite(ctx, dbPath, q)
+		if err == nil {
+			return rows
+		}
+	}
+	return nil
+}
+
+// chromeTimeOrZero 转换 Chromium 时间；0/空值返回 0（而不是当前时间）。
+func chromeTimeOrZero(v string) int64 {
+	if iv, err := parseInt64(v); err != nil || iv <= 0 {
+		return 0
+	}
+	return chrometimeToEpoch(v)
+}
@@ -0,0 +1,61 @@
+package host
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestCollectChromiumFormData(t *testing.T) {
+	root := t.TempDir()
+	profile := filepath.Join(root, "Profile 1")
+	if err := os.MkdirAll(profile, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	const created = (int64(1709281800) + 11644473600) * 1_000_000
+	exec := func(path string, stmts ...string) {
+		t.Helper()
+		db, err := sql.Open("sqlite", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+		for _, stmt := range stmts {
+			if _, err := db.Exec(stmt); err != nil {
+				t.Fatalf("%s: %v", stmt, err)
+			}
+		}
+	}
+	exec(filepath.Join(profile, "Login Data"),
+		`CREATE TABLE logins (origin_url TEXT, action_url TEXT, username_element TEXT, username_value TEXT,
+			password_element TEXT, password_value BLOB, times_used INTEGER, date_created INTEGER, date_last_used INTEGER)`,
+		`INSERT INTO logins VALUES ('https://www.okx.com/account/login', 'https://www.okx.com/v2/login', 'email', 'alice@example.com',
+			'password', x'0102', 3, `+strconv.FormatInt(created, 10)+`, 0)`,
+	)
+	// 新版 Chromium 只有 local_addresses（无 origin 列）。
+	exec(filepath.Join(profile, "Web Data"),
+		`CREATE TABLE local_addresses (guid TEXT, use_count INTEGER, use_date INTEGER, date_modified INTEGER)`,
+		`INSERT INTO local_addresses VALUES ('g1', 2, 1709290000, 1709280000)`,
+	)
+
+	recs := collectChromiumFormData(context.Background(), root, "chrome")
+	if len(recs) != 2 {
+		t.Fatalf("records=%+v", recs)
+	}
+	login := recs[0]
+	if login.Source != "login_form" || login.Domain != "okx.com" || login.ActionURL != "https://www.okx.com/v2/login" ||
+		login.UseCount != 3 || login.CreatedAt != 1709281800 || login.LastUsedAt != 0 || login.Profile != "Profile 1" {
+		t.Fatalf("login=%+v", login)
+	}
+	if len(login.FieldNames) != 2 || login.FieldNames[0] != "email" || login.FieldNames[1] != "password" {
+		t.Fatalf("field names=%v", login.FieldNames)
+	}
+	if af := recs[1]; af.Source != "autofill_profile" || af.Domain != "" || af.UseCount != 2 || af.LastUsedAt != 1709290000 {
+		t.Fatalf("autofill=%+v", af)
+	}
+}
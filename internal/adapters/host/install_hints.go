@@ -0,0 +1,96 @@
+package host
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 安装目录特征采集（best effort）
+//
+// 很多桌面钱包是 Electron/Chromium 外壳 + 本地 LevelDB 存储。
+// 这里只做“浅层遍历 + 文件名特征”，不读取文件内容，避免在大目录上耗时过久。
+
+const (
+	installHintMaxDepth   = 4
+	installHintMaxEntries = 3000
+)
+
+// installHintMarkers 定义文件/目录名 -> 特征标签。
+var installHintMarkers = map[string]string{
+	"leveldb":                      "leveldb",
+	"app.asar":                     "electron_asar",
+	"electron framework.framework": "electron_runtime",
+	"chrome_100_percent.pak":       "chromium_runtime",
+	"resources.pak":                "chromium_runtime",
+	"icudtl.dat":                   "chromium_runtime",
+	"libegl.dll":                   "chromium_runtime",
+	"v8_context_snapshot.bin":      "chromium_runtime",
+	"keystore":                     "keystore",
+	"wallet.dat":                   "wallet_dat",
+}
+
+// annotateInstallHints 为每个应用补充 InstallHints（原地修改并返回）。
+func annotateInstallHints(ctx context.Context, apps []model.AppRecord) []model.AppRecord {
+	for i := range apps {
+		if ctx.Err() != nil {
+			break
+		}
+		dir := strings.TrimSpace(apps[i].InstallLocation)
+		if dir == "" {
+			dir = strings.TrimSpace(apps[i].Path)
+		}
+		if dir == "" {
+			continue
+		}
+		apps[i].InstallHints = detectInstallHints(dir)
+	}
+	return apps
+}
+
+// detectInstallHints 浅层遍历目录，返回命中的特征标签（去重排序）。
+func detectInstallHints(root string) []string {
+	root = filepath.Clean(strings.Trim(root, `"`))
+	baseDepth := strings.Count(root, string(filepath.Separator))
+
+	found := make(map[string]struct{})
+	entries := 0
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		entries++
+		if entries > installHintMaxEntries {
+			return fs.SkipAll
+		}
+		if d.IsDir() && strings.Count(path, string(filepath.Separator))-baseDepth > installHintMaxDepth {
+			return fs.SkipDir
+		}
+
+		name := strings.ToLower(d.Name())
+		if tag, ok := installHintMarkers[name]; ok {
+			found[tag] = struct{}{}
+		}
+		if !d.IsDir() && strings.HasSuffix(name, ".ldb") {
+			found["leveldb"] = struct{}{}
+		}
+		return nil
+	})
+
+	if len(found) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(found))
+	for tag := range found {
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,218 @@
+package host
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+)
+
+// 采集干扰（杀毒软件隔离）检测
+//
+// 部分杀毒软件会在扫描过程中隔离/改写我们刚写出的 SQLite 副本与证据快照，表现为文件“创建后立即消失或被改动”，
+// 最终结果里只看到证据缺失而没有原因。启用 InterferenceLog 后：
+// - 证据快照写出并计算哈希后，短暂等待再复核文件仍在、大小与修改时间未变，否则重写（最多 interferenceAttempts 次）
+// - SQLite 临时副本复制后立即消失时重新复制
+// 每次异常都记为一条事件，由 hostscan 固化为 collection_interference 证据，证据缺口有据可查。
+
+// 干扰事件类型。
+const (
+	InterferenceSnapshotMissing  = "snapshot_missing"
+	InterferenceSnapshotModified = "snapshot_modified"
+	InterferenceTempCopyMissing  = "temp_copy_missing"
+)
+
+// interferenceAttempts 是同一快照/副本的最大尝试次数（含首次）。
+const interferenceAttempts = 3
+
+// defaultInterferenceSettle 是快照写出后复核前的等待时间（给实时防护留出扫描并隔离的窗口）。
+const defaultInterferenceSettle = 150 * time.Millisecond
+
+// errInterference 表示文件在创建后立即消失或被改动。
+var errInterference = errors.New("file disappeared or changed right after creation (possible antivirus interference)")
+
+// InterferenceEvent 是一次快照/临时副本异常。
+type InterferenceEvent struct {
+	Kind      string `json:"kind"`
+	Path      string `json:"path"`
+	SourceRef string `json:"source_ref,omitempty"`
+	Attempt   int    `json:"attempt"`
+	// Recovered 为 true 表示后续重试成功（证据完整）；false 表示该来源最终缺失。
+	Recovered  bool   `json:"recovered"`
+	Error      string `json:"error,omitempty"`
+	DetectedAt int64  `json:"detected_at"`
+}
+
+// InterferenceLog 收集一次扫描中的干扰事件（并发安全；nil 表示不检测）。
+type InterferenceLog struct {
+	mu     sync.Mutex
+	items  []InterferenceEvent
+	settle time.Duration
+}
+
+func NewInterferenceLog() *InterferenceLog {
+	return &InterferenceLog{settle: defaultInterferenceSettle}
+}
+
+// Items 返回已记录的事件（按发生顺序）。
+func (l *InterferenceLog) Items() []InterferenceEvent {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]InterferenceEvent(nil), l.items...)
+}
+
+func (l *InterferenceLog) record(kind, path, ref string, attempt int, err error) {
+	if l == nil {
+		return
+	}
+	ev := InterferenceEvent{Kind: kind, Path: path, SourceRef: ref, Attempt: attempt, DetectedAt: time.Now().Unix()}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.items = append(l.items, ev)
+}
+
+// recovered 把路径或 source_ref 为 key 的事件标记为已恢复（重试成功后调用）。
+func (l *InterferenceLog) recovered(key string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range l.items {
+		if l.items[i].Path == key || (key != "" && l.items[i].SourceRef == key) {
+			l.items[i].Recovered = true
+		}
+	}
+}
+
+// verify 等待 settle 后复核 path 仍存在且大小、修改时间未变；正常返回空串，否则返回事件类型。
+func (l *InterferenceLog) verify(path string, size int64) string {
+	before, err := os.Stat(path)
+	if err != nil {
+		return InterferenceSnapshotMissing
+	}
+	time.Sleep(l.settle)
+	after, err := os.Stat(path)
+	switch {
+	case err != nil:
+		return InterferenceSnapshotMissing
+	case before.Size() != size || after.Size() != size || !after.ModTime().Equal(before.ModTime()):
+		return InterferenceSnapshotModified
+	default:
+		return ""
+	}
+}
+
+// writeVerified 调用 write 写出快照并计算哈希。未启用 Interference 时与直接写出相同；
+// 启用时复核快照，消失/被改动则记录事件并重写。write 因输入文件（如临时副本）不存在而失败时
+// 记为 temp_copy_missing 并返回包装了 errInterference 的错误，由调用方决定是否重新准备输入。
+func (s *Scanner) writeVerified(ref string, write func() (string, error)) (path, sum string, size int64, err error) {
+	l := s.Interference
+	for attempt := 1; ; attempt++ {
+		path, err = write()
+		if err != nil {
+			if l != nil && errors.Is(err, fs.ErrNotExist) {
+				l.record(InterferenceTempCopyMissing, path, ref, attempt, err)
+				return "", "", 0, fmt.Errorf("%w: %v", errInterference, err)
+			}
+			return "", "", 0, err
+		}
+		sum, size, err = hash.File(path)
+		kind := ""
+		switch {
+		case err != nil && (l == nil || !errors.Is(err, fs.ErrNotExist)):
+			return "", "", 0, fmt.Errorf("hash evidence file: %w", err)
+		case err != nil:
+			kind = InterferenceSnapshotMissing
+		case l != nil:
+			kind = l.verify(path, size)
+		}
+		if kind == "" {
+			if attempt > 1 {
+				l.recovered(path)
+			}
+			return path, sum, size, nil
+		}
+		l.record(kind, path, ref, attempt, err)
+		if attempt >= interferenceAttempts {
+			return "", "", 0, fmt.Errorf("%w: %s %s after %d attempts", errInterference, kind, path, attempt)
+		}
+	}
+}
+
+// copySQLiteWithRetry 复制 SQLite 副本；副本复制后立即消失时重新复制并记录到 l（nil 时同样重试，但不记录）。
+func copySQLiteWithRetry(l *InterferenceLog, src string) (dst string, cleanup func(), err error) {
+	for attempt := 1; ; attempt++ {
+		dst, cleanup, err = copySQLiteForRead(src)
+		if !errors.Is(err, errInterference) {
+			if err == nil && attempt > 1 {
+				l.recovered(src)
+			}
+			return dst, cleanup, err
+		}
+		l.record(InterferenceTempCopyMissing, src, "", attempt, err)
+		if attempt >= interferenceAttempts {
+			return "", nil, err
+		}
+	}
+}
+
+type interferenceLogKey struct{}
+
+// withInterferenceLog 把 l 挂到 ctx 上，供不持有 Scanner 的采集函数记录事件。
+func withInterferenceLog(ctx context.Context, l *InterferenceLog) context.Context {
+	if l == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, interferenceLogKey{}, l)
+}
+
+func interferenceFromContext(ctx context.Context) *InterferenceLog {
+	l, _ := ctx.Value(interferenceLogKey{}).(*InterferenceLog)
+	return l
+}
+
+// InterferenceArtifact 把本次扫描记录的干扰事件固化为一条 collection_interference 证据（无事件时返回 nil）。
+func (s *Scanner) InterferenceArtifact(caseID, deviceID string) ([]model.Artifact, error) {
+	events := s.Interference.Items()
+	if len(events) == 0 {
+		return nil, nil
+	}
+	unrecovered := 0
+	for _, ev := range events {
+		if !ev.Recovered {
+			unrecovered++
+		}
+	}
+	art, err := s.makeArtifact(caseID, deviceID, model.ArtifactCollectionInterference, "collection_interference", "integrity_recheck", map[string]any{
+		"kind":        string(model.ArtifactCollectionInterference),
+		"events":      events,
+		"unrecovered": unrecovered,
+		"hint":        InterferenceHint(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return []model.Artifact{art}, nil
+}
+
+// InterferenceHint 给出排除杀毒软件干扰的建议。
+func InterferenceHint() string {
+	if runtime.GOOS == "windows" {
+		return "add Microsoft Defender / antivirus exclusions for the evidence directory and %TEMP%\\crypto_inspector_sqlite_* (e.g. Add-MpPreference -ExclusionPath), then rerun the scan"
+	}
+	return "add antivirus exclusions for the evidence directory and the temp directory (crypto_inspector_sqlite_*), then rerun the scan"
+}
@@ -0,0 +1,61 @@
+package host
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestWriteVerified_RetriesQuarantinedSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	s := NewScanner(dir)
+	s.Interference = &InterferenceLog{}
+
+	// 第一次写出后文件立即被“隔离”，第二次正常。
+	calls := 0
+	path := filepath.Join(dir, "history.zip")
+	write := func() (string, error) {
+		calls++
+		if err := os.WriteFile(path, []byte("snapshot"), 0o644); err != nil {
+			return path, err
+		}
+		if calls == 1 {
+			_ = os.Remove(path)
+		}
+		return path, nil
+	}
+	got, sum, size, err := s.writeVerified("chrome_Default", write)
+	if err != nil || got != path || sum == "" || size != int64(len("snapshot")) {
+		t.Fatalf("writeVerified: path=%s sum=%s size=%d err=%v", got, sum, size, err)
+	}
+	events := s.Interference.Items()
+	if calls != 2 || len(events) != 1 || events[0].Kind != InterferenceSnapshotMissing || !events[0].Recovered {
+		t.Fatalf("calls=%d events=%+v", calls, events)
+	}
+
+	// 每次都被隔离：重试耗尽后返回 errInterference，事件未恢复。
+	s.Interference = &InterferenceLog{}
+	_, _, _, err = s.writeVerified("edge_Default", func() (string, error) {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			return path, err
+		}
+		return path, os.Remove(path)
+	})
+	if !errors.Is(err, errInterference) {
+		t.Fatalf("err=%v", err)
+	}
+	if events := s.Interference.Items(); len(events) != interferenceAttempts || events[0].Recovered {
+		t.Fatalf("events=%+v", events)
+	}
+
+	arts, err := s.InterferenceArtifact("case_1", "dev_1")
+	if err != nil || len(arts) != 1 || arts[0].Type != model.ArtifactCollectionInterference {
+		t.Fatalf("artifact: %+v err=%v", arts, err)
+	}
+	if arts, _ := NewScanner(dir).InterferenceArtifact("case_1", "dev_1"); arts != nil {
+		t.Fatal("no log, no artifact")
+	}
+}
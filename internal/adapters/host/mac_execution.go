@@ -0,0 +1,274 @@
+package host
+
+import (
+	"bufio"
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"howett.net/plist"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/toolbox"
+)
+
+// macOS 应用运行痕迹
+//
+// 嫌疑人卸载钱包时通常只是把 .app 拖进废纸篓，/Applications 扫描因此找不到；以下位置仍会留下记录：
+// - LaunchServices 登记（lsregister -dump）：登记过的每个 .app 路径，移入废纸篓后路径随之变为 ~/.Trash/...
+// - Dock（com.apple.dock.plist）：固定项与“最近使用的应用”，删除 .app 后条目仍保留
+// - Saved Application State：应用运行并打开过窗口才会生成 <bundle id>.savedState，目录修改时间接近最近一次使用
+// - 废纸篓中的 .app
+// 全部为只读读取；lsregister 不可用（离线目录）时只解析文件类来源。
+
+const lsregisterPath = "/System/Library/Frameworks/CoreServices.framework/Frameworks/LaunchServices.framework/Support/lsregister"
+
+// collectMacAppExecution 采集当前用户的应用运行/登记痕迹。
+func collectMacAppExecution(ctx context.Context) []model.AppExecutionRecord {
+	var out []model.AppExecutionRecord
+	if raw, err := toolbox.Output(ctx, lsregisterPath, "-dump"); err == nil {
+		out = append(out, parseLSRegisterDump(string(raw))...)
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		out = append(out, parseDockPlist(filepath.Join(home, "Library", "Preferences", "com.apple.dock.plist"))...)
+		out = append(out, collectSavedStates(filepath.Join(home, "Library", "Saved Application State"))...)
+		out = append(out, collectTrashApps(filepath.Join(home, ".Trash"))...)
+	}
+	return dedupeAppExecution(out)
+}
+
+// parseLSRegisterDump 解析 `lsregister -dump` 输出中的 .app 登记块（按分隔线切分，取 path/name/identifier/reg date）。
+// 系统自带应用（/System/ 下）数量大且与案件无关，直接跳过。
+//
+//	--------------------------------------------------------------------------------
+//	path:                       /Users/a/.Trash/Exodus.app (0x1a2b)
+//	name:                       Exodus
+//	identifier:                 com.exodus-movement.exodus
+//	reg date:                   2024-03-01 10:00:00
+func parseLSRegisterDump(raw string) []model.AppExecutionRecord {
+	var out []model.AppExecutionRecord
+	var cur model.AppExecutionRecord
+	flush := func() {
+		if strings.HasSuffix(strings.ToLower(cur.Path), ".app") && !strings.HasPrefix(cur.Path, "/System/") {
+			cur.Source = "launch_services"
+			cur.InTrash = isTrashPath(cur.Path)
+			if cur.Name == "" {
+				cur.Name = strings.TrimSuffix(filepath.Base(cur.Path), ".app")
+			}
+			out = append(out, cur)
+		}
+		cur = model.AppExecutionRecord{}
+	}
+	sc := bufio.NewScanner(strings.NewReader(raw))
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "-----") {
+			flush()
+			continue
+		}
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		val = strings.TrimSpace(val)
+		// 新版输出在路径、标识等值后附加 " (0x...)" 形式的内部 ID。
+		if i := strings.LastIndex(val, " (0x"); i > 0 && strings.HasSuffix(val, ")") {
+			val = val[:i]
+		}
+		switch strings.TrimSpace(key) {
+		case "path":
+			if cur.Path == "" {
+				cur.Path = val
+			}
+		case "name":
+			if cur.Name == "" {
+				cur.Name = val
+			}
+		case "identifier":
+			if cur.BundleID == "" {
+				cur.BundleID = val
+			}
+		case "reg date":
+			cur.LastUsedAt = parseLSDate(val)
+		}
+	}
+	flush()
+	return out
+}
+
+// parseLSDate 解析 lsregister 的登记时间（本地时区）；格式不识别时返回 0。
+func parseLSDate(v string) int64 {
+	for _, layout := range []string{"2006-01-02 15:04:05", "01/02/2006, 15:04:05", "2006-01-02T15:04:05Z07:00"} {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t.Unix()
+		}
+	}
+	return 0
+}
+
+// dockTile 是 com.apple.dock.plist 中 persistent-apps / recent-apps 的一项。
+type dockTile struct {
+	TileData struct {
+		FileLabel string `plist:"file-label"`
+		BundleID  string `plist:"bundle-identifier"`
+		FileData  struct {
+			URL string `plist:"_CFURLString"`
+		} `plist:"file-data"`
+	} `plist:"tile-data"`
+}
+
+// parseDockPlist 读取 Dock 固定项与最近使用的应用（XML / 二进制 plist 均可）。
+func parseDockPlist(path string) []model.AppExecutionRecord {
+	raw, err := os.ReadFile(path)
+	if err != nil || len(raw) == 0 {
+		return nil
+	}
+	var p struct {
+		PersistentApps []dockTile `plist:"persistent-apps"`
+		RecentApps     []dockTile `plist:"recent-apps"`
+	}
+	if _, err := plist.Unmarshal(raw, &p); err != nil {
+		return nil
+	}
+	var out []model.AppExecutionRecord
+	for _, group := range []struct {
+		source string
+		tiles  []dockTile
+	}{{"dock_persistent", p.PersistentApps}, {"dock_recent", p.RecentApps}} {
+		for _, t := range group.tiles {
+			appPath := fileURLPath(t.TileData.FileData.URL)
+			rec := model.AppExecutionRecord{
+				Source:     group.source,
+				Name:       strings.TrimSpace(t.TileData.FileLabel),
+				BundleID:   strings.TrimSpace(t.TileData.BundleID),
+				Path:       appPath,
+				InTrash:    isTrashPath(appPath),
+				SourcePath: path,
+			}
+			if rec.Name == "" && rec.BundleID == "" && rec.Path == "" {
+				continue
+			}
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+// collectSavedStates 列出 Saved Application State 下的 <bundle id>.savedState 目录。
+func collectSavedStates(dir string) []model.AppExecutionRecord {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var out []model.AppExecutionRecord
+	for _, e := range entries {
+		if rec, ok := savedStateRecord(filepath.Join(dir, e.Name())); ok {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+// savedStateRecord 把一个 .savedState 目录转换为运行痕迹。
+func savedStateRecord(path string) (model.AppExecutionRecord, bool) {
+	name := filepath.Base(path)
+	if !strings.HasSuffix(name, ".savedState") {
+		return model.AppExecutionRecord{}, false
+	}
+	info, err := os.Stat(path)
+	if err != nil || !info.IsDir() {
+		return model.AppExecutionRecord{}, false
+	}
+	bundleID := strings.TrimSuffix(name, ".savedState")
+	short := bundleID
+	if i := strings.LastIndex(bundleID, "."); i >= 0 && i+1 < len(bundleID) {
+		short = bundleID[i+1:]
+	}
+	return model.AppExecutionRecord{
+		Source:     "saved_state",
+		Name:       short,
+		BundleID:   bundleID,
+		LastUsedAt: info.ModTime().Unix(),
+		SourcePath: path,
+	}, true
+}
+
+// collectTrashApps 列出废纸篓中的 .app。
+func collectTrashApps(dir string) []model.AppExecutionRecord {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var out []model.AppExecutionRecord
+	for _, e := range entries {
+		if !e.IsDir() || !strings.HasSuffix(strings.ToLower(e.Name()), ".app") {
+			continue
+		}
+		out = append(out, trashAppRecord(filepath.Join(dir, e.Name())))
+	}
+	return out
+}
+
+// trashAppRecord 把废纸篓中的 .app 转换为运行痕迹（读取 Info.plist 取 bundle id，时间取目录修改时间）。
+func trashAppRecord(appPath string) model.AppExecutionRecord {
+	info := readMacAppInfo(appPath)
+	name := strings.TrimSpace(info.Name)
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(appPath), ".app")
+	}
+	rec := model.AppExecutionRecord{Source: "trash", Name: name, BundleID: info.BundleID, Path: appPath, InTrash: true}
+	if st, err := os.Stat(appPath); err == nil {
+		rec.LastUsedAt = st.ModTime().Unix()
+	}
+	return rec
+}
+
+// fileURLPath 把 file:///Applications/Exodus.app/ 转换为文件路径。
+func fileURLPath(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "file" {
+		return raw
+	}
+	p := u.Path
+	if len(p) > 1 {
+		p = strings.TrimSuffix(p, "/")
+	}
+	return p
+}
+
+func isTrashPath(p string) bool {
+	return strings.Contains(p, "/.Trash/") || strings.Contains(p, "/.Trashes/")
+}
+
+// dedupeAppExecution 按 (source, bundle id, path, name) 去重并排序，保证快照稳定。
+func dedupeAppExecution(in []model.AppExecutionRecord) []model.AppExecutionRecord {
+	out := []model.AppExecutionRecord{}
+	seen := map[string]struct{}{}
+	for _, r := range in {
+		key := strings.ToLower(strings.Join([]string{r.Source, r.BundleID, r.Path, r.Name}, "|"))
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Source != out[j].Source {
+			return out[i].Source < out[j].Source
+		}
+		if out[i].BundleID != out[j].BundleID {
+			return out[i].BundleID < out[j].BundleID
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
@@ -0,0 +1,85 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseLSRegisterDump(t *testing.T) {
+	dump := `Checking data integrity......done.
+--------------------------------------------------------------------------------
+bundle id:                  12345
+path:                       /Users/a/.Trash/Exodus.app (0x1a2b)
+name:                       Exodus
+identifier:                 com.exodus-movement.exodus (0x2b)
+reg date:                   2024-03-01 10:00:00
+--------------------------------------------------------------------------------
+path:                       /System/Applications/Mail.app
+name:                       Mail
+--------------------------------------------------------------------------------
+path:                       /Applications/Ledger Live.app
+identifier:                 com.ledger.live
+--------------------------------------------------------------------------------
+path:                       /Library/Frameworks/Foo.framework
+`
+	recs := parseLSRegisterDump(dump)
+	if len(recs) != 2 {
+		t.Fatalf("records=%+v", recs)
+	}
+	ex := recs[0]
+	want, _ := time.ParseInLocation("2006-01-02 15:04:05", "2024-03-01 10:00:00", time.Local)
+	if ex.Path != "/Users/a/.Trash/Exodus.app" || !ex.InTrash || ex.Name != "Exodus" || ex.BundleID != "com.exodus-movement.exodus" || ex.LastUsedAt != want.Unix() || ex.Source != "launch_services" {
+		t.Fatalf("exodus=%+v", ex)
+	}
+	if recs[1].Name != "Ledger Live" || recs[1].BundleID != "com.ledger.live" || recs[1].InTrash {
+		t.Fatalf("ledger=%+v", recs[1])
+	}
+}
+
+func TestParseDockPlistAndSavedState(t *testing.T) {
+	dir := t.TempDir()
+	dock := filepath.Join(dir, "com.apple.dock.plist")
+	xml := `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0"><dict>
+<key>persistent-apps</key><array>
+ <dict><key>tile-data</key><dict>
+  <key>file-label</key><string>Exodus</string>
+  <key>bundle-identifier</key><string>com.exodus-movement.exodus</string>
+  <key>file-data</key><dict><key>_CFURLString</key><string>file:///Applications/Exodus.app/</string></dict>
+ </dict></dict>
+</array>
+<key>recent-apps</key><array>
+ <dict><key>tile-data</key><dict>
+  <key>file-label</key><string>Ledger Live</string>
+  <key>file-data</key><dict><key>_CFURLString</key><string>file:///Users/a/.Trash/Ledger%20Live.app/</string></dict>
+ </dict></dict>
+</array>
+</dict></plist>`
+	if err := os.WriteFile(dock, []byte(xml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recs := parseDockPlist(dock)
+	if len(recs) != 2 {
+		t.Fatalf("dock records=%+v", recs)
+	}
+	if recs[0].Source != "dock_persistent" || recs[0].Path != "/Applications/Exodus.app" || recs[0].BundleID != "com.exodus-movement.exodus" {
+		t.Fatalf("persistent=%+v", recs[0])
+	}
+	if recs[1].Source != "dock_recent" || recs[1].Path != "/Users/a/.Trash/Ledger Live.app" || !recs[1].InTrash {
+		t.Fatalf("recent=%+v", recs[1])
+	}
+
+	states := filepath.Join(dir, "Saved Application State")
+	if err := os.MkdirAll(filepath.Join(states, "com.exodus-movement.exodus.savedState"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(states, "notes.txt"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	saved := collectSavedStates(states)
+	if len(saved) != 1 || saved[0].BundleID != "com.exodus-movement.exodus" || saved[0].Name != "exodus" || saved[0].LastUsedAt == 0 {
+		t.Fatalf("saved states=%+v", saved)
+	}
+}
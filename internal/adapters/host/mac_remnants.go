@@ -0,0 +1,199 @@
+package host
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/toolbox"
+)
+
+// macOS 钥匙串 / TCC 中的应用痕迹
+//
+// 钱包应用删除后，以下记录通常不会随之清理，仍能说明应用曾安装并运行过：
+// - 钥匙串：Electron 钱包（Exodus、Atomic 等）首次运行时写入 "<应用名> Safe Storage" 应用密码条目
+// - TCC：应用申请过摄像头/辅助功能/文件访问等权限后，TCC.db 的 access 表保留其 bundle id 或可执行文件路径
+// 只采集元数据：钥匙串用 `security dump-keychain`（不带 -d，不输出密码，也不会弹出授权框），TCC.db 只读副本查询；
+// 用户级 TCC.db 需要完全磁盘访问权限，系统级还需要 root，读取失败时按权限不足留痕（见 access.go）。
+
+const systemTCCDB = "/Library/Application Support/com.apple.TCC/TCC.db"
+
+// collectMacAppRemnants 采集当前用户钥匙串的应用密码标签与用户级/系统级 TCC 授权记录。
+func collectMacAppRemnants(ctx context.Context) []model.AppRemnantRecord {
+	var out []model.AppRemnantRecord
+	if raw, err := toolbox.Output(ctx, "security", "dump-keychain"); err == nil {
+		out = append(out, parseKeychainDump(string(raw))...)
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		out = append(out, collectTCCRecords(ctx, filepath.Join(home, "Library", "Application Support", "com.apple.TCC", "TCC.db"))...)
+	}
+	out = append(out, collectTCCRecords(ctx, systemTCCDB)...)
+	return dedupeAppRemnants(out)
+}
+
+// parseKeychainDump 解析 `security dump-keychain` 输出中的应用密码条目（class "genp"），取 labl/svce/mdat。
+//
+//	keychain: "/Users/a/Library/Keychains/login.keychain-db"
+//	class: "genp"
+//	attributes:
+//	    "labl"<blob>="Exodus Safe Storage"
+//	    "mdat"<timedate>=0x32303234...  "20240301100000Z\000"
+//	    "svce"<blob>="Exodus Safe Storage"
+func parseKeychainDump(raw string) []model.AppRemnantRecord {
+	var out []model.AppRemnantRecord
+	var keychain, class string
+	var cur model.AppRemnantRecord
+	flush := func() {
+		if class == "genp" {
+			if cur.Label == "" {
+				cur.Label = cur.Service
+			}
+			if cur.Label != "" {
+				cur.Source = "keychain"
+				cur.SourcePath = keychain
+				out = append(out, cur)
+			}
+		}
+		cur, class = model.AppRemnantRecord{}, ""
+	}
+	sc := bufio.NewScanner(strings.NewReader(raw))
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(line, "keychain:"):
+			flush()
+			keychain = keychainValue(strings.TrimSpace(strings.TrimPrefix(line, "keychain:")))
+		case strings.HasPrefix(line, "class:"):
+			class = strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "class:")), `"`)
+		case strings.HasPrefix(line, `"`):
+			// "labl"<blob>="..."：四字符属性名 + <类型> + = + 值。
+			name, rest, ok := strings.Cut(line[1:], `"`)
+			if !ok {
+				continue
+			}
+			_, val, ok := strings.Cut(rest, "=")
+			if !ok {
+				continue
+			}
+			switch name {
+			case "labl":
+				cur.Label = keychainValue(val)
+			case "svce":
+				cur.Service = keychainValue(val)
+			case "mdat":
+				if t, err := time.Parse("20060102150405Z", keychainValue(val)); err == nil {
+					cur.ModifiedAt = t.Unix()
+				}
+			}
+		}
+	}
+	flush()
+	return out
+}
+
+// keychainValue 取属性值的文本：`"text"`、`0x<hex>  "text"`（非 ASCII 或日期，按十六进制解码，文本部分为八进制转义）或 `<NULL>`。
+func keychainValue(v string) string {
+	v = strings.TrimSpace(v)
+	if strings.HasPrefix(v, "0x") {
+		h, _, _ := strings.Cut(v[2:], " ")
+		if b, err := hex.DecodeString(h); err == nil {
+			return strings.TrimSpace(strings.TrimRight(string(b), "\x00"))
+		}
+		return ""
+	}
+	if len(v) < 2 || !strings.HasPrefix(v, `"`) || !strings.HasSuffix(v, `"`) {
+		return ""
+	}
+	v = strings.TrimSuffix(v[1:len(v)-1], `\000`)
+	return strings.TrimSpace(v)
+}
+
+// tccQueries 按新到旧的 TCC.db 结构依次尝试：Big Sur 起为 auth_value（0 拒绝 / 2 允许 / 3 受限），之前为 allowed（0/1）。
+var tccQueries = []string{
+	`SELECT service, client, client_type,
+  CASE auth_value WHEN 0 THEN 'denied' WHEN 2 THEN 'allowed' WHEN 3 THEN 'limited' ELSE 'unknown' END,
+  COALESCE(last_modified, 0)
+FROM access`,
+	`SELECT service, client, client_type, CASE allowed WHEN 1 THEN 'allowed' ELSE 'denied' END, COALESCE(last_modified, 0)
+FROM access`,
+	`SELECT service, client, client_type, CASE allowed WHEN 1 THEN 'allowed' ELSE 'denied' END, 0
+FROM access`,
+}
+
+// collectTCCRecords 读取 TCC.db 的 access 表；系统组件（com.apple.*、/System/、/usr/）的授权数量大且与案件无关，直接跳过。
+func collectTCCRecords(ctx context.Context, dbPath string) []model.AppRemnantRecord {
+	if _, err := os.Stat(dbPath); err != nil {
+		recordAccess(ctx, AccessCategoryDirectory, dbPath, err)
+		return nil
+	}
+	var rows [][]string
+	var err error
+	for _, q := range tccQueries {
+		if rows, err = querySQLite(ctx, dbPath, q); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil
+	}
+	var out []model.AppRemnantRecord
+	for _, r := range rows {
+		if len(r) < 5 {
+			continue
+		}
+		client := strings.TrimSpace(r[1])
+		if client == "" || strings.HasPrefix(client, "com.apple.") || strings.HasPrefix(client, "/System/") || strings.HasPrefix(client, "/usr/") {
+			continue
+		}
+		clientType := "bundle_id"
+		if r[2] == "1" {
+			clientType = "path"
+		}
+		modified, _ := strconv.ParseInt(r[4], 10, 64)
+		out = append(out, model.AppRemnantRecord{
+			Source:     "tcc",
+			Service:    strings.TrimSpace(r[0]),
+			Client:     client,
+			ClientType: clientType,
+			Auth:       r[3],
+			ModifiedAt: modified,
+			SourcePath: dbPath,
+		})
+	}
+	return out
+}
+
+// dedupeAppRemnants 按 (source, label, service, client, source_path) 去重并排序，保证快照稳定。
+func dedupeAppRemnants(in []model.AppRemnantRecord) []model.AppRemnantRecord {
+	out := []model.AppRemnantRecord{}
+	seen := map[string]struct{}{}
+	for _, r := range in {
+		key := strings.Join([]string{r.Source, r.Label, r.Service, r.Client, r.SourcePath}, "|")
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Source != out[j].Source {
+			return out[i].Source < out[j].Source
+		}
+		if out[i].Label != out[j].Label {
+			return out[i].Label < out[j].Label
+		}
+		if out[i].Client != out[j].Client {
+			return out[i].Client < out[j].Client
+		}
+		return out[i].Service < out[j].Service
+	})
+	return out
+}
@@ -0,0 +1,88 @@
+package host
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestParseKeychainDump(t *testing.T) {
+	dump := `keychain: "/Users/a/Library/Keychains/login.keychain-db"
+version: 512
+class: "genp"
+attributes:
+    0x00000007 <blob>="Exodus Safe Storage"
+    "acct"<blob>="Exodus Key"
+    "cdat"<timedate>=0x32303234303330313130303030305A00  "20240301100000Z\000"
+    "labl"<blob>=<NULL>
+    "mdat"<timedate>=0x32303234303330313130303030305A00  "20240301100000Z\000"
+    "svce"<blob>="Exodus Safe Storage"
+keychain: "/Users/a/Library/Keychains/login.keychain-db"
+version: 512
+class: "inet"
+attributes:
+    "acct"<blob>="alice@example.com"
+    "labl"<blob>="www.binance.com (alice@example.com)"
+    "srvr"<blob>="www.binance.com"
+keychain: "/Users/a/Library/Keychains/login.keychain-db"
+version: 512
+class: 0x80001000
+attributes:
+    "labl"<blob>="Apple Worldwide Developer Relations"
+keychain: "/Library/Keychains/System.keychain"
+version: 256
+class: "genp"
+attributes:
+    "labl"<blob>=0xE992B1E58C85  "\351\222\261\345\214\205"
+    "svce"<blob>="wallet"
+`
+	recs := parseKeychainDump(dump)
+	if len(recs) != 2 {
+		t.Fatalf("records=%+v", recs)
+	}
+	if r := recs[0]; r.Source != "keychain" || r.Label != "Exodus Safe Storage" || r.ModifiedAt != 1709287200 || r.SourcePath != "/Users/a/Library/Keychains/login.keychain-db" {
+		t.Fatalf("exodus=%+v", r)
+	}
+	if r := recs[1]; r.Label != "钱包" || r.Service != "wallet" || r.SourcePath != "/Library/Keychains/System.keychain" {
+		t.Fatalf("system=%+v", r)
+	}
+}
+
+func TestCollectTCCRecords(t *testing.T) {
+	ctx := context.Background()
+	for name, schema := range map[string]string{
+		"auth_value": `CREATE TABLE access (service TEXT, client TEXT, client_type INTEGER, auth_value INTEGER, last_modified INTEGER)`,
+		"allowed":    `CREATE TABLE access (service TEXT, client TEXT, client_type INTEGER, allowed INTEGER, last_modified INTEGER)`,
+	} {
+		path := filepath.Join(t.TempDir(), "TCC.db")
+		db, err := sql.Open("sqlite", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, stmt := range []string{
+			schema,
+			`INSERT INTO access VALUES ('kTCCServiceCamera', 'com.exodus-movement.exodus', 0, 2, 1709287200)`,
+			`INSERT INTO access VALUES ('kTCCServiceAccessibility', '/Applications/Atomic Wallet.app/Contents/MacOS/Atomic Wallet', 1, 0, 0)`,
+			`INSERT INTO access VALUES ('kTCCServiceCamera', 'com.apple.FaceTime', 0, 2, 0)`,
+		} {
+			if _, err := db.Exec(stmt); err != nil {
+				t.Fatalf("%s: %v", stmt, err)
+			}
+		}
+		db.Close()
+
+		recs := dedupeAppRemnants(collectTCCRecords(ctx, path))
+		if len(recs) != 2 {
+			t.Fatalf("%s: records=%+v", name, recs)
+		}
+		if r := recs[0]; r.ClientType != "path" || r.Auth != "denied" || r.Service != "kTCCServiceAccessibility" {
+			t.Fatalf("%s: atomic=%+v", name, r)
+		}
+		if r := recs[1]; r.Client != "com.exodus-movement.exodus" || r.ClientType != "bundle_id" || r.ModifiedAt != 1709287200 || r.SourcePath != path {
+			t.Fatalf("%s: exodus=%+v", name, r)
+		}
+	}
+}
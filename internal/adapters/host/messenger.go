@@ -0,0 +1,279 @@
+package host
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// Telegram Desktop / Discord 痕迹（可选采集，scan host --scan-messengers）
+//
+// OTC 承兑、带单、内幕群等交易活动常在 Telegram 频道或 Discord 服务器中组织：
+// - install / data_dir：客户端安装位置与本地数据目录存在即记录
+// - Telegram 本地数据（tdata / Postbox）是加密的，不解析；只读取用户导出的聊天记录
+//   （Downloads/Telegram Desktop/ChatExport_*/result.json）中的会话名称与类型
+// - Discord 为 Electron 客户端，Local Storage 与 HTTP 缓存里常有明文的服务器/频道对象，
+//   按 {"id":"<snowflake>","name":"..."} 抽取名称（压缩过的缓存条目无法读取，属于 best effort）
+// 只记录名称、ID 与所在文件，不读取消息内容。
+
+const (
+	// messengerMaxFileBytes 限制单个缓存/导出文件的读取大小。
+	messengerMaxFileBytes = 64 << 20
+	// messengerMaxFiles 限制 Discord 缓存目录的扫描文件数。
+	messengerMaxFiles = 3000
+	// messengerMaxChannels 限制抽取的名称数量，避免缓存异常时结果膨胀。
+	messengerMaxChannels = 2000
+)
+
+// reDiscordObject 匹配 Discord API 对象开头的 id/name 字段对（服务器与频道对象均以此开头）。
+var reDiscordObject = regexp.MustCompile(`"id":\s*"(\d{17,20})",\s*"name":\s*"((?:[^"\\]|\\.){1,100})"`)
+
+// messengerHint 是一个已知安装位置或数据目录。
+type messengerHint struct {
+	App  string
+	Kind string
+	Path string
+}
+
+// messengerSources 汇总一个系统上的探测位置。
+type messengerSources struct {
+	hints           []messengerHint
+	telegramExports []string // 聊天记录导出根目录
+	discordCaches   []string // Discord 缓存目录（只扫描一层）
+}
+
+// collectWindowsMessengers 探测 Windows 主机上的 Telegram Desktop / Discord。
+func collectWindowsMessengers(ctx context.Context) []model.MessengerRecord {
+	local := os.Getenv("LOCALAPPDATA")
+	roaming := os.Getenv("APPDATA")
+	profile := os.Getenv("USERPROFILE")
+
+	var src messengerSources
+	if roaming != "" {
+		src.hints = append(src.hints,
+			messengerHint{App: "telegram", Kind: "install", Path: filepath.Join(roaming, "Telegram Desktop", "Telegram.exe")},
+			messengerHint{App: "telegram", Kind: "data_dir", Path: filepath.Join(roaming, "Telegram Desktop", "tdata")},
+			messengerHint{App: "discord", Kind: "data_dir", Path: filepath.Join(roaming, "discord")},
+		)
+		src.discordCaches = discordCacheDirs(filepath.Join(roaming, "discord"))
+	}
+	if local != "" {
+		src.hints = append(src.hints, messengerHint{App: "discord", Kind: "install", Path: filepath.Join(local, "Discord")})
+	}
+	if profile != "" {
+		src.telegramExports = append(src.telegramExports, filepath.Join(profile, "Downloads", "Telegram Desktop"))
+	}
+	return detectMessengers(ctx, src)
+}
+
+// collectMacMessengers 探测 macOS 主机上的 Telegram / Discord。
+func collectMacMessengers(ctx context.Context) []model.MessengerRecord {
+	src := messengerSources{hints: []messengerHint{
+		{App: "telegram", Kind: "install", Path: "/Applications/Telegram.app"},
+		{App: "telegram", Kind: "install", Path: "/Applications/Telegram Desktop.app"},
+		{App: "discord", Kind: "install", Path: "/Applications/Discord.app"},
+	}}
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		support := filepath.Join(home, "Library", "Application Support")
+		src.hints = append(src.hints,
+			messengerHint{App: "telegram", Kind: "data_dir", Path: filepath.Join(support, "Telegram Desktop", "tdata")},
+			messengerHint{App: "telegram", Kind: "data_dir", Path: filepath.Join(home, "Library", "Group Containers", "6N38VWS5BX.ru.keepcoder.Telegram")},
+			messengerHint{App: "discord", Kind: "data_dir", Path: filepath.Join(support, "discord")},
+		)
+		src.discordCaches = discordCacheDirs(filepath.Join(support, "discord"))
+		src.telegramExports = append(src.telegramExports, filepath.Join(home, "Downloads", "Telegram Desktop"))
+	}
+	return detectMessengers(ctx, src)
+}
+
+// discordCacheDirs 返回 Discord 数据目录下可能含明文服务器/频道对象的缓存目录。
+func discordCacheDirs(dataDir string) []string {
+	return []string{
+		filepath.Join(dataDir, "Local Storage", "leveldb"),
+		filepath.Join(dataDir, "Cache", "Cache_Data"),
+		filepath.Join(dataDir, "Cache"),
+	}
+}
+
+// detectMessengers 检查安装位置与数据目录，并抽取导出记录与缓存中的频道名称（结果按 app、kind、name 排序）。
+func detectMessengers(ctx context.Context, src messengerSources) []model.MessengerRecord {
+	out := []model.MessengerRecord{}
+	for _, h := range src.hints {
+		info, err := os.Stat(h.Path)
+		if err != nil {
+			continue
+		}
+		out = append(out, model.MessengerRecord{App: h.App, Kind: h.Kind, Path: h.Path, ModifiedAt: info.ModTime().Unix()})
+	}
+
+	var channels []model.MessengerRecord
+	for _, root := range src.telegramExports {
+		channels = append(channels, collectTelegramExports(ctx, root)...)
+	}
+	for _, dir := range src.discordCaches {
+		channels = append(channels, scanDiscordCache(ctx, dir)...)
+	}
+	seen := map[string]struct{}{}
+	for _, c := range channels {
+		key := c.App + "|" + c.ChannelID + "|" + strings.ToLower(c.Name)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		if len(seen) >= messengerMaxChannels {
+			break
+		}
+		seen[key] = struct{}{}
+		out = append(out, c)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].App != out[j].App {
+			return out[i].App < out[j].App
+		}
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
+
+// telegramChat 是 Telegram 导出 JSON 中的会话头部（消息内容不解码）。
+type telegramChat struct {
+	Name string          `json:"name"`
+	Type string          `json:"type"`
+	ID   json.RawMessage `json:"id"`
+}
+
+// collectTelegramExports 在导出根目录下（两层以内）查找 result.json 并读取会话名称。
+// 单会话导出的顶层即会话；整体导出在 chats.list / left_chats.list 中。
+func collectTelegramExports(ctx context.Context, root string) []model.MessengerRecord {
+	var out []model.MessengerRecord
+	base := strings.Count(filepath.Clean(root), string(filepath.Separator))
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if strings.Count(filepath.Clean(path), string(filepath.Separator))-base >= 2 {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "result.json" {
+			return nil
+		}
+		out = append(out, parseTelegramExport(path)...)
+		return nil
+	})
+	return out
+}
+
+// parseTelegramExport 解析一个 result.json。
+func parseTelegramExport(path string) []model.MessengerRecord {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() > messengerMaxFileBytes {
+		return nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var doc struct {
+		telegramChat
+		Chats struct {
+			List []telegramChat `json:"list"`
+		} `json:"chats"`
+		LeftChats struct {
+			List []telegramChat `json:"list"`
+		} `json:"left_chats"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil
+	}
+	chats := append([]telegramChat{doc.telegramChat}, doc.Chats.List...)
+	chats = append(chats, doc.LeftChats.List...)
+	var out []model.MessengerRecord
+	for _, c := range chats {
+		name := strings.TrimSpace(c.Name)
+		if name == "" {
+			continue
+		}
+		out = append(out, model.MessengerRecord{
+			App:        "telegram",
+			Kind:       "channel",
+			Name:       name,
+			ChannelID:  strings.Trim(string(c.ID), `"`),
+			ChatType:   c.Type,
+			Path:       path,
+			ModifiedAt: info.ModTime().Unix(),
+		})
+	}
+	return out
+}
+
+// scanDiscordCache 扫描缓存目录（一层）中的文件，抽取明文服务器/频道对象名称。
+func scanDiscordCache(ctx context.Context, dir string) []model.MessengerRecord {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var out []model.MessengerRecord
+	files := 0
+	for _, e := range entries {
+		if ctx.Err() != nil || files >= messengerMaxFiles {
+			break
+		}
+		if !e.Type().IsRegular() {
+			continue
+		}
+		files++
+		path := filepath.Join(dir, e.Name())
+		info, err := e.Info()
+		if err != nil || info.Size() == 0 || info.Size() > messengerMaxFileBytes {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		raw, err := io.ReadAll(io.LimitReader(f, messengerMaxFileBytes))
+		_ = f.Close()
+		if err != nil {
+			continue
+		}
+		for _, m := range reDiscordObject.FindAllSubmatch(raw, -1) {
+			name, err := strconv.Unquote(`"` + string(m[2]) + `"`)
+			if err != nil {
+				name = string(m[2])
+			}
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			out = append(out, model.MessengerRecord{
+				App:        "discord",
+				Kind:       "channel",
+				Name:       name,
+				ChannelID:  string(m[1]),
+				Path:       path,
+				ModifiedAt: info.ModTime().Unix(),
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,56 @@
+package host
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectMessengers(t *testing.T) {
+	dir := t.TempDir()
+	tdata := filepath.Join(dir, "Telegram Desktop", "tdata")
+	exportDir := filepath.Join(dir, "Downloads", "Telegram Desktop", "ChatExport_2024-03-01")
+	cache := filepath.Join(dir, "discord", "Local Storage", "leveldb")
+	for _, d := range []string{tdata, exportDir, cache} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	export := `{"name":"USDT OTC 承兑群","type":"private_supergroup","id":1234567890,"messages":[{"id":1,"text":"hi"}]}`
+	if err := os.WriteFile(filepath.Join(exportDir, "result.json"), []byte(export), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	blob := "\x00\x01junk{\"id\":\"912345678901234567\",\"name\":\"Binance \\u4e2d\\u6587\",\"icon\":null}more" +
+		"{\"id\": \"912345678901234568\", \"name\": \"general\"}"
+	if err := os.WriteFile(filepath.Join(cache, "000003.log"), []byte(blob), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	recs := detectMessengers(context.Background(), messengerSources{
+		hints: []messengerHint{
+			{App: "telegram", Kind: "data_dir", Path: tdata},
+			{App: "discord", Kind: "install", Path: filepath.Join(dir, "missing")},
+		},
+		telegramExports: []string{filepath.Join(dir, "Downloads", "Telegram Desktop")},
+		discordCaches:   []string{cache},
+	})
+	if len(recs) != 4 {
+		t.Fatalf("records=%+v", recs)
+	}
+	byName := map[string]string{}
+	for _, r := range recs {
+		byName[r.App+"/"+r.Kind+"/"+r.Name] = r.ChannelID
+	}
+	for key, id := range map[string]string{
+		"discord/channel/Binance 中文":    "912345678901234567",
+		"discord/channel/general":       "912345678901234568",
+		"telegram/channel/USDT OTC 承兑群": "1234567890",
+		"telegram/data_dir/":            "",
+	} {
+		got, ok := byName[key]
+		if !ok || got != id {
+			t.Fatalf("missing %s (id %q): %+v", key, id, recs)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package host
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 离线采集（scan offline）
+//
+// 输入是其他团队拷贝出来的目录（浏览器 profile、注册表 hive、macOS .app），
+// 不访问当前主机，按文件特征定位后复用在线采集的解析器：
+// - Chromium profile：{root}/{profile}/History、{root}/{profile}/Extensions
+// - Firefox 系 profile（含 Tor Browser、Waterfox 等分支，按路径识别）：{root}/{profile}/places.sqlite、extensions.json
+// - Safari：History.db
+// - 注册表 hive（SOFTWARE / NTUSER.DAT，按 regf 文件头识别）：卸载项
+// - macOS 应用：*.app/Contents/Info.plist
+// - macOS 运行痕迹：com.apple.dock.plist、*.savedState、.Trash 下的 .app；TCC 授权记录：TCC.db
+// 所有证据的 acquisition_method 统一为 offline_import。
+
+// AcquisitionOffline 是离线导入证据的获取方式。
+const AcquisitionOffline = "offline_import"
+
+// offlineMaxEntries 限制遍历的目录项数量，避免误选整块磁盘时长时间卡住。
+const offlineMaxEntries = 200000
+
+// DirDigest 是离线输入目录的摘要：按相对路径排序后逐行拼接 "path\tsha256\tsize"，再整体 sha256。
+type DirDigest struct {
+	SHA256     string `json:"sha256"`
+	FileCount  int    `json:"file_count"`
+	TotalBytes int64  `json:"total_bytes"`
+}
+
+// HashDirectory 计算目录摘要（同样的目录内容得到同样的哈希，用于证明离线输入未被改动）。
+func HashDirectory(ctx context.Context, root string) (DirDigest, error) {
+	type entry struct {
+		rel  string
+		sum  string
+		size int64
+	}
+	var entries []entry
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(entries) >= offlineMaxEntries {
+			return fmt.Errorf("too many files under %s (limit %d)", root, offlineMaxEntries)
+		}
+		sum, size, err := hash.File(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		entries = append(entries, entry{rel: filepath.ToSlash(rel), sum: sum, size: size})
+		return nil
+	})
+	if err != nil {
+		return DirDigest{}, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].rel < entries[j].rel })
+
+	h := sha256.New()
+	var total int64
+	for _, e := range entries {
+		fmt.Fprintf(h, "%s\t%s\t%d\n", e.rel, e.sum, e.size)
+		total += e.size
+	}
+	return DirDigest{SHA256: hex.EncodeToString(h.Sum(nil)), FileCount: len(entries), TotalBytes: total}, nil
+}
+
+// offlineSources 是在输入目录中定位到的可解析来源。
+type offlineSources struct {
+	chromiumRoots map[string]string // profile 根目录 -> browser
+	firefoxRoots  map[string]string // profile 根目录 -> browser（firefox / tor_browser / waterfox ...）
+	safariDBs     []string
+	hives         []string
+	macApps       []string
+	dockPlists    []string
+	savedStates   []string
+	tccDBs        []string
+}
+
+func discoverOfflineSources(ctx context.Context, root string) (*offlineSources, error) {
+	src := &offlineSources{chromiumRoots: map[string]string{}, firefoxRoots: map[string]string{}}
+	n := 0
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			recordAccess(ctx, AccessCategoryDirectory, path, err)
+			return nil // 无权限的子目录跳过
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if n++; n > offlineMaxEntries {
+			return fmt.Errorf("too many entries under %s (limit %d)", root, offlineMaxEntries)
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if strings.HasSuffix(name, ".savedState") {
+				src.savedStates = append(src.savedStates, path)
+				return filepath.SkipDir
+			}
+			if strings.HasSuffix(strings.ToLower(name), ".app") {
+				if _, err := os.Stat(filepath.Join(path, "Contents", "Info.plist")); err == nil {
+					src.macApps = append(src.macApps, path)
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		// 解析器按 {root}/{profile}/文件 的布局 glob，profile 根目录必须仍在输入目录内，避免扫到输入目录之外。
+		profileRoot := filepath.Dir(filepath.Dir(path))
+		switch {
+		case name == "History" && withinDir(root, profileRoot):
+			rel, _ := filepath.Rel(root, path)
+			src.chromiumRoots[profileRoot] = chromiumBrowserFromPath(rel)
+		case name == "places.sqlite" && withinDir(root, profileRoot):
+			rel, _ := filepath.Rel(root, profileRoot)
+			src.firefoxRoots[profileRoot] = firefoxBrowserFromPath(rel)
+		case name == "History.db":
+			src.safariDBs = append(src.safariDBs, path)
+		case name == "com.apple.dock.plist":
+			src.dockPlists = append(src.dockPlists, path)
+		case name == "TCC.db":
+			src.tccDBs = append(src.tccDBs, path)
+		default:
+			if isRegistryHive(path) {
+				src.hives = append(src.hives, path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return src, nil
+}
+
+// guessOS 根据来源推断离线数据所属系统（注册表 → windows；Safari/.app → macos）。
+func (s *offlineSources) guessOS() model.OSType {
+	switch {
+	case len(s.hives) > 0:
+		return model.OSWindows
+	case len(s.safariDBs) > 0 || len(s.macApps) > 0 || len(s.dockPlists) > 0 || len(s.savedStates) > 0 || len(s.tccDBs) > 0:
+		return model.OSMacOS
+	}
+	return ""
+}
+
+// appExecution 汇总离线目录中的 macOS 运行痕迹（离线时无法执行 lsregister）。
+func (s *offlineSources) appExecution() []model.AppExecutionRecord {
+	var out []model.AppExecutionRecord
+	for _, p := range s.dockPlists {
+		out = append(out, parseDockPlist(p)...)
+	}
+	for _, p := range s.savedStates {
+		if rec, ok := savedStateRecord(p); ok {
+			out = append(out, rec)
+		}
+	}
+	for _, p := range s.macApps {
+		if !isTrashPath(filepath.ToSlash(p)) {
+			continue
+		}
+		out = append(out, trashAppRecord(p))
+	}
+	return dedupeAppExecution(out)
+}
+
+// appRemnants 解析离线目录中的 TCC.db（钥匙串文件为加密格式，离线不解析）。
+func (s *offlineSources) appRemnants(ctx context.Context) []model.AppRemnantRecord {
+	var out []model.AppRemnantRecord
+	for _, p := range s.tccDBs {
+		out = append(out, collectTCCRecords(ctx, p)...)
+	}
+	return dedupeAppRemnants(out)
+}
+
+func withinDir(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// chromiumBrowserFromPath 按路径（例如 Microsoft/Edge/User Data/Default/History）推断浏览器，
+// 离线输入目录与便携版浏览器目录共用。
+func chromiumBrowserFromPath(path string) string {
+	p := strings.ToLower(filepath.ToSlash(path))
+	for _, b := range []string{"edge", "brave", "opera", "vivaldi", "360chrome", "360se", "chromium"} {
+		if strings.Contains(p, b) {
+			return b
+		}
+	}
+	return "chrome"
+}
+
+// OfflineDevice 为离线输入目录构造设备信息：os 为空时按目录内容推断，标识取目录摘要。
+func OfflineDevice(ctx context.Context, inputDir string, osType model.OSType, name string, digest DirDigest) (model.Device, error) {
+	if osType == "" {
+		src, err := discoverOfflineSources(ctx, inputDir)
+		if err != nil {
+			return model.Device{}, err
+		}
+		osType = src.guessOS()
+	}
+	if osType != model.OSWindows && osType != model.OSMacOS {
+		return model.Device{}, fmt.Errorf("cannot determine offline source os (windows|macos): %q", osType)
+	}
+	if strings.TrimSpace(name) == "" {
+		name = filepath.Base(filepath.Clean(inputDir))
+	}
+	return model.Device{
+		ID:         id.New("dev"),
+		Name:       strings.TrimSpace(name),
+		OS:         osType,
+		Identifier: "offline:" + digest.SHA256[:16],
+	}, nil
+}
+
+// ScanOffline 对离线目录执行与在线扫描相同的三类采集（安装软件 / 扩展 / 浏览历史）与原始库快照。
+func (s *Scanner) ScanOffline(ctx context.Context, caseID string, device model.Device, inputDir string) ([]model.Artifact, error) {
+	ctx = withAccessLog(ctx, s.Access)
+	ctx = withInterferenceLog(ctx, s.Interference)
+	src, err := discoverOfflineSources(ctx, inputDir)
+	if err != nil {
+		return nil, err
+	}
+	prefix := "offline_" + string(device.OS)
+
+	var apps []model.AppRecord
+	var appErrs []string
+	for _, hv := range src.hives {
+		rows, err := collectHiveInstalledApps(hv)
+		if err != nil {
+			s.Access.Record(AccessCategoryRegistryHive, hv, err)
+			appErrs = append(appErrs, filepath.Base(hv)+": "+err.Error())
+			continue
+		}
+		apps = append(apps, rows...)
+	}
+	for _, appPath := range src.macApps {
+		info := readMacAppInfo(appPath)
+		name := strings.TrimSpace(info.Name)
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(appPath), ".app")
+		}
+		apps = append(apps, model.AppRecord{Name: name, Version: info.Version, BundleID: info.BundleID, Path: appPath})
+	}
+	apps = dedupeApps(apps)
+
+	var ext []model.ExtensionRecord
+	var visits []model.VisitRecord
+	var forms []model.FormDataRecord
+	var specs []historyDBSpec
+	roots := make([]string, 0, len(src.chromiumRoots))
+	for r := range src.chromiumRoots {
+		roots = append(roots, r)
+	}
+	sort.Strings(roots)
+	for _, r := range roots {
+		browser := src.chromiumRoots[r]
+		ext = append(ext, scanChromiumExtensions(r, browser)...)
+		visits = append(visits, collectChromiumHistory(ctx, r, browser)...)
+		forms = append(forms, collectChromiumFormData(ctx, r, browser)...)
+		specs = append(specs, chromiumHistoryDBSpecs(r, browser)...)
+	}
+	roots = roots[:0]
+	for r := range src.firefoxRoots {
+		roots = append(roots, r)
+	}
+	sort.Strings(roots)
+	for _, r := range roots {
+		browser := src.firefoxRoots[r]
+		ext = append(ext, scanFirefoxExtensions(r, browser)...)
+		visits = append(visits, collectFirefoxHistory(ctx, r, browser)...)
+		specs = append(specs, firefoxPlacesDBSpecs(r, browser)...)
+	}
+	for _, db := range src.safariDBs {
+		visits = append(visits, collectSafariHistory(ctx, db)...)
+		specs = append(specs, safariHistoryDBSpecs(db)...)
+	}
+	ext = dedupeExtensions(ext)
+	visits = dedupeVisits(visits)
+
+	var out []model.Artifact
+	for _, item := range []struct {
+		t       model.ArtifactType
+		ref     string
+		payload any
+	}{
+		{model.ArtifactInstalledApps, prefix + "_apps", apps},
+		{model.ArtifactBrowserExt, prefix + "_browser_extensions", ext},
+		{model.ArtifactBrowserHistory, prefix + "_browser_history", visits},
+		{model.ArtifactBrowserFormData, prefix + "_browser_form_data", forms},
+	} {
+		parts, err := s.makeArtifactParts(caseID, device.ID, item.t, item.ref, AcquisitionOffline, item.payload)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, parts...)
+	}
+	if device.OS == model.OSMacOS {
+		artifact, err := s.makeArtifact(caseID, device.ID, model.ArtifactAppExecution, prefix+"_app_execution", AcquisitionOffline, src.appExecution())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, artifact)
+		artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactAppRemnants, prefix+"_app_remnants", AcquisitionOffline, src.appRemnants(ctx))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, artifact)
+	}
+	for _, a := range s.snapshotHistoryDBArtifacts(caseID, device.ID, specs) {
+		a.AcquisitionMethod = AcquisitionOffline
+		out = append(out, a)
+	}
+
+	var parts []string
+	if len(appErrs) > 0 {
+		parts = append(parts, "hives: "+strings.Join(appErrs, ", "))
+	}
+	if len(src.hives) == 0 && len(src.macApps) == 0 {
+		parts = append(parts, "apps: no registry hive or .app bundle found")
+	}
+	if len(visits) == 0 {
+		parts = append(parts, "history: no history records collected")
+	}
+	if len(parts) > 0 {
+		return out, errors.New(strings.Join(parts, "; "))
+	}
+	return out, nil
+}
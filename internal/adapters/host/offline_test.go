@@ -0,0 +1,226 @@
+package host
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"unicode/utf16"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// hiveBuilder 生成最小可解析的 regf 文件（只包含测试用到的 nk/vk/lf 记录）。
+type hiveBuilder struct{ buf []byte }
+
+func newHiveBuilder() *hiveBuilder {
+	b := &hiveBuilder{buf: make([]byte, regfBaseBlockSize+0x20)}
+	copy(b.buf, "regf")
+	copy(b.buf[regfBaseBlockSize:], "hbin")
+	return b
+}
+
+func (b *hiveBuilder) cell(data []byte) uint32 {
+	off := uint32(len(b.buf) - regfBaseBlockSize)
+	size := (len(data) + 4 + 7) &^ 7
+	c := make([]byte, size)
+	binary.LittleEndian.PutUint32(c, uint32(-int32(size)))
+	copy(c[4:], data)
+	b.buf = append(b.buf, c...)
+	return off
+}
+
+func (b *hiveBuilder) key(name string, subkeys, values []uint32) uint32 {
+	d := make([]byte, 0x4c+len(name))
+	copy(d, "nk")
+	binary.LittleEndian.PutUint16(d[2:], 0x20)
+	if len(subkeys) > 0 {
+		lf := make([]byte, 4+8*len(subkeys))
+		copy(lf, "lf")
+		binary.LittleEndian.PutUint16(lf[2:], uint16(len(subkeys)))
+		for i, off := range subkeys {
+			binary.LittleEndian.PutUint32(lf[4+i*8:], off)
+		}
+		binary.LittleEndian.PutUint32(d[0x14:], uint32(len(subkeys)))
+		binary.LittleEndian.PutUint32(d[0x1c:], b.cell(lf))
+	}
+	if len(values) > 0 {
+		list := make([]byte, 4*len(values))
+		for i, off := range values {
+			binary.LittleEndian.PutUint32(list[i*4:], off)
+		}
+		binary.LittleEndian.PutUint32(d[0x24:], uint32(len(values)))
+		binary.LittleEndian.PutUint32(d[0x28:], b.cell(list))
+	}
+	binary.LittleEndian.PutUint16(d[0x48:], uint16(len(name)))
+	copy(d[0x4c:], name)
+	return b.cell(d)
+}
+
+func (b *hiveBuilder) stringValue(name, value string) uint32 {
+	u := utf16.Encode([]rune(value + "\x00"))
+	data := make([]byte, 2*len(u))
+	for i, r := range u {
+		binary.LittleEndian.PutUint16(data[i*2:], r)
+	}
+	d := make([]byte, 0x14+len(name))
+	copy(d, "vk")
+	binary.LittleEndian.PutUint16(d[2:], uint16(len(name)))
+	binary.LittleEndian.PutUint32(d[4:], uint32(len(data)))
+	binary.LittleEndian.PutUint32(d[8:], b.cell(data))
+	binary.LittleEndian.PutUint32(d[12:], regSZ)
+	binary.LittleEndian.PutUint16(d[16:], 0x1)
+	copy(d[0x14:], name)
+	return b.cell(d)
+}
+
+func (b *hiveBuilder) write(t *testing.T, path string, root uint32) {
+	t.Helper()
+	binary.LittleEndian.PutUint32(b.buf[0x24:], root)
+	if err := os.WriteFile(path, b.buf, 0o644); err != nil {
+		t.Fatalf("write hive: %v", err)
+	}
+}
+
+func TestScanOfflineHiveAndChromiumProfile(t *testing.T) {
+	ctx := context.Background()
+	input := t.TempDir()
+
+	// SOFTWARE hive：Microsoft\Windows\CurrentVersion\Uninstall\{Exodus, NoName}
+	hb := newHiveBuilder()
+	exodus := hb.key("Exodus", nil, []uint32{
+		hb.stringValue("DisplayName", "Exodus"),
+		hb.stringValue("DisplayVersion", "24.1.1"),
+		hb.stringValue("Publisher", "Exodus Movement Inc"),
+	})
+	noName := hb.key("KB123", nil, []uint32{hb.stringValue("Comments", "hotfix")})
+	uninstall := hb.key("Uninstall", []uint32{exodus, noName}, nil)
+	cur := hb.key("CurrentVersion", []uint32{uninstall}, nil)
+	win := hb.key("Windows", []uint32{cur}, nil)
+	ms := hb.key("Microsoft", []uint32{win}, nil)
+	root := hb.key("ROOT", []uint32{ms}, nil)
+	if err := os.MkdirAll(filepath.Join(input, "hives"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	hb.write(t, filepath.Join(input, "hives", "SOFTWARE"), root)
+
+	// Chromium profile：Chrome/User Data/Default/History
+	profile := filepath.Join(input, "Chrome", "User Data", "Default")
+	if err := os.MkdirAll(profile, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", filepath.Join(profile, "History"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const visitTime = (int64(1709281800) + 11644473600) * 1_000_000
+	for _, stmt := range []string{
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT, title TEXT)`,
+		`CREATE TABLE visits (id INTEGER PRIMARY KEY, url INTEGER, visit_time INTEGER)`,
+		`INSERT INTO urls (id, url, title) VALUES (1, 'https://www.binance.com/en', 'Binance')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	if _, err := db.Exec(`INSERT INTO visits (url, visit_time) VALUES (1, ?)`, visitTime); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	digest, err := HashDirectory(ctx, input)
+	if err != nil || digest.FileCount != 2 || len(digest.SHA256) != 64 {
+		t.Fatalf("digest=%+v err=%v", digest, err)
+	}
+	again, _ := HashDirectory(ctx, input)
+	if again.SHA256 != digest.SHA256 {
+		t.Fatalf("digest not stable: %s != %s", again.SHA256, digest.SHA256)
+	}
+
+	dev, err := OfflineDevice(ctx, input, "", "", digest)
+	if err != nil || dev.OS != model.OSWindows || dev.Identifier != "offline:"+digest.SHA256[:16] {
+		t.Fatalf("device=%+v err=%v", dev, err)
+	}
+
+	s := NewScanner(t.TempDir())
+	arts, err := s.ScanOffline(ctx, "case_1", dev, input)
+	if err != nil {
+		t.Fatalf("ScanOffline: %v", err)
+	}
+	var apps []model.AppRecord
+	var visits []model.VisitRecord
+	hasDBSnapshot := false
+	for _, a := range arts {
+		if a.AcquisitionMethod != AcquisitionOffline {
+			t.Fatalf("artifact %s acquisition_method=%s", a.Type, a.AcquisitionMethod)
+		}
+		switch a.Type {
+		case model.ArtifactInstalledApps:
+			_ = json.Unmarshal(a.PayloadJSON, &apps)
+		case model.ArtifactBrowserHistory:
+			_ = json.Unmarshal(a.PayloadJSON, &visits)
+		case model.ArtifactBrowserHistoryDB:
+			hasDBSnapshot = true
+		}
+	}
+	if len(apps) != 1 || apps[0].Name != "Exodus" || apps[0].Version != "24.1.1" || apps[0].Publisher != "Exodus Movement Inc" {
+		t.Fatalf("apps=%+v", apps)
+	}
+	if len(visits) != 1 || visits[0].Domain != "binance.com" || visits[0].Browser != "chrome" || visits[0].Profile != "Default" || visits[0].VisitedAt != 1709281800 {
+		t.Fatalf("visits=%+v", visits)
+	}
+	if !hasDBSnapshot {
+		t.Fatalf("missing browser_history_db snapshot")
+	}
+}
+
+func TestCollectChromiumHistoryTransitionAndReferrer(t *testing.T) {
+	root := t.TempDir()
+	profile := filepath.Join(root, "Default")
+	if err := os.MkdirAll(profile, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", filepath.Join(profile, "History"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	const base = (int64(1709281800) + 11644473600) * 1_000_000
+	for _, stmt := range []string{
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT, title TEXT)`,
+		`CREATE TABLE visits (id INTEGER PRIMARY KEY, url INTEGER, visit_time INTEGER, from_visit INTEGER, transition INTEGER)`,
+		`INSERT INTO urls (id, url, title) VALUES (1, 'https://search.example/?q=okx', 'search'), (2, 'https://www.okx.com/', 'OKX'), (3, 'https://www.okx.com/balance/recharge', 'Deposit')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	// 0x20000000 = LINK | CHAIN_END；7 = FORM_SUBMIT；0x30000001 = TYPED | CHAIN_START | CHAIN_END
+	for _, v := range [][4]int64{{1, 1, 0, 1}, {2, 2, 1, 0x20000000}, {3, 3, 2, 7}, {4, 2, 0, 0x30000001}} {
+		if _, err := db.Exec(`INSERT INTO visits (id, url, visit_time, from_visit, transition) VALUES (?, ?, ?, ?, ?)`,
+			v[0], v[1], base+(v[0]-1)*1_000_000, v[2], v[3]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	visits := collectChromiumHistory(context.Background(), root, "chrome")
+	if len(visits) != 4 {
+		t.Fatalf("visits=%+v", visits)
+	}
+	byTime := map[int64]model.VisitRecord{}
+	for _, v := range visits {
+		byTime[v.VisitedAt-1709281800] = v
+	}
+	if v := byTime[1]; v.Transition != "link" || v.ReferrerURL != "https://search.example/?q=okx" {
+		t.Fatalf("link visit=%+v", v)
+	}
+	if v := byTime[2]; v.Transition != "form_submit" || v.ReferrerURL != "https://www.okx.com/" {
+		t.Fatalf("form visit=%+v", v)
+	}
+	if v := byTime[3]; v.Transition != "typed" || v.ReferrerURL != "" {
+		t.Fatalf("typed visit=%+v", v)
+	}
+}
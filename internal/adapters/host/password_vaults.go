@@ -0,0 +1,229 @@
+package host
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 密码管理器保险库识别
+//
+// 交易所凭据往往保存在密码管理器里，起诉/调证时需要知道保险库是否存在、在哪里：
+// - app：已知安装位置存在即记录（1Password/Bitwarden/KeePass/KeePassXC/MacPass/Strongbox）
+// - vault_file：各产品默认数据位置的保险库文件（1Password sqlite、Bitwarden data.json），
+//   以及用户常用目录、云盘同步目录下的 KeePass 数据库（.kdbx/.kdb，校验文件签名）
+// 只记录路径、大小、修改时间，不读取保险库内容。
+
+// pwVaultMaxDepth 限制 KeePass 数据库查找的递归深度。
+const pwVaultMaxDepth = 4
+
+// KeePass 数据库文件签名（前 8 字节）。
+var (
+	kdbxSignature = []byte{0x03, 0xd9, 0xa2, 0x9a, 0x67, 0xfb, 0x4b, 0xb5}
+	kdbSignature  = []byte{0x03, 0xd9, 0xa2, 0x9a, 0x65, 0xfb, 0x4b, 0xb5}
+)
+
+// pwAppHint 是一个密码管理器的已知安装位置。
+type pwAppHint struct {
+	Product string
+	Name    string
+	Path    string
+}
+
+// pwVaultFile 是一个已知位置的保险库文件。
+type pwVaultFile struct {
+	Product string
+	Format  string
+	Path    string
+}
+
+// collectWindowsPasswordVaults 探测 Windows 主机上的密码管理器与保险库文件。
+func collectWindowsPasswordVaults(ctx context.Context) []model.PasswordVaultRecord {
+	local := os.Getenv("LOCALAPPDATA")
+	roaming := os.Getenv("APPDATA")
+	profile := os.Getenv("USERPROFILE")
+
+	var apps []pwAppHint
+	for _, pf := range []string{os.Getenv("ProgramFiles"), os.Getenv("ProgramFiles(x86)")} {
+		if pf == "" {
+			continue
+		}
+		apps = append(apps,
+			pwAppHint{Product: "keepass", Name: "KeePass Password Safe 2", Path: filepath.Join(pf, "KeePass Password Safe 2")},
+			pwAppHint{Product: "keepassxc", Name: "KeePassXC", Path: filepath.Join(pf, "KeePassXC")},
+			pwAppHint{Product: "1password", Name: "1Password", Path: filepath.Join(pf, "1Password")},
+		)
+	}
+	var files []pwVaultFile
+	if local != "" {
+		apps = append(apps,
+			pwAppHint{Product: "1password", Name: "1Password", Path: filepath.Join(local, "1Password", "app")},
+			pwAppHint{Product: "bitwarden", Name: "Bitwarden", Path: filepath.Join(local, "Programs", "Bitwarden")},
+		)
+		files = append(files,
+			pwVaultFile{Product: "1password", Format: "sqlite", Path: filepath.Join(local, "1Password", "data", "1password.sqlite")},
+			pwVaultFile{Product: "1password", Format: "sqlite", Path: filepath.Join(local, "1Password", "data", "1Password10.sqlite")},
+		)
+	}
+	if roaming != "" {
+		files = append(files, pwVaultFile{Product: "bitwarden", Format: "json", Path: filepath.Join(roaming, "Bitwarden", "data.json")})
+	}
+	var roots []string
+	if profile != "" {
+		for _, d := range []string{"Documents", "Desktop", "Downloads", "OneDrive", "Dropbox"} {
+			roots = append(roots, filepath.Join(profile, d))
+		}
+	}
+	return detectPasswordVaults(ctx, apps, files, roots)
+}
+
+// collectMacPasswordVaults 探测 macOS 主机上的密码管理器与保险库文件。
+func collectMacPasswordVaults(ctx context.Context) []model.PasswordVaultRecord {
+	apps := []pwAppHint{
+		{Product: "1password", Name: "1Password", Path: "/Applications/1Password.app"},
+		{Product: "1password", Name: "1Password 7", Path: "/Applications/1Password 7.app"},
+		{Product: "bitwarden", Name: "Bitwarden", Path: "/Applications/Bitwarden.app"},
+		{Product: "keepassxc", Name: "KeePassXC", Path: "/Applications/KeePassXC.app"},
+		{Product: "macpass", Name: "MacPass", Path: "/Applications/MacPass.app"},
+		{Product: "strongbox", Name: "Strongbox", Path: "/Applications/Strongbox.app"},
+	}
+	var files []pwVaultFile
+	var roots []string
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		group := filepath.Join(home, "Library", "Group Containers")
+		support := filepath.Join(home, "Library", "Application Support")
+		files = append(files,
+			pwVaultFile{Product: "1password", Format: "sqlite", Path: filepath.Join(group, "2BUA8C4S2C.com.1password", "Library", "Application Support", "1Password", "Data", "1password.sqlite")},
+			pwVaultFile{Product: "1password", Format: "sqlite", Path: filepath.Join(group, "2BUA8C4S2C.com.agilebits", "Library", "Application Support", "1Password", "Data", "B5.sqlite")},
+			pwVaultFile{Product: "bitwarden", Format: "json", Path: filepath.Join(support, "Bitwarden", "data.json")},
+			pwVaultFile{Product: "bitwarden", Format: "json", Path: filepath.Join(home, "Library", "Containers", "com.bitwarden.desktop", "Data", "Library", "Application Support", "Bitwarden", "data.json")},
+		)
+		for _, d := range []string{"Documents", "Desktop", "Downloads", "Dropbox"} {
+			roots = append(roots, filepath.Join(home, d))
+		}
+		roots = append(roots, filepath.Join(home, "Library", "Mobile Documents", "com~apple~CloudDocs"))
+	}
+	return detectPasswordVaults(ctx, apps, files, roots)
+}
+
+// detectPasswordVaults 检查安装位置与已知保险库文件，并在 roots 下查找 KeePass 数据库（结果按 kind、path 排序）。
+func detectPasswordVaults(ctx context.Context, apps []pwAppHint, files []pwVaultFile, roots []string) []model.PasswordVaultRecord {
+	out := []model.PasswordVaultRecord{}
+	seen := map[string]struct{}{}
+	add := func(rec model.PasswordVaultRecord) {
+		if _, ok := seen[rec.Path]; ok {
+			return
+		}
+		seen[rec.Path] = struct{}{}
+		out = append(out, rec)
+	}
+	for _, a := range apps {
+		info, err := os.Stat(a.Path)
+		if err != nil {
+			continue
+		}
+		add(model.PasswordVaultRecord{Kind: "app", Product: a.Product, Name: a.Name, Path: a.Path, ModifiedAt: info.ModTime().Unix()})
+	}
+	for _, f := range files {
+		info, err := os.Stat(f.Path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		add(model.PasswordVaultRecord{
+			Kind:       "vault_file",
+			Product:    f.Product,
+			Name:       filepath.Base(f.Path),
+			Path:       f.Path,
+			Format:     f.Format,
+			SizeBytes:  info.Size(),
+			ModifiedAt: info.ModTime().Unix(),
+			Note:       "default data location",
+		})
+	}
+	for _, root := range roots {
+		for _, rec := range findKeePassDatabases(ctx, root) {
+			add(rec)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Kind != out[j].Kind {
+			return out[i].Kind < out[j].Kind
+		}
+		return out[i].Path < out[j].Path
+	})
+	return out
+}
+
+// findKeePassDatabases 在 root 下（限定深度）查找 .kdbx/.kdb 文件，并用文件签名确认格式。
+func findKeePassDatabases(ctx context.Context, root string) []model.PasswordVaultRecord {
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil
+	}
+	var out []model.PasswordVaultRecord
+	base := strings.Count(filepath.Clean(root), string(os.PathSeparator))
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if path != root && strings.Count(path, string(os.PathSeparator))-base >= pwVaultMaxDepth {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if !d.Type().IsRegular() || (ext != ".kdbx" && ext != ".kdb") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		format, note := keePassFormat(path, strings.TrimPrefix(ext, "."))
+		out = append(out, model.PasswordVaultRecord{
+			Kind:       "vault_file",
+			Product:    "keepass",
+			Name:       filepath.Base(path),
+			Path:       path,
+			Format:     format,
+			SizeBytes:  info.Size(),
+			ModifiedAt: info.ModTime().Unix(),
+			Note:       note,
+		})
+		return nil
+	})
+	return out
+}
+
+// keePassFormat 读取文件签名确认 KeePass 格式；签名不符时仍按扩展名记录并注明。
+func keePassFormat(path, extFormat string) (format, note string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return extFormat, "unreadable: " + err.Error()
+	}
+	defer f.Close()
+	head := make([]byte, len(kdbxSignature))
+	if _, err := io.ReadFull(f, head); err != nil {
+		return extFormat, "signature mismatch (file extension only)"
+	}
+	switch {
+	case bytes.Equal(head, kdbxSignature):
+		return "kdbx", "kdbx signature"
+	case bytes.Equal(head, kdbSignature):
+		return "kdb", "kdb signature"
+	}
+	return extFormat, "signature mismatch (file extension only)"
+}
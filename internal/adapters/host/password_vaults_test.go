@@ -0,0 +1,54 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectPasswordVaults(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel string, content []byte) string {
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	appDir := filepath.Join(dir, "Programs", "KeePassXC")
+	if err := os.MkdirAll(appDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	bw := write("Roaming/Bitwarden/data.json", []byte(`{"encrypted":true}`))
+	kdbx := write("Documents/keys/exchanges.kdbx", append(append([]byte{}, kdbxSignature...), 0x01, 0x00))
+	fake := write("Documents/old.kdb", []byte("not a keepass file"))
+	write("Documents/notes.txt", []byte("hello"))
+
+	got := detectPasswordVaults(t.Context(),
+		[]pwAppHint{{Product: "keepassxc", Name: "KeePassXC", Path: appDir}, {Product: "bitwarden", Name: "Bitwarden", Path: filepath.Join(dir, "missing")}},
+		[]pwVaultFile{{Product: "bitwarden", Format: "json", Path: bw}},
+		[]string{filepath.Join(dir, "Documents")},
+	)
+	if len(got) != 4 {
+		t.Fatalf("records=%+v", got)
+	}
+	if got[0].Kind != "app" || got[0].Product != "keepassxc" {
+		t.Fatalf("first record should be the app: %+v", got[0])
+	}
+	byPath := map[string]int{}
+	for i, r := range got {
+		byPath[r.Path] = i
+	}
+	if r := got[byPath[kdbx]]; r.Format != "kdbx" || r.Note != "kdbx signature" || r.SizeBytes != 10 {
+		t.Fatalf("kdbx=%+v", r)
+	}
+	if r := got[byPath[fake]]; r.Format != "kdb" || r.Note != "signature mismatch (file extension only)" {
+		t.Fatalf("fake kdb=%+v", r)
+	}
+	if r := got[byPath[bw]]; r.Product != "bitwarden" || r.Format != "json" {
+		t.Fatalf("bitwarden=%+v", r)
+	}
+}
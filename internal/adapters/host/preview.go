@@ -0,0 +1,92 @@
+package host
+
+import (
+	"context"
+	"fmt"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 扫描预览（scan host --dry-run）
+//
+// 只枚举“将会采集什么”：浏览器 profile、历史库路径与大小、扩展数量等，
+// 不复制文件、不写证据快照，供取证人员在接触证据前确认范围。
+// 大小为源文件（含 -wal/-shm）当前字节数，实际快照为 zip 压缩后的结果，通常更小。
+
+// PreviewSource 是预览中的一个待采集来源。
+type PreviewSource struct {
+	Kind      string   `json:"kind"` // 对应证据类型，如 browser_history_db
+	Browser   string   `json:"browser,omitempty"`
+	Profile   string   `json:"profile,omitempty"`
+	Path      string   `json:"path"`
+	Files     []string `json:"files,omitempty"`
+	SizeBytes int64    `json:"size_bytes"`
+}
+
+// ScanPreview 是一次扫描的范围预览。
+type ScanPreview struct {
+	OS             model.OSType    `json:"os"`
+	Profiles       []string        `json:"profiles"` // browser/profile
+	Sources        []PreviewSource `json:"sources"`
+	ExtensionCount int             `json:"extension_count"`
+	EstimatedBytes int64           `json:"estimated_bytes"`
+	Notes          []string        `json:"notes,omitempty"`
+}
+
+// PreviewScan 枚举在线扫描将要采集的来源（只读，不落盘）。
+func PreviewScan(ctx context.Context, device model.Device) (*ScanPreview, error) {
+	var specs []historyDBSpec
+	var exts []model.ExtensionRecord
+	var err error
+	switch device.OS {
+	case model.OSWindows:
+		firefox := discoverFirefoxRoots(ctx, model.OSWindows)
+		specs = collectWindowsHistoryDBSpecs(firefox)
+		exts, err = collectWindowsExtensions(ctx, firefox)
+	case model.OSMacOS:
+		firefox := discoverFirefoxRoots(ctx, model.OSMacOS)
+		specs = collectMacHistoryDBSpecs(firefox)
+		exts, err = collectMacExtensions(ctx, firefox)
+	default:
+		return nil, fmt.Errorf("unsupported host os: %s", device.OS)
+	}
+	p := &ScanPreview{OS: device.OS, ExtensionCount: len(exts)}
+	if err != nil {
+		p.Notes = append(p.Notes, "extensions: "+err.Error())
+	}
+	p.addHistoryDBs(specs)
+	p.Notes = append(p.Notes, "installed apps, visit records and other metadata are collected as JSON snapshots; their size is not estimated")
+	p.finish()
+	return p, nil
+}
+
+func (p *ScanPreview) addHistoryDBs(specs []historyDBSpec) {
+	for _, sp := range specs {
+		size, files, err := historyDBSize(sp.Path)
+		if err != nil {
+			continue
+		}
+		s := PreviewSource{
+			Kind:      string(model.ArtifactBrowserHistoryDB),
+			Browser:   sp.Browser,
+			Profile:   sp.Profile,
+			Path:      sp.Path,
+			Files:     files,
+			SizeBytes: size,
+		}
+		p.Sources = append(p.Sources, s)
+		p.Profiles = append(p.Profiles, sp.Browser+"/"+sp.Profile)
+	}
+}
+
+func (p *ScanPreview) finish() {
+	if p.Profiles == nil {
+		p.Profiles = []string{}
+	}
+	if p.Sources == nil {
+		p.Sources = []PreviewSource{}
+	}
+	for _, s := range p.Sources {
+		p.EstimatedBytes += s.SizeBytes
+	}
+}
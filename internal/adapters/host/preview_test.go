@@ -0,0 +1,40 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreviewHistoryDBSizes(t *testing.T) {
+	root := t.TempDir()
+	for name, size := range map[string]int{
+		"Default/History":       100,
+		"Default/History-wal":   20,
+		"Profile 1/History":     50,
+		"Profile 1/Preferences": 7, // 不属于历史库，不计入
+	} {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := &ScanPreview{}
+	p.addHistoryDBs(chromiumHistoryDBSpecs(root, "chrome"))
+	p.finish()
+	if len(p.Sources) != 2 || p.EstimatedBytes != 170 {
+		t.Fatalf("preview=%+v", p)
+	}
+	if p.Profiles[0] != "chrome/Default" || len(p.Sources[0].Files) != 2 || p.Sources[0].SizeBytes != 120 {
+		t.Fatalf("sources=%+v profiles=%v", p.Sources, p.Profiles)
+	}
+	// 预览不应在源目录旁生成任何文件。
+	entries, _ := os.ReadDir(filepath.Join(root, "Default"))
+	if len(entries) != 2 {
+		t.Fatalf("preview wrote files: %v", entries)
+	}
+}
@@ -0,0 +1,313 @@
+package host
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 注册表 hive（regf）只读解析
+//
+// 离线模式下拿到的是别的团队拷贝出来的 SOFTWARE / NTUSER.DAT 文件，无法通过 PowerShell 查询。
+// 这里只实现读取卸载项所需的最小子集：nk（键）、vk（值）、lf/lh/li/ri（子键列表），
+// 不处理大数据块（db）、安全描述符与事务日志（.LOG1/.LOG2 不回放）。
+
+const (
+	regfBaseBlockSize = 4096
+	regfMaxHiveBytes  = 1 << 30
+
+	regSZ       = 1
+	regExpandSZ = 2
+	regDWORD    = 4
+)
+
+var errNotHive = errors.New("not a registry hive")
+
+// uninstallKeyPaths 是卸载项在各类 hive 中的相对路径（SOFTWARE hive 根即 HKLM\Software；NTUSER.DAT 根即 HKCU）。
+var uninstallKeyPaths = [][]string{
+	{"Microsoft", "Windows", "CurrentVersion", "Uninstall"},
+	{"WOW6432Node", "Microsoft", "Windows", "CurrentVersion", "Uninstall"},
+	{"Software", "Microsoft", "Windows", "CurrentVersion", "Uninstall"},
+	{"Software", "WOW6432Node", "Microsoft", "Windows", "CurrentVersion", "Uninstall"},
+}
+
+type regfHive struct {
+	data []byte
+	root uint32
+}
+
+// isRegistryHive 按文件头判断是否为 regf hive。
+func isRegistryHive(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	var magic [4]byte
+	if _, err := f.Read(magic[:]); err != nil {
+		return false
+	}
+	return string(magic[:]) == "regf"
+}
+
+func openHive(path string) (*regfHive, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if st.Size() > regfMaxHiveBytes {
+		return nil, fmt.Errorf("hive too large: %d bytes", st.Size())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < regfBaseBlockSize || string(data[:4]) != "regf" {
+		return nil, errNotHive
+	}
+	return &regfHive{data: data, root: binary.LittleEndian.Uint32(data[0x24:])}, nil
+}
+
+// cell 返回 hive 内相对偏移处的 cell 数据（去掉 4 字节 size 头）。
+func (h *regfHive) cell(off uint32) ([]byte, error) {
+	start := int64(regfBaseBlockSize) + int64(off)
+	if off == 0xffffffff || start+4 > int64(len(h.data)) {
+		return nil, fmt.Errorf("cell offset out of range: %#x", off)
+	}
+	size := int32(binary.LittleEndian.Uint32(h.data[start:]))
+	if size < 0 {
+		size = -size
+	}
+	end := start + int64(size)
+	if size < 4 || end > int64(len(h.data)) {
+		return nil, fmt.Errorf("bad cell size at %#x", off)
+	}
+	return h.data[start+4 : end], nil
+}
+
+type regfKey struct {
+	name       string
+	subkeyList uint32
+	subkeys    uint32
+	valueList  uint32
+	values     uint32
+}
+
+func (h *regfHive) key(off uint32) (regfKey, error) {
+	c, err := h.cell(off)
+	if err != nil {
+		return regfKey{}, err
+	}
+	if len(c) < 0x4c || string(c[:2]) != "nk" {
+		return regfKey{}, fmt.Errorf("not a key cell at %#x", off)
+	}
+	flags := binary.LittleEndian.Uint16(c[2:])
+	nameLen := int(binary.LittleEndian.Uint16(c[0x48:]))
+	if 0x4c+nameLen > len(c) {
+		return regfKey{}, fmt.Errorf("bad key name at %#x", off)
+	}
+	return regfKey{
+		name:       decodeRegName(c[0x4c:0x4c+nameLen], flags&0x20 != 0),
+		subkeys:    binary.LittleEndian.Uint32(c[0x14:]),
+		subkeyList: binary.LittleEndian.Uint32(c[0x1c:]),
+		values:     binary.LittleEndian.Uint32(c[0x24:]),
+		valueList:  binary.LittleEndian.Uint32(c[0x28:]),
+	}, nil
+}
+
+// subkeys 展开子键列表（lf/lh/li，以及 ri 的间接列表）。
+func (h *regfHive) subkeys(k regfKey) ([]uint32, error) {
+	if k.subkeys == 0 {
+		return nil, nil
+	}
+	return h.subkeyOffsets(k.subkeyList, 0)
+}
+
+func (h *regfHive) subkeyOffsets(listOff uint32, depth int) ([]uint32, error) {
+	if depth > 2 {
+		return nil, errors.New("subkey index too deep")
+	}
+	c, err := h.cell(listOff)
+	if err != nil {
+		return nil, err
+	}
+	if len(c) < 4 {
+		return nil, errors.New("bad subkey list")
+	}
+	sig := string(c[:2])
+	n := int(binary.LittleEndian.Uint16(c[2:]))
+	var out []uint32
+	switch sig {
+	case "lf", "lh":
+		for i := 0; i < n && 4+i*8+4 <= len(c); i++ {
+			out = append(out, binary.LittleEndian.Uint32(c[4+i*8:]))
+		}
+	case "li":
+		for i := 0; i < n && 4+i*4+4 <= len(c); i++ {
+			out = append(out, binary.LittleEndian.Uint32(c[4+i*4:]))
+		}
+	case "ri":
+		for i := 0; i < n && 4+i*4+4 <= len(c); i++ {
+			sub, err := h.subkeyOffsets(binary.LittleEndian.Uint32(c[4+i*4:]), depth+1)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, sub...)
+		}
+	default:
+		return nil, fmt.Errorf("unknown subkey list %q", sig)
+	}
+	return out, nil
+}
+
+// child 按名称（不区分大小写）查找子键。
+func (h *regfHive) child(k regfKey, name string) (regfKey, bool) {
+	offs, err := h.subkeys(k)
+	if err != nil {
+		return regfKey{}, false
+	}
+	for _, off := range offs {
+		sk, err := h.key(off)
+		if err == nil && strings.EqualFold(sk.name, name) {
+			return sk, true
+		}
+	}
+	return regfKey{}, false
+}
+
+// stringValues 读取键下的字符串/DWORD 值（值名 → 文本）。
+func (h *regfHive) stringValues(k regfKey) map[string]string {
+	out := map[string]string{}
+	if k.values == 0 {
+		return out
+	}
+	list, err := h.cell(k.valueList)
+	if err != nil {
+		return out
+	}
+	for i := 0; i < int(k.values) && i*4+4 <= len(list); i++ {
+		c, err := h.cell(binary.LittleEndian.Uint32(list[i*4:]))
+		if err != nil || len(c) < 0x14 || string(c[:2]) != "vk" {
+			continue
+		}
+		nameLen := int(binary.LittleEndian.Uint16(c[2:]))
+		size := binary.LittleEndian.Uint32(c[4:])
+		dataOff := binary.LittleEndian.Uint32(c[8:])
+		typ := binary.LittleEndian.Uint32(c[12:])
+		flags := binary.LittleEndian.Uint16(c[16:])
+		if 0x14+nameLen > len(c) {
+			continue
+		}
+		name := decodeRegName(c[0x14:0x14+nameLen], flags&0x1 != 0)
+
+		var data []byte
+		if size&0x80000000 != 0 {
+			// 小数据（≤4 字节）直接存放在 data offset 字段中。
+			n := size & 0x7fffffff
+			if n > 4 {
+				continue
+			}
+			data = c[8 : 8+n]
+		} else {
+			d, err := h.cell(dataOff)
+			if err != nil || int(size) > len(d) {
+				continue // 大数据块（db）不支持
+			}
+			data = d[:size]
+		}
+		switch typ {
+		case regSZ, regExpandSZ:
+			out[name] = decodeUTF16(data)
+		case regDWORD:
+			if len(data) >= 4 {
+				out[name] = fmt.Sprintf("%d", binary.LittleEndian.Uint32(data))
+			}
+		}
+	}
+	return out
+}
+
+// collectHiveInstalledApps 从离线 hive 中读取卸载项，字段与在线采集保持一致。
+func collectHiveInstalledApps(path string) ([]model.AppRecord, error) {
+	h, err := openHive(path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := h.key(h.root)
+	if err != nil {
+		return nil, err
+	}
+
+	var apps []model.AppRecord
+	for _, p := range uninstallKeyPaths {
+		k, ok := root, true
+		for _, part := range p {
+			if k, ok = h.child(k, part); !ok {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		offs, err := h.subkeys(k)
+		if err != nil {
+			continue
+		}
+		for _, off := range offs {
+			sk, err := h.key(off)
+			if err != nil {
+				continue
+			}
+			if app, ok := appFromUninstallValues(h.stringValues(sk)); ok {
+				apps = append(apps, app)
+			}
+		}
+	}
+	return dedupeApps(apps), nil
+}
+
+// uninstallValueNames 是卸载项中需要读取的值名。
+var uninstallValueNames = []string{"DisplayName", "DisplayVersion", "Publisher", "InstallLocation", "InstallDate", "UninstallString", "DisplayIcon"}
+
+// appFromUninstallValues 把一个卸载项的值转换为 AppRecord；没有 DisplayName 的项（补丁、组件）跳过。
+// 离线 hive、在线注册表 API 与 PowerShell 三条路径共用，保证字段一致。
+func appFromUninstallValues(v map[string]string) (model.AppRecord, bool) {
+	name := strings.TrimSpace(v["DisplayName"])
+	if name == "" {
+		return model.AppRecord{}, false
+	}
+	return model.AppRecord{
+		Name:            name,
+		Version:         strings.TrimSpace(v["DisplayVersion"]),
+		Publisher:       strings.TrimSpace(v["Publisher"]),
+		InstallLocation: strings.TrimSpace(v["InstallLocation"]),
+		InstallDate:     strings.TrimSpace(v["InstallDate"]),
+		UninstallString: strings.TrimSpace(v["UninstallString"]),
+		DisplayIcon:     strings.TrimSpace(v["DisplayIcon"]),
+	}, true
+}
+
+func decodeRegName(b []byte, ascii bool) string {
+	if ascii {
+		return string(b)
+	}
+	return decodeUTF16(b)
+}
+
+func decodeUTF16(b []byte) string {
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u = append(u, binary.LittleEndian.Uint16(b[i:]))
+	}
+	s := string(utf16.Decode(u))
+	if i := strings.IndexByte(s, 0); i >= 0 {
+		s = s[:i]
+	}
+	return string(bytes.TrimSpace([]byte(s)))
+}
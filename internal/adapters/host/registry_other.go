@@ -0,0 +1,15 @@
+//go:build !windows
+
+package host
+
+import (
+	"context"
+	"errors"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// collectNativeRegistryApps 在非 Windows 平台上不可用（离线 hive 走 collectHiveInstalledApps）。
+func collectNativeRegistryApps(ctx context.Context) ([]model.AppRecord, error) {
+	return nil, errors.New("native registry access is only available on windows")
+}
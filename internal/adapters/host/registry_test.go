@@ -0,0 +1,32 @@
+package host
+
+import (
+	"context"
+	"runtime"
+	"testing"
+)
+
+func TestAppFromUninstallValues(t *testing.T) {
+	app, ok := appFromUninstallValues(map[string]string{
+		"DisplayName":     " Exodus ",
+		"DisplayVersion":  "24.1.0",
+		"InstallLocation": `C:\Users\a\AppData\Local\exodus`,
+		"InstallDate":     "20240301",
+	})
+	if !ok || app.Name != "Exodus" || app.Version != "24.1.0" || app.InstallDate != "20240301" {
+		t.Fatalf("app=%+v ok=%v", app, ok)
+	}
+	// 没有 DisplayName 的卸载项（补丁/组件）不计入安装软件。
+	if _, ok := appFromUninstallValues(map[string]string{"UninstallString": "msiexec /x {GUID}"}); ok {
+		t.Fatal("entry without DisplayName must be skipped")
+	}
+}
+
+func TestCollectNativeRegistryApps_NonWindows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("live registry is read on windows")
+	}
+	if _, err := collectNativeRegistryApps(context.Background()); err == nil {
+		t.Fatal("native registry should be unavailable off windows so the powershell fallback is used")
+	}
+}
@@ -0,0 +1,92 @@
+//go:build windows
+
+package host
+
+import (
+	"context"
+	"strconv"
+
+	"golang.org/x/sys/windows/registry"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// liveUninstallRoots 是在线读取的卸载项位置（与 PowerShell 查询覆盖的三处一致）。
+// 64 位视图与 32 位视图分别显式打开，避免 32 位进程被重定向到 WOW6432Node 后漏掉 64 位软件。
+var liveUninstallRoots = []struct {
+	root   registry.Key
+	path   string
+	access uint32
+}{
+	{registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`, registry.WOW64_64KEY},
+	{registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`, registry.WOW64_32KEY},
+	{registry.CURRENT_USER, `SOFTWARE\Microsoft\Windows\CurrentVersion\Uninstall`, 0},
+}
+
+// collectNativeRegistryApps 通过 Windows 注册表 API 读取卸载项（只读打开，不依赖 PowerShell）。
+// 任一位置无法打开时跳过；全部位置都无法打开时返回最后一个错误。
+func collectNativeRegistryApps(ctx context.Context) ([]model.AppRecord, error) {
+	var apps []model.AppRecord
+	var lastErr error
+	opened := 0
+	for _, r := range liveUninstallRoots {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		k, err := registry.OpenKey(r.root, r.path, registry.ENUMERATE_SUB_KEYS|registry.QUERY_VALUE|r.access)
+		if err != nil {
+			recordAccess(ctx, AccessCategoryRegistry, registryRootName(r.root)+`\`+r.path, err)
+			lastErr = err
+			continue
+		}
+		opened++
+		names, err := k.ReadSubKeyNames(-1)
+		if err != nil {
+			k.Close()
+			lastErr = err
+			continue
+		}
+		for _, name := range names {
+			sk, err := registry.OpenKey(k, name, registry.QUERY_VALUE|r.access)
+			if err != nil {
+				continue
+			}
+			if app, ok := appFromUninstallValues(readRegistryStrings(sk)); ok {
+				apps = append(apps, app)
+			}
+			sk.Close()
+		}
+		k.Close()
+	}
+	if opened == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return apps, nil
+}
+
+// registryRootName 返回根键的常用缩写（用于留痕路径）。
+func registryRootName(k registry.Key) string {
+	switch k {
+	case registry.LOCAL_MACHINE:
+		return "HKLM"
+	case registry.CURRENT_USER:
+		return "HKCU"
+	default:
+		return "HK"
+	}
+}
+
+// readRegistryStrings 读取卸载项需要的值；REG_DWORD 按十进制字符串返回（与离线 hive 解析一致）。
+func readRegistryStrings(k registry.Key) map[string]string {
+	out := make(map[string]string, len(uninstallValueNames))
+	for _, name := range uninstallValueNames {
+		if s, _, err := k.GetStringValue(name); err == nil {
+			out[name] = s
+			continue
+		}
+		if n, _, err := k.GetIntegerValue(name); err == nil {
+			out[name] = strconv.FormatUint(n, 10)
+		}
+	}
+	return out
+}
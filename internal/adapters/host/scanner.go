@@ -11,7 +11,6 @@ import (
 	"io"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"sort"
@@ -19,22 +18,37 @@ import (
 	"strings"
 	"time"
 
+	"crypto-inspector/internal/app"
 	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/budget"
+	"crypto-inspector/internal/platform/filetype"
 	"crypto-inspector/internal/platform/hash"
 	"crypto-inspector/internal/platform/id"
+	"crypto-inspector/internal/platform/idn"
+	"crypto-inspector/internal/platform/snapshot"
+	"crypto-inspector/internal/platform/toolbox"
 
 	"howett.net/plist"
 	_ "modernc.org/sqlite"
 )
 
-const (
-	collectorVersion = "0.1.0"
-	parserVersion    = "0.1.0"
-)
-
 // Scanner 负责主机端证据采集与快照落盘。
 type Scanner struct {
 	EvidenceRoot string
+	// SnapshotCompression 是 JSON 快照的压缩方式（空/none/gzip），见 platform/snapshot。
+	SnapshotCompression string
+	// ScanMessengers 为 true 时额外采集 Telegram Desktop / Discord 痕迹（见 messenger.go）。
+	ScanMessengers bool
+	// Budget 约束原始历史库副本的总字节数（nil 表示不限）。
+	Budget *budget.Budget
+	// SkipHistoryDB 为 true 时不复制原始历史库（浏览记录照常解析入库）。
+	SkipHistoryDB bool
+	// MaxPayloadBytes 是单条证据 payload 上限（0 表示不拆分），超出的浏览记录等数组证据拆为多个分片。
+	MaxPayloadBytes int64
+	// Access 记录因权限不足/被锁定而跳过的来源（nil 表示不记录），见 access.go。
+	Access *AccessLog
+	// Interference 非 nil 时复核刚写出的快照/副本并在被隔离时重试，见 interference.go。
+	Interference *InterferenceLog
 }
 
 func NewScanner(evidenceRoot string) *Scanner {
@@ -70,6 +84,8 @@ func DetectHostDevice() (model.Device, error) {
 
 // Scan 根据 OS 分发到不同采集器实现。
 func (s *Scanner) Scan(ctx context.Context, caseID string, device model.Device) ([]model.Artifact, error) {
+	ctx = withAccessLog(ctx, s.Access)
+	ctx = withInterferenceLog(ctx, s.Interference)
 	switch device.OS {
 	case model.OSWindows:
 		return s.scanWindows(ctx, caseID, device)
@@ -82,32 +98,65 @@ func (s *Scanner) Scan(ctx context.Context, caseID string, device model.Device)
 
 // scanWindows 采集 Windows 主机三类核心证据：
 // 1) 安装软件 2) 浏览器扩展 3) 浏览历史
+// 另附虚拟化环境清单（虚拟机软件、VM 镜像、WSL 发行版）与密码管理器清单。
 func (s *Scanner) scanWindows(ctx context.Context, caseID string, device model.Device) ([]model.Artifact, error) {
 	var out []model.Artifact
 
 	apps, appErr := collectWindowsInstalledApps(ctx)
+	apps = annotateInstallHints(ctx, apps)
 	artifact, err := s.makeArtifact(caseID, device.ID, model.ArtifactInstalledApps, "windows_registry_apps", "windows_registry", apps)
 	if err != nil {
 		return nil, err
 	}
 	out = append(out, artifact)
 
-	ext, extErr := collectWindowsExtensions()
+	firefox := discoverFirefoxRoots(ctx, model.OSWindows)
+	ext, extErr := collectWindowsExtensions(ctx, firefox)
 	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactBrowserExt, "windows_browser_extensions", "directory_scan", ext)
 	if err != nil {
 		return nil, err
 	}
 	out = append(out, artifact)
 
-	visits, historyErr := collectWindowsHistory(ctx)
-	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactBrowserHistory, "windows_browser_history", "sqlite_extract", visits)
+	visits, historyErr := collectWindowsHistory(ctx, firefox)
+	historyParts, err := s.makeArtifactParts(caseID, device.ID, model.ArtifactBrowserHistory, "windows_browser_history", "sqlite_extract", visits)
 	if err != nil {
 		return nil, err
 	}
-	out = append(out, artifact)
+	out = append(out, historyParts...)
 
 	// P1：增强证据强度，把用于解析的原始 SQLite 库副本也落盘为 artifact（best effort）。
-	out = append(out, s.snapshotHistoryDBArtifacts(caseID, device.ID, collectWindowsHistoryDBSpecs())...)
+	out = append(out, s.snapshotHistoryDBArtifacts(caseID, device.ID, collectWindowsHistoryDBSpecs(firefox))...)
+
+	// 表单来源与自动填充元数据（只取来源与统计，不取填写值）：说明在站点上有过交互而不只是浏览。
+	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactBrowserFormData, "windows_browser_form_data", "sqlite_extract", collectWindowsFormData(ctx))
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, artifact)
+
+	// 虚拟化环境（虚拟机软件/VM 镜像/WSL 发行版）：提示可能存在宿主机扫描覆盖不到的系统。
+	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactVirtualization, "windows_virtualization", "directory_scan", collectWindowsVirtualization(ctx))
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, artifact)
+
+	// 密码管理器与保险库文件：交易所凭据可能保存在其中。
+	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactPasswordVaults, "windows_password_vaults", "directory_scan", collectWindowsPasswordVaults(ctx))
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, artifact)
+
+	// 可选：Telegram Desktop / Discord 安装与频道名称（OTC/交易社群线索）。
+	if s.ScanMessengers {
+		artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactMessengerTraces, "windows_messengers", "directory_scan", collectWindowsMessengers(ctx))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, artifact)
+	}
 
 	if appErr != nil || extErr != nil || historyErr != nil {
 		var parts []string
@@ -128,32 +177,79 @@ func (s *Scanner) scanWindows(ctx context.Context, caseID string, device model.D
 
 // scanMacOS 采集 macOS 主机三类核心证据：
 // 1) 应用 bundle 2) 浏览器扩展 3) 浏览历史
+// 另附虚拟化环境清单（虚拟机软件、VM 镜像）与密码管理器清单。
 func (s *Scanner) scanMacOS(ctx context.Context, caseID string, device model.Device) ([]model.Artifact, error) {
 	var out []model.Artifact
 
 	apps, appErr := collectMacInstalledApps()
+	apps = annotateInstallHints(ctx, apps)
 	artifact, err := s.makeArtifact(caseID, device.ID, model.ArtifactInstalledApps, "macos_bundle_apps", "bundle_scan", apps)
 	if err != nil {
 		return nil, err
 	}
 	out = append(out, artifact)
 
-	ext, extErr := collectMacExtensions()
+	firefox := discoverFirefoxRoots(ctx, model.OSMacOS)
+	ext, extErr := collectMacExtensions(ctx, firefox)
 	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactBrowserExt, "macos_browser_extensions", "directory_scan", ext)
 	if err != nil {
 		return nil, err
 	}
 	out = append(out, artifact)
 
-	visits, historyErr := collectMacHistory(ctx)
-	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactBrowserHistory, "macos_browser_history", "sqlite_extract", visits)
+	visits, historyErr := collectMacHistory(ctx, firefox)
+	historyParts, err := s.makeArtifactParts(caseID, device.ID, model.ArtifactBrowserHistory, "macos_browser_history", "sqlite_extract", visits)
 	if err != nil {
 		return nil, err
 	}
-	out = append(out, artifact)
+	out = append(out, historyParts...)
 
 	// P1：增强证据强度，把用于解析的原始 SQLite 库副本也落盘为 artifact（best effort）。
-	out = append(out, s.snapshotHistoryDBArtifacts(caseID, device.ID, collectMacHistoryDBSpecs())...)
+	out = append(out, s.snapshotHistoryDBArtifacts(caseID, device.ID, collectMacHistoryDBSpecs(firefox))...)
+
+	// 表单来源与自动填充元数据（只取来源与统计，不取填写值）：说明在站点上有过交互而不只是浏览。
+	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactBrowserFormData, "macos_browser_form_data", "sqlite_extract", collectMacFormData(ctx))
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, artifact)
+
+	// 虚拟化环境（虚拟机软件/VM 镜像）：提示可能存在宿主机扫描覆盖不到的系统。
+	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactVirtualization, "macos_virtualization", "directory_scan", collectMacVirtualization(ctx))
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, artifact)
+
+	// 密码管理器与保险库文件：交易所凭据可能保存在其中。
+	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactPasswordVaults, "macos_password_vaults", "directory_scan", collectMacPasswordVaults(ctx))
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, artifact)
+
+	// 可选：Telegram Desktop / Discord 安装与频道名称（OTC/交易社群线索）。
+	if s.ScanMessengers {
+		artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactMessengerTraces, "macos_messengers", "directory_scan", collectMacMessengers(ctx))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, artifact)
+	}
+
+	// 应用运行痕迹（LaunchServices / Dock / Saved Application State / 废纸篓）：.app 被删除后仍能证明曾经运行。
+	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactAppExecution, "macos_app_execution", "plist_parse", collectMacAppExecution(ctx))
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, artifact)
+
+	// 钥匙串应用密码标签与 TCC 授权记录（只取元数据）：钱包删除后仍能说明曾安装并运行。
+	artifact, err = s.makeArtifact(caseID, device.ID, model.ArtifactAppRemnants, "macos_app_remnants", "metadata_query", collectMacAppRemnants(ctx))
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, artifact)
 
 	if appErr != nil || extErr != nil || historyErr != nil {
 		var parts []string
@@ -177,28 +273,68 @@ func (s *Scanner) scanMacOS(ctx context.Context, caseID string, device model.Dev
 // - 写入 evidence 目录
 // - 计算文件哈希与 record_hash
 func (s *Scanner) makeArtifact(caseID, deviceID string, t model.ArtifactType, sourceRef, method string, payload any) (model.Artifact, error) {
-	now := time.Now().Unix()
-	artifactID := id.New("art")
-
 	raw, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
 		return model.Artifact{}, fmt.Errorf("marshal payload %s: %w", t, err)
 	}
+	return s.writeArtifact(caseID, deviceID, t, sourceRef, method, raw, time.Now().Unix(), "")
+}
+
+// makeArtifactParts 与 makeArtifact 相同，但数组 payload 超过 MaxPayloadBytes 时拆成多个分片证据
+// （见 snapshot.SplitJSONArray），分片共用第 1 个分片的 ID 作为 PartGroup。
+func (s *Scanner) makeArtifactParts(caseID, deviceID string, t model.ArtifactType, sourceRef, method string, payload any) ([]model.Artifact, error) {
+	raw, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload %s: %w", t, err)
+	}
+	now := time.Now().Unix()
+	chunks := snapshot.SplitJSONArray(raw, s.MaxPayloadBytes)
+	if chunks == nil {
+		a, err := s.writeArtifact(caseID, deviceID, t, sourceRef, method, raw, now, "")
+		if err != nil {
+			return nil, err
+		}
+		return []model.Artifact{a}, nil
+	}
+	out := make([]model.Artifact, 0, len(chunks))
+	for i, chunk := range chunks {
+		a, err := s.writeArtifact(caseID, deviceID, t, sourceRef, method, chunk, now, fmt.Sprintf("_part%03d", i+1))
+		if err != nil {
+			return nil, err
+		}
+		a.PartGroup = a.ID
+		if i > 0 {
+			a.PartGroup = out[0].PartGroup
+		}
+		a.PartNo, a.PartCount = i+1, len(chunks)
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// writeArtifact 把已序列化的 payload 写为快照文件并生成 Artifact；nameSuffix 用于区分同一秒内的分片文件。
+func (s *Scanner) writeArtifact(caseID, deviceID string, t model.ArtifactType, sourceRef, method string, raw []byte, now int64, nameSuffix string) (model.Artifact, error) {
+	artifactID := id.New("art")
 
 	dir := filepath.Join(s.EvidenceRoot, caseID, deviceID)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return model.Artifact{}, fmt.Errorf("create evidence dir: %w", err)
 	}
 
-	name := fmt.Sprintf("%s_%s_%d.json", string(t), sourceRef, now)
-	snapshotPath := filepath.Join(dir, sanitizeFilename(name))
-	if err := os.WriteFile(snapshotPath, raw, 0o644); err != nil {
-		return model.Artifact{}, fmt.Errorf("write evidence file: %w", err)
+	name := fmt.Sprintf("%s_%s_%d%s.json", string(t), sourceRef, now, nameSuffix)
+	compression, err := snapshot.ParseCompression(s.SnapshotCompression)
+	if err != nil {
+		return model.Artifact{}, err
 	}
-
-	sum, size, err := hash.File(snapshotPath)
+	snapshotPath, sum, size, err := s.writeVerified(sourceRef, func() (string, error) {
+		p, err := snapshot.WriteJSON(filepath.Join(dir, sanitizeFilename(name)), raw, compression)
+		if err != nil {
+			return p, fmt.Errorf("write evidence file: %w", err)
+		}
+		return p, nil
+	})
 	if err != nil {
-		return model.Artifact{}, fmt.Errorf("hash evidence file: %w", err)
+		return model.Artifact{}, err
 	}
 
 	recordHash := hash.Text(
@@ -211,8 +347,8 @@ func (s *Scanner) makeArtifact(caseID, deviceID string, t model.ArtifactType, so
 		sum,
 		fmt.Sprintf("%d", size),
 		fmt.Sprintf("%d", now),
-		"host_scanner",
-		collectorVersion,
+		app.HostScanner.Name,
+		app.HostScanner.CollectorVersion,
 		string(raw),
 	)
 
@@ -225,10 +361,12 @@ func (s *Scanner) makeArtifact(caseID, deviceID string, t model.ArtifactType, so
 		SnapshotPath:      snapshotPath,
 		SHA256:            sum,
 		SizeBytes:         size,
+		MimeType:          filetype.MimeJSON,
+		Compression:       compression,
 		CollectedAt:       now,
-		CollectorName:     "host_scanner",
-		CollectorVersion:  collectorVersion,
-		ParserVersion:     parserVersion,
+		CollectorName:     app.HostScanner.Name,
+		CollectorVersion:  app.HostScanner.CollectorVersion,
+		ParserVersion:     app.HostScanner.ParserVersion,
 		AcquisitionMethod: method,
 		PayloadJSON:       raw,
 		RecordHash:        recordHash,
@@ -252,14 +390,15 @@ func (s *Scanner) makeZipArtifact(caseID, deviceID string, t model.ArtifactType,
 	}
 
 	name := fmt.Sprintf("%s_%s_%d.zip", string(t), sourceRef, now)
-	snapshotPath := filepath.Join(dir, sanitizeFilename(name))
-	if err := writeZip(snapshotPath, files); err != nil {
-		return model.Artifact{}, fmt.Errorf("write zip evidence file: %w", err)
-	}
-
-	sum, size, err := hash.File(snapshotPath)
+	snapshotPath, sum, size, err := s.writeVerified(sourceRef, func() (string, error) {
+		p := filepath.Join(dir, sanitizeFilename(name))
+		if err := writeZip(p, files); err != nil {
+			return p, fmt.Errorf("write zip evidence file: %w", err)
+		}
+		return p, nil
+	})
 	if err != nil {
-		return model.Artifact{}, fmt.Errorf("hash evidence file: %w", err)
+		return model.Artifact{}, err
 	}
 
 	recordHash := hash.Text(
@@ -272,8 +411,8 @@ func (s *Scanner) makeZipArtifact(caseID, deviceID string, t model.ArtifactType,
 		sum,
 		fmt.Sprintf("%d", size),
 		fmt.Sprintf("%d", now),
-		"host_scanner",
-		collectorVersion,
+		app.HostScanner.Name,
+		app.HostScanner.CollectorVersion,
 		string(raw),
 	)
 
@@ -286,10 +425,11 @@ func (s *Scanner) makeZipArtifact(caseID, deviceID string, t model.ArtifactType,
 		SnapshotPath:      snapshotPath,
 		SHA256:            sum,
 		SizeBytes:         size,
+		MimeType:          filetype.MimeZip,
 		CollectedAt:       now,
-		CollectorName:     "host_scanner",
-		CollectorVersion:  collectorVersion,
-		ParserVersion:     parserVersion,
+		CollectorName:     app.HostScanner.Name,
+		CollectorVersion:  app.HostScanner.CollectorVersion,
+		ParserVersion:     app.HostScanner.ParserVersion,
 		AcquisitionMethod: method,
 		PayloadJSON:       raw,
 		RecordHash:        recordHash,
@@ -313,39 +453,34 @@ func (s *Scanner) snapshotHistoryDBArtifacts(caseID, deviceID string, specs []hi
 		if src == "" {
 			continue
 		}
-		if _, err := os.Stat(src); err != nil {
-			continue
-		}
-
-		// 先复制（含 wal/shm）到临时目录，避免“浏览器锁文件 + wal 旁路数据”导致证据不完整。
-		tmpCopy, cleanup, err := copySQLiteForRead(src)
+		size, _, err := historyDBSize(src)
 		if err != nil {
+			s.Access.Record(AccessCategoryBrowserDB, src, err)
 			continue
 		}
-
-		files := map[string]string{
-			filepath.Base(src): tmpCopy,
+		sourceRef := fmt.Sprintf("%s_%s", sp.Browser, sp.Profile)
+		if s.SkipHistoryDB {
+			s.Budget.Skip(string(model.ArtifactBrowserHistoryDB), sourceRef, src, size)
+			continue
 		}
-		for _, suffix := range []string{"-wal", "-shm"} {
-			if _, err := os.Stat(tmpCopy + suffix); err == nil {
-				files[filepath.Base(src)+suffix] = tmpCopy + suffix
-			}
+		if !s.Budget.Take(string(model.ArtifactBrowserHistoryDB), sourceRef, src, size) {
+			continue
 		}
 
-		payload := map[string]any{
-			"kind":        "sqlite_snapshot_zip",
-			"browser":     sp.Browser,
-			"profile":     sp.Profile,
-			"origin_path": src,
-			"files":       sortedKeys(files),
-		}
-		sourceRef := fmt.Sprintf("%s_%s", sp.Browser, sp.Profile)
-		art, err := s.makeZipArtifact(caseID, deviceID, model.ArtifactBrowserHistoryDB, sourceRef, "sqlite_snapshot_zip", files, payload)
-		cleanup()
-		if err != nil {
-			continue
+		// 打包前临时副本被隔离时（errInterference）重新复制后再打包。
+		for attempt := 1; attempt <= interferenceAttempts; attempt++ {
+			art, err := s.snapshotHistoryDB(caseID, deviceID, sp, src, sourceRef)
+			if err == nil {
+				if attempt > 1 {
+					s.Interference.recovered(sourceRef)
+				}
+				out = append(out, art)
+				break
+			}
+			if !errors.Is(err, errInterference) {
+				break
+			}
 		}
-		out = append(out, art)
 	}
 
 	if len(out) == 0 {
@@ -354,7 +489,52 @@ func (s *Scanner) snapshotHistoryDBArtifacts(caseID, deviceID string, specs []hi
 	return out
 }
 
-func collectWindowsHistoryDBSpecs() []historyDBSpec {
+// snapshotHistoryDB 复制一个历史库（含 wal/shm）并打包为 browser_history_db 证据。
+func (s *Scanner) snapshotHistoryDB(caseID, deviceID string, sp historyDBSpec, src, sourceRef string) (model.Artifact, error) {
+	// 先复制（含 wal/shm）到临时目录，避免“浏览器锁文件 + wal 旁路数据”导致证据不完整。
+	tmpCopy, cleanup, err := copySQLiteWithRetry(s.Interference, src)
+	if err != nil {
+		s.Access.Record(AccessCategoryBrowserDB, src, err)
+		return model.Artifact{}, err
+	}
+	defer cleanup()
+
+	files := map[string]string{
+		filepath.Base(src): tmpCopy,
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if _, err := os.Stat(tmpCopy + suffix); err == nil {
+			files[filepath.Base(src)+suffix] = tmpCopy + suffix
+		}
+	}
+
+	payload := map[string]any{
+		"kind":        "sqlite_snapshot_zip",
+		"browser":     sp.Browser,
+		"profile":     sp.Profile,
+		"origin_path": src,
+		"files":       sortedKeys(files),
+	}
+	return s.makeZipArtifact(caseID, deviceID, model.ArtifactBrowserHistoryDB, sourceRef, "sqlite_snapshot_zip", files, payload)
+}
+
+// historyDBSize 返回历史库及其 -wal/-shm 的总字节数与文件名列表。
+func historyDBSize(path string) (int64, []string, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	size, files := st.Size(), []string{filepath.Base(path)}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if st, err := os.Stat(path + suffix); err == nil {
+			files = append(files, filepath.Base(path)+suffix)
+			size += st.Size()
+		}
+	}
+	return size, files, nil
+}
+
+func collectWindowsHistoryDBSpecs(firefox []firefoxRoot) []historyDBSpec {
 	local := os.Getenv("LOCALAPPDATA")
 	appdata := os.Getenv("APPDATA")
 	if local == "" && appdata == "" {
@@ -366,13 +546,11 @@ func collectWindowsHistoryDBSpecs() []historyDBSpec {
 		out = append(out, chromiumHistoryDBSpecs(filepath.Join(local, "Google", "Chrome", "User Data"), "chrome")...)
 		out = append(out, chromiumHistoryDBSpecs(filepath.Join(local, "Microsoft", "Edge", "User Data"), "edge")...)
 	}
-	if appdata != "" {
-		out = append(out, firefoxPlacesDBSpecs(filepath.Join(appdata, "Mozilla", "Firefox", "Profiles"))...)
-	}
+	out = append(out, firefoxRootsPlacesDBSpecs(firefox)...)
 	return out
 }
 
-func collectMacHistoryDBSpecs() []historyDBSpec {
+func collectMacHistoryDBSpecs(firefox []firefoxRoot) []historyDBSpec {
 	home, err := os.UserHomeDir()
 	if err != nil || home == "" {
 		return nil
@@ -381,7 +559,7 @@ func collectMacHistoryDBSpecs() []historyDBSpec {
 	var out []historyDBSpec
 	out = append(out, chromiumHistoryDBSpecs(filepath.Join(home, "Library", "Application Support", "Google", "Chrome"), "chrome")...)
 	out = append(out, chromiumHistoryDBSpecs(filepath.Join(home, "Library", "Application Support", "Microsoft Edge"), "edge")...)
-	out = append(out, firefoxPlacesDBSpecs(filepath.Join(home, "Library", "Application Support", "Firefox", "Profiles"))...)
+	out = append(out, firefoxRootsPlacesDBSpecs(firefox)...)
 	out = append(out, safariHistoryDBSpecs(filepath.Join(home, "Library", "Safari", "History.db"))...)
 	return out
 }
@@ -405,7 +583,7 @@ func chromiumHistoryDBSpecs(profileRoot, browser string) []historyDBSpec {
 	return out
 }
 
-func firefoxPlacesDBSpecs(profileRoot string) []historyDBSpec {
+func firefoxPlacesDBSpecs(profileRoot, browser string) []historyDBSpec {
 	pattern := filepath.Join(profileRoot, "*", "places.sqlite")
 	files, _ := filepath.Glob(pattern)
 	if len(files) == 0 {
@@ -416,7 +594,7 @@ func firefoxPlacesDBSpecs(profileRoot string) []historyDBSpec {
 	for _, f := range files {
 		profile := filepath.Base(filepath.Dir(f))
 		out = append(out, historyDBSpec{
-			Browser: "firefox",
+			Browser: browser,
 			Profile: profile,
 			Path:    f,
 		})
@@ -493,10 +671,27 @@ func sanitizeFilename(in string) string {
 	return r.Replace(in)
 }
 
-// collectWindowsInstalledApps 从注册表读取安装程序信息。
+// collectWindowsInstalledApps 从注册表读取安装程序信息：
+// 优先用原生注册表 API（不依赖 PowerShell，AppLocker/受限语言模式下仍可用且更快），
+// 原生读取失败或一条都没读到时回退到 PowerShell 查询。
 func collectWindowsInstalledApps(ctx context.Context) ([]model.AppRecord, error) {
-	// Use PowerShell registry query for installed applications.
-	cmd := exec.CommandContext(ctx, "powershell", "-NoProfile", "-Command", `
+	apps, nativeErr := collectNativeRegistryApps(ctx)
+	if nativeErr == nil && len(apps) > 0 {
+		return dedupeApps(apps), nil
+	}
+	apps, psErr := collectPowerShellInstalledApps(ctx)
+	if psErr != nil {
+		if nativeErr != nil {
+			return nil, fmt.Errorf("native registry: %v; %w", nativeErr, psErr)
+		}
+		return nil, psErr
+	}
+	return apps, nil
+}
+
+// collectPowerShellInstalledApps 通过 PowerShell Get-ItemProperty 读取卸载项（原生读取的回退路径）。
+func collectPowerShellInstalledApps(ctx context.Context) ([]model.AppRecord, error) {
+	out, err := toolbox.Output(ctx, "powershell", "-NoProfile", "-Command", `
 $ErrorActionPreference = 'SilentlyContinue'
 $paths = @(
   'HKLM:\Software\Microsoft\Windows\CurrentVersion\Uninstall\*',
@@ -508,7 +703,6 @@ Get-ItemProperty $paths |
   Select-Object DisplayName,DisplayVersion,Publisher,InstallLocation,InstallDate,UninstallString,DisplayIcon |
   ConvertTo-Json -Depth 3
 `)
-	out, err := cmd.Output()
 	if err != nil {
 		return nil, fmt.Errorf("powershell query failed: %w", err)
 	}
@@ -534,15 +728,18 @@ Get-ItemProperty $paths |
 
 	apps := make([]model.AppRecord, 0, len(many))
 	for _, item := range many {
-		apps = append(apps, model.AppRecord{
-			Name:            strings.TrimSpace(item.DisplayName),
-			Version:         strings.TrimSpace(item.DisplayVersion),
-			Publisher:       strings.TrimSpace(item.Publisher),
-			InstallLocation: strings.TrimSpace(item.InstallLocation),
-			InstallDate:     strings.TrimSpace(item.InstallDate),
-			UninstallString: strings.TrimSpace(item.UninstallString),
-			DisplayIcon:     strings.TrimSpace(item.DisplayIcon),
+		app, ok := appFromUninstallValues(map[string]string{
+			"DisplayName":     item.DisplayName,
+			"DisplayVersion":  item.DisplayVersion,
+			"Publisher":       item.Publisher,
+			"InstallLocation": item.InstallLocation,
+			"InstallDate":     item.InstallDate,
+			"UninstallString": item.UninstallString,
+			"DisplayIcon":     item.DisplayIcon,
 		})
+		if ok {
+			apps = append(apps, app)
+		}
 	}
 	return dedupeApps(apps), nil
 }
@@ -638,44 +835,44 @@ func readMacAppInfo(appPath string) macAppInfo {
 	}
 }
 
-// collectWindowsExtensions 扫描 Chrome/Edge/Firefox 扩展目录。
-func collectWindowsExtensions() ([]model.ExtensionRecord, error) {
+// collectWindowsExtensions 扫描 Chromium 系浏览器（默认目录、运行中进程、便携版）与 Firefox 系 profile 的扩展。
+func collectWindowsExtensions(ctx context.Context, firefox []firefoxRoot) ([]model.ExtensionRecord, error) {
 	local := os.Getenv("LOCALAPPDATA")
 	appdata := os.Getenv("APPDATA")
 	if local == "" && appdata == "" {
 		return nil, errors.New("LOCALAPPDATA and APPDATA are empty")
 	}
 
-	var out []model.ExtensionRecord
-	if local != "" {
-		out = append(out, scanChromiumExtensions(filepath.Join(local, "Google", "Chrome", "User Data"), "chrome")...)
-		out = append(out, scanChromiumExtensions(filepath.Join(local, "Microsoft", "Edge", "User Data"), "edge")...)
-	}
-	if appdata != "" {
-		out = append(out, scanFirefoxExtensions(filepath.Join(appdata, "Mozilla", "Firefox", "Profiles"))...)
-	}
+	roots := windowsChromiumRoots(local, appdata)
+	roots = append(roots, rootsFromProcesses(listBrowserProcesses(ctx, model.OSWindows))...)
+	roots = append(roots, findPortableChromiumRoots(ctx, portableSearchDirs(model.OSWindows, os.Getenv("USERPROFILE")))...)
+	out := scanChromiumRoots(roots)
+	out = append(out, scanFirefoxRoots(firefox)...)
 	return dedupeExtensions(out), nil
 }
 
-// collectMacExtensions 扫描 macOS 下 Chrome/Edge/Firefox 扩展目录。
-func collectMacExtensions() ([]model.ExtensionRecord, error) {
+// collectMacExtensions 扫描 macOS 下 Chromium 系浏览器（默认目录、运行中进程、便携版）与 Firefox 系 profile 的扩展。
+func collectMacExtensions(ctx context.Context, firefox []firefoxRoot) ([]model.ExtensionRecord, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, err
 	}
 
-	var out []model.ExtensionRecord
-	out = append(out, scanChromiumExtensions(filepath.Join(home, "Library", "Application Support", "Google", "Chrome"), "chrome")...)
-	out = append(out, scanChromiumExtensions(filepath.Join(home, "Library", "Application Support", "Microsoft Edge"), "edge")...)
-	out = append(out, scanFirefoxExtensions(filepath.Join(home, "Library", "Application Support", "Firefox", "Profiles"))...)
+	roots := macChromiumRoots(home)
+	roots = append(roots, rootsFromProcesses(listBrowserProcesses(ctx, model.OSMacOS))...)
+	roots = append(roots, findPortableChromiumRoots(ctx, portableSearchDirs(model.OSMacOS, home))...)
+	out := scanChromiumRoots(roots)
+	out = append(out, scanFirefoxRoots(firefox)...)
 	return dedupeExtensions(out), nil
 }
 
 // scanChromiumExtensions 扫描 Chromium 系浏览器扩展目录结构：
-// {profile}/Extensions/{extensionID}
+// {profile}/Extensions/{extensionID}；Opera 等单 profile 浏览器的用户数据目录本身就是 profile（{root}/Extensions/{extensionID}）。
 func scanChromiumExtensions(root, browser string) []model.ExtensionRecord {
 	pattern := filepath.Join(root, "*", "Extensions", "*")
 	matches, _ := filepath.Glob(pattern)
+	single, _ := filepath.Glob(filepath.Join(root, "Extensions", "*"))
+	matches = append(matches, single...)
 
 	out := make([]model.ExtensionRecord, 0, len(matches))
 	for _, m := range matches {
@@ -705,8 +902,8 @@ func scanChromiumExtensions(root, browser string) []model.ExtensionRecord {
 	return out
 }
 
-// scanFirefoxExtensions 扫描 Firefox 扩展目录并提取 profile 信息。
-func scanFirefoxExtensions(profileRoot string) []model.ExtensionRecord {
+// scanFirefoxExtensions 扫描 Firefox 系浏览器的 profile 根目录并提取各 profile 的扩展。
+func scanFirefoxExtensions(profileRoot, browser string) []model.ExtensionRecord {
 	// Firefox 的真实扩展信息（id/name/version/active）优先来自 extensions.json。
 	// 该文件位于 profile 根目录，结构稳定且无需解压 xpi。
 	profiles, _ := filepath.Glob(filepath.Join(profileRoot, "*"))
@@ -746,7 +943,7 @@ func scanFirefoxExtensions(profileRoot string) []model.ExtensionRecord {
 						continue
 					}
 					out = append(out, model.ExtensionRecord{
-						Browser:     "firefox",
+						Browser:     browser,
 						Profile:     profile,
 						ExtensionID: id,
 						Name:        strings.TrimSpace(a.DefaultLocale.Name),
@@ -768,7 +965,7 @@ func scanFirefoxExtensions(profileRoot string) []model.ExtensionRecord {
 				continue
 			}
 			out = append(out, model.ExtensionRecord{
-				Browser:     "firefox",
+				Browser:     browser,
 				Profile:     profile,
 				ExtensionID: strings.TrimSuffix(name, filepath.Ext(name)),
 				Name:        name,
@@ -969,8 +1166,8 @@ func lookupChromiumLocaleMessage(extVersionDir, defaultLocale, key string) strin
 	return ""
 }
 
-// collectWindowsHistory 采集 Windows 下 Chrome/Edge/Firefox 历史。
-func collectWindowsHistory(ctx context.Context) ([]model.VisitRecord, error) {
+// collectWindowsHistory 采集 Windows 下 Chrome/Edge 与 Firefox 系（含 Tor Browser）历史。
+func collectWindowsHistory(ctx context.Context, firefox []firefoxRoot) ([]model.VisitRecord, error) {
 	local := os.Getenv("LOCALAPPDATA")
 	appdata := os.Getenv("APPDATA")
 	if local == "" && appdata == "" {
@@ -982,17 +1179,15 @@ func collectWindowsHistory(ctx context.Context) ([]model.VisitRecord, error) {
 		out = append(out, collectChromiumHistory(ctx, filepath.Join(local, "Google", "Chrome", "User Data"), "chrome")...)
 		out = append(out, collectChromiumHistory(ctx, filepath.Join(local, "Microsoft", "Edge", "User Data"), "edge")...)
 	}
-	if appdata != "" {
-		out = append(out, collectFirefoxHistory(ctx, filepath.Join(appdata, "Mozilla", "Firefox", "Profiles"))...)
-	}
+	out = append(out, collectFirefoxRootsHistory(ctx, firefox)...)
 	if len(out) == 0 {
 		return nil, errors.New("no history records collected")
 	}
 	return out, nil
 }
 
-// collectMacHistory 采集 macOS 下 Chrome/Edge/Firefox/Safari 历史。
-func collectMacHistory(ctx context.Context) ([]model.VisitRecord, error) {
+// collectMacHistory 采集 macOS 下 Chrome/Edge/Safari 与 Firefox 系（含 Tor Browser）历史。
+func collectMacHistory(ctx context.Context, firefox []firefoxRoot) ([]model.VisitRecord, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil, err
@@ -1001,7 +1196,7 @@ func collectMacHistory(ctx context.Context) ([]model.VisitRecord, error) {
 	var out []model.VisitRecord
 	out = append(out, collectChromiumHistory(ctx, filepath.Join(home, "Library", "Application Support", "Google", "Chrome"), "chrome")...)
 	out = append(out, collectChromiumHistory(ctx, filepath.Join(home, "Library", "Application Support", "Microsoft Edge"), "edge")...)
-	out = append(out, collectFirefoxHistory(ctx, filepath.Join(home, "Library", "Application Support", "Firefox", "Profiles"))...)
+	out = append(out, collectFirefoxRootsHistory(ctx, firefox)...)
 	out = append(out, collectSafariHistory(ctx, filepath.Join(home, "Library", "Safari", "History.db"))...)
 	if len(out) == 0 {
 		return nil, errors.New("no history records collected")
@@ -1009,24 +1204,40 @@ func collectMacHistory(ctx context.Context) ([]model.VisitRecord, error) {
 	return out, nil
 }
 
-// collectChromiumHistory 查询 Chromium History 库，提取 URL 与访问时间。
-func collectChromiumHistory(ctx context.Context, profileRoot, browser string) []model.VisitRecord {
-	pattern := filepath.Join(profileRoot, "*", "History")
-	files, _ := filepath.Glob(pattern)
-	var out []model.VisitRecord
+// chromiumVisitQuery 同时取出导航类型与上一跳 URL（visits.from_visit 指向同表的来源访问）。
+const chromiumVisitQuery = `
+SELECT urls.url, COALESCE(urls.title, ''), visits.visit_time,
+       COALESCE(visits.transition, 0), COALESCE(ref.url, '')
+FROM urls
+JOIN visits ON urls.id = visits.url
+LEFT JOIN visits pv ON visits.from_visit > 0 AND pv.id = visits.from_visit
+LEFT JOIN urls ref ON ref.id = pv.url
+ORDER BY visits.visit_time DESC
+LIMIT 1500;
+`
 
-	for _, f := range files {
-		profile := filepath.Base(filepath.Dir(f))
-		query := `
+// chromiumVisitQueryLegacy 用于缺少 transition/from_visit 列的精简或旧版 History 库。
+const chromiumVisitQueryLegacy = `
 SELECT urls.url, COALESCE(urls.title, ''), visits.visit_time
 FROM urls
 JOIN visits ON urls.id = visits.url
 ORDER BY visits.visit_time DESC
 LIMIT 1500;
 `
-		rows, err := querySQLite(ctx, f, query)
+
+// collectChromiumHistory 查询 Chromium History 库，提取 URL、访问时间、导航类型与来源 URL。
+func collectChromiumHistory(ctx context.Context, profileRoot, browser string) []model.VisitRecord {
+	pattern := filepath.Join(profileRoot, "*", "History")
+	files, _ := filepath.Glob(pattern)
+	var out []model.VisitRecord
+
+	for _, f := range files {
+		profile := filepath.Base(filepath.Dir(f))
+		rows, err := querySQLite(ctx, f, chromiumVisitQuery)
 		if err != nil {
-			continue
+			if rows, err = querySQLite(ctx, f, chromiumVisitQueryLegacy); err != nil {
+				continue
+			}
 		}
 		for _, r := range rows {
 			if len(r) < 3 {
@@ -1037,21 +1248,45 @@ LIMIT 1500;
 			if domain == "" {
 				continue
 			}
-			out = append(out, model.VisitRecord{
+			v := model.VisitRecord{
 				Browser:   browser,
 				Profile:   profile,
 				URL:       u,
 				Domain:    domain,
 				Title:     r[1],
 				VisitedAt: chrometimeToEpoch(r[2]),
-			})
+			}
+			if len(r) >= 5 {
+				v.Transition = chromiumTransition(r[3])
+				v.ReferrerURL = strings.TrimSpace(r[4])
+			}
+			out = append(out, v)
 		}
 	}
 	return dedupeVisits(out)
 }
 
-// collectFirefoxHistory 查询 places.sqlite 中访问记录。
-func collectFirefoxHistory(ctx context.Context, profileRoot string) []model.VisitRecord {
+// chromiumCoreTransitions 是 Chromium ui::PageTransition 的核心类型（低 8 位）。
+var chromiumCoreTransitions = []string{
+	"link", "typed", "auto_bookmark", "auto_subframe", "manual_subframe",
+	"generated", "auto_toplevel", "form_submit", "reload", "keyword", "keyword_generated",
+}
+
+// chromiumTransition 把 visits.transition 的核心类型转换为名称；无法解析时返回空。
+func chromiumTransition(v string) string {
+	iv, err := parseInt64(strings.TrimSpace(v))
+	if err != nil || iv < 0 {
+		return ""
+	}
+	core := iv & 0xff
+	if core >= int64(len(chromiumCoreTransitions)) {
+		return ""
+	}
+	return chromiumCoreTransitions[core]
+}
+
+// collectFirefoxHistory 查询 profile 根目录下各 places.sqlite 中的访问记录。
+func collectFirefoxHistory(ctx context.Context, profileRoot, browser string) []model.VisitRecord {
 	pattern := filepath.Join(profileRoot, "*", "places.sqlite")
 	files, _ := filepath.Glob(pattern)
 	var out []model.VisitRecord
@@ -1079,7 +1314,7 @@ LIMIT 1500;
 				continue
 			}
 			out = append(out, model.VisitRecord{
-				Browser:   "firefox",
+				Browser:   browser,
 				Profile:   profile,
 				URL:       u,
 				Domain:    domain,
@@ -1094,6 +1329,8 @@ LIMIT 1500;
 // collectSafariHistory 查询 Safari 的 History.db。
 func collectSafariHistory(ctx context.Context, historyDB string) []model.VisitRecord {
 	if _, err := os.Stat(historyDB); err != nil {
+		// macOS 未授予“完全磁盘访问权限”时 Safari 目录不可读（EPERM）。
+		recordAccess(ctx, AccessCategoryBrowserDB, historyDB, err)
 		return nil
 	}
 	query := `
@@ -1133,13 +1370,16 @@ LIMIT 1500;
 // querySQLite 使用 Go 的 sqlite 驱动读取 sqlite：
 // 先复制数据库（含 -wal/-shm）再查询，避免浏览器锁文件导致读取失败，
 // 同时尽量保留 WAL 中的最新记录（常见于 Chrome/Edge/Safari）。
+// 因权限不足/被锁定无法读取时记录到 ctx 上的 AccessLog。
 func querySQLite(ctx context.Context, dbPath, sqlQuery string) ([][]string, error) {
 	if _, err := os.Stat(dbPath); err != nil {
+		recordAccess(ctx, AccessCategoryBrowserDB, dbPath, err)
 		return nil, err
 	}
 
-	tmpCopy, cleanup, err := copySQLiteForRead(dbPath)
+	tmpCopy, cleanup, err := copySQLiteWithRetry(interferenceFromContext(ctx), dbPath)
 	if err != nil {
+		recordAccess(ctx, AccessCategoryBrowserDB, dbPath, err)
 		return nil, err
 	}
 	defer cleanup()
@@ -1209,6 +1449,11 @@ func copySQLiteForRead(src string) (dst string, cleanup func(), err error) {
 		cleanup()
 		return "", nil, err
 	}
+	// 副本刚写完就不见了：多为杀毒软件隔离，sqlite 打开不存在的文件会建出空库，必须在此拦截。
+	if _, err := os.Stat(dst); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("%w: %s: %v", errInterference, dst, err)
+	}
 
 	// sqlite WAL/SHM sidecars
 	for _, suffix := range []string{"-wal", "-shm"} {
@@ -1254,7 +1499,8 @@ func extractDomain(rawURL string) string {
 	if err != nil {
 		return ""
 	}
-	host := strings.ToLower(strings.TrimSpace(u.Hostname()))
+	// punycode（xn--）统一解码为 Unicode，便于人工识别 IDN 形近仿冒域名。
+	host := idn.ToUnicode(u.Hostname())
 	host = strings.TrimPrefix(host, "www.")
 	return host
 }
@@ -1340,7 +1586,8 @@ func dedupeExtensions(in []model.ExtensionRecord) []model.ExtensionRecord {
 	seen := map[string]struct{}{}
 	out := make([]model.ExtensionRecord, 0, len(in))
 	for _, e := range in {
-		key := strings.ToLower(strings.TrimSpace(e.Browser + "|" + e.Profile + "|" + e.ExtensionID))
+		// 带上路径：便携版与安装版的同名 profile（例如两个 Chrome 的 Default）各自保留。
+		key := strings.ToLower(strings.TrimSpace(e.Browser + "|" + e.Profile + "|" + e.ExtensionID + "|" + e.Path))
 		if key == "" {
 			continue
 		}
@@ -0,0 +1,41 @@
+package host
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestMakeArtifactParts(t *testing.T) {
+	s := NewScanner(filepath.Join(t.TempDir(), "evidence"))
+	var visits []model.VisitRecord
+	for i := 0; i < 50; i++ {
+		visits = append(visits, model.VisitRecord{Browser: "chrome", URL: "https://www.binance.com/en/trade/" + strings.Repeat("x", i)})
+	}
+
+	whole, err := s.makeArtifactParts("case_1", "dev_1", model.ArtifactBrowserHistory, "test_history", "sqlite_extract", visits)
+	if err != nil || len(whole) != 1 || whole[0].PartGroup != "" {
+		t.Fatalf("unlimited: err=%v artifacts=%d", err, len(whole))
+	}
+
+	s.MaxPayloadBytes = int64(len(whole[0].PayloadJSON) / 4)
+	parts, err := s.makeArtifactParts("case_1", "dev_1", model.ArtifactBrowserHistory, "test_history", "sqlite_extract", visits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) < 4 {
+		t.Fatalf("parts=%d", len(parts))
+	}
+	seen := map[string]bool{}
+	for i, p := range parts {
+		if p.PartGroup != parts[0].ID || p.PartNo != i+1 || p.PartCount != len(parts) {
+			t.Fatalf("part %d: group=%s no=%d count=%d", i, p.PartGroup, p.PartNo, p.PartCount)
+		}
+		if seen[p.SnapshotPath] || p.SHA256 == "" {
+			t.Fatalf("part %d snapshot=%s sha256=%s", i, p.SnapshotPath, p.SHA256)
+		}
+		seen[p.SnapshotPath] = true
+	}
+}
@@ -0,0 +1,120 @@
+package host
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// macOS 完全磁盘访问权限（TCC）探测
+//
+// 未授予“完全磁盘访问权限”时，Safari History.db、Mail 数据等目录读取返回 EPERM，
+// 采集器只会得到空结果；这里用几个受 TCC 保护的固定位置探测授权状态，供前置检查与健康检查展示。
+// 注意该权限按“发起进程”（可执行文件或启动它的终端）授予，sudo 无法绕过。
+
+// 单个探测位置的结果。
+const (
+	TCCReadable = "readable"
+	TCCDenied   = "denied"
+	TCCMissing  = "missing"
+	TCCError    = "error"
+)
+
+// 完全磁盘访问权限的总体状态。
+const (
+	FullDiskAccessGranted = "granted"
+	FullDiskAccessDenied  = "denied"
+	// FullDiskAccessUnknown 表示所有探测位置都不存在（或非 macOS），无法判断。
+	FullDiskAccessUnknown = "unknown"
+)
+
+// TCCProbe 是一个受 TCC 保护位置的探测结果。
+type TCCProbe struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// FullDiskAccessStatus 是当前进程的完全磁盘访问权限状态。
+type FullDiskAccessStatus struct {
+	// Applicable 为 false 表示非 macOS，不涉及 TCC。
+	Applicable bool       `json:"applicable"`
+	Status     string     `json:"status"`
+	Executable string     `json:"executable,omitempty"`
+	Probes     []TCCProbe `json:"probes,omitempty"`
+	Hint       string     `json:"hint,omitempty"`
+}
+
+// CheckFullDiskAccess 探测当前进程是否具有完全磁盘访问权限（非 macOS 返回 Applicable=false）。
+func CheckFullDiskAccess() FullDiskAccessStatus {
+	if runtime.GOOS != "darwin" {
+		return FullDiskAccessStatus{Status: FullDiskAccessUnknown}
+	}
+	home, _ := os.UserHomeDir()
+	exe, _ := os.Executable()
+	return checkFullDiskAccess(home, exe)
+}
+
+func checkFullDiskAccess(home, executable string) FullDiskAccessStatus {
+	st := FullDiskAccessStatus{Applicable: true, Status: FullDiskAccessUnknown, Executable: executable}
+	if home == "" {
+		return st
+	}
+	probes := []struct{ name, path string }{
+		{"safari_history", filepath.Join(home, "Library", "Safari", "History.db")},
+		{"mail", filepath.Join(home, "Library", "Mail")},
+		{"tcc_db", filepath.Join(home, "Library", "Application Support", "com.apple.TCC", "TCC.db")},
+	}
+	readable := false
+	for _, p := range probes {
+		probe := probeTCCPath(p.name, p.path)
+		switch probe.Status {
+		case TCCDenied:
+			st.Status = FullDiskAccessDenied
+		case TCCReadable:
+			readable = true
+		}
+		st.Probes = append(st.Probes, probe)
+	}
+	if st.Status != FullDiskAccessDenied && readable {
+		st.Status = FullDiskAccessGranted
+	}
+	if st.Status == FullDiskAccessDenied {
+		target := executable
+		if target == "" {
+			target = "the inspector binary"
+		}
+		st.Hint = "grant Full Disk Access to " + target + " (and the terminal that launches it) in System Settings > Privacy & Security > Full Disk Access, then restart it and rerun; sudo does not bypass this"
+	}
+	return st
+}
+
+// probeTCCPath 只打开文件/目录（不读取内容）判断可读性。
+func probeTCCPath(name, path string) TCCProbe {
+	probe := TCCProbe{Name: name, Path: path, Status: TCCReadable}
+	f, err := os.Open(path)
+	if err == nil {
+		// 目录需要列举才会触发 TCC 检查。
+		if fi, statErr := f.Stat(); statErr == nil && fi.IsDir() {
+			_, err = f.Readdirnames(1)
+			if errors.Is(err, io.EOF) {
+				err = nil
+			}
+		}
+		_ = f.Close()
+	}
+	switch {
+	case err == nil:
+	case AccessDeniedReason(err) != "":
+		probe.Status, probe.Error = TCCDenied, err.Error()
+	case errors.Is(err, fs.ErrNotExist):
+		probe.Status = TCCMissing
+	default:
+		probe.Status, probe.Error = TCCError, err.Error()
+	}
+	return probe
+}
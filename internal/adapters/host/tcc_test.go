@@ -0,0 +1,43 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCheckFullDiskAccess(t *testing.T) {
+	if st := checkFullDiskAccess("", "inspector"); st.Status != FullDiskAccessUnknown {
+		t.Fatalf("no home: %+v", st)
+	}
+
+	home := t.TempDir()
+	if st := checkFullDiskAccess(home, "inspector"); st.Status != FullDiskAccessUnknown || len(st.Probes) != 3 {
+		t.Fatalf("nothing to probe should be unknown: %+v", st)
+	}
+
+	safari := filepath.Join(home, "Library", "Safari")
+	if err := os.MkdirAll(safari, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(safari, "History.db"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if st := checkFullDiskAccess(home, "inspector"); st.Status != FullDiskAccessGranted || st.Hint != "" {
+		t.Fatalf("readable history should be granted: %+v", st)
+	}
+
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		return
+	}
+	mail := filepath.Join(home, "Library", "Mail")
+	if err := os.Mkdir(mail, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(mail, 0o755)
+	st := checkFullDiskAccess(home, "/opt/inspector")
+	if st.Status != FullDiskAccessDenied || st.Probes[1].Status != TCCDenied || st.Hint == "" {
+		t.Fatalf("unreadable mail dir should be denied: %+v", st)
+	}
+}
@@ -0,0 +1,258 @@
+package host
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 虚拟化环境识别
+//
+// 嫌疑人常把钱包装在虚拟机里，宿主机扫描看不到。这里只做只读的文件系统探测：
+// - hypervisor：已知安装位置存在即记录（VMware/VirtualBox/Parallels/Hyper-V/UTM）
+// - disk_image：在各产品默认 VM 目录下查找磁盘镜像（vmdk/vdi/vhd/vhdx/hdd/qcow2），记录大小与修改时间
+// - wsl_distro：%LOCALAPPDATA%\Packages\*\LocalState\ext4.vhdx 与 Docker Desktop 的 WSL 数据盘
+// 不挂载、不解析镜像内容（二次扫描见后续流程）。
+
+// vmImageFormats 是按扩展名识别的镜像格式。
+var vmImageFormats = map[string]string{
+	".vmdk":  "vmdk",
+	".vdi":   "vdi",
+	".vhd":   "vhd",
+	".vhdx":  "vhdx",
+	".hdd":   "hdd", // Parallels：.hdd 为目录型镜像
+	".qcow2": "qcow2",
+}
+
+// vmMaxDepth 限制镜像目录的递归深度（VM 目录通常是 root/<vm>/<disk>，bundle 再深一层）。
+const vmMaxDepth = 4
+
+// vmHypervisorHint 是一个虚拟机软件的已知安装位置。
+type vmHypervisorHint struct {
+	Product string
+	Name    string
+	Path    string
+}
+
+// vmImageRoot 是一个需要查找磁盘镜像的目录。
+type vmImageRoot struct {
+	Product string
+	Path    string
+}
+
+// collectWindowsVirtualization 探测 Windows 主机上的虚拟机软件、镜像与 WSL 发行版。
+func collectWindowsVirtualization(ctx context.Context) []model.VMRecord {
+	programFiles := os.Getenv("ProgramFiles")
+	programFilesX86 := os.Getenv("ProgramFiles(x86)")
+	programData := os.Getenv("ProgramData")
+	profile := os.Getenv("USERPROFILE")
+	local := os.Getenv("LOCALAPPDATA")
+	public := os.Getenv("PUBLIC")
+	systemRoot := os.Getenv("SystemRoot")
+
+	var hints []vmHypervisorHint
+	for _, pf := range []string{programFiles, programFilesX86} {
+		if pf == "" {
+			continue
+		}
+		hints = append(hints,
+			vmHypervisorHint{Product: "vmware", Name: "VMware Workstation", Path: filepath.Join(pf, "VMware", "VMware Workstation")},
+			vmHypervisorHint{Product: "vmware", Name: "VMware Player", Path: filepath.Join(pf, "VMware", "VMware Player")},
+			vmHypervisorHint{Product: "virtualbox", Name: "Oracle VirtualBox", Path: filepath.Join(pf, "Oracle", "VirtualBox")},
+			vmHypervisorHint{Product: "qemu", Name: "QEMU", Path: filepath.Join(pf, "qemu")},
+		)
+	}
+	if systemRoot != "" {
+		hints = append(hints, vmHypervisorHint{Product: "hyperv", Name: "Hyper-V", Path: filepath.Join(systemRoot, "System32", "vmms.exe")})
+	}
+
+	var roots []vmImageRoot
+	if profile != "" {
+		roots = append(roots,
+			vmImageRoot{Product: "vmware", Path: filepath.Join(profile, "Documents", "Virtual Machines")},
+			vmImageRoot{Product: "virtualbox", Path: filepath.Join(profile, "VirtualBox VMs")},
+		)
+	}
+	if public != "" {
+		roots = append(roots, vmImageRoot{Product: "hyperv", Path: filepath.Join(public, "Documents", "Hyper-V", "Virtual hard disks")})
+	}
+	if programData != "" {
+		roots = append(roots, vmImageRoot{Product: "hyperv", Path: filepath.Join(programData, "Microsoft", "Windows", "Virtual Hard Disks")})
+	}
+
+	out := detectVirtualization(ctx, hints, roots)
+	if local != "" {
+		out = append(out, detectWSLDistros(filepath.Join(local, "Packages"), filepath.Join(local, "Docker", "wsl"))...)
+	}
+	return out
+}
+
+// collectMacVirtualization 探测 macOS 主机上的虚拟机软件与镜像。
+func collectMacVirtualization(ctx context.Context) []model.VMRecord {
+	hints := []vmHypervisorHint{
+		{Product: "vmware", Name: "VMware Fusion", Path: "/Applications/VMware Fusion.app"},
+		{Product: "virtualbox", Name: "VirtualBox", Path: "/Applications/VirtualBox.app"},
+		{Product: "parallels", Name: "Parallels Desktop", Path: "/Applications/Parallels Desktop.app"},
+		{Product: "utm", Name: "UTM", Path: "/Applications/UTM.app"},
+	}
+	var roots []vmImageRoot
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		roots = append(roots,
+			vmImageRoot{Product: "vmware", Path: filepath.Join(home, "Virtual Machines.localized")},
+			vmImageRoot{Product: "vmware", Path: filepath.Join(home, "Virtual Machines")},
+			vmImageRoot{Product: "virtualbox", Path: filepath.Join(home, "VirtualBox VMs")},
+			vmImageRoot{Product: "parallels", Path: filepath.Join(home, "Parallels")},
+			vmImageRoot{Product: "utm", Path: filepath.Join(home, "Library", "Containers", "com.utmapp.UTM", "Data", "Documents")},
+		)
+	}
+	return detectVirtualization(ctx, hints, roots)
+}
+
+// detectVirtualization 检查虚拟机软件安装位置，并在镜像目录下查找磁盘镜像（结果按 kind、path 排序）。
+func detectVirtualization(ctx context.Context, hints []vmHypervisorHint, roots []vmImageRoot) []model.VMRecord {
+	out := []model.VMRecord{}
+	for _, h := range hints {
+		info, err := os.Stat(h.Path)
+		if err != nil {
+			continue
+		}
+		out = append(out, model.VMRecord{
+			Kind:       "hypervisor",
+			Product:    h.Product,
+			Name:       h.Name,
+			Path:       h.Path,
+			ModifiedAt: info.ModTime().Unix(),
+		})
+	}
+
+	seen := map[string]struct{}{}
+	for _, root := range roots {
+		for _, rec := range findVMImages(ctx, root) {
+			if _, ok := seen[rec.Path]; ok {
+				continue
+			}
+			seen[rec.Path] = struct{}{}
+			out = append(out, rec)
+		}
+	}
+	sortVMRecords(out)
+	return out
+}
+
+// findVMImages 在 root 下（限定深度）查找磁盘镜像；目录型镜像（Parallels .hdd）不再向下递归。
+func findVMImages(ctx context.Context, root vmImageRoot) []model.VMRecord {
+	if info, err := os.Stat(root.Path); err != nil || !info.IsDir() {
+		return nil
+	}
+	var out []model.VMRecord
+	base := strings.Count(filepath.Clean(root.Path), string(os.PathSeparator))
+	_ = filepath.WalkDir(root.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		format, ok := vmImageFormats[strings.ToLower(filepath.Ext(path))]
+		if !ok {
+			if d.IsDir() && strings.Count(path, string(os.PathSeparator))-base >= vmMaxDepth {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size := info.Size()
+		if d.IsDir() {
+			size = dirSize(path)
+		}
+		out = append(out, model.VMRecord{
+			Kind:       "disk_image",
+			Product:    root.Product,
+			Name:       filepath.Base(path),
+			Path:       path,
+			Format:     format,
+			SizeBytes:  size,
+			ModifiedAt: info.ModTime().Unix(),
+		})
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	return out
+}
+
+// detectWSLDistros 查找 WSL 发行版的 ext4.vhdx（packagesDir 为 %LOCALAPPDATA%\Packages），
+// 以及 Docker Desktop 的 WSL 数据盘（dockerDir 为 %LOCALAPPDATA%\Docker\wsl）。
+func detectWSLDistros(packagesDir, dockerDir string) []model.VMRecord {
+	var out []model.VMRecord
+	add := func(name, path string) {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			return
+		}
+		out = append(out, model.VMRecord{
+			Kind:       "wsl_distro",
+			Product:    "wsl",
+			Name:       name,
+			Path:       path,
+			Format:     "vhdx",
+			SizeBytes:  info.Size(),
+			ModifiedAt: info.ModTime().Unix(),
+		})
+	}
+	if matches, err := filepath.Glob(filepath.Join(packagesDir, "*", "LocalState", "ext4.vhdx")); err == nil {
+		for _, m := range matches {
+			// 包名形如 CanonicalGroupLimited.Ubuntu22.04LTS_79rhkp1fndgsc，去掉发布者后缀作为发行版名。
+			pkg := filepath.Base(filepath.Dir(filepath.Dir(m)))
+			if i := strings.LastIndex(pkg, "_"); i > 0 {
+				pkg = pkg[:i]
+			}
+			add(pkg, m)
+		}
+	}
+	if matches, err := filepath.Glob(filepath.Join(dockerDir, "*", "ext4.vhdx")); err == nil {
+		for _, m := range matches {
+			add("docker-desktop-"+filepath.Base(filepath.Dir(m)), m)
+		}
+	}
+	sortVMRecords(out)
+	return out
+}
+
+func dirSize(root string) int64 {
+	var total int64
+	_ = filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+func sortVMRecords(list []model.VMRecord) {
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Path < list[j].Path
+	})
+}
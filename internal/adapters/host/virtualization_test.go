@@ -0,0 +1,60 @@
+package host
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectVirtualization(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel string, size int) string {
+		p := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	vbox := write("VirtualBox VMs/win10/win10.vdi", 300)
+	write("VirtualBox VMs/win10/win10.vbox", 10)
+	write("Parallels/Ubuntu.pvm/disk.hdd/disk.hds", 200)
+	write("Parallels/Ubuntu.pvm/disk.hdd/DiskDescriptor.xml", 20)
+	write("VirtualBox VMs/a/b/c/d/e/deep.vmdk", 1) // 超出递归深度
+	app := filepath.Join(dir, "Apps", "VirtualBox.app")
+	if err := os.MkdirAll(app, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got := detectVirtualization(context.Background(),
+		[]vmHypervisorHint{
+			{Product: "virtualbox", Name: "VirtualBox", Path: app},
+			{Product: "vmware", Name: "VMware Fusion", Path: filepath.Join(dir, "Apps", "missing.app")},
+		},
+		[]vmImageRoot{
+			{Product: "virtualbox", Path: filepath.Join(dir, "VirtualBox VMs")},
+			{Product: "parallels", Path: filepath.Join(dir, "Parallels")},
+			{Product: "vmware", Path: filepath.Join(dir, "missing")},
+		})
+	if len(got) != 3 {
+		t.Fatalf("records=%+v", got)
+	}
+	if got[0].Kind != "disk_image" || got[0].Format != "hdd" || got[0].SizeBytes != 220 {
+		t.Fatalf("parallels=%+v", got[0])
+	}
+	if got[1].Path != vbox || got[1].Format != "vdi" || got[1].SizeBytes != 300 || got[1].ModifiedAt == 0 {
+		t.Fatalf("virtualbox=%+v", got[1])
+	}
+	if got[2].Kind != "hypervisor" || got[2].Product != "virtualbox" {
+		t.Fatalf("hypervisor=%+v", got[2])
+	}
+
+	wsl := write("Packages/CanonicalGroupLimited.Ubuntu22.04LTS_79rhkp1fndgsc/LocalState/ext4.vhdx", 50)
+	distros := detectWSLDistros(filepath.Join(dir, "Packages"), filepath.Join(dir, "Docker", "wsl"))
+	if len(distros) != 1 || distros[0].Name != "CanonicalGroupLimited.Ubuntu22.04LTS" || distros[0].Path != wsl || distros[0].SizeBytes != 50 {
+		t.Fatalf("wsl=%+v", distros)
+	}
+}
@@ -0,0 +1,129 @@
+package mobile
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// reAndroidAccount 匹配 dumpsys account 输出中的账户行：
+//
+//	Account {name=someone@gmail.com, type=com.google}
+var reAndroidAccount = regexp.MustCompile(`Account \{name=(.*?), type=([^}\s]+)\}`)
+
+type androidAccount struct {
+	Name string
+	Type string
+}
+
+// collectAndroidAccounts 通过 `adb shell dumpsys account` 读取设备登记的系统账户清单。
+//
+// 仅做逻辑采集：shell 用户可读的 dumpsys 输出，不涉及凭据/令牌。
+// 设备上的 Google 账户等信息可作为后续向服务商调证的依据。
+func collectAndroidAccounts(ctx context.Context, serial string) ([]androidAccount, error) {
+	serial = strings.TrimSpace(serial)
+	if serial == "" {
+		return nil, fmt.Errorf("android serial is empty")
+	}
+	raw, err := runCmd(ctx, "adb", "-s", serial, "shell", "dumpsys", "account")
+	if err != nil {
+		return nil, err
+	}
+	accounts := parseAndroidAccounts(raw)
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no accounts parsed from dumpsys account (may be restricted on this device)")
+	}
+	return accounts, nil
+}
+
+// parseAndroidAccounts 解析 dumpsys account 输出，返回去重后的账户（按 type、name 排序）。
+func parseAndroidAccounts(raw string) []androidAccount {
+	set := map[androidAccount]struct{}{}
+	for _, m := range reAndroidAccount.FindAllStringSubmatch(raw, -1) {
+		a := androidAccount{Name: strings.TrimSpace(m[1]), Type: strings.TrimSpace(m[2])}
+		if a.Name == "" || a.Type == "" {
+			continue
+		}
+		set[a] = struct{}{}
+	}
+	out := make([]androidAccount, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// collectAndroidBookmarks 通过 ADB content provider 采集浏览器书签（best effort）。
+//
+// 与浏览历史共用候选 provider，只取 bookmark=1 的行；现代 Chrome 通常拒绝 shell 访问，
+// 因此失败时由上层记为 skipped。
+func collectAndroidBookmarks(ctx context.Context, serial string) (AndroidHistoryCollectResult, error) {
+	serial = strings.TrimSpace(serial)
+	if serial == "" {
+		return AndroidHistoryCollectResult{}, fmt.Errorf("android serial is empty")
+	}
+
+	candidates := []struct {
+		URI     string
+		Browser string
+	}{
+		{URI: "content://browser/bookmarks", Browser: "android_browser"},
+		{URI: "content://com.android.browser/bookmarks", Browser: "aosp_browser"},
+		{URI: "content://com.android.chrome.browser/bookmarks", Browser: "chrome"},
+		{URI: "content://com.sec.android.app.sbrowser.browser/bookmarks", Browser: "samsung_browser"},
+	}
+
+	var attempts []AndroidHistoryAttempt
+	for _, c := range candidates {
+		raw, err := runCmd(ctx, "adb", "-s", serial, "shell", "content", "query", "--uri", c.URI, "--where", "bookmark=1")
+		if err != nil {
+			attempts = append(attempts, AndroidHistoryAttempt{URI: c.URI, Status: "error", Error: err.Error()})
+			continue
+		}
+		marks := parseAndroidContentQueryVisits(raw, c.Browser, "adb_content_bookmark", 5000)
+		status := "empty"
+		if len(marks) > 0 {
+			status = "ok"
+		}
+		attempts = append(attempts, AndroidHistoryAttempt{URI: c.URI, Status: status, ParsedCount: len(marks)})
+		if len(marks) > 0 {
+			return AndroidHistoryCollectResult{
+				Visits:    marks,
+				SourceRef: "android_browser_bookmarks",
+				Method:    "adb_shell_content_query",
+				UsedURI:   c.URI,
+				Attempts:  attempts,
+			}, nil
+		}
+	}
+	return AndroidHistoryCollectResult{
+		SourceRef: "android_browser_bookmarks",
+		Method:    "adb_shell_content_query",
+		Attempts:  attempts,
+	}, fmt.Errorf("no bookmarks extracted via content providers (may be unsupported or permission denied)")
+}
+
+// androidAccountRecords 把解析出的账户转换为证据记录。
+func androidAccountRecords(deviceID, serial string, accounts []androidAccount) []model.MobileAccountRecord {
+	out := make([]model.MobileAccountRecord, 0, len(accounts))
+	for _, a := range accounts {
+		out = append(out, model.MobileAccountRecord{
+			OS:          model.OSAndroid,
+			DeviceID:    deviceID,
+			Identifier:  serial,
+			Name:        a.Name,
+			AccountType: a.Type,
+		})
+	}
+	return out
+}
@@ -0,0 +1,22 @@
+package mobile
+
+import "testing"
+
+func TestParseAndroidAccounts(t *testing.T) {
+	raw := `User UserInfo{0:Owner:c13} :
+  Accounts: 3
+    Account {name=someone@gmail.com, type=com.google}
+    Account {name=trader, type=com.binance.dev}
+    Account {name=someone@gmail.com, type=com.google}
+
+  Active Sessions: 0
+`
+	got := parseAndroidAccounts(raw)
+	if len(got) != 2 {
+		t.Fatalf("accounts=%+v", got)
+	}
+	if got[0] != (androidAccount{Name: "trader", Type: "com.binance.dev"}) ||
+		got[1] != (androidAccount{Name: "someone@gmail.com", Type: "com.google"}) {
+		t.Fatalf("accounts=%+v", got)
+	}
+}
@@ -0,0 +1,175 @@
+package mobile
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+	"crypto-inspector/internal/platform/toolbox"
+)
+
+// HarmonyOS 采集（hdc）
+//
+// HarmonyOS NEXT 设备没有 adb，改用 hdc（HarmonyOS Device Connector，随 DevEco/命令行工具发布）：
+//   - hdc list targets -v：列出设备连接标识（connect key）、连接方式与状态；Connected 视为已授权，
+//     Unauthorized 表示设备上尚未点击“允许调试”
+//   - hdc -t <key> shell param get：读取设备型号（best effort）
+//   - hdc -t <key> shell bm dump -a：读取已安装应用包名，写入 mobile_packages 证据参与钱包规则匹配
+// 只做逻辑采集，不安装任何组件，也不读取应用数据目录。
+
+// reHarmonyBundle 匹配 bm dump -a 输出中的包名行（反向域名形式）。
+var reHarmonyBundle = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z0-9_]+)+$`)
+
+type hdcTarget struct {
+	Key        string
+	Connection string
+	State      string
+}
+
+func (s *Scanner) scanHarmony(ctx context.Context, caseID string) ([]ConnectedDevice, []model.Artifact, []model.PrecheckResult, []string, error) {
+	if _, err := toolbox.LookPathContext(ctx, "hdc"); err != nil {
+		return nil, nil, nil, []string{"hdc not found, skip harmonyos scan"}, nil
+	}
+
+	raw, err := runCmd(ctx, "hdc", "list", "targets", "-v")
+	if err != nil {
+		return nil, nil, nil, []string{"hdc list targets failed: " + err.Error()}, nil
+	}
+
+	var connected []ConnectedDevice
+	var artifacts []model.Artifact
+	var prechecks []model.PrecheckResult
+	var warnings []string
+
+	for _, t := range parseHDCTargets(raw) {
+		authorized := t.State == "connected"
+		name := t.Key
+		if authorized {
+			if n, err := queryHarmonyParam(ctx, t.Key, "const.product.name"); err == nil && n != "" {
+				name = n
+			}
+		}
+		dev := model.Device{
+			ID:         id.New("dev"),
+			Name:       name,
+			OS:         model.OSHarmonyOS,
+			Identifier: t.Key,
+		}
+		connected = append(connected, ConnectedDevice{
+			Device:         dev,
+			ConnectionType: t.Connection,
+			Authorized:     authorized,
+			AuthNote:       t.State,
+		})
+
+		check := model.PrecheckResult{
+			CaseID:     caseID,
+			DeviceID:   dev.ID,
+			ScanScope:  "mobile",
+			CheckCode:  "harmony_bundles",
+			CheckName:  "HarmonyOS 应用清单采集（bm dump -a）",
+			Required:   false,
+			CheckedAt:  time.Now().Unix(),
+			DetailJSON: mustJSON(map[string]any{"connect_key": t.Key}),
+		}
+		if !authorized {
+			warnings = append(warnings, fmt.Sprintf("harmonyos device %s not authorized/state=%s", t.Key, t.State))
+			check.Status = model.PrecheckSkipped
+			check.Message = fmt.Sprintf("device state=%s (need debugging authorization on the device)", t.State)
+			prechecks = append(prechecks, check)
+			continue
+		}
+
+		bundlesRaw, err := runCmd(ctx, "hdc", "-t", t.Key, "shell", "bm", "dump", "-a")
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("collect harmonyos bundles failed (%s): %v", t.Key, err))
+			check.Status = model.PrecheckSkipped
+			check.Message = err.Error()
+			prechecks = append(prechecks, check)
+			continue
+		}
+		bundles := parseHarmonyBundles(bundlesRaw)
+		records := make([]model.MobilePackageRecord, 0, len(bundles))
+		for _, b := range bundles {
+			records = append(records, model.MobilePackageRecord{
+				OS:         model.OSHarmonyOS,
+				DeviceID:   dev.ID,
+				Identifier: dev.Identifier,
+				Package:    b,
+			})
+		}
+		art, err := s.makeArtifact(caseID, dev.ID, model.ArtifactMobilePackages, "harmony_bm_bundles", "hdc_shell_bm_dump", records)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		artifacts = append(artifacts, art)
+		check.Status = model.PrecheckPassed
+		check.Message = fmt.Sprintf("ok (%d bundles)", len(records))
+		prechecks = append(prechecks, check)
+	}
+
+	return connected, artifacts, prechecks, warnings, nil
+}
+
+// parseHDCTargets 解析 hdc list targets -v 输出：
+//
+//	7001005458323933328a01fce2d18a00	USB	Connected	localhost	hdc
+//	192.168.1.8:5555	TCP	Offline	localhost	hdc
+//
+// 没有设备时输出 [Empty]。连接方式与状态统一为小写。
+func parseHDCTargets(raw string) []hdcTarget {
+	s := bufio.NewScanner(strings.NewReader(raw))
+	seen := map[string]bool{}
+	out := []hdcTarget{}
+	for s.Scan() {
+		parts := strings.Fields(s.Text())
+		if len(parts) == 0 || strings.EqualFold(parts[0], "[Empty]") || seen[parts[0]] {
+			continue
+		}
+		t := hdcTarget{Key: parts[0], Connection: "usb", State: "unknown"}
+		if len(parts) >= 3 {
+			t.Connection = strings.ToLower(parts[1])
+			t.State = strings.ToLower(parts[2])
+		}
+		seen[t.Key] = true
+		out = append(out, t)
+	}
+	return out
+}
+
+// parseHarmonyBundles 解析 bm dump -a 输出（按用户分组列出包名），返回去重排序后的包名。
+func parseHarmonyBundles(raw string) []string {
+	s := bufio.NewScanner(strings.NewReader(raw))
+	set := map[string]struct{}{}
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if !reHarmonyBundle.MatchString(line) {
+			continue
+		}
+		set[line] = struct{}{}
+	}
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func queryHarmonyParam(ctx context.Context, key, param string) (string, error) {
+	out, err := runCmd(ctx, "hdc", "-t", key, "shell", "param", "get", param)
+	if err != nil {
+		return "", err
+	}
+	v := strings.TrimSpace(out)
+	if strings.Contains(strings.ToLower(v), "fail") {
+		return "", fmt.Errorf("param get %s: %s", param, v)
+	}
+	return v, nil
+}
@@ -0,0 +1,32 @@
+package mobile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseHDCTargets(t *testing.T) {
+	raw := "7001005458323933328a01fce2d18a00\tUSB\tConnected\tlocalhost\thdc\n" +
+		"192.168.1.8:5555\tTCP\tUnauthorized\tlocalhost\thdc\n" +
+		"7001005458323933328a01fce2d18a00\tUSB\tConnected\tlocalhost\thdc\n"
+	got := parseHDCTargets(raw)
+	want := []hdcTarget{
+		{Key: "7001005458323933328a01fce2d18a00", Connection: "usb", State: "connected"},
+		{Key: "192.168.1.8:5555", Connection: "tcp", State: "unauthorized"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("targets=%+v", got)
+	}
+	if got := parseHDCTargets("[Empty]\n"); len(got) != 0 {
+		t.Fatalf("empty targets=%+v", got)
+	}
+}
+
+func TestParseHarmonyBundles(t *testing.T) {
+	raw := "ID: 100:\n\tcom.huawei.hmos.settings\n\tio.metamask\n\tcom.huawei.hmos.settings\n\nID: 0:\n\tcom.ohos.launcher\n"
+	got := parseHarmonyBundles(raw)
+	want := []string{"com.huawei.hmos.settings", "com.ohos.launcher", "io.metamask"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("bundles=%v", got)
+	}
+}
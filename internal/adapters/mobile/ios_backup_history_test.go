@@ -181,3 +181,13 @@ func mustExec(t *testing.T, db *sql.DB, q string, args ...any) {
 		t.Fatalf("exec %q: %v", q, err)
 	}
 }
+
+func TestParseIOSDiskUsage(t *testing.T) {
+	raw := "AmountDataAvailable: 1000\nTotalDataAvailable: 4000\nTotalDataCapacity: 64000\nTotalDiskCapacity: 128000\n"
+	if got := parseIOSDiskUsage(raw); got != 60000 {
+		t.Fatalf("estimate=%d", got)
+	}
+	if got := parseIOSDiskUsage("ERROR: Could not connect"); got != 0 {
+		t.Fatalf("unknown estimate=%d", got)
+	}
+}
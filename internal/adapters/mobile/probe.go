@@ -0,0 +1,88 @@
+package mobile
+
+import (
+	"context"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/toolbox"
+)
+
+// DeviceState 是一次轻量探测看到的设备连接/授权状态（不采集任何证据）。
+type DeviceState struct {
+	OS         model.OSType `json:"os"`
+	Identifier string       `json:"identifier"`
+	// State 为原始状态：Android 取 adb devices 的状态列（device/unauthorized/offline...），
+	// iOS 为 paired/unpaired（idevicepair validate 的结果），HarmonyOS 取 hdc list targets -v 的状态列（connected/unauthorized/offline...）。
+	State      string `json:"state"`
+	Authorized bool   `json:"authorized"`
+	AuthNote   string `json:"auth_note,omitempty"`
+}
+
+// Probe 列出当前连接的 Android/iOS/HarmonyOS 设备及授权状态，供实时监测轮询使用。
+//
+// 与 Scanner.Scan 不同，这里只调用 adb devices / idevice_id -l / idevicepair validate / hdc list targets，
+// 不创建设备记录、不落盘；工具缺失或执行失败时以 warnings 返回，不视为错误。
+func Probe(ctx context.Context, enableAndroid, enableIOS, enableHarmony bool) ([]DeviceState, []string) {
+	out := []DeviceState{}
+	var warnings []string
+
+	if enableAndroid {
+		if _, err := toolbox.LookPathContext(ctx, "adb"); err != nil {
+			warnings = append(warnings, "adb not found, skip android monitor")
+		} else if raw, err := runCmd(ctx, "adb", "devices"); err != nil {
+			warnings = append(warnings, "adb devices failed: "+err.Error())
+		} else {
+			for _, d := range parseADBDevices(raw) {
+				out = append(out, DeviceState{
+					OS:         model.OSAndroid,
+					Identifier: d.Serial,
+					State:      d.State,
+					Authorized: d.State == "device",
+					AuthNote:   d.State,
+				})
+			}
+		}
+	}
+
+	if enableIOS {
+		if _, err := toolbox.LookPathContext(ctx, "idevice_id"); err != nil {
+			warnings = append(warnings, "idevice_id not found, skip ios monitor")
+		} else if raw, err := runCmd(ctx, "idevice_id", "-l"); err != nil {
+			warnings = append(warnings, "idevice_id -l failed: "+err.Error())
+		} else {
+			for _, udid := range parseUDIDs(raw) {
+				authorized, note := validateIOSPair(ctx, udid)
+				state := "unpaired"
+				if authorized {
+					state = "paired"
+				}
+				out = append(out, DeviceState{
+					OS:         model.OSIOS,
+					Identifier: udid,
+					State:      state,
+					Authorized: authorized,
+					AuthNote:   note,
+				})
+			}
+		}
+	}
+
+	if enableHarmony {
+		if _, err := toolbox.LookPathContext(ctx, "hdc"); err != nil {
+			warnings = append(warnings, "hdc not found, skip harmonyos monitor")
+		} else if raw, err := runCmd(ctx, "hdc", "list", "targets", "-v"); err != nil {
+			warnings = append(warnings, "hdc list targets failed: "+err.Error())
+		} else {
+			for _, t := range parseHDCTargets(raw) {
+				out = append(out, DeviceState{
+					OS:         model.OSHarmonyOS,
+					Identifier: t.Key,
+					State:      t.State,
+					Authorized: t.State == "connected",
+					AuthNote:   t.State,
+				})
+			}
+		}
+	}
+	return out, warnings
+}
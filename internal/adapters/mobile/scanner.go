@@ -7,20 +7,20 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"crypto-inspector/internal/app"
 	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/budget"
+	"crypto-inspector/internal/platform/filetype"
 	"crypto-inspector/internal/platform/hash"
 	"crypto-inspector/internal/platform/id"
-)
-
-const (
-	collectorVersion = "0.1.0"
-	parserVersion    = "0.1.0"
+	"crypto-inspector/internal/platform/snapshot"
+	"crypto-inspector/internal/platform/toolbox"
 )
 
 // ConnectedDevice 描述一次扫描中识别到的移动设备。
@@ -46,21 +46,29 @@ type Scanner struct {
 	EvidenceRoot        string
 	IOSBackupDir        string
 	EnableIOSFullBackup bool
-	// EnableAndroid/EnableIOS 用于控制采集范围（UI 勾选项对齐）。
+	// EnableAndroid/EnableIOS/EnableHarmony 用于控制采集范围（UI 勾选项对齐）。
 	EnableAndroid bool
 	EnableIOS     bool
+	EnableHarmony bool
+	// SnapshotCompression 是 JSON 快照的压缩方式（空/none/gzip），见 platform/snapshot。
+	SnapshotCompression string
+	// Budget 约束 iOS 完整备份的总字节数（nil 表示不限）；超出预算的设备只采元数据。
+	Budget *budget.Budget
+	// MaxPayloadBytes 是单条证据 payload 上限（0 表示不拆分），超出的浏览记录等数组证据拆为多个分片。
+	MaxPayloadBytes int64
 }
 
-func NewScanner(evidenceRoot, iosBackupDir string, enableIOSFullBackup bool, enableAndroid bool, enableIOS bool) *Scanner {
+func NewScanner(evidenceRoot, iosBackupDir string, enableIOSFullBackup bool, enableAndroid bool, enableIOS bool, enableHarmony bool) *Scanner {
 	if iosBackupDir == "" {
 		tmp := filepath.Join(evidenceRoot, "ios_backups")
 		tmp = filepath.Clean(tmp)
 		iosBackupDir = tmp
 	}
-	// 兼容策略：如果两个都为 false，则默认都开启（防止旧调用方因零值导致“全跳过”）。
-	if !enableAndroid && !enableIOS {
+	// 兼容策略：如果全部为 false，则默认都开启（防止旧调用方因零值导致“全跳过”）。
+	if !enableAndroid && !enableIOS && !enableHarmony {
 		enableAndroid = true
 		enableIOS = true
+		enableHarmony = true
 	}
 	return &Scanner{
 		EvidenceRoot:        evidenceRoot,
@@ -68,6 +76,7 @@ func NewScanner(evidenceRoot, iosBackupDir string, enableIOSFullBackup bool, ena
 		EnableIOSFullBackup: enableIOSFullBackup,
 		EnableAndroid:       enableAndroid,
 		EnableIOS:           enableIOS,
+		EnableHarmony:       enableHarmony,
 	}
 }
 
@@ -100,11 +109,24 @@ func (s *Scanner) Scan(ctx context.Context, caseID string) (*ScanResult, error)
 		out.Warnings = append(out.Warnings, "ios scan disabled by request")
 	}
 
+	if s.EnableHarmony {
+		harmonyDevices, harmonyArtifacts, harmonyPrechecks, harmonyWarnings, err := s.scanHarmony(ctx, caseID)
+		if err != nil {
+			return nil, err
+		}
+		out.Devices = append(out.Devices, harmonyDevices...)
+		out.Artifacts = append(out.Artifacts, harmonyArtifacts...)
+		out.Prechecks = append(out.Prechecks, harmonyPrechecks...)
+		out.Warnings = append(out.Warnings, harmonyWarnings...)
+	} else {
+		out.Warnings = append(out.Warnings, "harmonyos scan disabled by request")
+	}
+
 	return out, nil
 }
 
 func (s *Scanner) scanAndroid(ctx context.Context, caseID string) ([]ConnectedDevice, []model.Artifact, []model.PrecheckResult, []string, error) {
-	if _, err := exec.LookPath("adb"); err != nil {
+	if _, err := toolbox.LookPathContext(ctx, "adb"); err != nil {
 		return nil, nil, nil, []string{"adb not found, skip android scan"}, nil
 	}
 
@@ -182,6 +204,15 @@ func (s *Scanner) scanAndroid(ctx context.Context, caseID string) ([]ConnectedDe
 				Package:    pkg,
 			})
 		}
+		schemeCheck := urlSchemePrecheck(caseID, dev.ID, "android_url_schemes", "Android 应用 URL scheme/深度链接声明（dumpsys package r）", d.Serial)
+		if links, err := collectAndroidURLSchemes(ctx, d.Serial); err != nil {
+			schemeCheck.Status, schemeCheck.Message = model.PrecheckSkipped, err.Error()
+			warnings = append(warnings, fmt.Sprintf("collect android url schemes skipped (%s): %v", d.Serial, err))
+		} else {
+			schemeCheck.Status = model.PrecheckPassed
+			schemeCheck.Message = fmt.Sprintf("ok (%d apps declare url schemes/app links)", attachAppLinks(records, links))
+		}
+		prechecks = append(prechecks, schemeCheck)
 
 		art, err := s.makeArtifact(caseID, dev.ID, model.ArtifactMobilePackages, "android_pm_packages", "adb_shell_pm", records)
 		if err != nil {
@@ -263,19 +294,94 @@ func (s *Scanner) scanAndroid(ctx context.Context, caseID string) ([]ConnectedDe
 				}),
 			})
 
-			hArt, err := s.makeArtifact(caseID, dev.ID, model.ArtifactBrowserHistory, hres.SourceRef, hres.Method, hres.Visits)
+			hArts, err := s.makeArtifactParts(caseID, dev.ID, model.ArtifactBrowserHistory, hres.SourceRef, hres.Method, hres.Visits)
 			if err != nil {
 				return nil, nil, nil, nil, err
 			}
-			artifacts = append(artifacts, hArt)
+			artifacts = append(artifacts, hArts...)
 		}
+
+		// Android 系统账户清单 + 浏览器书签（best effort，结果参与交易所规则匹配）。
+		aArts, aChecks, aWarnings, err := s.collectAndroidAccountsAndBookmarks(ctx, caseID, dev, d.Serial)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		artifacts = append(artifacts, aArts...)
+		prechecks = append(prechecks, aChecks...)
+		warnings = append(warnings, aWarnings...)
 	}
 
 	return connected, artifacts, prechecks, warnings, nil
 }
 
+// collectAndroidAccountsAndBookmarks 采集账户清单（dumpsys account）与书签，返回证据、前置检查结果与告警。
+func (s *Scanner) collectAndroidAccountsAndBookmarks(ctx context.Context, caseID string, dev model.Device, serial string) ([]model.Artifact, []model.PrecheckResult, []string, error) {
+	var artifacts []model.Artifact
+	var prechecks []model.PrecheckResult
+	var warnings []string
+
+	accountCheck := model.PrecheckResult{
+		CaseID:     caseID,
+		DeviceID:   dev.ID,
+		ScanScope:  "mobile",
+		CheckCode:  "android_accounts",
+		CheckName:  "Android 系统账户清单采集（dumpsys account）",
+		Required:   false,
+		CheckedAt:  time.Now().Unix(),
+		DetailJSON: mustJSON(map[string]any{"serial": serial}),
+	}
+	accounts, err := collectAndroidAccounts(ctx, serial)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("collect android accounts skipped (%s): %v", serial, err))
+		accountCheck.Status = model.PrecheckSkipped
+		accountCheck.Message = err.Error()
+	} else {
+		art, err := s.makeArtifact(caseID, dev.ID, model.ArtifactMobileAccounts, "android_accounts", "adb_shell_dumpsys_account", androidAccountRecords(dev.ID, serial, accounts))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		artifacts = append(artifacts, art)
+		accountCheck.Status = model.PrecheckPassed
+		accountCheck.Message = fmt.Sprintf("ok (%d accounts)", len(accounts))
+	}
+	prechecks = append(prechecks, accountCheck)
+
+	bres, berr := collectAndroidBookmarks(ctx, serial)
+	bookmarkCheck := model.PrecheckResult{
+		CaseID:    caseID,
+		DeviceID:  dev.ID,
+		ScanScope: "mobile",
+		CheckCode: "android_browser_bookmarks",
+		CheckName: "Android 浏览器书签采集（best effort）",
+		Required:  false,
+		CheckedAt: time.Now().Unix(),
+		DetailJSON: mustJSON(map[string]any{
+			"serial":   serial,
+			"method":   bres.Method,
+			"used_uri": bres.UsedURI,
+			"attempts": bres.Attempts,
+		}),
+	}
+	if berr != nil {
+		warnings = append(warnings, fmt.Sprintf("collect android bookmarks skipped (%s): %v", serial, berr))
+		bookmarkCheck.Status = model.PrecheckSkipped
+		bookmarkCheck.Message = berr.Error()
+	} else {
+		art, err := s.makeArtifact(caseID, dev.ID, model.ArtifactBrowserBookmarks, bres.SourceRef, bres.Method, bres.Visits)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		artifacts = append(artifacts, art)
+		bookmarkCheck.Status = model.PrecheckPassed
+		bookmarkCheck.Message = fmt.Sprintf("ok (%d bookmarks)", len(bres.Visits))
+	}
+	prechecks = append(prechecks, bookmarkCheck)
+
+	return artifacts, prechecks, warnings, nil
+}
+
 func (s *Scanner) scanIOS(ctx context.Context, caseID string) ([]ConnectedDevice, []model.Artifact, []model.PrecheckResult, []string, error) {
-	if _, err := exec.LookPath("idevice_id"); err != nil {
+	if _, err := toolbox.LookPathContext(ctx, "idevice_id"); err != nil {
 		return nil, nil, nil, []string{"idevice_id not found, skip ios scan"}, nil
 	}
 
@@ -318,7 +424,19 @@ func (s *Scanner) scanIOS(ctx context.Context, caseID string) ([]ConnectedDevice
 		backupRoot := filepath.Join(s.IOSBackupDir, udid)
 		backupHint := "skeleton only, no full backup performed"
 		backupErrText := ""
-		if authorized && s.EnableIOSFullBackup {
+		backupKind := string(model.ArtifactMobileBackup)
+		var backupEstimate int64
+		if authorized && s.Budget != nil {
+			backupEstimate = EstimateIOSBackupBytes(ctx, udid)
+		}
+		switch {
+		case !authorized:
+			// 未授权设备无法备份，不计入预算。
+		case !s.EnableIOSFullBackup:
+			s.Budget.Skip(backupKind, udid, backupRoot, backupEstimate)
+		case !s.Budget.Take(backupKind, udid, backupRoot, backupEstimate):
+			backupHint = "full backup skipped: over collection budget, metadata only"
+		default:
 			if err := os.MkdirAll(backupRoot, 0o755); err != nil {
 				backupErrText = err.Error()
 				warnings = append(warnings, fmt.Sprintf("create ios backup root failed (%s): %v", udid, err))
@@ -451,11 +569,11 @@ func (s *Scanner) scanIOS(ctx context.Context, caseID string) ([]ConnectedDevice
 					}),
 				})
 
-				historyArtifact, err := s.makeArtifact(caseID, dev.ID, model.ArtifactBrowserHistory, "ios_safari_history", "ios_backup_manifest", visits)
+				historyParts, err := s.makeArtifactParts(caseID, dev.ID, model.ArtifactBrowserHistory, "ios_safari_history", "ios_backup_manifest", visits)
 				if err != nil {
 					return nil, nil, nil, nil, err
 				}
-				artifacts = append(artifacts, historyArtifact)
+				artifacts = append(artifacts, historyParts...)
 			}
 
 			// Chrome（best effort）
@@ -505,11 +623,11 @@ func (s *Scanner) scanIOS(ctx context.Context, caseID string) ([]ConnectedDevice
 					}),
 				})
 
-				historyArtifact, err := s.makeArtifact(caseID, dev.ID, model.ArtifactBrowserHistory, "ios_chrome_history", "ios_backup_manifest", visits)
+				historyParts, err := s.makeArtifactParts(caseID, dev.ID, model.ArtifactBrowserHistory, "ios_chrome_history", "ios_backup_manifest", visits)
 				if err != nil {
 					return nil, nil, nil, nil, err
 				}
-				artifacts = append(artifacts, historyArtifact)
+				artifacts = append(artifacts, historyParts...)
 			}
 		}
 
@@ -527,6 +645,15 @@ func (s *Scanner) scanIOS(ctx context.Context, caseID string) ([]ConnectedDevice
 				Package:    pkg,
 			})
 		}
+		schemeCheck := urlSchemePrecheck(caseID, dev.ID, "ios_url_schemes", "iOS 应用 URL scheme 声明（ideviceinstaller xml）", udid)
+		if links, err := collectIOSURLSchemes(ctx, udid); err != nil {
+			schemeCheck.Status, schemeCheck.Message = model.PrecheckSkipped, err.Error()
+			warnings = append(warnings, fmt.Sprintf("collect ios url schemes skipped (%s): %v", udid, err))
+		} else {
+			schemeCheck.Status = model.PrecheckPassed
+			schemeCheck.Message = fmt.Sprintf("ok (%d apps declare url schemes)", attachAppLinks(records, links))
+		}
+		prechecks = append(prechecks, schemeCheck)
 		packagesArtifact, err := s.makeArtifact(caseID, dev.ID, model.ArtifactMobilePackages, "ios_installed_apps", "ideviceinstaller_list", records)
 		if err != nil {
 			return nil, nil, nil, nil, err
@@ -538,22 +665,61 @@ func (s *Scanner) scanIOS(ctx context.Context, caseID string) ([]ConnectedDevice
 }
 
 func (s *Scanner) makeArtifact(caseID, deviceID string, t model.ArtifactType, sourceRef, method string, payload any) (model.Artifact, error) {
-	now := time.Now().Unix()
-	artifactID := id.New("art")
-
 	raw, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
 		return model.Artifact{}, fmt.Errorf("marshal payload %s: %w", t, err)
 	}
+	return s.writeArtifact(caseID, deviceID, t, sourceRef, method, raw, time.Now().Unix(), "")
+}
+
+// makeArtifactParts 与 makeArtifact 相同，但数组 payload 超过 MaxPayloadBytes 时拆成多个分片证据
+// （见 snapshot.SplitJSONArray），分片共用第 1 个分片的 ID 作为 PartGroup。
+func (s *Scanner) makeArtifactParts(caseID, deviceID string, t model.ArtifactType, sourceRef, method string, payload any) ([]model.Artifact, error) {
+	raw, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload %s: %w", t, err)
+	}
+	now := time.Now().Unix()
+	chunks := snapshot.SplitJSONArray(raw, s.MaxPayloadBytes)
+	if chunks == nil {
+		a, err := s.writeArtifact(caseID, deviceID, t, sourceRef, method, raw, now, "")
+		if err != nil {
+			return nil, err
+		}
+		return []model.Artifact{a}, nil
+	}
+	out := make([]model.Artifact, 0, len(chunks))
+	for i, chunk := range chunks {
+		a, err := s.writeArtifact(caseID, deviceID, t, sourceRef, method, chunk, now, fmt.Sprintf("_part%03d", i+1))
+		if err != nil {
+			return nil, err
+		}
+		a.PartGroup = a.ID
+		if i > 0 {
+			a.PartGroup = out[0].PartGroup
+		}
+		a.PartNo, a.PartCount = i+1, len(chunks)
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// writeArtifact 把已序列化的 payload 写为快照文件并生成 Artifact；nameSuffix 用于区分同一秒内的分片文件。
+func (s *Scanner) writeArtifact(caseID, deviceID string, t model.ArtifactType, sourceRef, method string, raw []byte, now int64, nameSuffix string) (model.Artifact, error) {
+	artifactID := id.New("art")
 
 	dir := filepath.Join(s.EvidenceRoot, caseID, deviceID)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return model.Artifact{}, fmt.Errorf("create evidence dir: %w", err)
 	}
 
-	name := fmt.Sprintf("%s_%s_%d.json", string(t), sourceRef, now)
-	snapshotPath := filepath.Join(dir, sanitizeFilename(name))
-	if err := os.WriteFile(snapshotPath, raw, 0o644); err != nil {
+	name := fmt.Sprintf("%s_%s_%d%s.json", string(t), sourceRef, now, nameSuffix)
+	compression, err := snapshot.ParseCompression(s.SnapshotCompression)
+	if err != nil {
+		return model.Artifact{}, err
+	}
+	snapshotPath, err := snapshot.WriteJSON(filepath.Join(dir, sanitizeFilename(name)), raw, compression)
+	if err != nil {
 		return model.Artifact{}, fmt.Errorf("write evidence file: %w", err)
 	}
 
@@ -572,8 +738,8 @@ func (s *Scanner) makeArtifact(caseID, deviceID string, t model.ArtifactType, so
 		sum,
 		fmt.Sprintf("%d", size),
 		fmt.Sprintf("%d", now),
-		"mobile_scanner",
-		collectorVersion,
+		app.MobileScanner.Name,
+		app.MobileScanner.CollectorVersion,
 		string(raw),
 	)
 
@@ -586,10 +752,12 @@ func (s *Scanner) makeArtifact(caseID, deviceID string, t model.ArtifactType, so
 		SnapshotPath:      snapshotPath,
 		SHA256:            sum,
 		SizeBytes:         size,
+		MimeType:          filetype.MimeJSON,
+		Compression:       compression,
 		CollectedAt:       now,
-		CollectorName:     "mobile_scanner",
-		CollectorVersion:  collectorVersion,
-		ParserVersion:     parserVersion,
+		CollectorName:     app.MobileScanner.Name,
+		CollectorVersion:  app.MobileScanner.CollectorVersion,
+		ParserVersion:     app.MobileScanner.ParserVersion,
 		AcquisitionMethod: method,
 		PayloadJSON:       raw,
 		RecordHash:        recordHash,
@@ -673,11 +841,10 @@ func parseUDIDs(raw string) []string {
 }
 
 func validateIOSPair(ctx context.Context, udid string) (bool, string) {
-	if _, err := exec.LookPath("idevicepair"); err != nil {
+	if _, err := toolbox.LookPathContext(ctx, "idevicepair"); err != nil {
 		return false, "idevicepair not found"
 	}
-	cmd := exec.CommandContext(ctx, "idevicepair", "-u", udid, "validate")
-	out, err := cmd.CombinedOutput()
+	out, err := toolbox.CombinedOutput(ctx, "idevicepair", "-u", udid, "validate")
 	if err != nil {
 		msg := strings.TrimSpace(string(out))
 		if msg == "" {
@@ -689,7 +856,7 @@ func validateIOSPair(ctx context.Context, udid string) (bool, string) {
 }
 
 func queryIOSDeviceName(ctx context.Context, udid string) (string, error) {
-	if _, err := exec.LookPath("ideviceinfo"); err != nil {
+	if _, err := toolbox.LookPathContext(ctx, "ideviceinfo"); err != nil {
 		return "", err
 	}
 	out, err := runCmd(ctx, "ideviceinfo", "-u", udid, "-k", "DeviceName")
@@ -700,7 +867,7 @@ func queryIOSDeviceName(ctx context.Context, udid string) (string, error) {
 }
 
 func collectIOSPackages(ctx context.Context, udid string) ([]string, error) {
-	if _, err := exec.LookPath("ideviceinstaller"); err != nil {
+	if _, err := toolbox.LookPathContext(ctx, "ideviceinstaller"); err != nil {
 		return nil, errors.New("ideviceinstaller not found")
 	}
 
@@ -739,14 +906,50 @@ func collectIOSPackages(ctx context.Context, udid string) ([]string, error) {
 	return pkgs, nil
 }
 
+// EstimateIOSBackupBytes 用设备已用数据空间（TotalDataCapacity - TotalDataAvailable）估算完整备份大小（上限估计）。
+// 无法读取时返回 0（视为大小未知，不阻止备份）。
+func EstimateIOSBackupBytes(ctx context.Context, udid string) int64 {
+	if _, err := toolbox.LookPathContext(ctx, "ideviceinfo"); err != nil {
+		return 0
+	}
+	raw, err := runCmd(ctx, "ideviceinfo", "-u", udid, "-q", "com.apple.disk_usage")
+	if err != nil {
+		return 0
+	}
+	return parseIOSDiskUsage(raw)
+}
+
+func parseIOSDiskUsage(raw string) int64 {
+	var capacity, available int64
+	for _, line := range strings.Split(raw, "\n") {
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(k) {
+		case "TotalDataCapacity":
+			capacity = n
+		case "TotalDataAvailable":
+			available = n
+		}
+	}
+	if capacity <= available {
+		return 0
+	}
+	return capacity - available
+}
+
 func tryIOSFullBackup(ctx context.Context, udid, backupRoot string) error {
-	if _, err := exec.LookPath("idevicebackup2"); err != nil {
+	if _, err := toolbox.LookPathContext(ctx, "idevicebackup2"); err != nil {
 		return errors.New("idevicebackup2 not found")
 	}
 	backupCtx, cancel := context.WithTimeout(ctx, 15*time.Minute)
 	defer cancel()
-	cmd := exec.CommandContext(backupCtx, "idevicebackup2", "-u", udid, "backup", backupRoot)
-	out, err := cmd.CombinedOutput()
+	out, err := toolbox.CombinedOutput(backupCtx, "idevicebackup2", "-u", udid, "backup", backupRoot)
 	if err != nil {
 		msg := strings.TrimSpace(string(out))
 		if msg == "" {
@@ -757,9 +960,9 @@ func tryIOSFullBackup(ctx context.Context, udid, backupRoot string) error {
 	return nil
 }
 
+// runCmd 通过 context 中的执行器运行外部工具（见 toolbox.WithRunner），失败时把输出内容带入错误信息。
 func runCmd(ctx context.Context, name string, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
-	out, err := cmd.CombinedOutput()
+	out, err := toolbox.CombinedOutput(ctx, name, args...)
 	if err != nil {
 		msg := strings.TrimSpace(string(out))
 		if msg == "" {
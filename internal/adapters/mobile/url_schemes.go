@@ -0,0 +1,230 @@
+package mobile
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/toolbox"
+
+	"howett.net/plist"
+)
+
+// 应用声明的 URL scheme / 深度链接
+//
+// 交易所 App 会注册自定义 scheme（binance://、okx://）或 App Links 域名，用于从网页/推送唤起 App 完成登录、充值等操作；
+// 声明了这些入口说明 App 已安装并配置为可用。这里只读取系统公开的声明信息（不读取 App 数据）：
+// - Android：`dumpsys package r` 的 Activity Resolver Table / Schemes 段（intent filter）
+// - iOS：`ideviceinstaller -l -o xml` 输出中各应用 Info.plist 的 CFBundleURLTypes
+// 结果合并进 mobile_packages 记录的 url_schemes / app_link_hosts 字段。
+
+// appLinks 是单个应用声明的 scheme 与 App Links 域名。
+type appLinks struct {
+	Schemes []string
+	Hosts   []string
+}
+
+// genericSchemes 是系统通用 scheme，几乎所有应用都可能声明，不具区分度。
+var genericSchemes = map[string]bool{
+	"http": true, "https": true, "file": true, "content": true, "data": true, "package": true,
+	"tel": true, "sms": true, "smsto": true, "mms": true, "mmsto": true, "mailto": true,
+	"geo": true, "intent": true, "market": true, "android-app": true, "javascript": true, "about": true,
+}
+
+// reResolverComponent 匹配 resolver 表中的组件行：“  4c8a2b1 com.binance.dev/.DeepLinkActivity filter 9e0f3a2”。
+var reResolverComponent = regexp.MustCompile(`^[0-9a-f]+ ([A-Za-z0-9_.]+)/\S+(?: filter [0-9a-f]+)?$`)
+
+// reResolverAuthority 匹配 intent filter 的 Authority 行：`Authority: "app.binance.com": -1`。
+var reResolverAuthority = regexp.MustCompile(`^Authority: "([^"]+)"`)
+
+// collectAndroidURLSchemes 读取 Android 设备上各应用声明的 scheme 与 App Links 域名（按包名）。
+func collectAndroidURLSchemes(ctx context.Context, serial string) (map[string]*appLinks, error) {
+	serial = strings.TrimSpace(serial)
+	if serial == "" {
+		return nil, fmt.Errorf("android serial is empty")
+	}
+	raw, err := runCmd(ctx, "adb", "-s", serial, "shell", "dumpsys", "package", "r")
+	if err != nil {
+		return nil, err
+	}
+	links := parseAndroidSchemeResolvers(raw)
+	if len(links) == 0 {
+		return nil, errors.New("no url schemes parsed from dumpsys package resolvers")
+	}
+	return links, nil
+}
+
+// parseAndroidSchemeResolvers 解析 `dumpsys package r` 输出中 Activity Resolver Table 的 Schemes 段。
+// 自定义 scheme 记到声明它的包名下；http(s) 只记录 Authority 域名（App Links）。
+func parseAndroidSchemeResolvers(raw string) map[string]*appLinks {
+	out := map[string]*appLinks{}
+	schemeSets := map[string]map[string]struct{}{}
+	hostSets := map[string]map[string]struct{}{}
+	add := func(sets map[string]map[string]struct{}, pkg, v string) {
+		if sets[pkg] == nil {
+			sets[pkg] = map[string]struct{}{}
+		}
+		sets[pkg][v] = struct{}{}
+	}
+
+	inActivity, inSchemes := false, false
+	scheme, pkg := "", ""
+	s := bufio.NewScanner(strings.NewReader(raw))
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		switch {
+		case indent == 0:
+			// 顶层段落：只处理 Activity Resolver Table（Receiver/Service Resolver Table 不对应用户可唤起的入口）。
+			inActivity = trimmed == "Activity Resolver Table:"
+			inSchemes = false
+			continue
+		case !inActivity:
+			continue
+		case indent <= 2:
+			inSchemes = trimmed == "Schemes:"
+			scheme, pkg = "", ""
+			continue
+		case !inSchemes:
+			continue
+		}
+
+		if m := reResolverComponent.FindStringSubmatch(trimmed); m != nil {
+			pkg = strings.ToLower(m[1])
+			if scheme != "" && !genericSchemes[scheme] {
+				add(schemeSets, pkg, scheme)
+			}
+			continue
+		}
+		if m := reResolverAuthority.FindStringSubmatch(trimmed); m != nil {
+			if pkg != "" && (scheme == "http" || scheme == "https") {
+				add(hostSets, pkg, strings.ToLower(strings.TrimPrefix(m[1], "*.")))
+			}
+			continue
+		}
+		// scheme 标题行：“      binance:”（无空格、以冒号结尾，且不是 Action:/Category: 等属性行）。
+		if strings.HasSuffix(trimmed, ":") && !strings.ContainsAny(trimmed, " \"") {
+			scheme = strings.ToLower(strings.TrimSuffix(trimmed, ":"))
+			pkg = ""
+		}
+	}
+
+	for p, set := range schemeSets {
+		out[p] = &appLinks{Schemes: sortedKeys(set)}
+	}
+	for p, set := range hostSets {
+		if out[p] == nil {
+			out[p] = &appLinks{}
+		}
+		out[p].Hosts = sortedKeys(set)
+	}
+	return out
+}
+
+// collectIOSURLSchemes 读取 iOS 设备上用户应用声明的 URL scheme（按 bundle id）。
+// 兼容新旧两种 ideviceinstaller 命令行。
+func collectIOSURLSchemes(ctx context.Context, udid string) (map[string]*appLinks, error) {
+	if _, err := toolbox.LookPathContext(ctx, "ideviceinstaller"); err != nil {
+		return nil, errors.New("ideviceinstaller not found")
+	}
+	raw, err := runCmd(ctx, "ideviceinstaller", "-u", udid, "-l", "-o", "xml")
+	if err != nil || !strings.Contains(raw, "<plist") {
+		raw, err = runCmd(ctx, "ideviceinstaller", "-u", udid, "list", "--xml")
+		if err != nil {
+			return nil, err
+		}
+	}
+	links, err := parseIOSURLSchemes(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(links) == 0 {
+		return nil, errors.New("no url schemes parsed from ideviceinstaller xml output")
+	}
+	return links, nil
+}
+
+// parseIOSURLSchemes 解析 ideviceinstaller XML（plist 数组，每项为应用 Info.plist 字典）中的 CFBundleURLTypes。
+func parseIOSURLSchemes(raw string) (map[string]*appLinks, error) {
+	if i := strings.Index(raw, "<?xml"); i > 0 {
+		raw = raw[i:]
+	}
+	var apps []struct {
+		BundleID string `plist:"CFBundleIdentifier"`
+		URLTypes []struct {
+			Schemes []string `plist:"CFBundleURLSchemes"`
+		} `plist:"CFBundleURLTypes"`
+	}
+	if _, err := plist.Unmarshal([]byte(raw), &apps); err != nil {
+		return nil, fmt.Errorf("decode ideviceinstaller xml: %w", err)
+	}
+	out := map[string]*appLinks{}
+	for _, app := range apps {
+		bundle := strings.ToLower(strings.TrimSpace(app.BundleID))
+		if bundle == "" {
+			continue
+		}
+		set := map[string]struct{}{}
+		for _, t := range app.URLTypes {
+			for _, sc := range t.Schemes {
+				sc = strings.ToLower(strings.TrimSpace(sc))
+				if sc != "" && !genericSchemes[sc] {
+					set[sc] = struct{}{}
+				}
+			}
+		}
+		if len(set) > 0 {
+			out[bundle] = &appLinks{Schemes: sortedKeys(set)}
+		}
+	}
+	return out, nil
+}
+
+// urlSchemePrecheck 返回 scheme 采集的前置检查骨架（Status/Message 由调用方填写）。
+func urlSchemePrecheck(caseID, deviceID, code, name, identifier string) model.PrecheckResult {
+	return model.PrecheckResult{
+		CaseID:     caseID,
+		DeviceID:   deviceID,
+		ScanScope:  "mobile",
+		CheckCode:  code,
+		CheckName:  name,
+		Required:   false,
+		CheckedAt:  time.Now().Unix(),
+		DetailJSON: mustJSON(map[string]any{"identifier": identifier}),
+	}
+}
+
+// attachAppLinks 把 links 合并进同包名的应用记录，返回带有声明的记录数。
+func attachAppLinks(records []model.MobilePackageRecord, links map[string]*appLinks) int {
+	n := 0
+	for i := range records {
+		l := links[strings.ToLower(strings.TrimSpace(records[i].Package))]
+		if l == nil {
+			continue
+		}
+		records[i].URLSchemes = l.Schemes
+		records[i].AppLinkHosts = l.Hosts
+		n++
+	}
+	return n
+}
+
+func sortedKeys(set map[string]struct{}) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,78 @@
+package mobile
+
+import (
+	"reflect"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestParseAndroidSchemeResolvers(t *testing.T) {
+	raw := `Activity Resolver Table:
+  Schemes:
+      binance:
+        4c8a2b1 com.binance.dev/com.binance.app.DeepLinkActivity filter 9e0f3a2
+          Action: "android.intent.action.VIEW"
+          Category: "android.intent.category.BROWSABLE"
+          Scheme: "binance"
+      https:
+        5d6e7f8 com.binance.dev/.AppLinkActivity filter 1a2b3c4
+          Action: "android.intent.action.VIEW"
+          Scheme: "https"
+          Authority: "app.binance.com": -1
+          AutoVerify=true
+        6e7f8a9 com.android.chrome/com.google.android.apps.chrome.IntentDispatcher filter 2b3c4d5
+          Scheme: "https"
+      tel:
+        7f8a9b0 com.android.dialer/.DialtactsActivity filter 3c4d5e6
+
+  Non-Data Actions:
+      android.intent.action.MAIN:
+        8a9b0c1 com.okinc.okex.gp/.MainActivity filter 4d5e6f7
+
+Receiver Resolver Table:
+  Schemes:
+      okx:
+        9b0c1d2 com.okinc.okex.gp/.SchemeReceiver filter 5e6f7a8
+`
+	got := parseAndroidSchemeResolvers(raw)
+	if len(got) != 1 || got["com.binance.dev"] == nil {
+		t.Fatalf("got=%v", got)
+	}
+	if l := got["com.binance.dev"]; !reflect.DeepEqual(l.Schemes, []string{"binance"}) || !reflect.DeepEqual(l.Hosts, []string{"app.binance.com"}) {
+		t.Fatalf("binance links=%+v", l)
+	}
+}
+
+func TestParseIOSURLSchemes(t *testing.T) {
+	raw := `Total: 2 apps
+<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<array>
+  <dict>
+    <key>CFBundleIdentifier</key><string>com.okex.OKExAppstoreFull</string>
+    <key>CFBundleURLTypes</key>
+    <array>
+      <dict><key>CFBundleURLSchemes</key><array><string>okx</string><string>OKEX</string></array></dict>
+      <dict><key>CFBundleURLSchemes</key><array><string>https</string></array></dict>
+    </array>
+  </dict>
+  <dict>
+    <key>CFBundleIdentifier</key><string>com.example.notes</string>
+  </dict>
+</array>
+</plist>`
+	got, err := parseIOSURLSchemes(raw)
+	if err != nil {
+		t.Fatalf("parseIOSURLSchemes: %v", err)
+	}
+	if len(got) != 1 || got["com.okex.okexappstorefull"] == nil || !reflect.DeepEqual(got["com.okex.okexappstorefull"].Schemes, []string{"okex", "okx"}) {
+		t.Fatalf("got=%v", got)
+	}
+
+	records := []model.MobilePackageRecord{{Package: "com.okex.OKExAppstoreFull"}, {Package: "com.example.notes"}}
+	if n := attachAppLinks(records, got); n != 1 || len(records[0].URLSchemes) != 2 || records[1].URLSchemes != nil {
+		t.Fatalf("attach n=%d records=%+v", n, records)
+	}
+}
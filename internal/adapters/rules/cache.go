@@ -0,0 +1,153 @@
+package rules
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 规则加载缓存（serve 模式进程内共享）
+//
+// 每次扫描/页面请求都重新读取、解析并哈希 YAML 规则文件。Cache 以规则文件路径为键缓存 LoadedRules：
+//   - 文件 mtime 与大小均未变：直接返回缓存（只做 stat）
+//   - mtime/大小变化：重新计算文件 sha256，与缓存一致时（例如只是被 touch）仍复用缓存，否则完整重新加载
+// 加载失败不缓存。返回的 *LoadedRules 在调用方之间共享，只能读取、不能修改。
+
+// Cache 是按文件 mtime+哈希校验的规则加载缓存，可并发使用；零值不可用，使用 NewCache 创建。
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+
+	hits          int64
+	misses        int64
+	invalidatedAt int64
+}
+
+// CacheStats 是缓存的使用统计。
+type CacheStats struct {
+	Entries       int   `json:"entries"`
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	InvalidatedAt int64 `json:"invalidated_at,omitempty"`
+}
+
+type cacheEntry struct {
+	stamps [3]fileStamp
+	loaded *LoadedRules
+}
+
+// fileStamp 记录规则文件的 mtime 与大小；文件不存在（仅正则规则允许）时 Missing 为 true。
+type fileStamp struct {
+	ModTime int64
+	Size    int64
+	Missing bool
+}
+
+func NewCache() *Cache {
+	return &Cache{entries: map[string]*cacheEntry{}}
+}
+
+// Load 通过缓存加载 l 指向的规则文件；c 为 nil 时等价于 l.Load(ctx)。
+func (c *Cache) Load(ctx context.Context, l *Loader) (*LoadedRules, error) {
+	if c == nil {
+		return l.Load(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	key := l.WalletFile + "\x00" + l.ExchangeFile + "\x00" + l.RegexFile
+	stamps, ok := l.stamps()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e := c.entries[key]; e != nil && ok {
+		if e.stamps == stamps || l.sameContent(e.loaded) {
+			e.stamps = stamps
+			c.hits++
+			return e.loaded, nil
+		}
+	}
+
+	c.misses++
+	loaded, err := l.Load(ctx)
+	if err != nil {
+		delete(c.entries, key)
+		return nil, err
+	}
+	if ok {
+		c.entries[key] = &cacheEntry{stamps: stamps, loaded: loaded}
+	}
+	return loaded, nil
+}
+
+// Invalidate 清空缓存，返回清除的条目数。规则文件被外部工具原地覆盖且 mtime 未变时可手动调用。
+func (c *Cache) Invalidate() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := len(c.entries)
+	c.entries = map[string]*cacheEntry{}
+	c.invalidatedAt = time.Now().Unix()
+	return n
+}
+
+// Stats 返回缓存的使用统计。
+func (c *Cache) Stats() CacheStats {
+	if c == nil {
+		return CacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{Entries: len(c.entries), Hits: c.hits, Misses: c.misses, InvalidatedAt: c.invalidatedAt}
+}
+
+// stamps 读取三个规则文件的 mtime/大小；任一文件无法 stat（正则规则不存在除外）时 ok 为 false，不走缓存。
+func (l *Loader) stamps() (out [3]fileStamp, ok bool) {
+	for i, p := range []string{l.WalletFile, l.ExchangeFile, l.RegexFile} {
+		if i == 2 && strings.TrimSpace(p) == "" {
+			out[i].Missing = true
+			continue
+		}
+		fi, err := os.Stat(p)
+		if i == 2 && errors.Is(err, fs.ErrNotExist) {
+			out[i].Missing = true
+			continue
+		}
+		if err != nil {
+			return out, false
+		}
+		out[i] = fileStamp{ModTime: fi.ModTime().UnixNano(), Size: fi.Size()}
+	}
+	return out, true
+}
+
+// sameContent 重新计算规则文件的 sha256，判断内容是否与已加载的规则一致。
+func (l *Loader) sameContent(loaded *LoadedRules) bool {
+	want := []string{loaded.WalletSHA256, loaded.ExchangeSHA256, loaded.RegexSHA256}
+	for i, p := range []string{l.WalletFile, l.ExchangeFile, l.RegexFile} {
+		got := ""
+		if strings.TrimSpace(p) != "" {
+			raw, err := os.ReadFile(p)
+			switch {
+			case i == 2 && errors.Is(err, fs.ErrNotExist):
+			case err != nil:
+				return false
+			default:
+				sum := sha256.Sum256(raw)
+				got = hex.EncodeToString(sum[:])
+			}
+		}
+		if got != want[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,62 @@
+package rules
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheLoad(t *testing.T) {
+	dir := t.TempDir()
+	walletPath := filepath.Join(dir, "wallet.yaml")
+	exchangePath := filepath.Join(dir, "exchange.yaml")
+	write := func(p, content string, mtime time.Time) {
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base := time.Now().Add(-time.Hour)
+	write(walletPath, "version: v1\nbundle_type: wallet_signatures\nwallets:\n  - id: metamask\n    name: MetaMask\n    enabled: true\n    mobile:\n      android_packages: [io.metamask]\n", base)
+	write(exchangePath, "version: v1\nbundle_type: exchange_domains\nexchanges:\n  - id: binance\n    name: Binance\n    enabled: true\n    domains: [binance.com]\n", base)
+
+	ctx := context.Background()
+	c := NewCache()
+	loader := NewLoader(walletPath, exchangePath).WithRegexFile(filepath.Join(dir, "missing.yaml"))
+	first, err := c.Load(ctx, loader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := c.Load(ctx, loader); again != first {
+		t.Fatalf("expected cached rules on unchanged files")
+	}
+
+	// 仅修改 mtime、内容不变：哈希一致，仍复用缓存。
+	write(exchangePath, "version: v1\nbundle_type: exchange_domains\nexchanges:\n  - id: binance\n    name: Binance\n    enabled: true\n    domains: [binance.com]\n", base.Add(time.Minute))
+	if again, _ := c.Load(ctx, loader); again != first {
+		t.Fatalf("expected cached rules when only mtime changed")
+	}
+
+	write(exchangePath, "version: v2\nbundle_type: exchange_domains\nexchanges:\n  - id: binance\n    name: Binance\n    enabled: true\n    domains: [binance.com, binance.us]\n", base.Add(2*time.Minute))
+	changed, err := c.Load(ctx, loader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed == first || changed.Exchange.Version != "v2" || changed.WalletSHA256 != first.WalletSHA256 {
+		t.Fatalf("expected reload after content change: %+v", changed.Exchange)
+	}
+
+	if n := c.Invalidate(); n != 1 {
+		t.Fatalf("invalidate cleared %d entries", n)
+	}
+	if reloaded, _ := c.Load(ctx, loader); reloaded == changed {
+		t.Fatalf("expected reload after invalidate")
+	}
+	if st := c.Stats(); st.Hits != 2 || st.Misses != 3 || st.Entries != 1 {
+		t.Fatalf("stats=%+v", st)
+	}
+}
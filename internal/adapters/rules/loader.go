@@ -6,9 +6,12 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"strings"
 
+	"crypto-inspector/internal/domain/apperr"
 	"crypto-inspector/internal/domain/model"
 
 	"gopkg.in/yaml.v3"
@@ -18,6 +21,8 @@ import (
 type Loader struct {
 	WalletFile   string
 	ExchangeFile string
+	// RegexFile 是可选的自定义正则规则文件；为空或文件不存在时不启用。
+	RegexFile string
 }
 
 // LoadedRules 是加载后的规则集合和其文件哈希，用于留痕与版本确认。
@@ -26,13 +31,24 @@ type LoadedRules struct {
 	WalletSHA256   string
 	Exchange       model.ExchangeRuleBundle
 	ExchangeSHA256 string
+	// Regex/RegexSHA256 仅在加载了正则规则文件时非空；RegexRules 为已编译的启用规则。
+	Regex       model.RegexRuleBundle
+	RegexSHA256 string
+	RegexRules  []CompiledRegexRule
 }
 
 func NewLoader(walletFile, exchangeFile string) *Loader {
 	return &Loader{WalletFile: walletFile, ExchangeFile: exchangeFile}
 }
 
+// WithRegexFile 设置自定义正则规则文件路径，便于链式调用。
+func (l *Loader) WithRegexFile(path string) *Loader {
+	l.RegexFile = path
+	return l
+}
+
 // Load 按顺序加载钱包规则与交易所规则，并执行基础结构校验。
+// 读取/解析/校验失败统一返回 apperr.CodeRulesInvalid。
 func (l *Loader) Load(ctx context.Context) (*LoadedRules, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -40,15 +56,15 @@ func (l *Loader) Load(ctx context.Context) (*LoadedRules, error) {
 
 	walletRaw, err := os.ReadFile(l.WalletFile)
 	if err != nil {
-		return nil, fmt.Errorf("read wallet rules: %w", err)
+		return nil, apperr.WrapT(apperr.CodeRulesInvalid, err, "rules.read_wallet")
 	}
 
 	var wallet model.WalletRuleBundle
 	if err := yaml.Unmarshal(walletRaw, &wallet); err != nil {
-		return nil, fmt.Errorf("parse wallet rules: %w", err)
+		return nil, apperr.WrapT(apperr.CodeRulesInvalid, err, "rules.parse_wallet")
 	}
 	if err := validateWalletRules(wallet); err != nil {
-		return nil, err
+		return nil, apperr.Wrap(apperr.CodeRulesInvalid, err, "")
 	}
 
 	if err := ctx.Err(); err != nil {
@@ -57,26 +73,62 @@ func (l *Loader) Load(ctx context.Context) (*LoadedRules, error) {
 
 	exchangeRaw, err := os.ReadFile(l.ExchangeFile)
 	if err != nil {
-		return nil, fmt.Errorf("read exchange rules: %w", err)
+		return nil, apperr.WrapT(apperr.CodeRulesInvalid, err, "rules.read_exchange")
 	}
 
 	var exchange model.ExchangeRuleBundle
 	if err := yaml.Unmarshal(exchangeRaw, &exchange); err != nil {
-		return nil, fmt.Errorf("parse exchange rules: %w", err)
+		return nil, apperr.WrapT(apperr.CodeRulesInvalid, err, "rules.parse_exchange")
 	}
 	if err := validateExchangeRules(exchange); err != nil {
-		return nil, err
+		return nil, apperr.Wrap(apperr.CodeRulesInvalid, err, "")
 	}
 
 	walletSum := sha256.Sum256(walletRaw)
 	exchangeSum := sha256.Sum256(exchangeRaw)
 
-	return &LoadedRules{
+	loaded := &LoadedRules{
 		Wallet:         wallet,
 		WalletSHA256:   hex.EncodeToString(walletSum[:]),
 		Exchange:       exchange,
 		ExchangeSHA256: hex.EncodeToString(exchangeSum[:]),
-	}, nil
+	}
+	if err := l.loadRegex(ctx, loaded); err != nil {
+		return nil, err
+	}
+	return loaded, nil
+}
+
+// loadRegex 加载可选的正则规则文件：路径为空或文件不存在时跳过，其余错误与钱包/交易所规则一样按规则非法处理。
+func (l *Loader) loadRegex(ctx context.Context, loaded *LoadedRules) error {
+	if strings.TrimSpace(l.RegexFile) == "" {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	raw, err := os.ReadFile(l.RegexFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return apperr.WrapT(apperr.CodeRulesInvalid, err, "rules.read_regex")
+	}
+
+	var bundle model.RegexRuleBundle
+	if err := yaml.Unmarshal(raw, &bundle); err != nil {
+		return apperr.WrapT(apperr.CodeRulesInvalid, err, "rules.parse_regex")
+	}
+	compiled, err := compileRegexRules(bundle)
+	if err != nil {
+		return apperr.Wrap(apperr.CodeRulesInvalid, err, "")
+	}
+
+	sum := sha256.Sum256(raw)
+	loaded.Regex = bundle
+	loaded.RegexSHA256 = hex.EncodeToString(sum[:])
+	loaded.RegexRules = compiled
+	return nil
 }
 
 // validateWalletRules 检查钱包规则的完整性与唯一性。
@@ -109,6 +161,9 @@ func validateWalletRules(bundle model.WalletRuleBundle) error {
 		if !hasAnyWalletMatcher(w) {
 			return fmt.Errorf("wallet rules: no matcher found for wallet: %s", id)
 		}
+		if c := strings.TrimSpace(w.Category); c != "" && !model.IsWalletCategory(c) {
+			return fmt.Errorf("wallet rules: invalid category %q for wallet: %s (want one of %s)", c, id, strings.Join(model.WalletCategories(), ", "))
+		}
 	}
 
 	return nil
@@ -124,7 +179,8 @@ func hasAnyWalletMatcher(w model.WalletSignature) bool {
 		len(w.BrowserExtensions.EdgeIDs) > 0 ||
 		len(w.BrowserExtensions.FirefoxIDs) > 0 ||
 		len(w.Mobile.AndroidPackages) > 0 ||
-		len(w.Mobile.IOSBundleIDs) > 0
+		len(w.Mobile.IOSBundleIDs) > 0 ||
+		len(w.Mobile.HarmonyBundleNames) > 0
 }
 
 // validateExchangeRules 检查交易所规则的完整性与唯一性。
@@ -153,10 +209,55 @@ func validateExchangeRules(bundle model.ExchangeRuleBundle) error {
 		if strings.TrimSpace(ex.Name) == "" {
 			return fmt.Errorf("exchange rules: exchange name is required: %s", id)
 		}
-		if len(ex.Domains) == 0 && len(ex.URLsContains) == 0 {
+		if len(ex.Domains) == 0 && len(ex.URLsContains) == 0 && !hasExchangeDesktopMatcher(ex) {
 			return fmt.Errorf("exchange rules: no matcher found for exchange: %s", id)
 		}
+		if err := validateExchangeConditions(ex); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// hasExchangeDesktopMatcher 判断交易所规则是否配置了桌面客户端线索。
+func hasExchangeDesktopMatcher(ex model.ExchangeDomain) bool {
+	return len(ex.Desktop.AppKeywords) > 0 ||
+		len(ex.Desktop.BundleIDs) > 0 ||
+		len(ex.Desktop.InstallPathsWindows) > 0 ||
+		len(ex.Desktop.InstallPathsMacOS) > 0
+}
+
+// validateExchangeConditions 检查结构化条件：条件依附于域名命中，且每条至少有一个子条件。
+func validateExchangeConditions(ex model.ExchangeDomain) error {
+	if len(ex.Conditions) == 0 {
+		return nil
+	}
+	if len(ex.Domains) == 0 {
+		return fmt.Errorf("exchange rules: conditions require domains: %s", ex.ID)
+	}
+	seen := make(map[string]struct{}, len(ex.Conditions))
+	for _, c := range ex.Conditions {
+		cid := strings.TrimSpace(c.ID)
+		if cid == "" {
+			return fmt.Errorf("exchange rules: condition id is required: %s", ex.ID)
+		}
+		if _, ok := seen[cid]; ok {
+			return fmt.Errorf("exchange rules: duplicate condition id: %s/%s", ex.ID, cid)
+		}
+		seen[cid] = struct{}{}
+		if strings.TrimSpace(c.Subdomain) == "" && strings.TrimSpace(c.PathPrefix) == "" && len(c.QueryParams) == 0 {
+			return fmt.Errorf("exchange rules: empty condition: %s/%s", ex.ID, cid)
+		}
+		if _, err := path.Match(strings.TrimSpace(c.Subdomain), ""); err != nil {
+			return fmt.Errorf("exchange rules: invalid subdomain pattern for %s/%s: %w", ex.ID, cid, err)
+		}
+		if p := strings.TrimSpace(c.PathPrefix); p != "" && !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("exchange rules: path_prefix must start with /: %s/%s", ex.ID, cid)
+		}
+		if c.Confidence < 0 || c.Confidence > 1 {
+			return fmt.Errorf("exchange rules: condition confidence must be within [0,1]: %s/%s", ex.ID, cid)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// defaultRegexConfidence 是未配置 confidence 的正则规则的默认置信度。
+const defaultRegexConfidence = 0.6
+
+// CompiledRegexRule 是校验并编译后的正则规则。
+type CompiledRegexRule struct {
+	model.RegexRule
+	Re *regexp.Regexp
+	// FieldSet / TypeSet 为空表示不限制。
+	FieldSet map[string]struct{}
+	TypeSet  map[model.ArtifactType]struct{}
+}
+
+// compileRegexRules 校验正则规则文件并编译启用的规则（禁用的规则同样要求可编译，避免启用时才暴露错误）。
+func compileRegexRules(bundle model.RegexRuleBundle) ([]CompiledRegexRule, error) {
+	if strings.TrimSpace(bundle.Version) == "" {
+		return nil, errors.New("regex rules: version is required")
+	}
+	if strings.TrimSpace(bundle.BundleType) == "" {
+		return nil, errors.New("regex rules: bundle_type is required")
+	}
+
+	out := []CompiledRegexRule{}
+	seen := make(map[string]struct{}, len(bundle.Rules))
+	for _, r := range bundle.Rules {
+		id := strings.TrimSpace(r.ID)
+		if id == "" {
+			return nil, errors.New("regex rules: rule id is required")
+		}
+		if _, ok := seen[id]; ok {
+			return nil, fmt.Errorf("regex rules: duplicate rule id: %s", id)
+		}
+		seen[id] = struct{}{}
+		r.ID = id
+
+		if strings.TrimSpace(r.Name) == "" {
+			return nil, fmt.Errorf("regex rules: rule name is required: %s", id)
+		}
+		if strings.TrimSpace(r.Pattern) == "" {
+			return nil, fmt.Errorf("regex rules: pattern is required: %s", id)
+		}
+		pattern := r.Pattern
+		if r.CaseInsensitive {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("regex rules: invalid pattern for %s: %w", id, err)
+		}
+		if r.ValueGroup = strings.TrimSpace(r.ValueGroup); r.ValueGroup != "" && re.SubexpIndex(r.ValueGroup) < 0 {
+			return nil, fmt.Errorf("regex rules: value_group %q not found in pattern: %s", r.ValueGroup, id)
+		}
+
+		switch model.HitType(strings.TrimSpace(r.HitType)) {
+		case "":
+			r.HitType = string(model.HitRegexMatch)
+		case model.HitRegexMatch, model.HitWalletAddress:
+			r.HitType = strings.TrimSpace(r.HitType)
+		default:
+			return nil, fmt.Errorf("regex rules: unsupported hit_type %q for %s (regex_match|wallet_address)", r.HitType, id)
+		}
+		if r.Confidence < 0 || r.Confidence > 1 {
+			return nil, fmt.Errorf("regex rules: confidence must be within [0,1]: %s", id)
+		}
+		if r.Confidence == 0 {
+			r.Confidence = defaultRegexConfidence
+		}
+
+		c := CompiledRegexRule{RegexRule: r, Re: re}
+		for _, f := range r.Fields {
+			if f = strings.TrimSpace(f); f != "" {
+				if c.FieldSet == nil {
+					c.FieldSet = map[string]struct{}{}
+				}
+				c.FieldSet[f] = struct{}{}
+			}
+		}
+		for _, t := range r.ArtifactTypes {
+			if t = strings.TrimSpace(t); t != "" {
+				if c.TypeSet == nil {
+					c.TypeSet = map[model.ArtifactType]struct{}{}
+				}
+				c.TypeSet[model.ArtifactType(t)] = struct{}{}
+			}
+		}
+		if r.Enabled {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
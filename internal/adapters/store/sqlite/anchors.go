@@ -0,0 +1,67 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 报告哈希外部锚定回执（见 043_evidence_anchors.sql）
+//
+// 回执只追加不修改；锚定请求与回执校验由 services/anchor 完成后写入。
+
+const anchorColumns = `anchor_id, case_id, report_id, sha256, adapter, endpoint, COALESCE(receipt_id, ''), COALESCE(anchored_at, 0), receipt, receipt_sha256, operator, created_at`
+
+// SaveEvidenceAnchor 写入一条锚定回执并返回落库结果。
+func (s *Store) SaveEvidenceAnchor(ctx context.Context, a model.EvidenceAnchor) (*model.EvidenceAnchor, error) {
+	if a.AnchorID == "" {
+		a.AnchorID = id.New("anc")
+	}
+	if a.CreatedAt == 0 {
+		a.CreatedAt = time.Now().Unix()
+	}
+	var anchoredAt any
+	if a.AnchoredAt > 0 {
+		anchoredAt = a.AnchoredAt
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO evidence_anchors(anchor_id, case_id, report_id, sha256, adapter, endpoint, receipt_id, anchored_at, receipt, receipt_sha256, operator, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, a.AnchorID, a.CaseID, a.ReportID, a.SHA256, a.Adapter, a.Endpoint, nullIfEmpty(a.ReceiptID), anchoredAt, a.Receipt, a.ReceiptSHA256, a.Operator, a.CreatedAt); err != nil {
+		return nil, fmt.Errorf("insert evidence anchor: %w", err)
+	}
+	return &a, nil
+}
+
+// ListEvidenceAnchors 返回案件的锚定回执，按写入顺序排列；reportID 为空时不过滤。
+func (s *Store) ListEvidenceAnchors(ctx context.Context, caseID, reportID string) ([]model.EvidenceAnchor, error) {
+	query := `SELECT ` + anchorColumns + ` FROM evidence_anchors WHERE case_id = ?`
+	args := []any{caseID}
+	if reportID != "" {
+		query += ` AND report_id = ?`
+		args = append(args, reportID)
+	}
+	query += ` ORDER BY created_at, rowid`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query evidence anchors: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.EvidenceAnchor{}
+	for rows.Next() {
+		var a model.EvidenceAnchor
+		if err := rows.Scan(&a.AnchorID, &a.CaseID, &a.ReportID, &a.SHA256, &a.Adapter, &a.Endpoint, &a.ReceiptID, &a.AnchoredAt, &a.Receipt, &a.ReceiptSHA256, &a.Operator, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan evidence anchor: %w", err)
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate evidence anchors: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 超大证据分片（见 040_artifact_parts.sql）
+//
+// 拆分由采集器在写快照时完成（snapshot.SplitJSONArray），库里每个分片都是一条普通证据，
+// 这里只负责读取上限配置与按分片组回查。
+
+// SchemaKeyArtifactPayloadMax 是单条证据 payload 上限（字节）的 schema_meta 键；0 表示不拆分。
+const SchemaKeyArtifactPayloadMax = "artifact_payload_max_bytes"
+
+// ArtifactPayloadMaxBytes 返回单条证据 payload 上限（字节，0 表示不拆分）。
+func (s *Store) ArtifactPayloadMaxBytes(ctx context.Context) (int64, error) {
+	v, err := s.GetSchemaMetaValue(ctx, SchemaKeyArtifactPayloadMax)
+	if err != nil {
+		return 0, err
+	}
+	if strings.TrimSpace(v) == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid schema_meta %s: %q", SchemaKeyArtifactPayloadMax, v)
+	}
+	return n, nil
+}
+
+// SetArtifactPayloadMaxBytes 设置单条证据 payload 上限（只影响之后采集的证据）。
+func (s *Store) SetArtifactPayloadMaxBytes(ctx context.Context, n int64) error {
+	if n < 0 {
+		return fmt.Errorf("artifact payload max bytes must be >= 0")
+	}
+	return s.UpsertSchemaMetaValue(ctx, SchemaKeyArtifactPayloadMax, strconv.FormatInt(n, 10))
+}
+
+// ListArtifactParts 返回分片组内的全部分片（按分片序号升序）。
+func (s *Store) ListArtifactParts(ctx context.Context, partGroup string) ([]model.ArtifactInfo, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			artifact_id, case_id, device_id, artifact_type, COALESCE(source_ref, ''),
+			snapshot_path, sha256, size_bytes, collected_at,
+			COALESCE(collector_name, ''), COALESCE(collector_version, ''), COALESCE(parser_version, ''), COALESCE(acquisition_method, ''),
+			COALESCE(mime_type, ''), snapshot_compression, COALESCE(exhibit_no, 0),
+			COALESCE(part_group, ''), COALESCE(part_no, 0), COALESCE(part_count, 0)
+		FROM artifacts
+		WHERE part_group = ?
+		ORDER BY part_no, artifact_id
+	`, partGroup)
+	if err != nil {
+		return nil, fmt.Errorf("query artifact parts: %w", err)
+	}
+	defer rows.Close()
+	return scanArtifactInfoRows(rows)
+}
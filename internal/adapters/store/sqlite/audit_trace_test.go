@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crypto-inspector/internal/platform/trace"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestWithTraceIDKeepsDetailBytes(t *testing.T) {
+	for in, want := range map[string]string{
+		`{"z":1,"a":9007199254740993}`: `{"z":1,"a":9007199254740993,"trace_id":"t1"}`,
+		`{}`:                           `{"trace_id":"t1"}`,
+		`{"trace_id":"keep"}`:          `{"trace_id":"keep"}`,
+		`[1,2]`:                        `[1,2]`,
+	} {
+		if got := string(withTraceID([]byte(in), "t1")); got != want {
+			t.Fatalf("%s: got %s want %s", in, got, want)
+		}
+	}
+}
+
+func TestListAuditLogsByTrace(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := NewStore(db)
+	caseA, err := store.EnsureCase(ctx, "", "", "a", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caseB, err := store.EnsureCase(ctx, "", "", "b", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	traced := trace.WithTraceID(ctx, "trace0001")
+	// 同一 trace 下其他案件的记录在前，按案件过滤时不应挤占 limit。
+	for i := 0; i < 3; i++ {
+		if err := store.AppendAudit(traced, caseB, "", "t", "x", "success", "op", "test", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.AppendAudit(traced, caseA, "", "t", "x", "success", "op", "test", map[string]any{"big": int64(9007199254740993)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AppendAudit(ctx, caseA, "", "t", "y", "success", "op", "test", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := store.ListAuditLogsByTrace(ctx, "trace0001", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("cross-case rows=%d", len(all))
+	}
+	rows, err := store.ListAuditLogsByTrace(ctx, "trace0001", caseA, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].CaseID != caseA {
+		t.Fatalf("case rows=%+v", rows)
+	}
+	var detail map[string]json.RawMessage
+	if err := json.Unmarshal(rows[0].DetailJSON, &detail); err != nil {
+		t.Fatal(err)
+	}
+	if string(detail["big"]) != "9007199254740993" || string(detail["trace_id"]) != `"trace0001"` {
+		t.Fatalf("detail=%s", rows[0].DetailJSON)
+	}
+
+	var plan string
+	if err := db.QueryRowContext(ctx, `EXPLAIN QUERY PLAN SELECT event_id FROM audit_logs WHERE trace_id = ?`, "trace0001").Scan(new(int), new(int), new(int), &plan); err != nil {
+		t.Fatal(err)
+	}
+	if want := "idx_audit_logs_trace"; !strings.Contains(plan, want) {
+		t.Fatalf("query plan %q does not use %s", plan, want)
+	}
+}
@@ -0,0 +1,141 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 按 ID 批量读取（分页写出报告用）
+//
+// 扫描结束后内部 JSON 报告按页（每页几百个 ID）从库中读取本次扫描的命中/证据，写完即释放，
+// 不必在内存中同时持有整份报告。ids 应控制在 SQLite 变量上限内（调用方分页）。
+
+// ListRuleHitsByIDs 按 hit_id 批量读取命中（含关联证据 ID），按 ids 的顺序返回；不存在的 ID 忽略。
+func (s *Store) ListRuleHitsByIDs(ctx context.Context, ids []string) ([]model.RuleHit, error) {
+	if len(ids) == 0 {
+		return []model.RuleHit{}, nil
+	}
+	args := make([]any, 0, len(ids))
+	for _, v := range ids {
+		args = append(args, v)
+	}
+	// 与 ListCaseHitDetails 相同：用 LEFT JOIN + GROUP_CONCAT 聚合 artifact_id，避免单连接下的子查询死锁。
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			h.hit_id, h.case_id, h.device_id, h.hit_type, h.rule_id,
+			COALESCE(h.rule_name, ''), COALESCE(h.rule_bundle_id, ''), COALESCE(h.rule_version, ''), h.matched_value,
+			COALESCE(h.first_seen_at, 0), COALESCE(h.last_seen_at, 0),
+			h.confidence, h.verdict, COALESCE(h.detail_json, ''),
+			COALESCE(GROUP_CONCAT(l.artifact_id, ','), '')
+		FROM rule_hits h
+		LEFT JOIN hit_artifact_links l ON l.hit_id = h.hit_id
+		WHERE h.hit_id IN (`+placeholders(len(ids))+`)
+		GROUP BY h.hit_id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query hits by id: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[string]model.RuleHit, len(ids))
+	for rows.Next() {
+		var h model.RuleHit
+		var hitType, detail, artifactIDsRaw string
+		if err := rows.Scan(
+			&h.ID, &h.CaseID, &h.DeviceID, &hitType, &h.RuleID,
+			&h.RuleName, &h.RuleBundleID, &h.RuleVersion, &h.MatchedValue,
+			&h.FirstSeenAt, &h.LastSeenAt,
+			&h.Confidence, &h.Verdict, &detail,
+			&artifactIDsRaw,
+		); err != nil {
+			return nil, fmt.Errorf("scan hit: %w", err)
+		}
+		h.Type = model.HitType(hitType)
+		if detail != "" {
+			h.DetailJSON = []byte(detail)
+		}
+		for _, p := range strings.Split(artifactIDsRaw, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				h.ArtifactIDs = append(h.ArtifactIDs, p)
+			}
+		}
+		byID[h.ID] = h
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate hits by id: %w", err)
+	}
+
+	out := make([]model.RuleHit, 0, len(byID))
+	for _, v := range ids {
+		if h, ok := byID[v]; ok {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
+
+// ListArtifactsByIDs 按 artifact_id 批量读取证据索引信息，按 ids 的顺序返回；不存在的 ID 忽略。
+func (s *Store) ListArtifactsByIDs(ctx context.Context, ids []string) ([]model.ArtifactInfo, error) {
+	if len(ids) == 0 {
+		return []model.ArtifactInfo{}, nil
+	}
+	args := make([]any, 0, len(ids))
+	for _, v := range ids {
+		args = append(args, v)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			artifact_id,
+			case_id,
+			device_id,
+			artifact_type,
+			COALESCE(source_ref, ''),
+			snapshot_path,
+			sha256,
+			size_bytes,
+			collected_at,
+			COALESCE(collector_name, ''),
+			COALESCE(collector_version, ''),
+			COALESCE(parser_version, ''),
+			COALESCE(acquisition_method, ''),
+			COALESCE(mime_type, ''),
+			snapshot_compression,
+			COALESCE(exhibit_no, 0),
+			COALESCE(part_group, ''),
+			COALESCE(part_no, 0),
+			COALESCE(part_count, 0)
+		FROM artifacts
+		WHERE artifact_id IN (`+placeholders(len(ids))+`)
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query artifacts by id: %w", err)
+	}
+	defer rows.Close()
+
+	items, err := scanArtifactInfoRows(rows)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]model.ArtifactInfo, len(items))
+	for _, a := range items {
+		byID[a.ArtifactID] = a
+	}
+	out := make([]model.ArtifactInfo, 0, len(items))
+	for _, v := range ids {
+		if a, ok := byID[v]; ok {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// placeholders 返回 n 个以逗号分隔的 “?”。
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?,", n-1) + "?"
+}
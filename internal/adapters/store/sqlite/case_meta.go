@@ -0,0 +1,32 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CaseIDByCaseNo 返回使用该案件编号的案件（不存在时为空）。
+func (s *Store) CaseIDByCaseNo(ctx context.Context, caseNo string) (string, error) {
+	var caseID string
+	err := s.db.QueryRowContext(ctx, `SELECT case_id FROM cases WHERE case_no = ? LIMIT 1`, caseNo).Scan(&caseID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("query case by case_no: %w", err)
+	}
+	return caseID, nil
+}
+
+// UpdateCaseMeta 覆盖案件标题与编号（caseNo 为空时清除编号）。案件不存在时返回 false。
+func (s *Store) UpdateCaseMeta(ctx context.Context, caseID, title, caseNo string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE cases SET title = ?, case_no = ?, updated_at = ? WHERE case_id = ?`,
+		title, nullIfEmpty(caseNo), time.Now().Unix(), caseID)
+	if err != nil {
+		return false, fmt.Errorf("update case meta: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 案件侦查清单（见 036_case_checklist.sql）
+//
+// 清单项的生成、自动完成与结案校验由 services/checklist 负责，这里只做读写。
+
+const checklistColumns = `case_id, item_id, label, required, COALESCE(auto, ''), position, status,
+	COALESCE(responsible, ''), COALESCE(completed_by, ''), COALESCE(completed_at, 0), COALESCE(note, ''), created_at, updated_at`
+
+// SyncChecklistItems 按模板生成案件清单项；已存在的项只同步 label/required/auto/position，不改变完成状态。
+func (s *Store) SyncChecklistItems(ctx context.Context, caseID string, items []model.ChecklistItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin checklist tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	for _, it := range items {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO case_checklist(case_id, item_id, label, required, auto, position, status, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, 'pending', ?, ?)
+			ON CONFLICT(case_id, item_id) DO UPDATE SET
+				label = excluded.label,
+				required = excluded.required,
+				auto = excluded.auto,
+				position = excluded.position
+		`, caseID, it.ItemID, it.Label, boolToInt(it.Required), nullIfEmpty(it.Auto), it.Position, now, now); err != nil {
+			return fmt.Errorf("upsert checklist item %s: %w", it.ItemID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit checklist tx: %w", err)
+	}
+	return nil
+}
+
+// ListChecklistItems 返回案件清单项（按模板顺序）。
+func (s *Store) ListChecklistItems(ctx context.Context, caseID string) ([]model.ChecklistItem, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+checklistColumns+` FROM case_checklist WHERE case_id = ? ORDER BY position, item_id`, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("query checklist: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.ChecklistItem{}
+	for rows.Next() {
+		it, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *it)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate checklist: %w", err)
+	}
+	return out, nil
+}
+
+// UpdateChecklistItem 更新清单项的状态、负责人与说明；清单项不存在时返回 nil。
+// 状态变为 done/not_applicable 时记录完成人与时间，回到 pending 时清空。
+func (s *Store) UpdateChecklistItem(ctx context.Context, caseID, itemID, status, responsible, completedBy, note string) (*model.ChecklistItem, error) {
+	now := time.Now().Unix()
+	var completedAt any
+	if status != model.ChecklistPending {
+		completedAt = now
+	} else {
+		completedBy = ""
+	}
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE case_checklist
+		SET status = ?, responsible = ?, completed_by = ?, completed_at = ?, note = ?, updated_at = ?
+		WHERE case_id = ? AND item_id = ?
+	`, status, nullIfEmpty(responsible), nullIfEmpty(completedBy), completedAt, nullIfEmpty(note), now, caseID, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("update checklist item: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, nil
+	}
+	return scanChecklistItem(s.db.QueryRowContext(ctx, `SELECT `+checklistColumns+` FROM case_checklist WHERE case_id = ? AND item_id = ?`, caseID, itemID))
+}
+
+// SetCaseStatus 修改案件状态（open/closed/archived）；closed 时记录结案时间，archived 保留原结案时间。案件不存在时返回 false。
+func (s *Store) SetCaseStatus(ctx context.Context, caseID, status string) (bool, error) {
+	now := time.Now().Unix()
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE cases SET
+			status = ?,
+			closed_at = CASE ? WHEN 'closed' THEN ? WHEN 'archived' THEN COALESCE(closed_at, ?) ELSE NULL END,
+			updated_at = ?
+		WHERE case_id = ?
+	`, status, status, now, now, now, caseID)
+	if err != nil {
+		return false, fmt.Errorf("update case status: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanChecklistItem(row rowScanner) (*model.ChecklistItem, error) {
+	var it model.ChecklistItem
+	var required int
+	if err := row.Scan(&it.CaseID, &it.ItemID, &it.Label, &required, &it.Auto, &it.Position, &it.Status,
+		&it.Responsible, &it.CompletedBy, &it.CompletedAt, &it.Note, &it.CreatedAt, &it.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("scan checklist item: %w", err)
+	}
+	it.Required = required == 1
+	return &it, nil
+}
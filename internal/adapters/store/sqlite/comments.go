@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 评论（见 033_comments.sql）
+//
+// 评论只追加，不提供修改/删除；目标与回复关系由 services/comments 校验后写入。
+
+const commentColumns = `comment_id, case_id, target_type, target_id, COALESCE(parent_id, ''), author, body, created_at`
+
+// AddComment 写入一条评论并返回落库结果。
+func (s *Store) AddComment(ctx context.Context, c model.Comment) (*model.Comment, error) {
+	if c.CommentID == "" {
+		c.CommentID = id.New("cmt")
+	}
+	if c.CreatedAt == 0 {
+		c.CreatedAt = time.Now().Unix()
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO comments(comment_id, case_id, target_type, target_id, parent_id, author, body, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, c.CommentID, c.CaseID, c.TargetType, c.TargetID, nullIfEmpty(c.ParentID), c.Author, c.Text, c.CreatedAt); err != nil {
+		return nil, fmt.Errorf("insert comment: %w", err)
+	}
+	return &c, nil
+}
+
+// GetComment 返回案件内的一条评论；不存在时返回 nil。
+func (s *Store) GetComment(ctx context.Context, caseID, commentID string) (*model.Comment, error) {
+	var c model.Comment
+	err := s.db.QueryRowContext(ctx, `
+		SELECT `+commentColumns+`
+		FROM comments
+		WHERE case_id = ? AND comment_id = ?
+	`, caseID, commentID).Scan(&c.CommentID, &c.CaseID, &c.TargetType, &c.TargetID, &c.ParentID, &c.Author, &c.Text, &c.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query comment: %w", err)
+	}
+	return &c, nil
+}
+
+// ListComments 返回案件评论，按写入顺序排列；targetType/targetID 为空时不过滤。
+func (s *Store) ListComments(ctx context.Context, caseID, targetType, targetID string) ([]model.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments WHERE case_id = ?`
+	args := []any{caseID}
+	if targetType != "" {
+		query += ` AND target_type = ?`
+		args = append(args, targetType)
+	}
+	if targetID != "" {
+		query += ` AND target_id = ?`
+		args = append(args, targetID)
+	}
+	query += ` ORDER BY created_at, rowid`
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query comments: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.Comment{}
+	for rows.Next() {
+		var c model.Comment
+		if err := rows.Scan(&c.CommentID, &c.CaseID, &c.TargetType, &c.TargetID, &c.ParentID, &c.Author, &c.Text, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan comment: %w", err)
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate comments: %w", err)
+	}
+	return out, nil
+}
+
+// HitInCase 判断命中是否属于该案件。
+func (s *Store) HitInCase(ctx context.Context, caseID, hitID string) (bool, error) {
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM rule_hits WHERE case_id = ? AND hit_id = ?`, caseID, hitID).Scan(&n); err != nil {
+		return false, fmt.Errorf("query hit: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// UpdateDeviceCustody 覆盖设备的保管字段（空字符串清除），按 d.CaseID + d.DeviceID 定位。设备不存在时返回 false。
+func (s *Store) UpdateDeviceCustody(ctx context.Context, d model.CaseDevice) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE case_devices SET
+			physical_label = ?,
+			imei = ?,
+			seizure_location = ?,
+			custody_officer = ?,
+			custody_note = ?,
+			custody_updated_by = ?,
+			custody_updated_at = ?
+		WHERE case_id = ? AND device_id = ?
+	`, nullIfEmpty(d.PhysicalLabel), nullIfEmpty(d.IMEI), nullIfEmpty(d.SeizureLocation), nullIfEmpty(d.CustodyOfficer),
+		nullIfEmpty(d.CustodyNote), nullIfEmpty(d.CustodyUpdatedBy), d.CustodyUpdatedAt, d.CaseID, d.DeviceID)
+	if err != nil {
+		return false, fmt.Errorf("update device custody: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// SaveDevicePhoto 写入一条设备照片记录。
+func (s *Store) SaveDevicePhoto(ctx context.Context, p model.DevicePhoto) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO device_photos(
+			photo_id, case_id, device_id, original_name, file_path, sha256, size_bytes, mime_type,
+			caption, uploaded_by, uploaded_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, p.PhotoID, p.CaseID, p.DeviceID, p.OriginalName, p.FilePath, p.SHA256, p.SizeBytes, p.MimeType,
+		nullIfEmpty(p.Caption), nullIfEmpty(p.UploadedBy), p.UploadedAt); err != nil {
+		return fmt.Errorf("insert device photo: %w", err)
+	}
+	return nil
+}
+
+// ListDevicePhotos 返回案件的设备照片（deviceID 为空时返回全部设备），按上传时间正序。
+func (s *Store) ListDevicePhotos(ctx context.Context, caseID, deviceID string) ([]model.DevicePhoto, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT photo_id, case_id, device_id, original_name, file_path, sha256, size_bytes, mime_type,
+			COALESCE(caption, ''), COALESCE(uploaded_by, ''), uploaded_at
+		FROM device_photos
+		WHERE case_id = ? AND (? = '' OR device_id = ?)
+		ORDER BY uploaded_at, photo_id
+	`, caseID, deviceID, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("query device photos: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.DevicePhoto{}
+	for rows.Next() {
+		var item model.DevicePhoto
+		if err := rows.Scan(
+			&item.PhotoID,
+			&item.CaseID,
+			&item.DeviceID,
+			&item.OriginalName,
+			&item.FilePath,
+			&item.SHA256,
+			&item.SizeBytes,
+			&item.MimeType,
+			&item.Caption,
+			&item.UploadedBy,
+			&item.UploadedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan device photo: %w", err)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate device photos: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+)
+
+// 检材编号
+//
+// 法庭要求证据按编号引用（检材-001、检材-002 ...）。编号按案件独立递增，
+// 导出前为尚未编号的证据按 collected_at、artifact_id 顺序补编，已分配的编号不再变化。
+
+// ExhibitLabelPrefix 是检材编号标签前缀。
+const ExhibitLabelPrefix = "检材"
+
+// ExhibitLabel 返回检材编号标签（例如 检材-001）；n <= 0 表示未编号，返回空串。
+func ExhibitLabel(n int64) string {
+	if n <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s-%03d", ExhibitLabelPrefix, n)
+}
+
+// AssignExhibitNumbers 为案件内尚未编号的证据分配检材编号，返回本次新分配的数量。
+func (s *Store) AssignExhibitNumbers(ctx context.Context, caseID string) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin assign exhibit numbers: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var next int64
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(exhibit_no), 0) FROM artifacts WHERE case_id = ?`, caseID).Scan(&next); err != nil {
+		return 0, fmt.Errorf("query max exhibit no: %w", err)
+	}
+	rows, err := tx.QueryContext(ctx, `
+		SELECT artifact_id
+		FROM artifacts
+		WHERE case_id = ? AND exhibit_no IS NULL
+		ORDER BY collected_at ASC, artifact_id ASC
+	`, caseID)
+	if err != nil {
+		return 0, fmt.Errorf("query unnumbered artifacts: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("scan unnumbered artifact: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate unnumbered artifacts: %w", err)
+	}
+	for _, id := range ids {
+		next++
+		if _, err := tx.ExecContext(ctx, `UPDATE artifacts SET exhibit_no = ? WHERE artifact_id = ?`, next, id); err != nil {
+			return 0, fmt.Errorf("assign exhibit no: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit exhibit numbers: %w", err)
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestHandoverCase(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := NewStore(db)
+	caseA, _ := store.EnsureCase(ctx, "", "", "a", "alice", "")
+	if _, err := store.EnsureCase(ctx, "", "", "b", "alice", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// 建案人即初始负责人；再次 EnsureCase（例如扫描）不改变负责人。
+	a, err := store.HandoverCase(ctx, caseA, "bob", "night shift: mobile scan pending", "alice")
+	if err != nil || a == nil || a.FromOperator != "alice" || a.ToOperator != "bob" {
+		t.Fatalf("handover=%+v err=%v", a, err)
+	}
+	if _, err := store.EnsureCase(ctx, caseA, "", "", "alice", ""); err != nil {
+		t.Fatal(err)
+	}
+	if ov, _ := store.GetCaseOverview(ctx, caseA); ov.Owner != "bob" || ov.CreatedBy != "alice" {
+		t.Fatalf("overview=%+v", ov)
+	}
+
+	mine, _ := store.ListCases(ctx, "bob", 50, 0)
+	if len(mine) != 1 || mine[0].CaseID != caseA {
+		t.Fatalf("bob cases=%+v", mine)
+	}
+	if all, _ := store.ListCases(ctx, "", 50, 0); len(all) != 2 {
+		t.Fatalf("all cases=%d", len(all))
+	}
+
+	if _, err := store.HandoverCase(ctx, caseA, "carol", "back to day shift", "bob"); err != nil {
+		t.Fatal(err)
+	}
+	hist, _ := store.ListCaseAssignments(ctx, caseA, "", 0)
+	if len(hist) != 2 || hist[0].ToOperator != "carol" || hist[0].FromOperator != "bob" {
+		t.Fatalf("history=%+v", hist)
+	}
+	if log, _ := store.ListCaseAssignments(ctx, "", "bob", 0); len(log) != 2 {
+		t.Fatalf("bob shift log=%+v", log)
+	}
+	if a, err := store.HandoverCase(ctx, "case_missing", "bob", "x", "alice"); a != nil || err != nil {
+		t.Fatalf("missing case a=%+v err=%v", a, err)
+	}
+}
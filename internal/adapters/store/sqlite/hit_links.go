@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// ListHitArtifactLinks 返回命中或证据任一端属于该案件的关联记录（caseID 为空时返回全部），
+// 两端记录缺失时对应的 Exists 为 false（外键只在连接开启 foreign_keys 时生效，外部工具改库/旧库恢复后关联可能残留）。
+func (s *Store) ListHitArtifactLinks(ctx context.Context, caseID string) ([]model.HitArtifactLink, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			l.hit_id,
+			l.artifact_id,
+			h.hit_id IS NOT NULL,
+			COALESCE(h.case_id, ''),
+			a.artifact_id IS NOT NULL,
+			COALESCE(a.case_id, ''),
+			COALESCE(a.snapshot_path, '')
+		FROM hit_artifact_links l
+		LEFT JOIN rule_hits h ON h.hit_id = l.hit_id
+		LEFT JOIN artifacts a ON a.artifact_id = l.artifact_id
+		WHERE ? = '' OR h.case_id = ? OR a.case_id = ?
+		ORDER BY l.hit_id, l.artifact_id
+	`, caseID, caseID, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("query hit artifact links: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.HitArtifactLink{}
+	for rows.Next() {
+		var item model.HitArtifactLink
+		var hitExists, artifactExists int
+		if err := rows.Scan(&item.HitID, &item.ArtifactID, &hitExists, &item.HitCaseID, &artifactExists, &item.ArtifactCaseID, &item.SnapshotPath); err != nil {
+			return nil, fmt.Errorf("scan hit artifact link: %w", err)
+		}
+		item.HitExists = hitExists == 1
+		item.ArtifactExists = artifactExists == 1
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate hit artifact links: %w", err)
+	}
+	return out, nil
+}
+
+// ListUnlinkedHitIDs 返回案件中没有任何证据关联的命中。
+func (s *Store) ListUnlinkedHitIDs(ctx context.Context, caseID string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT h.hit_id
+		FROM rule_hits h
+		WHERE h.case_id = ?
+		  AND NOT EXISTS (SELECT 1 FROM hit_artifact_links l WHERE l.hit_id = h.hit_id)
+		ORDER BY h.hit_id
+	`, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("query unlinked hits: %w", err)
+	}
+	defer rows.Close()
+
+	out := []string{}
+	for rows.Next() {
+		var hitID string
+		if err := rows.Scan(&hitID); err != nil {
+			return nil, fmt.Errorf("scan unlinked hit: %w", err)
+		}
+		out = append(out, hitID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate unlinked hits: %w", err)
+	}
+	return out, nil
+}
+
+// CountCaseHits 返回案件命中条数。
+func (s *Store) CountCaseHits(ctx context.Context, caseID string) (int, error) {
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM rule_hits WHERE case_id = ?`, caseID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count case hits: %w", err)
+	}
+	return n, nil
+}
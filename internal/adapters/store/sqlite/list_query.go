@@ -0,0 +1,458 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+)
+
+// 列表查询：筛选 + 排序 + 游标分页
+//
+// 大案件的命中/证据/审计列表可达数万行，一次性返回会产生数 MB 的响应。这里统一采用 keyset 分页：
+// - ORDER BY 由“排序字段 + 主键”组成，保证顺序稳定
+// - 游标记录上一页最后一行的各排序键值（base64url(JSON)），下一页用
+//   (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... 的条件续读，不依赖 OFFSET，翻页成本不随页数增长
+// - 游标中带排序签名，与本次请求的排序不一致时视为参数错误
+
+const (
+	// defaultPageLimit 是带游标但未指定 limit 时的页大小。
+	defaultPageLimit = 200
+	// maxPageLimit 是单页最大条数（与审计日志原有上限一致）。
+	maxPageLimit = 5000
+)
+
+// sortKey 是 ORDER BY 中的一列。
+type sortKey struct {
+	expr string
+	desc bool
+}
+
+// keyset 描述一种排序方式（最后一列必须是主键，保证唯一）。
+type keyset []sortKey
+
+func (k keyset) signature() string {
+	parts := make([]string, len(k))
+	for i, c := range k {
+		dir := "asc"
+		if c.desc {
+			dir = "desc"
+		}
+		parts[i] = c.expr + " " + dir
+	}
+	return strings.Join(parts, ",")
+}
+
+func (k keyset) orderBy() string {
+	parts := make([]string, len(k))
+	for i, c := range k {
+		parts[i] = c.expr + " ASC"
+		if c.desc {
+			parts[i] = c.expr + " DESC"
+		}
+	}
+	return "ORDER BY " + strings.Join(parts, ", ")
+}
+
+// after 返回“排在游标之后”的 WHERE 条件及参数。
+func (k keyset) after(values []any) (string, []any) {
+	var ors []string
+	var args []any
+	for i := range k {
+		var ands []string
+		for j := 0; j < i; j++ {
+			ands = append(ands, k[j].expr+" = ?")
+			args = append(args, values[j])
+		}
+		op := " > ?"
+		if k[i].desc {
+			op = " < ?"
+		}
+		ands = append(ands, k[i].expr+op)
+		args = append(args, values[i])
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	return "(" + strings.Join(ors, " OR ") + ")", args
+}
+
+type cursorPayload struct {
+	Sort string `json:"s"`
+	Keys []any  `json:"k"`
+}
+
+func encodeCursor(k keyset, values []any) string {
+	raw, _ := json.Marshal(cursorPayload{Sort: k.signature(), Keys: values})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(k keyset, cursor string) ([]any, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(cursor))
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "invalid cursor")
+	}
+	var p cursorPayload
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "invalid cursor")
+	}
+	if p.Sort != k.signature() || len(p.Keys) != len(k) {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "cursor does not match the requested sort; restart from the first page")
+	}
+	return p.Keys, nil
+}
+
+// resolveSort 按 sort/order 参数选出排序方式：fields 为允许的排序字段 -> (列表达式, 默认是否降序)，
+// sort 为空时使用 def。主键列 idExpr 追加在末尾，方向与第一列一致。
+func resolveSort(p model.Page, fields map[string]sortKey, def keyset, idExpr string) (keyset, error) {
+	order := strings.ToLower(strings.TrimSpace(p.Order))
+	if order != "" && order != "asc" && order != "desc" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid order: %s (asc|desc)", p.Order))
+	}
+	name := strings.ToLower(strings.TrimSpace(p.Sort))
+	if name == "" {
+		if order == "" {
+			return def, nil
+		}
+		// 只指定方向时，整体翻转默认排序。
+		flipped := make(keyset, len(def))
+		for i, c := range def {
+			flipped[i] = sortKey{expr: c.expr, desc: !c.desc}
+		}
+		if (order == "desc") != def[0].desc {
+			return flipped, nil
+		}
+		return def, nil
+	}
+	col, ok := fields[name]
+	if !ok {
+		allowed := make([]string, 0, len(fields))
+		for f := range fields {
+			allowed = append(allowed, f)
+		}
+		sort.Strings(allowed)
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid sort: %s (%s)", p.Sort, strings.Join(allowed, "|")))
+	}
+	if order != "" {
+		col.desc = order == "desc"
+	}
+	return keyset{col, {expr: idExpr, desc: col.desc}}, nil
+}
+
+// pageLimit 规范化页大小：limit <= 0 时取 def（def 为 0 表示不分页），上限 maxPageLimit。
+func pageLimit(limit, def int) int {
+	if limit <= 0 {
+		limit = def
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+	return limit
+}
+
+// whereBuilder 拼接 AND 条件。
+type whereBuilder struct {
+	conds []string
+	args  []any
+}
+
+func (w *whereBuilder) add(cond string, args ...any) {
+	w.conds = append(w.conds, cond)
+	w.args = append(w.args, args...)
+}
+
+func (w *whereBuilder) sql() string {
+	return "WHERE " + strings.Join(w.conds, " AND ")
+}
+
+var hitSortFields = map[string]sortKey{
+	"confidence":    {expr: "h.confidence", desc: true},
+	"first_seen_at": {expr: "COALESCE(h.first_seen_at, 0)", desc: true},
+	"last_seen_at":  {expr: "COALESCE(h.last_seen_at, 0)", desc: true},
+	"hit_type":      {expr: "h.hit_type"},
+	"matched_value": {expr: "h.matched_value"},
+}
+
+// hitDefaultSort 与 ListCaseHitDetails 的顺序一致，末尾追加 hit_id 保证稳定。
+var hitDefaultSort = keyset{
+	{expr: "h.hit_type"},
+	{expr: "h.confidence", desc: true},
+	{expr: "COALESCE(h.last_seen_at, 0)", desc: true},
+	{expr: "h.hit_id"},
+}
+
+// QueryCaseHits 按筛选条件查询案件命中，返回本页结果与下一页游标（没有更多时为空）。
+//
+// Limit 为 0 且未带游标时返回全部（与 ListCaseHitDetails 一致），否则默认每页 200 条。
+func (s *Store) QueryCaseHits(ctx context.Context, caseID string, q model.HitQuery) ([]model.HitDetail, string, error) {
+	ks, err := resolveSort(q.Page, hitSortFields, hitDefaultSort, "h.hit_id")
+	if err != nil {
+		return nil, "", err
+	}
+	w := &whereBuilder{}
+	w.add("h.case_id = ?", caseID)
+	if v := strings.TrimSpace(q.HitType); v != "" {
+		w.add("h.hit_type = ?", v)
+	}
+	if v := strings.TrimSpace(q.Verdict); v != "" {
+		w.add("h.verdict = ?", v)
+	}
+	if v := strings.TrimSpace(q.DeviceID); v != "" {
+		w.add("h.device_id = ?", v)
+	}
+	if v := strings.TrimSpace(q.WalletCategory); v != "" {
+		w.add("h.hit_type IN (?, ?)", string(model.HitWalletInstalled), string(model.HitWalletExecuted))
+		category := "CASE WHEN json_valid(h.detail_json) THEN json_extract(h.detail_json, '$.wallet_category') END"
+		if v == model.WalletCategoryUncategorized {
+			w.add("COALESCE(" + category + ", '') = ''")
+		} else {
+			w.add(category+" = ?", v)
+		}
+	}
+	if q.MinConfidence > 0 {
+		w.add("h.confidence >= ?", q.MinConfidence)
+	}
+	if q.MaxConfidence > 0 {
+		w.add("h.confidence <= ?", q.MaxConfidence)
+	}
+	if q.Since > 0 {
+		w.add("COALESCE(NULLIF(h.last_seen_at, 0), h.first_seen_at, 0) >= ?", q.Since)
+	}
+	if q.Until > 0 {
+		w.add("COALESCE(h.first_seen_at, 0) <= ?", q.Until)
+	}
+	def := 0
+	if q.Cursor != "" {
+		def = defaultPageLimit
+		values, err := decodeCursor(ks, q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		cond, args := ks.after(values)
+		w.add(cond, args...)
+	}
+	limit := pageLimit(q.Limit, def)
+
+	query := `
+		SELECT
+			h.hit_id, h.case_id, h.device_id, h.hit_type, h.rule_id,
+			COALESCE(h.rule_name, ''), COALESCE(h.rule_version, ''), h.matched_value,
+			COALESCE(h.first_seen_at, 0), COALESCE(h.last_seen_at, 0),
+			h.confidence, h.verdict, COALESCE(h.detail_json, '{}'),
+			COALESCE(GROUP_CONCAT(l.artifact_id, ','), ''),
+			COALESCE(h.cluster_id, '')
+		FROM rule_hits h
+		LEFT JOIN hit_artifact_links l ON l.hit_id = h.hit_id
+		` + w.sql() + `
+		GROUP BY h.hit_id
+		` + ks.orderBy()
+	args := w.args
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit+1)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("query case hits: %w", err)
+	}
+	defer rows.Close()
+	out, err := scanHitDetailRows(rows)
+	if err != nil {
+		return nil, "", err
+	}
+	if limit <= 0 || len(out) <= limit {
+		return out, "", nil
+	}
+	out = out[:limit]
+	return out, encodeCursor(ks, hitSortValues(ks, out[len(out)-1])), nil
+}
+
+// hitSortValues 取出命中在各排序列上的值（顺序与 keyset 一致）。
+func hitSortValues(ks keyset, h model.HitDetail) []any {
+	values := make([]any, len(ks))
+	for i, c := range ks {
+		switch c.expr {
+		case "h.confidence":
+			values[i] = h.Confidence
+		case "COALESCE(h.first_seen_at, 0)":
+			values[i] = h.FirstSeenAt
+		case "COALESCE(h.last_seen_at, 0)":
+			values[i] = h.LastSeenAt
+		case "h.hit_type":
+			values[i] = h.HitType
+		case "h.matched_value":
+			values[i] = h.MatchedValue
+		case "h.hit_id":
+			values[i] = h.HitID
+		}
+	}
+	return values
+}
+
+var artifactSortFields = map[string]sortKey{
+	"collected_at":  {expr: "collected_at", desc: true},
+	"size_bytes":    {expr: "size_bytes", desc: true},
+	"artifact_type": {expr: "artifact_type"},
+}
+
+// artifactDefaultSort 与 ListArtifactsByCase 的顺序一致。
+var artifactDefaultSort = keyset{
+	{expr: "collected_at", desc: true},
+	{expr: "artifact_id", desc: true},
+}
+
+// QueryArtifacts 按筛选条件查询案件证据索引，返回本页结果与下一页游标。
+//
+// Limit 为 0 且未带游标时返回全部（与 ListArtifactsByCase 一致），否则默认每页 200 条。
+func (s *Store) QueryArtifacts(ctx context.Context, caseID string, q model.ArtifactQuery) ([]model.ArtifactInfo, string, error) {
+	ks, err := resolveSort(q.Page, artifactSortFields, artifactDefaultSort, "artifact_id")
+	if err != nil {
+		return nil, "", err
+	}
+	w := &whereBuilder{}
+	w.add("case_id = ?", caseID)
+	if v := strings.TrimSpace(q.ArtifactType); v != "" {
+		w.add("artifact_type = ?", v)
+	}
+	if v := strings.TrimSpace(q.DeviceID); v != "" {
+		w.add("device_id = ?", v)
+	}
+	if q.Since > 0 {
+		w.add("collected_at >= ?", q.Since)
+	}
+	if q.Until > 0 {
+		w.add("collected_at <= ?", q.Until)
+	}
+	def := 0
+	if q.Cursor != "" {
+		def = defaultPageLimit
+		values, err := decodeCursor(ks, q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		cond, args := ks.after(values)
+		w.add(cond, args...)
+	}
+	limit := pageLimit(q.Limit, def)
+
+	query := `
+		SELECT
+			artifact_id, case_id, device_id, artifact_type, COALESCE(source_ref, ''),
+			snapshot_path, sha256, size_bytes, collected_at,
+			COALESCE(collector_name, ''), COALESCE(collector_version, ''), COALESCE(parser_version, ''), COALESCE(acquisition_method, ''),
+			COALESCE(mime_type, ''), snapshot_compression, COALESCE(exhibit_no, 0),
+			COALESCE(part_group, ''), COALESCE(part_no, 0), COALESCE(part_count, 0)
+		FROM artifacts
+		` + w.sql() + `
+		` + ks.orderBy()
+	args := w.args
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit+1)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("query artifacts: %w", err)
+	}
+	defer rows.Close()
+	out, err := scanArtifactInfoRows(rows)
+	if err != nil {
+		return nil, "", err
+	}
+	if limit <= 0 || len(out) <= limit {
+		return out, "", nil
+	}
+	out = out[:limit]
+	last := out[len(out)-1]
+	values := make([]any, len(ks))
+	for i, c := range ks {
+		switch c.expr {
+		case "collected_at":
+			values[i] = last.CollectedAt
+		case "size_bytes":
+			values[i] = last.SizeBytes
+		case "artifact_type":
+			values[i] = last.ArtifactType
+		case "artifact_id":
+			values[i] = last.ArtifactID
+		}
+	}
+	return out, encodeCursor(ks, values), nil
+}
+
+// auditDefaultSort 与 ListAuditLogs 的顺序一致（按发生时间正序，便于对照审计链）。
+var auditDefaultSort = keyset{
+	{expr: "occurred_at"},
+	{expr: "event_id"},
+}
+
+var auditSortFields = map[string]sortKey{
+	"occurred_at": {expr: "occurred_at"},
+}
+
+// QueryAuditLogs 按筛选条件查询案件审计日志，返回本页结果与下一页游标。
+//
+// 与 ListAuditLogs 一致：Limit 默认 500，上限 5000；还有更多记录时返回游标（原接口会静默截断）。
+func (s *Store) QueryAuditLogs(ctx context.Context, caseID string, q model.AuditQuery) ([]model.AuditLog, string, error) {
+	ks, err := resolveSort(q.Page, auditSortFields, auditDefaultSort, "event_id")
+	if err != nil {
+		return nil, "", err
+	}
+	w := &whereBuilder{}
+	w.add("case_id = ?", caseID)
+	if v := strings.TrimSpace(q.EventType); v != "" {
+		w.add("event_type = ?", v)
+	}
+	if v := strings.TrimSpace(q.Status); v != "" {
+		w.add("status = ?", v)
+	}
+	if v := strings.TrimSpace(q.Actor); v != "" {
+		w.add("actor = ?", v)
+	}
+	if v := strings.TrimSpace(q.DeviceID); v != "" {
+		w.add("device_id = ?", v)
+	}
+	if q.Since > 0 {
+		w.add("occurred_at >= ?", q.Since)
+	}
+	if q.Until > 0 {
+		w.add("occurred_at <= ?", q.Until)
+	}
+	if q.Cursor != "" {
+		values, err := decodeCursor(ks, q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		cond, args := ks.after(values)
+		w.add(cond, args...)
+	}
+	limit := pageLimit(q.Limit, 500)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			rowid, event_id, case_id, COALESCE(device_id, ''), event_type, action, status,
+			COALESCE(actor, ''), COALESCE(source, ''), COALESCE(detail_json, '{}'), occurred_at,
+			COALESCE(chain_prev_hash, ''), chain_hash
+		FROM audit_logs
+		`+w.sql()+`
+		`+ks.orderBy()+`
+		LIMIT ?
+	`, append(w.args, limit+1)...)
+	if err != nil {
+		return nil, "", fmt.Errorf("query audit logs: %w", err)
+	}
+	defer rows.Close()
+	out, err := scanAuditLogRows(rows)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(out) <= limit {
+		return out, "", nil
+	}
+	out = out[:limit]
+	last := out[len(out)-1]
+	return out, encodeCursor(ks, []any{last.OccurredAt, last.EventID}), nil
+}
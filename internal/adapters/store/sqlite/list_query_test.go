@@ -0,0 +1,167 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestQueryCaseHitsPagination(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "d", OS: model.OSWindows, Identifier: "id-1"}, true, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	var hits []model.RuleHit
+	for i := 0; i < 7; i++ {
+		verdict := "confirmed"
+		if i%2 == 1 {
+			verdict = "suspected"
+		}
+		hits = append(hits, model.RuleHit{
+			ID: fmt.Sprintf("hit_%d", i), CaseID: caseID, DeviceID: "dev_1", Type: model.HitExchangeVisited,
+			RuleID: "okx", RuleName: "OKX", RuleVersion: "1", MatchedValue: fmt.Sprintf("d%d.okx.com", i),
+			// 两两相同的置信度，验证游标在并列值上的续读。
+			Confidence: 0.5 + float64(i/2)/10, Verdict: verdict,
+			FirstSeenAt: int64(1000 + i), LastSeenAt: int64(2000 + i), DetailJSON: []byte(`{}`),
+		})
+	}
+	if err := store.SaveRuleHits(ctx, hits); err != nil {
+		t.Fatal(err)
+	}
+
+	// 全量（不分页）与 ListCaseHitDetails 一致。
+	all, next, err := store.QueryCaseHits(ctx, caseID, model.HitQuery{})
+	if err != nil || next != "" || len(all) != 7 {
+		t.Fatalf("all=%d next=%q err=%v", len(all), next, err)
+	}
+
+	for _, sort := range []string{"", "confidence", "first_seen_at", "matched_value"} {
+		var got []string
+		q := model.HitQuery{Page: model.Page{Limit: 3, Sort: sort}}
+		for page := 0; page < 5; page++ {
+			rows, cursor, err := store.QueryCaseHits(ctx, caseID, q)
+			if err != nil {
+				t.Fatalf("sort=%q page %d: %v", sort, page, err)
+			}
+			for _, r := range rows {
+				got = append(got, r.HitID)
+			}
+			if cursor == "" {
+				break
+			}
+			q.Cursor = cursor
+		}
+		seen := map[string]bool{}
+		for _, id := range got {
+			if seen[id] {
+				t.Fatalf("sort=%q duplicate %s in %v", sort, id, got)
+			}
+			seen[id] = true
+		}
+		if len(got) != 7 {
+			t.Fatalf("sort=%q paged ids=%v", sort, got)
+		}
+	}
+
+	rows, _, err := store.QueryCaseHits(ctx, caseID, model.HitQuery{Verdict: "suspected", MinConfidence: 0.6, Since: 2004})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 || rows[0].HitID != "hit_5" {
+		t.Fatalf("filtered=%+v", rows)
+	}
+
+	_, cursor, err := store.QueryCaseHits(ctx, caseID, model.HitQuery{Page: model.Page{Limit: 2, Sort: "confidence"}})
+	if err != nil || cursor == "" {
+		t.Fatalf("cursor=%q err=%v", cursor, err)
+	}
+	_, _, err = store.QueryCaseHits(ctx, caseID, model.HitQuery{Page: model.Page{Cursor: cursor, Sort: "first_seen_at"}})
+	var ae *apperr.Error
+	if !errors.As(err, &ae) || ae.Code != apperr.CodeInvalidArgument {
+		t.Fatalf("mismatched cursor err=%v", err)
+	}
+	if _, _, err := store.QueryCaseHits(ctx, caseID, model.HitQuery{Page: model.Page{Sort: "bogus"}}); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("bad sort err=%v", err)
+	}
+}
+
+func TestQueryCaseHitsWalletCategory(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "d", OS: model.OSWindows, Identifier: "id-1"}, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	hit := func(id string, typ model.HitType, detail string) model.RuleHit {
+		return model.RuleHit{ID: id, CaseID: caseID, DeviceID: "dev_1", Type: typ, RuleID: id, RuleName: id, RuleVersion: "1",
+			MatchedValue: id, Confidence: 0.9, Verdict: "confirmed", DetailJSON: []byte(detail)}
+	}
+	if err := store.SaveRuleHits(ctx, []model.RuleHit{
+		hit("binance", model.HitWalletInstalled, `{"match_field":"app_keyword","wallet_category":"custodial"}`),
+		hit("ledger", model.HitWalletExecuted, `{"match_field":"app_execution","wallet_category":"hardware_companion"}`),
+		hit("legacy", model.HitWalletInstalled, `{"match_field":"app_keyword"}`),
+		hit("okx.com", model.HitExchangeVisited, `{"wallet_category":"custodial"}`),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string][]string{
+		model.WalletCategoryCustodial:         {"binance"},
+		model.WalletCategoryHardwareCompanion: {"ledger"},
+		model.WalletCategoryUncategorized:     {"legacy"},
+		model.WalletCategoryPrivacyCoin:       nil,
+	}
+	for category, want := range cases {
+		rows, _, err := store.QueryCaseHits(ctx, caseID, model.HitQuery{WalletCategory: category})
+		if err != nil {
+			t.Fatalf("%s: %v", category, err)
+		}
+		if len(rows) != len(want) {
+			t.Fatalf("%s: rows=%+v", category, rows)
+		}
+		for i, r := range rows {
+			if r.HitID != want[i] || r.WalletCategory != category {
+				t.Fatalf("%s: row=%+v", category, r)
+			}
+		}
+	}
+	all, _, err := store.QueryCaseHits(ctx, caseID, model.HitQuery{HitType: string(model.HitExchangeVisited)})
+	if err != nil || len(all) != 1 || all[0].WalletCategory != "" {
+		t.Fatalf("non-wallet hit should have no category: %+v err=%v", all, err)
+	}
+}
@@ -0,0 +1,69 @@
+-- 005_wallet_suspected_unknown_hit.sql
+--
+-- 目的：
+-- - rule_hits.hit_type 增加一个新枚举值：wallet_suspected_unknown（启发式识别的“疑似未知钱包”，低置信）
+-- - schema_version 升级到 4
+--
+-- 注意：
+-- - SQLite 无法直接修改 CHECK 约束的枚举列表，因此通过“重建表”方式完成升级。
+-- - 该迁移依赖 migrator 的“只执行一次”语义（schema_migrations），不要求可重复执行。
+
+PRAGMA foreign_keys = OFF;
+
+BEGIN TRANSACTION;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '4');
+
+CREATE TABLE rule_hits_new (
+  hit_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  device_id TEXT NOT NULL,
+  hit_type TEXT NOT NULL CHECK (
+    hit_type IN (
+      'wallet_installed',
+      'exchange_visited',
+      'wallet_address',
+      'token_balance',
+      'wallet_suspected_unknown'
+    )
+  ),
+  rule_id TEXT NOT NULL,
+  rule_name TEXT,
+  rule_bundle_id TEXT,
+  rule_version TEXT,
+  matched_value TEXT NOT NULL,
+  first_seen_at INTEGER,
+  last_seen_at INTEGER,
+  confidence REAL NOT NULL CHECK (confidence >= 0 AND confidence <= 1),
+  verdict TEXT NOT NULL DEFAULT 'suspected' CHECK (verdict IN ('confirmed', 'suspected', 'unsupported')),
+  detail_json TEXT,
+  created_at INTEGER NOT NULL,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE,
+  FOREIGN KEY (device_id) REFERENCES case_devices(device_id) ON DELETE CASCADE,
+  FOREIGN KEY (rule_bundle_id) REFERENCES rule_bundles(bundle_id) ON DELETE SET NULL
+);
+
+INSERT INTO rule_hits_new(
+  hit_id, case_id, device_id, hit_type, rule_id, rule_name, rule_bundle_id,
+  rule_version, matched_value, first_seen_at, last_seen_at, confidence,
+  verdict, detail_json, created_at
+)
+SELECT
+  hit_id, case_id, device_id, hit_type, rule_id, rule_name, rule_bundle_id,
+  rule_version, matched_value, first_seen_at, last_seen_at, confidence,
+  verdict, detail_json, created_at
+FROM rule_hits;
+
+DROP TABLE rule_hits;
+ALTER TABLE rule_hits_new RENAME TO rule_hits;
+
+-- 重建索引（与 001_init.sql 对齐）
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_id ON rule_hits(case_id);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_type ON rule_hits(case_id, hit_type);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_value ON rule_hits(case_id, matched_value);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_confidence ON rule_hits(confidence);
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
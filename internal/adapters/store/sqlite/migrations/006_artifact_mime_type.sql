@@ -0,0 +1,17 @@
+-- 006_artifact_mime_type.sql
+--
+-- 目的：
+-- - 历史版本写入 artifacts 时 mime_type 固定为 application/json（包括 zip 快照）
+-- - 按快照扩展名回填真实类型（新写入的证据由采集器/入库时探测）
+-- - schema_version 升级到 5
+
+BEGIN TRANSACTION;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '5');
+
+UPDATE artifacts
+SET mime_type = 'application/zip'
+WHERE lower(snapshot_path) LIKE '%.zip';
+
+COMMIT;
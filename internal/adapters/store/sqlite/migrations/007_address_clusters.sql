@@ -0,0 +1,34 @@
+-- 007_address_clusters.sql
+--
+-- 目的：
+-- - rule_hits 增加 cluster_id：钱包地址命中按共现关系聚类后的分组 ID
+-- - 新增 address_clusters：保存每个分组的成员、关联依据与时间范围（报告/UI 展示用）
+-- - schema_version 升级到 6
+--
+-- 说明：
+-- - 聚类结果可重复计算（按案件整体重算并覆盖），cluster_id 由成员地址确定性生成。
+
+BEGIN TRANSACTION;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '6');
+
+ALTER TABLE rule_hits ADD COLUMN cluster_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_cluster ON rule_hits(case_id, cluster_id);
+
+CREATE TABLE IF NOT EXISTS address_clusters (
+  cluster_id TEXT NOT NULL,
+  case_id TEXT NOT NULL,
+  size INTEGER NOT NULL,
+  score REAL NOT NULL,
+  addresses_json TEXT NOT NULL,
+  reasons_json TEXT,
+  first_seen_at INTEGER,
+  last_seen_at INTEGER,
+  created_at INTEGER NOT NULL,
+  PRIMARY KEY (case_id, cluster_id),
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE
+);
+
+COMMIT;
@@ -0,0 +1,54 @@
+-- 008_name_resolutions.sql
+--
+-- 目的：
+-- - 新增 name_resolutions：浏览痕迹中抽取到的 ENS(.eth) / SPACE ID(.bnb) 域名及链上解析结果
+-- - 新增 case_addresses：案件地址簿（抽取到的地址 + 域名解析得到的地址，按案件去重）
+-- - schema_version 升级到 7
+--
+-- 说明：
+-- - 解析结果记录固定的区块号/区块时间戳与 RPC 端点，便于事后复核（同一域名在不同时间可能指向不同地址）。
+-- - 同一域名每次扫描都追加一条记录（保留历史），地址簿只保留首次/末次出现时间。
+
+BEGIN TRANSACTION;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '7');
+
+CREATE TABLE IF NOT EXISTS name_resolutions (
+  resolution_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  device_id TEXT,
+  name TEXT NOT NULL,
+  name_service TEXT NOT NULL,         -- ens|space_id
+  namehash TEXT NOT NULL,
+  status TEXT NOT NULL,               -- resolved|unresolved|failed|skipped
+  resolved_address TEXT,
+  resolver TEXT,
+  registry TEXT,
+  block_number INTEGER,
+  block_timestamp INTEGER,
+  rpc_url TEXT,
+  error_message TEXT,
+  artifact_ids_json TEXT,
+  first_seen_at INTEGER,
+  last_seen_at INTEGER,
+  resolved_at INTEGER NOT NULL,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_name_resolutions_case_name ON name_resolutions(case_id, name, resolved_at);
+
+CREATE TABLE IF NOT EXISTS case_addresses (
+  case_id TEXT NOT NULL,
+  address TEXT NOT NULL,
+  chain TEXT,
+  source TEXT NOT NULL,               -- extracted|ens|space_id
+  label TEXT,                         -- 来源域名等
+  first_seen_at INTEGER NOT NULL,
+  last_seen_at INTEGER NOT NULL,
+  detail_json TEXT,
+  PRIMARY KEY (case_id, address),
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE
+);
+
+COMMIT;
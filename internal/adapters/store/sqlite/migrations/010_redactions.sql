@@ -0,0 +1,64 @@
+-- 010_redactions.sql
+--
+-- 目的：
+-- - 新增 redactions：分析人员标记的“对外披露时需遮盖”的证据记录/字段或命中（不修改原始证据）
+-- - reports.report_type 增加一个新枚举值：disclosure_zip（遮盖后的对外披露导出包）
+-- - schema_version 升级到 9
+--
+-- 注意：
+-- - reports 通过“重建表”方式修改 CHECK 约束（同 005/009）。
+-- - pointer 为 JSON Pointer（RFC 6901），空串表示整条证据/整条命中。
+
+PRAGMA foreign_keys = OFF;
+
+BEGIN TRANSACTION;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '9');
+
+CREATE TABLE IF NOT EXISTS redactions (
+  redaction_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  target_type TEXT NOT NULL CHECK (target_type IN ('artifact', 'hit')),
+  target_id TEXT NOT NULL,            -- artifact_id | hit_id
+  pointer TEXT NOT NULL DEFAULT '',   -- 证据快照 JSON / 命中 detail_json 内的位置
+  reason TEXT,
+  operator TEXT,
+  created_at INTEGER NOT NULL,
+  UNIQUE (case_id, target_type, target_id, pointer),
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_redactions_case_target ON redactions(case_id, target_type, target_id);
+
+CREATE TABLE reports_new (
+  report_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  report_type TEXT NOT NULL CHECK (
+    report_type IN ('internal_html', 'internal_json', 'forensic_pdf', 'forensic_zip', 'disclosure_zip')
+  ),
+  file_path TEXT NOT NULL,
+  sha256 TEXT NOT NULL CHECK (length(sha256) = 64),
+  generated_at INTEGER NOT NULL,
+  generator_version TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'ready' CHECK (status IN ('ready', 'failed')),
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE
+);
+
+INSERT INTO reports_new(
+  report_id, case_id, report_type, file_path, sha256, generated_at, generator_version, status
+)
+SELECT
+  report_id, case_id, report_type, file_path, sha256, generated_at, generator_version, status
+FROM reports;
+
+DROP TABLE reports;
+ALTER TABLE reports_new RENAME TO reports;
+
+-- 重建索引（与 001 对齐）
+CREATE INDEX IF NOT EXISTS idx_reports_case_type ON reports(case_id, report_type);
+CREATE INDEX IF NOT EXISTS idx_reports_generated_at ON reports(generated_at);
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,133 @@
+-- 011_manual_hits.sql
+--
+-- 目的：
+-- - artifacts.artifact_type 增加 manual_evidence（人工发现证据的附件，例如纸质助记词照片）
+-- - rule_hits.hit_type 增加 manual_finding（无法归入既有类型的人工发现，例如助记词）
+-- - schema_version 升级到 10
+--
+-- 注意：
+-- - 与 004/009 相同，通过“重建表”方式修改 CHECK 约束；rule_hits 需保留 cluster_id 列与索引。
+-- - 该迁移依赖 migrator 的“只执行一次”语义（schema_migrations），不要求可重复执行。
+
+PRAGMA foreign_keys = OFF;
+
+BEGIN TRANSACTION;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '10');
+
+CREATE TABLE artifacts_new (
+  artifact_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  device_id TEXT NOT NULL,
+  artifact_type TEXT NOT NULL CHECK (
+    artifact_type IN (
+      'installed_apps',
+      'browser_history',
+      'browser_extension',
+      'browser_history_db',
+      'mobile_packages',
+      'mobile_backup',
+      'chain_balance',
+      'manual_evidence'
+    )
+  ),
+  source_ref TEXT,
+  snapshot_path TEXT NOT NULL,
+  sha256 TEXT NOT NULL CHECK (length(sha256) = 64),
+  sha256_algo TEXT NOT NULL DEFAULT 'sha256',
+  size_bytes INTEGER NOT NULL CHECK (size_bytes >= 0),
+  mime_type TEXT,
+  collected_at INTEGER NOT NULL,
+  collector_name TEXT NOT NULL,
+  collector_version TEXT NOT NULL,
+  parser_version TEXT,
+  acquisition_method TEXT,
+  payload_json TEXT,
+  is_encrypted INTEGER NOT NULL DEFAULT 0 CHECK (is_encrypted IN (0, 1)),
+  encryption_note TEXT,
+  record_hash TEXT NOT NULL CHECK (length(record_hash) = 64),
+  created_at INTEGER NOT NULL,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE,
+  FOREIGN KEY (device_id) REFERENCES case_devices(device_id) ON DELETE CASCADE
+);
+
+INSERT INTO artifacts_new(
+  artifact_id, case_id, device_id, artifact_type, source_ref, snapshot_path,
+  sha256, sha256_algo, size_bytes, mime_type, collected_at, collector_name,
+  collector_version, parser_version, acquisition_method, payload_json,
+  is_encrypted, encryption_note, record_hash, created_at
+)
+SELECT
+  artifact_id, case_id, device_id, artifact_type, source_ref, snapshot_path,
+  sha256, sha256_algo, size_bytes, mime_type, collected_at, collector_name,
+  collector_version, parser_version, acquisition_method, payload_json,
+  is_encrypted, encryption_note, record_hash, created_at
+FROM artifacts;
+
+DROP TABLE artifacts;
+ALTER TABLE artifacts_new RENAME TO artifacts;
+
+-- 重建 artifacts 索引（与 001_init.sql 对齐）
+CREATE INDEX IF NOT EXISTS idx_artifacts_case_id ON artifacts(case_id);
+CREATE INDEX IF NOT EXISTS idx_artifacts_device_id ON artifacts(device_id);
+CREATE INDEX IF NOT EXISTS idx_artifacts_case_type ON artifacts(case_id, artifact_type);
+CREATE INDEX IF NOT EXISTS idx_artifacts_collected_at ON artifacts(collected_at);
+CREATE INDEX IF NOT EXISTS idx_artifacts_sha256 ON artifacts(sha256);
+
+CREATE TABLE rule_hits_new (
+  hit_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  device_id TEXT NOT NULL,
+  hit_type TEXT NOT NULL CHECK (
+    hit_type IN (
+      'wallet_installed',
+      'exchange_visited',
+      'wallet_address',
+      'token_balance',
+      'wallet_suspected_unknown',
+      'nft_holdings',
+      'manual_finding'
+    )
+  ),
+  rule_id TEXT NOT NULL,
+  rule_name TEXT,
+  rule_bundle_id TEXT,
+  rule_version TEXT,
+  matched_value TEXT NOT NULL,
+  first_seen_at INTEGER,
+  last_seen_at INTEGER,
+  confidence REAL NOT NULL CHECK (confidence >= 0 AND confidence <= 1),
+  verdict TEXT NOT NULL DEFAULT 'suspected' CHECK (verdict IN ('confirmed', 'suspected', 'unsupported')),
+  detail_json TEXT,
+  created_at INTEGER NOT NULL,
+  cluster_id TEXT,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE,
+  FOREIGN KEY (device_id) REFERENCES case_devices(device_id) ON DELETE CASCADE,
+  FOREIGN KEY (rule_bundle_id) REFERENCES rule_bundles(bundle_id) ON DELETE SET NULL
+);
+
+INSERT INTO rule_hits_new(
+  hit_id, case_id, device_id, hit_type, rule_id, rule_name, rule_bundle_id,
+  rule_version, matched_value, first_seen_at, last_seen_at, confidence,
+  verdict, detail_json, created_at, cluster_id
+)
+SELECT
+  hit_id, case_id, device_id, hit_type, rule_id, rule_name, rule_bundle_id,
+  rule_version, matched_value, first_seen_at, last_seen_at, confidence,
+  verdict, detail_json, created_at, cluster_id
+FROM rule_hits;
+
+DROP TABLE rule_hits;
+ALTER TABLE rule_hits_new RENAME TO rule_hits;
+
+-- 重建 rule_hits 索引（与 001/007 对齐）
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_id ON rule_hits(case_id);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_type ON rule_hits(case_id, hit_type);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_value ON rule_hits(case_id, matched_value);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_confidence ON rule_hits(confidence);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_cluster ON rule_hits(case_id, cluster_id);
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,19 @@
+-- 015_payload_offload.sql
+--
+-- 目的：
+-- - artifacts 增加 payload_storage / payload_bytes：大体积 payload 可以只在库中保留摘要，
+--   原文从证据快照文件按需读取（仅当 payload 与快照文件逐字节一致时才允许，读取时复核 sha256）
+-- - schema_meta 增加 payload_inline_max_bytes（0 表示不启用，保持全部内联）
+-- - schema_version 升级到 14
+--
+-- 注意：
+-- - ADD COLUMN 不需要重建表；存量数据默认 inline，瘦身由 `inspector-cli migrate --offload-payloads-over N` 完成。
+
+ALTER TABLE artifacts ADD COLUMN payload_storage TEXT NOT NULL DEFAULT 'inline' CHECK (payload_storage IN ('inline', 'snapshot'));
+ALTER TABLE artifacts ADD COLUMN payload_bytes INTEGER;
+
+INSERT OR IGNORE INTO schema_meta (key, value) VALUES
+  ('payload_inline_max_bytes', '0');
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '14');
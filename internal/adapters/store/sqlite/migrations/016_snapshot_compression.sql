@@ -0,0 +1,12 @@
+-- 016_snapshot_compression.sql
+--
+-- 目的：
+-- - artifacts 增加 snapshot_compression：JSON 证据快照可按 gzip 压缩存储（*.json.gz）
+--   sha256 / size_bytes 始终针对磁盘上实际存储的字节，读取方按文件头透明解压
+-- - 历史快照保持 none
+-- - schema_version 升级到 15
+--
+
+ALTER TABLE artifacts ADD COLUMN snapshot_compression TEXT NOT NULL DEFAULT 'none' CHECK (snapshot_compression IN ('none', 'gzip'));
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '15');
@@ -0,0 +1,13 @@
+-- 017_case_storage_quota.sql
+--
+-- 目的：
+-- - cases 增加 storage_quota_bytes：案件级存储配额（NULL 表示使用全局默认）
+-- - schema_meta 增加全局默认配额 case_quota_bytes（0 表示不限制）与超额策略 case_quota_mode（warn|block）
+-- - schema_version 升级到 16
+--
+
+ALTER TABLE cases ADD COLUMN storage_quota_bytes INTEGER CHECK (storage_quota_bytes IS NULL OR storage_quota_bytes >= 0);
+
+INSERT OR IGNORE INTO schema_meta (key, value) VALUES ('case_quota_bytes', '0');
+INSERT OR IGNORE INTO schema_meta (key, value) VALUES ('case_quota_mode', 'warn');
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '16');
@@ -0,0 +1,27 @@
+-- 018_case_attachments.sql
+--
+-- 目的：
+-- - 新增 case_attachments：案件级附件（当前仅 authorization_document：扫描版执法授权文书/工单 PDF）
+-- - 附件原样落盘，库内记录 sha256 与上传时登记的工单号/签发单位，前置检查据此留痕
+-- - schema_version 升级到 17
+--
+
+CREATE TABLE IF NOT EXISTS case_attachments (
+  attachment_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  kind TEXT NOT NULL CHECK (kind IN ('authorization_document')),
+  original_name TEXT NOT NULL,
+  file_path TEXT NOT NULL,
+  sha256 TEXT NOT NULL CHECK (length(sha256) = 64),
+  size_bytes INTEGER NOT NULL CHECK (size_bytes >= 0),
+  mime_type TEXT NOT NULL,
+  authorization_order TEXT,
+  agency TEXT,
+  uploaded_by TEXT,
+  uploaded_at INTEGER NOT NULL,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_case_attachments_case_kind ON case_attachments(case_id, kind, uploaded_at);
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '17');
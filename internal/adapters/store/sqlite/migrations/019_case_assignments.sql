@@ -0,0 +1,30 @@
+-- 019_case_assignments.sql
+--
+-- 目的：
+-- - cases 增加 owner：当前负责该案件的操作员（历史数据以 created_by 回填）
+-- - 新增 case_assignments：案件交接记录（交出人、接收人、交接说明），即操作员的交接班日志
+-- - schema_version 升级到 18
+--
+
+ALTER TABLE cases ADD COLUMN owner TEXT;
+
+UPDATE cases SET owner = created_by WHERE owner IS NULL AND created_by IS NOT NULL AND created_by <> '';
+
+CREATE INDEX IF NOT EXISTS idx_cases_owner ON cases(owner, updated_at);
+
+CREATE TABLE IF NOT EXISTS case_assignments (
+  assignment_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  from_operator TEXT,
+  to_operator TEXT NOT NULL,
+  note TEXT,
+  assigned_by TEXT,
+  assigned_at INTEGER NOT NULL,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_case_assignments_case ON case_assignments(case_id, assigned_at);
+CREATE INDEX IF NOT EXISTS idx_case_assignments_to ON case_assignments(to_operator, assigned_at);
+CREATE INDEX IF NOT EXISTS idx_case_assignments_from ON case_assignments(from_operator, assigned_at);
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '18');
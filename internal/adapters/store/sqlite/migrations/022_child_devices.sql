@@ -0,0 +1,13 @@
+-- 022_child_devices.sql
+--
+-- 目的：
+-- - case_devices 增加 parent_device_id：从宿主机发现的 VM 磁盘镜像做二次扫描时，
+--   镜像内的系统作为宿主机的“子设备”入库（证据、命中仍按子设备归属）
+-- - schema_version 升级到 21
+--
+
+ALTER TABLE case_devices ADD COLUMN parent_device_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_case_devices_parent ON case_devices(parent_device_id);
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '21');
@@ -0,0 +1,94 @@
+-- 025_regex_rules.sql
+--
+-- 目的：
+-- - rule_bundles.bundle_type 增加 regex_rules（可选的自定义正则规则文件，留痕版本与哈希）
+-- - rule_hits.hit_type 增加 regex_match（自定义正则规则命中）
+-- - schema_version 升级到 24
+--
+-- 注意：
+-- - SQLite 无法直接修改 CHECK 约束，rule_bundles 与 rule_hits 均通过“重建表”方式修改（与 024 相同，保留 cluster_id 列与索引）。
+-- - 该迁移依赖 migrator 的“只执行一次”语义（schema_migrations），不要求可重复执行。
+
+PRAGMA foreign_keys = OFF;
+
+BEGIN TRANSACTION;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '24');
+
+CREATE TABLE rule_bundles_new (
+  bundle_id TEXT PRIMARY KEY,
+  bundle_type TEXT NOT NULL CHECK (bundle_type IN ('wallet_signatures', 'exchange_domains', 'regex_rules')),
+  bundle_version TEXT NOT NULL,
+  sha256 TEXT NOT NULL CHECK (length(sha256) = 64),
+  source TEXT,
+  loaded_at INTEGER NOT NULL
+);
+
+INSERT INTO rule_bundles_new(bundle_id, bundle_type, bundle_version, sha256, source, loaded_at)
+SELECT bundle_id, bundle_type, bundle_version, sha256, source, loaded_at
+FROM rule_bundles;
+
+DROP TABLE rule_bundles;
+ALTER TABLE rule_bundles_new RENAME TO rule_bundles;
+
+CREATE INDEX IF NOT EXISTS idx_rule_bundles_type_version ON rule_bundles(bundle_type, bundle_version);
+
+CREATE TABLE rule_hits_new (
+  hit_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  device_id TEXT NOT NULL,
+  hit_type TEXT NOT NULL CHECK (
+    hit_type IN (
+      'wallet_installed',
+      'exchange_visited',
+      'wallet_address',
+      'token_balance',
+      'wallet_suspected_unknown',
+      'nft_holdings',
+      'manual_finding',
+      'watchlist_match',
+      'regex_match'
+    )
+  ),
+  rule_id TEXT NOT NULL,
+  rule_name TEXT,
+  rule_bundle_id TEXT,
+  rule_version TEXT,
+  matched_value TEXT NOT NULL,
+  first_seen_at INTEGER,
+  last_seen_at INTEGER,
+  confidence REAL NOT NULL CHECK (confidence >= 0 AND confidence <= 1),
+  verdict TEXT NOT NULL DEFAULT 'suspected' CHECK (verdict IN ('confirmed', 'suspected', 'unsupported')),
+  detail_json TEXT,
+  created_at INTEGER NOT NULL,
+  cluster_id TEXT,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE,
+  FOREIGN KEY (device_id) REFERENCES case_devices(device_id) ON DELETE CASCADE,
+  FOREIGN KEY (rule_bundle_id) REFERENCES rule_bundles(bundle_id) ON DELETE SET NULL
+);
+
+INSERT INTO rule_hits_new(
+  hit_id, case_id, device_id, hit_type, rule_id, rule_name, rule_bundle_id,
+  rule_version, matched_value, first_seen_at, last_seen_at, confidence,
+  verdict, detail_json, created_at, cluster_id
+)
+SELECT
+  hit_id, case_id, device_id, hit_type, rule_id, rule_name, rule_bundle_id,
+  rule_version, matched_value, first_seen_at, last_seen_at, confidence,
+  verdict, detail_json, created_at, cluster_id
+FROM rule_hits;
+
+DROP TABLE rule_hits;
+ALTER TABLE rule_hits_new RENAME TO rule_hits;
+
+-- 重建 rule_hits 索引（与 001/007 对齐）
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_id ON rule_hits(case_id);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_type ON rule_hits(case_id, hit_type);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_value ON rule_hits(case_id, matched_value);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_confidence ON rule_hits(confidence);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_case_cluster ON rule_hits(case_id, cluster_id);
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,47 @@
+-- 027_graph_export.sql
+--
+-- 目的：
+-- - reports.report_type 增加 graph_export（案件实体关系图导出包：GraphML + Neo4j 导入 CSV）
+-- - schema_version 升级到 26
+--
+-- 注意：
+-- - reports 通过“重建表”方式修改 CHECK 约束（同 010）。
+
+PRAGMA foreign_keys = OFF;
+
+BEGIN TRANSACTION;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '26');
+
+CREATE TABLE reports_new (
+  report_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  report_type TEXT NOT NULL CHECK (
+    report_type IN ('internal_html', 'internal_json', 'forensic_pdf', 'forensic_zip', 'disclosure_zip', 'graph_export')
+  ),
+  file_path TEXT NOT NULL,
+  sha256 TEXT NOT NULL CHECK (length(sha256) = 64),
+  generated_at INTEGER NOT NULL,
+  generator_version TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'ready' CHECK (status IN ('ready', 'failed')),
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE
+);
+
+INSERT INTO reports_new(
+  report_id, case_id, report_type, file_path, sha256, generated_at, generator_version, status
+)
+SELECT
+  report_id, case_id, report_type, file_path, sha256, generated_at, generator_version, status
+FROM reports;
+
+DROP TABLE reports;
+ALTER TABLE reports_new RENAME TO reports;
+
+-- 重建索引（与 001 对齐）
+CREATE INDEX IF NOT EXISTS idx_reports_case_type ON reports(case_id, report_type);
+CREATE INDEX IF NOT EXISTS idx_reports_generated_at ON reports(generated_at);
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
@@ -0,0 +1,28 @@
+-- 031_org_profile.sql
+--
+-- 目的：
+-- - 新增 org_profile：出具报告的单位信息（单位名称、部门、地址、徽标文件、联系方式）与报告编号前缀/流水号
+-- - reports 增加 report_no：对外交付的报告（取证 PDF / 导出包）按前缀 + 流水号编号
+-- - schema_version 升级到 30
+--
+-- 注意：
+-- - org_profile 只有一行（id = 1）；report_seq 只在签发编号时递增，不随配置修改回退。
+
+CREATE TABLE IF NOT EXISTS org_profile (
+  id INTEGER PRIMARY KEY CHECK (id = 1),
+  agency_name TEXT NOT NULL DEFAULT '',
+  unit TEXT NOT NULL DEFAULT '',
+  address TEXT NOT NULL DEFAULT '',
+  logo_path TEXT NOT NULL DEFAULT '',
+  contact TEXT NOT NULL DEFAULT '',
+  report_prefix TEXT NOT NULL DEFAULT '',
+  report_seq INTEGER NOT NULL DEFAULT 0 CHECK (report_seq >= 0),
+  updated_by TEXT,
+  updated_at INTEGER NOT NULL DEFAULT 0
+);
+
+ALTER TABLE reports ADD COLUMN report_no TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reports_report_no ON reports(report_no) WHERE report_no IS NOT NULL;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '30');
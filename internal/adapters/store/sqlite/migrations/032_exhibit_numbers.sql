@@ -0,0 +1,15 @@
+-- 032_exhibit_numbers.sql
+--
+-- 目的：
+-- - artifacts 增加 exhibit_no：案件内检材编号（检材-001、检材-002 ...），导出时为尚未编号的证据按采集时间顺序补编
+-- - schema_version 升级到 31
+--
+-- 注意：
+-- - 编号一经分配不再变化，多次导出的 manifest.json / hashes.sha256 / PDF / CSV 中同一证据编号一致。
+-- - 后续如需重建 artifacts 表（修改 CHECK 约束），必须保留 exhibit_no 列与 idx_artifacts_case_exhibit 索引。
+
+ALTER TABLE artifacts ADD COLUMN exhibit_no INTEGER CHECK (exhibit_no IS NULL OR exhibit_no > 0);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_artifacts_case_exhibit ON artifacts(case_id, exhibit_no) WHERE exhibit_no IS NOT NULL;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '31');
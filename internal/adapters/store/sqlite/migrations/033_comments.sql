@@ -0,0 +1,26 @@
+-- 033_comments.sql
+--
+-- 目的：
+-- - 新增 comments：分析人员对单条证据/命中的评论（作者、时间、正文），支持一层回复（parent_id 指向主题首条）
+-- - schema_version 升级到 32
+--
+-- 注意：
+-- - 评论只追加不修改/删除（复核讨论本身也是留痕的一部分），写入同时记审计。
+-- - 命中/证据不会被删除（重新扫描只追加），target_id 不设外键，由服务层校验目标属于该案件。
+
+CREATE TABLE IF NOT EXISTS comments (
+  comment_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  target_type TEXT NOT NULL CHECK (target_type IN ('artifact', 'hit')),
+  target_id TEXT NOT NULL,            -- artifact_id | hit_id
+  parent_id TEXT,                     -- 回复的主题首条评论；NULL 表示主题首条
+  author TEXT NOT NULL,
+  body TEXT NOT NULL CHECK (length(body) > 0),
+  created_at INTEGER NOT NULL,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE,
+  FOREIGN KEY (parent_id) REFERENCES comments(comment_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_comments_case_target ON comments(case_id, target_type, target_id, created_at);
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '32');
@@ -0,0 +1,30 @@
+-- 036_case_checklist.sql
+--
+-- 目的：
+-- - 新增 case_checklist：案件侦查清单（主机已扫描、手机已扫描、余额已查询、报告已签名等），记录完成状态与负责人
+-- - schema_version 升级到 35
+--
+-- 注意：
+-- - 清单项由生效的清单模板（schema_meta.case_checklist_yaml，未配置时为内置默认模板）在首次查看案件时生成；
+--   模板调整后同步 label/required/auto/position，已记录的完成状态不变。
+-- - required=1 的清单项在 external profile 下结案前必须为 done 或 not_applicable。
+
+CREATE TABLE IF NOT EXISTS case_checklist (
+  case_id TEXT NOT NULL,
+  item_id TEXT NOT NULL,
+  label TEXT NOT NULL,
+  required INTEGER NOT NULL DEFAULT 0 CHECK (required IN (0, 1)),
+  auto TEXT,                          -- 自动完成条件（host_scan|mobile_scan|balance_query|forensic_export）；NULL 表示只能手工勾选
+  position INTEGER NOT NULL DEFAULT 0,
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'done', 'not_applicable')),
+  responsible TEXT,                   -- 负责人
+  completed_by TEXT,
+  completed_at INTEGER,
+  note TEXT,
+  created_at INTEGER NOT NULL,
+  updated_at INTEGER NOT NULL,
+  PRIMARY KEY (case_id, item_id),
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE
+);
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '35');
@@ -0,0 +1,63 @@
+-- 037_harmonyos_device.sql
+--
+-- 目的：
+-- - case_devices.os_type 增加 harmonyos（HarmonyOS NEXT 设备，通过 hdc 采集）
+-- - case_devices.connection_type 增加 tcp（hdc 支持网络连接设备）
+-- - schema_version 升级到 36
+--
+-- 注意：
+-- - 与 013 相同，通过“重建表”方式修改 CHECK 约束；重建会丢失索引与触发器，需同步重建（含 022 的 parent_device_id）。
+-- - 该迁移依赖 migrator 的“只执行一次”语义（schema_migrations），不要求可重复执行。
+
+PRAGMA foreign_keys = OFF;
+
+BEGIN TRANSACTION;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '36');
+
+CREATE TABLE case_devices_new (
+  device_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  os_type TEXT NOT NULL CHECK (os_type IN ('windows', 'macos', 'android', 'ios', 'harmonyos')),
+  device_name TEXT,
+  identifier TEXT,
+  connection_type TEXT NOT NULL DEFAULT 'local' CHECK (connection_type IN ('local', 'usb', 'tcp', 'import')),
+  is_authorized INTEGER NOT NULL DEFAULT 0 CHECK (is_authorized IN (0, 1)),
+  auth_note TEXT,
+  first_seen_at INTEGER NOT NULL,
+  last_seen_at INTEGER NOT NULL,
+  created_at INTEGER NOT NULL,
+  updated_at INTEGER NOT NULL,
+  parent_device_id TEXT,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE
+);
+
+INSERT INTO case_devices_new(
+  device_id, case_id, os_type, device_name, identifier, connection_type,
+  is_authorized, auth_note, first_seen_at, last_seen_at, created_at, updated_at, parent_device_id
+)
+SELECT
+  device_id, case_id, os_type, device_name, identifier, connection_type,
+  is_authorized, auth_note, first_seen_at, last_seen_at, created_at, updated_at, parent_device_id
+FROM case_devices;
+
+DROP TABLE case_devices;
+ALTER TABLE case_devices_new RENAME TO case_devices;
+
+-- 重建 case_devices 索引与触发器（与 013/022 对齐）
+CREATE INDEX IF NOT EXISTS idx_case_devices_case_id ON case_devices(case_id);
+CREATE INDEX IF NOT EXISTS idx_case_devices_case_os ON case_devices(case_id, os_type);
+CREATE INDEX IF NOT EXISTS idx_case_devices_identifier ON case_devices(identifier);
+CREATE INDEX IF NOT EXISTS idx_case_devices_parent ON case_devices(parent_device_id);
+
+CREATE TRIGGER IF NOT EXISTS trg_case_devices_updated_at
+AFTER UPDATE ON case_devices
+FOR EACH ROW
+BEGIN
+  UPDATE case_devices SET updated_at = strftime('%s','now') WHERE device_id = OLD.device_id;
+END;
+
+COMMIT;
+
+PRAGMA foreign_keys = ON;
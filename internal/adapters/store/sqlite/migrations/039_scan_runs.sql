@@ -0,0 +1,28 @@
+-- 039_scan_runs.sql
+--
+-- 目的：
+-- - 新增 scan_runs：每次扫描的规范化参数快照（profile、隐私模式、压缩方式、深度采集开关、规则路径、预算等），
+--   取证 ZIP 的 manifest.json 原样收录，便于复现采集参数
+-- - schema_version 升级到 38
+--
+-- 注意：
+-- - options_json 由扫描服务按固定字段顺序生成（model.ScanOptions），options_sha256 为其 SHA-256；
+--   RPC 地址等可能含密钥的参数只记录是否启用。
+-- - mobile_scan 一次可采集多台设备，device_id 为空。
+
+CREATE TABLE IF NOT EXISTS scan_runs (
+  run_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  device_id TEXT,
+  scan_type TEXT NOT NULL,            -- host_scan|offline_scan|mobile_scan
+  operator TEXT,
+  options_json TEXT NOT NULL,
+  options_sha256 TEXT NOT NULL CHECK (length(options_sha256) = 64),
+  started_at INTEGER NOT NULL,
+  created_at INTEGER NOT NULL,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_scan_runs_case ON scan_runs(case_id, started_at);
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '38');
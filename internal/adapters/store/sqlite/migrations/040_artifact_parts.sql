@@ -0,0 +1,22 @@
+-- 040_artifact_parts.sql
+--
+-- 目的：
+-- - artifacts 增加 part_group / part_no / part_count：超过 payload 上限的 JSON 数组证据按元素拆成多个分片证据，
+--   每个分片有独立的快照文件与 sha256，part_group 为第 1 个分片的 artifact_id（未拆分的证据均为 NULL）
+-- - schema_meta 增加 artifact_payload_max_bytes（默认 64MiB，0 表示不拆分）
+-- - schema_version 升级到 39
+--
+-- 注意：
+-- - ADD COLUMN 不需要重建表；后续重建 artifacts 表的迁移需要保留这三列。
+
+ALTER TABLE artifacts ADD COLUMN part_group TEXT;
+ALTER TABLE artifacts ADD COLUMN part_no INTEGER;
+ALTER TABLE artifacts ADD COLUMN part_count INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_artifacts_part_group ON artifacts(part_group) WHERE part_group IS NOT NULL;
+
+INSERT OR IGNORE INTO schema_meta (key, value) VALUES
+  ('artifact_payload_max_bytes', '67108864');
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '39');
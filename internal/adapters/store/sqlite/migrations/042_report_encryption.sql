@@ -0,0 +1,12 @@
+-- 042_report_encryption.sql
+--
+-- 目的：
+-- - reports 增加 encryption：导出包的加密方式（zip_aes256 = 各条目 WinZip AES-256 加密），未加密为 NULL
+-- - schema_version 升级到 41
+--
+-- 注意：
+-- - 只记录加密方式，密码不落库。
+
+ALTER TABLE reports ADD COLUMN encryption TEXT;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '41');
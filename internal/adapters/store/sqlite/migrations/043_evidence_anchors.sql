@@ -0,0 +1,31 @@
+-- 043_evidence_anchors.sql
+--
+-- 目的：
+-- - 新增 evidence_anchors：导出包哈希在外部锚定服务（单位时间戳服务 RFC 3161 / 许可链存证端点）登记后的回执
+-- - schema_version 升级到 42
+--
+-- 注意：
+-- - 只向外部发送报告文件的 sha256，不发送案件/命中内容；回执原样保存（receipt），便于日后独立核验存在时间。
+-- - 锚定记录只追加不修改，同一报告可多次锚定（例如同时登记到两个服务）。
+
+CREATE TABLE IF NOT EXISTS evidence_anchors (
+  anchor_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  report_id TEXT NOT NULL,
+  sha256 TEXT NOT NULL CHECK (length(sha256) = 64),
+  adapter TEXT NOT NULL,             -- rest | rfc3161
+  endpoint TEXT NOT NULL,
+  receipt_id TEXT,                   -- 对方返回的凭证编号（交易哈希 / 时间戳序列号）
+  anchored_at INTEGER,               -- 对方声明的锚定时间（时间戳 genTime / 上链时间），未知为 NULL
+  receipt TEXT NOT NULL,             -- 回执原文（rfc3161 为 base64 DER 时间戳令牌，rest 为响应 JSON）
+  receipt_sha256 TEXT NOT NULL CHECK (length(receipt_sha256) = 64),
+  operator TEXT NOT NULL,
+  created_at INTEGER NOT NULL,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE,
+  FOREIGN KEY (report_id) REFERENCES reports(report_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_evidence_anchors_case ON evidence_anchors(case_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_evidence_anchors_report ON evidence_anchors(report_id);
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '42');
@@ -0,0 +1,35 @@
+-- 044_device_custody.sql
+--
+-- 目的：
+-- - case_devices 新增人工维护的保管字段：实物标签、IMEI、扣押地点、保管人、备注（扫描 upsert 不覆盖这些列）
+-- - 新增 device_photos：设备照片（原样落盘到证据目录，库内记录 sha256）
+-- - schema_version 升级到 43
+
+ALTER TABLE case_devices ADD COLUMN physical_label TEXT;
+ALTER TABLE case_devices ADD COLUMN imei TEXT;
+ALTER TABLE case_devices ADD COLUMN seizure_location TEXT;
+ALTER TABLE case_devices ADD COLUMN custody_officer TEXT;
+ALTER TABLE case_devices ADD COLUMN custody_note TEXT;
+ALTER TABLE case_devices ADD COLUMN custody_updated_by TEXT;
+ALTER TABLE case_devices ADD COLUMN custody_updated_at INTEGER;
+
+CREATE TABLE IF NOT EXISTS device_photos (
+  photo_id TEXT PRIMARY KEY,
+  case_id TEXT NOT NULL,
+  device_id TEXT NOT NULL,
+  original_name TEXT NOT NULL,
+  file_path TEXT NOT NULL,
+  sha256 TEXT NOT NULL CHECK (length(sha256) = 64),
+  size_bytes INTEGER NOT NULL,
+  mime_type TEXT NOT NULL,           -- image/png | image/jpeg
+  caption TEXT,
+  uploaded_by TEXT,
+  uploaded_at INTEGER NOT NULL,
+  FOREIGN KEY (case_id) REFERENCES cases(case_id) ON DELETE CASCADE,
+  FOREIGN KEY (device_id) REFERENCES case_devices(device_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_device_photos_device ON device_photos(device_id, uploaded_at);
+CREATE INDEX IF NOT EXISTS idx_device_photos_case ON device_photos(case_id);
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES ('schema_version', '43');
@@ -0,0 +1,16 @@
+-- 046_rule_hit_bundle_stats.sql
+--
+-- 目的：
+-- - 规则命中统计支持按规则包版本/加载时间分段（规则大版本更新后，趋势看板不再把新旧版本的命中混在一起）
+-- - schema_version 升级到 45
+--
+-- 注意：
+-- - 分段维度取自既有字段：rule_hits.rule_bundle_id -> rule_bundles(bundle_version, loaded_at)，
+--   规则包记录缺失（早期数据或非规则命中）时退回 rule_hits.rule_version，因此不需要回填数据。
+-- - 这里只补充统计查询使用的索引。
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '45');
+
+CREATE INDEX IF NOT EXISTS idx_rule_hits_rule_bundle ON rule_hits(hit_type, rule_id, rule_bundle_id, rule_version);
+CREATE INDEX IF NOT EXISTS idx_rule_hits_created_at ON rule_hits(created_at);
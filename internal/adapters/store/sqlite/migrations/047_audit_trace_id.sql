@@ -0,0 +1,28 @@
+-- 047_audit_trace_id.sql
+--
+-- 目的：
+-- - audit_logs 增加 trace_id 列并建索引：按 trace_id 排查（GET /api/audits?trace_id=）不再对 detail_json 全表 json_extract
+-- - schema_version 升级到 46
+--
+-- 注意：
+-- - trace_id 仍保留在 detail_json 中（参与 chain_hash 计算），新列只是它的索引副本，不参与哈希链。
+-- - 历史记录从 detail_json.trace_id 回填；audit_logs 为只追加表，回填期间临时移除 UPDATE 触发器后立即恢复。
+
+ALTER TABLE audit_logs ADD COLUMN trace_id TEXT;
+
+DROP TRIGGER IF EXISTS trg_audit_logs_prevent_update;
+
+UPDATE audit_logs
+SET trace_id = json_extract(detail_json, '$.trace_id')
+WHERE json_valid(detail_json) AND json_type(detail_json, '$.trace_id') = 'text';
+
+CREATE TRIGGER IF NOT EXISTS trg_audit_logs_prevent_update
+BEFORE UPDATE ON audit_logs
+BEGIN
+  SELECT RAISE(ABORT, 'audit_logs is append-only');
+END;
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_trace ON audit_logs(trace_id, occurred_at) WHERE trace_id IS NOT NULL;
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '46');
@@ -0,0 +1,10 @@
+-- 048_reports_generated_at_index.sql
+--
+-- 目的：
+-- - 补回 idx_reports_generated_at：010 / 027 重建 reports 表时漏建，已升级的库缺少该索引
+-- - schema_version 升级到 47
+
+CREATE INDEX IF NOT EXISTS idx_reports_generated_at ON reports(generated_at);
+
+INSERT OR REPLACE INTO schema_meta (key, value) VALUES
+  ('schema_version', '47');
@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// TestMigrationsKeepIndexes 确认全部迁移执行后，各迁移创建过的索引都还在（重建表的迁移容易漏建索引）。
+func TestMigrationsKeepIndexes(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	have := map[string]bool{}
+	rows, err := db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'index'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		have[name] = true
+	}
+	rows.Close()
+
+	entries, err := migrationFS.ReadDir("migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	createRe := regexp.MustCompile(`(?i)CREATE\s+(?:UNIQUE\s+)?INDEX\s+IF\s+NOT\s+EXISTS\s+(\w+)`)
+	dropRe := regexp.MustCompile(`(?i)DROP\s+INDEX\s+(?:IF\s+EXISTS\s+)?(\w+)`)
+	want := map[string]string{}
+	for _, e := range entries {
+		raw, err := migrationFS.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range dropRe.FindAllStringSubmatch(string(raw), -1) {
+			delete(want, m[1])
+		}
+		for _, m := range createRe.FindAllStringSubmatch(string(raw), -1) {
+			want[m[1]] = e.Name()
+		}
+	}
+	for name, file := range want {
+		if !have[name] {
+			t.Errorf("index %s (created in %s) missing after all migrations", name, file)
+		}
+	}
+}
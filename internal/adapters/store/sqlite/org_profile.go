@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 单位信息与报告编号
+//
+// org_profile 只有一行（id = 1）；未配置时返回零值。
+// 流水号在签发时用单条 UPDATE ... RETURNING 原子递增，并发导出不会拿到同一编号。
+
+// GetOrgProfile 返回单位信息（未配置时为零值）；Training 取自演练库标记。
+func (s *Store) GetOrgProfile(ctx context.Context) (model.OrgProfile, error) {
+	training, err := s.IsTrainingDB(ctx)
+	if err != nil {
+		return model.OrgProfile{}, err
+	}
+	p := model.OrgProfile{Training: training}
+	var updatedBy sql.NullString
+	err = s.db.QueryRowContext(ctx, `
+		SELECT agency_name, unit, address, logo_path, contact, report_prefix, report_seq, updated_by, updated_at
+		FROM org_profile
+		WHERE id = 1
+	`).Scan(&p.AgencyName, &p.Unit, &p.Address, &p.LogoPath, &p.Contact, &p.ReportPrefix, &p.ReportSeq, &updatedBy, &p.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return model.OrgProfile{Training: training}, nil
+		}
+		return p, fmt.Errorf("query org profile: %w", err)
+	}
+	p.UpdatedBy = updatedBy.String
+	return p, nil
+}
+
+// SaveOrgProfile 写入单位信息；report_seq 保持不变（流水号只随签发递增）。
+func (s *Store) SaveOrgProfile(ctx context.Context, p model.OrgProfile, operator string) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO org_profile(id, agency_name, unit, address, logo_path, contact, report_prefix, updated_by, updated_at)
+		VALUES(1, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			agency_name=excluded.agency_name,
+			unit=excluded.unit,
+			address=excluded.address,
+			logo_path=excluded.logo_path,
+			contact=excluded.contact,
+			report_prefix=excluded.report_prefix,
+			updated_by=excluded.updated_by,
+			updated_at=excluded.updated_at
+	`, p.AgencyName, p.Unit, p.Address, p.LogoPath, p.Contact, p.ReportPrefix, operator, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("save org profile: %w", err)
+	}
+	return nil
+}
+
+// NextReportSeq 递增并返回报告流水号；未配置编号前缀时 ok=false，不消耗流水号。
+func (s *Store) NextReportSeq(ctx context.Context) (prefix string, seq int64, ok bool, err error) {
+	err = s.db.QueryRowContext(ctx, `
+		UPDATE org_profile
+		SET report_seq = report_seq + 1
+		WHERE id = 1 AND report_prefix <> ''
+		RETURNING report_prefix, report_seq
+	`).Scan(&prefix, &seq)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", 0, false, nil
+		}
+		return "", 0, false, fmt.Errorf("next report seq: %w", err)
+	}
+	return prefix, seq, true, nil
+}
+
+// SetReportNo 登记报告编号。
+func (s *Store) SetReportNo(ctx context.Context, reportID, reportNo string) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE reports SET report_no = ? WHERE report_id = ?`, reportNo, reportID); err != nil {
+		return fmt.Errorf("set report no: %w", err)
+	}
+	return nil
+}
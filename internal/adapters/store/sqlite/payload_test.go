@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/snapshot"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestPayloadOffloadAndLazyLoad(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev := model.Device{ID: "dev_1", Name: "d", OS: model.OSWindows, Identifier: "id-1"}
+	if err := store.UpsertDevice(ctx, caseID, dev, true, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	mk := func(name, payload string) model.Artifact {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(payload), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		sum, size, err := hash.File(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return model.Artifact{
+			ID: "art_" + name, CaseID: caseID, DeviceID: dev.ID, Type: model.ArtifactInstalledApps,
+			SourceRef: name, SnapshotPath: path, SHA256: sum, SizeBytes: size,
+			CollectedAt: time.Now().Unix(), CollectorName: "test", CollectorVersion: "1",
+			ParserVersion: "1", AcquisitionMethod: "test", PayloadJSON: []byte(payload),
+			RecordHash: strings.Repeat("0", 64),
+		}
+	}
+	big := `[{"name":"Exodus","version":"` + strings.Repeat("1", 200) + `"}]`
+
+	// 存量数据：先以内联方式写入，再用 OffloadArtifactPayloads 外置。
+	if err := store.SaveArtifacts(ctx, []model.Artifact{mk("a.json", big)}); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := store.OffloadArtifactPayloads(ctx, 64)
+	if err != nil || stats.Offloaded != 1 || stats.BytesFreed <= 0 {
+		t.Fatalf("offload stats=%+v err=%v", stats, err)
+	}
+
+	// 新数据：设置上限后直接外置；payload 与快照不一致的证据保持内联。
+	if err := store.SetPayloadInlineLimit(ctx, 64); err != nil {
+		t.Fatal(err)
+	}
+	mismatch := mk("c.json", `{"zip":true}`)
+	mismatch.ID, mismatch.PayloadJSON = "art_c_meta", []byte(big)
+	// gzip 压缩快照：sha256 针对压缩字节，外置前需解压比对。
+	gzPath, err := snapshot.WriteJSON(filepath.Join(dir, "d.json"), []byte(big), snapshot.CompressionGzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := mk("d.json", big)
+	gz.ID, gz.SnapshotPath, gz.Compression = "art_d_gz", gzPath, snapshot.CompressionGzip
+	if gz.SHA256, gz.SizeBytes, err = hash.File(gzPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveArtifacts(ctx, []model.Artifact{mk("b.json", big), mismatch, gz}); err != nil {
+		t.Fatal(err)
+	}
+	var offloaded int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(1) FROM artifacts WHERE payload_storage = 'snapshot' AND length(payload_json) < 100`).Scan(&offloaded); err != nil || offloaded != 3 {
+		t.Fatalf("offloaded rows=%d err=%v", offloaded, err)
+	}
+
+	payloads, err := store.ListArtifactPayloadsWithIDByType(ctx, caseID, string(model.ArtifactInstalledApps))
+	if err != nil || len(payloads) != 4 {
+		t.Fatalf("payloads=%d err=%v", len(payloads), err)
+	}
+	for _, p := range payloads {
+		if string(p.PayloadJSON) != big {
+			t.Fatalf("artifact %s payload not restored: %s", p.ArtifactID, p.PayloadJSON)
+		}
+	}
+
+	// 快照被改动后拒绝读取。
+	if err := os.WriteFile(filepath.Join(dir, "b.json"), []byte(`[]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.ListArtifactPayloadsByType(ctx, caseID, string(model.ArtifactInstalledApps)); err == nil {
+		t.Fatalf("expected sha256 mismatch error")
+	}
+}
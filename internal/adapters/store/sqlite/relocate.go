@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 证据路径迁移
+//
+// 证据目录整体搬到其他磁盘后，库内的 snapshot_path / file_path 需要按前缀改写。
+// 候选行用 instr(path, prefix) = 1 预筛（字符语义与参数一致，不受多字节路径影响），
+// 前缀边界与新路径由调用方计算；改写在单个事务内逐行执行，并要求旧路径未被并发修改。
+
+// relocateTables 是保存证据文件路径的表。
+var relocateTables = []struct {
+	kind, table, idCol, pathCol, sizeExpr string
+}{
+	{model.RelocateKindArtifact, "artifacts", "artifact_id", "snapshot_path", "size_bytes"},
+	{model.RelocateKindReport, "reports", "report_id", "file_path", "-1"},
+	{model.RelocateKindAttachment, "case_attachments", "attachment_id", "file_path", "size_bytes"},
+}
+
+// ListPathsWithPrefix 返回路径以 prefix 开头的证据快照、报告与附件（caseID 为空时不限案件）。
+// NewPath 留空，由调用方填写。
+func (s *Store) ListPathsWithPrefix(ctx context.Context, caseID, prefix string) ([]model.RelocatedPath, error) {
+	var out []model.RelocatedPath
+	for _, t := range relocateTables {
+		rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+			SELECT %s, case_id, %s, sha256, %s
+			FROM %s
+			WHERE instr(%s, ?) = 1 AND (? = '' OR case_id = ?)
+			ORDER BY case_id, %s
+		`, t.idCol, t.pathCol, t.sizeExpr, t.table, t.pathCol, t.idCol), prefix, caseID, caseID)
+		if err != nil {
+			return nil, fmt.Errorf("query %s paths: %w", t.table, err)
+		}
+		for rows.Next() {
+			p := model.RelocatedPath{Kind: t.kind}
+			if err := rows.Scan(&p.ID, &p.CaseID, &p.OldPath, &p.SHA256, &p.SizeBytes); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan %s path: %w", t.table, err)
+			}
+			out = append(out, p)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("iterate %s paths: %w", t.table, err)
+		}
+		rows.Close()
+	}
+	return out, nil
+}
+
+// RelocatePaths 在单个事务内把 paths 中每一行的路径从 OldPath 改为 NewPath。
+// 任一行的当前路径已不是 OldPath（被并发修改或已迁移）时整体回滚。
+func (s *Store) RelocatePaths(ctx context.Context, paths []model.RelocatedPath) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin relocate tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, p := range paths {
+		var table, idCol, pathCol string
+		for _, t := range relocateTables {
+			if t.kind == p.Kind {
+				table, idCol, pathCol = t.table, t.idCol, t.pathCol
+			}
+		}
+		if table == "" {
+			return fmt.Errorf("unknown relocate kind: %s", p.Kind)
+		}
+		res, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET %s = ? WHERE %s = ? AND %s = ?`, table, pathCol, idCol, pathCol),
+			p.NewPath, p.ID, p.OldPath)
+		if err != nil {
+			return fmt.Errorf("update %s path %s: %w", table, p.ID, err)
+		}
+		if n, _ := res.RowsAffected(); n != 1 {
+			return fmt.Errorf("relocate %s %s: path changed concurrently", p.Kind, p.ID)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit relocate tx: %w", err)
+	}
+	return nil
+}
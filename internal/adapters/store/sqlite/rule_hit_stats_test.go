@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestRuleHitBundleStats(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "d", OS: model.OSWindows, Identifier: "id-1"}, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	v1, err := store.EnsureRuleBundle(ctx, "wallet_signatures", "2024.01", fmt.Sprintf("%064d", 1), "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := store.EnsureRuleBundle(ctx, "wallet_signatures", "2024.06", fmt.Sprintf("%064d", 2), "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE rule_bundles SET loaded_at = CASE bundle_id WHEN ? THEN 100 ELSE 500 END`, v1); err != nil {
+		t.Fatal(err)
+	}
+
+	// 旧数据（无规则包记录）1 条、2024.01 版 2 条、2024.06 版 3 条。
+	var hits []model.RuleHit
+	add := func(bundleID, version string, n int) {
+		for i := 0; i < n; i++ {
+			hits = append(hits, model.RuleHit{
+				ID: fmt.Sprintf("hit_%d", len(hits)), CaseID: caseID, DeviceID: "dev_1", Type: model.HitWalletInstalled,
+				RuleID: "metamask", RuleName: "MetaMask", RuleBundleID: bundleID, RuleVersion: version,
+				MatchedValue: fmt.Sprintf("v%d", len(hits)), Confidence: 0.9, Verdict: "confirmed", DetailJSON: []byte(`{}`),
+			})
+		}
+	}
+	add("", "2023.12", 1)
+	add(v1, "2024.01", 2)
+	add(v2, "2024.06", 3)
+	if err := store.SaveRuleHits(ctx, hits); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE rule_hits SET created_at = 10 * (CAST(substr(hit_id, 5) AS INTEGER) + 1)`); err != nil {
+		t.Fatal(err)
+	}
+
+	segs, err := store.ListRuleHitBundleStats(ctx, model.RuleHitStatsQuery{RuleID: "metamask"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := ""
+	for _, s := range segs {
+		got += fmt.Sprintf("%s:%d:%d-%d;", s.BundleVersion, s.HitCount, s.FirstHitAt, s.LastHitAt)
+	}
+	if want := "2023.12:1:10-10;2024.01:2:20-30;2024.06:3:40-60;"; got != want {
+		t.Fatalf("segments=%s want %s", got, want)
+	}
+	if segs[2].BundleID != v2 || segs[2].BundleLoadedAt != 500 || segs[2].BundleType != "wallet_signatures" || segs[0].BundleID != "" {
+		t.Fatalf("segments=%+v", segs)
+	}
+
+	stats, err := store.ListRuleHitStats(ctx, model.RuleHitStatsQuery{BundleVersion: "2024.06"})
+	if err != nil || len(stats) != 1 || stats[0].HitCount != 3 || stats[0].LastHitAt != 60 {
+		t.Fatalf("stats=%+v err=%v", stats, err)
+	}
+	stats, err = store.ListRuleHitStats(ctx, model.RuleHitStatsQuery{Since: 20, Until: 40})
+	if err != nil || len(stats) != 1 || stats[0].HitCount != 3 {
+		t.Fatalf("window stats=%+v err=%v", stats, err)
+	}
+	stats, err = store.ListRuleHitStats(ctx, model.RuleHitStatsQuery{})
+	if err != nil || len(stats) != 1 || stats[0].HitCount != 6 || stats[0].CaseCount != 1 {
+		t.Fatalf("all stats=%+v err=%v", stats, err)
+	}
+}
+
+func TestListRuleHitStatsMergeHitTypes(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := NewStore(db)
+	var hits []model.RuleHit
+	for _, c := range []string{"a", "b"} {
+		caseID, err := store.EnsureCase(ctx, "", "", c, "op", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		devID := "dev_" + c
+		if err := store.UpsertDevice(ctx, caseID, model.Device{ID: devID, Name: "d", OS: model.OSWindows, Identifier: "id-" + c}, true, ""); err != nil {
+			t.Fatal(err)
+		}
+		// 案件 a：exodus 安装 + 运行痕迹；案件 b：只有运行痕迹。binance 只有客户端安装命中。
+		types := []model.HitType{model.HitWalletExecuted}
+		if c == "a" {
+			types = append(types, model.HitWalletInstalled)
+		}
+		for _, ht := range types {
+			hits = append(hits, model.RuleHit{
+				ID: fmt.Sprintf("hit_%d", len(hits)), CaseID: caseID, DeviceID: devID, Type: ht, RuleID: "exodus",
+				MatchedValue: "Exodus", Confidence: 0.9, Verdict: "confirmed", DetailJSON: []byte(`{}`),
+			})
+		}
+		hits = append(hits, model.RuleHit{
+			ID: fmt.Sprintf("hit_%d", len(hits)), CaseID: caseID, DeviceID: devID, Type: model.HitExchangeAppInstalled, RuleID: "binance",
+			MatchedValue: "Binance", Confidence: 0.9, Verdict: "confirmed", DetailJSON: []byte(`{}`),
+		})
+	}
+	if err := store.SaveRuleHits(ctx, hits); err != nil {
+		t.Fatal(err)
+	}
+
+	wallet := []string{string(model.HitWalletInstalled), string(model.HitWalletExecuted)}
+	perType, err := store.ListRuleHitStats(ctx, model.RuleHitStatsQuery{HitTypes: wallet})
+	if err != nil || len(perType) != 2 {
+		t.Fatalf("per type=%+v err=%v", perType, err)
+	}
+	merged, err := store.ListRuleHitStats(ctx, model.RuleHitStatsQuery{HitTypes: wallet, MergeHitTypes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 条命中、2 个案件（案件 a 的两种类型不重复计数）。
+	if len(merged) != 1 || merged[0].RuleID != "exodus" || merged[0].HitType != "" || merged[0].HitCount != 3 || merged[0].CaseCount != 2 {
+		t.Fatalf("merged=%+v", merged)
+	}
+}
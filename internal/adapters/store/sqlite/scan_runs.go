@@ -0,0 +1,72 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 扫描参数快照（见 039_scan_runs.sql）
+//
+// 每次扫描开始时由扫描服务写入一条，options_json 原样保存、不再修改。
+
+// SaveScanRun 保存一次扫描的规范化参数快照并返回记录。
+func (s *Store) SaveScanRun(ctx context.Context, caseID, deviceID, operator string, startedAt int64, opts model.ScanOptions) (*model.ScanRun, error) {
+	raw, err := json.Marshal(opts)
+	if err != nil {
+		return nil, fmt.Errorf("marshal scan options: %w", err)
+	}
+	run := model.ScanRun{
+		RunID:         id.New("run"),
+		CaseID:        caseID,
+		DeviceID:      deviceID,
+		ScanType:      opts.ScanType,
+		Operator:      operator,
+		Options:       raw,
+		OptionsSHA256: hash.Bytes(raw),
+		StartedAt:     startedAt,
+		CreatedAt:     time.Now().Unix(),
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO scan_runs(run_id, case_id, device_id, scan_type, operator, options_json, options_sha256, started_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, run.RunID, caseID, nullIfEmpty(deviceID), run.ScanType, nullIfEmpty(operator), string(raw), run.OptionsSHA256, startedAt, run.CreatedAt); err != nil {
+		return nil, fmt.Errorf("insert scan run: %w", err)
+	}
+	return &run, nil
+}
+
+// ListScanRuns 返回案件的扫描参数快照（按开始时间升序）。
+func (s *Store) ListScanRuns(ctx context.Context, caseID string) ([]model.ScanRun, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT run_id, case_id, COALESCE(device_id, ''), scan_type, COALESCE(operator, ''),
+			options_json, options_sha256, started_at, created_at
+		FROM scan_runs
+		WHERE case_id = ?
+		ORDER BY started_at, run_id
+	`, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("query scan runs: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.ScanRun{}
+	for rows.Next() {
+		var r model.ScanRun
+		var raw string
+		if err := rows.Scan(&r.RunID, &r.CaseID, &r.DeviceID, &r.ScanType, &r.Operator, &raw, &r.OptionsSHA256, &r.StartedAt, &r.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan scan run: %w", err)
+		}
+		r.Options = json.RawMessage(raw)
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate scan runs: %w", err)
+	}
+	return out, nil
+}
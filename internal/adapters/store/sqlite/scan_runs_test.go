@@ -0,0 +1,60 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestScanRunsRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := model.ScanOptions{
+		ScanType:    "mobile_scan",
+		Profile:     "external",
+		PrivacyMode: "masked",
+		Mobile:      &model.MobileScanOptions{EnableAndroid: true, EnableIOSFullBackup: true},
+	}
+	saved, err := store.SaveScanRun(ctx, caseID, "", "alice", 100, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runs, err := store.ListScanRuns(ctx, caseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].RunID != saved.RunID || runs[0].ScanType != "mobile_scan" || runs[0].DeviceID != "" || runs[0].Operator != "alice" {
+		t.Fatalf("runs=%+v", runs)
+	}
+	if runs[0].OptionsSHA256 != hash.Bytes(runs[0].Options) {
+		t.Fatalf("options sha256 mismatch: %s", runs[0].OptionsSHA256)
+	}
+	var got model.ScanOptions
+	if err := json.Unmarshal(runs[0].Options, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Profile != "external" || got.Mobile == nil || !got.Mobile.EnableIOSFullBackup || got.Host != nil {
+		t.Fatalf("options=%s", runs[0].Options)
+	}
+}
@@ -1,17 +1,24 @@
 package sqlite
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"crypto-inspector/internal/app"
 	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/filetype"
 	"crypto-inspector/internal/platform/hash"
 	"crypto-inspector/internal/platform/id"
+	"crypto-inspector/internal/platform/snapshot"
+	"crypto-inspector/internal/platform/trace"
 )
 
 // Store 封装与 SQLite 的读写逻辑。
@@ -35,14 +42,14 @@ func (s *Store) EnsureCase(ctx context.Context, caseID, caseNo, title, operator,
 	}
 
 	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO cases(case_id, case_no, title, status, created_by, note, created_at, updated_at)
-		VALUES(?, ?, ?, 'open', ?, ?, ?, ?)
+		INSERT INTO cases(case_id, case_no, title, status, created_by, owner, note, created_at, updated_at)
+		VALUES(?, ?, ?, 'open', ?, ?, ?, ?, ?)
 		ON CONFLICT(case_id) DO UPDATE SET
 			updated_at=excluded.updated_at,
 			case_no=CASE WHEN excluded.case_no IS NULL OR excluded.case_no='' THEN cases.case_no ELSE excluded.case_no END,
 			title=CASE WHEN excluded.title IS NULL OR excluded.title='' THEN cases.title ELSE excluded.title END,
 			note=CASE WHEN excluded.note IS NULL OR excluded.note='' THEN cases.note ELSE excluded.note END
-	`, caseID, nullIfEmpty(caseNo), title, operator, note, now, now)
+	`, caseID, nullIfEmpty(caseNo), title, operator, nullIfEmpty(operator), note, now, now)
 	if err != nil {
 		return "", fmt.Errorf("upsert case: %w", err)
 	}
@@ -125,10 +132,17 @@ func (s *Store) UpsertDevice(ctx context.Context, caseID string, d model.Device,
 }
 
 // SaveArtifacts 批量写入 artifacts，使用事务保证原子性。
+//
+// 启用分层存储（payload_inline_max_bytes > 0）时，超过上限且与快照文件逐字节一致的 payload
+// 只在库中保留摘要，原文按需从快照文件读取。
 func (s *Store) SaveArtifacts(ctx context.Context, artifacts []model.Artifact) error {
 	if len(artifacts) == 0 {
 		return nil
 	}
+	inlineLimit, err := s.PayloadInlineLimit(ctx)
+	if err != nil {
+		return err
+	}
 
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -145,9 +159,10 @@ func (s *Store) SaveArtifacts(ctx context.Context, artifacts []model.Artifact) e
 			artifact_id, case_id, device_id, artifact_type, source_ref, snapshot_path,
 			sha256, sha256_algo, size_bytes, mime_type, collected_at, collector_name,
 			collector_version, parser_version, acquisition_method, payload_json,
-			is_encrypted, encryption_note, record_hash, created_at
+			payload_storage, payload_bytes, snapshot_compression,
+			is_encrypted, encryption_note, record_hash, part_group, part_no, part_count, created_at
 		)
-		VALUES(?, ?, ?, ?, ?, ?, ?, 'sha256', ?, 'application/json', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		VALUES(?, ?, ?, ?, ?, ?, ?, 'sha256', ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("prepare insert artifacts: %w", err)
@@ -156,6 +171,19 @@ func (s *Store) SaveArtifacts(ctx context.Context, artifacts []model.Artifact) e
 
 	now := time.Now().Unix()
 	for _, a := range artifacts {
+		// 采集器未显式声明类型时按快照文件头探测（不再一律记为 application/json）。
+		mimeType := strings.TrimSpace(a.MimeType)
+		if mimeType == "" {
+			mimeType = filetype.DetectFile(a.SnapshotPath)
+		}
+		compression := a.Compression
+		if compression == "" {
+			compression = snapshot.CompressionNone
+		}
+		payload, storage := string(a.PayloadJSON), payloadStorageInline
+		if inlineLimit > 0 && int64(len(a.PayloadJSON)) > inlineLimit && canOffloadPayload(a.PayloadJSON, a.SnapshotPath, a.SHA256, compression) {
+			payload, storage = payloadSummary(a.PayloadJSON), payloadStorageSnapshot
+		}
 		_, err = stmt.ExecContext(ctx,
 			a.ID,
 			a.CaseID,
@@ -165,15 +193,22 @@ func (s *Store) SaveArtifacts(ctx context.Context, artifacts []model.Artifact) e
 			a.SnapshotPath,
 			a.SHA256,
 			a.SizeBytes,
+			mimeType,
 			a.CollectedAt,
 			a.CollectorName,
 			a.CollectorVersion,
 			a.ParserVersion,
 			a.AcquisitionMethod,
-			string(a.PayloadJSON),
+			payload,
+			storage,
+			len(a.PayloadJSON),
+			compression,
 			boolToInt(a.IsEncrypted),
 			a.EncryptionNote,
 			a.RecordHash,
+			nullIfEmpty(a.PartGroup),
+			nullIfZero(a.PartNo),
+			nullIfZero(a.PartCount),
 			now,
 		)
 		if err != nil {
@@ -401,6 +436,12 @@ func (s *Store) AppendAudit(ctx context.Context, caseID, deviceID, eventType, ac
 			detailJSON = raw
 		}
 	}
+	// trace_id 写入 detail_json（参与 chain_hash 计算），便于按一次请求/扫描串联审计记录；
+	// 同时写入 trace_id 列（带索引，不参与哈希）供按 trace 查询。
+	traceID := trace.IDFromContext(ctx)
+	if traceID != "" {
+		detailJSON = withTraceID(detailJSON, traceID)
+	}
 
 	prev := ""
 	err := s.db.QueryRowContext(ctx, `
@@ -421,10 +462,10 @@ func (s *Store) AppendAudit(ctx context.Context, caseID, deviceID, eventType, ac
 	_, err = s.db.ExecContext(ctx, `
 		INSERT INTO audit_logs(
 			event_id, case_id, device_id, event_type, action, status,
-			actor, source, detail_json, occurred_at, chain_prev_hash, chain_hash
+			actor, source, detail_json, occurred_at, chain_prev_hash, chain_hash, trace_id
 		)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, eventID, caseID, nullIfEmpty(deviceID), eventType, action, status, actor, source, string(detailJSON), now, nullIfEmpty(prev), chain)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, eventID, caseID, nullIfEmpty(deviceID), eventType, action, status, actor, source, string(detailJSON), now, nullIfEmpty(prev), chain, nullIfEmpty(traceID))
 	if err != nil {
 		return fmt.Errorf("insert audit log: %w", err)
 	}
@@ -432,6 +473,33 @@ func (s *Store) AppendAudit(ctx context.Context, caseID, deviceID, eventType, ac
 	return nil
 }
 
+// withTraceID 在 JSON 对象末尾追加 trace_id 字段（非对象或已存在时原样返回）。
+//
+// 只在原字节后拼接，不做解码再编码：原有字段的顺序与数值（超过 2^53 的整数）保持不变。
+func withTraceID(detailJSON []byte, traceID string) []byte {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(detailJSON, &m); err != nil || m == nil {
+		return detailJSON
+	}
+	if _, ok := m["trace_id"]; ok {
+		return detailJSON
+	}
+	field, err := json.Marshal(traceID)
+	if err != nil {
+		return detailJSON
+	}
+	body := bytes.TrimRight(detailJSON, " \t\r\n")
+	body = bytes.TrimSuffix(body, []byte("}"))
+	out := make([]byte, 0, len(body)+len(field)+14)
+	out = append(out, body...)
+	if len(m) > 0 {
+		out = append(out, ',')
+	}
+	out = append(out, `"trace_id":`...)
+	out = append(out, field...)
+	return append(out, '}')
+}
+
 // SaveReport 记录报告产物信息，供 UI 或导出流程追踪。
 func (s *Store) SaveReport(ctx context.Context, caseID, reportType, filePath, sha256, generatorVersion, status string) (string, error) {
 	reportID := id.New("report")
@@ -449,6 +517,14 @@ func (s *Store) SaveReport(ctx context.Context, caseID, reportType, filePath, sh
 	return reportID, nil
 }
 
+// SetReportEncryption 登记导出包的加密方式（只记录方式，不记录密码）。
+func (s *Store) SetReportEncryption(ctx context.Context, reportID, encryption string) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE reports SET encryption = ? WHERE report_id = ?`, encryption, reportID); err != nil {
+		return fmt.Errorf("set report encryption: %w", err)
+	}
+	return nil
+}
+
 // GetCaseOverview 返回案件聚合摘要（设备数/证据数/命中数/报告数）。
 func (s *Store) GetCaseOverview(ctx context.Context, caseID string) (*model.CaseOverview, error) {
 	row := s.db.QueryRowContext(ctx, `
@@ -458,6 +534,7 @@ func (s *Store) GetCaseOverview(ctx context.Context, caseID string) (*model.Case
 			COALESCE(c.title, ''),
 			c.status,
 			COALESCE(c.created_by, ''),
+			COALESCE(c.owner, ''),
 			COALESCE(c.note, ''),
 			c.created_at,
 			c.updated_at,
@@ -476,6 +553,7 @@ func (s *Store) GetCaseOverview(ctx context.Context, caseID string) (*model.Case
 		&out.Title,
 		&out.Status,
 		&out.CreatedBy,
+		&out.Owner,
 		&out.Note,
 		&out.CreatedAt,
 		&out.UpdatedAt,
@@ -512,7 +590,8 @@ func (s *Store) ListCaseHitDetails(ctx context.Context, caseID, hitType string)
 				COALESCE(h.rule_name, ''), COALESCE(h.rule_version, ''), h.matched_value,
 				COALESCE(h.first_seen_at, 0), COALESCE(h.last_seen_at, 0),
 				h.confidence, h.verdict, COALESCE(h.detail_json, '{}'),
-				COALESCE(GROUP_CONCAT(l.artifact_id, ','), '')
+				COALESCE(GROUP_CONCAT(l.artifact_id, ','), ''),
+				COALESCE(h.cluster_id, '')
 			FROM rule_hits h
 			LEFT JOIN hit_artifact_links l ON l.hit_id = h.hit_id
 			WHERE h.case_id = ?
@@ -526,7 +605,8 @@ func (s *Store) ListCaseHitDetails(ctx context.Context, caseID, hitType string)
 				COALESCE(h.rule_name, ''), COALESCE(h.rule_version, ''), h.matched_value,
 				COALESCE(h.first_seen_at, 0), COALESCE(h.last_seen_at, 0),
 				h.confidence, h.verdict, COALESCE(h.detail_json, '{}'),
-				COALESCE(GROUP_CONCAT(l.artifact_id, ','), '')
+				COALESCE(GROUP_CONCAT(l.artifact_id, ','), ''),
+				COALESCE(h.cluster_id, '')
 			FROM rule_hits h
 			LEFT JOIN hit_artifact_links l ON l.hit_id = h.hit_id
 			WHERE h.case_id = ? AND h.hit_type = ?
@@ -539,6 +619,11 @@ func (s *Store) ListCaseHitDetails(ctx context.Context, caseID, hitType string)
 	}
 	defer rows.Close()
 
+	return scanHitDetailRows(rows)
+}
+
+// scanHitDetailRows 读取命中明细查询结果（列顺序见 ListCaseHitDetails）。
+func scanHitDetailRows(rows *sql.Rows) ([]model.HitDetail, error) {
 	var out []model.HitDetail
 	for rows.Next() {
 		var item model.HitDetail
@@ -558,6 +643,7 @@ func (s *Store) ListCaseHitDetails(ctx context.Context, caseID, hitType string)
 			&item.Verdict,
 			&item.DetailJSON,
 			&artifactIDsRaw,
+			&item.ClusterID,
 		); err != nil {
 			return nil, fmt.Errorf("scan hit detail: %w", err)
 		}
@@ -576,6 +662,8 @@ func (s *Store) ListCaseHitDetails(ctx context.Context, caseID, hitType string)
 		} else {
 			item.ArtifactIDs = []string{}
 		}
+		item.Manual = item.RuleID == model.ManualHitRuleID
+		item.WalletCategory = model.WalletCategoryOf(item.HitType, item.DetailJSON)
 		out = append(out, item)
 	}
 	if err := rows.Err(); err != nil {
@@ -585,10 +673,56 @@ func (s *Store) ListCaseHitDetails(ctx context.Context, caseID, hitType string)
 	return out, nil
 }
 
+// ListBalanceHitsAsOf 返回截至 asOf（含）落库的余额类命中（token_balance / nft_holdings），按写入时间升序。
+// 用于还原“某份报告生成时”的链上余额状态（同一地址后写入的结果覆盖先前结果）。
+func (s *Store) ListBalanceHitsAsOf(ctx context.Context, caseID string, asOf int64) ([]model.HitDetail, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			hit_id, case_id, device_id, hit_type, rule_id,
+			COALESCE(rule_name, ''), COALESCE(rule_version, ''), matched_value,
+			COALESCE(first_seen_at, 0), COALESCE(last_seen_at, 0),
+			confidence, verdict, COALESCE(detail_json, '{}')
+		FROM rule_hits
+		WHERE case_id = ? AND hit_type IN ('token_balance', 'nft_holdings') AND created_at <= ?
+		ORDER BY created_at ASC, hit_id ASC
+	`, caseID, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("query balance hits: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.HitDetail{}
+	for rows.Next() {
+		var item model.HitDetail
+		if err := rows.Scan(
+			&item.HitID,
+			&item.CaseID,
+			&item.DeviceID,
+			&item.HitType,
+			&item.RuleID,
+			&item.RuleName,
+			&item.RuleVersion,
+			&item.MatchedValue,
+			&item.FirstSeenAt,
+			&item.LastSeenAt,
+			&item.Confidence,
+			&item.Verdict,
+			&item.DetailJSON,
+		); err != nil {
+			return nil, fmt.Errorf("scan balance hit: %w", err)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate balance hits: %w", err)
+	}
+	return out, nil
+}
+
 // GetLatestReportByCase 返回案件最新报告索引。
 func (s *Store) GetLatestReportByCase(ctx context.Context, caseID string) (*model.ReportInfo, error) {
 	row := s.db.QueryRowContext(ctx, `
-		SELECT report_id, case_id, report_type, file_path, sha256, generated_at, generator_version, status
+		SELECT report_id, case_id, report_type, file_path, sha256, generated_at, generator_version, status, COALESCE(report_no, ''), COALESCE(encryption, '')
 		FROM reports
 		WHERE case_id = ?
 		ORDER BY generated_at DESC, report_id DESC
@@ -600,7 +734,7 @@ func (s *Store) GetLatestReportByCase(ctx context.Context, caseID string) (*mode
 // GetReportByID 按报告 ID 查询报告索引。
 func (s *Store) GetReportByID(ctx context.Context, reportID string) (*model.ReportInfo, error) {
 	row := s.db.QueryRowContext(ctx, `
-		SELECT report_id, case_id, report_type, file_path, sha256, generated_at, generator_version, status
+		SELECT report_id, case_id, report_type, file_path, sha256, generated_at, generator_version, status, COALESCE(report_no, ''), COALESCE(encryption, '')
 		FROM reports
 		WHERE report_id = ?
 		LIMIT 1
@@ -611,7 +745,7 @@ func (s *Store) GetReportByID(ctx context.Context, reportID string) (*model.Repo
 // ListReportsByCase 返回案件全部报告索引，按生成时间倒序。
 func (s *Store) ListReportsByCase(ctx context.Context, caseID string) ([]model.ReportInfo, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT report_id, case_id, report_type, file_path, sha256, generated_at, generator_version, status
+		SELECT report_id, case_id, report_type, file_path, sha256, generated_at, generator_version, status, COALESCE(report_no, ''), COALESCE(encryption, '')
 		FROM reports
 		WHERE case_id = ?
 		ORDER BY generated_at DESC, report_id DESC
@@ -633,6 +767,8 @@ func (s *Store) ListReportsByCase(ctx context.Context, caseID string) ([]model.R
 			&item.GeneratedAt,
 			&item.GeneratorVersion,
 			&item.Status,
+			&item.ReportNo,
+			&item.Encryption,
 		); err != nil {
 			return nil, fmt.Errorf("scan report: %w", err)
 		}
@@ -658,6 +794,8 @@ func scanReportInfo(row *sql.Row) (*model.ReportInfo, error) {
 		&out.GeneratedAt,
 		&out.GeneratorVersion,
 		&out.Status,
+		&out.ReportNo,
+		&out.Encryption,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -668,7 +806,9 @@ func scanReportInfo(row *sql.Row) (*model.ReportInfo, error) {
 }
 
 // ListCases 返回案件列表，按更新时间倒序。
-func (s *Store) ListCases(ctx context.Context, limit, offset int) ([]model.CaseSummary, error) {
+//
+// owner 非空时只返回该操作员当前负责的案件（“我的案件”）。
+func (s *Store) ListCases(ctx context.Context, owner string, limit, offset int) ([]model.CaseSummary, error) {
 	if limit <= 0 {
 		limit = 50
 	}
@@ -686,13 +826,15 @@ func (s *Store) ListCases(ctx context.Context, limit, offset int) ([]model.CaseS
 			COALESCE(c.title, ''),
 			c.status,
 			COALESCE(c.created_by, ''),
+			COALESCE(c.owner, ''),
 			COALESCE(c.note, ''),
 			c.created_at,
 			c.updated_at
 		FROM cases c
+		WHERE ? = '' OR c.owner = ?
 		ORDER BY c.updated_at DESC, c.created_at DESC
 		LIMIT ? OFFSET ?
-	`, limit, offset)
+	`, owner, owner, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("query cases: %w", err)
 	}
@@ -707,6 +849,7 @@ func (s *Store) ListCases(ctx context.Context, limit, offset int) ([]model.CaseS
 			&item.Title,
 			&item.Status,
 			&item.CreatedBy,
+			&item.Owner,
 			&item.Note,
 			&item.CreatedAt,
 			&item.UpdatedAt,
@@ -799,6 +942,7 @@ func (s *Store) ListAuditLogs(ctx context.Context, caseID string, limit int) ([]
 
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT
+			rowid,
 			event_id,
 			case_id,
 			COALESCE(device_id, ''),
@@ -821,11 +965,117 @@ func (s *Store) ListAuditLogs(ctx context.Context, caseID string, limit int) ([]
 	}
 	defer rows.Close()
 
+	return scanAuditLogRows(rows)
+}
+
+// ListAuditLogsByTrace 按 trace_id 查询审计日志；caseID 为空时跨案件。
+func (s *Store) ListAuditLogsByTrace(ctx context.Context, traceID, caseID string, limit int) ([]model.AuditLog, error) {
+	if limit <= 0 {
+		limit = 500
+	}
+	if limit > 5000 {
+		limit = 5000
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			rowid,
+			event_id,
+			case_id,
+			COALESCE(device_id, ''),
+			event_type,
+			action,
+			status,
+			COALESCE(actor, ''),
+			COALESCE(source, ''),
+			COALESCE(detail_json, '{}'),
+			occurred_at,
+			COALESCE(chain_prev_hash, ''),
+			chain_hash
+		FROM audit_logs
+		WHERE trace_id = ? AND (? = '' OR case_id = ?)
+		ORDER BY occurred_at ASC, event_id ASC
+		LIMIT ?
+	`, traceID, caseID, caseID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query audit logs by trace: %w", err)
+	}
+	defer rows.Close()
+
+	return scanAuditLogRows(rows)
+}
+
+// AuditLogQuery 是跨案件按写入顺序读取审计日志的条件（SIEM 转发/回放用）。
+//
+// AfterSeq 为游标（audit_logs.rowid，单调递增）；其余字段为空/0 表示不过滤。
+type AuditLogQuery struct {
+	AfterSeq int64
+	CaseID   string
+	Since    int64 // occurred_at >= Since
+	Until    int64 // occurred_at <= Until
+	Limit    int
+}
+
+// ListAuditLogsAfter 按 rowid 升序返回游标之后的审计日志（跨案件）。
+func (s *Store) ListAuditLogsAfter(ctx context.Context, q AuditLogQuery) ([]model.AuditLog, error) {
+	if q.Limit <= 0 {
+		q.Limit = 500
+	}
+	if q.Limit > 5000 {
+		q.Limit = 5000
+	}
+
+	where := []string{"rowid > ?"}
+	args := []any{q.AfterSeq}
+	if strings.TrimSpace(q.CaseID) != "" {
+		where = append(where, "case_id = ?")
+		args = append(args, strings.TrimSpace(q.CaseID))
+	}
+	if q.Since > 0 {
+		where = append(where, "occurred_at >= ?")
+		args = append(args, q.Since)
+	}
+	if q.Until > 0 {
+		where = append(where, "occurred_at <= ?")
+		args = append(args, q.Until)
+	}
+	args = append(args, q.Limit)
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			rowid,
+			event_id,
+			case_id,
+			COALESCE(device_id, ''),
+			event_type,
+			action,
+			status,
+			COALESCE(actor, ''),
+			COALESCE(source, ''),
+			COALESCE(detail_json, '{}'),
+			occurred_at,
+			COALESCE(chain_prev_hash, ''),
+			chain_hash
+		FROM audit_logs
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY rowid ASC
+		LIMIT ?
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit logs after seq: %w", err)
+	}
+	defer rows.Close()
+
+	return scanAuditLogRows(rows)
+}
+
+func scanAuditLogRows(rows *sql.Rows) ([]model.AuditLog, error) {
 	var out []model.AuditLog
 	for rows.Next() {
 		var item model.AuditLog
 		var detail string
 		if err := rows.Scan(
+			&item.Seq,
 			&item.EventID,
 			&item.CaseID,
 			&item.DeviceID,
@@ -868,7 +1118,14 @@ func (s *Store) ListArtifactsByCase(ctx context.Context, caseID string) ([]model
 			collected_at,
 			COALESCE(collector_name, ''),
 			COALESCE(collector_version, ''),
-			COALESCE(acquisition_method, '')
+			COALESCE(parser_version, ''),
+			COALESCE(acquisition_method, ''),
+			COALESCE(mime_type, ''),
+			snapshot_compression,
+			COALESCE(exhibit_no, 0),
+			COALESCE(part_group, ''),
+			COALESCE(part_no, 0),
+			COALESCE(part_count, 0)
 		FROM artifacts
 		WHERE case_id = ?
 		ORDER BY collected_at DESC, artifact_id DESC
@@ -878,6 +1135,11 @@ func (s *Store) ListArtifactsByCase(ctx context.Context, caseID string) ([]model
 	}
 	defer rows.Close()
 
+	return scanArtifactInfoRows(rows)
+}
+
+// scanArtifactInfoRows 读取证据索引查询结果（列顺序见 ListArtifactsByCase）。
+func scanArtifactInfoRows(rows *sql.Rows) ([]model.ArtifactInfo, error) {
 	var out []model.ArtifactInfo
 	for rows.Next() {
 		var item model.ArtifactInfo
@@ -893,10 +1155,19 @@ func (s *Store) ListArtifactsByCase(ctx context.Context, caseID string) ([]model
 			&item.CollectedAt,
 			&item.CollectorName,
 			&item.CollectorVersion,
+			&item.ParserVersion,
 			&item.AcquisitionMethod,
+			&item.MimeType,
+			&item.Compression,
+			&item.ExhibitNo,
+			&item.PartGroup,
+			&item.PartNo,
+			&item.PartCount,
 		); err != nil {
 			return nil, fmt.Errorf("scan artifact info: %w", err)
 		}
+		item.Exhibit = ExhibitLabel(item.ExhibitNo)
+		item.CompatWarning = app.ParserCompatWarning(item.CollectorName, item.ParserVersion)
 		out = append(out, item)
 	}
 	if err := rows.Err(); err != nil {
@@ -923,7 +1194,14 @@ func (s *Store) GetArtifactInfo(ctx context.Context, artifactID string) (*model.
 			collected_at,
 			COALESCE(collector_name, ''),
 			COALESCE(collector_version, ''),
-			COALESCE(acquisition_method, '')
+			COALESCE(parser_version, ''),
+			COALESCE(acquisition_method, ''),
+			COALESCE(mime_type, ''),
+			snapshot_compression,
+			COALESCE(exhibit_no, 0),
+			COALESCE(part_group, ''),
+			COALESCE(part_no, 0),
+			COALESCE(part_count, 0)
 		FROM artifacts
 		WHERE artifact_id = ?
 		LIMIT 1
@@ -942,16 +1220,68 @@ func (s *Store) GetArtifactInfo(ctx context.Context, artifactID string) (*model.
 		&item.CollectedAt,
 		&item.CollectorName,
 		&item.CollectorVersion,
+		&item.ParserVersion,
 		&item.AcquisitionMethod,
+		&item.MimeType,
+		&item.Compression,
+		&item.ExhibitNo,
+		&item.PartGroup,
+		&item.PartNo,
+		&item.PartCount,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
 		}
 		return nil, fmt.Errorf("query artifact info: %w", err)
 	}
+	item.Exhibit = ExhibitLabel(item.ExhibitNo)
+	item.CompatWarning = app.ParserCompatWarning(item.CollectorName, item.ParserVersion)
 	return &item, nil
 }
 
+// ListArtifactPayloadsByType 返回指定类型证据的 payload_json（caseID 为空时跨全部案件）。
+//
+// 主要给“离线工具类”功能使用（例如从历史扫描中汇总未知扩展 ID），不在 UI 列表中使用。
+func (s *Store) ListArtifactPayloadsByType(ctx context.Context, caseID, artifactType string) ([]json.RawMessage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT COALESCE(payload_json, ''), payload_storage, snapshot_path, sha256
+		FROM artifacts
+		WHERE artifact_type = ?
+		  AND (? = '' OR case_id = ?)
+		ORDER BY collected_at DESC, COALESCE(part_group, artifact_id) DESC, COALESCE(part_no, 0) ASC, artifact_id DESC
+	`, artifactType, caseID, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("query artifact payloads: %w", err)
+	}
+	defer rows.Close()
+
+	var out []json.RawMessage
+	for rows.Next() {
+		var raw, storage, snapshotPath, sum string
+		if err := rows.Scan(&raw, &storage, &snapshotPath, &sum); err != nil {
+			return nil, fmt.Errorf("scan artifact payload: %w", err)
+		}
+		if storage == payloadStorageSnapshot {
+			b, err := loadSnapshotPayload(snapshotPath, sum)
+			if err != nil {
+				return nil, err
+			}
+			raw = string(b)
+		}
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		out = append(out, json.RawMessage(raw))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate artifact payloads: %w", err)
+	}
+	if out == nil {
+		out = []json.RawMessage{}
+	}
+	return out, nil
+}
+
 // ListCaseDevices 返回案件关联的设备列表。
 func (s *Store) ListCaseDevices(ctx context.Context, caseID string) ([]model.CaseDevice, error) {
 	rows, err := s.db.QueryContext(ctx, `
@@ -965,7 +1295,15 @@ func (s *Store) ListCaseDevices(ctx context.Context, caseID string) ([]model.Cas
 			is_authorized,
 			COALESCE(auth_note, ''),
 			first_seen_at,
-			last_seen_at
+			last_seen_at,
+			COALESCE(parent_device_id, ''),
+			COALESCE(physical_label, ''),
+			COALESCE(imei, ''),
+			COALESCE(seizure_location, ''),
+			COALESCE(custody_officer, ''),
+			COALESCE(custody_note, ''),
+			COALESCE(custody_updated_by, ''),
+			COALESCE(custody_updated_at, 0)
 		FROM case_devices
 		WHERE case_id = ?
 		ORDER BY os_type, device_name, device_id
@@ -990,6 +1328,14 @@ func (s *Store) ListCaseDevices(ctx context.Context, caseID string) ([]model.Cas
 			&item.AuthNote,
 			&item.FirstSeenAt,
 			&item.LastSeenAt,
+			&item.ParentDeviceID,
+			&item.PhysicalLabel,
+			&item.IMEI,
+			&item.SeizureLocation,
+			&item.CustodyOfficer,
+			&item.CustodyNote,
+			&item.CustodyUpdatedBy,
+			&item.CustodyUpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan case device: %w", err)
 		}
@@ -1000,7 +1346,55 @@ func (s *Store) ListCaseDevices(ctx context.Context, caseID string) ([]model.Cas
 		return nil, fmt.Errorf("iterate case devices: %w", err)
 	}
 	if out == nil {
-		out = []model.CaseDevice{}
+		return []model.CaseDevice{}, nil
+	}
+	rows.Close()
+
+	photos, err := s.ListDevicePhotos(ctx, caseID, "")
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		for _, p := range photos {
+			if p.DeviceID == out[i].DeviceID {
+				out[i].Photos = append(out[i].Photos, p)
+			}
+		}
+	}
+	return out, nil
+}
+
+// FindOpenCasesByIdentifier 返回其他未结案件（status=open）中同一设备标识的登记记录，按最近出现时间倒序。
+func (s *Store) FindOpenCasesByIdentifier(ctx context.Context, identifier, excludeCaseID string) ([]model.DeviceCaseRef, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			c.case_id,
+			COALESCE(c.case_no, ''),
+			COALESCE(c.title, ''),
+			d.device_id,
+			COALESCE(d.device_name, ''),
+			d.os_type,
+			d.last_seen_at
+		FROM case_devices d
+		JOIN cases c ON c.case_id = d.case_id
+		WHERE d.identifier = ? AND d.case_id <> ? AND c.status = 'open'
+		ORDER BY d.last_seen_at DESC, c.case_id
+	`, identifier, excludeCaseID)
+	if err != nil {
+		return nil, fmt.Errorf("query cases by device identifier: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.DeviceCaseRef{}
+	for rows.Next() {
+		item := model.DeviceCaseRef{Identifier: identifier}
+		if err := rows.Scan(&item.CaseID, &item.CaseNo, &item.Title, &item.DeviceID, &item.DeviceName, &item.OSType, &item.LastSeenAt); err != nil {
+			return nil, fmt.Errorf("scan case by device identifier: %w", err)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate cases by device identifier: %w", err)
 	}
 	return out, nil
 }
@@ -1046,3 +1440,1022 @@ func nullIfEmpty(s string) any {
 	}
 	return s
 }
+
+// 0 按 NULL 写入（例如未拆分证据的分片序号）。
+func nullIfZero(n int) any {
+	if n == 0 {
+		return nil
+	}
+	return n
+}
+
+// ListArtifactPayloadsWithIDByType 返回案件内指定类型证据的 artifact_id + payload_json。
+func (s *Store) ListArtifactPayloadsWithIDByType(ctx context.Context, caseID, artifactType string) ([]model.ArtifactPayload, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT artifact_id, device_id, COALESCE(payload_json, ''), payload_storage, snapshot_path, sha256
+		FROM artifacts
+		WHERE case_id = ? AND artifact_type = ?
+		ORDER BY collected_at ASC, COALESCE(part_group, artifact_id) ASC, COALESCE(part_no, 0) ASC, artifact_id ASC
+	`, caseID, artifactType)
+	if err != nil {
+		return nil, fmt.Errorf("query artifact payloads: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.ArtifactPayload{}
+	for rows.Next() {
+		var item model.ArtifactPayload
+		var raw, storage, snapshotPath, sum string
+		if err := rows.Scan(&item.ArtifactID, &item.DeviceID, &raw, &storage, &snapshotPath, &sum); err != nil {
+			return nil, fmt.Errorf("scan artifact payload: %w", err)
+		}
+		if storage == payloadStorageSnapshot {
+			b, err := loadSnapshotPayload(snapshotPath, sum)
+			if err != nil {
+				return nil, err
+			}
+			raw = string(b)
+		}
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		item.PayloadJSON = json.RawMessage(raw)
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate artifact payloads: %w", err)
+	}
+	return out, nil
+}
+
+// ReplaceAddressClusters 用新的聚类结果整体覆盖案件原有聚类（含 rule_hits.cluster_id）。
+func (s *Store) ReplaceAddressClusters(ctx context.Context, caseID string, clusters []model.AddressCluster) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx replace clusters: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, `UPDATE rule_hits SET cluster_id = NULL WHERE case_id = ? AND cluster_id IS NOT NULL`, caseID); err != nil {
+		return fmt.Errorf("reset hit clusters: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, `DELETE FROM address_clusters WHERE case_id = ?`, caseID); err != nil {
+		return fmt.Errorf("delete address clusters: %w", err)
+	}
+
+	now := time.Now().Unix()
+	for _, c := range clusters {
+		addrs, _ := json.Marshal(c.Addresses)
+		reasons, _ := json.Marshal(c.Reasons)
+		if _, err = tx.ExecContext(ctx, `
+			INSERT INTO address_clusters(
+				cluster_id, case_id, size, score, addresses_json, reasons_json,
+				first_seen_at, last_seen_at, created_at
+			)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, c.ClusterID, caseID, c.Size, c.Score, string(addrs), string(reasons), c.FirstSeenAt, c.LastSeenAt, now); err != nil {
+			return fmt.Errorf("insert address cluster %s: %w", c.ClusterID, err)
+		}
+		for _, hitID := range c.HitIDs {
+			if _, err = tx.ExecContext(ctx, `UPDATE rule_hits SET cluster_id = ? WHERE case_id = ? AND hit_id = ?`, c.ClusterID, caseID, hitID); err != nil {
+				return fmt.Errorf("update hit cluster %s: %w", hitID, err)
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit replace clusters: %w", err)
+	}
+	return nil
+}
+
+// ListAddressClusters 返回案件地址聚类（按规模降序），附带成员命中 ID。
+func (s *Store) ListAddressClusters(ctx context.Context, caseID string) ([]model.AddressCluster, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			c.cluster_id, c.case_id, c.size, c.score, c.addresses_json,
+			COALESCE(c.reasons_json, '{}'),
+			COALESCE(c.first_seen_at, 0), COALESCE(c.last_seen_at, 0), c.created_at,
+			COALESCE(GROUP_CONCAT(h.hit_id, ','), '')
+		FROM address_clusters c
+		LEFT JOIN rule_hits h ON h.case_id = c.case_id AND h.cluster_id = c.cluster_id
+		WHERE c.case_id = ?
+		GROUP BY c.cluster_id
+		ORDER BY c.size DESC, c.score DESC, c.cluster_id
+	`, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("query address clusters: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.AddressCluster{}
+	for rows.Next() {
+		var item model.AddressCluster
+		var addrs, reasons, hitIDs string
+		if err := rows.Scan(
+			&item.ClusterID,
+			&item.CaseID,
+			&item.Size,
+			&item.Score,
+			&addrs,
+			&reasons,
+			&item.FirstSeenAt,
+			&item.LastSeenAt,
+			&item.CreatedAt,
+			&hitIDs,
+		); err != nil {
+			return nil, fmt.Errorf("scan address cluster: %w", err)
+		}
+		_ = json.Unmarshal([]byte(addrs), &item.Addresses)
+		_ = json.Unmarshal([]byte(reasons), &item.Reasons)
+		item.HitIDs = []string{}
+		for _, id := range strings.Split(hitIDs, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				item.HitIDs = append(item.HitIDs, id)
+			}
+		}
+		sort.Strings(item.HitIDs)
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate address clusters: %w", err)
+	}
+	return out, nil
+}
+
+// SaveNameResolutions 追加保存域名解析记录（同一域名多次解析保留历史）。
+func (s *Store) SaveNameResolutions(ctx context.Context, items []model.NameResolution) (err error) {
+	if len(items) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx save name resolutions: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	now := time.Now().Unix()
+	for i := range items {
+		r := &items[i]
+		if r.ResolutionID == "" {
+			r.ResolutionID = id.New("nres")
+		}
+		if r.ResolvedAt == 0 {
+			r.ResolvedAt = now
+		}
+		artifacts, _ := json.Marshal(r.ArtifactIDs)
+		if _, err = tx.ExecContext(ctx, `
+			INSERT INTO name_resolutions(
+				resolution_id, case_id, device_id, name, name_service, namehash, status,
+				resolved_address, resolver, registry, block_number, block_timestamp, rpc_url,
+				error_message, artifact_ids_json, first_seen_at, last_seen_at, resolved_at
+			)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`,
+			r.ResolutionID, r.CaseID, nullIfEmpty(r.DeviceID), r.Name, r.Service, r.Namehash, r.Status,
+			nullIfEmpty(r.ResolvedAddress), nullIfEmpty(r.Resolver), nullIfEmpty(r.Registry), r.BlockNumber, r.BlockTimestamp, nullIfEmpty(r.RPCURL),
+			nullIfEmpty(r.Error), string(artifacts), r.FirstSeenAt, r.LastSeenAt, r.ResolvedAt,
+		); err != nil {
+			return fmt.Errorf("insert name resolution %s: %w", r.Name, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit save name resolutions: %w", err)
+	}
+	return nil
+}
+
+// ListNameResolutions 返回案件内的域名解析记录（最新在前）。
+func (s *Store) ListNameResolutions(ctx context.Context, caseID string) ([]model.NameResolution, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			resolution_id, case_id, COALESCE(device_id, ''), name, name_service, namehash, status,
+			COALESCE(resolved_address, ''), COALESCE(resolver, ''), COALESCE(registry, ''),
+			COALESCE(block_number, 0), COALESCE(block_timestamp, 0), COALESCE(rpc_url, ''),
+			COALESCE(error_message, ''), COALESCE(artifact_ids_json, '[]'),
+			COALESCE(first_seen_at, 0), COALESCE(last_seen_at, 0), resolved_at
+		FROM name_resolutions
+		WHERE case_id = ?
+		ORDER BY resolved_at DESC, name, resolution_id
+	`, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("query name resolutions: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.NameResolution{}
+	for rows.Next() {
+		var item model.NameResolution
+		var artifacts string
+		if err := rows.Scan(
+			&item.ResolutionID,
+			&item.CaseID,
+			&item.DeviceID,
+			&item.Name,
+			&item.Service,
+			&item.Namehash,
+			&item.Status,
+			&item.ResolvedAddress,
+			&item.Resolver,
+			&item.Registry,
+			&item.BlockNumber,
+			&item.BlockTimestamp,
+			&item.RPCURL,
+			&item.Error,
+			&artifacts,
+			&item.FirstSeenAt,
+			&item.LastSeenAt,
+			&item.ResolvedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan name resolution: %w", err)
+		}
+		_ = json.Unmarshal([]byte(artifacts), &item.ArtifactIDs)
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate name resolutions: %w", err)
+	}
+	return out, nil
+}
+
+// UpsertCaseAddresses 合并写入案件地址簿：
+// - 已存在的地址只扩展首次/末次出现时间
+// - 抽取来源（extracted）的地址若后续由域名解析得到，则升级为域名来源并补充标签
+func (s *Store) UpsertCaseAddresses(ctx context.Context, items []model.CaseAddress) (err error) {
+	if len(items) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx upsert case addresses: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	now := time.Now().Unix()
+	for _, a := range items {
+		addr := strings.TrimSpace(a.Address)
+		if addr == "" {
+			continue
+		}
+		first, last := a.FirstSeenAt, a.LastSeenAt
+		if first == 0 {
+			first = now
+		}
+		if last == 0 {
+			last = first
+		}
+		var detail any
+		if len(a.Detail) > 0 {
+			detail = string(a.Detail)
+		}
+		if _, err = tx.ExecContext(ctx, `
+			INSERT INTO case_addresses(case_id, address, chain, source, label, first_seen_at, last_seen_at, detail_json)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(case_id, address) DO UPDATE SET
+				chain = COALESCE(NULLIF(case_addresses.chain, ''), excluded.chain),
+				source = CASE WHEN case_addresses.source = 'extracted' THEN excluded.source ELSE case_addresses.source END,
+				label = COALESCE(NULLIF(excluded.label, ''), case_addresses.label),
+				first_seen_at = MIN(case_addresses.first_seen_at, excluded.first_seen_at),
+				last_seen_at = MAX(case_addresses.last_seen_at, excluded.last_seen_at),
+				detail_json = COALESCE(excluded.detail_json, case_addresses.detail_json)
+		`, a.CaseID, addr, nullIfEmpty(a.Chain), a.Source, nullIfEmpty(a.Label), first, last, detail); err != nil {
+			return fmt.Errorf("upsert case address %s: %w", addr, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit upsert case addresses: %w", err)
+	}
+	return nil
+}
+
+// ListCaseAddresses 返回案件地址簿（按首次出现时间排序）。
+func (s *Store) ListCaseAddresses(ctx context.Context, caseID string) ([]model.CaseAddress, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT case_id, address, COALESCE(chain, ''), source, COALESCE(label, ''),
+		       first_seen_at, last_seen_at, COALESCE(detail_json, '')
+		FROM case_addresses
+		WHERE case_id = ?
+		ORDER BY first_seen_at, address
+	`, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("query case addresses: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.CaseAddress{}
+	for rows.Next() {
+		var item model.CaseAddress
+		var detail string
+		if err := rows.Scan(
+			&item.CaseID,
+			&item.Address,
+			&item.Chain,
+			&item.Source,
+			&item.Label,
+			&item.FirstSeenAt,
+			&item.LastSeenAt,
+			&detail,
+		); err != nil {
+			return nil, fmt.Errorf("scan case address: %w", err)
+		}
+		if detail != "" {
+			item.Detail = json.RawMessage(detail)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate case addresses: %w", err)
+	}
+	return out, nil
+}
+
+// AddRedaction 新增一条遮盖标记；同一目标+位置已存在时返回已有记录（幂等）。
+func (s *Store) AddRedaction(ctx context.Context, r model.Redaction) (*model.Redaction, error) {
+	if r.RedactionID == "" {
+		r.RedactionID = id.New("rdc")
+	}
+	if r.CreatedAt == 0 {
+		r.CreatedAt = time.Now().Unix()
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO redactions(
+			redaction_id, case_id, target_type, target_id, pointer, reason, operator, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, r.RedactionID, r.CaseID, r.TargetType, r.TargetID, r.Pointer, nullIfEmpty(r.Reason), nullIfEmpty(r.Operator), r.CreatedAt); err != nil {
+		return nil, fmt.Errorf("insert redaction: %w", err)
+	}
+
+	var out model.Redaction
+	err := s.db.QueryRowContext(ctx, `
+		SELECT redaction_id, case_id, target_type, target_id, pointer,
+			COALESCE(reason, ''), COALESCE(operator, ''), created_at
+		FROM redactions
+		WHERE case_id = ? AND target_type = ? AND target_id = ? AND pointer = ?
+	`, r.CaseID, r.TargetType, r.TargetID, r.Pointer).Scan(
+		&out.RedactionID, &out.CaseID, &out.TargetType, &out.TargetID, &out.Pointer,
+		&out.Reason, &out.Operator, &out.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query redaction: %w", err)
+	}
+	return &out, nil
+}
+
+// ListRedactions 返回案件全部遮盖标记，按目标与位置排序。
+func (s *Store) ListRedactions(ctx context.Context, caseID string) ([]model.Redaction, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT redaction_id, case_id, target_type, target_id, pointer,
+			COALESCE(reason, ''), COALESCE(operator, ''), created_at
+		FROM redactions
+		WHERE case_id = ?
+		ORDER BY target_type, target_id, pointer
+	`, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("query redactions: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.Redaction{}
+	for rows.Next() {
+		var item model.Redaction
+		if err := rows.Scan(
+			&item.RedactionID,
+			&item.CaseID,
+			&item.TargetType,
+			&item.TargetID,
+			&item.Pointer,
+			&item.Reason,
+			&item.Operator,
+			&item.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan redaction: %w", err)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate redactions: %w", err)
+	}
+	return out, nil
+}
+
+// DeleteRedaction 删除一条遮盖标记；返回是否存在。
+func (s *Store) DeleteRedaction(ctx context.Context, caseID, redactionID string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM redactions WHERE case_id = ? AND redaction_id = ?`, caseID, redactionID)
+	if err != nil {
+		return false, fmt.Errorf("delete redaction: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete redaction: %w", err)
+	}
+	return n > 0, nil
+}
+
+// payload 分层存储（见 015_payload_offload.sql）。
+const (
+	payloadStorageInline   = "inline"
+	payloadStorageSnapshot = "snapshot"
+
+	// SchemaKeyPayloadInlineMax 是 payload 内联上限（字节）的 schema_meta 键；0 表示不启用分层存储。
+	SchemaKeyPayloadInlineMax = "payload_inline_max_bytes"
+)
+
+// PayloadInlineLimit 返回 payload 内联上限（字节，0 表示全部内联）。
+func (s *Store) PayloadInlineLimit(ctx context.Context) (int64, error) {
+	v, err := s.GetSchemaMetaValue(ctx, SchemaKeyPayloadInlineMax)
+	if err != nil {
+		return 0, err
+	}
+	if strings.TrimSpace(v) == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid schema_meta %s: %q", SchemaKeyPayloadInlineMax, v)
+	}
+	return n, nil
+}
+
+// SetPayloadInlineLimit 设置 payload 内联上限（只影响之后写入的证据；存量数据用 OffloadArtifactPayloads）。
+func (s *Store) SetPayloadInlineLimit(ctx context.Context, n int64) error {
+	if n < 0 {
+		return fmt.Errorf("payload inline limit must be >= 0")
+	}
+	return s.UpsertSchemaMetaValue(ctx, SchemaKeyPayloadInlineMax, strconv.FormatInt(n, 10))
+}
+
+// PayloadOffloadStats 是存量 payload 外置的统计。
+type PayloadOffloadStats struct {
+	Scanned    int   `json:"scanned"`
+	Offloaded  int   `json:"offloaded"`
+	Skipped    int   `json:"skipped"` // payload 与快照不一致（例如 zip 快照/导入原始报告）或快照缺失/被改动
+	BytesFreed int64 `json:"bytes_freed"`
+}
+
+// OffloadArtifactPayloads 把存量证据中超过 threshold 的内联 payload 改为“摘要 + 快照指针”。
+//
+// 只处理 payload 与快照文件逐字节一致、且快照文件当前哈希与入库 sha256 一致的证据，
+// 保证外置后随时可以从快照文件还原出原 payload。数据库文件需要 VACUUM 才会真正变小。
+func (s *Store) OffloadArtifactPayloads(ctx context.Context, threshold int64) (*PayloadOffloadStats, error) {
+	if threshold <= 0 {
+		return nil, fmt.Errorf("offload threshold must be > 0")
+	}
+	// 单连接：先收集候选 ID，再逐条读取/更新，避免游标未关闭时写入。
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT artifact_id
+		FROM artifacts
+		WHERE payload_storage = 'inline' AND length(payload_json) > ?
+		ORDER BY artifact_id
+	`, threshold)
+	if err != nil {
+		return nil, fmt.Errorf("query offload candidates: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan offload candidate: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("iterate offload candidates: %w", err)
+	}
+	rows.Close()
+
+	stats := &PayloadOffloadStats{}
+	for _, artifactID := range ids {
+		if err := ctx.Err(); err != nil {
+			return stats, err
+		}
+		stats.Scanned++
+		var raw, snapshotPath, sum, compression string
+		if err := s.db.QueryRowContext(ctx, `
+			SELECT payload_json, snapshot_path, sha256, snapshot_compression FROM artifacts WHERE artifact_id = ?
+		`, artifactID).Scan(&raw, &snapshotPath, &sum, &compression); err != nil {
+			return stats, fmt.Errorf("query artifact payload %s: %w", artifactID, err)
+		}
+		if !canOffloadPayload([]byte(raw), snapshotPath, sum, compression) {
+			stats.Skipped++
+			continue
+		}
+		if _, err := loadSnapshotPayload(snapshotPath, sum); err != nil {
+			stats.Skipped++
+			continue
+		}
+		summary := payloadSummary([]byte(raw))
+		if _, err := s.db.ExecContext(ctx, `
+			UPDATE artifacts
+			SET payload_json = ?, payload_storage = 'snapshot', payload_bytes = ?
+			WHERE artifact_id = ? AND payload_storage = 'inline'
+		`, summary, len(raw), artifactID); err != nil {
+			return stats, fmt.Errorf("offload artifact payload %s: %w", artifactID, err)
+		}
+		stats.Offloaded++
+		stats.BytesFreed += int64(len(raw) - len(summary))
+	}
+	return stats, nil
+}
+
+// canOffloadPayload 判断 payload 能否只保留快照指针：必须与快照文件（解压后）逐字节一致。
+// 未压缩快照直接比较 sha256；压缩快照需要读取文件解压后比较。
+func canOffloadPayload(raw []byte, snapshotPath, snapshotSHA256, compression string) bool {
+	if len(raw) == 0 {
+		return false
+	}
+	if compression != snapshot.CompressionGzip {
+		return strings.EqualFold(hash.Bytes(raw), strings.TrimSpace(snapshotSHA256))
+	}
+	stored, err := loadSnapshotPayload(snapshotPath, snapshotSHA256)
+	return err == nil && bytes.Equal(stored, raw)
+}
+
+// payloadSummary 生成外置 payload 在库中保留的摘要（数组 payload 额外记录条数）。
+func payloadSummary(raw []byte) string {
+	m := map[string]any{
+		"payload_storage": payloadStorageSnapshot,
+		"bytes":           len(raw),
+	}
+	var rows []json.RawMessage
+	if err := json.Unmarshal(raw, &rows); err == nil {
+		m["records"] = len(rows)
+	}
+	b, _ := json.Marshal(m)
+	return string(b)
+}
+
+// loadSnapshotPayload 从快照文件读取外置 payload：先对存储字节复核 sha256（快照被改动时拒绝返回），再透明解压。
+func loadSnapshotPayload(path, expectedSHA256 string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read offloaded payload %s: %w", path, err)
+	}
+	if !strings.EqualFold(hash.Bytes(raw), strings.TrimSpace(expectedSHA256)) {
+		return nil, fmt.Errorf("offloaded payload sha256 mismatch: %s", path)
+	}
+	return snapshot.Decompress(raw)
+}
+
+// 案件存储配额（见 017_case_storage_quota.sql）。
+const (
+	SchemaKeyCaseQuotaBytes = "case_quota_bytes"
+	SchemaKeyCaseQuotaMode  = "case_quota_mode"
+
+	CaseQuotaModeWarn  = "warn"
+	CaseQuotaModeBlock = "block"
+)
+
+// caseStorageTables 是按 case_id 统计行数的表（hit_artifact_links 随 rule_hits 级联，不单独统计）。
+var caseStorageTables = []string{
+	"case_devices", "artifacts", "rule_hits", "audit_logs", "reports", "precheck_results",
+	"address_clusters", "name_resolutions", "case_addresses", "redactions", "case_attachments", "case_assignments",
+}
+
+// GetCaseStorageCounts 统计案件在库中的占用（证据字节、payload 字节、各表行数）；案件不存在时返回 nil。
+// 报告文件大小需要读取磁盘，由调用方（services/casestorage）补齐。
+func (s *Store) GetCaseStorageCounts(ctx context.Context, caseID string) (*model.CaseStorageUsage, error) {
+	var exists int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM cases WHERE case_id = ?`, caseID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("query case: %w", err)
+	}
+	if exists == 0 {
+		return nil, nil
+	}
+	out := &model.CaseStorageUsage{CaseID: caseID, DBRows: map[string]int64{}}
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(1), COALESCE(SUM(size_bytes), 0), COALESCE(SUM(length(payload_json)), 0)
+		FROM artifacts
+		WHERE case_id = ?
+	`, caseID).Scan(&out.ArtifactCount, &out.EvidenceBytes, &out.PayloadBytes); err != nil {
+		return nil, fmt.Errorf("query artifact storage: %w", err)
+	}
+	for _, table := range caseStorageTables {
+		var n int64
+		// 表名来自上面的固定列表，不接受外部输入。
+		if err := s.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM `+table+` WHERE case_id = ?`, caseID).Scan(&n); err != nil {
+			return nil, fmt.Errorf("count %s rows: %w", table, err)
+		}
+		out.DBRows[table] = n
+	}
+	out.ReportCount = out.DBRows["reports"]
+	return out, nil
+}
+
+// GetCaseQuota 返回案件生效的配额：案件级配置优先，否则使用全局默认；limit=0 表示不限制。
+func (s *Store) GetCaseQuota(ctx context.Context, caseID string) (model.CaseStorageQuota, error) {
+	q := model.CaseStorageQuota{Source: "default", Mode: CaseQuotaModeWarn}
+	var perCase sql.NullInt64
+	err := s.db.QueryRowContext(ctx, `SELECT storage_quota_bytes FROM cases WHERE case_id = ?`, caseID).Scan(&perCase)
+	if err != nil && err != sql.ErrNoRows {
+		return q, fmt.Errorf("query case quota: %w", err)
+	}
+	if perCase.Valid {
+		q.LimitBytes, q.Source = perCase.Int64, "case"
+	} else {
+		v, err := s.GetSchemaMetaValue(ctx, SchemaKeyCaseQuotaBytes)
+		if err != nil {
+			return q, err
+		}
+		if strings.TrimSpace(v) != "" {
+			n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+			if err != nil || n < 0 {
+				return q, fmt.Errorf("invalid schema_meta %s: %q", SchemaKeyCaseQuotaBytes, v)
+			}
+			q.LimitBytes = n
+		}
+	}
+	mode, err := s.GetSchemaMetaValue(ctx, SchemaKeyCaseQuotaMode)
+	if err != nil {
+		return q, err
+	}
+	if strings.TrimSpace(mode) == CaseQuotaModeBlock {
+		q.Mode = CaseQuotaModeBlock
+	}
+	return q, nil
+}
+
+// SetCaseQuota 设置案件级配额；limit 为 nil 时清除案件级配置（回退到全局默认）。
+func (s *Store) SetCaseQuota(ctx context.Context, caseID string, limit *int64) (bool, error) {
+	var v any
+	if limit != nil {
+		if *limit < 0 {
+			return false, fmt.Errorf("quota must be >= 0")
+		}
+		v = *limit
+	}
+	res, err := s.db.ExecContext(ctx, `UPDATE cases SET storage_quota_bytes = ?, updated_at = ? WHERE case_id = ?`, v, time.Now().Unix(), caseID)
+	if err != nil {
+		return false, fmt.Errorf("update case quota: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// SetDefaultCaseQuota 设置全局默认配额（0 表示不限制）与超额策略（warn|block）。
+func (s *Store) SetDefaultCaseQuota(ctx context.Context, limit int64, mode string) error {
+	if limit < 0 {
+		return fmt.Errorf("quota must be >= 0")
+	}
+	if mode != CaseQuotaModeWarn && mode != CaseQuotaModeBlock {
+		return fmt.Errorf("invalid quota mode %q (warn|block)", mode)
+	}
+	if err := s.UpsertSchemaMetaValue(ctx, SchemaKeyCaseQuotaBytes, strconv.FormatInt(limit, 10)); err != nil {
+		return err
+	}
+	return s.UpsertSchemaMetaValue(ctx, SchemaKeyCaseQuotaMode, mode)
+}
+
+// ListRuleHitStats 按 (hit_type, rule_id) 汇总全部案件的命中数、涉及案件数与最近命中时间。
+// q 为空时统计全部命中；指定 BundleVersion / 时间窗口时只统计对应分段；
+// MergeHitTypes 时按 rule_id 汇总（涉及案件数跨类型去重）。
+func (s *Store) ListRuleHitStats(ctx context.Context, q model.RuleHitStatsQuery) ([]model.RuleHitStat, error) {
+	where, args := ruleHitStatsWhere(q)
+	hitType := ruleHitStatsTypeExpr(q)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+hitType+` AS hit_type, h.rule_id, COUNT(1), COUNT(DISTINCT h.case_id), COALESCE(MAX(h.created_at), 0)
+		FROM rule_hits h
+		LEFT JOIN rule_bundles b ON b.bundle_id = h.rule_bundle_id
+		`+where+`
+		GROUP BY `+hitType+`, h.rule_id
+		ORDER BY `+hitType+`, h.rule_id
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query rule hit stats: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.RuleHitStat{}
+	for rows.Next() {
+		var item model.RuleHitStat
+		if err := rows.Scan(&item.HitType, &item.RuleID, &item.HitCount, &item.CaseCount, &item.LastHitAt); err != nil {
+			return nil, fmt.Errorf("scan rule hit stat: %w", err)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rule hit stats: %w", err)
+	}
+	return out, nil
+}
+
+// ListRuleHitBundleStats 按 (hit_type, rule_id, 规则包) 分段汇总命中统计。
+//
+// 规则包取 rule_hits.rule_bundle_id 关联的 rule_bundles 记录，缺失时按命中的 rule_version 归组；
+// 同一规则的各分段按规则包加载时间（缺失时取首次命中时间）升序排列，便于绘制版本趋势。
+// q.MergeHitTypes 为 true 时只按 (rule_id, 规则包) 分段，各命中类型合并计数。
+func (s *Store) ListRuleHitBundleStats(ctx context.Context, q model.RuleHitStatsQuery) ([]model.RuleHitBundleStat, error) {
+	where, args := ruleHitStatsWhere(q)
+	hitType := ruleHitStatsTypeExpr(q)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+hitType+`, h.rule_id, COALESCE(h.rule_bundle_id, ''),
+			COALESCE(MAX(b.bundle_type), ''), COALESCE(b.bundle_version, h.rule_version, '') AS version,
+			COALESCE(MAX(b.loaded_at), 0),
+			COUNT(1), COUNT(DISTINCT h.case_id), COALESCE(MIN(h.created_at), 0), COALESCE(MAX(h.created_at), 0)
+		FROM rule_hits h
+		LEFT JOIN rule_bundles b ON b.bundle_id = h.rule_bundle_id
+		`+where+`
+		GROUP BY `+hitType+`, h.rule_id, COALESCE(h.rule_bundle_id, ''), version
+		ORDER BY `+hitType+`, h.rule_id, COALESCE(MAX(b.loaded_at), MIN(h.created_at)), version
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query rule hit bundle stats: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.RuleHitBundleStat{}
+	for rows.Next() {
+		var item model.RuleHitBundleStat
+		if err := rows.Scan(&item.HitType, &item.RuleID, &item.BundleID, &item.BundleType, &item.BundleVersion,
+			&item.BundleLoadedAt, &item.HitCount, &item.CaseCount, &item.FirstHitAt, &item.LastHitAt); err != nil {
+			return nil, fmt.Errorf("scan rule hit bundle stat: %w", err)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate rule hit bundle stats: %w", err)
+	}
+	return out, nil
+}
+
+// ruleHitStatsTypeExpr 返回统计结果中 hit_type 列的表达式（合并命中类型时为空串常量）。
+func ruleHitStatsTypeExpr(q model.RuleHitStatsQuery) string {
+	if q.MergeHitTypes {
+		return "''"
+	}
+	return "h.hit_type"
+}
+
+// ruleHitStatsWhere 生成规则命中统计的筛选条件（rule_hits 别名 h，rule_bundles 别名 b）。
+func ruleHitStatsWhere(q model.RuleHitStatsQuery) (string, []any) {
+	var conds []string
+	var args []any
+	if v := strings.TrimSpace(q.HitType); v != "" {
+		conds = append(conds, "h.hit_type = ?")
+		args = append(args, v)
+	}
+	if len(q.HitTypes) > 0 {
+		conds = append(conds, "h.hit_type IN ("+strings.TrimSuffix(strings.Repeat("?,", len(q.HitTypes)), ",")+")")
+		for _, v := range q.HitTypes {
+			args = append(args, v)
+		}
+	}
+	if v := strings.TrimSpace(q.RuleID); v != "" {
+		conds = append(conds, "h.rule_id = ?")
+		args = append(args, v)
+	}
+	if v := strings.TrimSpace(q.BundleVersion); v != "" {
+		conds = append(conds, "COALESCE(b.bundle_version, h.rule_version, '') = ?")
+		args = append(args, v)
+	}
+	if q.Since > 0 {
+		conds = append(conds, "h.created_at >= ?")
+		args = append(args, q.Since)
+	}
+	if q.Until > 0 {
+		conds = append(conds, "h.created_at <= ?")
+		args = append(args, q.Until)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), args
+}
+
+// SaveCaseAttachment 写入一条案件附件记录（文件由调用方落盘并计算哈希）。
+func (s *Store) SaveCaseAttachment(ctx context.Context, a model.CaseAttachment) error {
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO case_attachments(
+			attachment_id, case_id, kind, original_name, file_path, sha256, size_bytes, mime_type,
+			authorization_order, agency, uploaded_by, uploaded_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, a.AttachmentID, a.CaseID, a.Kind, a.OriginalName, a.FilePath, a.SHA256, a.SizeBytes, a.MimeType,
+		nullIfEmpty(a.AuthorizationOrder), nullIfEmpty(a.Agency), nullIfEmpty(a.UploadedBy), a.UploadedAt); err != nil {
+		return fmt.Errorf("insert case attachment: %w", err)
+	}
+	return nil
+}
+
+// ListCaseAttachments 返回案件附件（kind 为空时返回全部），最新上传的在前。
+func (s *Store) ListCaseAttachments(ctx context.Context, caseID, kind string) ([]model.CaseAttachment, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT attachment_id, case_id, kind, original_name, file_path, sha256, size_bytes, mime_type,
+			COALESCE(authorization_order, ''), COALESCE(agency, ''), COALESCE(uploaded_by, ''), uploaded_at
+		FROM case_attachments
+		WHERE case_id = ? AND (? = '' OR kind = ?)
+		ORDER BY uploaded_at DESC, attachment_id DESC
+	`, caseID, kind, kind)
+	if err != nil {
+		return nil, fmt.Errorf("query case attachments: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.CaseAttachment{}
+	for rows.Next() {
+		var item model.CaseAttachment
+		if err := rows.Scan(
+			&item.AttachmentID,
+			&item.CaseID,
+			&item.Kind,
+			&item.OriginalName,
+			&item.FilePath,
+			&item.SHA256,
+			&item.SizeBytes,
+			&item.MimeType,
+			&item.AuthorizationOrder,
+			&item.Agency,
+			&item.UploadedBy,
+			&item.UploadedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan case attachment: %w", err)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate case attachments: %w", err)
+	}
+	return out, nil
+}
+
+// HandoverCase 把案件负责人改为 to，并在同一事务内写入交接记录；案件不存在时返回 nil。
+func (s *Store) HandoverCase(ctx context.Context, caseID, to, note, assignedBy string) (*model.CaseAssignment, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin handover tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var from string
+	if err := tx.QueryRowContext(ctx, `SELECT COALESCE(owner, '') FROM cases WHERE case_id = ?`, caseID).Scan(&from); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("query case owner: %w", err)
+	}
+
+	a := model.CaseAssignment{
+		AssignmentID: id.New("asg"),
+		CaseID:       caseID,
+		FromOperator: from,
+		ToOperator:   to,
+		Note:         note,
+		AssignedBy:   assignedBy,
+		AssignedAt:   time.Now().Unix(),
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE cases SET owner = ?, updated_at = ? WHERE case_id = ?`, to, a.AssignedAt, caseID); err != nil {
+		return nil, fmt.Errorf("update case owner: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO case_assignments(assignment_id, case_id, from_operator, to_operator, note, assigned_by, assigned_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, a.AssignmentID, a.CaseID, nullIfEmpty(a.FromOperator), a.ToOperator, nullIfEmpty(a.Note), nullIfEmpty(a.AssignedBy), a.AssignedAt); err != nil {
+		return nil, fmt.Errorf("insert case assignment: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit handover tx: %w", err)
+	}
+	return &a, nil
+}
+
+// ListCaseAssignments 返回交接记录（最新在前）：
+// - caseID 非空：该案件的负责人变更历史
+// - operator 非空：该操作员交出或接收的全部交接（交接班日志）
+func (s *Store) ListCaseAssignments(ctx context.Context, caseID, operator string, limit int) ([]model.CaseAssignment, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 500
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT assignment_id, case_id, COALESCE(from_operator, ''), to_operator,
+			COALESCE(note, ''), COALESCE(assigned_by, ''), assigned_at
+		FROM case_assignments
+		WHERE (? = '' OR case_id = ?)
+			AND (? = '' OR from_operator = ? OR to_operator = ?)
+		ORDER BY assigned_at DESC, rowid DESC
+		LIMIT ?
+	`, caseID, caseID, operator, operator, operator, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query case assignments: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.CaseAssignment{}
+	for rows.Next() {
+		var item model.CaseAssignment
+		if err := rows.Scan(
+			&item.AssignmentID,
+			&item.CaseID,
+			&item.FromOperator,
+			&item.ToOperator,
+			&item.Note,
+			&item.AssignedBy,
+			&item.AssignedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan case assignment: %w", err)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate case assignments: %w", err)
+	}
+	return out, nil
+}
+
+// SetDeviceParent 把设备标记为 parentDeviceID 的子设备（VM 镜像二次扫描）；父设备须属于同一案件。
+func (s *Store) SetDeviceParent(ctx context.Context, caseID, deviceID, parentDeviceID string) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE case_devices SET parent_device_id = ?, updated_at = ?
+		WHERE case_id = ? AND device_id = ?
+		  AND EXISTS (SELECT 1 FROM case_devices p WHERE p.case_id = ? AND p.device_id = ?)
+	`, parentDeviceID, time.Now().Unix(), caseID, deviceID, caseID, parentDeviceID)
+	if err != nil {
+		return fmt.Errorf("set device parent: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("set device parent: device %s or parent %s not found in case %s", deviceID, parentDeviceID, caseID)
+	}
+	return nil
+}
+
+// AddWatchlistTerm 新增一条案件关注词；同类型同词已存在时返回已有记录（幂等）。
+func (s *Store) AddWatchlistTerm(ctx context.Context, t model.WatchlistTerm) (*model.WatchlistTerm, error) {
+	if t.TermID == "" {
+		t.TermID = id.New("wl")
+	}
+	if t.CreatedAt == 0 {
+		t.CreatedAt = time.Now().Unix()
+	}
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT OR IGNORE INTO case_watchlist(term_id, case_id, term, term_type, note, operator, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, t.TermID, t.CaseID, t.Term, t.TermType, nullIfEmpty(t.Note), nullIfEmpty(t.Operator), t.CreatedAt); err != nil {
+		return nil, fmt.Errorf("insert watchlist term: %w", err)
+	}
+
+	var out model.WatchlistTerm
+	err := s.db.QueryRowContext(ctx, `
+		SELECT term_id, case_id, term, term_type, COALESCE(note, ''), COALESCE(operator, ''), created_at
+		FROM case_watchlist
+		WHERE case_id = ? AND term_type = ? AND term = ?
+	`, t.CaseID, t.TermType, t.Term).Scan(
+		&out.TermID, &out.CaseID, &out.Term, &out.TermType, &out.Note, &out.Operator, &out.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query watchlist term: %w", err)
+	}
+	return &out, nil
+}
+
+// ListWatchlistTerms 返回案件全部关注词，按类型与词排序。
+func (s *Store) ListWatchlistTerms(ctx context.Context, caseID string) ([]model.WatchlistTerm, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT term_id, case_id, term, term_type, COALESCE(note, ''), COALESCE(operator, ''), created_at
+		FROM case_watchlist
+		WHERE case_id = ?
+		ORDER BY term_type, term
+	`, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("query watchlist terms: %w", err)
+	}
+	defer rows.Close()
+
+	out := []model.WatchlistTerm{}
+	for rows.Next() {
+		var item model.WatchlistTerm
+		if err := rows.Scan(&item.TermID, &item.CaseID, &item.Term, &item.TermType, &item.Note, &item.Operator, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan watchlist term: %w", err)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate watchlist terms: %w", err)
+	}
+	return out, nil
+}
+
+// DeleteWatchlistTerm 删除一条关注词（已产生的命中保留）；返回是否存在。
+func (s *Store) DeleteWatchlistTerm(ctx context.Context, caseID, termID string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM case_watchlist WHERE case_id = ? AND term_id = ?`, caseID, termID)
+	if err != nil {
+		return false, fmt.Errorf("delete watchlist term: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete watchlist term: %w", err)
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+)
+
+// 演练库标记
+//
+// 演练模式使用独立的数据库文件，并在 schema_meta 写入 training_mode=1。标记跟随数据而不是命令行开关：
+// 任何命令打开演练库生成的报告/导出都会加水印（见 GetOrgProfile），真实案件库也不会被误当作演练库写入。
+
+const trainingMetaKey = "training_mode"
+
+// IsTrainingDB 判断当前库是否为演练库。
+func (s *Store) IsTrainingDB(ctx context.Context) (bool, error) {
+	v, err := s.GetSchemaMetaValue(ctx, trainingMetaKey)
+	if err != nil {
+		return false, err
+	}
+	return v == "1", nil
+}
+
+// MarkTrainingDB 把当前库标记为演练库；库内已有案件且未标记时拒绝（避免把真实案件库变成演练库）。
+func (s *Store) MarkTrainingDB(ctx context.Context) error {
+	training, err := s.IsTrainingDB(ctx)
+	if err != nil || training {
+		return err
+	}
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(1) FROM cases`).Scan(&n); err != nil {
+		return fmt.Errorf("count cases: %w", err)
+	}
+	if n > 0 {
+		return fmt.Errorf("database already holds %d case(s) and is not a training database; use a separate --db for training", n)
+	}
+	return s.UpsertSchemaMetaValue(ctx, trainingMetaKey, "1")
+}
@@ -5,6 +5,8 @@ type Config struct {
 	DBPath           string
 	WalletRulePath   string
 	ExchangeRulePath string
+	// RegexRulePath 是可选的自定义正则规则文件（不存在时不启用）。
+	RegexRulePath string
 }
 
 // DefaultConfig 返回本地开发环境的默认配置。
@@ -13,5 +15,6 @@ func DefaultConfig() Config {
 		DBPath:           "data/inspector.db",
 		WalletRulePath:   "rules/wallet_signatures.template.yaml",
 		ExchangeRulePath: "rules/exchange_domains.template.yaml",
+		RegexRulePath:    "rules/regex_rules.template.yaml",
 	}
 }
@@ -0,0 +1,97 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 采集组件版本登记
+//
+// 证据的 collector_name/collector_version/parser_version 统一从这里取值，避免各采集器各自硬编码。
+// 采集器解析逻辑（payload 结构）变化时提升 ParserVersion；读取证据时，若 parser_version 高于当前
+// 程序登记的版本，说明证据由更新的程序生成，当前程序可能无法完整解读，给出兼容性提示。
+
+// Component 是一个采集组件的版本信息。
+type Component struct {
+	Name             string `json:"name"`
+	CollectorVersion string `json:"collector_version"`
+	ParserVersion    string `json:"parser_version"`
+}
+
+// 已登记的采集组件。
+var (
+	HostScanner   = Component{Name: "host_scanner", CollectorVersion: "0.1.0", ParserVersion: "0.1.0"}
+	MobileScanner = Component{Name: "mobile_scanner", CollectorVersion: "0.1.0", ParserVersion: "0.1.0"}
+)
+
+// Components 返回全部已登记的采集组件。
+func Components() []Component {
+	return []Component{HostScanner, MobileScanner}
+}
+
+// LookupComponent 按 collector_name 查找已登记组件。
+func LookupComponent(name string) (Component, bool) {
+	for _, c := range Components() {
+		if c.Name == strings.TrimSpace(name) {
+			return c, true
+		}
+	}
+	return Component{}, false
+}
+
+// ParserCompatWarning 检查证据的 parser_version 是否为当前程序所支持：
+// 证据由已登记组件生成且 parser_version 高于登记版本时返回提示文本，否则（含未登记组件、版本无法解析）返回空串。
+func ParserCompatWarning(collectorName, parserVersion string) string {
+	c, ok := LookupComponent(collectorName)
+	if !ok {
+		return ""
+	}
+	got, ok1 := parseVersion(parserVersion)
+	supported, ok2 := parseVersion(c.ParserVersion)
+	if !ok1 || !ok2 || compareVersion(got, supported) <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("artifact parsed by %s %s, newer than supported %s; upgrade the inspector to read it reliably", c.Name, strings.TrimSpace(parserVersion), c.ParserVersion)
+}
+
+// parseVersion 解析点分数字版本（允许 name- 前缀与 v 前缀，如 forensicimport-0.1.0、v1.2）。
+func parseVersion(v string) ([]int, bool) {
+	v = strings.TrimSpace(v)
+	if i := strings.LastIndex(v, "-"); i >= 0 {
+		v = v[i+1:]
+	}
+	v = strings.TrimPrefix(v, "v")
+	if v == "" {
+		return nil, false
+	}
+	parts := strings.Split(v, ".")
+	out := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		out = append(out, n)
+	}
+	return out, true
+}
+
+func compareVersion(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
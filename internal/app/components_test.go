@@ -0,0 +1,22 @@
+package app
+
+import "testing"
+
+func TestParserCompatWarning(t *testing.T) {
+	cases := []struct {
+		collector, parser string
+		warn              bool
+	}{
+		{HostScanner.Name, HostScanner.ParserVersion, false},
+		{HostScanner.Name, "0.0.9", false},
+		{MobileScanner.Name, "0.2.0", true},
+		{HostScanner.Name, "host_scanner-1.0", true},
+		{HostScanner.Name, "", false},
+		{"forensic_import", "9.9.9", false},
+	}
+	for _, c := range cases {
+		if got := ParserCompatWarning(c.collector, c.parser); (got != "") != c.warn {
+			t.Fatalf("ParserCompatWarning(%q, %q)=%q", c.collector, c.parser, got)
+		}
+	}
+}
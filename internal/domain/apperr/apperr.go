@@ -0,0 +1,222 @@
+package apperr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/platform/i18n"
+)
+
+// Code 是跨服务/API 稳定的错误码（UI 与外部调用方据此分支处理，不要依赖错误文本）。
+type Code string
+
+const (
+	// CodePrecheckAuth 授权工单/授权依据前置检查未通过。
+	CodePrecheckAuth Code = "ERR_PRECHECK_AUTH"
+	// CodePrecheckPolicy 前置检查策略要求的检查未通过（或未执行）。
+	CodePrecheckPolicy Code = "ERR_PRECHECK_POLICY"
+	// CodeDeviceUnauthorized 设备未授权（Android USB 调试未允许 / iOS 未配对信任）。
+	CodeDeviceUnauthorized Code = "ERR_DEVICE_UNAUTHORIZED"
+	// CodeNoDevice 未检测到可扫描设备。
+	CodeNoDevice Code = "ERR_NO_DEVICE"
+	// CodeRulesInvalid 规则文件缺失、无法解析或校验不通过。
+	CodeRulesInvalid Code = "ERR_RULES_INVALID"
+	// CodeDBLocked SQLite 被其他进程/连接占用（database is locked / SQLITE_BUSY）。
+	CodeDBLocked Code = "ERR_DB_LOCKED"
+	// CodeInvalidArgument 请求参数不合法。
+	CodeInvalidArgument Code = "ERR_INVALID_ARGUMENT"
+	// CodeNotFound 资源不存在（案件/报告/证据等）。
+	CodeNotFound Code = "ERR_NOT_FOUND"
+	// CodeConflict 状态冲突（例如已有任务在运行）。
+	CodeConflict Code = "ERR_CONFLICT"
+	// CodeUpstreamUnavailable 外部数据源（RPC/区块浏览器 API）不可用或全部失败。
+	CodeUpstreamUnavailable Code = "ERR_UPSTREAM_UNAVAILABLE"
+	// CodeForbidden 无权执行该操作（未归入更具体错误码的 403，例如权限校验拒绝）。
+	CodeForbidden Code = "ERR_FORBIDDEN"
+	// CodeCSRF 写请求未通过 CSRF 校验（跨站来源或 X-CSRF-Token 不匹配）。
+	CodeCSRF Code = "ERR_CSRF"
+	// CodeRateLimited 请求过于频繁或昂贵接口并发已满（配合 Retry-After 重试）。
+	CodeRateLimited Code = "ERR_RATE_LIMITED"
+	// CodeQuotaExceeded 案件存储占用已超过配额且策略为 block。
+	CodeQuotaExceeded Code = "ERR_QUOTA_EXCEEDED"
+	// CodeUnmaskDenied 隐私模式 partial 下缺少 unmask 权限（未携带或携带了无效的 unmask 令牌）。
+	CodeUnmaskDenied Code = "ERR_UNMASK_DENIED"
+	// CodeChecklistIncomplete external profile 结案时案件侦查清单仍有必需项未完成。
+	CodeChecklistIncomplete Code = "ERR_CHECKLIST_INCOMPLETE"
+	// CodeEvidenceIntegrity 命中与证据交叉引用不一致（引用的证据记录/快照文件缺失、孤立关联），导出被拒绝。
+	CodeEvidenceIntegrity Code = "ERR_EVIDENCE_INTEGRITY"
+	// CodeCanceled 请求被取消或超时。
+	CodeCanceled Code = "ERR_CANCELED"
+	// CodeInternal 未分类的内部错误（兜底）。
+	CodeInternal Code = "ERR_INTERNAL"
+)
+
+// Error 是携带稳定错误码的领域错误。
+//
+// Message 面向人读（可能随版本调整），Code 面向程序判断（保持稳定）。
+// Key/Args 非空时 Message 是目录文本（i18n）的英文渲染，出口可用 Localize 按操作员语言重新渲染。
+type Error struct {
+	Code    Code
+	Message string
+	Err     error
+	Key     string
+	Args    []any
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Message != "" && e.Err != nil:
+		return e.Message + ": " + e.Err.Error()
+	case e.Message != "":
+		return e.Message
+	case e.Err != nil:
+		return e.Err.Error()
+	default:
+		return string(e.Code)
+	}
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// New 创建一个带错误码的新错误。
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap 给已有错误附加错误码；err 为 nil 时返回 nil。
+func Wrap(code Code, err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+// T 创建一个消息来自 i18n 目录的错误（Message 为英文渲染）。
+func T(code Code, key string, args ...any) *Error {
+	return &Error{Code: code, Message: i18n.English(key, args...), Key: key, Args: args}
+}
+
+// WrapT 同 Wrap，消息来自 i18n 目录；err 为 nil 时返回 nil。
+func WrapT(code Code, err error, key string, args ...any) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Message: i18n.English(key, args...), Err: err, Key: key, Args: args}
+}
+
+// Localize 按 l 的语言渲染错误文本（CLI stderr / HTTP error 字段）：
+// - 英文（或 nil）原样返回 err.Error()，保持与未本地化时一致
+// - 错误链上带目录 key 的 *Error 用目标语言替换其英文文本，外层 fmt.Errorf 前缀保留
+// - 其余错误在原文前加错误码标题（例如“参数不合法：”），原文多为底层库输出，不做翻译
+func Localize(l *i18n.Localizer, err error) string {
+	if err == nil {
+		return ""
+	}
+	if l.Lang() == i18n.EN {
+		return err.Error()
+	}
+	if msg, ok := localizeKeyed(l, err); ok {
+		return msg
+	}
+	return l.T("code."+string(CodeOf(err))) + l.T("code.separator") + err.Error()
+}
+
+// localizeKeyed 渲染错误链上第一个带目录 key 的 *Error（其内层错误递归处理）；没有时返回 false。
+func localizeKeyed(l *i18n.Localizer, err error) (string, bool) {
+	var ae *Error
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if x, ok := e.(*Error); ok && x.Key != "" {
+			ae = x
+			break
+		}
+	}
+	if ae == nil {
+		return "", false
+	}
+	local := l.T(ae.Key, ae.Args...)
+	if ae.Err != nil {
+		inner, ok := localizeKeyed(l, ae.Err)
+		if !ok {
+			inner = ae.Err.Error()
+		}
+		local += l.T("code.separator") + inner
+	}
+	return strings.Replace(err.Error(), ae.Error(), local, 1), true
+}
+
+// CodeOf 提取错误码：
+// - 错误链上存在 *Error 时返回其 Code
+// - 否则按已知错误特征推断（context 取消、SQLite 锁）
+// - 都不匹配时返回 CodeInternal
+func CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var ae *Error
+	if errors.As(err, &ae) && ae.Code != "" {
+		return ae.Code
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return CodeCanceled
+	}
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "database is locked") || strings.Contains(msg, "sqlite_busy") {
+		return CodeDBLocked
+	}
+	return CodeInternal
+}
+
+// HTTPStatus 返回错误码对应的 HTTP 状态码。
+func HTTPStatus(code Code) int {
+	switch code {
+	case CodeInvalidArgument, CodeRulesInvalid:
+		return http.StatusBadRequest
+	case CodePrecheckAuth, CodePrecheckPolicy, CodeDeviceUnauthorized, CodeForbidden, CodeCSRF, CodeUnmaskDenied:
+		return http.StatusForbidden
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeConflict, CodeChecklistIncomplete, CodeEvidenceIntegrity:
+		return http.StatusConflict
+	case CodeNoDevice:
+		return http.StatusUnprocessableEntity
+	case CodeDBLocked:
+		return http.StatusServiceUnavailable
+	case CodeUpstreamUnavailable:
+		return http.StatusBadGateway
+	case CodeRateLimited:
+		return http.StatusTooManyRequests
+	case CodeQuotaExceeded:
+		return http.StatusInsufficientStorage
+	case CodeCanceled:
+		return http.StatusRequestTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// CodeForStatus 在错误本身没有错误码时，根据 handler 选择的 HTTP 状态码给出兜底错误码。
+func CodeForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidArgument
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusForbidden:
+		// 前置检查未通过由扫描服务显式返回 CodePrecheckAuth，这里只给出通用的“禁止访问”。
+		return CodeForbidden
+	case http.StatusServiceUnavailable:
+		return CodeDBLocked
+	case http.StatusBadGateway:
+		return CodeUpstreamUnavailable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusInsufficientStorage:
+		return CodeQuotaExceeded
+	default:
+		return CodeInternal
+	}
+}
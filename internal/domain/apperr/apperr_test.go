@@ -0,0 +1,61 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"crypto-inspector/internal/platform/i18n"
+)
+
+func TestLocalize(t *testing.T) {
+	zh := i18n.New(i18n.ZH)
+	keyed := fmt.Errorf("update case: %w", T(CodeNotFound, "case.not_found", "case_1"))
+	if keyed.Error() != "update case: case not found: case_1" {
+		t.Fatalf("english text changed: %q", keyed.Error())
+	}
+	for name, tc := range map[string]struct {
+		l    *i18n.Localizer
+		err  error
+		want string
+	}{
+		"english unchanged":  {i18n.New(i18n.EN), keyed, "update case: case not found: case_1"},
+		"nil localizer":      {nil, keyed, "update case: case not found: case_1"},
+		"keyed keeps prefix": {zh, keyed, "update case: 案件不存在：case_1"},
+		"wrapped cause":      {zh, WrapT(CodeRulesInvalid, errors.New("yaml: line 3"), "rules.parse_wallet"), "解析钱包规则失败：yaml: line 3"},
+		"unkeyed":            {zh, New(CodeConflict, "busy"), "状态冲突：busy"},
+		"plain error":        {zh, errors.New("boom"), "内部错误：boom"},
+	} {
+		if got := Localize(tc.l, tc.err); got != tc.want {
+			t.Fatalf("%s: got %q want %q", name, got, tc.want)
+		}
+	}
+	if CodeOf(keyed) != CodeNotFound {
+		t.Fatalf("code=%s", CodeOf(keyed))
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	for status, want := range map[int]Code{
+		http.StatusBadRequest:          CodeInvalidArgument,
+		http.StatusForbidden:           CodeForbidden,
+		http.StatusNotFound:            CodeNotFound,
+		http.StatusConflict:            CodeConflict,
+		http.StatusServiceUnavailable:  CodeDBLocked,
+		http.StatusBadGateway:          CodeUpstreamUnavailable,
+		http.StatusTooManyRequests:     CodeRateLimited,
+		http.StatusInsufficientStorage: CodeQuotaExceeded,
+		http.StatusTeapot:              CodeInternal,
+	} {
+		if got := CodeForStatus(status); got != want {
+			t.Fatalf("status %d: got %s want %s", status, got, want)
+		}
+	}
+	// 前置检查与 CSRF 等具体 403 保留自身错误码，映射回 403。
+	for _, code := range []Code{CodePrecheckAuth, CodeCSRF, CodeUnmaskDenied, CodeForbidden} {
+		if HTTPStatus(code) != http.StatusForbidden {
+			t.Fatalf("%s: status=%d", code, HTTPStatus(code))
+		}
+	}
+}
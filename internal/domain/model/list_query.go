@@ -0,0 +1,49 @@
+package model
+
+// Page 是列表查询共用的分页/排序参数。
+//
+// 分页为游标（keyset）方式：上一页响应中的 next_cursor 原样传回即可取下一页；
+// 游标与排序方式绑定，换了 sort/order 需要从第一页重新开始。
+type Page struct {
+	Cursor string
+	// Limit 为本页最大条数；各查询对 0 的含义不同（见具体方法说明）。
+	Limit int
+	Sort  string
+	Order string // asc|desc，为空时按排序字段的默认方向
+}
+
+// HitQuery 是命中列表的筛选条件。
+type HitQuery struct {
+	Page
+	HitType  string
+	Verdict  string
+	DeviceID string
+	// WalletCategory 只保留该托管类型的钱包安装/运行命中（uncategorized 表示规则未配置类型）。
+	WalletCategory string
+	// MinConfidence/MaxConfidence 为置信度闭区间；MaxConfidence <= 0 表示不限上限。
+	MinConfidence float64
+	MaxConfidence float64
+	// Since/Until 为时间窗口（unix 秒，0 表示不限），与命中的 [first_seen_at, last_seen_at] 有交集即保留。
+	Since int64
+	Until int64
+}
+
+// ArtifactQuery 是证据列表的筛选条件（时间窗口作用于 collected_at）。
+type ArtifactQuery struct {
+	Page
+	ArtifactType string
+	DeviceID     string
+	Since        int64
+	Until        int64
+}
+
+// AuditQuery 是审计日志列表的筛选条件（时间窗口作用于 occurred_at）。
+type AuditQuery struct {
+	Page
+	EventType string
+	Status    string
+	Actor     string
+	DeviceID  string
+	Since     int64
+	Until     int64
+}
@@ -16,6 +16,39 @@ type HitDetail struct {
 	Verdict      string   `json:"verdict"`
 	DetailJSON   string   `json:"detail_json,omitempty"`
 	ArtifactIDs  []string `json:"artifact_ids,omitempty"`
+	ClusterID    string   `json:"cluster_id,omitempty"` // 地址聚类分组（仅 wallet_address）
+	Manual       bool     `json:"manual,omitempty"`     // 人工录入（非规则/查询自动产生）
+	// WalletCategory 是钱包安装/运行命中的托管类型（取自 detail_json，未配置时为 uncategorized；其它类型为空）。
+	WalletCategory string `json:"wallet_category,omitempty"`
+}
+
+// WalletCategoryGroup 是按钱包托管类型分组的命中汇总（报告与命中接口使用）。
+type WalletCategoryGroup struct {
+	WalletCategory string   `json:"wallet_category"`
+	HitCount       int      `json:"hit_count"`
+	DeviceCount    int      `json:"device_count"`
+	RuleIDs        []string `json:"rule_ids"`
+	RuleNames      []string `json:"rule_names"`
+	HitIDs         []string `json:"hit_ids"`
+}
+
+// HitRollup 是案件级命中汇总：同一 hit_type + rule_id + matched_value 在多台设备上的命中合并为一行，
+// 用于报告摘要去重；逐设备明细仍见 HitDetail（HitIDs 可回溯）。
+type HitRollup struct {
+	HitType       string   `json:"hit_type"`
+	RuleID        string   `json:"rule_id"`
+	RuleName      string   `json:"rule_name"`
+	MatchedValue  string   `json:"matched_value"`
+	HitCount      int      `json:"hit_count"`
+	DeviceCount   int      `json:"device_count"`
+	DeviceIDs     []string `json:"device_ids"`
+	HitIDs        []string `json:"hit_ids"`
+	ArtifactCount int      `json:"artifact_count"`
+	FirstSeenAt   int64    `json:"first_seen_at"`
+	LastSeenAt    int64    `json:"last_seen_at"`
+	MaxConfidence float64  `json:"max_confidence"`
+	Verdicts      []string `json:"verdicts"`
+	Manual        bool     `json:"manual,omitempty"` // 含人工录入命中
 }
 
 // ReportInfo 表示报告索引信息（reports 表）。
@@ -28,8 +61,14 @@ type ReportInfo struct {
 	GeneratedAt      int64  `json:"generated_at"`
 	GeneratorVersion string `json:"generator_version"`
 	Status           string `json:"status"`
+	ReportNo         string `json:"report_no,omitempty"`
+	// Encryption 是导出包的加密方式（如 zip_aes256）；未加密时为空。
+	Encryption string `json:"encryption,omitempty"`
 }
 
+// ReportEncryptionZipAES256 表示导出包各条目为 WinZip AES-256（AE-2）加密。
+const ReportEncryptionZipAES256 = "zip_aes256"
+
 // CaseOverview 是案件摘要，便于 UI 首页展示。
 type CaseOverview struct {
 	CaseID        string `json:"case_id"`
@@ -37,6 +76,7 @@ type CaseOverview struct {
 	Title         string `json:"title,omitempty"`
 	Status        string `json:"status"`
 	CreatedBy     string `json:"created_by,omitempty"`
+	Owner         string `json:"owner,omitempty"`
 	Note          string `json:"note,omitempty"`
 	CreatedAt     int64  `json:"created_at"`
 	UpdatedAt     int64  `json:"updated_at"`
@@ -44,4 +84,7 @@ type CaseOverview struct {
 	ArtifactCount int    `json:"artifact_count"`
 	HitCount      int    `json:"hit_count"`
 	ReportCount   int    `json:"report_count"`
+
+	// Checklist 为案件侦查清单（由 webapp 概览接口附带，store 查询不填充）。
+	Checklist []ChecklistItem `json:"checklist,omitempty"`
 }
@@ -9,6 +9,7 @@ type CaseSummary struct {
 	Title     string `json:"title,omitempty"`
 	Status    string `json:"status"`
 	CreatedBy string `json:"created_by,omitempty"`
+	Owner     string `json:"owner,omitempty"`
 	Note      string `json:"note,omitempty"`
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
@@ -16,6 +17,8 @@ type CaseSummary struct {
 
 // AuditLog 表示一条审计日志记录（audit_logs 表）。
 type AuditLog struct {
+	// Seq 为写入顺序号（audit_logs.rowid），用于增量转发游标；不参与审计链哈希。
+	Seq           int64           `json:"seq,omitempty"`
 	EventID       string          `json:"event_id"`
 	CaseID        string          `json:"case_id"`
 	DeviceID      string          `json:"device_id,omitempty"`
@@ -43,7 +46,21 @@ type ArtifactInfo struct {
 	CollectedAt       int64  `json:"collected_at"`
 	CollectorName     string `json:"collector_name,omitempty"`
 	CollectorVersion  string `json:"collector_version,omitempty"`
+	ParserVersion     string `json:"parser_version,omitempty"`
 	AcquisitionMethod string `json:"acquisition_method,omitempty"`
+	MimeType          string `json:"mime_type,omitempty"`
+	// Compression 是快照文件的压缩方式（none|gzip），sha256/size_bytes 针对压缩后的存储字节。
+	Compression string `json:"snapshot_compression,omitempty"`
+	// ExhibitNo / Exhibit 是案件内检材编号及展示标签（例如 检材-001），导出时分配，未分配时为 0 / 空。
+	ExhibitNo int64  `json:"exhibit_no,omitempty"`
+	Exhibit   string `json:"exhibit,omitempty"`
+	// CompatWarning 非空表示证据的 parser_version 高于当前程序支持的版本（见 app.ParserCompatWarning）。
+	CompatWarning string `json:"compat_warning,omitempty"`
+	// PartGroup/PartNo/PartCount 非空表示该证据是超大 payload 拆出的第 PartNo/PartCount 个分片，
+	// PartGroup 为第 1 个分片的 artifact_id。
+	PartGroup string `json:"part_group,omitempty"`
+	PartNo    int    `json:"part_no,omitempty"`
+	PartCount int    `json:"part_count,omitempty"`
 }
 
 // CaseDevice 是案件关联设备信息（case_devices 表）。
@@ -58,4 +75,374 @@ type CaseDevice struct {
 	AuthNote       string `json:"auth_note,omitempty"`
 	FirstSeenAt    int64  `json:"first_seen_at"`
 	LastSeenAt     int64  `json:"last_seen_at"`
+	// ParentDeviceID 非空表示该设备来自父设备上发现的 VM 磁盘镜像（二次扫描）。
+	ParentDeviceID string `json:"parent_device_id,omitempty"`
+
+	// 以下为人工维护的保管信息（devicecustody），扫描不会覆盖。
+	PhysicalLabel    string        `json:"physical_label,omitempty"`
+	IMEI             string        `json:"imei,omitempty"`
+	SeizureLocation  string        `json:"seizure_location,omitempty"`
+	CustodyOfficer   string        `json:"custody_officer,omitempty"`
+	CustodyNote      string        `json:"custody_note,omitempty"`
+	CustodyUpdatedBy string        `json:"custody_updated_by,omitempty"`
+	CustodyUpdatedAt int64         `json:"custody_updated_at,omitempty"`
+	Photos           []DevicePhoto `json:"photos,omitempty"`
+}
+
+// HitArtifactLink 是一条命中与证据关联（hit_artifact_links 表）及两端记录的现状，供交叉引用校验使用。
+type HitArtifactLink struct {
+	HitID          string `json:"hit_id"`
+	ArtifactID     string `json:"artifact_id"`
+	HitExists      bool   `json:"hit_exists"`
+	HitCaseID      string `json:"hit_case_id,omitempty"`
+	ArtifactExists bool   `json:"artifact_exists"`
+	ArtifactCaseID string `json:"artifact_case_id,omitempty"`
+	SnapshotPath   string `json:"snapshot_path,omitempty"`
+}
+
+// DevicePhoto 是一张设备照片（device_photos 表）。
+type DevicePhoto struct {
+	PhotoID      string `json:"photo_id"`
+	CaseID       string `json:"case_id"`
+	DeviceID     string `json:"device_id"`
+	OriginalName string `json:"original_name"`
+	FilePath     string `json:"file_path"`
+	SHA256       string `json:"sha256"`
+	SizeBytes    int64  `json:"size_bytes"`
+	MimeType     string `json:"mime_type"`
+	Caption      string `json:"caption,omitempty"`
+	UploadedBy   string `json:"uploaded_by,omitempty"`
+	UploadedAt   int64  `json:"uploaded_at"`
+}
+
+// DeviceCaseRef 是同一设备标识在其他未结案件中的登记记录（重复建案提示）。
+type DeviceCaseRef struct {
+	CaseID     string `json:"case_id"`
+	CaseNo     string `json:"case_no,omitempty"`
+	Title      string `json:"title,omitempty"`
+	DeviceID   string `json:"device_id"`
+	DeviceName string `json:"device_name,omitempty"`
+	OSType     string `json:"os_type"`
+	Identifier string `json:"identifier"`
+	LastSeenAt int64  `json:"last_seen_at"`
+}
+
+// ArtifactPayload 是证据 ID + 结构化内容（分析类功能按证据回溯来源时使用）。
+type ArtifactPayload struct {
+	ArtifactID  string          `json:"artifact_id"`
+	DeviceID    string          `json:"device_id"`
+	PayloadJSON json.RawMessage `json:"payload_json"`
+}
+
+// AddressCluster 是钱包地址共现聚类结果（address_clusters 表）。
+type AddressCluster struct {
+	ClusterID   string         `json:"cluster_id"`
+	CaseID      string         `json:"case_id"`
+	Size        int            `json:"size"`
+	Score       float64        `json:"score"`
+	Addresses   []string       `json:"addresses"`
+	HitIDs      []string       `json:"hit_ids,omitempty"`
+	Reasons     map[string]int `json:"reasons"` // 关联依据计数：same_page/same_url/temporal/same_artifact
+	FirstSeenAt int64          `json:"first_seen_at"`
+	LastSeenAt  int64          `json:"last_seen_at"`
+	CreatedAt   int64          `json:"created_at,omitempty"`
+}
+
+// NameResolution 是一次链上域名解析记录（name_resolutions 表）。
+type NameResolution struct {
+	ResolutionID    string   `json:"resolution_id"`
+	CaseID          string   `json:"case_id"`
+	DeviceID        string   `json:"device_id,omitempty"`
+	Name            string   `json:"name"`
+	Service         string   `json:"service"` // ens|space_id
+	Namehash        string   `json:"namehash"`
+	Status          string   `json:"status"` // resolved|unresolved|failed|skipped
+	ResolvedAddress string   `json:"resolved_address,omitempty"`
+	Resolver        string   `json:"resolver,omitempty"`
+	Registry        string   `json:"registry,omitempty"`
+	BlockNumber     int64    `json:"block_number,omitempty"`
+	BlockTimestamp  int64    `json:"block_timestamp,omitempty"`
+	RPCURL          string   `json:"rpc_url,omitempty"`
+	Error           string   `json:"error,omitempty"`
+	ArtifactIDs     []string `json:"artifact_ids,omitempty"`
+	FirstSeenAt     int64    `json:"first_seen_at,omitempty"`
+	LastSeenAt      int64    `json:"last_seen_at,omitempty"`
+	ResolvedAt      int64    `json:"resolved_at"`
+}
+
+// CaseAddress 是案件地址簿中的一条地址（case_addresses 表）。
+type CaseAddress struct {
+	CaseID      string          `json:"case_id"`
+	Address     string          `json:"address"`
+	Chain       string          `json:"chain,omitempty"`
+	Source      string          `json:"source"` // extracted|ens|space_id
+	Label       string          `json:"label,omitempty"`
+	FirstSeenAt int64           `json:"first_seen_at"`
+	LastSeenAt  int64           `json:"last_seen_at"`
+	Detail      json.RawMessage `json:"detail,omitempty"`
+}
+
+// Redaction 是一条对外披露遮盖标记（redactions 表）。
+type Redaction struct {
+	RedactionID string `json:"redaction_id"`
+	CaseID      string `json:"case_id"`
+	TargetType  string `json:"target_type"` // artifact|hit
+	TargetID    string `json:"target_id"`
+	Pointer     string `json:"pointer"` // JSON Pointer；空串表示整条证据/命中
+	Reason      string `json:"reason,omitempty"`
+	Operator    string `json:"operator,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// Comment 是分析人员对证据/命中的一条评论（comments 表）。
+type Comment struct {
+	CommentID  string `json:"comment_id"`
+	CaseID     string `json:"case_id"`
+	TargetType string `json:"target_type"` // artifact|hit
+	TargetID   string `json:"target_id"`
+	ParentID   string `json:"parent_id,omitempty"` // 回复的主题首条评论；空串表示主题首条
+	Author     string `json:"author"`
+	Text       string `json:"text"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+// 关注词类型（case_watchlist.term_type）。
+const (
+	WatchlistKeyword = "keyword" // 任意关键词，忽略大小写子串匹配
+	WatchlistAlias   = "alias"   // 嫌疑人别名/昵称，匹配方式同 keyword
+	WatchlistAddress = "address" // 钱包地址，忽略大小写子串匹配
+	WatchlistPhone   = "phone"   // 手机号，只比较数字部分
+)
+
+// WatchlistTerm 是案件关注词（case_watchlist 表），匹配时在全部文本类证据中检索。
+type WatchlistTerm struct {
+	TermID    string `json:"term_id"`
+	CaseID    string `json:"case_id"`
+	Term      string `json:"term"`
+	TermType  string `json:"term_type"`
+	Note      string `json:"note,omitempty"`
+	Operator  string `json:"operator,omitempty"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// CaseStorageUsage 是案件存储占用统计（GET /api/cases/{id}/storage）。
+type CaseStorageUsage struct {
+	CaseID         string           `json:"case_id"`
+	EvidenceBytes  int64            `json:"evidence_bytes"` // artifacts.size_bytes 合计（磁盘上存储的字节，压缩快照按压缩后计）
+	ArtifactCount  int64            `json:"artifact_count"`
+	PayloadBytes   int64            `json:"payload_bytes"` // 库内 payload_json 字节
+	ReportBytes    int64            `json:"report_bytes"`
+	ReportCount    int64            `json:"report_count"`
+	MissingReports int              `json:"missing_reports,omitempty"` // 报告文件已不存在的条数
+	DBRows         map[string]int64 `json:"db_rows"`                   // 各表中属于该案件的行数
+	TotalBytes     int64            `json:"total_bytes"`               // evidence_bytes + report_bytes（配额按此计算）
+	Quota          CaseStorageQuota `json:"quota"`
+}
+
+// CaseStorageQuota 是案件生效的存储配额及当前状态。
+type CaseStorageQuota struct {
+	LimitBytes int64   `json:"limit_bytes"` // 0 表示不限制
+	Source     string  `json:"source"`      // case|default
+	Mode       string  `json:"mode"`        // warn|block
+	UsedRatio  float64 `json:"used_ratio,omitempty"`
+	Status     string  `json:"status"` // unlimited|ok|near_limit|exceeded
+}
+
+// RuleHitStat 是单条规则在全部案件中的命中统计（规则浏览页使用）。
+type RuleHitStat struct {
+	RuleID    string `json:"rule_id"`
+	HitType   string `json:"hit_type"`
+	HitCount  int64  `json:"hit_count"`
+	CaseCount int64  `json:"case_count"`
+	LastHitAt int64  `json:"last_hit_at,omitempty"`
+}
+
+// RuleHitStatsQuery 是规则命中统计的筛选条件；时间窗口作用于命中入库时间 created_at（unix 秒，0 表示不限）。
+type RuleHitStatsQuery struct {
+	HitType string
+	// HitTypes 非空时只统计这些命中类型（与 HitType 同时指定时两者都需满足）。
+	HitTypes []string
+	// MergeHitTypes 为 true 时不按命中类型分组，同一规则的各类型命中合并为一条（结果的 HitType 为空）。
+	MergeHitTypes bool
+	RuleID        string
+	// BundleVersion 只统计该规则包版本产生的命中（规则包记录缺失时按命中的 rule_version 比较）。
+	BundleVersion string
+	Since         int64
+	Until         int64
+}
+
+// RuleHitBundleStat 是单条规则在某个规则包下的命中统计，供趋势看板按规则版本分段。
+//
+// 同一版本号的规则文件被修改过（sha256 不同）时视为不同规则包，分开统计；
+// 没有规则包记录的命中（早期数据、内置规则）BundleID 为空，BundleVersion 取命中的 rule_version。
+type RuleHitBundleStat struct {
+	RuleID         string `json:"rule_id"`
+	HitType        string `json:"hit_type"`
+	BundleID       string `json:"bundle_id,omitempty"`
+	BundleType     string `json:"bundle_type,omitempty"`
+	BundleVersion  string `json:"bundle_version"`
+	BundleLoadedAt int64  `json:"bundle_loaded_at,omitempty"`
+	HitCount       int64  `json:"hit_count"`
+	CaseCount      int64  `json:"case_count"`
+	FirstHitAt     int64  `json:"first_hit_at,omitempty"`
+	LastHitAt      int64  `json:"last_hit_at,omitempty"`
+}
+
+// CaseAttachment 是案件级附件（例如扫描版执法授权文书）。
+type CaseAttachment struct {
+	AttachmentID       string `json:"attachment_id"`
+	CaseID             string `json:"case_id"`
+	Kind               string `json:"kind"` // authorization_document
+	OriginalName       string `json:"original_name"`
+	FilePath           string `json:"file_path"`
+	SHA256             string `json:"sha256"`
+	SizeBytes          int64  `json:"size_bytes"`
+	MimeType           string `json:"mime_type"`
+	AuthorizationOrder string `json:"authorization_order,omitempty"`
+	Agency             string `json:"agency,omitempty"`
+	UploadedBy         string `json:"uploaded_by,omitempty"`
+	UploadedAt         int64  `json:"uploaded_at"`
+}
+
+// CaseAssignment 是一条案件交接记录（负责人变更 + 交接说明）。
+type CaseAssignment struct {
+	AssignmentID string `json:"assignment_id"`
+	CaseID       string `json:"case_id"`
+	FromOperator string `json:"from_operator,omitempty"`
+	ToOperator   string `json:"to_operator"`
+	Note         string `json:"note,omitempty"`
+	AssignedBy   string `json:"assigned_by,omitempty"`
+	AssignedAt   int64  `json:"assigned_at"`
+}
+
+// 侦查清单项状态（case_checklist.status）。
+const (
+	ChecklistPending       = "pending"
+	ChecklistDone          = "done"
+	ChecklistNotApplicable = "not_applicable" // 不适用（例如案件没有手机），必须填写说明
+)
+
+// ChecklistItem 是案件侦查清单中的一项（case_checklist 表）。
+type ChecklistItem struct {
+	CaseID      string `json:"case_id"`
+	ItemID      string `json:"item_id"`
+	Label       string `json:"label"`
+	Required    bool   `json:"required"`       // external profile 下结案前必须完成
+	Auto        string `json:"auto,omitempty"` // 自动完成条件；空表示只能手工勾选
+	Position    int    `json:"position"`
+	Status      string `json:"status"`
+	Responsible string `json:"responsible,omitempty"` // 负责人
+	CompletedBy string `json:"completed_by,omitempty"`
+	CompletedAt int64  `json:"completed_at,omitempty"`
+	Note        string `json:"note,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// 证据路径迁移涉及的记录类型。
+const (
+	RelocateKindArtifact   = "artifact"
+	RelocateKindReport     = "report"
+	RelocateKindAttachment = "attachment"
+)
+
+// RelocatedPath 是证据根目录迁移时的一条文件路径改写（artifacts / reports / case_attachments）。
+type RelocatedPath struct {
+	Kind      string `json:"kind"` // artifact|report|attachment
+	ID        string `json:"id"`
+	CaseID    string `json:"case_id"`
+	OldPath   string `json:"old_path"`
+	NewPath   string `json:"new_path"`
+	SHA256    string `json:"sha256"`
+	SizeBytes int64  `json:"size_bytes"` // report 无入库大小，为 -1
+}
+
+// OrgProfile 是出具报告的单位信息，用于报告页眉/页脚与导出文件名。
+type OrgProfile struct {
+	AgencyName   string `json:"agency_name"`
+	Unit         string `json:"unit,omitempty"`
+	Address      string `json:"address,omitempty"`
+	LogoPath     string `json:"logo_path,omitempty"`
+	Contact      string `json:"contact,omitempty"`
+	ReportPrefix string `json:"report_prefix,omitempty"`
+	ReportSeq    int64  `json:"report_seq"` // 最近签发的流水号
+	UpdatedBy    string `json:"updated_by,omitempty"`
+	UpdatedAt    int64  `json:"updated_at,omitempty"`
+	// Training 表示当前库是演练库（schema_meta.training_mode=1），不由单位信息保存；报告与导出据此加水印。
+	Training bool `json:"training,omitempty"`
+}
+
+// TrainingWatermark 是演练库出具的所有报告与导出包上的水印文字。
+const TrainingWatermark = "演练/TRAINING"
+
+// ScanOptions 是一次扫描的规范化参数快照（scan_runs.options_json）：字段顺序固定，便于比对与复现采集参数。
+// RPC 地址等可能含密钥的参数只记录是否启用。
+type ScanOptions struct {
+	ScanType            string             `json:"scan_type"`
+	Profile             string             `json:"profile"` // internal|external
+	AppVersion          string             `json:"app_version"`
+	PrivacyMode         string             `json:"privacy_mode"`
+	SnapshotCompression string             `json:"snapshot_compression"`
+	RequireAuthOrder    bool               `json:"require_auth_order"`
+	AuthorizationOrder  string             `json:"authorization_order,omitempty"`
+	AuthorizationBasis  string             `json:"authorization_basis,omitempty"`
+	EvidenceRoot        string             `json:"evidence_root"`
+	WalletRulePath      string             `json:"wallet_rule_path"`
+	ExchangeRulePath    string             `json:"exchange_rule_path"`
+	RegexRulePath       string             `json:"regex_rule_path,omitempty"`
+	BudgetLimitBytes    int64              `json:"budget_limit_bytes,omitempty"` // 0 表示不限
+	Host                *HostScanOptions   `json:"host,omitempty"`
+	Mobile              *MobileScanOptions `json:"mobile,omitempty"`
+}
+
+// HostScanOptions 是主机/离线扫描特有的参数。
+type HostScanOptions struct {
+	ScanMessengers  bool   `json:"scan_messengers"`
+	SkipHistoryDB   bool   `json:"skip_history_db"`
+	ResolveETHNames bool   `json:"resolve_eth_names"`
+	ResolveBNBNames bool   `json:"resolve_bnb_names"`
+	OfflineInputDir string `json:"offline_input_dir,omitempty"`
+	OfflineOS       string `json:"offline_os,omitempty"`
+	ParentDeviceID  string `json:"parent_device_id,omitempty"`
+}
+
+// MobileScanOptions 是移动端扫描特有的参数。
+type MobileScanOptions struct {
+	EnableAndroid       bool   `json:"enable_android"`
+	EnableIOS           bool   `json:"enable_ios"`
+	EnableHarmony       bool   `json:"enable_harmony"`
+	EnableIOSFullBackup bool   `json:"enable_ios_full_backup"`
+	RequireAuthorized   bool   `json:"require_authorized"`
+	IOSBackupDir        string `json:"ios_backup_dir"`
+}
+
+// ScanRun 是一次扫描的参数快照记录（scan_runs 表）。
+type ScanRun struct {
+	RunID         string          `json:"run_id"`
+	CaseID        string          `json:"case_id"`
+	DeviceID      string          `json:"device_id,omitempty"`
+	ScanType      string          `json:"scan_type"`
+	Operator      string          `json:"operator,omitempty"`
+	Options       json.RawMessage `json:"options"`
+	OptionsSHA256 string          `json:"options_sha256"`
+	StartedAt     int64           `json:"started_at"`
+	CreatedAt     int64           `json:"created_at"`
+}
+
+// EvidenceAnchor 是一次报告哈希外部锚定的回执（evidence_anchors 表）。
+type EvidenceAnchor struct {
+	AnchorID  string `json:"anchor_id"`
+	CaseID    string `json:"case_id"`
+	ReportID  string `json:"report_id"`
+	SHA256    string `json:"sha256"`
+	Adapter   string `json:"adapter"` // rest|rfc3161
+	Endpoint  string `json:"endpoint"`
+	ReceiptID string `json:"receipt_id,omitempty"`
+	// AnchoredAt 为锚定服务声明的时间（时间戳 genTime / 上链时间）；未知为 0。
+	AnchoredAt int64 `json:"anchored_at,omitempty"`
+	// Receipt 为回执原文：rfc3161 为 base64 DER 时间戳令牌，rest 为响应 JSON。
+	Receipt       string `json:"receipt"`
+	ReceiptSHA256 string `json:"receipt_sha256"`
+	Operator      string `json:"operator"`
+	CreatedAt     int64  `json:"created_at"`
 }
@@ -1,5 +1,10 @@
 package model
 
+import (
+	"encoding/json"
+	"strings"
+)
+
 // WalletRuleBundle 是钱包规则文件的顶层结构。
 type WalletRuleBundle struct {
 	Version     string            `yaml:"version"`
@@ -18,17 +23,70 @@ type WalletBundleMeta struct {
 
 // WalletSignature 定义一条钱包识别规则。
 type WalletSignature struct {
-	ID                string             `yaml:"id"`
-	Enabled           bool               `yaml:"enabled"`
-	Name              string             `yaml:"name"`
-	Aliases           []string           `yaml:"aliases"`
-	Categories        []string           `yaml:"categories"`
+	ID      string   `yaml:"id"`
+	Enabled bool     `yaml:"enabled"`
+	Name    string   `yaml:"name"`
+	Aliases []string `yaml:"aliases"`
+	// LocalizedAliases 是本地化系统上的程序名（中文/韩文等，如“小狐狸钱包”“메타마스크”），参与程序名关键词匹配。
+	LocalizedAliases []string `yaml:"localized_aliases"`
+	Categories       []string `yaml:"categories"`
+	// Category 是钱包托管类型（custodial/non_custodial/hardware_companion/privacy_coin），
+	// 写入命中详情 wallet_category，报告与命中接口据此分组；与描述形态的 Categories 无关。
+	Category          string             `yaml:"category"`
 	Desktop           WalletDesktopHints `yaml:"desktop"`
 	BrowserExtensions BrowserExtensions  `yaml:"browser_extensions"`
 	Mobile            WalletMobileHints  `yaml:"mobile"`
 	Confidence        WalletConfidence   `yaml:"confidence"`
 }
 
+// 钱包托管类型（wallet_signatures 的 category）。不同类型的后续调查方向不同：
+// 托管钱包可向服务商调取账户资料，非托管钱包依赖助记词/链上分析，硬件钱包配套软件提示需扣押实体设备，
+// 隐私币钱包需要查看密钥才能还原交易。
+const (
+	WalletCategoryCustodial         = "custodial"
+	WalletCategoryNonCustodial      = "non_custodial"
+	WalletCategoryHardwareCompanion = "hardware_companion"
+	WalletCategoryPrivacyCoin       = "privacy_coin"
+	// WalletCategoryUncategorized 是未配置 category 的钱包命中在分组中的归类。
+	WalletCategoryUncategorized = "uncategorized"
+)
+
+// WalletCategories 返回全部合法的钱包托管类型（固定顺序，分组输出按此排列）。
+func WalletCategories() []string {
+	return []string{WalletCategoryCustodial, WalletCategoryNonCustodial, WalletCategoryHardwareCompanion, WalletCategoryPrivacyCoin}
+}
+
+// IsWalletCategory 判断 c 是否为合法的钱包托管类型。
+func IsWalletCategory(c string) bool {
+	for _, v := range WalletCategories() {
+		if c == v {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWalletAppHit 判断命中类型是否为带托管类型的钱包安装/运行命中。
+func IsWalletAppHit(hitType string) bool {
+	return hitType == string(HitWalletInstalled) || hitType == string(HitWalletExecuted)
+}
+
+// WalletCategoryOf 从命中详情中取出钱包托管类型：钱包安装/运行命中未配置时返回 uncategorized，其它命中类型返回空串。
+func WalletCategoryOf(hitType, detailJSON string) string {
+	if !IsWalletAppHit(hitType) {
+		return ""
+	}
+	var detail struct {
+		WalletCategory string `json:"wallet_category"`
+	}
+	if err := json.Unmarshal([]byte(detailJSON), &detail); err == nil {
+		if c := strings.TrimSpace(detail.WalletCategory); c != "" {
+			return c
+		}
+	}
+	return WalletCategoryUncategorized
+}
+
 // WalletDesktopHints 是桌面端钱包识别线索。
 type WalletDesktopHints struct {
 	AppKeywords         []string `yaml:"app_keywords"`
@@ -48,6 +106,8 @@ type BrowserExtensions struct {
 type WalletMobileHints struct {
 	AndroidPackages []string `yaml:"android_packages"`
 	IOSBundleIDs    []string `yaml:"ios_bundle_ids"`
+	// HarmonyBundleNames 是 HarmonyOS 应用包名（bm dump -a）；未填写时 HarmonyOS 设备按 android_packages 比对。
+	HarmonyBundleNames []string `yaml:"harmony_bundle_names"`
 }
 
 // WalletConfidence 定义钱包命中的置信度配置。
@@ -71,17 +131,50 @@ type ExchangeRuleBundle struct {
 type ExchangeMeta struct {
 	MatchModes         []string           `yaml:"match_modes"`
 	ConfidenceDefaults ExchangeConfidence `yaml:"confidence_defaults"`
+	// CommunityKeywords 是判断 Telegram/Discord 频道是否为 OTC/交易社群的关键词（忽略大小写包含匹配）。
+	CommunityKeywords []string `yaml:"community_keywords"`
 }
 
 // ExchangeDomain 定义一条交易所识别规则。
 type ExchangeDomain struct {
-	ID           string             `yaml:"id"`
-	Enabled      bool               `yaml:"enabled"`
-	Name         string             `yaml:"name"`
-	Aliases      []string           `yaml:"aliases"`
-	Domains      []string           `yaml:"domains"`
-	URLsContains []string           `yaml:"urls_contains"`
-	Confidence   ExchangeConfidence `yaml:"confidence"`
+	ID      string   `yaml:"id"`
+	Enabled bool     `yaml:"enabled"`
+	Name    string   `yaml:"name"`
+	Aliases []string `yaml:"aliases"`
+	// LocalizedAliases 是本地化系统上的客户端名称（如“欧易”“幣安”“바이낸스”），参与桌面客户端程序名与社群名称匹配。
+	LocalizedAliases []string             `yaml:"localized_aliases"`
+	Domains          []string             `yaml:"domains"`
+	CertOrgs         []string             `yaml:"cert_orgs"` // 官方站点 TLS 证书主体组织名（subject O），用于钓鱼判定
+	URLsContains     []string             `yaml:"urls_contains"`
+	Conditions       []ExchangeCondition  `yaml:"conditions"`
+	Desktop          ExchangeDesktopHints `yaml:"desktop"`
+	Mobile           ExchangeMobileHints  `yaml:"mobile"`
+	Confidence       ExchangeConfidence   `yaml:"confidence"`
+}
+
+// ExchangeDesktopHints 是交易所桌面客户端识别线索（部分交易所提供 Electron 客户端，不经过浏览器）。
+type ExchangeDesktopHints struct {
+	AppKeywords         []string `yaml:"app_keywords"`
+	BundleIDs           []string `yaml:"bundle_ids"` // macOS bundle id，完全一致时命中
+	InstallPathsWindows []string `yaml:"install_paths_windows"`
+	InstallPathsMacOS   []string `yaml:"install_paths_macos"`
+}
+
+// ExchangeMobileHints 是交易所移动端 App 识别线索。
+type ExchangeMobileHints struct {
+	// URLSchemes 是交易所 App 注册的 URL scheme（不含 "://"，如 "binance"），与移动端应用声明的 scheme 完全一致（忽略大小写）时命中。
+	URLSchemes []string `yaml:"url_schemes"`
+}
+
+// ExchangeCondition 是交易所规则的结构化条件：在域名命中（exact/root）的基础上细分页面或行为，
+// 例如“访问充值页”比“访问首页”更有证明力。已配置的子条件需全部满足。
+type ExchangeCondition struct {
+	ID          string   `yaml:"id"`
+	Label       string   `yaml:"label"`
+	Subdomain   string   `yaml:"subdomain"`    // 子域名通配（path.Match 语法，如 "accounts"、"p2p*"）；"" 表示不限制
+	PathPrefix  string   `yaml:"path_prefix"`  // 路径前缀（忽略大小写），单段可用 *，如 "/*/my/wallet/deposit"
+	QueryParams []string `yaml:"query_params"` // 查询参数标记："key" 或 "key=value"，需全部出现
+	Confidence  float64  `yaml:"confidence"`   // 条件命中时的置信度；为 0 时沿用域名命中的置信度
 }
 
 // ExchangeConfidence 定义交易所命中的置信度配置。
@@ -89,4 +182,33 @@ type ExchangeConfidence struct {
 	ExactDomain float64 `yaml:"exact_domain"`
 	RootDomain  float64 `yaml:"root_domain"`
 	URLContains float64 `yaml:"url_contains"`
+	// Homoglyph 是形近域名（IDN 形近字/易混字符仿冒）的置信度，应低于精确匹配。
+	Homoglyph float64 `yaml:"homoglyph"`
+	// AppDirect / AppKeyword 是桌面客户端按 bundle id 或安装路径命中、按程序名关键词命中的置信度。
+	AppDirect  float64 `yaml:"app_direct"`
+	AppKeyword float64 `yaml:"app_keyword"`
+}
+
+// RegexRuleBundle 是自定义正则规则文件的顶层结构（可选规则包，用于机构自定义线索）。
+type RegexRuleBundle struct {
+	Version     string      `yaml:"version"`
+	BundleType  string      `yaml:"bundle_type"`
+	Maintainer  string      `yaml:"maintainer"`
+	Description string      `yaml:"description"`
+	Rules       []RegexRule `yaml:"rules"`
+}
+
+// RegexRule 定义一条自定义正则规则：在指定证据类型的指定字段中查找匹配。
+type RegexRule struct {
+	ID              string   `yaml:"id"`
+	Enabled         bool     `yaml:"enabled"`
+	Name            string   `yaml:"name"`
+	Description     string   `yaml:"description"`
+	Pattern         string   `yaml:"pattern"`          // RE2 语法，可使用命名分组 (?P<name>...)
+	CaseInsensitive bool     `yaml:"case_insensitive"` // 为 true 时自动加 (?i)
+	Fields          []string `yaml:"fields"`           // payload 字段名（如 url/title）；为空表示全部字符串字段
+	ArtifactTypes   []string `yaml:"artifact_types"`   // 证据类型；为空表示全部（analysis 除外）
+	ValueGroup      string   `yaml:"value_group"`      // 作为 matched_value 的命名分组；为空取整个匹配
+	HitType         string   `yaml:"hit_type"`         // regex_match（默认）| wallet_address
+	Confidence      float64  `yaml:"confidence"`       // 为 0 时取默认值 0.6
 }
@@ -12,6 +12,8 @@ const (
 	OSAndroid OSType = "android"
 	// OSIOS 表示 iOS 设备。
 	OSIOS OSType = "ios"
+	// OSHarmonyOS 表示 HarmonyOS（NEXT）设备，通过 hdc 采集。
+	OSHarmonyOS OSType = "harmonyos"
 )
 
 // Device 表示一次案件中的设备对象（当前为主机）。
@@ -41,6 +43,34 @@ const (
 	ArtifactMobileBackup ArtifactType = "mobile_backup"
 	// ArtifactChainBalance 链上余额查询结果快照（用于把“链上查询结果”固化进证据链）。
 	ArtifactChainBalance ArtifactType = "chain_balance"
+	// ArtifactManualEvidence 人工发现证据的附件（例如现场拍摄的纸质助记词照片），原样入库。
+	ArtifactManualEvidence ArtifactType = "manual_evidence"
+	// ArtifactAnalysis 基于已有证据/命中计算出的分析结果快照（例如多设备关联分析）。
+	ArtifactAnalysis ArtifactType = "analysis"
+	// ArtifactTimeline 第三方时间线工具（Plaso/log2timeline、Autopsy）导出的事件，归一化后入库。
+	ArtifactTimeline ArtifactType = "timeline"
+	// ArtifactBrowserBookmarks 浏览器书签证据（payload 结构同 browser_history，visited_at 为书签创建时间）。
+	ArtifactBrowserBookmarks ArtifactType = "browser_bookmarks"
+	// ArtifactMobileAccounts 移动设备系统账户清单证据（Android dumpsys account）。
+	ArtifactMobileAccounts ArtifactType = "mobile_accounts"
+	// ArtifactVirtualization 主机虚拟化环境清单（虚拟机软件、VM 磁盘镜像、WSL 发行版）。
+	ArtifactVirtualization ArtifactType = "virtualization"
+	// ArtifactPasswordVaults 主机上的密码管理器及保险库文件清单（只记录路径/大小，不读取内容）。
+	ArtifactPasswordVaults ArtifactType = "password_vaults"
+	// ArtifactBrowserFormData Chromium 保存的表单来源（Login Data）与自动填充资料元数据（Web Data），不含字段值。
+	ArtifactBrowserFormData ArtifactType = "browser_form_data"
+	// ArtifactAppExecution macOS 应用运行/登记痕迹（LaunchServices、Dock、Saved Application State），.app 被移走后仍可能保留。
+	ArtifactAppExecution ArtifactType = "app_execution"
+	// ArtifactMessengerTraces Telegram Desktop / Discord 安装与本地可读缓存中的频道/服务器名称（可选采集）。
+	ArtifactMessengerTraces ArtifactType = "messenger_traces"
+	// ArtifactPriceSnapshot 报告折算金额所用的报价快照（来源接口、报价时间、交易对与原始响应），估值口径可独立复核。
+	ArtifactPriceSnapshot ArtifactType = "price_snapshot"
+	// ArtifactBrowserExtSnapshot 命中扩展的本地目录快照（zip：manifest、图标与目录清单），扩展被卸载后仍可复核。
+	ArtifactBrowserExtSnapshot ArtifactType = "browser_extension_snapshot"
+	// ArtifactCollectionInterference 采集期间快照/临时副本创建后立即消失或被改动的记录（常见于杀毒软件隔离），说明证据缺口。
+	ArtifactCollectionInterference ArtifactType = "collection_interference"
+	// ArtifactAppRemnants macOS 钥匙串条目标签与 TCC 授权记录中的应用标识（只取元数据，不含密码/密钥），应用删除后仍保留。
+	ArtifactAppRemnants ArtifactType = "app_remnants"
 )
 
 // Artifact 表示一条落库证据（对应 artifacts 表）。
@@ -53,6 +83,8 @@ type Artifact struct {
 	SnapshotPath      string       // 证据快照文件路径
 	SHA256            string       // 快照文件哈希
 	SizeBytes         int64        // 快照文件大小
+	MimeType          string       // 快照文件 MIME 类型（为空时入库前按文件头探测）
+	Compression       string       // 快照文件压缩方式（空/none/gzip）
 	CollectedAt       int64        // 采集时间（Unix 秒）
 	CollectorName     string       // 采集器名称
 	CollectorVersion  string       // 采集器版本
@@ -62,6 +94,9 @@ type Artifact struct {
 	IsEncrypted       bool         // 是否加密内容
 	EncryptionNote    string       // 加密说明
 	RecordHash        string       // 元数据链路哈希
+	PartGroup         string       // 分片组（第 1 个分片的证据 ID，未拆分时为空）
+	PartNo            int          // 分片序号（从 1 开始，未拆分时为 0）
+	PartCount         int          // 分片总数（未拆分时为 0）
 }
 
 // HitType 表示规则命中类型。
@@ -76,8 +111,38 @@ const (
 	HitWalletAddress HitType = "wallet_address"
 	// HitTokenBalance 链上余额查询结果（例如 ETH/USDT/BTC 的数量）。
 	HitTokenBalance HitType = "token_balance"
+	// HitWalletSuspectedUnknown 规则库未收录、但启发式特征像钱包的应用（低置信，供人工复核）。
+	HitWalletSuspectedUnknown HitType = "wallet_suspected_unknown"
+	// HitNFTHoldings 链上 NFT 持有查询结果（ERC-721/ERC-1155，含 token ID 与合约元数据）。
+	HitNFTHoldings HitType = "nft_holdings"
+	// HitManualFinding 无法归入既有类型的人工发现（例如纸质助记词）。
+	HitManualFinding HitType = "manual_finding"
+	// HitWatchlistMatch 案件关注词（别名/地址/手机号等）在文本类证据中出现。
+	HitWatchlistMatch HitType = "watchlist_match"
+	// HitRegexMatch 自定义正则规则（rules/regex_rules）在证据字段中的匹配。
+	HitRegexMatch HitType = "regex_match"
+	// HitExchangeFormActivity 在交易所域名上保存过登录/表单记录（交互行为，强于单纯浏览）。
+	HitExchangeFormActivity HitType = "exchange_form_activity"
+	// HitWalletExecuted 钱包应用有运行/登记痕迹（包括已移到废纸篓或已删除的 .app）。
+	HitWalletExecuted HitType = "wallet_executed"
+	// HitExchangeAppInstalled 主机上安装了交易所桌面客户端。
+	HitExchangeAppInstalled HitType = "exchange_app_installed"
+	// HitMessengerCommunity Telegram/Discord 频道或服务器名称疑似 OTC/交易社群（中置信，需人工跟进）。
+	HitMessengerCommunity HitType = "messenger_community"
+	// HitPhishingSuspected 访问的域名形近/拼写仿冒交易所官方域名，且站点 TLS 证书不属于该交易所（疑似钓鱼登录页）。
+	HitPhishingSuspected HitType = "phishing_suspected"
 )
 
+// WalletRuleHitTypes 是 rule_id 取自钱包规则（wallet_signatures）的命中类型，规则统计按规则合并这些类型。
+var WalletRuleHitTypes = []HitType{HitWalletInstalled, HitWalletExecuted}
+
+// ExchangeRuleHitTypes 是 rule_id 取自交易所规则（exchange_domains）的命中类型。
+// messenger_community 只有频道名命中交易所名称时才使用交易所规则 ID（关键词命中为 community:<kw>），按 rule_id 统计时自然区分。
+var ExchangeRuleHitTypes = []HitType{HitExchangeVisited, HitExchangeAppInstalled, HitExchangeFormActivity, HitPhishingSuspected, HitMessengerCommunity}
+
+// ManualHitRuleID 是人工录入命中的 rule_id；报告中据此标记“人工录入”。
+const ManualHitRuleID = "manual"
+
 // RuleHit 表示一次规则命中结果（对应 rule_hits 表）。
 type RuleHit struct {
 	ID           string   // 命中 ID
@@ -112,6 +177,10 @@ type AppRecord struct {
 
 	// macOS 常见字段（来自 .app/Contents/Info.plist）
 	BundleID string `json:"bundle_id,omitempty"` // CFBundleIdentifier
+
+	// InstallHints 是安装目录内容特征（best effort），例如 leveldb / chromium_runtime / electron_asar。
+	// 用于“未知钱包”启发式判定，不参与规则命中。
+	InstallHints []string `json:"install_hints,omitempty"`
 }
 
 // ExtensionRecord 是浏览器扩展采集后的统一结构。
@@ -122,6 +191,8 @@ type ExtensionRecord struct {
 	Name        string `json:"name,omitempty"`
 	Version     string `json:"version,omitempty"`
 	Path        string `json:"path,omitempty"` // 扩展目录或扩展包路径（best effort）
+	// Source 为 Chromium 用户数据目录的发现途径：known_path | running_process | portable_dir（见 host 包）。
+	Source string `json:"source,omitempty"`
 }
 
 // VisitRecord 是浏览历史采集后的统一结构。
@@ -132,6 +203,127 @@ type VisitRecord struct {
 	Domain    string `json:"domain"`
 	Title     string `json:"title,omitempty"`
 	VisitedAt int64  `json:"visited_at"`
+	// Transition 是 Chromium 访问的导航类型（typed/link/form_submit/...）；其他浏览器为空。
+	Transition string `json:"transition,omitempty"`
+	// ReferrerURL 是 Chromium 访问链中上一跳（visits.from_visit）的 URL。
+	ReferrerURL string `json:"referrer_url,omitempty"`
+}
+
+// TimelineEventRecord 是时间线导入中的一条事件（仅保留被识别为浏览/应用线索的事件）。
+type TimelineEventRecord struct {
+	Timestamp     int64  `json:"timestamp"`
+	TimestampDesc string `json:"timestamp_desc,omitempty"` // 例如 Last Visited Time / Content Modification Time
+	Source        string `json:"source,omitempty"`         // Plaso source 短名（WEBHIST/REG/...）或 Autopsy 数据类型
+	SourceType    string `json:"source_type,omitempty"`
+	Parser        string `json:"parser,omitempty"`
+	Host          string `json:"host,omitempty"`
+	Message       string `json:"message"`
+	Kind          string `json:"kind"` // visit|app：归一化后对应的记录类型
+}
+
+// VMRecord 是主机上发现的一项虚拟化环境线索。
+//
+// 嫌疑人可能把钱包藏在虚拟机内，仅扫描宿主机会漏掉；这些记录提示需要对镜像做二次扫描。
+type VMRecord struct {
+	Kind       string `json:"kind"`             // hypervisor|disk_image|wsl_distro
+	Product    string `json:"product"`          // vmware|virtualbox|parallels|hyperv|utm|qemu|wsl
+	Name       string `json:"name"`             // 软件名 / 镜像文件名 / 发行版名
+	Path       string `json:"path"`             // 安装目录或镜像路径
+	Format     string `json:"format,omitempty"` // 镜像格式：vmdk|vdi|vhd|vhdx|hdd|qcow2
+	SizeBytes  int64  `json:"size_bytes"`       // 镜像大小（目录型镜像为目录内文件合计）
+	ModifiedAt int64  `json:"modified_at"`      // 最后修改时间（unix 秒）
+}
+
+// PasswordVaultRecord 是主机上发现的一项密码管理器线索。
+//
+// 交易所账号密码通常保存在密码管理器中；报告需提示保险库存在，以便后续依法要求提供主密码或解密。
+type PasswordVaultRecord struct {
+	Kind       string `json:"kind"`             // app|vault_file
+	Product    string `json:"product"`          // 1password|bitwarden|keepass|keepassxc|macpass|strongbox
+	Name       string `json:"name"`             // 软件名 / 保险库文件名
+	Path       string `json:"path"`             // 安装位置或保险库文件路径
+	Format     string `json:"format,omitempty"` // 保险库格式：sqlite|json|kdbx|kdb
+	SizeBytes  int64  `json:"size_bytes"`       // 保险库文件大小
+	ModifiedAt int64  `json:"modified_at"`      // 最后修改时间（unix 秒）
+	Note       string `json:"note,omitempty"`   // 识别依据，例如 "kdbx signature"
+}
+
+// FormDataRecord 是 Chromium 浏览器保存的一条表单来源或自动填充资料元数据。
+//
+// 只记录来源、字段名与使用统计，不记录用户名、密码、地址等字段值：
+// - login_form：Login Data.logins（保存过登录表单的站点，含提交地址与字段名）
+// - autofill_profile：Web Data 自动填充资料（仅使用次数与时间；旧版本带来源站点）
+type FormDataRecord struct {
+	Browser    string   `json:"browser"`
+	Profile    string   `json:"profile,omitempty"`
+	Source     string   `json:"source"`                // login_form|autofill_profile
+	Origin     string   `json:"origin,omitempty"`      // 表单所在页面 / 资料来源站点
+	ActionURL  string   `json:"action_url,omitempty"`  // 表单提交地址
+	Domain     string   `json:"domain,omitempty"`      // 由 origin 提取的域名
+	FieldNames []string `json:"field_names,omitempty"` // 表单字段名（如 username_element/password_element）
+	UseCount   int64    `json:"use_count"`
+	CreatedAt  int64    `json:"created_at,omitempty"`   // unix 秒
+	LastUsedAt int64    `json:"last_used_at,omitempty"` // unix 秒
+}
+
+// AppExecutionRecord 是 macOS 上一条应用运行/登记痕迹。
+//
+// - launch_services：LaunchServices 数据库中登记的应用（lsregister -dump），说明 .app 曾出现在该路径
+// - dock_persistent / dock_recent：Dock 固定项与“最近使用的应用”（com.apple.dock.plist）
+// - saved_state：~/Library/Saved Application State/<bundle id>.savedState，应用运行并打开过窗口才会生成
+// - trash：~/.Trash 中的 .app
+type AppExecutionRecord struct {
+	Source     string `json:"source"` // launch_services|dock_persistent|dock_recent|saved_state|trash
+	Name       string `json:"name,omitempty"`
+	BundleID   string `json:"bundle_id,omitempty"`
+	Path       string `json:"path,omitempty"`         // .app 路径（saved_state 无此字段）
+	InTrash    bool   `json:"in_trash,omitempty"`     // .app 位于 ~/.Trash
+	LastUsedAt int64  `json:"last_used_at,omitempty"` // saved_state/trash 目录修改时间 / LaunchServices 登记时间（unix 秒）
+	SourcePath string `json:"source_path,omitempty"`  // 读取的 plist / 目录
+}
+
+// AppRemnantRecord 是 macOS 钥匙串 / TCC 中与某个应用相关的一条元数据痕迹（不含密码、密钥等钥匙串数据）。
+//
+// - keychain：应用密码条目（genp）的标签/服务名（security dump-keychain 不带 -d，只输出属性），例如 "Exodus Safe Storage"
+// - tcc：TCC 授权记录的客户端（bundle id 或可执行文件路径）与权限类型，应用运行并申请过权限才会生成
+//
+// 网站密码条目（inet）的标签含用户名且与应用无关，不采集。
+type AppRemnantRecord struct {
+	Source     string `json:"source"`                // keychain|tcc
+	Label      string `json:"label,omitempty"`       // keychain：labl（为空时取 svce）
+	Service    string `json:"service,omitempty"`     // keychain：svce；tcc：kTCCService*
+	Client     string `json:"client,omitempty"`      // tcc：bundle id 或可执行文件路径
+	ClientType string `json:"client_type,omitempty"` // tcc：bundle_id|path
+	Auth       string `json:"auth,omitempty"`        // tcc：allowed|denied|limited|unknown
+	ModifiedAt int64  `json:"modified_at,omitempty"` // 钥匙串 mdat / TCC last_modified（unix 秒）
+	SourcePath string `json:"source_path,omitempty"` // 钥匙串文件 / TCC.db
+}
+
+// MessengerRecord 是主机上的一条 Telegram Desktop / Discord 痕迹。
+//
+// - install / data_dir：客户端安装位置或本地数据目录（存在即记录，tdata 等加密数据不解析）
+// - channel：从本地可读缓存中取出的频道/群组/服务器名称
+//   - Telegram：用户导出的聊天记录（ChatExport_*/result.json）
+//   - Discord：Local Storage / 缓存文件中明文出现的 {"id":"<snowflake>","name":"..."} 对象
+type MessengerRecord struct {
+	App        string `json:"app"`  // telegram|discord
+	Kind       string `json:"kind"` // install|data_dir|channel
+	Name       string `json:"name,omitempty"`
+	ChannelID  string `json:"channel_id,omitempty"`
+	ChatType   string `json:"chat_type,omitempty"` // Telegram 导出中的 type，例如 public_supergroup / public_channel
+	Path       string `json:"path"`                // 安装位置 / 数据目录 / 名称所在文件
+	ModifiedAt int64  `json:"modified_at,omitempty"`
+}
+
+// EncryptionFinding 是主机上发现的一项加密卷/加密容器线索。
+//
+// 全盘加密或已挂载的加密卷在断电后将无法读取，需在关机前完成取证或办理解密相关的法律手续。
+type EncryptionFinding struct {
+	Kind   string `json:"kind"`             // bitlocker|filevault|veracrypt|truecrypt|luks
+	Scope  string `json:"scope"`            // system_volume|mounted_volume|container_file|software
+	Path   string `json:"path"`             // 卷号 / 挂载点 / 容器文件路径 / 软件安装位置
+	Status string `json:"status,omitempty"` // 加密状态原始描述，例如 "Protection On" / "FileVault is On."
+	Note   string `json:"note,omitempty"`   // 识别依据，例如 "LUKS header magic"
 }
 
 // MobilePackageRecord 是移动端安装包采集后的统一结构。
@@ -141,6 +333,19 @@ type MobilePackageRecord struct {
 	Identifier string `json:"identifier"`
 	Package    string `json:"package"`
 	Raw        string `json:"raw,omitempty"`
+	// URLSchemes 是应用声明的自定义 URL scheme（Android intent filter / iOS CFBundleURLTypes，如 binance）；
+	// AppLinkHosts 是 Android http(s) 深度链接（App Links）声明的域名。可读取时才填写。
+	URLSchemes   []string `json:"url_schemes,omitempty"`
+	AppLinkHosts []string `json:"app_link_hosts,omitempty"`
+}
+
+// MobileAccountRecord 是移动设备上登记的一个系统账户（例如 Google 账户），可用于后续调证。
+type MobileAccountRecord struct {
+	OS          OSType `json:"os"`
+	DeviceID    string `json:"device_id"`
+	Identifier  string `json:"identifier"`
+	Name        string `json:"name"`
+	AccountType string `json:"account_type"` // 例如 com.google / com.whatsapp
 }
 
 // MobileBackupRecord 是移动端备份信息的统一结构（用于 iOS 备份骨架）。
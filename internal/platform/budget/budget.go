@@ -0,0 +1,116 @@
+package budget
+
+import (
+	"fmt"
+	"sync"
+)
+
+// 采集预算
+//
+// 复制大型 SQLite 库、iOS 完整备份之前先估算字节数，与单次扫描的预算（--max-bytes）比较：
+// - 预算内的来源照常复制，超出预算的来源跳过并记录（元数据/解析结果照常采集）
+// - 取证人员也可以主动取消勾选重型来源（如 --skip-history-db / --ios-full-backup=false），同样记录在摘要中
+// 预算只约束“原样复制”的大文件，JSON 快照体积小，不计入。
+
+// 跳过原因。
+const (
+	ReasonOverBudget = "over_budget"
+	ReasonDeselected = "deselected"
+)
+
+// Item 是一项未复制的大文件来源。
+type Item struct {
+	Kind      string `json:"kind"`
+	Ref       string `json:"ref"`
+	Path      string `json:"path,omitempty"`
+	SizeBytes int64  `json:"size_bytes"` // 估算值，未知时为 0
+	Reason    string `json:"reason"`
+}
+
+// Summary 是一次扫描的预算使用情况。
+type Summary struct {
+	LimitBytes int64  `json:"limit_bytes"` // 0 表示不限
+	UsedBytes  int64  `json:"used_bytes"`
+	Skipped    []Item `json:"skipped,omitempty"`
+}
+
+// Budget 记录单次扫描的预算占用，scan all 中主机与移动端共享同一预算；nil 表示不限且不记录。
+type Budget struct {
+	mu      sync.Mutex
+	limit   int64
+	used    int64
+	skipped []Item
+}
+
+// New 创建预算；limit<=0 表示不限（仍记录占用与取消勾选的来源）。
+func New(limit int64) *Budget {
+	if limit < 0 {
+		limit = 0
+	}
+	return &Budget{limit: limit}
+}
+
+// Take 尝试为一个来源占用 size 字节：预算足够时记账并返回 true，否则记为 over_budget 并返回 false。
+func (b *Budget) Take(kind, ref, path string, size int64) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit > 0 && b.used+size > b.limit {
+		b.skipped = append(b.skipped, Item{Kind: kind, Ref: ref, Path: path, SizeBytes: size, Reason: ReasonOverBudget})
+		return false
+	}
+	b.used += size
+	return true
+}
+
+// Skip 记录一个被取证人员取消勾选的来源。
+func (b *Budget) Skip(kind, ref, path string, size int64) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.skipped = append(b.skipped, Item{Kind: kind, Ref: ref, Path: path, SizeBytes: size, Reason: ReasonDeselected})
+}
+
+// Mark 返回当前已记录的跳过项数量，配合 Since 取出某一阶段（如 scan all 中的主机/移动端）新增的跳过项。
+func (b *Budget) Mark() int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.skipped)
+}
+
+// Since 返回预算摘要的副本，只包含 mark 之后记录的跳过项（nil 预算返回 nil）。
+func (b *Budget) Since(mark int) *Summary {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if mark < 0 || mark > len(b.skipped) {
+		mark = 0
+	}
+	return &Summary{LimitBytes: b.limit, UsedBytes: b.used, Skipped: append([]Item(nil), b.skipped[mark:]...)}
+}
+
+// Warnings 把跳过的来源转为扫描告警文本。
+func (s *Summary) Warnings() []string {
+	if s == nil {
+		return nil
+	}
+	out := make([]string, 0, len(s.Skipped))
+	for _, it := range s.Skipped {
+		switch it.Reason {
+		case ReasonOverBudget:
+			out = append(out, fmt.Sprintf("skipped %s %s (%d bytes): over collection budget %d/%d bytes", it.Kind, it.Ref, it.SizeBytes, s.UsedBytes, s.LimitBytes))
+		default:
+			out = append(out, fmt.Sprintf("skipped %s %s (%d bytes): deselected by operator", it.Kind, it.Ref, it.SizeBytes))
+		}
+	}
+	return out
+}
@@ -0,0 +1,32 @@
+package budget
+
+import "testing"
+
+func TestBudgetTakeAndSince(t *testing.T) {
+	b := New(100)
+	if !b.Take("browser_history_db", "chrome_Default", "/a", 60) {
+		t.Fatalf("first source should fit")
+	}
+	if b.Take("browser_history_db", "edge_Default", "/b", 50) {
+		t.Fatalf("second source should exceed budget")
+	}
+	mark := b.Mark()
+	if !b.Take("mobile_backup", "udid-1", "/c", 40) {
+		t.Fatalf("third source should fit the remaining budget")
+	}
+	b.Skip("mobile_backup", "udid-2", "/d", 0)
+
+	all := b.Since(0)
+	if all.UsedBytes != 100 || len(all.Skipped) != 2 || all.Skipped[0].Reason != ReasonOverBudget {
+		t.Fatalf("summary=%+v", all)
+	}
+	since := b.Since(mark)
+	if len(since.Skipped) != 1 || since.Skipped[0].Reason != ReasonDeselected || len(since.Warnings()) != 1 {
+		t.Fatalf("since=%+v", since)
+	}
+
+	var nb *Budget
+	if !nb.Take("x", "y", "", 1<<40) || nb.Since(nb.Mark()) != nil {
+		t.Fatalf("nil budget should be unlimited")
+	}
+}
@@ -0,0 +1,128 @@
+package filetype
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// 常用 MIME 类型（证据快照中实际出现的类型）。
+const (
+	MimeJSON   = "application/json"
+	MimeZip    = "application/zip"
+	MimeSQLite = "application/vnd.sqlite3"
+	MimePDF    = "application/pdf"
+	MimePNG    = "image/png"
+	MimeJPEG   = "image/jpeg"
+	MimeGIF    = "image/gif"
+	MimeText   = "text/plain"
+	MimeBinary = "application/octet-stream"
+)
+
+// sniffLen 是探测时读取的文件头长度。
+const sniffLen = 512
+
+// DetectFile 读取文件头探测 MIME 类型；文件不可读时按扩展名兜底。
+func DetectFile(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return byExt(path)
+	}
+	defer f.Close()
+
+	head := make([]byte, sniffLen)
+	n, _ := io.ReadFull(f, head)
+	return Detect(head[:n], path)
+}
+
+// Detect 基于魔数（优先）与文件名扩展名探测 MIME 类型。
+//
+// 只识别证据链路中会出现的少数类型，不追求覆盖全部格式：
+// 无法识别的二进制统一为 application/octet-stream，可读文本为 text/plain。
+func Detect(head []byte, name string) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return MimeZip
+	case bytes.HasPrefix(head, []byte("SQLite format 3\x00")):
+		return MimeSQLite
+	case bytes.HasPrefix(head, []byte("%PDF-")):
+		return MimePDF
+	case bytes.HasPrefix(head, []byte("\x89PNG\r\n\x1a\n")):
+		return MimePNG
+	case bytes.HasPrefix(head, []byte{0xFF, 0xD8, 0xFF}):
+		return MimeJPEG
+	case bytes.HasPrefix(head, []byte("GIF87a")), bytes.HasPrefix(head, []byte("GIF89a")):
+		return MimeGIF
+	}
+
+	if len(head) == 0 {
+		return byExt(name)
+	}
+	if isText(head) {
+		trimmed := bytes.TrimSpace(head)
+		if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+			return MimeJSON
+		}
+		if ext := byExt(name); ext != MimeBinary {
+			return ext
+		}
+		return MimeText
+	}
+	return MimeBinary
+}
+
+// IsImage 判断是否为可生成缩略图的图片类型。
+func IsImage(mime string) bool {
+	switch mime {
+	case MimePNG, MimeJPEG, MimeGIF:
+		return true
+	default:
+		return false
+	}
+}
+
+func byExt(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return MimeJSON
+	case ".zip":
+		return MimeZip
+	case ".db", ".sqlite", ".sqlite3":
+		return MimeSQLite
+	case ".pdf":
+		return MimePDF
+	case ".png":
+		return MimePNG
+	case ".jpg", ".jpeg":
+		return MimeJPEG
+	case ".gif":
+		return MimeGIF
+	case ".txt", ".log", ".csv":
+		return MimeText
+	default:
+		return MimeBinary
+	}
+}
+
+// isText 判断文件头是否为可读文本（UTF-8 且不含 NUL/大量控制字符）。
+func isText(head []byte) bool {
+	// 截断位置可能落在多字节字符中间，去掉末尾不完整的 rune 再判断。
+	for i := 0; i < utf8.UTFMax && len(head) > 0 && !utf8.Valid(head); i++ {
+		head = head[:len(head)-1]
+	}
+	if !utf8.Valid(head) {
+		return false
+	}
+	for _, b := range head {
+		if b == 0 {
+			return false
+		}
+		if b < 0x20 && b != '\n' && b != '\r' && b != '\t' && b != '\f' {
+			return false
+		}
+	}
+	return true
+}
@@ -37,3 +37,9 @@ func File(path string) (sum string, size int64, err error) {
 	}
 	return hex.EncodeToString(h.Sum(nil)), n, nil
 }
+
+// Bytes 计算内存数据的 SHA-256（与 File 对同样内容的结果一致）。
+func Bytes(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,86 @@
+package hash
+
+import "math/bits"
+
+// Keccak256 计算以太坊使用的 Keccak-256（原始 Keccak 填充 0x01，不是 FIPS-202 SHA3-256）。
+// 用于 ENS namehash、ABI 函数选择器等链上场景。
+func Keccak256(parts ...[]byte) []byte {
+	const rate = 136 // 1088 bit
+	var st [25]uint64
+	var buf []byte
+	for _, p := range parts {
+		buf = append(buf, p...)
+	}
+
+	absorb := func(block []byte) {
+		for i := 0; i < rate/8; i++ {
+			st[i] ^= uint64(block[i*8]) | uint64(block[i*8+1])<<8 | uint64(block[i*8+2])<<16 | uint64(block[i*8+3])<<24 |
+				uint64(block[i*8+4])<<32 | uint64(block[i*8+5])<<40 | uint64(block[i*8+6])<<48 | uint64(block[i*8+7])<<56
+		}
+		keccakF1600(&st)
+	}
+	for len(buf) >= rate {
+		absorb(buf[:rate])
+		buf = buf[rate:]
+	}
+	last := make([]byte, rate)
+	copy(last, buf)
+	last[len(buf)] ^= 0x01
+	last[rate-1] ^= 0x80
+	absorb(last)
+
+	out := make([]byte, 32)
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 8; j++ {
+			out[i*8+j] = byte(st[i] >> (8 * j))
+		}
+	}
+	return out
+}
+
+var keccakRC = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808A, 0x8000000080008000,
+	0x000000000000808B, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008A, 0x0000000000000088, 0x0000000080008009, 0x000000008000000A,
+	0x000000008000808B, 0x800000000000008B, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800A, 0x800000008000000A,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+var keccakRotc = [24]int{1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 2, 14, 27, 41, 56, 8, 25, 43, 62, 18, 39, 61, 20, 44}
+var keccakPiln = [24]int{10, 7, 11, 17, 18, 3, 5, 16, 8, 21, 24, 4, 15, 23, 19, 13, 12, 2, 20, 14, 22, 9, 6, 1}
+
+func keccakF1600(st *[25]uint64) {
+	var bc [5]uint64
+	for round := 0; round < 24; round++ {
+		// θ
+		for i := 0; i < 5; i++ {
+			bc[i] = st[i] ^ st[i+5] ^ st[i+10] ^ st[i+15] ^ st[i+20]
+		}
+		for i := 0; i < 5; i++ {
+			t := bc[(i+4)%5] ^ bits.RotateLeft64(bc[(i+1)%5], 1)
+			for j := 0; j < 25; j += 5 {
+				st[j+i] ^= t
+			}
+		}
+		// ρ + π
+		t := st[1]
+		for i := 0; i < 24; i++ {
+			j := keccakPiln[i]
+			bc[0] = st[j]
+			st[j] = bits.RotateLeft64(t, keccakRotc[i])
+			t = bc[0]
+		}
+		// χ
+		for j := 0; j < 25; j += 5 {
+			for i := 0; i < 5; i++ {
+				bc[i] = st[j+i]
+			}
+			for i := 0; i < 5; i++ {
+				st[j+i] ^= (^bc[(i+1)%5]) & bc[(i+2)%5]
+			}
+		}
+		// ι
+		st[0] ^= keccakRC[round]
+	}
+}
@@ -0,0 +1,103 @@
+package i18n
+
+// message 是一条目录文本的各语言模板（fmt 格式，各语言使用相同的参数；语序不同时用 %[n]s 指定参数位置）。
+type message struct {
+	en string
+	zh string
+}
+
+// catalog 是 CLI/HTTP 面向操作员的文本目录。
+//
+// key 约定：code.<错误码> 为错误码标题（未带 key 的错误按它加前缀）；cli.* 为 CLI 固定文本；
+// 其余按服务分组（case.* / casemeta.* / xref.* ...）。英文模板与改造前的错误文本逐字一致，
+// 依赖错误文本的脚本不受影响。
+var catalog = map[string]message{
+	// 错误码标题。
+	"code.ERR_PRECHECK_AUTH":        {"authorization precheck failed", "授权前置检查未通过"},
+	"code.ERR_PRECHECK_POLICY":      {"precheck policy not satisfied", "前置检查策略未满足"},
+	"code.ERR_DEVICE_UNAUTHORIZED":  {"device not authorized", "设备未授权"},
+	"code.ERR_NO_DEVICE":            {"no device detected", "未检测到设备"},
+	"code.ERR_RULES_INVALID":        {"rules invalid", "规则文件无效"},
+	"code.ERR_DB_LOCKED":            {"database is locked", "数据库被占用"},
+	"code.ERR_INVALID_ARGUMENT":     {"invalid argument", "参数不合法"},
+	"code.ERR_NOT_FOUND":            {"not found", "资源不存在"},
+	"code.ERR_CONFLICT":             {"conflict", "状态冲突"},
+	"code.ERR_UPSTREAM_UNAVAILABLE": {"upstream unavailable", "外部数据源不可用"},
+	"code.ERR_FORBIDDEN":            {"forbidden", "无权执行该操作"},
+	"code.ERR_CSRF":                 {"csrf check failed", "CSRF 校验未通过"},
+	"code.ERR_RATE_LIMITED":         {"rate limited", "请求过于频繁"},
+	"code.ERR_QUOTA_EXCEEDED":       {"storage quota exceeded", "存储配额已超出"},
+	"code.ERR_UNMASK_DENIED":        {"unmask denied", "无脱敏解除权限"},
+	"code.ERR_CHECKLIST_INCOMPLETE": {"checklist incomplete", "侦查清单未完成"},
+	"code.ERR_EVIDENCE_INTEGRITY":   {"evidence integrity check failed", "证据完整性校验未通过"},
+	"code.ERR_CANCELED":             {"canceled", "已取消"},
+	"code.ERR_INTERNAL":             {"internal error", "内部错误"},
+	"code.separator":                {": ", "："},
+	"cli.error":                     {"error: %s", "错误：%s"},
+	"cli.usage":                     {"Usage:", "用法："},
+	"cli.unknown_command":           {"unknown command: %s", "未知命令：%s"},
+
+	// 通用校验。
+	"case.not_found":          {"case not found: %s", "案件不存在：%s"},
+	"case.archived_readonly":  {"archived cases are read-only", "已归档案件只读"},
+	"text.too_long":           {"%s too long: max=%d chars", "%s 过长：最多 %d 个字符"},
+	"text.control_chars":      {"%s must not contain control characters", "%s 不能包含控制字符"},
+	"precheck.host_auth":      {"host precheck failed: %s", "主机扫描前置检查未通过：%s"},
+	"precheck.mobile_failed":  {"mobile precheck failed: %s", "移动设备前置检查未通过：%s"},
+	"precheck.mobile_nodev":   {"mobile precheck failed: no device connected", "移动设备前置检查未通过：未连接设备"},
+	"precheck.policy_missing": {"%s precheck failed: %s is required by precheck policy (%s)", "%s 前置检查未通过：前置检查策略要求 %s（%s）"},
+
+	// casemeta：案件元数据修改。
+	"casemeta.no_fields":         {"no fields to update (title, case_no, status)", "没有需要修改的字段（title、case_no、status）"},
+	"casemeta.title_empty":       {"title must not be empty", "标题不能为空"},
+	"casemeta.case_no_taken":     {"case_no %q is already used by case %s", "案件编号 %q 已被案件 %s 使用"},
+	"casemeta.invalid_status":    {"invalid status: %q (want %s|%s|%s)", "无效的案件状态：%q（可选 %s|%s|%s）"},
+	"casemeta.transition":        {"status transition %s -> %s is not allowed (open -> closed -> archived)", "不允许的状态流转 %s -> %s（只能 open -> closed -> archived）"},
+	"casemeta.archived_readonly": {"archived cases are read-only; change title/case_no before archiving", "已归档案件只读；请在归档前修改标题/案件编号"},
+	"checklist.incomplete":       {"checklist incomplete: %s", "侦查清单未完成：%s"},
+
+	// devicecustody：设备保管信息与照片。
+	"device.not_found":        {"device not found in case %s: %s", "案件 %s 中不存在设备：%s"},
+	"device.no_fields":        {"no fields to update (physical_label, imei, seizure_location, custody_officer, custody_note)", "没有需要修改的字段（physical_label、imei、seizure_location、custody_officer、custody_note）"},
+	"device.ids_required":     {"case_id and device_id are required", "case_id 与 device_id 为必填项"},
+	"device.photo_empty":      {"photo content is empty", "照片内容为空"},
+	"device.photo_too_large":  {"photo too large: max=%d bytes", "照片过大：最多 %d 字节"},
+	"device.photo_type":       {"device photo must be PNG or JPEG (detected %s)", "设备照片必须是 PNG 或 JPEG（检测到 %s）"},
+	"device.imei_digits":      {"invalid imei %q: digits only", "IMEI %q 无效：只能包含数字"},
+	"device.imei_check_digit": {"invalid imei %q: check digit mismatch", "IMEI %q 无效：校验位不匹配"},
+	"device.imei_length":      {"invalid imei %q: want 15 digits (or 16 for IMEISV)", "IMEI %q 无效：应为 15 位数字（IMEISV 为 16 位）"},
+
+	// xrefverify：命中 ↔ 证据交叉引用。
+	"xref.failed":           {"hit/evidence cross-reference check failed (%d issues): %s", "命中与证据交叉引用校验未通过（%d 个问题）：%s"},
+	"xref.more":             {"... %d more", "……另有 %d 个"},
+	"xref.orphan_link":      {"link %s -> %s references a missing hit", "关联 %s -> %s 引用的命中不存在"},
+	"xref.missing_artifact": {"hit %s references missing artifact %s", "命中 %s 引用的证据 %s 不存在"},
+	"xref.cross_case":       {"hit %s (case %s) references artifact %s of case %s", "命中 %s（案件 %s）引用了案件 %[4]s 的证据 %[3]s"},
+	"xref.missing_file":     {"artifact %s referenced by hits has no snapshot file (%s)", "被命中引用的证据 %s 缺少快照文件（%s）"},
+	"xref.unlinked_hit":     {"hit %s has no linked evidence", "命中 %s 没有关联任何证据"},
+
+	// 其他服务。
+	"storage.near_quota":     {"case storage near quota: %d/%d bytes", "案件存储接近配额：%d/%d 字节"},
+	"storage.over_quota":     {"case storage exceeds quota: %d/%d bytes", "案件存储超出配额：%d/%d 字节"},
+	"relocate.files_missing": {"%d files are missing under %s; copy the evidence first or use --force", "%[2]s 下缺少 %[1]d 个文件；请先复制证据或使用 --force"},
+	"rules.read_wallet":      {"read wallet rules", "读取钱包规则失败"},
+	"rules.parse_wallet":     {"parse wallet rules", "解析钱包规则失败"},
+	"rules.read_exchange":    {"read exchange rules", "读取交易所规则失败"},
+	"rules.parse_exchange":   {"parse exchange rules", "解析交易所规则失败"},
+	"rules.read_regex":       {"read regex rules", "读取正则规则失败"},
+	"rules.parse_regex":      {"parse regex rules", "解析正则规则失败"},
+	"anchor.not_configured":  {"evidence anchoring is not configured (start serve with --anchor-config)", "未配置证据锚定（请以 --anchor-config 启动 serve）"},
+	"anchor.export_failed":   {"evidence anchor failed: %v", "证据锚定失败：%v"},
+	"job.not_cancelable":     {"job %s is not cancelable (kind=%s status=%s)", "任务 %s 不可取消（kind=%s status=%s）"},
+	"csrf.mismatch":          {"csrf token mismatch", "CSRF 令牌不匹配"},
+	"csrf.missing_header":    {"missing %s header", "缺少 %s 请求头"},
+	"csrf.cross_origin":      {"cross-origin request rejected", "已拒绝跨源请求"},
+	"csrf.cross_site":        {"cross-site request rejected", "已拒绝跨站请求"},
+	"unmask.invalid_token":   {"invalid unmask token", "脱敏解除令牌无效"},
+	"unmask.required":        {"unmask permission required (privacy mode partial): send %s", "需要脱敏解除权限（隐私模式 partial）：请携带 %s"},
+	"ratelimit.retry_after":  {"%s; retry after %ds", "%s；请在 %d 秒后重试"},
+	"ratelimit.address":      {"too many requests from this address", "该地址请求过于频繁"},
+	"ratelimit.token":        {"too many requests for this token", "该令牌请求过于频繁"},
+	"ratelimit.concurrent":   {"too many concurrent requests for this endpoint", "该接口并发请求过多"},
+	"ratelimit.export_jobs":  {"%d export jobs already running", "已有 %d 个导出任务在运行"},
+}
@@ -0,0 +1,188 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 界面/错误文本本地化（CLI 输出与 HTTP 错误消息）
+//
+// - 文本集中在本包的消息目录（catalog.go），按 key 取当前语言的模板，再用 fmt 格式化参数
+// - 语言来自 CLI --lang / 环境变量 CRYPTO_INSPECTOR_LANG，HTTP 请求的 ?lang= / Accept-Language，默认英文
+// - Localizer 随 context 传入服务层；服务返回带 key 的 apperr 错误，由 CLI/HTTP 出口按请求语言渲染
+// - 审计、报告、导出清单等持久化内容不随语言变化（仍写英文），错误码保持稳定
+//
+// 目录缺少某语言的文本时回退英文，英文也缺时原样返回 key，不会因为漏翻译而报错。
+
+// Lang 是支持的界面语言（BCP 47 标签）。
+type Lang string
+
+const (
+	// EN 英文（默认）。
+	EN Lang = "en"
+	// ZH 简体中文。
+	ZH Lang = "zh-CN"
+)
+
+// EnvVar 是指定默认语言的环境变量。
+const EnvVar = "CRYPTO_INSPECTOR_LANG"
+
+// Supported 返回支持的语言列表。
+func Supported() []Lang { return []Lang{EN, ZH} }
+
+// Parse 解析语言标签，接受 en / en-US / zh / zh-CN / zh_CN.UTF-8 / zh-Hans 等写法。
+func Parse(s string) (Lang, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(s, ".@"); i >= 0 { // POSIX locale：zh_CN.UTF-8
+		s = s[:i]
+	}
+	s = strings.ReplaceAll(s, "_", "-")
+	primary, _, _ := strings.Cut(s, "-")
+	switch primary {
+	case "en":
+		return EN, true
+	case "zh":
+		return ZH, true
+	default:
+		return "", false
+	}
+}
+
+// FromAcceptLanguage 按 q 值选出 Accept-Language 中第一个支持的语言。
+func FromAcceptLanguage(header string) (Lang, bool) {
+	type cand struct {
+		lang Lang
+		q    float64
+		pos  int
+	}
+	var cands []cand
+	for i, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, ok := Parse(tag)
+		if !ok {
+			continue
+		}
+		q := 1.0
+		if v, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			cands = append(cands, cand{lang, q, i})
+		}
+	}
+	if len(cands) == 0 {
+		return "", false
+	}
+	sort.SliceStable(cands, func(a, b int) bool { return cands[a].q > cands[b].q })
+	return cands[0].lang, true
+}
+
+// Resolve 按优先级取第一个可解析的语言（显式参数 → 环境变量 → 英文）；显式参数不支持时返回错误。
+func Resolve(explicit string) (Lang, error) {
+	if strings.TrimSpace(explicit) != "" {
+		lang, ok := Parse(explicit)
+		if !ok {
+			return EN, fmt.Errorf("unsupported language: %q (want en|zh-CN)", explicit)
+		}
+		return lang, nil
+	}
+	if lang, ok := Parse(os.Getenv(EnvVar)); ok {
+		return lang, nil
+	}
+	return EN, nil
+}
+
+// Localizer 按固定语言渲染目录中的文本；nil Localizer 按英文渲染。
+type Localizer struct {
+	lang Lang
+}
+
+// New 创建指定语言的 Localizer；不支持的语言按英文处理。
+func New(lang Lang) *Localizer {
+	if lang != ZH {
+		lang = EN
+	}
+	return &Localizer{lang: lang}
+}
+
+// Lang 返回 Localizer 的语言。
+func (l *Localizer) Lang() Lang {
+	if l == nil {
+		return EN
+	}
+	return l.lang
+}
+
+// T 渲染 key 对应的文本；参数中的 Localizable（如 Message）先按同一语言渲染。
+func (l *Localizer) T(key string, args ...any) string {
+	tmpl := lookup(l.Lang(), key)
+	if len(args) == 0 {
+		return tmpl
+	}
+	resolved := make([]any, len(args))
+	for i, a := range args {
+		if m, ok := a.(Localizable); ok {
+			resolved[i] = m.Localize(l)
+		} else {
+			resolved[i] = a
+		}
+	}
+	return fmt.Sprintf(tmpl, resolved...)
+}
+
+// English 按英文渲染 key（用于错误的 Message 字段、审计等不随语言变化的文本）。
+func English(key string, args ...any) string { return (*Localizer)(nil).T(key, args...) }
+
+func lookup(lang Lang, key string) string {
+	m, ok := catalog[key]
+	if !ok {
+		return key
+	}
+	if lang == ZH && m.zh != "" {
+		return m.zh
+	}
+	return m.en
+}
+
+// Localizable 是可按语言渲染的值（可作为 T 的参数延迟渲染）。
+type Localizable interface {
+	Localize(l *Localizer) string
+}
+
+// Message 是一条未渲染的目录文本（key + 参数），用于服务层先产出、出口再按语言渲染。
+type Message struct {
+	Key  string
+	Args []any
+}
+
+// M 构造 Message。
+func M(key string, args ...any) Message { return Message{Key: key, Args: args} }
+
+// Localize 按 l 的语言渲染。
+func (m Message) Localize(l *Localizer) string { return l.T(m.Key, m.Args...) }
+
+// String 按英文渲染。
+func (m Message) String() string { return English(m.Key, m.Args...) }
+
+type ctxKey struct{}
+
+// WithLocalizer 把 Localizer 放入 context（CLI 命令 / HTTP 请求 / 后台任务）。
+func WithLocalizer(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext 取出 context 中的 Localizer；没有时返回英文 Localizer。
+func FromContext(ctx context.Context) *Localizer {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKey{}).(*Localizer); ok && l != nil {
+			return l
+		}
+	}
+	return New(EN)
+}
@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseAndAcceptLanguage(t *testing.T) {
+	for in, want := range map[string]Lang{"en": EN, "EN-us": EN, "zh": ZH, "zh_CN.UTF-8": ZH, "zh-Hans": ZH} {
+		if got, ok := Parse(in); !ok || got != want {
+			t.Fatalf("Parse(%q)=%q,%v want %q", in, got, ok, want)
+		}
+	}
+	if _, ok := Parse("fr"); ok {
+		t.Fatal("Parse accepted an unsupported language")
+	}
+	for in, want := range map[string]Lang{
+		"zh-CN,zh;q=0.9,en;q=0.8":   ZH,
+		"fr-FR, en;q=0.5, zh;q=0.7": ZH,
+		"en-GB;q=0.9, de":           EN,
+	} {
+		if got, ok := FromAcceptLanguage(in); !ok || got != want {
+			t.Fatalf("FromAcceptLanguage(%q)=%q,%v want %q", in, got, ok, want)
+		}
+	}
+	if _, ok := FromAcceptLanguage("fr, zh;q=0"); ok {
+		t.Fatal("q=0 must exclude a language")
+	}
+
+	t.Setenv(EnvVar, "zh_CN.UTF-8")
+	if lang, err := Resolve(""); err != nil || lang != ZH {
+		t.Fatalf("Resolve env: %q err=%v", lang, err)
+	}
+	if lang, err := Resolve("en"); err != nil || lang != EN {
+		t.Fatalf("Resolve explicit: %q err=%v", lang, err)
+	}
+	if _, err := Resolve("klingon"); err == nil {
+		t.Fatal("Resolve accepted an unsupported language")
+	}
+}
+
+// 每条目录文本的中英文模板必须接受同样的参数（否则渲染出 %!v(MISSING) 之类的残片）。
+func TestCatalogTemplatesAgree(t *testing.T) {
+	for key, m := range catalog {
+		if m.en == "" || m.zh == "" {
+			t.Fatalf("%s: missing translation", key)
+		}
+		n := strings.Count(strings.ReplaceAll(m.en, "%%", ""), "%")
+		args := make([]any, n)
+		for i := range args {
+			args[i] = i
+		}
+		for lang, tmpl := range map[Lang]string{EN: m.en, ZH: m.zh} {
+			if out := fmt.Sprintf(tmpl, args...); strings.Contains(out, "MISSING") || strings.Contains(out, "EXTRA") || strings.Contains(out, "BADINDEX") {
+				t.Fatalf("%s (%s): %q", key, lang, out)
+			}
+		}
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	zh := New(ZH)
+	if got := zh.T("case.not_found", "case_1"); got != "案件不存在：case_1" {
+		t.Fatalf("zh: %q", got)
+	}
+	if got := English("case.not_found", "case_1"); got != "case not found: case_1" {
+		t.Fatalf("en: %q", got)
+	}
+	if got := zh.T("no.such.key"); got != "no.such.key" {
+		t.Fatalf("missing key: %q", got)
+	}
+	// 嵌套的 Message 按外层语言渲染。
+	if got := zh.T("ratelimit.retry_after", M("ratelimit.address"), 3); got != "该地址请求过于频繁；请在 3 秒后重试" {
+		t.Fatalf("nested: %q", got)
+	}
+	if FromContext(context.Background()).Lang() != EN || FromContext(WithLocalizer(context.Background(), zh)).Lang() != ZH {
+		t.Fatal("context localizer")
+	}
+}
@@ -0,0 +1,213 @@
+package idn
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// 国际化域名（IDN）归一化
+//
+// 钓鱼域名常用 IDN 形近字（binánce.com、coinbаse.com 中的西里尔 а）：
+// - ToUnicode 把 xn-- 开头的 punycode 标签解码为 Unicode，使浏览记录与规则在同一形式下比较
+// - Skeleton 把形近字符折叠为 ASCII 骨架（参考 Unicode confusables 的常见子集），骨架相同即为疑似仿冒
+// 只依赖标准库；解码失败的标签保持原样。
+
+const acePrefix = "xn--"
+
+// RFC 3492 参数。
+const (
+	base        int32 = 36
+	tMin        int32 = 1
+	tMax        int32 = 26
+	skew        int32 = 38
+	damp        int32 = 700
+	initialBias int32 = 72
+	initialN    int32 = 128
+	maxInt32    int32 = 1<<31 - 1
+)
+
+var errPunycode = errors.New("invalid punycode")
+
+// ToUnicode 把域名中的 punycode 标签解码为 Unicode（小写、去除末尾点）。
+func ToUnicode(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if !strings.Contains(host, acePrefix) {
+		return host
+	}
+	labels := strings.Split(host, ".")
+	for i, l := range labels {
+		if !strings.HasPrefix(l, acePrefix) {
+			continue
+		}
+		if u, err := decodePunycode(l[len(acePrefix):]); err == nil && u != "" {
+			labels[i] = u
+		}
+	}
+	return strings.Join(labels, ".")
+}
+
+// IsIDN 判断域名（解码后）是否含非 ASCII 字符。
+func IsIDN(host string) bool {
+	u := ToUnicode(host)
+	for i := 0; i < len(u); i++ {
+		if u[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// confusables 是形近字符到 ASCII 的映射（拉丁变音、西里尔、希腊字母与易混数字）。
+// i/l/1 与 o/0 互相折叠，因此骨架只用于比较，不用于展示。
+var confusables = map[rune]string{
+	// 拉丁变音
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ă': "a", 'ą': "a",
+	'ç': "c", 'ć': "c", 'č': "c",
+	'ď': "d", 'đ': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ğ': "g", 'ġ': "g", 'ɡ': "g",
+	'ì': "l", 'í': "l", 'î': "l", 'ï': "l", 'ī': "l", 'ı': "l", 'ł': "l", 'ĺ': "l", 'ľ': "l",
+	'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o",
+	'ŕ': "r", 'ř': "r",
+	'ś': "s", 'š': "s", 'ş': "s",
+	'ť': "t", 'ţ': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+	// 西里尔
+	'а': "a", 'в': "b", 'с': "c", 'ԁ': "d", 'е': "e", 'ё': "e", 'һ': "h", 'і': "l", 'ї': "l", 'ј': "j",
+	'к': "k", 'ӏ': "l", 'м': "m", 'п': "n", 'о': "o", 'р': "p", 'ԛ': "q", 'ѕ': "s", 'т': "t",
+	'у': "y", 'х': "x", 'ԝ': "w",
+	// 希腊
+	'α': "a", 'β': "b", 'ε': "e", 'η': "n", 'ι': "l", 'κ': "k", 'ν': "v", 'ο': "o", 'ρ': "p",
+	'τ': "t", 'υ': "u", 'χ': "x",
+	// ASCII 易混
+	'i': "l", '1': "l", '|': "l", '0': "o",
+}
+
+// Skeleton 返回域名的形近骨架：解码 punycode、折叠形近字符，并把 rn/vv 视为 m/w。
+func Skeleton(host string) string {
+	u := ToUnicode(host)
+	var b strings.Builder
+	b.Grow(len(u))
+	for _, r := range u {
+		if s, ok := confusables[r]; ok {
+			b.WriteString(s)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	out := b.String()
+	out = strings.ReplaceAll(out, "rn", "m")
+	out = strings.ReplaceAll(out, "vv", "w")
+	return out
+}
+
+// decodePunycode 按 RFC 3492 解码单个标签（不含 xn-- 前缀）。
+func decodePunycode(s string) (string, error) {
+	var output []rune
+	if b := strings.LastIndexByte(s, '-'); b >= 0 {
+		for i := 0; i < b; i++ {
+			if s[i] >= utf8.RuneSelf {
+				return "", errPunycode
+			}
+			output = append(output, rune(s[i]))
+		}
+		s = s[b+1:]
+	}
+
+	n, i, bias := initialN, int32(0), initialBias
+	for pos := 0; pos < len(s); {
+		oldi, w := i, int32(1)
+		for k := base; ; k += base {
+			if pos >= len(s) {
+				return "", errPunycode
+			}
+			digit := punyDigit(s[pos])
+			pos++
+			if digit < 0 || digit > (maxInt32-i)/w {
+				return "", errPunycode
+			}
+			i += digit * w
+			t := k - bias
+			if t < tMin {
+				t = tMin
+			} else if t > tMax {
+				t = tMax
+			}
+			if digit < t {
+				break
+			}
+			if w > maxInt32/(base-t) {
+				return "", errPunycode
+			}
+			w *= base - t
+		}
+		count := int32(len(output) + 1)
+		bias = adapt(i-oldi, count, oldi == 0)
+		if i/count > maxInt32-n {
+			return "", errPunycode
+		}
+		n += i / count
+		i %= count
+		if n > utf8.MaxRune {
+			return "", errPunycode
+		}
+		output = append(output, 0)
+		copy(output[i+1:], output[i:])
+		output[i] = n
+		i++
+	}
+	return string(output), nil
+}
+
+func punyDigit(c byte) int32 {
+	switch {
+	case c >= '0' && c <= '9':
+		return int32(c-'0') + 26
+	case c >= 'a' && c <= 'z':
+		return int32(c - 'a')
+	case c >= 'A' && c <= 'Z':
+		return int32(c - 'A')
+	}
+	return -1
+}
+
+func adapt(delta, numPoints int32, first bool) int32 {
+	if first {
+		delta /= damp
+	} else {
+		delta /= 2
+	}
+	delta += delta / numPoints
+	k := int32(0)
+	for delta > ((base-tMin)*tMax)/2 {
+		delta /= base - tMin
+		k += base
+	}
+	return k + (base-tMin+1)*delta/(delta+skew)
+}
+
+// EditDistance 返回两个字符串按 rune 计算的 Levenshtein 编辑距离（插入/删除/替换各计 1）。
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
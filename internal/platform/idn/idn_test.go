@@ -0,0 +1,55 @@
+package idn
+
+import "testing"
+
+func TestToUnicode(t *testing.T) {
+	cases := map[string]string{
+		"xn--bcher-kva.example":   "bücher.example",
+		"XN--BINNCE-RTA.COM.":     "binánce.com",
+		"accounts.xn--kx-emc.com": "accounts.оkx.com",
+		"binance.com":             "binance.com",
+		"xn--!!.com":              "xn--!!.com",
+	}
+	for in, want := range cases {
+		if got := ToUnicode(in); got != want {
+			t.Fatalf("ToUnicode(%q)=%q want %q", in, got, want)
+		}
+	}
+	if !IsIDN("xn--coinbse-6fg.com") || IsIDN("coinbase.com") {
+		t.Fatal("IsIDN mismatch")
+	}
+}
+
+func TestSkeleton(t *testing.T) {
+	want := Skeleton("binance.com")
+	for _, lookalike := range []string{"xn--binnce-rta.com", "b1nance.com", "bіnance.com"} {
+		if got := Skeleton(lookalike); got != want {
+			t.Fatalf("Skeleton(%q)=%q want %q", lookalike, got, want)
+		}
+	}
+	if Skeleton("xn--coinbse-6fg.com") != Skeleton("coinbase.com") || Skeleton("rnexc.com") != Skeleton("mexc.com") {
+		t.Fatal("skeleton should fold cyrillic a and rn")
+	}
+	if Skeleton("kraken.com") == Skeleton("binance.com") {
+		t.Fatal("distinct domains should not collide")
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"binance.com", "binance.com", 0},
+		{"binnance.com", "binance.com", 1},
+		{"bnance.com", "binance.com", 1},
+		{"coinbase.com", "colnbase.com", 1},
+		{"kraken.com", "binance.com", 6},
+		{"", "okx", 3},
+	}
+	for _, c := range cases {
+		if got := EditDistance(c.a, c.b); got != c.want {
+			t.Fatalf("EditDistance(%q,%q)=%d want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package progress
+
+import (
+	"context"
+	"sync"
+)
+
+// 长任务进度与取消
+//
+// 报告/导出包生成在大案件（数万条命中、上千份证据）上耗时较长。生成函数按有序阶段创建 Tracker，
+// 在循环中调用 Step：
+// - 进度经 context 上挂的回调（WithReporter）上报，换算为整体百分比，同一百分比只回调一次
+// - 每次 Step 同时检查 ctx，客户端断开或调用取消接口后返回 ctx.Err()，生成函数据此中止并清理半成品
+// 未挂回调时 Step 只做取消检查。
+
+// Update 是一次进度快照。
+type Update struct {
+	Stage   string `json:"stage"`
+	Done    int    `json:"done"`
+	Total   int    `json:"total"`
+	Percent int    `json:"percent"` // 整体进度 0-100（各阶段等权）
+}
+
+// Reporter 接收进度快照；在生成 goroutine 中同步调用，应尽快返回。
+type Reporter func(Update)
+
+type reporterKey struct{}
+
+// WithReporter 把 fn 挂到 ctx 上（fn 为 nil 时原样返回）。
+func WithReporter(ctx context.Context, fn Reporter) context.Context {
+	if fn == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, reporterKey{}, fn)
+}
+
+// Tracker 把有序阶段上的进度换算为整体百分比并上报（并发安全）。
+type Tracker struct {
+	ctx    context.Context
+	fn     Reporter
+	stages []string
+
+	mu   sync.Mutex
+	last Update
+}
+
+// Start 按阶段列表创建 Tracker，并上报第一个阶段的 0%。
+func Start(ctx context.Context, stages ...string) *Tracker {
+	fn, _ := ctx.Value(reporterKey{}).(Reporter)
+	t := &Tracker{ctx: ctx, fn: fn, stages: stages, last: Update{Percent: -1}}
+	if len(stages) > 0 {
+		_ = t.Step(stages[0], 0, 0)
+	}
+	return t
+}
+
+// Step 上报 stage 阶段已完成 done/total（total<=0 表示阶段开始、条目数未知），并返回 ctx.Err()。
+func (t *Tracker) Step(stage string, done, total int) error {
+	if t == nil {
+		return nil
+	}
+	if err := t.ctx.Err(); err != nil {
+		return err
+	}
+	if t.fn == nil {
+		return nil
+	}
+	u := Update{Stage: stage, Done: done, Total: total, Percent: t.percent(stage, done, total)}
+	t.mu.Lock()
+	if u.Stage == t.last.Stage && u.Percent == t.last.Percent && done != total {
+		t.mu.Unlock()
+		return nil
+	}
+	t.last = u
+	t.mu.Unlock()
+	t.fn(u)
+	return nil
+}
+
+// Done 上报全部完成（100%）。
+func (t *Tracker) Done() {
+	if t == nil || t.fn == nil {
+		return
+	}
+	t.fn(Update{Stage: "finished", Percent: 100})
+}
+
+func (t *Tracker) percent(stage string, done, total int) int {
+	n := len(t.stages)
+	idx := n - 1
+	for i, s := range t.stages {
+		if s == stage {
+			idx = i
+			break
+		}
+	}
+	if n == 0 || idx < 0 {
+		return 0
+	}
+	frac := 0.0
+	if total > 0 {
+		frac = float64(min(done, total)) / float64(total)
+	}
+	// 完成前最多报 99%，100% 留给 Done。
+	return min(int((float64(idx)+frac)/float64(n)*100), 99)
+}
@@ -0,0 +1,40 @@
+package progress
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTrackerPercentAndCancel(t *testing.T) {
+	var got []Update
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tr := Start(WithReporter(ctx, func(u Update) { got = append(got, u) }), "load", "package")
+	for i := 0; i <= 4; i++ {
+		if err := tr.Step("package", i, 4); err != nil {
+			t.Fatalf("Step: %v", err)
+		}
+	}
+	_ = tr.Step("package", 4, 4) // 重复的完成快照仍上报
+	tr.Done()
+
+	want := []int{0, 50, 62, 75, 87, 99, 99, 100}
+	if len(got) != len(want) {
+		t.Fatalf("updates=%+v", got)
+	}
+	for i, u := range got {
+		if u.Percent != want[i] {
+			t.Fatalf("update %d percent=%d want=%d (%+v)", i, u.Percent, want[i], got)
+		}
+	}
+
+	cancel()
+	if err := tr.Step("package", 1, 4); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Step after cancel err=%v", err)
+	}
+	var nilTracker *Tracker
+	if err := nilTracker.Step("x", 1, 1); err != nil {
+		t.Fatalf("nil tracker err=%v", err)
+	}
+}
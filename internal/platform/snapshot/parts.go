@@ -0,0 +1,70 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// 超大 JSON 证据分片
+//
+// 单条证据的 payload 过大（例如 200MB 的浏览记录）会拖垮导出与 API。payload 为 JSON 数组且超过上限时，
+// 按元素顺序拆成多个分片，每个分片本身仍是合法的 JSON 数组（与整体相同的缩进格式），作为独立证据入库；
+// 按分片序号依次拼接即可还原为与拆分前逐字节一致的 payload。
+
+// SplitJSONArray 把 MarshalIndent("", "  ") 生成的 JSON 数组按 maxBytes 拆分为多个分片。
+//
+// 不需要拆分（maxBytes <= 0、未超过上限、不是数组或元素不足两个）时返回 nil；
+// 单个元素本身超过上限时独占一个分片。
+func SplitJSONArray(raw []byte, maxBytes int64) [][]byte {
+	if maxBytes <= 0 || int64(len(raw)) <= maxBytes {
+		return nil
+	}
+	var elems []json.RawMessage
+	if err := json.Unmarshal(raw, &elems); err != nil || len(elems) < 2 {
+		return nil
+	}
+
+	var parts [][]byte
+	var cur []json.RawMessage
+	// 分片字节数："[\n" + 每个元素（两空格缩进 + 内容 + ",\n"，最后一个不带逗号）+ "]"。
+	size := int64(3)
+	flush := func() bool {
+		b, err := json.MarshalIndent(cur, "", "  ")
+		if err != nil {
+			return false
+		}
+		parts = append(parts, b)
+		cur, size = nil, 3
+		return true
+	}
+	for _, e := range elems {
+		n := int64(len(e)) + 4
+		if len(cur) > 0 && size+n > maxBytes {
+			if !flush() {
+				return nil
+			}
+		}
+		cur = append(cur, e)
+		size += n
+	}
+	if len(cur) > 0 && !flush() {
+		return nil
+	}
+	if len(parts) < 2 {
+		return nil
+	}
+	return parts
+}
+
+// JoinJSONArrays 按顺序拼接 SplitJSONArray 拆出的分片，还原为完整的 JSON 数组。
+func JoinJSONArrays(parts [][]byte) ([]byte, error) {
+	all := []json.RawMessage{}
+	for i, p := range parts {
+		var elems []json.RawMessage
+		if err := json.Unmarshal(p, &elems); err != nil {
+			return nil, fmt.Errorf("decode payload part %d: %w", i+1, err)
+		}
+		all = append(all, elems...)
+	}
+	return json.MarshalIndent(all, "", "  ")
+}
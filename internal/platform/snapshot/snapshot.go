@@ -0,0 +1,142 @@
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// JSON 证据快照的压缩存储
+//
+// 浏览历史等 JSON 快照压缩率很高，可按 gzip 写成 *.json.gz：
+// - 入库 sha256 / size_bytes 始终针对磁盘上实际存储的字节（压缩后），复核时无需解压；
+// - 读取方统一用 Open / ReadFile，按文件头（1f 8b）透明解压，未压缩的历史快照不受影响。
+// zstd 需要额外依赖，当前构建只支持 gzip。
+
+// 压缩方式（artifacts.snapshot_compression）。
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+)
+
+// GzipExt 是 gzip 压缩快照追加的扩展名。
+const GzipExt = ".gz"
+
+// MaxDecompressedBytes 限制单个快照解压后的大小，防止异常文件耗尽内存。
+const MaxDecompressedBytes = 1 << 30
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// ParseCompression 规范化压缩方式参数：空 / none → none；gzip / gz → gzip。
+func ParseCompression(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", CompressionNone:
+		return CompressionNone, nil
+	case CompressionGzip, "gz":
+		return CompressionGzip, nil
+	case "zstd", "zst":
+		return "", fmt.Errorf("snapshot compression %q is not supported in this build (use gzip)", s)
+	default:
+		return "", fmt.Errorf("invalid snapshot compression %q (none|gzip)", s)
+	}
+}
+
+// WriteJSON 按压缩方式写入 JSON 快照，返回实际路径（gzip 时追加 .gz）。
+//
+// gzip 头不写文件名与修改时间，同样的内容得到同样的压缩字节。
+func WriteJSON(path string, raw []byte, compression string) (string, error) {
+	if compression != CompressionGzip {
+		return path, os.WriteFile(path, raw, 0o644)
+	}
+	path += GzipExt
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := zw.Write(raw); err != nil {
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// IsCompressed 按文件头判断快照是否为 gzip 压缩。
+func IsCompressed(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, len(gzipMagic))
+	n, _ := io.ReadFull(f, head)
+	return bytes.Equal(head[:n], gzipMagic)
+}
+
+// Open 打开快照并返回解压后的内容流；compression 为实际识别到的压缩方式。
+func Open(path string) (io.ReadCloser, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	br := bufio.NewReader(f)
+	head, _ := br.Peek(len(gzipMagic))
+	if !bytes.Equal(head, gzipMagic) {
+		return readCloser{Reader: br, closers: []io.Closer{f}}, CompressionNone, nil
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		f.Close()
+		return nil, "", fmt.Errorf("open gzip snapshot %s: %w", path, err)
+	}
+	limited := io.LimitReader(zr, MaxDecompressedBytes)
+	return readCloser{Reader: limited, closers: []io.Closer{zr, f}}, CompressionGzip, nil
+}
+
+// ReadFile 读取快照内容（gzip 快照透明解压）。
+func ReadFile(path string) ([]byte, error) {
+	rc, _, err := Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	raw, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot %s: %w", path, err)
+	}
+	return raw, nil
+}
+
+// Decompress 对内存中的快照字节透明解压（未压缩时原样返回）。
+func Decompress(raw []byte) ([]byte, error) {
+	if !bytes.HasPrefix(raw, gzipMagic) {
+		return raw, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("open gzip snapshot: %w", err)
+	}
+	defer zr.Close()
+	return io.ReadAll(io.LimitReader(zr, MaxDecompressedBytes))
+}
+
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r readCloser) Close() error {
+	var first error
+	for _, c := range r.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
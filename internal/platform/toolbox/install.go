@@ -0,0 +1,299 @@
+package toolbox
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/apperr"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bundle 是清单中一个经过核验的工具包（见 rules/tool_bundles.template.yaml）。
+type Bundle struct {
+	Name    string `yaml:"name" json:"name"`
+	Version string `yaml:"version" json:"version"`
+	// OS 为适用平台（windows|darwin|linux），与 runtime.GOOS 比较。
+	OS  string `yaml:"os" json:"os"`
+	URL string `yaml:"url,omitempty" json:"url,omitempty"`
+	// SHA256 为归档文件的固定哈希；为空的条目拒绝安装。
+	SHA256 string `yaml:"sha256" json:"sha256"`
+	// StripPrefix 为归档内需要去掉的目录前缀（如 platform-tools/）。
+	StripPrefix string `yaml:"strip_prefix,omitempty" json:"strip_prefix,omitempty"`
+	// Tools 为 工具名 -> 归档内相对路径（去掉前缀后）；未列出的文件（DLL 等）照常解压但不登记。
+	Tools map[string]string `yaml:"tools" json:"tools"`
+}
+
+type catalogFile struct {
+	Bundles []Bundle `yaml:"bundles"`
+}
+
+// LoadCatalog 读取工具包清单。
+func LoadCatalog(p string) ([]Bundle, error) {
+	raw, err := os.ReadFile(p)
+	if err != nil {
+		return nil, fmt.Errorf("read tool catalog: %w", err)
+	}
+	var f catalogFile
+	if err := yaml.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("parse tool catalog: %w", err)
+	}
+	return f.Bundles, nil
+}
+
+// FindBundle 在清单中查找适用于当前平台的工具包。
+func FindBundle(bundles []Bundle, name string) (*Bundle, error) {
+	for i := range bundles {
+		b := bundles[i]
+		if b.Name == name && (b.OS == "" || b.OS == runtime.GOOS) {
+			return &b, nil
+		}
+	}
+	return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("tool bundle %q not found for %s", name, runtime.GOOS))
+}
+
+// InstallOptions 定义安装参数。
+type InstallOptions struct {
+	// Dir 为受管目录（空表示 Dir()）。
+	Dir string
+	// Archive 为本地归档路径（离线安装）；为空时从 Bundle.URL 下载。
+	Archive string
+	// Timeout 为下载超时（零值 5 分钟）。
+	Timeout time.Duration
+}
+
+// Install 校验归档哈希后解压到受管目录，并在 manifest 中登记工具。
+func Install(ctx context.Context, b Bundle, opts InstallOptions) ([]Entry, error) {
+	if strings.TrimSpace(b.SHA256) == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("tool bundle %s has no pinned sha256; refusing to install", b.Name))
+	}
+	if len(b.Tools) == 0 {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("tool bundle %s lists no tools", b.Name))
+	}
+	d := strings.TrimSpace(opts.Dir)
+	if d == "" {
+		d = Dir()
+	}
+	if err := os.MkdirAll(d, 0o755); err != nil {
+		return nil, fmt.Errorf("create tools dir: %w", err)
+	}
+
+	archive := strings.TrimSpace(opts.Archive)
+	if archive == "" {
+		downloaded, err := download(ctx, b, d, opts.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		defer os.Remove(downloaded)
+		archive = downloaded
+	}
+	sum, err := hashFile(archive)
+	if err != nil {
+		return nil, fmt.Errorf("hash archive: %w", err)
+	}
+	if !strings.EqualFold(sum, strings.TrimSpace(b.SHA256)) {
+		return nil, apperr.New(apperr.CodeConflict, fmt.Sprintf("archive sha256 mismatch for %s: got %s want %s", b.Name, sum, b.SHA256))
+	}
+
+	rel := path.Join(b.Name, b.Version)
+	dest := filepath.Join(d, filepath.FromSlash(rel))
+	tmp := dest + ".tmp"
+	_ = os.RemoveAll(tmp)
+	if err := extract(archive, tmp, b.StripPrefix); err != nil {
+		_ = os.RemoveAll(tmp)
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(b.Tools))
+	for name, p := range b.Tools {
+		full := filepath.Join(tmp, filepath.FromSlash(p))
+		sum, err := hashFile(full)
+		if err != nil {
+			_ = os.RemoveAll(tmp)
+			return nil, fmt.Errorf("tool %s not found in bundle %s: %w", name, b.Name, err)
+		}
+		if runtime.GOOS != "windows" {
+			_ = os.Chmod(full, 0o755)
+		}
+		entries = append(entries, Entry{Name: name, Path: path.Join(rel, p), SHA256: sum, Bundle: b.Name, Version: b.Version})
+	}
+
+	// 同版本重复安装：旧目录改名后替换，失败时不留下半成品。
+	if _, err := os.Stat(dest); err == nil {
+		old := dest + ".old-" + time.Now().Format("20060102-150405")
+		if err := os.Rename(dest, old); err != nil {
+			_ = os.RemoveAll(tmp)
+			return nil, fmt.Errorf("replace bundle dir: %w", err)
+		}
+		defer os.RemoveAll(old)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.RemoveAll(tmp)
+		return nil, fmt.Errorf("install bundle dir: %w", err)
+	}
+
+	m, err := LoadManifest(d)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		m.Tools[e.Name] = e
+	}
+	if err := SaveManifest(d, m); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func download(ctx context.Context, b Bundle, d string, timeout time.Duration) (string, error) {
+	if strings.TrimSpace(b.URL) == "" {
+		return "", apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("tool bundle %s has no url; use --from to install from a local archive", b.Name))
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.URL, nil)
+	if err != nil {
+		return "", fmt.Errorf("build download request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", apperr.Wrap(apperr.CodeUpstreamUnavailable, err, "download tool bundle")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", apperr.New(apperr.CodeUpstreamUnavailable, fmt.Sprintf("download tool bundle: http %d", resp.StatusCode))
+	}
+	f, err := os.CreateTemp(d, "download-*"+archiveExt(b.URL))
+	if err != nil {
+		return "", fmt.Errorf("create download file: %w", err)
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("download tool bundle: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+func archiveExt(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ".tar.gz"
+	default:
+		return ".zip"
+	}
+}
+
+// extract 解压 zip / tar.gz 到 dest，去掉 strip 前缀并拒绝越界路径。
+func extract(archive, dest, strip string) error {
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return err
+	}
+	if archiveExt(archive) == ".tar.gz" {
+		return extractTarGz(archive, dest, strip)
+	}
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return fmt.Errorf("open zip: %w", err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		target, ok := targetPath(dest, f.Name, strip)
+		if !ok || f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("open %s: %w", f.Name, err)
+		}
+		err = writeFile(target, rc, f.Mode())
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractTarGz(archive, dest, strip string) error {
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("open tar.gz: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read tar: %w", err)
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		target, ok := targetPath(dest, h.Name, strip)
+		if !ok {
+			continue
+		}
+		if err := writeFile(target, tr, h.FileInfo().Mode()); err != nil {
+			return err
+		}
+	}
+}
+
+// targetPath 计算归档条目的落盘路径；不在 strip 前缀下或越出 dest 的条目返回 false。
+func targetPath(dest, name, strip string) (string, bool) {
+	name = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, `\`, "/")), "/")
+	if strip = strings.Trim(strip, "/"); strip != "" {
+		if !strings.HasPrefix(name, strip+"/") {
+			return "", false
+		}
+		name = strings.TrimPrefix(name, strip+"/")
+	}
+	if name == "" || name == "." {
+		return "", false
+	}
+	return filepath.Join(dest, filepath.FromSlash(name)), true
+}
+
+func writeFile(target string, r io.Reader, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	perm := mode.Perm() | 0o600
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", target, err)
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return fmt.Errorf("extract %s: %w", target, err)
+	}
+	return out.Close()
+}
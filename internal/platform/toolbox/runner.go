@@ -0,0 +1,76 @@
+package toolbox
+
+import (
+	"context"
+)
+
+// 命令执行器
+//
+// 采集适配器（mobile 的 adb / libimobiledevice / hdc，host 的 powershell / ps / security 等）不直接创建 exec.Cmd，
+// 而是通过 context 中的 Runner 定位与执行外部命令：未注入时为 ExecRunner（受管目录优先，见 Command）；
+// 测试与 CI 用 WithRunner 注入模拟设备（见 services/devicesim），不接真实设备也能跑完整的扫描流程。
+
+// SourceSimulated 是模拟执行器定位到的工具来源（前置检查记录中可据此区分真实采集与模拟数据）。
+const SourceSimulated = "simulated"
+
+// Runner 定位并执行外部命令。
+type Runner interface {
+	// Resolve 定位工具，语义同包级 Resolve。
+	Resolve(name string) (*Resolved, error)
+	// Output 运行命令并返回标准输出（同 exec.Cmd.Output）。
+	Output(ctx context.Context, name string, args ...string) ([]byte, error)
+	// CombinedOutput 运行命令并返回标准输出与标准错误的合并内容（同 exec.Cmd.CombinedOutput）。
+	CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// ExecRunner 在本机执行命令（受管目录优先，其次系统 PATH）。
+type ExecRunner struct{}
+
+func (ExecRunner) Resolve(name string) (*Resolved, error) { return Resolve(name) }
+
+func (ExecRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return Command(ctx, name, args...).Output()
+}
+
+func (ExecRunner) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return Command(ctx, name, args...).CombinedOutput()
+}
+
+type runnerKey struct{}
+
+// WithRunner 返回使用 r 执行外部命令的 context（r 为 nil 时恢复默认执行器）。
+func WithRunner(ctx context.Context, r Runner) context.Context {
+	return context.WithValue(ctx, runnerKey{}, r)
+}
+
+// RunnerFrom 返回 context 中的执行器；未注入时返回 ExecRunner。
+func RunnerFrom(ctx context.Context) Runner {
+	if r, ok := ctx.Value(runnerKey{}).(Runner); ok && r != nil {
+		return r
+	}
+	return ExecRunner{}
+}
+
+// ResolveContext 通过 context 中的执行器定位工具。
+func ResolveContext(ctx context.Context, name string) (*Resolved, error) {
+	return RunnerFrom(ctx).Resolve(name)
+}
+
+// LookPathContext 通过 context 中的执行器返回工具的可执行路径。
+func LookPathContext(ctx context.Context, name string) (string, error) {
+	r, err := ResolveContext(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return r.Path, nil
+}
+
+// Output 通过 context 中的执行器运行命令并返回标准输出。
+func Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return RunnerFrom(ctx).Output(ctx, name, args...)
+}
+
+// CombinedOutput 通过 context 中的执行器运行命令并返回合并输出。
+func CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return RunnerFrom(ctx).CombinedOutput(ctx, name, args...)
+}
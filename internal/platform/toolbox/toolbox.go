@@ -0,0 +1,272 @@
+package toolbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 外部工具管理（adb / libimobiledevice / hdc）
+//
+// 现场电脑经常没有装 adb、ideviceinstaller 等工具，或装的是来源不明的版本。本包维护一个受管目录：
+// - tools install 把经过核验的工具包（归档 sha256 固定在清单里）解压到 <dir>/<bundle>/<version>/，
+//   并在 <dir>/manifest.json 记录每个可执行文件的路径、sha256、所属工具包与版本
+// - 采集时 LookPath/Command 优先使用受管工具，且每次使用前按 manifest 校验哈希；
+//   受管文件被替换/损坏时直接报错，不会静默退回系统 PATH
+// - 受管目录中没有的工具才使用系统 PATH，前置检查记录实际使用的来源、路径与版本
+
+// DefaultDir 是默认的受管目录（相对工作目录，与 data/inspector.db 同级）。
+const DefaultDir = "data/tools"
+
+// ManifestName 是受管目录下的清单文件名。
+const ManifestName = "manifest.json"
+
+// 工具来源。
+const (
+	SourceManaged = "managed"
+	SourceSystem  = "system"
+)
+
+// ErrVerification 表示受管工具与 manifest 登记的哈希不一致（被替换或损坏）。
+var ErrVerification = errors.New("managed tool failed verification")
+
+// Known 是采集流程会调用的工具（tools list 默认展示）。
+var Known = []string{"adb", "idevice_id", "ideviceinfo", "idevicepair", "ideviceinstaller", "idevicebackup2", "hdc"}
+
+// Entry 是 manifest 中的一个受管可执行文件。
+type Entry struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"` // 相对受管目录
+	SHA256  string `json:"sha256"`
+	Bundle  string `json:"bundle"`
+	Version string `json:"version"`
+}
+
+// Manifest 是受管目录清单。
+type Manifest struct {
+	UpdatedAt int64            `json:"updated_at"`
+	Tools     map[string]Entry `json:"tools"`
+}
+
+// Resolved 是一次工具定位的结果。
+type Resolved struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Source  string `json:"source"` // managed|system
+	Bundle  string `json:"bundle,omitempty"`
+	Version string `json:"version,omitempty"`
+	SHA256  string `json:"sha256,omitempty"`
+}
+
+var (
+	mu  sync.RWMutex
+	dir = DefaultDir
+
+	// verified 缓存已校验的受管文件（路径 -> 大小/修改时间/哈希），避免每次调用都重新计算哈希。
+	verifiedMu sync.Mutex
+	verified   = map[string]fileStamp{}
+)
+
+type fileStamp struct {
+	size    int64
+	modTime int64
+	sha256  string
+}
+
+// SetDir 设置受管目录（空串恢复默认）。
+func SetDir(d string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if d = strings.TrimSpace(d); d == "" {
+		d = DefaultDir
+	}
+	dir = d
+}
+
+// Dir 返回当前受管目录。
+func Dir() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return dir
+}
+
+// LoadManifest 读取受管目录清单；不存在时返回空清单。
+func LoadManifest(d string) (*Manifest, error) {
+	raw, err := os.ReadFile(filepath.Join(d, ManifestName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Manifest{Tools: map[string]Entry{}}, nil
+		}
+		return nil, fmt.Errorf("read tools manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, fmt.Errorf("parse tools manifest: %w", err)
+	}
+	if m.Tools == nil {
+		m.Tools = map[string]Entry{}
+	}
+	return &m, nil
+}
+
+// SaveManifest 原子写入受管目录清单。
+func SaveManifest(d string, m *Manifest) error {
+	m.UpdatedAt = time.Now().Unix()
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(d, ManifestName)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("write tools manifest: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write tools manifest: %w", err)
+	}
+	return nil
+}
+
+// Resolve 定位工具：受管目录优先（校验哈希），否则使用系统 PATH。
+func Resolve(name string) (*Resolved, error) {
+	d := Dir()
+	m, err := LoadManifest(d)
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := m.Tools[name]; ok {
+		path := filepath.Join(d, filepath.FromSlash(e.Path))
+		if err := verifyEntry(path, e.SHA256); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrVerification, name, err)
+		}
+		return &Resolved{Name: name, Path: path, Source: SourceManaged, Bundle: e.Bundle, Version: e.Version, SHA256: e.SHA256}, nil
+	}
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s not found (not in managed tools dir %s or PATH)", name, d)
+	}
+	return &Resolved{Name: name, Path: path, Source: SourceSystem}, nil
+}
+
+// LookPath 返回工具的可执行路径（语义同 exec.LookPath，受管目录优先）。
+func LookPath(name string) (string, error) {
+	r, err := Resolve(name)
+	if err != nil {
+		return "", err
+	}
+	return r.Path, nil
+}
+
+// Command 以定位到的工具创建命令；定位失败时仍按原名创建，让 Run 返回与 exec 一致的错误。
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	path, err := LookPath(name)
+	if err != nil {
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Err = err
+		return cmd
+	}
+	return exec.CommandContext(ctx, path, args...)
+}
+
+// versionArgs 是各工具输出版本号的参数。
+var versionArgs = map[string][]string{
+	"adb": {"version"},
+	"hdc": {"-v"},
+}
+
+// Version 运行工具的版本命令并返回首行输出（受管工具与模拟工具直接返回登记的版本）。
+// 命令经 context 中的执行器运行，见 runner.go。
+func Version(ctx context.Context, r *Resolved) string {
+	if r == nil {
+		return ""
+	}
+	if r.Source != SourceSystem && r.Version != "" {
+		return r.Version
+	}
+	args, ok := versionArgs[r.Name]
+	if !ok {
+		args = []string{"--version"}
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	out, err := RunnerFrom(ctx).CombinedOutput(ctx, r.Name, args...)
+	if err != nil && len(out) == 0 {
+		return ""
+	}
+	for _, line := range strings.Split(string(bytes.TrimSpace(out)), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// Verify 重新校验受管目录中全部工具的哈希（不使用缓存），返回 名称 -> 错误（nil 表示通过）。
+func Verify(d string) (map[string]error, error) {
+	m, err := LoadManifest(d)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]error, len(m.Tools))
+	for name, e := range m.Tools {
+		path := filepath.Join(d, filepath.FromSlash(e.Path))
+		sum, err := hashFile(path)
+		switch {
+		case err != nil:
+			out[name] = err
+		case sum != e.SHA256:
+			out[name] = fmt.Errorf("sha256 mismatch: got %s want %s", sum, e.SHA256)
+		default:
+			out[name] = nil
+		}
+	}
+	return out, nil
+}
+
+func verifyEntry(path, want string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	verifiedMu.Lock()
+	st, ok := verified[path]
+	verifiedMu.Unlock()
+	if ok && st.size == info.Size() && st.modTime == info.ModTime().UnixNano() && st.sha256 == want {
+		return nil
+	}
+	sum, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+	if sum != want {
+		return fmt.Errorf("sha256 mismatch: got %s want %s", sum, want)
+	}
+	verifiedMu.Lock()
+	verified[path] = fileStamp{size: info.Size(), modTime: info.ModTime().UnixNano(), sha256: sum}
+	verifiedMu.Unlock()
+	return nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,87 @@
+package toolbox
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"crypto-inspector/internal/domain/apperr"
+)
+
+func TestInstallAndResolveManaged(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	toolsDir := filepath.Join(dir, "tools")
+	archive := filepath.Join(dir, "platform-tools.zip")
+
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for name, body := range map[string]string{
+		"platform-tools/adb":          "#!/bin/sh\necho adb\n",
+		"platform-tools/lib/helper":   "x",
+		"platform-tools/../../escape": "bad",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(body))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	sum, err := hashFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := Bundle{Name: "platform-tools", Version: "35.0.2", SHA256: sum, StripPrefix: "platform-tools/", Tools: map[string]string{"adb": "adb"}}
+	if _, err := Install(ctx, Bundle{Name: "x", Tools: b.Tools}, InstallOptions{Dir: toolsDir, Archive: archive}); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("unpinned bundle err=%v", err)
+	}
+	bad := b
+	bad.SHA256 = "00"
+	if _, err := Install(ctx, bad, InstallOptions{Dir: toolsDir, Archive: archive}); apperr.CodeOf(err) != apperr.CodeConflict {
+		t.Fatalf("hash mismatch err=%v", err)
+	}
+
+	entries, err := Install(ctx, b, InstallOptions{Dir: toolsDir, Archive: archive})
+	if err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Path != "platform-tools/35.0.2/adb" {
+		t.Fatalf("entries=%+v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "escape")); err == nil {
+		t.Fatalf("zip entry escaped the tools dir")
+	}
+
+	SetDir(toolsDir)
+	defer SetDir("")
+	r, err := Resolve("adb")
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if r.Source != SourceManaged || r.Version != "35.0.2" || Version(ctx, r) != "35.0.2" {
+		t.Fatalf("resolved=%+v", r)
+	}
+
+	// 受管文件被替换后拒绝使用，不退回系统 PATH。
+	if err := os.WriteFile(r.Path, []byte("tampered"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Resolve("adb"); !errors.Is(err, ErrVerification) {
+		t.Fatalf("expected tampered tool to fail verification")
+	}
+	res, err := Verify(toolsDir)
+	if err != nil || res["adb"] == nil {
+		t.Fatalf("Verify=%v err=%v", res, err)
+	}
+}
@@ -0,0 +1,169 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 轻量请求追踪（不引入 OpenTelemetry 依赖）
+//
+// - 一次 HTTP 请求 / 一次 CLI 命令对应一个 trace_id（32 位 hex，与 W3C traceparent 的 trace-id 格式一致）
+// - 服务层用 Start/End 记录 span 耗时；span 记录默认丢弃，可通过 SetSink 输出到日志
+// - 审计日志写入时自动带上 trace_id，便于把“慢扫描/失败导出”与审计留痕对应起来
+
+type ctxKey struct{}
+
+type spanState struct {
+	traceID string
+	spanID  string
+}
+
+// SpanRecord 是一个结束的 span（用于日志输出或外部采集）。
+type SpanRecord struct {
+	TraceID    string
+	SpanID     string
+	ParentID   string
+	Name       string
+	StartedAt  time.Time
+	DurationMS int64
+	Err        string
+}
+
+var (
+	sinkMu sync.RWMutex
+	sink   func(SpanRecord)
+)
+
+// SetSink 设置 span 结束时的回调（nil 表示丢弃）。
+func SetSink(fn func(SpanRecord)) {
+	sinkMu.Lock()
+	defer sinkMu.Unlock()
+	sink = fn
+}
+
+// WriterSink 返回一个把 span 以 key=value 单行格式写入 w 的 sink。
+func WriterSink(w io.Writer) func(SpanRecord) {
+	var mu sync.Mutex
+	return func(r SpanRecord) {
+		mu.Lock()
+		defer mu.Unlock()
+		line := fmt.Sprintf("trace=%s span=%s parent=%s name=%s dur_ms=%d", r.TraceID, r.SpanID, r.ParentID, r.Name, r.DurationMS)
+		if r.Err != "" {
+			line += fmt.Sprintf(" err=%q", r.Err)
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+}
+
+// NewTraceID 生成 32 位 hex trace_id。
+func NewTraceID() string {
+	return randomHex(16)
+}
+
+func newSpanID() string {
+	return randomHex(8)
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// WithTraceID 把 trace_id 放入 context（会开启一个新的根 span 作用域）。
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	traceID = strings.ToLower(strings.TrimSpace(traceID))
+	if traceID == "" {
+		traceID = NewTraceID()
+	}
+	return context.WithValue(ctx, ctxKey{}, spanState{traceID: traceID})
+}
+
+// IDFromContext 返回 context 中的 trace_id（没有则返回空字符串）。
+func IDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	st, _ := ctx.Value(ctxKey{}).(spanState)
+	return st.traceID
+}
+
+// Ensure 保证 context 中存在 trace_id（CLI 等没有入口中间件的场景使用）。
+func Ensure(ctx context.Context) (context.Context, string) {
+	if tid := IDFromContext(ctx); tid != "" {
+		return ctx, tid
+	}
+	ctx = WithTraceID(ctx, "")
+	return ctx, IDFromContext(ctx)
+}
+
+// Span 表示一个进行中的 span。
+type Span struct {
+	rec   SpanRecord
+	ended bool
+}
+
+// Start 开启一个子 span；context 中没有 trace_id 时自动生成。
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	ctx, traceID := Ensure(ctx)
+	parent, _ := ctx.Value(ctxKey{}).(spanState)
+	spanID := newSpanID()
+	ctx = context.WithValue(ctx, ctxKey{}, spanState{traceID: traceID, spanID: spanID})
+	return ctx, &Span{rec: SpanRecord{
+		TraceID:   traceID,
+		SpanID:    spanID,
+		ParentID:  parent.spanID,
+		Name:      name,
+		StartedAt: time.Now(),
+	}}
+}
+
+// TraceID 返回 span 所属的 trace_id。
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.rec.TraceID
+}
+
+// End 结束 span（可重复调用，只记录第一次）；err 可为空。
+func (s *Span) End(err error) {
+	if s == nil || s.ended {
+		return
+	}
+	s.ended = true
+	s.rec.DurationMS = time.Since(s.rec.StartedAt).Milliseconds()
+	if err != nil {
+		s.rec.Err = err.Error()
+	}
+
+	sinkMu.RLock()
+	fn := sink
+	sinkMu.RUnlock()
+	if fn != nil {
+		fn(s.rec)
+	}
+}
+
+// ParseTraceparent 从 W3C traceparent 头中提取 trace-id（格式不合法返回空字符串）。
+//
+// 格式：version-traceid-parentid-flags，例如 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func ParseTraceparent(v string) string {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return ""
+	}
+	if strings.Trim(parts[1], "0") == "" {
+		return ""
+	}
+	return strings.ToLower(parts[1])
+}
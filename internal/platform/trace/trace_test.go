@@ -0,0 +1,55 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	for in, want := range map[string]string{
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01": "4bf92f3577b34da6a3ce929d0e0e4736",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01": "", // 全 0 非法
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01":   "", // 长度不对
+		"00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": "",
+		"": "",
+	} {
+		if got := ParseTraceparent(in); got != want {
+			t.Fatalf("%q: got %q want %q", in, got, want)
+		}
+	}
+}
+
+func TestSpanPropagation(t *testing.T) {
+	var recs []SpanRecord
+	SetSink(func(r SpanRecord) { recs = append(recs, r) })
+	defer SetSink(nil)
+
+	ctx := WithTraceID(context.Background(), " ABC12345 ")
+	if IDFromContext(ctx) != "abc12345" {
+		t.Fatalf("trace id=%q", IDFromContext(ctx))
+	}
+	if ensured, tid := Ensure(ctx); tid != "abc12345" || IDFromContext(ensured) != tid {
+		t.Fatalf("Ensure replaced existing trace id: %q", tid)
+	}
+
+	ctx, parent := Start(ctx, "parent")
+	_, child := Start(ctx, "child")
+	child.End(errors.New("boom"))
+	child.End(nil) // 重复 End 只记录一次
+	parent.End(nil)
+
+	if len(recs) != 2 {
+		t.Fatalf("spans=%+v", recs)
+	}
+	if recs[0].Name != "child" || recs[0].ParentID != recs[1].SpanID || recs[0].Err != "boom" || recs[0].TraceID != "abc12345" {
+		t.Fatalf("child span=%+v parent=%+v", recs[0], recs[1])
+	}
+	if recs[1].ParentID != "" {
+		t.Fatalf("root span has parent %q", recs[1].ParentID)
+	}
+
+	if _, tid := Ensure(context.Background()); len(tid) != 32 {
+		t.Fatalf("generated trace id=%q", tid)
+	}
+}
@@ -0,0 +1,411 @@
+package zipaes
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// WinZip AES 加密条目（AE-2，AES-256）
+//
+// 司法导出包需要拷到 U 盘流转，这里实现 7-Zip / WinZip / Windows 11 资源管理器等通用工具都能解开的
+// WinZip AES 格式，不引入第三方依赖：
+// - 密钥：PBKDF2-HMAC-SHA1(密码, 16 字节随机盐, 1000 次) 派生 AES 密钥 / HMAC 密钥 / 2 字节口令校验值
+// - 数据：deflate 压缩后用 AES-CTR（小端计数器，从 1 开始）加密，HMAC-SHA1 覆盖密文（取前 10 字节）
+// - 条目头：method=99，加密标志位，extra 0x9901 记录 AE-2 / AES-256 / 实际压缩方法；AE-2 不写 CRC32
+// 文件名与条目大小不加密（ZIP 格式限制），敏感信息只应出现在文件内容中。
+
+// MethodAES 是 WinZip AES 条目在 ZIP 头中的压缩方法号。
+const MethodAES = 99
+
+// MinPasswordLen 是导出包密码的最小长度。
+const MinPasswordLen = 8
+
+const (
+	extraID     = 0x9901
+	iterations  = 1000
+	verifierLen = 2
+	authLen     = 10
+	strength256 = 3
+	versionAE1  = 1
+	versionAE2  = 2
+)
+
+var (
+	// ErrPassword 表示口令校验值不符（密码错误）。
+	ErrPassword = errors.New("zipaes: wrong password")
+	// ErrAuth 表示密文 HMAC 校验失败（条目被篡改或损坏）。
+	ErrAuth = errors.New("zipaes: authentication code mismatch")
+)
+
+// IsEncrypted 判断条目是否为 WinZip AES 加密条目。
+func IsEncrypted(f *zip.File) bool {
+	return f.Method == MethodAES || f.Flags&0x1 != 0
+}
+
+// Writer 是一个加密条目的写入端；写完后必须 Close（写入 HMAC 并回填条目大小），再创建下一个条目。
+type Writer struct {
+	fh     *zip.FileHeader
+	out    io.Writer
+	enc    *encWriter
+	flate  *flate.Writer
+	n      int64
+	closed bool
+}
+
+// Create 在 zw 中创建一个用 password 加密的 deflate 条目（AE-2，AES-256）。
+// fh 的 Name/Modified 由调用方设置；Method、标志位、大小与 CRC32 由这里填写。
+func Create(zw *zip.Writer, fh *zip.FileHeader, password []byte) (*Writer, error) {
+	if len(password) == 0 {
+		return nil, errors.New("zipaes: empty password")
+	}
+	salt := make([]byte, saltLen(strength256))
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("zipaes: generate salt: %w", err)
+	}
+	encKey, macKey, verifier, err := deriveKeys(password, salt, strength256)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if fh.Modified.IsZero() {
+		fh.Modified = time.Now()
+	}
+	fh.ModifiedDate, fh.ModifiedTime = msDosTime(fh.Modified)
+	fh.Method = MethodAES
+	fh.Flags |= 0x1 | 0x8 // 加密 + 数据描述符（大小在写完后回填）
+	if !isASCII(fh.Name) && utf8.ValidString(fh.Name) {
+		fh.Flags |= 0x800
+	}
+	fh.CreatorVersion = fh.CreatorVersion&0xff00 | 20
+	fh.ReaderVersion = 51 // AES 加密条目要求 5.1
+	fh.CRC32 = 0          // AE-2 不记录 CRC32，完整性由 HMAC 保证
+	fh.CompressedSize64, fh.UncompressedSize64 = 0, 0
+	fh.Extra = append(fh.Extra, aesExtra(strength256, versionAE2, zip.Deflate)...)
+	fh.Extra = append(fh.Extra, modTimeExtra(fh.Modified)...)
+
+	out, err := zw.CreateRaw(fh)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := out.Write(append(salt, verifier...)); err != nil {
+		return nil, err
+	}
+	enc := &encWriter{w: out, ctr: newCTR(block), mac: hmac.New(sha1.New, macKey), n: int64(len(salt) + len(verifier))}
+	fw, err := flate.NewWriter(enc, flate.DefaultCompression)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{fh: fh, out: out, enc: enc, flate: fw}, nil
+}
+
+// Write 压缩并加密 p。
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("zipaes: write after close")
+	}
+	n, err := w.flate.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// Close 结束压缩，写入 HMAC 校验码并回填条目大小。
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if err := w.flate.Close(); err != nil {
+		return err
+	}
+	code := w.enc.mac.Sum(nil)[:authLen]
+	if _, err := w.out.Write(code); err != nil {
+		return err
+	}
+	w.fh.CompressedSize64 = uint64(w.enc.n + authLen)
+	w.fh.UncompressedSize64 = uint64(w.n)
+	if w.fh.CompressedSize64 > math.MaxUint32 || w.fh.UncompressedSize64 > math.MaxUint32 {
+		w.fh.CompressedSize = math.MaxUint32
+		w.fh.UncompressedSize = math.MaxUint32
+		w.fh.ReaderVersion = 51
+	} else {
+		w.fh.CompressedSize = uint32(w.fh.CompressedSize64)
+		w.fh.UncompressedSize = uint32(w.fh.UncompressedSize64)
+	}
+	return nil
+}
+
+// encWriter 加密写出并同时计算密文 HMAC。
+type encWriter struct {
+	w   io.Writer
+	ctr *ctrLE
+	mac hash.Hash
+	n   int64
+	buf []byte
+}
+
+func (e *encWriter) Write(p []byte) (int, error) {
+	if cap(e.buf) < len(p) {
+		e.buf = make([]byte, len(p))
+	}
+	buf := e.buf[:len(p)]
+	e.ctr.XORKeyStream(buf, p)
+	e.mac.Write(buf)
+	n, err := e.w.Write(buf)
+	e.n += int64(n)
+	return n, err
+}
+
+// Open 用 password 解开一个 WinZip AES 条目（支持 AE-1/AE-2、AES-128/192/256、store/deflate）。
+// 密码错误时返回 ErrPassword；读到末尾时校验 HMAC（AE-1 另校验 CRC32），不符时 Read 返回 ErrAuth。
+func Open(f *zip.File, password []byte) (io.ReadCloser, error) {
+	if f.Method != MethodAES {
+		return nil, fmt.Errorf("zipaes: %s is not a WinZip AES entry (method %d)", f.Name, f.Method)
+	}
+	strength, version, method, err := parseAESExtra(f.Extra)
+	if err != nil {
+		return nil, fmt.Errorf("zipaes: %s: %w", f.Name, err)
+	}
+	if method != zip.Store && method != zip.Deflate {
+		return nil, fmt.Errorf("zipaes: %s: unsupported compression method %d", f.Name, method)
+	}
+	sl := saltLen(strength)
+	overhead := uint64(sl + verifierLen + authLen)
+	if f.CompressedSize64 < overhead {
+		return nil, fmt.Errorf("zipaes: %s: entry too short", f.Name)
+	}
+	raw, err := f.OpenRaw()
+	if err != nil {
+		return nil, err
+	}
+	head := make([]byte, sl+verifierLen)
+	if _, err := io.ReadFull(raw, head); err != nil {
+		return nil, fmt.Errorf("zipaes: %s: read salt: %w", f.Name, err)
+	}
+	encKey, macKey, verifier, err := deriveKeys(password, head[:sl], strength)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(verifier, head[sl:]) != 1 {
+		return nil, ErrPassword
+	}
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	dec := &decReader{
+		r:    io.LimitReader(raw, int64(f.CompressedSize64-overhead)),
+		tail: raw,
+		ctr:  newCTR(block),
+		mac:  hmac.New(sha1.New, macKey),
+	}
+	pr := &plainReader{dec: dec, r: dec}
+	if method == zip.Deflate {
+		fr := flate.NewReader(dec)
+		pr.r, pr.closer = fr, fr
+	}
+	if version == versionAE1 {
+		pr.crc, pr.wantCRC = crc32.NewIEEE(), f.CRC32
+	}
+	return pr, nil
+}
+
+// decReader 解密密文并在读到末尾时校验 HMAC。
+type decReader struct {
+	r    io.Reader
+	tail io.Reader
+	ctr  *ctrLE
+	mac  hash.Hash
+	done bool
+	err  error
+}
+
+func (d *decReader) Read(p []byte) (int, error) {
+	if d.done {
+		return 0, d.err
+	}
+	n, err := d.r.Read(p)
+	if n > 0 {
+		d.mac.Write(p[:n])
+		d.ctr.XORKeyStream(p[:n], p[:n])
+	}
+	if err == io.EOF {
+		d.done, d.err = true, io.EOF
+		code := make([]byte, authLen)
+		if _, rerr := io.ReadFull(d.tail, code); rerr != nil {
+			d.err = fmt.Errorf("zipaes: read authentication code: %w", rerr)
+		} else if !hmac.Equal(code, d.mac.Sum(nil)[:authLen]) {
+			d.err = ErrAuth
+		}
+		return n, d.err
+	}
+	return n, err
+}
+
+// plainReader 输出明文；解压结束后读完剩余密文以触发 HMAC 校验。
+type plainReader struct {
+	dec     *decReader
+	r       io.Reader
+	closer  io.Closer
+	crc     hash.Hash32
+	wantCRC uint32
+}
+
+func (p *plainReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if p.crc != nil && n > 0 {
+		p.crc.Write(b[:n])
+	}
+	if err != io.EOF {
+		return n, err
+	}
+	if _, derr := io.Copy(io.Discard, p.dec); derr != nil {
+		return n, derr
+	}
+	if p.dec.err != io.EOF {
+		return n, p.dec.err
+	}
+	if p.crc != nil && p.crc.Sum32() != p.wantCRC {
+		return n, ErrAuth
+	}
+	return n, io.EOF
+}
+
+func (p *plainReader) Close() error {
+	if p.closer != nil {
+		return p.closer.Close()
+	}
+	return nil
+}
+
+// ctrLE 是 WinZip AES 使用的 CTR 模式：128 位小端计数器，第一块为 1。
+type ctrLE struct {
+	block   cipher.Block
+	counter [aes.BlockSize]byte
+	stream  [aes.BlockSize]byte
+	pos     int
+}
+
+func newCTR(block cipher.Block) *ctrLE {
+	return &ctrLE{block: block, pos: aes.BlockSize}
+}
+
+func (c *ctrLE) XORKeyStream(dst, src []byte) {
+	for i := range src {
+		if c.pos == aes.BlockSize {
+			for j := range c.counter {
+				c.counter[j]++
+				if c.counter[j] != 0 {
+					break
+				}
+			}
+			c.block.Encrypt(c.stream[:], c.counter[:])
+			c.pos = 0
+		}
+		dst[i] = src[i] ^ c.stream[c.pos]
+		c.pos++
+	}
+}
+
+// deriveKeys 按 WinZip AES 规范派生 AES 密钥、HMAC 密钥与口令校验值。
+func deriveKeys(password, salt []byte, strength int) (encKey, macKey, verifier []byte, err error) {
+	kl := keyLen(strength)
+	dk, err := pbkdf2.Key(sha1.New, string(password), salt, iterations, 2*kl+verifierLen)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("zipaes: derive key: %w", err)
+	}
+	return dk[:kl], dk[kl : 2*kl], dk[2*kl:], nil
+}
+
+func keyLen(strength int) int { return 8 + 8*strength }
+
+func saltLen(strength int) int { return 4 + 4*strength }
+
+// aesExtra 返回 0x9901 扩展字段：版本、厂商 "AE"、密钥强度、实际压缩方法。
+func aesExtra(strength, version int, method uint16) []byte {
+	b := make([]byte, 11)
+	binary.LittleEndian.PutUint16(b[0:], extraID)
+	binary.LittleEndian.PutUint16(b[2:], 7)
+	binary.LittleEndian.PutUint16(b[4:], uint16(version))
+	b[6], b[7] = 'A', 'E'
+	b[8] = byte(strength)
+	binary.LittleEndian.PutUint16(b[9:], method)
+	return b
+}
+
+func parseAESExtra(extra []byte) (strength, version int, method uint16, err error) {
+	for len(extra) >= 4 {
+		tag := binary.LittleEndian.Uint16(extra[0:])
+		size := int(binary.LittleEndian.Uint16(extra[2:]))
+		if len(extra) < 4+size {
+			break
+		}
+		body := extra[4 : 4+size]
+		extra = extra[4+size:]
+		if tag != extraID {
+			continue
+		}
+		if size < 7 || !bytes.Equal(body[2:4], []byte("AE")) {
+			return 0, 0, 0, errors.New("malformed AES extra field")
+		}
+		version = int(binary.LittleEndian.Uint16(body[0:]))
+		strength = int(body[4])
+		method = binary.LittleEndian.Uint16(body[5:])
+		if strength < 1 || strength > 3 {
+			return 0, 0, 0, fmt.Errorf("unsupported AES strength %d", strength)
+		}
+		if version != versionAE1 && version != versionAE2 {
+			return 0, 0, 0, fmt.Errorf("unsupported AES version %d", version)
+		}
+		return strength, version, method, nil
+	}
+	return 0, 0, 0, errors.New("AES extra field not found")
+}
+
+// modTimeExtra 返回 Info-ZIP 扩展时间戳字段（与 zip.Writer.CreateHeader 写入的一致）。
+func modTimeExtra(t time.Time) []byte {
+	b := make([]byte, 9)
+	binary.LittleEndian.PutUint16(b[0:], 0x5455)
+	binary.LittleEndian.PutUint16(b[2:], 5)
+	b[4] = 1
+	binary.LittleEndian.PutUint32(b[5:], uint32(t.Unix()))
+	return b
+}
+
+func msDosTime(t time.Time) (date, tm uint16) {
+	if t.Year() < 1980 {
+		t = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	date = uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	tm = uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+	return date, tm
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
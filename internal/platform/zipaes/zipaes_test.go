@@ -0,0 +1,68 @@
+package zipaes
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCreateOpenRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	content := []byte(strings.Repeat("evidence snapshot 证据快照\n", 5000))
+	w, err := Create(zw, &zip.FileHeader{Name: "evidence/dev_1/apps.json"}, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 分多次写入，覆盖 CTR 计数器跨块与 flate 分段输出。
+	for i := 0; i < len(content); i += 1000 {
+		if _, err := w.Write(content[i:min(i+1000, len(content))]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("evidence snapshot")) {
+		t.Fatalf("plaintext leaked into archive")
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := zr.File[0]
+	if !IsEncrypted(f) || f.UncompressedSize64 != uint64(len(content)) {
+		t.Fatalf("header=%+v", f.FileHeader)
+	}
+	rc, err := Open(f, []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(rc)
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("round trip err=%v len=%d", err, len(got))
+	}
+	if _, err := Open(f, []byte("wrong password")); !errors.Is(err, ErrPassword) {
+		t.Fatalf("wrong password err=%v", err)
+	}
+
+	// 篡改密文末尾：HMAC 校验失败。
+	raw := bytes.Clone(buf.Bytes())
+	off, _ := f.DataOffset()
+	raw[off+int64(f.CompressedSize64)-authLen-1] ^= 0xff
+	zr, _ = zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	rc, err = Open(zr.File[0], []byte("correct horse"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(rc); err == nil {
+		t.Fatalf("tampered entry read without error")
+	}
+}
@@ -0,0 +1,367 @@
+package addrcluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+)
+
+// 钱包地址聚类
+//
+// 浏览痕迹中抽取到的地址往往有几十个，平铺展示难以阅读。这里按“共现关系”把地址分组：
+// - same_page：同一条访问记录（URL/标题）里同时出现
+// - same_url：出现在同一 URL（忽略 fragment）或同一页面标题的不同访问中
+// - temporal：同一设备上时间相近（默认 10 分钟内）的访问中先后出现
+// - same_artifact：来自同一份证据（单独不足以成组，只作为加分项）
+//
+// 两个地址之间的关联分 = 各依据权重之和（封顶 1），达到 MinScore 即连边；
+// 连通分量即为一个聚类（只保留 2 个及以上地址的分组）。
+// 聚类只表示“可能由同一主体控制/关注”，报告中需提示人工复核。
+
+// 关联依据。
+const (
+	ReasonSamePage     = "same_page"
+	ReasonSameURL      = "same_url"
+	ReasonTemporal     = "temporal"
+	ReasonSameArtifact = "same_artifact"
+)
+
+var reasonWeight = map[string]float64{
+	ReasonSamePage:     1.0,
+	ReasonSameURL:      0.8,
+	ReasonTemporal:     0.6,
+	ReasonSameArtifact: 0.2,
+}
+
+// Options 控制聚类参数。
+type Options struct {
+	Window   time.Duration // temporal 判定窗口（默认 10 分钟）
+	MinScore float64       // 连边阈值（默认 0.5）
+}
+
+// Address 是待聚类的一条地址命中。
+type Address struct {
+	HitID       string
+	Value       string
+	DeviceID    string
+	ArtifactIDs []string
+}
+
+// Visit 是一条带来源的访问记录。
+type Visit struct {
+	ArtifactID string
+	DeviceID   string
+	model.VisitRecord
+}
+
+type pair struct{ a, b string }
+
+func newPair(a, b string) pair {
+	if a > b {
+		a, b = b, a
+	}
+	return pair{a, b}
+}
+
+// Analyze 计算地址聚类（纯函数，不访问数据库）。
+func Analyze(caseID string, addrs []Address, visits []Visit, opts Options) []model.AddressCluster {
+	if opts.Window <= 0 {
+		opts.Window = 10 * time.Minute
+	}
+	if opts.MinScore <= 0 {
+		opts.MinScore = 0.5
+	}
+
+	hitsByAddr := map[string][]string{}
+	artifactsByAddr := map[string]map[string]struct{}{}
+	for _, a := range addrs {
+		v := strings.TrimSpace(a.Value)
+		if v == "" {
+			continue
+		}
+		hitsByAddr[v] = append(hitsByAddr[v], a.HitID)
+		if artifactsByAddr[v] == nil {
+			artifactsByAddr[v] = map[string]struct{}{}
+		}
+		for _, id := range a.ArtifactIDs {
+			artifactsByAddr[v][id] = struct{}{}
+		}
+	}
+	if len(hitsByAddr) < 2 {
+		return []model.AddressCluster{}
+	}
+	values := make([]string, 0, len(hitsByAddr))
+	for v := range hitsByAddr {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+
+	reasons := map[pair]map[string]int{}
+	link := func(a, b, reason string) {
+		if a == b {
+			return
+		}
+		p := newPair(a, b)
+		if reasons[p] == nil {
+			reasons[p] = map[string]int{}
+		}
+		reasons[p][reason]++
+	}
+
+	// 每条访问中出现的地址，以及出现时间（首次/末次）。
+	type occurrence struct {
+		addr   string
+		at     int64
+		device string
+	}
+	var occs []occurrence
+	byURL := map[string][]string{}
+	byTitle := map[string][]string{}
+	seenAt := map[string][2]int64{}
+	for _, v := range visits {
+		found := addressesIn(values, v.URL+"\n"+v.Title)
+		if len(found) == 0 {
+			continue
+		}
+		for i := range found {
+			for j := i + 1; j < len(found); j++ {
+				link(found[i], found[j], ReasonSamePage)
+			}
+			if k := normalizeURL(v.URL); k != "" {
+				byURL[k] = append(byURL[k], found[i])
+			}
+			if t := strings.TrimSpace(v.Title); t != "" {
+				byTitle[t] = append(byTitle[t], found[i])
+			}
+			if v.VisitedAt > 0 {
+				occs = append(occs, occurrence{addr: found[i], at: v.VisitedAt, device: v.DeviceID})
+				r := seenAt[found[i]]
+				if r[0] == 0 || v.VisitedAt < r[0] {
+					r[0] = v.VisitedAt
+				}
+				if v.VisitedAt > r[1] {
+					r[1] = v.VisitedAt
+				}
+				seenAt[found[i]] = r
+			}
+		}
+	}
+	for _, group := range []map[string][]string{byURL, byTitle} {
+		for _, list := range group {
+			list = dedupe(list)
+			for i := range list {
+				for j := i + 1; j < len(list); j++ {
+					link(list[i], list[j], ReasonSameURL)
+				}
+			}
+		}
+	}
+
+	// temporal：按时间排序后滑动窗口（同一设备）。
+	sort.Slice(occs, func(i, j int) bool { return occs[i].at < occs[j].at })
+	window := int64(opts.Window / time.Second)
+	for i := range occs {
+		for j := i + 1; j < len(occs) && occs[j].at-occs[i].at <= window; j++ {
+			if occs[i].device == occs[j].device && occs[i].addr != occs[j].addr {
+				link(occs[i].addr, occs[j].addr, ReasonTemporal)
+			}
+		}
+	}
+
+	// same_artifact：只给已有其他依据的地址对加分，避免整份浏览历史被并成一组。
+	for p := range reasons {
+		if shareAny(artifactsByAddr[p.a], artifactsByAddr[p.b]) {
+			reasons[p][ReasonSameArtifact]++
+		}
+	}
+
+	// 并查集合并达到阈值的地址对。
+	parent := map[string]string{}
+	var find func(string) string
+	find = func(x string) string {
+		if parent[x] == "" || parent[x] == x {
+			parent[x] = x
+			return x
+		}
+		parent[x] = find(parent[x])
+		return parent[x]
+	}
+	type edge struct {
+		p     pair
+		score float64
+	}
+	var edges []edge
+	for p, rs := range reasons {
+		score := 0.0
+		for r := range rs {
+			score += reasonWeight[r]
+		}
+		if score > 1 {
+			score = 1
+		}
+		if score < opts.MinScore {
+			continue
+		}
+		edges = append(edges, edge{p: p, score: score})
+		ra, rb := find(p.a), find(p.b)
+		if ra != rb {
+			if ra > rb {
+				ra, rb = rb, ra
+			}
+			parent[rb] = ra
+		}
+	}
+
+	members := map[string][]string{}
+	for _, v := range values {
+		if _, ok := parent[v]; !ok {
+			continue
+		}
+		root := find(v)
+		members[root] = append(members[root], v)
+	}
+
+	out := []model.AddressCluster{}
+	for root, list := range members {
+		if len(list) < 2 {
+			continue
+		}
+		sort.Strings(list)
+		c := model.AddressCluster{
+			ClusterID: clusterID(list),
+			CaseID:    caseID,
+			Size:      len(list),
+			Addresses: list,
+			Reasons:   map[string]int{},
+		}
+		total, n := 0.0, 0
+		for _, e := range edges {
+			if find(e.p.a) != root {
+				continue
+			}
+			total += e.score
+			n++
+			for r, cnt := range reasons[e.p] {
+				c.Reasons[r] += cnt
+			}
+		}
+		if n > 0 {
+			c.Score = float64(int(total/float64(n)*100+0.5)) / 100
+		}
+		for _, addr := range list {
+			c.HitIDs = append(c.HitIDs, hitsByAddr[addr]...)
+			r := seenAt[addr]
+			if r[0] > 0 && (c.FirstSeenAt == 0 || r[0] < c.FirstSeenAt) {
+				c.FirstSeenAt = r[0]
+			}
+			if r[1] > c.LastSeenAt {
+				c.LastSeenAt = r[1]
+			}
+		}
+		sort.Strings(c.HitIDs)
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Size != out[j].Size {
+			return out[i].Size > out[j].Size
+		}
+		return out[i].ClusterID < out[j].ClusterID
+	})
+	return out
+}
+
+// RunForCase 读取案件内的地址命中与浏览历史，重算聚类并写回数据库。
+func RunForCase(ctx context.Context, store *sqliteadapter.Store, caseID string, opts Options) ([]model.AddressCluster, error) {
+	hits, err := store.ListCaseHitDetails(ctx, caseID, string(model.HitWalletAddress))
+	if err != nil {
+		return nil, err
+	}
+	payloads, err := store.ListArtifactPayloadsWithIDByType(ctx, caseID, string(model.ArtifactBrowserHistory))
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]Address, 0, len(hits))
+	for _, h := range hits {
+		addrs = append(addrs, Address{HitID: h.HitID, Value: h.MatchedValue, DeviceID: h.DeviceID, ArtifactIDs: h.ArtifactIDs})
+	}
+	var visits []Visit
+	for _, p := range payloads {
+		var rows []model.VisitRecord
+		if err := json.Unmarshal(p.PayloadJSON, &rows); err != nil {
+			return nil, fmt.Errorf("decode browser_history payload %s: %w", p.ArtifactID, err)
+		}
+		for _, r := range rows {
+			visits = append(visits, Visit{ArtifactID: p.ArtifactID, DeviceID: p.DeviceID, VisitRecord: r})
+		}
+	}
+
+	clusters := Analyze(caseID, addrs, visits, opts)
+	if err := store.ReplaceAddressClusters(ctx, caseID, clusters); err != nil {
+		return nil, err
+	}
+	return clusters, nil
+}
+
+// addressesIn 返回文本中出现的地址（EVM/bech32 命中值为小写，按不区分大小写匹配）。
+func addressesIn(values []string, text string) []string {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	lower := strings.ToLower(text)
+	var out []string
+	for _, v := range values {
+		if strings.Contains(text, v) || (v == strings.ToLower(v) && strings.Contains(lower, v)) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func normalizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	return strings.ToLower(u.Host) + u.EscapedPath() + "?" + u.RawQuery
+}
+
+func clusterID(addrs []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(addrs, "\n")))
+	return "acl_" + hex.EncodeToString(sum[:6])
+}
+
+func dedupe(list []string) []string {
+	seen := map[string]struct{}{}
+	out := list[:0:0]
+	for _, v := range list {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
+
+func shareAny(a, b map[string]struct{}) bool {
+	for k := range a {
+		if _, ok := b[k]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package addrcluster
+
+import (
+	"reflect"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestAnalyze_GroupsByCoOccurrence(t *testing.T) {
+	const (
+		a = "0x1111111111111111111111111111111111111111"
+		b = "0x2222222222222222222222222222222222222222"
+		c = "bc1qar0srrr7xfkvy5l643lydnw9re59gtzzwf5mdq"
+		d = "0x4444444444444444444444444444444444444444"
+		e = "0x5555555555555555555555555555555555555555"
+	)
+	addrs := []Address{
+		{HitID: "hit_a", Value: a, DeviceID: "dev1", ArtifactIDs: []string{"art1"}},
+		{HitID: "hit_b", Value: b, DeviceID: "dev1", ArtifactIDs: []string{"art1"}},
+		{HitID: "hit_c", Value: c, DeviceID: "dev1", ArtifactIDs: []string{"art1"}},
+		{HitID: "hit_d", Value: d, DeviceID: "dev1", ArtifactIDs: []string{"art1"}},
+		{HitID: "hit_e", Value: e, DeviceID: "dev2", ArtifactIDs: []string{"art2"}},
+	}
+	visit := func(dev, url, title string, at int64) Visit {
+		return Visit{ArtifactID: "art1", DeviceID: dev, VisitRecord: model.VisitRecord{URL: url, Title: title, VisitedAt: at}}
+	}
+	const t0 = 1_700_000_000
+	visits := []Visit{
+		// a/b 同页出现（分别在 URL 与标题中）
+		visit("dev1", "https://etherscan.io/address/0x1111111111111111111111111111111111111111", "Transfer to 0x2222222222222222222222222222222222222222", t0),
+		// c 在 5 分钟后出现（temporal）
+		visit("dev1", "https://mempool.space/address/"+c, "", t0+300),
+		// d 两天后单独出现：不应入组
+		visit("dev1", "https://etherscan.io/address/"+d, "", t0+2*86400),
+		// e 同一时间但在另一台设备：不应入组
+		{ArtifactID: "art2", DeviceID: "dev2", VisitRecord: model.VisitRecord{URL: "https://etherscan.io/address/" + e, VisitedAt: t0 + 10}},
+	}
+
+	got := Analyze("case1", addrs, visits, Options{})
+	if len(got) != 1 {
+		t.Fatalf("want 1 cluster, got %d: %+v", len(got), got)
+	}
+	cl := got[0]
+	if !reflect.DeepEqual(cl.Addresses, []string{a, b, c}) || !reflect.DeepEqual(cl.HitIDs, []string{"hit_a", "hit_b", "hit_c"}) {
+		t.Fatalf("unexpected members: %+v", cl)
+	}
+	if cl.Reasons[ReasonSamePage] == 0 || cl.Reasons[ReasonTemporal] == 0 {
+		t.Fatalf("missing reasons: %+v", cl.Reasons)
+	}
+	if cl.FirstSeenAt != t0 || cl.LastSeenAt != t0+300 {
+		t.Fatalf("unexpected time range: %d ~ %d", cl.FirstSeenAt, cl.LastSeenAt)
+	}
+
+	// 成员不变时 cluster_id 稳定（重算不会产生新分组 ID）。
+	again := Analyze("case1", addrs, visits, Options{})
+	if again[0].ClusterID != cl.ClusterID {
+		t.Fatalf("cluster id not stable: %s vs %s", again[0].ClusterID, cl.ClusterID)
+	}
+}
@@ -0,0 +1,305 @@
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 报告哈希外部锚定
+//
+// 把导出包（默认最新的 forensic_zip）的 sha256 登记到外部锚定服务，取得独立于本系统的“存在时间”证明：
+// - 适配器（Adapter）负责协议，按名称注册；内置 rfc3161（单位时间戳服务）与 rest（许可链存证等 JSON 端点）
+// - 只向外部发送报告文件的 sha256（不含案件编号、命中或文件名）；发送前重新计算文件哈希，与报告记录不一致时拒绝锚定
+// - 回执原样保存到 evidence_anchors（rfc3161 为时间戳令牌，可用 openssl ts -verify 独立核验），同时写审计
+// - 触发方式：手动（CLI case anchor / POST /api/cases/{id}/anchors），或导出完成后自动锚定（anchor_on_export）
+// - 凭据只从环境变量读取（token_env），配置文件与审计日志中不出现明文
+
+// Receipt 是适配器返回的锚定回执。
+type Receipt struct {
+	// Endpoint 为去掉查询串后的请求地址（避免把查询串中的密钥写入回执/审计）。
+	Endpoint string
+	// ReceiptID 为对方返回的凭证编号（交易哈希 / 时间戳序列号）。
+	ReceiptID string
+	// AnchoredAt 为对方声明的锚定时间（Unix 秒）；未知为 0。
+	AnchoredAt int64
+	// Raw 为回执原文（文本；二进制令牌用 base64）。
+	Raw string
+}
+
+// Adapter 是一种外部锚定服务协议。
+type Adapter interface {
+	// Name 是配置中 adapter 字段使用的名称。
+	Name() string
+	// Anchor 登记一个 sha256（64 位十六进制）并返回回执。
+	Anchor(ctx context.Context, cfg Config, sha256Hex string) (*Receipt, error)
+}
+
+var (
+	mu       sync.RWMutex
+	adapters = map[string]Adapter{}
+)
+
+// Register 注册一种适配器；名称为空或重复注册时 panic（属于编程错误）。
+func Register(a Adapter) {
+	name := strings.TrimSpace(a.Name())
+	if name == "" {
+		panic("anchor: empty adapter name")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := adapters[name]; ok {
+		panic(fmt.Sprintf("anchor: duplicate adapter %q", name))
+	}
+	adapters[name] = a
+}
+
+// Lookup 按名称查找适配器。
+func Lookup(name string) (Adapter, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	a, ok := adapters[strings.TrimSpace(name)]
+	return a, ok
+}
+
+// Adapters 返回已注册的适配器名称（排序）。
+func Adapters() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]string, 0, len(adapters))
+	for name := range adapters {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Config 是锚定配置（见 rules/evidence_anchor.template.yaml）。
+type Config struct {
+	Adapter  string `yaml:"adapter" json:"adapter"`
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+	// TokenEnv 为保存访问令牌的环境变量名，令牌以 Authorization: Bearer 发送。
+	TokenEnv string            `yaml:"token_env,omitempty" json:"token_env,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Timeout  time.Duration     `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// AnchorOnExport 列出完成后自动锚定的导出格式（空表示只手动锚定）。
+	AnchorOnExport []string `yaml:"anchor_on_export,omitempty" json:"anchor_on_export,omitempty"`
+
+	// rfc3161：PolicyOID 为请求的时间戳策略（可选）；CertReq 要求对方在令牌中附带签名证书。
+	PolicyOID string `yaml:"policy_oid,omitempty" json:"policy_oid,omitempty"`
+	CertReq   bool   `yaml:"cert_req,omitempty" json:"cert_req,omitempty"`
+
+	// rest：HashField 为请求 JSON 中哈希字段名（默认 sha256）；
+	// ReceiptIDField / AnchoredAtField 为响应 JSON 中凭证编号与锚定时间的路径（点号分隔，可选）。
+	HashField       string `yaml:"hash_field,omitempty" json:"hash_field,omitempty"`
+	ReceiptIDField  string `yaml:"receipt_id_field,omitempty" json:"receipt_id_field,omitempty"`
+	AnchoredAtField string `yaml:"anchored_at_field,omitempty" json:"anchored_at_field,omitempty"`
+}
+
+// DefaultTimeout 是未配置 timeout 时的请求超时。
+const DefaultTimeout = 15 * time.Second
+
+// LoadConfig 读取并校验锚定配置；path 为空时返回 nil（未启用）。
+func LoadConfig(path string) (*Config, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read anchor config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parse anchor config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate 校验配置完整性。
+func (c *Config) Validate() error {
+	c.Adapter = strings.TrimSpace(c.Adapter)
+	if c.Adapter == "" {
+		c.Adapter = "rest"
+	}
+	if _, ok := Lookup(c.Adapter); !ok {
+		return apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unknown anchor adapter %q (available: %s)", c.Adapter, strings.Join(Adapters(), ", ")))
+	}
+	u, err := url.Parse(strings.TrimSpace(c.Endpoint))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid anchor endpoint: %q", c.Endpoint))
+	}
+	if c.Timeout < 0 {
+		return apperr.New(apperr.CodeInvalidArgument, "timeout must be >= 0")
+	}
+	return nil
+}
+
+// AnchorOnExportKind 判断某导出格式完成后是否自动锚定。
+func (c *Config) AnchorOnExportKind(kind string) bool {
+	if c == nil {
+		return false
+	}
+	for _, k := range c.AnchorOnExport {
+		if k = strings.TrimSpace(k); k == kind || k == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Anchor 把报告文件的 sha256 登记到外部锚定服务，回执写入 evidence_anchors 并记审计。
+//
+// reportID 为空时锚定案件最新的 forensic_zip。
+func Anchor(ctx context.Context, store *sqliteadapter.Store, cfg *Config, caseID, reportID, operator string) (*model.EvidenceAnchor, error) {
+	if cfg == nil {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "evidence anchoring is not configured")
+	}
+	a, ok := Lookup(cfg.Adapter)
+	if !ok {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unknown anchor adapter %q", cfg.Adapter))
+	}
+	if operator = strings.TrimSpace(operator); operator == "" {
+		operator = "system"
+	}
+
+	report, err := resolveReport(ctx, store, caseID, strings.TrimSpace(reportID))
+	if err != nil {
+		return nil, err
+	}
+	// 登记的必须是磁盘上文件当前的哈希：文件缺失或已被改动时不锚定。
+	sum, _, err := hash.File(report.FilePath)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeNotFound, err, fmt.Sprintf("read report file %s", report.FilePath))
+	}
+	if !strings.EqualFold(sum, report.SHA256) {
+		return nil, apperr.New(apperr.CodeConflict, fmt.Sprintf("report file sha256 %s does not match the recorded %s", sum, report.SHA256))
+	}
+
+	detail := map[string]any{
+		"adapter":     cfg.Adapter,
+		"report_id":   report.ReportID,
+		"report_type": report.ReportType,
+		"sha256":      report.SHA256,
+	}
+	rec, err := a.Anchor(ctx, *cfg, report.SHA256)
+	if err != nil {
+		detail["error"] = err.Error()
+		_ = store.AppendAudit(ctx, caseID, "", "evidence_anchor", "anchor", "failed", operator, "anchor.Anchor", detail)
+		return nil, err
+	}
+	row, err := store.SaveEvidenceAnchor(ctx, model.EvidenceAnchor{
+		CaseID:        caseID,
+		ReportID:      report.ReportID,
+		SHA256:        report.SHA256,
+		Adapter:       cfg.Adapter,
+		Endpoint:      rec.Endpoint,
+		ReceiptID:     rec.ReceiptID,
+		AnchoredAt:    rec.AnchoredAt,
+		Receipt:       rec.Raw,
+		ReceiptSHA256: hash.Bytes([]byte(rec.Raw)),
+		Operator:      operator,
+	})
+	if err != nil {
+		return nil, err
+	}
+	detail["anchor_id"] = row.AnchorID
+	detail["endpoint"] = row.Endpoint
+	detail["receipt_id"] = row.ReceiptID
+	detail["anchored_at"] = row.AnchoredAt
+	detail["receipt_sha256"] = row.ReceiptSHA256
+	if err := store.AppendAudit(ctx, caseID, "", "evidence_anchor", "anchor", "success", operator, "anchor.Anchor", detail); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+// resolveReport 返回案件内要锚定的报告；reportID 为空时取最新的 forensic_zip。
+func resolveReport(ctx context.Context, store *sqliteadapter.Store, caseID, reportID string) (*model.ReportInfo, error) {
+	if reportID != "" {
+		r, err := store.GetReportByID(ctx, reportID)
+		if err != nil {
+			return nil, err
+		}
+		if r == nil || r.CaseID != caseID {
+			return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("report not found in case: %s", reportID))
+		}
+		return r, nil
+	}
+	reports, err := store.ListReportsByCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range reports {
+		if reports[i].ReportType == "forensic_zip" {
+			return &reports[i], nil
+		}
+	}
+	return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("no forensic_zip report in case %s (export one first or pass a report id)", caseID))
+}
+
+// post 发送请求，返回 2xx 响应体（最多 1 MiB）与去掉查询串的端点；凭据与自定义请求头按配置附加。
+func post(ctx context.Context, cfg Config, contentType, accept string, body []byte) ([]byte, string, error) {
+	endpoint := strings.TrimSpace(cfg.Endpoint)
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, "", apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid anchor endpoint: %s", cfg.Endpoint))
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, "", fmt.Errorf("build anchor request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", accept)
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if env := strings.TrimSpace(cfg.TokenEnv); env != "" {
+		token := strings.TrimSpace(os.Getenv(env))
+		if token == "" {
+			return nil, "", apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("anchor token env %s is empty", env))
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return nil, "", apperr.Wrap(apperr.CodeUpstreamUnavailable, err, "anchor request failed")
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, "", apperr.Wrap(apperr.CodeUpstreamUnavailable, err, "read anchor response")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet := strings.TrimSpace(string(raw))
+		if len(snippet) > 512 {
+			snippet = snippet[:512]
+		}
+		return nil, "", apperr.New(apperr.CodeUpstreamUnavailable, fmt.Sprintf("anchor endpoint returned %d: %s", resp.StatusCode, snippet))
+	}
+	return raw, u.Scheme + "://" + u.Host + u.Path, nil
+}
@@ -0,0 +1,156 @@
+package anchor
+
+import (
+	"context"
+	"database/sql"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/platform/hash"
+
+	_ "modernc.org/sqlite"
+)
+
+// fakeTSA 按请求签发一个未签名的时间戳令牌（只为覆盖请求/响应编解码；签名由 openssl ts -verify 另行核验）。
+func fakeTSA(t *testing.T, genTime time.Time, tamper bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		var req timeStampReq
+		if _, err := asn1.Unmarshal(raw, &req); err != nil || r.Header.Get("Content-Type") != "application/timestamp-query" {
+			t.Errorf("bad timestamp request: %v", err)
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		imprint := req.MessageImprint
+		if tamper {
+			imprint.HashedMessage = make([]byte, 32)
+		}
+		tst, _ := asn1.Marshal(tstInfo{
+			Version: 1, Policy: asn1.ObjectIdentifier{1, 2, 3, 4, 1}, MessageImprint: imprint,
+			SerialNumber: big.NewInt(4242), GenTime: genTime, Nonce: req.Nonce,
+		})
+		sd, _ := asn1.Marshal(signedData{
+			Version:          3,
+			DigestAlgorithms: asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true},
+			EncapContentInfo: encapContentInfo{EContentType: oidTSTInfo, EContent: tst},
+		})
+		// ContentInfo.content 为 [0] EXPLICIT，这里手工包一层上下文标签。
+		token, _ := asn1.Marshal(struct {
+			ContentType asn1.ObjectIdentifier
+			Content     asn1.RawValue
+		}{oidSignedData, asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 0, IsCompound: true, Bytes: sd}})
+		resp, _ := asn1.Marshal(timeStampResp{Status: pkiStatusInfo{Status: 0}, Token: asn1.RawValue{FullBytes: token}})
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		_, _ = w.Write(resp)
+	}
+}
+
+func TestAnchorForensicZipHash(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "anchor case", "op", "")
+	if err != nil {
+		t.Fatalf("EnsureCase: %v", err)
+	}
+	if _, err := Anchor(ctx, store, &Config{Adapter: "rest"}, caseID, "", "alice"); apperr.CodeOf(err) != apperr.CodeNotFound {
+		t.Fatalf("no forensic_zip yet: err=%v", err)
+	}
+
+	zipPath := filepath.Join(dir, "forensic.zip")
+	if err := os.WriteFile(zipPath, []byte("PK fake forensic package"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum, _, _ := hash.File(zipPath)
+	reportID, err := store.SaveReport(ctx, caseID, "forensic_zip", zipPath, sum, "test", "ready")
+	if err != nil {
+		t.Fatalf("SaveReport: %v", err)
+	}
+
+	// rest：请求体只含哈希，回执按配置路径提取。
+	var got map[string]any
+	restSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &got)
+		_, _ = w.Write([]byte(`{"result":{"tx":"0xfeed","block_time":"2026-10-16T08:00:00Z"}}`))
+	}))
+	defer restSrv.Close()
+	cfg := &Config{
+		Endpoint:        restSrv.URL + "/notary?key=secret",
+		HashField:       "digest",
+		ReceiptIDField:  "result.tx",
+		AnchoredAtField: "result.block_time",
+		AnchorOnExport:  []string{"forensic-zip"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !cfg.AnchorOnExportKind("forensic-zip") || cfg.AnchorOnExportKind("forensic-pdf") {
+		t.Fatalf("anchor_on_export=%v", cfg.AnchorOnExport)
+	}
+	row, err := Anchor(ctx, store, cfg, caseID, "", "alice")
+	if err != nil {
+		t.Fatalf("Anchor rest: %v", err)
+	}
+	if len(got) != 2 || got["digest"] != sum || got["hash_algorithm"] != "sha256" {
+		t.Fatalf("payload=%v", got)
+	}
+	if row.ReportID != reportID || row.ReceiptID != "0xfeed" || row.AnchoredAt != time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC).Unix() ||
+		row.Endpoint != restSrv.URL+"/notary" || row.ReceiptSHA256 != hash.Bytes([]byte(row.Receipt)) {
+		t.Fatalf("rest anchor=%+v", row)
+	}
+
+	// rfc3161：令牌摘要/nonce 与请求一致时保存令牌与 genTime。
+	genTime := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	tsa := httptest.NewServer(fakeTSA(t, genTime, false))
+	defer tsa.Close()
+	row, err = Anchor(ctx, store, &Config{Adapter: "rfc3161", Endpoint: tsa.URL}, caseID, reportID, "alice")
+	if err != nil {
+		t.Fatalf("Anchor rfc3161: %v", err)
+	}
+	if row.ReceiptID != "4242" || row.AnchoredAt != genTime.Unix() {
+		t.Fatalf("rfc3161 anchor=%+v", row)
+	}
+	if token, err := base64.StdEncoding.DecodeString(row.Receipt); err != nil || len(token) == 0 {
+		t.Fatalf("receipt is not a base64 token: %v", err)
+	}
+
+	bad := httptest.NewServer(fakeTSA(t, genTime, true))
+	defer bad.Close()
+	if _, err := Anchor(ctx, store, &Config{Adapter: "rfc3161", Endpoint: bad.URL}, caseID, reportID, "alice"); apperr.CodeOf(err) != apperr.CodeUpstreamUnavailable {
+		t.Fatalf("token for another digest: err=%v", err)
+	}
+
+	// 文件被改动后不再锚定。
+	if err := os.WriteFile(zipPath, []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Anchor(ctx, store, cfg, caseID, reportID, "alice"); apperr.CodeOf(err) != apperr.CodeConflict {
+		t.Fatalf("tampered file: err=%v", err)
+	}
+
+	rows, err := store.ListEvidenceAnchors(ctx, caseID, reportID)
+	if err != nil || len(rows) != 2 || rows[0].Adapter != "rest" || rows[1].Adapter != "rfc3161" {
+		t.Fatalf("anchors=%+v err=%v", rows, err)
+	}
+}
@@ -0,0 +1,88 @@
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/apperr"
+)
+
+// restAdapter 把哈希以 JSON POST 到存证端点（许可链网关 / 单位存证服务）。
+//
+// 请求体只有两个字段：{"<hash_field>": "<sha256>", "hash_algorithm": "sha256"}；
+// 响应原文作为回执保存，receipt_id_field / anchored_at_field 配置时从响应 JSON 提取凭证编号与锚定时间
+// （时间可以是 Unix 秒数或 RFC 3339 字符串）。
+type restAdapter struct{}
+
+func init() { Register(restAdapter{}) }
+
+func (restAdapter) Name() string { return "rest" }
+
+func (restAdapter) Anchor(ctx context.Context, cfg Config, sha256Hex string) (*Receipt, error) {
+	field := strings.TrimSpace(cfg.HashField)
+	if field == "" {
+		field = "sha256"
+	}
+	payload, err := json.Marshal(map[string]string{field: sha256Hex, "hash_algorithm": "sha256"})
+	if err != nil {
+		return nil, fmt.Errorf("marshal anchor payload: %w", err)
+	}
+	body, endpoint, err := post(ctx, cfg, "application/json", "application/json", payload)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &Receipt{Endpoint: endpoint, Raw: strings.TrimSpace(string(body))}
+	if cfg.ReceiptIDField == "" && cfg.AnchoredAtField == "" {
+		return rec, nil
+	}
+	var parsed map[string]any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber() // 数字编号保持原样，避免浮点格式化
+	if err := dec.Decode(&parsed); err != nil {
+		return nil, apperr.New(apperr.CodeUpstreamUnavailable, fmt.Sprintf("anchor endpoint returned non-JSON receipt: %v", err))
+	}
+	if v, ok := lookupPath(parsed, cfg.ReceiptIDField); ok && v != nil {
+		rec.ReceiptID = fmt.Sprint(v)
+	}
+	if v, ok := lookupPath(parsed, cfg.AnchoredAtField); ok && v != nil {
+		rec.AnchoredAt = parseTime(v)
+	}
+	return rec, nil
+}
+
+// parseTime 解析 Unix 秒数（数字或数字字符串）或 RFC 3339 时间；无法解析时返回 0。
+func parseTime(v any) int64 {
+	s := strings.TrimSpace(fmt.Sprint(v))
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n > 0 {
+		return n
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t.Unix()
+	}
+	return 0
+}
+
+// lookupPath 按点号路径取值（只支持对象字段）。
+func lookupPath(root map[string]any, path string) (any, bool) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, false
+	}
+	var cur any = root
+	for _, seg := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[seg]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
@@ -0,0 +1,202 @@
+package anchor
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/apperr"
+)
+
+// rfc3161Adapter 向单位时间戳服务（TSA，RFC 3161）申请时间戳令牌。
+//
+// 请求只含 SHA-256 摘要与随机 nonce；收到令牌后核对摘要与 nonce 是否与请求一致，
+// 提取 genTime 与序列号，令牌（DER）以 base64 原样保存。令牌签名不在本工具内验证：
+// 需要时用 TSA 证书执行 openssl ts -verify -digest <sha256> -token_in -in token.der -CAfile tsa.pem。
+type rfc3161Adapter struct{}
+
+func init() { Register(rfc3161Adapter{}) }
+
+func (rfc3161Adapter) Name() string { return "rfc3161" }
+
+var (
+	oidSHA256     = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSignedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidTSTInfo    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+)
+
+type messageImprint struct {
+	HashAlgorithm pkix.AlgorithmIdentifier
+	HashedMessage []byte
+}
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint messageImprint
+	ReqPolicy      asn1.ObjectIdentifier `asn1:"optional"`
+	Nonce          *big.Int              `asn1:"optional"`
+	CertReq        bool                  `asn1:"optional"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString []string       `asn1:"optional,utf8"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status pkiStatusInfo
+	Token  asn1.RawValue `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,tag:0"`
+}
+
+// signedData 只解析到 encapContentInfo，证书与签名信息不在本工具内使用。
+type signedData struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue
+	EncapContentInfo encapContentInfo
+}
+
+type encapContentInfo struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+type accuracy struct {
+	Seconds int `asn1:"optional"`
+	Millis  int `asn1:"optional,tag:0"`
+	Micros  int `asn1:"optional,tag:1"`
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint messageImprint
+	SerialNumber   *big.Int
+	GenTime        time.Time `asn1:"generalized"`
+	Accuracy       accuracy  `asn1:"optional"`
+	Ordering       bool      `asn1:"optional"`
+	Nonce          *big.Int  `asn1:"optional"`
+}
+
+func (rfc3161Adapter) Anchor(ctx context.Context, cfg Config, sha256Hex string) (*Receipt, error) {
+	digest, err := hex.DecodeString(sha256Hex)
+	if err != nil || len(digest) != 32 {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid sha256: %q", sha256Hex))
+	}
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, fmt.Errorf("generate timestamp nonce: %w", err)
+	}
+	req := timeStampReq{
+		Version: 1,
+		MessageImprint: messageImprint{
+			HashAlgorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			HashedMessage: digest,
+		},
+		Nonce:   nonce,
+		CertReq: cfg.CertReq,
+	}
+	if p := strings.TrimSpace(cfg.PolicyOID); p != "" {
+		oid, err := parseOID(p)
+		if err != nil {
+			return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid policy_oid %q", p))
+		}
+		req.ReqPolicy = oid
+	}
+	der, err := asn1.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal timestamp request: %w", err)
+	}
+
+	body, endpoint, err := post(ctx, cfg, "application/timestamp-query", "application/timestamp-reply", der)
+	if err != nil {
+		return nil, err
+	}
+	token, info, err := parseTimeStampResp(body)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeUpstreamUnavailable, err, "invalid timestamp response")
+	}
+	if !info.MessageImprint.HashAlgorithm.Algorithm.Equal(oidSHA256) || !bytes.Equal(info.MessageImprint.HashedMessage, digest) {
+		return nil, apperr.New(apperr.CodeUpstreamUnavailable, "timestamp token does not cover the requested sha256")
+	}
+	if info.Nonce == nil || info.Nonce.Cmp(nonce) != 0 {
+		return nil, apperr.New(apperr.CodeUpstreamUnavailable, "timestamp token nonce does not match the request")
+	}
+
+	rec := &Receipt{
+		Endpoint:   endpoint,
+		AnchoredAt: info.GenTime.Unix(),
+		Raw:        base64.StdEncoding.EncodeToString(token),
+	}
+	if info.SerialNumber != nil {
+		rec.ReceiptID = info.SerialNumber.String()
+	}
+	return rec, nil
+}
+
+// parseTimeStampResp 解析 TimeStampResp，返回令牌 DER 与其中的 TSTInfo。
+func parseTimeStampResp(der []byte) ([]byte, *tstInfo, error) {
+	var resp timeStampResp
+	if _, err := asn1.Unmarshal(der, &resp); err != nil {
+		return nil, nil, fmt.Errorf("parse TimeStampResp: %w", err)
+	}
+	// 0 = granted，1 = grantedWithMods；其余为拒绝/等待。
+	if resp.Status.Status != 0 && resp.Status.Status != 1 {
+		return nil, nil, fmt.Errorf("timestamp request rejected (status %d): %s", resp.Status.Status, strings.Join(resp.Status.StatusString, "; "))
+	}
+	if len(resp.Token.FullBytes) == 0 {
+		return nil, nil, fmt.Errorf("timestamp response has no token")
+	}
+	token := resp.Token.FullBytes
+
+	var ci contentInfo
+	if _, err := asn1.Unmarshal(token, &ci); err != nil {
+		return nil, nil, fmt.Errorf("parse token ContentInfo: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, nil, fmt.Errorf("token is not SignedData: %v", ci.ContentType)
+	}
+	var sd signedData
+	if _, err := asn1.Unmarshal(ci.Content.Bytes, &sd); err != nil {
+		return nil, nil, fmt.Errorf("parse token SignedData: %w", err)
+	}
+	if !sd.EncapContentInfo.EContentType.Equal(oidTSTInfo) || len(sd.EncapContentInfo.EContent) == 0 {
+		return nil, nil, fmt.Errorf("token does not carry TSTInfo")
+	}
+	var info tstInfo
+	if _, err := asn1.Unmarshal(sd.EncapContentInfo.EContent, &info); err != nil {
+		return nil, nil, fmt.Errorf("parse TSTInfo: %w", err)
+	}
+	return token, &info, nil
+}
+
+// parseOID 解析点分十进制 OID。
+func parseOID(s string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("too few arcs")
+	}
+	oid := make(asn1.ObjectIdentifier, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid arc %q", p)
+		}
+		oid = append(oid, n)
+	}
+	return oid, nil
+}
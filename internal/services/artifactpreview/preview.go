@@ -0,0 +1,459 @@
+package artifactpreview
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"crypto-inspector/internal/platform/filetype"
+	"crypto-inspector/internal/platform/snapshot"
+
+	// 注册缩略图需要的解码器。
+	_ "image/gif"
+	_ "image/jpeg"
+
+	_ "modernc.org/sqlite"
+)
+
+// 证据预览
+//
+// 目标：让 UI 按证据真实类型展示结构化摘要，而不是把快照原样当文本输出：
+// - zip：文件清单（每个条目附带探测到的类型，便于发现其中的 SQLite 副本）
+// - sqlite：表/视图清单与行数（只读打开，不改动证据文件）
+// - 图片：缩略图（data URL）
+// - json/文本：截断后的文本内容
+// - 其他二进制：文件头 hex dump
+//
+// Render 字段是给前端的渲染提示，与 Kind 一一对应。
+
+const (
+	KindZip    = "zip"
+	KindSQLite = "sqlite"
+	KindImage  = "image"
+	KindJSON   = "json"
+	KindText   = "text"
+	KindBinary = "binary"
+)
+
+// Options 控制预览的规模上限。
+type Options struct {
+	MaxZipEntries int   // zip 清单最多返回条目数（默认 500）
+	MaxTables     int   // sqlite 最多列出表数（默认 200）
+	MaxTextBytes  int   // 文本/JSON 最多返回字节数（默认 64KB）
+	ThumbSize     int   // 缩略图最长边（默认 256）
+	MaxPixels     int64 // 超过该像素数的图片不生成缩略图（默认 40M，防止解码炸弹）
+}
+
+// Preview 是一个证据快照的结构化预览。
+type Preview struct {
+	MimeType  string `json:"mime_type"`
+	Kind      string `json:"kind"`
+	Render    string `json:"render"` // file_list|table_list|image|code|text|hex
+	SizeBytes int64  `json:"size_bytes"`
+	// Compression 非空表示快照为压缩存储（预览内容已透明解压）。
+	Compression string `json:"compression,omitempty"`
+
+	Zip    *ZipListing    `json:"zip,omitempty"`
+	SQLite *SQLiteSummary `json:"sqlite,omitempty"`
+	Image  *ImageThumb    `json:"image,omitempty"`
+	Text   *TextPreview   `json:"text,omitempty"`
+	Hex    string         `json:"hex,omitempty"`
+}
+
+// ZipEntry 是 zip 清单中的一个条目。
+type ZipEntry struct {
+	Name            string `json:"name"`
+	SizeBytes       int64  `json:"size_bytes"`
+	CompressedBytes int64  `json:"compressed_bytes"`
+	Modified        int64  `json:"modified,omitempty"`
+	MimeType        string `json:"mime_type"`
+}
+
+// ZipListing 是 zip 文件清单。
+type ZipListing struct {
+	Entries   []ZipEntry `json:"entries"`
+	Total     int        `json:"total"`
+	Truncated bool       `json:"truncated"`
+}
+
+// SQLiteTable 是 sqlite 中的一张表或视图。
+type SQLiteTable struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"` // table|view
+	Rows    int64    `json:"rows"`
+	Columns []string `json:"columns"`
+}
+
+// SQLiteSummary 是 sqlite 数据库摘要。
+type SQLiteSummary struct {
+	Tables    []SQLiteTable `json:"tables"`
+	Truncated bool          `json:"truncated"`
+}
+
+// ImageThumb 是图片缩略图。
+type ImageThumb struct {
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	ThumbWidth  int    `json:"thumb_width,omitempty"`
+	ThumbHeight int    `json:"thumb_height,omitempty"`
+	DataURL     string `json:"data_url,omitempty"`
+	Note        string `json:"note,omitempty"`
+}
+
+// TextPreview 是文本/JSON 内容预览。
+type TextPreview struct {
+	Content   string `json:"content"`
+	Language  string `json:"language"` // json|text
+	Truncated bool   `json:"truncated"`
+}
+
+// Build 读取证据快照并生成预览；类型以文件内容探测结果为准（不信任库中记录）。
+func Build(ctx context.Context, path string, opts Options) (*Preview, error) {
+	opts = withDefaults(opts)
+	st, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat snapshot: %w", err)
+	}
+	if st.IsDir() {
+		return nil, fmt.Errorf("snapshot is a directory: %s", path)
+	}
+
+	mime := filetype.DetectFile(path)
+	p := &Preview{MimeType: mime, SizeBytes: st.Size()}
+	if snapshot.IsCompressed(path) {
+		// 压缩快照只支持文本/JSON 预览（证据链路中只有 JSON 快照会压缩存储）。
+		p.Compression = snapshot.CompressionGzip
+		p.MimeType = detectCompressed(path)
+		if p.MimeType == filetype.MimeJSON || p.MimeType == filetype.MimeText {
+			mime = p.MimeType
+		}
+	}
+	switch {
+	case mime == filetype.MimeZip:
+		p.Kind, p.Render = KindZip, "file_list"
+		p.Zip, err = listZip(path, opts.MaxZipEntries)
+	case mime == filetype.MimeSQLite:
+		p.Kind, p.Render = KindSQLite, "table_list"
+		p.SQLite, err = summarizeSQLite(ctx, path, opts.MaxTables)
+	case filetype.IsImage(mime):
+		p.Kind, p.Render = KindImage, "image"
+		p.Image, err = thumbnail(path, opts.ThumbSize, opts.MaxPixels)
+	case mime == filetype.MimeJSON:
+		p.Kind, p.Render = KindJSON, "code"
+		p.Text, err = readText(path, opts.MaxTextBytes, true)
+	case mime == filetype.MimeText:
+		p.Kind, p.Render = KindText, "text"
+		p.Text, err = readText(path, opts.MaxTextBytes, false)
+	default:
+		p.Kind, p.Render = KindBinary, "hex"
+		p.Hex, err = hexHead(path, 256)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func listZip(path string, limit int) (*ZipListing, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("open zip: %w", err)
+	}
+	defer zr.Close()
+
+	out := &ZipListing{Entries: []ZipEntry{}, Total: len(zr.File)}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			out.Total--
+			continue
+		}
+		if len(out.Entries) >= limit {
+			out.Truncated = true
+			continue
+		}
+		out.Entries = append(out.Entries, ZipEntry{
+			Name:            f.Name,
+			SizeBytes:       int64(f.UncompressedSize64),
+			CompressedBytes: int64(f.CompressedSize64),
+			Modified:        f.Modified.Unix(),
+			MimeType:        zipEntryMime(f),
+		})
+	}
+	return out, nil
+}
+
+// zipEntryMime 读取条目头部探测类型（只解压前 512 字节）。
+func zipEntryMime(f *zip.File) string {
+	rc, err := f.Open()
+	if err != nil {
+		return filetype.Detect(nil, f.Name)
+	}
+	defer rc.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(rc, head)
+	return filetype.Detect(head[:n], f.Name)
+}
+
+// OpenSQLiteReadOnly 以只读 + immutable 方式打开 sqlite 文件：
+// 不加锁、不创建 -wal/-shm，保证证据文件不被改动。
+func OpenSQLiteReadOnly(path string) (*sql.DB, error) {
+	uri, err := SQLiteURI(path, "mode=ro&immutable=1")
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", uri)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// SQLiteURI 把本地路径转换为 sqlite file: URI（params 形如 "mode=ro"）。
+func SQLiteURI(path, params string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolve sqlite path: %w", err)
+	}
+	p := filepath.ToSlash(abs)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // Windows 盘符路径：file:/C:/...
+	}
+	p = strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23").Replace(p)
+	if params != "" {
+		p += "?" + params
+	}
+	return "file:" + p, nil
+}
+
+func summarizeSQLite(ctx context.Context, path string, limit int) (*SQLiteSummary, error) {
+	db, err := OpenSQLiteReadOnly(path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return SummarizeSQLite(ctx, db, limit)
+}
+
+// SummarizeSQLite 列出已打开数据库中的表/视图、列名与行数（最多 limit 张）。
+func SummarizeSQLite(ctx context.Context, db *sql.DB, limit int) (*SQLiteSummary, error) {
+	if limit <= 0 {
+		limit = 200
+	}
+	rows, err := db.QueryContext(ctx, `
+		SELECT name, type
+		FROM sqlite_master
+		WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'
+		ORDER BY type, name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query sqlite tables: %w", err)
+	}
+	out := &SQLiteSummary{Tables: []SQLiteTable{}}
+	for rows.Next() {
+		var t SQLiteTable
+		if err := rows.Scan(&t.Name, &t.Type); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan sqlite table: %w", err)
+		}
+		if len(out.Tables) >= limit {
+			out.Truncated = true
+			continue
+		}
+		out.Tables = append(out.Tables, t)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("iterate sqlite tables: %w", err)
+	}
+	rows.Close()
+
+	// 单连接：先收集表名，再逐表查询列与行数（避免在 rows.Next() 中嵌套查询）。
+	for i := range out.Tables {
+		t := &out.Tables[i]
+		cols, err := TableColumns(ctx, db, t.Name)
+		if err != nil {
+			return nil, err
+		}
+		t.Columns = cols
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+QuoteIdent(t.Name)).Scan(&t.Rows); err != nil {
+			// 视图可能引用缺失的表，行数取不到不影响其他表的展示。
+			t.Rows = -1
+		}
+	}
+	return out, nil
+}
+
+// TableColumns 返回表/视图的列名（按定义顺序）。
+func TableColumns(ctx context.Context, db *sql.DB, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return nil, fmt.Errorf("query table columns %s: %w", table, err)
+	}
+	defer rows.Close()
+	cols := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan table column: %w", err)
+		}
+		cols = append(cols, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate table columns: %w", err)
+	}
+	return cols, nil
+}
+
+// QuoteIdent 转义 sqlite 标识符（表名/列名）。
+func QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func thumbnail(path string, size int, maxPixels int64) (*ImageThumb, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open image: %w", err)
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode image config: %w", err)
+	}
+	out := &ImageThumb{Width: cfg.Width, Height: cfg.Height}
+	if int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		out.Note = "image too large for thumbnail"
+		return out, nil
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("seek image: %w", err)
+	}
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+	thumb := scaleDown(img, size)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, thumb); err != nil {
+		return nil, fmt.Errorf("encode thumbnail: %w", err)
+	}
+	b := thumb.Bounds()
+	out.ThumbWidth, out.ThumbHeight = b.Dx(), b.Dy()
+	out.DataURL = "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	return out, nil
+}
+
+// scaleDown 用最近邻采样把图片缩放到最长边不超过 size（不放大）。
+func scaleDown(src image.Image, size int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= size && h <= size {
+		return src
+	}
+	tw, th := size, size
+	if w >= h {
+		th = max(1, h*size/w)
+	} else {
+		tw = max(1, w*size/h)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		sy := b.Min.Y + y*h/th
+		for x := 0; x < tw; x++ {
+			dst.Set(x, y, src.At(b.Min.X+x*w/tw, sy))
+		}
+	}
+	return dst
+}
+
+// detectCompressed 按解压后的内容头探测压缩快照的 MIME 类型。
+func detectCompressed(path string) string {
+	rc, _, err := snapshot.Open(path)
+	if err != nil {
+		return filetype.MimeBinary
+	}
+	defer rc.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(rc, head)
+	return filetype.Detect(head[:n], strings.TrimSuffix(path, snapshot.GzipExt))
+}
+
+func readText(path string, limit int, isJSON bool) (*TextPreview, error) {
+	f, _, err := snapshot.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open snapshot: %w", err)
+	}
+	defer f.Close()
+
+	raw, err := io.ReadAll(io.LimitReader(f, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	out := &TextPreview{Language: "text"}
+	if isJSON {
+		out.Language = "json"
+	}
+	if len(raw) > limit {
+		out.Truncated = true
+		raw = raw[:limit]
+		for len(raw) > 0 && !utf8.Valid(raw) {
+			raw = raw[:len(raw)-1]
+		}
+	}
+	// 完整的 JSON 统一缩进输出，便于阅读；截断内容无法解析，原样返回。
+	if isJSON && !out.Truncated {
+		var buf bytes.Buffer
+		if json.Indent(&buf, raw, "", "  ") == nil {
+			raw = buf.Bytes()
+		}
+	}
+	out.Content = string(raw)
+	return out, nil
+}
+
+func hexHead(path string, n int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("open snapshot: %w", err)
+	}
+	defer f.Close()
+	head := make([]byte, n)
+	m, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", fmt.Errorf("read snapshot: %w", err)
+	}
+	return hex.Dump(head[:m]), nil
+}
+
+func withDefaults(opts Options) Options {
+	if opts.MaxZipEntries <= 0 {
+		opts.MaxZipEntries = 500
+	}
+	if opts.MaxTables <= 0 {
+		opts.MaxTables = 200
+	}
+	if opts.MaxTextBytes <= 0 {
+		opts.MaxTextBytes = 64 * 1024
+	}
+	if opts.ThumbSize <= 0 {
+		opts.ThumbSize = 256
+	}
+	if opts.MaxPixels <= 0 {
+		opts.MaxPixels = 40_000_000
+	}
+	return opts
+}
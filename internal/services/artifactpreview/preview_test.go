@@ -0,0 +1,94 @@
+package artifactpreview
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crypto-inspector/internal/platform/filetype"
+)
+
+func TestBuild_TypeSpecificPreviews(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	// sqlite：建一张表写入两行。
+	dbPath := filepath.Join(tmp, "History")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `CREATE TABLE urls(id INTEGER PRIMARY KEY, url TEXT); INSERT INTO urls(url) VALUES('https://a'), ('https://b');`); err != nil {
+		t.Fatalf("seed sqlite: %v", err)
+	}
+	db.Close()
+
+	p, err := Build(ctx, dbPath, Options{})
+	if err != nil {
+		t.Fatalf("sqlite preview: %v", err)
+	}
+	if p.Kind != KindSQLite || p.MimeType != filetype.MimeSQLite || len(p.SQLite.Tables) != 1 || p.SQLite.Tables[0].Rows != 2 {
+		t.Fatalf("unexpected sqlite preview: %+v %+v", p, p.SQLite)
+	}
+	if got := strings.Join(p.SQLite.Tables[0].Columns, ","); got != "id,url" {
+		t.Fatalf("unexpected columns: %s", got)
+	}
+	// immutable 只读打开不应生成 -wal/-shm。
+	if _, err := os.Stat(dbPath + "-wal"); err == nil {
+		t.Fatalf("read-only preview must not create wal file")
+	}
+
+	// zip：包含上面的 sqlite 副本，条目类型应被识别。
+	zipPath := filepath.Join(tmp, "snapshot.zip")
+	zf, _ := os.Create(zipPath)
+	zw := zip.NewWriter(zf)
+	raw, _ := os.ReadFile(dbPath)
+	ew, _ := zw.Create("chrome/Default/History")
+	_, _ = ew.Write(raw)
+	ew, _ = zw.Create("meta.json")
+	_, _ = ew.Write([]byte(`{"ok":true}`))
+	zw.Close()
+	zf.Close()
+
+	p, err = Build(ctx, zipPath, Options{})
+	if err != nil {
+		t.Fatalf("zip preview: %v", err)
+	}
+	if p.Kind != KindZip || p.Zip.Total != 2 || p.Zip.Entries[0].MimeType != filetype.MimeSQLite || p.Zip.Entries[1].MimeType != filetype.MimeJSON {
+		t.Fatalf("unexpected zip preview: %+v %+v", p, p.Zip)
+	}
+
+	// 图片：生成缩略图。
+	imgPath := filepath.Join(tmp, "shot.png")
+	img := image.NewRGBA(image.Rect(0, 0, 600, 300))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	f, _ := os.Create(imgPath)
+	_ = png.Encode(f, img)
+	f.Close()
+
+	p, err = Build(ctx, imgPath, Options{ThumbSize: 64})
+	if err != nil {
+		t.Fatalf("image preview: %v", err)
+	}
+	if p.Kind != KindImage || p.Image.Width != 600 || p.Image.ThumbWidth != 64 || p.Image.ThumbHeight != 32 || !strings.HasPrefix(p.Image.DataURL, "data:image/png;base64,") {
+		t.Fatalf("unexpected image preview: %+v", p.Image)
+	}
+
+	// json：缩进输出。
+	jsonPath := filepath.Join(tmp, "payload.json")
+	_ = os.WriteFile(jsonPath, []byte(`{"a":1}`), 0o644)
+	p, err = Build(ctx, jsonPath, Options{})
+	if err != nil {
+		t.Fatalf("json preview: %v", err)
+	}
+	if p.Kind != KindJSON || p.Render != "code" || !strings.Contains(p.Text.Content, "\n  \"a\": 1") {
+		t.Fatalf("unexpected json preview: %+v %+v", p, p.Text)
+	}
+}
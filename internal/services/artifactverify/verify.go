@@ -0,0 +1,460 @@
+package artifactverify
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 证据快照批量哈希复核
+//
+// iOS 整机备份等大体量证据目录逐个串行复算 sha256 需要数小时，本包把复核拆成：
+// - worker pool 并行读取/哈希快照文件（默认 min(NumCPU, 8)，磁盘 IO 通常先于 CPU 饱和）
+// - 进度回调：已完成文件数/字节数、files/sec、MB/s、ETA（按字节速率估算，读取中的大文件也计入）
+// - 续跑标记：每校验通过一个文件就向标记文件追加一行 JSON；中断后带 Resume 重新执行时，
+//   标记中的文件若期望哈希、大小、修改时间均未变化则直接计为 ok，不再重新读取
+// 只有 ok 的结果写入标记，失败项每次都会重新复核；全部目标处理完后删除标记文件。
+
+// DefaultWorkers 返回默认并发数。
+func DefaultWorkers() int {
+	n := runtime.NumCPU()
+	if n > 8 {
+		n = 8
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
+
+// DefaultMarkerPath 返回案件的默认续跑标记路径（与数据库同目录的 verify/ 下）。
+func DefaultMarkerPath(dbPath, caseID string) string {
+	return filepath.Join(filepath.Dir(dbPath), "verify", caseID+"_artifacts.resume.jsonl")
+}
+
+// Options 定义一次批量复核的参数。
+type Options struct {
+	Workers int
+
+	// MarkerPath 是续跑标记文件路径；为空时不记录也不读取标记。
+	MarkerPath string
+	// Resume 为 true 时读取 MarkerPath 中已通过的记录并跳过；否则先清空旧标记。
+	Resume bool
+
+	// Progress 在处理过程中按 ProgressInterval 节流回调，结束时再回调一次（Final=true）。
+	Progress         func(Progress)
+	ProgressInterval time.Duration
+}
+
+// Item 是单个证据快照的复核结果。
+type Item struct {
+	ArtifactID     string `json:"artifact_id"`
+	SnapshotPath   string `json:"snapshot_path"`
+	ExpectedSHA256 string `json:"expected_sha256"`
+	ActualSHA256   string `json:"actual_sha256,omitempty"`
+	ExpectedSize   int64  `json:"expected_size_bytes"`
+	ActualSize     int64  `json:"actual_size_bytes,omitempty"`
+	Status         string `json:"status"` // ok|mismatch|missing|error
+	Error          string `json:"error,omitempty"`
+	// Resumed 表示该项来自续跑标记，本次未重新读取文件。
+	Resumed bool `json:"resumed,omitempty"`
+}
+
+// Progress 是复核进度快照。
+type Progress struct {
+	Total      int   `json:"total"`
+	Done       int   `json:"done"`
+	OK         int   `json:"ok"`
+	Failed     int   `json:"failed"`
+	Resumed    int   `json:"resumed"`
+	BytesTotal int64 `json:"bytes_total"`
+	BytesDone  int64 `json:"bytes_done"`
+
+	ElapsedSeconds float64 `json:"elapsed_seconds"`
+	FilesPerSec    float64 `json:"files_per_sec"`
+	BytesPerSec    float64 `json:"bytes_per_sec"`
+	// ETASeconds 为剩余时间估算；尚无速率样本时为 -1。
+	ETASeconds float64 `json:"eta_seconds"`
+	Final      bool    `json:"final,omitempty"`
+}
+
+// Percent 返回 0-100 的完成百分比（优先按字节计算）。
+func (p Progress) Percent() int {
+	switch {
+	case p.BytesTotal > 0:
+		return int(p.BytesDone * 100 / p.BytesTotal)
+	case p.Total > 0:
+		return p.Done * 100 / p.Total
+	}
+	return 100
+}
+
+// Result 是批量复核的汇总结果；Items 与输入 targets 顺序一致（中断时只包含已完成项）。
+type Result struct {
+	Total         int     `json:"total"`
+	OKCount       int     `json:"ok_count"`
+	MismatchCount int     `json:"mismatch_count"`
+	MissingCount  int     `json:"missing_count"`
+	ErrorCount    int     `json:"error_count"`
+	ResumedCount  int     `json:"resumed_count"`
+	Workers       int     `json:"workers"`
+	Interrupted   bool    `json:"interrupted,omitempty"`
+	ElapsedSec    float64 `json:"elapsed_seconds"`
+	Items         []Item  `json:"results"`
+}
+
+// Failed 返回 mismatch/missing/error 的总数。
+func (r Result) Failed() int {
+	return r.MismatchCount + r.MissingCount + r.ErrorCount
+}
+
+// marker 是续跑标记文件中的一行。
+type marker struct {
+	ArtifactID string `json:"artifact_id"`
+	SHA256     string `json:"sha256"`
+	SizeBytes  int64  `json:"size_bytes"`
+	ModTime    int64  `json:"mtime_ns"`
+	VerifiedAt int64  `json:"verified_at"`
+}
+
+// Run 并行复核 targets 的快照文件哈希。
+// ctx 取消时停止派发并返回已完成部分（Result.Interrupted=true），同时返回 ctx.Err()；标记文件保留以便续跑。
+func Run(ctx context.Context, targets []model.ArtifactInfo, opts Options) (*Result, error) {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers()
+	}
+	if workers > len(targets) && len(targets) > 0 {
+		workers = len(targets)
+	}
+	interval := opts.ProgressInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	done := map[string]marker{}
+	if opts.MarkerPath != "" {
+		if opts.Resume {
+			m, err := loadMarkers(opts.MarkerPath)
+			if err != nil {
+				return nil, err
+			}
+			done = m
+		} else if err := os.Remove(opts.MarkerPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("reset verify marker: %w", err)
+		}
+	}
+	var markerFile *os.File
+	if opts.MarkerPath != "" {
+		if err := os.MkdirAll(filepath.Dir(opts.MarkerPath), 0o755); err != nil {
+			return nil, fmt.Errorf("create verify marker directory: %w", err)
+		}
+		f, err := os.OpenFile(opts.MarkerPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("open verify marker: %w", err)
+		}
+		markerFile = f
+	}
+
+	start := time.Now()
+	items := make([]*Item, len(targets))
+
+	var (
+		mu       sync.Mutex
+		prog     = Progress{Total: len(targets), ETASeconds: -1}
+		readDone int64        // 本次实际读取且已完成文件的字节数
+		inflight atomic.Int64 // 读取中文件已读的字节数
+		wErr     error
+	)
+	for _, t := range targets {
+		if t.SizeBytes > 0 {
+			prog.BytesTotal += t.SizeBytes
+		}
+	}
+	// snapshot 必须在持锁状态下调用。
+	snapshot := func(final bool) Progress {
+		p := prog
+		p.Final = final
+		p.ElapsedSeconds = time.Since(start).Seconds()
+		cur := inflight.Load()
+		p.BytesDone += cur
+		if p.BytesDone > p.BytesTotal {
+			p.BytesDone = p.BytesTotal
+		}
+		if p.ElapsedSeconds > 0 {
+			p.FilesPerSec = float64(p.Done-p.Resumed) / p.ElapsedSeconds
+			p.BytesPerSec = float64(readDone+cur) / p.ElapsedSeconds
+		}
+		switch {
+		case final || p.Done == p.Total:
+			p.ETASeconds = 0
+		case p.BytesPerSec > 0 && p.BytesTotal > 0:
+			p.ETASeconds = float64(p.BytesTotal-p.BytesDone) / p.BytesPerSec
+		case p.FilesPerSec > 0:
+			p.ETASeconds = float64(p.Total-p.Done) / p.FilesPerSec
+		}
+		return p
+	}
+	record := func(idx int, it Item, modTime, read int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		items[idx] = &it
+		prog.Done++
+		if targets[idx].SizeBytes > 0 {
+			prog.BytesDone += targets[idx].SizeBytes
+		}
+		readDone += read
+		if it.Status != "ok" {
+			prog.Failed++
+			return
+		}
+		prog.OK++
+		if it.Resumed {
+			prog.Resumed++
+			return
+		}
+		if markerFile == nil || wErr != nil {
+			return
+		}
+		line, _ := json.Marshal(marker{
+			ArtifactID: it.ArtifactID,
+			SHA256:     it.ActualSHA256,
+			SizeBytes:  it.ActualSize,
+			ModTime:    modTime,
+			VerifiedAt: time.Now().Unix(),
+		})
+		if _, err := markerFile.Write(append(line, '\n')); err != nil {
+			wErr = fmt.Errorf("write verify marker: %w", err)
+		}
+	}
+
+	stopTicker := make(chan struct{})
+	var tickerWG sync.WaitGroup
+	if opts.Progress != nil {
+		tickerWG.Add(1)
+		go func() {
+			defer tickerWG.Done()
+			tk := time.NewTicker(interval)
+			defer tk.Stop()
+			for {
+				select {
+				case <-stopTicker:
+					return
+				case <-tk.C:
+					mu.Lock()
+					p := snapshot(false)
+					mu.Unlock()
+					opts.Progress(p)
+				}
+			}
+		}()
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				t := targets[idx]
+				if it, modTime, ok := resumedItem(t, done); ok {
+					record(idx, it, modTime, 0)
+					continue
+				}
+				var counter atomic.Int64
+				it, modTime, read := verifyOne(ctx, t, &counter, &inflight)
+				if ctx.Err() != nil && it.Status != "ok" {
+					// 中断时正在读取的文件不计结果，续跑时重新复核。
+					continue
+				}
+				record(idx, it, modTime, read)
+			}
+		}()
+	}
+dispatch:
+	for i := range targets {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- i:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(stopTicker)
+	tickerWG.Wait()
+
+	res := &Result{Total: len(targets), Workers: workers, Items: make([]Item, 0, len(targets))}
+	for _, it := range items {
+		if it == nil {
+			continue
+		}
+		res.Items = append(res.Items, *it)
+		switch it.Status {
+		case "ok":
+			res.OKCount++
+		case "mismatch":
+			res.MismatchCount++
+		case "missing":
+			res.MissingCount++
+		default:
+			res.ErrorCount++
+		}
+		if it.Resumed {
+			res.ResumedCount++
+		}
+	}
+	res.Interrupted = len(res.Items) < len(targets)
+	res.ElapsedSec = time.Since(start).Seconds()
+
+	if markerFile != nil {
+		if err := markerFile.Close(); err != nil && wErr == nil {
+			wErr = fmt.Errorf("close verify marker: %w", err)
+		}
+		if !res.Interrupted && wErr == nil {
+			_ = os.Remove(opts.MarkerPath)
+		}
+	}
+	if opts.Progress != nil {
+		mu.Lock()
+		p := snapshot(true)
+		mu.Unlock()
+		opts.Progress(p)
+	}
+	if res.Interrupted {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+	}
+	return res, wErr
+}
+
+// resumedItem 判断续跑标记中的记录是否仍然有效：期望哈希一致，且文件大小与修改时间未变化。
+func resumedItem(t model.ArtifactInfo, done map[string]marker) (Item, int64, bool) {
+	m, ok := done[t.ArtifactID]
+	if !ok || !strings.EqualFold(m.SHA256, strings.TrimSpace(t.SHA256)) || m.SizeBytes != t.SizeBytes {
+		return Item{}, 0, false
+	}
+	info, err := os.Stat(t.SnapshotPath)
+	if err != nil || info.Size() != m.SizeBytes || info.ModTime().UnixNano() != m.ModTime {
+		return Item{}, 0, false
+	}
+	return Item{
+		ArtifactID:     t.ArtifactID,
+		SnapshotPath:   t.SnapshotPath,
+		ExpectedSHA256: t.SHA256,
+		ActualSHA256:   m.SHA256,
+		ExpectedSize:   t.SizeBytes,
+		ActualSize:     m.SizeBytes,
+		Status:         "ok",
+		Resumed:        true,
+	}, m.ModTime, true
+}
+
+// verifyOne 复算单个快照文件；返回结果、文件修改时间（纳秒）与实际读取字节数。
+// inflight 在读取过程中累加，结束时扣回（已完成文件的字节由调用方计入）。
+func verifyOne(ctx context.Context, t model.ArtifactInfo, counter, inflight *atomic.Int64) (Item, int64, int64) {
+	it := Item{
+		ArtifactID:     t.ArtifactID,
+		SnapshotPath:   t.SnapshotPath,
+		ExpectedSHA256: t.SHA256,
+		ExpectedSize:   t.SizeBytes,
+	}
+	var modTime int64
+	if info, err := os.Stat(t.SnapshotPath); err == nil {
+		modTime = info.ModTime().UnixNano()
+	}
+	sum, size, err := hashFile(ctx, t.SnapshotPath, counter, inflight)
+	read := counter.Load()
+	inflight.Add(-read)
+	if err != nil {
+		it.Status = "error"
+		if errors.Is(err, os.ErrNotExist) {
+			it.Status = "missing"
+		}
+		it.Error = err.Error()
+		return it, modTime, read
+	}
+	it.ActualSHA256 = sum
+	it.ActualSize = size
+	if !strings.EqualFold(sum, strings.TrimSpace(t.SHA256)) || size != t.SizeBytes {
+		it.Status = "mismatch"
+		return it, modTime, read
+	}
+	it.Status = "ok"
+	return it, modTime, read
+}
+
+// loadMarkers 读取续跑标记文件；文件不存在时返回空表，损坏的行（例如中断时写了一半）忽略。
+func loadMarkers(path string) (map[string]marker, error) {
+	out := map[string]marker{}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return out, nil
+		}
+		return nil, fmt.Errorf("open verify marker: %w", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var m marker
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil || m.ArtifactID == "" {
+			continue
+		}
+		out[m.ArtifactID] = m
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read verify marker: %w", err)
+	}
+	return out, nil
+}
+
+// hashFile 计算文件 sha256，读取过程中把字节数累加到 counter/inflight 并响应 ctx 取消。
+func hashFile(ctx context.Context, path string, counter, inflight *atomic.Int64) (sum string, size int64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.CopyBuffer(h, &countingReader{ctx: ctx, r: f, counters: []*atomic.Int64{counter, inflight}}, make([]byte, 1<<20))
+	if err != nil {
+		return "", n, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+type countingReader struct {
+	ctx      context.Context
+	r        io.Reader
+	counters []*atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := c.r.Read(p)
+	if n > 0 {
+		for _, ctr := range c.counters {
+			ctr.Add(int64(n))
+		}
+	}
+	return n, err
+}
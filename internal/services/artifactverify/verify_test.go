@@ -0,0 +1,128 @@
+package artifactverify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+)
+
+func writeTarget(t *testing.T, dir, name, content string) model.ArtifactInfo {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	sum, size, err := hash.File(p)
+	if err != nil {
+		t.Fatalf("hash %s: %v", name, err)
+	}
+	return model.ArtifactInfo{ArtifactID: "art_" + name, SnapshotPath: p, SHA256: sum, SizeBytes: size}
+}
+
+func TestRun_ParallelStatusesAndOrder(t *testing.T) {
+	dir := t.TempDir()
+	var targets []model.ArtifactInfo
+	for _, n := range []string{"a", "b", "c", "d", "e"} {
+		targets = append(targets, writeTarget(t, dir, n, "payload-"+n))
+	}
+	// b 被改动，d 被删除
+	if err := os.WriteFile(targets[1].SnapshotPath, []byte("tampered"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(targets[3].SnapshotPath); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var last Progress
+	res, err := Run(context.Background(), targets, Options{Workers: 3, Progress: func(p Progress) {
+		mu.Lock()
+		last = p
+		mu.Unlock()
+	}})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Total != 5 || res.OKCount != 3 || res.MismatchCount != 1 || res.MissingCount != 1 || res.Failed() != 2 {
+		t.Fatalf("unexpected counts: %+v", res)
+	}
+	for i, it := range res.Items {
+		if it.ArtifactID != targets[i].ArtifactID {
+			t.Fatalf("items out of order at %d: %s", i, it.ArtifactID)
+		}
+	}
+	if !last.Final || last.Done != 5 || last.ETASeconds != 0 {
+		t.Fatalf("unexpected final progress: %+v", last)
+	}
+}
+
+func TestRun_ResumeSkipsVerifiedFiles(t *testing.T) {
+	dir := t.TempDir()
+	markerPath := filepath.Join(dir, "verify", "case.resume.jsonl")
+	a := writeTarget(t, dir, "a", "alpha")
+	b := writeTarget(t, dir, "b", "bravo")
+
+	// 完整执行结束后删除标记文件。
+	res, err := Run(context.Background(), []model.ArtifactInfo{a}, Options{Workers: 1, MarkerPath: markerPath})
+	if err != nil || res.OKCount != 1 {
+		t.Fatalf("first run: res=%+v err=%v", res, err)
+	}
+	if _, err := os.Stat(markerPath); !os.IsNotExist(err) {
+		t.Fatalf("marker should be removed after a complete run, stat err=%v", err)
+	}
+
+	// 中断的执行保留标记文件。
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res, err = Run(ctx, []model.ArtifactInfo{a, b}, Options{Workers: 1, MarkerPath: markerPath})
+	if err == nil || !res.Interrupted {
+		t.Fatalf("cancelled run should report interruption: res=%+v err=%v", res, err)
+	}
+	if _, err := os.Stat(markerPath); err != nil {
+		t.Fatalf("marker should be kept after interruption: %v", err)
+	}
+
+	// 模拟中断前 a 已通过。
+	info, err := os.Stat(a.SnapshotPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := fmt.Sprintf(`{"artifact_id":%q,"sha256":%q,"size_bytes":%d,"mtime_ns":%d}`+"\n", a.ArtifactID, a.SHA256, a.SizeBytes, info.ModTime().UnixNano())
+	if err := os.WriteFile(markerPath, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err = Run(context.Background(), []model.ArtifactInfo{a, b}, Options{Workers: 2, MarkerPath: markerPath, Resume: true})
+	if err != nil {
+		t.Fatalf("resume run: %v", err)
+	}
+	if res.OKCount != 2 || res.ResumedCount != 1 || !res.Items[0].Resumed || res.Items[1].Resumed {
+		t.Fatalf("unexpected resume result: %+v", res)
+	}
+
+	// 标记记录后文件被改动：不能沿用标记。
+	if err := os.WriteFile(markerPath, []byte(line), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(a.SnapshotPath, []byte("ALPHA"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	later := info.ModTime().Add(time.Minute)
+	if err := os.Chtimes(a.SnapshotPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	res, err = Run(context.Background(), []model.ArtifactInfo{a}, Options{MarkerPath: markerPath, Resume: true})
+	if err != nil {
+		t.Fatalf("resume after change: %v", err)
+	}
+	if res.ResumedCount != 0 || res.MismatchCount != 1 {
+		t.Fatalf("changed file must be re-hashed: %+v", res)
+	}
+}
@@ -0,0 +1,226 @@
+package authdoc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/filetype"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/id"
+	"crypto-inspector/internal/services/precheckpolicy"
+)
+
+// 执法授权文书
+//
+// --auth-order 只是一个字符串；这里允许把扫描版授权文书/工单 PDF 作为案件附件上传：
+// - 文件原样落盘到证据目录 <case_id>/authorization/，库内记录 sha256、工单号与签发单位
+// - 上传时若填写了工单号，按前置检查策略中该签发单位的格式（正则）校验
+// - 扫描前置检查 authorization_order 的 detail 记录最近一份授权文书的哈希，并按同样的格式校验本次工单号；
+//   另有 authorization_document 检查（默认非必需，可由前置检查策略设为 block）
+
+// KindAuthorizationDocument 是授权文书附件的 kind。
+const KindAuthorizationDocument = "authorization_document"
+
+// MaxDocumentBytes 限制单个授权文书大小。
+const MaxDocumentBytes = 50 << 20
+
+var reUnsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Input 是一次授权文书上传的参数。
+type Input struct {
+	CaseID             string
+	FileName           string
+	Content            []byte
+	AuthorizationOrder string // 文书上的工单号（可选，填写时按签发单位格式校验）
+	Agency             string // 签发单位（可选，用于选择工单号格式）
+	Operator           string
+	EvidenceRoot       string
+}
+
+// Attach 校验并保存一份授权文书，追加审计。
+func Attach(ctx context.Context, store *sqliteadapter.Store, in Input) (*model.CaseAttachment, error) {
+	in.CaseID = strings.TrimSpace(in.CaseID)
+	in.AuthorizationOrder = strings.TrimSpace(in.AuthorizationOrder)
+	in.Agency = strings.TrimSpace(in.Agency)
+	in.Operator = strings.TrimSpace(in.Operator)
+	if in.Operator == "" {
+		in.Operator = "system"
+	}
+	if in.CaseID == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "case_id is required")
+	}
+	if len(in.Content) == 0 {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "document content is empty")
+	}
+	if len(in.Content) > MaxDocumentBytes {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("document too large: max=%d bytes", MaxDocumentBytes))
+	}
+	// 只按文件头判定，不信任扩展名。
+	if mime := filetype.Detect(in.Content, ""); mime != filetype.MimePDF {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("authorization document must be a PDF (detected %s)", mime))
+	}
+	if strings.TrimSpace(in.EvidenceRoot) == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "evidence root is required")
+	}
+	ov, err := store.GetCaseOverview(ctx, in.CaseID)
+	if err != nil {
+		return nil, err
+	}
+	if ov == nil {
+		return nil, apperr.T(apperr.CodeNotFound, "case.not_found", in.CaseID)
+	}
+	if in.AuthorizationOrder != "" {
+		policy, err := precheckpolicy.Load(ctx, store)
+		if err != nil {
+			return nil, err
+		}
+		if err := policy.ValidateOrder(in.Agency, in.AuthorizationOrder); err != nil {
+			return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "invalid authorization order")
+		}
+	}
+
+	attachmentID := id.New("att")
+	dir := filepath.Join(in.EvidenceRoot, in.CaseID, "authorization")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create authorization dir: %w", err)
+	}
+	name := reUnsafeName.ReplaceAllString(filepath.Base(strings.TrimSpace(in.FileName)), "_")
+	if name == "" || name == "." || name == "_" {
+		name = "authorization.pdf"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s", attachmentID, name))
+	if err := os.WriteFile(path, in.Content, 0o644); err != nil {
+		return nil, fmt.Errorf("write authorization document: %w", err)
+	}
+	sum, size, err := hash.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("hash authorization document: %w", err)
+	}
+
+	att := model.CaseAttachment{
+		AttachmentID:       attachmentID,
+		CaseID:             in.CaseID,
+		Kind:               KindAuthorizationDocument,
+		OriginalName:       strings.TrimSpace(in.FileName),
+		FilePath:           path,
+		SHA256:             sum,
+		SizeBytes:          size,
+		MimeType:           filetype.MimePDF,
+		AuthorizationOrder: in.AuthorizationOrder,
+		Agency:             in.Agency,
+		UploadedBy:         in.Operator,
+		UploadedAt:         time.Now().Unix(),
+	}
+	if att.OriginalName == "" {
+		att.OriginalName = name
+	}
+	if err := store.SaveCaseAttachment(ctx, att); err != nil {
+		return nil, err
+	}
+	_ = store.AppendAudit(ctx, in.CaseID, "", "authorization", "attach_document", "success", in.Operator, "authdoc.Attach", map[string]any{
+		"attachment_id":       att.AttachmentID,
+		"original_name":       att.OriginalName,
+		"sha256":              att.SHA256,
+		"size_bytes":          att.SizeBytes,
+		"authorization_order": att.AuthorizationOrder,
+		"agency":              att.Agency,
+	})
+	return &att, nil
+}
+
+// Latest 返回案件最近上传的授权文书；没有时返回 nil。
+func Latest(ctx context.Context, store *sqliteadapter.Store, caseID string) (*model.CaseAttachment, error) {
+	docs, err := store.ListCaseAttachments(ctx, caseID, KindAuthorizationDocument)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	return &docs[0], nil
+}
+
+// Prechecks 构造授权相关的前置检查：authorization_order（工单号 + 格式 + 文书哈希）与 authorization_document。
+//
+// 工单号缺失且 requireOrder 时、或工单号不符合签发单位格式时，authorization_order 为 failed；
+// 是否中止扫描由调用方按 Required 与前置检查策略判定。
+func Prechecks(ctx context.Context, store *sqliteadapter.Store, policy *precheckpolicy.Policy, caseID, order, basis string, requireOrder bool) ([]model.PrecheckResult, error) {
+	doc, err := Latest(ctx, store, caseID)
+	if err != nil {
+		return nil, err
+	}
+	agency := ""
+	if doc != nil {
+		agency = doc.Agency
+	}
+
+	status := model.PrecheckPassed
+	message := order
+	detail := map[string]any{"authorization_basis": basis}
+	if order == "" {
+		status = model.PrecheckSkipped
+		message = "not provided"
+		if requireOrder {
+			status = model.PrecheckFailed
+			message = "authorization order is required but missing"
+		}
+	} else if f, ok := policy.OrderFormatFor(agency); ok {
+		detail["order_format"] = map[string]any{"agency": f.Agency, "pattern": f.Pattern}
+		if err := policy.ValidateOrder(agency, order); err != nil {
+			status = model.PrecheckFailed
+			message = err.Error()
+		}
+	}
+	docStatus, docMessage := model.PrecheckSkipped, "not uploaded"
+	docDetail := map[string]any{}
+	if doc != nil {
+		docStatus, docMessage = model.PrecheckPassed, doc.SHA256
+		docDetail = map[string]any{
+			"attachment_id":       doc.AttachmentID,
+			"original_name":       doc.OriginalName,
+			"sha256":              doc.SHA256,
+			"size_bytes":          doc.SizeBytes,
+			"authorization_order": doc.AuthorizationOrder,
+			"agency":              doc.Agency,
+			"uploaded_at":         doc.UploadedAt,
+		}
+		detail["authorization_document"] = docDetail
+	}
+
+	rawDetail, _ := json.Marshal(detail)
+	rawDocDetail, _ := json.Marshal(docDetail)
+	now := time.Now().Unix()
+	return []model.PrecheckResult{
+		{
+			CaseID:     caseID,
+			ScanScope:  "general",
+			CheckCode:  "authorization_order",
+			CheckName:  "执法授权工单已提供",
+			Required:   requireOrder,
+			Status:     status,
+			Message:    message,
+			DetailJSON: rawDetail,
+			CheckedAt:  now,
+		},
+		{
+			CaseID:     caseID,
+			ScanScope:  "general",
+			CheckCode:  "authorization_document",
+			CheckName:  "执法授权文书已上传",
+			Required:   false,
+			Status:     docStatus,
+			Message:    docMessage,
+			DetailJSON: rawDocDetail,
+			CheckedAt:  now,
+		},
+	}, nil
+}
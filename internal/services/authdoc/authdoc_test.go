@@ -0,0 +1,90 @@
+package authdoc
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/services/precheckpolicy"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestAttachAndPrechecks(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := precheckpolicy.Save(ctx, store, []byte(`
+bundle_type: precheck_policy
+authorization_order_formats:
+  - agency: cyber
+    pattern: '^CY-\d{6}$'
+`)); err != nil {
+		t.Fatal(err)
+	}
+
+	pdf := []byte("%PDF-1.4\n% warrant\n")
+	in := Input{CaseID: caseID, FileName: "warrant scan.pdf", Content: pdf, Agency: "cyber", Operator: "op", EvidenceRoot: filepath.Join(dir, "evidence")}
+
+	bad := in
+	bad.Content = []byte("not a pdf")
+	if _, err := Attach(ctx, store, bad); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("non-pdf err=%v", err)
+	}
+	bad = in
+	bad.AuthorizationOrder = "TICKET-1"
+	if _, err := Attach(ctx, store, bad); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("bad order err=%v", err)
+	}
+
+	in.AuthorizationOrder = "CY-000123"
+	doc, err := Attach(ctx, store, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.SHA256 != hash.Bytes(pdf) || doc.MimeType != "application/pdf" || filepath.Base(doc.FilePath) != doc.AttachmentID+"_warrant_scan.pdf" {
+		t.Fatalf("doc=%+v", doc)
+	}
+
+	// 工单号按授权文书登记的签发单位校验，detail 记录文书哈希。
+	policy, err := precheckpolicy.Load(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks, err := Prechecks(ctx, store, policy, caseID, "CY-000123", "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var detail struct {
+		Document struct {
+			SHA256 string `json:"sha256"`
+		} `json:"authorization_document"`
+	}
+	_ = json.Unmarshal(checks[0].DetailJSON, &detail)
+	if checks[0].Status != model.PrecheckPassed || detail.Document.SHA256 != doc.SHA256 || checks[1].Status != model.PrecheckPassed {
+		t.Fatalf("checks=%+v", checks)
+	}
+	checks, _ = Prechecks(ctx, store, policy, caseID, "wrong", "", true)
+	if checks[0].Status != model.PrecheckFailed || !checks[0].Required {
+		t.Fatalf("bad order check=%+v", checks[0])
+	}
+}
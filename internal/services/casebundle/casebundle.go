@@ -0,0 +1,413 @@
+package casebundle
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/platform/hash"
+)
+
+// 案件证据/报告打包下载
+//
+// 交接时逐个下载几十个证据文件不现实，这里把案件的证据快照与报告文件打成一个 tar：
+//   - evidence/<device_id>/<artifact_id>_<文件名>、reports/<report_id>_<文件名>
+//   - manifest.json：每个文件的来源 ID、入库 sha256 与打包时实际 sha256（不一致/缺失的文件记入 warnings）
+//   - SHA256SUMS：最后一个条目，列出包内全部文件的 sha256（sha256sum -c 可直接校验解包结果）
+// 内容相同（证据与报告的 ID、sha256 不变）时打包结果逐字节一致，并缓存在 CacheDir 下复用，
+// 断点续传的 Range 请求始终读取同一个文件。
+
+// 打包范围。
+const (
+	KindAll      = "all"
+	KindEvidence = "evidence"
+	KindReports  = "reports"
+)
+
+// Options 是打包参数。
+type Options struct {
+	Kind     string
+	CacheDir string
+}
+
+// Bundle 是一次打包的结果（tar 文件位于缓存目录，调用方只读）。
+type Bundle struct {
+	Path        string   `json:"-"`
+	CaseID      string   `json:"case_id"`
+	Kind        string   `json:"kind"`
+	Fingerprint string   `json:"fingerprint"`
+	SHA256      string   `json:"sha256"`
+	SizeBytes   int64    `json:"size_bytes"`
+	FileCount   int      `json:"file_count"`
+	Warnings    []string `json:"warnings,omitempty"`
+	ModTime     int64    `json:"mod_time"`
+	Cached      bool     `json:"cached"`
+}
+
+// ManifestFile 是 manifest.json 中的一个文件。
+type ManifestFile struct {
+	Path           string `json:"path"`
+	Source         string `json:"source"` // artifact|report
+	SourceID       string `json:"source_id"`
+	DeviceID       string `json:"device_id,omitempty"`
+	Type           string `json:"type"`
+	ExpectedSHA256 string `json:"expected_sha256"`
+	SHA256         string `json:"sha256"`
+	SizeBytes      int64  `json:"size_bytes"`
+}
+
+// Manifest 是包内 manifest.json。
+type Manifest struct {
+	CaseID      string         `json:"case_id"`
+	Kind        string         `json:"kind"`
+	Fingerprint string         `json:"fingerprint"`
+	Files       []ManifestFile `json:"files"`
+	Warnings    []string       `json:"warnings,omitempty"`
+}
+
+// ParseKind 规范化打包范围（空视为 all）。
+func ParseKind(kind string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "", KindAll:
+		return KindAll, nil
+	case KindEvidence:
+		return KindEvidence, nil
+	case KindReports:
+		return KindReports, nil
+	default:
+		return "", apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid bundle kind %q (all|evidence|reports)", kind))
+	}
+}
+
+type source struct {
+	file    ManifestFile
+	srcPath string
+	modTime int64
+}
+
+// Build 打包案件的证据与报告；缓存中已有相同内容的包时直接复用。案件不存在时返回 CodeNotFound。
+func Build(ctx context.Context, store *sqliteadapter.Store, caseID string, opts Options) (*Bundle, error) {
+	kind, err := ParseKind(opts.Kind)
+	if err != nil {
+		return nil, err
+	}
+	ov, err := store.GetCaseOverview(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if ov == nil {
+		return nil, apperr.T(apperr.CodeNotFound, "case.not_found", caseID)
+	}
+
+	sources, err := collectSources(ctx, store, caseID, kind)
+	if err != nil {
+		return nil, err
+	}
+	fp := fingerprint(caseID, kind, sources)
+	var modTime int64
+	for _, s := range sources {
+		if s.modTime > modTime {
+			modTime = s.modTime
+		}
+	}
+
+	if err := os.MkdirAll(opts.CacheDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create bundle cache dir: %w", err)
+	}
+	prefix := fmt.Sprintf("%s_%s_", safeName(caseID), kind)
+	target := filepath.Join(opts.CacheDir, prefix+fp[:fingerprintLen]+".tar")
+	manifestPath := target + ".json"
+
+	if b, err := loadCached(target, manifestPath); err == nil {
+		b.Cached = true
+		return b, nil
+	}
+
+	b, err := writeBundle(ctx, caseID, kind, fp, modTime, sources, target)
+	if err != nil {
+		return nil, err
+	}
+	raw, _ := json.Marshal(b)
+	if err := os.WriteFile(manifestPath, raw, 0o644); err != nil {
+		return nil, fmt.Errorf("write bundle index: %w", err)
+	}
+	removeStale(opts.CacheDir, prefix, caseID, target)
+	return b, nil
+}
+
+// collectSources 按固定顺序（证据按采集时间、报告按生成时间）列出要打包的文件。
+func collectSources(ctx context.Context, store *sqliteadapter.Store, caseID, kind string) ([]source, error) {
+	var out []source
+	if kind == KindAll || kind == KindEvidence {
+		arts, err := store.ListArtifactsByCase(ctx, caseID)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(arts, func(i, j int) bool {
+			if arts[i].CollectedAt != arts[j].CollectedAt {
+				return arts[i].CollectedAt < arts[j].CollectedAt
+			}
+			return arts[i].ArtifactID < arts[j].ArtifactID
+		})
+		for _, a := range arts {
+			out = append(out, source{
+				file: ManifestFile{
+					Path:           path.Join("evidence", safeName(a.DeviceID), a.ArtifactID+"_"+filepath.Base(a.SnapshotPath)),
+					Source:         "artifact",
+					SourceID:       a.ArtifactID,
+					DeviceID:       a.DeviceID,
+					Type:           a.ArtifactType,
+					ExpectedSHA256: strings.ToLower(a.SHA256),
+				},
+				srcPath: a.SnapshotPath,
+				modTime: a.CollectedAt,
+			})
+		}
+	}
+	if kind == KindAll || kind == KindReports {
+		reports, err := store.ListReportsByCase(ctx, caseID)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(reports, func(i, j int) bool {
+			if reports[i].GeneratedAt != reports[j].GeneratedAt {
+				return reports[i].GeneratedAt < reports[j].GeneratedAt
+			}
+			return reports[i].ReportID < reports[j].ReportID
+		})
+		for _, r := range reports {
+			out = append(out, source{
+				file: ManifestFile{
+					Path:           path.Join("reports", r.ReportID+"_"+filepath.Base(r.FilePath)),
+					Source:         "report",
+					SourceID:       r.ReportID,
+					Type:           r.ReportType,
+					ExpectedSHA256: strings.ToLower(r.SHA256),
+				},
+				srcPath: r.FilePath,
+				modTime: r.GeneratedAt,
+			})
+		}
+	}
+	return out, nil
+}
+
+// fingerprint 由打包范围、每个文件的来源 ID、入库 sha256 以及磁盘文件的大小/修改时间计算：
+// 入库记录与磁盘文件都不变时不变；文件被改动、删除或恢复时重新打包（warnings 随之更新）。
+func fingerprint(caseID, kind string, sources []source) string {
+	parts := []string{caseID, kind}
+	for _, s := range sources {
+		stat := "missing"
+		if st, err := os.Stat(s.srcPath); err == nil {
+			stat = fmt.Sprintf("%d/%d", st.Size(), st.ModTime().UnixNano())
+		}
+		parts = append(parts, s.file.Source, s.file.SourceID, s.file.ExpectedSHA256, s.file.Path, stat)
+	}
+	return hash.Text(parts...)
+}
+
+func writeBundle(ctx context.Context, caseID, kind, fp string, modTime int64, sources []source, target string) (_ *Bundle, retErr error) {
+	tmp, err := os.CreateTemp(filepath.Dir(target), filepath.Base(target)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("create bundle file: %w", err)
+	}
+	defer func() {
+		if retErr != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	sum := sha256.New()
+	tw := tar.NewWriter(io.MultiWriter(tmp, sum))
+	manifest := Manifest{CaseID: caseID, Kind: kind, Fingerprint: fp, Files: []ManifestFile{}}
+	var sums []string
+	for _, s := range sources {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		src, st, err := openSource(s.srcPath)
+		if err != nil {
+			manifest.Warnings = append(manifest.Warnings, fmt.Sprintf("%s %s skipped: %v", s.file.Source, s.file.SourceID, err))
+			continue
+		}
+		f, err := addFile(tw, s, src, st)
+		src.Close()
+		if err != nil {
+			return nil, err
+		}
+		if f.SHA256 != f.ExpectedSHA256 {
+			manifest.Warnings = append(manifest.Warnings, fmt.Sprintf("%s %s sha256 mismatch: expected %s got %s", f.Source, f.SourceID, f.ExpectedSHA256, f.SHA256))
+		}
+		manifest.Files = append(manifest.Files, f)
+		sums = append(sums, f.SHA256+"  "+f.Path)
+	}
+
+	rawManifest, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal bundle manifest: %w", err)
+	}
+	if err := addBytes(tw, "manifest.json", rawManifest, modTime); err != nil {
+		return nil, err
+	}
+	sums = append(sums, hash.Bytes(rawManifest)+"  manifest.json")
+	if err := addBytes(tw, "SHA256SUMS", []byte(strings.Join(sums, "\n")+"\n"), modTime); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("finish bundle: %w", err)
+	}
+	st, err := tmp.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat bundle: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("write bundle: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return nil, fmt.Errorf("store bundle: %w", err)
+	}
+	return &Bundle{
+		Path:        target,
+		CaseID:      caseID,
+		Kind:        kind,
+		Fingerprint: fp,
+		SHA256:      hex.EncodeToString(sum.Sum(nil)),
+		SizeBytes:   st.Size(),
+		FileCount:   len(manifest.Files),
+		Warnings:    manifest.Warnings,
+		ModTime:     modTime,
+	}, nil
+}
+
+// openSource 打开待打包的源文件；文件缺失或不是普通文件时由调用方跳过并记入 warnings。
+func openSource(p string) (*os.File, os.FileInfo, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if !st.Mode().IsRegular() {
+		f.Close()
+		return nil, nil, fmt.Errorf("not a regular file: %s", p)
+	}
+	return f, st, nil
+}
+
+// addFile 把源文件写入 tar，同时计算实际 sha256；tar 头写出后出错无法跳过，整个打包失败。
+func addFile(tw *tar.Writer, s source, f *os.File, st os.FileInfo) (ManifestFile, error) {
+	if err := tw.WriteHeader(header(s.file.Path, st.Size(), s.modTime)); err != nil {
+		return ManifestFile{}, fmt.Errorf("write tar header: %w", err)
+	}
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(tw, h), f)
+	if err != nil {
+		return ManifestFile{}, fmt.Errorf("copy %s: %w", s.srcPath, err)
+	}
+	if n != st.Size() {
+		return ManifestFile{}, fmt.Errorf("file size changed while bundling: %s", s.srcPath)
+	}
+	out := s.file
+	out.SHA256 = hex.EncodeToString(h.Sum(nil))
+	out.SizeBytes = n
+	return out, nil
+}
+
+func addBytes(tw *tar.Writer, name string, raw []byte, modTime int64) error {
+	if err := tw.WriteHeader(header(name, int64(len(raw)), modTime)); err != nil {
+		return fmt.Errorf("write tar header: %w", err)
+	}
+	if _, err := tw.Write(raw); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+// header 生成固定属主/权限的 tar 头，保证同样的内容得到同样的字节。
+func header(name string, size, modTime int64) *tar.Header {
+	return &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     0o644,
+		ModTime:  time.Unix(modTime, 0),
+		Format:   tar.FormatPAX,
+	}
+}
+
+func loadCached(target, manifestPath string) (*Bundle, error) {
+	raw, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	var b Bundle
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return nil, err
+	}
+	st, err := os.Stat(target)
+	if err != nil {
+		return nil, err
+	}
+	if st.Size() != b.SizeBytes {
+		return nil, fmt.Errorf("cached bundle size mismatch: %s", target)
+	}
+	b.Path = target
+	return &b, nil
+}
+
+// fingerprintLen 是缓存文件名中指纹（十六进制）的长度。
+const fingerprintLen = 16
+
+// removeStale 删除同一案件/范围下内容已过期的旧包。
+//
+// 文件名只认 prefix + 定长指纹 + ".tar"：案件 a 的前缀 a_all_ 同样是案件 a_all 缓存文件名的前缀，
+// 宽松匹配会误删其他案件的包（可能正在下载）。safeName 可能把不同案件 ID 映射为同一前缀，
+// 因此还要求索引中的 case_id 一致，索引缺失或无法解析时保留文件。
+func removeStale(dir, prefix, caseID, keep string) {
+	matches, _ := filepath.Glob(filepath.Join(dir, prefix+"*.tar"))
+	for _, m := range matches {
+		if m == keep {
+			continue
+		}
+		fp := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(m), prefix), ".tar")
+		if len(fp) != fingerprintLen || strings.Trim(fp, "0123456789abcdef") != "" {
+			continue
+		}
+		raw, err := os.ReadFile(m + ".json")
+		if err != nil {
+			continue
+		}
+		var b Bundle
+		if err := json.Unmarshal(raw, &b); err != nil || b.CaseID != caseID {
+			continue
+		}
+		_ = os.Remove(m)
+		_ = os.Remove(m + ".json")
+	}
+}
+
+// safeName 把 ID 限制为文件名安全字符。
+func safeName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
@@ -0,0 +1,186 @@
+package casebundle
+
+import (
+	"archive/tar"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestBuild_DeterministicCachedWithChecksumTrailer(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev := model.Device{ID: "dev_1", Name: "d", OS: model.OSWindows, Identifier: "id-1"}
+	if err := store.UpsertDevice(ctx, caseID, dev, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	var arts []model.Artifact
+	for i, name := range []string{"apps.json", "gone.json"} {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(`["`+name+`"]`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		sum, size, err := hash.File(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		arts = append(arts, model.Artifact{
+			ID: "art_" + name, CaseID: caseID, DeviceID: dev.ID, Type: model.ArtifactInstalledApps,
+			SourceRef: name, SnapshotPath: p, SHA256: sum, SizeBytes: size,
+			CollectedAt: time.Now().Unix() + int64(i), CollectorName: "test", CollectorVersion: "1", ParserVersion: "1",
+			AcquisitionMethod: "test", PayloadJSON: []byte(`[]`), RecordHash: strings.Repeat("0", 64),
+		})
+	}
+	if err := store.SaveArtifacts(ctx, arts); err != nil {
+		t.Fatal(err)
+	}
+	// 快照丢失的证据只记 warning，不中断打包。
+	if err := os.Remove(arts[1].SnapshotPath); err != nil {
+		t.Fatal(err)
+	}
+
+	cacheDir := filepath.Join(dir, "cache")
+	first, err := Build(ctx, store, caseID, Options{Kind: "all", CacheDir: cacheDir})
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if first.Cached || first.FileCount != 1 || len(first.Warnings) != 1 {
+		t.Fatalf("unexpected first bundle: %+v", first)
+	}
+	second, err := Build(ctx, store, caseID, Options{CacheDir: cacheDir})
+	if err != nil {
+		t.Fatalf("Build again: %v", err)
+	}
+	if !second.Cached || second.SHA256 != first.SHA256 || second.Path != first.Path {
+		t.Fatalf("second build should reuse the cached bundle: first=%+v second=%+v", first, second)
+	}
+	if sum, _, err := hash.File(first.Path); err != nil || sum != first.SHA256 {
+		t.Fatalf("bundle sha256=%s err=%v want %s", sum, err, first.SHA256)
+	}
+
+	f, err := os.Open(first.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var names []string
+	tr := tar.NewReader(f)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read tar: %v", err)
+		}
+		names = append(names, h.Name)
+	}
+	want := []string{"evidence/dev_1/art_apps.json_apps.json", "manifest.json", "SHA256SUMS"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("tar entries=%v want %v", names, want)
+	}
+
+	if _, err := Build(ctx, store, caseID, Options{Kind: "bogus", CacheDir: cacheDir}); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("invalid kind err=%v", err)
+	}
+	if _, err := Build(ctx, store, "case_missing", Options{CacheDir: cacheDir}); apperr.CodeOf(err) != apperr.CodeNotFound {
+		t.Fatalf("missing case err=%v", err)
+	}
+}
+
+func TestBuild_StaleCleanupKeepsOtherCasesWithSharedPrefix(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	// 案件 a 的 all 包前缀 a_all_ 也是案件 a_all 的缓存文件名前缀（a_all_all_<fp>.tar）。
+	for _, id := range []string{"a", "a_all"} {
+		if _, err := store.EnsureCase(ctx, id, "", id, "op", ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.UpsertDevice(ctx, id, model.Device{ID: "dev_" + id, Name: "d", OS: model.OSWindows, Identifier: "id-" + id}, true, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	addArtifact := func(caseID, name string) {
+		p := filepath.Join(dir, caseID+"_"+name)
+		if err := os.WriteFile(p, []byte(`["`+name+`"]`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		sum, size, err := hash.File(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.SaveArtifacts(ctx, []model.Artifact{{
+			ID: "art_" + caseID + "_" + name, CaseID: caseID, DeviceID: "dev_" + caseID, Type: model.ArtifactInstalledApps,
+			SourceRef: name, SnapshotPath: p, SHA256: sum, SizeBytes: size,
+			CollectedAt: time.Now().Unix(), CollectorName: "test", CollectorVersion: "1", ParserVersion: "1",
+			AcquisitionMethod: "test", PayloadJSON: []byte(`[]`), RecordHash: strings.Repeat("0", 64),
+		}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cacheDir := filepath.Join(dir, "cache")
+	addArtifact("a_all", "apps.json")
+	other, err := Build(ctx, store, "a_all", Options{Kind: "all", CacheDir: cacheDir})
+	if err != nil {
+		t.Fatalf("Build a_all: %v", err)
+	}
+	addArtifact("a", "apps.json")
+	old, err := Build(ctx, store, "a", Options{Kind: "all", CacheDir: cacheDir})
+	if err != nil {
+		t.Fatalf("Build a: %v", err)
+	}
+	addArtifact("a", "more.json")
+	fresh, err := Build(ctx, store, "a", Options{Kind: "all", CacheDir: cacheDir})
+	if err != nil {
+		t.Fatalf("Build a again: %v", err)
+	}
+	if fresh.Path == old.Path {
+		t.Fatalf("content changed but bundle path reused: %s", fresh.Path)
+	}
+	if _, err := os.Stat(old.Path); !os.IsNotExist(err) {
+		t.Fatalf("stale bundle of case a should be removed: %v", err)
+	}
+	for _, p := range []string{other.Path, other.Path + ".json"} {
+		if _, err := os.Stat(p); err != nil {
+			t.Fatalf("bundle of case a_all removed while building case a: %v", err)
+		}
+	}
+}
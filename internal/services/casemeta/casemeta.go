@@ -0,0 +1,182 @@
+package casemeta
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/checklist"
+)
+
+// 案件元数据修改（标题 / 案件编号 / 状态）
+//
+// 状态只能单向流转：open → closed → archived，相同状态视为无变化。
+// 改为 closed 走 checklist.Close（external profile 仍要求清单完成）；archived 的案件元数据只读，
+// 归档请求也不能同时修改其他字段。每个发生变化的字段各写一条审计（event_type=case, action=update）。
+
+const (
+	// MaxTitleLen 限制标题长度（字符数）。
+	MaxTitleLen = 200
+	// MaxCaseNoLen 限制案件编号长度（字符数）。
+	MaxCaseNoLen = 64
+)
+
+// 案件状态。
+const (
+	StatusOpen     = "open"
+	StatusClosed   = "closed"
+	StatusArchived = "archived"
+)
+
+// transitions 是允许的状态流转。
+var transitions = map[string]string{
+	StatusOpen:   StatusClosed,
+	StatusClosed: StatusArchived,
+}
+
+// Patch 是一次部分更新；nil 字段保持不变，CaseNo 为空字符串表示清除编号。
+type Patch struct {
+	Title  *string `json:"title,omitempty"`
+	CaseNo *string `json:"case_no,omitempty"`
+	Status *string `json:"status,omitempty"`
+	// Profile 为改为 closed 时的结案 profile（internal|external，默认 internal）。
+	Profile  string `json:"profile,omitempty"`
+	Operator string `json:"operator,omitempty"`
+}
+
+// Change 是一个字段的变化。
+type Change struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Result 是修改结果：更新后的案件概览与实际发生的变化（无变化时 Changes 为空）。
+type Result struct {
+	Case    *model.CaseOverview `json:"case"`
+	Changes []Change            `json:"changes"`
+}
+
+// Update 校验并应用 Patch。参数不合法返回 ERR_INVALID_ARGUMENT，案件不存在返回 ERR_NOT_FOUND，
+// 状态流转不允许 / 编号被占用 / 修改归档案件返回 ERR_CONFLICT。
+func Update(ctx context.Context, store *sqliteadapter.Store, caseID string, p Patch) (*Result, error) {
+	if p.Title == nil && p.CaseNo == nil && p.Status == nil {
+		return nil, apperr.T(apperr.CodeInvalidArgument, "casemeta.no_fields")
+	}
+	operator := strings.TrimSpace(p.Operator)
+	if operator == "" {
+		operator = "system"
+	}
+	cur, err := store.GetCaseOverview(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if cur == nil {
+		return nil, apperr.T(apperr.CodeNotFound, "case.not_found", caseID)
+	}
+
+	// 先完成全部校验，再写库，避免部分字段生效。
+	title, caseNo := cur.Title, cur.CaseNo
+	if p.Title != nil {
+		if title, err = cleanText("title", *p.Title, MaxTitleLen); err != nil {
+			return nil, err
+		}
+		if title == "" {
+			return nil, apperr.T(apperr.CodeInvalidArgument, "casemeta.title_empty")
+		}
+	}
+	if p.CaseNo != nil {
+		if caseNo, err = cleanText("case_no", *p.CaseNo, MaxCaseNoLen); err != nil {
+			return nil, err
+		}
+		if caseNo != "" && caseNo != cur.CaseNo {
+			owner, err := store.CaseIDByCaseNo(ctx, caseNo)
+			if err != nil {
+				return nil, err
+			}
+			if owner != "" && owner != caseID {
+				return nil, apperr.T(apperr.CodeConflict, "casemeta.case_no_taken", caseNo, owner)
+			}
+		}
+	}
+	status := cur.Status
+	if p.Status != nil {
+		status = strings.ToLower(strings.TrimSpace(*p.Status))
+		switch status {
+		case StatusOpen, StatusClosed, StatusArchived:
+		default:
+			return nil, apperr.T(apperr.CodeInvalidArgument, "casemeta.invalid_status", *p.Status, StatusOpen, StatusClosed, StatusArchived)
+		}
+		if status != cur.Status && transitions[cur.Status] != status {
+			return nil, apperr.T(apperr.CodeConflict, "casemeta.transition", cur.Status, status)
+		}
+	}
+	metaChanged := title != cur.Title || caseNo != cur.CaseNo
+	if metaChanged && (cur.Status == StatusArchived || status == StatusArchived) {
+		return nil, apperr.T(apperr.CodeConflict, "casemeta.archived_readonly")
+	}
+
+	// 先写标题/编号，成功后再改状态；状态写入失败（例如 external 结案清单未完成）时恢复原标题/编号，
+	// 保证一次 PATCH 要么全部生效、要么都不生效。
+	var changes []Change
+	if metaChanged {
+		if _, err := store.UpdateCaseMeta(ctx, caseID, title, caseNo); err != nil {
+			return nil, err
+		}
+		if title != cur.Title {
+			changes = append(changes, Change{Field: "title", From: cur.Title, To: title})
+		}
+		if caseNo != cur.CaseNo {
+			changes = append(changes, Change{Field: "case_no", From: cur.CaseNo, To: caseNo})
+		}
+	}
+	if status != cur.Status {
+		var err error
+		if status == StatusClosed {
+			_, err = checklist.Close(ctx, store, caseID, p.Profile, operator)
+		} else {
+			_, err = store.SetCaseStatus(ctx, caseID, status)
+		}
+		if err != nil {
+			if metaChanged {
+				if _, rerr := store.UpdateCaseMeta(ctx, caseID, cur.Title, cur.CaseNo); rerr != nil {
+					return nil, fmt.Errorf("%w (restore case meta: %v)", err, rerr)
+				}
+			}
+			return nil, err
+		}
+		changes = append(changes, Change{Field: "status", From: cur.Status, To: status})
+	}
+	for _, c := range changes {
+		_ = store.AppendAudit(ctx, caseID, "", "case", "update", "success", operator, "casemeta.Update", map[string]any{
+			"field": c.Field,
+			"from":  c.From,
+			"to":    c.To,
+		})
+	}
+
+	updated, err := store.GetCaseOverview(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = []Change{}
+	}
+	return &Result{Case: updated, Changes: changes}, nil
+}
+
+// cleanText 去掉首尾空白并检查长度与控制字符。
+func cleanText(field, v string, max int) (string, error) {
+	v = strings.TrimSpace(v)
+	if len([]rune(v)) > max {
+		return "", apperr.T(apperr.CodeInvalidArgument, "text.too_long", field, max)
+	}
+	if strings.IndexFunc(v, unicode.IsControl) >= 0 {
+		return "", apperr.T(apperr.CodeInvalidArgument, "text.control_chars", field)
+	}
+	return v, nil
+}
@@ -0,0 +1,139 @@
+package casemeta
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+
+	_ "modernc.org/sqlite"
+)
+
+func ptr(s string) *string { return &s }
+
+func TestUpdateCaseMeta(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	a, _ := store.EnsureCase(ctx, "", "NO-1", "Tittle typo", "alice", "")
+	b, _ := store.EnsureCase(ctx, "", "NO-2", "other", "alice", "")
+
+	for name, tc := range map[string]struct {
+		id   string
+		p    Patch
+		code apperr.Code
+	}{
+		"empty patch":     {a, Patch{}, apperr.CodeInvalidArgument},
+		"missing case":    {"case_missing", Patch{Title: ptr("x")}, apperr.CodeNotFound},
+		"blank title":     {a, Patch{Title: ptr("  ")}, apperr.CodeInvalidArgument},
+		"control chars":   {a, Patch{CaseNo: ptr("NO\n3")}, apperr.CodeInvalidArgument},
+		"duplicate no":    {a, Patch{CaseNo: ptr("NO-2")}, apperr.CodeConflict},
+		"bad status":      {a, Patch{Status: ptr("deleted")}, apperr.CodeInvalidArgument},
+		"skip to archive": {a, Patch{Status: ptr("archived")}, apperr.CodeConflict},
+	} {
+		if _, err := Update(ctx, store, tc.id, tc.p); apperr.CodeOf(err) != tc.code {
+			t.Fatalf("%s: err=%v want %s", name, err, tc.code)
+		}
+	}
+
+	res, err := Update(ctx, store, a, Patch{Title: ptr(" Title "), CaseNo: ptr("NO-3"), Status: ptr("closed"), Operator: "bob"})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if len(res.Changes) != 3 || res.Case.Title != "Title" || res.Case.CaseNo != "NO-3" || res.Case.Status != StatusClosed {
+		t.Fatalf("result=%+v case=%+v", res.Changes, res.Case)
+	}
+	// 相同值不产生变化与审计。
+	if res, err := Update(ctx, store, a, Patch{Title: ptr("Title"), Status: ptr("closed")}); err != nil || len(res.Changes) != 0 {
+		t.Fatalf("no-op: %+v err=%v", res, err)
+	}
+	if _, err := Update(ctx, store, a, Patch{Title: ptr("late"), Status: ptr("archived")}); apperr.CodeOf(err) != apperr.CodeConflict {
+		t.Fatalf("edit while archiving: err=%v", err)
+	}
+	if _, err := Update(ctx, store, a, Patch{Status: ptr("archived")}); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	for name, p := range map[string]Patch{
+		"reopen":        {Status: ptr("open")},
+		"edit archived": {CaseNo: ptr("")},
+	} {
+		if _, err := Update(ctx, store, a, p); apperr.CodeOf(err) != apperr.CodeConflict {
+			t.Fatalf("%s: err=%v", name, err)
+		}
+	}
+	// 清除编号后可被其他案件使用。
+	if res, err := Update(ctx, store, b, Patch{CaseNo: ptr("")}); err != nil || res.Case.CaseNo != "" {
+		t.Fatalf("clear case_no: %+v err=%v", res, err)
+	}
+
+	logs, err := store.ListAuditLogs(ctx, a, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates := 0
+	for _, l := range logs {
+		if l.EventType == "case" && l.Action == "update" {
+			updates++
+		}
+	}
+	if updates != 4 {
+		t.Fatalf("update audits=%d, want 4 (title, case_no, closed, archived)", updates)
+	}
+}
+
+func TestUpdateCaseMetaAllOrNothing(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	a, _ := store.EnsureCase(ctx, "", "NO-1", "title", "alice", "")
+
+	check := func(name string) {
+		t.Helper()
+		ov, err := store.GetCaseOverview(ctx, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ov.Status != StatusOpen || ov.Title != "title" || ov.CaseNo != "NO-1" {
+			t.Fatalf("%s: case partially updated: %+v", name, ov)
+		}
+	}
+
+	// 元数据写入失败（模拟写库错误）：状态不得被改为 closed。
+	if _, err := db.ExecContext(ctx, `CREATE TRIGGER trg_test_fail_meta BEFORE UPDATE OF title ON cases
+		BEGIN SELECT RAISE(ABORT, 'meta write failed'); END`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Update(ctx, store, a, Patch{Title: ptr("new title"), Status: ptr("closed")}); err == nil {
+		t.Fatalf("expected meta write error")
+	}
+	check("meta write failed")
+	if _, err := db.ExecContext(ctx, `DROP TRIGGER trg_test_fail_meta`); err != nil {
+		t.Fatal(err)
+	}
+
+	// 状态写入失败（external 结案清单未完成）：已写入的标题/编号需恢复。
+	_, err = Update(ctx, store, a, Patch{Title: ptr("new title"), CaseNo: ptr("NO-9"), Status: ptr("closed"), Profile: "external"})
+	if apperr.CodeOf(err) != apperr.CodeChecklistIncomplete {
+		t.Fatalf("close with pending checklist: err=%v", err)
+	}
+	check("close failed")
+}
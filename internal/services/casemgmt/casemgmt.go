@@ -0,0 +1,387 @@
+package casemgmt
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 案件管理系统对接
+//
+// 把案件摘要、命中与报告哈希推送到外部（公安/单位）案件管理系统，避免在局内系统里重复录入：
+// - 适配器（Adapter）负责协议，按名称注册；内置参考实现 rest（JSON over HTTP）
+// - 推送内容先组装成统一的源文档（case / hits / reports / trigger），再按配置的 mapping 改写成对方字段
+// - 触发方式：手动（CLI case push / POST /api/cases/{id}/case-management/push），
+//   或导出完成后自动推送（配置 push_on_export 列出的导出格式）
+// - 凭据只从环境变量读取（token_env），配置文件与审计日志中不出现明文
+// - 每次推送写审计日志（case_management/push），记录端点、对方返回的编号与载荷 sha256
+
+// Source 是映射前的源文档字段（mapping 的取值路径以此为根）。
+//
+// - case.*：案件摘要（case_id / case_no / title / status / owner / hit_count / report_count ...）
+// - hits：命中列表，每条按 hit_mapping 映射（hit_id / hit_type / rule_name / matched_value / confidence / verdict ...）
+// - reports：报告列表，每条按 report_mapping 映射（report_id / report_type / report_no / sha256 / generated_at）
+// - trigger.*：触发信息（kind=manual|export / export_kind / report_id / operator）
+// - pushed_at / generator
+type Source map[string]any
+
+// Receipt 是一次推送的结果。
+type Receipt struct {
+	Adapter  string `json:"adapter"`
+	Endpoint string `json:"endpoint"`
+	// StatusCode 为对方返回的状态码（非 HTTP 适配器可为 0）。
+	StatusCode int `json:"status_code"`
+	// ExternalID 为对方系统返回的记录编号（配置 response_id_field 时提取）。
+	ExternalID    string `json:"external_id,omitempty"`
+	PayloadSHA256 string `json:"payload_sha256"`
+	HitCount      int    `json:"hit_count"`
+	ReportCount   int    `json:"report_count"`
+	PushedAt      int64  `json:"pushed_at"`
+}
+
+// Adapter 是一种外部案件管理系统协议。
+type Adapter interface {
+	// Name 是配置中 adapter 字段使用的名称。
+	Name() string
+	// Push 发送已映射的载荷（JSON 字节）；返回的 Receipt 由调用方补全计数与哈希。
+	Push(ctx context.Context, cfg Config, caseRef CaseRef, payload []byte) (*Receipt, error)
+}
+
+// CaseRef 用于端点模板占位符（{case_id} / {case_no}）。
+type CaseRef struct {
+	CaseID string
+	CaseNo string
+}
+
+var (
+	mu       sync.RWMutex
+	adapters = map[string]Adapter{}
+)
+
+// Register 注册一种适配器；名称为空或重复注册时 panic（属于编程错误）。
+func Register(a Adapter) {
+	name := strings.TrimSpace(a.Name())
+	if name == "" {
+		panic("casemgmt: empty adapter name")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := adapters[name]; ok {
+		panic(fmt.Sprintf("casemgmt: duplicate adapter %q", name))
+	}
+	adapters[name] = a
+}
+
+// Lookup 按名称查找适配器。
+func Lookup(name string) (Adapter, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	a, ok := adapters[strings.TrimSpace(name)]
+	return a, ok
+}
+
+// Adapters 返回已注册的适配器名称（排序）。
+func Adapters() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]string, 0, len(adapters))
+	for name := range adapters {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Config 是对接配置（见 rules/case_management.template.yaml）。
+type Config struct {
+	Adapter  string `yaml:"adapter" json:"adapter"`
+	Endpoint string `yaml:"endpoint" json:"endpoint"`
+	Method   string `yaml:"method,omitempty" json:"method,omitempty"`
+	// TokenEnv 为保存访问令牌的环境变量名，令牌以 Authorization: Bearer 发送。
+	TokenEnv string            `yaml:"token_env,omitempty" json:"token_env,omitempty"`
+	Headers  map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Timeout  time.Duration     `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+
+	// PushOnExport 列出完成后自动推送的导出格式（空表示只手动推送）。
+	PushOnExport []string `yaml:"push_on_export,omitempty" json:"push_on_export,omitempty"`
+	// MaxHits 限制推送的命中条数（0 表示默认 1000）。
+	MaxHits int `yaml:"max_hits,omitempty" json:"max_hits,omitempty"`
+
+	// Mapping 为 对方字段 -> 源文档路径；对方字段可用点号表示嵌套对象。为空时原样发送源文档。
+	Mapping       map[string]string `yaml:"mapping,omitempty" json:"mapping,omitempty"`
+	HitMapping    map[string]string `yaml:"hit_mapping,omitempty" json:"hit_mapping,omitempty"`
+	ReportMapping map[string]string `yaml:"report_mapping,omitempty" json:"report_mapping,omitempty"`
+
+	// ResponseIDField 为对方响应 JSON 中记录编号的路径（点号分隔，可选）。
+	ResponseIDField string `yaml:"response_id_field,omitempty" json:"response_id_field,omitempty"`
+}
+
+// DefaultMaxHits 是未配置 max_hits 时推送的命中上限。
+const DefaultMaxHits = 1000
+
+// LoadConfig 读取并校验对接配置；path 为空时返回 nil（未启用）。
+func LoadConfig(path string) (*Config, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read case management config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parse case management config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate 校验配置完整性。
+func (c *Config) Validate() error {
+	c.Adapter = strings.TrimSpace(c.Adapter)
+	if c.Adapter == "" {
+		c.Adapter = "rest"
+	}
+	if _, ok := Lookup(c.Adapter); !ok {
+		return apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unknown case management adapter %q (available: %s)", c.Adapter, strings.Join(Adapters(), ", ")))
+	}
+	if strings.TrimSpace(c.Endpoint) == "" {
+		return apperr.New(apperr.CodeInvalidArgument, "case management endpoint is required")
+	}
+	if c.MaxHits < 0 {
+		return apperr.New(apperr.CodeInvalidArgument, "max_hits must be >= 0")
+	}
+	for target := range c.Mapping {
+		if strings.TrimSpace(target) == "" {
+			return apperr.New(apperr.CodeInvalidArgument, "mapping contains an empty target field")
+		}
+	}
+	return nil
+}
+
+// PushOnExportKind 判断某导出格式完成后是否自动推送。
+func (c *Config) PushOnExportKind(kind string) bool {
+	if c == nil {
+		return false
+	}
+	for _, k := range c.PushOnExport {
+		if k = strings.TrimSpace(k); k == kind || k == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Trigger 描述推送的触发来源。
+type Trigger struct {
+	Kind       string `json:"kind"` // manual|export
+	ExportKind string `json:"export_kind,omitempty"`
+	ReportID   string `json:"report_id,omitempty"`
+	Operator   string `json:"operator,omitempty"`
+}
+
+// BuildSource 组装案件的源文档。
+func BuildSource(ctx context.Context, store *sqliteadapter.Store, caseID string, maxHits int, trig Trigger) (Source, CaseRef, error) {
+	overview, err := store.GetCaseOverview(ctx, caseID)
+	if err != nil {
+		return nil, CaseRef{}, err
+	}
+	if overview == nil {
+		return nil, CaseRef{}, apperr.T(apperr.CodeNotFound, "case.not_found", caseID)
+	}
+	hits, err := store.ListCaseHitDetails(ctx, caseID, "")
+	if err != nil {
+		return nil, CaseRef{}, err
+	}
+	if maxHits <= 0 {
+		maxHits = DefaultMaxHits
+	}
+	truncated := len(hits) > maxHits
+	if truncated {
+		hits = hits[:maxHits]
+	}
+	for i := range hits {
+		hits[i].DetailJSON = ""
+	}
+	reports, err := store.ListReportsByCase(ctx, caseID)
+	if err != nil {
+		return nil, CaseRef{}, err
+	}
+
+	src := Source{
+		"case":           toGeneric(overview),
+		"hits":           toGeneric(hits),
+		"reports":        toGeneric(reports),
+		"hits_truncated": truncated,
+		"trigger":        toGeneric(trig),
+		"pushed_at":      time.Now().Unix(),
+		"generator":      "crypto-inspector",
+	}
+	return src, CaseRef{CaseID: overview.CaseID, CaseNo: overview.CaseNo}, nil
+}
+
+// Apply 按配置映射源文档；未配置 mapping 时原样返回。
+func Apply(cfg Config, src Source) map[string]any {
+	if len(cfg.Mapping) == 0 {
+		return src
+	}
+	out := map[string]any{}
+	for target, path := range cfg.Mapping {
+		v, ok := lookupPath(map[string]any(src), path)
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(path) {
+		case "hits":
+			v = mapList(v, cfg.HitMapping)
+		case "reports":
+			v = mapList(v, cfg.ReportMapping)
+		}
+		setPath(out, target, v)
+	}
+	return out
+}
+
+// Push 组装、映射并推送案件数据，结果写入审计日志。
+func Push(ctx context.Context, store *sqliteadapter.Store, cfg *Config, caseID string, trig Trigger) (*Receipt, error) {
+	if cfg == nil {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "case management integration is not configured")
+	}
+	a, ok := Lookup(cfg.Adapter)
+	if !ok {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unknown case management adapter %q", cfg.Adapter))
+	}
+	if strings.TrimSpace(trig.Operator) == "" {
+		trig.Operator = "system"
+	}
+	if trig.Kind == "" {
+		trig.Kind = "manual"
+	}
+
+	src, ref, err := BuildSource(ctx, store, caseID, cfg.MaxHits, trig)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(Apply(*cfg, src))
+	if err != nil {
+		return nil, fmt.Errorf("marshal case management payload: %w", err)
+	}
+	sum := sha256.Sum256(payload)
+	payloadSHA := hex.EncodeToString(sum[:])
+
+	detail := map[string]any{
+		"adapter":        cfg.Adapter,
+		"trigger":        trig.Kind,
+		"export_kind":    trig.ExportKind,
+		"report_id":      trig.ReportID,
+		"payload_sha256": payloadSHA,
+	}
+	rec, err := a.Push(ctx, *cfg, ref, payload)
+	if err != nil {
+		detail["error"] = err.Error()
+		_ = store.AppendAudit(ctx, caseID, "", "case_management", "push", "failed", trig.Operator, "casemgmt.Push", detail)
+		return nil, err
+	}
+	rec.Adapter = cfg.Adapter
+	rec.PayloadSHA256 = payloadSHA
+	rec.PushedAt = time.Now().Unix()
+	if hs, ok := src["hits"].([]any); ok {
+		rec.HitCount = len(hs)
+	}
+	if rs, ok := src["reports"].([]any); ok {
+		rec.ReportCount = len(rs)
+	}
+	detail["endpoint"] = rec.Endpoint
+	detail["status_code"] = rec.StatusCode
+	detail["external_id"] = rec.ExternalID
+	detail["hit_count"] = rec.HitCount
+	detail["report_count"] = rec.ReportCount
+	if err := store.AppendAudit(ctx, caseID, "", "case_management", "push", "success", trig.Operator, "casemgmt.Push", detail); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// toGeneric 把结构体按 JSON 标签转换为 map/slice，供路径取值。
+func toGeneric(v any) any {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+func mapList(v any, mapping map[string]string) any {
+	items, ok := v.([]any)
+	if !ok || len(mapping) == 0 {
+		return v
+	}
+	out := make([]any, 0, len(items))
+	for _, it := range items {
+		m, ok := it.(map[string]any)
+		if !ok {
+			continue
+		}
+		row := map[string]any{}
+		for target, path := range mapping {
+			if val, ok := lookupPath(m, path); ok {
+				setPath(row, target, val)
+			}
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+// lookupPath 按点号路径取值（只支持对象字段）。
+func lookupPath(root map[string]any, path string) (any, bool) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, false
+	}
+	var cur any = root
+	for _, seg := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[seg]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// setPath 按点号路径写值，中间层级自动创建对象。
+func setPath(root map[string]any, path string, v any) {
+	segs := strings.Split(strings.TrimSpace(path), ".")
+	cur := root
+	for _, seg := range segs[:len(segs)-1] {
+		next, ok := cur[seg].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			cur[seg] = next
+		}
+		cur = next
+	}
+	cur[segs[len(segs)-1]] = v
+}
@@ -0,0 +1,105 @@
+package casemgmt
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/manualhit"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestPushMapsCaseToRESTEndpoint(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "JZ-2024-001", "wallet case", "op", "")
+	if err != nil {
+		t.Fatalf("EnsureCase: %v", err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "host", OS: model.OSWindows, Identifier: "h"}, true, ""); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+	if _, err := manualhit.Create(ctx, store, manualhit.Input{
+		CaseID: caseID, Value: "0xabc", Justification: "note", Operator: "alice",
+		AttachmentName: "a.txt", Attachment: []byte("x"), EvidenceRoot: filepath.Join(dir, "evidence"),
+	}); err != nil {
+		t.Fatalf("manualhit.Create: %v", err)
+	}
+
+	var gotPath, gotAuth string
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &got)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"data":{"id":20240001}}`))
+	}))
+	defer srv.Close()
+
+	t.Setenv("CMS_TEST_TOKEN", "secret")
+	cfg := &Config{
+		Endpoint:        srv.URL + "/cases/{case_no}/intake",
+		TokenEnv:        "CMS_TEST_TOKEN",
+		PushOnExport:    []string{"forensic-pdf"},
+		Mapping:         map[string]string{"caseNumber": "case.case_no", "summary.hitCount": "case.hit_count", "clues": "hits", "trigger": "trigger.kind"},
+		HitMapping:      map[string]string{"value": "matched_value", "kind": "hit_type"},
+		ResponseIDField: "data.id",
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if !cfg.PushOnExportKind("forensic-pdf") || cfg.PushOnExportKind("forensic-zip") {
+		t.Fatalf("push_on_export=%v", cfg.PushOnExport)
+	}
+
+	rec, err := Push(ctx, store, cfg, caseID, Trigger{Kind: "export", ExportKind: "forensic-pdf", Operator: "alice"})
+	if err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if gotPath != "/cases/JZ-2024-001/intake" || gotAuth != "Bearer secret" {
+		t.Fatalf("path=%q auth=%q", gotPath, gotAuth)
+	}
+	if got["caseNumber"] != "JZ-2024-001" || got["trigger"] != "export" {
+		t.Fatalf("payload=%v", got)
+	}
+	if summary, _ := got["summary"].(map[string]any); summary["hitCount"] != float64(1) {
+		t.Fatalf("summary=%v", got["summary"])
+	}
+	clues, _ := got["clues"].([]any)
+	if len(clues) != 1 || clues[0].(map[string]any)["value"] != "0xabc" {
+		t.Fatalf("clues=%v", got["clues"])
+	}
+	if rec.ExternalID != "20240001" || rec.StatusCode != http.StatusCreated || rec.HitCount != 1 || rec.PayloadSHA256 == "" {
+		t.Fatalf("receipt=%+v", rec)
+	}
+
+	// 对方返回错误时记为上游不可用。
+	cfg.Endpoint = srv.URL + "/missing"
+	if _, err := Push(ctx, store, cfg, caseID, Trigger{}); apperr.CodeOf(err) != apperr.CodeUpstreamUnavailable {
+		t.Fatalf("err=%v", err)
+	}
+}
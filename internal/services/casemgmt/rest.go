@@ -0,0 +1,95 @@
+package casemgmt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/apperr"
+)
+
+// restAdapter 是参考实现：把映射后的 JSON 以 POST/PUT 发送到 REST 端点。
+//
+// 端点中的 {case_id} / {case_no} 会替换为 URL 转义后的案件字段；2xx 视为成功，
+// 其他状态码返回 ERR_UPSTREAM_UNAVAILABLE 并附带响应前 512 字节便于排查映射问题。
+type restAdapter struct{}
+
+// DefaultTimeout 是未配置 timeout 时的请求超时。
+const DefaultTimeout = 15 * time.Second
+
+func init() { Register(restAdapter{}) }
+
+func (restAdapter) Name() string { return "rest" }
+
+func (restAdapter) Push(ctx context.Context, cfg Config, ref CaseRef, payload []byte) (*Receipt, error) {
+	endpoint := strings.NewReplacer(
+		"{case_id}", url.PathEscape(ref.CaseID),
+		"{case_no}", url.PathEscape(ref.CaseNo),
+	).Replace(strings.TrimSpace(cfg.Endpoint))
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid case management endpoint: %s", cfg.Endpoint))
+	}
+	method := strings.ToUpper(strings.TrimSpace(cfg.Method))
+	if method == "" {
+		method = http.MethodPost
+	}
+	if method != http.MethodPost && method != http.MethodPut && method != http.MethodPatch {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unsupported method %q (want POST|PUT|PATCH)", cfg.Method))
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("build case management request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if env := strings.TrimSpace(cfg.TokenEnv); env != "" {
+		token := strings.TrimSpace(os.Getenv(env))
+		if token == "" {
+			return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("case management token env %s is empty", env))
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeUpstreamUnavailable, err, "case management request failed")
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet := strings.TrimSpace(string(body))
+		if len(snippet) > 512 {
+			snippet = snippet[:512]
+		}
+		return nil, apperr.New(apperr.CodeUpstreamUnavailable, fmt.Sprintf("case management endpoint returned %d: %s", resp.StatusCode, snippet))
+	}
+
+	rec := &Receipt{Endpoint: u.Scheme + "://" + u.Host + u.Path, StatusCode: resp.StatusCode}
+	if field := strings.TrimSpace(cfg.ResponseIDField); field != "" && len(body) > 0 {
+		var parsed map[string]any
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber() // 数字编号保持原样，避免浮点格式化
+		if dec.Decode(&parsed) == nil {
+			if v, ok := lookupPath(parsed, field); ok && v != nil {
+				rec.ExternalID = fmt.Sprint(v)
+			}
+		}
+	}
+	return rec, nil
+}
@@ -0,0 +1,119 @@
+package casestorage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/i18n"
+)
+
+// 案件存储统计与配额
+//
+// - 证据字节取 artifacts.size_bytes（入库时的快照存储字节），报告字节按报告文件实际大小统计
+// - 配额按 evidence_bytes + report_bytes 计算：案件级配置优先，否则使用全局默认（0 表示不限制）
+// - 新扫描开始前检查：达到 NearLimitRatio 记警告；已超额时 warn 策略只警告，block 策略拒绝扫描
+// 扫描产生的数据量无法事先知道，因此“会超额”以“当前已达到/超过配额”判断。
+
+// NearLimitRatio 是“接近配额”的告警阈值。
+const NearLimitRatio = 0.9
+
+// 配额状态。
+const (
+	StatusUnlimited = "unlimited"
+	StatusOK        = "ok"
+	StatusNearLimit = "near_limit"
+	StatusExceeded  = "exceeded"
+)
+
+// Usage 统计案件存储占用并给出配额状态；案件不存在时返回 CodeNotFound。
+func Usage(ctx context.Context, store *sqliteadapter.Store, caseID string) (*model.CaseStorageUsage, error) {
+	usage, err := store.GetCaseStorageCounts(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if usage == nil {
+		return nil, apperr.T(apperr.CodeNotFound, "case.not_found", caseID)
+	}
+	reports, err := store.ListReportsByCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reports {
+		st, err := os.Stat(r.FilePath)
+		if err != nil {
+			usage.MissingReports++
+			continue
+		}
+		usage.ReportBytes += st.Size()
+	}
+	usage.TotalBytes = usage.EvidenceBytes + usage.ReportBytes
+
+	quota, err := store.GetCaseQuota(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	usage.Quota = evaluate(quota, usage.TotalBytes)
+	return usage, nil
+}
+
+func evaluate(q model.CaseStorageQuota, total int64) model.CaseStorageQuota {
+	if q.LimitBytes <= 0 {
+		q.Status = StatusUnlimited
+		return q
+	}
+	q.UsedRatio = float64(total) / float64(q.LimitBytes)
+	switch {
+	case total >= q.LimitBytes:
+		q.Status = StatusExceeded
+	case q.UsedRatio >= NearLimitRatio:
+		q.Status = StatusNearLimit
+	default:
+		q.Status = StatusOK
+	}
+	return q
+}
+
+// Precheck 在新扫描开始前检查案件配额，返回可直接落库的前置检查结果。
+//
+// 已超额且策略为 block 时返回 CodeQuotaExceeded 错误（调用方应中止扫描）；
+// 其余情况 err 为 nil，warning 非空时调用方应写入扫描告警。
+func Precheck(ctx context.Context, store *sqliteadapter.Store, caseID, scope string) (check model.PrecheckResult, warning string, err error) {
+	check = model.PrecheckResult{
+		CaseID:    caseID,
+		ScanScope: scope,
+		CheckCode: "case_storage_quota",
+		CheckName: "案件存储未超过配额",
+		Status:    model.PrecheckPassed,
+		CheckedAt: time.Now().Unix(),
+	}
+	usage, err := Usage(ctx, store, caseID)
+	if err != nil {
+		check.Status, check.Message, check.DetailJSON = model.PrecheckSkipped, err.Error(), json.RawMessage("{}")
+		return check, "", nil
+	}
+	q := usage.Quota
+	check.Required = q.Mode == sqliteadapter.CaseQuotaModeBlock
+	check.Message = q.Status
+	check.DetailJSON, _ = json.Marshal(map[string]any{
+		"total_bytes": usage.TotalBytes,
+		"limit_bytes": q.LimitBytes,
+		"mode":        q.Mode,
+		"source":      q.Source,
+	})
+	switch q.Status {
+	case StatusNearLimit:
+		warning = i18n.English("storage.near_quota", usage.TotalBytes, q.LimitBytes)
+	case StatusExceeded:
+		warning = i18n.English("storage.over_quota", usage.TotalBytes, q.LimitBytes)
+		if q.Mode == sqliteadapter.CaseQuotaModeBlock {
+			check.Status = model.PrecheckFailed
+			return check, warning, apperr.T(apperr.CodeQuotaExceeded, "storage.over_quota", usage.TotalBytes, q.LimitBytes)
+		}
+	}
+	return check, warning, nil
+}
@@ -0,0 +1,101 @@
+package casestorage
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestUsageAndQuotaPrecheck(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev := model.Device{ID: "dev_1", Name: "d", OS: model.OSWindows, Identifier: "id-1"}
+	if err := store.UpsertDevice(ctx, caseID, dev, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	snap := filepath.Join(dir, "apps.json")
+	if err := os.WriteFile(snap, []byte(`[]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveArtifacts(ctx, []model.Artifact{{
+		ID: "art_1", CaseID: caseID, DeviceID: dev.ID, Type: model.ArtifactInstalledApps,
+		SourceRef: "apps", SnapshotPath: snap, SHA256: strings.Repeat("a", 64), SizeBytes: 700,
+		CollectedAt: time.Now().Unix(), CollectorName: "test", CollectorVersion: "1", ParserVersion: "1",
+		AcquisitionMethod: "test", PayloadJSON: []byte(`[]`), RecordHash: strings.Repeat("0", 64),
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	report := filepath.Join(dir, "report.html")
+	if err := os.WriteFile(report, make([]byte, 200), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.SaveReport(ctx, caseID, "internal_html", report, strings.Repeat("b", 64), "test", "ready"); err != nil {
+		t.Fatal(err)
+	}
+
+	usage, err := Usage(ctx, store, caseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.EvidenceBytes != 700 || usage.ReportBytes != 200 || usage.TotalBytes != 900 ||
+		usage.DBRows["artifacts"] != 1 || usage.DBRows["case_devices"] != 1 || usage.Quota.Status != StatusUnlimited {
+		t.Fatalf("usage=%+v", usage)
+	}
+	if _, err := Usage(ctx, store, "case_missing"); apperr.CodeOf(err) != apperr.CodeNotFound {
+		t.Fatalf("missing case err=%v", err)
+	}
+
+	// 默认配额 warn：接近上限只告警。
+	if err := store.SetDefaultCaseQuota(ctx, 1000, sqliteadapter.CaseQuotaModeWarn); err != nil {
+		t.Fatal(err)
+	}
+	check, warning, err := Precheck(ctx, store, caseID, "general")
+	if err != nil || check.Status != model.PrecheckPassed || check.Message != StatusNearLimit || warning == "" {
+		t.Fatalf("near limit check=%+v warning=%q err=%v", check, warning, err)
+	}
+
+	// 案件级配额优先；block 策略下超额拒绝扫描。
+	if err := store.SetDefaultCaseQuota(ctx, 1000, sqliteadapter.CaseQuotaModeBlock); err != nil {
+		t.Fatal(err)
+	}
+	limit := int64(500)
+	if ok, err := store.SetCaseQuota(ctx, caseID, &limit); err != nil || !ok {
+		t.Fatalf("set case quota ok=%v err=%v", ok, err)
+	}
+	check, _, err = Precheck(ctx, store, caseID, "general")
+	if apperr.CodeOf(err) != apperr.CodeQuotaExceeded || check.Status != model.PrecheckFailed || !check.Required {
+		t.Fatalf("exceeded check=%+v err=%v", check, err)
+	}
+
+	// 清除案件级配额后回退到默认（1000 字节，未超额）。
+	if _, err := store.SetCaseQuota(ctx, caseID, nil); err != nil {
+		t.Fatal(err)
+	}
+	if usage, _ := Usage(ctx, store, caseID); usage.Quota.Source != "default" || usage.Quota.Status != StatusNearLimit {
+		t.Fatalf("quota after clear=%+v", usage.Quota)
+	}
+}
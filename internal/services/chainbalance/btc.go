@@ -25,6 +25,11 @@ type BTCProvider struct {
 	BaseURL string
 	Symbol  string
 
+	// FallbackBaseURLs / Retry / Breaker 语义同 EVMProvider（备用 API 需兼容 Blockstream 格式）。
+	FallbackBaseURLs []string
+	Retry            *RetryPolicy
+	Breaker          *CircuitBreaker
+
 	HTTPClient *http.Client
 }
 
@@ -32,7 +37,20 @@ func NewBTCProvider(baseURL string) *BTCProvider {
 	return &BTCProvider{BaseURL: strings.TrimSpace(baseURL)}
 }
 
+// QueryBalances 查询全部地址；任意地址最终失败则返回错误。
 func (p *BTCProvider) QueryBalances(ctx context.Context, addresses []string) (map[string]map[string]string, error) {
+	res, err := p.QueryBalancesPartial(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Err(); err != nil {
+		return nil, err
+	}
+	return res.Balances, nil
+}
+
+// QueryBalancesPartial 查询全部地址并返回部分结果（失败地址进入 Errors）。
+func (p *BTCProvider) QueryBalancesPartial(ctx context.Context, addresses []string) (*PartialResult, error) {
 	base := strings.TrimSpace(p.BaseURL)
 	if base == "" {
 		base = DefaultPublicBTCAPI
@@ -47,22 +65,22 @@ func (p *BTCProvider) QueryBalances(ctx context.Context, addresses []string) (ma
 		c = &http.Client{Timeout: 12 * time.Second}
 	}
 
-	out := make(map[string]map[string]string, len(addresses))
-	for _, addr := range addresses {
-		addr = strings.TrimSpace(addr)
-		if addr == "" {
-			continue
-		}
-		sat, err := btcGetBalanceSats(ctx, c, base, addr)
-		if err != nil {
-			return nil, fmt.Errorf("query %s: %w", addr, err)
-		}
-		out[addr] = map[string]string{
-			"SAT":  sat.String(),
-			symbol: formatUnits(sat, 8),
-		}
+	q := resilientQuery{
+		Endpoints: buildEndpoints(base, p.FallbackBaseURLs),
+		Retry:     retryPolicyOrDefault(p.Retry),
+		Breaker:   p.Breaker,
+		QueryOne: func(ctx context.Context, endpoint, addr string) (map[string]string, error) {
+			sat, err := btcGetBalanceSats(ctx, c, endpoint, addr)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]string{
+				"SAT":  sat.String(),
+				symbol: formatUnits(sat, 8),
+			}, nil
+		},
 	}
-	return out, nil
+	return q.run(ctx, addresses)
 }
 
 type blockstreamAddressResp struct {
@@ -92,7 +110,7 @@ func btcGetBalanceSats(ctx context.Context, c *http.Client, baseURL, address str
 		return nil, err
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+		return nil, &httpStatusError{Prefix: "http", StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(b))}
 	}
 
 	var out blockstreamAddressResp
@@ -104,3 +122,112 @@ func btcGetBalanceSats(ctx context.Context, c *http.Client, baseURL, address str
 		out.MempoolStats.FundedTxoSum - out.MempoolStats.SpentTxoSum
 	return big.NewInt(total), nil
 }
+
+// QueryHistory 查询地址最近的交易（Blockstream /address/{addr}/txs：最多 50 笔未确认 + 最近 25 笔已确认）。
+// 返回的 Delta 为该地址在每笔交易中的净变化（satoshi，转出为负）。
+func (p *BTCProvider) QueryHistory(ctx context.Context, address string) ([]Transfer, string, error) {
+	base := strings.TrimSpace(p.BaseURL)
+	if base == "" {
+		base = DefaultPublicBTCAPI
+	}
+	c := p.HTTPClient
+	if c == nil {
+		c = &http.Client{Timeout: 12 * time.Second}
+	}
+
+	var transfers []Transfer
+	q := resilientQuery{
+		Endpoints: buildEndpoints(base, p.FallbackBaseURLs),
+		Retry:     retryPolicyOrDefault(p.Retry),
+		Breaker:   p.Breaker,
+		QueryOne: func(ctx context.Context, endpoint, addr string) (map[string]string, error) {
+			t, err := btcGetTransfers(ctx, c, endpoint, addr)
+			if err != nil {
+				return nil, err
+			}
+			transfers = t
+			return map[string]string{}, nil
+		},
+	}
+	_, endpoint, aerr := q.queryAddress(ctx, strings.TrimSpace(address))
+	if aerr != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, "", err
+		}
+		return nil, "", fmt.Errorf("query %s: %s", address, aerr.Error)
+	}
+	return transfers, endpoint, nil
+}
+
+type blockstreamTx struct {
+	TxID   string `json:"txid"`
+	Fee    int64  `json:"fee"`
+	Status struct {
+		Confirmed   bool  `json:"confirmed"`
+		BlockHeight int64 `json:"block_height"`
+		BlockTime   int64 `json:"block_time"`
+	} `json:"status"`
+	Vin []struct {
+		Prevout *struct {
+			Address string `json:"scriptpubkey_address"`
+			Value   int64  `json:"value"`
+		} `json:"prevout"`
+	} `json:"vin"`
+	Vout []struct {
+		Address string `json:"scriptpubkey_address"`
+		Value   int64  `json:"value"`
+	} `json:"vout"`
+}
+
+func btcGetTransfers(ctx context.Context, c *http.Client, baseURL, address string) ([]Transfer, error) {
+	u := strings.TrimRight(baseURL, "/") + "/address/" + address + "/txs"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 8<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &httpStatusError{Prefix: "http", StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(b))}
+	}
+
+	var txs []blockstreamTx
+	if err := json.Unmarshal(b, &txs); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+	out := make([]Transfer, 0, len(txs))
+	for _, tx := range txs {
+		var delta int64
+		for _, in := range tx.Vin {
+			if in.Prevout != nil && in.Prevout.Address == address {
+				delta -= in.Prevout.Value
+			}
+		}
+		for _, o := range tx.Vout {
+			if o.Address == address {
+				delta += o.Value
+			}
+		}
+		amount := formatUnits(new(big.Int).Abs(big.NewInt(delta)), 8)
+		if delta < 0 {
+			amount = "-" + amount
+		}
+		out = append(out, Transfer{
+			TxID:        tx.TxID,
+			BlockHeight: tx.Status.BlockHeight,
+			BlockTime:   tx.Status.BlockTime,
+			Confirmed:   tx.Status.Confirmed,
+			Delta:       fmt.Sprintf("%d", delta),
+			Amount:      amount,
+			Fee:         tx.Fee,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,313 @@
+package chainbalance
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/platform/hash"
+)
+
+// 链上域名解析（ENS .eth / SPACE ID .bnb）
+//
+// 两者都采用 ENS 的 registry + resolver 结构：
+//  1. node = namehash(name)
+//  2. resolver = registry.resolver(node)
+//  3. address  = resolver.addr(node)
+//
+// 为了让结果可复核，所有 eth_call 都固定在同一个区块高度上执行，
+// 并记录该区块号与区块时间戳（不同时间解析同一域名可能得到不同地址）。
+
+// 默认注册表合约地址。
+const (
+	// ENSRegistryAddress 是以太坊主网 ENS Registry（with fallback）。
+	ENSRegistryAddress = "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"
+	// SpaceIDBNBRegistryAddress 是 BNB Chain 上 SPACE ID（.bnb）的 Registry。
+	SpaceIDBNBRegistryAddress = "0x08CEd32a7f3eeC915Ba84415e9C07a7286977956"
+)
+
+// 函数选择器：resolver(bytes32) / addr(bytes32)。
+const (
+	selectorResolver = "0x0178b8bf"
+	selectorAddr     = "0x3b3b57de"
+)
+
+// 域名服务标识。
+const (
+	NameServiceENS     = "ens"
+	NameServiceSpaceID = "space_id"
+)
+
+// 解析状态。
+const (
+	NameStatusResolved   = "resolved"   // 解析到非零地址
+	NameStatusUnresolved = "unresolved" // 未注册 / 未设置 resolver / addr 为空
+)
+
+// NameServiceFor 根据域名后缀返回域名服务与默认注册表地址。
+func NameServiceFor(name string) (service, registry string, ok bool) {
+	n := strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case strings.HasSuffix(n, ".eth"):
+		return NameServiceENS, ENSRegistryAddress, true
+	case strings.HasSuffix(n, ".bnb"):
+		return NameServiceSpaceID, SpaceIDBNBRegistryAddress, true
+	default:
+		return "", "", false
+	}
+}
+
+// Namehash 计算 EIP-137 namehash（输入需已小写规范化）。
+func Namehash(name string) [32]byte {
+	var node [32]byte
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		label := hash.Keccak256([]byte(labels[i]))
+		copy(node[:], hash.Keccak256(node[:], label))
+	}
+	return node
+}
+
+// NameResolution 是一次域名解析的结果。
+type NameResolution struct {
+	Name           string `json:"name"`
+	Service        string `json:"service"`
+	Namehash       string `json:"namehash"`
+	Registry       string `json:"registry"`
+	Resolver       string `json:"resolver,omitempty"`
+	Address        string `json:"address,omitempty"`
+	Status         string `json:"status"`
+	BlockNumber    int64  `json:"block_number"`
+	BlockTimestamp int64  `json:"block_timestamp"`
+	RPCURL         string `json:"rpc_url"`
+}
+
+// NameResolver 通过 EVM JSON-RPC 解析 ENS 风格域名。
+type NameResolver struct {
+	RPCURL string
+	// Registry 为注册表合约地址（为空时按域名后缀选择默认注册表）。
+	Registry string
+
+	FallbackRPCURLs []string
+	Retry           *RetryPolicy
+	Breaker         *CircuitBreaker
+
+	HTTPClient *http.Client
+}
+
+func NewNameResolver(rpcURL string) *NameResolver {
+	return &NameResolver{RPCURL: strings.TrimSpace(rpcURL)}
+}
+
+// ResolvePartial 逐个解析域名：成功（含“未注册”）进入 resolutions，
+// 重试/切换备用节点后仍失败的域名进入 errors。
+func (r *NameResolver) ResolvePartial(ctx context.Context, names []string) (map[string]NameResolution, map[string]AddressError, error) {
+	rpcURL := strings.TrimSpace(r.RPCURL)
+	if rpcURL == "" {
+		return nil, nil, fmt.Errorf("rpc_url is required")
+	}
+	c := r.HTTPClient
+	if c == nil {
+		c = &http.Client{Timeout: 12 * time.Second}
+	}
+
+	q := resilientQuery{
+		Endpoints: buildEndpoints(rpcURL, r.FallbackRPCURLs),
+		Retry:     retryPolicyOrDefault(r.Retry),
+		Breaker:   r.Breaker,
+		QueryOne: func(ctx context.Context, endpoint, name string) (map[string]string, error) {
+			res, err := r.resolveOne(ctx, c, endpoint, name)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]string{
+				"service":         res.Service,
+				"namehash":        res.Namehash,
+				"registry":        res.Registry,
+				"resolver":        res.Resolver,
+				"address":         res.Address,
+				"status":          res.Status,
+				"block_number":    strconv.FormatInt(res.BlockNumber, 10),
+				"block_timestamp": strconv.FormatInt(res.BlockTimestamp, 10),
+			}, nil
+		},
+	}
+	normalized := make([]string, 0, len(names))
+	for _, n := range names {
+		normalized = append(normalized, strings.ToLower(strings.TrimSpace(n)))
+	}
+	part, err := q.run(ctx, normalized)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := make(map[string]NameResolution, len(part.Balances))
+	for name, m := range part.Balances {
+		bn, _ := strconv.ParseInt(m["block_number"], 10, 64)
+		ts, _ := strconv.ParseInt(m["block_timestamp"], 10, 64)
+		out[name] = NameResolution{
+			Name:           name,
+			Service:        m["service"],
+			Namehash:       m["namehash"],
+			Registry:       m["registry"],
+			Resolver:       m["resolver"],
+			Address:        m["address"],
+			Status:         m["status"],
+			BlockNumber:    bn,
+			BlockTimestamp: ts,
+			RPCURL:         part.Endpoints[name],
+		}
+	}
+	return out, part.Errors, nil
+}
+
+func (r *NameResolver) resolveOne(ctx context.Context, c *http.Client, rpcURL, name string) (*NameResolution, error) {
+	service, registry, ok := NameServiceFor(name)
+	if !ok {
+		return nil, permanent(fmt.Errorf("unsupported name suffix: %s", name))
+	}
+	if strings.TrimSpace(r.Registry) != "" {
+		registry = strings.TrimSpace(r.Registry)
+	}
+	node := Namehash(name)
+	nodeHex := hex.EncodeToString(node[:])
+	res := &NameResolution{
+		Name:     name,
+		Service:  service,
+		Namehash: "0x" + nodeHex,
+		Registry: registry,
+		RPCURL:   rpcURL,
+		Status:   NameStatusUnresolved,
+	}
+
+	// 固定区块：后续 eth_call 都在该高度执行。
+	var blockHex string
+	if err := evmRPC(ctx, c, rpcURL, "eth_blockNumber", []any{}, &blockHex); err != nil {
+		return nil, err
+	}
+	bn, err := parseHexInt64(blockHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block number: %w", err)
+	}
+	var block struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := evmRPC(ctx, c, rpcURL, "eth_getBlockByNumber", []any{blockHex, false}, &block); err != nil {
+		return nil, err
+	}
+	ts, err := parseHexInt64(block.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block timestamp: %w", err)
+	}
+	res.BlockNumber, res.BlockTimestamp = bn, ts
+
+	resolver, err := evmCallAddress(ctx, c, rpcURL, registry, selectorResolver+nodeHex, blockHex)
+	if err != nil {
+		return nil, err
+	}
+	if resolver == "" {
+		return res, nil
+	}
+	res.Resolver = resolver
+
+	addr, err := evmCallAddress(ctx, c, rpcURL, resolver, selectorAddr+nodeHex, blockHex)
+	if err != nil {
+		// resolver 未实现 addr(bytes32) 时节点返回 revert：视为未解析而不是端点故障。
+		if isRevert(err) {
+			return res, nil
+		}
+		return nil, err
+	}
+	if addr != "" {
+		res.Address = addr
+		res.Status = NameStatusResolved
+	}
+	return res, nil
+}
+
+// evmCallAddress 执行 eth_call 并把返回的 32 字节解码为地址（全零返回空串）。
+func evmCallAddress(ctx context.Context, c *http.Client, rpcURL, to, data, block string) (string, error) {
+	var out string
+	params := []any{map[string]any{"to": to, "data": data}, block}
+	if err := evmRPC(ctx, c, rpcURL, "eth_call", params, &out); err != nil {
+		return "", err
+	}
+	h := strings.TrimPrefix(strings.TrimSpace(out), "0x")
+	if h == "" {
+		return "", nil
+	}
+	if len(h) < 64 {
+		return "", fmt.Errorf("invalid eth_call result: %s", out)
+	}
+	addr := strings.ToLower(h[24:64])
+	if strings.Trim(addr, "0") == "" {
+		return "", nil
+	}
+	return "0x" + addr, nil
+}
+
+// evmRPC 发起一次 JSON-RPC 调用并把 result 解码到 out。
+func evmRPC(ctx context.Context, c *http.Client, rpcURL, method string, params []any, out any) error {
+	raw, _ := json.Marshal(evmRPCReq{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rpcURL, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &httpStatusError{Prefix: "rpc http", StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(b))}
+	}
+
+	var env struct {
+		Result json.RawMessage `json:"result"`
+		Error  *evmRPCError    `json:"error,omitempty"`
+	}
+	if err := json.Unmarshal(b, &env); err != nil {
+		return fmt.Errorf("decode rpc json: %w", err)
+	}
+	if env.Error != nil {
+		return &rpcCallError{Code: env.Error.Code, Message: env.Error.Message}
+	}
+	if len(env.Result) == 0 || string(env.Result) == "null" {
+		return fmt.Errorf("empty result for %s", method)
+	}
+	if err := json.Unmarshal(env.Result, out); err != nil {
+		return fmt.Errorf("decode %s result: %w", method, err)
+	}
+	return nil
+}
+
+func parseHexInt64(s string) (int64, error) {
+	h := strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	if h == "" {
+		return 0, fmt.Errorf("empty hex")
+	}
+	n, ok := new(big.Int).SetString(h, 16)
+	if !ok || !n.IsInt64() {
+		return 0, fmt.Errorf("invalid hex: %s", s)
+	}
+	return n.Int64(), nil
+}
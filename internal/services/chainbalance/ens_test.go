@@ -0,0 +1,97 @@
+package chainbalance
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNamehash_EIP137Vectors(t *testing.T) {
+	cases := map[string]string{
+		"":        "0000000000000000000000000000000000000000000000000000000000000000",
+		"eth":     "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae",
+		"foo.eth": "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f",
+	}
+	for name, want := range cases {
+		got := Namehash(name)
+		if hex.EncodeToString(got[:]) != want {
+			t.Fatalf("namehash(%q)=%x, want %s", name, got, want)
+		}
+	}
+}
+
+// fakeENSNode 模拟 registry/resolver：alice.eth 解析到固定地址，其他域名未注册。
+func fakeENSNode(t *testing.T, resolver, addr string) *httptest.Server {
+	t.Helper()
+	alice := Namehash("alice.eth")
+	aliceHex := hex.EncodeToString(alice[:])
+	word := func(a string) string { return "0x" + strings.Repeat("0", 24) + strings.TrimPrefix(a, "0x") }
+	zero := "0x" + strings.Repeat("0", 64)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req evmRPCReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode req: %v", err)
+		}
+		result := any(nil)
+		switch req.Method {
+		case "eth_blockNumber":
+			result = "0x10"
+		case "eth_getBlockByNumber":
+			if req.Params[0] != "0x10" {
+				t.Fatalf("block param=%v", req.Params[0])
+			}
+			result = map[string]any{"number": "0x10", "timestamp": "0x65000000"}
+		case "eth_call":
+			if req.Params[1] != "0x10" {
+				t.Fatalf("eth_call not pinned to block: %v", req.Params[1])
+			}
+			call := req.Params[0].(map[string]any)
+			data := call["data"].(string)
+			switch {
+			case strings.EqualFold(call["to"].(string), ENSRegistryAddress) && data == selectorResolver+aliceHex:
+				result = word(resolver)
+			case call["to"] == resolver && data == selectorAddr+aliceHex:
+				result = word(addr)
+			default:
+				result = zero
+			}
+		default:
+			t.Fatalf("unexpected method %s", req.Method)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": result})
+	}))
+}
+
+func TestNameResolver_ResolvePartial(t *testing.T) {
+	resolver := "0x4976fb03c32e5b8cfe2b6ccb31c09ba78ebaba41"
+	addr := "0xd8da6bf26964af9d7eed9e03e53415d37aa96045"
+	srv := fakeENSNode(t, resolver, addr)
+	defer srv.Close()
+
+	r := NewNameResolver(srv.URL)
+	r.Retry = &RetryPolicy{MaxAttempts: 1}
+	got, errs, err := r.ResolvePartial(context.Background(), []string{"Alice.eth", "nobody.eth"})
+	if err != nil {
+		t.Fatalf("ResolvePartial: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Fatalf("errors=%v", errs)
+	}
+
+	alice := got["alice.eth"]
+	if alice.Status != NameStatusResolved || alice.Address != addr || alice.Resolver != resolver {
+		t.Fatalf("alice=%+v", alice)
+	}
+	if alice.BlockNumber != 16 || alice.BlockTimestamp != 0x65000000 || alice.Service != NameServiceENS {
+		t.Fatalf("alice block/service=%+v", alice)
+	}
+	if nobody := got["nobody.eth"]; nobody.Status != NameStatusUnresolved || nobody.Address != "" {
+		t.Fatalf("nobody=%+v", nobody)
+	}
+}
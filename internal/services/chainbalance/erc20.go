@@ -18,10 +18,18 @@ import (
 // - 该实现仅覆盖最常见的 balanceOf(address)->uint256，不做 ABI 泛化。
 // - 返回同时包含：<SYMBOL>（按 decimals 格式化）与 <SYMBOL>_RAW（原始整数）。
 type ERC20Provider struct {
-	RPCURL     string
-	Symbol     string // 例如 USDT/USDC
-	Contract   string // token 合约地址
-	Decimals   int    // 例如 USDT=6，USDC=6，DAI=18
+	RPCURL   string
+	Symbol   string // 例如 USDT/USDC
+	Contract string // token 合约地址
+	Decimals int    // 例如 USDT=6，USDC=6，DAI=18
+	// Chain 为空或 EVM 链时按 0x 地址查询；tron 时合约/持有地址可为 base58（T...），查询前转换为十六进制。
+	Chain string
+
+	// FallbackRPCURLs / Retry / Breaker 语义同 EVMProvider。
+	FallbackRPCURLs []string
+	Retry           *RetryPolicy
+	Breaker         *CircuitBreaker
+
 	HTTPClient *http.Client
 }
 
@@ -29,7 +37,20 @@ func NewERC20Provider(rpcURL string) *ERC20Provider {
 	return &ERC20Provider{RPCURL: strings.TrimSpace(rpcURL)}
 }
 
+// QueryBalances 查询全部地址；任意地址最终失败则返回错误。
 func (p *ERC20Provider) QueryBalances(ctx context.Context, addresses []string) (map[string]map[string]string, error) {
+	res, err := p.QueryBalancesPartial(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Err(); err != nil {
+		return nil, err
+	}
+	return res.Balances, nil
+}
+
+// QueryBalancesPartial 查询全部地址并返回部分结果（失败地址进入 Errors）。
+func (p *ERC20Provider) QueryBalancesPartial(ctx context.Context, addresses []string) (*PartialResult, error) {
 	rpcURL := strings.TrimSpace(p.RPCURL)
 	if rpcURL == "" {
 		return nil, fmt.Errorf("rpc_url is required")
@@ -46,34 +67,51 @@ func (p *ERC20Provider) QueryBalances(ctx context.Context, addresses []string) (
 	if decimals < 0 {
 		decimals = 0
 	}
+	tron := NormalizeChain(p.Chain) == ChainTron
+	if tron {
+		hexContract, err := TronToEVMHex(contract)
+		if err != nil {
+			return nil, err
+		}
+		contract = hexContract
+	}
 
 	c := p.HTTPClient
 	if c == nil {
 		c = &http.Client{Timeout: 12 * time.Second}
 	}
 
-	out := make(map[string]map[string]string, len(addresses))
-	for _, addr := range addresses {
-		addr = strings.TrimSpace(addr)
-		if addr == "" {
-			continue
-		}
-		n, err := evmERC20BalanceOf(ctx, c, rpcURL, contract, addr)
-		if err != nil {
-			return nil, fmt.Errorf("query %s: %w", addr, err)
-		}
-		out[addr] = map[string]string{
-			symbol + "_RAW": n.String(),
-			symbol:          formatUnits(n, decimals),
-		}
+	q := resilientQuery{
+		Endpoints: buildEndpoints(rpcURL, p.FallbackRPCURLs),
+		Retry:     retryPolicyOrDefault(p.Retry),
+		Breaker:   p.Breaker,
+		QueryOne: func(ctx context.Context, endpoint, addr string) (map[string]string, error) {
+			holder := addr
+			if tron {
+				h, err := TronToEVMHex(addr)
+				if err != nil {
+					return nil, permanent(err)
+				}
+				holder = h
+			}
+			n, err := evmERC20BalanceOf(ctx, c, endpoint, contract, holder)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]string{
+				symbol + "_RAW": n.String(),
+				symbol:          formatUnits(n, decimals),
+			}, nil
+		},
 	}
-	return out, nil
+	return q.run(ctx, addresses)
 }
 
 func evmERC20BalanceOf(ctx context.Context, c *http.Client, rpcURL, contract, holder string) (*big.Int, error) {
 	data, err := encodeERC20BalanceOf(holder)
 	if err != nil {
-		return nil, err
+		// 地址格式错误：重试/换节点都没有意义。
+		return nil, permanent(err)
 	}
 
 	reqBody := evmRPCReq{
@@ -107,7 +145,7 @@ func evmERC20BalanceOf(ctx context.Context, c *http.Client, rpcURL, contract, ho
 		return nil, err
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("rpc http %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+		return nil, &httpStatusError{Prefix: "rpc http", StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(b))}
 	}
 
 	var out evmRPCResp
@@ -115,7 +153,7 @@ func evmERC20BalanceOf(ctx context.Context, c *http.Client, rpcURL, contract, ho
 		return nil, fmt.Errorf("decode rpc json: %w", err)
 	}
 	if out.Error != nil {
-		return nil, fmt.Errorf("rpc error %d: %s", out.Error.Code, out.Error.Message)
+		return nil, &rpcCallError{Code: out.Error.Code, Message: out.Error.Message}
 	}
 
 	hexVal := strings.TrimSpace(out.Result)
@@ -0,0 +1,93 @@
+package chainbalance
+
+import (
+	"fmt"
+	"strings"
+
+	"crypto-inspector/internal/domain/apperr"
+)
+
+// ERC20Target 是一次 ERC20 查询最终使用的链/合约/decimals/RPC。
+type ERC20Target struct {
+	Chain    string
+	Symbol   string
+	Contract string
+	Decimals int
+	RPCURL   string
+	Preset   *TokenPreset
+}
+
+// ResolveERC20Target 决定 ERC20 查询参数：
+// - 显式给出 contract 时以调用方参数为准
+// - 否则按 chain + symbol 从预置清单选择（记录清单版本）
+// - 两者都没有时沿用旧行为：USDT 回退到以太坊主网合约
+func ResolveERC20Target(chain, symbol, contract string, decimals int, rpcURL string) (ERC20Target, []string, error) {
+	warnings := []string{}
+	t := ERC20Target{
+		Symbol:   strings.TrimSpace(symbol),
+		Contract: strings.TrimSpace(contract),
+		Decimals: decimals,
+		RPCURL:   strings.TrimSpace(rpcURL),
+	}
+	if t.Symbol == "" {
+		t.Symbol = "USDT"
+	}
+	if strings.TrimSpace(chain) != "" {
+		t.Chain = NormalizeChain(chain)
+		if t.Chain == "" {
+			return t, nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unknown chain: %s", chain))
+		}
+	}
+
+	if t.RPCURL == "" {
+		// 内部试用默认走公共 RPC，方便开箱即用。
+		// 对外/正式环境建议改为“强制配置私有 RPC”，并做访问控制与审计。
+		if t.Chain != "" {
+			t.RPCURL = DefaultRPCForChain(t.Chain)
+			warnings = append(warnings, fmt.Sprintf("rpc_url not provided; fallback to default public rpc for %s", t.Chain))
+		} else {
+			t.RPCURL = DefaultPublicEVMRPC
+			warnings = append(warnings, "rpc_url not provided; fallback to default public rpc")
+		}
+	}
+
+	if t.Contract == "" && t.Chain != "" {
+		preset, ok := LookupToken(t.Chain, t.Symbol)
+		if !ok {
+			return t, nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("token %s on %s not found in registry %s; provide contract and decimals", t.Symbol, t.Chain, TokenRegistryVersion))
+		}
+		t.Preset = &preset
+		t.Symbol = preset.Symbol
+		t.Contract = preset.Contract
+		if t.Decimals == 0 {
+			t.Decimals = preset.Decimals
+		}
+		return t, warnings, nil
+	}
+
+	if t.Contract == "" && strings.EqualFold(t.Symbol, "USDT") {
+		// 内测默认值（Ethereum Mainnet USDT）
+		t.Contract = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+		warnings = append(warnings, "contract not provided; fallback to Ethereum mainnet USDT contract")
+	}
+	if t.Contract == "" {
+		return t, nil, apperr.New(apperr.CodeInvalidArgument, "contract is required")
+	}
+	if t.Decimals == 0 && strings.EqualFold(t.Symbol, "USDT") {
+		// USDT 在以太坊主网常用 decimals=6。
+		t.Decimals = 6
+		warnings = append(warnings, "decimals not provided; fallback to 6 for USDT")
+	}
+	return t, warnings, nil
+}
+
+// Annotate 把链与预置清单信息写入响应/证据元数据。
+func (t ERC20Target) Annotate(m map[string]any) {
+	if t.Chain != "" {
+		m["network"] = t.Chain
+	}
+	if t.Preset != nil {
+		m["token_registry_version"] = TokenRegistryVersion
+		m["token_preset"] = t.Preset
+	}
+}
@@ -21,6 +21,13 @@ type EVMProvider struct {
 	RPCURL string
 	Symbol string // 例如 ETH/BNB/MATIC
 
+	// FallbackRPCURLs 为备用节点：主节点失败（重试耗尽）或熔断时依次切换。
+	FallbackRPCURLs []string
+	// Retry 为单节点重试策略（nil 使用 DefaultRetryPolicy）。
+	Retry *RetryPolicy
+	// Breaker 为按节点的熔断器（nil 表示不熔断；可在多次请求间共享）。
+	Breaker *CircuitBreaker
+
 	HTTPClient *http.Client
 }
 
@@ -28,7 +35,20 @@ func NewEVMProvider(rpcURL string) *EVMProvider {
 	return &EVMProvider{RPCURL: strings.TrimSpace(rpcURL)}
 }
 
+// QueryBalances 查询全部地址；任意地址在重试/切换备用节点后仍失败则返回错误。
 func (p *EVMProvider) QueryBalances(ctx context.Context, addresses []string) (map[string]map[string]string, error) {
+	res, err := p.QueryBalancesPartial(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Err(); err != nil {
+		return nil, err
+	}
+	return res.Balances, nil
+}
+
+// QueryBalancesPartial 查询全部地址并返回部分结果：成功地址进入 Balances，失败地址进入 Errors。
+func (p *EVMProvider) QueryBalancesPartial(ctx context.Context, addresses []string) (*PartialResult, error) {
 	rpcURL := strings.TrimSpace(p.RPCURL)
 	if rpcURL == "" {
 		return nil, fmt.Errorf("rpc_url is required")
@@ -43,25 +63,23 @@ func (p *EVMProvider) QueryBalances(ctx context.Context, addresses []string) (ma
 		c = &http.Client{Timeout: 12 * time.Second}
 	}
 
-	out := make(map[string]map[string]string, len(addresses))
-	for _, addr := range addresses {
-		addr = strings.TrimSpace(addr)
-		if addr == "" {
-			continue
-		}
-
-		wei, err := evmGetBalance(ctx, c, rpcURL, addr)
-		if err != nil {
-			return nil, fmt.Errorf("query %s: %w", addr, err)
-		}
-
-		out[addr] = map[string]string{
-			"WEI": wei.String(),
-			// 为了便于人读，这里同时给出 18 位小数的“ETH”格式；精确值请以 WEI 为准。
-			symbol: formatEther18(wei),
-		}
-	}
-	return out, nil
+	q := resilientQuery{
+		Endpoints: buildEndpoints(rpcURL, p.FallbackRPCURLs),
+		Retry:     retryPolicyOrDefault(p.Retry),
+		Breaker:   p.Breaker,
+		QueryOne: func(ctx context.Context, endpoint, addr string) (map[string]string, error) {
+			wei, err := evmGetBalance(ctx, c, endpoint, addr)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]string{
+				"WEI": wei.String(),
+				// 为了便于人读，这里同时给出 18 位小数的“ETH”格式；精确值请以 WEI 为准。
+				symbol: formatEther18(wei),
+			}, nil
+		},
+	}
+	return q.run(ctx, addresses)
 }
 
 type evmRPCReq struct {
@@ -109,7 +127,7 @@ func evmGetBalance(ctx context.Context, c *http.Client, rpcURL string, address s
 		return nil, err
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("rpc http %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+		return nil, &httpStatusError{Prefix: "rpc http", StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(b))}
 	}
 
 	var out evmRPCResp
@@ -117,7 +135,7 @@ func evmGetBalance(ctx context.Context, c *http.Client, rpcURL string, address s
 		return nil, fmt.Errorf("decode rpc json: %w", err)
 	}
 	if out.Error != nil {
-		return nil, fmt.Errorf("rpc error %d: %s", out.Error.Code, out.Error.Message)
+		return nil, &rpcCallError{Code: out.Error.Code, Message: out.Error.Message}
 	}
 
 	hex := strings.TrimSpace(out.Result)
@@ -0,0 +1,382 @@
+package chainbalance
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// NFTProvider 使用 eth_call 查询地址持有的 NFT（ERC-721 / ERC-1155）。
+//
+// 说明：
+//   - 只查询调用方指定的合约（不做全链扫描，也不依赖第三方索引服务）。
+//   - ERC-721：balanceOf(owner) 得到持有数量；合约实现 Enumerable 扩展时
+//     再用 tokenOfOwnerByIndex 枚举 token ID（超过 MaxTokens 截断）。
+//   - ERC-1155：标准没有枚举接口，只能对调用方给出的 TokenIDs 逐个 balanceOf(owner, id)。
+//   - 合约名称/符号（name()/symbol()）为可选元数据，查询失败不影响结果。
+type NFTProvider struct {
+	RPCURL    string
+	Standard  string   // erc721|erc1155
+	Contracts []string // 待查询的 NFT 合约地址
+	TokenIDs  []string // ERC-1155 待查询的 token ID（十进制或 0x 十六进制）
+	MaxTokens int      // ERC-721 单地址单合约最多枚举的 token 数（默认 100）
+
+	// FallbackRPCURLs / Retry / Breaker 语义同 EVMProvider。
+	FallbackRPCURLs []string
+	Retry           *RetryPolicy
+	Breaker         *CircuitBreaker
+
+	HTTPClient *http.Client
+}
+
+// NFT 标准。
+const (
+	NFTStandardERC721  = "erc721"
+	NFTStandardERC1155 = "erc1155"
+)
+
+// 函数选择器。
+const (
+	selectorBalanceOf           = "0x70a08231" // balanceOf(address)
+	selectorTokenOfOwnerByIndex = "0x2f745c59" // tokenOfOwnerByIndex(address,uint256)
+	selectorBalanceOf1155       = "0x00fdd58e" // balanceOf(address,uint256)
+	selectorName                = "0x06fdde03" // name()
+	selectorSymbol              = "0x95d89b41" // symbol()
+)
+
+const defaultNFTMaxTokens = 100
+
+// NFTHolding 是某地址在某个合约下的持有情况。
+type NFTHolding struct {
+	Contract   string   `json:"contract"`
+	Standard   string   `json:"standard"`
+	Name       string   `json:"name,omitempty"`
+	Symbol     string   `json:"symbol,omitempty"`
+	Balance    string   `json:"balance"` // ERC-721 为持有数量；ERC-1155 为各 token 数量之和
+	TokenIDs   []string `json:"token_ids,omitempty"`
+	Enumerable bool     `json:"enumerable"`          // ERC-721 是否支持枚举 token ID
+	Truncated  bool     `json:"truncated,omitempty"` // token ID 超过 MaxTokens 被截断
+	// Amounts 为 ERC-1155 各 token ID 的持有数量（只列出非零项）。
+	Amounts map[string]string `json:"amounts,omitempty"`
+}
+
+// NFTResult 是允许部分失败的 NFT 批量查询结果。
+type NFTResult struct {
+	// Holdings 只包含持有数量非零的合约。
+	Holdings  map[string][]NFTHolding `json:"holdings"`
+	Errors    map[string]AddressError `json:"errors,omitempty"`
+	Endpoints map[string]string       `json:"endpoints,omitempty"`
+}
+
+func NewNFTProvider(rpcURL string) *NFTProvider {
+	return &NFTProvider{RPCURL: strings.TrimSpace(rpcURL)}
+}
+
+// QueryBalances 实现 Provider：address -> contract -> 持有数量。
+func (p *NFTProvider) QueryBalances(ctx context.Context, addresses []string) (map[string]map[string]string, error) {
+	res, err := p.QueryBalancesPartial(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	if err := res.Err(); err != nil {
+		return nil, err
+	}
+	return res.Balances, nil
+}
+
+// QueryBalancesPartial 返回按合约汇总的持有数量（明细见 QueryHoldingsPartial）。
+func (p *NFTProvider) QueryBalancesPartial(ctx context.Context, addresses []string) (*PartialResult, error) {
+	res, err := p.QueryHoldingsPartial(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+	return res.Summary(), nil
+}
+
+// QueryHoldingsPartial 查询全部地址的 NFT 持有明细（失败地址进入 Errors）。
+func (p *NFTProvider) QueryHoldingsPartial(ctx context.Context, addresses []string) (*NFTResult, error) {
+	rpcURL := strings.TrimSpace(p.RPCURL)
+	if rpcURL == "" {
+		return nil, fmt.Errorf("rpc_url is required")
+	}
+	standard := strings.ToLower(strings.TrimSpace(p.Standard))
+	if standard == "" {
+		standard = NFTStandardERC721
+	}
+	if standard != NFTStandardERC721 && standard != NFTStandardERC1155 {
+		return nil, fmt.Errorf("unsupported nft standard: %s", p.Standard)
+	}
+	contracts := make([]string, 0, len(p.Contracts))
+	for _, c := range p.Contracts {
+		if c = strings.TrimSpace(c); c != "" {
+			contracts = append(contracts, c)
+		}
+	}
+	if len(contracts) == 0 {
+		return nil, fmt.Errorf("contracts is required")
+	}
+	var tokenIDs []*big.Int
+	if standard == NFTStandardERC1155 {
+		for _, s := range p.TokenIDs {
+			n, err := parseUint256(s)
+			if err != nil {
+				return nil, err
+			}
+			tokenIDs = append(tokenIDs, n)
+		}
+		if len(tokenIDs) == 0 {
+			return nil, fmt.Errorf("token_ids is required for erc1155")
+		}
+	}
+	maxTokens := p.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultNFTMaxTokens
+	}
+
+	c := p.HTTPClient
+	if c == nil {
+		c = &http.Client{Timeout: 12 * time.Second}
+	}
+
+	// 合约元数据在一次查询内缓存（所有地址共用）。
+	type meta struct{ name, symbol string }
+	metas := map[string]*meta{}
+	holdings := map[string][]NFTHolding{}
+
+	q := resilientQuery{
+		Endpoints: buildEndpoints(rpcURL, p.FallbackRPCURLs),
+		Retry:     retryPolicyOrDefault(p.Retry),
+		Breaker:   p.Breaker,
+		QueryOne: func(ctx context.Context, endpoint, owner string) (map[string]string, error) {
+			ownerWord, err := encodeAddressWord(owner)
+			if err != nil {
+				// 地址格式错误：重试/换节点都没有意义。
+				return nil, permanent(err)
+			}
+			var hs []NFTHolding
+			for _, contract := range contracts {
+				var h *NFTHolding
+				if standard == NFTStandardERC721 {
+					h, err = erc721Holding(ctx, c, endpoint, contract, ownerWord, maxTokens)
+				} else {
+					h, err = erc1155Holding(ctx, c, endpoint, contract, ownerWord, tokenIDs)
+				}
+				if err != nil {
+					return nil, err
+				}
+				if h == nil {
+					continue
+				}
+				m := metas[contract]
+				if m == nil {
+					m = &meta{
+						name:   evmCallString(ctx, c, endpoint, contract, selectorName),
+						symbol: evmCallString(ctx, c, endpoint, contract, selectorSymbol),
+					}
+					metas[contract] = m
+				}
+				h.Name, h.Symbol = m.name, m.symbol
+				hs = append(hs, *h)
+			}
+			holdings[owner] = hs
+
+			summary := make(map[string]string, len(hs))
+			for _, h := range hs {
+				summary[h.Contract] = h.Balance
+			}
+			return summary, nil
+		},
+	}
+	part, err := q.run(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &NFTResult{
+		Holdings:  make(map[string][]NFTHolding, len(part.Balances)),
+		Errors:    part.Errors,
+		Endpoints: part.Endpoints,
+	}
+	for addr := range part.Balances {
+		hs := holdings[addr]
+		if hs == nil {
+			hs = []NFTHolding{}
+		}
+		out.Holdings[addr] = hs
+	}
+	return out, nil
+}
+
+// Summary 把持有明细汇总为 PartialResult（address -> contract -> 持有数量）。
+func (r *NFTResult) Summary() *PartialResult {
+	out := &PartialResult{
+		Balances:  make(map[string]map[string]string, len(r.Holdings)),
+		Errors:    r.Errors,
+		Endpoints: r.Endpoints,
+	}
+	for addr, hs := range r.Holdings {
+		m := make(map[string]string, len(hs))
+		for _, h := range hs {
+			m[h.Contract] = h.Balance
+		}
+		out.Balances[addr] = m
+	}
+	return out
+}
+
+// Err 在存在失败地址时返回汇总错误。
+func (r *NFTResult) Err() error {
+	if r == nil {
+		return nil
+	}
+	return r.Summary().Err()
+}
+
+// erc721Holding 查询 ERC-721 持有情况；持有数量为 0 时返回 nil。
+func erc721Holding(ctx context.Context, c *http.Client, rpcURL, contract, ownerWord string, maxTokens int) (*NFTHolding, error) {
+	bal, err := evmCallUint(ctx, c, rpcURL, contract, selectorBalanceOf+ownerWord)
+	if err != nil {
+		return nil, err
+	}
+	if bal.Sign() == 0 {
+		return nil, nil
+	}
+	h := &NFTHolding{Contract: contract, Standard: NFTStandardERC721, Balance: bal.String(), Enumerable: true}
+
+	n := maxTokens
+	if bal.IsInt64() && bal.Int64() <= int64(n) {
+		n = int(bal.Int64())
+	} else {
+		h.Truncated = true
+	}
+	for i := 0; i < n; i++ {
+		tokenID, err := evmCallUint(ctx, c, rpcURL, contract, selectorTokenOfOwnerByIndex+ownerWord+uint256Word(big.NewInt(int64(i))))
+		if err != nil {
+			if isRevert(err) {
+				// 合约未实现 Enumerable：只保留持有数量。
+				h.Enumerable, h.Truncated, h.TokenIDs = false, false, nil
+				return h, nil
+			}
+			return nil, err
+		}
+		h.TokenIDs = append(h.TokenIDs, tokenID.String())
+	}
+	return h, nil
+}
+
+// erc1155Holding 对指定 token ID 逐个查询 ERC-1155 余额；全部为 0 时返回 nil。
+func erc1155Holding(ctx context.Context, c *http.Client, rpcURL, contract, ownerWord string, tokenIDs []*big.Int) (*NFTHolding, error) {
+	total := new(big.Int)
+	h := &NFTHolding{Contract: contract, Standard: NFTStandardERC1155, Amounts: map[string]string{}}
+	for _, id := range tokenIDs {
+		n, err := evmCallUint(ctx, c, rpcURL, contract, selectorBalanceOf1155+ownerWord+uint256Word(id))
+		if err != nil {
+			return nil, err
+		}
+		if n.Sign() == 0 {
+			continue
+		}
+		h.TokenIDs = append(h.TokenIDs, id.String())
+		h.Amounts[id.String()] = n.String()
+		total.Add(total, n)
+	}
+	if total.Sign() == 0 {
+		return nil, nil
+	}
+	h.Balance = total.String()
+	return h, nil
+}
+
+// evmCallUint 执行 eth_call（latest）并把返回值解码为 uint256。
+func evmCallUint(ctx context.Context, c *http.Client, rpcURL, to, data string) (*big.Int, error) {
+	var out string
+	params := []any{map[string]any{"to": to, "data": data}, "latest"}
+	if err := evmRPC(ctx, c, rpcURL, "eth_call", params, &out); err != nil {
+		return nil, err
+	}
+	h := strings.TrimPrefix(strings.TrimSpace(out), "0x")
+	if h == "" {
+		// 目标地址不是合约时 eth_call 返回 0x。
+		return nil, permanent(fmt.Errorf("empty eth_call result from %s (not a contract?)", to))
+	}
+	if len(h) > 64 {
+		h = h[:64]
+	}
+	n, ok := new(big.Int).SetString(h, 16)
+	if !ok {
+		return nil, fmt.Errorf("invalid eth_call result: %s", out)
+	}
+	return n, nil
+}
+
+// evmCallString 调用返回 string 的只读函数（name/symbol）；失败或不可解码时返回空串。
+func evmCallString(ctx context.Context, c *http.Client, rpcURL, to, selector string) string {
+	var out string
+	params := []any{map[string]any{"to": to, "data": selector}, "latest"}
+	if err := evmRPC(ctx, c, rpcURL, "eth_call", params, &out); err != nil {
+		return ""
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(out), "0x"))
+	if err != nil || len(b) < 64 {
+		return ""
+	}
+	// ABI 动态 string：offset(32) + length(32) + data
+	off := new(big.Int).SetBytes(b[:32])
+	if !off.IsInt64() || off.Int64()+32 > int64(len(b)) {
+		return ""
+	}
+	o := int(off.Int64())
+	l := new(big.Int).SetBytes(b[o : o+32])
+	if !l.IsInt64() || int64(o+32)+l.Int64() > int64(len(b)) {
+		return ""
+	}
+	s := b[o+32 : o+32+int(l.Int64())]
+	if !utf8.Valid(s) {
+		return ""
+	}
+	return string(s)
+}
+
+// encodeAddressWord 把地址编码为 32 字节 ABI 参数（不含 0x）。
+func encodeAddressWord(addr string) (string, error) {
+	h := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(addr)), "0x")
+	if len(h) != 40 {
+		return "", fmt.Errorf("invalid address length: %d", len(h))
+	}
+	if _, err := hex.DecodeString(h); err != nil {
+		return "", fmt.Errorf("invalid address hex: %s", addr)
+	}
+	return strings.Repeat("0", 24) + h, nil
+}
+
+func uint256Word(n *big.Int) string {
+	return fmt.Sprintf("%064x", n)
+}
+
+func parseUint256(s string) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	base := 10
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s, base = s[2:], 16
+	}
+	n, ok := new(big.Int).SetString(s, base)
+	if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid token id: %s", s)
+	}
+	return n, nil
+}
+
+// isRevert 判断 eth_call 是否因合约 revert 失败（函数未实现/参数越界）。
+func isRevert(err error) bool {
+	var re *rpcCallError
+	if !errors.As(err, &re) {
+		return false
+	}
+	return re.Code == 3 || strings.Contains(strings.ToLower(re.Message), "revert")
+}
@@ -0,0 +1,93 @@
+package chainbalance
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func abiString(s string) string {
+	b := make([]byte, 64, 96)
+	b[31] = 0x20
+	big.NewInt(int64(len(s))).FillBytes(b[32:64])
+	pad := make([]byte, (32-len(s)%32)%32)
+	return "0x" + hex.EncodeToString(append(append(b, s...), pad...))
+}
+
+func TestNFTProvider_ERC721AndERC1155(t *testing.T) {
+	owner := "0x000000000000000000000000000000000000dEaD"
+	ownerWord, _ := encodeAddressWord(owner)
+	enumerable := "0x1111111111111111111111111111111111111111"
+	plain := "0x2222222222222222222222222222222222222222"
+	multi := "0x3333333333333333333333333333333333333333"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req evmRPCReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode req: %v", err)
+		}
+		call := req.Params[0].(map[string]any)
+		to, data := call["to"].(string), call["data"].(string)
+		reply := func(result string) {
+			_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "result": result})
+		}
+		switch {
+		case data == selectorName:
+			reply(abiString("Test Apes"))
+		case data == selectorSymbol:
+			reply(abiString("TAPE"))
+		case data == selectorBalanceOf+ownerWord && to == enumerable:
+			reply("0x" + uint256Word(big.NewInt(2)))
+		case data == selectorBalanceOf+ownerWord && to == plain:
+			reply("0x" + uint256Word(big.NewInt(1)))
+		case strings.HasPrefix(data, selectorTokenOfOwnerByIndex+ownerWord) && to == enumerable:
+			idx, _ := new(big.Int).SetString(data[len(data)-64:], 16)
+			reply("0x" + uint256Word(new(big.Int).Add(idx, big.NewInt(100))))
+		case strings.HasPrefix(data, selectorTokenOfOwnerByIndex) && to == plain:
+			_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": 1, "error": map[string]any{"code": 3, "message": "execution reverted"}})
+		case data == selectorBalanceOf1155+ownerWord+uint256Word(big.NewInt(7)) && to == multi:
+			reply("0x" + uint256Word(big.NewInt(5)))
+		case strings.HasPrefix(data, selectorBalanceOf1155):
+			reply("0x" + uint256Word(big.NewInt(0)))
+		default:
+			t.Fatalf("unexpected call to=%s data=%s", to, data)
+		}
+	}))
+	defer srv.Close()
+
+	p := NewNFTProvider(srv.URL)
+	p.Contracts = []string{enumerable, plain}
+	p.Retry = &RetryPolicy{MaxAttempts: 1}
+	res, err := p.QueryHoldingsPartial(context.Background(), []string{owner})
+	if err != nil {
+		t.Fatalf("QueryHoldingsPartial: %v", err)
+	}
+	hs := res.Holdings[owner]
+	if len(hs) != 2 {
+		t.Fatalf("holdings=%+v errors=%+v", hs, res.Errors)
+	}
+	if hs[0].Balance != "2" || !hs[0].Enumerable || strings.Join(hs[0].TokenIDs, ",") != "100,101" || hs[0].Name != "Test Apes" || hs[0].Symbol != "TAPE" {
+		t.Fatalf("enumerable holding=%+v", hs[0])
+	}
+	if hs[1].Balance != "1" || hs[1].Enumerable || len(hs[1].TokenIDs) != 0 {
+		t.Fatalf("plain holding=%+v", hs[1])
+	}
+
+	p1155 := NewNFTProvider(srv.URL)
+	p1155.Standard = NFTStandardERC1155
+	p1155.Contracts = []string{multi}
+	p1155.TokenIDs = []string{"7", "0x8"}
+	p1155.Retry = &RetryPolicy{MaxAttempts: 1}
+	sum, err := p1155.QueryBalancesPartial(context.Background(), []string{owner})
+	if err != nil {
+		t.Fatalf("QueryBalancesPartial: %v", err)
+	}
+	if sum.Balances[owner][multi] != "5" {
+		t.Fatalf("erc1155 balances=%+v", sum.Balances)
+	}
+}
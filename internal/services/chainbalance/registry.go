@@ -0,0 +1,442 @@
+package chainbalance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"crypto-inspector/internal/domain/apperr"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 链上查询提供方注册表
+//
+// 留痕查询接口按请求中的 kind 查表得到 ChainProvider，不再在 handler 里逐个 switch 具体实现。
+// 每个条目由“驱动（driver）+ 配置”组成：
+// - 驱动决定查询协议（evm 原生币 / erc20 / nft / btc 兼容 API）
+// - 配置决定链名、默认端点、币种符号
+// 因此新增一条同协议的链（例如 BSC 原生币、LTC 的 Blockstream 兼容网关）只需在配置文件里加一段；
+// 新协议（Solana/TRON 原生）则新增驱动并在 drivers 中登记，handler 无需改动。
+
+// 内置驱动名。
+const (
+	DriverEVM   = "evm"
+	DriverERC20 = "erc20"
+	DriverNFT   = "nft"
+	DriverBTC   = "btc"
+)
+
+// ChainConfig 是注册表中的一个条目（Name 即请求中的 kind）。
+type ChainConfig struct {
+	Name   string `yaml:"name" json:"name"`
+	Driver string `yaml:"driver" json:"driver"`
+	// Chain 为链标识（ethereum/bsc/polygon/tron/bitcoin/litecoin...），写入证据元数据的 network 字段。
+	Chain string `yaml:"chain,omitempty" json:"chain,omitempty"`
+	// Endpoint 为默认 RPC/API 地址；请求未指定端点时使用。
+	Endpoint          string   `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	FallbackEndpoints []string `yaml:"fallback_endpoints,omitempty" json:"fallback_endpoints,omitempty"`
+	Symbol            string   `yaml:"symbol,omitempty" json:"symbol,omitempty"`
+	Description       string   `yaml:"description,omitempty" json:"description,omitempty"`
+	// History 表示该条目是否支持地址交易历史查询（由驱动决定，仅用于展示）。
+	History bool `yaml:"-" json:"history"`
+}
+
+// Query 是一次查询的调用方参数（零值字段使用条目配置/驱动默认值）。
+type Query struct {
+	Endpoint          string
+	FallbackEndpoints []string
+
+	// ERC20
+	Chain    string
+	Symbol   string
+	Contract string
+	Decimals int
+
+	// NFT
+	Standard  string
+	Contracts []string
+	TokenIDs  []string
+	MaxTokens int
+
+	// Breaker 在多次请求间共享（nil 表示不熔断）。
+	Breaker *CircuitBreaker
+}
+
+// QueryResult 是一次余额查询的结果与证据元数据。
+type QueryResult struct {
+	*PartialResult
+	// Holdings 仅 NFT 驱动返回。
+	Holdings map[string][]NFTHolding
+	// Meta 写入证据快照的 query 字段（端点、符号、合约等）。
+	Meta map[string]any
+	// Endpoints 为本次实际配置的端点（主 + 备用），用于展示熔断状态。
+	Endpoints []string
+	Warnings  []string
+}
+
+// Transfer 是地址交易历史中的一笔（Delta 为该地址的净变化，带符号，按最小单位计）。
+type Transfer struct {
+	TxID        string `json:"txid"`
+	BlockHeight int64  `json:"block_height,omitempty"`
+	BlockTime   int64  `json:"block_time,omitempty"`
+	Confirmed   bool   `json:"confirmed"`
+	Delta       string `json:"delta"`
+	Amount      string `json:"amount"`
+	Fee         int64  `json:"fee,omitempty"`
+}
+
+// HistoryResult 是一次地址历史查询的结果。
+type HistoryResult struct {
+	Address   string         `json:"address"`
+	Symbol    string         `json:"symbol"`
+	Endpoint  string         `json:"endpoint"`
+	Transfers []Transfer     `json:"transfers"`
+	Meta      map[string]any `json:"query"`
+	Warnings  []string       `json:"warnings"`
+}
+
+// ErrHistoryUnsupported 表示该提供方不支持交易历史（例如标准 EVM JSON-RPC 没有按地址索引的接口）。
+var ErrHistoryUnsupported = apperr.New(apperr.CodeInvalidArgument, "history query not supported by this chain provider")
+
+// ChainProvider 是注册表中的一个查询提供方。
+type ChainProvider interface {
+	ChainInfo() ChainConfig
+	QueryBalances(ctx context.Context, q Query, addresses []string) (*QueryResult, error)
+	QueryHistory(ctx context.Context, q Query, address string) (*HistoryResult, error)
+}
+
+// drivers 把驱动名映射到构造函数。
+var drivers = map[string]func(cfg ChainConfig) ChainProvider{
+	DriverEVM:   func(cfg ChainConfig) ChainProvider { return evmChain{cfg: cfg} },
+	DriverERC20: func(cfg ChainConfig) ChainProvider { return erc20Chain{cfg: cfg} },
+	DriverNFT:   func(cfg ChainConfig) ChainProvider { return nftChain{cfg: cfg} },
+	DriverBTC:   func(cfg ChainConfig) ChainProvider { cfg.History = true; return btcChain{cfg: cfg} },
+}
+
+// BuiltinChains 是内置条目，保持既有 kind（evm_native/evm_erc20/evm_nft/btc）不变。
+func BuiltinChains() []ChainConfig {
+	return []ChainConfig{
+		{Name: "evm_native", Driver: DriverEVM, Endpoint: DefaultPublicEVMRPC, Symbol: "ETH", Description: "EVM 原生币余额（eth_getBalance）"},
+		{Name: "evm_erc20", Driver: DriverERC20, Description: "ERC20/TRC20 代币余额（balanceOf，支持预置清单）"},
+		{Name: "evm_nft", Driver: DriverNFT, Endpoint: DefaultPublicEVMRPC, Description: "ERC-721/ERC-1155 NFT 持有"},
+		{Name: "btc", Driver: DriverBTC, Chain: "bitcoin", Endpoint: DefaultPublicBTCAPI, Symbol: "BTC", Description: "BTC 地址余额（Blockstream 兼容 API）"},
+	}
+}
+
+// Registry 是按 kind 索引的提供方注册表（并发安全）。
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]ChainProvider
+}
+
+// NewRegistry 返回只包含内置条目的注册表。
+func NewRegistry() *Registry {
+	r := &Registry{providers: map[string]ChainProvider{}}
+	for _, cfg := range BuiltinChains() {
+		if err := r.Register(cfg); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Register 按配置创建提供方并注册；同名条目会被覆盖（配置文件可改写内置条目的默认端点）。
+func (r *Registry) Register(cfg ChainConfig) error {
+	cfg.Name = strings.ToLower(strings.TrimSpace(cfg.Name))
+	cfg.Driver = strings.ToLower(strings.TrimSpace(cfg.Driver))
+	cfg.Chain = strings.ToLower(strings.TrimSpace(cfg.Chain))
+	cfg.Endpoint = strings.TrimSpace(cfg.Endpoint)
+	cfg.Symbol = strings.TrimSpace(cfg.Symbol)
+	if cfg.Name == "" {
+		return fmt.Errorf("chain provider name is required")
+	}
+	newProvider, ok := drivers[cfg.Driver]
+	if !ok {
+		return fmt.Errorf("chain provider %s: unknown driver %q", cfg.Name, cfg.Driver)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[cfg.Name] = newProvider(cfg)
+	return nil
+}
+
+// Lookup 按 kind 查找提供方。
+func (r *Registry) Lookup(kind string) (ChainProvider, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.providers[strings.ToLower(strings.TrimSpace(kind))]
+	return p, ok
+}
+
+// List 返回全部条目（按 name 排序）。
+func (r *Registry) List() []ChainConfig {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]ChainConfig, 0, len(r.providers))
+	for _, p := range r.providers {
+		out = append(out, p.ChainInfo())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+type chainProvidersFile struct {
+	Providers []ChainConfig `yaml:"providers"`
+}
+
+// LoadRegistry 在内置条目基础上加载配置文件（path 为空时只用内置条目）。
+//
+// 文件格式：
+//
+//	providers:
+//	  - name: bsc_native
+//	    driver: evm
+//	    chain: bsc
+//	    endpoint: https://bsc-dataseed.binance.org
+//	    symbol: BNB
+func LoadRegistry(path string) (*Registry, error) {
+	r := NewRegistry()
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return r, nil
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read chain providers: %w", err)
+	}
+	var f chainProvidersFile
+	if err := yaml.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("parse chain providers: %w", err)
+	}
+	for _, cfg := range f.Providers {
+		if err := r.Register(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// resolveEndpoint 选择本次查询端点：请求指定 > 条目配置；使用配置时返回提示。
+func resolveEndpoint(cfg ChainConfig, q Query, param string) (string, []string, []string) {
+	fallbacks := q.FallbackEndpoints
+	if ep := strings.TrimSpace(q.Endpoint); ep != "" {
+		return ep, fallbacks, nil
+	}
+	if len(fallbacks) == 0 {
+		fallbacks = cfg.FallbackEndpoints
+	}
+	var warning string
+	switch cfg.Endpoint {
+	case "":
+		return "", fallbacks, nil
+	case DefaultPublicEVMRPC:
+		warning = param + " not provided; fallback to default public rpc"
+	case DefaultPublicBTCAPI:
+		warning = param + " not provided; fallback to default public btc api"
+	default:
+		warning = fmt.Sprintf("%s not provided; using configured endpoint for %s", param, cfg.Name)
+	}
+	return cfg.Endpoint, fallbacks, []string{warning}
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func annotateNetwork(cfg ChainConfig, meta map[string]any) {
+	meta["provider"] = cfg.Name
+	if cfg.Chain != "" {
+		meta["network"] = cfg.Chain
+	}
+}
+
+// evmChain 查询 EVM 兼容链原生币余额。
+type evmChain struct{ cfg ChainConfig }
+
+func (c evmChain) ChainInfo() ChainConfig { return c.cfg }
+
+func (c evmChain) QueryBalances(ctx context.Context, q Query, addresses []string) (*QueryResult, error) {
+	rpcURL, fallbacks, warnings := resolveEndpoint(c.cfg, q, "rpc_url")
+	if rpcURL == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "rpc_url is required")
+	}
+	symbol := firstNonEmpty(q.Symbol, c.cfg.Symbol, "ETH")
+	p := NewEVMProvider(rpcURL)
+	p.Symbol = symbol
+	p.FallbackRPCURLs = fallbacks
+	p.Breaker = q.Breaker
+	res, err := p.QueryBalancesPartial(ctx, addresses)
+	meta := map[string]any{
+		"chain":             "evm",
+		"rpc_url":           rpcURL,
+		"fallback_rpc_urls": fallbacks,
+		"symbol":            symbol,
+	}
+	annotateNetwork(c.cfg, meta)
+	return &QueryResult{PartialResult: res, Meta: meta, Endpoints: buildEndpoints(rpcURL, fallbacks), Warnings: warnings}, err
+}
+
+func (c evmChain) QueryHistory(context.Context, Query, string) (*HistoryResult, error) {
+	return nil, ErrHistoryUnsupported
+}
+
+// erc20Chain 查询 ERC20（及 ABI 兼容的 TRC20）代币余额。
+type erc20Chain struct{ cfg ChainConfig }
+
+func (c erc20Chain) ChainInfo() ChainConfig { return c.cfg }
+
+func (c erc20Chain) QueryBalances(ctx context.Context, q Query, addresses []string) (*QueryResult, error) {
+	t, warnings, err := ResolveERC20Target(firstNonEmpty(q.Chain, c.cfg.Chain), firstNonEmpty(q.Symbol, c.cfg.Symbol), q.Contract, q.Decimals, firstNonEmpty(q.Endpoint, c.cfg.Endpoint))
+	if err != nil {
+		return nil, err
+	}
+	fallbacks := q.FallbackEndpoints
+	if len(fallbacks) == 0 && strings.TrimSpace(q.Endpoint) == "" {
+		fallbacks = c.cfg.FallbackEndpoints
+	}
+	p := NewERC20Provider(t.RPCURL)
+	p.Symbol = t.Symbol
+	p.Contract = t.Contract
+	p.Decimals = t.Decimals
+	p.Chain = t.Chain
+	p.FallbackRPCURLs = fallbacks
+	p.Breaker = q.Breaker
+	res, err := p.QueryBalancesPartial(ctx, addresses)
+	meta := map[string]any{
+		"chain":             "evm",
+		"token_type":        "erc20",
+		"rpc_url":           t.RPCURL,
+		"fallback_rpc_urls": fallbacks,
+		"symbol":            t.Symbol,
+		"contract":          t.Contract,
+		"decimals":          t.Decimals,
+	}
+	annotateNetwork(c.cfg, meta)
+	t.Annotate(meta)
+	return &QueryResult{PartialResult: res, Meta: meta, Endpoints: buildEndpoints(t.RPCURL, fallbacks), Warnings: warnings}, err
+}
+
+func (c erc20Chain) QueryHistory(context.Context, Query, string) (*HistoryResult, error) {
+	return nil, ErrHistoryUnsupported
+}
+
+// nftChain 查询 ERC-721 / ERC-1155 持有情况。
+type nftChain struct{ cfg ChainConfig }
+
+func (c nftChain) ChainInfo() ChainConfig { return c.cfg }
+
+func (c nftChain) QueryBalances(ctx context.Context, q Query, addresses []string) (*QueryResult, error) {
+	rpcURL, fallbacks, warnings := resolveEndpoint(c.cfg, q, "rpc_url")
+	if rpcURL == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "rpc_url is required")
+	}
+	if len(q.Contracts) == 0 {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "contracts is required")
+	}
+	p := NewNFTProvider(rpcURL)
+	p.Standard = q.Standard
+	p.Contracts = q.Contracts
+	p.TokenIDs = q.TokenIDs
+	p.MaxTokens = q.MaxTokens
+	p.FallbackRPCURLs = fallbacks
+	p.Breaker = q.Breaker
+	nres, err := p.QueryHoldingsPartial(ctx, addresses)
+	standard := strings.ToLower(strings.TrimSpace(q.Standard))
+	if standard == "" {
+		standard = NFTStandardERC721
+	}
+	meta := map[string]any{
+		"chain":             "evm",
+		"token_type":        standard,
+		"rpc_url":           rpcURL,
+		"fallback_rpc_urls": fallbacks,
+		"contracts":         q.Contracts,
+	}
+	if len(q.TokenIDs) > 0 {
+		meta["token_ids"] = q.TokenIDs
+	}
+	annotateNetwork(c.cfg, meta)
+	out := &QueryResult{Meta: meta, Endpoints: buildEndpoints(rpcURL, fallbacks), Warnings: warnings}
+	if nres != nil {
+		out.PartialResult = nres.Summary()
+		out.Holdings = nres.Holdings
+	}
+	return out, err
+}
+
+func (c nftChain) QueryHistory(context.Context, Query, string) (*HistoryResult, error) {
+	return nil, ErrHistoryUnsupported
+}
+
+// btcChain 查询 Blockstream 兼容 API（BTC 及提供同格式网关的 UTXO 链，如 LTC）。
+type btcChain struct{ cfg ChainConfig }
+
+func (c btcChain) ChainInfo() ChainConfig { return c.cfg }
+
+func (c btcChain) QueryBalances(ctx context.Context, q Query, addresses []string) (*QueryResult, error) {
+	baseURL, fallbacks, warnings := resolveEndpoint(c.cfg, q, "base_url")
+	if baseURL == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "base_url is required")
+	}
+	symbol := firstNonEmpty(q.Symbol, c.cfg.Symbol, "BTC")
+	p := NewBTCProvider(baseURL)
+	p.Symbol = symbol
+	p.FallbackBaseURLs = fallbacks
+	p.Breaker = q.Breaker
+	res, err := p.QueryBalancesPartial(ctx, addresses)
+	meta := map[string]any{
+		"chain":              "btc",
+		"base_url":           baseURL,
+		"fallback_base_urls": fallbacks,
+		"symbol":             symbol,
+	}
+	annotateNetwork(c.cfg, meta)
+	return &QueryResult{PartialResult: res, Meta: meta, Endpoints: buildEndpoints(baseURL, fallbacks), Warnings: warnings}, err
+}
+
+func (c btcChain) QueryHistory(ctx context.Context, q Query, address string) (*HistoryResult, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "address is required")
+	}
+	baseURL, fallbacks, warnings := resolveEndpoint(c.cfg, q, "base_url")
+	if baseURL == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "base_url is required")
+	}
+	symbol := firstNonEmpty(q.Symbol, c.cfg.Symbol, "BTC")
+	p := NewBTCProvider(baseURL)
+	p.FallbackBaseURLs = fallbacks
+	p.Breaker = q.Breaker
+	transfers, endpoint, err := p.QueryHistory(ctx, address)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+		return nil, apperr.Wrap(apperr.CodeUpstreamUnavailable, err, "query address history")
+	}
+	meta := map[string]any{
+		"chain":              "btc",
+		"base_url":           baseURL,
+		"fallback_base_urls": fallbacks,
+		"symbol":             symbol,
+	}
+	annotateNetwork(c.cfg, meta)
+	return &HistoryResult{
+		Address:   address,
+		Symbol:    symbol,
+		Endpoint:  endpoint,
+		Transfers: transfers,
+		Meta:      meta,
+		Warnings:  warnings,
+	}, nil
+}
@@ -0,0 +1,96 @@
+package chainbalance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crypto-inspector/internal/domain/apperr"
+)
+
+func TestLoadRegistryFromConfig(t *testing.T) {
+	const addr = "ltc1qexample"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/address/" + addr:
+			_, _ = w.Write([]byte(`{"chain_stats":{"funded_txo_sum":300000000,"spent_txo_sum":100000000},"mempool_stats":{}}`))
+		case "/address/" + addr + "/txs":
+			_, _ = w.Write([]byte(`[
+				{"txid":"t2","fee":150,"status":{"confirmed":true,"block_height":11,"block_time":2000},
+				 "vin":[{"prevout":{"scriptpubkey_address":"` + addr + `","value":300000000}}],
+				 "vout":[{"scriptpubkey_address":"other","value":100000000},{"scriptpubkey_address":"` + addr + `","value":199999850}]},
+				{"txid":"t1","status":{"confirmed":true,"block_height":10,"block_time":1000},
+				 "vin":[{"prevout":{"scriptpubkey_address":"other","value":300000100}}],
+				 "vout":[{"scriptpubkey_address":"` + addr + `","value":300000000}]}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "chain_providers.yaml")
+	cfg := "providers:\n" +
+		"  - name: ltc\n    driver: btc\n    chain: litecoin\n    endpoint: " + srv.URL + "\n    symbol: LTC\n" +
+		"  - name: bsc_native\n    driver: evm\n    chain: bsc\n    endpoint: https://bsc.example\n    symbol: BNB\n"
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	reg, err := LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadRegistry: %v", err)
+	}
+	for _, kind := range []string{"evm_native", "evm_erc20", "evm_nft", "btc", "ltc", "bsc_native"} {
+		if _, ok := reg.Lookup(kind); !ok {
+			t.Fatalf("kind %s not registered", kind)
+		}
+	}
+
+	p, _ := reg.Lookup("LTC")
+	res, err := p.QueryBalances(context.Background(), Query{}, []string{addr})
+	if err != nil {
+		t.Fatalf("QueryBalances: %v", err)
+	}
+	if res.Balances[addr]["LTC"] != "2" || res.Meta["network"] != "litecoin" || res.Meta["provider"] != "ltc" {
+		t.Fatalf("res=%+v meta=%v", res.PartialResult, res.Meta)
+	}
+	if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], "configured endpoint for ltc") {
+		t.Fatalf("warnings=%v", res.Warnings)
+	}
+
+	h, err := p.QueryHistory(context.Background(), Query{}, addr)
+	if err != nil {
+		t.Fatalf("QueryHistory: %v", err)
+	}
+	if len(h.Transfers) != 2 || h.Transfers[0].Delta != "-100000150" || h.Transfers[0].Amount != "-1.0000015" || h.Transfers[1].Delta != "300000000" {
+		t.Fatalf("transfers=%+v", h.Transfers)
+	}
+
+	evm, _ := reg.Lookup("bsc_native")
+	if _, err := evm.QueryHistory(context.Background(), Query{}, addr); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("evm history err=%v", err)
+	}
+}
+
+func TestChainProvidersTemplateLoads(t *testing.T) {
+	reg, err := LoadRegistry(filepath.Join("..", "..", "..", "rules", "chain_providers.template.yaml"))
+	if err != nil {
+		t.Fatalf("LoadRegistry template: %v", err)
+	}
+	if len(reg.List()) <= len(BuiltinChains()) {
+		t.Fatalf("template added no kinds: %+v", reg.List())
+	}
+}
+
+func TestRegistryRejectsUnknownDriver(t *testing.T) {
+	if err := NewRegistry().Register(ChainConfig{Name: "sol", Driver: "solana"}); err == nil {
+		t.Fatalf("expected error for unknown driver")
+	}
+	if _, err := LoadRegistry(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Fatalf("expected error for missing config file")
+	}
+}
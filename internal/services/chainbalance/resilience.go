@@ -0,0 +1,391 @@
+package chainbalance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 余额查询的容错策略
+//
+// 背景：公共 RPC/API 经常出现偶发超时、429 限流或 5xx，
+// 以前“任意一个地址失败 -> 整批失败”，现场很难判断到底哪些地址没查到。
+//
+// 这里统一提供：
+// - 单地址重试（指数退避）
+// - 备用端点（主端点失败或熔断时依次切换）
+// - 按端点熔断（连续失败达到阈值后在冷却期内直接跳过）
+// - 部分结果（成功地址照常返回，失败地址单独给出错误）
+
+// RetryPolicy 定义单个端点上的重试策略。
+type RetryPolicy struct {
+	MaxAttempts int           // 单端点最多尝试次数（含首次），<=0 按 1 处理
+	BaseDelay   time.Duration // 首次重试前等待时间，之后按 2 倍递增
+	MaxDelay    time.Duration // 单次等待上限（0 表示不设上限）
+}
+
+// DefaultRetryPolicy 是默认重试策略：单端点最多 3 次，300ms 起步，最长等待 3s。
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 300 * time.Millisecond, MaxDelay: 3 * time.Second}
+
+func retryPolicyOrDefault(p *RetryPolicy) RetryPolicy {
+	if p == nil {
+		return DefaultRetryPolicy
+	}
+	return *p
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts <= 0 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// backoff 返回第 n 次重试（从 1 开始）前的等待时间。
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < n && d > 0; i++ {
+		d *= 2
+		if p.MaxDelay > 0 && d >= p.MaxDelay {
+			break
+		}
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// CircuitBreaker 按端点（RPC URL / API BaseURL）统计连续失败次数。
+//
+// 状态：
+// - closed：正常放行
+// - open：连续失败达到阈值，冷却期内直接跳过该端点
+// - half_open：冷却期结束，放行一次试探请求；成功则恢复 closed，失败则重新 open
+//
+// 同一个 CircuitBreaker 可在多个 Provider / 多次请求之间共享（并发安全）。
+type CircuitBreaker struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+
+	mu        sync.Mutex
+	failures  map[string]int
+	openUntil map[string]time.Time
+	now       func() time.Time
+}
+
+// 熔断器状态。
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// NewCircuitBreaker 创建熔断器；threshold<=0 时默认 5，cooldown<=0 时默认 30s。
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if cooldown <= 0 {
+		cooldown = 30 * time.Second
+	}
+	return &CircuitBreaker{
+		FailureThreshold: threshold,
+		Cooldown:         cooldown,
+		failures:         make(map[string]int),
+		openUntil:        make(map[string]time.Time),
+		now:              time.Now,
+	}
+}
+
+// Allow 判断端点当前是否允许发起请求（nil 熔断器总是放行）。
+func (b *CircuitBreaker) Allow(endpoint string) bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	until, ok := b.openUntil[endpoint]
+	if !ok {
+		return true
+	}
+	if b.now().Before(until) {
+		return false
+	}
+	// 冷却期结束：进入 half_open，只放行一次试探请求，直到 Success/Failure 更新状态。
+	b.openUntil[endpoint] = b.now().Add(b.Cooldown)
+	return true
+}
+
+// Success 记录一次成功（清零失败计数并关闭熔断）。
+func (b *CircuitBreaker) Success(endpoint string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.failures, endpoint)
+	delete(b.openUntil, endpoint)
+}
+
+// Failure 记录一次失败；连续失败达到阈值时打开熔断。
+func (b *CircuitBreaker) Failure(endpoint string) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures[endpoint]++
+	if b.failures[endpoint] >= b.FailureThreshold {
+		b.openUntil[endpoint] = b.now().Add(b.Cooldown)
+	}
+}
+
+// State 返回端点当前状态（closed/open/half_open）。
+func (b *CircuitBreaker) State(endpoint string) string {
+	if b == nil {
+		return BreakerClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	until, ok := b.openUntil[endpoint]
+	switch {
+	case !ok:
+		return BreakerClosed
+	case b.now().Before(until):
+		return BreakerOpen
+	default:
+		return BreakerHalfOpen
+	}
+}
+
+// AddressError 描述单个地址查询失败的原因（用于 UI 展示“哪些地址没查到”）。
+type AddressError struct {
+	Address  string   `json:"address"`
+	Error    string   `json:"error"`
+	Attempts int      `json:"attempts"`
+	Tried    []string `json:"tried_endpoints,omitempty"`
+	Skipped  []string `json:"skipped_endpoints,omitempty"` // 因熔断被跳过的端点
+}
+
+// PartialResult 是允许部分失败的批量查询结果。
+type PartialResult struct {
+	Balances map[string]map[string]string `json:"balances"`
+	Errors   map[string]AddressError      `json:"errors,omitempty"`
+	// Endpoints 记录每个成功地址实际使用的端点（便于判断是否切换到了备用节点）。
+	Endpoints map[string]string `json:"endpoints,omitempty"`
+}
+
+// Err 在存在失败地址时返回汇总错误（用于兼容“全部成功才算成功”的旧接口）。
+func (r *PartialResult) Err() error {
+	if r == nil || len(r.Errors) == 0 {
+		return nil
+	}
+	addrs := make([]string, 0, len(r.Errors))
+	for addr := range r.Errors {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	first := r.Errors[addrs[0]]
+	if len(addrs) == 1 {
+		return fmt.Errorf("query %s: %s", first.Address, first.Error)
+	}
+	return fmt.Errorf("query %d addresses failed (first %s: %s)", len(addrs), first.Address, first.Error)
+}
+
+// PartialProvider 是支持部分结果的 Provider（EVM/ERC20/BTC 均已实现）。
+type PartialProvider interface {
+	Provider
+	QueryBalancesPartial(ctx context.Context, addresses []string) (*PartialResult, error)
+}
+
+// permanentError 标记“重试无意义”的错误（例如地址格式错误、HTTP 4xx）。
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// httpStatusError 是上游返回非 2xx 时的错误（保留状态码以判断是否可重试）。
+type httpStatusError struct {
+	Prefix     string
+	StatusCode int
+	Body       string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("%s %d: %s", e.Prefix, e.StatusCode, e.Body)
+}
+
+// rpcCallError 是 JSON-RPC 返回的 error 对象。
+type rpcCallError struct {
+	Code    int
+	Message string
+}
+
+func (e *rpcCallError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// isRetryable 判断错误是否值得重试/切换端点。
+//
+// - 调用方 ctx 已取消/超时：不重试（调用方已放弃）
+// - 单次请求超时（http.Client.Timeout / 传输层超时，错误链上也可能是 context.DeadlineExceeded）：重试并计入熔断
+// - 显式标记的 permanentError：不重试
+// - HTTP 429/5xx：重试；其他 4xx：不重试
+// - JSON-RPC 参数类错误（-32600/-32602）：不重试
+// - 其他（网络错误、解析错误等）：重试
+func isRetryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var pe *permanentError
+	if errors.As(err, &pe) {
+		return false
+	}
+	var he *httpStatusError
+	if errors.As(err, &he) {
+		return he.StatusCode == 429 || he.StatusCode >= 500
+	}
+	var re *rpcCallError
+	if errors.As(err, &re) {
+		return re.Code != -32600 && re.Code != -32602
+	}
+	return true
+}
+
+// resilientQuery 是三类 Provider 共用的批量查询骨架。
+type resilientQuery struct {
+	Endpoints []string // 主端点在前，备用端点在后（已去重）
+	Retry     RetryPolicy
+	Breaker   *CircuitBreaker
+
+	// QueryOne 在指定端点上查询单个地址。
+	QueryOne func(ctx context.Context, endpoint, address string) (map[string]string, error)
+}
+
+// buildEndpoints 合并主端点与备用端点（去空、去重，保持顺序）。
+func buildEndpoints(primary string, fallbacks []string) []string {
+	seen := make(map[string]struct{}, len(fallbacks)+1)
+	out := make([]string, 0, len(fallbacks)+1)
+	for _, u := range append([]string{primary}, fallbacks...) {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+		if _, ok := seen[u]; ok {
+			continue
+		}
+		seen[u] = struct{}{}
+		out = append(out, u)
+	}
+	return out
+}
+
+func (q resilientQuery) run(ctx context.Context, addresses []string) (*PartialResult, error) {
+	if len(q.Endpoints) == 0 {
+		return nil, fmt.Errorf("no endpoint configured")
+	}
+	res := &PartialResult{
+		Balances:  make(map[string]map[string]string, len(addresses)),
+		Errors:    make(map[string]AddressError),
+		Endpoints: make(map[string]string, len(addresses)),
+	}
+	for _, addr := range addresses {
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			continue
+		}
+		if _, ok := res.Balances[addr]; ok {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			// 整体已取消：不再逐个记录失败，直接返回错误。
+			return nil, err
+		}
+		bal, endpoint, aerr := q.queryAddress(ctx, addr)
+		if aerr != nil {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			res.Errors[addr] = *aerr
+			continue
+		}
+		res.Balances[addr] = bal
+		res.Endpoints[addr] = endpoint
+	}
+	return res, nil
+}
+
+func (q resilientQuery) queryAddress(ctx context.Context, addr string) (map[string]string, string, *AddressError) {
+	aerr := &AddressError{Address: addr}
+	var lastErr error
+	for _, endpoint := range q.Endpoints {
+		if !q.Breaker.Allow(endpoint) {
+			aerr.Skipped = append(aerr.Skipped, endpoint)
+			continue
+		}
+		aerr.Tried = append(aerr.Tried, endpoint)
+
+		for attempt := 1; attempt <= q.Retry.attempts(); attempt++ {
+			if attempt > 1 {
+				if err := sleepCtx(ctx, q.Retry.backoff(attempt-1)); err != nil {
+					lastErr = err
+					break
+				}
+			}
+			aerr.Attempts++
+			bal, err := q.QueryOne(ctx, endpoint, addr)
+			if err == nil {
+				q.Breaker.Success(endpoint)
+				return bal, endpoint, nil
+			}
+			lastErr = err
+			if !isRetryable(ctx, err) {
+				break
+			}
+		}
+
+		var pe *permanentError
+		if errors.As(lastErr, &pe) {
+			// 地址本身有问题：换端点也没用，且不应计入端点失败。
+			break
+		}
+		if isRetryable(ctx, lastErr) {
+			// 只有“端点不可用”类错误计入熔断；4xx/参数错误换下一个端点再试即可。
+			q.Breaker.Failure(endpoint)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("all endpoints unavailable (circuit open)")
+	}
+	aerr.Error = lastErr.Error()
+	return nil, "", aerr
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
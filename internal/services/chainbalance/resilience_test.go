@@ -0,0 +1,139 @@
+package chainbalance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEVMProvider_QueryBalancesPartial_RetryFallbackAndBreaker(t *testing.T) {
+	t.Parallel()
+
+	// 主节点：0xA 第一次 502、第二次成功；0xBAD 永远 503。
+	var primaryCalls int32
+	var flakyOnce int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&primaryCalls, 1)
+		var req evmRPCReq
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		addr, _ := req.Params[0].(string)
+		if addr == "0xBAD" || (addr == "0xA" && atomic.AddInt32(&flakyOnce, 1) == 1) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": "0x1"})
+	}))
+	defer primary.Close()
+
+	// 备用节点：只认识 0xB（其余返回 400，不应重试）。
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req evmRPCReq
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		addr, _ := req.Params[0].(string)
+		if addr != "0xB" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": "0x2"})
+	}))
+	defer fallback.Close()
+
+	breaker := NewCircuitBreaker(1, time.Minute)
+	p := NewEVMProvider(primary.URL)
+	p.FallbackRPCURLs = []string{fallback.URL}
+	p.Retry = &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+	p.Breaker = breaker
+
+	// 0xBAD 让主节点连续失败并熔断；之后的 0xB 直接跳过主节点走备用节点。
+	res, err := p.QueryBalancesPartial(context.Background(), []string{"0xA", "0xBAD", "0xBAD2", "0xB"})
+	if err != nil {
+		t.Fatalf("QueryBalancesPartial: %v", err)
+	}
+	if res.Balances["0xA"]["WEI"] != "1" || res.Endpoints["0xA"] != primary.URL {
+		t.Fatalf("0xA should succeed on primary after retry, got %+v endpoint=%q", res.Balances["0xA"], res.Endpoints["0xA"])
+	}
+	if res.Balances["0xB"]["WEI"] != "2" || res.Endpoints["0xB"] != fallback.URL {
+		t.Fatalf("0xB should succeed on fallback, got %+v endpoint=%q", res.Balances["0xB"], res.Endpoints["0xB"])
+	}
+	e, ok := res.Errors["0xBAD"]
+	if !ok || e.Attempts != 3 || len(e.Tried) != 2 {
+		t.Fatalf("0xBAD: want 3 attempts over 2 endpoints, got %+v", e)
+	}
+	if breaker.State(primary.URL) != BreakerOpen {
+		t.Fatalf("primary breaker: want open, got %s", breaker.State(primary.URL))
+	}
+	if e2 := res.Errors["0xBAD2"]; len(e2.Skipped) != 1 || e2.Skipped[0] != primary.URL {
+		t.Fatalf("0xBAD2: want primary skipped by breaker, got %+v", e2)
+	}
+	if got := atomic.LoadInt32(&primaryCalls); got != 4 {
+		t.Fatalf("primary calls: want 4 (0xA x2 + 0xBAD x2), got %d", got)
+	}
+
+	// 旧接口保持“全部成功才算成功”的语义。
+	if _, err := p.QueryBalances(context.Background(), []string{"0xB", "0xBAD"}); err == nil {
+		t.Fatalf("QueryBalances: want error when an address fails")
+	}
+}
+
+func TestEVMProvider_QueryBalancesPartial_StalledNodeTimeout(t *testing.T) {
+	t.Parallel()
+
+	// 主节点卡住不响应：单次请求靠 http.Client.Timeout 结束，应重试、计入熔断并切到备用节点。
+	release := make(chan struct{})
+	var stalledCalls int32
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&stalledCalls, 1)
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer stalled.Close()
+	defer close(release)
+
+	fallback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req evmRPCReq
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": "0x3"})
+	}))
+	defer fallback.Close()
+
+	breaker := NewCircuitBreaker(1, time.Minute)
+	p := NewEVMProvider(stalled.URL)
+	p.FallbackRPCURLs = []string{fallback.URL}
+	p.Retry = &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}
+	p.Breaker = breaker
+	p.HTTPClient = &http.Client{Timeout: 50 * time.Millisecond}
+
+	res, err := p.QueryBalancesPartial(context.Background(), []string{"0xA", "0xB"})
+	if err != nil {
+		t.Fatalf("QueryBalancesPartial: %v", err)
+	}
+	for _, addr := range []string{"0xA", "0xB"} {
+		if res.Balances[addr]["WEI"] != "3" || res.Endpoints[addr] != fallback.URL {
+			t.Fatalf("%s should succeed on fallback, got %+v endpoint=%q errors=%+v", addr, res.Balances[addr], res.Endpoints[addr], res.Errors)
+		}
+	}
+	if breaker.State(stalled.URL) != BreakerOpen {
+		t.Fatalf("stalled node breaker: want open, got %s", breaker.State(stalled.URL))
+	}
+	if got := atomic.LoadInt32(&stalledCalls); got != 2 {
+		t.Fatalf("stalled node calls: want 2 (0xA retried once, 0xB skipped by breaker), got %d", got)
+	}
+
+	// 调用方自己取消时不重试、不计入熔断。
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b2 := NewCircuitBreaker(1, time.Minute)
+	p.Breaker = b2
+	if _, err := p.QueryBalancesPartial(ctx, []string{"0xA"}); err == nil {
+		t.Fatalf("canceled context: want error")
+	}
+	if b2.State(stalled.URL) != BreakerClosed {
+		t.Fatalf("caller cancellation must not open breaker, got %s", b2.State(stalled.URL))
+	}
+}
@@ -0,0 +1,158 @@
+package chainbalance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// 稳定币预置清单（token registry）
+//
+// 手工填写合约地址/decimals 容易出错（同名代币在不同链上 decimals 也不同，例如 BSC 上 USDT 为 18 位）。
+// 这里内置常用稳定币在主流链上的合约，ERC20 余额接口可按 symbol + chain 直接选择；
+// 清单版本会写入留痕证据，便于事后确认“当时用的是哪份清单”。
+//
+// TRON 的 TRC20 与 ERC20 ABI 兼容，可通过 TronGrid 的 JSON-RPC（eth_call）查询，
+// 地址需先从 base58（T...）转换为 20 字节十六进制。
+
+// TokenRegistryVersion 是内置清单版本；修改清单内容时需同步递增。
+const TokenRegistryVersion = "1.0.0"
+
+// 支持的链标识。
+const (
+	ChainEthereum = "ethereum"
+	ChainBSC      = "bsc"
+	ChainPolygon  = "polygon"
+	ChainTron     = "tron"
+)
+
+// TokenPreset 是一条预置代币。
+type TokenPreset struct {
+	Chain    string `json:"chain"`
+	Symbol   string `json:"symbol"`
+	Name     string `json:"name"`
+	Contract string `json:"contract"` // EVM 链为 0x 地址；TRON 为 base58 地址
+	Decimals int    `json:"decimals"`
+}
+
+var tokenRegistry = []TokenPreset{
+	{Chain: ChainEthereum, Symbol: "USDT", Name: "Tether USD", Contract: "0xdAC17F958D2ee523a2206206994597C13D831ec7", Decimals: 6},
+	{Chain: ChainEthereum, Symbol: "USDC", Name: "USD Coin", Contract: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6},
+	{Chain: ChainEthereum, Symbol: "DAI", Name: "Dai Stablecoin", Contract: "0x6B175474E89094C44Da98b954EedeAC495271d0F", Decimals: 18},
+	{Chain: ChainEthereum, Symbol: "BUSD", Name: "Binance USD", Contract: "0x4Fabb145d64652a948d72533023f6E7A623C7C53", Decimals: 18},
+
+	{Chain: ChainBSC, Symbol: "USDT", Name: "Binance-Peg BSC-USD", Contract: "0x55d398326f99059fF775485246999027B3197955", Decimals: 18},
+	{Chain: ChainBSC, Symbol: "USDC", Name: "Binance-Peg USD Coin", Contract: "0x8AC76a51cc950d9822D68b83fE1Ad97B32Cd580d", Decimals: 18},
+	{Chain: ChainBSC, Symbol: "DAI", Name: "Binance-Peg Dai Token", Contract: "0x1AF3F329e8BE154074D8769D1FFa4eE058B1DBc3", Decimals: 18},
+	{Chain: ChainBSC, Symbol: "BUSD", Name: "Binance-Peg BUSD Token", Contract: "0xe9e7CEA3DedcA5984780Bafc599bD69ADd087D56", Decimals: 18},
+
+	{Chain: ChainPolygon, Symbol: "USDT", Name: "(PoS) Tether USD", Contract: "0xc2132D05D31c914a87C6611C10748AEb04B58e8F", Decimals: 6},
+	{Chain: ChainPolygon, Symbol: "USDC", Name: "USD Coin", Contract: "0x3c499c542cEF5E3811e1192ce70d8cC03d5c3359", Decimals: 6},
+	{Chain: ChainPolygon, Symbol: "USDC.E", Name: "USD Coin (PoS, bridged)", Contract: "0x2791Bca1f2de4661ED88A30C99A7a9449Aa84174", Decimals: 6},
+	{Chain: ChainPolygon, Symbol: "DAI", Name: "(PoS) Dai Stablecoin", Contract: "0x8f3Cf7ad23Cd3CaDbD9735AFf958023239c6A063", Decimals: 18},
+	{Chain: ChainPolygon, Symbol: "BUSD", Name: "Binance-Peg BUSD Token", Contract: "0xdAb529f40E671A1D4bF91361c21bf9f0C9712ab7", Decimals: 18},
+
+	{Chain: ChainTron, Symbol: "USDT", Name: "Tether USD", Contract: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", Decimals: 6},
+	{Chain: ChainTron, Symbol: "USDC", Name: "USD Coin", Contract: "TEkxiTehnzSmSe2XqrBj4w32RUN966rdz8", Decimals: 6},
+	{Chain: ChainTron, Symbol: "BUSD", Name: "Binance USD", Contract: "TMz2SWatiAtZVVcH2ebpsbVtYwUPT9EdjH", Decimals: 18},
+}
+
+// defaultChainRPC 是各链默认公共 RPC（内部试用，不保证长期可用）。
+var defaultChainRPC = map[string]string{
+	ChainEthereum: DefaultPublicEVMRPC,
+	ChainBSC:      "https://bsc-dataseed.binance.org",
+	ChainPolygon:  "https://polygon-rpc.com",
+	ChainTron:     "https://api.trongrid.io/jsonrpc",
+}
+
+// NormalizeChain 把常见别名归一为链标识（未知链返回空串）。
+func NormalizeChain(chain string) string {
+	switch strings.ToLower(strings.TrimSpace(chain)) {
+	case "ethereum", "eth", "erc20", "mainnet":
+		return ChainEthereum
+	case "bsc", "bnb", "bep20", "binance":
+		return ChainBSC
+	case "polygon", "matic", "pol":
+		return ChainPolygon
+	case "tron", "trx", "trc20":
+		return ChainTron
+	default:
+		return ""
+	}
+}
+
+// LookupToken 按链 + 符号查找预置代币（大小写不敏感）。
+func LookupToken(chain, symbol string) (TokenPreset, bool) {
+	c := NormalizeChain(chain)
+	sym := strings.ToUpper(strings.TrimSpace(symbol))
+	for _, t := range tokenRegistry {
+		if t.Chain == c && t.Symbol == sym {
+			return t, true
+		}
+	}
+	return TokenPreset{}, false
+}
+
+// ListTokens 返回预置代币（chain 为空时返回全部），按链、符号排序。
+func ListTokens(chain string) []TokenPreset {
+	c := ""
+	if strings.TrimSpace(chain) != "" {
+		c = NormalizeChain(chain)
+	}
+	out := []TokenPreset{}
+	for _, t := range tokenRegistry {
+		if c == "" || t.Chain == c {
+			out = append(out, t)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Chain != out[j].Chain {
+			return out[i].Chain < out[j].Chain
+		}
+		return out[i].Symbol < out[j].Symbol
+	})
+	return out
+}
+
+// DefaultRPCForChain 返回链的默认公共 RPC（未知链返回空串）。
+func DefaultRPCForChain(chain string) string {
+	return defaultChainRPC[NormalizeChain(chain)]
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// TronToEVMHex 把 TRON base58check 地址（T...）转换为 0x 开头的 20 字节十六进制地址；
+// 已是 0x/41 开头的十六进制地址时直接规范化返回。
+func TronToEVMHex(addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	lower := strings.ToLower(addr)
+	switch {
+	case strings.HasPrefix(lower, "0x") && len(lower) == 42:
+		return lower, nil
+	case strings.HasPrefix(lower, "41") && len(lower) == 42:
+		return "0x" + lower[2:], nil
+	}
+
+	n := new(big.Int)
+	for _, r := range addr {
+		i := strings.IndexRune(base58Alphabet, r)
+		if i < 0 {
+			return "", fmt.Errorf("invalid tron address: %s", addr)
+		}
+		n.Mul(n, big.NewInt(58))
+		n.Add(n, big.NewInt(int64(i)))
+	}
+	raw := n.Bytes()
+	if len(raw) != 25 || raw[0] != 0x41 {
+		return "", fmt.Errorf("invalid tron address: %s", addr)
+	}
+	h1 := sha256.Sum256(raw[:21])
+	h2 := sha256.Sum256(h1[:])
+	if string(h2[:4]) != string(raw[21:]) {
+		return "", fmt.Errorf("invalid tron address checksum: %s", addr)
+	}
+	return "0x" + hex.EncodeToString(raw[1:21]), nil
+}
@@ -0,0 +1,81 @@
+package chainbalance
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTronToEVMHex(t *testing.T) {
+	got, err := TronToEVMHex("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t")
+	if err != nil {
+		t.Fatalf("TronToEVMHex: %v", err)
+	}
+	if got != "0xa614f803b6fd780986a42c78ec9c7f77e6ded13c" {
+		t.Fatalf("got=%s", got)
+	}
+	if got, _ := TronToEVMHex("41A614F803B6FD780986A42C78EC9C7F77E6DED13C"); got != "0xa614f803b6fd780986a42c78ec9c7f77e6ded13c" {
+		t.Fatalf("hex passthrough=%s", got)
+	}
+	// 改动最后一位，校验和应失败。
+	if _, err := TronToEVMHex("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u"); err == nil {
+		t.Fatalf("expected checksum error")
+	}
+}
+
+func TestLookupToken(t *testing.T) {
+	p, ok := LookupToken("bep20", "usdt")
+	if !ok || p.Chain != ChainBSC || p.Decimals != 18 {
+		t.Fatalf("bsc usdt=%+v ok=%v", p, ok)
+	}
+	if p, ok := LookupToken("eth", "USDC"); !ok || p.Decimals != 6 {
+		t.Fatalf("eth usdc=%+v ok=%v", p, ok)
+	}
+	if _, ok := LookupToken("tron", "DAI"); ok {
+		t.Fatalf("tron DAI should not be in registry")
+	}
+	for _, p := range ListTokens("") {
+		if p.Chain == ChainTron {
+			if _, err := TronToEVMHex(p.Contract); err != nil {
+				t.Fatalf("tron preset %s: %v", p.Symbol, err)
+			}
+		}
+	}
+	if DefaultRPCForChain("matic") == "" {
+		t.Fatalf("missing polygon default rpc")
+	}
+}
+
+func TestERC20Provider_TronAddresses(t *testing.T) {
+	holder := "41000000000000000000000000000000000000dead"
+	wantData, _ := encodeERC20BalanceOf("0x000000000000000000000000000000000000dead")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req evmRPCReq
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		callObj, _ := req.Params[0].(map[string]any)
+		if callObj["to"] != "0xa614f803b6fd780986a42c78ec9c7f77e6ded13c" {
+			t.Fatalf("to=%v", callObj["to"])
+		}
+		if callObj["data"] != wantData {
+			t.Fatalf("data=%v", callObj["data"])
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x0f4240"}`))
+	}))
+	defer srv.Close()
+
+	p := NewERC20Provider(srv.URL)
+	p.Chain = "trc20"
+	p.Symbol = "USDT"
+	p.Contract = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	p.Decimals = 6
+	out, err := p.QueryBalances(context.Background(), []string{holder})
+	if err != nil {
+		t.Fatalf("QueryBalances: %v", err)
+	}
+	if out[holder]["USDT"] != "1" {
+		t.Fatalf("USDT=%s", out[holder]["USDT"])
+	}
+}
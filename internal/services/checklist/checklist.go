@@ -0,0 +1,250 @@
+package checklist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+)
+
+// 案件侦查清单
+//
+// 每个案件按生效模板生成一份清单（主机已扫描、手机已扫描、余额已查询、报告已签名等），逐项记录
+// 完成状态、负责人、完成人与说明：
+//   - 满足 auto 条件的清单项在查看时自动标记完成，完成人记为 system，说明中写明依据
+//   - 不适用的清单项可标记为 not_applicable，但必须填写说明（例如“案件未扣押手机”）
+//   - external profile 结案前，required 清单项必须全部为 done 或 not_applicable；internal profile 只在审计中记录未完成项
+// 清单的每次修改与结案都写审计。
+
+// 结案 profile（与扫描 profile 含义一致）。
+const (
+	ProfileInternal = "internal"
+	ProfileExternal = "external"
+)
+
+// Get 按生效模板同步并返回案件清单（模板中已删除且尚未完成的清单项不再返回）。
+func Get(ctx context.Context, store *sqliteadapter.Store, caseID string) ([]model.ChecklistItem, error) {
+	ov, err := store.GetCaseOverview(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if ov == nil {
+		return nil, apperr.T(apperr.CodeNotFound, "case.not_found", caseID)
+	}
+	cfg, err := Load(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	defs := make([]model.ChecklistItem, 0, len(cfg.Items))
+	inTemplate := map[string]bool{}
+	for i, it := range cfg.Items {
+		defs = append(defs, model.ChecklistItem{ItemID: it.ID, Label: it.Label, Required: it.Required, Auto: it.Auto, Position: i})
+		inTemplate[it.ID] = true
+	}
+	if err := store.SyncChecklistItems(ctx, caseID, defs); err != nil {
+		return nil, err
+	}
+	rows, err := store.ListChecklistItems(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]model.ChecklistItem, 0, len(rows))
+	for _, it := range rows {
+		if !inTemplate[it.ItemID] && it.Status == model.ChecklistPending {
+			continue
+		}
+		if it.Status == model.ChecklistPending && it.Auto != "" {
+			evidence, err := autoSatisfied(ctx, store, caseID, it.Auto)
+			if err != nil {
+				return nil, err
+			}
+			if evidence != "" {
+				updated, err := store.UpdateChecklistItem(ctx, caseID, it.ItemID, model.ChecklistDone, it.Responsible, "system", "auto: "+evidence)
+				if err != nil {
+					return nil, err
+				}
+				if updated != nil {
+					_ = store.AppendAudit(ctx, caseID, "", "checklist", "auto_complete", "success", "system", "checklist.Get", map[string]any{
+						"item_id":  it.ItemID,
+						"auto":     it.Auto,
+						"evidence": evidence,
+					})
+					it = *updated
+				}
+			}
+		}
+		out = append(out, it)
+	}
+	return out, nil
+}
+
+// autoSatisfied 检查 auto 条件；满足时返回依据说明，否则返回空串。
+func autoSatisfied(ctx context.Context, store *sqliteadapter.Store, caseID, auto string) (string, error) {
+	switch auto {
+	case AutoHostScan, AutoMobileScan:
+		devices, err := store.ListCaseDevices(ctx, caseID)
+		if err != nil {
+			return "", err
+		}
+		for _, d := range devices {
+			mobile := d.OSType == string(model.OSAndroid) || d.OSType == string(model.OSIOS) || d.OSType == string(model.OSHarmonyOS)
+			if mobile == (auto == AutoMobileScan) {
+				return fmt.Sprintf("device %s (%s)", d.DeviceID, d.OSType), nil
+			}
+		}
+	case AutoBalanceQuery:
+		hits, err := store.ListCaseHitDetails(ctx, caseID, string(model.HitTokenBalance))
+		if err != nil {
+			return "", err
+		}
+		if len(hits) > 0 {
+			return fmt.Sprintf("%d token_balance hits", len(hits)), nil
+		}
+	case AutoForensicExport:
+		reports, err := store.ListReportsByCase(ctx, caseID)
+		if err != nil {
+			return "", err
+		}
+		for _, r := range reports {
+			switch r.ReportType {
+			case "forensic_pdf", "forensic_zip", "disclosure_zip":
+				return fmt.Sprintf("report %s (%s)", r.ReportID, r.ReportType), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// Update 是一次清单项修改。
+type Update struct {
+	Status      string // pending|done|not_applicable；为空时保持原状态
+	Responsible string // 负责人；为空时保持原负责人
+	Note        string
+	Operator    string
+}
+
+// SetItem 修改清单项的状态/负责人并写审计。
+func SetItem(ctx context.Context, store *sqliteadapter.Store, caseID, itemID string, u Update) (*model.ChecklistItem, error) {
+	items, err := Get(ctx, store, caseID)
+	if err != nil {
+		return nil, err
+	}
+	var cur *model.ChecklistItem
+	for i := range items {
+		if items[i].ItemID == itemID {
+			cur = &items[i]
+			break
+		}
+	}
+	if cur == nil {
+		return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("checklist item not found: %s", itemID))
+	}
+
+	operator := strings.TrimSpace(u.Operator)
+	if operator == "" {
+		operator = "system"
+	}
+	status := strings.TrimSpace(u.Status)
+	if status == "" {
+		status = cur.Status
+	}
+	switch status {
+	case model.ChecklistPending, model.ChecklistDone, model.ChecklistNotApplicable:
+	default:
+		return nil, apperr.T(apperr.CodeInvalidArgument, "casemeta.invalid_status", u.Status, model.ChecklistPending, model.ChecklistDone, model.ChecklistNotApplicable)
+	}
+	note := strings.TrimSpace(u.Note)
+	if note == "" && status == cur.Status {
+		note = cur.Note
+	}
+	if status == model.ChecklistNotApplicable && note == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "note is required when marking an item not_applicable")
+	}
+	responsible := strings.TrimSpace(u.Responsible)
+	if responsible == "" {
+		responsible = cur.Responsible
+	}
+	completedBy := operator
+	if status == cur.Status && cur.CompletedBy != "" {
+		completedBy = cur.CompletedBy
+	}
+
+	updated, err := store.UpdateChecklistItem(ctx, caseID, itemID, status, responsible, completedBy, note)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("checklist item not found: %s", itemID))
+	}
+	_ = store.AppendAudit(ctx, caseID, "", "checklist", "update", "success", operator, "checklist.SetItem", map[string]any{
+		"item_id":     itemID,
+		"from_status": cur.Status,
+		"status":      updated.Status,
+		"responsible": updated.Responsible,
+		"note":        updated.Note,
+	})
+	return updated, nil
+}
+
+// Pending 返回尚未完成的 required 清单项。
+func Pending(items []model.ChecklistItem) []model.ChecklistItem {
+	var out []model.ChecklistItem
+	for _, it := range items {
+		if it.Required && it.Status == model.ChecklistPending {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+// CloseResult 是结案结果。
+type CloseResult struct {
+	CaseID  string                `json:"case_id"`
+	Status  string                `json:"status"`
+	Profile string                `json:"profile"`
+	Pending []model.ChecklistItem `json:"pending,omitempty"` // internal profile 下结案时仍未完成的 required 清单项
+}
+
+// Close 结案：external profile 要求 required 清单项全部完成，否则返回 ERR_CHECKLIST_INCOMPLETE 且不修改案件状态。
+func Close(ctx context.Context, store *sqliteadapter.Store, caseID, profile, operator string) (*CloseResult, error) {
+	profile = strings.ToLower(strings.TrimSpace(profile))
+	if profile == "" {
+		profile = ProfileInternal
+	}
+	if profile != ProfileInternal && profile != ProfileExternal {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid profile: %s (expect internal|external)", profile))
+	}
+	operator = strings.TrimSpace(operator)
+	if operator == "" {
+		operator = "system"
+	}
+	items, err := Get(ctx, store, caseID)
+	if err != nil {
+		return nil, err
+	}
+	pending := Pending(items)
+	pendingIDs := make([]string, 0, len(pending))
+	for _, it := range pending {
+		pendingIDs = append(pendingIDs, it.ItemID)
+	}
+	if profile == ProfileExternal && len(pending) > 0 {
+		_ = store.AppendAudit(ctx, caseID, "", "case", "close", "failed", operator, "checklist.Close", map[string]any{
+			"profile":       profile,
+			"error_code":    apperr.CodeChecklistIncomplete,
+			"pending_items": pendingIDs,
+		})
+		return nil, apperr.T(apperr.CodeChecklistIncomplete, "checklist.incomplete", strings.Join(pendingIDs, ", "))
+	}
+	if _, err := store.SetCaseStatus(ctx, caseID, "closed"); err != nil {
+		return nil, err
+	}
+	_ = store.AppendAudit(ctx, caseID, "", "case", "close", "success", operator, "checklist.Close", map[string]any{
+		"profile":       profile,
+		"pending_items": pendingIDs,
+	})
+	return &CloseResult{CaseID: caseID, Status: "closed", Profile: profile, Pending: pending}, nil
+}
@@ -0,0 +1,83 @@
+package checklist
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestChecklistGatesExternalClose(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "host", OS: model.OSWindows, Identifier: "h"}, true, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	items, err := Get(ctx, store, caseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 4 || items[0].ItemID != "host_scan_done" || items[0].Status != model.ChecklistDone || items[0].CompletedBy != "system" {
+		t.Fatalf("items=%+v", items)
+	}
+	if items[1].Status != model.ChecklistPending {
+		t.Fatalf("phones_scanned should stay pending without a mobile device: %+v", items[1])
+	}
+
+	if _, err := Close(ctx, store, caseID, ProfileExternal, "alice"); apperr.CodeOf(err) != apperr.CodeChecklistIncomplete {
+		t.Fatalf("external close err=%v", err)
+	}
+	if _, err := SetItem(ctx, store, caseID, "phones_scanned", Update{Status: model.ChecklistNotApplicable, Operator: "alice"}); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("not_applicable without note err=%v", err)
+	}
+	for _, u := range []struct {
+		id string
+		u  Update
+	}{
+		{"phones_scanned", Update{Status: model.ChecklistNotApplicable, Note: "案件未扣押手机", Operator: "alice"}},
+		{"balances_queried", Update{Status: model.ChecklistDone, Responsible: "bob", Operator: "bob"}},
+		{"export_signed", Update{Status: model.ChecklistDone, Operator: "alice"}},
+	} {
+		if _, err := SetItem(ctx, store, caseID, u.id, u.u); err != nil {
+			t.Fatalf("set %s: %v", u.id, err)
+		}
+	}
+
+	res, err := Close(ctx, store, caseID, ProfileExternal, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ov, err := store.GetCaseOverview(ctx, caseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Status != "closed" || len(res.Pending) != 0 || ov.Status != "closed" {
+		t.Fatalf("close=%+v overview status=%s", res, ov.Status)
+	}
+}
+
+func TestParseRejectsUnknownAuto(t *testing.T) {
+	if _, err := Parse([]byte("bundle_type: case_checklist\nitems:\n  - id: x\n    label: X\n    auto: magic\n")); err == nil {
+		t.Fatal("expected error for unknown auto condition")
+	}
+}
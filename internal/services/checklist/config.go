@@ -0,0 +1,161 @@
+package checklist
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 清单模板
+//
+// 生效模板以 YAML 文本存放在 schema_meta（key 见 SchemaKeyConfig），未配置时使用内置默认模板（DefaultConfig）。
+// 每个清单项可声明 auto 条件，案件数据满足条件时自动标记完成（完成人记为 system）：
+//   - host_scan      ：案件已有主机设备（windows/macos/linux）
+//   - mobile_scan    ：案件已有手机设备（android/ios/harmonyos）
+//   - balance_query  ：案件已有链上余额查询结果（token_balance 命中）
+//   - forensic_export：案件已生成对外交付报告（forensic_pdf / forensic_zip / disclosure_zip）
+// 报告签名等线下动作没有可检测的痕迹，只能手工勾选。
+
+// SchemaKeyConfig 是 schema_meta 中保存清单模板 YAML 的 key。
+const SchemaKeyConfig = "case_checklist_yaml"
+
+// BundleType 是模板文件的 bundle_type。
+const BundleType = "case_checklist"
+
+// 自动完成条件。
+const (
+	AutoHostScan       = "host_scan"
+	AutoMobileScan     = "mobile_scan"
+	AutoBalanceQuery   = "balance_query"
+	AutoForensicExport = "forensic_export"
+)
+
+var knownAuto = map[string]bool{
+	AutoHostScan:       true,
+	AutoMobileScan:     true,
+	AutoBalanceQuery:   true,
+	AutoForensicExport: true,
+}
+
+var reItemID = regexp.MustCompile(`^[a-z0-9][a-z0-9_]{0,63}$`)
+
+// ItemDef 是模板中的一个清单项。
+type ItemDef struct {
+	ID       string `yaml:"id" json:"id"`
+	Label    string `yaml:"label" json:"label"`
+	Required bool   `yaml:"required" json:"required"`
+	Auto     string `yaml:"auto,omitempty" json:"auto,omitempty"`
+}
+
+// Config 是完整的清单模板。
+type Config struct {
+	Version    string    `yaml:"version" json:"version"`
+	BundleType string    `yaml:"bundle_type" json:"bundle_type"`
+	Items      []ItemDef `yaml:"items" json:"items"`
+}
+
+// DefaultConfig 返回内置默认模板。
+func DefaultConfig() *Config {
+	return &Config{
+		Version:    "builtin",
+		BundleType: BundleType,
+		Items: []ItemDef{
+			{ID: "host_scan_done", Label: "主机扫描完成", Required: true, Auto: AutoHostScan},
+			{ID: "phones_scanned", Label: "手机扫描完成", Required: true, Auto: AutoMobileScan},
+			{ID: "balances_queried", Label: "链上余额已查询", Required: true, Auto: AutoBalanceQuery},
+			{ID: "export_signed", Label: "报告已导出并签名", Required: true},
+		},
+	}
+}
+
+// Parse 解析并校验模板 YAML。
+func Parse(raw []byte) (*Config, error) {
+	var c Config
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("parse case checklist: %w", err)
+	}
+	for i := range c.Items {
+		c.Items[i].ID = strings.TrimSpace(c.Items[i].ID)
+		c.Items[i].Label = strings.TrimSpace(c.Items[i].Label)
+		c.Items[i].Auto = strings.ToLower(strings.TrimSpace(c.Items[i].Auto))
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// ParseFile 读取并解析模板文件。
+func ParseFile(path string) (*Config, []byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read case checklist: %w", err)
+	}
+	c, err := Parse(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, raw, nil
+}
+
+// Validate 校验 bundle_type、清单项 ID 与 auto 条件。
+func (c *Config) Validate() error {
+	if strings.TrimSpace(c.BundleType) != BundleType {
+		return fmt.Errorf("case checklist bundle_type must be %q", BundleType)
+	}
+	if len(c.Items) == 0 {
+		return fmt.Errorf("case checklist items is empty")
+	}
+	seen := map[string]bool{}
+	for i, it := range c.Items {
+		if !reItemID.MatchString(it.ID) {
+			return fmt.Errorf("items[%d].id must match %s", i, reItemID.String())
+		}
+		if seen[it.ID] {
+			return fmt.Errorf("items[%d]: duplicate id %s", i, it.ID)
+		}
+		seen[it.ID] = true
+		if it.Label == "" {
+			return fmt.Errorf("items[%d].label is required", i)
+		}
+		if it.Auto != "" && !knownAuto[it.Auto] {
+			return fmt.Errorf("items[%d].auto: unknown condition %q (want %s|%s|%s|%s)", i, it.Auto, AutoHostScan, AutoMobileScan, AutoBalanceQuery, AutoForensicExport)
+		}
+	}
+	return nil
+}
+
+// Load 读取生效模板；未配置时返回内置默认模板。
+func Load(ctx context.Context, store *sqliteadapter.Store) (*Config, error) {
+	raw, err := store.GetSchemaMetaValue(ctx, SchemaKeyConfig)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(raw) == "" {
+		return DefaultConfig(), nil
+	}
+	return Parse([]byte(raw))
+}
+
+// Save 校验并保存模板 YAML 为生效模板。
+func Save(ctx context.Context, store *sqliteadapter.Store, raw []byte) (*Config, error) {
+	c, err := Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.UpsertSchemaMetaValue(ctx, SchemaKeyConfig, string(raw)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reset 清除生效模板（恢复内置默认模板）。
+func Reset(ctx context.Context, store *sqliteadapter.Store) error {
+	return store.UpsertSchemaMetaValue(ctx, SchemaKeyConfig, "")
+}
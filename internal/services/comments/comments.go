@@ -0,0 +1,120 @@
+package comments
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+)
+
+// 分析评论
+//
+// 复核讨论直接挂在证据/命中上，避免散落在外部聊天工具里：
+// - 每条评论记录作者、时间与正文，只追加不修改/删除
+// - 回复统一挂到主题首条评论下（只有一层），回复的回复也归入同一主题
+// - 评论属于内部复核材料：取证 PDF 可选收录（--include-comments），对外披露包不收录
+
+// 评论目标类型。
+const (
+	TargetArtifact = "artifact"
+	TargetHit      = "hit"
+)
+
+// MaxTextLen 限制单条评论长度（字符）。
+const MaxTextLen = 4000
+
+// Add 校验并写入一条评论。
+func Add(ctx context.Context, store *sqliteadapter.Store, c model.Comment) (*model.Comment, error) {
+	c.TargetType = strings.TrimSpace(c.TargetType)
+	c.TargetID = strings.TrimSpace(c.TargetID)
+	c.ParentID = strings.TrimSpace(c.ParentID)
+	c.Author = strings.TrimSpace(c.Author)
+	c.Text = strings.TrimSpace(c.Text)
+	if c.Author == "" {
+		c.Author = "system"
+	}
+	if c.Text == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "comment text is required")
+	}
+	if n := len([]rune(c.Text)); n > MaxTextLen {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("comment too long: %d chars (max %d)", n, MaxTextLen))
+	}
+
+	if c.ParentID != "" {
+		parent, err := store.GetComment(ctx, c.CaseID, c.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent == nil {
+			return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("parent comment not found in case: %s", c.ParentID))
+		}
+		// 回复继承父评论的目标；显式传入且不一致时拒绝，避免回复串到别的证据/命中下。
+		if (c.TargetType != "" && c.TargetType != parent.TargetType) || (c.TargetID != "" && c.TargetID != parent.TargetID) {
+			return nil, apperr.New(apperr.CodeInvalidArgument, "reply target does not match parent comment")
+		}
+		c.TargetType, c.TargetID = parent.TargetType, parent.TargetID
+		if parent.ParentID != "" {
+			c.ParentID = parent.ParentID
+		}
+	} else if err := checkTarget(ctx, store, c.CaseID, c.TargetType, c.TargetID); err != nil {
+		return nil, err
+	}
+
+	return store.AddComment(ctx, c)
+}
+
+// checkTarget 校验评论目标存在且属于该案件。
+func checkTarget(ctx context.Context, store *sqliteadapter.Store, caseID, targetType, targetID string) error {
+	if targetID == "" {
+		return apperr.New(apperr.CodeInvalidArgument, "target_id is required")
+	}
+	switch targetType {
+	case TargetArtifact:
+		info, err := store.GetArtifactInfo(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		if info == nil || info.CaseID != caseID {
+			return apperr.New(apperr.CodeNotFound, fmt.Sprintf("artifact not found in case: %s", targetID))
+		}
+	case TargetHit:
+		ok, err := store.HitInCase(ctx, caseID, targetID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return apperr.New(apperr.CodeNotFound, fmt.Sprintf("hit not found in case: %s", targetID))
+		}
+	default:
+		return apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unsupported target_type: %q (want artifact|hit)", targetType))
+	}
+	return nil
+}
+
+// Key 返回评论目标的分组键（target_type/target_id）。
+func Key(targetType, targetID string) string {
+	return targetType + "/" + targetID
+}
+
+// Group 按目标分组；组内保持原顺序，回复紧跟在所属主题之后。
+func Group(rows []model.Comment) map[string][]model.Comment {
+	replies := map[string][]model.Comment{}
+	for _, c := range rows {
+		if c.ParentID != "" {
+			replies[c.ParentID] = append(replies[c.ParentID], c)
+		}
+	}
+	out := map[string][]model.Comment{}
+	for _, c := range rows {
+		if c.ParentID != "" {
+			continue
+		}
+		k := Key(c.TargetType, c.TargetID)
+		out[k] = append(out[k], c)
+		out[k] = append(out[k], replies[c.CommentID]...)
+	}
+	return out
+}
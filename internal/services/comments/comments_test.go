@@ -0,0 +1,97 @@
+package comments
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/manualhit"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestAddThreads(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatalf("EnsureCase: %v", err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "host", OS: model.OSType("windows"), Identifier: "h"}, true, ""); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+	hit, err := manualhit.Create(ctx, store, manualhit.Input{
+		CaseID: caseID, Value: "0xabc", Justification: "note", Operator: "alice",
+		AttachmentName: "a.txt", Attachment: []byte("x"), EvidenceRoot: filepath.Join(dir, "evidence"),
+	})
+	if err != nil {
+		t.Fatalf("manualhit.Create: %v", err)
+	}
+
+	root, err := Add(ctx, store, model.Comment{CaseID: caseID, TargetType: TargetHit, TargetID: hit.HitID, Author: "alice", Text: " is this the suspect's address? "})
+	if err != nil {
+		t.Fatalf("Add root: %v", err)
+	}
+	reply, err := Add(ctx, store, model.Comment{CaseID: caseID, ParentID: root.CommentID, Author: "bob", Text: "yes, seen on two devices"})
+	if err != nil {
+		t.Fatalf("Add reply: %v", err)
+	}
+	if reply.TargetType != TargetHit || reply.TargetID != hit.HitID || reply.ParentID != root.CommentID {
+		t.Fatalf("reply=%+v", reply)
+	}
+	// 回复的回复归入同一主题。
+	nested, err := Add(ctx, store, model.Comment{CaseID: caseID, ParentID: reply.CommentID, Text: "ok"})
+	if err != nil {
+		t.Fatalf("Add nested: %v", err)
+	}
+	if nested.ParentID != root.CommentID || nested.Author != "system" {
+		t.Fatalf("nested=%+v", nested)
+	}
+	if _, err := Add(ctx, store, model.Comment{CaseID: caseID, TargetType: TargetArtifact, TargetID: hit.ArtifactID, Text: "photo is blurry"}); err != nil {
+		t.Fatalf("Add artifact comment: %v", err)
+	}
+
+	for _, bad := range []model.Comment{
+		{CaseID: caseID, TargetType: TargetHit, TargetID: hit.HitID, Text: "  "},
+		{CaseID: caseID, TargetType: "device", TargetID: "dev_1", Text: "x"},
+		{CaseID: caseID, ParentID: root.CommentID, TargetType: TargetArtifact, TargetID: hit.ArtifactID, Text: "x"},
+	} {
+		if _, err := Add(ctx, store, bad); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+			t.Fatalf("Add(%+v) err=%v", bad, err)
+		}
+	}
+	if _, err := Add(ctx, store, model.Comment{CaseID: caseID, TargetType: TargetHit, TargetID: "hit_missing", Text: "x"}); apperr.CodeOf(err) != apperr.CodeNotFound {
+		t.Fatalf("missing hit err=%v", err)
+	}
+
+	rows, err := store.ListComments(ctx, caseID, TargetHit, hit.HitID)
+	if err != nil || len(rows) != 3 {
+		t.Fatalf("ListComments=%v err=%v", rows, err)
+	}
+	all, err := store.ListComments(ctx, caseID, "", "")
+	if err != nil {
+		t.Fatalf("ListComments all: %v", err)
+	}
+	g := Group(all)
+	thread := g[Key(TargetHit, hit.HitID)]
+	if len(thread) != 3 || thread[0].CommentID != root.CommentID || thread[0].Text != "is this the suspect's address?" {
+		t.Fatalf("thread=%+v", thread)
+	}
+	if len(g[Key(TargetArtifact, hit.ArtifactID)]) != 1 {
+		t.Fatalf("groups=%+v", g)
+	}
+}
@@ -0,0 +1,398 @@
+package correlation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 多设备关联分析
+//
+// 一个案件常包含一台电脑 + 多部手机，跨设备的关联往往是案件的核心：
+// - 同一交易所（按规则 ID 归并，列出各设备访问的域名）
+// - 同一钱包应用/扩展（按规则 ID 归并）
+// - 同一钱包地址（wallet_address 以及余额/NFT 查询命中中的地址）
+// - 时间窗口重叠：两台设备的活跃时间段（命中 first/last_seen）是否交叠
+//
+// 结果只基于已落库命中计算，写入 analysis 证据（snapshot + payload），
+// 报告中以独立章节呈现；关联只表示“可能属于同一主体”，需人工复核。
+
+// AnalysisKind 是 analysis 证据 payload 中的分析类型标识。
+const AnalysisKind = "device_correlation"
+
+// Version 是关联算法版本；调整归并口径时需同步递增。
+const Version = "correlation-0.1.0"
+
+// 关联对象类型。
+const (
+	KindExchange = "exchange"
+	KindWallet   = "wallet"
+	KindAddress  = "address"
+)
+
+// DeviceRef 是参与分析的一台设备及其活跃窗口。
+type DeviceRef struct {
+	DeviceID    string `json:"device_id"`
+	DeviceName  string `json:"device_name,omitempty"`
+	OSType      string `json:"os_type"`
+	HitCount    int    `json:"hit_count"`
+	FirstSeenAt int64  `json:"first_seen_at,omitempty"`
+	LastSeenAt  int64  `json:"last_seen_at,omitempty"`
+}
+
+// Sighting 是某个关联对象在一台设备上的出现情况。
+type Sighting struct {
+	DeviceID    string   `json:"device_id"`
+	FirstSeenAt int64    `json:"first_seen_at,omitempty"`
+	LastSeenAt  int64    `json:"last_seen_at,omitempty"`
+	HitIDs      []string `json:"hit_ids"`
+}
+
+// SharedItem 是在两台及以上设备上出现的同一对象。
+type SharedItem struct {
+	Kind    string     `json:"kind"`
+	Key     string     `json:"key"`
+	Label   string     `json:"label,omitempty"`
+	Values  []string   `json:"values"` // 各设备上的命中值（例如不同域名）
+	Devices []Sighting `json:"devices"`
+	// Overlap 表示各设备上的出现时间段存在共同交集（OverlapStart~OverlapEnd）。
+	Overlap      bool  `json:"overlap"`
+	OverlapStart int64 `json:"overlap_start,omitempty"`
+	OverlapEnd   int64 `json:"overlap_end,omitempty"`
+}
+
+// WindowOverlap 是两台设备活跃窗口的交集。
+type WindowOverlap struct {
+	DeviceA string `json:"device_a"`
+	DeviceB string `json:"device_b"`
+	Start   int64  `json:"start"`
+	End     int64  `json:"end"`
+	Seconds int64  `json:"seconds"`
+}
+
+// Result 是一次关联分析的结果（同时作为 analysis 证据的 payload）。
+type Result struct {
+	Analysis        string          `json:"analysis"`
+	Version         string          `json:"version"`
+	CaseID          string          `json:"case_id"`
+	GeneratedAt     int64           `json:"generated_at"`
+	ArtifactID      string          `json:"artifact_id,omitempty"`
+	Devices         []DeviceRef     `json:"devices"`
+	SharedExchanges []SharedItem    `json:"shared_exchanges"`
+	SharedWallets   []SharedItem    `json:"shared_wallets"`
+	SharedAddresses []SharedItem    `json:"shared_addresses"`
+	WindowOverlaps  []WindowOverlap `json:"window_overlaps"`
+}
+
+// itemAcc 累积一个关联对象在各设备上的出现情况。
+type itemAcc struct {
+	label    string
+	values   map[string]struct{}
+	byDevice map[string]*Sighting
+}
+
+// Analyze 计算多设备关联（纯函数，不访问数据库）。
+func Analyze(caseID string, devices []model.CaseDevice, hits []model.HitDetail, now int64) *Result {
+	res := &Result{
+		Analysis:        AnalysisKind,
+		Version:         Version,
+		CaseID:          caseID,
+		GeneratedAt:     now,
+		Devices:         []DeviceRef{},
+		SharedExchanges: []SharedItem{},
+		SharedWallets:   []SharedItem{},
+		SharedAddresses: []SharedItem{},
+		WindowOverlaps:  []WindowOverlap{},
+	}
+
+	refs := map[string]*DeviceRef{}
+	order := []string{}
+	for _, d := range devices {
+		if _, ok := refs[d.DeviceID]; ok {
+			continue
+		}
+		refs[d.DeviceID] = &DeviceRef{DeviceID: d.DeviceID, DeviceName: d.DeviceName, OSType: d.OSType}
+		order = append(order, d.DeviceID)
+	}
+
+	groups := map[string]map[string]*itemAcc{KindExchange: {}, KindWallet: {}, KindAddress: {}}
+	add := func(kind, key, label, value string, h model.HitDetail) {
+		if key == "" {
+			return
+		}
+		acc := groups[kind][key]
+		if acc == nil {
+			acc = &itemAcc{label: label, values: map[string]struct{}{}, byDevice: map[string]*Sighting{}}
+			groups[kind][key] = acc
+		}
+		if value != "" {
+			acc.values[value] = struct{}{}
+		}
+		s := acc.byDevice[h.DeviceID]
+		if s == nil {
+			s = &Sighting{DeviceID: h.DeviceID}
+			acc.byDevice[h.DeviceID] = s
+		}
+		s.HitIDs = append(s.HitIDs, h.HitID)
+		s.FirstSeenAt, s.LastSeenAt = widen(s.FirstSeenAt, s.LastSeenAt, h.FirstSeenAt, h.LastSeenAt)
+	}
+
+	for _, h := range hits {
+		ref := refs[h.DeviceID]
+		if ref == nil {
+			ref = &DeviceRef{DeviceID: h.DeviceID}
+			refs[h.DeviceID] = ref
+			order = append(order, h.DeviceID)
+		}
+		ref.HitCount++
+		ref.FirstSeenAt, ref.LastSeenAt = widen(ref.FirstSeenAt, ref.LastSeenAt, h.FirstSeenAt, h.LastSeenAt)
+
+		switch model.HitType(h.HitType) {
+		case model.HitExchangeVisited, model.HitExchangeFormActivity, model.HitExchangeAppInstalled:
+			add(KindExchange, strings.TrimSpace(h.RuleID), h.RuleName, strings.ToLower(strings.TrimSpace(h.MatchedValue)), h)
+		case model.HitWalletInstalled, model.HitWalletExecuted:
+			add(KindWallet, strings.TrimSpace(h.RuleID), h.RuleName, strings.TrimSpace(h.MatchedValue), h)
+		case model.HitWalletAddress:
+			addr := normalizeAddress(h.MatchedValue)
+			add(KindAddress, addr, "", addr, h)
+		case model.HitTokenBalance, model.HitNFTHoldings:
+			// 命中值格式：addr|symbol 或 addr|contract（见 webapp/chain.go）
+			addr := normalizeAddress(strings.SplitN(h.MatchedValue, "|", 2)[0])
+			add(KindAddress, addr, "", addr, h)
+		}
+	}
+
+	for _, did := range order {
+		res.Devices = append(res.Devices, *refs[did])
+	}
+	res.SharedExchanges = sharedItems(KindExchange, groups[KindExchange])
+	res.SharedWallets = sharedItems(KindWallet, groups[KindWallet])
+	res.SharedAddresses = sharedItems(KindAddress, groups[KindAddress])
+
+	for i := 0; i < len(res.Devices); i++ {
+		for j := i + 1; j < len(res.Devices); j++ {
+			a, b := res.Devices[i], res.Devices[j]
+			if a.FirstSeenAt == 0 || b.FirstSeenAt == 0 {
+				continue
+			}
+			start, end := max(a.FirstSeenAt, b.FirstSeenAt), min(a.LastSeenAt, b.LastSeenAt)
+			if start > end {
+				continue
+			}
+			res.WindowOverlaps = append(res.WindowOverlaps, WindowOverlap{
+				DeviceA: a.DeviceID,
+				DeviceB: b.DeviceID,
+				Start:   start,
+				End:     end,
+				Seconds: end - start,
+			})
+		}
+	}
+	return res
+}
+
+// sharedItems 只保留出现在两台及以上设备上的对象：设备数多的在前，其次按 key 排序。
+func sharedItems(kind string, groups map[string]*itemAcc) []SharedItem {
+	out := []SharedItem{}
+	for key, acc := range groups {
+		if len(acc.byDevice) < 2 {
+			continue
+		}
+		item := SharedItem{Kind: kind, Key: key, Label: acc.label, Values: []string{}, Devices: []Sighting{}}
+		for v := range acc.values {
+			item.Values = append(item.Values, v)
+		}
+		sort.Strings(item.Values)
+
+		overlap := true
+		var start, end int64
+		for _, s := range acc.byDevice {
+			sort.Strings(s.HitIDs)
+			item.Devices = append(item.Devices, *s)
+			if s.FirstSeenAt == 0 {
+				overlap = false
+				continue
+			}
+			if start == 0 || s.FirstSeenAt > start {
+				start = s.FirstSeenAt
+			}
+			if end == 0 || s.LastSeenAt < end {
+				end = s.LastSeenAt
+			}
+		}
+		sort.Slice(item.Devices, func(i, j int) bool { return item.Devices[i].DeviceID < item.Devices[j].DeviceID })
+		if overlap && start <= end {
+			item.Overlap, item.OverlapStart, item.OverlapEnd = true, start, end
+		}
+		out = append(out, item)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if len(out[i].Devices) != len(out[j].Devices) {
+			return len(out[i].Devices) > len(out[j].Devices)
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// widen 用一条命中的时间范围扩展窗口（0 表示未知，不参与计算）。
+func widen(first, last, hitFirst, hitLast int64) (int64, int64) {
+	if hitFirst <= 0 {
+		hitFirst = hitLast
+	}
+	if hitLast <= 0 {
+		hitLast = hitFirst
+	}
+	if hitFirst <= 0 {
+		return first, last
+	}
+	if first == 0 || hitFirst < first {
+		first = hitFirst
+	}
+	if hitLast > last {
+		last = hitLast
+	}
+	return first, last
+}
+
+// normalizeAddress：EVM 地址不区分大小写，统一小写；其他地址保持原样。
+func normalizeAddress(v string) string {
+	v = strings.TrimSpace(v)
+	if strings.HasPrefix(strings.ToLower(v), "0x") {
+		return strings.ToLower(v)
+	}
+	return v
+}
+
+// RunForCase 按案件当前命中计算关联分析，并落库为 analysis 证据。
+func RunForCase(ctx context.Context, store *sqliteadapter.Store, evidenceRoot, caseID, operator string) (*Result, error) {
+	caseID = strings.TrimSpace(caseID)
+	if caseID == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "case_id is required")
+	}
+	if strings.TrimSpace(evidenceRoot) == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "evidence root is required")
+	}
+	if strings.TrimSpace(operator) == "" {
+		operator = "system"
+	}
+	devices, err := store.ListCaseDevices(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("case has no devices: %s", caseID))
+	}
+	hits, err := store.ListCaseHitDetails(ctx, caseID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	res := Analyze(caseID, devices, hits, now)
+	res.ArtifactID = id.New("art")
+	raw, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal correlation: %w", err)
+	}
+
+	// analysis 证据是案件级的，挂到案件本机设备（否则第一台设备）上。
+	deviceID := devices[0].DeviceID
+	for _, d := range devices {
+		if strings.TrimSpace(d.ConnectionType) == "local" {
+			deviceID = d.DeviceID
+			break
+		}
+	}
+	dir := filepath.Join(evidenceRoot, caseID, deviceID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create evidence dir: %w", err)
+	}
+	snapshotPath := filepath.Join(dir, fmt.Sprintf("%s_%d.json", AnalysisKind, now))
+	if err := os.WriteFile(snapshotPath, raw, 0o644); err != nil {
+		return nil, fmt.Errorf("write evidence file: %w", err)
+	}
+	sum, size, err := hash.File(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("hash evidence file: %w", err)
+	}
+
+	collectorName := "correlation"
+	collectorVer := "correlation-" + strings.TrimSpace(app.Version)
+	if strings.TrimSpace(app.Version) == "" {
+		collectorVer = "correlation-dev"
+	}
+	art := model.Artifact{
+		ID:                res.ArtifactID,
+		CaseID:            caseID,
+		DeviceID:          deviceID,
+		Type:              model.ArtifactAnalysis,
+		SourceRef:         AnalysisKind,
+		SnapshotPath:      snapshotPath,
+		SHA256:            sum,
+		SizeBytes:         size,
+		CollectedAt:       now,
+		CollectorName:     collectorName,
+		CollectorVersion:  collectorVer,
+		ParserVersion:     Version,
+		AcquisitionMethod: "derived",
+		PayloadJSON:       raw,
+		RecordHash: hash.Text(
+			res.ArtifactID,
+			caseID,
+			deviceID,
+			string(model.ArtifactAnalysis),
+			AnalysisKind,
+			snapshotPath,
+			sum,
+			fmt.Sprintf("%d", size),
+			fmt.Sprintf("%d", now),
+			collectorName,
+			collectorVer,
+			string(raw),
+		),
+	}
+	if err := store.SaveArtifacts(ctx, []model.Artifact{art}); err != nil {
+		return nil, err
+	}
+
+	_ = store.AppendAudit(ctx, caseID, "", "analysis", AnalysisKind, "success", operator, "correlation.RunForCase", map[string]any{
+		"artifact_id":      res.ArtifactID,
+		"sha256":           sum,
+		"devices":          len(res.Devices),
+		"shared_exchanges": len(res.SharedExchanges),
+		"shared_wallets":   len(res.SharedWallets),
+		"shared_addresses": len(res.SharedAddresses),
+		"window_overlaps":  len(res.WindowOverlaps),
+	})
+	return res, nil
+}
+
+// Latest 返回案件最近一次落库的关联分析（没有时返回 nil）。
+func Latest(ctx context.Context, store *sqliteadapter.Store, caseID string) (*Result, error) {
+	payloads, err := store.ListArtifactPayloadsWithIDByType(ctx, caseID, string(model.ArtifactAnalysis))
+	if err != nil {
+		return nil, err
+	}
+	for i := len(payloads) - 1; i >= 0; i-- {
+		var res Result
+		if err := json.Unmarshal(payloads[i].PayloadJSON, &res); err != nil || res.Analysis != AnalysisKind {
+			continue
+		}
+		res.ArtifactID = payloads[i].ArtifactID
+		return &res, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,119 @@
+package correlation
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestAnalyze(t *testing.T) {
+	devices := []model.CaseDevice{
+		{DeviceID: "laptop", OSType: "windows", ConnectionType: "local"},
+		{DeviceID: "phone_a", OSType: "android"},
+		{DeviceID: "phone_b", OSType: "ios"},
+	}
+	hits := []model.HitDetail{
+		{HitID: "h1", DeviceID: "laptop", HitType: "exchange_visited", RuleID: "binance", RuleName: "Binance", MatchedValue: "www.binance.com", FirstSeenAt: 100, LastSeenAt: 500},
+		{HitID: "h2", DeviceID: "phone_a", HitType: "exchange_visited", RuleID: "binance", RuleName: "Binance", MatchedValue: "binance.com", FirstSeenAt: 400, LastSeenAt: 900},
+		{HitID: "h3", DeviceID: "phone_b", HitType: "exchange_visited", RuleID: "okx", RuleName: "OKX", MatchedValue: "okx.com", FirstSeenAt: 2000, LastSeenAt: 2100},
+		{HitID: "h4", DeviceID: "laptop", HitType: "wallet_address", MatchedValue: "0xAbC0000000000000000000000000000000000001", FirstSeenAt: 150, LastSeenAt: 150},
+		{HitID: "h5", DeviceID: "phone_b", HitType: "token_balance", MatchedValue: "0xabc0000000000000000000000000000000000001|USDT", FirstSeenAt: 3000, LastSeenAt: 3000},
+		{HitID: "h6", DeviceID: "phone_a", HitType: "wallet_installed", RuleID: "metamask", MatchedValue: "io.metamask"},
+		{HitID: "h7", DeviceID: "laptop", HitType: "wallet_installed", RuleID: "metamask", MatchedValue: "nkbihfbeogaeaoehlefnkodbefgpgknn", FirstSeenAt: 200, LastSeenAt: 200},
+	}
+
+	res := Analyze("case_1", devices, hits, 10)
+	if len(res.Devices) != 3 || res.Devices[0].HitCount != 3 || res.Devices[0].FirstSeenAt != 100 || res.Devices[0].LastSeenAt != 500 {
+		t.Fatalf("devices=%+v", res.Devices)
+	}
+
+	if len(res.SharedExchanges) != 1 {
+		t.Fatalf("shared exchanges=%+v", res.SharedExchanges)
+	}
+	ex := res.SharedExchanges[0]
+	if ex.Key != "binance" || len(ex.Devices) != 2 || len(ex.Values) != 2 || !ex.Overlap || ex.OverlapStart != 400 || ex.OverlapEnd != 500 {
+		t.Fatalf("exchange=%+v", ex)
+	}
+
+	if len(res.SharedAddresses) != 1 {
+		t.Fatalf("shared addresses=%+v", res.SharedAddresses)
+	}
+	addr := res.SharedAddresses[0]
+	if addr.Key != "0xabc0000000000000000000000000000000000001" || addr.Overlap {
+		t.Fatalf("address=%+v", addr)
+	}
+
+	// 没有时间信息的设备不判定重叠，但仍计入共同钱包。
+	if len(res.SharedWallets) != 1 || res.SharedWallets[0].Overlap {
+		t.Fatalf("shared wallets=%+v", res.SharedWallets)
+	}
+
+	if len(res.WindowOverlaps) != 1 {
+		t.Fatalf("window overlaps=%+v", res.WindowOverlaps)
+	}
+	if o := res.WindowOverlaps[0]; o.DeviceA != "laptop" || o.DeviceB != "phone_a" || o.Start != 400 || o.End != 500 || o.Seconds != 100 {
+		t.Fatalf("overlap=%+v", o)
+	}
+}
+
+func TestRunForCasePersistsAnalysisArtifact(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatalf("EnsureCase: %v", err)
+	}
+
+	if _, err := RunForCase(ctx, store, filepath.Join(dir, "evidence"), caseID, "op"); err == nil {
+		t.Fatalf("expected error for case without devices")
+	}
+	for _, d := range []model.Device{
+		{ID: "dev_pc", Name: "pc", OS: model.OSWindows, Identifier: "pc"},
+		{ID: "dev_phone", Name: "phone", OS: model.OSAndroid, Identifier: "phone"},
+	} {
+		if err := store.UpsertDevice(ctx, caseID, d, true, ""); err != nil {
+			t.Fatalf("UpsertDevice: %v", err)
+		}
+	}
+	if err := store.SaveRuleHits(ctx, []model.RuleHit{
+		{ID: "hit_1", CaseID: caseID, DeviceID: "dev_pc", Type: model.HitExchangeVisited, RuleID: "binance", MatchedValue: "binance.com", FirstSeenAt: 10, LastSeenAt: 20, Confidence: 0.9, Verdict: "confirmed"},
+		{ID: "hit_2", CaseID: caseID, DeviceID: "dev_phone", Type: model.HitExchangeVisited, RuleID: "binance", MatchedValue: "binance.com", FirstSeenAt: 15, LastSeenAt: 30, Confidence: 0.9, Verdict: "confirmed"},
+	}); err != nil {
+		t.Fatalf("SaveRuleHits: %v", err)
+	}
+
+	if got, err := Latest(ctx, store, caseID); err != nil || got != nil {
+		t.Fatalf("Latest before run=%v err=%v", got, err)
+	}
+	res, err := RunForCase(ctx, store, filepath.Join(dir, "evidence"), caseID, "op")
+	if err != nil {
+		t.Fatalf("RunForCase: %v", err)
+	}
+	if len(res.SharedExchanges) != 1 || res.ArtifactID == "" {
+		t.Fatalf("res=%+v", res)
+	}
+	info, err := store.GetArtifactInfo(ctx, res.ArtifactID)
+	if err != nil || info == nil || info.ArtifactType != string(model.ArtifactAnalysis) {
+		t.Fatalf("artifact=%+v err=%v", info, err)
+	}
+	got, err := Latest(ctx, store, caseID)
+	if err != nil || got == nil || got.ArtifactID != res.ArtifactID || len(got.SharedExchanges) != 1 {
+		t.Fatalf("Latest=%+v err=%v", got, err)
+	}
+}
@@ -0,0 +1,451 @@
+package dbreplica
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/apperr"
+
+	_ "modernc.org/sqlite"
+)
+
+// 数据库热备副本
+//
+// 外勤笔记本磁盘随时可能损坏，证据元数据（案件/证据/命中/审计链）只存在一份 SQLite 里风险太大。
+// 本包把主库定期同步到第二个路径（如外接 SSD），主盘故障后用 failover 把副本提升为主库：
+// - 同步用 VACUUM INTO 生成一致性快照（包含尚未 checkpoint 的 WAL 内容），写临时文件 + fsync 后原子替换副本
+// - 副本旁写 <replica>.meta.json（来源、同步时间、sha256、schema_version），failover 前据此校验副本完整
+// - 后台 Run 按间隔检查主库/WAL 文件是否变化，有变化才同步；退出时再同步一次
+// - 这是周期快照而非逐帧 WAL 流式复制：最坏丢失一个同步间隔内的写入（RPO = Interval）
+// - failover 不删除任何文件：原主库（含 -wal/-shm）改名为 <db>.failed-<时间戳> 留作排查
+
+// DefaultInterval 是后台同步的默认间隔。
+const DefaultInterval = 30 * time.Second
+
+// Options 定义后台同步参数。
+type Options struct {
+	// Path 为副本路径；为空表示不启用。
+	Path string
+	// Interval 为检查/同步间隔（零值使用 DefaultInterval）。
+	Interval time.Duration
+	// Logf 用于输出同步日志（nil 表示不输出）。
+	Logf func(format string, args ...any)
+}
+
+// Meta 是副本旁的元数据（<replica>.meta.json）。
+type Meta struct {
+	Source        string `json:"source"`
+	SyncedAt      int64  `json:"synced_at"`
+	SizeBytes     int64  `json:"size_bytes"`
+	SHA256        string `json:"sha256"`
+	SchemaVersion string `json:"schema_version"`
+}
+
+// Status 是副本状态。
+type Status struct {
+	Path       string `json:"path"`
+	Exists     bool   `json:"exists"`
+	Meta       *Meta  `json:"meta,omitempty"`
+	AgeSeconds int64  `json:"age_seconds,omitempty"`
+	// HashMatch 表示副本当前 sha256 与元数据一致（无元数据时为 false）。
+	HashMatch bool `json:"hash_match"`
+}
+
+// FailoverResult 是一次提升副本的结果。
+type FailoverResult struct {
+	Replica       string   `json:"replica"`
+	Target        string   `json:"target"`
+	MovedAside    []string `json:"moved_aside,omitempty"`
+	SchemaVersion string   `json:"schema_version"`
+	SyncedAt      int64    `json:"synced_at"`
+}
+
+// MetaPath 返回副本元数据文件路径。
+func MetaPath(replica string) string {
+	return replica + ".meta.json"
+}
+
+// Sync 把 db（主库连接）生成一致性快照写到 replica，并更新元数据。
+func Sync(ctx context.Context, db *sql.DB, primary, replica string) (*Meta, error) {
+	replica = strings.TrimSpace(replica)
+	if replica == "" {
+		return nil, fmt.Errorf("replica path is required")
+	}
+	if samePath(primary, replica) {
+		return nil, fmt.Errorf("replica path must differ from primary db: %s", replica)
+	}
+	if err := os.MkdirAll(filepath.Dir(replica), 0o755); err != nil {
+		return nil, fmt.Errorf("create replica directory: %w", err)
+	}
+
+	tmp := replica + ".tmp"
+	_ = os.Remove(tmp)
+	if _, err := db.ExecContext(ctx, `VACUUM INTO ?`, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return nil, fmt.Errorf("snapshot primary db: %w", err)
+	}
+	if err := fsyncFile(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	version, err := schemaVersion(ctx, tmp)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	sum, size, err := hashFile(tmp)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, replica); err != nil {
+		_ = os.Remove(tmp)
+		return nil, fmt.Errorf("replace replica: %w", err)
+	}
+	fsyncDir(filepath.Dir(replica))
+
+	meta := &Meta{
+		Source:        absPath(primary),
+		SyncedAt:      time.Now().Unix(),
+		SizeBytes:     size,
+		SHA256:        sum,
+		SchemaVersion: version,
+	}
+	if err := writeMeta(replica, meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// Replicator 在后台按间隔同步副本。
+type Replicator struct {
+	db      *sql.DB
+	primary string
+	opts    Options
+
+	last fileSig
+}
+
+// New 创建后台同步器。
+func New(db *sql.DB, primary string, opts Options) (*Replicator, error) {
+	opts.Path = strings.TrimSpace(opts.Path)
+	if opts.Path == "" {
+		return nil, fmt.Errorf("replica path is required")
+	}
+	if samePath(primary, opts.Path) {
+		return nil, fmt.Errorf("replica path must differ from primary db: %s", opts.Path)
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultInterval
+	}
+	return &Replicator{db: db, primary: primary, opts: opts}, nil
+}
+
+// Run 启动时先同步一次，之后每个间隔在主库有变化时同步，ctx 取消时做最后一次同步后返回。
+func (r *Replicator) Run(ctx context.Context) {
+	r.syncIfChanged(ctx, true)
+	t := time.NewTicker(r.opts.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			// 退出前补一次同步，尽量缩小停机前最后一段写入的丢失窗口。
+			finalCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			r.syncIfChanged(finalCtx, false)
+			cancel()
+			return
+		case <-t.C:
+			r.syncIfChanged(ctx, false)
+		}
+	}
+}
+
+// SyncNow 立即同步一次（不检查变化）。
+func (r *Replicator) SyncNow(ctx context.Context) (*Meta, error) {
+	sig := statSig(r.primary)
+	meta, err := Sync(ctx, r.db, r.primary, r.opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	r.last = sig
+	return meta, nil
+}
+
+func (r *Replicator) syncIfChanged(ctx context.Context, force bool) {
+	if !force && statSig(r.primary) == r.last {
+		return
+	}
+	meta, err := r.SyncNow(ctx)
+	if err != nil {
+		r.logf("db replica sync failed: %v", err)
+		return
+	}
+	r.logf("db replica synced: path=%s size=%d sha256=%s", r.opts.Path, meta.SizeBytes, meta.SHA256)
+}
+
+func (r *Replicator) logf(format string, args ...any) {
+	if r.opts.Logf != nil {
+		r.opts.Logf(format, args...)
+	}
+}
+
+// ReadStatus 读取副本状态并重新计算哈希。
+func ReadStatus(replica string) (*Status, error) {
+	st := &Status{Path: replica}
+	info, err := os.Stat(replica)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st, nil
+		}
+		return nil, fmt.Errorf("stat replica: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("replica path is a directory: %s", replica)
+	}
+	st.Exists = true
+	meta, err := readMeta(replica)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil {
+		return st, nil
+	}
+	st.Meta = meta
+	st.AgeSeconds = time.Now().Unix() - meta.SyncedAt
+	sum, _, err := hashFile(replica)
+	if err != nil {
+		return nil, err
+	}
+	st.HashMatch = sum == meta.SHA256
+	return st, nil
+}
+
+// Failover 校验副本后把它复制到 target（通常是原主库路径）。
+//
+// target 已存在且仍能通过完整性检查时需要 force=true，避免把较新的主库误回滚到旧副本；
+// 原文件一律改名保留，不做删除。
+func Failover(ctx context.Context, replica, target string, force bool) (*FailoverResult, error) {
+	replica, target = strings.TrimSpace(replica), strings.TrimSpace(target)
+	if replica == "" || target == "" {
+		return nil, fmt.Errorf("replica and target paths are required")
+	}
+	if samePath(replica, target) {
+		return nil, fmt.Errorf("replica and target must differ")
+	}
+
+	st, err := ReadStatus(replica)
+	if err != nil {
+		return nil, err
+	}
+	if !st.Exists {
+		return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("replica not found: %s", replica))
+	}
+	if st.Meta != nil && !st.HashMatch && !force {
+		return nil, apperr.New(apperr.CodeConflict, fmt.Sprintf("replica sha256 does not match %s (use --force to promote anyway)", MetaPath(replica)))
+	}
+	if err := integrityCheck(ctx, replica); err != nil {
+		return nil, fmt.Errorf("replica failed integrity check: %w", err)
+	}
+
+	if _, err := os.Stat(target); err == nil && !force {
+		if integrityCheck(ctx, target) == nil {
+			return nil, apperr.New(apperr.CodeConflict, fmt.Sprintf("target db %s still passes integrity check; use --force to replace it with the replica", target))
+		}
+	}
+
+	res := &FailoverResult{Replica: replica, Target: target}
+	if st.Meta != nil {
+		res.SchemaVersion, res.SyncedAt = st.Meta.SchemaVersion, st.Meta.SyncedAt
+	}
+	suffix := ".failed-" + time.Now().Format("20060102-150405")
+	for _, p := range []string{target, target + "-wal", target + "-shm"} {
+		if _, err := os.Lstat(p); err != nil {
+			continue
+		}
+		aside := p + suffix
+		if err := os.Rename(p, aside); err != nil {
+			return nil, fmt.Errorf("move aside %s: %w", p, err)
+		}
+		res.MovedAside = append(res.MovedAside, aside)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return nil, fmt.Errorf("create target directory: %w", err)
+	}
+	tmp := target + ".tmp"
+	if err := copyFile(replica, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		_ = os.Remove(tmp)
+		return nil, fmt.Errorf("promote replica: %w", err)
+	}
+	fsyncDir(filepath.Dir(target))
+	if res.SchemaVersion == "" {
+		res.SchemaVersion, _ = schemaVersion(ctx, target)
+	}
+	return res, nil
+}
+
+// integrityCheck 以只读方式打开数据库并执行 PRAGMA integrity_check。
+func integrityCheck(ctx context.Context, path string) error {
+	db, err := openReadOnly(path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	var res string
+	if err := db.QueryRowContext(ctx, `PRAGMA integrity_check`).Scan(&res); err != nil {
+		return fmt.Errorf("integrity_check: %w", err)
+	}
+	if res != "ok" {
+		return fmt.Errorf("integrity_check: %s", res)
+	}
+	return nil
+}
+
+func schemaVersion(ctx context.Context, path string) (string, error) {
+	db, err := openReadOnly(path)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+	var v string
+	if err := db.QueryRowContext(ctx, `SELECT value FROM schema_meta WHERE key = 'schema_version'`).Scan(&v); err != nil {
+		return "", fmt.Errorf("read replica schema_version: %w", err)
+	}
+	return v, nil
+}
+
+func openReadOnly(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(path)+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// fileSig 用主库与 WAL 的大小/修改时间粗略判断是否有新写入。
+type fileSig struct {
+	dbSize, dbMod   int64
+	walSize, walMod int64
+}
+
+func statSig(primary string) fileSig {
+	var s fileSig
+	if info, err := os.Stat(primary); err == nil {
+		s.dbSize, s.dbMod = info.Size(), info.ModTime().UnixNano()
+	}
+	if info, err := os.Stat(primary + "-wal"); err == nil {
+		s.walSize, s.walMod = info.Size(), info.ModTime().UnixNano()
+	}
+	return s
+}
+
+func readMeta(replica string) (*Meta, error) {
+	b, err := os.ReadFile(MetaPath(replica))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read replica meta: %w", err)
+	}
+	var m Meta
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("parse replica meta: %w", err)
+	}
+	return &m, nil
+}
+
+func writeMeta(replica string, m *Meta) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := MetaPath(replica)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return fmt.Errorf("write replica meta: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write replica meta: %w", err)
+	}
+	return nil
+}
+
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), n, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", src, err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("copy %s: %w", src, err)
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return fmt.Errorf("fsync %s: %w", dst, err)
+	}
+	return out.Close()
+}
+
+func fsyncFile(path string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer f.Close()
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("fsync %s: %w", path, err)
+	}
+	return nil
+}
+
+// fsyncDir 尽力同步目录项（Windows 不支持，忽略错误）。
+func fsyncDir(dir string) {
+	if f, err := os.Open(dir); err == nil {
+		_ = f.Sync()
+		f.Close()
+	}
+}
+
+func absPath(p string) string {
+	if a, err := filepath.Abs(p); err == nil {
+		return a
+	}
+	return p
+}
+
+func samePath(a, b string) bool {
+	return filepath.Clean(absPath(a)) == filepath.Clean(absPath(b))
+}
@@ -0,0 +1,92 @@
+package dbreplica
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+)
+
+func TestSyncAndFailover(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "data", "inspector.db")
+	replica := filepath.Join(dir, "ssd", "inspector.replica.db")
+	if err := os.MkdirAll(filepath.Dir(primary), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", primary)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	if _, err := store.EnsureCase(ctx, "case_a", "A-001", "first", "op", ""); err != nil {
+		t.Fatalf("EnsureCase: %v", err)
+	}
+
+	r, err := New(db, primary, Options{Path: replica})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	meta, err := r.SyncNow(ctx)
+	if err != nil {
+		t.Fatalf("SyncNow: %v", err)
+	}
+	if meta.SchemaVersion == "" || meta.SHA256 == "" {
+		t.Fatalf("meta=%+v", meta)
+	}
+	// 副本同步后的写入不在副本中（RPO = 同步间隔）。
+	if _, err := store.EnsureCase(ctx, "case_b", "B-001", "second", "op", ""); err != nil {
+		t.Fatalf("EnsureCase: %v", err)
+	}
+	if st, err := ReadStatus(replica); err != nil || !st.Exists || !st.HashMatch {
+		t.Fatalf("status=%+v err=%v", st, err)
+	}
+
+	// 主库仍完好时需要 force。
+	if _, err := Failover(ctx, replica, primary, false); apperr.CodeOf(err) != apperr.CodeConflict {
+		t.Fatalf("expected failover to refuse a healthy primary")
+	}
+	db.Close()
+
+	// 模拟主盘损坏。
+	if err := os.WriteFile(primary, []byte("not a database"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Failover(ctx, replica, primary, false)
+	if err != nil {
+		t.Fatalf("Failover: %v", err)
+	}
+	if len(res.MovedAside) == 0 || res.SchemaVersion != meta.SchemaVersion {
+		t.Fatalf("res=%+v", res)
+	}
+	if _, err := os.Stat(res.MovedAside[0]); err != nil {
+		t.Fatalf("moved-aside primary missing: %v", err)
+	}
+
+	db2, err := sql.Open("sqlite", primary)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer db2.Close()
+	var n int
+	if err := db2.QueryRowContext(ctx, `SELECT COUNT(1) FROM cases`).Scan(&n); err != nil || n != 1 {
+		t.Fatalf("cases=%d err=%v", n, err)
+	}
+
+	// 副本被篡改时拒绝提升。
+	if err := os.WriteFile(replica, []byte("garbage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Failover(ctx, replica, filepath.Join(dir, "other.db"), false); apperr.CodeOf(err) != apperr.CodeConflict {
+		t.Fatalf("expected hash mismatch to block failover")
+	}
+}
@@ -0,0 +1,326 @@
+package devicecustody
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/filetype"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 设备保管信息（实物标签 / IMEI / 扣押地点 / 保管人 / 备注 / 照片）
+//
+// 这些字段由办案人员手工维护，扫描 upsert 不会覆盖；每个发生变化的字段各写一条审计
+// （event_type=device, action=update_custody）。照片原样落盘到 <evidence>/<case_id>/<device_id>/photos/，
+// 库内记录 sha256，取证 PDF 的设备章节列出保管字段与照片哈希。归档案件只读。
+
+const (
+	// MaxFieldLen 限制单行字段（标签 / 地点 / 保管人）长度（字符数）。
+	MaxFieldLen = 200
+	// MaxNoteLen 限制备注长度（字符数）。
+	MaxNoteLen = 2000
+	// MaxPhotoBytes 限制单张照片大小。
+	MaxPhotoBytes = 20 << 20
+)
+
+var reUnsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Patch 是一次部分更新；nil 字段保持不变，空字符串表示清除。
+type Patch struct {
+	PhysicalLabel   *string `json:"physical_label,omitempty"`
+	IMEI            *string `json:"imei,omitempty"`
+	SeizureLocation *string `json:"seizure_location,omitempty"`
+	CustodyOfficer  *string `json:"custody_officer,omitempty"`
+	Note            *string `json:"custody_note,omitempty"`
+	Operator        string  `json:"operator,omitempty"`
+}
+
+// Change 是一个字段的变化。
+type Change struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Result 是修改结果：更新后的设备与实际发生的变化（无变化时 Changes 为空）。
+type Result struct {
+	Device  *model.CaseDevice `json:"device"`
+	Changes []Change          `json:"changes"`
+}
+
+// Get 返回案件中的一台设备（含照片）；案件或设备不存在返回 ERR_NOT_FOUND。
+func Get(ctx context.Context, store *sqliteadapter.Store, caseID, deviceID string) (*model.CaseDevice, error) {
+	devices, err := store.ListCaseDevices(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range devices {
+		if devices[i].DeviceID == deviceID {
+			return &devices[i], nil
+		}
+	}
+	return nil, apperr.T(apperr.CodeNotFound, "device.not_found", caseID, deviceID)
+}
+
+// Update 校验并应用 Patch。参数不合法返回 ERR_INVALID_ARGUMENT，设备不存在返回 ERR_NOT_FOUND，
+// 案件已归档返回 ERR_CONFLICT。
+func Update(ctx context.Context, store *sqliteadapter.Store, caseID, deviceID string, p Patch) (*Result, error) {
+	if p.PhysicalLabel == nil && p.IMEI == nil && p.SeizureLocation == nil && p.CustodyOfficer == nil && p.Note == nil {
+		return nil, apperr.T(apperr.CodeInvalidArgument, "device.no_fields")
+	}
+	operator := strings.TrimSpace(p.Operator)
+	if operator == "" {
+		operator = "system"
+	}
+	if err := ensureWritable(ctx, store, caseID); err != nil {
+		return nil, err
+	}
+	cur, err := Get(ctx, store, caseID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先完成全部校验，再写库，避免部分字段生效。
+	next := *cur
+	fields := []struct {
+		name  string
+		in    *string
+		max   int
+		multi bool
+		dst   *string
+	}{
+		{"physical_label", p.PhysicalLabel, MaxFieldLen, false, &next.PhysicalLabel},
+		{"imei", p.IMEI, MaxFieldLen, false, &next.IMEI},
+		{"seizure_location", p.SeizureLocation, MaxFieldLen, false, &next.SeizureLocation},
+		{"custody_officer", p.CustodyOfficer, MaxFieldLen, false, &next.CustodyOfficer},
+		{"custody_note", p.Note, MaxNoteLen, true, &next.CustodyNote},
+	}
+	for _, f := range fields {
+		if f.in == nil {
+			continue
+		}
+		v, err := cleanText(f.name, *f.in, f.max, f.multi)
+		if err != nil {
+			return nil, err
+		}
+		*f.dst = v
+	}
+	if p.IMEI != nil {
+		if next.IMEI, err = NormalizeIMEI(next.IMEI); err != nil {
+			return nil, err
+		}
+	}
+
+	var changes []Change
+	for _, c := range []Change{
+		{"physical_label", cur.PhysicalLabel, next.PhysicalLabel},
+		{"imei", cur.IMEI, next.IMEI},
+		{"seizure_location", cur.SeizureLocation, next.SeizureLocation},
+		{"custody_officer", cur.CustodyOfficer, next.CustodyOfficer},
+		{"custody_note", cur.CustodyNote, next.CustodyNote},
+	} {
+		if c.From != c.To {
+			changes = append(changes, c)
+		}
+	}
+	if len(changes) > 0 {
+		next.CustodyUpdatedBy = operator
+		next.CustodyUpdatedAt = time.Now().Unix()
+		if _, err := store.UpdateDeviceCustody(ctx, next); err != nil {
+			return nil, err
+		}
+		for _, c := range changes {
+			_ = store.AppendAudit(ctx, caseID, deviceID, "device", "update_custody", "success", operator, "devicecustody.Update", map[string]any{
+				"field": c.Field,
+				"from":  c.From,
+				"to":    c.To,
+			})
+		}
+	}
+
+	updated, err := Get(ctx, store, caseID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = []Change{}
+	}
+	return &Result{Device: updated, Changes: changes}, nil
+}
+
+// PhotoInput 是一次设备照片上传的参数。
+type PhotoInput struct {
+	CaseID       string
+	DeviceID     string
+	FileName     string
+	Content      []byte
+	Caption      string
+	Operator     string
+	EvidenceRoot string
+}
+
+// AttachPhoto 校验（只接受 PNG/JPEG，按文件头判定）并保存一张设备照片，追加审计。
+func AttachPhoto(ctx context.Context, store *sqliteadapter.Store, in PhotoInput) (*model.DevicePhoto, error) {
+	in.CaseID = strings.TrimSpace(in.CaseID)
+	in.DeviceID = strings.TrimSpace(in.DeviceID)
+	in.Operator = strings.TrimSpace(in.Operator)
+	if in.Operator == "" {
+		in.Operator = "system"
+	}
+	if in.CaseID == "" || in.DeviceID == "" {
+		return nil, apperr.T(apperr.CodeInvalidArgument, "device.ids_required")
+	}
+	if len(in.Content) == 0 {
+		return nil, apperr.T(apperr.CodeInvalidArgument, "device.photo_empty")
+	}
+	if len(in.Content) > MaxPhotoBytes {
+		return nil, apperr.T(apperr.CodeInvalidArgument, "device.photo_too_large", MaxPhotoBytes)
+	}
+	mime := filetype.Detect(in.Content, "")
+	if mime != filetype.MimePNG && mime != filetype.MimeJPEG {
+		return nil, apperr.T(apperr.CodeInvalidArgument, "device.photo_type", mime)
+	}
+	caption, err := cleanText("caption", in.Caption, MaxFieldLen, false)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(in.EvidenceRoot) == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "evidence root is required")
+	}
+	if err := ensureWritable(ctx, store, in.CaseID); err != nil {
+		return nil, err
+	}
+	if _, err := Get(ctx, store, in.CaseID, in.DeviceID); err != nil {
+		return nil, err
+	}
+
+	photoID := id.New("photo")
+	dir := filepath.Join(in.EvidenceRoot, in.CaseID, in.DeviceID, "photos")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create device photo dir: %w", err)
+	}
+	name := reUnsafeName.ReplaceAllString(filepath.Base(strings.TrimSpace(in.FileName)), "_")
+	if name == "" || name == "." || name == "_" {
+		name = "photo.jpg"
+		if mime == filetype.MimePNG {
+			name = "photo.png"
+		}
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s", photoID, name))
+	if err := os.WriteFile(path, in.Content, 0o644); err != nil {
+		return nil, fmt.Errorf("write device photo: %w", err)
+	}
+	sum, size, err := hash.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("hash device photo: %w", err)
+	}
+
+	photo := model.DevicePhoto{
+		PhotoID:      photoID,
+		CaseID:       in.CaseID,
+		DeviceID:     in.DeviceID,
+		OriginalName: strings.TrimSpace(in.FileName),
+		FilePath:     path,
+		SHA256:       sum,
+		SizeBytes:    size,
+		MimeType:     mime,
+		Caption:      caption,
+		UploadedBy:   in.Operator,
+		UploadedAt:   time.Now().Unix(),
+	}
+	if photo.OriginalName == "" {
+		photo.OriginalName = name
+	}
+	if err := store.SaveDevicePhoto(ctx, photo); err != nil {
+		return nil, err
+	}
+	_ = store.AppendAudit(ctx, in.CaseID, in.DeviceID, "device", "attach_photo", "success", in.Operator, "devicecustody.AttachPhoto", map[string]any{
+		"photo_id":      photo.PhotoID,
+		"original_name": photo.OriginalName,
+		"sha256":        photo.SHA256,
+		"size_bytes":    photo.SizeBytes,
+		"mime_type":     photo.MimeType,
+		"caption":       photo.Caption,
+	})
+	return &photo, nil
+}
+
+// NormalizeIMEI 去掉空格与连字符后校验 IMEI：15 位数字且 Luhn 校验通过，或 16 位数字的 IMEISV；空值表示清除。
+func NormalizeIMEI(v string) (string, error) {
+	v = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(v))
+	if v == "" {
+		return "", nil
+	}
+	for _, r := range v {
+		if r < '0' || r > '9' {
+			return "", apperr.T(apperr.CodeInvalidArgument, "device.imei_digits", v)
+		}
+	}
+	switch len(v) {
+	case 15:
+		if !luhnValid(v) {
+			return "", apperr.T(apperr.CodeInvalidArgument, "device.imei_check_digit", v)
+		}
+	case 16:
+		// IMEISV 无校验位。
+	default:
+		return "", apperr.T(apperr.CodeInvalidArgument, "device.imei_length", v)
+	}
+	return v, nil
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// ensureWritable 检查案件存在且未归档。
+func ensureWritable(ctx context.Context, store *sqliteadapter.Store, caseID string) error {
+	ov, err := store.GetCaseOverview(ctx, caseID)
+	if err != nil {
+		return err
+	}
+	if ov == nil {
+		return apperr.T(apperr.CodeNotFound, "case.not_found", caseID)
+	}
+	if ov.Status == "archived" {
+		return apperr.T(apperr.CodeConflict, "case.archived_readonly")
+	}
+	return nil
+}
+
+// cleanText 去掉首尾空白并检查长度与控制字符（multiline 时允许换行与制表符）。
+func cleanText(field, v string, max int, multiline bool) (string, error) {
+	v = strings.TrimSpace(v)
+	if len([]rune(v)) > max {
+		return "", apperr.T(apperr.CodeInvalidArgument, "text.too_long", field, max)
+	}
+	if strings.IndexFunc(v, func(r rune) bool {
+		return unicode.IsControl(r) && !(multiline && (r == '\n' || r == '\t'))
+	}) >= 0 {
+		return "", apperr.T(apperr.CodeInvalidArgument, "text.control_chars", field)
+	}
+	return v, nil
+}
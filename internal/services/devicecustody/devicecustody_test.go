@@ -0,0 +1,103 @@
+package devicecustody
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func ptr(s string) *string { return &s }
+
+func TestUpdateAndAttachPhoto(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, _ := store.EnsureCase(ctx, "", "", "custody", "alice", "")
+	dev := model.Device{ID: "dev_1", Name: "phone", OS: model.OSType("android"), Identifier: "serial1"}
+	if err := store.UpsertDevice(ctx, caseID, dev, true, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, tc := range map[string]struct {
+		device string
+		p      Patch
+		code   apperr.Code
+	}{
+		"empty patch":    {"dev_1", Patch{}, apperr.CodeInvalidArgument},
+		"missing device": {"dev_x", Patch{PhysicalLabel: ptr("A")}, apperr.CodeNotFound},
+		"bad imei":       {"dev_1", Patch{IMEI: ptr("490154203237519")}, apperr.CodeInvalidArgument},
+		"control chars":  {"dev_1", Patch{CustodyOfficer: ptr("bob\x00")}, apperr.CodeInvalidArgument},
+	} {
+		if _, err := Update(ctx, store, caseID, tc.device, tc.p); apperr.CodeOf(err) != tc.code {
+			t.Fatalf("%s: err=%v want %s", name, err, tc.code)
+		}
+	}
+
+	res, err := Update(ctx, store, caseID, "dev_1", Patch{
+		PhysicalLabel:   ptr(" EXH-001 "),
+		IMEI:            ptr("49-015420-323751-8"),
+		SeizureLocation: ptr("Room 3"),
+		Note:            ptr("sealed bag #7\nscreen cracked"),
+		Operator:        "bob",
+	})
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	d := res.Device
+	if len(res.Changes) != 4 || d.PhysicalLabel != "EXH-001" || d.IMEI != "490154203237518" || d.CustodyUpdatedBy != "bob" {
+		t.Fatalf("changes=%+v device=%+v", res.Changes, d)
+	}
+	// 重新扫描（upsert）不覆盖保管字段。
+	if err := store.UpsertDevice(ctx, caseID, dev, true, "rescan"); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := Update(ctx, store, caseID, "dev_1", Patch{PhysicalLabel: ptr("EXH-001")}); err != nil || len(res.Changes) != 0 || res.Device.IMEI == "" {
+		t.Fatalf("no-op after rescan: %+v err=%v", res, err)
+	}
+
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if _, err := AttachPhoto(ctx, store, PhotoInput{CaseID: caseID, DeviceID: "dev_1", Content: []byte("%PDF-1.4"), EvidenceRoot: dir}); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("pdf photo: err=%v", err)
+	}
+	photo, err := AttachPhoto(ctx, store, PhotoInput{CaseID: caseID, DeviceID: "dev_1", FileName: "../front.png", Content: png, Caption: "front", EvidenceRoot: dir})
+	if err != nil {
+		t.Fatalf("AttachPhoto: %v", err)
+	}
+	if photo.MimeType != "image/png" || filepath.Dir(photo.FilePath) != filepath.Join(dir, caseID, "dev_1", "photos") {
+		t.Fatalf("photo=%+v", photo)
+	}
+	got, err := Get(ctx, store, caseID, "dev_1")
+	if err != nil || len(got.Photos) != 1 || got.Photos[0].SHA256 != photo.SHA256 {
+		t.Fatalf("device=%+v err=%v", got, err)
+	}
+
+	logs, err := store.ListAuditLogs(ctx, caseID, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, l := range logs {
+		if l.EventType == "device" && (l.Action == "update_custody" || l.Action == "attach_photo") {
+			n++
+		}
+	}
+	if n != 5 {
+		t.Fatalf("device audits=%d, want 5", n)
+	}
+}
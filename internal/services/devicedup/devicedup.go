@@ -0,0 +1,80 @@
+package devicedup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+)
+
+// 重复建案提示
+//
+// 同一台电脑/手机被不同人分别建案扫描时，会在多个案件里留下并行的证据与命中。
+// 扫描识别到设备后按设备标识（identifier）检索其他未结案件：
+// - 找到时前置检查 device_in_other_case 记为 failed（默认非必需，不中断扫描），并写入扫描告警
+// - 扫描结果返回 duplicate_cases，UI/CLI 据此提示操作员改用已有案件（--case-id）继续采集
+// - 只提示不合并：已写入当前案件的数据保持原样
+
+// CheckCode 是前置检查代码。
+const CheckCode = "device_in_other_case"
+
+// Lookup 返回其他未结案件中同一设备标识的登记记录（identifier 为空时返回空列表）。
+func Lookup(ctx context.Context, store *sqliteadapter.Store, identifier, excludeCaseID string) ([]model.DeviceCaseRef, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return []model.DeviceCaseRef{}, nil
+	}
+	return store.FindOpenCasesByIdentifier(ctx, identifier, excludeCaseID)
+}
+
+// Precheck 检查设备是否已登记在其他未结案件中，返回可直接落库的前置检查结果。
+//
+// 查询失败时检查记为 skipped；warning 非空时调用方应写入扫描告警。
+func Precheck(ctx context.Context, store *sqliteadapter.Store, caseID, scope string, device model.Device) (check model.PrecheckResult, refs []model.DeviceCaseRef, warning string) {
+	check = model.PrecheckResult{
+		CaseID:    caseID,
+		DeviceID:  device.ID,
+		ScanScope: scope,
+		CheckCode: CheckCode,
+		CheckName: "设备未登记在其他未结案件中",
+		Status:    model.PrecheckPassed,
+		Message:   "ok",
+		CheckedAt: time.Now().Unix(),
+	}
+	if strings.TrimSpace(device.Identifier) == "" {
+		check.Status, check.Message, check.DetailJSON = model.PrecheckSkipped, "device identifier unavailable", json.RawMessage("{}")
+		return check, nil, ""
+	}
+	refs, err := Lookup(ctx, store, device.Identifier, caseID)
+	if err != nil {
+		check.Status, check.Message, check.DetailJSON = model.PrecheckSkipped, err.Error(), json.RawMessage("{}")
+		return check, nil, ""
+	}
+	caseIDs := make([]string, 0, len(refs))
+	seen := map[string]bool{}
+	for _, r := range refs {
+		if !seen[r.CaseID] {
+			seen[r.CaseID] = true
+			caseIDs = append(caseIDs, r.CaseID)
+		}
+	}
+	check.DetailJSON, _ = json.Marshal(map[string]any{
+		"identifier": device.Identifier,
+		"case_ids":   caseIDs,
+	})
+	if len(caseIDs) == 0 {
+		return check, nil, ""
+	}
+	check.Status = model.PrecheckFailed
+	check.Message = strings.Join(caseIDs, ",")
+	name := device.Name
+	if name == "" {
+		name = device.Identifier
+	}
+	warning = fmt.Sprintf("device %s is already registered in other open case(s): %s; rerun with --case-id to attach to the existing case", name, strings.Join(caseIDs, ", "))
+	return check, refs, warning
+}
@@ -0,0 +1,64 @@
+package devicedup
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestPrecheckFindsOtherOpenCases(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "inspector.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseA, err := store.EnsureCase(ctx, "", "A-001", "first", "op", "")
+	if err != nil {
+		t.Fatalf("EnsureCase A: %v", err)
+	}
+	caseB, err := store.EnsureCase(ctx, "", "B-001", "second", "op", "")
+	if err != nil {
+		t.Fatalf("EnsureCase B: %v", err)
+	}
+	laptop := model.Device{ID: "dev_a", Name: "laptop", OS: model.OSWindows, Identifier: "ident-1"}
+	if err := store.UpsertDevice(ctx, caseA, laptop, true, ""); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+
+	again := model.Device{ID: "dev_b", Name: "laptop", OS: model.OSWindows, Identifier: "ident-1"}
+	check, refs, warning := Precheck(ctx, store, caseB, "host", again)
+	if check.Status != model.PrecheckFailed || check.Required || check.Message != caseA || warning == "" {
+		t.Fatalf("check=%+v warning=%q", check, warning)
+	}
+	if len(refs) != 1 || refs[0].CaseID != caseA || refs[0].CaseNo != "A-001" || refs[0].DeviceID != "dev_a" {
+		t.Fatalf("refs=%+v", refs)
+	}
+
+	// 当前案件自身的登记不算重复。
+	if check, _, _ := Precheck(ctx, store, caseA, "host", laptop); check.Status != model.PrecheckPassed {
+		t.Fatalf("same case check=%+v", check)
+	}
+	// 无标识时跳过。
+	if check, _, _ := Precheck(ctx, store, caseB, "mobile", model.Device{ID: "dev_c"}); check.Status != model.PrecheckSkipped {
+		t.Fatalf("no identifier check=%+v", check)
+	}
+	// 已结案件不提示。
+	if _, err := db.ExecContext(ctx, `UPDATE cases SET status = 'closed' WHERE case_id = ?`, caseA); err != nil {
+		t.Fatalf("close case: %v", err)
+	}
+	if check, refs, _ := Precheck(ctx, store, caseB, "host", again); check.Status != model.PrecheckPassed || len(refs) != 0 {
+		t.Fatalf("closed case check=%+v refs=%+v", check, refs)
+	}
+}
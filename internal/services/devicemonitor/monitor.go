@@ -0,0 +1,206 @@
+package devicemonitor
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"crypto-inspector/internal/adapters/mobile"
+)
+
+// 移动设备实时监测
+//
+// serve --monitor 时后台按固定间隔轮询 adb devices / idevice_id -l / hdc list targets，与上一轮结果比对后生成事件：
+// - device_connected：新设备接入
+// - device_disconnected：设备拔出
+// - authorization_changed：授权状态变化（例如 Android 从 unauthorized 变为 device，即对方点了“允许 USB 调试”）
+// 事件推送给所有订阅者（webapp 以 SSE 转发给 UI）；订阅者消费过慢时丢弃事件，由快照接口重新对齐。
+
+// 事件类型。
+const (
+	EventConnected            = "device_connected"
+	EventDisconnected         = "device_disconnected"
+	EventAuthorizationChanged = "authorization_changed"
+)
+
+// DefaultInterval 是默认轮询间隔。
+const DefaultInterval = 2 * time.Second
+
+// probeTimeout 限制单轮探测时长，避免 idevicepair 卡住时阻塞后续轮询。
+const probeTimeout = 10 * time.Second
+
+// subscriberBuffer 是每个订阅者的事件缓冲。
+const subscriberBuffer = 32
+
+// Event 是一次设备状态变化。
+type Event struct {
+	Type   string             `json:"type"`
+	Device mobile.DeviceState `json:"device"`
+	// PrevState 为变化前的状态（仅 authorization_changed / device_disconnected）。
+	PrevState string `json:"prev_state,omitempty"`
+	At        int64  `json:"at"`
+}
+
+// Snapshot 是当前已知的设备列表。
+type Snapshot struct {
+	Devices    []mobile.DeviceState `json:"devices"`
+	Warnings   []string             `json:"warnings"`
+	PolledAt   int64                `json:"polled_at"`
+	IntervalMS int64                `json:"interval_ms"`
+}
+
+// ProbeFunc 返回一轮探测的设备状态与告警（默认 mobile.Probe）。
+type ProbeFunc func(ctx context.Context) ([]mobile.DeviceState, []string)
+
+// Monitor 轮询设备状态并向订阅者广播变化。
+type Monitor struct {
+	interval time.Duration
+	probe    ProbeFunc
+
+	mu       sync.Mutex
+	devices  map[string]mobile.DeviceState
+	warnings []string
+	polledAt int64
+	subs     map[chan Event]struct{}
+}
+
+// New 创建监测器；interval<=0 时使用 DefaultInterval，probe 为 nil 时探测 Android 与 iOS。
+func New(interval time.Duration, probe ProbeFunc) *Monitor {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if probe == nil {
+		probe = func(ctx context.Context) ([]mobile.DeviceState, []string) {
+			return mobile.Probe(ctx, true, true, true)
+		}
+	}
+	return &Monitor{
+		interval: interval,
+		probe:    probe,
+		devices:  map[string]mobile.DeviceState{},
+		subs:     map[chan Event]struct{}{},
+	}
+}
+
+// Run 立即探测一次，之后按间隔轮询，直到 ctx 结束。
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll 执行一轮探测，更新快照并广播变化事件（返回本轮事件）。
+func (m *Monitor) Poll(ctx context.Context) []Event {
+	probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+	devices, warnings := m.probe(probeCtx)
+	cancel()
+	if ctx.Err() != nil {
+		return nil
+	}
+	now := time.Now().Unix()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	events := Diff(m.devices, devices, now)
+	m.devices = make(map[string]mobile.DeviceState, len(devices))
+	for _, d := range devices {
+		m.devices[key(d)] = d
+	}
+	m.warnings = warnings
+	m.polledAt = now
+	for _, ev := range events {
+		for ch := range m.subs {
+			select {
+			case ch <- ev:
+			default:
+			}
+		}
+	}
+	return events
+}
+
+// Snapshot 返回当前已知设备（按系统与标识排序）。
+func (m *Monitor) Snapshot() Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := Snapshot{
+		Devices:    make([]mobile.DeviceState, 0, len(m.devices)),
+		Warnings:   append([]string{}, m.warnings...),
+		PolledAt:   m.polledAt,
+		IntervalMS: m.interval.Milliseconds(),
+	}
+	for _, d := range m.devices {
+		out.Devices = append(out.Devices, d)
+	}
+	sortDevices(out.Devices)
+	return out
+}
+
+// Subscribe 注册一个事件订阅；返回的 cancel 必须调用以释放订阅。
+func (m *Monitor) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	m.mu.Lock()
+	m.subs[ch] = struct{}{}
+	m.mu.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			m.mu.Lock()
+			delete(m.subs, ch)
+			m.mu.Unlock()
+		})
+	}
+}
+
+// Diff 比较上一轮与本轮的设备状态，按 接入/授权变化/拔出 顺序返回事件。
+func Diff(prev map[string]mobile.DeviceState, cur []mobile.DeviceState, at int64) []Event {
+	sorted := append([]mobile.DeviceState{}, cur...)
+	sortDevices(sorted)
+
+	var events []Event
+	seen := make(map[string]bool, len(sorted))
+	for _, d := range sorted {
+		k := key(d)
+		seen[k] = true
+		old, ok := prev[k]
+		switch {
+		case !ok:
+			events = append(events, Event{Type: EventConnected, Device: d, At: at})
+		case old.State != d.State || old.Authorized != d.Authorized:
+			events = append(events, Event{Type: EventAuthorizationChanged, Device: d, PrevState: old.State, At: at})
+		}
+	}
+
+	var gone []mobile.DeviceState
+	for k, d := range prev {
+		if !seen[k] {
+			gone = append(gone, d)
+		}
+	}
+	sortDevices(gone)
+	for _, d := range gone {
+		events = append(events, Event{Type: EventDisconnected, Device: d, PrevState: d.State, At: at})
+	}
+	return events
+}
+
+func key(d mobile.DeviceState) string {
+	return string(d.OS) + "\x00" + d.Identifier
+}
+
+func sortDevices(list []mobile.DeviceState) {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].OS != list[j].OS {
+			return list[i].OS < list[j].OS
+		}
+		return list[i].Identifier < list[j].Identifier
+	})
+}
@@ -0,0 +1,54 @@
+package devicemonitor
+
+import (
+	"context"
+	"testing"
+
+	"crypto-inspector/internal/adapters/mobile"
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestPollEmitsConnectAuthorizeDisconnect(t *testing.T) {
+	rounds := [][]mobile.DeviceState{
+		{{OS: model.OSAndroid, Identifier: "R58M", State: "unauthorized"}},
+		{{OS: model.OSAndroid, Identifier: "R58M", State: "device", Authorized: true}},
+		{{OS: model.OSAndroid, Identifier: "R58M", State: "device", Authorized: true}},
+		{},
+	}
+	i := 0
+	m := New(0, func(ctx context.Context) ([]mobile.DeviceState, []string) {
+		out := rounds[i]
+		i++
+		return out, nil
+	})
+	events, cancel := m.Subscribe()
+	defer cancel()
+
+	want := []struct {
+		typ       string
+		state     string
+		prevState string
+	}{
+		{EventConnected, "unauthorized", ""},
+		{EventAuthorizationChanged, "device", "unauthorized"},
+		{EventDisconnected, "device", "device"},
+	}
+	var got []Event
+	for range rounds {
+		got = append(got, m.Poll(context.Background())...)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events=%+v", got)
+	}
+	for j, w := range want {
+		if got[j].Type != w.typ || got[j].Device.State != w.state || got[j].PrevState != w.prevState {
+			t.Fatalf("event[%d]=%+v want %+v", j, got[j], w)
+		}
+		if ev := <-events; ev.Type != w.typ {
+			t.Fatalf("subscriber event[%d]=%+v", j, ev)
+		}
+	}
+	if snap := m.Snapshot(); len(snap.Devices) != 0 || snap.PolledAt == 0 || snap.IntervalMS != DefaultInterval.Milliseconds() {
+		t.Fatalf("snapshot=%+v", snap)
+	}
+}
@@ -0,0 +1,101 @@
+package devicesim
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/toolbox"
+)
+
+func TestRunnerOutputs(t *testing.T) {
+	r := NewRunner(&Fixture{
+		Android: []AndroidDevice{{Serial: "emu-1", Packages: []string{"io.metamask"}}},
+		IOS:     []IOSDevice{{UDID: "u1", Unpaired: true}},
+	})
+	ctx := toolbox.WithRunner(context.Background(), r)
+
+	if res, err := toolbox.ResolveContext(ctx, "adb"); err != nil || res.Source != toolbox.SourceSimulated {
+		t.Fatalf("resolve adb=%+v err=%v", res, err)
+	}
+	if _, err := toolbox.ResolveContext(ctx, "powershell"); err == nil {
+		t.Fatal("powershell should not resolve in simulated environment")
+	}
+	out, err := toolbox.CombinedOutput(ctx, "adb", "-s", "emu-1", "shell", "pm", "list", "packages")
+	if err != nil || string(out) != "package:io.metamask\n" {
+		t.Fatalf("pm list=%q err=%v", out, err)
+	}
+	if _, err := toolbox.CombinedOutput(ctx, "idevicepair", "-u", "u1", "validate"); err == nil {
+		t.Fatal("unpaired device should fail validate")
+	}
+
+	r.Handle("adb", []string{"-s", "emu-1", "shell", "getprop"}, func([]string) ([]byte, error) { return []byte("14\n"), nil })
+	if out, err := toolbox.CombinedOutput(ctx, "adb", "-s", "emu-1", "shell", "getprop", "ro.build.version.release"); err != nil || string(out) != "14\n" {
+		t.Fatalf("override=%q err=%v", out, err)
+	}
+	if calls := r.Calls(); len(calls) != 3 || calls[0].Name != "adb" {
+		t.Fatalf("calls=%+v", calls)
+	}
+}
+
+func TestRunScanMatchExport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("full scan flow")
+	}
+	dir := t.TempDir()
+	visited := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC).Unix()
+	f := &Fixture{
+		Android: []AndroidDevice{{
+			Serial:   "emulator-5554",
+			Packages: []string{"com.android.chrome", "io.metamask"},
+			History:  []Visit{{URL: "https://www.okx.com/", Title: "OKX", VisitedAt: visited}},
+		}},
+		IOS: []IOSDevice{{UDID: "00008110-000A1B2C3D4E5F60", Name: "Test iPhone", Apps: []string{"io.metamask"}}},
+		Hosts: []Host{{
+			Name: "win-pc",
+			Browsers: []BrowserProfile{{
+				History:    []Visit{{URL: "https://www.binance.com/en", Title: "Binance", VisitedAt: visited}},
+				Extensions: []string{"nkbihfbeogaeaoehlefnkodbefgpgknn"},
+			}},
+		}},
+	}
+	res, err := Run(context.Background(), f, Options{
+		DBPath:           filepath.Join(dir, "inspector.db"),
+		EvidenceRoot:     filepath.Join(dir, "evidence"),
+		WalletRulePath:   filepath.Join("..", "..", "..", "rules", "wallet_signatures.template.yaml"),
+		ExchangeRulePath: filepath.Join("..", "..", "..", "rules", "exchange_domains.template.yaml"),
+		RegexRulePath:    filepath.Join(dir, "regex_rules.yaml"), // 不存在时不启用
+		Export:           true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Mobile == nil || res.Mobile.AndroidCount != 1 || res.Mobile.IOSCount != 1 || len(res.Hosts) != 1 {
+		t.Fatalf("mobile=%+v hosts=%d", res.Mobile, len(res.Hosts))
+	}
+	if res.Hosts[0].CaseID != res.CaseID {
+		t.Fatalf("host case=%s want %s", res.Hosts[0].CaseID, res.CaseID)
+	}
+	walletDevices := map[string]bool{}
+	for _, h := range res.Hits {
+		if h.HitType == string(model.HitWalletInstalled) && h.RuleID == "wallet_metamask" {
+			walletDevices[h.DeviceID] = true
+		}
+	}
+	// Android 应用、iOS 应用、主机浏览器扩展各命中一次。
+	if len(walletDevices) != 3 {
+		t.Fatalf("metamask hits on %d devices, hits=%+v", len(walletDevices), res.Hits)
+	}
+	if !res.HasHit(model.HitExchangeVisited, "binance") || !res.HasHit(model.HitExchangeVisited, "okx") {
+		t.Fatalf("exchange hits missing: %+v", res.Hits)
+	}
+	if res.Export == nil || res.Export.ZipSHA256 == "" {
+		t.Fatalf("export=%+v", res.Export)
+	}
+	if _, err := os.Stat(res.Export.ZipPath); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,156 @@
+package devicesim
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// 模拟设备（端到端测试夹具）
+//
+// 夹具描述一组“被检设备”：
+// - Android / iOS / HarmonyOS 设备由 Runner 模拟 adb、libimobiledevice、hdc 的输出，经 toolbox.WithRunner
+//   注入后，移动端采集代码与接真机时走同一条路径（mobile.Scanner -> matcher -> 入库）
+// - 主机以离线目录的形式生成 Chromium 系浏览器 profile（History 库与扩展目录），走 scan offline 流程
+// 规则作者可以用 JSON 夹具描述目标应用/站点，配合 `dev simulate` 验证新规则能否命中，不需要真实设备。
+
+// Visit 是一条浏览记录或书签。
+type Visit struct {
+	URL       string `json:"url"`
+	Title     string `json:"title,omitempty"`
+	VisitedAt int64  `json:"visited_at,omitempty"` // unix 秒，0 表示未知
+}
+
+// AndroidAccount 是 dumpsys account 中的一个系统账户。
+type AndroidAccount struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// AndroidDevice 是一台经 adb 连接的 Android 设备。
+type AndroidDevice struct {
+	Serial string `json:"serial"`
+	// State 是 adb devices 中的状态（device / unauthorized / offline），为空视为 device（已授权）。
+	State     string           `json:"state,omitempty"`
+	Packages  []string         `json:"packages,omitempty"`
+	Accounts  []AndroidAccount `json:"accounts,omitempty"`
+	History   []Visit          `json:"history,omitempty"`
+	Bookmarks []Visit          `json:"bookmarks,omitempty"`
+}
+
+// IOSDevice 是一台经 libimobiledevice 连接的 iOS 设备。
+type IOSDevice struct {
+	UDID string `json:"udid"`
+	Name string `json:"name,omitempty"`
+	// Unpaired 为 true 时 idevicepair validate 失败（设备未信任本机）。
+	Unpaired bool     `json:"unpaired,omitempty"`
+	Apps     []string `json:"apps,omitempty"` // bundle id
+}
+
+// HarmonyDevice 是一台经 hdc 连接的 HarmonyOS 设备。
+type HarmonyDevice struct {
+	Key  string `json:"key"`
+	Name string `json:"name,omitempty"`
+	// State 是 hdc list targets 中的状态（connected / unauthorized / offline），为空视为 connected。
+	State   string   `json:"state,omitempty"`
+	Bundles []string `json:"bundles,omitempty"`
+}
+
+// BrowserProfile 是主机上一个 Chromium 系浏览器 profile。
+type BrowserProfile struct {
+	// Browser 为 chrome / edge / brave（决定 profile 所在目录），为空视为 chrome。
+	Browser string `json:"browser,omitempty"`
+	// Profile 是 profile 目录名，为空视为 Default。
+	Profile    string   `json:"profile,omitempty"`
+	History    []Visit  `json:"history,omitempty"`
+	Extensions []string `json:"extensions,omitempty"` // 扩展 ID
+}
+
+// Host 是一台以离线目录形式导入的主机。
+type Host struct {
+	Name string `json:"name"`
+	// OS 为 windows / macos，为空视为 windows。
+	OS       string           `json:"os,omitempty"`
+	User     string           `json:"user,omitempty"` // 用户目录名，为空视为 analyst
+	Browsers []BrowserProfile `json:"browsers,omitempty"`
+}
+
+// Fixture 是一组模拟设备。
+type Fixture struct {
+	Android []AndroidDevice `json:"android,omitempty"`
+	IOS     []IOSDevice     `json:"ios,omitempty"`
+	Harmony []HarmonyDevice `json:"harmony,omitempty"`
+	Hosts   []Host          `json:"hosts,omitempty"`
+}
+
+// HasMobile 判断夹具中是否有移动设备。
+func (f *Fixture) HasMobile() bool {
+	return len(f.Android)+len(f.IOS)+len(f.Harmony) > 0
+}
+
+// Validate 检查设备标识是否齐全且不重复。
+func (f *Fixture) Validate() error {
+	seen := map[string]bool{}
+	check := func(kind, key string) error {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return fmt.Errorf("%s device without identifier", kind)
+		}
+		if seen[kind+"\x00"+key] {
+			return fmt.Errorf("duplicate %s device: %s", kind, key)
+		}
+		seen[kind+"\x00"+key] = true
+		return nil
+	}
+	for _, d := range f.Android {
+		if err := check("android", d.Serial); err != nil {
+			return err
+		}
+	}
+	for _, d := range f.IOS {
+		if err := check("ios", d.UDID); err != nil {
+			return err
+		}
+	}
+	for _, d := range f.Harmony {
+		if err := check("harmony", d.Key); err != nil {
+			return err
+		}
+	}
+	for _, h := range f.Hosts {
+		if err := check("host", h.Name); err != nil {
+			return err
+		}
+		switch strings.ToLower(strings.TrimSpace(h.OS)) {
+		case "", "windows", "macos":
+		default:
+			return fmt.Errorf("host %s: unsupported os %q (windows|macos)", h.Name, h.OS)
+		}
+		for _, b := range h.Browsers {
+			if _, ok := chromiumDirs[browserOrDefault(b.Browser)]; !ok {
+				return fmt.Errorf("host %s: unsupported browser %q (chrome|edge|brave)", h.Name, b.Browser)
+			}
+		}
+	}
+	if !f.HasMobile() && len(f.Hosts) == 0 {
+		return fmt.Errorf("fixture has no devices")
+	}
+	return nil
+}
+
+// LoadFixture 读取 JSON 夹具文件。
+func LoadFixture(path string) (*Fixture, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fixture: %w", err)
+	}
+	var f Fixture
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("parse fixture: %w", err)
+	}
+	if err := f.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid fixture: %w", err)
+	}
+	return &f, nil
+}
@@ -0,0 +1,131 @@
+package devicesim
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+// chromiumDirs 是各浏览器 User Data 相对用户目录的位置（windows / macos），与现场拷贝出的目录结构一致，
+// 离线采集按路径识别浏览器（见 host.chromiumBrowserFromPath）。
+var chromiumDirs = map[string][2]string{
+	"chrome": {
+		"AppData/Local/Google/Chrome/User Data",
+		"Library/Application Support/Google/Chrome",
+	},
+	"edge": {
+		"AppData/Local/Microsoft/Edge/User Data",
+		"Library/Application Support/Microsoft Edge",
+	},
+	"brave": {
+		"AppData/Local/BraveSoftware/Brave-Browser/User Data",
+		"Library/Application Support/BraveSoftware/Brave-Browser",
+	},
+}
+
+// chromeEpochOffset 是 1601-01-01 到 1970-01-01 的秒数（Chromium visit_time 为 1601 起的微秒）。
+const chromeEpochOffset = 11644473600
+
+func browserOrDefault(b string) string {
+	if b = strings.ToLower(strings.TrimSpace(b)); b != "" {
+		return b
+	}
+	return "chrome"
+}
+
+// hostOS 返回主机系统（windows / macos）。
+func hostOS(h Host) string {
+	if os := strings.ToLower(strings.TrimSpace(h.OS)); os != "" {
+		return os
+	}
+	return "windows"
+}
+
+// WriteHostTree 在 dir 下按主机系统的目录布局生成浏览器 profile：
+//
+//	Users/<user>/<User Data>/<profile>/History
+//	Users/<user>/<User Data>/<profile>/Extensions/<id>/1.0.0/manifest.json
+func WriteHostTree(dir string, h Host) error {
+	user := strings.TrimSpace(h.User)
+	if user == "" {
+		user = "analyst"
+	}
+	idx := 0
+	if hostOS(h) == "macos" {
+		idx = 1
+	}
+	for _, b := range h.Browsers {
+		dirs, ok := chromiumDirs[browserOrDefault(b.Browser)]
+		if !ok {
+			return fmt.Errorf("unsupported browser %q", b.Browser)
+		}
+		profile := strings.TrimSpace(b.Profile)
+		if profile == "" {
+			profile = "Default"
+		}
+		profileDir := filepath.Join(dir, "Users", user, filepath.FromSlash(dirs[idx]), profile)
+		if err := os.MkdirAll(profileDir, 0o755); err != nil {
+			return err
+		}
+		if err := writeChromiumHistory(filepath.Join(profileDir, "History"), b.History); err != nil {
+			return fmt.Errorf("write history for %s/%s: %w", browserOrDefault(b.Browser), profile, err)
+		}
+		for _, ext := range b.Extensions {
+			if err := writeExtensionManifest(filepath.Join(profileDir, "Extensions"), ext); err != nil {
+				return fmt.Errorf("write extension %s: %w", ext, err)
+			}
+		}
+	}
+	return nil
+}
+
+// writeChromiumHistory 生成只含 urls / visits 两张表的 History 库（采集只读取这两张表）。
+func writeChromiumHistory(path string, visits []Visit) error {
+	_ = os.Remove(path)
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT NOT NULL, title TEXT)`,
+		`CREATE TABLE visits (id INTEGER PRIMARY KEY, url INTEGER NOT NULL, visit_time INTEGER NOT NULL, from_visit INTEGER, transition INTEGER)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	for i, v := range visits {
+		n := i + 1
+		if _, err := db.Exec(`INSERT INTO urls (id, url, title) VALUES (?, ?, ?)`, n, v.URL, v.Title); err != nil {
+			return err
+		}
+		// transition 1 = TYPED（地址栏输入）。
+		if _, err := db.Exec(`INSERT INTO visits (id, url, visit_time, from_visit, transition) VALUES (?, ?, ?, 0, 1)`,
+			n, n, (v.VisitedAt+chromeEpochOffset)*1_000_000); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeExtensionManifest(extRoot, id string) error {
+	id = strings.TrimSpace(id)
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return fmt.Errorf("invalid extension id")
+	}
+	verDir := filepath.Join(extRoot, id, "1.0.0")
+	if err := os.MkdirAll(verDir, 0o755); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(map[string]any{"manifest_version": 3, "name": id, "version": "1.0.0"})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(verDir, "manifest.json"), raw, 0o644)
+}
@@ -0,0 +1,195 @@
+package devicesim
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/toolbox"
+	"crypto-inspector/internal/services/forensicexport"
+	"crypto-inspector/internal/services/hostscan"
+	"crypto-inspector/internal/services/mobilescan"
+)
+
+// Options 是一次模拟扫描的参数（规则路径为空时使用默认配置）。
+type Options struct {
+	DBPath           string
+	EvidenceRoot     string
+	WalletRulePath   string
+	ExchangeRulePath string
+	RegexRulePath    string
+	CaseID           string // 为空时由首个扫描新建案件
+	Operator         string
+	// WorkDir 存放生成的主机离线目录，为空时使用临时目录并在结束后删除。
+	WorkDir string
+	// Export 为 true 时在扫描后生成取证 ZIP。
+	Export bool
+}
+
+// HitSummary 是一条命中的摘要（用于断言规则是否生效）。
+type HitSummary struct {
+	DeviceID     string  `json:"device_id"`
+	HitType      string  `json:"hit_type"`
+	RuleID       string  `json:"rule_id"`
+	MatchedValue string  `json:"matched_value"`
+	Confidence   float64 `json:"confidence"`
+}
+
+// Result 是模拟扫描的输出。
+type Result struct {
+	CaseID string                    `json:"case_id"`
+	Mobile *mobilescan.Result        `json:"mobile,omitempty"`
+	Hosts  []*hostscan.Result        `json:"hosts,omitempty"`
+	Hits   []HitSummary              `json:"hits"`
+	Export *forensicexport.ZipResult `json:"export,omitempty"`
+	// Calls 是移动端采集执行的模拟命令（按调用顺序），便于排查规则未命中的原因。
+	Calls []Call `json:"calls,omitempty"`
+}
+
+// Run 按夹具执行 扫描 → 匹配 → 入库 →（可选）导出 的完整流程：
+// 移动设备经 Runner 注入 mobilescan，主机生成离线目录后走 hostscan 离线模式，所有设备归入同一案件。
+func Run(ctx context.Context, f *Fixture, opts Options) (*Result, error) {
+	if f == nil {
+		return nil, fmt.Errorf("fixture is required")
+	}
+	if err := f.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid fixture: %w", err)
+	}
+	if strings.TrimSpace(opts.DBPath) == "" {
+		opts.DBPath = app.DefaultConfig().DBPath
+	}
+	if strings.TrimSpace(opts.Operator) == "" {
+		opts.Operator = "devicesim"
+	}
+	workDir := strings.TrimSpace(opts.WorkDir)
+	if workDir == "" && len(f.Hosts) > 0 {
+		tmp, err := os.MkdirTemp("", "devicesim-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(tmp)
+		workDir = tmp
+	}
+
+	res := &Result{CaseID: strings.TrimSpace(opts.CaseID)}
+	if f.HasMobile() {
+		runner := NewRunner(f)
+		mr, err := mobilescan.Run(toolbox.WithRunner(ctx, runner), mobilescan.Options{
+			DBPath:           opts.DBPath,
+			EvidenceRoot:     opts.EvidenceRoot,
+			WalletRulePath:   opts.WalletRulePath,
+			ExchangeRulePath: opts.ExchangeRulePath,
+			RegexRulePath:    opts.RegexRulePath,
+			CaseID:           res.CaseID,
+			Operator:         opts.Operator,
+			Note:             "devicesim",
+			EnableAndroid:    len(f.Android) > 0,
+			EnableIOS:        len(f.IOS) > 0,
+			EnableHarmony:    len(f.Harmony) > 0,
+		})
+		res.Calls = runner.Calls()
+		if err != nil {
+			return res, fmt.Errorf("mobile scan: %w", err)
+		}
+		res.Mobile = mr
+		res.CaseID = mr.CaseID
+	}
+
+	for _, h := range f.Hosts {
+		dir := filepath.Join(workDir, safeDirName(h.Name))
+		if err := os.RemoveAll(dir); err != nil {
+			return res, err
+		}
+		if err := WriteHostTree(dir, h); err != nil {
+			return res, fmt.Errorf("host %s: %w", h.Name, err)
+		}
+		hr, err := hostscan.Run(ctx, hostscan.Options{
+			DBPath:           opts.DBPath,
+			EvidenceRoot:     opts.EvidenceRoot,
+			WalletRulePath:   opts.WalletRulePath,
+			ExchangeRulePath: opts.ExchangeRulePath,
+			RegexRulePath:    opts.RegexRulePath,
+			CaseID:           res.CaseID,
+			Operator:         opts.Operator,
+			Note:             "devicesim",
+			OfflineInputDir:  dir,
+			OfflineOS:        hostOS(h),
+			DeviceName:       h.Name,
+		})
+		if err != nil {
+			return res, fmt.Errorf("host %s: %w", h.Name, err)
+		}
+		res.Hosts = append(res.Hosts, hr)
+		res.CaseID = hr.CaseID
+	}
+
+	db, err := sql.Open("sqlite", opts.DBPath)
+	if err != nil {
+		return res, err
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	_, _ = db.ExecContext(ctx, `PRAGMA busy_timeout = 5000`)
+	store := sqliteadapter.NewStore(db)
+
+	hits, err := store.ListCaseHitDetails(ctx, res.CaseID, "")
+	if err != nil {
+		return res, err
+	}
+	res.Hits = make([]HitSummary, 0, len(hits))
+	for _, h := range hits {
+		res.Hits = append(res.Hits, HitSummary{
+			DeviceID:     h.DeviceID,
+			HitType:      h.HitType,
+			RuleID:       h.RuleID,
+			MatchedValue: h.MatchedValue,
+			Confidence:   h.Confidence,
+		})
+	}
+
+	if opts.Export {
+		zr, err := forensicexport.GenerateForensicZip(ctx, store, forensicexport.ZipOptions{
+			CaseID:           res.CaseID,
+			DBPath:           opts.DBPath,
+			EvidenceRoot:     opts.EvidenceRoot,
+			WalletRulePath:   opts.WalletRulePath,
+			ExchangeRulePath: opts.ExchangeRulePath,
+			Operator:         opts.Operator,
+		})
+		if err != nil {
+			return res, fmt.Errorf("export: %w", err)
+		}
+		res.Export = zr
+	}
+	return res, nil
+}
+
+// HasHit 判断结果中是否有指定类型与规则的命中（hitType 为空时不限类型）。
+func (r *Result) HasHit(hitType model.HitType, ruleID string) bool {
+	for _, h := range r.Hits {
+		if (hitType == "" || h.HitType == string(hitType)) && h.RuleID == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
+// safeDirName 把主机名转换为可用作目录名的字符串。
+func safeDirName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" || name == "." || name == ".." {
+		return "host"
+	}
+	return name
+}
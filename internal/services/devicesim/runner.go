@@ -0,0 +1,294 @@
+package devicesim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"crypto-inspector/internal/platform/toolbox"
+)
+
+// errExit 模拟外部命令以非零状态退出（输出内容作为错误信息返回给调用方，与 exec 的行为一致）。
+var errExit = errors.New("exit status 1")
+
+// Handler 处理一条模拟命令（args 不含工具名），返回命令输出；返回错误表示命令执行失败。
+type Handler func(args []string) ([]byte, error)
+
+// Call 是一次命令调用记录。
+type Call struct {
+	Name string   `json:"name"`
+	Args []string `json:"args"`
+}
+
+type override struct {
+	name   string
+	prefix []string
+	h      Handler
+}
+
+// Runner 按夹具模拟 adb / libimobiledevice / hdc 的输出（实现 toolbox.Runner）。
+//
+// toolbox.Known 中的工具均视为已安装（来源 simulated）；其他命令（powershell、security 等）按“未安装”返回错误，
+// 采集代码会按工具缺失的路径降级，与现场缺少该工具时一致。
+type Runner struct {
+	fixture *Fixture
+
+	mu        sync.Mutex
+	overrides []override
+	calls     []Call
+}
+
+// NewRunner 创建模拟执行器。
+func NewRunner(f *Fixture) *Runner {
+	if f == nil {
+		f = &Fixture{}
+	}
+	return &Runner{fixture: f}
+}
+
+// Handle 为 name 工具中参数以 prefix 开头的命令注册自定义输出，优先于内置模拟（后注册的优先）。
+// 用于模拟内置夹具未覆盖的输出，例如某款 ROM 的 dumpsys 格式或 idevicebackup2 生成的备份目录。
+func (r *Runner) Handle(name string, prefix []string, h Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.overrides = append(r.overrides, override{name: name, prefix: prefix, h: h})
+}
+
+// Calls 返回已执行命令的副本（按调用顺序）。
+func (r *Runner) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.calls)
+}
+
+func (r *Runner) Resolve(name string) (*toolbox.Resolved, error) {
+	if !slices.Contains(toolbox.Known, name) && !r.overridden(name) {
+		return nil, fmt.Errorf("%s not found (simulated environment)", name)
+	}
+	return &toolbox.Resolved{Name: name, Path: "sim://" + name, Source: toolbox.SourceSimulated, Version: "simulated"}, nil
+}
+
+func (r *Runner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return r.run(ctx, name, args)
+}
+
+func (r *Runner) CombinedOutput(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return r.run(ctx, name, args)
+}
+
+func (r *Runner) overridden(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, o := range r.overrides {
+		if o.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Runner) run(ctx context.Context, name string, args []string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	name = strings.TrimSuffix(filepath.Base(name), ".exe")
+	r.mu.Lock()
+	r.calls = append(r.calls, Call{Name: name, Args: slices.Clone(args)})
+	var h Handler
+	for i := len(r.overrides) - 1; i >= 0; i-- {
+		o := r.overrides[i]
+		if o.name == name && len(args) >= len(o.prefix) && slices.Equal(args[:len(o.prefix)], o.prefix) {
+			h = o.h
+			break
+		}
+	}
+	r.mu.Unlock()
+	if h != nil {
+		return h(args)
+	}
+
+	switch name {
+	case "adb":
+		return r.adb(args)
+	case "idevice_id", "idevicepair", "ideviceinfo", "ideviceinstaller":
+		return r.idevice(name, args)
+	case "hdc":
+		return r.hdc(args)
+	}
+	return unsupported(name, args)
+}
+
+func unsupported(name string, args []string) ([]byte, error) {
+	return []byte(fmt.Sprintf("%s %s: not simulated", name, strings.Join(args, " "))), errExit
+}
+
+// adb 模拟 adb devices、pm list packages、dumpsys account / package 与 content query。
+func (r *Runner) adb(args []string) ([]byte, error) {
+	if slices.Equal(args, []string{"devices"}) {
+		var b strings.Builder
+		b.WriteString("List of devices attached\n")
+		for _, d := range r.fixture.Android {
+			fmt.Fprintf(&b, "%s\t%s\n", d.Serial, androidState(d))
+		}
+		return []byte(b.String()), nil
+	}
+	if len(args) < 3 || args[0] != "-s" || args[2] != "shell" {
+		return unsupported("adb", args)
+	}
+	var dev *AndroidDevice
+	for i := range r.fixture.Android {
+		if r.fixture.Android[i].Serial == args[1] {
+			dev = &r.fixture.Android[i]
+		}
+	}
+	if dev == nil {
+		return []byte(fmt.Sprintf("adb: device '%s' not found", args[1])), errExit
+	}
+	if androidState(*dev) != "device" {
+		return []byte("adb: device unauthorized."), errExit
+	}
+	shell := args[3:]
+	switch {
+	case slices.Equal(shell, []string{"pm", "list", "packages"}):
+		var b strings.Builder
+		for _, p := range dev.Packages {
+			fmt.Fprintf(&b, "package:%s\n", p)
+		}
+		return []byte(b.String()), nil
+	case slices.Equal(shell, []string{"dumpsys", "account"}):
+		var b strings.Builder
+		fmt.Fprintf(&b, "User UserInfo{0:Owner:c13} :\n  Accounts: %d\n", len(dev.Accounts))
+		for _, a := range dev.Accounts {
+			fmt.Fprintf(&b, "    Account {name=%s, type=%s}\n", a.Name, a.Type)
+		}
+		return []byte(b.String()), nil
+	case slices.Equal(shell, []string{"dumpsys", "package", "r"}):
+		return []byte("Activity Resolver Table:\n  Non-Data Actions:\n"), nil
+	case len(shell) >= 4 && shell[0] == "content" && shell[1] == "query" && shell[2] == "--uri":
+		// 只模拟 AOSP Browser provider（采集代码最先尝试的候选）；其余 provider 按无权限返回。
+		if shell[3] != "content://browser/bookmarks" {
+			return []byte("Error while accessing provider:" + strings.TrimPrefix(shell[3], "content://") + "\njava.lang.SecurityException: Permission Denial"), errExit
+		}
+		visits, bookmark := dev.History, 0
+		if slices.Contains(shell, "bookmark=1") {
+			visits, bookmark = dev.Bookmarks, 1
+		}
+		if len(visits) == 0 {
+			return []byte("No result found.\n"), nil
+		}
+		var b strings.Builder
+		for i, v := range visits {
+			fmt.Fprintf(&b, "Row: %d _id=%d, url=%s, visits=1, date=%d, bookmark=%d, title=%s\n", i, i+1, v.URL, v.VisitedAt*1000, bookmark, v.Title)
+		}
+		return []byte(b.String()), nil
+	}
+	return unsupported("adb", args)
+}
+
+func androidState(d AndroidDevice) string {
+	if s := strings.TrimSpace(d.State); s != "" {
+		return s
+	}
+	return "device"
+}
+
+// idevice 模拟 idevice_id -l、idevicepair validate、ideviceinfo 与 ideviceinstaller -l。
+func (r *Runner) idevice(name string, args []string) ([]byte, error) {
+	if name == "idevice_id" {
+		if !slices.Equal(args, []string{"-l"}) {
+			return unsupported(name, args)
+		}
+		var b strings.Builder
+		for _, d := range r.fixture.IOS {
+			fmt.Fprintf(&b, "%s\n", d.UDID)
+		}
+		return []byte(b.String()), nil
+	}
+	if len(args) < 2 || args[0] != "-u" {
+		return unsupported(name, args)
+	}
+	var dev *IOSDevice
+	for i := range r.fixture.IOS {
+		if r.fixture.IOS[i].UDID == args[1] {
+			dev = &r.fixture.IOS[i]
+		}
+	}
+	if dev == nil {
+		return []byte(fmt.Sprintf("ERROR: Device %s not found!", args[1])), errExit
+	}
+	rest := args[2:]
+	switch {
+	case name == "idevicepair" && slices.Equal(rest, []string{"validate"}):
+		if dev.Unpaired {
+			return []byte(fmt.Sprintf("ERROR: Device %s is not paired with this host", dev.UDID)), errExit
+		}
+		return []byte(fmt.Sprintf("SUCCESS: Validated pairing with device %s", dev.UDID)), nil
+	case dev.Unpaired:
+		return []byte("ERROR: Could not connect to lockdownd: Pairing dialog response pending (-19)"), errExit
+	case name == "ideviceinfo" && slices.Equal(rest, []string{"-k", "DeviceName"}):
+		n := dev.Name
+		if n == "" {
+			n = "iPhone"
+		}
+		return []byte(n + "\n"), nil
+	case name == "ideviceinfo" && slices.Equal(rest, []string{"-q", "com.apple.disk_usage"}):
+		return []byte("TotalDataAvailable: 32000000000\nTotalDataCapacity: 64000000000\n"), nil
+	case name == "ideviceinstaller" && slices.Equal(rest, []string{"-l"}):
+		var b strings.Builder
+		for _, a := range dev.Apps {
+			fmt.Fprintf(&b, "%s - %s\n", a, a)
+		}
+		return []byte(b.String()), nil
+	}
+	return unsupported(name, args)
+}
+
+// hdc 模拟 hdc list targets -v、bm dump -a 与 param get。
+func (r *Runner) hdc(args []string) ([]byte, error) {
+	if slices.Equal(args, []string{"list", "targets", "-v"}) {
+		if len(r.fixture.Harmony) == 0 {
+			return []byte("[Empty]\n"), nil
+		}
+		var b strings.Builder
+		for _, d := range r.fixture.Harmony {
+			state := strings.TrimSpace(d.State)
+			if state == "" {
+				state = "connected"
+			}
+			fmt.Fprintf(&b, "%s\tUSB\t%s%s\tlocalhost\thdc\n", d.Key, strings.ToUpper(state[:1]), state[1:])
+		}
+		return []byte(b.String()), nil
+	}
+	if len(args) < 3 || args[0] != "-t" || args[2] != "shell" {
+		return unsupported("hdc", args)
+	}
+	var dev *HarmonyDevice
+	for i := range r.fixture.Harmony {
+		if r.fixture.Harmony[i].Key == args[1] {
+			dev = &r.fixture.Harmony[i]
+		}
+	}
+	if dev == nil {
+		return []byte("[Fail]ExecuteCommand need connect-key"), errExit
+	}
+	shell := args[3:]
+	switch {
+	case slices.Equal(shell, []string{"bm", "dump", "-a"}):
+		var b strings.Builder
+		b.WriteString("ID: 100:\n")
+		for _, bn := range dev.Bundles {
+			fmt.Fprintf(&b, "\t%s\n", bn)
+		}
+		return []byte(b.String()), nil
+	case slices.Equal(shell, []string{"param", "get", "const.product.name"}):
+		if dev.Name == "" {
+			return []byte("Get parameter \"const.product.name\" fail! errNum is:106!\n"), nil
+		}
+		return []byte(dev.Name + "\n"), nil
+	}
+	return unsupported("hdc", args)
+}
@@ -0,0 +1,536 @@
+package devseed
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/adapters/rules"
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/snapshot"
+	"crypto-inspector/internal/services/matcher"
+)
+
+// 合成案件（开发 / 演示 / 压测用）
+//
+// 按 profile 生成虚构的设备、证据快照、规则命中、审计链与内部报告，不需要扫描真实机器。
+// 同一 case_id + seed 生成的设备、证据、命中（含 ID 与时间）完全一致；报告 ID 与审计时间为实际写入时生成。
+// 证据的 acquisition_method 固定为 synthetic、collector_name 为 dev_seed，避免与真实取证数据混淆。
+
+const (
+	ProfileDemo = "demo"
+	ProfileLoad = "load"
+
+	CollectorName     = "dev_seed"
+	CollectorVersion  = "0.1.0"
+	AcquisitionMethod = "synthetic"
+	generatorVersion  = "devseed-0.1.0"
+)
+
+// Profile 描述一个合成案件的规模。
+type Profile struct {
+	HostDevices     int
+	MobileDevices   int
+	VisitsPerDevice int
+	NoiseApps       int
+}
+
+var profiles = map[string]Profile{
+	// demo：界面开发与演示，几台设备、少量记录。
+	ProfileDemo: {HostDevices: 2, MobileDevices: 1, VisitsPerDevice: 60, NoiseApps: 12},
+	// load：压测列表分页、导出与匹配性能。
+	ProfileLoad: {HostDevices: 20, MobileDevices: 10, VisitsPerDevice: 5000, NoiseApps: 200},
+}
+
+// Options 是合成参数。
+type Options struct {
+	Profile      string
+	Seed         int64
+	CaseID       string // 为空时按 profile + seed 生成
+	EvidenceRoot string
+	ReportDir    string
+	Operator     string
+	// Rules 决定生成哪些钱包/交易所线索；为空时使用内置的小规则集。
+	Rules *rules.LoadedRules
+}
+
+// Result 是合成结果摘要。
+type Result struct {
+	CaseID    string   `json:"case_id"`
+	Profile   string   `json:"profile"`
+	Seed      int64    `json:"seed"`
+	Devices   int      `json:"devices"`
+	Artifacts int      `json:"artifacts"`
+	Hits      int      `json:"hits"`
+	ReportIDs []string `json:"report_ids"`
+}
+
+// ParseProfile 规范化 profile 名称（空视为 demo）。
+func ParseProfile(name string) (string, Profile, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = ProfileDemo
+	}
+	p, ok := profiles[name]
+	if !ok {
+		return "", Profile{}, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid seed profile %q (demo|load)", name))
+	}
+	return name, p, nil
+}
+
+// seeder 持有一次合成的随机源与确定性 ID 计数器。
+type seeder struct {
+	rng     *rand.Rand
+	caseID  string
+	counter int
+	base    int64
+}
+
+// newID 生成与 case_id、生成顺序绑定的确定性 ID。
+func (s *seeder) newID(prefix string) string {
+	s.counter++
+	return prefix + "_" + hash.Text(s.caseID, prefix, fmt.Sprintf("%d", s.counter))[:16]
+}
+
+// at 返回基准时间之后的随机时间点（maxOffset 秒内）。
+func (s *seeder) at(maxOffset int64) int64 {
+	return s.base + s.rng.Int63n(maxOffset)
+}
+
+func (s *seeder) pick(items []string) string {
+	return items[s.rng.Intn(len(items))]
+}
+
+// Seed 生成一个合成案件。case_id 已存在时返回 CodeConflict，不覆盖已有数据。
+func Seed(ctx context.Context, store *sqliteadapter.Store, opts Options) (*Result, error) {
+	profileName, profile, err := ParseProfile(opts.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(opts.EvidenceRoot) == "" || strings.TrimSpace(opts.ReportDir) == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "evidence root and report dir are required")
+	}
+	caseID := strings.TrimSpace(opts.CaseID)
+	if caseID == "" {
+		caseID = fmt.Sprintf("case_seed_%s_%d", profileName, opts.Seed)
+	}
+	if ov, err := store.GetCaseOverview(ctx, caseID); err != nil {
+		return nil, err
+	} else if ov != nil {
+		return nil, apperr.New(apperr.CodeConflict, fmt.Sprintf("case already exists: %s", caseID))
+	}
+	operator := strings.TrimSpace(opts.Operator)
+	if operator == "" {
+		operator = "dev_seed"
+	}
+	loaded := opts.Rules
+	if loaded == nil {
+		loaded = builtinRules()
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	s := &seeder{
+		rng:    rng,
+		caseID: caseID,
+		// 基准时间固定在 2024 年上半年内，由 seed 决定。
+		base: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix() + rng.Int63n(180*86400),
+	}
+
+	title := fmt.Sprintf("合成案件（%s, seed=%d）", profileName, opts.Seed)
+	if _, err := store.EnsureCase(ctx, caseID, fmt.Sprintf("SEED-%s-%d", strings.ToUpper(profileName), opts.Seed), title, operator, "synthetic data generated by inspector-cli dev seed; not real evidence"); err != nil {
+		return nil, err
+	}
+	_ = store.AppendAudit(ctx, caseID, "", "dev_seed", "seed_case", "started", operator, "devseed.Seed", map[string]any{
+		"profile": profileName,
+		"seed":    opts.Seed,
+	})
+
+	res := &Result{CaseID: caseID, Profile: profileName, Seed: opts.Seed}
+	var devices []model.Device
+	var allArtifacts []model.Artifact
+	var allHits []model.RuleHit
+	total := profile.HostDevices + profile.MobileDevices
+	for i := 0; i < total; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		mobile := i >= profile.HostDevices
+		dev, arts, err := s.device(i, mobile, profile, loaded, opts.EvidenceRoot)
+		if err != nil {
+			return nil, err
+		}
+		var mr *matcher.HostMatchResult
+		if mobile {
+			mr, err = matcher.MatchMobileArtifacts(loaded, arts)
+		} else {
+			mr, err = matcher.MatchHostArtifacts(loaded, arts)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("match synthetic artifacts: %w", err)
+		}
+		hits := s.stabilizeHits(mr.Hits, arts[0].CollectedAt)
+
+		scanType := "host_scan"
+		if mobile {
+			scanType = "mobile_scan"
+		}
+		if err := store.UpsertDevice(ctx, caseID, dev, true, "synthetic device"); err != nil {
+			return nil, err
+		}
+		if _, err := store.SaveScanRun(ctx, caseID, dev.ID, operator, arts[0].CollectedAt, model.ScanOptions{
+			ScanType:            scanType,
+			Profile:             "internal",
+			AppVersion:          app.Version,
+			PrivacyMode:         "off",
+			SnapshotCompression: snapshot.CompressionNone,
+			EvidenceRoot:        opts.EvidenceRoot,
+		}); err != nil {
+			return nil, err
+		}
+		_ = store.AppendAudit(ctx, caseID, dev.ID, scanType, "scan_start", "started", operator, "devseed.Seed", map[string]any{"synthetic": true})
+		if err := store.SaveArtifacts(ctx, arts); err != nil {
+			return nil, err
+		}
+		if err := store.SaveRuleHits(ctx, hits); err != nil {
+			return nil, err
+		}
+		_ = store.AppendAudit(ctx, caseID, dev.ID, scanType, "scan_finish", "success", operator, "devseed.Seed", map[string]any{
+			"artifacts": len(arts),
+			"hits":      len(hits),
+			"synthetic": true,
+		})
+
+		devices = append(devices, dev)
+		allArtifacts = append(allArtifacts, arts...)
+		allHits = append(allHits, hits...)
+	}
+	res.Devices = len(devices)
+	res.Artifacts = len(allArtifacts)
+	res.Hits = len(allHits)
+
+	reportIDs, err := s.writeReports(ctx, store, opts.ReportDir, profileName, opts.Seed, devices, allArtifacts, allHits)
+	if err != nil {
+		return nil, err
+	}
+	res.ReportIDs = reportIDs
+
+	_ = store.AppendAudit(ctx, caseID, "", "dev_seed", "seed_case", "success", operator, "devseed.Seed", map[string]any{
+		"profile":   profileName,
+		"seed":      opts.Seed,
+		"devices":   res.Devices,
+		"artifacts": res.Artifacts,
+		"hits":      res.Hits,
+	})
+	return res, nil
+}
+
+var (
+	noiseApps = []string{
+		"Google Chrome", "Microsoft Edge", "WeChat", "WPS Office", "7-Zip", "VLC media player", "Visual Studio Code",
+		"Zoom", "Notepad++", "Tencent Meeting", "Foxmail", "Adobe Acrobat Reader", "NetEase Cloud Music", "Python 3.11",
+	}
+	noiseDomains = []string{
+		"www.baidu.com", "news.qq.com", "github.com", "www.bilibili.com", "mail.163.com", "www.zhihu.com",
+		"map.baidu.com", "www.jd.com", "weibo.com", "www.taobao.com", "stackoverflow.com", "docs.python.org",
+	}
+	noisePackages = []string{
+		"com.tencent.mm", "com.eg.android.alipaygphone", "com.ss.android.ugc.aweme", "com.taobao.taobao",
+		"com.sina.weibo", "com.netease.cloudmusic", "com.autonavi.minimap", "tv.danmaku.bili",
+	}
+	hostBrowsers = []string{"chrome", "edge"}
+)
+
+// device 生成一台设备及其证据（主机：安装软件 / 浏览器扩展 / 浏览历史；移动端：应用清单 / 浏览历史）。
+func (s *seeder) device(i int, mobile bool, p Profile, loaded *rules.LoadedRules, evidenceRoot string) (model.Device, []model.Artifact, error) {
+	dev := model.Device{ID: s.newID("dev")}
+	switch {
+	case mobile:
+		dev.OS = model.OSAndroid
+		dev.Name = fmt.Sprintf("SEED-PHONE-%02d", i+1)
+		dev.Identifier = fmt.Sprintf("SEEDSERIAL%06d", s.rng.Intn(1000000))
+	case s.rng.Intn(3) == 0:
+		dev.OS = model.OSMacOS
+		dev.Name = fmt.Sprintf("seed-macbook-%02d", i+1)
+		dev.Identifier = s.newID("hw")
+	default:
+		dev.OS = model.OSWindows
+		dev.Name = fmt.Sprintf("SEED-PC-%02d", i+1)
+		dev.Identifier = s.newID("hw")
+	}
+	collectedAt := s.at(30 * 86400)
+
+	// 每台设备随机挑选一部分启用的钱包/交易所规则作为“真实线索”。
+	var wallets []model.WalletSignature
+	for _, w := range loaded.Wallet.Wallets {
+		if w.Enabled && s.rng.Intn(2) == 0 {
+			wallets = append(wallets, w)
+		}
+	}
+	var exchangeDomains []string
+	for _, e := range loaded.Exchange.Exchanges {
+		if e.Enabled && len(e.Domains) > 0 && s.rng.Intn(2) == 0 {
+			exchangeDomains = append(exchangeDomains, e.Domains[0])
+		}
+	}
+
+	var arts []model.Artifact
+	add := func(t model.ArtifactType, sourceRef string, v any) error {
+		a, err := s.artifact(evidenceRoot, dev.ID, t, sourceRef, collectedAt, v)
+		if err != nil {
+			return err
+		}
+		arts = append(arts, a)
+		return nil
+	}
+
+	if mobile {
+		var pkgs []model.MobilePackageRecord
+		for _, w := range wallets {
+			if len(w.Mobile.AndroidPackages) > 0 {
+				pkgs = append(pkgs, model.MobilePackageRecord{OS: dev.OS, DeviceID: dev.ID, Identifier: dev.Identifier, Package: w.Mobile.AndroidPackages[0]})
+			}
+		}
+		for n := 0; n < p.NoiseApps; n++ {
+			pkgs = append(pkgs, model.MobilePackageRecord{OS: dev.OS, DeviceID: dev.ID, Identifier: dev.Identifier, Package: s.pick(noisePackages)})
+		}
+		if err := add(model.ArtifactMobilePackages, "adb_pm_list", pkgs); err != nil {
+			return model.Device{}, nil, err
+		}
+	} else {
+		var apps []model.AppRecord
+		for _, w := range wallets {
+			if len(w.Desktop.AppKeywords) > 0 {
+				apps = append(apps, model.AppRecord{Name: w.Name, Version: fmt.Sprintf("%d.%d.%d", 1+s.rng.Intn(9), s.rng.Intn(20), s.rng.Intn(10))})
+			}
+		}
+		for n := 0; n < p.NoiseApps; n++ {
+			apps = append(apps, model.AppRecord{Name: s.pick(noiseApps), Version: fmt.Sprintf("%d.%d", 1+s.rng.Intn(30), s.rng.Intn(10))})
+		}
+		if err := add(model.ArtifactInstalledApps, "installed_apps", apps); err != nil {
+			return model.Device{}, nil, err
+		}
+
+		exts := []model.ExtensionRecord{}
+		for _, w := range wallets {
+			if len(w.BrowserExtensions.ChromeIDs) > 0 {
+				exts = append(exts, model.ExtensionRecord{Browser: "chrome", Profile: "Default", ExtensionID: w.BrowserExtensions.ChromeIDs[0], Name: w.Name})
+			}
+		}
+		if err := add(model.ArtifactBrowserExt, "chrome_extensions", exts); err != nil {
+			return model.Device{}, nil, err
+		}
+	}
+
+	visits := make([]model.VisitRecord, 0, p.VisitsPerDevice)
+	for n := 0; n < p.VisitsPerDevice; n++ {
+		browser := "chrome"
+		if !mobile {
+			browser = s.pick(hostBrowsers)
+		}
+		v := model.VisitRecord{Browser: browser, Profile: "Default", VisitedAt: collectedAt - s.rng.Int63n(90*86400)}
+		switch r := s.rng.Intn(20); {
+		case r < 3 && len(exchangeDomains) > 0:
+			d := s.pick(exchangeDomains)
+			v.Domain = "www." + d
+			v.URL = "https://www." + d + s.pick([]string{"/", "/en/my/wallet", "/assets/deposit", "/trade/BTC_USDT"})
+			v.Title = d
+		case r == 3:
+			addr := s.evmAddress()
+			v.Domain = "etherscan.io"
+			v.URL = "https://etherscan.io/address/" + addr
+			v.Title = "Address " + addr + " | Etherscan"
+		default:
+			d := s.pick(noiseDomains)
+			v.Domain = d
+			v.URL = "https://" + d + "/"
+		}
+		visits = append(visits, v)
+	}
+	sort.SliceStable(visits, func(a, b int) bool { return visits[a].VisitedAt > visits[b].VisitedAt })
+	if err := add(model.ArtifactBrowserHistory, browserSourceRef(mobile), visits); err != nil {
+		return model.Device{}, nil, err
+	}
+	return dev, arts, nil
+}
+
+func browserSourceRef(mobile bool) string {
+	if mobile {
+		return "android_chrome_history"
+	}
+	return "browser_history"
+}
+
+func (s *seeder) evmAddress() string {
+	b := make([]byte, 20)
+	s.rng.Read(b)
+	return "0x" + hex.EncodeToString(b)
+}
+
+// artifact 写入证据快照并返回证据记录（快照布局与采集器一致：<evidence_root>/<case_id>/<device_id>/）。
+func (s *seeder) artifact(evidenceRoot, deviceID string, t model.ArtifactType, sourceRef string, collectedAt int64, v any) (model.Artifact, error) {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return model.Artifact{}, fmt.Errorf("marshal synthetic %s: %w", t, err)
+	}
+	artifactID := s.newID("art")
+	dir := filepath.Join(evidenceRoot, s.caseID, deviceID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return model.Artifact{}, fmt.Errorf("create evidence dir: %w", err)
+	}
+	snapshotPath, err := snapshot.WriteJSON(filepath.Join(dir, fmt.Sprintf("%s_%s_%d.json", t, sourceRef, collectedAt)), raw, snapshot.CompressionNone)
+	if err != nil {
+		return model.Artifact{}, fmt.Errorf("write evidence file: %w", err)
+	}
+	sum, size, err := hash.File(snapshotPath)
+	if err != nil {
+		return model.Artifact{}, fmt.Errorf("hash evidence file: %w", err)
+	}
+	return model.Artifact{
+		ID:                artifactID,
+		CaseID:            s.caseID,
+		DeviceID:          deviceID,
+		Type:              t,
+		SourceRef:         sourceRef,
+		SnapshotPath:      snapshotPath,
+		SHA256:            sum,
+		SizeBytes:         size,
+		CollectedAt:       collectedAt,
+		CollectorName:     CollectorName,
+		CollectorVersion:  CollectorVersion,
+		ParserVersion:     CollectorVersion,
+		AcquisitionMethod: AcquisitionMethod,
+		PayloadJSON:       raw,
+		RecordHash: hash.Text(artifactID, s.caseID, deviceID, string(t), sourceRef, snapshotPath, sum,
+			fmt.Sprintf("%d", size), fmt.Sprintf("%d", collectedAt), CollectorName, CollectorVersion, string(raw)),
+	}, nil
+}
+
+// stabilizeHits 把匹配器生成的随机 hit_id 与“当前时间”替换为确定值，保证同一 seed 结果一致。
+func (s *seeder) stabilizeHits(hits []model.RuleHit, collectedAt int64) []model.RuleHit {
+	for i := range hits {
+		hits[i].ID = s.newID("hit")
+		if hits[i].FirstSeenAt <= 0 || hits[i].FirstSeenAt > collectedAt {
+			hits[i].FirstSeenAt = collectedAt
+		}
+		if hits[i].LastSeenAt <= 0 || hits[i].LastSeenAt > collectedAt {
+			hits[i].LastSeenAt = collectedAt
+		}
+	}
+	return hits
+}
+
+var reportHTML = template.Must(template.New("seed").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+<p>合成数据，仅用于开发与演示，不是真实证据。case_id={{.CaseID}}</p>
+<h2>设备（{{len .Devices}}）</h2>
+<ul>{{range .Devices}}<li>{{.Name}} ({{.OS}}) {{.ID}}</li>{{end}}</ul>
+<h2>命中（{{len .Hits}}）</h2>
+<table border="1" cellpadding="4"><tr><th>类型</th><th>规则</th><th>命中值</th><th>置信度</th><th>设备</th></tr>
+{{range .Hits}}<tr><td>{{.Type}}</td><td>{{.RuleName}}</td><td>{{.MatchedValue}}</td><td>{{printf "%.2f" .Confidence}}</td><td>{{.DeviceID}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+// writeReports 生成内部 JSON / HTML 报告并登记。
+func (s *seeder) writeReports(ctx context.Context, store *sqliteadapter.Store, reportDir, profile string, seed int64, devices []model.Device, artifacts []model.Artifact, hits []model.RuleHit) ([]string, error) {
+	if err := os.MkdirAll(reportDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create report dir: %w", err)
+	}
+	generatedAt := s.base + 31*86400
+	rawJSON, err := json.MarshalIndent(map[string]any{
+		"case_id":      s.caseID,
+		"synthetic":    true,
+		"profile":      profile,
+		"seed":         seed,
+		"generated_at": generatedAt,
+		"devices":      devices,
+		"summary": map[string]any{
+			"device_count":   len(devices),
+			"artifact_count": len(artifacts),
+			"hit_count":      len(hits),
+		},
+		"hits": hits,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal synthetic report: %w", err)
+	}
+	var html strings.Builder
+	if err := reportHTML.Execute(&html, map[string]any{
+		"Title":   fmt.Sprintf("合成案件报告（%s, seed=%d）", profile, seed),
+		"CaseID":  s.caseID,
+		"Devices": devices,
+		"Hits":    hits,
+	}); err != nil {
+		return nil, fmt.Errorf("render synthetic report: %w", err)
+	}
+
+	var ids []string
+	for _, r := range []struct {
+		reportType, ext string
+		raw             []byte
+	}{
+		{"internal_json", "json", rawJSON},
+		{"internal_html", "html", []byte(html.String())},
+	} {
+		p := filepath.Join(reportDir, fmt.Sprintf("%s_%s_%d.%s", s.caseID, r.reportType, generatedAt, r.ext))
+		if err := os.WriteFile(p, r.raw, 0o644); err != nil {
+			return nil, fmt.Errorf("write synthetic report: %w", err)
+		}
+		reportID, err := store.SaveReport(ctx, s.caseID, r.reportType, p, hash.Bytes(r.raw), generatorVersion, "ready")
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, reportID)
+	}
+	return ids, nil
+}
+
+// builtinRules 是未指定规则文件时使用的小规则集（取常见钱包与交易所，足以覆盖各类命中）。
+func builtinRules() *rules.LoadedRules {
+	loaded := &rules.LoadedRules{}
+	loaded.Wallet.Version = "seed-builtin"
+	loaded.Wallet.Wallets = []model.WalletSignature{
+		{
+			ID: "wallet_metamask", Enabled: true, Name: "MetaMask",
+			Desktop:           model.WalletDesktopHints{AppKeywords: []string{"metamask"}},
+			BrowserExtensions: model.BrowserExtensions{ChromeIDs: []string{"nkbihfbeogaeaoehlefnkodbefgpgknn"}},
+			Mobile:            model.WalletMobileHints{AndroidPackages: []string{"io.metamask"}},
+		},
+		{
+			ID: "wallet_imtoken", Enabled: true, Name: "imToken",
+			Desktop: model.WalletDesktopHints{AppKeywords: []string{"imtoken"}},
+			Mobile:  model.WalletMobileHints{AndroidPackages: []string{"im.token.app"}},
+		},
+		{
+			ID: "wallet_tokenpocket", Enabled: true, Name: "TokenPocket",
+			BrowserExtensions: model.BrowserExtensions{ChromeIDs: []string{"mfgccjchihfkkindfppnaooecgfneiii"}},
+			Mobile:            model.WalletMobileHints{AndroidPackages: []string{"vip.mytokenpocket"}},
+		},
+		{
+			ID: "wallet_exodus", Enabled: true, Name: "Exodus",
+			Desktop: model.WalletDesktopHints{AppKeywords: []string{"exodus"}},
+		},
+	}
+	loaded.Exchange.Version = "seed-builtin"
+	loaded.Exchange.Exchanges = []model.ExchangeDomain{
+		{ID: "binance", Enabled: true, Name: "Binance", Domains: []string{"binance.com"}},
+		{ID: "okx", Enabled: true, Name: "OKX", Domains: []string{"okx.com"}},
+		{ID: "htx", Enabled: true, Name: "HTX", Domains: []string{"htx.com"}},
+		{ID: "coinbase", Enabled: true, Name: "Coinbase", Domains: []string{"coinbase.com"}},
+	}
+	return loaded
+}
@@ -0,0 +1,86 @@
+package devseed
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+
+	_ "modernc.org/sqlite"
+)
+
+func openStore(t *testing.T, dir string) *sqliteadapter.Store {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(context.Background()); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return sqliteadapter.NewStore(db)
+}
+
+func TestSeed_DeterministicDemoCase(t *testing.T) {
+	ctx := context.Background()
+	type snapshot struct {
+		artifacts map[string]string
+		hits      map[string]string
+	}
+	run := func() (*Result, snapshot) {
+		dir := t.TempDir()
+		store := openStore(t, dir)
+		res, err := Seed(ctx, store, Options{Profile: "demo", Seed: 7, EvidenceRoot: filepath.Join(dir, "evidence"), ReportDir: filepath.Join(dir, "reports")})
+		if err != nil {
+			t.Fatalf("Seed: %v", err)
+		}
+		arts, err := store.ListArtifactsByCase(ctx, res.CaseID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hits, err := store.ListCaseHitDetails(ctx, res.CaseID, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		snap := snapshot{artifacts: map[string]string{}, hits: map[string]string{}}
+		for _, a := range arts {
+			snap.artifacts[a.ArtifactID] = a.SHA256
+		}
+		for _, h := range hits {
+			snap.hits[h.HitID] = h.RuleID + "|" + h.MatchedValue
+		}
+
+		if _, err := Seed(ctx, store, Options{Seed: 7, EvidenceRoot: filepath.Join(dir, "evidence"), ReportDir: filepath.Join(dir, "reports")}); apperr.CodeOf(err) != apperr.CodeConflict {
+			t.Fatalf("reseeding the same case err=%v", err)
+		}
+		return res, snap
+	}
+
+	a, snapA := run()
+	b, snapB := run()
+	if a.CaseID != "case_seed_demo_7" || a.Devices != 3 || a.Artifacts != 8 || a.Hits == 0 || len(a.ReportIDs) != 2 {
+		t.Fatalf("unexpected result: %+v", a)
+	}
+	if a.Hits != b.Hits || len(snapA.artifacts) != a.Artifacts || len(snapA.hits) != a.Hits {
+		t.Fatalf("runs differ: a=%+v b=%+v", a, b)
+	}
+	for id, sum := range snapA.artifacts {
+		if snapB.artifacts[id] != sum {
+			t.Fatalf("artifact %s differs between runs", id)
+		}
+	}
+	for id, v := range snapA.hits {
+		if snapB.hits[id] != v {
+			t.Fatalf("hit %s differs between runs: %s vs %s", id, v, snapB.hits[id])
+		}
+	}
+
+	if _, _, err := ParseProfile("huge"); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("invalid profile err=%v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package evidencerelocate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/services/artifactverify"
+)
+
+// 证据根目录迁移
+//
+// 证据目录整体搬到其他磁盘后，artifacts.snapshot_path、reports.file_path、case_attachments.file_path
+// 全部失效。本流程只改写数据库中的路径，不移动文件（文件由调查人员先行复制/移动）：
+// 1) 找出路径以 From 开头（按目录边界匹配）的记录，计算 To 下的新路径
+// 2) 检查新路径文件是否存在；存在缺失时默认拒绝改写（Force 可跳过）
+// 3) 单个事务内改写全部路径
+// 4) 按新路径复算 sha256 并与入库值对比，每个涉及的案件写一条 evidence/relocate 审计
+// DryRun 只执行 1)、2) 并返回统计。
+
+// missingSampleLimit 是结果中保留的缺失文件样例数。
+const missingSampleLimit = 50
+
+// Options 定义一次迁移的参数。
+type Options struct {
+	From     string
+	To       string
+	CaseID   string // 为空时迁移全部案件
+	Operator string
+	DryRun   bool
+	// Force 为 true 时即使新位置缺少部分文件也改写（缺失项会在复核中记为 missing）。
+	Force   bool
+	Workers int
+}
+
+// Result 是一次迁移的结果。
+type Result struct {
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	DryRun      bool     `json:"dry_run"`
+	Applied     bool     `json:"applied"`
+	CaseIDs     []string `json:"case_ids"`
+	Artifacts   int      `json:"artifacts"`
+	Reports     int      `json:"reports"`
+	Attachments int      `json:"attachments"`
+
+	MissingCount int                   `json:"missing_count"`
+	Missing      []model.RelocatedPath `json:"missing,omitempty"`
+
+	VerifyOK     int                   `json:"verify_ok"`
+	VerifyFailed int                   `json:"verify_failed"`
+	Failures     []artifactverify.Item `json:"failures,omitempty"`
+}
+
+// Run 执行证据根目录迁移。
+func Run(ctx context.Context, store *sqliteadapter.Store, opts Options) (*Result, error) {
+	from := trimSeparators(opts.From)
+	to := trimSeparators(opts.To)
+	if from == "" || to == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "both --from and --to are required")
+	}
+	if from == to {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "--from and --to are the same path")
+	}
+	if info, err := os.Stat(to); err != nil || !info.IsDir() {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("target evidence root is not a directory: %s", to))
+	}
+	operator := strings.TrimSpace(opts.Operator)
+	if operator == "" {
+		operator = "system"
+	}
+	caseID := strings.TrimSpace(opts.CaseID)
+
+	rows, err := store.ListPathsWithPrefix(ctx, caseID, from)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{From: from, To: to, DryRun: opts.DryRun, CaseIDs: []string{}}
+	cases := map[string]struct{}{}
+	var paths []model.RelocatedPath
+	for _, p := range rows {
+		rest, ok := underRoot(p.OldPath, from)
+		if !ok {
+			continue
+		}
+		p.NewPath = to + rest
+		paths = append(paths, p)
+		cases[p.CaseID] = struct{}{}
+		switch p.Kind {
+		case model.RelocateKindArtifact:
+			res.Artifacts++
+		case model.RelocateKindReport:
+			res.Reports++
+		case model.RelocateKindAttachment:
+			res.Attachments++
+		}
+		if _, err := os.Stat(p.NewPath); err != nil {
+			res.MissingCount++
+			if len(res.Missing) < missingSampleLimit {
+				res.Missing = append(res.Missing, p)
+			}
+		}
+	}
+	for id := range cases {
+		res.CaseIDs = append(res.CaseIDs, id)
+	}
+	sort.Strings(res.CaseIDs)
+
+	if opts.DryRun || len(paths) == 0 {
+		return res, nil
+	}
+	if res.MissingCount > 0 && !opts.Force {
+		return res, apperr.T(apperr.CodeConflict, "relocate.files_missing", res.MissingCount, to)
+	}
+	if err := store.RelocatePaths(ctx, paths); err != nil {
+		return res, err
+	}
+	res.Applied = true
+
+	perCase, err := verify(ctx, paths, opts.Workers, res)
+	if err != nil {
+		return res, err
+	}
+	for _, id := range res.CaseIDs {
+		c := perCase[id]
+		status := "success"
+		if c.failed > 0 {
+			status = "failed"
+		}
+		_ = store.AppendAudit(ctx, id, "", "evidence", "relocate", status, operator, "evidencerelocate.Run", map[string]any{
+			"from":          from,
+			"to":            to,
+			"artifacts":     c.artifacts,
+			"reports":       c.reports,
+			"attachments":   c.attachments,
+			"verify_ok":     c.ok,
+			"verify_failed": c.failed,
+			"forced":        opts.Force && res.MissingCount > 0,
+		})
+	}
+	return res, nil
+}
+
+// caseStats 是单个案件的迁移/复核计数（写入审计明细）。
+type caseStats struct {
+	artifacts, reports, attachments int
+	ok, failed                      int
+}
+
+// verify 按新路径复核哈希：证据快照与附件走并行复核（sha256 + 大小），报告只有 sha256。
+func verify(ctx context.Context, paths []model.RelocatedPath, workers int, res *Result) (map[string]*caseStats, error) {
+	perCase := map[string]*caseStats{}
+	stat := func(caseID string) *caseStats {
+		c, ok := perCase[caseID]
+		if !ok {
+			c = &caseStats{}
+			perCase[caseID] = c
+		}
+		return c
+	}
+	count := func(caseID string, it artifactverify.Item) {
+		c := stat(caseID)
+		if it.Status == "ok" {
+			c.ok++
+			res.VerifyOK++
+			return
+		}
+		c.failed++
+		res.VerifyFailed++
+		res.Failures = append(res.Failures, it)
+	}
+
+	var targets []model.ArtifactInfo
+	caseOf := map[string]string{}
+	for _, p := range paths {
+		c := stat(p.CaseID)
+		switch p.Kind {
+		case model.RelocateKindArtifact:
+			c.artifacts++
+		case model.RelocateKindReport:
+			c.reports++
+		case model.RelocateKindAttachment:
+			c.attachments++
+		}
+		if p.Kind == model.RelocateKindReport {
+			count(p.CaseID, verifyReport(p))
+			continue
+		}
+		targets = append(targets, model.ArtifactInfo{ArtifactID: p.ID, CaseID: p.CaseID, SnapshotPath: p.NewPath, SHA256: p.SHA256, SizeBytes: p.SizeBytes})
+		caseOf[p.ID] = p.CaseID
+	}
+	if len(targets) == 0 {
+		return perCase, nil
+	}
+	vr, err := artifactverify.Run(ctx, targets, artifactverify.Options{Workers: workers})
+	if vr == nil {
+		return perCase, err
+	}
+	for _, it := range vr.Items {
+		count(caseOf[it.ArtifactID], it)
+	}
+	return perCase, err
+}
+
+// verifyReport 复核报告文件 sha256（reports 表不记录文件大小）。
+func verifyReport(p model.RelocatedPath) artifactverify.Item {
+	it := artifactverify.Item{ArtifactID: p.ID, SnapshotPath: p.NewPath, ExpectedSHA256: p.SHA256, ExpectedSize: -1}
+	sum, size, err := hash.File(p.NewPath)
+	if err != nil {
+		it.Status = "missing"
+		it.Error = err.Error()
+		return it
+	}
+	it.ActualSHA256 = sum
+	it.ActualSize = size
+	it.Status = "ok"
+	if !strings.EqualFold(sum, strings.TrimSpace(p.SHA256)) {
+		it.Status = "mismatch"
+	}
+	return it
+}
+
+// trimSeparators 去掉首尾空白与末尾的路径分隔符（根目录 "/" 保留）。
+func trimSeparators(p string) string {
+	p = strings.TrimSpace(p)
+	for len(p) > 1 && (strings.HasSuffix(p, "/") || strings.HasSuffix(p, `\`)) {
+		p = p[:len(p)-1]
+	}
+	return p
+}
+
+// underRoot 判断 path 是否位于 root 之下（按目录边界，兼容 / 与 \ 分隔符），返回 root 之后的部分。
+func underRoot(path, root string) (string, bool) {
+	if !strings.HasPrefix(path, root) {
+		return "", false
+	}
+	rest := path[len(root):]
+	if rest == "" || rest[0] == '/' || rest[0] == '\\' {
+		return rest, true
+	}
+	return "", false
+}
@@ -0,0 +1,137 @@
+package evidencerelocate
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestRun_RelocatesAndVerifies(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev := model.Device{ID: "dev_1", Name: "d", OS: model.OSWindows, Identifier: "id-1"}
+	if err := store.UpsertDevice(ctx, caseID, dev, true, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	oldRoot := filepath.Join(dir, "evidence")
+	newRoot := filepath.Join(dir, "moved")
+	// evidence-old 与 evidence 共享前缀但不在同一目录下，不能被改写。
+	siblingRoot := filepath.Join(dir, "evidence-old")
+	write := func(root, name, content string) (string, string, int64) {
+		p := filepath.Join(root, caseID, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		sum, size, err := hash.File(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p, sum, size
+	}
+	artifact := func(id, path, sum string, size int64) model.Artifact {
+		return model.Artifact{
+			ID: id, CaseID: caseID, DeviceID: dev.ID, Type: model.ArtifactInstalledApps,
+			SourceRef: "apps", SnapshotPath: path, SHA256: sum, SizeBytes: size,
+			CollectedAt: time.Now().Unix(), CollectorName: "test", CollectorVersion: "1", ParserVersion: "1",
+			AcquisitionMethod: "test", PayloadJSON: []byte(`[]`), RecordHash: strings.Repeat("0", 64),
+		}
+	}
+	p1, s1, n1 := write(oldRoot, "apps.json", `["a"]`)
+	p2, s2, n2 := write(oldRoot, "sub/history.json", `["b"]`)
+	p3, s3, n3 := write(siblingRoot, "apps.json", `["c"]`)
+	if err := store.SaveArtifacts(ctx, []model.Artifact{artifact("art_1", p1, s1, n1), artifact("art_2", p2, s2, n2), artifact("art_3", p3, s3, n3)}); err != nil {
+		t.Fatal(err)
+	}
+	rp, rs, _ := write(oldRoot, "report.html", "<html></html>")
+	if _, err := store.SaveReport(ctx, caseID, "internal_html", rp, rs, "test", "ready"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 新位置还没有文件：dry-run 只统计，正式执行被拒绝且不改库。
+	if err := os.MkdirAll(newRoot, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{From: oldRoot + string(os.PathSeparator), To: newRoot, Operator: "alice"}
+	dry := opts
+	dry.DryRun = true
+	res, err := Run(ctx, store, dry)
+	if err != nil || res.Applied || res.Artifacts != 2 || res.Reports != 1 || res.MissingCount != 3 {
+		t.Fatalf("dry run res=%+v err=%v", res, err)
+	}
+	if _, err := Run(ctx, store, opts); apperr.CodeOf(err) != apperr.CodeConflict {
+		t.Fatalf("missing target files should be rejected, err=%v", err)
+	}
+	if info, _ := store.GetArtifactInfo(ctx, "art_1"); info.SnapshotPath != p1 {
+		t.Fatalf("path rewritten despite rejection: %s", info.SnapshotPath)
+	}
+
+	// 搬迁文件后执行：路径改写、哈希复核通过、写入审计。
+	if err := os.Rename(oldRoot, newRoot+"_tmp"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(newRoot); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(newRoot+"_tmp", newRoot); err != nil {
+		t.Fatal(err)
+	}
+	res, err = Run(ctx, store, opts)
+	if err != nil {
+		t.Fatalf("relocate: %v", err)
+	}
+	if !res.Applied || res.VerifyOK != 3 || res.VerifyFailed != 0 || len(res.CaseIDs) != 1 {
+		t.Fatalf("relocate res=%+v", res)
+	}
+	if info, _ := store.GetArtifactInfo(ctx, "art_2"); info.SnapshotPath != filepath.Join(newRoot, caseID, "sub", "history.json") {
+		t.Fatalf("art_2 path=%s", info.SnapshotPath)
+	}
+	if info, _ := store.GetArtifactInfo(ctx, "art_3"); info.SnapshotPath != p3 {
+		t.Fatalf("sibling root must not be rewritten: %s", info.SnapshotPath)
+	}
+	reports, _ := store.ListReportsByCase(ctx, caseID)
+	if len(reports) != 1 || !strings.HasPrefix(reports[0].FilePath, newRoot) {
+		t.Fatalf("reports=%+v", reports)
+	}
+	logs, err := store.ListAuditLogs(ctx, caseID, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, l := range logs {
+		if l.EventType == "evidence" && l.Action == "relocate" && l.Status == "success" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("relocate audit not found: %+v", logs)
+	}
+}
@@ -0,0 +1,11 @@
+// Package builtin 引入内置导出格式（各包在 init 中向 exporter 注册）。
+//
+// 新增导出格式：新建独立的包实现 exporter.Exporter 并在 init 中 Register，
+// 然后在这里加一行空导入；CLI 与 API 会自动暴露 `export <kind>` 与 /exports/<kind>。
+package builtin
+
+import (
+	_ "crypto-inspector/internal/services/forensicexport"
+	_ "crypto-inspector/internal/services/forensicpdf"
+	_ "crypto-inspector/internal/services/graphexport"
+)
@@ -0,0 +1,134 @@
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+)
+
+// 导出格式注册表
+//
+// 每种导出格式（forensic-zip / forensic-pdf / disclosure-zip / 后续的 DOCX、STIX、CSV、
+// 各单位自定义格式）实现 Exporter 接口，并在所在包的 init 中调用 Register。
+// CLI 的 `export <kind>` 与 API 的 POST /api/cases/{id}/exports/<kind> 都按 kind 查表分发，
+// 新增格式只需新建独立的包并在 exporter/builtin 中引入，不需要改动路由代码。
+
+// Request 是各导出格式共用的参数（格式不需要的字段忽略即可）。
+type Request struct {
+	CaseID string
+
+	DBPath       string
+	EvidenceRoot string
+
+	WalletRulePath   string
+	ExchangeRulePath string
+
+	Operator string
+	Note     string
+
+	// ExportDir 为空时由各格式自行决定落盘目录（一般为 db 同级目录）。
+	ExportDir string
+
+	// UIScreenshots / BrowserPath 仅 forensic-pdf 使用：附录收录内部 HTML 报告命中表的无头浏览器截图。
+	UIScreenshots bool
+	BrowserPath   string
+
+	// IncludeComments 仅 forensic-pdf 使用：在命中与证据条目下收录分析评论。
+	IncludeComments bool
+
+	// MaxDevices/MaxPrechecks/MaxHits/MaxArtifacts 仅 forensic-pdf 使用：正文各列表的输出上限
+	// （0 使用默认值，负数不限），超出部分完整收录到 PDF 内嵌的 CSV 附件。
+	MaxDevices   int
+	MaxPrechecks int
+	MaxHits      int
+	MaxArtifacts int
+
+	// Password 仅实现 Encrypter 的格式（forensic-zip / disclosure-zip）使用：非空时生成 AES-256 加密 ZIP（密码不落库、不写审计）。
+	Password string
+}
+
+// Result 是一次导出的结果。
+type Result struct {
+	CaseID   string
+	ReportID string
+	// ReportNo 是按单位信息签发的报告编号（未配置编号前缀时为空）。
+	ReportNo string
+	Path     string
+	SHA256   string
+	// FileKey 是结果文件的字段前缀（zip/pdf/...）：
+	// API 响应输出 {key}_path / {key}_sha256，CLI 输出 {key}= / {key}_sha256=。
+	FileKey  string
+	Warnings []string
+	// Extra 为格式特有的统计字段，API 响应中平铺输出。
+	Extra map[string]any
+}
+
+// Exporter 是一种导出格式。
+type Exporter interface {
+	// Kind 是路由使用的格式标识（小写，短横线分隔），例如 forensic-zip。
+	Kind() string
+	// Description 是给 CLI 帮助/前端展示的一行说明。
+	Description() string
+	Export(ctx context.Context, store *sqliteadapter.Store, req Request) (*Result, error)
+}
+
+// Encrypter 是可选接口：实现且返回 true 的格式会按 Request.Password 生成加密产物。
+// 未实现的格式不读取 Password，调用方应拒绝带密码的请求，避免以为已加密却得到明文文件。
+type Encrypter interface {
+	SupportsEncryption() bool
+}
+
+// SupportsEncryption 判断导出格式是否支持 Request.Password。
+func SupportsEncryption(e Exporter) bool {
+	enc, ok := e.(Encrypter)
+	return ok && enc.SupportsEncryption()
+}
+
+// Info 是已注册格式的展示信息。
+type Info struct {
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+}
+
+var (
+	mu        sync.RWMutex
+	exporters = map[string]Exporter{}
+)
+
+// Register 注册一种导出格式；kind 为空或重复注册时 panic（属于编程错误）。
+func Register(e Exporter) {
+	kind := strings.TrimSpace(e.Kind())
+	if kind == "" {
+		panic("exporter: empty kind")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := exporters[kind]; ok {
+		panic(fmt.Sprintf("exporter: duplicate kind %q", kind))
+	}
+	exporters[kind] = e
+}
+
+// Lookup 按 kind 查找导出格式。
+func Lookup(kind string) (Exporter, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	e, ok := exporters[strings.TrimSpace(kind)]
+	return e, ok
+}
+
+// List 返回已注册格式（按 kind 排序）。
+func List() []Info {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]Info, 0, len(exporters))
+	for kind, e := range exporters {
+		out = append(out, Info{Kind: kind, Description: e.Description()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Kind < out[j].Kind })
+	return out
+}
@@ -0,0 +1,67 @@
+package exporter_test
+
+import (
+	"context"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/services/exporter"
+	_ "crypto-inspector/internal/services/exporter/builtin"
+)
+
+type fakeExporter struct{ kind string }
+
+func (f fakeExporter) Kind() string        { return f.kind }
+func (f fakeExporter) Description() string { return "fake" }
+func (f fakeExporter) Export(context.Context, *sqliteadapter.Store, exporter.Request) (*exporter.Result, error) {
+	return &exporter.Result{FileKey: "csv"}, nil
+}
+
+func TestBuiltinKindsRegistered(t *testing.T) {
+	for _, kind := range []string{"forensic-zip", "forensic-pdf", "disclosure-zip", "graph-zip"} {
+		if _, ok := exporter.Lookup(kind); !ok {
+			t.Fatalf("builtin exporter %s not registered", kind)
+		}
+	}
+}
+
+func TestSupportsEncryption(t *testing.T) {
+	want := map[string]bool{"forensic-zip": true, "disclosure-zip": true, "forensic-pdf": false, "graph-zip": false}
+	for kind, ok := range want {
+		e, found := exporter.Lookup(kind)
+		if !found {
+			t.Fatalf("builtin exporter %s not registered", kind)
+		}
+		if got := exporter.SupportsEncryption(e); got != ok {
+			t.Fatalf("SupportsEncryption(%s)=%v want %v", kind, got, ok)
+		}
+	}
+	if exporter.SupportsEncryption(fakeExporter{kind: "x"}) {
+		t.Fatalf("exporter without Encrypter must not support encryption")
+	}
+}
+
+func TestRegisterAndLookup(t *testing.T) {
+	exporter.Register(fakeExporter{kind: "test-csv"})
+	e, ok := exporter.Lookup("test-csv")
+	if !ok || e.Kind() != "test-csv" {
+		t.Fatalf("lookup failed: %v %v", e, ok)
+	}
+	if _, ok := exporter.Lookup("docx"); ok {
+		t.Fatalf("unexpected exporter for unregistered kind")
+	}
+
+	list := exporter.List()
+	for i := 1; i < len(list); i++ {
+		if list[i-1].Kind >= list[i].Kind {
+			t.Fatalf("list not sorted: %+v", list)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic on duplicate kind")
+		}
+	}()
+	exporter.Register(fakeExporter{kind: "test-csv"})
+}
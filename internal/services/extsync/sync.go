@@ -0,0 +1,320 @@
+package extsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/adapters/rules"
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 扩展 ID 信誉同步（规则维护工具）
+//
+// 流程：
+// 1) 从历史扫描的 browser_extension 证据中汇总“规则库未收录”的扩展 ID；
+// 2) 向 Chrome Web Store / Edge Add-ons 查询名称、发布者、用户数；
+// 3) 把疑似钱包的扩展写成“候选规则”YAML（enabled=false），由规则维护人审核后再合入正式规则。
+//
+// 注意：候选文件只是草稿，不会被扫描流程加载，避免未经审核的规则影响取证结论。
+
+type Options struct {
+	// CaseID 可选：只汇总某个案件；为空则汇总数据库内全部案件。
+	CaseID string
+
+	WalletRulePath   string
+	ExchangeRulePath string
+
+	// OutPath 候选规则输出路径（默认 rules/staging/wallet_candidates_<ts>.yaml）。
+	OutPath string
+
+	// IncludeAll=true 时不做钱包关键词过滤，所有未知扩展都写入候选文件。
+	IncludeAll bool
+
+	// MaxLookups 限制本次最多查询多少个扩展（0=不限制），避免对商店发起过多请求。
+	MaxLookups int
+
+	Client *WebStoreClient
+}
+
+// Candidate 是一个未收录扩展的汇总信息与商店元数据。
+type Candidate struct {
+	ExtensionID     string         `json:"extension_id"`
+	Browsers        []string       `json:"browsers"`
+	LocalNames      []string       `json:"local_names,omitempty"`
+	SeenCount       int            `json:"seen_count"`
+	Chrome          *StoreMetadata `json:"chrome,omitempty"`
+	Edge            *StoreMetadata `json:"edge,omitempty"`
+	LooksLikeWallet bool           `json:"looks_like_wallet"`
+	Staged          bool           `json:"staged"`
+	LookupErrors    []string       `json:"lookup_errors,omitempty"`
+}
+
+type Result struct {
+	UnknownCount int         `json:"unknown_count"`
+	LookupCount  int         `json:"lookup_count"`
+	StagedCount  int         `json:"staged_count"`
+	OutPath      string      `json:"out_path,omitempty"`
+	Candidates   []Candidate `json:"candidates"`
+	Warnings     []string    `json:"warnings,omitempty"`
+}
+
+// walletKeywords 用于判断商店元数据是否“像钱包”（名称/发布者/分类）。
+var walletKeywords = []string{
+	"wallet", "钱包", "crypto", "web3", "defi", "bitcoin", "ethereum", "solana", "tron", "metamask", "ledger", "keyring",
+}
+
+// weakWalletKeywords 单独出现时不足以判断为钱包（"vault" 也常见于 Bitwarden、KeePass 等密码管理器），
+// 只有同时出现 weakWalletContext 中的词时才计入。
+var (
+	weakWalletKeywords = []string{"vault"}
+	weakWalletContext  = []string{"wallet", "钱包", "crypto", "seed", "助记词"}
+)
+
+// Run 执行一次扩展信誉同步。
+func Run(ctx context.Context, store *sqliteadapter.Store, opts Options) (*Result, error) {
+	if store == nil {
+		return nil, fmt.Errorf("store is required")
+	}
+
+	loaded, err := rules.NewLoader(opts.WalletRulePath, opts.ExchangeRulePath).Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := CollectUnknownExtensions(ctx, store, strings.TrimSpace(opts.CaseID), loaded)
+	if err != nil {
+		return nil, err
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = NewWebStoreClient("", "")
+	}
+
+	res := &Result{UnknownCount: len(candidates)}
+	for i := range candidates {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if opts.MaxLookups > 0 && res.LookupCount >= opts.MaxLookups {
+			res.Warnings = append(res.Warnings, fmt.Sprintf("lookup limit reached (%d), remaining extensions not queried", opts.MaxLookups))
+			break
+		}
+		c := &candidates[i]
+		// Firefox 扩展 ID 多为 email/GUID 格式，不在 Chromium 商店中，直接跳过查询。
+		if !IsChromiumExtensionID(c.ExtensionID) {
+			continue
+		}
+		res.LookupCount++
+
+		if meta, err := client.FetchChrome(ctx, c.ExtensionID); err != nil {
+			c.LookupErrors = append(c.LookupErrors, "chrome: "+err.Error())
+		} else {
+			c.Chrome = meta
+		}
+		if meta, err := client.FetchEdge(ctx, c.ExtensionID); err != nil {
+			c.LookupErrors = append(c.LookupErrors, "edge: "+err.Error())
+		} else {
+			c.Edge = meta
+		}
+		c.LooksLikeWallet = looksLikeWallet(*c)
+	}
+
+	var staged []model.WalletSignature
+	for i := range candidates {
+		c := &candidates[i]
+		if c.Chrome == nil && c.Edge == nil && len(c.LocalNames) == 0 {
+			continue
+		}
+		if !opts.IncludeAll && !c.LooksLikeWallet {
+			continue
+		}
+		c.Staged = true
+		staged = append(staged, candidateToSignature(*c))
+	}
+	res.StagedCount = len(staged)
+	res.Candidates = candidates
+
+	if len(staged) > 0 {
+		outPath := strings.TrimSpace(opts.OutPath)
+		if outPath == "" {
+			outPath = filepath.Join(filepath.Dir(opts.WalletRulePath), "staging", fmt.Sprintf("wallet_candidates_%d.yaml", time.Now().Unix()))
+		}
+		if err := writeCandidateBundle(outPath, staged, loaded); err != nil {
+			return nil, err
+		}
+		res.OutPath = outPath
+	}
+	return res, nil
+}
+
+// CollectUnknownExtensions 汇总 browser_extension 证据中“未被钱包规则收录”的扩展 ID。
+func CollectUnknownExtensions(ctx context.Context, store *sqliteadapter.Store, caseID string, loaded *rules.LoadedRules) ([]Candidate, error) {
+	known := make(map[string]struct{})
+	if loaded != nil {
+		for _, w := range loaded.Wallet.Wallets {
+			// 禁用的规则同样视为“已收录”，避免被重复提为候选。
+			for _, ids := range [][]string{w.BrowserExtensions.ChromeIDs, w.BrowserExtensions.EdgeIDs, w.BrowserExtensions.FirefoxIDs} {
+				for _, id := range ids {
+					known[strings.ToLower(strings.TrimSpace(id))] = struct{}{}
+				}
+			}
+		}
+	}
+
+	payloads, err := store.ListArtifactPayloadsByType(ctx, caseID, string(model.ArtifactBrowserExt))
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*Candidate)
+	for _, raw := range payloads {
+		var records []model.ExtensionRecord
+		if err := json.Unmarshal(raw, &records); err != nil {
+			// 单条证据格式异常不影响整体汇总。
+			continue
+		}
+		for _, rec := range records {
+			eid := strings.ToLower(strings.TrimSpace(rec.ExtensionID))
+			if eid == "" {
+				continue
+			}
+			if _, ok := known[eid]; ok {
+				continue
+			}
+			c := byID[eid]
+			if c == nil {
+				c = &Candidate{ExtensionID: eid}
+				byID[eid] = c
+			}
+			c.SeenCount++
+			c.Browsers = appendUnique(c.Browsers, strings.TrimSpace(rec.Browser))
+			name := strings.TrimSpace(rec.Name)
+			// Chromium manifest 里常见 "__MSG_appName__" 这类未本地化占位符，没有参考价值。
+			if name != "" && !strings.HasPrefix(name, "__MSG_") {
+				c.LocalNames = appendUnique(c.LocalNames, name)
+			}
+		}
+	}
+
+	out := make([]Candidate, 0, len(byID))
+	for _, c := range byID {
+		sort.Strings(c.Browsers)
+		sort.Strings(c.LocalNames)
+		c.LooksLikeWallet = looksLikeWallet(*c)
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].SeenCount != out[j].SeenCount {
+			return out[i].SeenCount > out[j].SeenCount
+		}
+		return out[i].ExtensionID < out[j].ExtensionID
+	})
+	return out, nil
+}
+
+func looksLikeWallet(c Candidate) bool {
+	var texts []string
+	texts = append(texts, c.LocalNames...)
+	for _, m := range []*StoreMetadata{c.Chrome, c.Edge} {
+		if m == nil {
+			continue
+		}
+		texts = append(texts, m.Name, m.Publisher, m.Category)
+	}
+	all := strings.ToLower(strings.Join(texts, "\n"))
+	if containsAny(all, walletKeywords) {
+		return true
+	}
+	return containsAny(all, weakWalletKeywords) && containsAny(all, weakWalletContext)
+}
+
+func containsAny(s string, kws []string) bool {
+	for _, kw := range kws {
+		if strings.Contains(s, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// candidateToSignature 把候选扩展转换为一条“待审核”的钱包规则（默认禁用）。
+func candidateToSignature(c Candidate) model.WalletSignature {
+	name := ""
+	if c.Chrome != nil && c.Chrome.Name != "" {
+		name = c.Chrome.Name
+	} else if c.Edge != nil && c.Edge.Name != "" {
+		name = c.Edge.Name
+	} else if len(c.LocalNames) > 0 {
+		name = c.LocalNames[0]
+	}
+
+	sig := model.WalletSignature{
+		ID:         "candidate_" + c.ExtensionID,
+		Enabled:    false,
+		Name:       name,
+		Aliases:    c.LocalNames,
+		Categories: []string{"browser_extension", "candidate"},
+	}
+	if IsChromiumExtensionID(c.ExtensionID) {
+		if c.Chrome != nil {
+			sig.BrowserExtensions.ChromeIDs = []string{c.ExtensionID}
+		}
+		if c.Edge != nil {
+			sig.BrowserExtensions.EdgeIDs = []string{c.ExtensionID}
+		}
+		if c.Chrome == nil && c.Edge == nil {
+			sig.BrowserExtensions.ChromeIDs = []string{c.ExtensionID}
+		}
+	} else {
+		sig.BrowserExtensions.FirefoxIDs = []string{c.ExtensionID}
+	}
+	return sig
+}
+
+func writeCandidateBundle(outPath string, staged []model.WalletSignature, loaded *rules.LoadedRules) error {
+	bundle := model.WalletRuleBundle{
+		Version:     time.Now().Format("2006-01-02") + "-candidates",
+		BundleType:  "wallet_signatures",
+		Maintainer:  "extsync",
+		Description: "Candidate wallet extension rules staged from web store metadata; review before merging",
+		Wallets:     staged,
+	}
+	if loaded != nil {
+		bundle.Meta.ConfidenceDefaults = loaded.Wallet.Meta.ConfidenceDefaults
+		bundle.Meta.Notes = []string{"based_on_wallet_rules_version=" + loaded.Wallet.Version}
+	}
+
+	raw, err := yaml.Marshal(bundle)
+	if err != nil {
+		return fmt.Errorf("marshal candidate rules: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return fmt.Errorf("create staging dir: %w", err)
+	}
+	if err := os.WriteFile(outPath, raw, 0o644); err != nil {
+		return fmt.Errorf("write candidate rules: %w", err)
+	}
+	return nil
+}
+
+func appendUnique(list []string, v string) []string {
+	if v == "" {
+		return list
+	}
+	for _, x := range list {
+		if x == v {
+			return list
+		}
+	}
+	return append(list, v)
+}
@@ -0,0 +1,27 @@
+package extsync
+
+import "testing"
+
+func TestLooksLikeWallet(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		c    Candidate
+		want bool
+	}{
+		{"metamask", Candidate{LocalNames: []string{"MetaMask"}}, true},
+		{"password manager vault", Candidate{
+			LocalNames: []string{"Bitwarden Password Manager"},
+			Chrome:     &StoreMetadata{Name: "Bitwarden - Free Password Manager", Publisher: "Bitwarden Inc.", Category: "Productivity"},
+		}, false},
+		{"keepass vault", Candidate{LocalNames: []string{"KeePassXC-Browser"}, Edge: &StoreMetadata{Name: "KeePass Vault Connector"}}, false},
+		{"vault with seed phrase", Candidate{LocalNames: []string{"Seed Vault"}}, true},
+		{"crypto vault", Candidate{Chrome: &StoreMetadata{Name: "Vault", Category: "Crypto tools"}}, true},
+	}
+	for _, tc := range cases {
+		if got := looksLikeWallet(tc.c); got != tc.want {
+			t.Errorf("%s: looksLikeWallet=%v want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package extsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 默认的商店元数据地址（公共服务，不保证长期可用；内网可替换为镜像/代理）。
+const (
+	DefaultChromeWebStoreURL = "https://chromewebstore.google.com/detail"
+	DefaultEdgeAddonsURL     = "https://microsoftedge.microsoft.com/addons/getproductdetailsbycrxid"
+)
+
+// StoreMetadata 是从扩展商店拉取的扩展元数据（best effort）。
+type StoreMetadata struct {
+	Store     string `json:"store"` // chrome_web_store|edge_addons
+	URL       string `json:"url"`
+	Name      string `json:"name,omitempty"`
+	Publisher string `json:"publisher,omitempty"`
+	UserCount int64  `json:"user_count,omitempty"`
+	Category  string `json:"category,omitempty"`
+}
+
+// WebStoreClient 查询 Chrome Web Store / Edge Add-ons 的扩展元数据。
+//
+// 说明：
+// - Chrome Web Store 没有公开 JSON API，这里解析详情页 HTML 的 og 标签与“xxx users”文本；
+// - Edge Add-ons 使用商店前端使用的 getproductdetailsbycrxid JSON 接口；
+// - 两者都可能随商店改版失效，因此只作为“候选规则”的参考信息，不直接进入正式规则。
+type WebStoreClient struct {
+	ChromeBaseURL string
+	EdgeBaseURL   string
+
+	HTTPClient *http.Client
+}
+
+func NewWebStoreClient(chromeBaseURL, edgeBaseURL string) *WebStoreClient {
+	return &WebStoreClient{
+		ChromeBaseURL: strings.TrimSpace(chromeBaseURL),
+		EdgeBaseURL:   strings.TrimSpace(edgeBaseURL),
+	}
+}
+
+var (
+	reChromeExtID   = regexp.MustCompile(`^[a-p]{32}$`)
+	reOGTitle       = regexp.MustCompile(`(?i)<meta[^>]+property="og:title"[^>]+content="([^"]*)"`)
+	reChromeUsers   = regexp.MustCompile(`(?i)([\d,\.]+)\s*\+?\s*users`)
+	reChromeOfferBy = regexp.MustCompile(`(?i)offered by[^<]*</[^>]+>\s*<[^>]+>([^<]+)<`)
+)
+
+// IsChromiumExtensionID 判断是否为 Chromium 系扩展 ID（32 位 a-p）。
+func IsChromiumExtensionID(extID string) bool {
+	return reChromeExtID.MatchString(strings.ToLower(strings.TrimSpace(extID)))
+}
+
+// FetchChrome 从 Chrome Web Store 拉取扩展元数据；扩展不存在时返回 nil, nil。
+func (c *WebStoreClient) FetchChrome(ctx context.Context, extID string) (*StoreMetadata, error) {
+	base := strings.TrimSpace(c.ChromeBaseURL)
+	if base == "" {
+		base = DefaultChromeWebStoreURL
+	}
+	u := strings.TrimRight(base, "/") + "/" + extID
+
+	body, status, err := c.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("chrome web store http %d", status)
+	}
+
+	page := string(body)
+	meta := &StoreMetadata{Store: "chrome_web_store", URL: u}
+	if m := reOGTitle.FindStringSubmatch(page); len(m) == 2 {
+		name := html.UnescapeString(strings.TrimSpace(m[1]))
+		name = strings.TrimSuffix(name, " - Chrome Web Store")
+		meta.Name = strings.TrimSpace(name)
+	}
+	if m := reChromeOfferBy.FindStringSubmatch(page); len(m) == 2 {
+		meta.Publisher = html.UnescapeString(strings.TrimSpace(m[1]))
+	}
+	if m := reChromeUsers.FindStringSubmatch(page); len(m) == 2 {
+		meta.UserCount = parseUserCount(m[1])
+	}
+	if meta.Name == "" {
+		// 商店对下架扩展通常仍返回 200 + 跳转首页，此时视为未找到。
+		return nil, nil
+	}
+	return meta, nil
+}
+
+type edgeProductResp struct {
+	Name               string `json:"name"`
+	Developer          string `json:"developer"`
+	ActiveInstallCount int64  `json:"activeInstallCount"`
+	Category           string `json:"category"`
+}
+
+// FetchEdge 从 Edge Add-ons 拉取扩展元数据；扩展不存在时返回 nil, nil。
+func (c *WebStoreClient) FetchEdge(ctx context.Context, extID string) (*StoreMetadata, error) {
+	base := strings.TrimSpace(c.EdgeBaseURL)
+	if base == "" {
+		base = DefaultEdgeAddonsURL
+	}
+	u := strings.TrimRight(base, "/") + "/" + extID
+
+	body, status, err := c.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound || status == http.StatusNoContent {
+		return nil, nil
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("edge addons http %d", status)
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return nil, nil
+	}
+
+	var resp edgeProductResp
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("decode edge addons response: %w", err)
+	}
+	if strings.TrimSpace(resp.Name) == "" {
+		return nil, nil
+	}
+	return &StoreMetadata{
+		Store:     "edge_addons",
+		URL:       u,
+		Name:      strings.TrimSpace(resp.Name),
+		Publisher: strings.TrimSpace(resp.Developer),
+		UserCount: resp.ActiveInstallCount,
+		Category:  strings.TrimSpace(resp.Category),
+	}, nil
+}
+
+func (c *WebStoreClient) get(ctx context.Context, u string) ([]byte, int, error) {
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: 15 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept-Language", "en-US,en;q=0.8")
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	return body, resp.StatusCode, nil
+}
+
+// parseUserCount 解析 "1,000,000" / "10000" 这类用户数文本（失败返回 0）。
+func parseUserCount(s string) int64 {
+	s = strings.NewReplacer(",", "", ".", "").Replace(strings.TrimSpace(s))
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,59 @@
+package extsync
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebStoreClient_FetchChromeAndEdge(t *testing.T) {
+	t.Parallel()
+
+	extID := "nkbihfbeogaeaoehlefnkodbefgpgknn"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chrome/" + extID:
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><head><meta property="og:title" content="MetaMask - Chrome Web Store"></head>
+<body><div>10,000,000 users</div></body></html>`))
+		case "/edge/" + extID:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"MetaMask","developer":"https://metamask.io","activeInstallCount":1234567,"category":"Productivity"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWebStoreClient(srv.URL+"/chrome", srv.URL+"/edge")
+
+	chrome, err := c.FetchChrome(context.Background(), extID)
+	if err != nil {
+		t.Fatalf("FetchChrome: %v", err)
+	}
+	if chrome == nil || chrome.Name != "MetaMask" || chrome.UserCount != 10000000 {
+		t.Fatalf("chrome=%+v", chrome)
+	}
+
+	edge, err := c.FetchEdge(context.Background(), extID)
+	if err != nil {
+		t.Fatalf("FetchEdge: %v", err)
+	}
+	if edge == nil || edge.Name != "MetaMask" || edge.UserCount != 1234567 {
+		t.Fatalf("edge=%+v", edge)
+	}
+
+	missing, err := c.FetchEdge(context.Background(), "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	if err != nil {
+		t.Fatalf("FetchEdge missing: %v", err)
+	}
+	if missing != nil {
+		t.Fatalf("expected nil for missing extension, got %+v", missing)
+	}
+
+	if !looksLikeWallet(Candidate{ExtensionID: extID, Chrome: chrome}) {
+		t.Fatalf("expected wallet-like candidate")
+	}
+}
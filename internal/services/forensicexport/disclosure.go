@@ -0,0 +1,826 @@
+package forensicexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/filetype"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/progress"
+	"crypto-inspector/internal/platform/snapshot"
+	"crypto-inspector/internal/platform/trace"
+	"crypto-inspector/internal/services/hitrollup"
+	"crypto-inspector/internal/services/holdings"
+	"crypto-inspector/internal/services/orgprofile"
+	"crypto-inspector/internal/services/xrefverify"
+)
+
+// 对外披露导出（遮盖副本）
+//
+// 对外提供证据时，常需要去掉与案件无关的内容（例如无关的浏览记录行）。
+// 分析人员在 redactions 表中标记要遮盖的位置，导出时：
+// - 原始证据文件与数据库记录保持不变，只在导出的 ZIP 中生成遮盖后的副本
+// - 被遮盖的值替换为 "[REDACTED sha256:<hex>]"，hex 为原值紧凑 JSON（键排序、不转义 HTML）的 sha256，
+//   持有原件的一方可据此复核“被遮盖的内容是什么”
+// - 整条遮盖的非 JSON 证据（ZIP/DB 等）以占位说明文件代替，哈希为原文件 sha256
+// - redactions.json 记录每条遮盖标记及其执行结果（applied/covered/not_found/failed）
+// - 内部报告（internal_json/html、PDF）包含未遮盖内容，不纳入披露包
+
+const (
+	disclosureManifestSchemaV1 = "crypto_inspector.disclosure_export_manifest.v1"
+	disclosureGeneratorVer     = "disclosure-exportzip-0.1.0"
+)
+
+// 遮盖目标类型。
+const (
+	RedactTargetArtifact = "artifact"
+	RedactTargetHit      = "hit"
+)
+
+// 遮盖执行结果。
+const (
+	RedactionApplied  = "applied"   // 已遮盖
+	RedactionCovered  = "covered"   // 上级位置已整体遮盖
+	RedactionNotFound = "not_found" // 目标或位置不存在
+	RedactionFailed   = "failed"    // 读取/解析失败
+)
+
+// ErrPointerNotFound 表示 JSON Pointer 在文档中不存在。
+var ErrPointerNotFound = errors.New("json pointer not found")
+
+// DisclosureOptions 定义对外披露导出参数（字段语义同 ZipOptions）。
+type DisclosureOptions struct {
+	CaseID string
+
+	DBPath       string
+	EvidenceRoot string
+
+	WalletRulePath   string
+	ExchangeRulePath string
+
+	Operator string
+	Note     string
+
+	ExportDir string
+
+	Password string
+}
+
+// RedactionLogEntry 是 redactions.json 中的一条记录。
+type RedactionLogEntry struct {
+	model.Redaction
+	ZipPath     string `json:"zip_path,omitempty"`
+	HashOf      string `json:"hash_of,omitempty"` // json_value|file
+	ValueSHA256 string `json:"value_sha256,omitempty"`
+	Status      string `json:"status"`
+	Error       string `json:"error,omitempty"`
+}
+
+// DisclosureResult 是一次披露导出的摘要输出。
+type DisclosureResult struct {
+	CaseID         string   `json:"case_id"`
+	ReportID       string   `json:"report_id"`
+	ReportNo       string   `json:"report_no,omitempty"`
+	ZipPath        string   `json:"zip_path"`
+	ZipSHA256      string   `json:"zip_sha256"`
+	Encryption     string   `json:"encryption,omitempty"`
+	RedactionCount int      `json:"redaction_count"`
+	AppliedCount   int      `json:"applied_count"`
+	Warnings       []string `json:"warnings,omitempty"`
+	StartedAt      int64    `json:"started_at"`
+	FinishedAt     int64    `json:"finished_at"`
+}
+
+// GenerateDisclosureZip 生成遮盖后的对外披露导出包，并在 reports 表中登记为 report_type=disclosure_zip。
+//
+// 输出 ZIP 内容（v1）：
+// - manifest.json：案件/证据/命中（已遮盖）/审计清单
+// - redactions.json：遮盖日志
+// - hashes.sha256：ZIP 内各文件（除自身）sha256 列表（证据文件前注释检材编号）
+// - exhibits.csv：检材清单
+// - evidence/..：证据快照（遮盖副本或原样副本）
+// - rules/..：规则文件
+//
+// 进度与取消处理同 GenerateForensicZip。
+func GenerateDisclosureZip(ctx context.Context, store *sqliteadapter.Store, opts DisclosureOptions) (_ *DisclosureResult, retErr error) {
+	ctx, span := trace.Start(ctx, "forensicexport.GenerateDisclosureZip")
+	defer func() { span.End(retErr) }()
+	tr := progress.Start(ctx, "load", "package", "manifest")
+
+	startedAt := time.Now().Unix()
+
+	caseID := strings.TrimSpace(opts.CaseID)
+	if caseID == "" {
+		return nil, fmt.Errorf("case_id is required")
+	}
+	password, encryption, err := exportPassword(opts.Password)
+	if err != nil {
+		return nil, err
+	}
+	dbPath := strings.TrimSpace(opts.DBPath)
+	if dbPath == "" {
+		dbPath = app.DefaultConfig().DBPath
+	}
+	evidenceRoot := strings.TrimSpace(opts.EvidenceRoot)
+	if evidenceRoot == "" {
+		evidenceRoot = "data/evidence"
+	}
+	operator := strings.TrimSpace(opts.Operator)
+	if operator == "" {
+		operator = "system"
+	}
+	zipPath := ""
+	defer func() {
+		cleanupCanceled(ctx, store, retErr, caseID, "disclosure_zip", operator, "forensicexport.GenerateDisclosureZip", zipPath)
+	}()
+	exportDir := strings.TrimSpace(opts.ExportDir)
+	if exportDir == "" {
+		exportDir = filepath.Join(filepath.Dir(dbPath), "exports")
+	}
+	if err := os.MkdirAll(exportDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create export dir: %w", err)
+	}
+
+	overview, err := store.GetCaseOverview(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if overview == nil {
+		return nil, fmt.Errorf("case not found: %s", caseID)
+	}
+	if err := xrefverify.PreExport(ctx, store, caseID, "disclosure_zip", operator, "forensicexport.GenerateDisclosureZip"); err != nil {
+		return nil, err
+	}
+	devices, err := store.ListCaseDevices(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	// 持有汇总先于补编检材编号与证据/审计列表计算：本次生成的 price_snapshot 证据与 price_quote 审计都进入导出包。
+	held, heldErr := holdings.ForCase(ctx, store, caseID, holdings.Options{
+		Operator:     operator,
+		Source:       "forensicexport.GenerateDisclosureZip",
+		EvidenceRoot: evidenceRoot,
+	})
+	// 检材编号：导出前为尚未编号的证据补编（已有编号不变）。
+	if _, err := store.AssignExhibitNumbers(ctx, caseID); err != nil {
+		return nil, err
+	}
+	artifacts, err := store.ListArtifactsByCase(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	hits, err := store.ListCaseHitDetails(ctx, caseID, "")
+	if err != nil {
+		return nil, err
+	}
+	prechecks, err := store.ListPrecheckResults(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	audits, err := store.ListAuditLogs(ctx, caseID, 5000)
+	if err != nil {
+		return nil, err
+	}
+	redactions, err := store.ListRedactions(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	byTarget := map[string][]model.Redaction{}
+	for _, r := range redactions {
+		k := r.TargetType + "|" + r.TargetID
+		byTarget[k] = append(byTarget[k], r)
+	}
+	logByID := map[string]*RedactionLogEntry{}
+	logEntries := make([]RedactionLogEntry, len(redactions))
+	for i, r := range redactions {
+		logEntries[i] = RedactionLogEntry{Redaction: r, Status: RedactionNotFound}
+		logByID[r.RedactionID] = &logEntries[i]
+	}
+
+	warnings := []string{"internal reports are excluded from disclosure exports (they contain unredacted content)"}
+	if heldErr != nil {
+		warnings = append(warnings, "aggregate holdings failed: "+heldErr.Error())
+	} else if held != nil {
+		warnings = append(warnings, held.Warnings...)
+	}
+	warnings = append(warnings, compatWarnings(artifacts)...)
+
+	stamp, err := orgprofile.Issue(ctx, store)
+	if err != nil {
+		warnings = append(warnings, "issue report number failed: "+err.Error())
+	}
+
+	zipName := stamp.FileName(fmt.Sprintf("%s_disclosure_export_%d.zip", caseID, time.Now().Unix()))
+	zipPath = filepath.Join(exportDir, zipName)
+	f, err := os.Create(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("create zip: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	zw := zip.NewWriter(f)
+	defer func() { _ = zw.Close() }()
+	if wm := stamp.Watermark(); wm != "" {
+		_ = zw.SetComment(wm + " - synthetic training data, not evidence")
+	}
+
+	var fileHashes []FileHashEntry
+	addBytes := func(zipPath, kind string, b []byte) error {
+		sum, size, err := writeZipFileFromBytes(zw, zipPath, b, password)
+		if err != nil {
+			return err
+		}
+		fileHashes = append(fileHashes, FileHashEntry{Path: zipPath, SHA256: sum, SizeBytes: size, Kind: kind})
+		return nil
+	}
+	addDisk := func(srcPath, zipPath, kind string) {
+		sum, size, err := writeZipFileFromDisk(ctx, zw, srcPath, zipPath, password)
+		if err != nil {
+			if ctx.Err() != nil {
+				return // 取消由下一次 tr.Step 返回
+			}
+			warnings = append(warnings, fmt.Sprintf("skip file %s -> %s: %v", srcPath, zipPath, err))
+			return
+		}
+		fileHashes = append(fileHashes, FileHashEntry{Path: zipPath, SHA256: sum, SizeBytes: size, Kind: kind})
+	}
+
+	// --- 证据快照 ---
+	evidenceBaseAbs := mustAbs(evidenceRoot)
+	manifestArtifacts := make([]ManifestArtifact, 0, len(artifacts))
+	for i, a := range artifacts {
+		if err := tr.Step("package", i, len(artifacts)); err != nil {
+			return nil, err
+		}
+		src := strings.TrimSpace(a.SnapshotPath)
+		if src == "" {
+			warnings = append(warnings, fmt.Sprintf("artifact %s snapshot_path empty", a.ArtifactID))
+			continue
+		}
+		rel := safeRel(evidenceBaseAbs, mustAbs(src))
+		if rel == "" {
+			rel = filepath.Join(a.DeviceID, filepath.Base(src))
+		}
+		entryPath := filepath.ToSlash(filepath.Join("evidence", rel))
+
+		marks := byTarget[RedactTargetArtifact+"|"+a.ArtifactID]
+		if len(marks) == 0 {
+			addDisk(src, entryPath, "artifact")
+			manifestArtifacts = append(manifestArtifacts, ManifestArtifact{Artifact: a, ZipPath: entryPath})
+			continue
+		}
+
+		// 遮盖前先复核原件完整性：哈希不一致时仍导出，但必须留下痕迹。
+		if sum, _, err := hash.File(src); err != nil {
+			markAll(logByID, marks, RedactionFailed, err.Error())
+			warnings = append(warnings, fmt.Sprintf("artifact %s read failed: %v", a.ArtifactID, err))
+			continue
+		} else if !strings.EqualFold(sum, a.SHA256) {
+			warnings = append(warnings, fmt.Sprintf("artifact %s sha256 mismatch before redaction: recorded=%s actual=%s", a.ArtifactID, a.SHA256, sum))
+		}
+
+		if whole := wholeRedaction(marks); whole != nil {
+			entryPath += ".redacted.txt"
+			placeholder := fmt.Sprintf("REDACTED artifact_id=%s sha256=%s size_bytes=%d redaction_id=%s reason=%s\n",
+				a.ArtifactID, a.SHA256, a.SizeBytes, whole.RedactionID, whole.Reason)
+			if err := addBytes(entryPath, "artifact", []byte(placeholder)); err != nil {
+				return nil, fmt.Errorf("write redacted artifact: %w", err)
+			}
+			for _, m := range marks {
+				e := logByID[m.RedactionID]
+				e.ZipPath = entryPath
+				if m.RedactionID == whole.RedactionID {
+					e.Status, e.HashOf, e.ValueSHA256 = RedactionApplied, "file", strings.ToLower(a.SHA256)
+				} else {
+					e.Status = RedactionCovered
+				}
+			}
+			manifestArtifacts = append(manifestArtifacts, ManifestArtifact{Artifact: a, ZipPath: entryPath, Redacted: true})
+			continue
+		}
+
+		if !isJSONArtifact(a) {
+			markAll(logByID, marks, RedactionFailed, "field redaction requires a json snapshot")
+			warnings = append(warnings, fmt.Sprintf("artifact %s is not json; field redactions skipped and artifact withheld", a.ArtifactID))
+			continue
+		}
+		raw, err := snapshot.ReadFile(src)
+		if err != nil {
+			markAll(logByID, marks, RedactionFailed, err.Error())
+			continue
+		}
+		// 遮盖后的内容以明文 JSON 写入导出包。
+		entryPath = strings.TrimSuffix(entryPath, snapshot.GzipExt)
+		pointers := make([]string, 0, len(marks))
+		for _, m := range marks {
+			pointers = append(pointers, m.Pointer)
+		}
+		out, results, err := RedactJSON(raw, pointers)
+		if err != nil {
+			// 无法安全遮盖时不导出该证据，避免泄露。
+			markAll(logByID, marks, RedactionFailed, err.Error())
+			warnings = append(warnings, fmt.Sprintf("artifact %s redaction failed; artifact withheld: %v", a.ArtifactID, err))
+			continue
+		}
+		for _, m := range marks {
+			e := logByID[m.RedactionID]
+			res := results[m.Pointer]
+			e.ZipPath, e.Status, e.ValueSHA256 = entryPath, res.Status, res.ValueSHA256
+			if res.Status == RedactionApplied {
+				e.HashOf = "json_value"
+			}
+		}
+		if err := addBytes(entryPath, "artifact", out); err != nil {
+			return nil, fmt.Errorf("write redacted artifact: %w", err)
+		}
+		manifestArtifacts = append(manifestArtifacts, ManifestArtifact{Artifact: a, ZipPath: entryPath, Redacted: true})
+	}
+
+	// --- 命中（manifest 内）---
+	for i := range hits {
+		marks := byTarget[RedactTargetHit+"|"+hits[i].HitID]
+		if len(marks) > 0 {
+			redactHit(&hits[i], marks, logByID)
+		}
+	}
+
+	// --- 规则 ---
+	walletRule := strings.TrimSpace(opts.WalletRulePath)
+	if walletRule == "" {
+		walletRule = app.DefaultConfig().WalletRulePath
+	}
+	exchangeRule := strings.TrimSpace(opts.ExchangeRulePath)
+	if exchangeRule == "" {
+		exchangeRule = app.DefaultConfig().ExchangeRulePath
+	}
+	addDisk(walletRule, filepath.ToSlash(filepath.Join("rules", filepath.Base(walletRule))), "rule")
+	addDisk(exchangeRule, filepath.ToSlash(filepath.Join("rules", filepath.Base(exchangeRule))), "rule")
+
+	if err := tr.Step("manifest", 0, 0); err != nil {
+		return nil, err
+	}
+
+	// --- exhibits.csv（检材清单） ---
+	exhibitRaw, err := exhibitCSV(manifestArtifacts)
+	if err != nil {
+		return nil, err
+	}
+	if err := addBytes(exhibitCSVPath, "exhibit_list", exhibitRaw); err != nil {
+		return nil, fmt.Errorf("write exhibits.csv to zip: %w", err)
+	}
+
+	applied := 0
+	for _, e := range logEntries {
+		switch e.Status {
+		case RedactionApplied, RedactionCovered:
+			applied++
+		case RedactionNotFound:
+			warnings = append(warnings, fmt.Sprintf("redaction %s target not found: %s %s %q", e.RedactionID, e.TargetType, e.TargetID, e.Pointer))
+		}
+	}
+
+	// --- redactions.json ---
+	logRaw, err := json.MarshalIndent(map[string]any{
+		"case_id":      caseID,
+		"generated_at": time.Now().Unix(),
+		"placeholder":  "[REDACTED sha256:<hex>]",
+		"hash_rule":    "json_value: sha256 of compact JSON (sorted keys, no HTML escaping) of the original value; file: sha256 of the original snapshot file",
+		"redactions":   logEntries,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal redaction log: %w", err)
+	}
+	if err := addBytes("redactions.json", "redaction_log", logRaw); err != nil {
+		return nil, fmt.Errorf("write redactions.json to zip: %w", err)
+	}
+
+	// --- manifest.json ---
+	rollup := hitrollup.Build(hits)
+	manifest := ZipManifest{
+		Schema:           disclosureManifestSchemaV1,
+		GeneratedAt:      time.Now().Unix(),
+		Case:             overview,
+		Devices:          devices,
+		Artifacts:        manifestArtifacts,
+		Hits:             hits,
+		HitRollup:        rollup,
+		WalletCategories: hitrollup.ByWalletCategory(hits),
+		Holdings:         held,
+		Prechecks:        prechecks,
+		Audits:           audits,
+		Reports:          []ManifestReport{},
+		Warnings:         warnings,
+		Note:             strings.TrimSpace(opts.Note),
+		Extra: map[string]any{
+			"evidence_root": evidenceRoot,
+			"disclosure":    true,
+			"organization":  stamp.Fields(),
+		},
+		Stats: map[string]any{
+			"device_count":     len(devices),
+			"artifact_count":   len(manifestArtifacts),
+			"hit_count":        len(hits),
+			"hit_rollup_count": len(rollup),
+			"precheck_count":   len(prechecks),
+			"audit_count":      len(audits),
+			"redaction_count":  len(redactions),
+			"applied_count":    applied,
+		},
+	}
+	manifest.App.Version = app.Version
+	manifest.App.Commit = app.Commit
+	manifest.App.BuildTime = app.BuildTime
+	sort.Slice(fileHashes, func(i, j int) bool { return fileHashes[i].Path < fileHashes[j].Path })
+	manifest.Files = append([]FileHashEntry(nil), fileHashes...)
+
+	manifestRaw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal manifest: %w", err)
+	}
+	if err := addBytes("manifest.json", "manifest", manifestRaw); err != nil {
+		return nil, fmt.Errorf("write manifest to zip: %w", err)
+	}
+
+	// --- hashes.sha256 ---
+	sort.Slice(fileHashes, func(i, j int) bool { return fileHashes[i].Path < fileHashes[j].Path })
+	hashLines := hashListLines([]string{
+		"# crypto-inspector disclosure export hash list",
+		fmt.Sprintf("# generated_at=%d", time.Now().Unix()),
+		"# format: <sha256><two spaces><path>",
+	}, fileHashes, manifestArtifacts)
+	if _, _, err := writeZipFileFromBytes(zw, "hashes.sha256", []byte(strings.Join(hashLines, "\n")), password); err != nil {
+		return nil, fmt.Errorf("write hashes.sha256 to zip: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("close zip writer: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("close zip file: %w", err)
+	}
+	zipSum, _, err := hash.File(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("hash zip: %w", err)
+	}
+	if err := tr.Step("manifest", 1, 1); err != nil {
+		return nil, err
+	}
+
+	reportID, err := store.SaveReport(ctx, caseID, "disclosure_zip", zipPath, zipSum, disclosureGeneratorVer, "ready")
+	if err != nil {
+		return nil, err
+	}
+	if err := stamp.Record(ctx, store, reportID); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	if encryption != "" {
+		if err := store.SetReportEncryption(ctx, reportID, encryption); err != nil {
+			warnings = append(warnings, err.Error())
+		}
+	}
+	_ = store.AppendAudit(ctx, caseID, "", "export", "disclosure_zip", "success", operator, "forensicexport.GenerateDisclosureZip", map[string]any{
+		"zip_path":        zipPath,
+		"zip_sha256":      zipSum,
+		"report_no":       stamp.ReportNo,
+		"encryption":      encryption,
+		"redaction_count": len(redactions),
+		"applied_count":   applied,
+		"warnings":        warnings,
+	})
+	tr.Done()
+
+	return &DisclosureResult{
+		CaseID:         caseID,
+		ReportID:       reportID,
+		ReportNo:       stamp.ReportNo,
+		ZipPath:        zipPath,
+		ZipSHA256:      zipSum,
+		Encryption:     encryption,
+		RedactionCount: len(redactions),
+		AppliedCount:   applied,
+		Warnings:       warnings,
+		StartedAt:      startedAt,
+		FinishedAt:     time.Now().Unix(),
+	}, nil
+}
+
+// PointerResult 是单个 JSON Pointer 的遮盖结果。
+type PointerResult struct {
+	Status      string
+	ValueSHA256 string
+}
+
+// RedactJSON 把文档中指定 JSON Pointer 位置的值替换为遮盖占位串，返回新文档与各位置结果。
+// 空 pointer 表示整个文档；上级位置已遮盖的下级位置记为 covered。
+func RedactJSON(raw []byte, pointers []string) ([]byte, map[string]PointerResult, error) {
+	doc, err := decodeJSON(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sorted := append([]string(nil), pointers...)
+	sort.Strings(sorted) // 上级位置排在下级位置之前
+	results := make(map[string]PointerResult, len(sorted))
+	var applied []string
+	for _, p := range sorted {
+		if _, done := results[p]; done {
+			continue
+		}
+		if coveredBy(p, applied) {
+			results[p] = PointerResult{Status: RedactionCovered}
+			continue
+		}
+		tokens, err := ParsePointer(p)
+		if err != nil {
+			return nil, nil, err
+		}
+		next, sum, err := redactAt(doc, tokens)
+		if errors.Is(err, ErrPointerNotFound) {
+			results[p] = PointerResult{Status: RedactionNotFound}
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		doc = next
+		applied = append(applied, p)
+		results[p] = PointerResult{Status: RedactionApplied, ValueSHA256: sum}
+	}
+
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode redacted json: %w", err)
+	}
+	return out, results, nil
+}
+
+// CheckPointer 校验 JSON Pointer 在文档中存在（用于标记时提前发现错误位置）。
+func CheckPointer(raw []byte, pointer string) error {
+	tokens, err := ParsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	doc, err := decodeJSON(raw)
+	if err != nil {
+		return err
+	}
+	for _, t := range tokens {
+		switch v := doc.(type) {
+		case map[string]any:
+			child, ok := v[t]
+			if !ok {
+				return ErrPointerNotFound
+			}
+			doc = child
+		case []any:
+			i, ok := arrayIndex(t, len(v))
+			if !ok {
+				return ErrPointerNotFound
+			}
+			doc = v[i]
+		default:
+			return ErrPointerNotFound
+		}
+	}
+	return nil
+}
+
+// ParsePointer 解析 RFC 6901 JSON Pointer（"" 表示整个文档）。
+func ParsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(p, "/") {
+		return nil, fmt.Errorf("invalid json pointer %q: must be empty or start with '/'", p)
+	}
+	parts := strings.Split(p[1:], "/")
+	for i, s := range parts {
+		parts[i] = strings.ReplaceAll(strings.ReplaceAll(s, "~1", "/"), "~0", "~")
+	}
+	return parts, nil
+}
+
+// RedactedPlaceholder 返回遮盖占位串。
+func RedactedPlaceholder(sum string) string {
+	return "[REDACTED sha256:" + sum + "]"
+}
+
+func redactAt(doc any, tokens []string) (any, string, error) {
+	if len(tokens) == 0 {
+		sum, err := valueSHA256(doc)
+		if err != nil {
+			return nil, "", err
+		}
+		return RedactedPlaceholder(sum), sum, nil
+	}
+	switch v := doc.(type) {
+	case map[string]any:
+		child, ok := v[tokens[0]]
+		if !ok {
+			return nil, "", ErrPointerNotFound
+		}
+		next, sum, err := redactAt(child, tokens[1:])
+		if err != nil {
+			return nil, "", err
+		}
+		v[tokens[0]] = next
+		return v, sum, nil
+	case []any:
+		i, ok := arrayIndex(tokens[0], len(v))
+		if !ok {
+			return nil, "", ErrPointerNotFound
+		}
+		next, sum, err := redactAt(v[i], tokens[1:])
+		if err != nil {
+			return nil, "", err
+		}
+		v[i] = next
+		return v, sum, nil
+	default:
+		return nil, "", ErrPointerNotFound
+	}
+}
+
+func arrayIndex(tok string, n int) (int, bool) {
+	if tok == "" || (len(tok) > 1 && tok[0] == '0') {
+		return 0, false
+	}
+	i, err := strconv.Atoi(tok)
+	if err != nil || i < 0 || i >= n {
+		return 0, false
+	}
+	return i, true
+}
+
+func coveredBy(p string, applied []string) bool {
+	for _, a := range applied {
+		if a == "" || p == a || strings.HasPrefix(p, a+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func decodeJSON(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+	return doc, nil
+}
+
+// valueSHA256 计算值的紧凑 JSON（键排序、不转义 HTML）的 sha256。
+func valueSHA256(v any) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", fmt.Errorf("encode value: %w", err)
+	}
+	sum := sha256.Sum256(bytes.TrimRight(buf.Bytes(), "\n"))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// redactHit 在 manifest 的命中记录上执行遮盖：空 pointer 遮盖 matched_value 与整个 detail_json，
+// 否则 pointer 指向 detail_json 内的位置。
+func redactHit(h *model.HitDetail, marks []model.Redaction, logByID map[string]*RedactionLogEntry) {
+	if whole := wholeRedaction(marks); whole != nil {
+		var detail any = h.DetailJSON
+		if d, err := decodeJSON([]byte(h.DetailJSON)); err == nil {
+			detail = d
+		}
+		sum, err := valueSHA256(map[string]any{"matched_value": h.MatchedValue, "detail": detail})
+		if err != nil {
+			markAll(logByID, marks, RedactionFailed, err.Error())
+			return
+		}
+		h.MatchedValue = RedactedPlaceholder(sum)
+		h.DetailJSON = strconv.Quote(RedactedPlaceholder(sum))
+		for _, m := range marks {
+			e := logByID[m.RedactionID]
+			if m.RedactionID == whole.RedactionID {
+				e.Status, e.HashOf, e.ValueSHA256 = RedactionApplied, "json_value", sum
+			} else {
+				e.Status = RedactionCovered
+			}
+		}
+		return
+	}
+
+	pointers := make([]string, 0, len(marks))
+	for _, m := range marks {
+		pointers = append(pointers, m.Pointer)
+	}
+	out, results, err := RedactJSON([]byte(h.DetailJSON), pointers)
+	if err != nil {
+		// 无法解析时整体遮盖 detail_json，宁可多遮也不泄露。
+		h.DetailJSON = strconv.Quote("[REDACTED]")
+		markAll(logByID, marks, RedactionFailed, err.Error())
+		return
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, out); err == nil {
+		out = compact.Bytes()
+	}
+	h.DetailJSON = string(out)
+	for _, m := range marks {
+		e := logByID[m.RedactionID]
+		res := results[m.Pointer]
+		e.Status, e.ValueSHA256 = res.Status, res.ValueSHA256
+		if res.Status == RedactionApplied {
+			e.HashOf = "json_value"
+		}
+	}
+}
+
+func wholeRedaction(marks []model.Redaction) *model.Redaction {
+	for i := range marks {
+		if marks[i].Pointer == "" {
+			return &marks[i]
+		}
+	}
+	return nil
+}
+
+func markAll(logByID map[string]*RedactionLogEntry, marks []model.Redaction, status, msg string) {
+	for _, m := range marks {
+		e := logByID[m.RedactionID]
+		e.Status, e.Error = status, msg
+	}
+}
+
+func isJSONArtifact(a model.ArtifactInfo) bool {
+	if a.MimeType != "" {
+		return a.MimeType == filetype.MimeJSON
+	}
+	return strings.EqualFold(filepath.Ext(strings.TrimSuffix(a.SnapshotPath, snapshot.GzipExt)), ".json")
+}
+
+// ValidateRedaction 校验遮盖标记：目标必须属于案件，pointer 必须能在目标 JSON 中定位。
+func ValidateRedaction(ctx context.Context, store *sqliteadapter.Store, r model.Redaction) error {
+	if _, err := ParsePointer(r.Pointer); err != nil {
+		return apperr.Wrap(apperr.CodeInvalidArgument, err, "invalid pointer")
+	}
+	switch r.TargetType {
+	case RedactTargetArtifact:
+		info, err := store.GetArtifactInfo(ctx, r.TargetID)
+		if err != nil {
+			return err
+		}
+		if info == nil || info.CaseID != r.CaseID {
+			return apperr.New(apperr.CodeNotFound, fmt.Sprintf("artifact not found in case: %s", r.TargetID))
+		}
+		if r.Pointer == "" {
+			return nil
+		}
+		if !isJSONArtifact(*info) {
+			return apperr.New(apperr.CodeInvalidArgument, "field redaction requires a json snapshot; use an empty pointer to redact the whole artifact")
+		}
+		raw, err := snapshot.ReadFile(info.SnapshotPath)
+		if err != nil {
+			return fmt.Errorf("read snapshot: %w", err)
+		}
+		if err := CheckPointer(raw, r.Pointer); err != nil {
+			return apperr.Wrap(apperr.CodeInvalidArgument, err, fmt.Sprintf("pointer %q", r.Pointer))
+		}
+		return nil
+	case RedactTargetHit:
+		hits, err := store.ListCaseHitDetails(ctx, r.CaseID, "")
+		if err != nil {
+			return err
+		}
+		for _, h := range hits {
+			if h.HitID != r.TargetID {
+				continue
+			}
+			if r.Pointer == "" {
+				return nil
+			}
+			if err := CheckPointer([]byte(h.DetailJSON), r.Pointer); err != nil {
+				return apperr.Wrap(apperr.CodeInvalidArgument, err, fmt.Sprintf("pointer %q", r.Pointer))
+			}
+			return nil
+		}
+		return apperr.New(apperr.CodeNotFound, fmt.Sprintf("hit not found in case: %s", r.TargetID))
+	default:
+		return apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid target_type: %s (want artifact|hit)", r.TargetType))
+	}
+}
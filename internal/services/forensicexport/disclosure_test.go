@@ -0,0 +1,237 @@
+package forensicexport
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/progress"
+	"crypto-inspector/internal/platform/zipaes"
+
+	_ "modernc.org/sqlite"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestRedactJSON(t *testing.T) {
+	raw := []byte(`[{"url":"https://a.example/?q=<x>","visit_count":3},{"url":"https://b.example","title":"b"}]`)
+	out, res, err := RedactJSON(raw, []string{"/0/url", "/1", "/1/title", "/5"})
+	if err != nil {
+		t.Fatalf("RedactJSON: %v", err)
+	}
+	if r := res["/0/url"]; r.Status != RedactionApplied || r.ValueSHA256 != sha256Hex(`"https://a.example/?q=<x>"`) {
+		t.Fatalf("/0/url=%+v", r)
+	}
+	if r := res["/1"]; r.Status != RedactionApplied || r.ValueSHA256 != sha256Hex(`{"title":"b","url":"https://b.example"}`) {
+		t.Fatalf("/1=%+v", r)
+	}
+	if res["/1/title"].Status != RedactionCovered || res["/5"].Status != RedactionNotFound {
+		t.Fatalf("res=%+v", res)
+	}
+
+	var doc []map[string]any
+	if err := json.Unmarshal(out, &doc); err == nil {
+		t.Fatalf("record 1 should be replaced by a string")
+	}
+	var generic []any
+	if err := json.Unmarshal(out, &generic); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	first := generic[0].(map[string]any)
+	if first["url"] != RedactedPlaceholder(res["/0/url"].ValueSHA256) || first["visit_count"] != float64(3) {
+		t.Fatalf("first=%v", first)
+	}
+
+	if _, err := ParsePointer("url"); err == nil {
+		t.Fatalf("expected invalid pointer error")
+	}
+	if err := CheckPointer(raw, "/0/a~1b"); err != ErrPointerNotFound {
+		t.Fatalf("CheckPointer err=%v", err)
+	}
+}
+
+func TestGenerateDisclosureZip(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "inspector.db")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatalf("EnsureCase: %v", err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "host", OS: model.OSType("windows"), Identifier: "h"}, true, ""); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+
+	evidenceRoot := filepath.Join(dir, "evidence")
+	snap := filepath.Join(evidenceRoot, caseID, "dev_1", "browser_history.json")
+	_ = os.MkdirAll(filepath.Dir(snap), 0o755)
+	original := `[{"url":"https://exchange.example"},{"url":"https://private.example/medical"}]`
+	if err := os.WriteFile(snap, []byte(original), 0o644); err != nil {
+		t.Fatalf("write snapshot: %v", err)
+	}
+	sum, size, _ := hash.File(snap)
+	if err := store.SaveArtifacts(ctx, []model.Artifact{{
+		ID: "art_1", CaseID: caseID, DeviceID: "dev_1", Type: model.ArtifactBrowserHistory,
+		SourceRef: "chrome", SnapshotPath: snap, SHA256: sum, SizeBytes: size, MimeType: "application/json",
+		CollectedAt: 1, CollectorName: "test", CollectorVersion: "0", PayloadJSON: []byte(original), RecordHash: strings.Repeat("0", 64),
+	}}); err != nil {
+		t.Fatalf("SaveArtifacts: %v", err)
+	}
+
+	mark := model.Redaction{CaseID: caseID, TargetType: RedactTargetArtifact, TargetID: "art_1", Pointer: "/1", Reason: "unrelated"}
+	if err := ValidateRedaction(ctx, store, mark); err != nil {
+		t.Fatalf("ValidateRedaction: %v", err)
+	}
+	if _, err := store.AddRedaction(ctx, mark); err != nil {
+		t.Fatalf("AddRedaction: %v", err)
+	}
+	bad := mark
+	bad.Pointer = "/9"
+	if err := ValidateRedaction(ctx, store, bad); err == nil {
+		t.Fatalf("expected pointer validation error")
+	}
+
+	res, err := GenerateDisclosureZip(ctx, store, DisclosureOptions{
+		CaseID: caseID, DBPath: dbPath, EvidenceRoot: evidenceRoot,
+		WalletRulePath: filepath.Join(dir, "missing_wallet.yaml"), ExchangeRulePath: filepath.Join(dir, "missing_exchange.yaml"),
+	})
+	if err != nil {
+		t.Fatalf("GenerateDisclosureZip: %v", err)
+	}
+	if res.RedactionCount != 1 || res.AppliedCount != 1 {
+		t.Fatalf("res=%+v", res)
+	}
+
+	// 原始证据不变。
+	if raw, _ := os.ReadFile(snap); string(raw) != original {
+		t.Fatalf("original evidence modified")
+	}
+
+	zr, err := zip.OpenReader(res.ZipPath)
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+	defer zr.Close()
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(b)
+	}
+	ev := files["evidence/"+caseID+"/dev_1/browser_history.json"]
+	if strings.Contains(ev, "private.example") || !strings.Contains(ev, "exchange.example") {
+		t.Fatalf("redacted evidence=%s", ev)
+	}
+	wantHash := sha256Hex(`{"url":"https://private.example/medical"}`)
+	if !strings.Contains(ev, RedactedPlaceholder(wantHash)) {
+		t.Fatalf("placeholder missing: %s", ev)
+	}
+	if !strings.Contains(files["redactions.json"], wantHash) || !strings.Contains(files["redactions.json"], `"status": "applied"`) {
+		t.Fatalf("redaction log=%s", files["redactions.json"])
+	}
+	if _, ok := files["manifest.json"]; !ok {
+		t.Fatalf("manifest missing")
+	}
+
+	// 检材编号：导出时分配，清单与哈希文件注释引用同一编号；再次导出不重新编号。
+	if !strings.Contains(files[exhibitCSVPath], "检材-001,1,art_1,") {
+		t.Fatalf("exhibits.csv=%s", files[exhibitCSVPath])
+	}
+	if !strings.Contains(files["hashes.sha256"], "# 检材-001 artifact_id=art_1") {
+		t.Fatalf("hashes.sha256=%s", files["hashes.sha256"])
+	}
+	if n, err := store.AssignExhibitNumbers(ctx, caseID); err != nil || n != 0 {
+		t.Fatalf("AssignExhibitNumbers again: n=%d err=%v", n, err)
+	}
+
+	// 加密导出：条目为 AES-256，需密码解开；reports 记录加密方式，过短的密码被拒绝。
+	if _, err := GenerateDisclosureZip(ctx, store, DisclosureOptions{CaseID: caseID, DBPath: dbPath, EvidenceRoot: evidenceRoot, Password: "short"}); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("short password err=%v", err)
+	}
+	enc, err := GenerateDisclosureZip(ctx, store, DisclosureOptions{
+		CaseID: caseID, DBPath: dbPath, EvidenceRoot: evidenceRoot, ExportDir: filepath.Join(dir, "encrypted"), Password: "usb-stick-pass",
+		WalletRulePath: filepath.Join(dir, "missing_wallet.yaml"), ExchangeRulePath: filepath.Join(dir, "missing_exchange.yaml"),
+	})
+	if err != nil || enc.Encryption != model.ReportEncryptionZipAES256 {
+		t.Fatalf("encrypted export res=%+v err=%v", enc, err)
+	}
+	if info, _ := store.GetReportByID(ctx, enc.ReportID); info == nil || info.Encryption != model.ReportEncryptionZipAES256 {
+		t.Fatalf("report row=%+v", info)
+	}
+	ezr, err := zip.OpenReader(enc.ZipPath)
+	if err != nil {
+		t.Fatalf("open encrypted zip: %v", err)
+	}
+	defer ezr.Close()
+	for _, f := range ezr.File {
+		if !zipaes.IsEncrypted(f) {
+			t.Fatalf("entry %s not encrypted", f.Name)
+		}
+		if f.Name != "hashes.sha256" {
+			continue
+		}
+		if _, err := zipaes.Open(f, []byte("wrong-password")); !errors.Is(err, zipaes.ErrPassword) {
+			t.Fatalf("wrong password err=%v", err)
+		}
+		rc, err := zipaes.Open(f, []byte("usb-stick-pass"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(rc)
+		if err != nil || !strings.Contains(string(b), "# 检材-001 artifact_id=art_1") {
+			t.Fatalf("decrypted hashes.sha256 err=%v: %s", err, b)
+		}
+	}
+
+	// 打包阶段取消：返回 context.Canceled，不留下 zip 文件与报告记录。
+	before, _ := store.ListReportsByCase(ctx, caseID)
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var stages []string
+	cctx = progress.WithReporter(cctx, func(u progress.Update) {
+		stages = append(stages, u.Stage)
+		if u.Stage == "package" {
+			cancel()
+		}
+	})
+	exportDir := filepath.Join(dir, "canceled")
+	if _, err := GenerateDisclosureZip(cctx, store, DisclosureOptions{
+		CaseID: caseID, DBPath: dbPath, EvidenceRoot: evidenceRoot, ExportDir: exportDir,
+		WalletRulePath: filepath.Join(dir, "missing_wallet.yaml"), ExchangeRulePath: filepath.Join(dir, "missing_exchange.yaml"),
+	}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled export err=%v stages=%v", err, stages)
+	}
+	if left, _ := filepath.Glob(filepath.Join(exportDir, "*.zip")); len(left) != 0 {
+		t.Fatalf("partial zip left behind: %v", left)
+	}
+	if after, _ := store.ListReportsByCase(ctx, caseID); len(after) != len(before) {
+		t.Fatalf("canceled export saved a report: before=%d after=%d", len(before), len(after))
+	}
+}
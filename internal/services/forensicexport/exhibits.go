@@ -0,0 +1,72 @@
+package forensicexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// 检材清单
+//
+// 导出包内的证据按案件检材编号（检材-001 ...）引用：
+// - exhibits.csv：检材编号、证据 ID、类型、来源、sha256 与 ZIP 内路径（按编号排序）
+// - hashes.sha256：每个证据文件前加一行 "# 检材-001 artifact_id=..." 注释（sha256sum -c 会忽略注释行）
+
+// exhibitCSVPath 是检材清单在 ZIP 内的路径。
+const exhibitCSVPath = "exhibits.csv"
+
+// exhibitCSV 生成检材清单 CSV。
+func exhibitCSV(items []ManifestArtifact) ([]byte, error) {
+	sorted := append([]ManifestArtifact(nil), items...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Artifact.ExhibitNo, sorted[j].Artifact.ExhibitNo
+		if (a > 0) != (b > 0) {
+			return a > 0
+		}
+		return a < b
+	})
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"exhibit", "exhibit_no", "artifact_id", "artifact_type", "device_id", "source_ref", "sha256", "size_bytes", "collected_at", "zip_path", "redacted"})
+	for _, it := range sorted {
+		a := it.Artifact
+		_ = w.Write([]string{
+			a.Exhibit,
+			strconv.FormatInt(a.ExhibitNo, 10),
+			a.ArtifactID,
+			a.ArtifactType,
+			a.DeviceID,
+			a.SourceRef,
+			a.SHA256,
+			strconv.FormatInt(a.SizeBytes, 10),
+			time.Unix(a.CollectedAt, 0).Format(time.RFC3339),
+			it.ZipPath,
+			strconv.FormatBool(it.Redacted),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("write exhibits csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// hashListLines 按 sha256sum 格式输出文件哈希行，证据文件前附检材编号注释。
+func hashListLines(header []string, files []FileHashEntry, items []ManifestArtifact) []string {
+	exhibits := make(map[string]ManifestArtifact, len(items))
+	for _, it := range items {
+		exhibits[it.ZipPath] = it
+	}
+	lines := append([]string{}, header...)
+	for _, fh := range files {
+		if it, ok := exhibits[fh.Path]; ok && it.Artifact.Exhibit != "" {
+			lines = append(lines, fmt.Sprintf("# %s artifact_id=%s", it.Artifact.Exhibit, it.Artifact.ArtifactID))
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s", fh.SHA256, fh.Path))
+	}
+	return append(lines, "")
+}
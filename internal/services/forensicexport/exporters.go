@@ -0,0 +1,104 @@
+package forensicexport
+
+import (
+	"context"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/services/exporter"
+)
+
+func init() {
+	exporter.Register(forensicZipExporter{})
+	exporter.Register(disclosureZipExporter{})
+}
+
+// forensicZipExporter 把司法导出包注册为 forensic-zip。
+type forensicZipExporter struct{}
+
+func (forensicZipExporter) Kind() string { return "forensic-zip" }
+
+func (forensicZipExporter) SupportsEncryption() bool { return true }
+
+func (forensicZipExporter) Description() string {
+	return "司法导出包（证据快照 + manifest.json + hashes.sha256）"
+}
+
+func (forensicZipExporter) Export(ctx context.Context, store *sqliteadapter.Store, req exporter.Request) (*exporter.Result, error) {
+	res, err := GenerateForensicZip(ctx, store, ZipOptions{
+		CaseID:           req.CaseID,
+		DBPath:           req.DBPath,
+		EvidenceRoot:     req.EvidenceRoot,
+		WalletRulePath:   req.WalletRulePath,
+		ExchangeRulePath: req.ExchangeRulePath,
+		Operator:         req.Operator,
+		Note:             req.Note,
+		ExportDir:        req.ExportDir,
+		Password:         req.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &exporter.Result{
+		CaseID:   res.CaseID,
+		ReportID: res.ReportID,
+		ReportNo: res.ReportNo,
+		Path:     res.ZipPath,
+		SHA256:   res.ZipSHA256,
+		FileKey:  "zip",
+		Warnings: res.Warnings,
+		Extra:    encryptionExtra(res.Encryption),
+	}, nil
+}
+
+// disclosureZipExporter 把遮盖后的对外披露包注册为 disclosure-zip。
+type disclosureZipExporter struct{}
+
+func (disclosureZipExporter) Kind() string { return "disclosure-zip" }
+
+func (disclosureZipExporter) SupportsEncryption() bool { return true }
+
+func (disclosureZipExporter) Description() string {
+	return "对外披露导出包（按遮盖标记处理，附 redactions.json）"
+}
+
+func (disclosureZipExporter) Export(ctx context.Context, store *sqliteadapter.Store, req exporter.Request) (*exporter.Result, error) {
+	res, err := GenerateDisclosureZip(ctx, store, DisclosureOptions{
+		CaseID:           req.CaseID,
+		DBPath:           req.DBPath,
+		EvidenceRoot:     req.EvidenceRoot,
+		WalletRulePath:   req.WalletRulePath,
+		ExchangeRulePath: req.ExchangeRulePath,
+		Operator:         req.Operator,
+		Note:             req.Note,
+		ExportDir:        req.ExportDir,
+		Password:         req.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+	extra := map[string]any{
+		"redaction_count": res.RedactionCount,
+		"applied_count":   res.AppliedCount,
+	}
+	for k, v := range encryptionExtra(res.Encryption) {
+		extra[k] = v
+	}
+	return &exporter.Result{
+		CaseID:   res.CaseID,
+		ReportID: res.ReportID,
+		ReportNo: res.ReportNo,
+		Path:     res.ZipPath,
+		SHA256:   res.ZipSHA256,
+		FileKey:  "zip",
+		Warnings: res.Warnings,
+		Extra:    extra,
+	}, nil
+}
+
+// encryptionExtra 在加密导出时返回 {"encryption": ...}，未加密时为 nil。
+func encryptionExtra(encryption string) map[string]any {
+	if encryption == "" {
+		return nil
+	}
+	return map[string]any{"encryption": encryption}
+}
@@ -14,11 +14,20 @@ import (
 	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
 	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
 	"crypto-inspector/internal/domain/model"
 	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/progress"
+	"crypto-inspector/internal/platform/trace"
+	"crypto-inspector/internal/platform/zipaes"
+	"crypto-inspector/internal/services/hitrollup"
+	"crypto-inspector/internal/services/holdings"
+	"crypto-inspector/internal/services/orgprofile"
+	"crypto-inspector/internal/services/xrefverify"
 )
 
 // ZipOptions 定义“司法导出包（ZIP）”生成参数。
@@ -44,18 +53,23 @@ type ZipOptions struct {
 
 	// ExportDir 可选：显式指定导出目录。
 	ExportDir string
+
+	// Password 非空时各条目写成 AES-256 加密（WinZip AE-2），至少 zipaes.MinPasswordLen 个字符；
+	// 密码不落库、不写审计，reports.encryption 只记录加密方式。
+	Password string
 }
 
 type FileHashEntry struct {
 	Path      string `json:"path"`       // ZIP 内路径（使用 "/" 分隔）
 	SHA256    string `json:"sha256"`     // 文件内容 SHA-256
 	SizeBytes int64  `json:"size_bytes"` // 原始字节数
-	Kind      string `json:"kind"`       // artifact|report|rule|manifest
+	Kind      string `json:"kind"`       // artifact|report|rule|exhibit_list|manifest
 }
 
 type ManifestArtifact struct {
 	Artifact model.ArtifactInfo `json:"artifact"`
 	ZipPath  string             `json:"zip_path"`
+	Redacted bool               `json:"redacted,omitempty"` // 披露导出：ZIP 内为遮盖副本（artifact.sha256 仍为原件哈希）
 }
 
 type ManifestReport struct {
@@ -73,26 +87,33 @@ type ZipManifest struct {
 		BuildTime string `json:"build_time"`
 	} `json:"app"`
 
-	Case      *model.CaseOverview    `json:"case"`
-	Devices   []model.CaseDevice     `json:"devices"`
-	Artifacts []ManifestArtifact     `json:"artifacts"`
-	Hits      []model.HitDetail      `json:"hits"`
-	Prechecks []model.PrecheckResult `json:"prechecks"`
-	Audits    []model.AuditLog       `json:"audits"`
-	Reports   []ManifestReport       `json:"reports"`
-	Files     []FileHashEntry        `json:"files"`
-	Warnings  []string               `json:"warnings,omitempty"`
-	Note      string                 `json:"note,omitempty"`
-	Extra     map[string]any         `json:"extra,omitempty"`
-	Stats     map[string]any         `json:"stats,omitempty"`
+	Case      *model.CaseOverview `json:"case"`
+	Devices   []model.CaseDevice  `json:"devices"`
+	Artifacts []ManifestArtifact  `json:"artifacts"`
+	Hits      []model.HitDetail   `json:"hits"`
+	HitRollup []model.HitRollup   `json:"hit_rollup,omitempty"` // 案件级汇总（按规则 + 命中值跨设备合并），逐设备明细见 hits
+	// WalletCategories 是钱包安装/运行命中按托管类型的分组（custodial/non_custodial/...）。
+	WalletCategories []model.WalletCategoryGroup `json:"wallet_categories,omitempty"`
+	Holdings         *holdings.Summary           `json:"holdings,omitempty"` // 已识别持有汇总（按价格来源折算；未配置价格来源时省略）
+	Prechecks        []model.PrecheckResult      `json:"prechecks"`
+	ScanRuns         []model.ScanRun             `json:"scan_runs,omitempty"` // 每次扫描的规范化参数快照（options 原样收录）
+	Audits           []model.AuditLog            `json:"audits"`
+	Reports          []ManifestReport            `json:"reports"`
+	Files            []FileHashEntry             `json:"files"`
+	Warnings         []string                    `json:"warnings,omitempty"`
+	Note             string                      `json:"note,omitempty"`
+	Extra            map[string]any              `json:"extra,omitempty"`
+	Stats            map[string]any              `json:"stats,omitempty"`
 }
 
 // ZipResult 是一次 ZIP 导出任务的摘要输出。
 type ZipResult struct {
 	CaseID     string   `json:"case_id"`
 	ReportID   string   `json:"report_id"`
+	ReportNo   string   `json:"report_no,omitempty"`
 	ZipPath    string   `json:"zip_path"`
 	ZipSHA256  string   `json:"zip_sha256"`
+	Encryption string   `json:"encryption,omitempty"`
 	Warnings   []string `json:"warnings,omitempty"`
 	StartedAt  int64    `json:"started_at"`
 	FinishedAt int64    `json:"finished_at"`
@@ -107,17 +128,29 @@ const (
 //
 // 输出 ZIP 内容（v1）：
 // - manifest.json：案件/证据/命中/审计/报告的结构化清单
-// - hashes.sha256：ZIP 内各文件（除自身）sha256 列表（sha256sum 兼容格式）
+// - hashes.sha256：ZIP 内各文件（除自身）sha256 列表（sha256sum 兼容格式，证据文件前注释检材编号）
+// - exhibits.csv：检材清单（检材编号 → 证据 ID / sha256 / ZIP 内路径）
 // - evidence/..：证据快照文件（原始 snapshot JSON）
 // - reports/..：报告产物文件（internal_json/forensic_pdf 等，不包含 forensic_zip 以避免递归）
 // - rules/..：规则文件（wallet/exchange）
-func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts ZipOptions) (*ZipResult, error) {
+//
+// 进度按 load/package/manifest 阶段经 ctx 上的 progress.Reporter 上报（package 阶段按文件计数）；
+// ctx 取消时在下一个文件（或大文件复制途中）中止，删除未完成的 ZIP 并写入 failed 审计，不登记报告。
+func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts ZipOptions) (_ *ZipResult, retErr error) {
+	ctx, span := trace.Start(ctx, "forensicexport.GenerateForensicZip")
+	defer func() { span.End(retErr) }()
+	tr := progress.Start(ctx, "load", "package", "manifest")
+
 	startedAt := time.Now().Unix()
 
 	caseID := strings.TrimSpace(opts.CaseID)
 	if caseID == "" {
 		return nil, fmt.Errorf("case_id is required")
 	}
+	password, encryption, err := exportPassword(opts.Password)
+	if err != nil {
+		return nil, err
+	}
 
 	dbPath := strings.TrimSpace(opts.DBPath)
 	if dbPath == "" {
@@ -131,6 +164,10 @@ func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts Z
 	if operator == "" {
 		operator = "system"
 	}
+	zipPath := ""
+	defer func() {
+		cleanupCanceled(ctx, store, retErr, caseID, "forensic_zip", operator, "forensicexport.GenerateForensicZip", zipPath)
+	}()
 
 	exportDir := strings.TrimSpace(opts.ExportDir)
 	if exportDir == "" {
@@ -148,12 +185,26 @@ func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts Z
 	if overview == nil {
 		return nil, fmt.Errorf("case not found: %s", caseID)
 	}
+	// 导出前检查：命中引用的证据记录与快照文件必须齐全，否则拒绝导出。
+	if err := xrefverify.PreExport(ctx, store, caseID, "forensic_zip", operator, "forensicexport.GenerateForensicZip"); err != nil {
+		return nil, err
+	}
 
 	// --- 拉取案件数据（全部用于 manifest；文件内容只打包快照/报告/规则） ---
 	devices, err := store.ListCaseDevices(ctx, caseID)
 	if err != nil {
 		return nil, err
 	}
+	// 持有汇总先于补编检材编号与证据/审计列表计算：本次生成的 price_snapshot 证据与 price_quote 审计都进入导出包。
+	held, heldErr := holdings.ForCase(ctx, store, caseID, holdings.Options{
+		Operator:     operator,
+		Source:       "forensicexport.GenerateForensicZip",
+		EvidenceRoot: evidenceRoot,
+	})
+	// 检材编号：导出前为尚未编号的证据补编（已有编号不变）。
+	if _, err := store.AssignExhibitNumbers(ctx, caseID); err != nil {
+		return nil, err
+	}
 	artifacts, err := store.ListArtifactsByCase(ctx, caseID)
 	if err != nil {
 		return nil, err
@@ -162,10 +213,17 @@ func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts Z
 	if err != nil {
 		return nil, err
 	}
+	if err := tr.Step("load", 1, 2); err != nil {
+		return nil, err
+	}
 	prechecks, err := store.ListPrecheckResults(ctx, caseID)
 	if err != nil {
 		return nil, err
 	}
+	scanRuns, err := store.ListScanRuns(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
 	audits, err := store.ListAuditLogs(ctx, caseID, 5000)
 	if err != nil {
 		return nil, err
@@ -183,18 +241,23 @@ func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts Z
 	}
 
 	var warnings []string
+	if heldErr != nil {
+		warnings = append(warnings, "aggregate holdings failed: "+heldErr.Error())
+	} else if held != nil {
+		warnings = append(warnings, held.Warnings...)
+	}
+	warnings = append(warnings, compatWarnings(artifacts)...)
 	var includes []includeSpec
 
+	stamp, err := orgprofile.Issue(ctx, store)
+	if err != nil {
+		warnings = append(warnings, "issue report number failed: "+err.Error())
+	}
+
 	// evidence snapshots
 	evidenceBaseAbs := mustAbs(evidenceRoot)
 	manifestArtifacts := make([]ManifestArtifact, 0, len(artifacts))
 	for _, a := range artifacts {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
-
 		src := strings.TrimSpace(a.SnapshotPath)
 		if src == "" {
 			warnings = append(warnings, fmt.Sprintf("artifact %s snapshot_path empty", a.ArtifactID))
@@ -217,11 +280,26 @@ func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts Z
 		})
 	}
 
-	// reports (skip forensic_zip itself to avoid "zip in zip" recursion)
+	// device photos（设备保管照片，与证据快照同在 evidence/ 下）
+	for _, d := range devices {
+		for _, ph := range d.Photos {
+			rel := safeRel(evidenceBaseAbs, mustAbs(ph.FilePath))
+			if rel == "" {
+				rel = filepath.Join(d.DeviceID, "photos", filepath.Base(ph.FilePath))
+			}
+			includes = append(includes, includeSpec{
+				SrcPath: ph.FilePath,
+				ZipPath: filepath.ToSlash(filepath.Join("evidence", rel)),
+				Kind:    "device_photo",
+			})
+		}
+	}
+
+	// reports (skip forensic_zip/disclosure_zip to avoid "zip in zip" recursion)
 	reportsBaseAbs := mustAbs(filepath.Join(filepath.Dir(dbPath), "reports"))
 	manifestReports := make([]ManifestReport, 0, len(allReports))
 	for _, r := range allReports {
-		if strings.TrimSpace(r.ReportType) == "forensic_zip" {
+		if t := strings.TrimSpace(r.ReportType); t == "forensic_zip" || t == "disclosure_zip" {
 			continue
 		}
 		src := strings.TrimSpace(r.FilePath)
@@ -265,8 +343,8 @@ func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts Z
 	})
 
 	// --- 开始写 ZIP ---
-	zipName := fmt.Sprintf("%s_forensic_export_%d.zip", caseID, time.Now().Unix())
-	zipPath := filepath.Join(exportDir, zipName)
+	zipName := stamp.FileName(fmt.Sprintf("%s_forensic_export_%d.zip", caseID, time.Now().Unix()))
+	zipPath = filepath.Join(exportDir, zipName)
 	f, err := os.Create(zipPath)
 	if err != nil {
 		return nil, fmt.Errorf("create zip: %w", err)
@@ -275,25 +353,25 @@ func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts Z
 
 	zw := zip.NewWriter(f)
 	defer func() { _ = zw.Close() }()
+	if wm := stamp.Watermark(); wm != "" {
+		_ = zw.SetComment(wm + " - synthetic training data, not evidence")
+	}
 
 	var fileHashes []FileHashEntry
 
-	addDiskFile := func(srcPath, zipPath, kind string) {
+	// addDiskFile 只在取消时返回错误；其他读取失败按 best-effort 记 warning。
+	addDiskFile := func(srcPath, zipPath, kind string) error {
 		if strings.TrimSpace(srcPath) == "" || strings.TrimSpace(zipPath) == "" {
-			return
-		}
-		select {
-		case <-ctx.Done():
-			warnings = append(warnings, "context cancelled")
-			return
-		default:
+			return nil
 		}
-
-		sum, size, err := writeZipFileFromDisk(zw, srcPath, zipPath)
+		sum, size, err := writeZipFileFromDisk(ctx, zw, srcPath, zipPath, password)
 		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			// 内测阶段走 best-effort：缺失文件不阻断导出，但必须在 manifest 里留下痕迹。
 			warnings = append(warnings, fmt.Sprintf("skip file %s -> %s: %v", srcPath, zipPath, err))
-			return
+			return nil
 		}
 		fileHashes = append(fileHashes, FileHashEntry{
 			Path:      zipPath,
@@ -301,35 +379,63 @@ func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts Z
 			SizeBytes: size,
 			Kind:      kind,
 		})
+		return nil
 	}
 
-	for _, it := range includes {
-		addDiskFile(it.SrcPath, it.ZipPath, it.Kind)
+	for i, it := range includes {
+		if err := tr.Step("package", i, len(includes)); err != nil {
+			return nil, err
+		}
+		if err := addDiskFile(it.SrcPath, it.ZipPath, it.Kind); err != nil {
+			return nil, err
+		}
+	}
+	if err := tr.Step("manifest", 0, 0); err != nil {
+		return nil, err
+	}
+
+	// exhibits.csv（检材清单）
+	exhibitRaw, err := exhibitCSV(manifestArtifacts)
+	if err != nil {
+		return nil, err
+	}
+	exhibitSum, exhibitSize, err := writeZipFileFromBytes(zw, exhibitCSVPath, exhibitRaw, password)
+	if err != nil {
+		return nil, fmt.Errorf("write exhibits.csv to zip: %w", err)
 	}
+	fileHashes = append(fileHashes, FileHashEntry{Path: exhibitCSVPath, SHA256: exhibitSum, SizeBytes: exhibitSize, Kind: "exhibit_list"})
 
 	// manifest.json（先写入，再把它的 hash 也记录进 hashes.sha256）
+	rollup := hitrollup.Build(hits)
 	manifest := ZipManifest{
-		Schema:      manifestSchemaV1,
-		GeneratedAt: time.Now().Unix(),
-		Case:        overview,
-		Devices:     devices,
-		Artifacts:   manifestArtifacts,
-		Hits:        hits,
-		Prechecks:   prechecks,
-		Audits:      audits,
-		Reports:     manifestReports,
-		Warnings:    warnings,
-		Note:        strings.TrimSpace(opts.Note),
+		Schema:           manifestSchemaV1,
+		GeneratedAt:      time.Now().Unix(),
+		Case:             overview,
+		Devices:          devices,
+		Artifacts:        manifestArtifacts,
+		Hits:             hits,
+		HitRollup:        rollup,
+		WalletCategories: hitrollup.ByWalletCategory(hits),
+		Holdings:         held,
+		Prechecks:        prechecks,
+		ScanRuns:         scanRuns,
+		Audits:           audits,
+		Reports:          manifestReports,
+		Warnings:         warnings,
+		Note:             strings.TrimSpace(opts.Note),
 		Extra: map[string]any{
 			"evidence_root": evidenceRoot,
+			"organization":  stamp.Fields(),
 		},
 		Stats: map[string]any{
-			"device_count":   len(devices),
-			"artifact_count": len(artifacts),
-			"hit_count":      len(hits),
-			"precheck_count": len(prechecks),
-			"audit_count":    len(audits),
-			"report_count":   len(allReports),
+			"device_count":     len(devices),
+			"artifact_count":   len(artifacts),
+			"hit_count":        len(hits),
+			"hit_rollup_count": len(rollup),
+			"precheck_count":   len(prechecks),
+			"scan_run_count":   len(scanRuns),
+			"audit_count":      len(audits),
+			"report_count":     len(allReports),
 		},
 	}
 	manifest.App.Version = app.Version
@@ -345,7 +451,7 @@ func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts Z
 		return nil, fmt.Errorf("marshal manifest: %w", err)
 	}
 	manifestZipPath := "manifest.json"
-	manifestSum, manifestSize, err := writeZipFileFromBytes(zw, manifestZipPath, manifestRaw)
+	manifestSum, manifestSize, err := writeZipFileFromBytes(zw, manifestZipPath, manifestRaw, password)
 	if err != nil {
 		return nil, fmt.Errorf("write manifest to zip: %w", err)
 	}
@@ -358,16 +464,13 @@ func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts Z
 
 	// hashes.sha256（sha256sum 兼容格式，默认不包含自身）
 	sort.Slice(fileHashes, func(i, j int) bool { return fileHashes[i].Path < fileHashes[j].Path })
-	hashLines := make([]string, 0, len(fileHashes)+4)
-	hashLines = append(hashLines, "# crypto-inspector forensic export hash list")
-	hashLines = append(hashLines, fmt.Sprintf("# generated_at=%d", time.Now().Unix()))
-	hashLines = append(hashLines, "# format: <sha256><two spaces><path>")
-	for _, fh := range fileHashes {
-		hashLines = append(hashLines, fmt.Sprintf("%s  %s", fh.SHA256, fh.Path))
-	}
-	hashLines = append(hashLines, "")
+	hashLines := hashListLines([]string{
+		"# crypto-inspector forensic export hash list",
+		fmt.Sprintf("# generated_at=%d", time.Now().Unix()),
+		"# format: <sha256><two spaces><path>",
+	}, fileHashes, manifestArtifacts)
 	hashRaw := []byte(strings.Join(hashLines, "\n"))
-	if _, _, err := writeZipFileFromBytes(zw, "hashes.sha256", hashRaw); err != nil {
+	if _, _, err := writeZipFileFromBytes(zw, "hashes.sha256", hashRaw, password); err != nil {
 		return nil, fmt.Errorf("write hashes.sha256 to zip: %w", err)
 	}
 
@@ -383,29 +486,57 @@ func GenerateForensicZip(ctx context.Context, store *sqliteadapter.Store, opts Z
 	if err != nil {
 		return nil, fmt.Errorf("hash zip: %w", err)
 	}
+	// 登记入库前最后一次检查取消：已取消的导出包不登记（由 defer 删除文件）。
+	if err := tr.Step("manifest", 1, 1); err != nil {
+		return nil, err
+	}
 
 	// 入库登记（reports 表）+ 审计留痕（audit_logs）
 	reportID, err := store.SaveReport(ctx, caseID, "forensic_zip", zipPath, zipSum, zipGeneratorVer, "ready")
 	if err != nil {
 		return nil, err
 	}
+	if err := stamp.Record(ctx, store, reportID); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	if encryption != "" {
+		if err := store.SetReportEncryption(ctx, reportID, encryption); err != nil {
+			warnings = append(warnings, err.Error())
+		}
+	}
 	_ = store.AppendAudit(ctx, caseID, "", "export", "forensic_zip", "success", operator, "forensicexport.GenerateForensicZip", map[string]any{
 		"zip_path":   zipPath,
 		"zip_sha256": zipSum,
+		"report_no":  stamp.ReportNo,
+		"encryption": encryption,
 		"warnings":   warnings,
 	})
+	tr.Done()
 
 	return &ZipResult{
 		CaseID:     caseID,
 		ReportID:   reportID,
+		ReportNo:   stamp.ReportNo,
 		ZipPath:    zipPath,
 		ZipSHA256:  zipSum,
+		Encryption: encryption,
 		Warnings:   warnings,
 		StartedAt:  startedAt,
 		FinishedAt: time.Now().Unix(),
 	}, nil
 }
 
+// compatWarnings 汇总由更新版本解析器生成的证据（当前程序可能无法完整解读）。
+func compatWarnings(artifacts []model.ArtifactInfo) []string {
+	var out []string
+	for _, a := range artifacts {
+		if a.CompatWarning != "" {
+			out = append(out, fmt.Sprintf("artifact %s: %s", a.ArtifactID, a.CompatWarning))
+		}
+	}
+	return out
+}
+
 func mustAbs(p string) string {
 	abs, err := filepath.Abs(p)
 	if err != nil {
@@ -430,7 +561,61 @@ func safeRel(baseAbs, targetAbs string) string {
 	return rel
 }
 
-func writeZipFileFromDisk(zw *zip.Writer, srcPath, zipPath string) (sum string, size int64, err error) {
+// cleanupCanceled 在导出因 ctx 取消而失败时删除未完成的文件（path 为空表示尚未创建），并写入 failed 审计。
+// 其他错误不处理（与取消无关的失败由调用方返回给上层）。
+func cleanupCanceled(ctx context.Context, store *sqliteadapter.Store, retErr error, caseID, action, operator, source, path string) {
+	if retErr == nil || ctx.Err() == nil {
+		return
+	}
+	if path != "" {
+		_ = os.Remove(path)
+	}
+	_ = store.AppendAudit(context.WithoutCancel(ctx), caseID, "", "export", action, "failed", operator, source, map[string]any{
+		"canceled": true,
+		"error":    retErr.Error(),
+	})
+}
+
+// ctxReader 在每次 Read 前检查 ctx，使大文件复制途中也能响应取消。
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// exportPassword 校验导出包密码，返回密码字节与 reports.encryption 取值（未加密时均为空）。
+func exportPassword(p string) ([]byte, string, error) {
+	if p == "" {
+		return nil, "", nil
+	}
+	if utf8.RuneCountInString(p) < zipaes.MinPasswordLen {
+		return nil, "", apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("export password must be at least %d characters", zipaes.MinPasswordLen))
+	}
+	return []byte(p), model.ReportEncryptionZipAES256, nil
+}
+
+// createZipEntry 创建 ZIP 条目：password 为空时按 deflate 写入，否则写成 AES-256 加密条目。
+// 写完后必须调用返回的 done（加密条目据此写入 HMAC 并回填大小）。
+func createZipEntry(zw *zip.Writer, hdr *zip.FileHeader, password []byte) (w io.Writer, done func() error, err error) {
+	if len(password) == 0 {
+		hdr.Method = zip.Deflate
+		w, err = zw.CreateHeader(hdr)
+		return w, func() error { return nil }, err
+	}
+	aw, err := zipaes.Create(zw, hdr, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aw, aw.Close, nil
+}
+
+func writeZipFileFromDisk(ctx context.Context, zw *zip.Writer, srcPath, zipPath string, password []byte) (sum string, size int64, err error) {
 	fi, err := os.Stat(srcPath)
 	if err != nil {
 		return "", 0, err
@@ -444,34 +629,35 @@ func writeZipFileFromDisk(zw *zip.Writer, srcPath, zipPath string) (sum string,
 		return "", 0, err
 	}
 	hdr.Name = zipPath
-	hdr.Method = zip.Deflate
 
-	w, err := zw.CreateHeader(hdr)
+	// 先打开源文件再创建条目，避免源文件缺失时在 ZIP 中留下空条目。
+	f, err := os.Open(srcPath)
 	if err != nil {
 		return "", 0, err
 	}
+	defer f.Close()
 
-	f, err := os.Open(srcPath)
+	w, done, err := createZipEntry(zw, hdr, password)
 	if err != nil {
 		return "", 0, err
 	}
-	defer f.Close()
-
 	hasher := sha256.New()
-	n, err := io.Copy(io.MultiWriter(w, hasher), f)
+	n, err := io.Copy(io.MultiWriter(w, hasher), ctxReader{ctx: ctx, r: f})
 	if err != nil {
 		return "", 0, err
 	}
+	if err := done(); err != nil {
+		return "", 0, err
+	}
 	return hex.EncodeToString(hasher.Sum(nil)), n, nil
 }
 
-func writeZipFileFromBytes(zw *zip.Writer, zipPath string, b []byte) (sum string, size int64, err error) {
+func writeZipFileFromBytes(zw *zip.Writer, zipPath string, b []byte, password []byte) (sum string, size int64, err error) {
 	hdr := &zip.FileHeader{
 		Name:     zipPath,
-		Method:   zip.Deflate,
 		Modified: time.Now(),
 	}
-	w, err := zw.CreateHeader(hdr)
+	w, done, err := createZipEntry(zw, hdr, password)
 	if err != nil {
 		return "", 0, err
 	}
@@ -480,5 +666,8 @@ func writeZipFileFromBytes(zw *zip.Writer, zipPath string, b []byte) (sum string
 	if err != nil {
 		return "", 0, err
 	}
+	if err := done(); err != nil {
+		return "", 0, err
+	}
 	return hex.EncodeToString(hasher.Sum(nil)), n, nil
 }
@@ -0,0 +1,484 @@
+package forensicimport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/adapters/rules"
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/filetype"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/id"
+	"crypto-inspector/internal/services/matcher"
+)
+
+// 第三方取证工具证据导入
+//
+// 很多现场的移动端提取由 Cellebrite UFED / Magnet AXIOM 完成，电脑镜像由 Plaso/Autopsy 处理，本工具无法直接连接设备。
+// 导入流程把这些工具的导出报告转换为本工具的证据：
+// - 原始报告文件原样复制到证据目录，sha256 作为导入证据的快照哈希
+// - 已安装应用 → mobile_packages（移动端）或 installed_apps（电脑）
+// - 浏览记录 → browser_history
+// - 时间线（Plaso/Autopsy）中被识别的事件 → timeline
+// - 设备登记为 connection_type=import，之后与扫描得到的证据一样参与钱包/交易所规则匹配与报告导出
+
+// ParserVersion 是导入解析器版本（写入证据 parser_version）。
+const ParserVersion = "forensicimport-0.1.0"
+
+// AcquisitionMethod 是导入证据的获取方式。
+const AcquisitionMethod = "third_party_import"
+
+var reUnsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Input 是一次导入的参数。
+type Input struct {
+	CaseID     string
+	SourcePath string // 第三方工具导出的报告文件
+	FileName   string // 原始文件名（为空时取 SourcePath 的文件名）
+	Format     string // ufed_xml|axiom_xml|csv|plaso_csv|autopsy_csv，为空时自动识别
+	OS         string // 报告中没有系统信息时必填：android|ios|harmonyos|windows|macos
+	// DeviceID 非空时挂到案件已有设备；否则按报告中的设备标识复用或新建导入设备。
+	DeviceID   string
+	DeviceName string
+	Operator   string
+	Note       string
+
+	EvidenceRoot     string
+	WalletRulePath   string
+	ExchangeRulePath string
+	RegexRulePath    string // 可选的自定义正则规则文件（不存在时不启用）
+}
+
+// Result 是一次导入的结果。
+type Result struct {
+	CaseID       string   `json:"case_id"`
+	DeviceID     string   `json:"device_id"`
+	Format       string   `json:"format"`
+	Tool         string   `json:"tool"`
+	OS           string   `json:"os"`
+	SourceSHA256 string   `json:"source_sha256"`
+	SnapshotPath string   `json:"snapshot_path"`
+	ArtifactIDs  []string `json:"artifact_ids"`
+	AppCount     int      `json:"app_count"`
+	VisitCount   int      `json:"visit_count"`
+	EventCount   int      `json:"event_count,omitempty"` // 时间线导入：读取的事件总数
+	Skipped      int      `json:"skipped"`
+	HitIDs       []string `json:"hit_ids"`
+	Warnings     []string `json:"warnings"`
+}
+
+// Run 解析报告、登记设备与证据，并执行规则匹配写入命中。
+func Run(ctx context.Context, store *sqliteadapter.Store, in Input) (*Result, error) {
+	in.CaseID = strings.TrimSpace(in.CaseID)
+	in.SourcePath = strings.TrimSpace(in.SourcePath)
+	in.Operator = strings.TrimSpace(in.Operator)
+	if in.Operator == "" {
+		in.Operator = "system"
+	}
+	if in.CaseID == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "case_id is required")
+	}
+	if in.SourcePath == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "source file is required")
+	}
+	if strings.TrimSpace(in.FileName) == "" {
+		in.FileName = filepath.Base(in.SourcePath)
+	}
+	defaults := app.DefaultConfig()
+	if in.WalletRulePath == "" {
+		in.WalletRulePath = defaults.WalletRulePath
+	}
+	if in.ExchangeRulePath == "" {
+		in.ExchangeRulePath = defaults.ExchangeRulePath
+	}
+	if in.RegexRulePath == "" {
+		in.RegexRulePath = defaults.RegexRulePath
+	}
+	if in.EvidenceRoot == "" {
+		in.EvidenceRoot = "data/evidence"
+	}
+
+	ov, err := store.GetCaseOverview(ctx, in.CaseID)
+	if err != nil {
+		return nil, err
+	}
+	if ov == nil {
+		return nil, apperr.T(apperr.CodeNotFound, "case.not_found", in.CaseID)
+	}
+
+	parsed, sum, err := parseSource(in)
+	if err != nil {
+		_ = store.AppendAudit(ctx, in.CaseID, "", "third_party_import", "parse", "failed", in.Operator, "forensicimport.Run", map[string]any{
+			"file_name": in.FileName,
+			"error":     err.Error(),
+		})
+		return nil, err
+	}
+	osType := parsed.OS
+	if strings.TrimSpace(in.OS) != "" {
+		osType = ParseOS(in.OS)
+		if osType == "" {
+			return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unknown os: %s", in.OS))
+		}
+	}
+	if osType == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "device os not found in report; specify os (android|ios|harmonyos|windows|macos)")
+	}
+	if len(parsed.Apps) == 0 && len(parsed.Visits) == 0 {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("no installed apps or browsing history found in %s report", parsed.Format))
+	}
+
+	dev, err := resolveDevice(ctx, store, in, parsed, osType, sum)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{
+		CaseID:       in.CaseID,
+		DeviceID:     dev.ID,
+		Format:       parsed.Format,
+		Tool:         parsed.Tool,
+		OS:           string(osType),
+		SourceSHA256: sum,
+		AppCount:     len(parsed.Apps),
+		VisitCount:   len(parsed.Visits),
+		EventCount:   parsed.Events,
+		Skipped:      parsed.Skipped,
+		ArtifactIDs:  []string{},
+		HitIDs:       []string{},
+		Warnings:     []string{},
+	}
+
+	arts, snapshotPath, err := buildArtifacts(in, parsed, dev, osType)
+	if err != nil {
+		return nil, err
+	}
+	res.SnapshotPath = snapshotPath
+	if arts[0].SHA256 != sum {
+		// 复制过程中源文件被改动：拒绝导入，避免证据哈希与解析内容不一致。
+		_ = os.Remove(snapshotPath)
+		return nil, fmt.Errorf("source file changed during import: sha256 %s != %s", arts[0].SHA256, sum)
+	}
+	if err := store.SaveArtifacts(ctx, arts); err != nil {
+		_ = store.AppendAudit(ctx, in.CaseID, dev.ID, "third_party_import", "save_artifacts", "failed", in.Operator, "forensicimport.Run", map[string]any{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+	for _, a := range arts {
+		res.ArtifactIDs = append(res.ArtifactIDs, a.ID)
+	}
+
+	hits, warnings, err := matchArtifacts(ctx, store, in, arts, osType)
+	if err != nil {
+		_ = store.AppendAudit(ctx, in.CaseID, dev.ID, "third_party_import", "match_rules", "failed", in.Operator, "forensicimport.Run", map[string]any{
+			"error":      err.Error(),
+			"error_code": apperr.CodeOf(err),
+		})
+		return nil, err
+	}
+	res.Warnings = append(res.Warnings, warnings...)
+	if err := store.SaveRuleHits(ctx, hits); err != nil {
+		_ = store.AppendAudit(ctx, in.CaseID, dev.ID, "third_party_import", "save_hits", "failed", in.Operator, "forensicimport.Run", map[string]any{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+	for _, h := range hits {
+		res.HitIDs = append(res.HitIDs, h.ID)
+	}
+
+	_ = store.AppendAudit(ctx, in.CaseID, dev.ID, "third_party_import", "import", "success", in.Operator, "forensicimport.Run", map[string]any{
+		"tool":          parsed.Tool,
+		"format":        parsed.Format,
+		"file_name":     in.FileName,
+		"source_sha256": sum,
+		"artifact_ids":  res.ArtifactIDs,
+		"app_count":     res.AppCount,
+		"visit_count":   res.VisitCount,
+		"event_count":   res.EventCount,
+		"skipped":       res.Skipped,
+		"hit_count":     len(hits),
+		"note":          strings.TrimSpace(in.Note),
+	})
+	return res, nil
+}
+
+// parseSource 计算源文件哈希并解析（格式为空时按文件头识别）。
+func parseSource(in Input) (*Parsed, string, error) {
+	sum, _, err := hash.File(in.SourcePath)
+	if err != nil {
+		return nil, "", apperr.Wrap(apperr.CodeInvalidArgument, err, "read source file")
+	}
+	f, err := os.Open(in.SourcePath)
+	if err != nil {
+		return nil, "", apperr.Wrap(apperr.CodeInvalidArgument, err, "open source file")
+	}
+	defer f.Close()
+
+	format := strings.ToLower(strings.TrimSpace(in.Format))
+	if format == "" {
+		head := make([]byte, 4096)
+		n, _ := io.ReadFull(f, head)
+		if format, err = DetectFormat(in.FileName, head[:n]); err != nil {
+			return nil, "", err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, "", fmt.Errorf("rewind source file: %w", err)
+		}
+	}
+	parsed, err := Parse(format, f)
+	if err != nil {
+		return nil, "", err
+	}
+	return parsed, sum, nil
+}
+
+// resolveDevice 决定导入证据挂到哪台设备：
+// - 指定 DeviceID：必须是案件已有设备
+// - 报告中的设备标识（IMEI/序列号）与案件已有设备一致：复用（同一部手机分多次导入）
+// - 否则登记新的导入设备（无标识时用报告 sha256 生成稳定标识）
+func resolveDevice(ctx context.Context, store *sqliteadapter.Store, in Input, parsed *Parsed, osType model.OSType, sum string) (model.Device, error) {
+	devices, err := store.ListCaseDevices(ctx, in.CaseID)
+	if err != nil {
+		return model.Device{}, err
+	}
+	if deviceID := strings.TrimSpace(in.DeviceID); deviceID != "" {
+		for _, d := range devices {
+			if d.DeviceID == deviceID {
+				return model.Device{ID: d.DeviceID, Name: d.DeviceName, OS: model.OSType(d.OSType), Identifier: d.Identifier}, nil
+			}
+		}
+		return model.Device{}, apperr.New(apperr.CodeNotFound, fmt.Sprintf("device not found in case: %s", deviceID))
+	}
+
+	identifier := strings.TrimSpace(parsed.Identifier)
+	if identifier != "" {
+		for _, d := range devices {
+			if d.Identifier == identifier && model.OSType(d.OSType) == osType {
+				return model.Device{ID: d.DeviceID, Name: d.DeviceName, OS: osType, Identifier: d.Identifier}, nil
+			}
+		}
+	} else {
+		identifier = "import:" + sum[:16]
+	}
+	name := strings.TrimSpace(in.DeviceName)
+	if name == "" {
+		name = parsed.DeviceName
+	}
+	if name == "" {
+		name = fmt.Sprintf("%s import (%s)", parsed.Tool, osType)
+	}
+	dev := model.Device{ID: id.New("dev"), Name: name, OS: osType, Identifier: identifier}
+	note := fmt.Sprintf("imported from %s report %s (sha256 %s)", parsed.Tool, in.FileName, sum)
+	if err := store.UpsertDeviceWithConnection(ctx, in.CaseID, dev, "import", true, note); err != nil {
+		return model.Device{}, err
+	}
+	return dev, nil
+}
+
+// buildArtifacts 复制原始报告到证据目录，并按内容生成证据（应用清单 + 浏览记录）。
+func buildArtifacts(in Input, parsed *Parsed, dev model.Device, osType model.OSType) ([]model.Artifact, string, error) {
+	dir := filepath.Join(in.EvidenceRoot, in.CaseID, dev.ID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, "", fmt.Errorf("create evidence dir: %w", err)
+	}
+	name := reUnsafeName.ReplaceAllString(filepath.Base(strings.TrimSpace(in.FileName)), "_")
+	if name == "" || name == "." || name == "_" {
+		name = "report"
+	}
+	now := time.Now().Unix()
+	snapshotPath := filepath.Join(dir, fmt.Sprintf("import_%s_%s_%s", parsed.Tool, id.New("imp"), name))
+	if err := copyFile(in.SourcePath, snapshotPath); err != nil {
+		return nil, "", err
+	}
+	sum, size, err := hash.File(snapshotPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("hash evidence file: %w", err)
+	}
+	mime := filetype.DetectFile(snapshotPath)
+
+	collectorName := parsed.Tool + "_import"
+	collectorVer := "forensicimport-" + strings.TrimSpace(app.Version)
+	if strings.TrimSpace(app.Version) == "" {
+		collectorVer = "forensicimport-dev"
+	}
+	newArtifact := func(typ model.ArtifactType, payload any) (model.Artifact, error) {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			return model.Artifact{}, fmt.Errorf("marshal %s payload: %w", typ, err)
+		}
+		artifactID := id.New("art")
+		sourceRef := parsed.Tool + ":" + in.FileName
+		return model.Artifact{
+			ID:                artifactID,
+			CaseID:            in.CaseID,
+			DeviceID:          dev.ID,
+			Type:              typ,
+			SourceRef:         sourceRef,
+			SnapshotPath:      snapshotPath,
+			SHA256:            sum,
+			SizeBytes:         size,
+			MimeType:          mime,
+			CollectedAt:       now,
+			CollectorName:     collectorName,
+			CollectorVersion:  collectorVer,
+			ParserVersion:     ParserVersion,
+			AcquisitionMethod: AcquisitionMethod,
+			PayloadJSON:       raw,
+			RecordHash: hash.Text(
+				artifactID,
+				in.CaseID,
+				dev.ID,
+				string(typ),
+				sourceRef,
+				snapshotPath,
+				sum,
+				fmt.Sprintf("%d", size),
+				fmt.Sprintf("%d", now),
+				collectorName,
+				collectorVer,
+				string(raw),
+			),
+		}, nil
+	}
+
+	var arts []model.Artifact
+	if len(parsed.Apps) > 0 {
+		var art model.Artifact
+		if osType == model.OSAndroid || osType == model.OSIOS || osType == model.OSHarmonyOS {
+			rows := make([]model.MobilePackageRecord, 0, len(parsed.Apps))
+			for _, a := range parsed.Apps {
+				if a.Identifier == "" {
+					continue
+				}
+				rows = append(rows, model.MobilePackageRecord{
+					OS:         osType,
+					DeviceID:   dev.ID,
+					Identifier: dev.Identifier,
+					Package:    a.Identifier,
+					Raw:        strings.TrimSpace(a.Name + " " + a.Version),
+				})
+			}
+			art, err = newArtifact(model.ArtifactMobilePackages, rows)
+		} else {
+			rows := make([]model.AppRecord, 0, len(parsed.Apps))
+			for _, a := range parsed.Apps {
+				r := model.AppRecord{Name: a.Name, Version: a.Version}
+				if a.Name == "" {
+					r.Name = a.Identifier
+				}
+				if osType == model.OSMacOS {
+					r.BundleID = a.Identifier
+				}
+				if a.InstalledAt > 0 {
+					r.InstallDate = time.Unix(a.InstalledAt, 0).UTC().Format("20060102")
+				}
+				rows = append(rows, r)
+			}
+			art, err = newArtifact(model.ArtifactInstalledApps, rows)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		arts = append(arts, art)
+	}
+	if len(parsed.Visits) > 0 {
+		art, err := newArtifact(model.ArtifactBrowserHistory, parsed.Visits)
+		if err != nil {
+			return nil, "", err
+		}
+		arts = append(arts, art)
+	}
+	if len(parsed.Timeline) > 0 {
+		// 归一化前的事件原文单独成证据，便于报告/复核追溯到时间线中的原始条目。
+		art, err := newArtifact(model.ArtifactTimeline, parsed.Timeline)
+		if err != nil {
+			return nil, "", err
+		}
+		arts = append(arts, art)
+	}
+	return arts, snapshotPath, nil
+}
+
+// matchArtifacts 对导入证据执行与扫描相同的规则匹配，并回填规则包 ID。
+func matchArtifacts(ctx context.Context, store *sqliteadapter.Store, in Input, arts []model.Artifact, osType model.OSType) ([]model.RuleHit, []string, error) {
+	loaded, err := rules.NewLoader(in.WalletRulePath, in.ExchangeRulePath).WithRegexFile(in.RegexRulePath).Load(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var warnings []string
+	walletBundleID, exchangeBundleID := "", ""
+	if id, err := store.EnsureRuleBundle(ctx, "wallet_signatures", loaded.Wallet.Version, loaded.WalletSHA256, in.WalletRulePath); err == nil {
+		walletBundleID = id
+	} else {
+		warnings = append(warnings, "rule bundle wallet: "+err.Error())
+	}
+	if id, err := store.EnsureRuleBundle(ctx, "exchange_domains", loaded.Exchange.Version, loaded.ExchangeSHA256, in.ExchangeRulePath); err == nil {
+		exchangeBundleID = id
+	} else {
+		warnings = append(warnings, "rule bundle exchange: "+err.Error())
+	}
+	regexBundleID := ""
+	if loaded.RegexSHA256 != "" {
+		if id, err := store.EnsureRuleBundle(ctx, "regex_rules", loaded.Regex.Version, loaded.RegexSHA256, in.RegexRulePath); err == nil {
+			regexBundleID = id
+		} else {
+			warnings = append(warnings, "rule bundle regex: "+err.Error())
+		}
+	}
+
+	var mr *matcher.HostMatchResult
+	if osType == model.OSAndroid || osType == model.OSIOS || osType == model.OSHarmonyOS {
+		mr, err = matcher.MatchMobileArtifacts(loaded, arts)
+	} else {
+		mr, err = matcher.MatchHostArtifacts(loaded, arts)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range mr.Hits {
+		switch mr.Hits[i].Type {
+		case model.HitWalletInstalled, model.HitWalletExecuted:
+			mr.Hits[i].RuleBundleID = walletBundleID
+		case model.HitExchangeVisited, model.HitExchangeFormActivity, model.HitExchangeAppInstalled, model.HitMessengerCommunity:
+			mr.Hits[i].RuleBundleID = exchangeBundleID
+		}
+		if matcher.IsRegexRuleHit(mr.Hits[i]) {
+			mr.Hits[i].RuleBundleID = regexBundleID
+		}
+	}
+	if terms, err := store.ListWatchlistTerms(ctx, in.CaseID); err == nil {
+		mr.Hits = append(mr.Hits, matcher.MatchWatchlist(terms, arts)...)
+	} else {
+		warnings = append(warnings, "watchlist: "+err.Error())
+	}
+	return mr.Hits, warnings, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open source file: %w", err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("create evidence file: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("copy evidence file: %w", err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,200 @@
+package forensicimport
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+const ufedReport = `<?xml version="1.0" encoding="utf-8"?>
+<project id="p1" name="Case 7" reportVersion="7.60" xmlns="http://pa.cellebrite.com/report/2.0">
+  <sourceExtractions>
+    <extractionInfo id="0" name="Logical" deviceName="Samsung SM-G950F" />
+  </sourceExtractions>
+  <metadata section="Device Info">
+    <item id="1" name="DeviceInfoOSType"><![CDATA[Android]]></item>
+    <item id="2" name="DeviceInfoIMEI"><![CDATA[356789012345678]]></item>
+  </metadata>
+  <decodedData>
+    <modelType type="InstalledApplication">
+      <model type="InstalledApplication" id="a1">
+        <field name="Identifier" type="String"><value type="String"><![CDATA[io.metamask]]></value></field>
+        <field name="Name" type="String"><value type="String"><![CDATA[MetaMask]]></value></field>
+        <field name="Version" type="String"><value type="String"><![CDATA[7.1.0]]></value></field>
+      </model>
+      <model type="InstalledApplication" id="a2">
+        <field name="Identifier" type="String"><value type="String"><![CDATA[com.android.chrome]]></value></field>
+        <field name="Name" type="String"><value type="String"><![CDATA[Chrome]]></value></field>
+      </model>
+    </modelType>
+    <modelType type="VisitedPage">
+      <model type="VisitedPage" id="v1">
+        <field name="Url" type="String"><value type="String"><![CDATA[https://www.binance.com/en/my/wallet]]></value></field>
+        <field name="Title" type="String"><value type="String"><![CDATA[Binance]]></value></field>
+        <field name="LastVisited" type="TimeStamp"><value type="TimeStamp">2024-03-01T08:30:00.000+00:00</value></field>
+        <field name="Source" type="String"><value type="String"><![CDATA[Chrome]]></value></field>
+      </model>
+    </modelType>
+    <modelType type="Call">
+      <model type="Call" id="c1">
+        <field name="Direction" type="String"><value type="String">Incoming</value></field>
+      </model>
+    </modelType>
+  </decodedData>
+</project>`
+
+func TestParseUFEDXML(t *testing.T) {
+	format, err := DetectFormat("report.xml", []byte(ufedReport[:200]))
+	if err != nil || format != FormatUFEDXML {
+		t.Fatalf("DetectFormat=%s err=%v", format, err)
+	}
+	p, err := Parse(format, strings.NewReader(ufedReport))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if p.OS != model.OSAndroid || p.Identifier != "356789012345678" || p.DeviceName != "Samsung SM-G950F" {
+		t.Fatalf("device=%s %s %s", p.OS, p.Identifier, p.DeviceName)
+	}
+	if len(p.Apps) != 2 || p.Apps[0].Identifier != "io.metamask" || p.Apps[0].Version != "7.1.0" {
+		t.Fatalf("apps=%+v", p.Apps)
+	}
+	if len(p.Visits) != 1 || p.Visits[0].Domain != "binance.com" || p.Visits[0].Browser != "chrome" || p.Visits[0].VisitedAt != 1709281800 {
+		t.Fatalf("visits=%+v", p.Visits)
+	}
+	if p.Skipped != 1 {
+		t.Fatalf("skipped=%d", p.Skipped)
+	}
+}
+
+func TestParseAXIOMCSV(t *testing.T) {
+	csvData := "\xef\xbb\xbfURL,Title,Last Visited Date/Time - UTC+00:00 (M/d/yyyy),Browser Source\n" +
+		"https://okx.com/trade,OKX,3/1/2024 8:30:00 AM,Safari\n" +
+		"not a url at all,,,\n"
+	p, err := Parse(FormatCSV, strings.NewReader(csvData))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(p.Visits) != 1 || p.Visits[0].Domain != "okx.com" || p.Visits[0].VisitedAt != 1709281800 || p.Visits[0].Browser != "safari" {
+		t.Fatalf("visits=%+v", p.Visits)
+	}
+
+	apps, err := Parse(FormatCSV, strings.NewReader("Application Name;Bundle ID;Version\nMetaMask;io.metamask;7.1\n"))
+	if err != nil || len(apps.Apps) != 1 || apps.Apps[0].Identifier != "io.metamask" {
+		t.Fatalf("apps=%+v err=%v", apps, err)
+	}
+}
+
+func TestRunImportsAndMatches(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatalf("EnsureCase: %v", err)
+	}
+
+	src := filepath.Join(dir, "report.xml")
+	if err := os.WriteFile(src, []byte(ufedReport), 0o644); err != nil {
+		t.Fatalf("write report: %v", err)
+	}
+	in := Input{
+		CaseID:           caseID,
+		SourcePath:       src,
+		Operator:         "alice",
+		EvidenceRoot:     filepath.Join(dir, "evidence"),
+		WalletRulePath:   filepath.Join("..", "..", "..", "rules", "wallet_signatures.template.yaml"),
+		ExchangeRulePath: filepath.Join("..", "..", "..", "rules", "exchange_domains.template.yaml"),
+	}
+	res, err := Run(ctx, store, in)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if res.Tool != ToolUFED || res.OS != "android" || len(res.ArtifactIDs) != 2 || len(res.HitIDs) == 0 {
+		t.Fatalf("res=%+v", res)
+	}
+
+	devices, err := store.ListCaseDevices(ctx, caseID)
+	if err != nil || len(devices) != 1 || devices[0].ConnectionType != "import" || devices[0].Identifier != "356789012345678" {
+		t.Fatalf("devices=%+v err=%v", devices, err)
+	}
+	info, err := store.GetArtifactInfo(ctx, res.ArtifactIDs[0])
+	if err != nil || info == nil || info.ArtifactType != string(model.ArtifactMobilePackages) || info.SHA256 != res.SourceSHA256 {
+		t.Fatalf("artifact=%+v err=%v", info, err)
+	}
+
+	wallets, _ := store.ListCaseHitDetails(ctx, caseID, string(model.HitWalletInstalled))
+	exchanges, _ := store.ListCaseHitDetails(ctx, caseID, string(model.HitExchangeVisited))
+	if len(wallets) != 1 || wallets[0].MatchedValue != "io.metamask" || len(exchanges) != 1 {
+		t.Fatalf("wallets=%+v exchanges=%+v", wallets, exchanges)
+	}
+
+	// 同一设备（IMEI 相同）的第二次导入复用设备记录。
+	again, err := Run(ctx, store, in)
+	if err != nil {
+		t.Fatalf("second Run: %v", err)
+	}
+	if again.DeviceID != res.DeviceID {
+		t.Fatalf("second import device=%s want %s", again.DeviceID, res.DeviceID)
+	}
+
+	csvPath := filepath.Join(dir, "history.csv")
+	if err := os.WriteFile(csvPath, []byte("URL,Title\nhttps://okx.com,OKX\n"), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	if _, err := Run(ctx, store, Input{CaseID: caseID, SourcePath: csvPath, EvidenceRoot: in.EvidenceRoot}); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("csv without os err=%v", err)
+	}
+}
+
+func TestParsePlasoAndAutopsy(t *testing.T) {
+	l2t := "date,time,timezone,MACB,source,sourcetype,type,user,host,short,desc,version,filename,inode,notes,format,extra\n" +
+		`03/01/2024,08:30:00,UTC,....,WEBHIST,Chrome History,Last Visited Time,-,WS-01,https://www.binance.com/en (Binance),https://www.binance.com/en (Binance) [count: 2] Type: [LINK],2,OS:/Users/a/History,-,-,sqlite/chrome_27_history,-` + "\n" +
+		`03/01/2024,08:00:00,UTC,M...,REG,Registry Key,Content Modification Time,-,WS-01,-,[HKLM\Software\Microsoft\Windows\CurrentVersion\Uninstall\Exodus] DisplayName: [REG_SZ] Exodus DisplayVersion: [REG_SZ] 24.1.1 Publisher: [REG_SZ] Exodus Movement,2,-,-,-,winreg/windows_uninstall,-` + "\n" +
+		`03/01/2024,08:10:00,UTC,M...,REG,Registry Key,Content Modification Time,-,WS-01,-,[HKLM\Software\Microsoft\Windows\CurrentVersion\Uninstall\Exodus] DisplayName: [REG_SZ] Exodus,2,-,-,-,winreg/windows_uninstall,-` + "\n" +
+		`03/01/2024,09:00:00,UTC,.A..,FILE,OS Last Access Time,Last Access Time,-,WS-01,-,C:/Windows/notepad.exe,2,-,-,-,filestat,-` + "\n"
+	format, err := DetectFormat("timeline.csv", []byte(l2t))
+	if err != nil || format != FormatPlasoCSV {
+		t.Fatalf("DetectFormat=%s err=%v", format, err)
+	}
+	p, err := Parse(format, strings.NewReader(l2t))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if p.OS != model.OSWindows || p.DeviceName != "WS-01" || p.Events != 4 || p.Skipped != 1 || len(p.Timeline) != 3 {
+		t.Fatalf("parsed os=%s device=%s events=%d skipped=%d timeline=%d", p.OS, p.DeviceName, p.Events, p.Skipped, len(p.Timeline))
+	}
+	if len(p.Visits) != 1 || p.Visits[0].Domain != "binance.com" || p.Visits[0].Title != "Binance" || p.Visits[0].Browser != "chrome" || p.Visits[0].VisitedAt != 1709281800 {
+		t.Fatalf("visits=%+v", p.Visits)
+	}
+	if len(p.Apps) != 1 || p.Apps[0].Name != "Exodus" || p.Apps[0].Version != "24.1.1" {
+		t.Fatalf("apps=%+v", p.Apps)
+	}
+
+	autopsy := "Web History\nURL\tDate Accessed\tReferrer URL\tTitle\tProgram Name\tDomain\tSource File\n" +
+		"https://okx.com/trade\t2024-03-01 08:30:00\t\tOKX\tFirefox\tokx.com\t/img/places.sqlite\n"
+	if format, _ := DetectFormat("Web History.txt", []byte(autopsy)); format != FormatAutopsyCSV {
+		t.Fatalf("DetectFormat autopsy=%s", format)
+	}
+	a, err := Parse(FormatAutopsyCSV, strings.NewReader(autopsy))
+	if err != nil || len(a.Visits) != 1 || a.Visits[0].Browser != "firefox" || a.Visits[0].VisitedAt != 1709281800 || len(a.Timeline) != 1 {
+		t.Fatalf("autopsy=%+v err=%v", a, err)
+	}
+}
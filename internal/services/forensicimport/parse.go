@@ -0,0 +1,475 @@
+package forensicimport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/idn"
+)
+
+// 支持的导出格式。
+const (
+	FormatUFEDXML  = "ufed_xml"  // Cellebrite UFED / Physical Analyzer 的 XML 报告（report.xml）
+	FormatAXIOMXML = "axiom_xml" // Magnet AXIOM Examine 导出的 XML
+	FormatCSV      = "csv"       // AXIOM（或其他工具）按证据类型导出的 CSV
+	// FormatPlasoCSV 为 psort 输出的时间线（l2tcsv / dynamic）。
+	FormatPlasoCSV = "plaso_csv"
+	// FormatAutopsyCSV 为 Autopsy 按数据类型生成的报告（Excel 另存 CSV / TSV）。
+	FormatAutopsyCSV = "autopsy_csv"
+)
+
+// 来源工具标识（写入证据 collector/source_ref）。
+const (
+	ToolUFED    = "cellebrite_ufed"
+	ToolAXIOM   = "magnet_axiom"
+	ToolCSV     = "csv_export"
+	ToolPlaso   = "plaso"
+	ToolAutopsy = "autopsy"
+)
+
+// App 是第三方报告中的一条已安装应用。
+type App struct {
+	Name        string `json:"name,omitempty"`
+	Identifier  string `json:"identifier,omitempty"` // Android 包名 / iOS Bundle ID
+	Version     string `json:"version,omitempty"`
+	InstalledAt int64  `json:"installed_at,omitempty"`
+}
+
+// Parsed 是一份第三方报告的解析结果。
+type Parsed struct {
+	Format string
+	Tool   string
+
+	// 设备信息（报告中有则填写）。
+	DeviceName string
+	OS         model.OSType
+	Identifier string // IMEI / 序列号 / UDID
+
+	Apps   []App
+	Visits []model.VisitRecord
+
+	// Timeline 为时间线导入中被识别的事件（仅 Plaso/Autopsy）；Events 为读取的事件总数。
+	Timeline []model.TimelineEventRecord
+	Events   int
+
+	// Skipped 为无法识别为应用/浏览记录的条目数（其他证据类型，例如通话记录）。
+	Skipped int
+}
+
+// DetectFormat 按扩展名与文件头判断导出格式（Plaso/Autopsy 按表头识别）。
+func DetectFormat(name string, head []byte) (string, error) {
+	if format := detectTimelineCSV(head); format != "" {
+		return format, nil
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv", ".tsv", ".txt":
+		return FormatCSV, nil
+	}
+	s := strings.ToLower(string(head))
+	switch {
+	case strings.Contains(s, "pa.cellebrite.com") || strings.Contains(s, "<project"):
+		return FormatUFEDXML, nil
+	case strings.Contains(s, "<artifacts") || strings.Contains(s, "magnet"):
+		return FormatAXIOMXML, nil
+	case strings.HasPrefix(strings.TrimSpace(s), "<"):
+		return "", apperr.New(apperr.CodeInvalidArgument, "unrecognized xml report; specify format ufed_xml or axiom_xml")
+	}
+	return FormatCSV, nil
+}
+
+// Parse 按格式解析报告。
+func Parse(format string, r io.Reader) (*Parsed, error) {
+	switch format {
+	case FormatUFEDXML:
+		return parseUFEDXML(r)
+	case FormatAXIOMXML:
+		return parseAXIOMXML(r)
+	case FormatCSV:
+		return parseCSV(r)
+	case FormatPlasoCSV:
+		return parsePlasoCSV(r)
+	case FormatAutopsyCSV:
+		return parseAutopsyCSV(r)
+	default:
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unsupported import format: %s", format))
+	}
+}
+
+// parseUFEDXML 流式解析 UFED report.xml（报告可能有数 GB，不整体读入内存）：
+// - metadata/item：设备信息（DeviceInfoOSType / DeviceInfoDetectedModel / IMEI / 序列号）
+// - decodedData/modelType/model：InstalledApplication → 应用；VisitedPage/WebHistory → 浏览记录
+func parseUFEDXML(r io.Reader) (*Parsed, error) {
+	out := &Parsed{Format: FormatUFEDXML, Tool: ToolUFED}
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "parse ufed xml")
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch se.Name.Local {
+		case "extractionInfo":
+			if out.DeviceName == "" {
+				out.DeviceName = attr(se, "deviceName")
+			}
+		case "item":
+			var item struct {
+				Value string `xml:",chardata"`
+			}
+			if err := dec.DecodeElement(&item, &se); err != nil {
+				return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "parse ufed metadata")
+			}
+			out.applyDeviceField(attr(se, "name"), item.Value)
+		case "model":
+			var m ufedModel
+			if err := dec.DecodeElement(&m, &se); err != nil {
+				return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "parse ufed model")
+			}
+			out.addRecord(m.Type, m.fields())
+		}
+	}
+	return out, nil
+}
+
+type ufedModel struct {
+	Type   string `xml:"type,attr"`
+	Fields []struct {
+		Name   string   `xml:"name,attr"`
+		Values []string `xml:"value"`
+	} `xml:"field"`
+}
+
+func (m ufedModel) fields() map[string]string {
+	out := make(map[string]string, len(m.Fields))
+	for _, f := range m.Fields {
+		if len(f.Values) > 0 {
+			out[f.Name] = strings.TrimSpace(f.Values[0])
+		}
+	}
+	return out
+}
+
+// parseAXIOMXML 解析 AXIOM 导出的 XML：Artifact(name) → Hit → Fragment(name) 键值。
+func parseAXIOMXML(r io.Reader) (*Parsed, error) {
+	out := &Parsed{Format: FormatAXIOMXML, Tool: ToolAXIOM}
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	artifactName := ""
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "parse axiom xml")
+		}
+		se, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch se.Name.Local {
+		case "Artifact":
+			artifactName = attr(se, "name")
+		case "Hit":
+			var hit struct {
+				Fragments []struct {
+					Name  string `xml:"name,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"Fragment"`
+			}
+			if err := dec.DecodeElement(&hit, &se); err != nil {
+				return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "parse axiom hit")
+			}
+			fields := make(map[string]string, len(hit.Fragments))
+			for _, f := range hit.Fragments {
+				fields[f.Name] = strings.TrimSpace(f.Value)
+			}
+			out.addRecord(artifactName, fields)
+		}
+	}
+	return out, nil
+}
+
+// parseCSV 解析按证据类型导出的 CSV：首行为表头，按列名识别应用/浏览记录。
+func parseCSV(r io.Reader) (*Parsed, error) {
+	out := &Parsed{Format: FormatCSV, Tool: ToolCSV}
+	br := bufio.NewReader(r)
+	// 去掉 UTF-8 BOM（Excel/AXIOM 导出常见）。
+	if b, _ := br.Peek(3); bytes.Equal(b, []byte("\xef\xbb\xbf")) {
+		_, _ = br.Discard(3)
+	}
+	head, _ := br.Peek(4096)
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.Comma = sniffDelimiter(head)
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return out, nil
+	}
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "parse csv header")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "parse csv row")
+		}
+		fields := make(map[string]string, len(header))
+		for i, v := range row {
+			if i < len(header) && header[i] != "" {
+				fields[header[i]] = strings.TrimSpace(v)
+			}
+		}
+		out.addRecord("", fields)
+	}
+	return out, nil
+}
+
+func sniffDelimiter(head []byte) rune {
+	line := string(head)
+	if i := strings.IndexAny(line, "\r\n"); i >= 0 {
+		line = line[:i]
+	}
+	best, bestN := ',', strings.Count(line, ",")
+	for _, d := range []rune{'\t', ';'} {
+		if n := strings.Count(line, string(d)); n > bestN {
+			best, bestN = d, n
+		}
+	}
+	return best
+}
+
+// 字段名候选（按规范化后的名字前缀匹配：小写、去掉空格与符号）。
+var (
+	urlKeys      = []string{"url", "webaddress", "link"}
+	titleKeys    = []string{"title", "pagetitle"}
+	visitKeys    = []string{"lastvisited", "visitdate", "visittime", "lastvisit", "accessed", "datetime", "timestamp", "date"}
+	browserKeys  = []string{"browser", "source", "application"}
+	packageKeys  = []string{"packagename", "package", "bundleidentifier", "bundleid", "identifier", "appidentifier", "applicationid", "appid"}
+	appNameKeys  = []string{"applicationname", "appname", "programname", "displayname", "name"}
+	versionKeys  = []string{"version", "appversion"}
+	installKeys  = []string{"installdate", "installed", "installtime", "purchasedate", "firstinstall"}
+	appTypeHints = []string{"installedapplication", "installedapp", "application", "programs", "apps"}
+)
+
+// addRecord 把一条键值记录识别为应用或浏览记录；recordType 为报告中的类型名（UFED model type / AXIOM artifact name）。
+func (p *Parsed) addRecord(recordType string, fields map[string]string) {
+	norm := make(map[string]string, len(fields))
+	for k, v := range fields {
+		if v = strings.TrimSpace(v); v != "" {
+			norm[normalizeKey(k)] = v
+		}
+	}
+	typ := normalizeKey(recordType)
+
+	if u := pick(norm, urlKeys); u != "" && !hasAny(typ, appTypeHints) {
+		host := urlHost(u)
+		if host == "" {
+			p.Skipped++
+			return
+		}
+		browser := pick(norm, browserKeys)
+		if browser == "" {
+			browser = browserFromType(recordType)
+		}
+		p.Visits = append(p.Visits, model.VisitRecord{
+			Browser:   strings.ToLower(browser),
+			URL:       u,
+			Domain:    host,
+			Title:     pick(norm, titleKeys),
+			VisitedAt: parseTime(pick(norm, visitKeys)),
+		})
+		return
+	}
+
+	pkg := pick(norm, packageKeys)
+	name := pick(norm, appNameKeys)
+	if pkg != "" || (name != "" && hasAny(typ, appTypeHints)) {
+		p.Apps = append(p.Apps, App{
+			Name:        name,
+			Identifier:  strings.ToLower(pkg),
+			Version:     pick(norm, versionKeys),
+			InstalledAt: parseTime(pick(norm, installKeys)),
+		})
+		return
+	}
+	p.Skipped++
+}
+
+// applyDeviceField 识别 UFED metadata 中的设备信息条目。
+func (p *Parsed) applyDeviceField(name, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	key := normalizeKey(strings.TrimPrefix(name, "DeviceInfo"))
+	switch {
+	case key == "ostype" || key == "os" || key == "platform":
+		if os := ParseOS(value); os != "" && p.OS == "" {
+			p.OS = os
+		}
+	case key == "detectedmodel" || key == "selectedmodel" || key == "model":
+		if p.DeviceName == "" {
+			p.DeviceName = value
+		}
+	case strings.Contains(key, "imei") || strings.Contains(key, "serial") || strings.Contains(key, "udid"):
+		if p.Identifier == "" {
+			p.Identifier = value
+		}
+	}
+}
+
+// ParseOS 把工具报告/参数中的系统名归一为 model.OSType（无法识别返回空）。
+func ParseOS(s string) model.OSType {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case strings.Contains(s, "harmony"):
+		return model.OSHarmonyOS
+	case strings.Contains(s, "android"):
+		return model.OSAndroid
+	case s == "ios" || strings.Contains(s, "iphone") || strings.Contains(s, "ipad") || strings.HasPrefix(s, "ios "):
+		return model.OSIOS
+	case strings.Contains(s, "windows"):
+		return model.OSWindows
+	case s == "macos" || strings.Contains(s, "mac os") || strings.Contains(s, "os x"):
+		return model.OSMacOS
+	}
+	return ""
+}
+
+func normalizeKey(k string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(k) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// pick 按候选顺序返回第一个命中的字段（字段名等于或以候选为前缀，兼容 AXIOM 带时区后缀的列名）。
+func pick(norm map[string]string, keys []string) string {
+	for _, k := range keys {
+		if v, ok := norm[k]; ok {
+			return v
+		}
+	}
+	names := make([]string, 0, len(norm))
+	for nk := range norm {
+		names = append(names, nk)
+	}
+	sort.Strings(names)
+	for _, k := range keys {
+		for _, nk := range names {
+			if strings.HasPrefix(nk, k) {
+				return norm[nk]
+			}
+		}
+	}
+	return ""
+}
+
+func hasAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func browserFromType(recordType string) string {
+	t := strings.ToLower(recordType)
+	for _, b := range []string{"chrome", "safari", "firefox", "edge", "opera", "samsung", "brave"} {
+		if strings.Contains(t, b) {
+			return b
+		}
+	}
+	return "imported"
+}
+
+func urlHost(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(idn.ToUnicode(u.Hostname()), "www.")
+}
+
+// 工具导出常见的时间格式（无时区的按 UTC 解释：AXIOM 列名中标注 UTC+00:00）。
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.000-07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05 -07:00",
+	"1/2/2006 3:04:05 PM",
+	"1/2/2006 15:04:05",
+	"01/02/2006 15:04:05",
+	"2006/01/02 15:04:05",
+	"2006-01-02",
+}
+
+// parseTime 解析时间为 Unix 秒；无法解析返回 0。
+func parseTime(s string) int64 {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0
+	}
+	// 去掉 UFED 常见的 "(UTC+0)" 之类后缀。
+	if i := strings.Index(s, "("); i > 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t.Unix()
+		}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > 1e12 {
+			return n / 1000 // 毫秒
+		}
+		return n
+	}
+	return 0
+}
+
+func attr(se xml.StartElement, name string) string {
+	for _, a := range se.Attr {
+		if a.Name.Local == name {
+			return strings.TrimSpace(a.Value)
+		}
+	}
+	return ""
+}
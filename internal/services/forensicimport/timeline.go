@@ -0,0 +1,359 @@
+package forensicimport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+)
+
+// 时间线导入（Plaso/log2timeline、Autopsy）
+//
+// 时间线工具输出的是“事件”而不是“应用清单/浏览记录”，这里按事件来源识别两类线索：
+// - 浏览事件（Plaso source=WEBHIST；Autopsy Web History）→ model.VisitRecord
+// - 应用事件（Windows 卸载项、macOS 安装记录、Android 应用；Autopsy Installed Programs）→ App
+// 被识别的事件原样保留为 timeline 证据，未识别的事件只计数（Skipped），避免把整条时间线灌进库。
+
+var (
+	reEventURL       = regexp.MustCompile(`(?i)\bhttps?://[^\s"'<>\]\)]+`)
+	reEventTitle     = regexp.MustCompile(`^\s*\(([^)]+)\)`)
+	reRegDisplayName = regexp.MustCompile(`DisplayName: \[REG_[A-Z_]+\] (.*?)(?:\s+\w+: \[REG_|\s*$)`)
+	reRegVersion     = regexp.MustCompile(`DisplayVersion: \[REG_[A-Z_]+\] (.*?)(?:\s+\w+: \[REG_|\s*$)`)
+	reMacInstall     = regexp.MustCompile(`Installation of \[(.+?)\]`)
+	reMacPackages    = regexp.MustCompile(`Packages: ([A-Za-z0-9_.\-, ]+?)\.?\s*$`)
+	reAndroidPackage = regexp.MustCompile(`(?i)\bpackage(?: name)?: ([A-Za-z][A-Za-z0-9_]*(?:\.[A-Za-z0-9_]+)+)`)
+	reTrailVersion   = regexp.MustCompile(`^(.+?)\s+v?(\d+(?:\.\d+)+\S*)$`)
+)
+
+// detectTimelineCSV 按表头识别 Plaso / Autopsy 的 CSV/TSV 导出（识别不出返回空）。
+func detectTimelineCSV(head []byte) string {
+	head = bytes.TrimPrefix(head, []byte("\xef\xbb\xbf"))
+	lines := strings.SplitN(strings.ToLower(string(head)), "\n", 3)
+	first := strings.TrimSpace(lines[0])
+	switch {
+	case strings.HasPrefix(first, "date,time,timezone,macb,source"):
+		return FormatPlasoCSV // l2tcsv
+	case strings.HasPrefix(first, "datetime,timestamp_desc,source,source_long,message"):
+		return FormatPlasoCSV // psort dynamic
+	}
+	top := first
+	if len(lines) > 1 {
+		top += "\n" + lines[1]
+	}
+	if strings.Contains(top, "source file") && (strings.Contains(top, "date accessed") || strings.Contains(top, "program name")) {
+		return FormatAutopsyCSV
+	}
+	return ""
+}
+
+// parsePlasoCSV 解析 psort 输出（l2tcsv 或 dynamic 两种列布局）。
+func parsePlasoCSV(r io.Reader) (*Parsed, error) {
+	out := &Parsed{Format: FormatPlasoCSV, Tool: ToolPlaso}
+	cr, header, err := openTimelineCSV(r)
+	if err != nil || header == nil {
+		return out, err
+	}
+	col := indexColumns(header)
+	apps := map[string]struct{}{}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "parse plaso csv row")
+		}
+		out.Events++
+		get := func(name string) string {
+			if i, ok := col[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		ev := model.TimelineEventRecord{
+			Source:  get("source"),
+			Host:    get("host"),
+			Message: get("message"),
+			Parser:  get("parser"),
+		}
+		if _, ok := col["datetime"]; ok {
+			// dynamic：datetime 为 ISO8601（含时区）。
+			ev.Timestamp = parseTime(get("datetime"))
+			ev.TimestampDesc = get("timestampdesc")
+			ev.SourceType = get("sourcelong")
+		} else {
+			// l2tcsv：date(MM/DD/YYYY) + time + timezone。
+			ev.Timestamp = parseL2TTime(get("date"), get("time"), get("timezone"))
+			ev.TimestampDesc = get("type")
+			ev.SourceType = get("sourcetype")
+			ev.Message = get("desc")
+			if ev.Message == "" {
+				ev.Message = get("short")
+			}
+			ev.Parser = get("format")
+		}
+		if out.DeviceName == "" && ev.Host != "" && ev.Host != "-" {
+			out.DeviceName = ev.Host
+		}
+		if !out.addPlasoEvent(ev, apps) {
+			out.Skipped++
+		}
+	}
+	return out, nil
+}
+
+// addPlasoEvent 把一条 Plaso 事件归一化为浏览/应用记录；未识别返回 false。
+func (p *Parsed) addPlasoEvent(ev model.TimelineEventRecord, apps map[string]struct{}) bool {
+	source := strings.ToUpper(ev.Source)
+	hint := strings.ToLower(ev.Parser + " " + ev.SourceType)
+
+	if source == "WEBHIST" && !strings.Contains(hint, "cookie") {
+		loc := reEventURL.FindStringIndex(ev.Message)
+		if loc == nil {
+			return false
+		}
+		u := ev.Message[loc[0]:loc[1]]
+		host := urlHost(u)
+		if host == "" {
+			return false
+		}
+		title := ""
+		if m := reEventTitle.FindStringSubmatch(ev.Message[loc[1]:]); m != nil {
+			title = strings.TrimSpace(m[1])
+		}
+		p.Visits = append(p.Visits, model.VisitRecord{
+			Browser:   browserFromType(hint),
+			URL:       u,
+			Domain:    host,
+			Title:     title,
+			VisitedAt: ev.Timestamp,
+		})
+		ev.Kind = "visit"
+		p.Timeline = append(p.Timeline, ev)
+		return true
+	}
+
+	var app App
+	var osHint model.OSType
+	switch {
+	case strings.Contains(strings.ToLower(ev.Message), `\uninstall\`):
+		m := reRegDisplayName.FindStringSubmatch(ev.Message)
+		if m == nil {
+			return false
+		}
+		app.Name = strings.TrimSpace(m[1])
+		if v := reRegVersion.FindStringSubmatch(ev.Message); v != nil {
+			app.Version = strings.TrimSpace(v[1])
+		}
+		osHint = model.OSWindows
+	case strings.Contains(hint, "install_history") || strings.Contains(hint, "install history"):
+		m := reMacInstall.FindStringSubmatch(ev.Message)
+		if m == nil {
+			return false
+		}
+		app.Name = strings.TrimSpace(m[1])
+		if v := reTrailVersion.FindStringSubmatch(app.Name); v != nil {
+			app.Name, app.Version = v[1], v[2]
+		}
+		if pk := reMacPackages.FindStringSubmatch(ev.Message); pk != nil {
+			app.Identifier = strings.ToLower(strings.TrimSpace(strings.Split(pk[1], ",")[0]))
+		}
+		osHint = model.OSMacOS
+	case strings.Contains(hint, "android"):
+		m := reAndroidPackage.FindStringSubmatch(ev.Message)
+		if m == nil {
+			return false
+		}
+		app.Identifier = strings.ToLower(m[1])
+		osHint = model.OSAndroid
+	default:
+		return false
+	}
+	if app.Name == "" && app.Identifier == "" {
+		return false
+	}
+	app.InstalledAt = ev.Timestamp
+	if p.OS == "" {
+		p.OS = osHint
+	}
+	ev.Kind = "app"
+	p.Timeline = append(p.Timeline, ev)
+	// 同一应用在时间线中会出现多次（注册表键写入、多次使用），应用清单只保留一条。
+	key := strings.ToLower(app.Identifier + "|" + app.Name)
+	if _, ok := apps[key]; !ok {
+		apps[key] = struct{}{}
+		p.Apps = append(p.Apps, app)
+	}
+	return true
+}
+
+// parseAutopsyCSV 解析 Autopsy 报告（Excel/TSV 按数据类型导出）：
+// 首行可以是数据类型标题（例如 "Web History"），之后是表头。
+func parseAutopsyCSV(r io.Reader) (*Parsed, error) {
+	out := &Parsed{Format: FormatAutopsyCSV, Tool: ToolAutopsy}
+	cr, header, err := openTimelineCSV(r)
+	if err != nil || header == nil {
+		return out, err
+	}
+	dataType := ""
+	if len(header) == 1 {
+		dataType = header[0]
+		if header, err = cr.Read(); err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "parse autopsy header")
+		}
+	}
+	col := indexColumns(header)
+	if dataType == "" {
+		if _, ok := col["url"]; ok {
+			dataType = "Web History"
+		} else if _, ok := col["programname"]; ok {
+			dataType = "Installed Programs"
+		}
+	}
+	isWeb := strings.HasPrefix(normalizeKey(dataType), "web")
+	isPrograms := strings.Contains(normalizeKey(dataType), "installedprograms")
+
+	apps := map[string]struct{}{}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "parse autopsy row")
+		}
+		out.Events++
+		get := func(names ...string) string {
+			for _, name := range names {
+				if i, ok := col[name]; ok && i < len(row) && strings.TrimSpace(row[i]) != "" {
+					return strings.TrimSpace(row[i])
+				}
+			}
+			return ""
+		}
+		ev := model.TimelineEventRecord{
+			Source:     dataType,
+			SourceType: get("sourcefile"),
+			Parser:     "autopsy",
+		}
+		switch {
+		case isWeb:
+			u := get("url")
+			host := urlHost(u)
+			if u == "" || host == "" {
+				out.Skipped++
+				continue
+			}
+			ev.Timestamp = parseTime(get("dateaccessed", "datecreated", "datetime"))
+			ev.TimestampDesc = "Date Accessed"
+			ev.Message = u
+			ev.Kind = "visit"
+			out.Visits = append(out.Visits, model.VisitRecord{
+				Browser:   strings.ToLower(firstNonEmpty(get("programname"), "imported")),
+				URL:       u,
+				Domain:    host,
+				Title:     get("title"),
+				VisitedAt: ev.Timestamp,
+			})
+		case isPrograms:
+			name := get("programname")
+			if name == "" {
+				out.Skipped++
+				continue
+			}
+			ev.Timestamp = parseTime(get("datetime", "dateinstalled"))
+			ev.TimestampDesc = "Installed"
+			ev.Message = name
+			ev.Kind = "app"
+			app := App{Name: name, InstalledAt: ev.Timestamp}
+			if v := reTrailVersion.FindStringSubmatch(name); v != nil {
+				app.Name, app.Version = v[1], v[2]
+			}
+			if _, ok := apps[strings.ToLower(app.Name)]; !ok {
+				apps[strings.ToLower(app.Name)] = struct{}{}
+				out.Apps = append(out.Apps, app)
+			}
+		default:
+			out.Skipped++
+			continue
+		}
+		out.Timeline = append(out.Timeline, ev)
+	}
+	return out, nil
+}
+
+// openTimelineCSV 打开 CSV/TSV 并读取首行（空文件返回 nil 表头）。
+func openTimelineCSV(r io.Reader) (*csv.Reader, []string, error) {
+	br := bufio.NewReader(r)
+	if b, _ := br.Peek(3); bytes.Equal(b, []byte("\xef\xbb\xbf")) {
+		_, _ = br.Discard(3)
+	}
+	head, _ := br.Peek(4096)
+	// Autopsy 的数据类型标题行不含分隔符：按下一行（表头）判断分隔符。
+	if i := bytes.IndexByte(head, '\n'); i >= 0 && !bytes.ContainsAny(head[:i], ",\t;") {
+		head = head[i+1:]
+	}
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	cr.Comma = sniffDelimiter(head)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return cr, nil, nil
+	}
+	if err != nil {
+		return nil, nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "parse csv header")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	return cr, header, nil
+}
+
+// indexColumns 返回“规范化列名 → 列下标”（同名列取第一列）。
+func indexColumns(header []string) map[string]int {
+	col := make(map[string]int, len(header))
+	for i, h := range header {
+		k := normalizeKey(h)
+		if _, ok := col[k]; !ok && k != "" {
+			col[k] = i
+		}
+	}
+	return col
+}
+
+// parseL2TTime 解析 l2tcsv 的 date/time/timezone 三列。
+func parseL2TTime(date, clock, tz string) int64 {
+	if date == "" || clock == "" {
+		return 0
+	}
+	loc := time.UTC
+	if tz = strings.TrimSpace(tz); tz != "" && !strings.EqualFold(tz, "UTC") {
+		if l, err := time.LoadLocation(tz); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("01/02/2006 15:04:05", date+" "+clock, loc)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package forensicpdf
+
+import (
+	"context"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/services/exporter"
+)
+
+func init() {
+	exporter.Register(pdfExporter{})
+}
+
+// pdfExporter 把取证 PDF 报告注册为 forensic-pdf。
+type pdfExporter struct{}
+
+func (pdfExporter) Kind() string { return "forensic-pdf" }
+
+func (pdfExporter) Description() string {
+	return "取证 PDF 报告（案件概览、命中、关联分析、证据清单）"
+}
+
+func (pdfExporter) Export(ctx context.Context, store *sqliteadapter.Store, req exporter.Request) (*exporter.Result, error) {
+	res, err := GenerateForensicPDF(ctx, store, Options{
+		CaseID:   req.CaseID,
+		DBPath:   req.DBPath,
+		Operator: req.Operator,
+		Note:     req.Note,
+
+		EvidenceRoot: req.EvidenceRoot,
+
+		UIScreenshots: req.UIScreenshots,
+		BrowserPath:   req.BrowserPath,
+
+		IncludeComments: req.IncludeComments,
+
+		Limits: Limits{
+			Devices:   req.MaxDevices,
+			Prechecks: req.MaxPrechecks,
+			Hits:      req.MaxHits,
+			Artifacts: req.MaxArtifacts,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var extra map[string]any
+	if len(res.Truncated) > 0 {
+		extra = map[string]any{"truncated": res.Truncated}
+	}
+	return &exporter.Result{
+		CaseID:   req.CaseID,
+		ReportID: res.ReportID,
+		ReportNo: res.ReportNo,
+		Path:     res.PDFPath,
+		SHA256:   res.PDFSHA256,
+		FileKey:  "pdf",
+		Warnings: res.Warnings,
+		Extra:    extra,
+	}, nil
+}
@@ -2,6 +2,7 @@ package forensicpdf
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,6 +14,14 @@ import (
 	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
 	"crypto-inspector/internal/domain/model"
 	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/progress"
+	"crypto-inspector/internal/platform/trace"
+	"crypto-inspector/internal/services/comments"
+	"crypto-inspector/internal/services/correlation"
+	"crypto-inspector/internal/services/hitrollup"
+	"crypto-inspector/internal/services/holdings"
+	"crypto-inspector/internal/services/orgprofile"
+	"crypto-inspector/internal/services/xrefverify"
 
 	"github.com/phpdave11/gofpdf"
 )
@@ -32,20 +41,43 @@ type Options struct {
 	DBPath   string
 	Operator string
 	Note     string
+	// EvidenceRoot 是证据目录（持有汇总的 price_snapshot 证据写入此处）；为空时使用 data/evidence。
+	EvidenceRoot string
+
+	// UIScreenshots 为 true 时，用无头浏览器渲染 internal_html 报告的命中表并作为附录插图。
+	UIScreenshots bool
+	// BrowserPath 指定 Chrome/Chromium/Edge 可执行文件；为空时自动探测。
+	BrowserPath string
+
+	// IncludeComments 为 true 时，在命中与证据条目下收录分析评论（内部复核材料）。
+	IncludeComments bool
+
+	// Limits 是正文各列表的输出上限（零值使用 DefaultLimits）；超出部分完整收录到内嵌 CSV 附件。
+	Limits Limits
 }
 
 type Result struct {
 	ReportID    string   `json:"report_id"`
+	ReportNo    string   `json:"report_no,omitempty"`
 	PDFPath     string   `json:"pdf_path"`
 	PDFSHA256   string   `json:"pdf_sha256"`
 	Warnings    []string `json:"warnings,omitempty"`
 	GeneratedAt int64    `json:"generated_at"`
+	// Truncated 列出正文中被截断的列表（完整列表见 PDF 内嵌 CSV 附件）。
+	Truncated []Truncation `json:"truncated,omitempty"`
 }
 
 const pdfGeneratorVer = "forensicpdf-0.1.0"
 
 // GenerateForensicPDF 生成“取证 PDF 报告”，并在 reports 表中登记为 report_type=forensic_pdf。
-func GenerateForensicPDF(ctx context.Context, store *sqliteadapter.Store, opts Options) (*Result, error) {
+//
+// 进度按 load/screenshots/render/write 阶段经 ctx 上的 progress.Reporter 上报；ctx 取消（客户端断开或取消接口）时
+// 在下一条渲染/写出步骤中止，删除未完成的 PDF 并写入 failed 审计。
+func GenerateForensicPDF(ctx context.Context, store *sqliteadapter.Store, opts Options) (_ *Result, retErr error) {
+	ctx, span := trace.Start(ctx, "forensicpdf.GenerateForensicPDF")
+	defer func() { span.End(retErr) }()
+	tr := progress.Start(ctx, "load", "screenshots", "render", "write")
+
 	caseID := strings.TrimSpace(opts.CaseID)
 	if caseID == "" {
 		return nil, fmt.Errorf("case_id is required")
@@ -58,6 +90,23 @@ func GenerateForensicPDF(ctx context.Context, store *sqliteadapter.Store, opts O
 	if operator == "" {
 		operator = "system"
 	}
+	evidenceRoot := strings.TrimSpace(opts.EvidenceRoot)
+	if evidenceRoot == "" {
+		evidenceRoot = "data/evidence"
+	}
+	pdfPath := ""
+	defer func() {
+		if retErr == nil || ctx.Err() == nil {
+			return
+		}
+		if pdfPath != "" {
+			_ = os.Remove(pdfPath)
+		}
+		_ = store.AppendAudit(context.WithoutCancel(ctx), caseID, "", "export", "forensic_pdf", "failed", operator, "forensicpdf.GenerateForensicPDF", map[string]any{
+			"canceled": true,
+			"error":    retErr.Error(),
+		})
+	}()
 
 	ov, err := store.GetCaseOverview(ctx, caseID)
 	if err != nil {
@@ -66,25 +115,71 @@ func GenerateForensicPDF(ctx context.Context, store *sqliteadapter.Store, opts O
 	if ov == nil {
 		return nil, fmt.Errorf("case not found: %s", caseID)
 	}
+	// 导出前检查先于签发报告编号：交叉引用有问题时不占用编号。
+	if err := xrefverify.PreExport(ctx, store, caseID, "forensic_pdf", operator, "forensicpdf.GenerateForensicPDF"); err != nil {
+		return nil, err
+	}
 
 	warnings := []string{}
 
+	// 单位信息与报告编号：签发失败不阻断报告生成，只记 warning。
+	stamp, err := orgprofile.Issue(ctx, store)
+	if err != nil {
+		warnings = append(warnings, "issue report number failed: "+err.Error())
+	}
+
 	// 数据准备：尽量从 DB 直接取，避免依赖 internal_json 报告文件的存在与顺序。
 	devices, err := store.ListCaseDevices(ctx, caseID)
 	if err != nil {
 		warnings = append(warnings, "list devices failed: "+err.Error())
 		devices = []model.CaseDevice{}
 	}
+	// 已识别持有汇总：未配置价格来源时为 nil，不输出该节。
+	// 在补编检材编号之前计算，使本次生成的 price_snapshot 证据同样获得编号并列入证据清单。
+	held, err := holdings.ForCase(ctx, store, caseID, holdings.Options{
+		Operator:     operator,
+		Source:       "forensicpdf.GenerateForensicPDF",
+		EvidenceRoot: evidenceRoot,
+	})
+	if err != nil {
+		warnings = append(warnings, "aggregate holdings failed: "+err.Error())
+	}
+	if held != nil {
+		warnings = append(warnings, held.Warnings...)
+	}
+	// 检材编号：与取证/披露导出包共用同一编号，先补齐未编号的证据。
+	if _, err := store.AssignExhibitNumbers(ctx, caseID); err != nil {
+		warnings = append(warnings, "assign exhibit numbers failed: "+err.Error())
+	}
 	artifacts, err := store.ListArtifactsByCase(ctx, caseID)
 	if err != nil {
 		warnings = append(warnings, "list artifacts failed: "+err.Error())
 		artifacts = []model.ArtifactInfo{}
 	}
+	if err := tr.Step("load", 1, 3); err != nil {
+		return nil, err
+	}
 	hits, err := store.ListCaseHitDetails(ctx, caseID, "")
 	if err != nil {
 		warnings = append(warnings, "list hits failed: "+err.Error())
 		hits = []model.HitDetail{}
 	}
+	if err := tr.Step("load", 2, 3); err != nil {
+		return nil, err
+	}
+	clusters, err := store.ListAddressClusters(ctx, caseID)
+	if err != nil {
+		warnings = append(warnings, "list address clusters failed: "+err.Error())
+		clusters = []model.AddressCluster{}
+	}
+	// 多设备关联：优先使用已落库的 analysis 证据；没有时按当前命中即时计算（不落库）。
+	corr, err := correlation.Latest(ctx, store, caseID)
+	if err != nil {
+		warnings = append(warnings, "load device correlation failed: "+err.Error())
+	}
+	if corr == nil {
+		corr = correlation.Analyze(caseID, devices, hits, time.Now().Unix())
+	}
 	prechecks, err := store.ListPrecheckResults(ctx, caseID)
 	if err != nil {
 		warnings = append(warnings, "list prechecks failed: "+err.Error())
@@ -96,29 +191,39 @@ func GenerateForensicPDF(ctx context.Context, store *sqliteadapter.Store, opts O
 		audits = []model.AuditLog{}
 	}
 
-	// 为了避免 PDF 过大，这里只展示部分列表（内部试用先够用）。
-	const (
-		maxDevices   = 100
-		maxArtifacts = 200
-		maxHits      = 300
-		maxPrechecks = 200
-	)
+	// 为了避免 PDF 过大，正文只展示各列表的前 N 条（见 truncation.go），完整列表作为内嵌 CSV 附件。
+	limits := opts.Limits.resolve()
+	deviceRows := devices[:shown(len(devices), limits.Devices)]
+	artifactRows := artifacts[:shown(len(artifacts), limits.Artifacts)]
+	// 跨设备汇总基于完整命中列表（不受命中上限截断影响），逐设备明细仍按原列表输出。
+	rollup := hitrollup.Build(hits)
+	walletGroups := hitrollup.ByWalletCategory(hits)
+	hitRows := hits[:shown(len(hits), limits.Hits)]
+	precheckRows := prechecks[:shown(len(prechecks), limits.Prechecks)]
 
-	deviceRows := devices
-	if len(deviceRows) > maxDevices {
-		deviceRows = deviceRows[:maxDevices]
-	}
-	artifactRows := artifacts
-	if len(artifactRows) > maxArtifacts {
-		artifactRows = artifactRows[:maxArtifacts]
-	}
-	hitRows := hits
-	if len(hitRows) > maxHits {
-		hitRows = hitRows[:maxHits]
+	// 命中的关联证据按完整列表映射检材编号（不受证据上限截断影响）。
+	exhibitNos := make(map[string]int64, len(artifacts))
+	for _, a := range artifacts {
+		if a.ExhibitNo > 0 {
+			exhibitNos[a.ArtifactID] = a.ExhibitNo
+		}
 	}
-	precheckRows := prechecks
-	if len(precheckRows) > maxPrechecks {
-		precheckRows = precheckRows[:maxPrechecks]
+
+	// 截断清单：CSV 在 buildPDF 排序命中之前生成，保持库中原顺序。
+	cut := &truncationPlan{}
+	for _, s := range []struct {
+		section, title string
+		shown, total   int
+		build          func() ([]byte, error)
+	}{
+		{SectionDevices, "2. Devices", len(deviceRows), len(devices), func() ([]byte, error) { return devicesCSV(devices) }},
+		{SectionPrechecks, "3. Prechecks", len(precheckRows), len(prechecks), func() ([]byte, error) { return prechecksCSV(prechecks) }},
+		{SectionHits, "4. Rule Hits", len(hitRows), len(hits), func() ([]byte, error) { return hitsCSV(hits, exhibitNos) }},
+		{SectionArtifacts, "7. Evidence Artifacts", len(artifactRows), len(artifacts), func() ([]byte, error) { return artifactsCSV(artifacts) }},
+	} {
+		if err := cut.add(s.section, s.title, s.shown, s.total, s.build); err != nil {
+			return nil, err
+		}
 	}
 
 	// 统计摘要
@@ -143,9 +248,26 @@ func GenerateForensicPDF(ctx context.Context, store *sqliteadapter.Store, opts O
 	if err := os.MkdirAll(reportDir, 0o755); err != nil {
 		return nil, fmt.Errorf("mkdir reports: %w", err)
 	}
-	pdfPath := filepath.Join(reportDir, fmt.Sprintf("%s_forensic_%d.pdf", caseID, now))
+	if err := tr.Step("screenshots", 0, 0); err != nil {
+		return nil, err
+	}
+	var shots []uiScreenshot
+	if opts.UIScreenshots {
+		var shotWarnings []string
+		shots, shotWarnings = collectUIScreenshots(ctx, store, caseID, opts.BrowserPath)
+		warnings = append(warnings, shotWarnings...)
+	}
+
+	var notes map[string][]model.Comment
+	if opts.IncludeComments {
+		rows, err := store.ListComments(ctx, caseID, "", "")
+		if err != nil {
+			warnings = append(warnings, "list comments failed: "+err.Error())
+		}
+		notes = comments.Group(rows)
+	}
 
-	pdf, utf8OK, err := buildPDF(*ov, deviceRows, artifactRows, hitRows, precheckRows, operator, opts.Note, walletHits, exchangeHits, lastAuditHash, warnings, now)
+	pdf, utf8OK, err := buildPDF(tr, *ov, deviceRows, artifactRows, exhibitNos, notes, rollup, walletGroups, hitRows, clusters, corr, held, precheckRows, cut, operator, opts.Note, walletHits, exchangeHits, lastAuditHash, warnings, shots, stamp, now)
 	if err != nil {
 		return nil, err
 	}
@@ -154,9 +276,17 @@ func GenerateForensicPDF(ctx context.Context, store *sqliteadapter.Store, opts O
 		// 这里将该事实写入 warnings，避免用户误解为“报告内容丢失”。
 		warnings = append(warnings, "pdf utf8 font not available; non-ascii text may be replaced with '?'")
 	}
+	if err := tr.Step("write", 0, 1); err != nil {
+		return nil, err
+	}
+	pdfPath = filepath.Join(reportDir, stamp.FileName(fmt.Sprintf("%s_forensic_%d.pdf", caseID, now)))
 	if err := pdf.OutputFileAndClose(pdfPath); err != nil {
 		return nil, fmt.Errorf("write pdf: %w", err)
 	}
+	// 登记入库前最后一次检查取消：已取消的报告不登记（由 defer 删除文件）。
+	if err := tr.Step("write", 1, 1); err != nil {
+		return nil, err
+	}
 
 	sum, _, err := hash.File(pdfPath)
 	if err != nil {
@@ -167,50 +297,91 @@ func GenerateForensicPDF(ctx context.Context, store *sqliteadapter.Store, opts O
 	if err != nil {
 		return nil, fmt.Errorf("save report: %w", err)
 	}
+	if err := stamp.Record(ctx, store, reportID); err != nil {
+		warnings = append(warnings, err.Error())
+	}
 
 	// 审计留痕：export/forensic_pdf
 	_ = store.AppendAudit(ctx, caseID, "", "export", "forensic_pdf", "success", operator, "forensicpdf.GenerateForensicPDF", map[string]any{
 		"pdf":            pdfPath,
 		"pdf_sha256":     sum,
+		"report_no":      stamp.ReportNo,
 		"device_count":   ov.DeviceCount,
 		"artifact_count": ov.ArtifactCount,
 		"hit_count":      ov.HitCount,
 		"report_count":   ov.ReportCount,
 		"note":           strings.TrimSpace(opts.Note),
 		"warnings":       warnings,
+		"ui_screenshots": screenshotAuditRefs(shots),
+		"comments":       opts.IncludeComments,
+		"limits":         limits,
+		"truncated":      cut.items,
 	})
+	tr.Done()
 
 	return &Result{
 		ReportID:    reportID,
+		ReportNo:    stamp.ReportNo,
 		PDFPath:     pdfPath,
 		PDFSHA256:   sum,
 		Warnings:    warnings,
 		GeneratedAt: now,
+		Truncated:   cut.items,
 	}, nil
 }
 
+// buildPDF 渲染 PDF；每输出一条设备/前置检查/命中/汇总/地址簇/证据调用一次 tr.Step("render")，ctx 取消时返回 ctx.Err()。
 func buildPDF(
+	tr *progress.Tracker,
 	ov model.CaseOverview,
 	devices []model.CaseDevice,
 	artifacts []model.ArtifactInfo,
+	exhibitNos map[string]int64,
+	notes map[string][]model.Comment,
+	rollup []model.HitRollup,
+	walletGroups []model.WalletCategoryGroup,
 	hits []model.HitDetail,
+	clusters []model.AddressCluster,
+	corr *correlation.Result,
+	held *holdings.Summary,
 	prechecks []model.PrecheckResult,
+	cut *truncationPlan,
 	operator string,
 	note string,
 	walletHits int,
 	exchangeHits int,
 	lastAuditHash string,
 	warnings []string,
+	shots []uiScreenshot,
+	stamp orgprofile.Stamp,
 	generatedAt int64,
 ) (*gofpdf.Fpdf, bool, error) {
+	rendered, total := 0, len(devices)+len(prechecks)+len(rollup)+len(hits)+len(clusters)+len(artifacts)
+	step := func() error {
+		rendered++
+		return tr.Step("render", rendered, total)
+	}
+
 	pdf := gofpdf.New("P", "mm", "A4", "")
 	pdf.SetMargins(14, 14, 14)
 	pdf.SetAutoPageBreak(true, 14)
 	pdf.SetTitle("Crypto Trace Inspector - Forensic Report", false)
+	if len(cut.attachments) > 0 {
+		pdf.SetAttachments(cut.attachments)
+	}
 
 	fontFamily, utf8OK := initPDFUnicodeFont(pdf)
+	applyOrgStamp(pdf, fontFamily, utf8OK, stamp)
 
 	pdf.AddPage()
+	if logo := strings.TrimSpace(stamp.Profile.LogoPath); logo != "" {
+		// 徽标放在首页右上角；读取失败不影响报告生成。
+		pdf.ImageOptions(logo, 170, 14, 26, 0, false, gofpdf.ImageOptions{ReadDpi: true}, 0, "")
+		if pdf.Err() {
+			pdf.ClearError()
+		}
+		pdf.SetXY(14, 14)
+	}
 
 	// 标题
 	pdf.SetFont(fontFamily, "B", 16)
@@ -220,6 +391,12 @@ func buildPDF(
 	pdf.SetTextColor(60, 60, 60)
 	pdf.CellFormat(0, 6, fmt.Sprintf("Generated at: %s", fmtTime(generatedAt)), "", 1, "L", false, 0, "")
 	pdf.CellFormat(0, 6, fmt.Sprintf("Operator: %s", safeText(operator, utf8OK)), "", 1, "L", false, 0, "")
+	if h := stamp.Header(); h != "" {
+		pdf.CellFormat(0, 6, fmt.Sprintf("Issued by: %s", safeText(h, utf8OK)), "", 1, "L", false, 0, "")
+	}
+	if stamp.ReportNo != "" {
+		pdf.CellFormat(0, 6, fmt.Sprintf("Report No: %s", safeText(stamp.ReportNo, utf8OK)), "", 1, "L", false, 0, "")
+	}
 	if strings.TrimSpace(note) != "" {
 		pdf.MultiCell(0, 5, fmt.Sprintf("Note: %s", safeText(note, utf8OK)), "", "L", false)
 	}
@@ -257,6 +434,13 @@ func buildPDF(
 		}
 		pdf.Ln(2)
 	}
+	writeTruncationNotice(pdf, fontFamily, cut)
+
+	if held != nil {
+		sectionTitle(pdf, fontFamily, "Total Identified Holdings")
+		writeHoldings(pdf, fontFamily, utf8OK, held, exhibitNos)
+		pdf.Ln(2)
+	}
 
 	// Devices
 	sectionTitle(pdf, fontFamily, "2. Devices (Top List)")
@@ -266,6 +450,9 @@ func buildPDF(
 		pdf.MultiCell(0, 5, "(empty)", "", "L", false)
 	} else {
 		for i, d := range devices {
+			if err := step(); err != nil {
+				return nil, utf8OK, err
+			}
 			pdf.SetFont(fontFamily, "B", 11)
 			pdf.SetTextColor(20, 20, 20)
 			pdf.CellFormat(0, 6, fmt.Sprintf("Device #%d", i+1), "", 1, "L", false, 0, "")
@@ -280,8 +467,10 @@ func buildPDF(
 			kv(pdf, fontFamily, utf8OK, "Auth Note", d.AuthNote)
 			kv(pdf, fontFamily, utf8OK, "First Seen", fmtTime(d.FirstSeenAt))
 			kv(pdf, fontFamily, utf8OK, "Last Seen", fmtTime(d.LastSeenAt))
+			writeDeviceCustody(pdf, fontFamily, utf8OK, d)
 			pdf.Ln(1)
 		}
+		writeOmittedNote(pdf, fontFamily, cut, SectionDevices)
 	}
 	pdf.Ln(2)
 
@@ -293,6 +482,9 @@ func buildPDF(
 		pdf.MultiCell(0, 5, "(empty)", "", "L", false)
 	} else {
 		for _, c := range prechecks {
+			if err := step(); err != nil {
+				return nil, utf8OK, err
+			}
 			line := fmt.Sprintf("[%s] %s (%s/%s) - %s",
 				strings.ToUpper(string(c.Status)),
 				safeText(c.CheckName, utf8OK),
@@ -304,6 +496,7 @@ func buildPDF(
 			pdf.SetTextColor(30, 30, 30)
 			pdf.MultiCell(0, 4.5, line, "", "L", false)
 		}
+		writeOmittedNote(pdf, fontFamily, cut, SectionPrechecks)
 	}
 	pdf.Ln(2)
 
@@ -314,6 +507,10 @@ func buildPDF(
 		pdf.SetTextColor(90, 90, 90)
 		pdf.MultiCell(0, 5, "(empty)", "", "L", false)
 	} else {
+		writeWalletCategories(pdf, fontFamily, utf8OK, walletGroups)
+		if err := writeHitRollup(pdf, fontFamily, utf8OK, rollup, step); err != nil {
+			return nil, utf8OK, err
+		}
 		// 为了让输出更稳定：按 hit_type + rule_name + matched_value 排序。
 		sort.Slice(hits, func(i, j int) bool {
 			a, b := hits[i], hits[j]
@@ -326,9 +523,17 @@ func buildPDF(
 			return a.MatchedValue < b.MatchedValue
 		})
 		for _, h := range hits {
+			if err := step(); err != nil {
+				return nil, utf8OK, err
+			}
 			pdf.SetFont(fontFamily, "B", 10)
 			pdf.SetTextColor(20, 20, 20)
-			pdf.MultiCell(0, 5, fmt.Sprintf("%s | %s | conf=%.2f | verdict=%s",
+			marker := ""
+			if h.Manual {
+				marker = "[MANUAL] "
+			}
+			pdf.MultiCell(0, 5, fmt.Sprintf("%s%s | %s | conf=%.2f | verdict=%s",
+				marker,
 				safeText(h.HitType, utf8OK),
 				safeText(firstNonEmpty(h.RuleName, h.RuleID), utf8OK),
 				h.Confidence,
@@ -340,17 +545,75 @@ func buildPDF(
 			pdf.MultiCell(0, 4.5, fmt.Sprintf("device_id: %s", safeText(h.DeviceID, utf8OK)), "", "L", false)
 			pdf.MultiCell(0, 4.5, fmt.Sprintf("first_seen: %s | last_seen: %s", fmtTime(h.FirstSeenAt), fmtTime(h.LastSeenAt)), "", "L", false)
 			if len(h.ArtifactIDs) > 0 {
-				ids := append([]string{}, h.ArtifactIDs...)
+				ids := make([]string, 0, len(h.ArtifactIDs))
+				for _, aid := range h.ArtifactIDs {
+					if label := exhibitLabel(exhibitNos[aid], utf8OK); label != "" {
+						aid = label + " " + aid
+					}
+					ids = append(ids, aid)
+				}
 				sort.Strings(ids)
 				pdf.MultiCell(0, 4.5, fmt.Sprintf("artifacts: %s", safeText(strings.Join(ids, ", "), utf8OK)), "", "L", false)
 			}
+			if h.WalletCategory != "" {
+				pdf.MultiCell(0, 4.5, fmt.Sprintf("wallet category: %s", safeText(h.WalletCategory, utf8OK)), "", "L", false)
+			}
+			if h.ClusterID != "" {
+				pdf.MultiCell(0, 4.5, fmt.Sprintf("address cluster: %s", safeText(h.ClusterID, utf8OK)), "", "L", false)
+			}
+			if h.Manual {
+				justification, by := manualHitNote(h.DetailJSON)
+				pdf.MultiCell(0, 4.5, fmt.Sprintf("manually entered by %s: %s", safeText(by, utf8OK), safeText(justification, utf8OK)), "", "L", false)
+			}
+			writeComments(pdf, utf8OK, notes[comments.Key(comments.TargetHit, h.HitID)])
+			pdf.Ln(1)
+		}
+		writeOmittedNote(pdf, fontFamily, cut, SectionHits)
+	}
+	pdf.Ln(2)
+
+	// Address clusters
+	sectionTitle(pdf, fontFamily, "5. Address Clusters")
+	if len(clusters) == 0 {
+		pdf.SetFont(fontFamily, "", 10)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.MultiCell(0, 5, "(empty)", "", "L", false)
+	} else {
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.MultiCell(0, 4.5, "Addresses grouped by co-occurrence (same page/URL, temporal proximity). A group indicates the addresses are likely controlled or tracked by the same subject; manual review is required.", "", "L", false)
+		pdf.Ln(1)
+		for _, c := range clusters {
+			if err := step(); err != nil {
+				return nil, utf8OK, err
+			}
+			reasons := make([]string, 0, len(c.Reasons))
+			for r, n := range c.Reasons {
+				reasons = append(reasons, fmt.Sprintf("%s=%d", r, n))
+			}
+			sort.Strings(reasons)
+			pdf.SetFont(fontFamily, "B", 10)
+			pdf.SetTextColor(20, 20, 20)
+			pdf.MultiCell(0, 5, fmt.Sprintf("%s | %d addresses | score=%.2f", safeText(c.ClusterID, utf8OK), c.Size, c.Score), "", "L", false)
+			pdf.SetFont(fontFamily, "", 9)
+			pdf.SetTextColor(40, 40, 40)
+			pdf.MultiCell(0, 4.5, fmt.Sprintf("evidence: %s", strings.Join(reasons, ", ")), "", "L", false)
+			pdf.MultiCell(0, 4.5, fmt.Sprintf("seen: %s ~ %s", fmtTime(c.FirstSeenAt), fmtTime(c.LastSeenAt)), "", "L", false)
+			for _, a := range c.Addresses {
+				pdf.MultiCell(0, 4.5, "  - "+safeText(a, utf8OK), "", "L", false)
+			}
 			pdf.Ln(1)
 		}
 	}
 	pdf.Ln(2)
 
+	// Cross-device correlation
+	sectionTitle(pdf, fontFamily, "6. Cross-Device Correlation")
+	writeCorrelationSection(pdf, fontFamily, corr, utf8OK)
+	pdf.Ln(2)
+
 	// Artifacts
-	sectionTitle(pdf, fontFamily, "5. Evidence Artifacts (Top List)")
+	sectionTitle(pdf, fontFamily, "7. Evidence Artifacts (Top List)")
 	if len(artifacts) == 0 {
 		pdf.SetFont(fontFamily, "", 10)
 		pdf.SetTextColor(90, 90, 90)
@@ -358,9 +621,16 @@ func buildPDF(
 	} else {
 		// artifacts 已按 collected_at DESC 排序（来自 store），这里直接输出即可。
 		for _, a := range artifacts {
+			if err := step(); err != nil {
+				return nil, utf8OK, err
+			}
 			pdf.SetFont(fontFamily, "B", 10)
 			pdf.SetTextColor(20, 20, 20)
-			pdf.MultiCell(0, 5, fmt.Sprintf("%s | %s | %s", safeText(a.ArtifactType, utf8OK), safeText(a.ArtifactID, utf8OK), fmtTime(a.CollectedAt)), "", "L", false)
+			label := ""
+			if l := exhibitLabel(a.ExhibitNo, utf8OK); l != "" {
+				label = l + " | "
+			}
+			pdf.MultiCell(0, 5, fmt.Sprintf("%s%s | %s | %s", label, safeText(a.ArtifactType, utf8OK), safeText(a.ArtifactID, utf8OK), fmtTime(a.CollectedAt)), "", "L", false)
 			pdf.SetFont(fontFamily, "", 9)
 			pdf.SetTextColor(40, 40, 40)
 			if strings.TrimSpace(a.SourceRef) != "" {
@@ -368,8 +638,10 @@ func buildPDF(
 			}
 			pdf.MultiCell(0, 4.5, fmt.Sprintf("snapshot: %s", safeText(a.SnapshotPath, utf8OK)), "", "L", false)
 			pdf.MultiCell(0, 4.5, fmt.Sprintf("sha256: %s", safeText(a.SHA256, utf8OK)), "", "L", false)
+			writeComments(pdf, utf8OK, notes[comments.Key(comments.TargetArtifact, a.ArtifactID)])
 			pdf.Ln(1)
 		}
+		writeOmittedNote(pdf, fontFamily, cut, SectionArtifacts)
 	}
 
 	// 尾注
@@ -378,9 +650,122 @@ func buildPDF(
 	pdf.SetTextColor(90, 90, 90)
 	pdf.MultiCell(0, 4.5, "Note: This PDF is an internal-forensics artifact. For full evidence chain, use the Forensic ZIP export (manifest.json + hashes.sha256).", "", "L", false)
 
+	if len(shots) > 0 {
+		if err := writeScreenshotAppendix(pdf, fontFamily, utf8OK, shots); err != nil {
+			return nil, utf8OK, err
+		}
+	}
+
 	return pdf, utf8OK, nil
 }
 
+// writeCorrelationSection 输出多设备关联章节：共同交易所/钱包/地址与活跃窗口重叠。
+func writeCorrelationSection(pdf *gofpdf.Fpdf, fontFamily string, corr *correlation.Result, utf8OK bool) {
+	if corr == nil || len(corr.Devices) < 2 {
+		pdf.SetFont(fontFamily, "", 10)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.MultiCell(0, 5, "(fewer than 2 devices in case)", "", "L", false)
+		return
+	}
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.SetTextColor(90, 90, 90)
+	source := "computed at report time (not persisted)"
+	if corr.ArtifactID != "" {
+		source = fmt.Sprintf("analysis artifact %s, generated %s", corr.ArtifactID, fmtTime(corr.GeneratedAt))
+	}
+	pdf.MultiCell(0, 4.5, fmt.Sprintf("Items observed on 2+ devices and overlapping activity windows (%s). Linkage suggests the devices may belong to or be used by the same subject; manual review is required.", safeText(source, utf8OK)), "", "L", false)
+	pdf.Ln(1)
+
+	groups := []struct {
+		title string
+		items []correlation.SharedItem
+	}{
+		{"Shared exchanges", corr.SharedExchanges},
+		{"Shared wallets", corr.SharedWallets},
+		{"Shared addresses", corr.SharedAddresses},
+	}
+	for _, g := range groups {
+		pdf.SetFont(fontFamily, "B", 10)
+		pdf.SetTextColor(20, 20, 20)
+		pdf.MultiCell(0, 5, fmt.Sprintf("%s (%d)", g.title, len(g.items)), "", "L", false)
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.SetTextColor(40, 40, 40)
+		for _, it := range g.items {
+			devs := make([]string, 0, len(it.Devices))
+			for _, d := range it.Devices {
+				devs = append(devs, d.DeviceID)
+			}
+			overlap := "no"
+			if it.Overlap {
+				overlap = fmt.Sprintf("%s ~ %s", fmtTime(it.OverlapStart), fmtTime(it.OverlapEnd))
+			}
+			pdf.MultiCell(0, 4.5, fmt.Sprintf("  - %s | devices: %s | values: %s | overlap: %s",
+				safeText(firstNonEmpty(it.Label, it.Key), utf8OK),
+				safeText(strings.Join(devs, ", "), utf8OK),
+				safeText(strings.Join(it.Values, ", "), utf8OK),
+				overlap,
+			), "", "L", false)
+		}
+	}
+
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.SetTextColor(20, 20, 20)
+	pdf.MultiCell(0, 5, fmt.Sprintf("Overlapping activity windows (%d)", len(corr.WindowOverlaps)), "", "L", false)
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.SetTextColor(40, 40, 40)
+	for _, o := range corr.WindowOverlaps {
+		pdf.MultiCell(0, 4.5, fmt.Sprintf("  - %s <-> %s | %s ~ %s",
+			safeText(o.DeviceA, utf8OK), safeText(o.DeviceB, utf8OK), fmtTime(o.Start), fmtTime(o.End)), "", "L", false)
+	}
+}
+
+// applyOrgStamp 设置每页页眉（单位 + 报告编号）与页脚（地址/联系方式 + 页码）；演练库每页另加斜向水印。
+func applyOrgStamp(pdf *gofpdf.Fpdf, fontFamily string, utf8OK bool, stamp orgprofile.Stamp) {
+	header := stamp.Header()
+	if stamp.ReportNo != "" {
+		header = strings.TrimSpace(header + "  No. " + stamp.ReportNo)
+	}
+	footer := stamp.Footer()
+	watermark := stamp.Watermark()
+	if watermark != "" && !utf8OK {
+		watermark = "TRAINING" // 内置字体无法渲染中文
+	}
+	pdf.AliasNbPages("")
+	pdf.SetHeaderFunc(func() {
+		if watermark != "" {
+			// 页眉回调先于正文执行，水印位于正文下层，不遮挡内容。
+			pdf.TransformBegin()
+			pdf.TransformRotate(35, 105, 150)
+			pdf.SetAlpha(0.18, "Normal")
+			pdf.SetFont(fontFamily, "B", 56)
+			pdf.SetTextColor(200, 30, 30)
+			pdf.SetXY(0, 140)
+			pdf.CellFormat(210, 20, watermark, "", 0, "C", false, 0, "")
+			pdf.SetAlpha(1, "Normal")
+			pdf.TransformEnd()
+			pdf.SetXY(14, 14)
+		}
+		if header == "" {
+			return
+		}
+		pdf.SetY(6)
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(0, 4, safeText(header, utf8OK), "", 0, "R", false, 0, "")
+		pdf.SetXY(14, 14)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-11)
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.SetTextColor(110, 110, 110)
+		text := fmt.Sprintf("Page %d/{nb}", pdf.PageNo())
+		if footer != "" {
+			text = safeText(footer, utf8OK) + "  |  " + text
+		}
+		pdf.CellFormat(0, 4, text, "", 0, "C", false, 0, "")
+	})
+}
+
 func sectionTitle(pdf *gofpdf.Fpdf, fontFamily string, title string) {
 	pdf.SetFont(fontFamily, "B", 12)
 	pdf.SetTextColor(0, 0, 0)
@@ -402,6 +787,30 @@ func kv(pdf *gofpdf.Fpdf, fontFamily string, utf8OK bool, key string, value stri
 	pdf.MultiCell(0, 5.2, safeText(value, utf8OK), "", "L", false)
 }
 
+// writeDeviceCustody 输出人工维护的保管信息与设备照片（文件名 + sha256）；都为空时不输出。
+func writeDeviceCustody(pdf *gofpdf.Fpdf, fontFamily string, utf8OK bool, d model.CaseDevice) {
+	if d.CustodyUpdatedAt == 0 && len(d.Photos) == 0 {
+		return
+	}
+	kv(pdf, fontFamily, utf8OK, "Physical Label", d.PhysicalLabel)
+	kv(pdf, fontFamily, utf8OK, "IMEI", d.IMEI)
+	kv(pdf, fontFamily, utf8OK, "Seized At", d.SeizureLocation)
+	kv(pdf, fontFamily, utf8OK, "Custody Officer", d.CustodyOfficer)
+	if d.CustodyNote != "" {
+		kv(pdf, fontFamily, utf8OK, "Custody Note", d.CustodyNote)
+	}
+	if d.CustodyUpdatedAt > 0 {
+		kv(pdf, fontFamily, utf8OK, "Custody Updated", fmt.Sprintf("%s by %s", fmtTime(d.CustodyUpdatedAt), d.CustodyUpdatedBy))
+	}
+	for i, p := range d.Photos {
+		v := fmt.Sprintf("%s  sha256=%s", p.OriginalName, p.SHA256)
+		if p.Caption != "" {
+			v = p.Caption + " - " + v
+		}
+		kv(pdf, fontFamily, utf8OK, fmt.Sprintf("Photo %d", i+1), v)
+	}
+}
+
 func fmtTime(ts int64) string {
 	if ts <= 0 {
 		return "-"
@@ -409,6 +818,156 @@ func fmtTime(ts int64) string {
 	return time.Unix(ts, 0).Format("2006-01-02 15:04:05")
 }
 
+// writeHitRollup 在逐设备命中明细前输出案件级汇总：同一规则 + 命中值在多台设备上出现时合并为一行。
+// writeHitRollup 输出跨设备汇总表；step 每行调用一次，返回错误（取消）时中止。
+// walletCategoryFollowUp 是各钱包托管类型在报告中提示的后续调查方向。
+var walletCategoryFollowUp = map[string]string{
+	model.WalletCategoryCustodial:         "custodial: funds are held by the provider; request account, KYC and transaction records from the operator",
+	model.WalletCategoryNonCustodial:      "non-custodial: keys are held by the user; look for seed phrases/keystores and trace addresses on-chain",
+	model.WalletCategoryHardwareCompanion: "hardware companion: keys live on a hardware device; locate and seize the device and its recovery sheet",
+	model.WalletCategoryPrivacyCoin:       "privacy coin: on-chain tracing is limited; obtain view keys or wallet files to reconstruct transactions",
+	model.WalletCategoryUncategorized:     "uncategorized: the wallet rule has no category; classify manually before drawing conclusions",
+}
+
+// writeWalletCategories 在命中一节开头按钱包托管类型分组列出钱包安装/运行命中（基于完整命中列表）。
+func writeWalletCategories(pdf *gofpdf.Fpdf, fontFamily string, utf8OK bool, groups []model.WalletCategoryGroup) {
+	if len(groups) == 0 {
+		return
+	}
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.SetTextColor(20, 20, 20)
+	pdf.MultiCell(0, 5, "Wallet hits by category:", "", "L", false)
+	for _, g := range groups {
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.SetTextColor(40, 40, 40)
+		pdf.MultiCell(0, 4.5, fmt.Sprintf("- %s | hits=%d devices=%d | wallets: %s",
+			safeText(g.WalletCategory, utf8OK),
+			g.HitCount,
+			g.DeviceCount,
+			safeText(strings.Join(g.RuleNames, ", "), utf8OK),
+		), "", "L", false)
+		if hint := walletCategoryFollowUp[g.WalletCategory]; hint != "" {
+			pdf.SetTextColor(90, 90, 90)
+			pdf.MultiCell(0, 4.5, "  follow-up: "+hint, "", "L", false)
+		}
+	}
+	pdf.Ln(1)
+}
+
+func writeHitRollup(pdf *gofpdf.Fpdf, fontFamily string, utf8OK bool, rows []model.HitRollup, step func() error) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.SetTextColor(20, 20, 20)
+	pdf.MultiCell(0, 5, fmt.Sprintf("Case summary (merged across devices): %d distinct hits", len(rows)), "", "L", false)
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.SetTextColor(40, 40, 40)
+	for _, r := range rows {
+		if err := step(); err != nil {
+			return err
+		}
+		pdf.MultiCell(0, 4.5, fmt.Sprintf("- %s | %s | %s | devices=%d hits=%d | conf<=%.2f | %s ~ %s",
+			safeText(r.HitType, utf8OK),
+			safeText(firstNonEmpty(r.RuleName, r.RuleID), utf8OK),
+			safeText(r.MatchedValue, utf8OK),
+			r.DeviceCount,
+			r.HitCount,
+			r.MaxConfidence,
+			fmtTime(r.FirstSeenAt),
+			fmtTime(r.LastSeenAt),
+		), "", "L", false)
+	}
+	pdf.Ln(1)
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.SetTextColor(20, 20, 20)
+	pdf.MultiCell(0, 5, "Per-device detail:", "", "L", false)
+	pdf.Ln(1)
+	return nil
+}
+
+// writeHoldings 输出已识别持有汇总表：每个代币一行（数量、单价、报价时间、折算金额），末行为合计；
+// 表后引用本次报价固化的 price_snapshot 证据，估值口径可据此复核。
+func writeHoldings(pdf *gofpdf.Fpdf, fontFamily string, utf8OK bool, sum *holdings.Summary, exhibitNos map[string]int64) {
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.MultiCell(0, 4.5, fmt.Sprintf("Latest on-chain balance per address, converted to %s via price source %q. Unpriced tokens are listed but excluded from the total.", safeText(sum.ReferenceCurrency, utf8OK), safeText(sum.PriceSource, utf8OK)), "", "L", false)
+	if len(sum.Holdings) == 0 {
+		pdf.SetFont(fontFamily, "", 10)
+		pdf.MultiCell(0, 5, "(no token balance hits)", "", "L", false)
+		return
+	}
+	widths := []float64{20, 44, 30, 28, 34, 26}
+	header := []string{"Token", "Amount", "Unit Price", "Value", "Price At", "Addresses"}
+	pdf.SetFont(fontFamily, "B", 9)
+	pdf.SetTextColor(20, 20, 20)
+	for i, h := range header {
+		pdf.CellFormat(widths[i], 6, h, "1", 0, "L", false, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.SetTextColor(40, 40, 40)
+	for _, h := range sum.Holdings {
+		price, value, at := "-", "(unpriced)", "-"
+		if h.Value != "" {
+			price, value, at = h.Price, h.Value, fmtTime(h.PriceAt)
+		}
+		cells := []string{h.Symbol, h.Amount, price, value, at, fmt.Sprintf("%d (%s)", h.AddressCount, strings.Join(h.Networks, ","))}
+		for i, c := range cells {
+			pdf.CellFormat(widths[i], 5.5, safeText(c, utf8OK), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.SetFont(fontFamily, "B", 9)
+	pdf.SetTextColor(20, 20, 20)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2], 6, "Total ("+safeText(sum.ReferenceCurrency, utf8OK)+")", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(widths[3], 6, sum.Total, "1", 0, "L", false, 0, "")
+	pdf.CellFormat(widths[4]+widths[5], 6, "", "1", 1, "L", false, 0, "")
+	if len(sum.Unpriced) > 0 {
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.SetTextColor(120, 80, 0)
+		pdf.MultiCell(0, 4.5, "Unpriced: "+safeText(strings.Join(sum.Unpriced, ", "), utf8OK), "", "L", false)
+	}
+	if ref := sum.PriceSnapshot; ref != nil {
+		label := ref.ArtifactID
+		if l := exhibitLabel(exhibitNos[ref.ArtifactID], utf8OK); l != "" {
+			label = l + " " + label
+		}
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.SetTextColor(40, 40, 40)
+		pdf.MultiCell(0, 4.5, fmt.Sprintf("Price snapshot: %s | sha256=%s | fetched %s | %s",
+			safeText(label, utf8OK), ref.SHA256, fmtTime(ref.FetchedAt), safeText(ref.Endpoint, utf8OK)), "", "L", false)
+	}
+}
+
+// writeComments 在命中/证据条目下输出分析评论（回复缩进一级）。
+func writeComments(pdf *gofpdf.Fpdf, utf8OK bool, rows []model.Comment) {
+	if len(rows) == 0 {
+		return
+	}
+	pdf.SetTextColor(70, 70, 120)
+	pdf.MultiCell(0, 4.5, "comments:", "", "L", false)
+	for _, c := range rows {
+		indent := "  "
+		if c.ParentID != "" {
+			indent = "      re: "
+		}
+		pdf.MultiCell(0, 4.5, fmt.Sprintf("%s[%s] %s: %s", indent, fmtTime(c.CreatedAt), safeText(c.Author, utf8OK), safeText(c.Text, utf8OK)), "", "L", false)
+	}
+	pdf.SetTextColor(40, 40, 40)
+}
+
+// exhibitLabel 返回检材编号标签；未加载 UTF-8 字体时改用 ASCII 形式 EX-001，避免输出 "??-001"。
+func exhibitLabel(n int64, utf8OK bool) string {
+	if n <= 0 {
+		return ""
+	}
+	if utf8OK {
+		return sqliteadapter.ExhibitLabel(n)
+	}
+	return fmt.Sprintf("EX-%03d", n)
+}
+
 func safeText(s string, utf8OK bool) string {
 	// gofpdf 的内置字体对 ASCII/Latin 表现最好；
 	// 如果未成功加载 UTF-8 字体，则把非 ASCII 字符替换为 '?'，确保 PDF 一定能生成（内部试用优先）。
@@ -503,3 +1062,13 @@ func initPDFUnicodeFont(pdf *gofpdf.Fpdf) (family string, utf8OK bool) {
 
 	return "Helvetica", false
 }
+
+// manualHitNote 从人工命中的 detail_json 中取出录入依据与操作员。
+func manualHitNote(detail string) (justification, operator string) {
+	var m struct {
+		Justification string `json:"justification"`
+		Operator      string `json:"operator"`
+	}
+	_ = json.Unmarshal([]byte(detail), &m)
+	return m.Justification, firstNonEmpty(m.Operator, "unknown")
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -144,11 +145,17 @@ func TestGenerateForensicPDF_CreatesReportAndFile(t *testing.T) {
 	_ = store.AppendAudit(ctx, caseID, dev.ID, "unit", "step1", "success", "tester", "pdf_test", map[string]any{"k": "v"})
 	_ = store.AppendAudit(ctx, caseID, dev.ID, "unit", "step2", "success", "tester", "pdf_test", map[string]any{"k2": "v2"})
 
+	// 分析评论（IncludeComments 时收录在命中条目下）
+	if _, err := store.AddComment(ctx, model.Comment{CaseID: caseID, TargetType: "hit", TargetID: h1.ID, Author: "tester", Text: "复核：与交易记录吻合"}); err != nil {
+		t.Fatalf("add comment: %v", err)
+	}
+
 	res, err := GenerateForensicPDF(ctx, store, Options{
-		CaseID:   caseID,
-		DBPath:   dbPath,
-		Operator: "tester",
-		Note:     "unit_test",
+		CaseID:          caseID,
+		DBPath:          dbPath,
+		Operator:        "tester",
+		Note:            "unit_test",
+		IncludeComments: true,
 	})
 	if err != nil {
 		t.Fatalf("GenerateForensicPDF: %v", err)
@@ -184,4 +191,41 @@ func TestGenerateForensicPDF_CreatesReportAndFile(t *testing.T) {
 	if info.SHA256 != res.PDFSHA256 {
 		t.Fatalf("sha mismatch: db=%s res=%s", info.SHA256, res.PDFSHA256)
 	}
+	if len(res.Truncated) != 0 {
+		t.Fatalf("default limits should not truncate: %+v", res.Truncated)
+	}
+
+	// 超出上限：截断清单列出省略条数，完整列表作为内嵌 CSV 附件。
+	more := make([]model.RuleHit, 0, 3)
+	for _, v := range []string{"okx.com", "huobi.com", "kraken.com"} {
+		h := h1
+		h.ID, h.MatchedValue = id.New("hit"), v
+		more = append(more, h)
+	}
+	if err := store.SaveRuleHits(ctx, more); err != nil {
+		t.Fatalf("save hits: %v", err)
+	}
+	res, err = GenerateForensicPDF(ctx, store, Options{
+		CaseID:   caseID,
+		DBPath:   dbPath,
+		Operator: "tester",
+		Limits:   Limits{Hits: 2, Artifacts: -1},
+	})
+	if err != nil {
+		t.Fatalf("GenerateForensicPDF (limited): %v", err)
+	}
+	if len(res.Truncated) != 1 {
+		t.Fatalf("truncated=%+v", res.Truncated)
+	}
+	cut := res.Truncated[0]
+	if cut.Section != SectionHits || cut.Shown != 2 || cut.Total != 4 || cut.Omitted != 2 || cut.Attachment != "hits_full.csv" || len(cut.AttachmentSHA256) != 64 {
+		t.Fatalf("truncation=%+v", cut)
+	}
+	raw, err := os.ReadFile(res.PDFPath)
+	if err != nil {
+		t.Fatalf("read pdf: %v", err)
+	}
+	if !strings.Contains(string(raw), "/EmbeddedFiles") {
+		t.Fatalf("pdf does not embed the csv attachment")
+	}
 }
@@ -0,0 +1,385 @@
+package forensicpdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/platform/hash"
+
+	"github.com/phpdave11/gofpdf"
+)
+
+// UI 截图附录（可选，export forensic-pdf --ui-screenshots）
+//
+// 分析人员在 UI 中看到的是内部 HTML 报告（internal_html）的命中表。为了让 PDF 与导出时的界面一致：
+// - 按 reports 表登记的 internal_html 报告，先校验文件 sha256 与登记值一致（不一致说明文件被改动，跳过）
+// - 截取报告的 <head> 样式与“命中”章节生成临时页面，用无头 Chrome/Chromium/Edge 渲染为 PNG
+// - PNG 按页切片后作为附录插图写入 PDF，图注记录来源报告 ID、HTML sha256 与 PNG sha256
+// 浏览器使用独立的临时 user-data-dir，不读写分析人员本机的浏览器配置；找不到浏览器时只记 warning。
+
+const (
+	// screenshotMaxReports 限制附录收录的 internal_html 报告数量（按生成时间倒序取前 N 个）。
+	screenshotMaxReports = 10
+	// screenshotWidth 是渲染视口宽度（像素）。
+	screenshotWidth = 1280
+	// screenshotMaxHeight 限制渲染视口高度（像素），命中过多时超出部分截断。
+	screenshotMaxHeight = 16000
+	// screenshotTimeout 是单次渲染的超时时间。
+	screenshotTimeout = 60 * time.Second
+)
+
+// uiScreenshot 是一张命中表截图。
+type uiScreenshot struct {
+	ReportID   string
+	HTMLPath   string
+	HTMLSHA256 string
+	PNG        []byte
+	PNGSHA256  string
+	Truncated  bool
+}
+
+// findHeadlessBrowser 返回可用的 Chromium 内核浏览器路径。
+//
+// 规则：
+// 1) 显式指定（--browser）时只使用该路径，不存在即报错。
+// 2) 否则优先使用环境变量 CRYPTO_INSPECTOR_BROWSER。
+// 3) 再按 PATH 与常见安装路径探测（macOS/Windows/Linux）。
+func findHeadlessBrowser(explicit string) (string, error) {
+	if p := strings.TrimSpace(explicit); p != "" {
+		if _, err := os.Stat(p); err != nil {
+			return "", fmt.Errorf("browser not found: %s", p)
+		}
+		return p, nil
+	}
+	if v := strings.TrimSpace(os.Getenv("CRYPTO_INSPECTOR_BROWSER")); v != "" {
+		if _, err := os.Stat(v); err == nil {
+			return v, nil
+		}
+	}
+	for _, name := range []string{"chromium", "chromium-browser", "google-chrome", "google-chrome-stable", "microsoft-edge", "msedge", "chrome"} {
+		if p, err := exec.LookPath(name); err == nil {
+			return p, nil
+		}
+	}
+
+	var candidates []string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = []string{
+			"/Applications/Google Chrome.app/Contents/MacOS/Google Chrome",
+			"/Applications/Chromium.app/Contents/MacOS/Chromium",
+			"/Applications/Microsoft Edge.app/Contents/MacOS/Microsoft Edge",
+		}
+	case "windows":
+		for _, root := range []string{os.Getenv("ProgramFiles"), os.Getenv("ProgramFiles(x86)"), os.Getenv("LOCALAPPDATA")} {
+			if root == "" {
+				continue
+			}
+			candidates = append(candidates,
+				filepath.Join(root, "Google", "Chrome", "Application", "chrome.exe"),
+				filepath.Join(root, "Microsoft", "Edge", "Application", "msedge.exe"),
+			)
+		}
+	default:
+		candidates = []string{"/usr/bin/chromium", "/usr/bin/chromium-browser", "/usr/bin/google-chrome", "/snap/bin/chromium"}
+	}
+	for _, p := range candidates {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("no headless chrome/chromium/edge found (use --browser or CRYPTO_INSPECTOR_BROWSER)")
+}
+
+// collectUIScreenshots 渲染案件 internal_html 报告的命中表；失败项只记 warning，不中断 PDF 生成。
+func collectUIScreenshots(ctx context.Context, store *sqliteadapter.Store, caseID, browserPath string) ([]uiScreenshot, []string) {
+	browser, err := findHeadlessBrowser(browserPath)
+	if err != nil {
+		return nil, []string{"ui screenshots skipped: " + err.Error()}
+	}
+	reports, err := store.ListReportsByCase(ctx, caseID)
+	if err != nil {
+		return nil, []string{"ui screenshots skipped: list reports failed: " + err.Error()}
+	}
+
+	tmp, err := os.MkdirTemp("", "crypto-inspector-shot-*")
+	if err != nil {
+		return nil, []string{"ui screenshots skipped: " + err.Error()}
+	}
+	defer os.RemoveAll(tmp)
+
+	var shots []uiScreenshot
+	var warnings []string
+	for _, r := range reports {
+		if r.ReportType != "internal_html" {
+			continue
+		}
+		if len(shots) >= screenshotMaxReports {
+			warnings = append(warnings, fmt.Sprintf("ui screenshots limited to the latest %d internal_html reports", screenshotMaxReports))
+			break
+		}
+		sum, _, err := hash.File(r.FilePath)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("ui screenshot %s skipped: %v", r.ReportID, err))
+			continue
+		}
+		if r.SHA256 != "" && !strings.EqualFold(sum, r.SHA256) {
+			warnings = append(warnings, fmt.Sprintf("ui screenshot %s skipped: html sha256 mismatch (recorded %s, actual %s)", r.ReportID, r.SHA256, sum))
+			continue
+		}
+		raw, err := os.ReadFile(r.FilePath)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("ui screenshot %s skipped: %v", r.ReportID, err))
+			continue
+		}
+		page, rows, ok := extractHitSection(string(raw))
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("ui screenshot %s skipped: hit section not found", r.ReportID))
+			continue
+		}
+		pagePath := filepath.Join(tmp, r.ReportID+".html")
+		if err := os.WriteFile(pagePath, []byte(page), 0o600); err != nil {
+			warnings = append(warnings, fmt.Sprintf("ui screenshot %s skipped: %v", r.ReportID, err))
+			continue
+		}
+
+		height := 200 + rows*48
+		truncated := height > screenshotMaxHeight
+		if truncated {
+			height = screenshotMaxHeight
+		}
+		pngPath := filepath.Join(tmp, r.ReportID+".png")
+		if err := renderScreenshot(ctx, browser, filepath.Join(tmp, "profile"), pagePath, pngPath, height); err != nil {
+			warnings = append(warnings, fmt.Sprintf("ui screenshot %s failed: %v", r.ReportID, err))
+			continue
+		}
+		img, err := readPNG(pngPath)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("ui screenshot %s failed: %v", r.ReportID, err))
+			continue
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, trimBottom(img)); err != nil {
+			warnings = append(warnings, fmt.Sprintf("ui screenshot %s failed: %v", r.ReportID, err))
+			continue
+		}
+		if truncated {
+			warnings = append(warnings, fmt.Sprintf("ui screenshot %s truncated at %dpx; see the html report for the full table", r.ReportID, screenshotMaxHeight))
+		}
+		shots = append(shots, uiScreenshot{
+			ReportID:   r.ReportID,
+			HTMLPath:   r.FilePath,
+			HTMLSHA256: sum,
+			PNG:        buf.Bytes(),
+			PNGSHA256:  hash.Bytes(buf.Bytes()),
+			Truncated:  truncated,
+		})
+	}
+	if len(shots) == 0 && len(warnings) == 0 {
+		warnings = append(warnings, "ui screenshots skipped: no internal_html report for case")
+	}
+	return shots, warnings
+}
+
+// extractHitSection 从内部 HTML 报告中截取 <head>、标题与“命中”章节，返回临时页面与表格行数。
+func extractHitSection(doc string) (page string, rows int, ok bool) {
+	bodyAt := strings.Index(doc, "<body>")
+	start := strings.Index(doc, "<h2>命中</h2>")
+	if bodyAt < 0 || start < 0 {
+		return "", 0, false
+	}
+	end := strings.Index(doc[start+len("<h2>命中</h2>"):], "<h2>")
+	section := doc[start:]
+	if end >= 0 {
+		section = doc[start : start+len("<h2>命中</h2>")+end]
+	}
+
+	var b strings.Builder
+	b.WriteString(doc[:bodyAt+len("<body>")])
+	b.WriteString("\n")
+	if h1s := strings.Index(doc, "<h1>"); h1s >= 0 && h1s < start {
+		if h1e := strings.Index(doc[h1s:], "</h1>"); h1e >= 0 {
+			b.WriteString(doc[h1s : h1s+h1e+len("</h1>")])
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString(section)
+	b.WriteString("</body>\n</html>\n")
+	return b.String(), strings.Count(section, "<tr>"), true
+}
+
+// renderScreenshot 调用无头浏览器把页面渲染为 PNG。
+func renderScreenshot(ctx context.Context, browser, profileDir, pagePath, pngPath string, height int) error {
+	abs, err := filepath.Abs(pagePath)
+	if err != nil {
+		return err
+	}
+	p := filepath.ToSlash(abs)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	pageURL := (&url.URL{Scheme: "file", Path: p}).String()
+
+	args := []string{
+		"--headless",
+		"--disable-gpu",
+		"--hide-scrollbars",
+		"--no-first-run",
+		"--disable-extensions",
+		"--user-data-dir=" + profileDir,
+		"--screenshot=" + pngPath,
+		fmt.Sprintf("--window-size=%d,%d", screenshotWidth, height),
+	}
+	if runtime.GOOS == "linux" && os.Geteuid() == 0 {
+		// root 下 Chromium 拒绝启用沙箱。
+		args = append(args, "--no-sandbox")
+	}
+	args = append(args, pageURL)
+
+	ctx, cancel := context.WithTimeout(ctx, screenshotTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, browser, args...).CombinedOutput()
+	if _, statErr := os.Stat(pngPath); statErr != nil {
+		if err == nil {
+			err = statErr
+		}
+		return fmt.Errorf("headless render: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// trimBottom 去掉图片底部与最后一行像素同色的空白区域（视口高度按行数估算，通常偏大）。
+func trimBottom(img image.Image) image.Image {
+	bounds := img.Bounds()
+	bg := img.At(bounds.Min.X, bounds.Max.Y-1)
+	br, bgc, bb, ba := bg.RGBA()
+	last := bounds.Max.Y
+	for y := bounds.Max.Y - 1; y > bounds.Min.Y; y-- {
+		blank := true
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			if r != br || g != bgc || b != bb || a != ba {
+				blank = false
+				break
+			}
+		}
+		if !blank {
+			break
+		}
+		last = y
+	}
+	// 保留少量底边距。
+	last = min(bounds.Max.Y, last+24)
+	sub, ok := img.(interface {
+		SubImage(r image.Rectangle) image.Image
+	})
+	if !ok || last >= bounds.Max.Y {
+		return img
+	}
+	return sub.SubImage(image.Rect(bounds.Min.X, bounds.Min.Y, bounds.Max.X, last))
+}
+
+// writeScreenshotAppendix 把截图作为附录插图写入 PDF；超过一页高度的截图按页切片。
+func writeScreenshotAppendix(pdf *gofpdf.Fpdf, fontFamily string, utf8OK bool, shots []uiScreenshot) error {
+	pdf.AddPage()
+	sectionTitle(pdf, fontFamily, "Appendix: UI Evidence Screenshots")
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.SetTextColor(90, 90, 90)
+	pdf.MultiCell(0, 4.5, "Hit tables of the internal HTML reports rendered by a headless browser at export time. Each figure records the source report and sha256 of both the HTML and the rendered PNG.", "", "L", false)
+	pdf.Ln(2)
+
+	left, top, right, bottom := 14.0, 14.0, 14.0, 14.0
+	pageW, pageH := pdf.GetPageSize()
+	contentW := pageW - left - right
+
+	for i, s := range shots {
+		img, err := png.Decode(bytes.NewReader(s.PNG))
+		if err != nil {
+			return fmt.Errorf("decode screenshot %s: %w", s.ReportID, err)
+		}
+		sub, ok := img.(interface {
+			SubImage(r image.Rectangle) image.Image
+		})
+		if !ok {
+			return fmt.Errorf("screenshot %s: unsupported image type", s.ReportID)
+		}
+		bounds := img.Bounds()
+		mmPerPx := contentW / float64(bounds.Dx())
+
+		if pdf.GetY() > pageH-bottom-40 {
+			pdf.AddPage()
+		}
+		pdf.SetFont(fontFamily, "B", 10)
+		pdf.SetTextColor(20, 20, 20)
+		caption := fmt.Sprintf("Figure %d: report %s", i+1, s.ReportID)
+		if s.Truncated {
+			caption += " (truncated)"
+		}
+		pdf.MultiCell(0, 5, safeText(caption, utf8OK), "", "L", false)
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.SetTextColor(60, 60, 60)
+		pdf.MultiCell(0, 4, "html: "+safeText(s.HTMLPath, utf8OK), "", "L", false)
+		pdf.MultiCell(0, 4, "html_sha256: "+s.HTMLSHA256, "", "L", false)
+		pdf.MultiCell(0, 4, "png_sha256: "+s.PNGSHA256, "", "L", false)
+		pdf.Ln(1)
+
+		for y, part := bounds.Min.Y, 0; y < bounds.Max.Y; part++ {
+			avail := pageH - bottom - pdf.GetY()
+			if avail < 30 {
+				pdf.AddPage()
+				pdf.SetY(top)
+				avail = pageH - bottom - top
+			}
+			slicePx := min(bounds.Max.Y-y, int(avail/mmPerPx))
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, sub.SubImage(image.Rect(bounds.Min.X, y, bounds.Max.X, y+slicePx))); err != nil {
+				return fmt.Errorf("encode screenshot slice: %w", err)
+			}
+			name := fmt.Sprintf("shot_%d_%d", i, part)
+			opts := gofpdf.ImageOptions{ImageType: "PNG"}
+			pdf.RegisterImageOptionsReader(name, opts, &buf)
+			h := float64(slicePx) * mmPerPx
+			pdf.ImageOptions(name, left, pdf.GetY(), contentW, h, false, opts, 0, "")
+			pdf.SetY(pdf.GetY() + h)
+			y += slicePx
+		}
+		pdf.Ln(4)
+		if pdf.Err() {
+			return pdf.Error()
+		}
+	}
+	return nil
+}
+
+// screenshotAuditRefs 返回写入审计日志的截图来源与哈希。
+func screenshotAuditRefs(shots []uiScreenshot) []map[string]string {
+	out := make([]map[string]string, 0, len(shots))
+	for _, s := range shots {
+		out = append(out, map[string]string{
+			"report_id":   s.ReportID,
+			"html_sha256": s.HTMLSHA256,
+			"png_sha256":  s.PNGSHA256,
+		})
+	}
+	return out
+}
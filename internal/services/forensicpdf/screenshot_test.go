@@ -0,0 +1,64 @@
+package forensicpdf
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/phpdave11/gofpdf"
+)
+
+func TestExtractHitSection(t *testing.T) {
+	doc := "<!doctype html>\n<html>\n<head><style>table{}</style></head>\n<body>\n" +
+		"<h1>数字货币痕迹检测报告（内部）</h1>\n<h2>设备</h2>\n<div>dev</div>\n" +
+		"<h2>命中</h2>\n<div class=\"box\"><table><tr><th>type</th></tr><tr><td>wallet_installed</td></tr></table></div>\n" +
+		"<h2>证据</h2>\n<div>artifacts</div>\n</body>\n</html>\n"
+	page, rows, ok := extractHitSection(doc)
+	if !ok || rows != 2 {
+		t.Fatalf("ok=%v rows=%d", ok, rows)
+	}
+	for _, want := range []string{"<style>table{}</style>", "<h1>数字货币痕迹检测报告（内部）</h1>", "wallet_installed"} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("page missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "<h2>设备</h2>") || strings.Contains(page, "<h2>证据</h2>") {
+		t.Fatalf("page contains other sections:\n%s", page)
+	}
+	if _, _, ok := extractHitSection("<html><body><h2>证据</h2></body></html>"); ok {
+		t.Fatalf("expected no hit section")
+	}
+}
+
+func TestWriteScreenshotAppendixSlicesTallImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 640, 3000))
+	for y := 0; y < 3000; y++ {
+		for x := 0; x < 640; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(y % 256), G: 20, B: 40, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(14, 14, 14)
+	pdf.SetAutoPageBreak(true, 14)
+	pdf.AddPage()
+	shots := []uiScreenshot{{ReportID: "rep_1", HTMLPath: "/tmp/a.html", HTMLSHA256: "aa", PNG: buf.Bytes(), PNGSHA256: "bb"}}
+	if err := writeScreenshotAppendix(pdf, "Helvetica", false, shots); err != nil {
+		t.Fatalf("appendix: %v", err)
+	}
+	// 3000px 宽 640px 的截图按 182mm 宽缩放约 853mm 高，需要切成至少 4 页。
+	if pdf.PageNo() < 5 {
+		t.Fatalf("pages=%d", pdf.PageNo())
+	}
+	var out bytes.Buffer
+	if err := pdf.Output(&out); err != nil {
+		t.Fatalf("output: %v", err)
+	}
+}
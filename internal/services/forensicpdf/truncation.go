@@ -0,0 +1,223 @@
+package forensicpdf
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+
+	"github.com/phpdave11/gofpdf"
+)
+
+// 正文列表上限与截断清单
+//
+// 为避免 PDF 过大，设备/前置检查/命中/证据列表在正文中只输出前 N 条（Limits，可配置）。
+// 超出上限时不再静默截断：
+// - 完整列表写成 CSV，作为 PDF 内嵌附件（embedded file）随报告一起归档，PDF 哈希同时覆盖附件
+// - 报告开头输出 Truncation Notice，逐节列出“显示 x / 共 y、省略 z 条、完整列表见哪个附件（含 sha256）”
+// - 各节末尾再提示一次省略条数；截断清单同时写入导出结果与审计
+
+// Limits 是正文各列表最多输出的条数；0 使用默认值，负数表示不限。
+type Limits struct {
+	Devices   int `json:"devices,omitempty"`
+	Prechecks int `json:"prechecks,omitempty"`
+	Hits      int `json:"hits,omitempty"`
+	Artifacts int `json:"artifacts,omitempty"`
+}
+
+// DefaultLimits 返回默认上限（设备 100、前置检查 200、命中 300、证据 200）。
+func DefaultLimits() Limits {
+	return Limits{Devices: 100, Prechecks: 200, Hits: 300, Artifacts: 200}
+}
+
+// resolve 用默认值补齐未设置（0）的上限。
+func (l Limits) resolve() Limits {
+	d := DefaultLimits()
+	if l.Devices == 0 {
+		l.Devices = d.Devices
+	}
+	if l.Prechecks == 0 {
+		l.Prechecks = d.Prechecks
+	}
+	if l.Hits == 0 {
+		l.Hits = d.Hits
+	}
+	if l.Artifacts == 0 {
+		l.Artifacts = d.Artifacts
+	}
+	return l
+}
+
+// shown 返回 total 条记录在上限 limit 下实际输出的条数。
+func shown(total, limit int) int {
+	if limit < 0 || total <= limit {
+		return total
+	}
+	return limit
+}
+
+// 截断清单中的列表名。
+const (
+	SectionDevices   = "devices"
+	SectionPrechecks = "prechecks"
+	SectionHits      = "hits"
+	SectionArtifacts = "artifacts"
+)
+
+// Truncation 是一节列表的截断记录：正文只输出 Shown 条，完整 Total 条见内嵌 CSV 附件。
+type Truncation struct {
+	Section          string `json:"section"`
+	Title            string `json:"title"`
+	Shown            int    `json:"shown"`
+	Total            int    `json:"total"`
+	Omitted          int    `json:"omitted"`
+	Attachment       string `json:"attachment"`
+	AttachmentSHA256 string `json:"attachment_sha256"`
+}
+
+// truncationPlan 是一次报告生成的截断清单与对应的内嵌附件。
+type truncationPlan struct {
+	items       []Truncation
+	attachments []gofpdf.Attachment
+}
+
+// add 在 total 超过 shown 时生成完整列表 CSV 附件并记录截断；未截断时不做任何事。
+func (p *truncationPlan) add(section, title string, shown, total int, build func() ([]byte, error)) error {
+	if total <= shown {
+		return nil
+	}
+	raw, err := build()
+	if err != nil {
+		return fmt.Errorf("build %s csv: %w", section, err)
+	}
+	name := section + "_full.csv"
+	p.items = append(p.items, Truncation{
+		Section:          section,
+		Title:            title,
+		Shown:            shown,
+		Total:            total,
+		Omitted:          total - shown,
+		Attachment:       name,
+		AttachmentSHA256: hash.Bytes(raw),
+	})
+	p.attachments = append(p.attachments, gofpdf.Attachment{
+		Content:     raw,
+		Filename:    name,
+		Description: fmt.Sprintf("full %s list (%d records)", section, total),
+	})
+	return nil
+}
+
+// find 返回 section 的截断记录（未截断时为 nil）。
+func (p *truncationPlan) find(section string) *Truncation {
+	if p == nil {
+		return nil
+	}
+	for i := range p.items {
+		if p.items[i].Section == section {
+			return &p.items[i]
+		}
+	}
+	return nil
+}
+
+// writeTruncationNotice 在报告开头列出被截断的列表及完整列表所在附件。
+func writeTruncationNotice(pdf *gofpdf.Fpdf, fontFamily string, plan *truncationPlan) {
+	if plan == nil || len(plan.items) == 0 {
+		return
+	}
+	sectionTitle(pdf, fontFamily, "Truncation Notice")
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.SetTextColor(120, 80, 0)
+	pdf.MultiCell(0, 4.5, "The following lists are shortened in the body of this report. The complete lists are embedded in this PDF as CSV file attachments (open the attachments panel of the PDF viewer); the PDF sha256 covers them.", "", "L", false)
+	for _, t := range plan.items {
+		pdf.MultiCell(0, 4.5, fmt.Sprintf("- %s: showing %d of %d, %d record(s) omitted -> attachment %s (sha256=%s)",
+			t.Title, t.Shown, t.Total, t.Omitted, t.Attachment, t.AttachmentSHA256), "", "L", false)
+	}
+	pdf.Ln(2)
+}
+
+// writeOmittedNote 在被截断的列表末尾提示省略条数与附件名。
+func writeOmittedNote(pdf *gofpdf.Fpdf, fontFamily string, plan *truncationPlan, section string) {
+	t := plan.find(section)
+	if t == nil {
+		return
+	}
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.SetTextColor(120, 80, 0)
+	pdf.MultiCell(0, 4.5, fmt.Sprintf("... %d more record(s) omitted (showing %d of %d); see attachment %s", t.Omitted, t.Shown, t.Total, t.Attachment), "", "L", false)
+}
+
+func writeCSV(header []string, n int, row func(i int) []string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		if err := w.Write(row(i)); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func devicesCSV(rows []model.CaseDevice) ([]byte, error) {
+	return writeCSV([]string{"device_id", "os_type", "device_name", "identifier", "connection_type", "authorized", "auth_note", "first_seen_at", "last_seen_at",
+		"physical_label", "imei", "seizure_location", "custody_officer", "custody_note", "photo_sha256"}, len(rows), func(i int) []string {
+		d := rows[i]
+		photos := make([]string, 0, len(d.Photos))
+		for _, p := range d.Photos {
+			photos = append(photos, p.SHA256)
+		}
+		return []string{d.DeviceID, d.OSType, d.DeviceName, d.Identifier, d.ConnectionType, strconv.FormatBool(d.Authorized), d.AuthNote, csvTime(d.FirstSeenAt), csvTime(d.LastSeenAt),
+			d.PhysicalLabel, d.IMEI, d.SeizureLocation, d.CustodyOfficer, d.CustodyNote, strings.Join(photos, ";")}
+	})
+}
+
+func prechecksCSV(rows []model.PrecheckResult) ([]byte, error) {
+	return writeCSV([]string{"scan_scope", "device_id", "check_code", "check_name", "required", "status", "message", "checked_at"}, len(rows), func(i int) []string {
+		c := rows[i]
+		return []string{c.ScanScope, c.DeviceID, c.CheckCode, c.CheckName, strconv.FormatBool(c.Required), string(c.Status), c.Message, csvTime(c.CheckedAt)}
+	})
+}
+
+func hitsCSV(rows []model.HitDetail, exhibitNos map[string]int64) ([]byte, error) {
+	return writeCSV([]string{"hit_id", "hit_type", "rule_id", "rule_name", "matched_value", "confidence", "verdict", "device_id", "first_seen_at", "last_seen_at", "artifact_ids", "exhibits", "cluster_id", "manual", "wallet_category"}, len(rows), func(i int) []string {
+		h := rows[i]
+		exhibits := make([]string, 0, len(h.ArtifactIDs))
+		for _, aid := range h.ArtifactIDs {
+			if n := exhibitNos[aid]; n > 0 {
+				exhibits = append(exhibits, exhibitLabel(n, true))
+			}
+		}
+		return []string{
+			h.HitID, h.HitType, h.RuleID, h.RuleName, h.MatchedValue, strconv.FormatFloat(h.Confidence, 'f', 2, 64), h.Verdict, h.DeviceID,
+			csvTime(h.FirstSeenAt), csvTime(h.LastSeenAt), strings.Join(h.ArtifactIDs, ";"), strings.Join(exhibits, ";"), h.ClusterID, strconv.FormatBool(h.Manual), h.WalletCategory,
+		}
+	})
+}
+
+func artifactsCSV(rows []model.ArtifactInfo) ([]byte, error) {
+	return writeCSV([]string{"exhibit", "artifact_id", "artifact_type", "device_id", "source_ref", "snapshot_path", "sha256", "size_bytes", "collected_at"}, len(rows), func(i int) []string {
+		a := rows[i]
+		return []string{exhibitLabel(a.ExhibitNo, true), a.ArtifactID, a.ArtifactType, a.DeviceID, a.SourceRef, a.SnapshotPath, a.SHA256, strconv.FormatInt(a.SizeBytes, 10), csvTime(a.CollectedAt)}
+	})
+}
+
+// csvTime 输出 UTC RFC3339 时间（未知时为空）。
+func csvTime(ts int64) string {
+	if ts <= 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,233 @@
+package graphexport
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/trace"
+	"crypto-inspector/internal/services/exporter"
+	"crypto-inspector/internal/services/orgprofile"
+)
+
+const graphGeneratorVer = "graphexport-0.1.0"
+
+func init() {
+	exporter.Register(graphExporter{})
+}
+
+// Options 定义关系图导出参数。
+type Options struct {
+	CaseID   string
+	DBPath   string
+	Operator string
+	Note     string
+	// ExportDir 为空时写入 db 同级的 exports 目录。
+	ExportDir string
+}
+
+// Result 是一次关系图导出的结果。
+type Result struct {
+	ReportID  string   `json:"report_id"`
+	ReportNo  string   `json:"report_no,omitempty"`
+	ZipPath   string   `json:"zip_path"`
+	ZipSHA256 string   `json:"zip_sha256"`
+	NodeCount int      `json:"node_count"`
+	EdgeCount int      `json:"edge_count"`
+	Warnings  []string `json:"warnings,omitempty"`
+}
+
+// GenerateGraphExport 生成案件关系图导出包，并在 reports 表中登记为 report_type=graph_export。
+//
+// 输出 ZIP 内容：
+// - graph.graphml：Gephi / yEd / i2 可直接打开
+// - nodes.csv、relationships.csv：neo4j-admin database import full --nodes=nodes.csv --relationships=relationships.csv
+// - hashes.sha256：ZIP 内各文件 sha256 列表
+func GenerateGraphExport(ctx context.Context, store *sqliteadapter.Store, opts Options) (_ *Result, retErr error) {
+	ctx, span := trace.Start(ctx, "graphexport.GenerateGraphExport")
+	defer func() { span.End(retErr) }()
+
+	caseID := strings.TrimSpace(opts.CaseID)
+	if caseID == "" {
+		return nil, fmt.Errorf("case_id is required")
+	}
+	dbPath := strings.TrimSpace(opts.DBPath)
+	if dbPath == "" {
+		return nil, fmt.Errorf("db_path is required")
+	}
+	operator := strings.TrimSpace(opts.Operator)
+	if operator == "" {
+		operator = "system"
+	}
+	exportDir := strings.TrimSpace(opts.ExportDir)
+	if exportDir == "" {
+		exportDir = filepath.Join(filepath.Dir(dbPath), "exports")
+	}
+
+	ov, err := store.GetCaseOverview(ctx, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("get case overview: %w", err)
+	}
+	if ov == nil {
+		return nil, fmt.Errorf("case not found: %s", caseID)
+	}
+	devices, err := store.ListCaseDevices(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	hits, err := store.ListCaseHitDetails(ctx, caseID, "")
+	if err != nil {
+		return nil, err
+	}
+	warnings := []string{}
+	stamp, err := orgprofile.Issue(ctx, store)
+	if err != nil {
+		warnings = append(warnings, "issue report number failed: "+err.Error())
+	}
+	clusters, err := store.ListAddressClusters(ctx, caseID)
+	if err != nil {
+		warnings = append(warnings, "list address clusters failed: "+err.Error())
+		clusters = []model.AddressCluster{}
+	}
+
+	g := Build(caseID, devices, hits, clusters)
+	var graphML, nodesCSV, edgesCSV bytes.Buffer
+	if err := g.WriteGraphML(&graphML); err != nil {
+		return nil, fmt.Errorf("write graphml: %w", err)
+	}
+	if err := g.WriteNodesCSV(&nodesCSV); err != nil {
+		return nil, fmt.Errorf("write nodes csv: %w", err)
+	}
+	if err := g.WriteEdgesCSV(&edgesCSV); err != nil {
+		return nil, fmt.Errorf("write relationships csv: %w", err)
+	}
+
+	if err := os.MkdirAll(exportDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create export dir: %w", err)
+	}
+	zipPath := filepath.Join(exportDir, stamp.FileName(fmt.Sprintf("%s_graph_export_%d.zip", caseID, time.Now().Unix())))
+	if err := writeGraphZip(zipPath, stamp.Watermark(), []zipEntry{
+		{"graph.graphml", graphML.Bytes()},
+		{"nodes.csv", nodesCSV.Bytes()},
+		{"relationships.csv", edgesCSV.Bytes()},
+	}); err != nil {
+		return nil, err
+	}
+	sum, _, err := hash.File(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("hash zip: %w", err)
+	}
+
+	reportID, err := store.SaveReport(ctx, caseID, "graph_export", zipPath, sum, graphGeneratorVer, "ready")
+	if err != nil {
+		return nil, fmt.Errorf("save report: %w", err)
+	}
+	if err := stamp.Record(ctx, store, reportID); err != nil {
+		warnings = append(warnings, err.Error())
+	}
+	_ = store.AppendAudit(ctx, caseID, "", "export", "graph_export", "success", operator, "graphexport.GenerateGraphExport", map[string]any{
+		"zip_path":   zipPath,
+		"zip_sha256": sum,
+		"report_no":  stamp.ReportNo,
+		"node_count": len(g.Nodes),
+		"edge_count": len(g.Edges),
+		"note":       strings.TrimSpace(opts.Note),
+		"warnings":   warnings,
+	})
+
+	return &Result{
+		ReportID:  reportID,
+		ReportNo:  stamp.ReportNo,
+		ZipPath:   zipPath,
+		ZipSHA256: sum,
+		NodeCount: len(g.Nodes),
+		EdgeCount: len(g.Edges),
+		Warnings:  warnings,
+	}, nil
+}
+
+type zipEntry struct {
+	name string
+	data []byte
+}
+
+// writeGraphZip 写入导出包，并附 hashes.sha256；watermark 非空时写入 ZIP 注释（演练库）。
+func writeGraphZip(zipPath, watermark string, entries []zipEntry) error {
+	f, err := os.Create(zipPath)
+	if err != nil {
+		return fmt.Errorf("create zip: %w", err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	if watermark != "" {
+		_ = zw.SetComment(watermark + " - synthetic training data, not evidence")
+	}
+
+	lines := []string{
+		"# crypto-inspector graph export hash list",
+		"# format: <sha256><two spaces><path>",
+	}
+	for _, e := range entries {
+		lines = append(lines, fmt.Sprintf("%s  %s", hash.Bytes(e.data), e.name))
+	}
+	entries = append(entries, zipEntry{"hashes.sha256", []byte(strings.Join(lines, "\n") + "\n")})
+	for _, e := range entries {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: e.name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return fmt.Errorf("write %s to zip: %w", e.name, err)
+		}
+		if _, err := w.Write(e.data); err != nil {
+			return fmt.Errorf("write %s to zip: %w", e.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("close zip writer: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close zip file: %w", err)
+	}
+	return nil
+}
+
+// graphExporter 把关系图导出注册为 graph-zip。
+type graphExporter struct{}
+
+func (graphExporter) Kind() string { return "graph-zip" }
+
+func (graphExporter) Description() string {
+	return "实体关系图（GraphML + Neo4j 导入 CSV，可用于 i2/Neo4j/Gephi）"
+}
+
+func (graphExporter) Export(ctx context.Context, store *sqliteadapter.Store, req exporter.Request) (*exporter.Result, error) {
+	res, err := GenerateGraphExport(ctx, store, Options{
+		CaseID:    req.CaseID,
+		DBPath:    req.DBPath,
+		Operator:  req.Operator,
+		Note:      req.Note,
+		ExportDir: req.ExportDir,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &exporter.Result{
+		CaseID:   req.CaseID,
+		ReportID: res.ReportID,
+		ReportNo: res.ReportNo,
+		Path:     res.ZipPath,
+		SHA256:   res.ZipSHA256,
+		FileKey:  "zip",
+		Warnings: res.Warnings,
+		Extra: map[string]any{
+			"node_count": res.NodeCount,
+			"edge_count": res.EdgeCount,
+		},
+	}, nil
+}
@@ -0,0 +1,361 @@
+package graphexport
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 案件实体关系图
+//
+// 关系图（link chart）是金融犯罪案件的常规交付物。这里把案件数据整理为节点/边：
+// - 节点：Case、Device、Hit，以及命中指向的实体 Domain（交易所域名）、App（钱包应用）、
+//   Address（钱包地址）、Cluster（地址聚类）、Indicator（关注词/正则/人工发现等其他命中值）
+// - 边：Case-HAS_DEVICE->Device、Device-CHILD_OF->Device（VM 子设备）、Device-RECORDED->Hit、
+//   Hit-REFERS_TO->实体、Address-IN_CLUSTER->Cluster
+// 同一实体在多台设备上出现时共用一个节点，跨设备的关联在图上直接可见。
+// 输出 GraphML（Gephi/yEd/i2）与 Neo4j neo4j-admin import 格式的 CSV；属性一律按字符串输出。
+
+// 节点标签。
+const (
+	LabelCase      = "Case"
+	LabelDevice    = "Device"
+	LabelHit       = "Hit"
+	LabelDomain    = "Domain"
+	LabelApp       = "App"
+	LabelAddress   = "Address"
+	LabelCluster   = "Cluster"
+	LabelIndicator = "Indicator"
+)
+
+// 边类型。
+const (
+	RelHasDevice = "HAS_DEVICE"
+	RelChildOf   = "CHILD_OF"
+	RelRecorded  = "RECORDED"
+	RelRefersTo  = "REFERS_TO"
+	RelInCluster = "IN_CLUSTER"
+)
+
+// Node 是图中的一个节点；ID 在图内唯一（形如 label 小写 + ":" + 业务键）。
+type Node struct {
+	ID    string
+	Label string
+	Name  string
+	Props map[string]string
+}
+
+// Edge 是一条有向边。
+type Edge struct {
+	Source string
+	Target string
+	Type   string
+	Props  map[string]string
+}
+
+// Graph 是导出用的节点/边集合（节点、边均按插入顺序输出）。
+type Graph struct {
+	Nodes []Node
+	Edges []Edge
+
+	index map[string]int
+	seen  map[string]struct{}
+}
+
+// NewGraph 创建空图。
+func NewGraph() *Graph {
+	return &Graph{index: map[string]int{}, seen: map[string]struct{}{}}
+}
+
+// AddNode 添加节点；同 ID 节点已存在时只补充缺失的属性。
+func (g *Graph) AddNode(n Node) {
+	if i, ok := g.index[n.ID]; ok {
+		cur := &g.Nodes[i]
+		for k, v := range n.Props {
+			if _, exists := cur.Props[k]; !exists && v != "" {
+				cur.Props[k] = v
+			}
+		}
+		return
+	}
+	if n.Props == nil {
+		n.Props = map[string]string{}
+	}
+	g.index[n.ID] = len(g.Nodes)
+	g.Nodes = append(g.Nodes, n)
+}
+
+// AddEdge 添加边；同起止点、同类型的边只保留第一条。
+func (g *Graph) AddEdge(e Edge) {
+	key := e.Source + "\x00" + e.Target + "\x00" + e.Type
+	if _, ok := g.seen[key]; ok {
+		return
+	}
+	if e.Props == nil {
+		e.Props = map[string]string{}
+	}
+	g.seen[key] = struct{}{}
+	g.Edges = append(g.Edges, e)
+}
+
+// Build 由案件设备、命中与地址聚类构建关系图。
+func Build(caseID string, devices []model.CaseDevice, hits []model.HitDetail, clusters []model.AddressCluster) *Graph {
+	g := NewGraph()
+	caseNode := "case:" + caseID
+	g.AddNode(Node{ID: caseNode, Label: LabelCase, Name: caseID})
+
+	for _, d := range devices {
+		name := d.DeviceName
+		if name == "" {
+			name = d.DeviceID
+		}
+		g.AddNode(Node{ID: "device:" + d.DeviceID, Label: LabelDevice, Name: name, Props: map[string]string{
+			"device_id":       d.DeviceID,
+			"os_type":         d.OSType,
+			"identifier":      d.Identifier,
+			"connection_type": d.ConnectionType,
+			"first_seen_at":   unixString(d.FirstSeenAt),
+			"last_seen_at":    unixString(d.LastSeenAt),
+		}})
+		g.AddEdge(Edge{Source: caseNode, Target: "device:" + d.DeviceID, Type: RelHasDevice})
+	}
+	for _, d := range devices {
+		if d.ParentDeviceID != "" {
+			g.AddEdge(Edge{Source: "device:" + d.DeviceID, Target: "device:" + d.ParentDeviceID, Type: RelChildOf})
+		}
+	}
+
+	for _, h := range hits {
+		deviceNode := "device:" + h.DeviceID
+		// 命中所属设备可能已从案件设备表移除，仍补一个节点保证边完整。
+		g.AddNode(Node{ID: deviceNode, Label: LabelDevice, Name: h.DeviceID, Props: map[string]string{"device_id": h.DeviceID}})
+
+		hitNode := "hit:" + h.HitID
+		g.AddNode(Node{ID: hitNode, Label: LabelHit, Name: h.HitType + " " + h.MatchedValue, Props: map[string]string{
+			"hit_id":        h.HitID,
+			"hit_type":      h.HitType,
+			"rule_id":       h.RuleID,
+			"rule_name":     h.RuleName,
+			"matched_value": h.MatchedValue,
+			"confidence":    strconv.FormatFloat(h.Confidence, 'f', 2, 64),
+			"verdict":       h.Verdict,
+			"first_seen_at": unixString(h.FirstSeenAt),
+			"last_seen_at":  unixString(h.LastSeenAt),
+			"manual":        strconv.FormatBool(h.Manual),
+		}})
+		g.AddEdge(Edge{Source: deviceNode, Target: hitNode, Type: RelRecorded})
+
+		if ent, ok := hitEntity(h); ok {
+			g.AddNode(ent)
+			g.AddEdge(Edge{Source: hitNode, Target: ent.ID, Type: RelRefersTo, Props: map[string]string{"hit_type": h.HitType}})
+			if ent.Label == LabelAddress && h.ClusterID != "" {
+				g.AddEdge(Edge{Source: ent.ID, Target: "cluster:" + h.ClusterID, Type: RelInCluster})
+				g.AddNode(Node{ID: "cluster:" + h.ClusterID, Label: LabelCluster, Name: h.ClusterID})
+			}
+		}
+	}
+
+	for _, c := range clusters {
+		clusterNode := "cluster:" + c.ClusterID
+		g.AddNode(Node{ID: clusterNode, Label: LabelCluster, Name: c.ClusterID, Props: map[string]string{
+			"size":  strconv.Itoa(c.Size),
+			"score": strconv.FormatFloat(c.Score, 'f', 2, 64),
+		}})
+		for _, addr := range c.Addresses {
+			addrNode := "address:" + normalizeAddress(addr)
+			g.AddNode(Node{ID: addrNode, Label: LabelAddress, Name: addr})
+			g.AddEdge(Edge{Source: addrNode, Target: clusterNode, Type: RelInCluster})
+		}
+	}
+	return g
+}
+
+// hitEntity 返回命中指向的实体节点；命中值为空时没有实体。
+func hitEntity(h model.HitDetail) (Node, bool) {
+	value := strings.TrimSpace(h.MatchedValue)
+	if value == "" {
+		return Node{}, false
+	}
+	switch model.HitType(h.HitType) {
+	case model.HitExchangeVisited, model.HitExchangeFormActivity, model.HitPhishingSuspected:
+		domain := strings.ToLower(value)
+		return Node{ID: "domain:" + domain, Label: LabelDomain, Name: domain, Props: map[string]string{
+			"exchange_id":   h.RuleID,
+			"exchange_name": h.RuleName,
+		}}, true
+	case model.HitWalletInstalled, model.HitWalletExecuted, model.HitWalletSuspectedUnknown:
+		key := strings.TrimSpace(h.RuleID)
+		if key == "" {
+			key = strings.ToLower(value)
+		}
+		name := h.RuleName
+		if name == "" {
+			name = value
+		}
+		return Node{ID: "app:" + key, Label: LabelApp, Name: name, Props: map[string]string{"wallet_id": h.RuleID}}, true
+	case model.HitExchangeAppInstalled:
+		// 交易所桌面客户端与钱包应用共用 App 标签，节点 ID 加前缀避免与钱包规则 ID 冲突。
+		name := h.RuleName
+		if name == "" {
+			name = value
+		}
+		return Node{ID: "app:exchange:" + strings.TrimSpace(h.RuleID), Label: LabelApp, Name: name, Props: map[string]string{"exchange_id": h.RuleID}}, true
+	case model.HitWalletAddress, model.HitTokenBalance, model.HitNFTHoldings:
+		// token_balance / nft_holdings 的命中值格式为 addr|symbol 或 addr|contract。
+		addr := strings.TrimSpace(strings.SplitN(value, "|", 2)[0])
+		return Node{ID: "address:" + normalizeAddress(addr), Label: LabelAddress, Name: addr}, true
+	default:
+		return Node{ID: "indicator:" + h.HitType + ":" + strings.ToLower(value), Label: LabelIndicator, Name: value, Props: map[string]string{
+			"indicator_type": h.HitType,
+		}}, true
+	}
+}
+
+// normalizeAddress 统一地址大小写：EVM（0x）与 bech32 地址不区分大小写，base58 地址保持原样。
+func normalizeAddress(addr string) string {
+	lower := strings.ToLower(addr)
+	if strings.HasPrefix(lower, "0x") || strings.HasPrefix(lower, "bc1") {
+		return lower
+	}
+	return addr
+}
+
+func unixString(v int64) string {
+	if v <= 0 {
+		return ""
+	}
+	return strconv.FormatInt(v, 10)
+}
+
+// propKeys 返回节点（或边）属性键的并集（排序后），用于 GraphML key 声明与 CSV 列。
+func propKeys(props []map[string]string) []string {
+	set := map[string]struct{}{}
+	for _, p := range props {
+		for k := range p {
+			set[k] = struct{}{}
+		}
+	}
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (g *Graph) nodeProps() []map[string]string {
+	out := make([]map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		out[i] = n.Props
+	}
+	return out
+}
+
+func (g *Graph) edgeProps() []map[string]string {
+	out := make([]map[string]string, len(g.Edges))
+	for i, e := range g.Edges {
+		out[i] = e.Props
+	}
+	return out
+}
+
+// WriteGraphML 以 GraphML 格式输出（有向图；label/name 与各属性均声明为 string key）。
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	nodeKeys := propKeys(g.nodeProps())
+	edgeKeys := propKeys(g.edgeProps())
+
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:schemaLocation="http://graphml.graphdrawing.org/xmlns http://graphml.graphdrawing.org/xmlns/1.0/graphml.xsd">` + "\n")
+	writeKey := func(id, scope, name string) {
+		fmt.Fprintf(&b, "  <key id=%q for=%q attr.name=%q attr.type=\"string\"/>\n", id, scope, name)
+	}
+	writeKey("n_label", "node", "label")
+	writeKey("n_name", "node", "name")
+	for _, k := range nodeKeys {
+		writeKey("n_"+k, "node", k)
+	}
+	writeKey("e_type", "edge", "type")
+	for _, k := range edgeKeys {
+		writeKey("e_"+k, "edge", k)
+	}
+	b.WriteString("  <graph id=\"G\" edgedefault=\"directed\">\n")
+	writeData := func(key, value string) {
+		if value == "" {
+			return
+		}
+		fmt.Fprintf(&b, "      <data key=%q>%s</data>\n", key, escapeXML(value))
+	}
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "    <node id=\"%s\">\n", escapeXML(n.ID))
+		writeData("n_label", n.Label)
+		writeData("n_name", n.Name)
+		for _, k := range nodeKeys {
+			writeData("n_"+k, n.Props[k])
+		}
+		b.WriteString("    </node>\n")
+	}
+	for i, e := range g.Edges {
+		fmt.Fprintf(&b, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\">\n", i, escapeXML(e.Source), escapeXML(e.Target))
+		writeData("e_type", e.Type)
+		for _, k := range edgeKeys {
+			writeData("e_"+k, e.Props[k])
+		}
+		b.WriteString("    </edge>\n")
+	}
+	b.WriteString("  </graph>\n</graphml>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteNodesCSV 以 neo4j-admin import 的节点文件格式输出（id:ID,:LABEL,name,属性...）。
+func (g *Graph) WriteNodesCSV(w io.Writer) error {
+	keys := propKeys(g.nodeProps())
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"id:ID", ":LABEL", "name"}, keys...)); err != nil {
+		return err
+	}
+	for _, n := range g.Nodes {
+		row := []string{n.ID, n.Label, n.Name}
+		for _, k := range keys {
+			row = append(row, n.Props[k])
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteEdgesCSV 以 neo4j-admin import 的关系文件格式输出（:START_ID,:END_ID,:TYPE,属性...）。
+func (g *Graph) WriteEdgesCSV(w io.Writer) error {
+	keys := propKeys(g.edgeProps())
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{":START_ID", ":END_ID", ":TYPE"}, keys...)); err != nil {
+		return err
+	}
+	for _, e := range g.Edges {
+		row := []string{e.Source, e.Target, e.Type}
+		for _, k := range keys {
+			row = append(row, e.Props[k])
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
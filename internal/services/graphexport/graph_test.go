@@ -0,0 +1,74 @@
+package graphexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestBuildSharesEntitiesAcrossDevices(t *testing.T) {
+	devices := []model.CaseDevice{
+		{DeviceID: "dev_host", DeviceName: "laptop", OSType: "windows"},
+		{DeviceID: "dev_vm", DeviceName: "vm:win10", OSType: "windows", ParentDeviceID: "dev_host"},
+	}
+	hits := []model.HitDetail{
+		{HitID: "h1", DeviceID: "dev_host", HitType: "exchange_visited", RuleID: "okx", RuleName: "OKX", MatchedValue: "OKX.com", Confidence: 0.95},
+		{HitID: "h2", DeviceID: "dev_vm", HitType: "exchange_form_activity", RuleID: "okx", RuleName: "OKX", MatchedValue: "okx.com", Confidence: 0.97},
+		{HitID: "h3", DeviceID: "dev_host", HitType: "wallet_address", MatchedValue: "0xABCDEF0123456789abcdef0123456789ABCDEF01", ClusterID: "cl_1"},
+		{HitID: "h4", DeviceID: "dev_vm", HitType: "token_balance", MatchedValue: "0xabcdef0123456789abcdef0123456789abcdef01|USDT"},
+		{HitID: "h5", DeviceID: "dev_host", HitType: "watchlist_match", MatchedValue: "alias<&>"},
+	}
+	g := Build("case_1", devices, hits, []model.AddressCluster{{ClusterID: "cl_1", Size: 1, Addresses: []string{"0xabcdef0123456789abcdef0123456789abcdef01"}}})
+
+	labels := map[string]int{}
+	for _, n := range g.Nodes {
+		labels[n.Label]++
+	}
+	want := map[string]int{LabelCase: 1, LabelDevice: 2, LabelHit: 5, LabelDomain: 1, LabelAddress: 1, LabelCluster: 1, LabelIndicator: 1}
+	for l, c := range want {
+		if labels[l] != c {
+			t.Fatalf("label %s count=%d want %d (all=%v)", l, labels[l], c, labels)
+		}
+	}
+	types := map[string]int{}
+	for _, e := range g.Edges {
+		types[e.Type]++
+	}
+	if types[RelChildOf] != 1 || types[RelRecorded] != 5 || types[RelRefersTo] != 5 || types[RelInCluster] != 1 || types[RelHasDevice] != 2 {
+		t.Fatalf("edge types=%v", types)
+	}
+
+	var gml bytes.Buffer
+	if err := g.WriteGraphML(&gml); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Graph struct {
+			Nodes []struct {
+				ID string `xml:"id,attr"`
+			} `xml:"node"`
+			Edges []struct{} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal(gml.Bytes(), &doc); err != nil {
+		t.Fatalf("graphml not well-formed: %v", err)
+	}
+	if len(doc.Graph.Nodes) != len(g.Nodes) || len(doc.Graph.Edges) != len(g.Edges) {
+		t.Fatalf("graphml nodes=%d edges=%d", len(doc.Graph.Nodes), len(doc.Graph.Edges))
+	}
+
+	var nodes bytes.Buffer
+	if err := g.WriteNodesCSV(&nodes); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&nodes).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(g.Nodes)+1 || rows[0][0] != "id:ID" || rows[0][1] != ":LABEL" {
+		t.Fatalf("nodes csv header=%v rows=%d", rows[0], len(rows))
+	}
+}
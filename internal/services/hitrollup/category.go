@@ -0,0 +1,83 @@
+package hitrollup
+
+import (
+	"sort"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// 按钱包托管类型分组
+//
+// 托管钱包、非托管钱包、硬件钱包配套软件、隐私币钱包的后续调查方向不同（调取平台资料 / 助记词与链上分析 /
+// 扣押实体设备 / 申请查看密钥），报告与命中接口按 wallet_category 把钱包安装/运行命中分组列出。
+
+// ByWalletCategory 把钱包安装/运行命中按托管类型分组；按 model.WalletCategories 的固定顺序输出，
+// 未配置类型的命中归入 uncategorized 排在最后，没有命中的类型不输出。
+func ByWalletCategory(hits []model.HitDetail) []model.WalletCategoryGroup {
+	type acc struct {
+		row       model.WalletCategoryGroup
+		devices   map[string]struct{}
+		ruleIDs   map[string]struct{}
+		ruleNames map[string]struct{}
+	}
+	groups := map[string]*acc{}
+	for _, h := range hits {
+		c := h.WalletCategory
+		if c == "" {
+			c = model.WalletCategoryOf(h.HitType, h.DetailJSON)
+		}
+		if c == "" {
+			continue
+		}
+		g, ok := groups[c]
+		if !ok {
+			g = &acc{
+				row:       model.WalletCategoryGroup{WalletCategory: c},
+				devices:   map[string]struct{}{},
+				ruleIDs:   map[string]struct{}{},
+				ruleNames: map[string]struct{}{},
+			}
+			groups[c] = g
+		}
+		g.row.HitCount++
+		g.row.HitIDs = append(g.row.HitIDs, h.HitID)
+		if h.DeviceID != "" {
+			g.devices[h.DeviceID] = struct{}{}
+		}
+		if h.RuleID != "" {
+			g.ruleIDs[h.RuleID] = struct{}{}
+		}
+		if h.RuleName != "" {
+			g.ruleNames[h.RuleName] = struct{}{}
+		}
+	}
+
+	order := append(model.WalletCategories(), model.WalletCategoryUncategorized)
+	out := make([]model.WalletCategoryGroup, 0, len(groups))
+	emit := func(c string) {
+		g, ok := groups[c]
+		if !ok {
+			return
+		}
+		r := g.row
+		r.DeviceCount = len(g.devices)
+		r.RuleIDs = sortedSet(g.ruleIDs)
+		r.RuleNames = sortedSet(g.ruleNames)
+		sort.Strings(r.HitIDs)
+		out = append(out, r)
+		delete(groups, c)
+	}
+	for _, c := range order {
+		emit(c)
+	}
+	// 规则文件校验会拒绝未知类型；旧数据中残留的其它取值按名称排在最后。
+	rest := make([]string, 0, len(groups))
+	for c := range groups {
+		rest = append(rest, c)
+	}
+	sort.Strings(rest)
+	for _, c := range rest {
+		emit(c)
+	}
+	return out
+}
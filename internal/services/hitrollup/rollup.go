@@ -0,0 +1,121 @@
+package hitrollup
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+)
+
+// 案件级命中汇总
+//
+// scan all 会让电脑和手机各自产生一条“访问了同一交易所”的命中：按设备聚合是正确的（证据链需要逐设备），
+// 但报告摘要里会出现多行几乎相同的记录。这里按 hit_type + rule_id + matched_value 跨设备合并：
+// - 只用于展示层摘要，不修改 rule_hits 表
+// - 每行保留设备列表与 hit_id 列表，可回溯到逐设备明细
+
+// Build 把命中明细按规则与命中值跨设备合并（输入顺序不影响结果）。
+func Build(hits []model.HitDetail) []model.HitRollup {
+	type acc struct {
+		row       model.HitRollup
+		devices   map[string]struct{}
+		artifacts map[string]struct{}
+		verdicts  map[string]struct{}
+	}
+	groups := map[string]*acc{}
+	order := make([]string, 0)
+	for _, h := range hits {
+		key := h.HitType + "\x00" + h.RuleID + "\x00" + strings.TrimSpace(h.MatchedValue)
+		g, ok := groups[key]
+		if !ok {
+			g = &acc{
+				row: model.HitRollup{
+					HitType:      h.HitType,
+					RuleID:       h.RuleID,
+					RuleName:     h.RuleName,
+					MatchedValue: strings.TrimSpace(h.MatchedValue),
+				},
+				devices:   map[string]struct{}{},
+				artifacts: map[string]struct{}{},
+				verdicts:  map[string]struct{}{},
+			}
+			groups[key] = g
+			order = append(order, key)
+		}
+		r := &g.row
+		r.HitCount++
+		r.HitIDs = append(r.HitIDs, h.HitID)
+		if r.RuleName == "" {
+			r.RuleName = h.RuleName
+		}
+		if h.DeviceID != "" {
+			g.devices[h.DeviceID] = struct{}{}
+		}
+		for _, a := range h.ArtifactIDs {
+			g.artifacts[a] = struct{}{}
+		}
+		if v := strings.TrimSpace(h.Verdict); v != "" {
+			g.verdicts[v] = struct{}{}
+		}
+		if h.FirstSeenAt > 0 && (r.FirstSeenAt == 0 || h.FirstSeenAt < r.FirstSeenAt) {
+			r.FirstSeenAt = h.FirstSeenAt
+		}
+		if h.LastSeenAt > r.LastSeenAt {
+			r.LastSeenAt = h.LastSeenAt
+		}
+		if h.Confidence > r.MaxConfidence {
+			r.MaxConfidence = h.Confidence
+		}
+		r.Manual = r.Manual || h.Manual
+	}
+
+	out := make([]model.HitRollup, 0, len(order))
+	for _, key := range order {
+		g := groups[key]
+		r := g.row
+		r.DeviceIDs = sortedSet(g.devices)
+		r.DeviceCount = len(r.DeviceIDs)
+		r.ArtifactCount = len(g.artifacts)
+		r.Verdicts = sortedSet(g.verdicts)
+		sort.Strings(r.HitIDs)
+		out = append(out, r)
+	}
+	// 跨设备出现的排在前面，其次按置信度；同等条件下按规则名与命中值稳定排序。
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.HitType != b.HitType {
+			return a.HitType < b.HitType
+		}
+		if a.DeviceCount != b.DeviceCount {
+			return a.DeviceCount > b.DeviceCount
+		}
+		if a.MaxConfidence != b.MaxConfidence {
+			return a.MaxConfidence > b.MaxConfidence
+		}
+		if a.RuleName != b.RuleName {
+			return a.RuleName < b.RuleName
+		}
+		return a.MatchedValue < b.MatchedValue
+	})
+	return out
+}
+
+// ForCase 读取案件命中并返回汇总（hitType 为空时包含全部类型）。
+func ForCase(ctx context.Context, store *sqliteadapter.Store, caseID, hitType string) ([]model.HitRollup, error) {
+	hits, err := store.ListCaseHitDetails(ctx, caseID, hitType)
+	if err != nil {
+		return nil, err
+	}
+	return Build(hits), nil
+}
+
+func sortedSet(m map[string]struct{}) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,53 @@
+package hitrollup
+
+import (
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestBuildMergesAcrossDevices(t *testing.T) {
+	hits := []model.HitDetail{
+		{HitID: "h1", DeviceID: "laptop", HitType: "exchange_visited", RuleID: "binance", RuleName: "Binance", MatchedValue: "binance.com", FirstSeenAt: 200, LastSeenAt: 300, Confidence: 0.8, Verdict: "suspected", ArtifactIDs: []string{"a1"}},
+		{HitID: "h2", DeviceID: "phone", HitType: "exchange_visited", RuleID: "binance", RuleName: "Binance", MatchedValue: "binance.com ", FirstSeenAt: 100, LastSeenAt: 250, Confidence: 0.9, Verdict: "confirmed", ArtifactIDs: []string{"a2", "a1"}},
+		{HitID: "h3", DeviceID: "phone", HitType: "exchange_visited", RuleID: "okx", RuleName: "OKX", MatchedValue: "okx.com", Confidence: 0.9},
+		{HitID: "h4", DeviceID: "laptop", HitType: "wallet_installed", RuleID: "binance", MatchedValue: "binance.com"},
+	}
+	rows := Build(hits)
+	if len(rows) != 3 {
+		t.Fatalf("rows=%+v", rows)
+	}
+	r := rows[0]
+	if r.RuleID != "binance" || r.HitCount != 2 || r.DeviceCount != 2 || r.ArtifactCount != 2 {
+		t.Fatalf("merged row=%+v", r)
+	}
+	if r.FirstSeenAt != 100 || r.LastSeenAt != 300 || r.MaxConfidence != 0.9 || len(r.Verdicts) != 2 {
+		t.Fatalf("merged row=%+v", r)
+	}
+	if r.HitIDs[0] != "h1" || r.HitIDs[1] != "h2" {
+		t.Fatalf("hit ids=%v", r.HitIDs)
+	}
+	if rows[1].RuleID != "okx" || rows[2].HitType != "wallet_installed" {
+		t.Fatalf("order=%+v", rows)
+	}
+}
+
+func TestByWalletCategory(t *testing.T) {
+	hits := []model.HitDetail{
+		{HitID: "h1", DeviceID: "laptop", HitType: "wallet_installed", RuleID: "monero", RuleName: "Monero GUI Wallet", WalletCategory: "privacy_coin"},
+		{HitID: "h2", DeviceID: "phone", HitType: "wallet_installed", RuleID: "binance", RuleName: "Binance", DetailJSON: `{"wallet_category":"custodial"}`},
+		{HitID: "h3", DeviceID: "laptop", HitType: "wallet_executed", RuleID: "binance", RuleName: "Binance", WalletCategory: "custodial"},
+		{HitID: "h4", DeviceID: "laptop", HitType: "wallet_installed", RuleID: "legacy", RuleName: "Legacy", DetailJSON: `{}`},
+		{HitID: "h5", DeviceID: "laptop", HitType: "exchange_visited", RuleID: "okx", MatchedValue: "okx.com"},
+	}
+	groups := ByWalletCategory(hits)
+	if len(groups) != 3 {
+		t.Fatalf("groups=%+v", groups)
+	}
+	if g := groups[0]; g.WalletCategory != "custodial" || g.HitCount != 2 || g.DeviceCount != 2 || len(g.RuleIDs) != 1 || g.HitIDs[0] != "h2" {
+		t.Fatalf("custodial=%+v", g)
+	}
+	if groups[1].WalletCategory != "privacy_coin" || groups[2].WalletCategory != "uncategorized" {
+		t.Fatalf("order=%+v", groups)
+	}
+}
@@ -0,0 +1,157 @@
+package holdings
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 价格来源配置
+//
+// 生效配置以 YAML 文本存放在 schema_meta（key 见 SchemaKeyConfig），未配置时报告不输出持有汇总。
+// driver 取值：
+//   - static   ：prices 中手工填写的单价（as_of 必填，作为报价时间写入报告）
+//   - coingecko：CoinGecko 兼容 /simple/price 接口（ids 把代币符号映射为 coin id，报价时间取接口返回的 last_updated_at）
+
+// SchemaKeyConfig 是 schema_meta 中保存价格来源配置 YAML 的 key。
+const SchemaKeyConfig = "price_source_yaml"
+
+// BundleType 是配置文件的 bundle_type。
+const BundleType = "price_source"
+
+// 价格来源驱动。
+const (
+	DriverStatic    = "static"
+	DriverCoinGecko = "coingecko"
+)
+
+// DefaultCoinGeckoEndpoint 是 coingecko 驱动未配置 endpoint 时使用的公共端点。
+const DefaultCoinGeckoEndpoint = "https://api.coingecko.com/api/v3"
+
+// Source 描述价格来源。
+type Source struct {
+	Driver   string            `yaml:"driver" json:"driver"`
+	Endpoint string            `yaml:"endpoint,omitempty" json:"endpoint,omitempty"`
+	AsOf     string            `yaml:"as_of,omitempty" json:"as_of,omitempty"`   // static：报价时间（RFC3339）
+	Prices   map[string]string `yaml:"prices,omitempty" json:"prices,omitempty"` // static：符号 -> 单价（十进制字符串）
+	IDs      map[string]string `yaml:"ids,omitempty" json:"ids,omitempty"`       // coingecko：符号 -> coin id
+}
+
+// Config 是完整的价格来源配置。
+type Config struct {
+	Version           string `yaml:"version" json:"version"`
+	BundleType        string `yaml:"bundle_type" json:"bundle_type"`
+	ReferenceCurrency string `yaml:"reference_currency" json:"reference_currency"`
+	Source            Source `yaml:"source" json:"source"`
+}
+
+// Parse 解析并校验配置 YAML（符号统一转为大写）。
+func Parse(raw []byte) (*Config, error) {
+	var c Config
+	if err := yaml.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("parse price source: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	c.ReferenceCurrency = strings.ToUpper(strings.TrimSpace(c.ReferenceCurrency))
+	c.Source.Driver = strings.ToLower(strings.TrimSpace(c.Source.Driver))
+	c.Source.Prices = upperKeys(c.Source.Prices)
+	c.Source.IDs = upperKeys(c.Source.IDs)
+	return &c, nil
+}
+
+// ParseFile 读取并解析配置文件。
+func ParseFile(path string) (*Config, []byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read price source: %w", err)
+	}
+	c, err := Parse(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, raw, nil
+}
+
+// Validate 校验 bundle_type、计价货币与驱动参数。
+func (c *Config) Validate() error {
+	if strings.TrimSpace(c.BundleType) != BundleType {
+		return fmt.Errorf("price source bundle_type must be %q", BundleType)
+	}
+	if strings.TrimSpace(c.ReferenceCurrency) == "" {
+		return fmt.Errorf("reference_currency is required")
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Source.Driver)) {
+	case DriverStatic:
+		if _, err := time.Parse(time.RFC3339, strings.TrimSpace(c.Source.AsOf)); err != nil {
+			return fmt.Errorf("source.as_of must be an RFC3339 timestamp for the static driver")
+		}
+		if len(c.Source.Prices) == 0 {
+			return fmt.Errorf("source.prices is required for the static driver")
+		}
+		for sym, p := range c.Source.Prices {
+			if _, ok := parseDecimal(p); !ok {
+				return fmt.Errorf("source.prices[%s]: invalid price %q", sym, p)
+			}
+		}
+	case DriverCoinGecko:
+		if len(c.Source.IDs) == 0 {
+			return fmt.Errorf("source.ids is required for the coingecko driver")
+		}
+	default:
+		return fmt.Errorf("source.driver must be %s|%s", DriverStatic, DriverCoinGecko)
+	}
+	return nil
+}
+
+// Configured 表示是否配置了价格来源。
+func (c *Config) Configured() bool {
+	return c != nil && c.Source.Driver != ""
+}
+
+// Load 读取生效配置；未配置时返回空配置。
+func Load(ctx context.Context, store *sqliteadapter.Store) (*Config, error) {
+	raw, err := store.GetSchemaMetaValue(ctx, SchemaKeyConfig)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(raw) == "" {
+		return &Config{BundleType: BundleType}, nil
+	}
+	return Parse([]byte(raw))
+}
+
+// Save 校验并保存配置 YAML 为生效配置。
+func Save(ctx context.Context, store *sqliteadapter.Store, raw []byte) (*Config, error) {
+	c, err := Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.UpsertSchemaMetaValue(ctx, SchemaKeyConfig, string(raw)); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Reset 清除生效配置（报告不再输出持有汇总）。
+func Reset(ctx context.Context, store *sqliteadapter.Store) error {
+	return store.UpsertSchemaMetaValue(ctx, SchemaKeyConfig, "")
+}
+
+func upperKeys(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return m
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[strings.ToUpper(strings.TrimSpace(k))] = strings.TrimSpace(v)
+	}
+	return out
+}
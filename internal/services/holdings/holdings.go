@@ -0,0 +1,287 @@
+package holdings
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+)
+
+// 已识别持有汇总
+//
+// 案件对多条链做过余额查询后，token_balance 命中分散在各链/各地址上。这里把它们折算为计价货币并合计：
+//   - 同一网络 + 地址 + 代币（+ 合约）只取最近一次查询结果，避免重复查询被重复累加
+//   - 按代币符号合并数量，乘以价格来源给出的单价；每个单价都记录报价时间与来源
+//   - 查不到单价的代币列入 unpriced，不计入合计（报告中如实列出，不做估算）
+// 汇总只用于报告展示，不修改 rule_hits 表。
+
+// Holding 是单个代币的合计持有。
+type Holding struct {
+	Symbol       string   `json:"symbol"`
+	Networks     []string `json:"networks"`
+	AddressCount int      `json:"address_count"`
+	Amount       string   `json:"amount"`
+	Pair         string   `json:"pair,omitempty"`
+	Price        string   `json:"price,omitempty"`
+	PriceAt      int64    `json:"price_at,omitempty"`
+	PriceSource  string   `json:"price_source,omitempty"`
+	Value        string   `json:"value,omitempty"` // 折算金额（计价货币，保留 2 位小数）
+	HitIDs       []string `json:"hit_ids"`
+}
+
+// Summary 是案件的已识别持有汇总。
+type Summary struct {
+	ReferenceCurrency string    `json:"reference_currency"`
+	PriceSource       string    `json:"price_source"`
+	GeneratedAt       int64     `json:"generated_at"`
+	Holdings          []Holding `json:"holdings"`
+	Total             string    `json:"total"`
+	Unpriced          []string  `json:"unpriced,omitempty"`
+	Warnings          []string  `json:"warnings,omitempty"`
+
+	// PriceSnapshot 是本次折算所用报价固化成的 price_snapshot 证据（仅报告导出时生成）。
+	PriceSnapshot *SnapshotRef `json:"price_snapshot,omitempty"`
+
+	quotes *QuoteSet
+}
+
+type balanceDetail struct {
+	Kind     string            `json:"kind"`
+	Symbol   string            `json:"symbol"`
+	Address  string            `json:"address"`
+	Balances map[string]string `json:"balances"`
+	Query    map[string]any    `json:"query"`
+}
+
+type position struct {
+	hitID   string
+	seenAt  int64
+	network string
+	address string
+	symbol  string
+	amount  *big.Rat
+}
+
+// Aggregate 汇总 token_balance 命中并按价格来源折算（其他类型命中被忽略）。
+// 价格来源查询失败时不中断：全部代币列入 unpriced，并在 warnings 中说明原因。
+func Aggregate(ctx context.Context, cfg *Config, src PriceSource, hits []model.HitDetail, now int64) *Summary {
+	latest := map[string]position{}
+	for _, h := range hits {
+		if h.HitType != string(model.HitTokenBalance) {
+			continue
+		}
+		p, key, ok := parsePosition(h)
+		if !ok {
+			continue
+		}
+		if cur, exists := latest[key]; exists && (cur.seenAt > p.seenAt || (cur.seenAt == p.seenAt && cur.hitID > p.hitID)) {
+			continue
+		}
+		latest[key] = p
+	}
+
+	type acc struct {
+		amount    *big.Rat
+		networks  map[string]struct{}
+		addresses map[string]struct{}
+		hitIDs    []string
+	}
+	groups := map[string]*acc{}
+	for _, p := range latest {
+		g, ok := groups[p.symbol]
+		if !ok {
+			g = &acc{amount: new(big.Rat), networks: map[string]struct{}{}, addresses: map[string]struct{}{}}
+			groups[p.symbol] = g
+		}
+		g.amount.Add(g.amount, p.amount)
+		g.networks[p.network] = struct{}{}
+		g.addresses[p.network+"\x00"+p.address] = struct{}{}
+		g.hitIDs = append(g.hitIDs, p.hitID)
+	}
+	symbols := make([]string, 0, len(groups))
+	for sym := range groups {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+
+	out := &Summary{
+		ReferenceCurrency: cfg.ReferenceCurrency,
+		PriceSource:       cfg.Source.Driver,
+		GeneratedAt:       now,
+		Holdings:          []Holding{},
+	}
+	quotes := map[string]Quote{}
+	if len(symbols) > 0 {
+		qs, err := src.Quotes(ctx, symbols)
+		if err != nil {
+			out.Warnings = append(out.Warnings, "price source query failed: "+err.Error())
+		} else {
+			quotes = qs.Quotes
+			out.quotes = qs
+		}
+	}
+
+	total := new(big.Rat)
+	values := map[string]*big.Rat{}
+	for _, sym := range symbols {
+		g := groups[sym]
+		sort.Strings(g.hitIDs)
+		row := Holding{
+			Symbol:       sym,
+			Networks:     sortedKeys(g.networks),
+			AddressCount: len(g.addresses),
+			Amount:       formatDecimal(g.amount, 18),
+			HitIDs:       g.hitIDs,
+		}
+		q, ok := quotes[sym]
+		price, priceOK := parseDecimal(q.Price)
+		if ok && priceOK {
+			v := new(big.Rat).Mul(g.amount, price)
+			values[sym] = v
+			total.Add(total, v)
+			row.Pair = q.Pair
+			row.Price = q.Price
+			row.PriceAt = q.PriceAt
+			row.PriceSource = q.Source
+			row.Value = v.FloatString(2)
+		} else {
+			out.Unpriced = append(out.Unpriced, sym)
+		}
+		out.Holdings = append(out.Holdings, row)
+	}
+	// 按折算金额降序；未定价的排在最后（按符号）。
+	sort.SliceStable(out.Holdings, func(i, j int) bool {
+		vi, vj := values[out.Holdings[i].Symbol], values[out.Holdings[j].Symbol]
+		switch {
+		case vi != nil && vj != nil:
+			return vi.Cmp(vj) > 0
+		case vi != nil || vj != nil:
+			return vi != nil
+		default:
+			return false
+		}
+	})
+	out.Total = total.FloatString(2)
+	return out
+}
+
+// Options 控制 ForCase 的审计与证据固化。
+type Options struct {
+	Operator string
+	Source   string // 审计 source（调用方函数名）
+	// EvidenceRoot 非空时把本次使用的报价固化为 price_snapshot 证据（报告导出使用；界面查看不固化）。
+	EvidenceRoot string
+}
+
+// ForCase 按生效价格来源汇总案件持有；未配置价格来源时返回 nil。
+// 每次折算都写一条 holdings/price_quote 审计，记录所用单价与报价时间。
+func ForCase(ctx context.Context, store *sqliteadapter.Store, caseID string, opts Options) (*Summary, error) {
+	cfg, err := Load(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Configured() {
+		return nil, nil
+	}
+	src, err := NewPriceSource(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	hits, err := store.ListCaseHitDetails(ctx, caseID, string(model.HitTokenBalance))
+	if err != nil {
+		return nil, err
+	}
+	operator := strings.TrimSpace(opts.Operator)
+	if operator == "" {
+		operator = "system"
+	}
+	sum := Aggregate(ctx, cfg, src, hits, time.Now().Unix())
+	if strings.TrimSpace(opts.EvidenceRoot) != "" && sum.quotes != nil && len(sum.quotes.Quotes) > 0 {
+		ref, err := saveSnapshot(ctx, store, opts.EvidenceRoot, caseID, operator, sum)
+		if err != nil {
+			sum.Warnings = append(sum.Warnings, "save price snapshot failed: "+err.Error())
+		} else {
+			sum.PriceSnapshot = ref
+		}
+	}
+
+	status := "success"
+	if len(sum.Warnings) > 0 {
+		status = "failed"
+	}
+	quotes := make([]map[string]any, 0, len(sum.Holdings))
+	for _, h := range sum.Holdings {
+		if h.Price == "" {
+			continue
+		}
+		quotes = append(quotes, map[string]any{"pair": h.Pair, "price": h.Price, "price_at": h.PriceAt, "source": h.PriceSource})
+	}
+	detail := map[string]any{
+		"reference_currency": sum.ReferenceCurrency,
+		"price_source":       sum.PriceSource,
+		"quotes":             quotes,
+		"total":              sum.Total,
+		"unpriced":           sum.Unpriced,
+		"warnings":           sum.Warnings,
+	}
+	if sum.PriceSnapshot != nil {
+		detail["price_snapshot_artifact_id"] = sum.PriceSnapshot.ArtifactID
+		detail["price_snapshot_sha256"] = sum.PriceSnapshot.SHA256
+	}
+	_ = store.AppendAudit(ctx, caseID, "", "holdings", "price_quote", status, operator, opts.Source, detail)
+	return sum, nil
+}
+
+func parsePosition(h model.HitDetail) (position, string, bool) {
+	var d balanceDetail
+	if err := json.Unmarshal([]byte(h.DetailJSON), &d); err != nil {
+		return position{}, "", false
+	}
+	symbol := strings.ToUpper(strings.TrimSpace(d.Symbol))
+	if symbol == "" || strings.TrimSpace(d.Address) == "" {
+		return position{}, "", false
+	}
+	raw, ok := d.Balances[d.Symbol]
+	if !ok {
+		raw, ok = d.Balances[symbol]
+	}
+	amount, valid := parseDecimal(raw)
+	if !ok || !valid {
+		return position{}, "", false
+	}
+	network := firstString(d.Query, "network", "chain")
+	if network == "" {
+		network = d.Kind
+	}
+	contract := strings.ToLower(firstString(d.Query, "contract"))
+	address := strings.TrimSpace(d.Address)
+	seenAt := h.LastSeenAt
+	if seenAt == 0 {
+		seenAt = h.FirstSeenAt
+	}
+	key := strings.Join([]string{network, strings.ToLower(address), symbol, contract}, "\x00")
+	return position{hitID: h.HitID, seenAt: seenAt, network: network, address: address, symbol: symbol, amount: amount}, key, true
+}
+
+func firstString(m map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := m[k].(string); ok && strings.TrimSpace(s) != "" {
+			return strings.TrimSpace(s)
+		}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
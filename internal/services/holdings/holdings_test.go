@@ -0,0 +1,123 @@
+package holdings
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+
+	_ "modernc.org/sqlite"
+)
+
+func balanceHit(id, network, addr, symbol, amount string, seenAt int64) model.HitDetail {
+	return model.HitDetail{
+		HitID:      id,
+		HitType:    string(model.HitTokenBalance),
+		LastSeenAt: seenAt,
+		DetailJSON: `{"kind":"k","symbol":"` + symbol + `","address":"` + addr + `","balances":{"` + symbol + `":"` + amount + `"},"query":{"network":"` + network + `"}}`,
+	}
+}
+
+func TestAggregateCoinGecko(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/simple/price" || r.URL.Query().Get("vs_currencies") != "usd" || r.URL.Query().Get("ids") != "bitcoin,tether" {
+			t.Errorf("unexpected request: %s", r.URL.String())
+		}
+		_, _ = w.Write([]byte(`{"bitcoin":{"usd":60000.5,"last_updated_at":1760000000},"tether":{"usd":1.0001,"last_updated_at":1760000100}}`))
+	}))
+	defer srv.Close()
+
+	cfg, err := Parse([]byte("bundle_type: price_source\nreference_currency: usd\nsource:\n  driver: coingecko\n  endpoint: " + srv.URL + "\n  ids:\n    btc: bitcoin\n    usdt: tether\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := NewPriceSource(cfg, srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hits := []model.HitDetail{
+		balanceHit("h1", "bitcoin", "bc1qaaa", "BTC", "0.5", 100),
+		balanceHit("h2", "bitcoin", "bc1qaaa", "BTC", "0.7", 200), // 同地址较新的查询覆盖旧值
+		balanceHit("h3", "bitcoin", "bc1qbbb", "BTC", "0.3", 100),
+		balanceHit("h4", "tron", "TXyz", "USDT", "1000", 100),
+		balanceHit("h5", "ethereum", "0xabc", "USDT", "500", 100),
+		balanceHit("h6", "ethereum", "0xabc", "PEPE", "1", 100),
+		{HitID: "h7", HitType: string(model.HitWalletAddress), MatchedValue: "0xabc"},
+	}
+	sum := Aggregate(context.Background(), cfg, src, hits, 1)
+	if sum.ReferenceCurrency != "USD" || len(sum.Holdings) != 3 || len(sum.Warnings) != 0 {
+		t.Fatalf("summary=%+v", sum)
+	}
+	btc := sum.Holdings[0]
+	if btc.Symbol != "BTC" || btc.Amount != "1" || btc.Value != "60000.50" || btc.AddressCount != 2 || btc.PriceAt != 1760000000 {
+		t.Fatalf("btc=%+v", btc)
+	}
+	usdt := sum.Holdings[1]
+	if usdt.Amount != "1500" || usdt.Value != "1500.15" || len(usdt.Networks) != 2 {
+		t.Fatalf("usdt=%+v", usdt)
+	}
+	if sum.Total != "61500.65" || len(sum.Unpriced) != 1 || sum.Unpriced[0] != "PEPE" || sum.Holdings[2].Value != "" {
+		t.Fatalf("total=%s unpriced=%v", sum.Total, sum.Unpriced)
+	}
+}
+
+func TestParseRejectsStaticWithoutTimestamp(t *testing.T) {
+	if _, err := Parse([]byte("bundle_type: price_source\nreference_currency: USD\nsource:\n  driver: static\n  prices:\n    BTC: \"60000\"\n")); err == nil {
+		t.Fatal("expected error for static prices without as_of")
+	}
+}
+
+func TestForCaseSavesPriceSnapshot(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "host", OS: model.OSType("windows"), Identifier: "h"}, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveRuleHits(ctx, []model.RuleHit{{
+		ID: "hit_1", CaseID: caseID, DeviceID: "dev_1", Type: model.HitTokenBalance, RuleID: "chain_balance_btc",
+		MatchedValue: "bc1qaaa|BTC", Confidence: 0.95, Verdict: "confirmed", LastSeenAt: 100,
+		DetailJSON: []byte(`{"kind":"btc","symbol":"BTC","address":"bc1qaaa","balances":{"SAT":"50000000","BTC":"0.5"},"query":{"chain":"btc"}}`),
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Save(ctx, store, []byte("bundle_type: price_source\nreference_currency: USD\nsource:\n  driver: static\n  as_of: \"2026-10-01T00:00:00Z\"\n  prices:\n    BTC: \"60000\"\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	sum, err := ForCase(ctx, store, caseID, Options{Operator: "alice", Source: "test", EvidenceRoot: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Total != "30000.00" || sum.Holdings[0].Pair != "BTC/USD" || sum.PriceSnapshot == nil {
+		t.Fatalf("summary=%+v", sum)
+	}
+	arts, err := store.ListArtifactsByCase(ctx, caseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(arts) != 1 || arts[0].ArtifactType != string(model.ArtifactPriceSnapshot) || arts[0].ArtifactID != sum.PriceSnapshot.ArtifactID {
+		t.Fatalf("artifacts=%+v", arts)
+	}
+	if got, _, err := hash.File(arts[0].SnapshotPath); err != nil || got != sum.PriceSnapshot.SHA256 {
+		t.Fatalf("snapshot sha256=%s err=%v want %s", got, err, sum.PriceSnapshot.SHA256)
+	}
+}
@@ -0,0 +1,182 @@
+package holdings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Quote 是单个代币的报价（单价为十进制字符串，避免浮点误差进入报告）。
+type Quote struct {
+	Symbol  string `json:"symbol"`
+	Pair    string `json:"pair"` // 交易对，例如 BTC/USD
+	Price   string `json:"price"`
+	PriceAt int64  `json:"price_at"` // 报价时间（unix 秒）
+	Source  string `json:"source"`
+}
+
+// QuoteSet 是一次报价查询的结果，连同来源接口与原始响应一起固化为 price_snapshot 证据。
+type QuoteSet struct {
+	Quotes    map[string]Quote
+	Endpoint  string          // 请求地址；static 驱动为 "static"
+	FetchedAt int64           // 查询时间（unix 秒）
+	Response  json.RawMessage // 原始响应；static 驱动为配置中的单价表
+}
+
+// PriceSource 按符号批量查询以计价货币表示的单价；查不到的符号不出现在结果中。
+type PriceSource interface {
+	Quotes(ctx context.Context, symbols []string) (*QuoteSet, error)
+}
+
+// NewPriceSource 按配置构造价格来源；client 为空时使用默认超时的 http.Client。
+func NewPriceSource(c *Config, client *http.Client) (PriceSource, error) {
+	switch c.Source.Driver {
+	case DriverStatic:
+		at, err := time.Parse(time.RFC3339, strings.TrimSpace(c.Source.AsOf))
+		if err != nil {
+			return nil, fmt.Errorf("invalid source.as_of: %w", err)
+		}
+		return staticSource{currency: c.ReferenceCurrency, prices: c.Source.Prices, at: at.Unix()}, nil
+	case DriverCoinGecko:
+		endpoint := strings.TrimSpace(c.Source.Endpoint)
+		if endpoint == "" {
+			endpoint = DefaultCoinGeckoEndpoint
+		}
+		if client == nil {
+			client = &http.Client{Timeout: 12 * time.Second}
+		}
+		return coinGeckoSource{endpoint: endpoint, currency: strings.ToLower(c.ReferenceCurrency), ids: c.Source.IDs, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown price source driver: %s", c.Source.Driver)
+	}
+}
+
+type staticSource struct {
+	currency string
+	prices   map[string]string
+	at       int64
+}
+
+func (s staticSource) Quotes(_ context.Context, symbols []string) (*QuoteSet, error) {
+	out := map[string]Quote{}
+	for _, sym := range symbols {
+		if p, ok := s.prices[sym]; ok {
+			out[sym] = Quote{Symbol: sym, Pair: sym + "/" + s.currency, Price: p, PriceAt: s.at, Source: DriverStatic}
+		}
+	}
+	raw, err := json.Marshal(map[string]any{"as_of": s.at, "prices": s.prices})
+	if err != nil {
+		return nil, err
+	}
+	return &QuoteSet{Quotes: out, Endpoint: DriverStatic, FetchedAt: time.Now().Unix(), Response: raw}, nil
+}
+
+type coinGeckoSource struct {
+	endpoint string
+	currency string
+	ids      map[string]string
+	client   *http.Client
+}
+
+// Quotes 调用 /simple/price?ids=...&vs_currencies=...&include_last_updated_at=true。
+func (s coinGeckoSource) Quotes(ctx context.Context, symbols []string) (*QuoteSet, error) {
+	idSet := map[string]struct{}{}
+	for _, sym := range symbols {
+		if id := s.ids[sym]; id != "" {
+			idSet[id] = struct{}{}
+		}
+	}
+	out := map[string]Quote{}
+	if len(idSet) == 0 {
+		return &QuoteSet{Quotes: out, FetchedAt: time.Now().Unix()}, nil
+	}
+	ids := make([]string, 0, len(idSet))
+	for id := range idSet {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	q := url.Values{}
+	q.Set("ids", strings.Join(ids, ","))
+	q.Set("vs_currencies", s.currency)
+	q.Set("include_last_updated_at", "true")
+	u := strings.TrimRight(s.endpoint, "/") + "/simple/price?" + q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	fetchedAt := time.Now().Unix()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 2<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("price source http %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+
+	// 单价按 json.Number 解码，保留接口返回的原始十进制表示。
+	var body map[string]map[string]json.Number
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+	for _, sym := range symbols {
+		row, ok := body[s.ids[sym]]
+		if !ok {
+			continue
+		}
+		price, ok := row[s.currency]
+		if !ok {
+			continue
+		}
+		r, ok := parseDecimal(price.String())
+		if !ok {
+			continue
+		}
+		at, _ := row["last_updated_at"].Int64()
+		out[sym] = Quote{
+			Symbol:  sym,
+			Pair:    sym + "/" + strings.ToUpper(s.currency),
+			Price:   formatDecimal(r, 8),
+			PriceAt: at,
+			Source:  DriverCoinGecko + ":" + s.ids[sym],
+		}
+	}
+	return &QuoteSet{Quotes: out, Endpoint: u, FetchedAt: fetchedAt, Response: json.RawMessage(b)}, nil
+}
+
+// parseDecimal 解析十进制字符串（支持科学计数法，例如 CoinGecko 对极小单价返回的 1.2e-05）。
+func parseDecimal(s string) (*big.Rat, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, false
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() < 0 {
+		return nil, false
+	}
+	return r, true
+}
+
+// formatDecimal 以最多 prec 位小数输出，并去掉末尾多余的 0。
+func formatDecimal(r *big.Rat, prec int) string {
+	s := r.FloatString(prec)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
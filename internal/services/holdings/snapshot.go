@@ -0,0 +1,151 @@
+package holdings
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 报价快照证据
+//
+// 报告中的折算金额经常在庭审中被质疑估值口径。每次导出报告时，把本次实际使用的报价
+// （来源接口、查询时间、交易对、单价、报价时间与原始响应）写成一份独立的 price_snapshot 证据并计算 sha256，
+// 报告中引用该证据，事后可以离线复核“用的是哪个价格、何时取得”。
+
+// SnapshotVersion 是 price_snapshot payload 的格式版本。
+const SnapshotVersion = "price-snapshot-0.1.0"
+
+// SnapshotRef 指向一份 price_snapshot 证据。
+type SnapshotRef struct {
+	ArtifactID string `json:"artifact_id"`
+	SHA256     string `json:"sha256"`
+	Endpoint   string `json:"endpoint"`
+	FetchedAt  int64  `json:"fetched_at"`
+}
+
+// Snapshot 是 price_snapshot 证据的 payload。
+type Snapshot struct {
+	Kind              string          `json:"kind"`
+	Version           string          `json:"version"`
+	CaseID            string          `json:"case_id"`
+	ReferenceCurrency string          `json:"reference_currency"`
+	Driver            string          `json:"driver"`
+	Endpoint          string          `json:"endpoint"`
+	FetchedAt         int64           `json:"fetched_at"`
+	Quotes            []Quote         `json:"quotes"`
+	Response          json.RawMessage `json:"response,omitempty"`
+}
+
+// saveSnapshot 把汇总所用的报价写入证据目录并落库为 price_snapshot 证据。
+func saveSnapshot(ctx context.Context, store *sqliteadapter.Store, evidenceRoot, caseID, operator string, sum *Summary) (*SnapshotRef, error) {
+	devices, err := store.ListCaseDevices(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	if len(devices) == 0 {
+		return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("case has no devices: %s", caseID))
+	}
+	// 报价快照是案件级的，与 analysis 证据一样挂到案件本机设备（否则第一台设备）上。
+	deviceID := devices[0].DeviceID
+	for _, d := range devices {
+		if strings.TrimSpace(d.ConnectionType) == "local" {
+			deviceID = d.DeviceID
+			break
+		}
+	}
+
+	qs := sum.quotes
+	snap := Snapshot{
+		Kind:              string(model.ArtifactPriceSnapshot),
+		Version:           SnapshotVersion,
+		CaseID:            caseID,
+		ReferenceCurrency: sum.ReferenceCurrency,
+		Driver:            sum.PriceSource,
+		Endpoint:          qs.Endpoint,
+		FetchedAt:         qs.FetchedAt,
+		Quotes:            make([]Quote, 0, len(qs.Quotes)),
+	}
+	for _, q := range qs.Quotes {
+		snap.Quotes = append(snap.Quotes, q)
+	}
+	sort.Slice(snap.Quotes, func(i, j int) bool { return snap.Quotes[i].Symbol < snap.Quotes[j].Symbol })
+	if json.Valid(qs.Response) {
+		snap.Response = qs.Response
+	}
+	raw, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal price snapshot: %w", err)
+	}
+
+	artifactID := id.New("art")
+	dir := filepath.Join(evidenceRoot, caseID, deviceID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create evidence dir: %w", err)
+	}
+	snapshotPath := filepath.Join(dir, fmt.Sprintf("price_snapshot_%s.json", artifactID))
+	if err := os.WriteFile(snapshotPath, raw, 0o644); err != nil {
+		return nil, fmt.Errorf("write evidence file: %w", err)
+	}
+	sum256, size, err := hash.File(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("hash evidence file: %w", err)
+	}
+
+	collectorName := "holdings"
+	collectorVer := "holdings-" + strings.TrimSpace(app.Version)
+	if strings.TrimSpace(app.Version) == "" {
+		collectorVer = "holdings-dev"
+	}
+	art := model.Artifact{
+		ID:                artifactID,
+		CaseID:            caseID,
+		DeviceID:          deviceID,
+		Type:              model.ArtifactPriceSnapshot,
+		SourceRef:         qs.Endpoint,
+		SnapshotPath:      snapshotPath,
+		SHA256:            sum256,
+		SizeBytes:         size,
+		CollectedAt:       qs.FetchedAt,
+		CollectorName:     collectorName,
+		CollectorVersion:  collectorVer,
+		ParserVersion:     SnapshotVersion,
+		AcquisitionMethod: "price_api",
+		PayloadJSON:       raw,
+		RecordHash: hash.Text(
+			artifactID,
+			caseID,
+			deviceID,
+			string(model.ArtifactPriceSnapshot),
+			qs.Endpoint,
+			snapshotPath,
+			sum256,
+			fmt.Sprintf("%d", size),
+			fmt.Sprintf("%d", qs.FetchedAt),
+			collectorName,
+			collectorVer,
+			string(raw),
+		),
+	}
+	if err := store.SaveArtifacts(ctx, []model.Artifact{art}); err != nil {
+		return nil, err
+	}
+	_ = store.AppendAudit(ctx, caseID, deviceID, "holdings", "price_snapshot", "success", operator, "holdings.saveSnapshot", map[string]any{
+		"artifact_id": artifactID,
+		"sha256":      sum256,
+		"endpoint":    qs.Endpoint,
+		"fetched_at":  qs.FetchedAt,
+		"quotes":      len(snap.Quotes),
+	})
+	return &SnapshotRef{ArtifactID: artifactID, SHA256: sum256, Endpoint: qs.Endpoint, FetchedAt: qs.FetchedAt}, nil
+}
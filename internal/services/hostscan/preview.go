@@ -0,0 +1,221 @@
+package hostscan
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/adapters/host"
+	"crypto-inspector/internal/adapters/mobile"
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/trace"
+	"crypto-inspector/internal/services/authdoc"
+	"crypto-inspector/internal/services/casestorage"
+	"crypto-inspector/internal/services/devicedup"
+	"crypto-inspector/internal/services/precheckpolicy"
+)
+
+// PreviewResult 是 scan host --dry-run 的输出：前置检查 + 采集范围预览。
+type PreviewResult struct {
+	DryRun           bool   `json:"dry_run"`
+	CaseID           string `json:"case_id,omitempty"`
+	DeviceName       string `json:"device_name"`
+	DeviceOS         string `json:"device_os"`
+	DeviceIdentifier string `json:"device_identifier,omitempty"`
+
+	Prechecks []model.PrecheckResult `json:"prechecks"`
+	// Blocked 非空表示正式扫描会被该检查（必需项或策略）中止。
+	Blocked *model.PrecheckResult `json:"blocked,omitempty"`
+
+	Preview *host.ScanPreview `json:"preview"`
+	// MobileDevices 为当前连接的 Android/iOS 设备（adb devices / idevice_id -l，工具缺失时为空）。
+	MobileDevices []mobile.DeviceState `json:"mobile_devices"`
+	// IOSBackupEstimates 为已配对 iOS 设备完整备份的估算字节数（UDID -> bytes，未知为 0）。
+	IOSBackupEstimates map[string]int64      `json:"ios_backup_estimates,omitempty"`
+	DuplicateCases     []model.DeviceCaseRef `json:"duplicate_cases,omitempty"`
+
+	// EstimatedBytes 为需原样复制的来源总量（历史库 + iOS 备份估算）；超过 BudgetLimitBytes 时 OverBudget 为 true。
+	EstimatedBytes   int64    `json:"estimated_bytes"`
+	BudgetLimitBytes int64    `json:"budget_limit_bytes,omitempty"`
+	OverBudget       bool     `json:"over_budget,omitempty"`
+	Warnings         []string `json:"warnings,omitempty"`
+	GeneratedAt      int64    `json:"generated_at"`
+}
+
+// Preview 执行主机扫描的预演：只跑前置检查并枚举将要采集的来源，不写证据、不保存检查结果、不建案。
+//
+// 数据库仅用于读取授权文书、策略、配额与设备登记（迁移照常执行）；
+// 证据目录不存在时不创建，可写检查记为 skipped。
+func Preview(ctx context.Context, opts Options) (_ *PreviewResult, retErr error) {
+	ctx, span := trace.Start(ctx, "hostscan.Preview")
+	defer func() { span.End(retErr) }()
+
+	defaults := app.DefaultConfig()
+	if opts.DBPath == "" {
+		opts.DBPath = defaults.DBPath
+	}
+	if opts.EvidenceRoot == "" {
+		opts.EvidenceRoot = "data/evidence"
+	}
+	opts.AuthorizationOrder = strings.TrimSpace(opts.AuthorizationOrder)
+	opts.AuthorizationBasis = strings.TrimSpace(opts.AuthorizationBasis)
+	if strings.TrimSpace(opts.OfflineInputDir) != "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "dry-run is only supported for online host scans")
+	}
+	scanType := "host_scan"
+
+	if err := os.MkdirAll(filepath.Dir(opts.DBPath), 0o755); err != nil {
+		return nil, fmt.Errorf("create db directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", opts.DBPath)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.ExecContext(ctx, `PRAGMA busy_timeout = 5000`); err != nil {
+		return nil, fmt.Errorf("set busy_timeout: %w", err)
+	}
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		return nil, fmt.Errorf("apply migrations: %w", err)
+	}
+	store := sqliteadapter.NewStore(db)
+
+	caseID := strings.TrimSpace(opts.CaseID)
+	if caseID != "" {
+		ov, err := store.GetCaseOverview(ctx, caseID)
+		if err != nil {
+			return nil, err
+		}
+		if ov == nil {
+			return nil, apperr.T(apperr.CodeNotFound, "case.not_found", caseID)
+		}
+	}
+	policy, err := precheckpolicy.Load(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &PreviewResult{DryRun: true, CaseID: caseID, MobileDevices: []mobile.DeviceState{}}
+	prechecks, err := authdoc.Prechecks(ctx, store, policy, caseID, opts.AuthorizationOrder, opts.AuthorizationBasis, opts.RequireAuthOrder)
+	if err != nil {
+		return nil, err
+	}
+	writable := model.PrecheckResult{
+		CaseID:     caseID,
+		ScanScope:  "host",
+		CheckCode:  "evidence_dir_writable",
+		CheckName:  "证据目录可写",
+		Required:   true,
+		Status:     model.PrecheckPassed,
+		Message:    "ok",
+		CheckedAt:  time.Now().Unix(),
+		DetailJSON: mustJSON(map[string]any{"evidence_root": opts.EvidenceRoot}),
+	}
+	if st, err := os.Stat(opts.EvidenceRoot); err != nil || !st.IsDir() {
+		writable.Status, writable.Message = model.PrecheckSkipped, "evidence root does not exist yet (created on scan)"
+	} else if err := precheckWritable(opts.EvidenceRoot); err != nil {
+		writable.Status, writable.Message = model.PrecheckFailed, err.Error()
+	}
+	prechecks = append(prechecks, writable)
+	if caseID != "" {
+		quotaCheck, quotaWarning, _ := casestorage.Precheck(ctx, store, caseID, "general")
+		prechecks = append(prechecks, quotaCheck)
+		if quotaWarning != "" {
+			out.Warnings = append(out.Warnings, quotaWarning)
+		}
+	}
+
+	device, err := host.DetectHostDevice()
+	osCheck := model.PrecheckResult{
+		CaseID:     caseID,
+		DeviceID:   device.ID,
+		ScanScope:  "host",
+		CheckCode:  "host_os_supported",
+		CheckName:  "主机操作系统受支持",
+		Required:   true,
+		Status:     model.PrecheckPassed,
+		Message:    string(device.OS),
+		CheckedAt:  time.Now().Unix(),
+		DetailJSON: mustJSON(map[string]any{"device_name": device.Name, "identifier": device.Identifier}),
+	}
+	if err != nil {
+		osCheck.Status, osCheck.Message, osCheck.DetailJSON = model.PrecheckFailed, err.Error(), mustJSON(map[string]any{})
+	}
+	prechecks = append(prechecks, osCheck)
+	out.DeviceName, out.DeviceOS, out.DeviceIdentifier = device.Name, string(device.OS), device.Identifier
+
+	if err == nil {
+		dupCheck, dupCases, dupWarning := devicedup.Precheck(ctx, store, caseID, "host", device)
+		prechecks = append(prechecks, dupCheck)
+		out.DuplicateCases = dupCases
+		encCheck, encWarning := encryptionPrecheck(ctx, caseID, device, false)
+		prechecks = append(prechecks, encCheck)
+		elevCheck, elevWarning := elevationPrecheck(ctx, caseID, device, false)
+		prechecks = append(prechecks, elevCheck)
+		fdaWarning := ""
+		if device.OS == model.OSMacOS {
+			var fdaCheck model.PrecheckResult
+			fdaCheck, fdaWarning = fullDiskAccessPrecheck(caseID, device)
+			prechecks = append(prechecks, fdaCheck)
+		}
+		for _, w := range []string{dupWarning, encWarning, elevWarning, fdaWarning} {
+			if w != "" {
+				out.Warnings = append(out.Warnings, w)
+			}
+		}
+
+		if out.Preview, err = host.PreviewScan(ctx, device); err != nil {
+			out.Warnings = append(out.Warnings, "preview sources failed: "+err.Error())
+		}
+	}
+	states, warnings := mobile.Probe(ctx, true, true, true)
+	out.MobileDevices = states
+	out.Warnings = append(out.Warnings, warnings...)
+	for _, st := range states {
+		if st.OS == model.OSIOS && st.Authorized {
+			if out.IOSBackupEstimates == nil {
+				out.IOSBackupEstimates = map[string]int64{}
+			}
+			out.IOSBackupEstimates[st.Identifier] = mobile.EstimateIOSBackupBytes(ctx, st.Identifier)
+		}
+	}
+
+	// 采集预算：取消勾选的历史库不计入估算。
+	if out.Preview != nil && !opts.SkipHistoryDB {
+		out.EstimatedBytes += out.Preview.EstimatedBytes
+	}
+	for _, n := range out.IOSBackupEstimates {
+		out.EstimatedBytes += n
+	}
+	if sum := opts.Budget.Since(0); sum != nil && sum.LimitBytes > 0 {
+		out.BudgetLimitBytes = sum.LimitBytes
+		if out.EstimatedBytes > sum.LimitBytes-sum.UsedBytes {
+			out.OverBudget = true
+			out.Warnings = append(out.Warnings, fmt.Sprintf("estimated %d bytes exceeds collection budget %d bytes; heavy sources will be skipped (consider --skip-history-db / --ios-full-backup=false)", out.EstimatedBytes, sum.LimitBytes))
+		}
+	}
+
+	prechecks = append(prechecks, policy.Missing(scanType, caseID, prechecks)...)
+	for i := range prechecks {
+		if c := prechecks[i]; c.Required && c.Status == model.PrecheckFailed {
+			out.Blocked = &prechecks[i]
+			break
+		}
+	}
+	if out.Blocked == nil {
+		blocked, policyWarnings := policy.Gate(scanType, prechecks)
+		out.Blocked = blocked
+		out.Warnings = append(out.Warnings, policyWarnings...)
+	}
+	out.Prechecks = prechecks
+	out.GeneratedAt = time.Now().Unix()
+	return out, nil
+}
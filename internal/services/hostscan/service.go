@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,20 +15,34 @@ import (
 	"crypto-inspector/internal/adapters/rules"
 	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
 	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
 	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/budget"
 	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/snapshot"
+	"crypto-inspector/internal/platform/trace"
+	"crypto-inspector/internal/services/addrcluster"
+	"crypto-inspector/internal/services/authdoc"
+	"crypto-inspector/internal/services/casestorage"
+	"crypto-inspector/internal/services/devicedup"
 	"crypto-inspector/internal/services/matcher"
+	"crypto-inspector/internal/services/nameresolve"
+	"crypto-inspector/internal/services/precheckpolicy"
 	"crypto-inspector/internal/services/privacy"
+	"crypto-inspector/internal/services/reportjson"
 
 	_ "modernc.org/sqlite"
 )
 
 // Options 定义一次主机扫描的输入参数。
 type Options struct {
-	DBPath             string
-	EvidenceRoot       string
-	WalletRulePath     string
-	ExchangeRulePath   string
+	DBPath           string
+	EvidenceRoot     string
+	WalletRulePath   string
+	ExchangeRulePath string
+	RegexRulePath    string // 可选的自定义正则规则文件（不存在时不启用）
+	// RulesCache 非空时通过共享缓存加载规则（serve 模式），为空时每次从磁盘加载。
+	RulesCache         *rules.Cache
 	CaseID             string
 	Operator           string
 	Note               string
@@ -35,6 +50,30 @@ type Options struct {
 	AuthorizationBasis string
 	RequireAuthOrder   bool
 	PrivacyMode        string
+	// Profile 是扫描 profile（internal|external，空视为 internal），记录在扫描参数快照中。
+	Profile string
+
+	// ETHRPCURL / BNBRPCURL 用于解析浏览痕迹中的 .eth / .bnb 域名（为空则只记录域名，不做解析）。
+	ETHRPCURL string
+	BNBRPCURL string
+
+	// OfflineInputDir 非空时为离线模式：不采集本机，而是解析该目录下拷贝来的浏览器 profile / 注册表 hive。
+	OfflineInputDir string
+	OfflineOS       string // 离线数据所属系统 windows|macos（为空时按目录内容推断）
+	DeviceName      string // 离线模式的设备显示名（默认取目录名）
+	// ParentDeviceID 非空时（仅离线模式）把本次设备登记为该设备的子设备，例如宿主机上发现的 VM 镜像。
+	ParentDeviceID string
+
+	// SnapshotCompression 是 JSON 证据快照的压缩方式（空/none/gzip），哈希针对压缩后的存储字节。
+	SnapshotCompression string
+
+	// ScanMessengers 为 true 时额外采集 Telegram Desktop / Discord 安装与频道名称（仅在线扫描）。
+	ScanMessengers bool
+
+	// Budget 约束原始历史库副本的总字节数（nil 表示不限），超出预算的库跳过复制、浏览记录照常解析。
+	Budget *budget.Budget
+	// SkipHistoryDB 为 true 时不复制原始历史库（取证人员取消勾选重型来源，保留元数据）。
+	SkipHistoryDB bool
 }
 
 // Result 定义一次主机扫描的摘要输出。
@@ -52,6 +91,23 @@ type Result struct {
 	ReportPath    string   `json:"report_path,omitempty"`
 	StartedAt     int64    `json:"started_at"`
 	FinishedAt    int64    `json:"finished_at"`
+	TraceID       string   `json:"trace_id,omitempty"`
+
+	// DuplicateCases 非空表示该设备已登记在其他未结案件中（见 devicedup），可改用已有案件继续采集。
+	DuplicateCases []model.DeviceCaseRef `json:"duplicate_cases,omitempty"`
+
+	// 离线模式：输入目录与目录摘要（见 host.HashDirectory）。
+	AcquisitionMethod string `json:"acquisition_method,omitempty"`
+	SourceDir         string `json:"source_dir,omitempty"`
+	SourceSHA256      string `json:"source_sha256,omitempty"`
+	SourceFileCount   int    `json:"source_file_count,omitempty"`
+
+	// Budget 为本次扫描的采集预算使用情况与跳过的来源（未设置预算时为空）。
+	Budget *budget.Summary `json:"budget,omitempty"`
+	// AccessDenied 为因权限不足/被锁定而跳过的来源（建议提权后重新扫描）。
+	AccessDenied []host.AccessDenial `json:"access_denied,omitempty"`
+	// Interference 为快照/临时副本创建后立即消失或被改动的事件（疑似杀毒软件隔离）。
+	Interference []host.InterferenceEvent `json:"interference,omitempty"`
 }
 
 // Run 执行主机扫描主流程：
@@ -60,7 +116,10 @@ type Result struct {
 // 3) 采集证据并入库
 // 4) 规则匹配并入库
 // 5) 生成内部报告与审计日志
-func Run(ctx context.Context, opts Options) (*Result, error) {
+func Run(ctx context.Context, opts Options) (_ *Result, retErr error) {
+	ctx, span := trace.Start(ctx, "hostscan.Run")
+	defer func() { span.End(retErr) }()
+
 	defaults := app.DefaultConfig()
 	if opts.DBPath == "" {
 		opts.DBPath = defaults.DBPath
@@ -74,6 +133,9 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	if opts.ExchangeRulePath == "" {
 		opts.ExchangeRulePath = defaults.ExchangeRulePath
 	}
+	if opts.RegexRulePath == "" {
+		opts.RegexRulePath = defaults.RegexRulePath
+	}
 	opts.AuthorizationOrder = strings.TrimSpace(opts.AuthorizationOrder)
 	opts.AuthorizationBasis = strings.TrimSpace(opts.AuthorizationBasis)
 	opts.PrivacyMode = strings.ToLower(strings.TrimSpace(opts.PrivacyMode))
@@ -83,6 +145,33 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	if opts.PrivacyMode != "off" && opts.PrivacyMode != "masked" {
 		opts.PrivacyMode = "off"
 	}
+	compression, err := snapshot.ParseCompression(opts.SnapshotCompression)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "invalid snapshot compression")
+	}
+	opts.SnapshotCompression = compression
+	if strings.EqualFold(strings.TrimSpace(opts.Profile), "external") {
+		opts.Profile = "external"
+	} else {
+		opts.Profile = "internal"
+	}
+	scanType := "host_scan"
+	opts.OfflineInputDir = strings.TrimSpace(opts.OfflineInputDir)
+	offline := opts.OfflineInputDir != ""
+	if offline {
+		scanType = "offline_scan"
+		if st, err := os.Stat(opts.OfflineInputDir); err != nil || !st.IsDir() {
+			return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("offline input is not a directory: %s", opts.OfflineInputDir))
+		}
+		opts.OfflineOS = strings.ToLower(strings.TrimSpace(opts.OfflineOS))
+		if opts.OfflineOS != "" && opts.OfflineOS != string(model.OSWindows) && opts.OfflineOS != string(model.OSMacOS) {
+			return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unsupported offline os: %s (windows|macos)", opts.OfflineOS))
+		}
+	}
+	opts.ParentDeviceID = strings.TrimSpace(opts.ParentDeviceID)
+	if opts.ParentDeviceID != "" && !offline {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "parent device is only supported for offline scans")
+	}
 
 	if err := os.MkdirAll(filepath.Dir(opts.DBPath), 0o755); err != nil {
 		return nil, fmt.Errorf("create db directory: %w", err)
@@ -114,6 +203,9 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	// case/device 是后续 artifacts、hits、audit 的主关联键。
 	store := sqliteadapter.NewStore(db)
 	title := "Host Scan"
+	if offline {
+		title = "Offline Scan"
+	}
 	if strings.TrimSpace(opts.CaseID) != "" {
 		// UI 支持“先建案再采集”。如果这里强制写入 "Host Scan"，会覆盖用户自定义标题。
 		// EnsureCase 的 upsert 逻辑：title 为空则不覆盖旧值，因此传空即可达到“只在新建时写默认值”的效果。
@@ -123,30 +215,15 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	if err != nil {
 		return nil, err
 	}
+	policy, err := precheckpolicy.Load(ctx, store)
+	if err != nil {
+		return nil, err
+	}
 
-	authStatus := model.PrecheckPassed
-	authMessage := opts.AuthorizationOrder
-	if opts.AuthorizationOrder == "" {
-		authStatus = model.PrecheckSkipped
-		authMessage = "not provided"
-		if opts.RequireAuthOrder {
-			authStatus = model.PrecheckFailed
-			authMessage = "authorization order is required but missing"
-		}
+	prechecks, err := authdoc.Prechecks(ctx, store, policy, caseID, opts.AuthorizationOrder, opts.AuthorizationBasis, opts.RequireAuthOrder)
+	if err != nil {
+		return nil, err
 	}
-	prechecks := []model.PrecheckResult{{
-		CaseID:    caseID,
-		ScanScope: "general",
-		CheckCode: "authorization_order",
-		CheckName: "执法授权工单已提供",
-		Required:  opts.RequireAuthOrder,
-		Status:    authStatus,
-		Message:   authMessage,
-		DetailJSON: mustJSON(map[string]any{
-			"authorization_basis": opts.AuthorizationBasis,
-		}),
-		CheckedAt: time.Now().Unix(),
-	}}
 	prechecks = append(prechecks, model.PrecheckResult{
 		CaseID:    caseID,
 		ScanScope: "general",
@@ -161,12 +238,17 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		}),
 		CheckedAt: time.Now().Unix(),
 	})
-	if opts.RequireAuthOrder && opts.AuthorizationOrder == "" {
+	blocked, policyWarnings := policy.Gate(scanType, prechecks)
+	if authCheck := prechecks[0]; (authCheck.Required && authCheck.Status == model.PrecheckFailed) || (blocked != nil && blocked.CheckCode == "authorization_order") {
 		_ = store.SavePrecheckResults(ctx, prechecks)
-		_ = store.AppendAudit(ctx, caseID, "", "host_scan", "precheck", "failed", opts.Operator, "hostscan.Run", map[string]any{
-			"reason": "authorization order required",
+		_ = store.AppendAudit(ctx, caseID, "", scanType, "precheck", "failed", opts.Operator, "hostscan.Run", map[string]any{
+			"reason":     authCheck.Message,
+			"error_code": apperr.CodePrecheckAuth,
 		})
-		return nil, fmt.Errorf("host precheck failed: authorization order is required")
+		return nil, apperr.T(apperr.CodePrecheckAuth, "precheck.host_auth", authCheck.Message)
+	}
+	if blocked != nil {
+		return nil, abortByPolicy(ctx, store, caseID, scanType, opts.Operator, prechecks, blocked)
 	}
 
 	if err := precheckWritable(opts.EvidenceRoot); err != nil {
@@ -182,7 +264,7 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 			DetailJSON: mustJSON(map[string]any{"evidence_root": opts.EvidenceRoot}),
 		})
 		_ = store.SavePrecheckResults(ctx, prechecks)
-		_ = store.AppendAudit(ctx, caseID, "", "host_scan", "precheck", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, "", scanType, "precheck", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 		return nil, fmt.Errorf("host precheck failed: %w", err)
 	}
 	prechecks = append(prechecks, model.PrecheckResult{
@@ -196,8 +278,26 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		CheckedAt:  time.Now().Unix(),
 		DetailJSON: mustJSON(map[string]any{"evidence_root": opts.EvidenceRoot}),
 	})
+	quotaCheck, quotaWarning, err := casestorage.Precheck(ctx, store, caseID, "general")
+	prechecks = append(prechecks, quotaCheck)
+	if err != nil {
+		_ = store.SavePrecheckResults(ctx, prechecks)
+		_ = store.AppendAudit(ctx, caseID, "", scanType, "precheck", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
+		return nil, err
+	}
+	gated := len(prechecks) - 1
 
-	device, err := host.DetectHostDevice()
+	var device model.Device
+	var digest host.DirDigest
+	if offline {
+		// 先对输入目录整体求摘要：证明“解析所用的数据”就是交接来的目录内容。
+		digest, err = host.HashDirectory(ctx, opts.OfflineInputDir)
+		if err == nil {
+			device, err = host.OfflineDevice(ctx, opts.OfflineInputDir, model.OSType(opts.OfflineOS), opts.DeviceName, digest)
+		}
+	} else {
+		device, err = host.DetectHostDevice()
+	}
 	if err != nil {
 		prechecks = append(prechecks, model.PrecheckResult{
 			CaseID:     caseID,
@@ -211,7 +311,7 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 			DetailJSON: mustJSON(map[string]any{}),
 		})
 		_ = store.SavePrecheckResults(ctx, prechecks)
-		_ = store.AppendAudit(ctx, caseID, "", "host_scan", "precheck", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, "", scanType, "precheck", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 		return nil, err
 	}
 	prechecks = append(prechecks, model.PrecheckResult{
@@ -229,34 +329,130 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 			"identifier":  device.Identifier,
 		}),
 	})
+	if offline {
+		prechecks = append(prechecks, model.PrecheckResult{
+			CaseID:    caseID,
+			DeviceID:  device.ID,
+			ScanScope: "host",
+			CheckCode: "offline_source_hashed",
+			CheckName: "离线输入目录已计算摘要",
+			Required:  true,
+			Status:    model.PrecheckPassed,
+			Message:   digest.SHA256,
+			CheckedAt: time.Now().Unix(),
+			DetailJSON: mustJSON(map[string]any{
+				"source_dir":  opts.OfflineInputDir,
+				"sha256":      digest.SHA256,
+				"file_count":  digest.FileCount,
+				"total_bytes": digest.TotalBytes,
+			}),
+		})
+	}
+	dupCheck, dupCases, dupWarning := devicedup.Precheck(ctx, store, caseID, "host", device)
+	prechecks = append(prechecks, dupCheck)
+	encCheck, encWarning := encryptionPrecheck(ctx, caseID, device, offline)
+	prechecks = append(prechecks, encCheck)
+	elevCheck, elevWarning := elevationPrecheck(ctx, caseID, device, offline)
+	prechecks = append(prechecks, elevCheck)
+	fdaWarning := ""
+	if device.OS == model.OSMacOS && !offline {
+		var fdaCheck model.PrecheckResult
+		fdaCheck, fdaWarning = fullDiskAccessPrecheck(caseID, device)
+		prechecks = append(prechecks, fdaCheck)
+	}
+	// 策略：判定其余检查，并补上策略要求但本次未执行的检查。
+	prechecks = append(prechecks, policy.Missing(scanType, caseID, prechecks)...)
+	blocked, more := policy.Gate(scanType, prechecks[gated:])
+	policyWarnings = append(policyWarnings, more...)
+	if blocked != nil {
+		return nil, abortByPolicy(ctx, store, caseID, scanType, opts.Operator, prechecks, blocked)
+	}
 	if err := store.SavePrecheckResults(ctx, prechecks); err != nil {
 		return nil, err
 	}
 
-	if err := store.UpsertDevice(ctx, caseID, device, true, "host local device"); err != nil {
+	if offline {
+		note := fmt.Sprintf("offline import from %s (dir sha256 %s, %d files)", opts.OfflineInputDir, digest.SHA256, digest.FileCount)
+		if err := store.UpsertDeviceWithConnection(ctx, caseID, device, "import", true, note); err != nil {
+			return nil, err
+		}
+		if opts.ParentDeviceID != "" {
+			if err := store.SetDeviceParent(ctx, caseID, device.ID, opts.ParentDeviceID); err != nil {
+				return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "invalid parent device")
+			}
+		}
+	} else if err := store.UpsertDevice(ctx, caseID, device, true, "host local device"); err != nil {
 		return nil, err
 	}
 
 	// 先写一条 started 审计日志，保证流程可追溯。
 	started := time.Now().Unix()
-	_ = store.AppendAudit(ctx, caseID, device.ID, "host_scan", "scan_start", "started", opts.Operator, "hostscan.Run", map[string]any{
+	startDetail := map[string]any{
 		"os":                    device.OS,
 		"hostname":              device.Name,
 		"privacy_mode_reserved": opts.PrivacyMode,
-	})
+		"snapshot_compression":  opts.SnapshotCompression,
+		"scan_messengers":       opts.ScanMessengers,
+		"skip_history_db":       opts.SkipHistoryDB,
+	}
+	if sum := opts.Budget.Since(0); sum != nil {
+		startDetail["budget_limit_bytes"] = sum.LimitBytes
+		startDetail["budget_used_bytes"] = sum.UsedBytes
+	}
+	if offline {
+		startDetail["acquisition_method"] = host.AcquisitionOffline
+		startDetail["source_dir"] = opts.OfflineInputDir
+		startDetail["source_sha256"] = digest.SHA256
+		startDetail["source_file_count"] = digest.FileCount
+		if opts.ParentDeviceID != "" {
+			startDetail["parent_device_id"] = opts.ParentDeviceID
+		}
+	}
+	run, err := store.SaveScanRun(ctx, caseID, device.ID, opts.Operator, started, scanOptions(scanType, opts))
+	if err != nil {
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "save_scan_run", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
+		return nil, err
+	}
+	startDetail["scan_run_id"] = run.RunID
+	startDetail["options_sha256"] = run.OptionsSHA256
+	_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "scan_start", "started", opts.Operator, "hostscan.Run", startDetail)
 
 	scanner := host.NewScanner(opts.EvidenceRoot)
-	artifacts, scanErr := scanner.Scan(ctx, caseID, device)
+	scanner.SnapshotCompression = opts.SnapshotCompression
+	scanner.ScanMessengers = opts.ScanMessengers
+	scanner.Budget = opts.Budget
+	scanner.SkipHistoryDB = opts.SkipHistoryDB
+	scanner.Access = host.NewAccessLog()
+	scanner.Interference = host.NewInterferenceLog()
+	if scanner.MaxPayloadBytes, err = store.ArtifactPayloadMaxBytes(ctx); err != nil {
+		return nil, err
+	}
+	budgetMark := opts.Budget.Mark()
+	var artifacts []model.Artifact
+	var scanErr error
+	if offline {
+		artifacts, scanErr = scanner.ScanOffline(ctx, caseID, device, opts.OfflineInputDir)
+	} else {
+		artifacts, scanErr = scanner.Scan(ctx, caseID, device)
+	}
+	// 因权限不足/被锁定而跳过的来源记为 skipped 前置检查，覆盖缺口随报告一并呈现。
+	accessDenied := scanner.Access.Items()
+	accessChecks, accessWarning := accessPrechecks(caseID, device.ID, accessDenied)
+	if len(accessChecks) > 0 {
+		_ = store.SavePrecheckResults(ctx, accessChecks)
+		prechecks = append(prechecks, accessChecks...)
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "source_access", "skipped", opts.Operator, "hostscan.Run", map[string]any{"sources": accessDenied})
+	}
 	if err := store.SaveArtifacts(ctx, artifacts); err != nil {
-		_ = store.AppendAudit(ctx, caseID, device.ID, "host_scan", "save_artifacts", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "save_artifacts", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 		return nil, err
 	}
 
 	// 规则加载失败属于硬错误：无法给出可信命中结果。
-	loader := rules.NewLoader(opts.WalletRulePath, opts.ExchangeRulePath)
-	loaded, err := loader.Load(ctx)
+	loader := rules.NewLoader(opts.WalletRulePath, opts.ExchangeRulePath).WithRegexFile(opts.RegexRulePath)
+	loaded, err := opts.RulesCache.Load(ctx, loader)
 	if err != nil {
-		_ = store.AppendAudit(ctx, caseID, device.ID, "host_scan", "load_rules", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "load_rules", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 		return nil, err
 	}
 
@@ -267,45 +463,174 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	if id, err := store.EnsureRuleBundle(ctx, "wallet_signatures", loaded.Wallet.Version, loaded.WalletSHA256, opts.WalletRulePath); err == nil {
 		walletBundleID = id
 	} else {
-		_ = store.AppendAudit(ctx, caseID, device.ID, "host_scan", "rule_bundle_wallet", "skipped", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "rule_bundle_wallet", "skipped", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 	}
 	if id, err := store.EnsureRuleBundle(ctx, "exchange_domains", loaded.Exchange.Version, loaded.ExchangeSHA256, opts.ExchangeRulePath); err == nil {
 		exchangeBundleID = id
 	} else {
-		_ = store.AppendAudit(ctx, caseID, device.ID, "host_scan", "rule_bundle_exchange", "skipped", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "rule_bundle_exchange", "skipped", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
+	}
+	regexBundleID := ""
+	if loaded.RegexSHA256 != "" {
+		if id, err := store.EnsureRuleBundle(ctx, "regex_rules", loaded.Regex.Version, loaded.RegexSHA256, opts.RegexRulePath); err == nil {
+			regexBundleID = id
+		} else {
+			_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "rule_bundle_regex", "skipped", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
+		}
 	}
 
 	matchResult, err := matcher.MatchHostArtifacts(loaded, artifacts)
 	if err != nil {
-		_ = store.AppendAudit(ctx, caseID, device.ID, "host_scan", "match_rules", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "match_rules", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 		return nil, err
 	}
 
 	// 把 rule_bundle_id 回填到命中结果（与规则包留痕关联）。
 	for i := range matchResult.Hits {
 		switch matchResult.Hits[i].Type {
-		case model.HitWalletInstalled:
+		case model.HitWalletInstalled, model.HitWalletExecuted:
 			matchResult.Hits[i].RuleBundleID = walletBundleID
-		case model.HitExchangeVisited:
+		case model.HitExchangeVisited, model.HitExchangeFormActivity, model.HitExchangeAppInstalled, model.HitMessengerCommunity:
 			matchResult.Hits[i].RuleBundleID = exchangeBundleID
 		}
+		if matcher.IsRegexRuleHit(matchResult.Hits[i]) {
+			matchResult.Hits[i].RuleBundleID = regexBundleID
+		}
+	}
+
+	// 命中扩展的本地目录快照（best effort）：嫌疑人事后卸载扩展，manifest/图标/目录清单仍可复核。
+	extSnapshots, extErrs := snapshotExtensionHits(caseID, device.ID, scanner, artifacts, matchResult.Hits)
+	extWarning := ""
+	if len(extSnapshots) > 0 {
+		if err := store.SaveArtifacts(ctx, extSnapshots); err != nil {
+			extErrs = append(extErrs, "save: "+err.Error())
+			for i := range matchResult.Hits {
+				matchResult.Hits[i].ArtifactIDs = withoutIDs(matchResult.Hits[i].ArtifactIDs, extSnapshots)
+			}
+		} else {
+			artifacts = append(artifacts, extSnapshots...)
+		}
+	}
+	if len(extSnapshots) > 0 || len(extErrs) > 0 {
+		status := "success"
+		if len(extErrs) > 0 {
+			status = "failed"
+			extWarning = fmt.Sprintf("browser extension snapshot incomplete (%d error(s)): %s", len(extErrs), strings.Join(extErrs, "; "))
+		}
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "extension_snapshot", status, opts.Operator, "hostscan.Run", map[string]any{
+			"snapshots": len(extSnapshots),
+			"errors":    extErrs,
+		})
+	}
+
+	// 杀毒软件干扰：快照/临时副本创建后立即消失或被改动时固化一条 collection_interference 证据，说明证据缺口。
+	interference := scanner.Interference.Items()
+	interferenceWarning := ""
+	if len(interference) > 0 {
+		unrecovered := 0
+		for _, ev := range interference {
+			if !ev.Recovered {
+				unrecovered++
+			}
+		}
+		interferenceWarning = fmt.Sprintf("%d evidence file(s) disappeared or changed right after creation (possible antivirus quarantine), %d not recovered after retries - %s", len(interference), unrecovered, host.InterferenceHint())
+		status := "success"
+		if unrecovered > 0 {
+			status = "failed"
+		}
+		detail := map[string]any{"events": interference, "unrecovered": unrecovered}
+		if arts, err := scanner.InterferenceArtifact(caseID, device.ID); err != nil {
+			detail["error"] = err.Error()
+		} else if err := store.SaveArtifacts(ctx, arts); err != nil {
+			detail["error"] = err.Error()
+		} else {
+			artifacts = append(artifacts, arts...)
+		}
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "collection_interference", status, opts.Operator, "hostscan.Run", detail)
+	}
+
+	// 案件关注词（best effort）：读取失败只记审计，不影响规则命中入库。
+	if terms, err := store.ListWatchlistTerms(ctx, caseID); err == nil {
+		matchResult.Hits = append(matchResult.Hits, matcher.MatchWatchlist(terms, artifacts)...)
+	} else {
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "match_watchlist", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
+	}
+
+	// 链上域名解析（best effort）：解析得到的地址作为 wallet_address 命中一并保存。
+	nameRes, nameErr := nameresolve.Resolve(ctx, artifacts, nameresolve.Options{
+		ETHRPCURL: opts.ETHRPCURL,
+		BNBRPCURL: opts.BNBRPCURL,
+	})
+	if nameErr == nil {
+		matchResult.Hits = append(matchResult.Hits, nameRes.Hits...)
 	}
 
 	if err := store.SaveRuleHits(ctx, matchResult.Hits); err != nil {
-		_ = store.AppendAudit(ctx, caseID, device.ID, "host_scan", "save_hits", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "save_hits", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 		return nil, err
 	}
 
 	// scanErr 表示“部分采集失败”，不一定阻断整体流程。
 	status := "success"
 	warnings := []string{}
+	if quotaWarning != "" {
+		warnings = append(warnings, quotaWarning)
+	}
+	if encWarning != "" {
+		warnings = append(warnings, encWarning)
+	}
+	if elevWarning != "" {
+		warnings = append(warnings, elevWarning)
+	}
+	if fdaWarning != "" {
+		warnings = append(warnings, fdaWarning)
+	}
+	if dupWarning != "" {
+		warnings = append(warnings, dupWarning)
+	}
+	warnings = append(warnings, policyWarnings...)
+	if extWarning != "" {
+		warnings = append(warnings, extWarning)
+	}
+	if interferenceWarning != "" {
+		warnings = append(warnings, interferenceWarning)
+	}
+	budgetSummary := opts.Budget.Since(budgetMark)
+	warnings = append(warnings, budgetSummary.Warnings()...)
+	if accessWarning != "" {
+		warnings = append(warnings, accessWarning)
+	}
 	if scanErr != nil {
 		warnings = append(warnings, scanErr.Error())
 		status = "failed"
 	}
 
+	if nameErr != nil {
+		warnings = append(warnings, "name resolution failed: "+nameErr.Error())
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "name_resolution", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": nameErr.Error(), "error_code": apperr.CodeOf(nameErr)})
+	} else if len(nameRes.Resolutions) > 0 {
+		if err := store.SaveNameResolutions(ctx, nameRes.Resolutions); err != nil {
+			warnings = append(warnings, "save name resolutions failed: "+err.Error())
+		}
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "name_resolution", "success", opts.Operator, "hostscan.Run", map[string]any{
+			"names":    len(nameRes.Resolutions),
+			"resolved": len(nameRes.Hits),
+		})
+	}
+	if err := store.UpsertCaseAddresses(ctx, nameresolve.AddressBook(caseID, matchResult.Hits)); err != nil {
+		warnings = append(warnings, "update address book failed: "+err.Error())
+	}
+
+	// 地址聚类（best effort）：按案件整体重算，失败不影响命中结果。
+	if clusters, err := addrcluster.RunForCase(ctx, store, caseID, addrcluster.Options{}); err != nil {
+		warnings = append(warnings, "address clustering failed: "+err.Error())
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "address_clusters", "failed", opts.Operator, "hostscan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
+	} else if len(clusters) > 0 {
+		_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "address_clusters", "success", opts.Operator, "hostscan.Run", map[string]any{"clusters": len(clusters)})
+	}
+
 	// 内部报告（JSON + HTML）
-	jsonPath, jsonHash, jsonErr := writeInternalJSONReport(opts.DBPath, caseID, opts.AuthorizationOrder, opts.PrivacyMode, device, artifacts, matchResult.Hits, warnings, prechecks)
+	jsonPath, jsonHash, jsonErr := writeInternalJSONReport(ctx, store, opts.DBPath, caseID, opts.AuthorizationOrder, opts.PrivacyMode, device, reportjson.ArtifactIDs(artifacts), reportjson.HitIDs(matchResult.Hits), warnings, prechecks)
 	jsonReportID := ""
 	if jsonErr == nil {
 		jsonReportID, _ = store.SaveReport(ctx, caseID, "internal_json", jsonPath, jsonHash, "hostscan-0.1.0", "ready")
@@ -313,7 +638,11 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		warnings = append(warnings, "write internal_json report failed: "+jsonErr.Error())
 	}
 
-	htmlPath, htmlHash, htmlErr := writeInternalHTMLReport(opts.DBPath, caseID, opts.AuthorizationOrder, opts.PrivacyMode, device, artifacts, matchResult.Hits, warnings, prechecks)
+	org, err := store.GetOrgProfile(ctx)
+	if err != nil {
+		warnings = append(warnings, "load org profile failed: "+err.Error())
+	}
+	htmlPath, htmlHash, htmlErr := writeInternalHTMLReport(opts.DBPath, caseID, opts.AuthorizationOrder, opts.PrivacyMode, org, device, artifacts, matchResult.Hits, warnings, prechecks)
 	if htmlErr == nil {
 		_, _ = store.SaveReport(ctx, caseID, "internal_html", htmlPath, htmlHash, "hostscan-0.1.0", "ready")
 	} else {
@@ -321,12 +650,14 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	}
 
 	// 结束审计日志写入最终统计。
-	_ = store.AppendAudit(ctx, caseID, device.ID, "host_scan", "scan_finish", status, opts.Operator, "hostscan.Run", map[string]any{
+	_ = store.AppendAudit(ctx, caseID, device.ID, scanType, "scan_finish", status, opts.Operator, "hostscan.Run", map[string]any{
 		"artifacts":            len(artifacts),
 		"hits":                 len(matchResult.Hits),
 		"warning":              scanErrString(scanErr),
 		"report_internal_json": jsonPath,
 		"report_internal_html": htmlPath,
+		"budget":               budgetSummary,
+		"access_denied":        len(accessDenied),
 	})
 
 	walletHits := 0
@@ -340,7 +671,8 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		}
 	}
 
-	return &Result{
+	res := &Result{
+		TraceID:       span.TraceID(),
 		CaseID:        caseID,
 		DeviceID:      device.ID,
 		DeviceName:    device.Name,
@@ -354,7 +686,118 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		ReportPath:    jsonPath,
 		StartedAt:     started,
 		FinishedAt:    time.Now().Unix(),
-	}, nil
+
+		DuplicateCases: dupCases,
+		Budget:         budgetSummary,
+		AccessDenied:   accessDenied,
+		Interference:   interference,
+	}
+	if offline {
+		res.AcquisitionMethod = host.AcquisitionOffline
+		res.SourceDir = opts.OfflineInputDir
+		res.SourceSHA256 = digest.SHA256
+		res.SourceFileCount = digest.FileCount
+	}
+	return res, nil
+}
+
+// snapshotExtensionHits 为 browser_extension_id 命中的扩展生成目录快照，并把快照证据 ID 追加到对应命中。
+// 同一扩展 ID 在多个浏览器/profile 中安装时，每个安装目录各生成一份快照。
+func snapshotExtensionHits(caseID, deviceID string, scanner *host.Scanner, artifacts []model.Artifact, hits []model.RuleHit) ([]model.Artifact, []string) {
+	targets := map[string][]int{}
+	for i, h := range hits {
+		if h.Type != model.HitWalletInstalled {
+			continue
+		}
+		var detail struct {
+			MatchField string `json:"match_field"`
+		}
+		if json.Unmarshal(h.DetailJSON, &detail) != nil || detail.MatchField != "browser_extension_id" {
+			continue
+		}
+		targets[h.MatchedValue] = append(targets[h.MatchedValue], i)
+	}
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	var out []model.Artifact
+	var errs []string
+	seen := map[string]bool{}
+	for _, a := range artifacts {
+		if a.Type != model.ArtifactBrowserExt {
+			continue
+		}
+		var rows []model.ExtensionRecord
+		if err := json.Unmarshal(a.PayloadJSON, &rows); err != nil {
+			continue
+		}
+		for _, ex := range rows {
+			eid := strings.ToLower(strings.TrimSpace(ex.ExtensionID))
+			idxs, ok := targets[eid]
+			if !ok || ex.Path == "" || seen[ex.Path] {
+				continue
+			}
+			seen[ex.Path] = true
+			art, err := scanner.SnapshotExtension(caseID, deviceID, ex)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s/%s %s: %v", ex.Browser, ex.Profile, eid, err))
+				continue
+			}
+			out = append(out, art)
+			for _, i := range idxs {
+				// 匹配器中多条命中可能共享同一 ArtifactIDs 底层数组，追加前先复制。
+				hits[i].ArtifactIDs = append(append([]string(nil), hits[i].ArtifactIDs...), art.ID)
+			}
+		}
+	}
+	return out, errs
+}
+
+// withoutIDs 从 ids 中去掉 arts 的证据 ID（快照入库失败时回退命中关联）。
+func withoutIDs(ids []string, arts []model.Artifact) []string {
+	drop := map[string]bool{}
+	for _, a := range arts {
+		drop[a.ID] = true
+	}
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !drop[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// scanOptions 生成本次扫描的规范化参数快照（见 model.ScanOptions）。
+func scanOptions(scanType string, opts Options) model.ScanOptions {
+	out := model.ScanOptions{
+		ScanType:            scanType,
+		Profile:             opts.Profile,
+		AppVersion:          app.Version,
+		PrivacyMode:         opts.PrivacyMode,
+		SnapshotCompression: opts.SnapshotCompression,
+		RequireAuthOrder:    opts.RequireAuthOrder,
+		AuthorizationOrder:  opts.AuthorizationOrder,
+		AuthorizationBasis:  opts.AuthorizationBasis,
+		EvidenceRoot:        opts.EvidenceRoot,
+		WalletRulePath:      opts.WalletRulePath,
+		ExchangeRulePath:    opts.ExchangeRulePath,
+		RegexRulePath:       opts.RegexRulePath,
+		Host: &model.HostScanOptions{
+			ScanMessengers:  opts.ScanMessengers,
+			SkipHistoryDB:   opts.SkipHistoryDB,
+			ResolveETHNames: strings.TrimSpace(opts.ETHRPCURL) != "",
+			ResolveBNBNames: strings.TrimSpace(opts.BNBRPCURL) != "",
+			OfflineInputDir: opts.OfflineInputDir,
+			OfflineOS:       opts.OfflineOS,
+			ParentDeviceID:  opts.ParentDeviceID,
+		},
+	}
+	if sum := opts.Budget.Since(0); sum != nil {
+		out.BudgetLimitBytes = sum.LimitBytes
+	}
+	return out
 }
 
 // scanErrString 将可空错误统一转为字符串，便于审计字段写入。
@@ -374,6 +817,148 @@ func precheckWritable(root string) error {
 	return nil
 }
 
+// elevationPrecheck 检查当前权限能否读取关键来源（其他用户 profile、受 TCC 保护的 Safari 历史），生成 elevation 检查（非必需）。
+// 存在缺口时记为 failed，消息与告警给出所需的具体权限；离线模式不涉及本机权限，记为 skipped。
+func elevationPrecheck(ctx context.Context, caseID string, device model.Device, offline bool) (model.PrecheckResult, string) {
+	check := model.PrecheckResult{
+		CaseID:    caseID,
+		DeviceID:  device.ID,
+		ScanScope: "host",
+		CheckCode: "elevation",
+		CheckName: "扫描权限",
+		Required:  false,
+		CheckedAt: time.Now().Unix(),
+	}
+	if offline {
+		check.Status = model.PrecheckSkipped
+		check.Message = "offline import: host privileges do not apply"
+		check.DetailJSON = mustJSON(map[string]any{})
+		return check, ""
+	}
+	st := host.CheckElevation(ctx, device)
+	check.DetailJSON = mustJSON(st)
+	if len(st.Requirements) == 0 {
+		check.Status = model.PrecheckPassed
+		check.Message = fmt.Sprintf("elevated=%t, key sources readable", st.Elevated)
+		return check, ""
+	}
+	parts := make([]string, 0, len(st.Requirements))
+	for _, r := range st.Requirements {
+		parts = append(parts, fmt.Sprintf("%s %s needs %s: %s", r.Source, r.Path, r.Permission, r.Hint))
+	}
+	check.Status = model.PrecheckFailed
+	check.Message = fmt.Sprintf("%d key source(s) need additional permission: %s", len(st.Requirements), strings.Join(parts, "; "))
+	return check, check.Message
+}
+
+// fullDiskAccessPrecheck 探测 macOS 完全磁盘访问权限（TCC），生成 macos_full_disk_access 检查（非必需）。
+// 未授权时 Safari 历史与 Mail 数据会静默为空，因此记为 failed 并给出授权步骤；探测位置都不存在时记为 skipped。
+func fullDiskAccessPrecheck(caseID string, device model.Device) (model.PrecheckResult, string) {
+	st := host.CheckFullDiskAccess()
+	check := model.PrecheckResult{
+		CaseID:     caseID,
+		DeviceID:   device.ID,
+		ScanScope:  "host",
+		CheckCode:  "macos_full_disk_access",
+		CheckName:  "macOS 完全磁盘访问权限",
+		Required:   false,
+		CheckedAt:  time.Now().Unix(),
+		DetailJSON: mustJSON(st),
+	}
+	switch st.Status {
+	case host.FullDiskAccessGranted:
+		check.Status = model.PrecheckPassed
+		check.Message = "full disk access granted"
+		return check, ""
+	case host.FullDiskAccessDenied:
+		check.Status = model.PrecheckFailed
+		check.Message = "full disk access not granted: Safari history and Mail data will be empty - " + st.Hint
+		return check, check.Message
+	default:
+		check.Status = model.PrecheckSkipped
+		check.Message = "no TCC-protected location found to probe"
+		return check, ""
+	}
+}
+
+// accessPrechecks 把权限不足/被锁定而跳过的来源转为非必需的 source_access 检查（skipped），并汇总为一条告警。
+func accessPrechecks(caseID, deviceID string, denied []host.AccessDenial) ([]model.PrecheckResult, string) {
+	if len(denied) == 0 {
+		return nil, ""
+	}
+	now := time.Now().Unix()
+	checks := make([]model.PrecheckResult, 0, len(denied))
+	paths := make([]string, 0, len(denied))
+	hints := []string{}
+	for _, d := range denied {
+		checks = append(checks, model.PrecheckResult{
+			CaseID:     caseID,
+			DeviceID:   deviceID,
+			ScanScope:  "host",
+			CheckCode:  "source_access",
+			CheckName:  "来源读取权限",
+			Required:   false,
+			Status:     model.PrecheckSkipped,
+			Message:    fmt.Sprintf("%s %s unreadable (%s); %s", d.Category, d.Path, d.Reason, d.Hint),
+			CheckedAt:  now,
+			DetailJSON: mustJSON(d),
+		})
+		paths = append(paths, d.Path)
+		if !slices.Contains(hints, d.Hint) {
+			hints = append(hints, d.Hint)
+		}
+	}
+	return checks, fmt.Sprintf("%d source(s) skipped due to insufficient access, coverage is incomplete: %s - %s", len(denied), strings.Join(paths, ", "), strings.Join(hints, "; "))
+}
+
+// abortByPolicy 保存前置检查并记录审计后返回策略错误（被策略要求的检查未通过时使用）。
+// encryptionPrecheck 探测主机上的加密卷/加密容器，生成 encryption_present 检查（非必需）。
+// 发现加密时检查记为 failed 并返回告警：断电后这些数据将无法读取，需在关机前完成取证或办理相应法律手续。
+// 离线模式无法探测宿主机状态，记为 skipped。
+func encryptionPrecheck(ctx context.Context, caseID string, device model.Device, offline bool) (model.PrecheckResult, string) {
+	check := model.PrecheckResult{
+		CaseID:    caseID,
+		DeviceID:  device.ID,
+		ScanScope: "host",
+		CheckCode: "encryption_present",
+		CheckName: "加密卷/加密容器探测",
+		Required:  false,
+		CheckedAt: time.Now().Unix(),
+	}
+	if offline {
+		check.Status = model.PrecheckSkipped
+		check.Message = "offline import: encryption state of the source host is unknown"
+		check.DetailJSON = mustJSON(map[string]any{})
+		return check, ""
+	}
+	findings := host.DetectEncryption(ctx, device.OS)
+	check.DetailJSON = mustJSON(map[string]any{"findings": findings})
+	if len(findings) == 0 {
+		check.Status = model.PrecheckPassed
+		check.Message = "no encrypted volumes or containers detected"
+		return check, ""
+	}
+	parts := make([]string, 0, len(findings))
+	for _, f := range findings {
+		parts = append(parts, fmt.Sprintf("%s %s %s", f.Kind, f.Scope, f.Path))
+	}
+	check.Status = model.PrecheckFailed
+	check.Message = fmt.Sprintf("encryption present (%d): %s", len(findings), strings.Join(parts, "; "))
+	return check, check.Message + " - do not power off before imaging/decryption is arranged"
+}
+
+func abortByPolicy(ctx context.Context, store *sqliteadapter.Store, caseID, scanType, operator string, prechecks []model.PrecheckResult, blocked *model.PrecheckResult) error {
+	err := precheckpolicy.BlockedError(scanType, blocked)
+	_ = store.SavePrecheckResults(ctx, prechecks)
+	_ = store.AppendAudit(ctx, caseID, blocked.DeviceID, scanType, "precheck", "failed", operator, "hostscan.Run", map[string]any{
+		"reason":     "required by precheck policy",
+		"check_code": blocked.CheckCode,
+		"error":      err.Error(),
+		"error_code": apperr.CodePrecheckPolicy,
+	})
+	return err
+}
+
 func mustJSON(v any) []byte {
 	raw, err := json.Marshal(v)
 	if err != nil {
@@ -383,77 +968,50 @@ func mustJSON(v any) []byte {
 }
 
 // writeInternalJSONReport 生成内部 JSON 报告，并返回文件路径与哈希。
-func writeInternalJSONReport(dbPath, caseID, authOrder, privacyMode string, device model.Device, artifacts []model.Artifact, hits []model.RuleHit, warnings []string, prechecks []model.PrecheckResult) (path string, sha string, err error) {
+// 证据与命中按页从库中读取并流式写出（见 reportjson），大案件不必在内存中持有整份报告。
+func writeInternalJSONReport(ctx context.Context, store *sqliteadapter.Store, dbPath, caseID, authOrder, privacyMode string, device model.Device, artifactIDs, hitIDs []string, warnings []string, prechecks []model.PrecheckResult) (path string, sha string, err error) {
 	reportDir := filepath.Join(filepath.Dir(dbPath), "reports")
 	if err := os.MkdirAll(reportDir, 0o755); err != nil {
 		return "", "", err
 	}
 	masked := strings.TrimSpace(strings.ToLower(privacyMode)) == "masked"
 
-	type artifactSummary struct {
-		ArtifactID   string `json:"artifact_id"`
-		ArtifactType string `json:"artifact_type"`
-		SourceRef    string `json:"source_ref"`
-		SnapshotPath string `json:"snapshot_path"`
-		SHA256       string `json:"sha256"`
-		CollectedAt  int64  `json:"collected_at"`
-		SizeBytes    int64  `json:"size_bytes"`
-	}
-
-	artifactRows := make([]artifactSummary, 0, len(artifacts))
-	for _, a := range artifacts {
-		snap := a.SnapshotPath
-		if masked {
-			snap = privacy.MaskSnapshotPath(snap)
-		}
-		artifactRows = append(artifactRows, artifactSummary{
-			ArtifactID:   a.ID,
-			ArtifactType: string(a.Type),
-			SourceRef:    a.SourceRef,
-			SnapshotPath: snap,
-			SHA256:       a.SHA256,
-			CollectedAt:  a.CollectedAt,
-			SizeBytes:    a.SizeBytes,
-		})
+	training, err := store.IsTrainingDB(ctx)
+	if err != nil {
+		return "", "", err
 	}
-
-	if masked {
-		hits = privacy.MaskRuleHitsForReport(hits)
+	filename := fmt.Sprintf("%s_internal_%d.json", caseID, time.Now().Unix())
+	if training {
+		filename = "TRAINING_" + filename
 	}
-	payload := map[string]any{
-		"case_id":             caseID,
-		"authorization_order": authOrder,
-		"privacy_mode":        privacyMode,
-		"generated_at":        time.Now().Unix(),
-		"device": map[string]any{
+	path = filepath.Join(reportDir, filename)
+	// 字段按键名字母序排列（与原先 map 编码的输出一致）。
+	fields := []reportjson.Field{
+		{Key: "artifacts", Stream: reportjson.Artifacts(ctx, store, artifactIDs, masked)},
+		{Key: "authorization_order", Value: authOrder},
+		{Key: "case_id", Value: caseID},
+		{Key: "device", Value: map[string]any{
 			"device_id":  device.ID,
 			"name":       device.Name,
 			"os":         device.OS,
 			"identifier": device.Identifier,
-		},
-		"summary": map[string]any{
-			"artifact_count": len(artifacts),
-			"hit_count":      len(hits),
+		}},
+		{Key: "generated_at", Value: time.Now().Unix()},
+		{Key: "hits", Stream: reportjson.Hits(ctx, store, hitIDs, masked)},
+		{Key: "prechecks", Value: prechecks},
+		{Key: "privacy_mode", Value: privacyMode},
+		{Key: "summary", Value: map[string]any{
+			"artifact_count": len(artifactIDs),
+			"hit_count":      len(hitIDs),
 			"precheck_count": len(prechecks),
-		},
-		"prechecks": prechecks,
-		"artifacts": artifactRows,
-		"hits":      hits,
-		"warnings":  warnings,
+		}},
 	}
-
-	raw, err := json.MarshalIndent(payload, "", "  ")
-	if err != nil {
-		return "", "", err
-	}
-
-	filename := fmt.Sprintf("%s_internal_%d.json", caseID, time.Now().Unix())
-	path = filepath.Join(reportDir, filename)
-	if err := os.WriteFile(path, raw, 0o644); err != nil {
-		return "", "", err
+	if training {
+		// 演练库标记插在 warnings 之前，保持字母序。
+		fields = append(fields, reportjson.Field{Key: "training", Value: model.TrainingWatermark})
 	}
-
-	sum, _, err := hash.File(path)
+	fields = append(fields, reportjson.Field{Key: "warnings", Value: warnings})
+	sum, err := reportjson.WriteFile(path, fields)
 	if err != nil {
 		return "", "", err
 	}
@@ -465,7 +1023,7 @@ func writeInternalJSONReport(dbPath, caseID, authOrder, privacyMode string, devi
 // 设计目标：
 // - 让“内部查看”更直观（无需下载 PDF 就能快速浏览）
 // - 同时保持可追溯字段（sha256/record_hash/审计链 hash 等）可被复制与复核
-func writeInternalHTMLReport(dbPath, caseID, authOrder, privacyMode string, device model.Device, artifacts []model.Artifact, hits []model.RuleHit, warnings []string, prechecks []model.PrecheckResult) (path string, sha string, err error) {
+func writeInternalHTMLReport(dbPath, caseID, authOrder, privacyMode string, org model.OrgProfile, device model.Device, artifacts []model.Artifact, hits []model.RuleHit, warnings []string, prechecks []model.PrecheckResult) (path string, sha string, err error) {
 	reportDir := filepath.Join(filepath.Dir(dbPath), "reports")
 	if err := os.MkdirAll(reportDir, 0o755); err != nil {
 		return "", "", err
@@ -476,7 +1034,12 @@ func writeInternalHTMLReport(dbPath, caseID, authOrder, privacyMode string, devi
 	}
 
 	now := time.Now().Unix()
+	title := "数字货币痕迹检测报告（内部）"
 	filename := fmt.Sprintf("%s_internal_%d.html", caseID, now)
+	if org.Training {
+		filename = "TRAINING_" + filename
+		title += " - " + model.TrainingWatermark
+	}
 	path = filepath.Join(reportDir, filename)
 
 	// 这里不追求复杂模板引擎，直接拼接 HTML（内测阶段够用，便于后续替换为更严格模板）。
@@ -484,7 +1047,7 @@ func writeInternalHTMLReport(dbPath, caseID, authOrder, privacyMode string, devi
 	b.Grow(32 * 1024)
 	b.WriteString("<!doctype html>\n<html lang=\"zh-CN\">\n<head>\n")
 	b.WriteString("<meta charset=\"utf-8\"/>\n<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\"/>\n")
-	b.WriteString("<title>数字货币痕迹检测报告（内部）</title>\n")
+	b.WriteString("<title>" + htmlEscape(title) + "</title>\n")
 	b.WriteString("<style>\n")
 	b.WriteString("body{font-family:ui-monospace,SFMono-Regular,Menlo,Monaco,Consolas,\"Liberation Mono\",monospace;background:#0b1220;color:#e8e8e8;margin:0;padding:24px;}\n")
 	b.WriteString("h1{font-size:18px;margin:0 0 12px 0;}\n")
@@ -502,12 +1065,18 @@ func writeInternalHTMLReport(dbPath, caseID, authOrder, privacyMode string, devi
 	b.WriteString("a{color:#4fc3f7;text-decoration:none;}\n")
 	b.WriteString("</style>\n</head>\n<body>\n")
 
-	b.WriteString("<h1>数字货币痕迹检测报告（内部）</h1>\n")
+	b.WriteString("<h1>" + htmlEscape(title) + "</h1>\n")
+	if org.Training {
+		b.WriteString("<div class=\"box bad\" style=\"margin-bottom:12px;font-size:14px;\">" + model.TrainingWatermark + "：演练模式，数据为内置模拟数据源，不得作为证据使用。</div>\n")
+	}
 	b.WriteString("<div class=\"box kv\">")
 	b.WriteString("<div class=\"muted\">case_id</div><div class=\"mono\">" + htmlEscape(caseID) + "</div>")
 	b.WriteString("<div class=\"muted\">generated_at</div><div class=\"mono\">" + htmlEscape(time.Unix(now, 0).Format("2006-01-02 15:04:05")) + "</div>")
 	b.WriteString("<div class=\"muted\">authorization_order</div><div class=\"mono\">" + htmlEscape(authOrder) + "</div>")
 	b.WriteString("<div class=\"muted\">privacy_mode</div><div class=\"mono\">" + htmlEscape(privacyMode) + "</div>")
+	if org.AgencyName != "" || org.Unit != "" {
+		b.WriteString("<div class=\"muted\">agency</div><div>" + htmlEscape(strings.Trim(org.AgencyName+" / "+org.Unit, " /")) + "</div>")
+	}
 	b.WriteString("</div>\n")
 
 	b.WriteString("<h2>设备</h2>\n<div class=\"box kv\">")
@@ -613,6 +1182,9 @@ func writeInternalHTMLReport(dbPath, caseID, authOrder, privacyMode string, devi
 	}
 	b.WriteString("</div>\n")
 
+	if footer := strings.Trim(org.Address+" | "+org.Contact, " |"); footer != "" {
+		b.WriteString("<p class=\"muted\">" + htmlEscape(footer) + "</p>\n")
+	}
 	b.WriteString("</body>\n</html>\n")
 
 	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
@@ -0,0 +1,330 @@
+package manualhit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/adapters/host"
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 人工录入命中
+//
+// 部分证据只能靠人工发现（例如现场拍摄的纸质助记词），无法由扫描规则产生：
+// - 操作员指定命中类型与命中值，并必须填写录入依据（justification）
+// - 可附带一个文件（照片/截图等），原样落库为 manual_evidence 证据并与命中关联
+// - 命中的 rule_id 固定为 "manual"，detail_json 记录 manual=true、依据与操作员，
+//   报告与导出据此明确标注“人工录入”，避免与规则自动命中混淆
+
+// RuleVersion 是人工录入命中的 rule_version。
+const RuleVersion = "manual-1"
+
+// MaxAttachmentBytes 限制单个附件大小（照片/截图足够）。
+const MaxAttachmentBytes = 50 << 20
+
+// manualHitTypes 是允许人工录入的命中类型。
+var manualHitTypes = map[model.HitType]bool{
+	model.HitWalletInstalled:        true,
+	model.HitExchangeVisited:        true,
+	model.HitWalletAddress:          true,
+	model.HitTokenBalance:           true,
+	model.HitWalletSuspectedUnknown: true,
+	model.HitNFTHoldings:            true,
+	model.HitManualFinding:          true,
+}
+
+var reUnsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// Input 是一次人工录入的参数。
+type Input struct {
+	CaseID        string
+	DeviceID      string // 为空时优先使用案件本机设备，其次第一台设备，否则登记当前主机
+	HitType       string
+	Value         string
+	Justification string // 必填：录入依据（在哪里、如何发现）
+	Operator      string
+	RuleName      string  // 为空时为“人工录入”
+	Confidence    float64 // 0 表示默认 1.0
+	Verdict       string  // 为空时为 confirmed
+	ObservedAt    int64   // 发现时间（Unix 秒），0 表示录入时间
+
+	// 附件（可选）：AttachmentName 为原始文件名，Attachment 为文件内容。
+	AttachmentName string
+	Attachment     []byte
+	EvidenceRoot   string
+}
+
+// Result 是录入结果。
+type Result struct {
+	HitID      string `json:"hit_id"`
+	CaseID     string `json:"case_id"`
+	DeviceID   string `json:"device_id"`
+	HitType    string `json:"hit_type"`
+	ArtifactID string `json:"artifact_id,omitempty"`
+	SHA256     string `json:"sha256,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+// Validate 校验参数并补齐默认值（不访问数据库）。
+func Validate(in *Input) error {
+	in.CaseID = strings.TrimSpace(in.CaseID)
+	in.HitType = strings.TrimSpace(in.HitType)
+	in.Value = strings.TrimSpace(in.Value)
+	in.Justification = strings.TrimSpace(in.Justification)
+	in.Operator = strings.TrimSpace(in.Operator)
+	in.Verdict = strings.TrimSpace(in.Verdict)
+	in.RuleName = strings.TrimSpace(in.RuleName)
+
+	if in.CaseID == "" {
+		return apperr.New(apperr.CodeInvalidArgument, "case_id is required")
+	}
+	if in.HitType == "" {
+		in.HitType = string(model.HitManualFinding)
+	}
+	if !manualHitTypes[model.HitType(in.HitType)] {
+		return apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unsupported hit_type: %s", in.HitType))
+	}
+	if in.Value == "" {
+		return apperr.New(apperr.CodeInvalidArgument, "value is required")
+	}
+	if in.Justification == "" {
+		return apperr.New(apperr.CodeInvalidArgument, "justification is required")
+	}
+	if in.Operator == "" {
+		in.Operator = "system"
+	}
+	if in.RuleName == "" {
+		in.RuleName = "人工录入"
+	}
+	if in.Confidence == 0 {
+		in.Confidence = 1
+	}
+	if in.Confidence < 0 || in.Confidence > 1 {
+		return apperr.New(apperr.CodeInvalidArgument, "confidence must be within [0,1]")
+	}
+	switch in.Verdict {
+	case "":
+		in.Verdict = "confirmed"
+	case "confirmed", "suspected", "unsupported":
+	default:
+		return apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unsupported verdict: %s", in.Verdict))
+	}
+	if len(in.Attachment) > MaxAttachmentBytes {
+		return apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("attachment too large: max=%d bytes", MaxAttachmentBytes))
+	}
+	if len(in.Attachment) > 0 && strings.TrimSpace(in.EvidenceRoot) == "" {
+		return apperr.New(apperr.CodeInvalidArgument, "evidence root is required for attachments")
+	}
+	return nil
+}
+
+// Create 写入一条人工命中（以及可选的附件证据），并追加审计。
+func Create(ctx context.Context, store *sqliteadapter.Store, in Input) (*Result, error) {
+	if err := Validate(&in); err != nil {
+		return nil, err
+	}
+	ov, err := store.GetCaseOverview(ctx, in.CaseID)
+	if err != nil {
+		return nil, err
+	}
+	if ov == nil {
+		return nil, apperr.T(apperr.CodeNotFound, "case.not_found", in.CaseID)
+	}
+	deviceID, err := resolveDevice(ctx, store, in.CaseID, strings.TrimSpace(in.DeviceID))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	observedAt := in.ObservedAt
+	if observedAt <= 0 {
+		observedAt = now
+	}
+	hitID := id.New("hit")
+	res := &Result{
+		HitID:     hitID,
+		CaseID:    in.CaseID,
+		DeviceID:  deviceID,
+		HitType:   in.HitType,
+		CreatedAt: now,
+	}
+
+	detail := map[string]any{
+		"manual":        true,
+		"justification": in.Justification,
+		"operator":      in.Operator,
+		"entered_at":    now,
+		"observed_at":   observedAt,
+	}
+
+	var artifactIDs []string
+	if len(in.Attachment) > 0 {
+		art, err := saveAttachment(ctx, store, in, deviceID, hitID, now)
+		if err != nil {
+			_ = store.AppendAudit(ctx, in.CaseID, deviceID, "manual_hit", "save_attachment", "failed", in.Operator, "manualhit.Create", map[string]any{
+				"hit_id": hitID,
+				"error":  err.Error(),
+			})
+			return nil, err
+		}
+		res.ArtifactID, res.SHA256 = art.ID, art.SHA256
+		artifactIDs = []string{art.ID}
+		detail["attachment"] = map[string]any{
+			"artifact_id":   art.ID,
+			"original_name": in.AttachmentName,
+			"sha256":        art.SHA256,
+			"size_bytes":    art.SizeBytes,
+		}
+	}
+
+	rawDetail, err := json.Marshal(detail)
+	if err != nil {
+		return nil, fmt.Errorf("marshal detail: %w", err)
+	}
+	hit := model.RuleHit{
+		ID:           hitID,
+		CaseID:       in.CaseID,
+		DeviceID:     deviceID,
+		Type:         model.HitType(in.HitType),
+		RuleID:       model.ManualHitRuleID,
+		RuleName:     in.RuleName,
+		RuleVersion:  RuleVersion,
+		MatchedValue: in.Value,
+		FirstSeenAt:  observedAt,
+		LastSeenAt:   observedAt,
+		Confidence:   in.Confidence,
+		Verdict:      in.Verdict,
+		DetailJSON:   rawDetail,
+		ArtifactIDs:  artifactIDs,
+	}
+	if err := store.SaveRuleHits(ctx, []model.RuleHit{hit}); err != nil {
+		_ = store.AppendAudit(ctx, in.CaseID, deviceID, "manual_hit", "create", "failed", in.Operator, "manualhit.Create", map[string]any{
+			"hit_id": hitID,
+			"error":  err.Error(),
+		})
+		return nil, err
+	}
+
+	// 审计不记录命中值本身（可能是助记词等敏感内容），只记录类型与依据。
+	_ = store.AppendAudit(ctx, in.CaseID, deviceID, "manual_hit", "create", "success", in.Operator, "manualhit.Create", map[string]any{
+		"hit_id":        hitID,
+		"hit_type":      in.HitType,
+		"justification": in.Justification,
+		"artifact_id":   res.ArtifactID,
+		"sha256":        res.SHA256,
+	})
+	return res, nil
+}
+
+// resolveDevice 决定人工命中挂到哪台设备（与链上查询留痕的选择逻辑一致）。
+func resolveDevice(ctx context.Context, store *sqliteadapter.Store, caseID, deviceID string) (string, error) {
+	devices, err := store.ListCaseDevices(ctx, caseID)
+	if err != nil {
+		return "", err
+	}
+	if deviceID != "" {
+		for _, d := range devices {
+			if d.DeviceID == deviceID {
+				return deviceID, nil
+			}
+		}
+		return "", apperr.New(apperr.CodeNotFound, fmt.Sprintf("device not found in case: %s", deviceID))
+	}
+	for _, d := range devices {
+		if strings.TrimSpace(d.ConnectionType) == "local" {
+			return d.DeviceID, nil
+		}
+	}
+	if len(devices) > 0 {
+		return devices[0].DeviceID, nil
+	}
+	dev, err := host.DetectHostDevice()
+	if err != nil {
+		return "", fmt.Errorf("detect host device: %w", err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, dev, true, "host local device (auto)"); err != nil {
+		return "", fmt.Errorf("upsert host device: %w", err)
+	}
+	return dev.ID, nil
+}
+
+// saveAttachment 把附件原样写入证据目录并登记为 manual_evidence 证据。
+func saveAttachment(ctx context.Context, store *sqliteadapter.Store, in Input, deviceID, hitID string, now int64) (*model.Artifact, error) {
+	artifactID := id.New("art")
+	dir := filepath.Join(in.EvidenceRoot, in.CaseID, deviceID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create evidence dir: %w", err)
+	}
+	name := reUnsafeName.ReplaceAllString(filepath.Base(strings.TrimSpace(in.AttachmentName)), "_")
+	if name == "" || name == "." || name == "_" {
+		name = "attachment.bin"
+	}
+	snapshotPath := filepath.Join(dir, fmt.Sprintf("manual_%s_%s", artifactID, name))
+	if err := os.WriteFile(snapshotPath, in.Attachment, 0o644); err != nil {
+		return nil, fmt.Errorf("write evidence file: %w", err)
+	}
+	sum, size, err := hash.File(snapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("hash evidence file: %w", err)
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"hit_id":        hitID,
+		"hit_type":      in.HitType,
+		"original_name": in.AttachmentName,
+		"justification": in.Justification,
+		"operator":      in.Operator,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	collectorName := "manual_entry"
+	collectorVer := "manualhit-" + strings.TrimSpace(app.Version)
+	if strings.TrimSpace(app.Version) == "" {
+		collectorVer = "manualhit-dev"
+	}
+	art := model.Artifact{
+		ID:                artifactID,
+		CaseID:            in.CaseID,
+		DeviceID:          deviceID,
+		Type:              model.ArtifactManualEvidence,
+		SourceRef:         "manual_upload",
+		SnapshotPath:      snapshotPath,
+		SHA256:            sum,
+		SizeBytes:         size,
+		CollectedAt:       now,
+		CollectorName:     collectorName,
+		CollectorVersion:  collectorVer,
+		AcquisitionMethod: "manual_upload",
+		PayloadJSON:       payload,
+		RecordHash: hash.Text(
+			artifactID,
+			in.CaseID,
+			deviceID,
+			string(model.ArtifactManualEvidence),
+			snapshotPath,
+			sum,
+			fmt.Sprintf("%d", size),
+			fmt.Sprintf("%d", now),
+			collectorName,
+			collectorVer,
+			string(payload),
+		),
+	}
+	if err := store.SaveArtifacts(ctx, []model.Artifact{art}); err != nil {
+		return nil, err
+	}
+	return &art, nil
+}
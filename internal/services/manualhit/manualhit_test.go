@@ -0,0 +1,103 @@
+package manualhit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestValidate(t *testing.T) {
+	in := Input{CaseID: "case_1", Value: "abandon abandon ...", Justification: " "}
+	if err := Validate(&in); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("missing justification err=%v", err)
+	}
+	in.Justification = "seed phrase on paper in desk drawer"
+	if err := Validate(&in); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if in.HitType != string(model.HitManualFinding) || in.Verdict != "confirmed" || in.Confidence != 1 || in.Operator != "system" {
+		t.Fatalf("defaults=%+v", in)
+	}
+	bad := Input{CaseID: "case_1", HitType: "rule_made_up", Value: "x", Justification: "y"}
+	if err := Validate(&bad); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("unsupported hit type err=%v", err)
+	}
+}
+
+func TestCreateWithAttachment(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatalf("EnsureCase: %v", err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "host", OS: model.OSType("windows"), Identifier: "h"}, true, ""); err != nil {
+		t.Fatalf("UpsertDevice: %v", err)
+	}
+
+	res, err := Create(ctx, store, Input{
+		CaseID:         caseID,
+		Value:          "abandon ability able",
+		Justification:  "photographed paper note on desk",
+		Operator:       "alice",
+		AttachmentName: "../IMG 0001.jpg",
+		Attachment:     []byte("\xff\xd8\xff\xe0fake-jpeg"),
+		EvidenceRoot:   filepath.Join(dir, "evidence"),
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if res.DeviceID != "dev_1" || res.ArtifactID == "" || len(res.SHA256) != 64 {
+		t.Fatalf("res=%+v", res)
+	}
+
+	info, err := store.GetArtifactInfo(ctx, res.ArtifactID)
+	if err != nil || info == nil {
+		t.Fatalf("GetArtifactInfo: %v %v", info, err)
+	}
+	if info.ArtifactType != string(model.ArtifactManualEvidence) || filepath.Dir(info.SnapshotPath) != filepath.Join(dir, "evidence", caseID, "dev_1") {
+		t.Fatalf("artifact=%+v", info)
+	}
+	if b, _ := os.ReadFile(info.SnapshotPath); string(b) != "\xff\xd8\xff\xe0fake-jpeg" {
+		t.Fatalf("snapshot content=%q", b)
+	}
+
+	hits, err := store.ListCaseHitDetails(ctx, caseID, string(model.HitManualFinding))
+	if err != nil || len(hits) != 1 {
+		t.Fatalf("hits=%v err=%v", hits, err)
+	}
+	h := hits[0]
+	if !h.Manual || h.RuleID != model.ManualHitRuleID || h.MatchedValue != "abandon ability able" || len(h.ArtifactIDs) != 1 || h.ArtifactIDs[0] != res.ArtifactID {
+		t.Fatalf("hit=%+v", h)
+	}
+	var detail map[string]any
+	if err := json.Unmarshal([]byte(h.DetailJSON), &detail); err != nil {
+		t.Fatalf("detail: %v", err)
+	}
+	if detail["manual"] != true || detail["justification"] != "photographed paper note on desk" || detail["operator"] != "alice" {
+		t.Fatalf("detail=%v", detail)
+	}
+
+	if _, err := Create(ctx, store, Input{CaseID: caseID, DeviceID: "dev_x", Value: "v", Justification: "j"}); apperr.CodeOf(err) != apperr.CodeNotFound {
+		t.Fatalf("unknown device err=%v", err)
+	}
+}
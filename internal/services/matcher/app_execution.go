@@ -0,0 +1,232 @@
+package matcher
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"crypto-inspector/internal/adapters/rules"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 钱包运行痕迹
+//
+// app_execution 证据来自 LaunchServices 登记、Dock、Saved Application State 与废纸篓（见 host/mac_execution.go），
+// app_remnants 证据来自钥匙串应用密码标签与 TCC 授权记录（见 host/mac_remnants.go）。
+// 与 wallet_installed 不同，这些痕迹在 .app 被删除后仍然存在，因此单独输出 wallet_executed 命中：
+// - 按钱包关键词匹配应用名、bundle id 与 .app 文件名（钥匙串条目按标签，TCC 按 bundle id / 可执行文件路径）
+// - 同一设备、同一钱包、同一应用的多条痕迹合并为一个命中，detail.sources 列出全部来源
+// - saved_state / dock_recent / keychain / tcc 说明应用确实运行过，置信度在关键词置信度基础上加分
+
+const (
+	executionRunBoost = 0.05
+	executionCap      = 0.99
+)
+
+// executionRunSources 是能说明应用确实运行过的来源（任一存在即加分）。
+var executionRunSources = []string{"saved_state", "dock_recent", "keychain", "tcc"}
+
+// executionGroup 是同一应用在多个来源中的痕迹合并结果。
+type executionGroup struct {
+	caseID, deviceID string
+	wallet           model.WalletSignature
+	matchedValue     string
+	matchedKeyword   string
+	bundleID         string
+	path             string
+	inTrash          bool
+	first, last      int64
+	sources          map[string]struct{}
+	artifactIDs      map[string]struct{}
+}
+
+// matchWalletExecution 把钱包应用的运行/登记痕迹固化为 wallet_executed 命中。
+func matchWalletExecution(loaded *rules.LoadedRules, artifacts []model.Artifact, agg map[string]*hitAccumulator) {
+	groups := map[string]*executionGroup{}
+	firstByWallet := map[string]string{} // device|wallet -> 第一个分组
+	var order []string
+	// 先处理 app_execution，钥匙串/TCC 痕迹再并入同一设备、同一钱包已有的分组（它们的标签/bundle id 与应用名不同）。
+	for _, t := range []model.ArtifactType{model.ArtifactAppExecution, model.ArtifactAppRemnants} {
+		for _, a := range artifacts {
+			if a.Type != t {
+				continue
+			}
+			for _, rec := range executionRecords(a) {
+				wr, kw, ok := walletForExecution(loaded, rec)
+				if !ok {
+					continue
+				}
+				value := executionDisplayName(rec)
+				key := hitKey(a.DeviceID, wr.ID, value)
+				if t == model.ArtifactAppRemnants {
+					if k, ok := firstByWallet[hitKey(a.DeviceID, wr.ID)]; ok {
+						key = k
+					}
+				}
+				g, ok := groups[key]
+				if !ok {
+					g = &executionGroup{
+						caseID:         a.CaseID,
+						deviceID:       a.DeviceID,
+						wallet:         wr,
+						matchedValue:   value,
+						matchedKeyword: kw,
+						sources:        map[string]struct{}{},
+						artifactIDs:    map[string]struct{}{},
+					}
+					groups[key] = g
+					order = append(order, key)
+					if _, ok := firstByWallet[hitKey(a.DeviceID, wr.ID)]; !ok {
+						firstByWallet[hitKey(a.DeviceID, wr.ID)] = key
+					}
+				}
+				g.sources[rec.Source] = struct{}{}
+				g.artifactIDs[a.ID] = struct{}{}
+				if g.bundleID == "" {
+					g.bundleID = rec.BundleID
+				}
+				// 优先保留废纸篓中的路径：它直接说明 .app 被删除。
+				if rec.Path != "" && (g.path == "" || (rec.InTrash && !g.inTrash)) {
+					g.path = rec.Path
+				}
+				g.inTrash = g.inTrash || rec.InTrash
+				ts := rec.LastUsedAt
+				if ts <= 0 {
+					ts = a.CollectedAt
+				}
+				if ts > 0 && (g.first == 0 || ts < g.first) {
+					g.first = ts
+				}
+				if ts > g.last {
+					g.last = ts
+				}
+			}
+		}
+	}
+
+	for _, key := range order {
+		g := groups[key]
+		sources := setToSortedSlice(g.sources)
+		conf := walletConf(g.wallet.Confidence.KeywordMatch, loaded.Wallet.Meta.ConfidenceDefaults.KeywordMatch, 0.7)
+		for _, src := range executionRunSources {
+			if _, ok := g.sources[src]; ok {
+				conf += executionRunBoost
+				break
+			}
+		}
+		if conf > executionCap {
+			conf = executionCap
+		}
+		verdict := "suspected"
+		if conf >= 0.85 {
+			verdict = "confirmed"
+		}
+		addOrUpdateHit(agg, hitKey(string(model.HitWalletExecuted), key), model.RuleHit{
+			ID:           id.New("hit"),
+			CaseID:       g.caseID,
+			DeviceID:     g.deviceID,
+			Type:         model.HitWalletExecuted,
+			RuleID:       g.wallet.ID,
+			RuleName:     g.wallet.Name,
+			RuleVersion:  loaded.Wallet.Version,
+			MatchedValue: g.matchedValue,
+			FirstSeenAt:  g.first,
+			LastSeenAt:   g.last,
+			Confidence:   conf,
+			Verdict:      verdict,
+			DetailJSON: walletDetailJSON(g.wallet, map[string]any{
+				"match_field":     "app_execution",
+				"matched_keyword": g.matchedKeyword,
+				"sources":         sources,
+				"bundle_id":       g.bundleID,
+				"path":            g.path,
+				"in_trash":        g.inTrash,
+			}),
+			ArtifactIDs: setToSortedSlice(g.artifactIDs),
+		})
+	}
+}
+
+// executionRecords 取出证据中的运行痕迹；app_remnants 按同一结构转换：
+// 钥匙串标签作为应用名，TCC 客户端按类型作为 bundle id 或路径（路径取所在 .app）。
+func executionRecords(a model.Artifact) []model.AppExecutionRecord {
+	if len(a.PayloadJSON) == 0 {
+		return nil
+	}
+	switch a.Type {
+	case model.ArtifactAppExecution:
+		var records []model.AppExecutionRecord
+		if err := json.Unmarshal(a.PayloadJSON, &records); err != nil {
+			return nil
+		}
+		return records
+	case model.ArtifactAppRemnants:
+		var remnants []model.AppRemnantRecord
+		if err := json.Unmarshal(a.PayloadJSON, &remnants); err != nil {
+			return nil
+		}
+		out := make([]model.AppExecutionRecord, 0, len(remnants))
+		for _, r := range remnants {
+			rec := model.AppExecutionRecord{Source: r.Source, LastUsedAt: r.ModifiedAt, SourcePath: r.SourcePath}
+			switch {
+			case r.Source == "keychain":
+				rec.Name = r.Label
+			case r.ClientType == "path":
+				rec.Path = appBundlePath(r.Client)
+			default:
+				rec.BundleID = r.Client
+			}
+			out = append(out, rec)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// appBundlePath 把 /Applications/Exodus.app/Contents/MacOS/Exodus 截到 .app；不在 .app 内时原样返回。
+func appBundlePath(p string) string {
+	if i := strings.Index(p, ".app/"); i >= 0 {
+		return p[:i+len(".app")]
+	}
+	return p
+}
+
+// walletForExecution 返回关键词命中的第一条启用钱包规则。
+func walletForExecution(loaded *rules.LoadedRules, rec model.AppExecutionRecord) (model.WalletSignature, string, bool) {
+	base := strings.TrimSuffix(filepath.Base(filepath.ToSlash(rec.Path)), ".app")
+	if rec.Path == "" {
+		base = ""
+	}
+	searchBase := foldName(strings.Join([]string{rec.Name, rec.BundleID, base}, " "))
+	if searchBase == "" {
+		return model.WalletSignature{}, "", false
+	}
+	for _, wr := range loaded.Wallet.Wallets {
+		if !wr.Enabled {
+			continue
+		}
+		for _, kw := range normalizedKeywords(wr) {
+			if kw != "" && strings.Contains(searchBase, kw) {
+				return wr, kw, true
+			}
+		}
+	}
+	return model.WalletSignature{}, "", false
+}
+
+// executionDisplayName 选择命中值：应用名 > .app 文件名 > bundle id。
+func executionDisplayName(rec model.AppExecutionRecord) string {
+	candidates := []string{rec.Name}
+	if rec.Path != "" {
+		candidates = append(candidates, strings.TrimSuffix(filepath.Base(filepath.ToSlash(rec.Path)), ".app"))
+	}
+	candidates = append(candidates, rec.BundleID)
+	for _, c := range candidates {
+		if c = strings.TrimSpace(c); c != "" {
+			return c
+		}
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+package matcher
+
+import (
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/adapters/rules"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 交易所桌面客户端
+//
+// 交易所匹配原本只看浏览记录，使用桌面客户端（多为 Electron）交易时浏览器里没有痕迹。
+// 这里按 exchange_domains 中的 desktop 线索匹配已安装软件，输出 exchange_app_installed 命中：
+// - bundle id 完全一致或安装路径命中：app_direct（默认 0.95）
+// - 程序名关键词命中：app_keyword（默认 0.80）
+
+// matchExchangeApps 基于已安装软件匹配交易所桌面客户端。
+func matchExchangeApps(loaded *rules.LoadedRules, apps []model.AppRecord, artifacts []model.Artifact, agg map[string]*hitAccumulator) {
+	if len(apps) == 0 {
+		return
+	}
+	artifactIDs := artifactIDsByType(artifacts, map[model.ArtifactType]struct{}{
+		model.ArtifactInstalledApps: {},
+	})
+	defaults := loaded.Exchange.Meta.ConfidenceDefaults
+	now := time.Now().Unix()
+
+	for _, exr := range loaded.Exchange.Exchanges {
+		if !exr.Enabled {
+			continue
+		}
+		desktop := exr.Desktop
+		if len(desktop.AppKeywords) == 0 && len(exr.LocalizedAliases) == 0 && len(desktop.BundleIDs) == 0 && len(desktop.InstallPathsWindows) == 0 && len(desktop.InstallPathsMacOS) == 0 {
+			continue
+		}
+		installPaths := append(append([]string{}, desktop.InstallPathsWindows...), desktop.InstallPathsMacOS...)
+		// 本地化别名与程序名关键词同等计分（本地化系统上客户端以“欧易”“바이낸스”等名称安装）。
+		keywords := append(append([]string{}, desktop.AppKeywords...), exr.LocalizedAliases...)
+
+		for _, app := range apps {
+			field, matched := matchExchangeApp(desktop, installPaths, keywords, app)
+			if field == "" {
+				continue
+			}
+			conf := exchangeConf(exr.Confidence.AppDirect, defaults.AppDirect, 0.95)
+			if field == "app_keyword" {
+				conf = exchangeConf(exr.Confidence.AppKeyword, defaults.AppKeyword, 0.80)
+			}
+			verdict := "suspected"
+			if conf >= 0.85 {
+				verdict = "confirmed"
+			}
+			matchedValue := strings.TrimSpace(app.Name)
+			if matchedValue == "" {
+				matchedValue = matched
+			}
+			installPath := app.InstallLocation
+			if installPath == "" {
+				installPath = app.Path
+			}
+
+			addOrUpdateHit(agg, hitKey(string(model.HitExchangeAppInstalled), exr.ID, matchedValue), model.RuleHit{
+				ID:           id.New("hit"),
+				CaseID:       firstCaseID(artifacts),
+				DeviceID:     firstDeviceID(artifacts),
+				Type:         model.HitExchangeAppInstalled,
+				RuleID:       exr.ID,
+				RuleName:     exr.Name,
+				RuleVersion:  loaded.Exchange.Version,
+				MatchedValue: matchedValue,
+				FirstSeenAt:  now,
+				LastSeenAt:   now,
+				Confidence:   conf,
+				Verdict:      verdict,
+				DetailJSON: mustJSON(map[string]any{
+					"match_field":     field,
+					"matched":         matched,
+					"localized_alias": field == "app_keyword" && isLocalizedKeyword(exr.LocalizedAliases, matched),
+					"name_script":     nameScript(app.Name),
+					"version":         app.Version,
+					"publisher":       app.Publisher,
+					"bundle_id":       app.BundleID,
+					"install_path":    installPath,
+					"install_date":    app.InstallDate,
+				}),
+				ArtifactIDs: artifactIDs,
+			})
+		}
+	}
+}
+
+// matchExchangeApp 依次尝试 bundle id、安装路径与程序名关键词（keywords，按 foldName 折叠后包含匹配），返回命中字段与命中的规则值。
+func matchExchangeApp(desktop model.ExchangeDesktopHints, installPaths, keywords []string, app model.AppRecord) (string, string) {
+	if bid := strings.TrimSpace(app.BundleID); bid != "" {
+		for _, want := range desktop.BundleIDs {
+			if strings.EqualFold(bid, strings.TrimSpace(want)) {
+				return "bundle_id", want
+			}
+		}
+	}
+	for _, p := range []string{app.InstallLocation, app.Path} {
+		have := normalizeInstallPath(p)
+		if have == "" {
+			continue
+		}
+		for _, want := range installPaths {
+			w := normalizeInstallPath(stripPathRoot(want))
+			if w != "" && (strings.HasSuffix(have, w) || strings.Contains(have, w+"/")) {
+				return "install_path", want
+			}
+		}
+	}
+	name := foldName(app.Name)
+	if name == "" {
+		return "", ""
+	}
+	for _, kw := range keywords {
+		kw = foldName(kw)
+		if kw != "" && strings.Contains(name, kw) {
+			return "app_keyword", kw
+		}
+	}
+	return "", ""
+}
+
+// stripPathRoot 去掉规则路径开头的 %VAR% 或 ~（不同用户/盘符下展开结果不同，只比较其后的部分）。
+func stripPathRoot(p string) string {
+	p = strings.TrimSpace(p)
+	if strings.HasPrefix(p, "%") {
+		if i := strings.Index(p[1:], "%"); i >= 0 {
+			return p[i+2:]
+		}
+	}
+	return strings.TrimPrefix(p, "~")
+}
+
+// normalizeInstallPath 统一分隔符与大小写，并去掉末尾的分隔符。
+func normalizeInstallPath(p string) string {
+	p = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(p), `\`, "/"))
+	return strings.TrimRight(p, "/")
+}
@@ -0,0 +1,126 @@
+package matcher
+
+import (
+	"net/url"
+	"path"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+// bestExchangeCondition 在域名已命中（exact/root）的前提下评估交易所结构化条件，
+// 返回置信度最高的命中条件；conf 为域名命中的置信度（条件未配置置信度时沿用）。
+func bestExchangeCondition(conds []model.ExchangeCondition, domain, rawURL string, targets []string, conf float64) (model.ExchangeCondition, float64, bool) {
+	if len(conds) == 0 {
+		return model.ExchangeCondition{}, 0, false
+	}
+	sub := exchangeSubdomain(domain, targets)
+	u := parseVisitURL(rawURL)
+	var best model.ExchangeCondition
+	bestConf, found := 0.0, false
+	for _, c := range conds {
+		if !exchangeConditionMatches(c, sub, u) {
+			continue
+		}
+		cc := c.Confidence
+		if cc <= 0 {
+			cc = conf
+		}
+		if !found || cc > bestConf {
+			best, bestConf, found = c, cc, true
+		}
+	}
+	return best, bestConf, found
+}
+
+func exchangeConditionMatches(c model.ExchangeCondition, sub string, u *url.URL) bool {
+	if p := strings.ToLower(strings.TrimSpace(c.Subdomain)); p != "" {
+		if ok, _ := path.Match(p, sub); !ok {
+			return false
+		}
+	}
+	if p := strings.TrimSpace(c.PathPrefix); p != "" {
+		if u == nil || !pathPrefixMatches(p, u.Path) {
+			return false
+		}
+	}
+	if len(c.QueryParams) > 0 {
+		if u == nil {
+			return false
+		}
+		q := u.Query()
+		for _, marker := range c.QueryParams {
+			key, want, hasValue := strings.Cut(strings.TrimSpace(marker), "=")
+			vals, ok := q[key]
+			if !ok {
+				return false
+			}
+			if hasValue && !containsFold(vals, want) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// exchangeSubdomain 返回域名相对于最短规则域名的子域部分（例如 accounts.binance.com -> accounts）。
+func exchangeSubdomain(domain string, targets []string) string {
+	sub, matched := "", false
+	for _, t := range targets {
+		var s string
+		switch {
+		case domain == t:
+			s = ""
+		case strings.HasSuffix(domain, "."+t):
+			s = strings.TrimSuffix(domain, "."+t)
+		default:
+			continue
+		}
+		if !matched || len(s) > len(sub) {
+			sub, matched = s, true
+		}
+	}
+	return sub
+}
+
+// pathPrefixMatches 按段比较路径前缀（忽略大小写），模式中的 * 匹配任意单段（如语言段 zh-CN/en）。
+func pathPrefixMatches(pattern, p string) bool {
+	want := strings.Split(strings.Trim(strings.ToLower(pattern), "/"), "/")
+	got := strings.Split(strings.Trim(strings.ToLower(p), "/"), "/")
+	if len(want) == 1 && want[0] == "" {
+		return true
+	}
+	if len(got) < len(want) {
+		return false
+	}
+	for i, w := range want {
+		if w != "*" && w != got[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func parseVisitURL(raw string) *url.URL {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+func containsFold(vals []string, want string) bool {
+	for _, v := range vals {
+		if strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
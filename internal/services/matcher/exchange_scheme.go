@@ -0,0 +1,93 @@
+package matcher
+
+import (
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/adapters/rules"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 交易所移动 App 的 URL scheme / 深度链接
+//
+// mobile_packages 记录带有应用声明的 url_schemes / app_link_hosts 时，按交易所规则匹配，输出 exchange_app_installed 命中：
+// - scheme 与规则 mobile.url_schemes 完全一致（忽略大小写）：match_field=url_scheme
+// - App Links 域名命中规则 domains（完全一致或为其子域名）：match_field=app_link_host
+// 两者都说明该 App 已安装且注册了交易所的唤起入口，置信度取 app_direct（默认 0.95）。
+
+// matchExchangeSchemes 基于移动端应用声明的 scheme 与 App Links 域名匹配交易所 App。
+func matchExchangeSchemes(loaded *rules.LoadedRules, pkgsByDev map[string][]model.MobilePackageRecord, artifactIDsByDev map[string][]string, caseID string, agg map[string]*hitAccumulator) {
+	defaults := loaded.Exchange.Meta.ConfidenceDefaults
+	now := time.Now().Unix()
+
+	for _, exr := range loaded.Exchange.Exchanges {
+		if !exr.Enabled {
+			continue
+		}
+		schemes := toSet(exr.Mobile.URLSchemes)
+		domains := make([]string, 0, len(exr.Domains))
+		for _, d := range exr.Domains {
+			if n := normalizeDomain(d); n != "" {
+				domains = append(domains, n)
+			}
+		}
+
+		for deviceID, rows := range pkgsByDev {
+			for _, pkg := range rows {
+				field, matched := matchExchangeScheme(schemes, domains, pkg)
+				if field == "" {
+					continue
+				}
+				p := strings.ToLower(strings.TrimSpace(pkg.Package))
+				conf := exchangeConf(exr.Confidence.AppDirect, defaults.AppDirect, 0.95)
+				verdict := "suspected"
+				if conf >= 0.85 {
+					verdict = "confirmed"
+				}
+				addOrUpdateHit(agg, hitKey(string(model.HitExchangeAppInstalled), deviceID, exr.ID, p, string(pkg.OS)), model.RuleHit{
+					ID:           id.New("hit"),
+					CaseID:       caseID,
+					DeviceID:     deviceID,
+					Type:         model.HitExchangeAppInstalled,
+					RuleID:       exr.ID,
+					RuleName:     exr.Name,
+					RuleVersion:  loaded.Exchange.Version,
+					MatchedValue: p,
+					FirstSeenAt:  now,
+					LastSeenAt:   now,
+					Confidence:   conf,
+					Verdict:      verdict,
+					DetailJSON: mustJSON(map[string]any{
+						"match_field":    field,
+						"matched":        matched,
+						"os":             pkg.OS,
+						"identifier":     pkg.Identifier,
+						"url_schemes":    pkg.URLSchemes,
+						"app_link_hosts": pkg.AppLinkHosts,
+					}),
+					ArtifactIDs: artifactIDsByDev[deviceID],
+				})
+			}
+		}
+	}
+}
+
+// matchExchangeScheme 先比对 scheme，再比对 App Links 域名，返回命中字段与命中值。
+func matchExchangeScheme(schemes map[string]struct{}, domains []string, pkg model.MobilePackageRecord) (string, string) {
+	for _, sc := range pkg.URLSchemes {
+		sc = strings.ToLower(strings.TrimSpace(sc))
+		if _, ok := schemes[sc]; ok {
+			return "url_scheme", sc
+		}
+	}
+	for _, h := range pkg.AppLinkHosts {
+		h = normalizeDomain(h)
+		for _, d := range domains {
+			if h == d || strings.HasSuffix(h, "."+d) {
+				return "app_link_host", h
+			}
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,123 @@
+package matcher
+
+import (
+	"encoding/json"
+	"strings"
+
+	"crypto-inspector/internal/adapters/rules"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 交易所表单交互
+//
+// browser_form_data 记录的是浏览器保存过的登录表单来源与自动填充资料（不含填写值）。
+// 与浏览记录相比，它说明用户在该站点上提交过表单，因此单独输出 exchange_form_activity 命中：
+// - 来源域名与交易所规则域名完全一致或为其子域名时命中
+// - 表单地址或字段名出现充值/提现/转账等词时视为交易相关表单，置信度更高
+// 形近域名、url_contains 只用于浏览记录，这里不做宽松匹配。
+
+const (
+	formActivityConfidence              = 0.90
+	formActivityTransactionalConfidence = 0.97
+)
+
+// transactionalFormTokens 是判断“交易相关表单”的关键词（匹配 URL 与字段名，忽略大小写）。
+// 不含单独的 "address"：登录表单字段常为 email_address，会造成误判。
+var transactionalFormTokens = []string{"withdraw", "deposit", "recharge", "transfer", "payout", "amount", "wallet_address"}
+
+// matchExchangeFormActivity 把保存过表单的交易所域名固化为 exchange_form_activity 命中。
+func matchExchangeFormActivity(loaded *rules.LoadedRules, artifacts []model.Artifact, agg map[string]*hitAccumulator) {
+	for _, a := range artifacts {
+		if a.Type != model.ArtifactBrowserFormData || len(a.PayloadJSON) == 0 {
+			continue
+		}
+		var records []model.FormDataRecord
+		if err := json.Unmarshal(a.PayloadJSON, &records); err != nil {
+			continue
+		}
+		for _, rec := range records {
+			domain := normalizeDomain(rec.Domain)
+			if domain == "" {
+				continue
+			}
+			exr, ok := exchangeForDomain(loaded, domain)
+			if !ok {
+				continue
+			}
+
+			transactional := isTransactionalForm(rec)
+			confidence := formActivityConfidence
+			if transactional {
+				confidence = formActivityTransactionalConfidence
+			}
+			first := rec.CreatedAt
+			last := rec.LastUsedAt
+			if last < first {
+				last = first
+			}
+			if first <= 0 {
+				first = last
+			}
+			if first <= 0 {
+				first = a.CollectedAt
+				last = a.CollectedAt
+			}
+			addOrUpdateHit(agg, hitKey(string(model.HitExchangeFormActivity), a.DeviceID, exr.ID, domain), model.RuleHit{
+				ID:           id.New("hit"),
+				CaseID:       a.CaseID,
+				DeviceID:     a.DeviceID,
+				Type:         model.HitExchangeFormActivity,
+				RuleID:       exr.ID,
+				RuleName:     exr.Name,
+				RuleVersion:  loaded.Exchange.Version,
+				MatchedValue: domain,
+				FirstSeenAt:  first,
+				LastSeenAt:   last,
+				Confidence:   confidence,
+				Verdict:      "confirmed",
+				DetailJSON: mustJSON(map[string]any{
+					"source":        rec.Source,
+					"browser":       rec.Browser,
+					"profile":       rec.Profile,
+					"origin":        rec.Origin,
+					"action_url":    rec.ActionURL,
+					"field_names":   rec.FieldNames,
+					"use_count":     rec.UseCount,
+					"transactional": transactional,
+				}),
+				ArtifactIDs: []string{a.ID},
+			})
+		}
+	}
+}
+
+// exchangeForDomain 返回域名完全一致或为其子域名的第一条启用交易所规则。
+func exchangeForDomain(loaded *rules.LoadedRules, domain string) (model.ExchangeDomain, bool) {
+	for _, exr := range loaded.Exchange.Exchanges {
+		if !exr.Enabled {
+			continue
+		}
+		for _, d := range exr.Domains {
+			t := normalizeDomain(d)
+			if t != "" && (domain == t || strings.HasSuffix(domain, "."+t)) {
+				return exr, true
+			}
+		}
+	}
+	return model.ExchangeDomain{}, false
+}
+
+// isTransactionalForm 判断表单地址或字段名是否与充值/提现/转账相关。
+func isTransactionalForm(rec model.FormDataRecord) bool {
+	texts := append([]string{rec.Origin, rec.ActionURL}, rec.FieldNames...)
+	for _, t := range texts {
+		lower := strings.ToLower(t)
+		for _, token := range transactionalFormTokens {
+			if strings.Contains(lower, token) {
+				return true
+			}
+		}
+	}
+	return false
+}
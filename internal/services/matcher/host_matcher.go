@@ -3,6 +3,7 @@ package matcher
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"regexp"
 	"sort"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"crypto-inspector/internal/adapters/rules"
 	"crypto-inspector/internal/domain/model"
 	"crypto-inspector/internal/platform/id"
+	"crypto-inspector/internal/platform/idn"
 )
 
 // HostMatchResult 表示主机证据匹配后的命中集合。
@@ -20,7 +22,7 @@ type HostMatchResult struct {
 
 // MatchHostArtifacts 是主机匹配入口：
 // - 先按证据类型反序列化
-// - 再分别执行钱包命中、交易所命中
+// - 再分别执行钱包命中、钱包运行痕迹、未知钱包启发式判定、交易所命中、交易所桌面客户端、Telegram/Discord 交易社群、交易所表单交互、地址抽取与自定义正则规则
 // - 最后聚合去重
 func MatchHostArtifacts(loaded *rules.LoadedRules, artifacts []model.Artifact) (*HostMatchResult, error) {
 	apps, extensions, visits, err := decodeArtifacts(artifacts)
@@ -31,8 +33,13 @@ func MatchHostArtifacts(loaded *rules.LoadedRules, artifacts []model.Artifact) (
 	agg := make(map[string]*hitAccumulator)
 
 	matchWallets(loaded, apps, extensions, artifacts, agg)
-	matchExchanges(loaded, visits, artifacts, agg)
-	matchWalletAddresses(visits, artifacts, agg)
+	matchWalletExecution(loaded, artifacts, agg)
+	classifyUnknownApps(apps, artifacts, agg)
+	matchBrowsingActivity(loaded, visits, artifacts, agg)
+	matchExchangeApps(loaded, apps, artifacts, agg)
+	matchMessengerCommunities(loaded, artifacts, agg)
+	matchExchangeFormActivity(loaded, artifacts, agg)
+	matchRegexRules(loaded, artifacts, agg)
 
 	hits := make([]model.RuleHit, 0, len(agg))
 	for _, a := range agg {
@@ -58,6 +65,15 @@ var (
 	reBTCBase58 = regexp.MustCompile(`[13][1-9A-HJ-NP-Za-km-z]{25,34}`)
 )
 
+// matchBrowsingActivity 对浏览记录执行交易所访问匹配与钱包地址抽取。
+//
+// 主机与移动端共用这一入口，保证同样的浏览记录在两端得到相同的命中；
+// artifacts 只应包含同一台设备的证据（命中的 device_id 与关联证据均取自其中）。
+func matchBrowsingActivity(loaded *rules.LoadedRules, visits []model.VisitRecord, artifacts []model.Artifact, agg map[string]*hitAccumulator) {
+	matchExchanges(loaded, visits, artifacts, agg)
+	matchWalletAddresses(visits, artifacts, agg)
+}
+
 // matchWalletAddresses 从浏览历史中抽取“疑似钱包地址”并固化为命中。
 //
 // 说明：
@@ -68,7 +84,9 @@ func matchWalletAddresses(visits []model.VisitRecord, artifacts []model.Artifact
 		return
 	}
 	artifactIDs := artifactIDsByType(artifacts, map[model.ArtifactType]struct{}{
-		model.ArtifactBrowserHistory: {},
+		model.ArtifactBrowserHistory:   {},
+		model.ArtifactBrowserBookmarks: {},
+		model.ArtifactMobileAccounts:   {},
 	})
 	now := time.Now().Unix()
 
@@ -86,10 +104,28 @@ func matchWalletAddresses(visits []model.VisitRecord, artifacts []model.Artifact
 			{Field: "title", Text: v.Title},
 		}
 		for _, src := range sources {
-			text := src.Text
-			if strings.TrimSpace(text) == "" {
+			raw := src.Text
+			if strings.TrimSpace(raw) == "" {
 				continue
 			}
+			// 百分号编码/全角字符归一化后再抽取（见 textnorm.go）。
+			text := normalizeAddressText(raw)
+			detail := func(extra map[string]any) []byte {
+				d := map[string]any{
+					"match_field": src.Field,
+					"browser":     v.Browser,
+					"profile":     v.Profile,
+					"visited_at":  v.VisitedAt,
+					"sample":      truncateText(raw, 240),
+				}
+				if text != raw {
+					d["decoded_sample"] = truncateText(text, 240)
+				}
+				for k, val := range extra {
+					d[k] = val
+				}
+				return mustJSON(d)
+			}
 
 			// EVM 0x... 地址
 			for _, m := range reEVMAddress.FindAllString(text, -1) {
@@ -108,15 +144,8 @@ func matchWalletAddresses(visits []model.VisitRecord, artifacts []model.Artifact
 					LastSeenAt:   first,
 					Confidence:   0.80,
 					Verdict:      "suspected",
-					DetailJSON: mustJSON(map[string]any{
-						"chain":       "evm",
-						"match_field": src.Field,
-						"browser":     v.Browser,
-						"profile":     v.Profile,
-						"visited_at":  v.VisitedAt,
-						"sample":      truncateText(text, 240),
-					}),
-					ArtifactIDs: artifactIDs,
+					DetailJSON:   detail(map[string]any{"chain": "evm"}),
+					ArtifactIDs:  artifactIDs,
 				})
 			}
 
@@ -137,16 +166,8 @@ func matchWalletAddresses(visits []model.VisitRecord, artifacts []model.Artifact
 					LastSeenAt:   first,
 					Confidence:   0.85,
 					Verdict:      "suspected",
-					DetailJSON: mustJSON(map[string]any{
-						"chain":       "btc",
-						"format":      "bech32",
-						"match_field": src.Field,
-						"browser":     v.Browser,
-						"profile":     v.Profile,
-						"visited_at":  v.VisitedAt,
-						"sample":      truncateText(text, 240),
-					}),
-					ArtifactIDs: artifactIDs,
+					DetailJSON:   detail(map[string]any{"chain": "btc", "format": "bech32"}),
+					ArtifactIDs:  artifactIDs,
 				})
 			}
 
@@ -183,16 +204,8 @@ func matchWalletAddresses(visits []model.VisitRecord, artifacts []model.Artifact
 					LastSeenAt:   first,
 					Confidence:   0.80,
 					Verdict:      "suspected",
-					DetailJSON: mustJSON(map[string]any{
-						"chain":       "btc",
-						"format":      "base58",
-						"match_field": src.Field,
-						"browser":     v.Browser,
-						"profile":     v.Profile,
-						"visited_at":  v.VisitedAt,
-						"sample":      truncateText(text, 240),
-					}),
-					ArtifactIDs: artifactIDs,
+					DetailJSON:   detail(map[string]any{"chain": "btc", "format": "base58"}),
+					ArtifactIDs:  artifactIDs,
 				})
 			}
 		}
@@ -234,7 +247,7 @@ type hitAccumulator struct {
 
 // decodeArtifacts 将统一 Artifact 还原为结构化业务记录。
 func decodeArtifacts(artifacts []model.Artifact) (apps []model.AppRecord, extensions []model.ExtensionRecord, visits []model.VisitRecord, err error) {
-	for _, a := range artifacts {
+	for _, a := range orderArtifactParts(artifacts) {
 		switch a.Type {
 		case model.ArtifactInstalledApps:
 			var rows []model.AppRecord
@@ -260,6 +273,36 @@ func decodeArtifacts(artifacts []model.Artifact) (apps []model.AppRecord, extens
 	return apps, extensions, visits, nil
 }
 
+// orderArtifactParts 把同一分片组的证据按分片序号排在该组首次出现的位置，其余证据顺序不变。
+//
+// 分片本身都是合法的 JSON 数组，按序号依次解码追加即还原拆分前的记录顺序（见 snapshot.SplitJSONArray）。
+func orderArtifactParts(artifacts []model.Artifact) []model.Artifact {
+	groups := map[string][]model.Artifact{}
+	for _, a := range artifacts {
+		if a.PartGroup != "" {
+			groups[a.PartGroup] = append(groups[a.PartGroup], a)
+		}
+	}
+	if len(groups) == 0 {
+		return artifacts
+	}
+	out := make([]model.Artifact, 0, len(artifacts))
+	for _, a := range artifacts {
+		if a.PartGroup == "" {
+			out = append(out, a)
+			continue
+		}
+		parts, ok := groups[a.PartGroup]
+		if !ok {
+			continue
+		}
+		sort.SliceStable(parts, func(i, j int) bool { return parts[i].PartNo < parts[j].PartNo })
+		out = append(out, parts...)
+		delete(groups, a.PartGroup)
+	}
+	return out
+}
+
 // matchWallets 匹配两类钱包线索：
 // 1) 浏览器扩展 ID（高置信）
 // 2) 应用名/路径关键词（中置信）
@@ -294,6 +337,16 @@ func matchWallets(loaded *rules.LoadedRules, apps []model.AppRecord, extensions
 				continue
 			}
 
+			detail := map[string]any{
+				"match_field": "browser_extension_id",
+				"browser":     ex.Browser,
+				"profile":     ex.Profile,
+			}
+			// 在线扫描的 Chromium 系扩展带用户数据目录的发现途径（默认目录/运行中进程/便携版目录），报告据此区分小众或便携浏览器。
+			if ex.Source != "" {
+				detail["browser_source"] = ex.Source
+				detail["extension_path"] = ex.Path
+			}
 			addOrUpdateHit(agg, hitKey(string(model.HitWalletInstalled), wr.ID, eid), model.RuleHit{
 				ID:           id.New("hit"),
 				CaseID:       firstCaseID(artifacts),
@@ -307,12 +360,8 @@ func matchWallets(loaded *rules.LoadedRules, apps []model.AppRecord, extensions
 				LastSeenAt:   time.Now().Unix(),
 				Confidence:   walletConf(wr.Confidence.DirectMatch, loaded.Wallet.Meta.ConfidenceDefaults.DirectMatch, 0.95),
 				Verdict:      "confirmed",
-				DetailJSON: mustJSON(map[string]any{
-					"match_field": "browser_extension_id",
-					"browser":     ex.Browser,
-					"profile":     ex.Profile,
-				}),
-				ArtifactIDs: artifactIDs,
+				DetailJSON:   walletDetailJSON(wr, detail),
+				ArtifactIDs:  artifactIDs,
 			})
 		}
 
@@ -322,7 +371,7 @@ func matchWallets(loaded *rules.LoadedRules, apps []model.AppRecord, extensions
 		}
 
 		for _, app := range apps {
-			searchBase := strings.ToLower(strings.Join([]string{app.Name, app.InstallLocation, app.Path}, " "))
+			searchBase := foldName(strings.Join([]string{app.Name, app.InstallLocation, app.Path}, " "))
 			if searchBase == "" {
 				continue
 			}
@@ -364,9 +413,11 @@ func matchWallets(loaded *rules.LoadedRules, apps []model.AppRecord, extensions
 				LastSeenAt:   time.Now().Unix(),
 				Confidence:   conf,
 				Verdict:      verdict,
-				DetailJSON: mustJSON(map[string]any{
+				DetailJSON: walletDetailJSON(wr, map[string]any{
 					"match_field":     "app_keyword",
 					"matched_keyword": matchedKeyword,
+					"localized_alias": isLocalizedKeyword(wr.LocalizedAliases, matchedKeyword),
+					"name_script":     nameScript(app.Name),
 					"install_path":    app.InstallLocation,
 				}),
 				ArtifactIDs: artifactIDs,
@@ -375,13 +426,21 @@ func matchWallets(loaded *rules.LoadedRules, apps []model.AppRecord, extensions
 	}
 }
 
+// typedNavigationBoost / typedNavigationCap 是地址栏直接输入（Chromium transition=typed）的加分与上限。
+const (
+	typedNavigationBoost = 0.03
+	typedNavigationCap   = 0.99
+)
+
 // matchExchanges 基于浏览历史匹配交易所域名与 URL 关键词。
 func matchExchanges(loaded *rules.LoadedRules, visits []model.VisitRecord, artifacts []model.Artifact, agg map[string]*hitAccumulator) {
 	if len(visits) == 0 {
 		return
 	}
 	artifactIDs := artifactIDsByType(artifacts, map[model.ArtifactType]struct{}{
-		model.ArtifactBrowserHistory: {},
+		model.ArtifactBrowserHistory:   {},
+		model.ArtifactBrowserBookmarks: {},
+		model.ArtifactMobileAccounts:   {},
 	})
 
 	for _, exr := range loaded.Exchange.Exchanges {
@@ -390,10 +449,12 @@ func matchExchanges(loaded *rules.LoadedRules, visits []model.VisitRecord, artif
 		}
 
 		targets := make([]string, 0, len(exr.Domains))
+		skeletons := make([]string, 0, len(exr.Domains))
 		for _, d := range exr.Domains {
 			n := normalizeDomain(d)
 			if n != "" {
 				targets = append(targets, n)
+				skeletons = append(skeletons, idn.Skeleton(n))
 			}
 		}
 		contains := make([]string, 0, len(exr.URLsContains))
@@ -412,6 +473,7 @@ func matchExchanges(loaded *rules.LoadedRules, visits []model.VisitRecord, artif
 
 			matchMode := ""
 			confidence := 0.0
+			lookalikeOf := ""
 			for _, t := range targets {
 				if domain == t {
 					matchMode = "exact_domain"
@@ -425,6 +487,44 @@ func matchExchanges(loaded *rules.LoadedRules, visits []model.VisitRecord, artif
 				}
 			}
 
+			// 结构化条件（子域名/路径前缀/查询参数）：在域名命中的基础上细分页面，例如充值页置信度高于首页。
+			var cond model.ExchangeCondition
+			condMatched := false
+			if matchMode != "" {
+				var condConf float64
+				if cond, condConf, condMatched = bestExchangeCondition(exr.Conditions, domain, v.URL, targets, confidence); condMatched {
+					matchMode = "url_condition"
+					confidence = condConf
+				}
+			}
+
+			// 形近域名：骨架与规则域名一致但字面不同（IDN 形近字、0/o、1/l、rn/m 等），疑似仿冒站点。
+			if matchMode == "" {
+				skel := idn.Skeleton(domain)
+				for i, ts := range skeletons {
+					if skel == ts || strings.HasSuffix(skel, "."+ts) {
+						matchMode = "homoglyph_domain"
+						confidence = exchangeConf(exr.Confidence.Homoglyph, loaded.Exchange.Meta.ConfidenceDefaults.Homoglyph, 0.60)
+						lookalikeOf = targets[i]
+						break
+					}
+				}
+			}
+
+			// 拼写仿冒：与规则域名仅差一处编辑（多一个/少一个/换一个字符），例如 binnance.com。
+			editDistance := 0
+			if matchMode == "" {
+				for _, t := range targets {
+					if d, ok := typosquatOf(domain, t); ok {
+						matchMode = "typosquat_domain"
+						confidence = exchangeConf(exr.Confidence.Homoglyph, loaded.Exchange.Meta.ConfidenceDefaults.Homoglyph, 0.60)
+						lookalikeOf = t
+						editDistance = d
+						break
+					}
+				}
+			}
+
 			if matchMode == "" {
 				urlLower := strings.ToLower(v.URL)
 				for _, token := range contains {
@@ -440,14 +540,44 @@ func matchExchanges(loaded *rules.LoadedRules, visits []model.VisitRecord, artif
 				continue
 			}
 
+			// 地址栏直接输入（typed）说明是主动访问而非被动跳转，在原置信度上小幅加分。
+			if v.Transition == "typed" {
+				confidence = math.Min(confidence+typedNavigationBoost, typedNavigationCap)
+			}
+
 			verdict := "suspected"
-			if confidence >= 0.85 {
+			if confidence >= 0.85 && lookalikeOf == "" {
 				verdict = "confirmed"
 			}
 			first := v.VisitedAt
 			if first <= 0 {
 				first = time.Now().Unix()
 			}
+			detail := map[string]any{
+				"match_mode": matchMode,
+				"browser":    v.Browser,
+				"profile":    v.Profile,
+				"url":        v.URL,
+			}
+			if lookalikeOf != "" {
+				detail["lookalike_of"] = lookalikeOf
+			}
+			if editDistance > 0 {
+				detail["edit_distance"] = editDistance
+			}
+			if condMatched {
+				detail["condition_id"] = cond.ID
+				detail["condition_label"] = cond.Label
+			}
+			if v.Transition != "" {
+				detail["transition"] = v.Transition
+			}
+			if v.ReferrerURL != "" {
+				detail["referrer_url"] = v.ReferrerURL
+			}
+			if idn.IsIDN(domain) {
+				detail["idn"] = true
+			}
 
 			addOrUpdateHit(agg, hitKey(string(model.HitExchangeVisited), firstDeviceID(artifacts), exr.ID, domain), model.RuleHit{
 				ID:           id.New("hit"),
@@ -462,33 +592,34 @@ func matchExchanges(loaded *rules.LoadedRules, visits []model.VisitRecord, artif
 				LastSeenAt:   first,
 				Confidence:   confidence,
 				Verdict:      verdict,
-				DetailJSON: mustJSON(map[string]any{
-					"match_mode": matchMode,
-					"browser":    v.Browser,
-					"profile":    v.Profile,
-					"url":        v.URL,
-				}),
-				ArtifactIDs: artifactIDs,
+				DetailJSON:   mustJSON(detail),
+				ArtifactIDs:  artifactIDs,
 			})
 		}
 	}
 }
 
-// normalizedKeywords 统一钱包关键词大小写与空白，减少匹配误差。
+// normalizedKeywords 返回钱包规则的程序名关键词（含别名与本地化别名），按 foldName 折叠，减少匹配误差。
 func normalizedKeywords(w model.WalletSignature) []string {
 	var out []string
-	for _, s := range w.Desktop.AppKeywords {
-		out = append(out, strings.ToLower(strings.TrimSpace(s)))
-	}
-	for _, s := range w.Desktop.FileKeywords {
-		out = append(out, strings.ToLower(strings.TrimSpace(s)))
-	}
-	for _, s := range w.Aliases {
-		out = append(out, strings.ToLower(strings.TrimSpace(s)))
+	for _, list := range [][]string{w.Desktop.AppKeywords, w.Desktop.FileKeywords, w.Aliases, w.LocalizedAliases} {
+		for _, s := range list {
+			out = append(out, foldName(s))
+		}
 	}
 	return out
 }
 
+// isLocalizedKeyword 判断折叠后的关键词 kw 是否来自本地化别名。
+func isLocalizedKeyword(localized []string, kw string) bool {
+	for _, s := range localized {
+		if foldName(s) == kw {
+			return true
+		}
+	}
+	return false
+}
+
 // walletConf 按 “规则值 > 全局默认 > 兜底值” 选择最终置信度。
 func walletConf(primary, fallback, def float64) float64 {
 	if primary > 0 {
@@ -559,13 +690,35 @@ func hitKey(parts ...string) string {
 	return strings.Join(parts, "|")
 }
 
-// normalizeDomain 用于域名匹配前预处理。
+// normalizeDomain 用于域名匹配前预处理：小写、punycode 解码为 Unicode、去掉 www.。
 func normalizeDomain(d string) string {
-	d = strings.ToLower(strings.TrimSpace(d))
+	d = idn.ToUnicode(d)
 	d = strings.TrimPrefix(d, "www.")
 	return d
 }
 
+// typosquatMinLabel 是参与拼写仿冒比较的主标签最短长度：过短的名称（okx、gate）一处编辑就会撞上大量正常域名。
+const typosquatMinLabel = 6
+
+// typosquatOf 判断 domain 是否为 target 的拼写仿冒：取与 target 同样层数的末尾标签比较形近骨架，
+// 编辑距离恰为 1 且首字符相同（仿冒通常保留品牌首字母，避免 finance.com 之类的正常域名被误判）。
+func typosquatOf(domain, target string) (int, bool) {
+	tLabels := strings.Split(target, ".")
+	dLabels := strings.Split(domain, ".")
+	if len(dLabels) < len(tLabels) || len([]rune(tLabels[0])) < typosquatMinLabel {
+		return 0, false
+	}
+	tail := strings.Join(dLabels[len(dLabels)-len(tLabels):], ".")
+	a, b := idn.Skeleton(tail), idn.Skeleton(target)
+	if a == b || a == "" || []rune(a)[0] != []rune(b)[0] {
+		return 0, false
+	}
+	if d := idn.EditDistance(a, b); d == 1 {
+		return d, true
+	}
+	return 0, false
+}
+
 // firstCaseID 从证据列表中提取 caseID（默认所有 artifact 属于同一案件）。
 func firstCaseID(artifacts []model.Artifact) string {
 	for _, a := range artifacts {
@@ -598,6 +751,14 @@ func artifactIDsByType(artifacts []model.Artifact, types map[model.ArtifactType]
 }
 
 // mustJSON 保证 detail_json 至少为合法 JSON。
+// walletDetailJSON 编码钱包命中详情，并附加规则的托管类型 wallet_category（未配置时不写）。
+func walletDetailJSON(wr model.WalletSignature, detail map[string]any) []byte {
+	if c := strings.TrimSpace(wr.Category); c != "" {
+		detail["wallet_category"] = c
+	}
+	return mustJSON(detail)
+}
+
 func mustJSON(v any) []byte {
 	raw, err := json.Marshal(v)
 	if err != nil {
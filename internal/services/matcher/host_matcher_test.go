@@ -2,6 +2,8 @@ package matcher
 
 import (
 	"encoding/json"
+	"math"
+	"strings"
 	"testing"
 
 	"crypto-inspector/internal/adapters/rules"
@@ -50,3 +52,485 @@ func TestMatchHostArtifacts_ExtractWalletAddresses_FromBrowserHistory(t *testing
 		t.Fatalf("wallet_address hits=%d, want 3", addrHits)
 	}
 }
+
+func TestMatchHostArtifacts_ExtractWalletAddresses_PercentEncoded(t *testing.T) {
+	loaded := &rules.LoadedRules{}
+	visits := []model.VisitRecord{
+		// base58 地址前紧贴 %2F：未解码时 F 会让边界判断失败
+		{Browser: "chrome", URL: "https://r.example/go?u=https%253A%252F%252Fblockchair.com%252Fbitcoin%252Faddress%252F1BoatSLRHtKNngkdXEeobR76b53LETtpyT", Domain: "r.example", VisitedAt: 1700000001},
+		{Browser: "chrome", URL: "https://foo.local/", Title: "转账到 ０ｘ000000000000000000000000000000000000dEaD", Domain: "foo.local", VisitedAt: 1700000002},
+	}
+	raw, _ := json.Marshal(visits)
+	res, err := MatchHostArtifacts(loaded, []model.Artifact{{ID: "art_1", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactBrowserHistory, PayloadJSON: raw}})
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+	got := map[string]map[string]any{}
+	for _, h := range res.Hits {
+		if h.Type != model.HitWalletAddress {
+			continue
+		}
+		var d map[string]any
+		_ = json.Unmarshal(h.DetailJSON, &d)
+		got[h.MatchedValue] = d
+	}
+	d, ok := got["1BoatSLRHtKNngkdXEeobR76b53LETtpyT"]
+	if !ok {
+		t.Fatalf("base58 address not extracted: %v", got)
+	}
+	if !strings.Contains(d["sample"].(string), "%252F1Boat") || !strings.Contains(d["decoded_sample"].(string), "/address/1Boat") {
+		t.Fatalf("detail=%v", d)
+	}
+	if _, ok := got["0x000000000000000000000000000000000000dead"]; !ok {
+		t.Fatalf("fullwidth evm address not extracted: %v", got)
+	}
+}
+
+func TestMatchHostArtifacts_UnknownWalletHeuristic(t *testing.T) {
+	loaded := &rules.LoadedRules{}
+
+	apps := []model.AppRecord{
+		// 名称 + 发布者 + Electron/LevelDB 特征 -> 应输出低置信命中
+		{Name: "Nebula Wallet", Publisher: "Nebula Crypto Ltd", InstallHints: []string{"electron_asar", "leveldb"}},
+		// 只有运行时特征，没有语义指示 -> 不应命中
+		{Name: "Slack", Publisher: "Slack Technologies", InstallHints: []string{"electron_asar", "leveldb"}},
+		// "eth" 只作为整词匹配，ethernet 不应触发
+		{Name: "Ethernet Monitor"},
+	}
+	raw, _ := json.Marshal(apps)
+
+	artifacts := []model.Artifact{
+		{ID: "art_apps_1", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactInstalledApps, PayloadJSON: raw},
+	}
+
+	res, err := MatchHostArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+
+	var got []model.RuleHit
+	for _, h := range res.Hits {
+		if h.Type == model.HitWalletSuspectedUnknown {
+			got = append(got, h)
+		}
+	}
+	if len(got) != 1 || got[0].MatchedValue != "Nebula Wallet" {
+		t.Fatalf("wallet_suspected_unknown hits=%+v, want only Nebula Wallet", got)
+	}
+	if got[0].Confidence > 0.5 || got[0].Verdict != "suspected" {
+		t.Fatalf("confidence=%v verdict=%s, want low-confidence suspected", got[0].Confidence, got[0].Verdict)
+	}
+}
+
+func TestMatchHostArtifacts_ExchangeIDNAndHomoglyph(t *testing.T) {
+	loaded := &rules.LoadedRules{Exchange: model.ExchangeRuleBundle{
+		Version: "test",
+		Exchanges: []model.ExchangeDomain{
+			{ID: "binance", Enabled: true, Name: "Binance", Domains: []string{"binance.com"}},
+		},
+	}}
+	visits := []model.VisitRecord{
+		{Browser: "chrome", URL: "https://www.binance.com/", Domain: "www.binance.com", VisitedAt: 1700000001},
+		// punycode 形式的 binánce.com
+		{Browser: "chrome", URL: "https://xn--binnce-rta.com/login", Domain: "xn--binnce-rta.com", VisitedAt: 1700000002},
+		{Browser: "chrome", URL: "https://login.b1nance.com/", Domain: "login.b1nance.com", VisitedAt: 1700000003},
+		{Browser: "chrome", URL: "https://kraken.com/", Domain: "kraken.com", VisitedAt: 1700000004},
+		// 拼写仿冒（编辑距离 1）；finance.com 首字母不同，不应误判
+		{Browser: "chrome", URL: "https://binnance.com/login", Domain: "binnance.com", VisitedAt: 1700000005},
+		{Browser: "chrome", URL: "https://finance.com/", Domain: "finance.com", VisitedAt: 1700000006},
+	}
+	raw, _ := json.Marshal(visits)
+	artifacts := []model.Artifact{{ID: "art_h", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactBrowserHistory, PayloadJSON: raw}}
+
+	res, err := MatchHostArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+	byValue := map[string]model.RuleHit{}
+	for _, h := range res.Hits {
+		if h.Type == model.HitExchangeVisited {
+			byValue[h.MatchedValue] = h
+		}
+	}
+	if len(byValue) != 4 {
+		t.Fatalf("exchange hits=%+v", byValue)
+	}
+	if h := byValue["binnance.com"]; h.Verdict != "suspected" || !strings.Contains(string(h.DetailJSON), `"match_mode":"typosquat_domain"`) || !strings.Contains(string(h.DetailJSON), `"edit_distance":1`) {
+		t.Fatalf("typosquat hit=%+v", h)
+	}
+	if h := byValue["binance.com"]; h.Confidence != 0.95 || h.Verdict != "confirmed" {
+		t.Fatalf("exact hit=%+v", h)
+	}
+	for _, v := range []string{"binánce.com", "login.b1nance.com"} {
+		h, ok := byValue[v]
+		var detail struct {
+			MatchMode   string `json:"match_mode"`
+			LookalikeOf string `json:"lookalike_of"`
+		}
+		_ = json.Unmarshal(h.DetailJSON, &detail)
+		if !ok || h.Confidence != 0.60 || h.Verdict != "suspected" || detail.MatchMode != "homoglyph_domain" || detail.LookalikeOf != "binance.com" {
+			t.Fatalf("homoglyph hit %q=%+v detail=%+v", v, h, detail)
+		}
+	}
+}
+
+func TestMatchHostArtifacts_ExchangeConditions(t *testing.T) {
+	loaded := &rules.LoadedRules{Exchange: model.ExchangeRuleBundle{
+		Version: "test",
+		Exchanges: []model.ExchangeDomain{{
+			ID: "binance", Enabled: true, Name: "Binance",
+			Domains: []string{"binance.com", "accounts.binance.com"},
+			Conditions: []model.ExchangeCondition{
+				{ID: "deposit_page", Label: "充值页", PathPrefix: "/*/my/wallet/account/main/deposit", Confidence: 0.98},
+				{ID: "p2p_trade", Label: "P2P", Subdomain: "c2c", QueryParams: []string{"fiat=CNY"}, Confidence: 0.96},
+				{ID: "login", Label: "登录页", Subdomain: "accounts"},
+			},
+		}},
+	}}
+	visits := []model.VisitRecord{
+		{URL: "https://www.binance.com/", Domain: "binance.com", VisitedAt: 1},
+		{URL: "https://www.binance.com/zh-CN/my/wallet/account/main/deposit/crypto/USDT", Domain: "binance.com", VisitedAt: 2},
+		{URL: "https://c2c.binance.com/zh-CN/trade/buy/USDT?fiat=cny", Domain: "c2c.binance.com", VisitedAt: 3},
+		{URL: "https://c2c.binance.com/en/trade/buy/USDT?fiat=EUR", Domain: "c2c.binance.com", VisitedAt: 4},
+		{URL: "https://accounts.binance.com/en/login", Domain: "accounts.binance.com", VisitedAt: 5},
+	}
+	raw, _ := json.Marshal(visits)
+	artifacts := []model.Artifact{{ID: "art_h", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactBrowserHistory, PayloadJSON: raw}}
+
+	res, err := MatchHostArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+	type detail struct {
+		MatchMode   string `json:"match_mode"`
+		ConditionID string `json:"condition_id"`
+	}
+	byValue := map[string]model.RuleHit{}
+	for _, h := range res.Hits {
+		byValue[h.MatchedValue] = h
+	}
+	check := func(domain string, conf float64, mode, cond string) {
+		t.Helper()
+		h := byValue[domain]
+		var d detail
+		_ = json.Unmarshal(h.DetailJSON, &d)
+		if h.Confidence != conf || d.MatchMode != mode || d.ConditionID != cond {
+			t.Fatalf("%s: hit=%+v detail=%+v", domain, h, d)
+		}
+	}
+	// 首页（0.95）与充值页（0.98）合并为同一命中，保留更高置信度的条件细节。
+	check("binance.com", 0.98, "url_condition", "deposit_page")
+	check("c2c.binance.com", 0.96, "url_condition", "p2p_trade")
+	// 未配置置信度的条件沿用域名命中置信度（此处按 binance.com 的 root_domain 命中，0.90）。
+	check("accounts.binance.com", 0.90, "url_condition", "login")
+}
+
+func TestMatchHostArtifacts_TypedNavigationBoost(t *testing.T) {
+	loaded := &rules.LoadedRules{Exchange: model.ExchangeRuleBundle{
+		Version:   "test",
+		Exchanges: []model.ExchangeDomain{{ID: "okx", Enabled: true, Name: "OKX", Domains: []string{"okx.com"}}},
+	}}
+	visits := []model.VisitRecord{
+		{URL: "https://www.okx.com/", Domain: "okx.com", VisitedAt: 1, Transition: "link", ReferrerURL: "https://search.example/?q=okx"},
+		{URL: "https://my.okx.com/", Domain: "my.okx.com", VisitedAt: 2, Transition: "typed"},
+	}
+	raw, _ := json.Marshal(visits)
+	artifacts := []model.Artifact{{ID: "art_h", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactBrowserHistory, PayloadJSON: raw}}
+
+	res, err := MatchHostArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+	byValue := map[string]model.RuleHit{}
+	for _, h := range res.Hits {
+		byValue[h.MatchedValue] = h
+	}
+	var detail struct {
+		Transition  string `json:"transition"`
+		ReferrerURL string `json:"referrer_url"`
+	}
+	_ = json.Unmarshal(byValue["okx.com"].DetailJSON, &detail)
+	if byValue["okx.com"].Confidence != 0.95 || detail.Transition != "link" || detail.ReferrerURL == "" {
+		t.Fatalf("link hit=%+v detail=%+v", byValue["okx.com"], detail)
+	}
+	if h := byValue["my.okx.com"]; math.Abs(h.Confidence-0.93) > 1e-9 {
+		t.Fatalf("typed root_domain hit should be boosted to 0.93: %+v", h)
+	}
+}
+
+func TestMatchHostArtifacts_ExchangeFormActivity(t *testing.T) {
+	loaded := &rules.LoadedRules{Exchange: model.ExchangeRuleBundle{
+		Version:   "test",
+		Exchanges: []model.ExchangeDomain{{ID: "okx", Enabled: true, Name: "OKX", Domains: []string{"okx.com"}}},
+	}}
+	forms := []model.FormDataRecord{
+		{Source: "login_form", Origin: "https://www.okx.com/account/login", Domain: "okx.com", FieldNames: []string{"email_address", "password"}, CreatedAt: 100},
+		{Source: "login_form", Origin: "https://my.okx.com/balance/withdraw", Domain: "my.okx.com", FieldNames: []string{"amount"}, CreatedAt: 200, LastUsedAt: 300},
+		{Source: "login_form", Origin: "https://example.org/", Domain: "example.org", CreatedAt: 100},
+		{Source: "autofill_profile", Origin: "Chrome settings", UseCount: 4},
+	}
+	raw, _ := json.Marshal(forms)
+	artifacts := []model.Artifact{{ID: "art_f", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactBrowserFormData, PayloadJSON: raw}}
+
+	res, err := MatchHostArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+	if len(res.Hits) != 2 {
+		t.Fatalf("hits=%+v", res.Hits)
+	}
+	byValue := map[string]model.RuleHit{}
+	for _, h := range res.Hits {
+		if h.Type != model.HitExchangeFormActivity || h.RuleID != "okx" {
+			t.Fatalf("unexpected hit: %+v", h)
+		}
+		byValue[h.MatchedValue] = h
+	}
+	if h := byValue["okx.com"]; h.Confidence != 0.90 || h.FirstSeenAt != 100 {
+		t.Fatalf("login form hit=%+v", h)
+	}
+	var detail struct {
+		Transactional bool `json:"transactional"`
+	}
+	h := byValue["my.okx.com"]
+	_ = json.Unmarshal(h.DetailJSON, &detail)
+	if h.Confidence != 0.97 || !detail.Transactional || h.LastSeenAt != 300 {
+		t.Fatalf("withdraw form hit=%+v detail=%+v", h, detail)
+	}
+}
+
+func TestMatchHostArtifacts_WalletExecution(t *testing.T) {
+	loaded := &rules.LoadedRules{Wallet: model.WalletRuleBundle{
+		Version: "test",
+		Wallets: []model.WalletSignature{{ID: "exodus", Enabled: true, Name: "Exodus", Desktop: model.WalletDesktopHints{AppKeywords: []string{"exodus"}}}},
+	}}
+	records := []model.AppExecutionRecord{
+		{Source: "launch_services", Name: "Exodus", BundleID: "com.exodus-movement.exodus", Path: "/Users/a/.Trash/Exodus.app", InTrash: true, LastUsedAt: 100},
+		{Source: "dock_persistent", Name: "Exodus", Path: "/Applications/Exodus.app"},
+		{Source: "saved_state", Name: "exodus", BundleID: "com.exodus-movement.exodus", LastUsedAt: 300},
+		{Source: "launch_services", Name: "Safari", BundleID: "com.apple.Safari", Path: "/Applications/Safari.app"},
+	}
+	raw, _ := json.Marshal(records)
+	artifacts := []model.Artifact{{ID: "art_x", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactAppExecution, PayloadJSON: raw, CollectedAt: 200}}
+
+	res, err := MatchHostArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+	if len(res.Hits) != 1 {
+		t.Fatalf("hits=%+v", res.Hits)
+	}
+	h := res.Hits[0]
+	if h.Type != model.HitWalletExecuted || h.RuleID != "exodus" || h.FirstSeenAt != 100 || h.LastSeenAt != 300 {
+		t.Fatalf("unexpected hit: %+v", h)
+	}
+	if math.Abs(h.Confidence-0.75) > 1e-9 || h.Verdict != "suspected" {
+		t.Fatalf("confidence=%v verdict=%s", h.Confidence, h.Verdict)
+	}
+	var detail struct {
+		Sources []string `json:"sources"`
+		Path    string   `json:"path"`
+		InTrash bool     `json:"in_trash"`
+	}
+	_ = json.Unmarshal(h.DetailJSON, &detail)
+	if len(detail.Sources) != 3 || !detail.InTrash || detail.Path != "/Users/a/.Trash/Exodus.app" {
+		t.Fatalf("detail=%+v", detail)
+	}
+}
+
+func TestMatchHostArtifacts_WalletRemnants(t *testing.T) {
+	loaded := &rules.LoadedRules{Wallet: model.WalletRuleBundle{
+		Version: "test",
+		Wallets: []model.WalletSignature{
+			{ID: "exodus", Enabled: true, Name: "Exodus", Desktop: model.WalletDesktopHints{AppKeywords: []string{"exodus"}}},
+			{ID: "atomic", Enabled: true, Name: "Atomic Wallet", Desktop: model.WalletDesktopHints{AppKeywords: []string{"atomic wallet"}}},
+		},
+	}}
+	execRaw, _ := json.Marshal([]model.AppExecutionRecord{{Source: "launch_services", Name: "Exodus", Path: "/Users/a/.Trash/Exodus.app", InTrash: true, LastUsedAt: 100}})
+	remnantRaw, _ := json.Marshal([]model.AppRemnantRecord{
+		{Source: "keychain", Label: "Exodus Safe Storage", ModifiedAt: 150},
+		{Source: "tcc", Service: "kTCCServiceAccessibility", Client: "/Applications/Atomic Wallet.app/Contents/MacOS/Atomic Wallet", ClientType: "path", ModifiedAt: 120},
+		{Source: "tcc", Service: "kTCCServiceCamera", Client: "com.example.notes", ClientType: "bundle_id"},
+	})
+	// 钥匙串证据排在前面也应并入 app_execution 的分组。
+	artifacts := []model.Artifact{
+		{ID: "art_r", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactAppRemnants, PayloadJSON: remnantRaw, CollectedAt: 200},
+		{ID: "art_x", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactAppExecution, PayloadJSON: execRaw, CollectedAt: 200},
+	}
+
+	res, err := MatchHostArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+	byRule := map[string]model.RuleHit{}
+	for _, h := range res.Hits {
+		byRule[h.RuleID] = h
+	}
+	if len(res.Hits) != 2 {
+		t.Fatalf("hits=%+v", res.Hits)
+	}
+	ex := byRule["exodus"]
+	var detail struct {
+		Sources []string `json:"sources"`
+		Path    string   `json:"path"`
+	}
+	_ = json.Unmarshal(ex.DetailJSON, &detail)
+	if ex.MatchedValue != "Exodus" || len(ex.ArtifactIDs) != 2 || ex.LastSeenAt != 150 || len(detail.Sources) != 2 || math.Abs(ex.Confidence-0.75) > 1e-9 {
+		t.Fatalf("exodus hit=%+v detail=%+v", ex, detail)
+	}
+	at := byRule["atomic"]
+	_ = json.Unmarshal(at.DetailJSON, &detail)
+	if at.Type != model.HitWalletExecuted || at.MatchedValue != "Atomic Wallet" || detail.Path != "/Applications/Atomic Wallet.app" || len(detail.Sources) != 1 || detail.Sources[0] != "tcc" {
+		t.Fatalf("atomic hit=%+v detail=%+v", at, detail)
+	}
+}
+
+func TestMatchHostArtifacts_ExchangeDesktopApps(t *testing.T) {
+	loaded := &rules.LoadedRules{Exchange: model.ExchangeRuleBundle{
+		Version: "test",
+		Exchanges: []model.ExchangeDomain{{ID: "binance", Enabled: true, Name: "Binance", Domains: []string{"binance.com"},
+			Desktop: model.ExchangeDesktopHints{
+				AppKeywords:         []string{"binance"},
+				BundleIDs:           []string{"com.binance.BinanceDesktop"},
+				InstallPathsWindows: []string{"%LOCALAPPDATA%/Programs/Binance"},
+			}}},
+	}}
+	apps := []model.AppRecord{
+		{Name: "Binance 1.50.0", InstallLocation: `C:\Users\a\AppData\Local\Programs\Binance\`},
+		{Name: "Binance", BundleID: "com.binance.binancedesktop", Path: "/Applications/Binance.app"},
+		{Name: "Binance Helper Tool"},
+		{Name: "Notepad++", InstallLocation: `C:\Program Files\Notepad++`},
+	}
+	raw, _ := json.Marshal(apps)
+	artifacts := []model.Artifact{{ID: "art_apps", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactInstalledApps, PayloadJSON: raw}}
+
+	res, err := MatchHostArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+	fields := map[string]string{}
+	for _, h := range res.Hits {
+		if h.Type != model.HitExchangeAppInstalled || h.RuleID != "binance" {
+			t.Fatalf("unexpected hit: %+v", h)
+		}
+		var detail struct {
+			MatchField string `json:"match_field"`
+		}
+		_ = json.Unmarshal(h.DetailJSON, &detail)
+		fields[h.MatchedValue] = detail.MatchField
+		if detail.MatchField == "app_keyword" && (h.Confidence != 0.80 || h.Verdict != "suspected") {
+			t.Fatalf("keyword hit=%+v", h)
+		}
+	}
+	want := map[string]string{"Binance 1.50.0": "install_path", "Binance": "bundle_id", "Binance Helper Tool": "app_keyword"}
+	if len(fields) != len(want) {
+		t.Fatalf("fields=%v", fields)
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Fatalf("fields=%v, want %v", fields, want)
+		}
+	}
+}
+
+func TestMatchHostArtifacts_LocalizedAppNames(t *testing.T) {
+	loaded := &rules.LoadedRules{Exchange: model.ExchangeRuleBundle{
+		Version: "test",
+		Exchanges: []model.ExchangeDomain{{ID: "binance", Enabled: true, Name: "Binance", Domains: []string{"binance.com"},
+			LocalizedAliases: []string{"币安", "바이낸스"},
+			Desktop:          model.ExchangeDesktopHints{AppKeywords: []string{"binance"}}}},
+	}}
+	apps := []model.AppRecord{
+		{Name: "幣安 桌面版"},
+		// macOS 文件名中的分解形式韩文字母。
+		{Name: "\u1107\u1161\u110b\u1175\u1102\u1162\u11ab\u1109\u1173"},
+		{Name: "ＢＩＮＡＮＣＥ"},
+		{Name: "记事本"},
+	}
+	raw, _ := json.Marshal(apps)
+	artifacts := []model.Artifact{{ID: "art_apps", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactInstalledApps, PayloadJSON: raw}}
+
+	res, err := MatchHostArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+	got := map[string]bool{}
+	for _, h := range res.Hits {
+		var detail struct {
+			Localized bool   `json:"localized_alias"`
+			Script    string `json:"name_script"`
+		}
+		_ = json.Unmarshal(h.DetailJSON, &detail)
+		got[detail.Script] = detail.Localized
+	}
+	want := map[string]bool{"han": true, "hangul": true, "latin": false}
+	if len(got) != len(want) {
+		t.Fatalf("hits=%+v", res.Hits)
+	}
+	for k, v := range want {
+		if l, ok := got[k]; !ok || l != v {
+			t.Fatalf("got=%v, want %v", got, want)
+		}
+	}
+}
+
+func TestMatchHostArtifacts_MessengerCommunities(t *testing.T) {
+	loaded := &rules.LoadedRules{Exchange: model.ExchangeRuleBundle{
+		Version:   "test",
+		Meta:      model.ExchangeMeta{CommunityKeywords: []string{"OTC", "承兑"}},
+		Exchanges: []model.ExchangeDomain{{ID: "binance", Enabled: true, Name: "Binance", Aliases: []string{"币安"}, Domains: []string{"binance.com"}}},
+	}}
+	records := []model.MessengerRecord{
+		{App: "telegram", Kind: "data_dir", Path: "/x/tdata"},
+		{App: "telegram", Kind: "channel", Name: "USDT otc 承兑群", ChannelID: "1", ChatType: "private_supergroup", ModifiedAt: 100},
+		{App: "discord", Kind: "channel", Name: "币安中文社区", ChannelID: "912345678901234567", ModifiedAt: 200},
+		{App: "discord", Kind: "channel", Name: "general", ChannelID: "912345678901234568"},
+	}
+	raw, _ := json.Marshal(records)
+	artifacts := []model.Artifact{{ID: "art_m", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactMessengerTraces, PayloadJSON: raw}}
+
+	res, err := MatchHostArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+	if len(res.Hits) != 2 {
+		t.Fatalf("hits=%+v", res.Hits)
+	}
+	byValue := map[string]model.RuleHit{}
+	for _, h := range res.Hits {
+		if h.Type != model.HitMessengerCommunity || h.Verdict != "suspected" {
+			t.Fatalf("unexpected hit: %+v", h)
+		}
+		byValue[h.MatchedValue] = h
+	}
+	if h := byValue["币安中文社区"]; h.RuleID != "binance" || h.Confidence != 0.60 || h.FirstSeenAt != 200 {
+		t.Fatalf("exchange community hit=%+v", h)
+	}
+	if h := byValue["USDT otc 承兑群"]; h.RuleID != "community:otc" || h.Confidence != 0.55 {
+		t.Fatalf("keyword community hit=%+v", h)
+	}
+}
+
+func TestDecodeArtifacts_ReassemblesParts(t *testing.T) {
+	part := func(id string, no int, urls ...string) model.Artifact {
+		var rows []model.VisitRecord
+		for _, u := range urls {
+			rows = append(rows, model.VisitRecord{URL: u})
+		}
+		raw, _ := json.Marshal(rows)
+		return model.Artifact{ID: id, Type: model.ArtifactBrowserHistory, PayloadJSON: raw, PartGroup: "art_1", PartNo: no, PartCount: 3}
+	}
+	// 分片乱序传入（例如从导出包回读），解码结果仍按拆分前的顺序。
+	artifacts := []model.Artifact{part("art_3", 3, "https://e"), part("art_1", 1, "https://a", "https://b"), part("art_2", 2, "https://c", "https://d")}
+	_, _, visits, err := decodeArtifacts(artifacts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range visits {
+		got = append(got, v.URL)
+	}
+	if strings.Join(got, ",") != "https://a,https://b,https://c,https://d,https://e" {
+		t.Fatalf("visits=%v", got)
+	}
+}
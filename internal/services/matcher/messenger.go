@@ -0,0 +1,98 @@
+package matcher
+
+import (
+	"encoding/json"
+	"strings"
+
+	"crypto-inspector/internal/adapters/rules"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+)
+
+// Telegram / Discord 交易社群
+//
+// messenger_traces 中的频道/服务器名称按两类规则匹配，输出 messenger_community 命中：
+// - 名称含交易所规则的名称或别名（例如 "Binance 中文社区"）：rule_id 为交易所 ID
+// - 名称含 exchange_domains meta.community_keywords（OTC/承兑/跑分等）：rule_id 为 community:<关键词>
+// 频道名称只是线索，不能说明账户归属或参与程度，因此统一为中置信 suspected，供分析人员跟进。
+
+const (
+	messengerExchangeConfidence = 0.60
+	messengerKeywordConfidence  = 0.55
+)
+
+// matchMessengerCommunities 把疑似交易社群的频道/服务器名称固化为 messenger_community 命中。
+func matchMessengerCommunities(loaded *rules.LoadedRules, artifacts []model.Artifact, agg map[string]*hitAccumulator) {
+	for _, a := range artifacts {
+		if a.Type != model.ArtifactMessengerTraces || len(a.PayloadJSON) == 0 {
+			continue
+		}
+		var records []model.MessengerRecord
+		if err := json.Unmarshal(a.PayloadJSON, &records); err != nil {
+			continue
+		}
+		for _, rec := range records {
+			if rec.Kind != "channel" || strings.TrimSpace(rec.Name) == "" {
+				continue
+			}
+			ruleID, ruleName, field, kw, conf, ok := communityRuleFor(loaded, rec.Name)
+			if !ok {
+				continue
+			}
+			ts := rec.ModifiedAt
+			if ts <= 0 {
+				ts = a.CollectedAt
+			}
+			ref := rec.ChannelID
+			if ref == "" {
+				ref = rec.Name
+			}
+			addOrUpdateHit(agg, hitKey(string(model.HitMessengerCommunity), a.DeviceID, rec.App, ref), model.RuleHit{
+				ID:           id.New("hit"),
+				CaseID:       a.CaseID,
+				DeviceID:     a.DeviceID,
+				Type:         model.HitMessengerCommunity,
+				RuleID:       ruleID,
+				RuleName:     ruleName,
+				RuleVersion:  loaded.Exchange.Version,
+				MatchedValue: strings.TrimSpace(rec.Name),
+				FirstSeenAt:  ts,
+				LastSeenAt:   ts,
+				Confidence:   conf,
+				Verdict:      "suspected",
+				DetailJSON: mustJSON(map[string]any{
+					"app":             rec.App,
+					"channel_id":      rec.ChannelID,
+					"chat_type":       rec.ChatType,
+					"match_field":     field,
+					"matched_keyword": kw,
+					"path":            rec.Path,
+				}),
+				ArtifactIDs: []string{a.ID},
+			})
+		}
+	}
+}
+
+// communityRuleFor 先按交易所名称/别名匹配，再按社群关键词匹配。
+func communityRuleFor(loaded *rules.LoadedRules, name string) (ruleID, ruleName, field, keyword string, conf float64, ok bool) {
+	lower := foldName(name)
+	for _, exr := range loaded.Exchange.Exchanges {
+		if !exr.Enabled {
+			continue
+		}
+		for _, n := range append(append([]string{exr.Name}, exr.Aliases...), exr.LocalizedAliases...) {
+			n = foldName(n)
+			if n != "" && strings.Contains(lower, n) {
+				return exr.ID, exr.Name, "exchange_name", n, messengerExchangeConfidence, true
+			}
+		}
+	}
+	for _, kw := range loaded.Exchange.Meta.CommunityKeywords {
+		kw = foldName(kw)
+		if kw != "" && strings.Contains(lower, kw) {
+			return "community:" + kw, kw, "community_keyword", kw, messengerKeywordConfidence, true
+		}
+	}
+	return "", "", "", "", 0, false
+}
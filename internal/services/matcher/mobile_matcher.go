@@ -13,8 +13,9 @@ import (
 )
 
 // MatchMobileArtifacts 基于移动端证据执行规则匹配：
-// - mobile_packages：钱包安装/APP 线索
-// - browser_history（如果存在）：交易所访问、地址抽取
+// - mobile_packages：钱包安装/APP 线索；应用声明的 URL scheme / App Links 域名对照交易所规则（exchange_app_installed）
+// - browser_history（如果存在）/ browser_bookmarks（Android 书签）：交易所访问、地址抽取（与主机共用 matchBrowsingActivity）
+// - mobile_accounts（Android 账户清单）：账户邮箱域名/账户类型（反向域名）对照交易所规则
 func MatchMobileArtifacts(loaded *rules.LoadedRules, artifacts []model.Artifact) (*HostMatchResult, error) {
 	pkgsByDev, pkgArtifactIDsByDev, err := decodeMobilePackagesByDevice(artifacts)
 	if err != nil {
@@ -32,7 +33,8 @@ func MatchMobileArtifacts(loaded *rules.LoadedRules, artifacts []model.Artifact)
 
 		androidSet := toSet(wr.Mobile.AndroidPackages)
 		iosSet := toSet(wr.Mobile.IOSBundleIDs)
-		if len(androidSet) == 0 && len(iosSet) == 0 {
+		harmonySet := toSet(wr.Mobile.HarmonyBundleNames)
+		if len(androidSet) == 0 && len(iosSet) == 0 && len(harmonySet) == 0 {
 			continue
 		}
 
@@ -56,6 +58,13 @@ func MatchMobileArtifacts(loaded *rules.LoadedRules, artifacts []model.Artifact)
 					if _, ok := iosSet[p]; ok {
 						matchField = "ios_bundle_id"
 					}
+				case model.OSHarmonyOS:
+					// HarmonyOS NEXT 应用多沿用 Android 包名，规则未单独列出时按 android_packages 比对。
+					if _, ok := harmonySet[p]; ok {
+						matchField = "harmony_bundle_name"
+					} else if _, ok := androidSet[p]; ok {
+						matchField = "android_package"
+					}
 				}
 				if matchField == "" {
 					continue
@@ -80,7 +89,7 @@ func MatchMobileArtifacts(loaded *rules.LoadedRules, artifacts []model.Artifact)
 					LastSeenAt:   now,
 					Confidence:   conf,
 					Verdict:      verdict,
-					DetailJSON: mustJSON(map[string]any{
+					DetailJSON: walletDetailJSON(wr, map[string]any{
 						"match_field": matchField,
 						"os":          pkg.OS,
 						"identifier":  pkg.Identifier,
@@ -91,42 +100,30 @@ func MatchMobileArtifacts(loaded *rules.LoadedRules, artifacts []model.Artifact)
 		}
 	}
 
-	// 移动端浏览历史（如果采集器提供）：用于交易所访问 + 地址抽取。
-	visitsByDev, historyArtifactIDsByDev, err := decodeBrowserHistoryByDevice(artifacts)
+	matchExchangeSchemes(loaded, pkgsByDev, pkgArtifactIDsByDev, caseID, agg)
+
+	// 浏览历史与书签：按设备、证据类型分组后走与主机相同的匹配流程（交易所访问 + 地址抽取），
+	// 关联证据只绑定同一设备、同一类型的 artifact。
+	groups, err := decodeBrowsingByDevice(artifacts)
 	if err != nil {
 		return nil, err
 	}
-	for deviceID, visits := range visitsByDev {
-		if len(visits) == 0 {
+	for _, g := range groups {
+		matchBrowsingActivity(loaded, g.visits, g.artifacts, agg)
+	}
+
+	// 账户清单：按账户逐条匹配，关联证据只绑定对应的 artifact。
+	for _, a := range artifacts {
+		if a.Type != model.ArtifactMobileAccounts {
 			continue
 		}
-		// 只传入当前设备的 browser_history artifacts（保证 firstDeviceID/关联证据正确）。
-		var devArts []model.Artifact
-		for _, a := range artifacts {
-			if a.DeviceID != deviceID {
-				continue
-			}
-			if a.Type != model.ArtifactBrowserHistory {
-				continue
-			}
-			devArts = append(devArts, a)
-		}
-		// 兜底：如果 artifacts 未携带该 device 的 browser_history（理论上不该发生），
-		// 仍然用 artifactIDs 作为关联证据集合。
-		if len(devArts) == 0 && len(historyArtifactIDsByDev[deviceID]) > 0 {
-			for _, aid := range historyArtifactIDsByDev[deviceID] {
-				devArts = append(devArts, model.Artifact{
-					ID:       aid,
-					CaseID:   caseID,
-					DeviceID: deviceID,
-					Type:     model.ArtifactBrowserHistory,
-				})
-			}
+		var accounts []model.MobileAccountRecord
+		if err := json.Unmarshal(a.PayloadJSON, &accounts); err != nil {
+			return nil, fmt.Errorf("decode mobile_accounts payload: %w", err)
 		}
-
-		matchExchanges(loaded, visits, devArts, agg)
-		matchWalletAddresses(visits, devArts, agg)
+		matchExchanges(loaded, accountDomains(accounts), []model.Artifact{a}, agg)
 	}
+	matchRegexRules(loaded, artifacts, agg)
 
 	hits := make([]model.RuleHit, 0, len(agg))
 	for _, a := range agg {
@@ -160,21 +157,35 @@ func decodeMobilePackagesByDevice(artifacts []model.Artifact) (map[string][]mode
 	return pkgsByDev, artIDsByDev, nil
 }
 
-func decodeBrowserHistoryByDevice(artifacts []model.Artifact) (map[string][]model.VisitRecord, map[string][]string, error) {
-	visitsByDev := map[string][]model.VisitRecord{}
-	artIDsByDev := map[string][]string{}
-	for _, a := range artifacts {
-		if a.Type != model.ArtifactBrowserHistory {
+// browsingGroup 是同一设备、同一证据类型（browser_history / browser_bookmarks）的浏览记录。
+type browsingGroup struct {
+	artifacts []model.Artifact
+	visits    []model.VisitRecord
+}
+
+// decodeBrowsingByDevice 按设备与证据类型分组解码浏览历史与书签（分片证据按序号还原），组顺序为首次出现顺序。
+func decodeBrowsingByDevice(artifacts []model.Artifact) ([]*browsingGroup, error) {
+	var groups []*browsingGroup
+	index := map[string]*browsingGroup{}
+	for _, a := range orderArtifactParts(artifacts) {
+		if a.Type != model.ArtifactBrowserHistory && a.Type != model.ArtifactBrowserBookmarks {
 			continue
 		}
 		var rows []model.VisitRecord
 		if err := json.Unmarshal(a.PayloadJSON, &rows); err != nil {
-			return nil, nil, fmt.Errorf("decode browser_history payload: %w", err)
+			return nil, fmt.Errorf("decode %s payload: %w", a.Type, err)
 		}
-		visitsByDev[a.DeviceID] = append(visitsByDev[a.DeviceID], rows...)
-		artIDsByDev[a.DeviceID] = append(artIDsByDev[a.DeviceID], a.ID)
+		key := a.DeviceID + "|" + string(a.Type)
+		g, ok := index[key]
+		if !ok {
+			g = &browsingGroup{}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.artifacts = append(g.artifacts, a)
+		g.visits = append(g.visits, rows...)
 	}
-	return visitsByDev, artIDsByDev, nil
+	return groups, nil
 }
 
 func toSet(items []string) map[string]struct{} {
@@ -188,3 +199,32 @@ func toSet(items []string) map[string]struct{} {
 	}
 	return set
 }
+
+// accountDomains 把账户清单转换为可按交易所域名规则匹配的记录：
+// - 账户名为邮箱时取邮箱域名（例如 someone@binance.com）
+// - 账户类型按反向域名还原（例如 com.binance.dev -> dev.binance.com，可按根域名命中 binance.com）
+func accountDomains(accounts []model.MobileAccountRecord) []model.VisitRecord {
+	out := make([]model.VisitRecord, 0, len(accounts))
+	for _, acc := range accounts {
+		ref := fmt.Sprintf("account://%s/%s", acc.AccountType, acc.Name)
+		var domains []string
+		if i := strings.LastIndex(acc.Name, "@"); i > 0 && i < len(acc.Name)-1 {
+			domains = append(domains, strings.ToLower(acc.Name[i+1:]))
+		}
+		if labels := strings.Split(strings.ToLower(strings.TrimSpace(acc.AccountType)), "."); len(labels) >= 2 {
+			for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+				labels[i], labels[j] = labels[j], labels[i]
+			}
+			domains = append(domains, strings.Join(labels, "."))
+		}
+		for _, d := range domains {
+			out = append(out, model.VisitRecord{
+				Browser: "android_accounts",
+				Profile: acc.AccountType,
+				URL:     ref,
+				Domain:  d,
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,163 @@
+package matcher
+
+import (
+	"encoding/json"
+	"testing"
+
+	"crypto-inspector/internal/adapters/rules"
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestMatchMobileArtifacts_AccountsAndBookmarks(t *testing.T) {
+	loaded := &rules.LoadedRules{}
+	loaded.Exchange.Exchanges = []model.ExchangeDomain{
+		{ID: "binance", Enabled: true, Name: "Binance", Domains: []string{"binance.com"}},
+		{ID: "okx", Enabled: true, Name: "OKX", Domains: []string{"okx.com"}},
+	}
+
+	accounts, _ := json.Marshal([]model.MobileAccountRecord{
+		{OS: model.OSAndroid, DeviceID: "dev_1", Name: "someone@gmail.com", AccountType: "com.google"},
+		{OS: model.OSAndroid, DeviceID: "dev_1", Name: "trader", AccountType: "com.binance.dev"},
+	})
+	marks, _ := json.Marshal([]model.VisitRecord{
+		{Browser: "chrome", URL: "https://www.okx.com/trade-spot", Domain: "www.okx.com"},
+	})
+	artifacts := []model.Artifact{
+		{ID: "art_accounts", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactMobileAccounts, PayloadJSON: accounts},
+		{ID: "art_bookmarks", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactBrowserBookmarks, PayloadJSON: marks},
+	}
+
+	res, err := MatchMobileArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("MatchMobileArtifacts: %v", err)
+	}
+	got := map[string][]string{}
+	for _, h := range res.Hits {
+		if h.Type == model.HitExchangeVisited {
+			got[h.RuleID] = h.ArtifactIDs
+		}
+	}
+	if len(got) != 2 || len(got["binance"]) != 1 || got["binance"][0] != "art_accounts" ||
+		len(got["okx"]) != 1 || got["okx"][0] != "art_bookmarks" {
+		t.Fatalf("exchange hits=%v", got)
+	}
+}
+
+func TestMatchMobileArtifacts_HarmonyBundles(t *testing.T) {
+	loaded := &rules.LoadedRules{}
+	loaded.Wallet.Wallets = []model.WalletSignature{
+		{ID: "wallet_metamask", Enabled: true, Name: "MetaMask", Mobile: model.WalletMobileHints{AndroidPackages: []string{"io.metamask"}}},
+		{ID: "wallet_tp", Enabled: true, Name: "TokenPocket", Mobile: model.WalletMobileHints{HarmonyBundleNames: []string{"vip.mytokenpocket.harmony"}}},
+	}
+	pkgs, _ := json.Marshal([]model.MobilePackageRecord{
+		{OS: model.OSHarmonyOS, DeviceID: "dev_h", Identifier: "7001005458", Package: "io.metamask"},
+		{OS: model.OSHarmonyOS, DeviceID: "dev_h", Identifier: "7001005458", Package: "vip.mytokenpocket.harmony"},
+		{OS: model.OSHarmonyOS, DeviceID: "dev_h", Identifier: "7001005458", Package: "com.huawei.hmos.settings"},
+	})
+	res, err := MatchMobileArtifacts(loaded, []model.Artifact{
+		{ID: "art_pkgs", CaseID: "case_1", DeviceID: "dev_h", Type: model.ArtifactMobilePackages, PayloadJSON: pkgs},
+	})
+	if err != nil {
+		t.Fatalf("MatchMobileArtifacts: %v", err)
+	}
+	fields := map[string]string{}
+	for _, h := range res.Hits {
+		if h.Type != model.HitWalletInstalled {
+			continue
+		}
+		var d map[string]any
+		_ = json.Unmarshal(h.DetailJSON, &d)
+		fields[h.RuleID], _ = d["match_field"].(string)
+	}
+	if len(fields) != 2 || fields["wallet_metamask"] != "android_package" || fields["wallet_tp"] != "harmony_bundle_name" {
+		t.Fatalf("wallet hits=%v", fields)
+	}
+}
+
+func TestMatchMobileArtifacts_BrowsingParityWithHost(t *testing.T) {
+	loaded := &rules.LoadedRules{}
+	loaded.Exchange.Exchanges = []model.ExchangeDomain{
+		{ID: "binance", Enabled: true, Name: "Binance", Domains: []string{"binance.com"}},
+	}
+	history, _ := json.Marshal([]model.VisitRecord{
+		{Browser: "chrome", URL: "https://www.binance.com/en/my/wallet", Domain: "www.binance.com", VisitedAt: 1700000000},
+		{Browser: "chrome", URL: "https://etherscan.io/address/0x52908400098527886E0F7030069857D2E4169EE7%3Ftab%3Dtxs", Domain: "etherscan.io", VisitedAt: 1700000100},
+	})
+	summarize := func(hits []model.RuleHit, deviceID string) map[string]string {
+		out := map[string]string{}
+		for _, h := range hits {
+			if h.DeviceID != deviceID {
+				continue
+			}
+			out[string(h.Type)+"|"+h.RuleID+"|"+h.MatchedValue] = string(h.DetailJSON)
+		}
+		return out
+	}
+
+	host, err := MatchHostArtifacts(loaded, []model.Artifact{
+		{ID: "art_h", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactBrowserHistory, PayloadJSON: history},
+	})
+	if err != nil {
+		t.Fatalf("MatchHostArtifacts: %v", err)
+	}
+	mobile, err := MatchMobileArtifacts(loaded, []model.Artifact{
+		{ID: "art_m1", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactBrowserHistory, PayloadJSON: history},
+		{ID: "art_m2", CaseID: "case_1", DeviceID: "dev_2", Type: model.ArtifactBrowserHistory, PayloadJSON: history},
+	})
+	if err != nil {
+		t.Fatalf("MatchMobileArtifacts: %v", err)
+	}
+
+	want := summarize(host.Hits, "dev_1")
+	if len(want) != 2 {
+		t.Fatalf("host hits=%v", want)
+	}
+	for _, dev := range []string{"dev_1", "dev_2"} {
+		got := summarize(mobile.Hits, dev)
+		if len(got) != len(want) {
+			t.Fatalf("%s hits=%v want %v", dev, got, want)
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Fatalf("%s hit %s detail=%s want %s", dev, k, got[k], v)
+			}
+		}
+	}
+	for _, h := range mobile.Hits {
+		if len(h.ArtifactIDs) != 1 || h.ArtifactIDs[0] != map[string]string{"dev_1": "art_m1", "dev_2": "art_m2"}[h.DeviceID] {
+			t.Fatalf("hit %s on %s linked to %v", h.MatchedValue, h.DeviceID, h.ArtifactIDs)
+		}
+	}
+}
+
+func TestMatchMobileArtifacts_ExchangeURLSchemes(t *testing.T) {
+	loaded := &rules.LoadedRules{}
+	loaded.Exchange.Exchanges = []model.ExchangeDomain{
+		{ID: "binance", Enabled: true, Name: "Binance", Domains: []string{"binance.com"}},
+		{ID: "okx", Enabled: true, Name: "OKX", Domains: []string{"okx.com"}, Mobile: model.ExchangeMobileHints{URLSchemes: []string{"okx"}}},
+	}
+	pkgs, _ := json.Marshal([]model.MobilePackageRecord{
+		{OS: model.OSAndroid, DeviceID: "dev_1", Package: "com.binance.dev", AppLinkHosts: []string{"app.binance.com"}},
+		{OS: model.OSAndroid, DeviceID: "dev_1", Package: "com.okinc.okex.gp", URLSchemes: []string{"OKX"}},
+		{OS: model.OSAndroid, DeviceID: "dev_1", Package: "com.example.notes", URLSchemes: []string{"notes"}, AppLinkHosts: []string{"notbinance.com"}},
+	})
+	res, err := MatchMobileArtifacts(loaded, []model.Artifact{
+		{ID: "art_pkgs", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactMobilePackages, PayloadJSON: pkgs},
+	})
+	if err != nil {
+		t.Fatalf("MatchMobileArtifacts: %v", err)
+	}
+	fields := map[string]string{}
+	for _, h := range res.Hits {
+		if h.Type != model.HitExchangeAppInstalled || h.Verdict != "confirmed" {
+			t.Fatalf("unexpected hit: %+v", h)
+		}
+		var d map[string]any
+		_ = json.Unmarshal(h.DetailJSON, &d)
+		fields[h.RuleID+"|"+h.MatchedValue], _ = d["match_field"].(string)
+	}
+	want := map[string]string{"binance|com.binance.dev": "app_link_host", "okx|com.okinc.okex.gp": "url_scheme"}
+	if len(fields) != len(want) || fields["binance|com.binance.dev"] != "app_link_host" || fields["okx|com.okinc.okex.gp"] != "url_scheme" {
+		t.Fatalf("fields=%v, want %v", fields, want)
+	}
+}
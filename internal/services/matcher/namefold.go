@@ -0,0 +1,97 @@
+package matcher
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 程序名/频道名的本地化匹配
+//
+// 中文、韩文系统上钱包/交易所客户端常以本地化名称安装（欧易、幣安、바이낸스），规则别名与实际名称之间
+// 常见的差异有：全角/半角、繁体/简体、macOS 文件名中分解形式（NFD）的韩文字母、大小写。
+// 匹配前对规则关键词与被匹配文本做同样的折叠（foldName），并在命中详情中记录名称所用文字（nameScript）。
+
+// foldName 返回用于关键词包含匹配的折叠文本：全角转半角、韩文字母合成音节、常用繁体字转简体、小写化、空白归一。
+func foldName(s string) string {
+	s = foldFullwidth(s)
+	s = composeHangul(s)
+	s = strings.Map(func(r rune) rune {
+		if v, ok := traditionalToSimplified[r]; ok {
+			return v
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// 韩文字母（Hangul Jamo）合成音节用的常量（Unicode 标准第 3.12 节）。
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulSCount = hangulLCount * hangulVCount * hangulTCount
+)
+
+// composeHangul 把分解形式的韩文字母（初声+中声[+终声]）合成为音节，其余字符不变。
+func composeHangul(s string) string {
+	if !strings.ContainsFunc(s, func(r rune) bool { return r >= hangulLBase && r <= 0x11FF }) {
+		return s
+	}
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		if n := len(out); n > 0 {
+			last := out[n-1]
+			switch {
+			case last >= hangulLBase && last < hangulLBase+hangulLCount && r >= hangulVBase && r < hangulVBase+hangulVCount:
+				out[n-1] = hangulSBase + ((last-hangulLBase)*hangulVCount+(r-hangulVBase))*hangulTCount
+				continue
+			case last >= hangulSBase && last < hangulSBase+hangulSCount && (last-hangulSBase)%hangulTCount == 0 &&
+				r > hangulTBase && r < hangulTBase+hangulTCount:
+				out[n-1] = last + (r - hangulTBase)
+				continue
+			}
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// traditionalToSimplified 是加密货币相关名称中常见的繁体字（不追求完整，仅覆盖规则别名用字）。
+var traditionalToSimplified = map[rune]rune{
+	'幣': '币', '錢': '钱', '歐': '欧', '貨': '货', '網': '网', '寶': '宝', '數': '数', '場': '场',
+	'來': '来', '虛': '虚', '擬': '拟', '礦': '矿', '鏈': '链', '區': '区', '塊': '块', '兌': '兑',
+	'換': '换', '帳': '账', '賬': '账', '號': '号', '資': '资', '產': '产', '銀': '银', '證': '证',
+	'開': '开', '門': '门', '萊': '莱', '達': '达', '雲': '云', '鑰': '钥', '記': '记', '詞': '词',
+	'華': '华', '點': '点', '際': '际', '團': '团', '鏡': '镜', '盤': '盘', '買': '买',
+	'賣': '卖', '險': '险', '匯': '汇', '長': '长', '億': '亿', '順': '顺', '體': '体', '國': '国',
+}
+
+// nameScript 粗略识别名称所用文字：han / hangul / kana / latin / mixed（无字母时为空；全角字母按半角计）。
+func nameScript(s string) string {
+	seen := map[string]bool{}
+	for _, r := range foldFullwidth(s) {
+		switch {
+		case unicode.Is(unicode.Hangul, r):
+			seen["hangul"] = true
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			seen["kana"] = true
+		case unicode.Is(unicode.Han, r):
+			seen["han"] = true
+		case unicode.IsLetter(r) && r < 0x250:
+			seen["latin"] = true
+		}
+	}
+	switch len(seen) {
+	case 0:
+		return ""
+	case 1:
+		for k := range seen {
+			return k
+		}
+	}
+	return "mixed"
+}
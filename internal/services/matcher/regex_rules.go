@@ -0,0 +1,125 @@
+package matcher
+
+import (
+	"encoding/json"
+	"strings"
+
+	"crypto-inspector/internal/adapters/rules"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 自定义正则规则
+//
+// 内置地址正则只覆盖 EVM/BTC；机构特有的线索（其他链地址格式、OTC 联系方式、平台订单号等）
+// 写在 rules/regex_rules 中，无需改代码：
+// - 每条规则限定证据类型与字段（字段名取 payload 中的 key，例如 url/title），未限定则检索全部字符串
+// - matched_value 取 value_group 指定的命名分组（未指定取整个匹配），其余命名分组写入 detail.groups
+// - 同一设备、同一规则、同一取值合并为一条命中；rule_id 为 regex:<规则 ID>
+// 命中类型默认为 regex_match，规则也可声明为 wallet_address，以便进入地址聚类与链上查询。
+
+// RegexRuleIDPrefix 是正则规则命中的 rule_id 前缀（用于回填规则包 ID）。
+const RegexRuleIDPrefix = "regex:"
+
+// IsRegexRuleHit 判断命中是否来自自定义正则规则。
+func IsRegexRuleHit(h model.RuleHit) bool {
+	return strings.HasPrefix(h.RuleID, RegexRuleIDPrefix)
+}
+
+// matchRegexRules 对证据 payload 中的字符串字段逐条应用正则规则。
+func matchRegexRules(loaded *rules.LoadedRules, artifacts []model.Artifact, agg map[string]*hitAccumulator) {
+	if loaded == nil || len(loaded.RegexRules) == 0 {
+		return
+	}
+	for _, a := range artifacts {
+		if a.Type == model.ArtifactAnalysis || len(a.PayloadJSON) == 0 {
+			continue
+		}
+		var active []rules.CompiledRegexRule
+		for _, r := range loaded.RegexRules {
+			if r.TypeSet != nil {
+				if _, ok := r.TypeSet[a.Type]; !ok {
+					continue
+				}
+			}
+			active = append(active, r)
+		}
+		if len(active) == 0 {
+			continue
+		}
+		var payload any
+		if err := json.Unmarshal(a.PayloadJSON, &payload); err != nil {
+			continue
+		}
+		walkStrings(payload, "", func(field, text string) {
+			leaf := fieldLeaf(field)
+			for _, r := range active {
+				if r.FieldSet != nil {
+					_, okLeaf := r.FieldSet[leaf]
+					_, okPath := r.FieldSet[field]
+					if !okLeaf && !okPath {
+						continue
+					}
+				}
+				for _, m := range r.Re.FindAllStringSubmatch(text, -1) {
+					value, groups := regexMatchValue(r, m)
+					if strings.TrimSpace(value) == "" {
+						continue
+					}
+					hitType := model.HitType(r.HitType)
+					if hitType == model.HitWalletAddress && strings.HasPrefix(strings.ToLower(value), "0x") {
+						value = strings.ToLower(value)
+					}
+					addOrUpdateHit(agg, hitKey(string(hitType), a.DeviceID, RegexRuleIDPrefix+r.ID, value), model.RuleHit{
+						ID:           id.New("hit"),
+						CaseID:       a.CaseID,
+						DeviceID:     a.DeviceID,
+						Type:         hitType,
+						RuleID:       RegexRuleIDPrefix + r.ID,
+						RuleName:     r.Name,
+						RuleVersion:  loaded.Regex.Version,
+						MatchedValue: value,
+						FirstSeenAt:  a.CollectedAt,
+						LastSeenAt:   a.CollectedAt,
+						Confidence:   r.Confidence,
+						Verdict:      "suspected",
+						DetailJSON: mustJSON(map[string]any{
+							"match_field":   field,
+							"artifact_type": a.Type,
+							"description":   r.Description,
+							"groups":        groups,
+							"sample":        truncateText(text, 240),
+						}),
+						ArtifactIDs: []string{a.ID},
+					})
+				}
+			}
+		})
+	}
+}
+
+// regexMatchValue 返回命中取值与命名分组（未参与匹配的分组省略）。
+func regexMatchValue(r rules.CompiledRegexRule, m []string) (string, map[string]string) {
+	groups := map[string]string{}
+	for i, name := range r.Re.SubexpNames() {
+		if i == 0 || name == "" || i >= len(m) || m[i] == "" {
+			continue
+		}
+		groups[name] = m[i]
+	}
+	if r.ValueGroup != "" {
+		return strings.TrimSpace(groups[r.ValueGroup]), groups
+	}
+	return strings.TrimSpace(m[0]), groups
+}
+
+// fieldLeaf 取点分路径的最后一段并去掉数组下标，例如 "[3].url" -> "url"。
+func fieldLeaf(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		path = path[i+1:]
+	}
+	if i := strings.Index(path, "["); i >= 0 {
+		path = path[:i]
+	}
+	return path
+}
@@ -0,0 +1,101 @@
+package matcher
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crypto-inspector/internal/adapters/rules"
+	"crypto-inspector/internal/domain/model"
+)
+
+func loadTemplateRules(t *testing.T, regexPath string) *rules.LoadedRules {
+	t.Helper()
+	root := filepath.Join("..", "..", "..", "rules")
+	loaded, err := rules.NewLoader(
+		filepath.Join(root, "wallet_signatures.template.yaml"),
+		filepath.Join(root, "exchange_domains.template.yaml"),
+	).WithRegexFile(regexPath).Load(context.Background())
+	if err != nil {
+		t.Fatalf("load rules: %v", err)
+	}
+	return loaded
+}
+
+func TestMatchRegexRulesTemplate(t *testing.T) {
+	loaded := loadTemplateRules(t, filepath.Join("..", "..", "..", "rules", "regex_rules.template.yaml"))
+	if loaded.RegexSHA256 == "" || len(loaded.RegexRules) != 2 {
+		t.Fatalf("regex rules not loaded: %+v", loaded.RegexRules)
+	}
+
+	visits, _ := json.Marshal([]model.VisitRecord{
+		{URL: "https://tronscan.org/#/address/TQn9Y2khEsLJW1ChVWFMSMeRDow5KcbLSE", Title: "TRON account", VisitedAt: 1700000000},
+		{URL: "https://t.me/OtcDesk_888", Title: "OTC desk"},
+		{URL: "https://example.com/?order_id=123456789012", Title: "order"},
+	})
+	apps, _ := json.Marshal([]model.AppRecord{{Name: "TQn9Y2khEsLJW1ChVWFMSMeRDow5KcbLSE"}})
+	artifacts := []model.Artifact{
+		{ID: "art_h", CaseID: "case1", DeviceID: "dev1", Type: model.ArtifactBrowserHistory, CollectedAt: 100, PayloadJSON: visits},
+		{ID: "art_a", CaseID: "case1", DeviceID: "dev1", Type: model.ArtifactInstalledApps, CollectedAt: 100, PayloadJSON: apps},
+	}
+
+	res, err := MatchHostArtifacts(loaded, artifacts)
+	if err != nil {
+		t.Fatalf("match: %v", err)
+	}
+	byRule := map[string]model.RuleHit{}
+	for _, h := range res.Hits {
+		if IsRegexRuleHit(h) {
+			byRule[h.RuleID] = h
+		}
+	}
+	if len(byRule) != 2 {
+		t.Fatalf("regex hits=%+v", byRule)
+	}
+	tron := byRule["regex:tron_address"]
+	if tron.Type != model.HitWalletAddress || tron.MatchedValue != "TQn9Y2khEsLJW1ChVWFMSMeRDow5KcbLSE" || tron.Confidence != 0.75 ||
+		len(tron.ArtifactIDs) != 1 || tron.ArtifactIDs[0] != "art_h" || tron.RuleVersion != loaded.Regex.Version {
+		t.Fatalf("tron hit=%+v", tron)
+	}
+	tg := byRule["regex:telegram_contact"]
+	var detail struct {
+		Field  string            `json:"match_field"`
+		Groups map[string]string `json:"groups"`
+	}
+	_ = json.Unmarshal(tg.DetailJSON, &detail)
+	if tg.Type != model.HitRegexMatch || tg.MatchedValue != "OtcDesk_888" || detail.Groups["handle"] != "OtcDesk_888" || !strings.HasSuffix(detail.Field, "url") {
+		t.Fatalf("telegram hit=%+v detail=%+v", tg, detail)
+	}
+}
+
+func TestRegexRulesLoaderValidation(t *testing.T) {
+	dir := t.TempDir()
+	// 文件不存在：不启用，不报错。
+	if loaded := loadTemplateRules(t, filepath.Join(dir, "missing.yaml")); loaded.RegexSHA256 != "" || len(loaded.RegexRules) != 0 {
+		t.Fatalf("missing regex file should be skipped: %+v", loaded.RegexRules)
+	}
+
+	root := filepath.Join("..", "..", "..", "rules")
+	cases := map[string]string{
+		"bad_pattern": "version: v1\nbundle_type: regex_rules\nrules:\n  - {id: a, name: A, enabled: true, pattern: '(unclosed'}\n",
+		"bad_group":   "version: v1\nbundle_type: regex_rules\nrules:\n  - {id: a, name: A, enabled: true, pattern: '(?P<x>\\d+)', value_group: y}\n",
+		"bad_type":    "version: v1\nbundle_type: regex_rules\nrules:\n  - {id: a, name: A, enabled: true, pattern: 'x', hit_type: exchange_visited}\n",
+		"duplicate":   "version: v1\nbundle_type: regex_rules\nrules:\n  - {id: a, name: A, pattern: 'x'}\n  - {id: a, name: B, pattern: 'y'}\n",
+	}
+	for name, body := range cases {
+		path := filepath.Join(dir, name+".yaml")
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_, err := rules.NewLoader(
+			filepath.Join(root, "wallet_signatures.template.yaml"),
+			filepath.Join(root, "exchange_domains.template.yaml"),
+		).WithRegexFile(path).Load(context.Background())
+		if err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
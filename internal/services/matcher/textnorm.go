@@ -0,0 +1,101 @@
+package matcher
+
+import (
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"crypto-inspector/internal/platform/idn"
+)
+
+// 地址抽取前的文本归一化
+//
+// 浏览记录中的地址常以百分号编码出现（?to=%30x...、跳转参数里的 %2F1BvBM...），编码后的字符会破坏
+// 正则边界判断（base58 地址前紧贴 %2F 的 F 会被当作“更长字符串的一部分”）。抽取前先：
+//   - 逐层解码 %XX（最多 maxUnescapeRounds 层，应对跳转链接的二次编码；非法转义保持原样）
+//   - 全角 ASCII（０ｘ…）折叠为半角
+//   - URL 主机名中的 punycode 标签解码为 Unicode（与交易所域名匹配一致，便于复核样本）
+// 命中详情同时保留原始样本（sample）与解码后样本（decoded_sample）。
+
+const maxUnescapeRounds = 3
+
+// normalizeAddressText 返回用于地址抽取的归一化文本；无需归一化时原样返回。
+func normalizeAddressText(text string) string {
+	out := text
+	for i := 0; i < maxUnescapeRounds && strings.Contains(out, "%"); i++ {
+		next := lenientUnescape(out)
+		if next == out {
+			break
+		}
+		out = next
+	}
+	out = foldFullwidth(out)
+	return decodeURLHost(out)
+}
+
+// lenientUnescape 解码合法的 %XX 转义，非法转义保持原样；解码结果不是合法 UTF-8 时整体保持原样。
+func lenientUnescape(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			b.WriteByte(unhex(s[i+1])<<4 | unhex(s[i+2]))
+			i += 2
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	out := b.String()
+	if !utf8.ValidString(out) {
+		return s
+	}
+	return out
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// foldFullwidth 把全角 ASCII（U+FF01–U+FF5E）与全角空格折叠为半角。
+func foldFullwidth(s string) string {
+	if !strings.ContainsFunc(s, func(r rune) bool { return (r >= 0xFF01 && r <= 0xFF5E) || r == 0x3000 }) {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 0xFF01 && r <= 0xFF5E:
+			return r - 0xFF01 + '!'
+		case r == 0x3000:
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// decodeURLHost 把 URL 主机名中的 punycode 标签解码为 Unicode；不是带主机名的 URL 时原样返回。
+func decodeURLHost(s string) string {
+	if !strings.Contains(s, "xn--") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return s
+	}
+	host := u.Hostname()
+	uni := idn.ToUnicode(host)
+	if uni == strings.ToLower(host) {
+		return s
+	}
+	return strings.Replace(s, host, uni, 1)
+}
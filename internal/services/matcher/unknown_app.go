@@ -0,0 +1,188 @@
+package matcher
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 未知钱包启发式分类（不依赖规则库、也不依赖任何模型）
+//
+// 目的：规则库总会落后于新钱包的出现速度。对“未命中任何钱包规则”的安装软件，
+// 按名称/发布者/安装目录特征打分，超过阈值的输出 wallet_suspected_unknown 低置信命中，
+// 交给分析人员复核（并可反哺规则库）。
+
+const (
+	unknownAppRuleID      = "heuristic_unknown_wallet_app"
+	unknownAppRuleVersion = "builtin-0.1.0"
+
+	// unknownAppThreshold 是输出命中的最低分（分值只用于排序/阈值，不直接等于置信度）。
+	unknownAppThreshold = 0.4
+)
+
+var (
+	// 名称中的强指示词（包含即可）。
+	unknownAppStrongTokens = []string{"wallet", "钱包", "錢包"}
+	// 名称中的弱指示词：英文按“整词”匹配，避免 method/ethernet 之类误报。
+	unknownAppWeakWords = map[string]struct{}{
+		"crypto": {}, "bitcoin": {}, "ethereum": {}, "btc": {}, "eth": {}, "defi": {}, "web3": {},
+		"blockchain": {}, "ledger": {}, "trezor": {}, "coin": {}, "token": {}, "solana": {}, "tron": {},
+	}
+	unknownAppWeakCJK = []string{"加密", "数字货币", "數位貨幣", "区块链", "區塊鏈", "比特币"}
+	// 发布者中的指示词。
+	unknownAppPublisherTokens = []string{"wallet", "crypto", "blockchain", "web3", "defi"}
+)
+
+// classifyUnknownApps 对未命中钱包规则的应用打分，输出 wallet_suspected_unknown 命中。
+func classifyUnknownApps(apps []model.AppRecord, artifacts []model.Artifact, agg map[string]*hitAccumulator) {
+	if len(apps) == 0 {
+		return
+	}
+
+	// 已被规则命中的应用（按应用名）不再重复判定。
+	matched := make(map[string]struct{})
+	for _, a := range agg {
+		if a.hit.Type == model.HitWalletInstalled {
+			matched[strings.ToLower(strings.TrimSpace(a.hit.MatchedValue))] = struct{}{}
+		}
+	}
+
+	artifactIDs := artifactIDsByType(artifacts, map[model.ArtifactType]struct{}{
+		model.ArtifactInstalledApps: {},
+	})
+	now := time.Now().Unix()
+
+	for _, app := range apps {
+		name := strings.TrimSpace(app.Name)
+		if name == "" {
+			continue
+		}
+		if _, ok := matched[strings.ToLower(name)]; ok {
+			continue
+		}
+
+		score, indicators := scoreUnknownApp(app)
+		if score < unknownAppThreshold {
+			continue
+		}
+
+		// 置信度刻意压低在 0.32~0.50，确保不会与规则命中混淆。
+		conf := math.Round((0.2+0.3*math.Min(score, 1))*100) / 100
+		addOrUpdateHit(agg, hitKey(string(model.HitWalletSuspectedUnknown), unknownAppRuleID, name), model.RuleHit{
+			ID:           id.New("hit"),
+			CaseID:       firstCaseID(artifacts),
+			DeviceID:     firstDeviceID(artifacts),
+			Type:         model.HitWalletSuspectedUnknown,
+			RuleID:       unknownAppRuleID,
+			RuleName:     "疑似未知钱包(启发式)",
+			RuleVersion:  unknownAppRuleVersion,
+			MatchedValue: name,
+			FirstSeenAt:  now,
+			LastSeenAt:   now,
+			Confidence:   conf,
+			Verdict:      "suspected",
+			DetailJSON: mustJSON(map[string]any{
+				"match_field":   "heuristic",
+				"score":         math.Round(score*100) / 100,
+				"indicators":    indicators,
+				"publisher":     app.Publisher,
+				"install_path":  firstNonEmpty(app.InstallLocation, app.Path),
+				"bundle_id":     app.BundleID,
+				"install_hints": app.InstallHints,
+			}),
+			ArtifactIDs: artifactIDs,
+		})
+	}
+}
+
+// scoreUnknownApp 计算应用的“钱包相似度”分值，并返回触发的指示项。
+//
+// 规则：只有“运行时特征”（Electron/LevelDB）不足以输出命中，
+// 必须至少有一个名称/发布者/钱包文件特征，否则 Slack/VS Code 这类应用都会被误报。
+func scoreUnknownApp(app model.AppRecord) (float64, []string) {
+	var (
+		score      float64
+		indicators []string
+		semantic   bool
+	)
+
+	lowerName := strings.ToLower(app.Name)
+	for _, tok := range unknownAppStrongTokens {
+		if strings.Contains(lowerName, tok) {
+			score += 0.35
+			semantic = true
+			indicators = append(indicators, "name:"+tok)
+			break
+		}
+	}
+	weak := 0
+	for _, w := range splitWords(lowerName) {
+		if _, ok := unknownAppWeakWords[w]; ok && weak < 2 {
+			score += 0.2
+			weak++
+			semantic = true
+			indicators = append(indicators, "name:"+w)
+		}
+	}
+	for _, tok := range unknownAppWeakCJK {
+		if weak < 2 && strings.Contains(lowerName, tok) {
+			score += 0.2
+			weak++
+			semantic = true
+			indicators = append(indicators, "name:"+tok)
+		}
+	}
+
+	lowerPublisher := strings.ToLower(app.Publisher)
+	for _, tok := range unknownAppPublisherTokens {
+		if lowerPublisher != "" && strings.Contains(lowerPublisher, tok) {
+			score += 0.15
+			semantic = true
+			indicators = append(indicators, "publisher:"+tok)
+			break
+		}
+	}
+
+	runtime := false
+	for _, h := range app.InstallHints {
+		switch h {
+		case "keystore", "wallet_dat":
+			score += 0.3
+			semantic = true
+			indicators = append(indicators, "install:"+h)
+		case "leveldb":
+			score += 0.15
+			indicators = append(indicators, "install:"+h)
+		case "chromium_runtime", "electron_runtime", "electron_asar":
+			if !runtime {
+				score += 0.1
+				runtime = true
+				indicators = append(indicators, "install:"+h)
+			}
+		}
+	}
+
+	if !semantic {
+		return 0, nil
+	}
+	return score, indicators
+}
+
+func splitWords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r)) || r > unicode.MaxASCII
+	})
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,238 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+)
+
+// 案件关注词匹配
+//
+// 规则库只覆盖内置的钱包/交易所特征；案件特有的线索（嫌疑人别名、特定地址、手机号）由办案人员
+// 登记到 case_watchlist，匹配时在全部文本类证据（payload 中的字符串字段）中检索：
+// - keyword / alias / address：忽略大小写的子串匹配
+// - phone：去掉空格、-、()、+ 等分隔符后比较数字串，允许证据或关注词一侧带国家码
+// 同一设备同一关注词合并为一条 watchlist_match 命中，detail 中保留前若干处出现位置。
+
+// watchlistSampleLimit 是每条命中保留的出现位置样例数。
+const watchlistSampleLimit = 5
+
+// watchlistSkipTypes 是不参与关注词检索的证据类型（分析结果由命中派生，检索会自我引用）。
+var watchlistSkipTypes = map[model.ArtifactType]struct{}{
+	model.ArtifactAnalysis: {},
+}
+
+// NormalizeWatchlistTerm 校验并规范化关注词：类型为空时按 keyword 处理。
+func NormalizeWatchlistTerm(term, termType string) (string, string, error) {
+	term = strings.TrimSpace(term)
+	termType = strings.ToLower(strings.TrimSpace(termType))
+	if termType == "" {
+		termType = model.WatchlistKeyword
+	}
+	switch termType {
+	case model.WatchlistKeyword, model.WatchlistAlias:
+		if len([]rune(term)) < 2 {
+			return "", "", apperr.New(apperr.CodeInvalidArgument, "watchlist term must be at least 2 characters")
+		}
+	case model.WatchlistAddress:
+		if len(term) < 8 || strings.ContainsAny(term, " \t") {
+			return "", "", apperr.New(apperr.CodeInvalidArgument, "watchlist address must be at least 8 characters without spaces")
+		}
+	case model.WatchlistPhone:
+		if len(phoneDigits(term)) < 6 {
+			return "", "", apperr.New(apperr.CodeInvalidArgument, "watchlist phone must contain at least 6 digits")
+		}
+	default:
+		return "", "", apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unsupported watchlist term type: %s (keyword|alias|address|phone)", termType))
+	}
+	return term, termType, nil
+}
+
+// watchlistSample 是关注词的一处出现位置。
+type watchlistSample struct {
+	ArtifactID   string `json:"artifact_id"`
+	ArtifactType string `json:"artifact_type"`
+	Field        string `json:"field"`
+	Sample       string `json:"sample"`
+}
+
+// MatchWatchlist 在证据中检索案件关注词，按设备 + 关注词生成 watchlist_match 命中。
+func MatchWatchlist(terms []model.WatchlistTerm, artifacts []model.Artifact) []model.RuleHit {
+	if len(terms) == 0 {
+		return nil
+	}
+	type acc struct {
+		term      model.WatchlistTerm
+		deviceID  string
+		caseID    string
+		first     int64
+		last      int64
+		count     int
+		samples   []watchlistSample
+		artifacts map[string]struct{}
+	}
+	agg := map[string]*acc{}
+	var keys []string
+
+	for _, a := range artifacts {
+		if _, skip := watchlistSkipTypes[a.Type]; skip || len(a.PayloadJSON) == 0 {
+			continue
+		}
+		var payload any
+		if err := json.Unmarshal(a.PayloadJSON, &payload); err != nil {
+			continue
+		}
+		walkStrings(payload, "", func(field, text string) {
+			lower := strings.ToLower(text)
+			for _, t := range terms {
+				if !watchlistContains(t, text, lower) {
+					continue
+				}
+				key := hitKey(a.DeviceID, t.TermID)
+				cur, ok := agg[key]
+				if !ok {
+					cur = &acc{term: t, deviceID: a.DeviceID, caseID: a.CaseID, artifacts: map[string]struct{}{}}
+					agg[key] = cur
+					keys = append(keys, key)
+				}
+				cur.count++
+				cur.artifacts[a.ID] = struct{}{}
+				if a.CollectedAt > 0 && (cur.first == 0 || a.CollectedAt < cur.first) {
+					cur.first = a.CollectedAt
+				}
+				if a.CollectedAt > cur.last {
+					cur.last = a.CollectedAt
+				}
+				if len(cur.samples) < watchlistSampleLimit {
+					cur.samples = append(cur.samples, watchlistSample{
+						ArtifactID:   a.ID,
+						ArtifactType: string(a.Type),
+						Field:        field,
+						Sample:       truncateText(text, 240),
+					})
+				}
+			}
+		})
+	}
+
+	sort.Strings(keys)
+	hits := make([]model.RuleHit, 0, len(keys))
+	for _, k := range keys {
+		cur := agg[k]
+		hits = append(hits, model.RuleHit{
+			ID:           id.New("hit"),
+			CaseID:       cur.caseID,
+			DeviceID:     cur.deviceID,
+			Type:         model.HitWatchlistMatch,
+			RuleID:       "watchlist:" + cur.term.TermID,
+			RuleName:     "关注词命中(" + cur.term.TermType + ")",
+			RuleVersion:  "case-watchlist",
+			MatchedValue: cur.term.Term,
+			FirstSeenAt:  cur.first,
+			LastSeenAt:   cur.last,
+			Confidence:   watchlistConfidence(cur.term.TermType),
+			Verdict:      "suspected",
+			DetailJSON: mustJSON(map[string]any{
+				"term_id":     cur.term.TermID,
+				"term_type":   cur.term.TermType,
+				"note":        cur.term.Note,
+				"match_count": cur.count,
+				"samples":     cur.samples,
+			}),
+			ArtifactIDs: setToSortedSlice(cur.artifacts),
+		})
+	}
+	return hits
+}
+
+// walkStrings 深度遍历 JSON 值，对每个字符串回调（field 为点分路径，数组下标用 [i]）。
+func walkStrings(v any, path string, fn func(field, text string)) {
+	switch x := v.(type) {
+	case string:
+		if strings.TrimSpace(x) != "" {
+			fn(path, x)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			next := k
+			if path != "" {
+				next = path + "." + k
+			}
+			walkStrings(x[k], next, fn)
+		}
+	case []any:
+		for i, item := range x {
+			walkStrings(item, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	}
+}
+
+func watchlistContains(t model.WatchlistTerm, text, lower string) bool {
+	if t.TermType == model.WatchlistPhone {
+		want := phoneDigits(t.Term)
+		for _, run := range phoneRuns(text) {
+			if run == want || strings.HasSuffix(run, want) || (len(run) >= 7 && strings.HasSuffix(want, run)) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(lower, strings.ToLower(t.Term))
+}
+
+func watchlistConfidence(termType string) float64 {
+	switch termType {
+	case model.WatchlistAddress:
+		return 0.90
+	case model.WatchlistPhone:
+		return 0.85
+	default:
+		return 0.70
+	}
+}
+
+// phoneDigits 提取字符串中的全部数字。
+func phoneDigits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// phoneRuns 把文本切成“数字串”：空格、-、.、()、+ 视为号码内部分隔符，其余非数字字符断开。
+func phoneRuns(s string) []string {
+	var out []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			out = append(out, b.String())
+			b.Reset()
+		}
+	}
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '+':
+		case unicode.IsSpace(r):
+		default:
+			flush()
+		}
+	}
+	flush()
+	return out
+}
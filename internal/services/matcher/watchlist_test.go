@@ -0,0 +1,64 @@
+package matcher
+
+import (
+	"encoding/json"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestMatchWatchlist(t *testing.T) {
+	visits, _ := json.Marshal([]model.VisitRecord{
+		{URL: "https://t.me/BigWhale88", Title: "Chat with bigwhale88", VisitedAt: 1700000000},
+		{URL: "https://etherscan.io/address/0xAbCdEf0123456789abcdef0123456789ABCDEF01", Title: "Address"},
+	})
+	accounts, _ := json.Marshal([]model.MobileAccountRecord{{Name: "13800138000", AccountType: "com.whatsapp"}})
+	artifacts := []model.Artifact{
+		{ID: "art_h", CaseID: "case1", DeviceID: "dev1", Type: model.ArtifactBrowserHistory, CollectedAt: 100, PayloadJSON: visits},
+		{ID: "art_a", CaseID: "case1", DeviceID: "dev2", Type: model.ArtifactMobileAccounts, CollectedAt: 200, PayloadJSON: accounts},
+		{ID: "art_x", CaseID: "case1", DeviceID: "dev1", Type: model.ArtifactAnalysis, PayloadJSON: []byte(`{"note":"bigwhale88"}`)},
+	}
+	terms := []model.WatchlistTerm{
+		{TermID: "wl_alias", Term: "BigWhale88", TermType: model.WatchlistAlias},
+		{TermID: "wl_addr", Term: "0xabcdef0123456789abcdef0123456789abcdef01", TermType: model.WatchlistAddress},
+		{TermID: "wl_phone", Term: "+86 138-0013-8000", TermType: model.WatchlistPhone},
+		{TermID: "wl_none", Term: "nothing-here", TermType: model.WatchlistKeyword},
+	}
+
+	hits := MatchWatchlist(terms, artifacts)
+	if len(hits) != 3 {
+		t.Fatalf("hits=%+v", hits)
+	}
+	byRule := map[string]model.RuleHit{}
+	for _, h := range hits {
+		if h.Type != model.HitWatchlistMatch || h.CaseID != "case1" {
+			t.Fatalf("unexpected hit %+v", h)
+		}
+		byRule[h.RuleID] = h
+	}
+	alias := byRule["watchlist:wl_alias"]
+	var detail struct {
+		MatchCount int `json:"match_count"`
+	}
+	_ = json.Unmarshal(alias.DetailJSON, &detail)
+	if alias.DeviceID != "dev1" || detail.MatchCount != 2 || len(alias.ArtifactIDs) != 1 || alias.ArtifactIDs[0] != "art_h" {
+		t.Fatalf("alias hit=%+v detail=%+v", alias, detail)
+	}
+	if h := byRule["watchlist:wl_addr"]; h.DeviceID != "dev1" || h.Confidence != 0.90 {
+		t.Fatalf("address hit=%+v", h)
+	}
+	if h := byRule["watchlist:wl_phone"]; h.DeviceID != "dev2" || h.FirstSeenAt != 200 {
+		t.Fatalf("phone hit=%+v", h)
+	}
+}
+
+func TestNormalizeWatchlistTerm(t *testing.T) {
+	if term, typ, err := NormalizeWatchlistTerm("  alice ", ""); err != nil || term != "alice" || typ != model.WatchlistKeyword {
+		t.Fatalf("term=%q type=%q err=%v", term, typ, err)
+	}
+	for _, c := range [][2]string{{"a", "keyword"}, {"12-34", "phone"}, {"0x12", "address"}, {"x y", "email"}} {
+		if _, _, err := NormalizeWatchlistTerm(c[0], c[1]); err == nil {
+			t.Fatalf("expected error for %v", c)
+		}
+	}
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -15,21 +15,34 @@ import (
 	"crypto-inspector/internal/adapters/rules"
 	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
 	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
 	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/budget"
 	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/snapshot"
+	"crypto-inspector/internal/platform/toolbox"
+	"crypto-inspector/internal/platform/trace"
+	"crypto-inspector/internal/services/authdoc"
+	"crypto-inspector/internal/services/casestorage"
+	"crypto-inspector/internal/services/devicedup"
 	"crypto-inspector/internal/services/matcher"
+	"crypto-inspector/internal/services/precheckpolicy"
 	"crypto-inspector/internal/services/privacy"
+	"crypto-inspector/internal/services/reportjson"
 
 	_ "modernc.org/sqlite"
 )
 
 // Options 定义一次移动端扫描的输入参数。
 type Options struct {
-	DBPath              string
-	EvidenceRoot        string
-	IOSBackupDir        string
-	WalletRulePath      string
-	ExchangeRulePath    string
+	DBPath           string
+	EvidenceRoot     string
+	IOSBackupDir     string
+	WalletRulePath   string
+	ExchangeRulePath string
+	RegexRulePath    string // 可选的自定义正则规则文件（不存在时不启用）
+	// RulesCache 非空时通过共享缓存加载规则（serve 模式），为空时每次从磁盘加载。
+	RulesCache          *rules.Cache
 	CaseID              string
 	Operator            string
 	Note                string
@@ -38,11 +51,20 @@ type Options struct {
 	RequireAuthOrder    bool
 	RequireAuthorized   bool
 	EnableIOSFullBackup bool
-	// EnableAndroid/EnableIOS 用于控制移动端采集范围。
-	// 注意：为兼容旧调用方（未设置该字段的情况），Run 内会把“两者都为 false”视为默认开启。
+	// EnableAndroid/EnableIOS/EnableHarmony 用于控制移动端采集范围。
+	// 注意：为兼容旧调用方（未设置该字段的情况），Run 内会把“全部为 false”视为默认开启。
 	EnableAndroid bool
 	EnableIOS     bool
+	EnableHarmony bool
 	PrivacyMode   string
+	// Profile 是扫描 profile（internal|external，空视为 internal），记录在扫描参数快照中。
+	Profile string
+
+	// SnapshotCompression 是 JSON 证据快照的压缩方式（空/none/gzip），哈希针对压缩后的存储字节。
+	SnapshotCompression string
+
+	// Budget 约束 iOS 完整备份的总字节数（nil 表示不限），超出预算的设备只采元数据。
+	Budget *budget.Budget
 }
 
 // Result 定义一次移动端扫描的摘要输出。
@@ -51,6 +73,7 @@ type Result struct {
 	DeviceCount   int      `json:"device_count"`
 	AndroidCount  int      `json:"android_count"`
 	IOSCount      int      `json:"ios_count"`
+	HarmonyCount  int      `json:"harmony_count"`
 	ArtifactCount int      `json:"artifact_count"`
 	HitCount      int      `json:"hit_count"`
 	WalletHits    int      `json:"wallet_hits"`
@@ -59,10 +82,20 @@ type Result struct {
 	ReportPath    string   `json:"report_path,omitempty"`
 	StartedAt     int64    `json:"started_at"`
 	FinishedAt    int64    `json:"finished_at"`
+	TraceID       string   `json:"trace_id,omitempty"`
+
+	// DuplicateCases 非空表示有设备已登记在其他未结案件中（见 devicedup），可改用已有案件继续采集。
+	DuplicateCases []model.DeviceCaseRef `json:"duplicate_cases,omitempty"`
+
+	// Budget 为本次扫描的采集预算使用情况与跳过的来源（未设置预算时为空）。
+	Budget *budget.Summary `json:"budget,omitempty"`
 }
 
-// Run 执行移动端扫描主流程（Android ADB + iOS 备份接入骨架）。
-func Run(ctx context.Context, opts Options) (*Result, error) {
+// Run 执行移动端扫描主流程（Android ADB + iOS 备份接入骨架 + HarmonyOS hdc）。
+func Run(ctx context.Context, opts Options) (_ *Result, retErr error) {
+	ctx, span := trace.Start(ctx, "mobilescan.Run")
+	defer func() { span.End(retErr) }()
+
 	defaults := app.DefaultConfig()
 	if opts.DBPath == "" {
 		opts.DBPath = defaults.DBPath
@@ -76,6 +109,9 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	if opts.ExchangeRulePath == "" {
 		opts.ExchangeRulePath = defaults.ExchangeRulePath
 	}
+	if opts.RegexRulePath == "" {
+		opts.RegexRulePath = defaults.RegexRulePath
+	}
 	if opts.IOSBackupDir == "" {
 		opts.IOSBackupDir = filepath.Join(opts.EvidenceRoot, "ios_backups")
 	}
@@ -88,11 +124,22 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	if opts.PrivacyMode != "off" && opts.PrivacyMode != "masked" {
 		opts.PrivacyMode = "off"
 	}
+	compression, err := snapshot.ParseCompression(opts.SnapshotCompression)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "invalid snapshot compression")
+	}
+	opts.SnapshotCompression = compression
+	if strings.EqualFold(strings.TrimSpace(opts.Profile), "external") {
+		opts.Profile = "external"
+	} else {
+		opts.Profile = "internal"
+	}
 
-	// 兼容策略：如果两个开关都没显式设置（零值 false/false），默认视为都开启。
-	if !opts.EnableAndroid && !opts.EnableIOS {
+	// 兼容策略：如果开关都没显式设置（零值 false），默认视为都开启。
+	if !opts.EnableAndroid && !opts.EnableIOS && !opts.EnableHarmony {
 		opts.EnableAndroid = true
 		opts.EnableIOS = true
+		opts.EnableHarmony = true
 	}
 
 	if err := os.MkdirAll(filepath.Dir(opts.DBPath), 0o755); err != nil {
@@ -136,38 +183,33 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		return nil, err
 	}
 
+	policy, err := precheckpolicy.Load(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+
 	started := time.Now().Unix()
+	run, err := store.SaveScanRun(ctx, caseID, "", opts.Operator, started, scanOptions(opts))
+	if err != nil {
+		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "save_scan_run", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
+		return nil, err
+	}
 	_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "scan_start", "started", opts.Operator, "mobilescan.Run", map[string]any{
 		"ios_backup_dir":        opts.IOSBackupDir,
 		"enable_ios_backup":     opts.EnableIOSFullBackup,
 		"enable_android":        opts.EnableAndroid,
 		"enable_ios":            opts.EnableIOS,
+		"enable_harmony":        opts.EnableHarmony,
 		"privacy_mode_reserved": opts.PrivacyMode,
+		"snapshot_compression":  opts.SnapshotCompression,
+		"scan_run_id":           run.RunID,
+		"options_sha256":        run.OptionsSHA256,
 	})
 
-	authStatus := model.PrecheckPassed
-	authMessage := opts.AuthorizationOrder
-	if opts.AuthorizationOrder == "" {
-		authStatus = model.PrecheckSkipped
-		authMessage = "not provided"
-		if opts.RequireAuthOrder {
-			authStatus = model.PrecheckFailed
-			authMessage = "authorization order is required but missing"
-		}
+	prechecks, err := authdoc.Prechecks(ctx, store, policy, caseID, opts.AuthorizationOrder, opts.AuthorizationBasis, opts.RequireAuthOrder)
+	if err != nil {
+		return nil, err
 	}
-	prechecks := []model.PrecheckResult{{
-		CaseID:    caseID,
-		ScanScope: "general",
-		CheckCode: "authorization_order",
-		CheckName: "执法授权工单已提供",
-		Required:  opts.RequireAuthOrder,
-		Status:    authStatus,
-		Message:   authMessage,
-		DetailJSON: mustJSON(map[string]any{
-			"authorization_basis": opts.AuthorizationBasis,
-		}),
-		CheckedAt: time.Now().Unix(),
-	}}
 	prechecks = append(prechecks, model.PrecheckResult{
 		CaseID:    caseID,
 		ScanScope: "general",
@@ -182,18 +224,47 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		}),
 		CheckedAt: time.Now().Unix(),
 	})
-	if opts.RequireAuthOrder && opts.AuthorizationOrder == "" {
+	blocked, policyWarnings := policy.Gate(precheckpolicy.ProfileMobileScan, prechecks)
+	if authCheck := prechecks[0]; (authCheck.Required && authCheck.Status == model.PrecheckFailed) || (blocked != nil && blocked.CheckCode == "authorization_order") {
 		_ = store.SavePrecheckResults(ctx, prechecks)
 		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "precheck", "failed", opts.Operator, "mobilescan.Run", map[string]any{
-			"reason": "authorization order required",
+			"reason":     authCheck.Message,
+			"error_code": apperr.CodePrecheckAuth,
 		})
-		return nil, fmt.Errorf("mobile precheck failed: authorization order is required")
+		return nil, apperr.T(apperr.CodePrecheckAuth, "precheck.mobile_failed", authCheck.Message)
 	}
-	prechecks = append(prechecks, precheckTool(caseID, "mobile", "android_adb_available", "Android ADB 工具可用", false, "adb"))
-	prechecks = append(prechecks, precheckTool(caseID, "mobile", "ios_idevice_id_available", "iOS 设备识别工具可用", false, "idevice_id"))
-	prechecks = append(prechecks, precheckTool(caseID, "mobile", "ios_idevicepair_available", "iOS 配对验证工具可用", false, "idevicepair"))
+	if blocked != nil {
+		return nil, abortByPolicy(ctx, store, caseID, opts.Operator, prechecks, blocked)
+	}
+	gated := len(prechecks)
+	quotaCheck, quotaWarning, err := casestorage.Precheck(ctx, store, caseID, "general")
+	prechecks = append(prechecks, quotaCheck)
+	if err != nil {
+		_ = store.SavePrecheckResults(ctx, prechecks)
+		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "precheck", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
+		return nil, err
+	}
+	prechecks = append(prechecks, precheckTool(ctx, caseID, "mobile", "android_adb_available", "Android ADB 工具可用", false, "adb"))
+	prechecks = append(prechecks, precheckTool(ctx, caseID, "mobile", "ios_idevice_id_available", "iOS 设备识别工具可用", false, "idevice_id"))
+	prechecks = append(prechecks, precheckTool(ctx, caseID, "mobile", "ios_idevicepair_available", "iOS 配对验证工具可用", false, "idevicepair"))
+	if opts.EnableHarmony {
+		prechecks = append(prechecks, precheckTool(ctx, caseID, "mobile", "harmony_hdc_available", "HarmonyOS hdc 工具可用", false, "hdc"))
+	}
+	// 策略：在开始采集前判定配额、工具可用性等检查。
+	blocked, more := policy.Gate(precheckpolicy.ProfileMobileScan, prechecks[gated:])
+	policyWarnings = append(policyWarnings, more...)
+	if blocked != nil {
+		return nil, abortByPolicy(ctx, store, caseID, opts.Operator, prechecks, blocked)
+	}
+	gated = len(prechecks)
 
-	scanner := mobile.NewScanner(opts.EvidenceRoot, opts.IOSBackupDir, opts.EnableIOSFullBackup, opts.EnableAndroid, opts.EnableIOS)
+	scanner := mobile.NewScanner(opts.EvidenceRoot, opts.IOSBackupDir, opts.EnableIOSFullBackup, opts.EnableAndroid, opts.EnableIOS, opts.EnableHarmony)
+	scanner.SnapshotCompression = opts.SnapshotCompression
+	scanner.Budget = opts.Budget
+	if scanner.MaxPayloadBytes, err = store.ArtifactPayloadMaxBytes(ctx); err != nil {
+		return nil, err
+	}
+	budgetMark := opts.Budget.Mark()
 	scanResult, err := scanner.Scan(ctx, caseID)
 	if err != nil {
 		prechecks = append(prechecks, model.PrecheckResult{
@@ -208,9 +279,15 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 			DetailJSON: mustJSON(map[string]any{}),
 		})
 		_ = store.SavePrecheckResults(ctx, prechecks)
-		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "collect_mobile", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "collect_mobile", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 		return nil, err
 	}
+	if quotaWarning != "" {
+		scanResult.Warnings = append(scanResult.Warnings, quotaWarning)
+	}
+	scanResult.Warnings = append(scanResult.Warnings, policyWarnings...)
+	budgetSummary := opts.Budget.Since(budgetMark)
+	scanResult.Warnings = append(scanResult.Warnings, budgetSummary.Warnings()...)
 
 	if len(scanResult.Devices) == 0 {
 		prechecks = append(prechecks, model.PrecheckResult{
@@ -226,21 +303,25 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		})
 		if opts.RequireAuthorized {
 			_ = store.SavePrecheckResults(ctx, prechecks)
-			_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "precheck", "failed", opts.Operator, "mobilescan.Run", map[string]any{"reason": "no device connected"})
-			return nil, fmt.Errorf("mobile precheck failed: no device connected")
+			_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "precheck", "failed", opts.Operator, "mobilescan.Run", map[string]any{"reason": "no device connected", "error_code": apperr.CodeNoDevice})
+			return nil, apperr.T(apperr.CodeNoDevice, "precheck.mobile_nodev")
 		}
 	}
 
 	androidCount := 0
 	iosCount := 0
+	harmonyCount := 0
 	hasAuthorized := false
 	unauthorized := 0
+	dupCases := []model.DeviceCaseRef{}
 	for _, d := range scanResult.Devices {
 		switch d.Device.OS {
 		case model.OSAndroid:
 			androidCount++
 		case model.OSIOS:
 			iosCount++
+		case model.OSHarmonyOS:
+			harmonyCount++
 		}
 
 		checkCode := "mobile_device_authorized"
@@ -252,6 +333,9 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		case model.OSIOS:
 			checkCode = "ios_pair_validated"
 			checkName = "iOS 设备配对授权"
+		case model.OSHarmonyOS:
+			checkCode = "harmony_debug_authorized"
+			checkName = "HarmonyOS 调试授权（hdc）"
 		}
 		status := model.PrecheckFailed
 		if d.Authorized {
@@ -278,8 +362,15 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 			}),
 		})
 
+		dupCheck, refs, dupWarning := devicedup.Precheck(ctx, store, caseID, "mobile", d.Device)
+		prechecks = append(prechecks, dupCheck)
+		dupCases = append(dupCases, refs...)
+		if dupWarning != "" {
+			scanResult.Warnings = append(scanResult.Warnings, dupWarning)
+		}
+
 		if err := store.UpsertDeviceWithConnection(ctx, caseID, d.Device, d.ConnectionType, d.Authorized, d.AuthNote); err != nil {
-			_ = store.AppendAudit(ctx, caseID, d.Device.ID, "mobile_scan", "upsert_device", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error()})
+			_ = store.AppendAudit(ctx, caseID, d.Device.ID, "mobile_scan", "upsert_device", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 			return nil, err
 		}
 	}
@@ -287,27 +378,35 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	if len(scanResult.Prechecks) > 0 {
 		prechecks = append(prechecks, scanResult.Prechecks...)
 	}
+	// 策略：判定设备/采集器检查，并补上策略要求但本次未执行的检查。
+	prechecks = append(prechecks, policy.Missing(precheckpolicy.ProfileMobileScan, caseID, prechecks)...)
+	blocked, more = policy.Gate(precheckpolicy.ProfileMobileScan, prechecks[gated:])
+	scanResult.Warnings = append(scanResult.Warnings, more...)
+	if blocked != nil {
+		return nil, abortByPolicy(ctx, store, caseID, opts.Operator, prechecks, blocked)
+	}
 	if err := store.SavePrecheckResults(ctx, prechecks); err != nil {
 		return nil, err
 	}
 	if opts.RequireAuthorized && !hasAuthorized {
-		msg := "no authorized device; require Android USB debugging authorization or iOS pairing authorization"
+		msg := "no authorized device; require Android USB debugging authorization, iOS pairing authorization or HarmonyOS hdc debugging authorization"
 		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "precheck", "failed", opts.Operator, "mobilescan.Run", map[string]any{
 			"require_authorized": opts.RequireAuthorized,
 			"unauthorized_count": unauthorized,
+			"error_code":         apperr.CodeDeviceUnauthorized,
 		})
-		return nil, fmt.Errorf("mobile precheck failed: %s", msg)
+		return nil, apperr.T(apperr.CodeDeviceUnauthorized, "precheck.mobile_failed", msg)
 	}
 
 	if err := store.SaveArtifacts(ctx, scanResult.Artifacts); err != nil {
-		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "save_artifacts", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "save_artifacts", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 		return nil, err
 	}
 
-	loader := rules.NewLoader(opts.WalletRulePath, opts.ExchangeRulePath)
-	loaded, err := loader.Load(ctx)
+	loader := rules.NewLoader(opts.WalletRulePath, opts.ExchangeRulePath).WithRegexFile(opts.RegexRulePath)
+	loaded, err := opts.RulesCache.Load(ctx, loader)
 	if err != nil {
-		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "load_rules", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "load_rules", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 		return nil, err
 	}
 
@@ -317,39 +416,58 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	if id, err := store.EnsureRuleBundle(ctx, "wallet_signatures", loaded.Wallet.Version, loaded.WalletSHA256, opts.WalletRulePath); err == nil {
 		walletBundleID = id
 	} else {
-		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "rule_bundle_wallet", "skipped", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "rule_bundle_wallet", "skipped", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 	}
 	if id, err := store.EnsureRuleBundle(ctx, "exchange_domains", loaded.Exchange.Version, loaded.ExchangeSHA256, opts.ExchangeRulePath); err == nil {
 		exchangeBundleID = id
 	} else {
-		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "rule_bundle_exchange", "skipped", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "rule_bundle_exchange", "skipped", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
+	}
+	regexBundleID := ""
+	if loaded.RegexSHA256 != "" {
+		if id, err := store.EnsureRuleBundle(ctx, "regex_rules", loaded.Regex.Version, loaded.RegexSHA256, opts.RegexRulePath); err == nil {
+			regexBundleID = id
+		} else {
+			_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "rule_bundle_regex", "skipped", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
+		}
 	}
 
 	matchResult, err := matcher.MatchMobileArtifacts(loaded, scanResult.Artifacts)
 	if err != nil {
-		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "match_rules", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "match_rules", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 		return nil, err
 	}
 
 	// 回填 rule_bundle_id：
 	// - 钱包安装命中来自 wallet_signatures
 	// - 交易所访问命中来自 exchange_domains（如果移动端后续也采集到浏览历史）
+	// - 自定义正则命中来自 regex_rules
 	for i := range matchResult.Hits {
 		switch matchResult.Hits[i].Type {
-		case model.HitWalletInstalled:
+		case model.HitWalletInstalled, model.HitWalletExecuted:
 			matchResult.Hits[i].RuleBundleID = walletBundleID
-		case model.HitExchangeVisited:
+		case model.HitExchangeVisited, model.HitExchangeFormActivity, model.HitExchangeAppInstalled, model.HitMessengerCommunity:
 			matchResult.Hits[i].RuleBundleID = exchangeBundleID
 		}
+		if matcher.IsRegexRuleHit(matchResult.Hits[i]) {
+			matchResult.Hits[i].RuleBundleID = regexBundleID
+		}
+	}
+
+	// 案件关注词（best effort）：读取失败只记审计，不影响规则命中入库。
+	if terms, err := store.ListWatchlistTerms(ctx, caseID); err == nil {
+		matchResult.Hits = append(matchResult.Hits, matcher.MatchWatchlist(terms, scanResult.Artifacts)...)
+	} else {
+		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "match_watchlist", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 	}
 
 	if err := store.SaveRuleHits(ctx, matchResult.Hits); err != nil {
-		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "save_hits", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error()})
+		_ = store.AppendAudit(ctx, caseID, "", "mobile_scan", "save_hits", "failed", opts.Operator, "mobilescan.Run", map[string]any{"error": err.Error(), "error_code": apperr.CodeOf(err)})
 		return nil, err
 	}
 
 	// 内部报告（JSON + HTML）
-	jsonPath, jsonHash, jsonErr := writeInternalJSONReport(opts.DBPath, caseID, opts.AuthorizationOrder, opts.PrivacyMode, scanResult.Devices, scanResult.Artifacts, matchResult.Hits, scanResult.Warnings, prechecks)
+	jsonPath, jsonHash, jsonErr := writeInternalJSONReport(ctx, store, opts.DBPath, caseID, opts.AuthorizationOrder, opts.PrivacyMode, scanResult.Devices, reportjson.ArtifactIDs(scanResult.Artifacts), reportjson.HitIDs(matchResult.Hits), scanResult.Warnings, prechecks)
 	jsonReportID := ""
 	if jsonErr == nil {
 		jsonReportID, _ = store.SaveReport(ctx, caseID, "internal_json", jsonPath, jsonHash, "mobilescan-0.1.0", "ready")
@@ -357,7 +475,11 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		scanResult.Warnings = append(scanResult.Warnings, "write internal_json report failed: "+jsonErr.Error())
 	}
 
-	htmlPath, htmlHash, htmlErr := writeInternalHTMLReport(opts.DBPath, caseID, opts.AuthorizationOrder, opts.PrivacyMode, scanResult.Devices, scanResult.Artifacts, matchResult.Hits, scanResult.Warnings, prechecks)
+	org, err := store.GetOrgProfile(ctx)
+	if err != nil {
+		scanResult.Warnings = append(scanResult.Warnings, "load org profile failed: "+err.Error())
+	}
+	htmlPath, htmlHash, htmlErr := writeInternalHTMLReport(opts.DBPath, caseID, opts.AuthorizationOrder, opts.PrivacyMode, org, scanResult.Devices, scanResult.Artifacts, matchResult.Hits, scanResult.Warnings, prechecks)
 	if htmlErr == nil {
 		_, _ = store.SaveReport(ctx, caseID, "internal_html", htmlPath, htmlHash, "mobilescan-0.1.0", "ready")
 	} else {
@@ -375,6 +497,7 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		"warnings":             scanResult.Warnings,
 		"report_internal_json": jsonPath,
 		"report_internal_html": htmlPath,
+		"budget":               budgetSummary,
 	})
 
 	walletHits := 0
@@ -385,10 +508,12 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 	}
 
 	return &Result{
+		TraceID:       span.TraceID(),
 		CaseID:        caseID,
 		DeviceCount:   len(scanResult.Devices),
 		AndroidCount:  androidCount,
 		IOSCount:      iosCount,
+		HarmonyCount:  harmonyCount,
 		ArtifactCount: len(scanResult.Artifacts),
 		HitCount:      len(matchResult.Hits),
 		WalletHits:    walletHits,
@@ -397,10 +522,43 @@ func Run(ctx context.Context, opts Options) (*Result, error) {
 		ReportPath:    jsonPath,
 		StartedAt:     started,
 		FinishedAt:    time.Now().Unix(),
+
+		DuplicateCases: dupCases,
+		Budget:         budgetSummary,
 	}, nil
 }
 
-func precheckTool(caseID, scope, code, name string, required bool, binary string) model.PrecheckResult {
+// scanOptions 生成本次扫描的规范化参数快照（见 model.ScanOptions）。
+func scanOptions(opts Options) model.ScanOptions {
+	out := model.ScanOptions{
+		ScanType:            "mobile_scan",
+		Profile:             opts.Profile,
+		AppVersion:          app.Version,
+		PrivacyMode:         opts.PrivacyMode,
+		SnapshotCompression: opts.SnapshotCompression,
+		RequireAuthOrder:    opts.RequireAuthOrder,
+		AuthorizationOrder:  opts.AuthorizationOrder,
+		AuthorizationBasis:  opts.AuthorizationBasis,
+		EvidenceRoot:        opts.EvidenceRoot,
+		WalletRulePath:      opts.WalletRulePath,
+		ExchangeRulePath:    opts.ExchangeRulePath,
+		RegexRulePath:       opts.RegexRulePath,
+		Mobile: &model.MobileScanOptions{
+			EnableAndroid:       opts.EnableAndroid,
+			EnableIOS:           opts.EnableIOS,
+			EnableHarmony:       opts.EnableHarmony,
+			EnableIOSFullBackup: opts.EnableIOSFullBackup,
+			RequireAuthorized:   opts.RequireAuthorized,
+			IOSBackupDir:        opts.IOSBackupDir,
+		},
+	}
+	if sum := opts.Budget.Since(0); sum != nil {
+		out.BudgetLimitBytes = sum.LimitBytes
+	}
+	return out
+}
+
+func precheckTool(ctx context.Context, caseID, scope, code, name string, required bool, binary string) model.PrecheckResult {
 	result := model.PrecheckResult{
 		CaseID:    caseID,
 		ScanScope: scope,
@@ -409,18 +567,45 @@ func precheckTool(caseID, scope, code, name string, required bool, binary string
 		Required:  required,
 		CheckedAt: time.Now().Unix(),
 	}
-	if _, err := exec.LookPath(binary); err != nil {
+	detail := map[string]any{"binary": binary, "tools_dir": toolbox.Dir()}
+	resolved, err := toolbox.ResolveContext(ctx, binary)
+	if err != nil {
+		// 受管工具哈希校验失败属于完整性问题，记为 failed；单纯未安装记为 skipped。
 		result.Status = model.PrecheckSkipped
-		result.Message = fmt.Sprintf("%s not found", binary)
-		result.DetailJSON = mustJSON(map[string]any{"binary": binary})
+		if errors.Is(err, toolbox.ErrVerification) {
+			result.Status = model.PrecheckFailed
+		}
+		result.Message = err.Error()
+		result.DetailJSON = mustJSON(detail)
 		return result
 	}
+	// 记录实际使用的工具来源/路径/版本，便于复核采集环境。
+	detail["source"] = resolved.Source
+	detail["path"] = resolved.Path
+	detail["version"] = toolbox.Version(ctx, resolved)
+	if resolved.SHA256 != "" {
+		detail["sha256"] = resolved.SHA256
+		detail["bundle"] = resolved.Bundle
+	}
 	result.Status = model.PrecheckPassed
 	result.Message = "ok"
-	result.DetailJSON = mustJSON(map[string]any{"binary": binary})
+	result.DetailJSON = mustJSON(detail)
 	return result
 }
 
+// abortByPolicy 保存前置检查并记录审计后返回策略错误（被策略要求的检查未通过时使用）。
+func abortByPolicy(ctx context.Context, store *sqliteadapter.Store, caseID, operator string, prechecks []model.PrecheckResult, blocked *model.PrecheckResult) error {
+	err := precheckpolicy.BlockedError(precheckpolicy.ProfileMobileScan, blocked)
+	_ = store.SavePrecheckResults(ctx, prechecks)
+	_ = store.AppendAudit(ctx, caseID, blocked.DeviceID, "mobile_scan", "precheck", "failed", operator, "mobilescan.Run", map[string]any{
+		"reason":     "required by precheck policy",
+		"check_code": blocked.CheckCode,
+		"error":      err.Error(),
+		"error_code": apperr.CodePrecheckPolicy,
+	})
+	return err
+}
+
 func mustJSON(v any) []byte {
 	raw, err := json.Marshal(v)
 	if err != nil {
@@ -429,7 +614,8 @@ func mustJSON(v any) []byte {
 	return raw
 }
 
-func writeInternalJSONReport(dbPath, caseID, authOrder, privacyMode string, devices []mobile.ConnectedDevice, artifacts []model.Artifact, hits []model.RuleHit, warnings []string, prechecks []model.PrecheckResult) (path string, sha string, err error) {
+// writeInternalJSONReport 生成手机扫描的内部 JSON 报告；证据与命中按页从库中读取并流式写出（见 reportjson）。
+func writeInternalJSONReport(ctx context.Context, store *sqliteadapter.Store, dbPath, caseID, authOrder, privacyMode string, devices []mobile.ConnectedDevice, artifactIDs, hitIDs []string, warnings []string, prechecks []model.PrecheckResult) (path string, sha string, err error) {
 	reportDir := filepath.Join(filepath.Dir(dbPath), "reports")
 	if err := os.MkdirAll(reportDir, 0o755); err != nil {
 		return "", "", err
@@ -446,16 +632,6 @@ func writeInternalJSONReport(dbPath, caseID, authOrder, privacyMode string, devi
 		Authorization string       `json:"authorization"`
 	}
 
-	type artifactSummary struct {
-		ArtifactID   string `json:"artifact_id"`
-		ArtifactType string `json:"artifact_type"`
-		SourceRef    string `json:"source_ref"`
-		SnapshotPath string `json:"snapshot_path"`
-		SHA256       string `json:"sha256"`
-		CollectedAt  int64  `json:"collected_at"`
-		SizeBytes    int64  `json:"size_bytes"`
-	}
-
 	deviceRows := make([]deviceSummary, 0, len(devices))
 	for _, d := range devices {
 		deviceRows = append(deviceRows, deviceSummary{
@@ -469,63 +645,45 @@ func writeInternalJSONReport(dbPath, caseID, authOrder, privacyMode string, devi
 		})
 	}
 
-	artifactRows := make([]artifactSummary, 0, len(artifacts))
-	for _, a := range artifacts {
-		snap := a.SnapshotPath
-		if masked {
-			snap = privacy.MaskSnapshotPath(snap)
-		}
-		artifactRows = append(artifactRows, artifactSummary{
-			ArtifactID:   a.ID,
-			ArtifactType: string(a.Type),
-			SourceRef:    a.SourceRef,
-			SnapshotPath: snap,
-			SHA256:       a.SHA256,
-			CollectedAt:  a.CollectedAt,
-			SizeBytes:    a.SizeBytes,
-		})
-	}
-
-	if masked {
-		hits = privacy.MaskRuleHitsForReport(hits)
-	}
-	payload := map[string]any{
-		"case_id":             caseID,
-		"authorization_order": authOrder,
-		"privacy_mode":        privacyMode,
-		"generated_at":        time.Now().Unix(),
-		"devices":             deviceRows,
-		"summary": map[string]any{
-			"device_count":   len(devices),
-			"artifact_count": len(artifacts),
-			"hit_count":      len(hits),
-			"precheck_count": len(prechecks),
-		},
-		"prechecks": prechecks,
-		"artifacts": artifactRows,
-		"hits":      hits,
-		"warnings":  warnings,
-	}
-
-	raw, err := json.MarshalIndent(payload, "", "  ")
+	training, err := store.IsTrainingDB(ctx)
 	if err != nil {
 		return "", "", err
 	}
-
 	filename := fmt.Sprintf("%s_mobile_internal_%d.json", caseID, time.Now().Unix())
+	if training {
+		filename = "TRAINING_" + filename
+	}
 	path = filepath.Join(reportDir, filename)
-	if err := os.WriteFile(path, raw, 0o644); err != nil {
-		return "", "", err
+	// 字段按键名字母序排列（与原先 map 编码的输出一致）。
+	fields := []reportjson.Field{
+		{Key: "artifacts", Stream: reportjson.Artifacts(ctx, store, artifactIDs, masked)},
+		{Key: "authorization_order", Value: authOrder},
+		{Key: "case_id", Value: caseID},
+		{Key: "devices", Value: deviceRows},
+		{Key: "generated_at", Value: time.Now().Unix()},
+		{Key: "hits", Stream: reportjson.Hits(ctx, store, hitIDs, masked)},
+		{Key: "prechecks", Value: prechecks},
+		{Key: "privacy_mode", Value: privacyMode},
+		{Key: "summary", Value: map[string]any{
+			"device_count":   len(devices),
+			"artifact_count": len(artifactIDs),
+			"hit_count":      len(hitIDs),
+			"precheck_count": len(prechecks),
+		}},
 	}
-
-	sum, _, err := hash.File(path)
+	if training {
+		// 演练库标记插在 warnings 之前，保持字母序。
+		fields = append(fields, reportjson.Field{Key: "training", Value: model.TrainingWatermark})
+	}
+	fields = append(fields, reportjson.Field{Key: "warnings", Value: warnings})
+	sum, err := reportjson.WriteFile(path, fields)
 	if err != nil {
 		return "", "", err
 	}
 	return path, sum, nil
 }
 
-func writeInternalHTMLReport(dbPath, caseID, authOrder, privacyMode string, devices []mobile.ConnectedDevice, artifacts []model.Artifact, hits []model.RuleHit, warnings []string, prechecks []model.PrecheckResult) (path string, sha string, err error) {
+func writeInternalHTMLReport(dbPath, caseID, authOrder, privacyMode string, org model.OrgProfile, devices []mobile.ConnectedDevice, artifacts []model.Artifact, hits []model.RuleHit, warnings []string, prechecks []model.PrecheckResult) (path string, sha string, err error) {
 	reportDir := filepath.Join(filepath.Dir(dbPath), "reports")
 	if err := os.MkdirAll(reportDir, 0o755); err != nil {
 		return "", "", err
@@ -536,14 +694,19 @@ func writeInternalHTMLReport(dbPath, caseID, authOrder, privacyMode string, devi
 	}
 
 	now := time.Now().Unix()
+	title := "数字货币痕迹检测报告（移动端，内部）"
 	filename := fmt.Sprintf("%s_mobile_internal_%d.html", caseID, now)
+	if org.Training {
+		filename = "TRAINING_" + filename
+		title += " - " + model.TrainingWatermark
+	}
 	path = filepath.Join(reportDir, filename)
 
 	var b strings.Builder
 	b.Grow(32 * 1024)
 	b.WriteString("<!doctype html>\n<html lang=\"zh-CN\">\n<head>\n")
 	b.WriteString("<meta charset=\"utf-8\"/>\n<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\"/>\n")
-	b.WriteString("<title>数字货币痕迹检测报告（移动端，内部）</title>\n")
+	b.WriteString("<title>" + htmlEscape(title) + "</title>\n")
 	b.WriteString("<style>\n")
 	b.WriteString("body{font-family:ui-monospace,SFMono-Regular,Menlo,Monaco,Consolas,\"Liberation Mono\",monospace;background:#0b1220;color:#e8e8e8;margin:0;padding:24px;}\n")
 	b.WriteString("h1{font-size:18px;margin:0 0 12px 0;}\n")
@@ -560,12 +723,18 @@ func writeInternalHTMLReport(dbPath, caseID, authOrder, privacyMode string, devi
 	b.WriteString(".mono{font-family:inherit;word-break:break-all;}\n")
 	b.WriteString("</style>\n</head>\n<body>\n")
 
-	b.WriteString("<h1>数字货币痕迹检测报告（移动端，内部）</h1>\n")
+	b.WriteString("<h1>" + htmlEscape(title) + "</h1>\n")
+	if org.Training {
+		b.WriteString("<div class=\"box bad\" style=\"margin-bottom:12px;font-size:14px;\">" + model.TrainingWatermark + "：演练模式，数据为内置模拟数据源，不得作为证据使用。</div>\n")
+	}
 	b.WriteString("<div class=\"box kv\">")
 	b.WriteString("<div class=\"muted\">case_id</div><div class=\"mono\">" + htmlEscape(caseID) + "</div>")
 	b.WriteString("<div class=\"muted\">generated_at</div><div class=\"mono\">" + htmlEscape(time.Unix(now, 0).Format("2006-01-02 15:04:05")) + "</div>")
 	b.WriteString("<div class=\"muted\">authorization_order</div><div class=\"mono\">" + htmlEscape(authOrder) + "</div>")
 	b.WriteString("<div class=\"muted\">privacy_mode</div><div class=\"mono\">" + htmlEscape(privacyMode) + "</div>")
+	if org.AgencyName != "" || org.Unit != "" {
+		b.WriteString("<div class=\"muted\">agency</div><div>" + htmlEscape(strings.Trim(org.AgencyName+" / "+org.Unit, " /")) + "</div>")
+	}
 	b.WriteString("</div>\n")
 
 	b.WriteString("<h2>设备</h2>\n<div class=\"box\">")
@@ -681,6 +850,9 @@ func writeInternalHTMLReport(dbPath, caseID, authOrder, privacyMode string, devi
 	}
 	b.WriteString("</div>\n")
 
+	if footer := strings.Trim(org.Address+" | "+org.Contact, " |"); footer != "" {
+		b.WriteString("<p class=\"muted\">" + htmlEscape(footer) + "</p>\n")
+	}
 	b.WriteString("</body>\n</html>\n")
 
 	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
@@ -0,0 +1,328 @@
+package nameresolve
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+	"crypto-inspector/internal/services/chainbalance"
+)
+
+// 链上域名抽取与解析
+//
+// 浏览痕迹里常见的是 alice.eth / bob.bnb 这类链上域名，而不是原始地址：
+// - 从 URL（先做 URL 解码）与标题中抽取 .eth（ENS）/ .bnb（SPACE ID）域名
+// - 扫描时通过配置的 RPC 解析为地址，记录固定区块号/时间戳（解析结果随时间可能变化）
+// - 解析成功的地址作为 wallet_address 命中，并与抽取到的地址一起写入案件地址簿
+//
+// 未配置 RPC 时不访问网络，只把域名记录为 skipped，便于事后补查。
+
+// 解析状态（在 chainbalance 的 resolved/unresolved 基础上补充本地状态）。
+const (
+	StatusResolved   = chainbalance.NameStatusResolved
+	StatusUnresolved = chainbalance.NameStatusUnresolved
+	StatusFailed     = "failed"  // RPC 重试/切换备用节点后仍失败
+	StatusSkipped    = "skipped" // 未配置对应链的 RPC
+)
+
+// 地址簿来源。
+const (
+	SourceExtracted = "extracted"
+)
+
+// maxNames 限制单次扫描解析的域名数量，避免异常历史记录触发大量 RPC 请求。
+const maxNames = 200
+
+// reName 匹配 ENS 风格域名；标签合法性在 normalizeName 中进一步校验。
+var reName = regexp.MustCompile(`(?i)\b[a-z0-9][a-z0-9-]*(?:\.[a-z0-9][a-z0-9-]*)*\.(?:eth|bnb)\b`)
+
+// Options 控制解析使用的 RPC。
+type Options struct {
+	ETHRPCURL string // .eth 解析使用的以太坊主网 RPC
+	BNBRPCURL string // .bnb 解析使用的 BNB Chain RPC
+
+	// Retry 为单节点重试策略（nil 使用默认策略）。
+	Retry *chainbalance.RetryPolicy
+	// Timeout 为整体解析超时（默认 60s）。
+	Timeout time.Duration
+}
+
+// Candidate 是从浏览痕迹中抽取到的一个域名。
+type Candidate struct {
+	Name        string
+	Service     string
+	FirstSeenAt int64
+	LastSeenAt  int64
+	Sample      string
+}
+
+// Result 是一次抽取+解析的结果。
+type Result struct {
+	Resolutions []model.NameResolution
+	// Hits 为解析成功的地址命中（wallet_address），由调用方与其他命中一起保存。
+	Hits []model.RuleHit
+}
+
+// ExtractNames 从访问记录中抽取链上域名（去重，按名称排序）。
+func ExtractNames(visits []model.VisitRecord) []Candidate {
+	byName := map[string]*Candidate{}
+	for _, v := range visits {
+		text := v.URL
+		if u, err := url.QueryUnescape(text); err == nil {
+			text = u
+		}
+		for _, src := range []string{text, v.Title} {
+			for _, m := range reName.FindAllString(src, -1) {
+				name, service, ok := normalizeName(m)
+				if !ok {
+					continue
+				}
+				c := byName[name]
+				if c == nil {
+					c = &Candidate{Name: name, Service: service, Sample: truncate(src, 240)}
+					byName[name] = c
+				}
+				if v.VisitedAt > 0 {
+					if c.FirstSeenAt == 0 || v.VisitedAt < c.FirstSeenAt {
+						c.FirstSeenAt = v.VisitedAt
+					}
+					if v.VisitedAt > c.LastSeenAt {
+						c.LastSeenAt = v.VisitedAt
+					}
+				}
+			}
+		}
+	}
+
+	out := make([]Candidate, 0, len(byName))
+	for _, c := range byName {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Resolve 抽取主机证据中的域名并解析（只访问 RPC，不写数据库）。
+func Resolve(ctx context.Context, artifacts []model.Artifact, opts Options) (*Result, error) {
+	visits, artifactIDs, caseID, deviceID, err := decodeVisits(artifacts)
+	if err != nil {
+		return nil, err
+	}
+	cands := ExtractNames(visits)
+	if len(cands) > maxNames {
+		cands = cands[:maxNames]
+	}
+	res := &Result{Resolutions: []model.NameResolution{}, Hits: []model.RuleHit{}}
+	if len(cands) == 0 {
+		return res, nil
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	rpcByService := map[string]string{
+		chainbalance.NameServiceENS:     strings.TrimSpace(opts.ETHRPCURL),
+		chainbalance.NameServiceSpaceID: strings.TrimSpace(opts.BNBRPCURL),
+	}
+	names := map[string][]string{}
+	for _, c := range cands {
+		names[c.Service] = append(names[c.Service], c.Name)
+	}
+
+	resolved := map[string]chainbalance.NameResolution{}
+	failed := map[string]string{}
+	for service, list := range names {
+		rpcURL := rpcByService[service]
+		if rpcURL == "" {
+			continue
+		}
+		r := chainbalance.NewNameResolver(rpcURL)
+		r.Retry = opts.Retry
+		ok, errs, err := r.ResolvePartial(ctx, list)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s names: %w", service, err)
+		}
+		for k, v := range ok {
+			resolved[k] = v
+		}
+		for k, v := range errs {
+			failed[k] = v.Error
+		}
+	}
+
+	now := time.Now().Unix()
+	for _, c := range cands {
+		_, registry, _ := chainbalance.NameServiceFor(c.Name)
+		node := chainbalance.Namehash(c.Name)
+		item := model.NameResolution{
+			CaseID:      caseID,
+			DeviceID:    deviceID,
+			Name:        c.Name,
+			Service:     c.Service,
+			Namehash:    fmt.Sprintf("0x%x", node[:]),
+			Registry:    registry,
+			RPCURL:      rpcByService[c.Service],
+			ArtifactIDs: artifactIDs,
+			FirstSeenAt: c.FirstSeenAt,
+			LastSeenAt:  c.LastSeenAt,
+			ResolvedAt:  now,
+		}
+		switch {
+		case item.RPCURL == "":
+			item.Status = StatusSkipped
+		case failed[c.Name] != "":
+			item.Status = StatusFailed
+			item.Error = failed[c.Name]
+		default:
+			r, ok := resolved[c.Name]
+			if !ok {
+				item.Status = StatusFailed
+				item.Error = "no result"
+				break
+			}
+			item.Status = r.Status
+			item.ResolvedAddress = r.Address
+			item.Resolver = r.Resolver
+			item.Registry = r.Registry
+			item.BlockNumber = r.BlockNumber
+			item.BlockTimestamp = r.BlockTimestamp
+			item.RPCURL = r.RPCURL
+		}
+		res.Resolutions = append(res.Resolutions, item)
+		if item.Status == StatusResolved && item.ResolvedAddress != "" {
+			res.Hits = append(res.Hits, resolutionHit(item, c))
+		}
+	}
+	return res, nil
+}
+
+// AddressBook 把钱包地址命中转换为地址簿条目（域名解析命中带域名标签）。
+func AddressBook(caseID string, hits []model.RuleHit) []model.CaseAddress {
+	out := make([]model.CaseAddress, 0, len(hits))
+	for _, h := range hits {
+		if h.Type != model.HitWalletAddress || strings.TrimSpace(h.MatchedValue) == "" {
+			continue
+		}
+		var detail struct {
+			Chain   string `json:"chain"`
+			Name    string `json:"name"`
+			Service string `json:"name_service"`
+		}
+		_ = json.Unmarshal(h.DetailJSON, &detail)
+		a := model.CaseAddress{
+			CaseID:      caseID,
+			Address:     h.MatchedValue,
+			Chain:       detail.Chain,
+			Source:      SourceExtracted,
+			FirstSeenAt: h.FirstSeenAt,
+			LastSeenAt:  h.LastSeenAt,
+		}
+		if detail.Service != "" {
+			a.Source = detail.Service
+			a.Label = detail.Name
+			a.Detail = json.RawMessage(h.DetailJSON)
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+func resolutionHit(r model.NameResolution, c Candidate) model.RuleHit {
+	first, last := c.FirstSeenAt, c.LastSeenAt
+	if first == 0 {
+		first = r.ResolvedAt
+	}
+	if last == 0 {
+		last = first
+	}
+	detail, _ := json.Marshal(map[string]any{
+		"chain":           "evm",
+		"name":            r.Name,
+		"name_service":    r.Service,
+		"namehash":        r.Namehash,
+		"resolver":        r.Resolver,
+		"registry":        r.Registry,
+		"block_number":    r.BlockNumber,
+		"block_timestamp": r.BlockTimestamp,
+		"rpc_url":         r.RPCURL,
+		"sample":          c.Sample,
+	})
+	return model.RuleHit{
+		ID:           id.New("hit"),
+		CaseID:       r.CaseID,
+		DeviceID:     r.DeviceID,
+		Type:         model.HitWalletAddress,
+		RuleID:       "name_resolution_" + r.Service,
+		RuleName:     "链上域名解析(" + r.Name + ")",
+		RuleVersion:  "builtin-0.1.0",
+		MatchedValue: r.ResolvedAddress,
+		FirstSeenAt:  first,
+		LastSeenAt:   last,
+		Confidence:   0.70,
+		Verdict:      "suspected",
+		DetailJSON:   detail,
+		ArtifactIDs:  r.ArtifactIDs,
+	}
+}
+
+func decodeVisits(artifacts []model.Artifact) (visits []model.VisitRecord, artifactIDs []string, caseID, deviceID string, err error) {
+	for _, a := range artifacts {
+		if caseID == "" {
+			caseID = a.CaseID
+		}
+		if deviceID == "" {
+			deviceID = a.DeviceID
+		}
+		if a.Type != model.ArtifactBrowserHistory {
+			continue
+		}
+		var rows []model.VisitRecord
+		if err := json.Unmarshal(a.PayloadJSON, &rows); err != nil {
+			return nil, nil, "", "", fmt.Errorf("decode browser_history payload %s: %w", a.ID, err)
+		}
+		visits = append(visits, rows...)
+		artifactIDs = append(artifactIDs, a.ID)
+	}
+	return visits, artifactIDs, caseID, deviceID, nil
+}
+
+// normalizeName 小写化并校验标签：每个标签不以 '-' 开头/结尾，二级标签至少 3 个字符（ENS 注册规则）。
+func normalizeName(raw string) (name, service string, ok bool) {
+	name = strings.ToLower(strings.TrimSpace(raw))
+	service, _, ok = chainbalance.NameServiceFor(name)
+	if !ok {
+		return "", "", false
+	}
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 || len(name) > 253 {
+		return "", "", false
+	}
+	for _, l := range labels {
+		if l == "" || len(l) > 63 || strings.HasPrefix(l, "-") || strings.HasSuffix(l, "-") {
+			return "", "", false
+		}
+	}
+	if len(labels[len(labels)-2]) < 3 {
+		return "", "", false
+	}
+	return name, service, true
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "..."
+}
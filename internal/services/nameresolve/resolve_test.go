@@ -0,0 +1,68 @@
+package nameresolve
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestExtractNames(t *testing.T) {
+	visits := []model.VisitRecord{
+		{URL: "https://app.ens.domains/Alice.eth", Title: "alice.eth | ENS", VisitedAt: 200},
+		{URL: "https://etherscan.io/enslookup-search?search=alice.eth%2C%20bob.bnb", VisitedAt: 100},
+		{URL: "https://vitalik.eth.limo/", Title: "Vitalik", VisitedAt: 300},
+		// 不应命中：二级标签过短 / 后缀不完整 / 标签以 '-' 结尾
+		{URL: "https://ab.eth/x", Title: "docs.ethereum.org, bad-.eth", VisitedAt: 400},
+	}
+	got := ExtractNames(visits)
+	want := []Candidate{
+		{Name: "alice.eth", Service: "ens", FirstSeenAt: 100, LastSeenAt: 200},
+		{Name: "bob.bnb", Service: "space_id", FirstSeenAt: 100, LastSeenAt: 100},
+		{Name: "vitalik.eth", Service: "ens", FirstSeenAt: 300, LastSeenAt: 300},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("names=%+v", got)
+	}
+	for i := range want {
+		if got[i].Name != want[i].Name || got[i].Service != want[i].Service ||
+			got[i].FirstSeenAt != want[i].FirstSeenAt || got[i].LastSeenAt != want[i].LastSeenAt {
+			t.Fatalf("names[%d]=%+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestResolve_SkippedWithoutRPCAndAddressBook(t *testing.T) {
+	payload, _ := json.Marshal([]model.VisitRecord{{URL: "https://app.ens.domains/alice.eth", VisitedAt: 100}})
+	artifacts := []model.Artifact{{ID: "art_1", CaseID: "case_1", DeviceID: "dev_1", Type: model.ArtifactBrowserHistory, PayloadJSON: payload}}
+
+	res, err := Resolve(context.Background(), artifacts, Options{})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(res.Hits) != 0 || len(res.Resolutions) != 1 {
+		t.Fatalf("result=%+v", res)
+	}
+	r := res.Resolutions[0]
+	if r.Status != StatusSkipped || r.CaseID != "case_1" || r.Namehash == "" || len(r.ArtifactIDs) != 1 {
+		t.Fatalf("resolution=%+v", r)
+	}
+
+	// 解析命中在地址簿中带域名来源与标签；普通抽取命中为 extracted。
+	hits := []model.RuleHit{
+		{Type: model.HitWalletAddress, MatchedValue: "0xabc", DetailJSON: []byte(`{"chain":"evm"}`)},
+		resolutionHit(model.NameResolution{CaseID: "case_1", Name: "alice.eth", Service: "ens", ResolvedAddress: "0xdef", ResolvedAt: 50}, Candidate{}),
+		{Type: model.HitExchangeVisited, MatchedValue: "binance.com"},
+	}
+	book := AddressBook("case_1", hits)
+	if len(book) != 2 {
+		t.Fatalf("book=%+v", book)
+	}
+	if book[0].Source != SourceExtracted || book[0].Chain != "evm" {
+		t.Fatalf("book[0]=%+v", book[0])
+	}
+	if book[1].Source != "ens" || book[1].Label != "alice.eth" || book[1].FirstSeenAt != 50 {
+		t.Fatalf("book[1]=%+v", book[1])
+	}
+}
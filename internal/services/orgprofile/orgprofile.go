@@ -0,0 +1,178 @@
+package orgprofile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/filetype"
+)
+
+// 单位信息（报告页眉页脚与编号）
+//
+// 单位名称、部门、地址、徽标与联系方式统一写入报告页眉/页脚；配置了编号前缀时，
+// 对外交付的报告（取证 PDF、取证/披露/关系图导出包）按 <前缀><6 位流水号> 签发编号，
+// 编号写入报告页眉、导出文件名与 reports.report_no。
+// 流水号存放在库内（org_profile.report_seq），签发后即递增；导出失败时该编号作废，不回收。
+// 未配置时报告与文件名保持原样。
+// 演练库（见 training 包）签发的报告另加“演练/TRAINING”水印：页眉前缀、文件名前缀 TRAINING_ 与清单 training 字段。
+
+// MaxFieldLen 限制单个文本字段长度。
+const MaxFieldLen = 200
+
+var rePrefix = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$`)
+
+// Load 返回当前单位信息。
+func Load(ctx context.Context, store *sqliteadapter.Store) (model.OrgProfile, error) {
+	return store.GetOrgProfile(ctx)
+}
+
+// Save 校验并保存单位信息（操作员与时间记录在 org_profile.updated_by/updated_at）。
+func Save(ctx context.Context, store *sqliteadapter.Store, p model.OrgProfile, operator string) (model.OrgProfile, error) {
+	operator = strings.TrimSpace(operator)
+	if operator == "" {
+		operator = "system"
+	}
+	p.AgencyName = strings.TrimSpace(p.AgencyName)
+	p.Unit = strings.TrimSpace(p.Unit)
+	p.Address = strings.TrimSpace(p.Address)
+	p.LogoPath = strings.TrimSpace(p.LogoPath)
+	p.Contact = strings.TrimSpace(p.Contact)
+	p.ReportPrefix = strings.TrimSpace(p.ReportPrefix)
+
+	for name, v := range map[string]string{
+		"agency_name": p.AgencyName, "unit": p.Unit, "address": p.Address, "contact": p.Contact,
+	} {
+		if len([]rune(v)) > MaxFieldLen {
+			return p, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("%s too long: max=%d chars", name, MaxFieldLen))
+		}
+	}
+	if p.ReportPrefix != "" && !rePrefix.MatchString(p.ReportPrefix) {
+		return p, apperr.New(apperr.CodeInvalidArgument, "report_prefix must be 1-32 chars of letters, digits, '-' or '_'")
+	}
+	if p.LogoPath != "" {
+		head, err := readHead(p.LogoPath, 512)
+		if err != nil {
+			return p, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("logo file not readable: %s", p.LogoPath))
+		}
+		// 只按文件头判定（不信任扩展名），gofpdf 只支持 PNG/JPEG。
+		switch mime := filetype.Detect(head, ""); mime {
+		case filetype.MimePNG, filetype.MimeJPEG:
+		default:
+			return p, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("logo must be a PNG or JPEG file (detected %s)", mime))
+		}
+	}
+
+	if err := store.SaveOrgProfile(ctx, p, operator); err != nil {
+		return p, err
+	}
+	return store.GetOrgProfile(ctx)
+}
+
+// Stamp 是一份报告签发时的单位信息与编号（ReportNo 为空表示未配置编号前缀）。
+type Stamp struct {
+	Profile  model.OrgProfile
+	ReportNo string
+}
+
+// Issue 读取单位信息并签发下一个报告编号。
+func Issue(ctx context.Context, store *sqliteadapter.Store) (Stamp, error) {
+	p, err := store.GetOrgProfile(ctx)
+	if err != nil {
+		return Stamp{}, err
+	}
+	prefix, seq, ok, err := store.NextReportSeq(ctx)
+	if err != nil {
+		return Stamp{Profile: p}, err
+	}
+	st := Stamp{Profile: p}
+	if ok {
+		st.Profile.ReportSeq = seq
+		st.ReportNo = FormatReportNo(prefix, seq)
+	}
+	return st, nil
+}
+
+// FormatReportNo 返回 <前缀><6 位流水号>，例如 GA-SZ-000042。
+func FormatReportNo(prefix string, seq int64) string {
+	return fmt.Sprintf("%s%06d", prefix, seq)
+}
+
+// FileName 在文件名前加上报告编号（未签发编号时不加）；演练库再加 TRAINING_ 前缀。
+func (s Stamp) FileName(name string) string {
+	if s.ReportNo != "" {
+		name = s.ReportNo + "_" + name
+	}
+	if s.Profile.Training {
+		name = "TRAINING_" + name
+	}
+	return name
+}
+
+// Watermark 返回报告水印文字（非演练库为空）。
+func (s Stamp) Watermark() string {
+	if !s.Profile.Training {
+		return ""
+	}
+	return model.TrainingWatermark
+}
+
+// Header 返回页眉文本：单位名称与部门（演练库前置水印）。
+func (s Stamp) Header() string {
+	return joinNonEmpty(" / ", s.Watermark(), s.Profile.AgencyName, s.Profile.Unit)
+}
+
+// Footer 返回页脚文本：地址与联系方式。
+func (s Stamp) Footer() string {
+	return joinNonEmpty(" | ", s.Profile.Address, s.Profile.Contact)
+}
+
+// Fields 返回写入导出清单的单位信息字段。
+func (s Stamp) Fields() map[string]any {
+	return map[string]any{
+		"report_no":   s.ReportNo,
+		"agency_name": s.Profile.AgencyName,
+		"unit":        s.Profile.Unit,
+		"address":     s.Profile.Address,
+		"contact":     s.Profile.Contact,
+		"training":    s.Profile.Training,
+	}
+}
+
+// Record 在 reports 表登记编号（未签发编号时跳过）。
+func (s Stamp) Record(ctx context.Context, store *sqliteadapter.Store, reportID string) error {
+	if s.ReportNo == "" || reportID == "" {
+		return nil
+	}
+	return store.SetReportNo(ctx, reportID, s.ReportNo)
+}
+
+func readHead(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	head := make([]byte, n)
+	m, err := io.ReadFull(f, head)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	return head[:m], nil
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
@@ -0,0 +1,96 @@
+package orgprofile
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestSaveAndIssue(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+
+	// 未配置：不签发编号，文件名不变。
+	st, err := Issue(ctx, store)
+	if err != nil || st.ReportNo != "" || st.FileName("a.pdf") != "a.pdf" {
+		t.Fatalf("unconfigured stamp=%+v err=%v", st, err)
+	}
+
+	if _, err := Save(ctx, store, model.OrgProfile{AgencyName: "x", ReportPrefix: "bad prefix"}, "op"); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("bad prefix err=%v", err)
+	}
+	notImage := filepath.Join(dir, "logo.png")
+	if err := os.WriteFile(notImage, []byte("not an image"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Save(ctx, store, model.OrgProfile{AgencyName: "x", LogoPath: notImage}, "op"); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("bad logo err=%v", err)
+	}
+
+	p, err := Save(ctx, store, model.OrgProfile{AgencyName: " 某市公安局 ", Unit: "网安支队", Contact: "010-1234", ReportPrefix: "GA-"}, "op")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.AgencyName != "某市公安局" || p.UpdatedBy != "op" || p.ReportSeq != 0 {
+		t.Fatalf("saved=%+v", p)
+	}
+
+	first, err := Issue(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Issue(ctx, store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ReportNo != "GA-000001" || second.ReportNo != "GA-000002" {
+		t.Fatalf("report numbers %q %q", first.ReportNo, second.ReportNo)
+	}
+	if first.FileName("case_forensic_1.pdf") != "GA-000001_case_forensic_1.pdf" || first.Header() != "某市公安局 / 网安支队" || first.Footer() != "010-1234" {
+		t.Fatalf("stamp=%+v", first)
+	}
+
+	// 修改单位信息不回退流水号。
+	if _, err := Save(ctx, store, model.OrgProfile{AgencyName: "某市公安局", ReportPrefix: "GA-"}, "op2"); err != nil {
+		t.Fatal(err)
+	}
+	third, err := Issue(ctx, store)
+	if err != nil || third.ReportNo != "GA-000003" {
+		t.Fatalf("third=%+v err=%v", third, err)
+	}
+
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportID, err := store.SaveReport(ctx, caseID, "forensic_pdf", filepath.Join(dir, "r.pdf"), strings.Repeat("a", 64), "test", "ready")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := third.Record(ctx, store, reportID); err != nil {
+		t.Fatal(err)
+	}
+	info, err := store.GetReportByID(ctx, reportID)
+	if err != nil || info == nil || info.ReportNo != "GA-000003" {
+		t.Fatalf("report=%+v err=%v", info, err)
+	}
+}
@@ -0,0 +1,277 @@
+package phishcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/id"
+	"crypto-inspector/internal/platform/idn"
+)
+
+// 交易所钓鱼登录页检测
+//
+// 诈骗案件受害人的设备上常见的是仿冒交易所站点，而不是官方站点。匹配阶段已把形近/拼写仿冒的域名
+// 记为 exchange_visited（match_mode 为 homoglyph_domain / typosquat_domain，detail 带 lookalike_of）；
+// 这里对这些域名做二次确认：
+//   - 读取站点 TLS 证书，证书 SAN 覆盖交易所官方域名或 subject O 与规则 cert_orgs 一致时视为交易所自有域名
+//   - 否则输出 phishing_suspected 命中，detail 保存域名比对（形近骨架、编辑距离）与证书比对证据
+//   - 站点已无法连接（钓鱼站点通常很快下线）时同样输出，但降低置信度并记录 cert_status=unavailable
+// 检测需要访问网络，只在调用方显式触发时执行；已检测过的来源命中不会重复输出。
+
+// 证书比对结果。
+const (
+	CertMatched     = "matched"     // 证书属于交易所（不输出命中）
+	CertMismatch    = "mismatch"    // 证书不属于交易所
+	CertUnavailable = "unavailable" // 无法取得证书（站点下线、无 443 端口等）
+)
+
+// 命中置信度：证书明确不符时为中高可信；取不到证书时只有域名证据，为中可信。
+const (
+	confidenceMismatch    = 0.85
+	confidenceUnavailable = 0.60
+)
+
+// Options 控制检测参数与审计。
+type Options struct {
+	Exchange model.ExchangeRuleBundle // 生效的交易所规则（官方域名与 cert_orgs）
+	Operator string
+	Source   string // 审计 source（调用方函数名）
+
+	// Fetch 为证书获取函数（nil 使用 FetchCert）。
+	Fetch CertFetcher
+	// Timeout 为单个域名的证书获取超时（默认 15s）。
+	Timeout time.Duration
+}
+
+// Check 是单个来源命中的检测结果。
+type Check struct {
+	SourceHitID   string    `json:"source_hit_id"`
+	DeviceID      string    `json:"device_id"`
+	ExchangeID    string    `json:"exchange_id"`
+	Domain        string    `json:"domain"`
+	LookalikeOf   string    `json:"lookalike_of"`
+	CertStatus    string    `json:"cert_status"`
+	MatchedBy     string    `json:"matched_by,omitempty"` // 证书属于交易所时的依据（san:... / organization:...）
+	Cert          *CertInfo `json:"cert,omitempty"`
+	CertError     string    `json:"cert_error,omitempty"`
+	PhishingHitID string    `json:"phishing_hit_id,omitempty"`
+	Skipped       string    `json:"skipped,omitempty"` // 跳过原因（已检测过等）
+}
+
+// Result 是一次案件检测的汇总。
+type Result struct {
+	CaseID  string  `json:"case_id"`
+	Checked int     `json:"checked"`
+	Flagged int     `json:"flagged"`
+	Checks  []Check `json:"checks"`
+}
+
+type visitDetail struct {
+	MatchMode    string `json:"match_mode"`
+	URL          string `json:"url"`
+	Browser      string `json:"browser"`
+	LookalikeOf  string `json:"lookalike_of"`
+	EditDistance int    `json:"edit_distance"`
+}
+
+// Run 检测案件中形近/拼写仿冒交易所域名的访问，并保存 phishing_suspected 命中。
+// 输出的命中沿用来源命中的设备、交易所规则 ID 与关联证据，便于与 exchange_visited 对照。
+func Run(ctx context.Context, store *sqliteadapter.Store, caseID string, opts Options) (*Result, error) {
+	fetch := opts.Fetch
+	if fetch == nil {
+		fetch = FetchCert
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	operator := strings.TrimSpace(opts.Operator)
+	if operator == "" {
+		operator = "system"
+	}
+
+	visits, err := store.ListCaseHitDetails(ctx, caseID, string(model.HitExchangeVisited))
+	if err != nil {
+		return nil, err
+	}
+	existing, err := store.ListCaseHitDetails(ctx, caseID, string(model.HitPhishingSuspected))
+	if err != nil {
+		return nil, err
+	}
+	done := map[string]struct{}{}
+	for _, h := range existing {
+		var d struct {
+			SourceHitID string `json:"source_hit_id"`
+		}
+		if json.Unmarshal([]byte(h.DetailJSON), &d) == nil && d.SourceHitID != "" {
+			done[d.SourceHitID] = struct{}{}
+		}
+	}
+	exchanges := map[string]model.ExchangeDomain{}
+	for _, exr := range opts.Exchange.Exchanges {
+		exchanges[exr.ID] = exr
+	}
+
+	out := &Result{CaseID: caseID, Checks: []Check{}}
+	type fetched struct {
+		info *CertInfo
+		err  string
+	}
+	certs := map[string]fetched{} // 同一主机在多台设备上出现时只取一次证书
+	var hits []model.RuleHit
+	now := time.Now().Unix()
+	for _, h := range visits {
+		var d visitDetail
+		if err := json.Unmarshal([]byte(h.DetailJSON), &d); err != nil || strings.TrimSpace(d.LookalikeOf) == "" {
+			continue
+		}
+		c := Check{SourceHitID: h.HitID, DeviceID: h.DeviceID, ExchangeID: h.RuleID, Domain: h.MatchedValue, LookalikeOf: d.LookalikeOf}
+		if _, ok := done[h.HitID]; ok {
+			c.Skipped = "already_checked"
+			out.Checks = append(out.Checks, c)
+			continue
+		}
+		out.Checked++
+
+		exr := exchanges[h.RuleID]
+		official := officialDomains(exr, d.LookalikeOf)
+		host := dialHost(h.MatchedValue, d.URL)
+		f, ok := certs[host]
+		if !ok {
+			if host == "" {
+				f.err = "no ascii host name to connect"
+			} else {
+				fctx, cancel := context.WithTimeout(ctx, timeout)
+				info, err := fetch(fctx, host)
+				cancel()
+				f.info = info
+				if err != nil {
+					f.err = err.Error()
+				}
+			}
+			certs[host] = f
+		}
+		c.Cert, c.CertError = f.info, f.err
+
+		confidence := confidenceMismatch
+		if f.info == nil {
+			c.CertStatus = CertUnavailable
+			confidence = confidenceUnavailable
+		} else if by, ok := certMatches(f.info, official, exr.CertOrgs); ok {
+			c.CertStatus = CertMatched
+			c.MatchedBy = by
+			out.Checks = append(out.Checks, c)
+			continue
+		} else {
+			c.CertStatus = CertMismatch
+		}
+
+		editDistance := d.EditDistance
+		if editDistance == 0 {
+			editDistance = idn.EditDistance(idn.Skeleton(h.MatchedValue), idn.Skeleton(d.LookalikeOf))
+		}
+		detail := map[string]any{
+			"source_hit_id":      h.HitID,
+			"source_match_mode":  d.MatchMode,
+			"url":                d.URL,
+			"browser":            d.Browser,
+			"domain":             h.MatchedValue,
+			"lookalike_of":       d.LookalikeOf,
+			"skeleton":           idn.Skeleton(h.MatchedValue),
+			"official_skeleton":  idn.Skeleton(d.LookalikeOf),
+			"edit_distance":      editDistance,
+			"expected_domains":   official,
+			"expected_cert_orgs": exr.CertOrgs,
+			"cert_status":        c.CertStatus,
+			"checked_at":         now,
+		}
+		if idn.IsIDN(h.MatchedValue) {
+			detail["idn"] = true
+		}
+		if c.Cert != nil {
+			detail["cert"] = c.Cert
+		}
+		if c.CertError != "" {
+			detail["cert_error"] = c.CertError
+		}
+		raw, _ := json.Marshal(detail)
+		c.PhishingHitID = id.New("hit")
+		hits = append(hits, model.RuleHit{
+			ID:           c.PhishingHitID,
+			CaseID:       caseID,
+			DeviceID:     h.DeviceID,
+			Type:         model.HitPhishingSuspected,
+			RuleID:       h.RuleID,
+			RuleName:     h.RuleName,
+			RuleVersion:  h.RuleVersion,
+			MatchedValue: h.MatchedValue,
+			FirstSeenAt:  h.FirstSeenAt,
+			LastSeenAt:   h.LastSeenAt,
+			Confidence:   confidence,
+			Verdict:      "suspected",
+			DetailJSON:   raw,
+			ArtifactIDs:  h.ArtifactIDs,
+		})
+		out.Flagged++
+		out.Checks = append(out.Checks, c)
+	}
+
+	if err := store.SaveRuleHits(ctx, hits); err != nil {
+		_ = store.AppendAudit(ctx, caseID, "", "phishing", "check", "failed", operator, opts.Source, map[string]any{"error": err.Error()})
+		return nil, err
+	}
+	status := "success"
+	if out.Checked == 0 {
+		status = "skipped"
+	}
+	_ = store.AppendAudit(ctx, caseID, "", "phishing", "check", status, operator, opts.Source, map[string]any{
+		"checked": out.Checked,
+		"flagged": out.Flagged,
+		"checks":  out.Checks,
+	})
+	return out, nil
+}
+
+// officialDomains 返回交易所规则中的官方域名（去掉 www.，规则缺失时退化为 lookalike_of）。
+func officialDomains(exr model.ExchangeDomain, lookalikeOf string) []string {
+	seen := map[string]struct{}{}
+	var out []string
+	for _, d := range append(append([]string{}, exr.Domains...), lookalikeOf) {
+		d = strings.TrimPrefix(idn.ToUnicode(d), "www.")
+		if d == "" {
+			continue
+		}
+		if _, ok := seen[d]; ok {
+			continue
+		}
+		seen[d] = struct{}{}
+		out = append(out, d)
+	}
+	return out
+}
+
+// dialHost 返回用于 TLS 连接的 ASCII 主机名：优先取访问 URL 中的主机（浏览器历史里 IDN 以 punycode 保存），
+// 其次为命中域名本身（仅 ASCII 时可用）。
+func dialHost(domain, rawURL string) string {
+	if u, err := url.Parse(strings.TrimSpace(rawURL)); err == nil && isASCII(u.Hostname()) && u.Hostname() != "" {
+		return strings.ToLower(u.Hostname())
+	}
+	if isASCII(domain) {
+		return strings.ToLower(domain)
+	}
+	return ""
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
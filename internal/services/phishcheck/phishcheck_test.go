@@ -0,0 +1,124 @@
+package phishcheck
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestRunFlagsLookalikeWithForeignCert(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "host", OS: model.OSType("windows"), Identifier: "h"}, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	visit := func(hitID, ruleID, domain, detail string) model.RuleHit {
+		return model.RuleHit{
+			ID: hitID, CaseID: caseID, DeviceID: "dev_1", Type: model.HitExchangeVisited, RuleID: ruleID, RuleName: ruleID,
+			MatchedValue: domain, Confidence: 0.6, Verdict: "suspected", FirstSeenAt: 100, LastSeenAt: 200, DetailJSON: []byte(detail),
+		}
+	}
+	if err := store.SaveRuleHits(ctx, []model.RuleHit{
+		visit("hit_typo", "binance", "binnance.com", `{"match_mode":"typosquat_domain","url":"https://binnance.com/en/login","lookalike_of":"binance.com","edit_distance":1}`),
+		visit("hit_own", "binance", "blnance.com", `{"match_mode":"homoglyph_domain","url":"https://blnance.com/","lookalike_of":"binance.com"}`),
+		visit("hit_down", "coinbase", "coinbasse.com", `{"match_mode":"typosquat_domain","url":"https://coinbasse.com/","lookalike_of":"coinbase.com","edit_distance":1}`),
+		visit("hit_official", "binance", "binance.com", `{"match_mode":"exact_domain","url":"https://www.binance.com/"}`),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var dialed []string
+	fetch := func(_ context.Context, host string) (*CertInfo, error) {
+		dialed = append(dialed, host)
+		switch host {
+		case "binnance.com":
+			return &CertInfo{Host: host, SubjectCN: host, DNSNames: []string{host}, Issuer: "CN=R3"}, nil
+		case "blnance.com":
+			return &CertInfo{Host: host, SubjectCN: "binance.com", DNSNames: []string{"blnance.com", "*.binance.com"}}, nil
+		default:
+			return nil, errors.New("connection refused")
+		}
+	}
+	opts := Options{
+		Exchange: model.ExchangeRuleBundle{Exchanges: []model.ExchangeDomain{
+			{ID: "binance", Domains: []string{"binance.com", "www.binance.com", "accounts.binance.com"}},
+			{ID: "coinbase", Domains: []string{"coinbase.com"}, CertOrgs: []string{"Coinbase, Inc."}},
+		}},
+		Operator: "alice",
+		Source:   "test",
+		Fetch:    fetch,
+	}
+	res, err := Run(ctx, store, caseID, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Checked != 3 || res.Flagged != 2 || len(dialed) != 3 {
+		t.Fatalf("result=%+v dialed=%v", res, dialed)
+	}
+
+	hits, err := store.ListCaseHitDetails(ctx, caseID, string(model.HitPhishingSuspected))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("phishing hits=%+v", hits)
+	}
+	byValue := map[string]model.HitDetail{}
+	for _, h := range hits {
+		byValue[h.MatchedValue] = h
+	}
+	typo := byValue["binnance.com"]
+	var d map[string]any
+	if err := json.Unmarshal([]byte(typo.DetailJSON), &d); err != nil {
+		t.Fatal(err)
+	}
+	if typo.Confidence != confidenceMismatch || typo.RuleID != "binance" || d["cert_status"] != CertMismatch || d["source_hit_id"] != "hit_typo" || d["edit_distance"] != float64(1) {
+		t.Fatalf("typosquat hit=%+v detail=%v", typo, d)
+	}
+	if down := byValue["coinbasse.com"]; down.Confidence != confidenceUnavailable || down.Verdict != "suspected" {
+		t.Fatalf("unavailable hit=%+v", down)
+	}
+
+	// 重复执行：已输出的来源命中不再检测；证书属于交易所的域名每次都会重新确认。
+	res, err = Run(ctx, store, caseID, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Checked != 1 || res.Flagged != 0 {
+		t.Fatalf("second run=%+v", res)
+	}
+}
+
+func TestCertMatches(t *testing.T) {
+	official := []string{"binance.com", "accounts.binance.com"}
+	if _, ok := certMatches(&CertInfo{DNSNames: []string{"*.binance.com"}}, official, nil); !ok {
+		t.Fatal("wildcard SAN should cover accounts.binance.com")
+	}
+	if _, ok := certMatches(&CertInfo{DNSNames: []string{"*.binance.com.evil.io"}}, official, nil); ok {
+		t.Fatal("foreign wildcard must not match")
+	}
+	if by, ok := certMatches(&CertInfo{Organizations: []string{"Binance Holdings Ltd"}, DNSNames: []string{"blnance.com"}}, official, []string{"binance holdings ltd"}); !ok || by != "organization:Binance Holdings Ltd" {
+		t.Fatalf("organization match by=%q ok=%v", by, ok)
+	}
+}
@@ -0,0 +1,327 @@
+package precheckpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 前置检查策略
+//
+// 策略按扫描 profile（host_scan / offline_scan / mobile_scan）声明哪些检查码是必需的、失败后如何处理：
+// - block：检查失败（或必需检查被跳过/未执行）时中止扫描
+// - warn：检查失败或被跳过时只写入扫描告警，不中止
+// 策略只能收紧要求：代码中原本必需的检查（例如证据目录可写）不会被 warn 放宽。
+// 生效策略以 YAML 文本存放在 schema_meta（key 见 SchemaKeyPolicy），未配置时沿用代码内置行为。
+//
+// authorization_order_formats 按签发单位配置授权工单号格式（正则），agency 为 "*" 时作为默认格式；
+// 签发单位取案件最近上传的授权文书登记的 agency（见 services/authdoc）。
+
+// SchemaKeyPolicy 是 schema_meta 中保存生效策略 YAML 的 key。
+const SchemaKeyPolicy = "precheck_policy_yaml"
+
+// BundleType 是策略文件的 bundle_type。
+const BundleType = "precheck_policy"
+
+// 失败处理方式。
+const (
+	OnFailBlock = "block"
+	OnFailWarn  = "warn"
+)
+
+// 扫描 profile（与审计 event_type 一致）。
+const (
+	ProfileHostScan    = "host_scan"
+	ProfileOfflineScan = "offline_scan"
+	ProfileMobileScan  = "mobile_scan"
+)
+
+var knownProfiles = map[string]bool{
+	ProfileHostScan:    true,
+	ProfileOfflineScan: true,
+	ProfileMobileScan:  true,
+}
+
+// CheckRule 是单个检查码的策略。
+type CheckRule struct {
+	Code   string `yaml:"code" json:"code"`
+	OnFail string `yaml:"on_fail" json:"on_fail"`
+	Note   string `yaml:"note,omitempty" json:"note,omitempty"`
+}
+
+// Profile 是一个扫描 profile 的检查策略集合。
+type Profile struct {
+	Name   string      `yaml:"name" json:"name"`
+	Checks []CheckRule `yaml:"checks" json:"checks"`
+}
+
+// OrderFormat 是某签发单位的授权工单号格式。
+type OrderFormat struct {
+	Agency  string `yaml:"agency" json:"agency"`
+	Pattern string `yaml:"pattern" json:"pattern"`
+	Note    string `yaml:"note,omitempty" json:"note,omitempty"`
+}
+
+// DefaultAgency 是未单独配置格式的签发单位使用的 agency 名。
+const DefaultAgency = "*"
+
+// Policy 是完整的前置检查策略。
+type Policy struct {
+	Version      string        `yaml:"version" json:"version"`
+	BundleType   string        `yaml:"bundle_type" json:"bundle_type"`
+	Profiles     []Profile     `yaml:"profiles" json:"profiles"`
+	OrderFormats []OrderFormat `yaml:"authorization_order_formats,omitempty" json:"authorization_order_formats,omitempty"`
+}
+
+// Parse 解析并校验策略 YAML。
+func Parse(raw []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("parse precheck policy: %w", err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ParseFile 读取并解析策略文件。
+func ParseFile(path string) (*Policy, []byte, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read precheck policy: %w", err)
+	}
+	p, err := Parse(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, raw, nil
+}
+
+// Validate 校验 bundle_type、profile 名称、检查码与 on_fail 取值。
+func (p *Policy) Validate() error {
+	if strings.TrimSpace(p.BundleType) != BundleType {
+		return fmt.Errorf("precheck policy bundle_type must be %q", BundleType)
+	}
+	seenProfile := map[string]bool{}
+	for i, prof := range p.Profiles {
+		name := strings.TrimSpace(prof.Name)
+		if !knownProfiles[name] {
+			return fmt.Errorf("profiles[%d]: unknown profile %q (host_scan|offline_scan|mobile_scan)", i, prof.Name)
+		}
+		if seenProfile[name] {
+			return fmt.Errorf("profiles[%d]: duplicate profile %q", i, name)
+		}
+		seenProfile[name] = true
+		seenCode := map[string]bool{}
+		for j, c := range prof.Checks {
+			code := strings.TrimSpace(c.Code)
+			if code == "" {
+				return fmt.Errorf("profiles[%d].checks[%d]: code is required", i, j)
+			}
+			if seenCode[code] {
+				return fmt.Errorf("profiles[%d].checks[%d]: duplicate code %q", i, j, code)
+			}
+			seenCode[code] = true
+			if c.OnFail != OnFailBlock && c.OnFail != OnFailWarn {
+				return fmt.Errorf("profiles[%d].checks[%d]: on_fail must be block|warn", i, j)
+			}
+		}
+	}
+	seenAgency := map[string]bool{}
+	for i, f := range p.OrderFormats {
+		agency := strings.TrimSpace(f.Agency)
+		if agency == "" {
+			return fmt.Errorf("authorization_order_formats[%d]: agency is required (use %q for the default)", i, DefaultAgency)
+		}
+		if seenAgency[agency] {
+			return fmt.Errorf("authorization_order_formats[%d]: duplicate agency %q", i, agency)
+		}
+		seenAgency[agency] = true
+		if _, err := regexp.Compile(f.Pattern); err != nil || strings.TrimSpace(f.Pattern) == "" {
+			return fmt.Errorf("authorization_order_formats[%d]: invalid pattern %q", i, f.Pattern)
+		}
+	}
+	return nil
+}
+
+// Configured 表示策略是否有任何生效内容（否则沿用内置行为）。
+func (p *Policy) Configured() bool {
+	return p != nil && (len(p.Profiles) > 0 || len(p.OrderFormats) > 0)
+}
+
+// OrderFormatFor 返回签发单位适用的工单号格式（无单独配置时回退到 "*"）。
+func (p *Policy) OrderFormatFor(agency string) (OrderFormat, bool) {
+	if p == nil {
+		return OrderFormat{}, false
+	}
+	agency = strings.TrimSpace(agency)
+	var fallback *OrderFormat
+	for i, f := range p.OrderFormats {
+		switch strings.TrimSpace(f.Agency) {
+		case agency:
+			if agency != "" {
+				return f, true
+			}
+		case DefaultAgency:
+			fallback = &p.OrderFormats[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, true
+	}
+	return OrderFormat{}, false
+}
+
+// ValidateOrder 按签发单位的格式校验工单号；未配置格式时不校验。
+func (p *Policy) ValidateOrder(agency, order string) error {
+	f, ok := p.OrderFormatFor(agency)
+	if !ok {
+		return nil
+	}
+	re, err := regexp.Compile(f.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid authorization order pattern %q: %w", f.Pattern, err)
+	}
+	if !re.MatchString(strings.TrimSpace(order)) {
+		return fmt.Errorf("authorization order %q does not match the format for agency %q (%s)", order, f.Agency, f.Pattern)
+	}
+	return nil
+}
+
+// Load 读取生效策略；未配置时返回空策略（沿用代码内置行为）。
+func Load(ctx context.Context, store *sqliteadapter.Store) (*Policy, error) {
+	raw, err := store.GetSchemaMetaValue(ctx, SchemaKeyPolicy)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(raw) == "" {
+		return &Policy{BundleType: BundleType}, nil
+	}
+	return Parse([]byte(raw))
+}
+
+// Save 校验并保存策略 YAML 为生效策略。
+func Save(ctx context.Context, store *sqliteadapter.Store, raw []byte) (*Policy, error) {
+	p, err := Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.UpsertSchemaMetaValue(ctx, SchemaKeyPolicy, string(raw)); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reset 清除生效策略，恢复代码内置行为。
+func Reset(ctx context.Context, store *sqliteadapter.Store) error {
+	return store.UpsertSchemaMetaValue(ctx, SchemaKeyPolicy, "")
+}
+
+// Rule 返回 profile 下某个检查码的策略。
+func (p *Policy) Rule(profile, code string) (CheckRule, bool) {
+	if p == nil {
+		return CheckRule{}, false
+	}
+	for _, prof := range p.Profiles {
+		if strings.TrimSpace(prof.Name) != profile {
+			continue
+		}
+		for _, c := range prof.Checks {
+			if strings.TrimSpace(c.Code) == code {
+				return c, true
+			}
+		}
+	}
+	return CheckRule{}, false
+}
+
+// Enforce 按策略判定一条前置检查结果（会就地更新 Required/Status/Message）。
+//
+// 策略叠加在代码内置判定之上：代码中已必需的检查仍由调用方原有逻辑中止；
+// 这里只在策略要求 block 且检查失败（或被跳过）时返回 block=true，策略为 warn 且检查失败或被跳过时返回告警。
+func (p *Policy) Enforce(profile string, c *model.PrecheckResult) (block bool, warning string) {
+	rule, ok := p.Rule(profile, c.CheckCode)
+	if !ok {
+		return false, ""
+	}
+	switch rule.OnFail {
+	case OnFailBlock:
+		c.Required = true
+		if c.Status == model.PrecheckSkipped {
+			c.Status = model.PrecheckFailed
+			c.Message = "required by precheck policy: " + c.Message
+		}
+		return c.Status == model.PrecheckFailed, ""
+	case OnFailWarn:
+		if c.Status != model.PrecheckPassed && !c.Required {
+			return false, fmt.Sprintf("precheck %s %s (policy warn): %s", c.CheckCode, c.Status, c.Message)
+		}
+	}
+	return false, ""
+}
+
+// Gate 依次判定一组检查结果：返回第一条被策略中止的检查（nil 表示放行）与告警列表。
+func (p *Policy) Gate(profile string, checks []model.PrecheckResult) (blocked *model.PrecheckResult, warnings []string) {
+	for i := range checks {
+		block, warning := p.Enforce(profile, &checks[i])
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+		if block && blocked == nil {
+			blocked = &checks[i]
+		}
+	}
+	return blocked, warnings
+}
+
+// BlockedError 把被策略中止的检查转换为带错误码的错误。
+func BlockedError(profile string, c *model.PrecheckResult) error {
+	return apperr.T(apperr.CodePrecheckPolicy, "precheck.policy_missing", profile, c.CheckCode, c.Message)
+}
+
+// Missing 返回 profile 中 on_fail=block、但本次扫描未执行的检查（以 failed 结果表示，可直接落库）。
+func (p *Policy) Missing(profile, caseID string, results []model.PrecheckResult) []model.PrecheckResult {
+	if p == nil {
+		return nil
+	}
+	done := map[string]bool{}
+	for _, r := range results {
+		done[r.CheckCode] = true
+	}
+	var out []model.PrecheckResult
+	for _, prof := range p.Profiles {
+		if strings.TrimSpace(prof.Name) != profile {
+			continue
+		}
+		for _, c := range prof.Checks {
+			code := strings.TrimSpace(c.Code)
+			if c.OnFail != OnFailBlock || done[code] {
+				continue
+			}
+			detail, _ := json.Marshal(map[string]any{"profile": profile, "note": c.Note})
+			out = append(out, model.PrecheckResult{
+				CaseID:     caseID,
+				ScanScope:  "general",
+				CheckCode:  code,
+				CheckName:  "策略要求的检查未执行",
+				Required:   true,
+				Status:     model.PrecheckFailed,
+				Message:    "required by precheck policy but not performed",
+				DetailJSON: detail,
+				CheckedAt:  time.Now().Unix(),
+			})
+		}
+	}
+	return out
+}
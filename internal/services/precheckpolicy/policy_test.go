@@ -0,0 +1,77 @@
+package precheckpolicy
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestTemplateAndEnforce(t *testing.T) {
+	policy, _, err := ParseFile(filepath.Join("..", "..", "..", "rules", "precheck_policy.template.yaml"))
+	if err != nil {
+		t.Fatalf("template: %v", err)
+	}
+
+	// 未提供授权工单（代码内置为 skipped、非必需）：策略 block 后变为 failed 并中止。
+	checks := []model.PrecheckResult{
+		{CheckCode: "authorization_order", Status: model.PrecheckSkipped, Message: "not provided"},
+		{CheckCode: "privacy_mode_reserved", Status: model.PrecheckPassed},
+	}
+	blocked, warnings := policy.Gate(ProfileHostScan, checks)
+	if blocked == nil || blocked.CheckCode != "authorization_order" || !checks[0].Required || checks[0].Status != model.PrecheckFailed || len(warnings) != 0 {
+		t.Fatalf("blocked=%+v checks=%+v warnings=%v", blocked, checks, warnings)
+	}
+	if err := BlockedError(ProfileHostScan, blocked); apperr.CodeOf(err) != apperr.CodePrecheckPolicy {
+		t.Fatalf("err=%v", err)
+	}
+
+	// warn：工具缺失只告警；未列入策略的检查保持原判定。
+	checks = []model.PrecheckResult{
+		{CheckCode: "android_adb_available", Status: model.PrecheckSkipped, Message: "adb not found"},
+		{CheckCode: "ios_idevice_id_available", Status: model.PrecheckSkipped, Message: "idevice_id not found"},
+	}
+	blocked, warnings = policy.Gate(ProfileMobileScan, checks)
+	if blocked != nil || len(warnings) != 1 || !strings.Contains(warnings[0], "android_adb_available") || checks[0].Required {
+		t.Fatalf("blocked=%+v warnings=%v", blocked, warnings)
+	}
+
+	// block 但本次未执行的检查补为 failed。
+	missing := policy.Missing(ProfileOfflineScan, "case_1", []model.PrecheckResult{{CheckCode: "authorization_order"}})
+	if len(missing) != 1 || missing[0].CheckCode != "offline_source_hashed" || missing[0].Status != model.PrecheckFailed || !missing[0].Required {
+		t.Fatalf("missing=%+v", missing)
+	}
+
+	// 空策略不改变任何判定。
+	empty := &Policy{BundleType: BundleType}
+	if blocked, _ := empty.Gate(ProfileHostScan, []model.PrecheckResult{{CheckCode: "authorization_order", Status: model.PrecheckSkipped}}); blocked != nil {
+		t.Fatalf("empty policy blocked %+v", blocked)
+	}
+
+	// 工单号格式：按签发单位选择，未配置的单位使用 "*"。
+	if err := policy.ValidateOrder("示例市公安局网安支队", "网安2024-000123"); err != nil {
+		t.Fatalf("valid order rejected: %v", err)
+	}
+	if err := policy.ValidateOrder("示例市公安局网安支队", "TICKET-1"); err == nil {
+		t.Fatalf("invalid order accepted")
+	}
+	if err := policy.ValidateOrder("", "a b"); err == nil {
+		t.Fatalf("default format not applied")
+	}
+	if err := empty.ValidateOrder("", "a b"); err != nil {
+		t.Fatalf("empty policy validated order: %v", err)
+	}
+
+	for _, bad := range []string{
+		"bundle_type: wallet_signatures\n",
+		"bundle_type: precheck_policy\nprofiles:\n  - name: desktop\n",
+		"bundle_type: precheck_policy\nprofiles:\n  - name: host_scan\n    checks:\n      - code: authorization_order\n        on_fail: stop\n",
+		"bundle_type: precheck_policy\nauthorization_order_formats:\n  - agency: x\n    pattern: '(['\n",
+	} {
+		if _, err := Parse([]byte(bad)); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
@@ -45,12 +45,12 @@ func MaskRuleHitsForReport(hits []model.RuleHit) []model.RuleHit {
 		case model.HitWalletAddress:
 			hh.MatchedValue = MaskAddress(hh.MatchedValue)
 			hh.DetailJSON = maskDetailJSONForWalletAddress(hh.DetailJSON)
-		case model.HitTokenBalance:
+		case model.HitTokenBalance, model.HitNFTHoldings:
 			hh.MatchedValue = maskTokenBalanceMatchedValue(hh.MatchedValue)
 			hh.DetailJSON = maskDetailJSONForTokenBalance(hh.DetailJSON)
-		case model.HitExchangeVisited:
+		case model.HitExchangeVisited, model.HitExchangeFormActivity, model.HitPhishingSuspected:
 			hh.DetailJSON = maskDetailJSONForExchangeVisited(hh.DetailJSON)
-		case model.HitWalletInstalled:
+		case model.HitWalletInstalled, model.HitWalletExecuted, model.HitWalletSuspectedUnknown, model.HitExchangeAppInstalled, model.HitMessengerCommunity:
 			hh.DetailJSON = maskDetailJSONForWalletInstalled(hh.DetailJSON)
 		default:
 			// 其他类型：保持原样
@@ -87,8 +87,10 @@ func maskDetailJSONForExchangeVisited(raw []byte) []byte {
 	if err := json.Unmarshal(raw, &m); err != nil {
 		return raw
 	}
-	if v, ok := m["url"].(string); ok {
-		m["url"] = MaskURL(v)
+	for _, k := range []string{"url", "referrer_url", "origin", "action_url"} {
+		if v, ok := m[k].(string); ok {
+			m[k] = MaskURL(v)
+		}
 	}
 	out, err := json.Marshal(m)
 	if err != nil {
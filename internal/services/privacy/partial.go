@@ -0,0 +1,209 @@
+package privacy
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/correlation"
+	"crypto-inspector/internal/services/phishcheck"
+)
+
+// 隐私模式
+//
+//   - off：不脱敏
+//   - masked：扫描报告本身脱敏（见 MaskRuleHitsForReport）
+//   - partial：仅 Web UI 层脱敏——库内与报告保留完整数据，界面默认展示脱敏后的 URL/地址；
+//     导出、下载原件等操作需持有 unmask 权限（按操作员发放的令牌），每次解除脱敏都写审计
+//
+// partial 用于有外部人员旁观的演示/会商场景，避免受害人浏览记录直接出现在屏幕上。
+const (
+	ModeOff     = "off"
+	ModeMasked  = "masked"
+	ModePartial = "partial"
+)
+
+// NormalizeMode 规范化隐私模式（空值视为 off）。
+func NormalizeMode(mode string) (string, error) {
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case "", ModeOff:
+		return ModeOff, nil
+	case ModeMasked, ModePartial:
+		return m, nil
+	default:
+		return "", fmt.Errorf("invalid privacy mode: %s (expect off|masked|partial)", mode)
+	}
+}
+
+// ScanMode 返回扫描任务使用的报告脱敏模式：partial 只影响界面展示，扫描报告保留完整数据。
+func ScanMode(mode string) string {
+	if m, err := NormalizeMode(mode); err == nil && m == ModeMasked {
+		return ModeMasked
+	}
+	return ModeOff
+}
+
+// Grants 是 unmask 权限表（操作员 -> 令牌）。
+type Grants map[string]string
+
+// ParseGrants 解析 "operator:token,operator2:token2" 形式的权限配置（通常来自环境变量，避免令牌出现在命令行/进程列表中）。
+func ParseGrants(spec string) (Grants, error) {
+	out := Grants{}
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		operator, token, ok := strings.Cut(item, ":")
+		operator, token = strings.TrimSpace(operator), strings.TrimSpace(token)
+		if !ok || operator == "" || token == "" {
+			return nil, fmt.Errorf("invalid unmask grant %q (expect operator:token)", item)
+		}
+		if len(token) < 12 {
+			return nil, fmt.Errorf("unmask token for %s is too short (min 12 chars)", operator)
+		}
+		out[operator] = token
+	}
+	return out, nil
+}
+
+// Operator 按令牌查找持有 unmask 权限的操作员（常量时间比较）。
+func (g Grants) Operator(token string) (string, bool) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", false
+	}
+	found := ""
+	for operator, t := range g {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			found = operator
+		}
+	}
+	return found, found != ""
+}
+
+// MaskHitDetails 对界面展示的命中明细脱敏（地址部分展示、URL 仅保留域名、路径仅保留文件名）。
+func MaskHitDetails(hits []model.HitDetail) []model.HitDetail {
+	out := make([]model.HitDetail, 0, len(hits))
+	for _, h := range hits {
+		masked := MaskRuleHitsForReport([]model.RuleHit{{
+			Type:         model.HitType(h.HitType),
+			MatchedValue: h.MatchedValue,
+			DetailJSON:   []byte(h.DetailJSON),
+		}})[0]
+		h.MatchedValue = masked.MatchedValue
+		h.DetailJSON = string(masked.DetailJSON)
+		out = append(out, h)
+	}
+	return out
+}
+
+// MaskHitRollups 对案件级命中汇总脱敏（规则与统计保留，命中值按类型脱敏）。
+func MaskHitRollups(rows []model.HitRollup) []model.HitRollup {
+	out := make([]model.HitRollup, 0, len(rows))
+	for _, r := range rows {
+		r.MatchedValue = maskMatchedValue(r.HitType, r.MatchedValue)
+		out = append(out, r)
+	}
+	return out
+}
+
+// MaskCaseAddresses 对案件地址列表脱敏；detail 可能含来源 URL/上下文，直接省略。
+func MaskCaseAddresses(rows []model.CaseAddress) []model.CaseAddress {
+	out := make([]model.CaseAddress, 0, len(rows))
+	for _, a := range rows {
+		a.Address = MaskAddress(a.Address)
+		a.Detail = nil
+		out = append(out, a)
+	}
+	return out
+}
+
+// MaskAddressClusters 对地址聚类中的地址脱敏。
+func MaskAddressClusters(rows []model.AddressCluster) []model.AddressCluster {
+	out := make([]model.AddressCluster, 0, len(rows))
+	for _, c := range rows {
+		addrs := make([]string, 0, len(c.Addresses))
+		for _, a := range c.Addresses {
+			addrs = append(addrs, MaskAddress(a))
+		}
+		c.Addresses = addrs
+		out = append(out, c)
+	}
+	return out
+}
+
+func maskMatchedValue(hitType, v string) string {
+	switch model.HitType(hitType) {
+	case model.HitWalletAddress:
+		return MaskAddress(v)
+	case model.HitTokenBalance, model.HitNFTHoldings:
+		return maskTokenBalanceMatchedValue(v)
+	default:
+		return v
+	}
+}
+
+// MaskCorrelation 对多设备关联分析脱敏（返回副本）：地址部分展示，交易所访问域名省略（规则 ID/名称保留），
+// 钱包命中值只保留文件名。
+func MaskCorrelation(res *correlation.Result) *correlation.Result {
+	if res == nil {
+		return nil
+	}
+	out := *res
+	maskItems := func(items []correlation.SharedItem, maskValue func(string) string) []correlation.SharedItem {
+		masked := make([]correlation.SharedItem, 0, len(items))
+		for _, it := range items {
+			values := []string{}
+			if maskValue != nil {
+				for _, v := range it.Values {
+					values = append(values, maskValue(v))
+				}
+			}
+			it.Values = values
+			if it.Kind == correlation.KindAddress {
+				it.Key = MaskAddress(it.Key)
+			}
+			masked = append(masked, it)
+		}
+		return masked
+	}
+	out.SharedExchanges = maskItems(res.SharedExchanges, nil)
+	out.SharedWallets = maskItems(res.SharedWallets, MaskSnapshotPath)
+	out.SharedAddresses = maskItems(res.SharedAddresses, MaskAddress)
+	return &out
+}
+
+// MaskPhishingCheck 对仿冒域名证书检测结果脱敏（返回副本）：访问的域名部分展示，证书详情与错误信息省略，
+// matched_by 只保留依据类型（san / organization）。
+func MaskPhishingCheck(res *phishcheck.Result) *phishcheck.Result {
+	if res == nil {
+		return nil
+	}
+	out := *res
+	out.Checks = make([]phishcheck.Check, 0, len(res.Checks))
+	for _, c := range res.Checks {
+		c.Domain = MaskDomain(c.Domain)
+		c.Cert = nil
+		c.CertError = ""
+		if kind, _, ok := strings.Cut(c.MatchedBy, ":"); ok {
+			c.MatchedBy = kind
+		}
+		out.Checks = append(out.Checks, c)
+	}
+	return &out
+}
+
+// MaskDomain 对域名做部分展示：只保留首字符与顶级域（例如 binance-login.com -> b***.com）。
+func MaskDomain(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if host == "" {
+		return ""
+	}
+	i := strings.LastIndex(host, ".")
+	if i <= 0 {
+		return "<masked>"
+	}
+	return host[:1] + "***" + host[i:]
+}
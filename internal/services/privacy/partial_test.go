@@ -0,0 +1,63 @@
+package privacy
+
+import (
+	"strings"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestParseGrants(t *testing.T) {
+	g, err := ParseGrants(" alice:token-aaaaaaaa , bob:token-bbbbbbbb,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op, ok := g.Operator("token-bbbbbbbb"); !ok || op != "bob" {
+		t.Fatalf("operator=%q ok=%v", op, ok)
+	}
+	if _, ok := g.Operator("token-cccccccc"); ok {
+		t.Fatalf("unknown token accepted")
+	}
+	if _, ok := g.Operator(""); ok {
+		t.Fatalf("empty token accepted")
+	}
+	for _, bad := range []string{"alice", "alice:", ":token-aaaaaaaa", "alice:short"} {
+		if _, err := ParseGrants(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestMaskHitDetails(t *testing.T) {
+	addr := "0x000000000000000000000000000000000000dEaD"
+	in := []model.HitDetail{
+		{HitID: "h1", HitType: string(model.HitWalletAddress), MatchedValue: addr},
+		{HitID: "h2", HitType: string(model.HitExchangeVisited), MatchedValue: "binance.com", DetailJSON: `{"url":"https://www.binance.com/en/my/wallet?uid=42"}`},
+	}
+	out := MaskHitDetails(in)
+	if out[0].MatchedValue == addr || !strings.Contains(out[0].MatchedValue, "...") {
+		t.Fatalf("address not masked: %q", out[0].MatchedValue)
+	}
+	if out[1].MatchedValue != "binance.com" || strings.Contains(out[1].DetailJSON, "uid=42") {
+		t.Fatalf("exchange hit=%+v", out[1])
+	}
+	if in[0].MatchedValue != addr {
+		t.Fatalf("input modified")
+	}
+	if m, _ := NormalizeMode(" Partial "); m != ModePartial || ScanMode(ModePartial) != ModeOff {
+		t.Fatalf("mode normalize")
+	}
+}
+
+func TestMaskDomain(t *testing.T) {
+	for in, want := range map[string]string{
+		"blnance-login.com": "b***.com",
+		"WWW.Binance.COM.":  "w***.com",
+		"localhost":         "<masked>",
+		"":                  "",
+	} {
+		if got := MaskDomain(in); got != want {
+			t.Fatalf("MaskDomain(%q)=%q want %q", in, got, want)
+		}
+	}
+}
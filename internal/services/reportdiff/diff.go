@@ -0,0 +1,377 @@
+package reportdiff
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+)
+
+// 案件报告对比
+//
+// 每次扫描都会生成一份 internal_json 报告（报告即“扫描快照”）。初查与复查之间发生了什么，
+// 需要有可留档的结构化说明：
+// - 新增/消失的命中：按 hit_type + rule_id + matched_value 识别（命中 ID 每次扫描都会变化）
+// - 余额变化：token_balance / nft_holdings 在两侧都存在但余额不同
+// - 新增/消失的证据：按 artifact_type + source_ref + sha256 识别（内容变化视为“旧的消失、新的出现”）
+//
+// 余额类命中通常由“链上余额查询”单独写入，不在扫描报告里；这里按报告生成时间
+// 从数据库还原当时已落库的余额结果（同一地址取最近一次）。
+
+// Side 是对比一侧的报告信息。
+type Side struct {
+	ReportID      string `json:"report_id"`
+	FilePath      string `json:"file_path"`
+	SHA256        string `json:"sha256"`
+	GeneratedAt   int64  `json:"generated_at"`
+	PrivacyMode   string `json:"privacy_mode,omitempty"`
+	HitCount      int    `json:"hit_count"`
+	ArtifactCount int    `json:"artifact_count"`
+}
+
+// HitRef 是差异中的一条命中。
+type HitRef struct {
+	HitID        string  `json:"hit_id"`
+	HitType      string  `json:"hit_type"`
+	RuleID       string  `json:"rule_id"`
+	RuleName     string  `json:"rule_name,omitempty"`
+	MatchedValue string  `json:"matched_value"`
+	Confidence   float64 `json:"confidence"`
+	Verdict      string  `json:"verdict"`
+}
+
+// BalanceChange 是一条余额变化（before/after 为余额相关字段）。
+type BalanceChange struct {
+	HitType      string          `json:"hit_type"`
+	RuleID       string          `json:"rule_id"`
+	MatchedValue string          `json:"matched_value"`
+	BeforeHitID  string          `json:"before_hit_id"`
+	AfterHitID   string          `json:"after_hit_id"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+}
+
+// ArtifactRef 是差异中的一条证据。
+type ArtifactRef struct {
+	ArtifactID   string `json:"artifact_id"`
+	ArtifactType string `json:"artifact_type"`
+	SourceRef    string `json:"source_ref"`
+	SHA256       string `json:"sha256"`
+	CollectedAt  int64  `json:"collected_at"`
+}
+
+// Summary 是差异计数。
+type Summary struct {
+	NewHits          int `json:"new_hits"`
+	RemovedHits      int `json:"removed_hits"`
+	ChangedBalances  int `json:"changed_balances"`
+	NewArtifacts     int `json:"new_artifacts"`
+	RemovedArtifacts int `json:"removed_artifacts"`
+}
+
+// Result 是两份报告的结构化差异（A 为基准，B 为对比对象）。
+type Result struct {
+	CaseID           string          `json:"case_id"`
+	A                Side            `json:"a"`
+	B                Side            `json:"b"`
+	Summary          Summary         `json:"summary"`
+	NewHits          []HitRef        `json:"new_hits"`
+	RemovedHits      []HitRef        `json:"removed_hits"`
+	ChangedBalances  []BalanceChange `json:"changed_balances"`
+	NewArtifacts     []ArtifactRef   `json:"new_artifacts"`
+	RemovedArtifacts []ArtifactRef   `json:"removed_artifacts"`
+	Warnings         []string        `json:"warnings"`
+}
+
+// Snapshot 是从报告（及当时的余额结果）还原出的命中与证据集合。
+type Snapshot struct {
+	Side      Side
+	Hits      []model.HitDetail
+	Artifacts []ArtifactRef
+}
+
+// internalReport 是 internal_json 报告中参与对比的字段（主机/手机报告结构一致）。
+type internalReport struct {
+	CaseID      string          `json:"case_id"`
+	PrivacyMode string          `json:"privacy_mode"`
+	GeneratedAt int64           `json:"generated_at"`
+	Artifacts   []ArtifactRef   `json:"artifacts"`
+	Hits        []model.RuleHit `json:"hits"`
+}
+
+// ParseInternalJSON 解析 internal_json 报告内容。
+func ParseInternalJSON(raw []byte) (*Snapshot, error) {
+	var rep internalReport
+	if err := json.Unmarshal(raw, &rep); err != nil {
+		return nil, fmt.Errorf("decode internal_json report: %w", err)
+	}
+	snap := &Snapshot{
+		Side: Side{
+			GeneratedAt: rep.GeneratedAt,
+			PrivacyMode: rep.PrivacyMode,
+		},
+		Hits:      make([]model.HitDetail, 0, len(rep.Hits)),
+		Artifacts: rep.Artifacts,
+	}
+	if snap.Artifacts == nil {
+		snap.Artifacts = []ArtifactRef{}
+	}
+	for _, h := range rep.Hits {
+		snap.Hits = append(snap.Hits, model.HitDetail{
+			HitID:        h.ID,
+			CaseID:       h.CaseID,
+			DeviceID:     h.DeviceID,
+			HitType:      string(h.Type),
+			RuleID:       h.RuleID,
+			RuleName:     h.RuleName,
+			RuleVersion:  h.RuleVersion,
+			MatchedValue: h.MatchedValue,
+			FirstSeenAt:  h.FirstSeenAt,
+			LastSeenAt:   h.LastSeenAt,
+			Confidence:   h.Confidence,
+			Verdict:      h.Verdict,
+			DetailJSON:   string(h.DetailJSON),
+			ArtifactIDs:  h.ArtifactIDs,
+		})
+	}
+	return snap, nil
+}
+
+// Run 对比案件的两份 internal_json 报告。
+// reportA/reportB 都为空时对比最近两份 internal_json 报告（较早的一份为 A）。
+func Run(ctx context.Context, store *sqliteadapter.Store, caseID, reportA, reportB string) (*Result, error) {
+	caseID = strings.TrimSpace(caseID)
+	reportA, reportB = strings.TrimSpace(reportA), strings.TrimSpace(reportB)
+	if caseID == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "case_id is required")
+	}
+	if (reportA == "") != (reportB == "") {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "report_a and report_b must be given together")
+	}
+
+	var infoA, infoB *model.ReportInfo
+	if reportA == "" {
+		rows, err := store.ListReportsByCase(ctx, caseID)
+		if err != nil {
+			return nil, err
+		}
+		jsonReports := []model.ReportInfo{}
+		for _, r := range rows {
+			if r.ReportType == "internal_json" && r.Status == "ready" {
+				jsonReports = append(jsonReports, r)
+			}
+		}
+		if len(jsonReports) < 2 {
+			return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("case %s has fewer than 2 internal_json reports", caseID))
+		}
+		// ListReportsByCase 按生成时间倒序。
+		infoA, infoB = &jsonReports[1], &jsonReports[0]
+	} else {
+		var err error
+		if infoA, err = loadReportInfo(ctx, store, caseID, reportA); err != nil {
+			return nil, err
+		}
+		if infoB, err = loadReportInfo(ctx, store, caseID, reportB); err != nil {
+			return nil, err
+		}
+	}
+
+	warnings := []string{}
+	snapA, w, err := loadSnapshot(ctx, store, caseID, infoA)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, w...)
+	snapB, w, err := loadSnapshot(ctx, store, caseID, infoB)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, w...)
+
+	res := Compare(snapA, snapB)
+	res.CaseID = caseID
+	res.Warnings = append(warnings, res.Warnings...)
+	return res, nil
+}
+
+func loadReportInfo(ctx context.Context, store *sqliteadapter.Store, caseID, reportID string) (*model.ReportInfo, error) {
+	info, err := store.GetReportByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil || info.CaseID != caseID {
+		return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("report not found in case %s: %s", caseID, reportID))
+	}
+	if info.ReportType != "internal_json" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("report %s is %s; only internal_json reports can be compared", reportID, info.ReportType))
+	}
+	return info, nil
+}
+
+// loadSnapshot 读取报告文件（复核 sha256）并补充报告生成时已落库的余额类命中。
+func loadSnapshot(ctx context.Context, store *sqliteadapter.Store, caseID string, info *model.ReportInfo) (*Snapshot, []string, error) {
+	warnings := []string{}
+	raw, err := os.ReadFile(info.FilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read report file %s: %w", info.ReportID, err)
+	}
+	if sum, _, err := hash.File(info.FilePath); err == nil && !strings.EqualFold(sum, info.SHA256) {
+		warnings = append(warnings, fmt.Sprintf("report %s file sha256 mismatch: recorded=%s actual=%s", info.ReportID, info.SHA256, sum))
+	}
+	snap, err := ParseInternalJSON(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("report %s: %w", info.ReportID, err)
+	}
+	snap.Side.ReportID = info.ReportID
+	snap.Side.FilePath = info.FilePath
+	snap.Side.SHA256 = info.SHA256
+	// 以报告索引的生成时间为准（文件内时间戳与入库时间可能相差数秒）。
+	snap.Side.GeneratedAt = info.GeneratedAt
+
+	balances, err := store.ListBalanceHitsAsOf(ctx, caseID, info.GeneratedAt)
+	if err != nil {
+		return nil, nil, err
+	}
+	snap.Hits = append(snap.Hits, balances...)
+	return snap, warnings, nil
+}
+
+// Compare 计算两个快照的差异（纯函数，不访问数据库）。
+func Compare(a, b *Snapshot) *Result {
+	hitsA, hitsB := indexHits(a.Hits), indexHits(b.Hits)
+	res := &Result{
+		A:                a.Side,
+		B:                b.Side,
+		NewHits:          []HitRef{},
+		RemovedHits:      []HitRef{},
+		ChangedBalances:  []BalanceChange{},
+		NewArtifacts:     []ArtifactRef{},
+		RemovedArtifacts: []ArtifactRef{},
+		Warnings:         []string{},
+	}
+	res.A.HitCount, res.A.ArtifactCount = len(hitsA), len(a.Artifacts)
+	res.B.HitCount, res.B.ArtifactCount = len(hitsB), len(b.Artifacts)
+	if a.Side.PrivacyMode != b.Side.PrivacyMode {
+		// 脱敏报告的 matched_value 已被遮盖，与未脱敏报告对比会产生大量伪差异。
+		res.Warnings = append(res.Warnings, fmt.Sprintf("privacy_mode differs (a=%q b=%q); masked values may show as spurious differences", a.Side.PrivacyMode, b.Side.PrivacyMode))
+	}
+
+	for _, k := range sortedKeys(hitsB) {
+		hb := hitsB[k]
+		ha, ok := hitsA[k]
+		if !ok {
+			res.NewHits = append(res.NewHits, hitRef(hb))
+			continue
+		}
+		if !isBalanceHit(hb.HitType) {
+			continue
+		}
+		before, after := balanceState(ha), balanceState(hb)
+		if string(before) != string(after) {
+			res.ChangedBalances = append(res.ChangedBalances, BalanceChange{
+				HitType:      hb.HitType,
+				RuleID:       hb.RuleID,
+				MatchedValue: hb.MatchedValue,
+				BeforeHitID:  ha.HitID,
+				AfterHitID:   hb.HitID,
+				Before:       before,
+				After:        after,
+			})
+		}
+	}
+	for _, k := range sortedKeys(hitsA) {
+		if _, ok := hitsB[k]; !ok {
+			res.RemovedHits = append(res.RemovedHits, hitRef(hitsA[k]))
+		}
+	}
+
+	artA, artB := indexArtifacts(a.Artifacts), indexArtifacts(b.Artifacts)
+	for _, k := range sortedKeys(artB) {
+		if _, ok := artA[k]; !ok {
+			res.NewArtifacts = append(res.NewArtifacts, artB[k])
+		}
+	}
+	for _, k := range sortedKeys(artA) {
+		if _, ok := artB[k]; !ok {
+			res.RemovedArtifacts = append(res.RemovedArtifacts, artA[k])
+		}
+	}
+
+	res.Summary = Summary{
+		NewHits:          len(res.NewHits),
+		RemovedHits:      len(res.RemovedHits),
+		ChangedBalances:  len(res.ChangedBalances),
+		NewArtifacts:     len(res.NewArtifacts),
+		RemovedArtifacts: len(res.RemovedArtifacts),
+	}
+	return res
+}
+
+// indexHits 按命中身份去重（后出现的覆盖先出现的，余额类命中因此取最近一次结果）。
+func indexHits(hits []model.HitDetail) map[string]model.HitDetail {
+	out := make(map[string]model.HitDetail, len(hits))
+	for _, h := range hits {
+		out[h.HitType+"|"+h.RuleID+"|"+h.MatchedValue] = h
+	}
+	return out
+}
+
+func indexArtifacts(items []ArtifactRef) map[string]ArtifactRef {
+	out := make(map[string]ArtifactRef, len(items))
+	for _, a := range items {
+		out[a.ArtifactType+"|"+a.SourceRef+"|"+strings.ToLower(a.SHA256)] = a
+	}
+	return out
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hitRef(h model.HitDetail) HitRef {
+	return HitRef{
+		HitID:        h.HitID,
+		HitType:      h.HitType,
+		RuleID:       h.RuleID,
+		RuleName:     h.RuleName,
+		MatchedValue: h.MatchedValue,
+		Confidence:   h.Confidence,
+		Verdict:      h.Verdict,
+	}
+}
+
+func isBalanceHit(hitType string) bool {
+	return hitType == string(model.HitTokenBalance) || hitType == string(model.HitNFTHoldings)
+}
+
+// balanceState 抽取命中详情中与余额相关的字段（查询元数据/时间戳不参与比较）。
+func balanceState(h model.HitDetail) json.RawMessage {
+	var detail map[string]json.RawMessage
+	_ = json.Unmarshal([]byte(h.DetailJSON), &detail)
+	fields := []string{"balances"}
+	if h.HitType == string(model.HitNFTHoldings) {
+		fields = []string{"balance", "token_ids", "amounts"}
+	}
+	state := map[string]any{}
+	for _, f := range fields {
+		if v, ok := detail[f]; ok {
+			var decoded any
+			_ = json.Unmarshal(v, &decoded)
+			state[f] = decoded
+		}
+	}
+	raw, _ := json.Marshal(state)
+	return raw
+}
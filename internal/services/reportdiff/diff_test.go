@@ -0,0 +1,84 @@
+package reportdiff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"crypto-inspector/internal/domain/model"
+)
+
+func mustReport(t *testing.T, privacyMode string, artifacts []ArtifactRef, hits []model.RuleHit) *Snapshot {
+	t.Helper()
+	// 与 hostscan/mobilescan 写报告的方式一致：hits 直接序列化 model.RuleHit。
+	raw, err := json.Marshal(map[string]any{
+		"case_id":      "case_1",
+		"privacy_mode": privacyMode,
+		"generated_at": 100,
+		"artifacts":    artifacts,
+		"hits":         hits,
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	snap, err := ParseInternalJSON(raw)
+	if err != nil {
+		t.Fatalf("ParseInternalJSON: %v", err)
+	}
+	return snap
+}
+
+func balanceHit(id, value, amount string) model.RuleHit {
+	detail, _ := json.Marshal(map[string]any{
+		"balances": map[string]string{"USDT": amount},
+		"query":    map[string]any{"queried_at": id},
+	})
+	return model.RuleHit{ID: id, Type: model.HitTokenBalance, RuleID: "chain_balance_evm_erc20", MatchedValue: value, DetailJSON: detail}
+}
+
+func TestCompare(t *testing.T) {
+	a := mustReport(t, "off",
+		[]ArtifactRef{
+			{ArtifactID: "art_a1", ArtifactType: "installed_apps", SourceRef: "apps", SHA256: "aa"},
+			{ArtifactID: "art_a2", ArtifactType: "browser_history", SourceRef: "chrome", SHA256: "bb"},
+		},
+		[]model.RuleHit{
+			{ID: "hit_a1", Type: model.HitWalletInstalled, RuleID: "metamask", MatchedValue: "MetaMask"},
+			{ID: "hit_a2", Type: model.HitExchangeVisited, RuleID: "binance", MatchedValue: "binance.com"},
+			balanceHit("hit_a3", "0xabc|USDT", "1"),
+			balanceHit("hit_a4", "0xdef|USDT", "5"),
+		})
+	b := mustReport(t, "off",
+		[]ArtifactRef{
+			{ArtifactID: "art_b1", ArtifactType: "installed_apps", SourceRef: "apps", SHA256: "AA"},
+			{ArtifactID: "art_b2", ArtifactType: "browser_history", SourceRef: "chrome", SHA256: "cc"},
+		},
+		[]model.RuleHit{
+			{ID: "hit_b1", Type: model.HitWalletInstalled, RuleID: "metamask", MatchedValue: "MetaMask"},
+			{ID: "hit_b2", Type: model.HitWalletInstalled, RuleID: "okx", MatchedValue: "OKX Wallet"},
+			balanceHit("hit_b3", "0xabc|USDT", "2"),
+			balanceHit("hit_b4", "0xdef|USDT", "5"),
+		})
+
+	res := Compare(a, b)
+	if res.Summary != (Summary{NewHits: 1, RemovedHits: 1, ChangedBalances: 1, NewArtifacts: 1, RemovedArtifacts: 1}) {
+		t.Fatalf("summary=%+v", res.Summary)
+	}
+	if res.NewHits[0].MatchedValue != "OKX Wallet" || res.RemovedHits[0].MatchedValue != "binance.com" {
+		t.Fatalf("new=%+v removed=%+v", res.NewHits, res.RemovedHits)
+	}
+	c := res.ChangedBalances[0]
+	if c.MatchedValue != "0xabc|USDT" || string(c.Before) != `{"balances":{"USDT":"1"}}` || string(c.After) != `{"balances":{"USDT":"2"}}` {
+		t.Fatalf("change=%+v before=%s after=%s", c, c.Before, c.After)
+	}
+	if res.NewArtifacts[0].ArtifactID != "art_b2" || res.RemovedArtifacts[0].ArtifactID != "art_a2" {
+		t.Fatalf("artifacts new=%+v removed=%+v", res.NewArtifacts, res.RemovedArtifacts)
+	}
+	if len(res.Warnings) != 0 {
+		t.Fatalf("warnings=%v", res.Warnings)
+	}
+
+	b.Side.PrivacyMode = "masked"
+	if w := Compare(a, b).Warnings; len(w) != 1 {
+		t.Fatalf("expected privacy warning, got %v", w)
+	}
+}
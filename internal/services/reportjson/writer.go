@@ -0,0 +1,211 @@
+package reportjson
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/privacy"
+)
+
+// 内部 JSON 报告的流式写出
+//
+// 大案件的 internal_json 报告可达数百 MB。整体 json.MarshalIndent 需要同时持有全部命中/证据、
+// 中间 map 与完整输出缓冲，采集笔记本上容易 OOM。这里改为边编码边写盘：
+// - 顶层对象按 fields 顺序逐个写出（调用方按键名字母序排列，与原先 map 编码的输出一致）
+// - 数组字段逐元素编码；命中/证据按 PageSize 分页从库中读取，写完一页即释放
+// - 写出同时计算 SHA-256，不再回读文件
+// 输出格式与 json.MarshalIndent(payload, "", "  ") 相同，reportdiff 等读取方无需改动。
+
+// PageSize 是每页从库中读取的命中/证据条数。
+const PageSize = 500
+
+// Field 是报告顶层对象的一个字段。
+type Field struct {
+	Key string
+	// Value 整体编码；Stream 非 nil 时忽略 Value，按数组逐元素写出。
+	Value  any
+	Stream func(emit func(v any) error) error
+}
+
+// WriteFile 把 fields 作为一个 JSON 对象流式写入 path，返回文件 SHA-256。失败时删除半成品文件。
+func WriteFile(path string, fields []Field) (sum string, err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(path)
+		}
+	}()
+
+	h := sha256.New()
+	bw := bufio.NewWriterSize(io.MultiWriter(f, h), 256*1024)
+	if err = writeObject(bw, fields); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err = bw.Flush(); err != nil {
+		_ = f.Close()
+		return "", err
+	}
+	if err = f.Close(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func writeObject(w *bufio.Writer, fields []Field) error {
+	if len(fields) == 0 {
+		_, err := w.WriteString("{}")
+		return err
+	}
+	w.WriteString("{")
+	for i, fd := range fields {
+		if i > 0 {
+			w.WriteString(",")
+		}
+		key, err := json.Marshal(fd.Key)
+		if err != nil {
+			return err
+		}
+		w.WriteString("\n  ")
+		w.Write(key)
+		w.WriteString(": ")
+		if fd.Stream != nil {
+			if err := writeArray(w, fd.Key, fd.Stream); err != nil {
+				return err
+			}
+			continue
+		}
+		raw, err := json.MarshalIndent(fd.Value, "  ", "  ")
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", fd.Key, err)
+		}
+		if _, err := w.Write(raw); err != nil {
+			return err
+		}
+	}
+	_, err := w.WriteString("\n}")
+	return err
+}
+
+func writeArray(w *bufio.Writer, key string, stream func(emit func(v any) error) error) error {
+	n := 0
+	w.WriteString("[")
+	err := stream(func(v any) error {
+		raw, err := json.MarshalIndent(v, "    ", "  ")
+		if err != nil {
+			return fmt.Errorf("encode %s[%d]: %w", key, n, err)
+		}
+		if n > 0 {
+			w.WriteString(",")
+		}
+		w.WriteString("\n    ")
+		n++
+		_, err = w.Write(raw)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		w.WriteString("\n  ")
+	}
+	_, err = w.WriteString("]")
+	return err
+}
+
+// artifactSummary 是报告中的证据摘要行（主机/手机报告结构一致）。
+type artifactSummary struct {
+	ArtifactID   string `json:"artifact_id"`
+	ArtifactType string `json:"artifact_type"`
+	SourceRef    string `json:"source_ref"`
+	SnapshotPath string `json:"snapshot_path"`
+	SHA256       string `json:"sha256"`
+	CollectedAt  int64  `json:"collected_at"`
+	SizeBytes    int64  `json:"size_bytes"`
+}
+
+// Artifacts 返回按页从库中读取 ids 对应证据、逐条写出摘要的 Stream；masked 时脱敏快照路径。
+func Artifacts(ctx context.Context, store *sqliteadapter.Store, ids []string, masked bool) func(emit func(v any) error) error {
+	return func(emit func(v any) error) error {
+		for start := 0; start < len(ids); start += PageSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			page, err := store.ListArtifactsByIDs(ctx, ids[start:min(start+PageSize, len(ids))])
+			if err != nil {
+				return err
+			}
+			for _, a := range page {
+				snap := a.SnapshotPath
+				if masked {
+					snap = privacy.MaskSnapshotPath(snap)
+				}
+				if err := emit(artifactSummary{
+					ArtifactID:   a.ArtifactID,
+					ArtifactType: a.ArtifactType,
+					SourceRef:    a.SourceRef,
+					SnapshotPath: snap,
+					SHA256:       a.SHA256,
+					CollectedAt:  a.CollectedAt,
+					SizeBytes:    a.SizeBytes,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// Hits 返回按页从库中读取 ids 对应命中并逐条写出的 Stream；masked 时按报告规则脱敏。
+func Hits(ctx context.Context, store *sqliteadapter.Store, ids []string, masked bool) func(emit func(v any) error) error {
+	return func(emit func(v any) error) error {
+		for start := 0; start < len(ids); start += PageSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			page, err := store.ListRuleHitsByIDs(ctx, ids[start:min(start+PageSize, len(ids))])
+			if err != nil {
+				return err
+			}
+			if masked {
+				page = privacy.MaskRuleHitsForReport(page)
+			}
+			for _, h := range page {
+				if err := emit(h); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// ArtifactIDs 返回证据 ID 列表（保持顺序）。
+func ArtifactIDs(artifacts []model.Artifact) []string {
+	out := make([]string, 0, len(artifacts))
+	for _, a := range artifacts {
+		out = append(out, a.ID)
+	}
+	return out
+}
+
+// HitIDs 返回命中 ID 列表（保持顺序）。
+func HitIDs(hits []model.RuleHit) []string {
+	out := make([]string, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, h.ID)
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package reportjson
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/services/reportdiff"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestWriteFileStreamsPagesFromStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "t.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "host", OS: model.OSType("windows"), Identifier: "h"}, true, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := filepath.Join(dir, "evidence", "apps.json")
+	_ = os.MkdirAll(filepath.Dir(snap), 0o755)
+	_ = os.WriteFile(snap, []byte(`[]`), 0o644)
+	sum, size, _ := hash.File(snap)
+	arts := []model.Artifact{{
+		ID: "art_1", CaseID: caseID, DeviceID: "dev_1", Type: model.ArtifactInstalledApps, SourceRef: "registry",
+		SnapshotPath: snap, SHA256: sum, SizeBytes: size, CollectedAt: 1, CollectorName: "test", CollectorVersion: "0",
+		PayloadJSON: []byte(`[]`), RecordHash: strings.Repeat("0", 64),
+	}}
+	if err := store.SaveArtifacts(ctx, arts); err != nil {
+		t.Fatal(err)
+	}
+	// 跨多页的命中，写出顺序应与 ID 列表一致。
+	hits := make([]model.RuleHit, 0, 2*PageSize+1)
+	for i := 0; i < 2*PageSize+1; i++ {
+		hits = append(hits, model.RuleHit{
+			ID: fmt.Sprintf("hit_%04d", 2*PageSize-i), CaseID: caseID, DeviceID: "dev_1", Type: model.HitWalletAddress,
+			RuleID: "addr", RuleName: "address", RuleVersion: "1", MatchedValue: fmt.Sprintf("0x%040d", i),
+			Confidence: 0.9, Verdict: "confirmed", DetailJSON: []byte(`{"chain":"eth"}`), ArtifactIDs: []string{"art_1"},
+		})
+	}
+	if err := store.SaveRuleHits(ctx, hits); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "report.json")
+	fields := []Field{
+		{Key: "artifacts", Stream: Artifacts(ctx, store, ArtifactIDs(arts), false)},
+		{Key: "case_id", Value: caseID},
+		{Key: "hits", Stream: Hits(ctx, store, HitIDs(hits), false)},
+		{Key: "warnings", Value: []string{}},
+	}
+	got, err := WriteFile(path, fields)
+	if err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	raw, _ := os.ReadFile(path)
+	if got != hash.Bytes(raw) {
+		t.Fatalf("sha256 mismatch")
+	}
+
+	// 与整体 MarshalIndent 的输出逐字节一致。
+	want, _ := json.MarshalIndent(map[string]any{
+		"artifacts": []artifactSummary{{ArtifactID: "art_1", ArtifactType: string(model.ArtifactInstalledApps), SourceRef: "registry", SnapshotPath: snap, SHA256: sum, CollectedAt: 1, SizeBytes: size}},
+		"case_id":   caseID,
+		"hits":      hits,
+		"warnings":  []string{},
+	}, "", "  ")
+	if string(raw) != string(want) {
+		t.Fatalf("streamed output differs from MarshalIndent:\n%.400s\nwant:\n%.400s", raw, want)
+	}
+	snapParsed, err := reportdiff.ParseInternalJSON(raw)
+	if err != nil || len(snapParsed.Hits) != len(hits) || len(snapParsed.Artifacts) != 1 {
+		t.Fatalf("ParseInternalJSON err=%v", err)
+	}
+
+	// 取消时返回错误且不留下半成品文件。
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	bad := filepath.Join(dir, "canceled.json")
+	if _, err := WriteFile(bad, []Field{{Key: "hits", Stream: Hits(cctx, store, HitIDs(hits), false)}}); err == nil {
+		t.Fatalf("expected error for canceled context")
+	}
+	if _, err := os.Stat(bad); !os.IsNotExist(err) {
+		t.Fatalf("partial report left behind: %v", err)
+	}
+}
@@ -0,0 +1,223 @@
+package siemforward
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/model"
+)
+
+// 输出格式
+//
+// - cef：RFC 5424 syslog 头 + ArcSight CEF 载荷（多数 SIEM 开箱可解析）
+// - syslog：RFC 5424 syslog 头 + 紧凑 JSON 载荷（便于自定义解析规则）
+//
+// syslog facility 固定为 13（log audit）。
+
+const (
+	FormatCEF    = "cef"
+	FormatSyslog = "syslog"
+
+	cefVendor  = "Crypto-Trace-Inspector"
+	cefProduct = "inspector"
+	appName    = "crypto-inspector"
+
+	facilityLogAudit = 13
+
+	// maxDetailLen 限制单条事件中 detail 的长度（UDP 报文过大时会被丢弃/截断）。
+	maxDetailLen = 1024
+)
+
+// Event 是一条待转发事件：普通审计日志，或由转发器生成的完整性告警。
+type Event struct {
+	Log model.AuditLog
+
+	// Alert 非空表示这是一条审计链完整性告警（Log 中只有 CaseID/OccurredAt 有意义）。
+	Alert *IntegrityAlert
+}
+
+// IntegrityAlert 是审计链校验失败告警。
+type IntegrityAlert struct {
+	CaseID          string `json:"case_id"`
+	Total           int    `json:"total"`
+	Failed          int    `json:"failed"`
+	PrevHashFailed  int    `json:"prev_hash_failed"`
+	ChainHashFailed int    `json:"chain_hash_failed"`
+	FirstEventID    string `json:"first_failed_event_id,omitempty"`
+	DetectedAt      int64  `json:"detected_at"`
+}
+
+// Format 把事件格式化为一行 syslog 消息（不含换行符）。
+func Format(ev Event, format, hostname string) (string, error) {
+	if strings.TrimSpace(hostname) == "" {
+		hostname = "-"
+	}
+	ts := ev.Log.OccurredAt
+	msgID := "audit"
+	if ev.Alert != nil {
+		ts = ev.Alert.DetectedAt
+		msgID = "integrity"
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s - %s - ",
+		facilityLogAudit*8+syslogSeverity(ev),
+		time.Unix(ts, 0).UTC().Format(time.RFC3339),
+		sanitizeHeaderField(hostname),
+		appName,
+		msgID,
+	)
+
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", FormatCEF:
+		return header + formatCEF(ev), nil
+	case FormatSyslog:
+		var raw []byte
+		var err error
+		if ev.Alert != nil {
+			raw, err = json.Marshal(map[string]any{"type": "audit_integrity_alert", "alert": ev.Alert})
+		} else {
+			raw, err = json.Marshal(ev.Log)
+		}
+		if err != nil {
+			return "", fmt.Errorf("marshal event: %w", err)
+		}
+		return header + string(raw), nil
+	default:
+		return "", fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// formatCEF 生成 CEF 载荷：
+// CEF:Version|Vendor|Product|Version|SignatureID|Name|Severity|Extension
+func formatCEF(ev Event) string {
+	version := strings.TrimSpace(app.Version)
+	if version == "" {
+		version = "dev"
+	}
+
+	if ev.Alert != nil {
+		a := ev.Alert
+		ext := []string{
+			"rt=" + strconv.FormatInt(a.DetectedAt*1000, 10),
+			"cs1Label=case_id", "cs1=" + cefExtValue(a.CaseID),
+			"cnt=" + strconv.Itoa(a.Failed),
+			"cn1Label=total", "cn1=" + strconv.Itoa(a.Total),
+			"cn2Label=prev_hash_failed", "cn2=" + strconv.Itoa(a.PrevHashFailed),
+			"cn3Label=chain_hash_failed", "cn3=" + strconv.Itoa(a.ChainHashFailed),
+			"externalId=" + cefExtValue(a.FirstEventID),
+			"msg=" + cefExtValue("audit chain verification failed"),
+		}
+		return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
+			cefHeader(cefVendor), cefHeader(cefProduct), cefHeader(version),
+			"audit_integrity.failed", "Audit chain integrity failure", 10,
+			strings.Join(ext, " "),
+		)
+	}
+
+	l := ev.Log
+	detail := compactDetail(l.DetailJSON)
+	ext := []string{
+		"rt=" + strconv.FormatInt(l.OccurredAt*1000, 10),
+		"externalId=" + cefExtValue(l.EventID),
+		"cat=" + cefExtValue(l.EventType),
+		"act=" + cefExtValue(l.Action),
+		"outcome=" + cefExtValue(l.Status),
+		"suser=" + cefExtValue(l.Actor),
+		"deviceProcessName=" + cefExtValue(l.Source),
+		"cs1Label=case_id", "cs1=" + cefExtValue(l.CaseID),
+		"cs2Label=device_id", "cs2=" + cefExtValue(l.DeviceID),
+		"cs3Label=trace_id", "cs3=" + cefExtValue(traceIDOf(l.DetailJSON)),
+		"cs4Label=chain_hash", "cs4=" + cefExtValue(l.ChainHash),
+		"msg=" + cefExtValue(detail),
+	}
+	return fmt.Sprintf("CEF:0|%s|%s|%s|%s|%s|%d|%s",
+		cefHeader(cefVendor), cefHeader(cefProduct), cefHeader(version),
+		cefHeader(l.EventType+"."+l.Action),
+		cefHeader(l.EventType+" "+l.Action+" "+l.Status),
+		cefSeverity(l.Status),
+		strings.Join(ext, " "),
+	)
+}
+
+// cefSeverity 把审计状态映射为 CEF 严重级别（0-10）。
+func cefSeverity(status string) int {
+	switch status {
+	case "failed":
+		return 7
+	case "skipped":
+		return 4
+	default:
+		return 2
+	}
+}
+
+// syslogSeverity 把事件映射为 syslog severity（RFC 5424）。
+func syslogSeverity(ev Event) int {
+	if ev.Alert != nil {
+		return 1 // alert
+	}
+	switch ev.Log.Status {
+	case "failed":
+		return 4 // warning
+	case "skipped":
+		return 5 // notice
+	default:
+		return 6 // informational
+	}
+}
+
+// cefHeader 转义 CEF 头字段：反斜杠与竖线。
+func cefHeader(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, "|", `\|`)
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(v)
+}
+
+// cefExtValue 转义 CEF 扩展字段值：反斜杠、等号与换行。
+func cefExtValue(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, "=", `\=`)
+	v = strings.ReplaceAll(v, "\r\n", `\n`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+	return strings.ReplaceAll(v, "\r", `\r`)
+}
+
+func sanitizeHeaderField(v string) string {
+	v = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, v)
+	if len(v) > 255 {
+		v = v[:255]
+	}
+	return v
+}
+
+func compactDetail(raw json.RawMessage) string {
+	s := strings.TrimSpace(string(raw))
+	if s == "" || s == "{}" {
+		return ""
+	}
+	if len(s) > maxDetailLen {
+		n := maxDetailLen
+		for n > 0 && !utf8.RuneStart(s[n]) {
+			n--
+		}
+		s = s[:n] + "...(truncated)"
+	}
+	return s
+}
+
+func traceIDOf(raw json.RawMessage) string {
+	var m struct {
+		TraceID string `json:"trace_id"`
+	}
+	_ = json.Unmarshal(raw, &m)
+	return m.TraceID
+}
@@ -0,0 +1,422 @@
+package siemforward
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/auditverify"
+)
+
+// SIEM 转发器
+//
+// 设计要点：
+// - audit_logs 本身就是持久化缓冲：转发器只维护一个游标（rowid），
+//   目标端点不可用时游标不前进，恢复后从断点继续，不丢事件
+// - 游标写入 state 文件（可选），进程重启后续传
+// - 每批转发后对涉及的案件重新校验审计链，发现断链/篡改时额外发送完整性告警
+// - Replay 用于历史回填：按时间范围重发，不读写游标
+
+// Filter 定义转发过滤条件（空表示不过滤）。
+type Filter struct {
+	CaseID     string
+	EventTypes []string
+	Statuses   []string
+}
+
+// Match 判断审计日志是否需要转发。
+func (f Filter) Match(l model.AuditLog) bool {
+	if c := strings.TrimSpace(f.CaseID); c != "" && l.CaseID != c {
+		return false
+	}
+	if len(f.EventTypes) > 0 && !containsFold(f.EventTypes, l.EventType) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !containsFold(f.Statuses, l.Status) {
+		return false
+	}
+	return true
+}
+
+// Options 定义转发器参数。
+type Options struct {
+	Endpoint string // udp://host:514 | tcp://host:514 | file:///path
+	Format   string // cef|syslog（默认 cef）
+	Hostname string // syslog HOSTNAME 字段（默认 os.Hostname）
+
+	Filter Filter
+
+	// StateFile 保存转发游标；为空时游标只保存在内存中（重启后从头转发）。
+	StateFile string
+
+	BatchSize    int           // 每批读取条数（默认 200）
+	PollInterval time.Duration // 轮询间隔（默认 5s）
+	MaxBackoff   time.Duration // 端点不可用时的最大重试间隔（默认 2m）
+
+	// VerifyIntegrity=true 时对每批涉及的案件校验审计链并转发完整性告警。
+	VerifyIntegrity bool
+
+	// Logf 用于输出转发器运行日志（nil 表示不输出）。
+	Logf func(format string, args ...any)
+}
+
+// State 是持久化的转发进度。
+type State struct {
+	LastSeq   int64 `json:"last_seq"`
+	Forwarded int64 `json:"forwarded"`
+	UpdatedAt int64 `json:"updated_at"`
+
+	// Alerted 记录已告警案件的失败签名，避免同一断链重复告警。
+	Alerted map[string]string `json:"alerted,omitempty"`
+}
+
+// Stats 是一次 RunOnce/Replay 的统计。
+type Stats struct {
+	Scanned   int   `json:"scanned"`
+	Forwarded int   `json:"forwarded"`
+	Filtered  int   `json:"filtered"`
+	Alerts    int   `json:"alerts"`
+	LastSeq   int64 `json:"last_seq"`
+}
+
+// Forwarder 增量转发审计日志。
+type Forwarder struct {
+	store  *sqliteadapter.Store
+	opts   Options
+	sender Sender
+
+	mu    sync.Mutex
+	state State
+}
+
+// New 创建转发器并加载游标。
+func New(store *sqliteadapter.Store, opts Options) (*Forwarder, error) {
+	opts = withDefaults(opts)
+	if _, err := Format(Event{}, opts.Format, opts.Hostname); err != nil {
+		return nil, err
+	}
+	sender, err := NewSender(opts.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	f := &Forwarder{store: store, opts: opts, sender: sender}
+	if err := f.loadState(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Close 释放连接。
+func (f *Forwarder) Close() error {
+	return f.sender.Close()
+}
+
+// State 返回当前游标状态（副本）。
+func (f *Forwarder) State() State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	st := f.state
+	st.Alerted = make(map[string]string, len(f.state.Alerted))
+	for k, v := range f.state.Alerted {
+		st.Alerted[k] = v
+	}
+	return st
+}
+
+// Run 持续转发直到 ctx 取消；端点不可用时按指数退避重试（期间事件留在数据库中）。
+func (f *Forwarder) Run(ctx context.Context) error {
+	backoff := f.opts.PollInterval
+	failing := false
+	for {
+		_, err := f.RunOnce(ctx)
+		wait := f.opts.PollInterval
+		switch {
+		case err != nil && ctx.Err() == nil:
+			if !failing {
+				f.logf("siem forward failed, buffering until endpoint recovers: %v", err)
+			}
+			failing = true
+			wait = backoff
+			backoff *= 2
+			if backoff > f.opts.MaxBackoff {
+				backoff = f.opts.MaxBackoff
+			}
+		case err == nil:
+			if failing {
+				f.logf("siem forward recovered: last_seq=%d", f.State().LastSeq)
+			}
+			failing = false
+			backoff = f.opts.PollInterval
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil
+		case <-t.C:
+		}
+	}
+}
+
+// RunOnce 转发游标之后的全部事件（分批），直到没有新事件或发送失败。
+func (f *Forwarder) RunOnce(ctx context.Context) (Stats, error) {
+	var stats Stats
+	for {
+		st := f.State()
+		logs, err := f.store.ListAuditLogsAfter(ctx, sqliteadapter.AuditLogQuery{
+			AfterSeq: st.LastSeq,
+			Limit:    f.opts.BatchSize,
+		})
+		if err != nil {
+			return stats, err
+		}
+		if len(logs) == 0 {
+			stats.LastSeq = st.LastSeq
+			return stats, nil
+		}
+
+		lines, bs, err := f.buildLines(logs)
+		if err != nil {
+			return stats, err
+		}
+		alertLines, alerted, err := f.integrityAlerts(ctx, logs, st.Alerted)
+		if err != nil {
+			return stats, err
+		}
+		lines = append(lines, alertLines...)
+
+		if err := f.sender.Send(ctx, lines); err != nil {
+			return stats, err
+		}
+
+		f.mu.Lock()
+		f.state.LastSeq = logs[len(logs)-1].Seq
+		f.state.Forwarded += int64(bs.Forwarded)
+		f.state.UpdatedAt = time.Now().Unix()
+		f.state.Alerted = alerted
+		f.mu.Unlock()
+		if err := f.saveState(); err != nil {
+			return stats, err
+		}
+
+		stats.Scanned += bs.Scanned
+		stats.Forwarded += bs.Forwarded
+		stats.Filtered += bs.Filtered
+		stats.Alerts += len(alertLines)
+		stats.LastSeq = logs[len(logs)-1].Seq
+		if len(logs) < f.opts.BatchSize {
+			return stats, nil
+		}
+	}
+}
+
+// ReplayOptions 定义历史回填范围。
+type ReplayOptions struct {
+	Since int64 // occurred_at >= Since（0 表示不限）
+	Until int64 // occurred_at <= Until（0 表示不限）
+}
+
+// Replay 按时间范围重发历史审计日志（不读写游标，SIEM 侧按 externalId 去重）。
+func Replay(ctx context.Context, store *sqliteadapter.Store, opts Options, r ReplayOptions) (Stats, error) {
+	opts = withDefaults(opts)
+	if _, err := Format(Event{}, opts.Format, opts.Hostname); err != nil {
+		return Stats{}, err
+	}
+	sender, err := NewSender(opts.Endpoint)
+	if err != nil {
+		return Stats{}, err
+	}
+	defer sender.Close()
+
+	f := &Forwarder{store: store, opts: opts, sender: sender}
+	var stats Stats
+	var after int64
+	for {
+		logs, err := store.ListAuditLogsAfter(ctx, sqliteadapter.AuditLogQuery{
+			AfterSeq: after,
+			CaseID:   opts.Filter.CaseID,
+			Since:    r.Since,
+			Until:    r.Until,
+			Limit:    opts.BatchSize,
+		})
+		if err != nil {
+			return stats, err
+		}
+		if len(logs) == 0 {
+			return stats, nil
+		}
+		lines, bs, err := f.buildLines(logs)
+		if err != nil {
+			return stats, err
+		}
+		if err := sender.Send(ctx, lines); err != nil {
+			return stats, err
+		}
+		after = logs[len(logs)-1].Seq
+		stats.Scanned += bs.Scanned
+		stats.Forwarded += bs.Forwarded
+		stats.Filtered += bs.Filtered
+		stats.LastSeq = after
+		if len(logs) < opts.BatchSize {
+			return stats, nil
+		}
+	}
+}
+
+func (f *Forwarder) buildLines(logs []model.AuditLog) ([]string, Stats, error) {
+	var st Stats
+	lines := make([]string, 0, len(logs))
+	for _, l := range logs {
+		st.Scanned++
+		if !f.opts.Filter.Match(l) {
+			st.Filtered++
+			continue
+		}
+		line, err := Format(Event{Log: l}, f.opts.Format, f.opts.Hostname)
+		if err != nil {
+			return nil, st, err
+		}
+		lines = append(lines, line)
+		st.Forwarded++
+	}
+	return lines, st, nil
+}
+
+// integrityAlerts 校验本批涉及案件的审计链，返回需要发送的告警行与更新后的告警签名表。
+func (f *Forwarder) integrityAlerts(ctx context.Context, logs []model.AuditLog, prev map[string]string) ([]string, map[string]string, error) {
+	alerted := make(map[string]string, len(prev))
+	for k, v := range prev {
+		alerted[k] = v
+	}
+	if !f.opts.VerifyIntegrity {
+		return nil, alerted, nil
+	}
+
+	seen := map[string]struct{}{}
+	var lines []string
+	for _, l := range logs {
+		if _, ok := seen[l.CaseID]; ok {
+			continue
+		}
+		seen[l.CaseID] = struct{}{}
+		if c := strings.TrimSpace(f.opts.Filter.CaseID); c != "" && c != l.CaseID {
+			continue
+		}
+
+		caseLogs, err := f.store.ListAuditLogs(ctx, l.CaseID, 5000)
+		if err != nil {
+			return nil, nil, err
+		}
+		res := auditverify.VerifyAuditLogs(caseLogs)
+		if res.OK {
+			delete(alerted, l.CaseID)
+			continue
+		}
+		first := ""
+		if len(res.Failures) > 0 {
+			first = res.Failures[0].EventID
+		}
+		sig := fmt.Sprintf("%d:%s", res.Failed, first)
+		if alerted[l.CaseID] == sig {
+			continue
+		}
+		line, err := Format(Event{Alert: &IntegrityAlert{
+			CaseID:          l.CaseID,
+			Total:           res.Total,
+			Failed:          res.Failed,
+			PrevHashFailed:  res.PrevHashFailed,
+			ChainHashFailed: res.ChainHashFailed,
+			FirstEventID:    first,
+			DetectedAt:      time.Now().Unix(),
+		}}, f.opts.Format, f.opts.Hostname)
+		if err != nil {
+			return nil, nil, err
+		}
+		lines = append(lines, line)
+		alerted[l.CaseID] = sig
+	}
+	return lines, alerted, nil
+}
+
+func (f *Forwarder) loadState() error {
+	if strings.TrimSpace(f.opts.StateFile) == "" {
+		return nil
+	}
+	raw, err := os.ReadFile(f.opts.StateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read siem state: %w", err)
+	}
+	var st State
+	if err := json.Unmarshal(raw, &st); err != nil {
+		return fmt.Errorf("parse siem state: %w", err)
+	}
+	f.state = st
+	return nil
+}
+
+func (f *Forwarder) saveState() error {
+	if strings.TrimSpace(f.opts.StateFile) == "" {
+		return nil
+	}
+	raw, err := json.MarshalIndent(f.State(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal siem state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(f.opts.StateFile), 0o755); err != nil {
+		return fmt.Errorf("create siem state dir: %w", err)
+	}
+	// 先写临时文件再 rename，避免进程中断留下半截 JSON。
+	tmp := f.opts.StateFile + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("write siem state: %w", err)
+	}
+	if err := os.Rename(tmp, f.opts.StateFile); err != nil {
+		return fmt.Errorf("replace siem state: %w", err)
+	}
+	return nil
+}
+
+func (f *Forwarder) logf(format string, args ...any) {
+	if f.opts.Logf != nil {
+		f.opts.Logf(format, args...)
+	}
+}
+
+func withDefaults(opts Options) Options {
+	if strings.TrimSpace(opts.Format) == "" {
+		opts.Format = FormatCEF
+	}
+	if strings.TrimSpace(opts.Hostname) == "" {
+		opts.Hostname, _ = os.Hostname()
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 200
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 5 * time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = 2 * time.Minute
+	}
+	return opts
+}
+
+func containsFold(list []string, v string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), v) {
+			return true
+		}
+	}
+	return false
+}
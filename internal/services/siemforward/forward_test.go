@@ -0,0 +1,112 @@
+package siemforward
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestForwarder_RunOnceFiltersAndResumesFromCursor(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	db, err := sql.Open("sqlite", filepath.Join(tmp, "inspector.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "SIEM-001", "SIEM Test", "tester", "")
+	if err != nil {
+		t.Fatalf("ensure case: %v", err)
+	}
+
+	appendAudit := func(status string) {
+		t.Helper()
+		// event_id 含毫秒时间戳：间隔 2ms 保证同一秒内的排序与写入顺序一致。
+		time.Sleep(2 * time.Millisecond)
+		if err := store.AppendAudit(ctx, caseID, "", "scan", "host_scan", status, "tester", "test", map[string]any{"k": "v|x=1"}); err != nil {
+			t.Fatalf("append audit: %v", err)
+		}
+	}
+	appendAudit("success")
+	appendAudit("failed")
+
+	outPath := filepath.Join(tmp, "siem.log")
+	opts := Options{
+		Endpoint:        "file://" + filepath.ToSlash(outPath),
+		Hostname:        "inspector-test",
+		StateFile:       filepath.Join(tmp, "state.json"),
+		Filter:          Filter{Statuses: []string{"failed"}},
+		VerifyIntegrity: true,
+	}
+	fwd, err := New(store, opts)
+	if err != nil {
+		t.Fatalf("new forwarder: %v", err)
+	}
+	stats, err := fwd.RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("run once: %v", err)
+	}
+	fwd.Close()
+	// 审计链完整：不应产生告警。
+	if stats.Scanned < 2 || stats.Forwarded != 1 || stats.Alerts != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	raw, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("want 1 line, got %d: %q", len(lines), raw)
+	}
+	if !strings.HasPrefix(lines[0], "<108>1 ") || !strings.Contains(lines[0], "CEF:0|Crypto-Trace-Inspector|inspector|") {
+		t.Fatalf("unexpected line: %s", lines[0])
+	}
+	if !strings.Contains(lines[0], "outcome=failed") || !strings.Contains(lines[0], `v|x\=1`) {
+		t.Fatalf("extension not escaped/filled: %s", lines[0])
+	}
+
+	// 新实例从 state 文件续传：只转发游标之后的新事件。
+	appendAudit("failed")
+	fwd, err = New(store, opts)
+	if err != nil {
+		t.Fatalf("reopen forwarder: %v", err)
+	}
+	defer fwd.Close()
+	stats, err = fwd.RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("run once (resume): %v", err)
+	}
+	if stats.Scanned != 1 || stats.Forwarded != 1 {
+		t.Fatalf("resume should only scan new events: %+v", stats)
+	}
+
+	// Replay 不受游标影响，可重发全部历史事件。
+	replayOut := filepath.Join(tmp, "replay.log")
+	ropts := opts
+	ropts.Endpoint = "file://" + filepath.ToSlash(replayOut)
+	ropts.Filter = Filter{}
+	ropts.Format = FormatSyslog
+	rs, err := Replay(ctx, store, ropts, ReplayOptions{})
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if rs.Forwarded != rs.Scanned || rs.Forwarded < 3 {
+		t.Fatalf("unexpected replay stats: %+v", rs)
+	}
+}
@@ -0,0 +1,130 @@
+package siemforward
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Sender 把格式化后的 syslog 行发送到目标端点。
+type Sender interface {
+	Send(ctx context.Context, lines []string) error
+	Close() error
+}
+
+// NewSender 根据端点 URL 创建发送器：
+// - udp://host:514：每行一个 UDP 报文（RFC 5426）
+// - tcp://host:514：按换行分帧（RFC 6587 non-transparent framing）
+// - file:///path/to/siem.log：追加写入本地文件（离线场景，由其他采集器接走）
+func NewSender(endpoint string) (Sender, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return nil, fmt.Errorf("siem endpoint is required")
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse siem endpoint: %w", err)
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "udp", "tcp":
+		if u.Host == "" {
+			return nil, fmt.Errorf("siem endpoint missing host: %s", endpoint)
+		}
+		addr := u.Host
+		if u.Port() == "" {
+			addr = net.JoinHostPort(u.Hostname(), "514")
+		}
+		return &netSender{network: strings.ToLower(u.Scheme), addr: addr, timeout: 10 * time.Second}, nil
+	case "file":
+		p := u.Path
+		if u.Host != "" {
+			// file://relative/path 被解析为 Host=relative，这里还原为相对路径。
+			p = filepath.Join(u.Host, u.Path)
+		}
+		if strings.TrimSpace(p) == "" {
+			return nil, fmt.Errorf("siem endpoint missing file path: %s", endpoint)
+		}
+		return &fileSender{path: filepath.FromSlash(p)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported siem endpoint scheme: %s (want udp|tcp|file)", u.Scheme)
+	}
+}
+
+// netSender 维护一个长连接；发送失败时关闭连接，下次发送自动重连。
+type netSender struct {
+	network string
+	addr    string
+	timeout time.Duration
+
+	conn net.Conn
+}
+
+func (s *netSender) Send(ctx context.Context, lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	if s.conn == nil {
+		d := net.Dialer{Timeout: s.timeout}
+		conn, err := d.DialContext(ctx, s.network, s.addr)
+		if err != nil {
+			return fmt.Errorf("dial %s://%s: %w", s.network, s.addr, err)
+		}
+		s.conn = conn
+	}
+	_ = s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+
+	var err error
+	if s.network == "udp" {
+		for _, line := range lines {
+			if _, err = s.conn.Write([]byte(line)); err != nil {
+				break
+			}
+		}
+	} else {
+		_, err = s.conn.Write([]byte(strings.Join(lines, "\n") + "\n"))
+	}
+	if err != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("write %s://%s: %w", s.network, s.addr, err)
+	}
+	return nil
+}
+
+func (s *netSender) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+type fileSender struct {
+	path string
+}
+
+func (s *fileSender) Send(_ context.Context, lines []string) error {
+	if len(lines) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create siem output dir: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open siem output file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		return fmt.Errorf("write siem output file: %w", err)
+	}
+	return f.Sync()
+}
+
+func (s *fileSender) Close() error { return nil }
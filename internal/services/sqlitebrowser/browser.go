@@ -0,0 +1,364 @@
+package sqlitebrowser
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/platform/filetype"
+	"crypto-inspector/internal/services/artifactpreview"
+)
+
+// SQLite 证据浏览器
+//
+// browser_history_db 证据是“原始 History DB（含 wal/shm）打包的 zip”。
+// SQLite 无法直接读 zip 内的文件，因此：
+// - 把目标 DB 与同名 -wal 解压到缓存目录（按证据 sha256 分目录，证据不变则复用）
+// - 以 mode=ro 打开解压副本（WAL 中未 checkpoint 的记录也能看到）
+// - 证据快照本身从不被打开写入；直接是 SQLite 文件的快照用 immutable 只读打开
+//
+// 所有表名/列名都先与 sqlite_master / table_info 比对，再以转义标识符拼接，杜绝注入。
+
+const (
+	defaultRowLimit = 100
+	maxRowLimit     = 1000
+
+	// maxBlobPreview 是 BLOB 列返回的最大字节数（hex）。
+	maxBlobPreview = 64
+)
+
+// Source 是一个证据快照。
+type Source struct {
+	SnapshotPath string
+	SHA256       string
+}
+
+// Database 是快照中可浏览的一个 SQLite 数据库。
+type Database struct {
+	Entry     string `json:"entry"` // zip 内路径；快照本身即 DB 时为空
+	SizeBytes int64  `json:"size_bytes"`
+	HasWAL    bool   `json:"has_wal"`
+}
+
+// RowQuery 是分页查询参数。
+type RowQuery struct {
+	Table   string
+	Offset  int
+	Limit   int
+	OrderBy string
+	Desc    bool
+}
+
+// RowPage 是一页表数据。
+type RowPage struct {
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+	Rows    [][]any  `json:"rows"`
+	Total   int64    `json:"total"`
+	Offset  int      `json:"offset"`
+	Limit   int      `json:"limit"`
+	OrderBy string   `json:"order_by,omitempty"`
+	Desc    bool     `json:"desc,omitempty"`
+}
+
+// Browser 管理解压缓存并提供只读查询。
+type Browser struct {
+	cacheDir string
+
+	mu sync.Mutex // 串行化解压，避免并发请求写同一缓存目录
+}
+
+// New 创建浏览器；cacheDir 为解压副本缓存目录。
+func New(cacheDir string) *Browser {
+	return &Browser{cacheDir: cacheDir}
+}
+
+// Databases 列出快照中的 SQLite 数据库。
+func (b *Browser) Databases(src Source) ([]Database, error) {
+	switch filetype.DetectFile(src.SnapshotPath) {
+	case filetype.MimeSQLite:
+		st, err := os.Stat(src.SnapshotPath)
+		if err != nil {
+			return nil, fmt.Errorf("stat snapshot: %w", err)
+		}
+		return []Database{{SizeBytes: st.Size()}}, nil
+	case filetype.MimeZip:
+	default:
+		return nil, apperr.New(apperr.CodeInvalidArgument, "artifact snapshot is not a sqlite database or zip archive")
+	}
+
+	zr, err := zip.OpenReader(src.SnapshotPath)
+	if err != nil {
+		return nil, fmt.Errorf("open zip: %w", err)
+	}
+	defer zr.Close()
+
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	out := []Database{}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || isSidecar(f.Name) || !isSQLiteEntry(f) {
+			continue
+		}
+		out = append(out, Database{
+			Entry:     f.Name,
+			SizeBytes: int64(f.UncompressedSize64),
+			HasWAL:    names[f.Name+"-wal"],
+		})
+	}
+	return out, nil
+}
+
+// Tables 列出数据库中的表/视图（含列名与行数）。
+func (b *Browser) Tables(ctx context.Context, src Source, entry string) (*artifactpreview.SQLiteSummary, error) {
+	db, err := b.open(src, entry)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return artifactpreview.SummarizeSQLite(ctx, db, 0)
+}
+
+// Rows 分页查询表数据。
+func (b *Browser) Rows(ctx context.Context, src Source, entry string, q RowQuery) (*RowPage, error) {
+	db, err := b.open(src, entry)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	var exists int
+	if err := db.QueryRowContext(ctx, `
+		SELECT COUNT(1) FROM sqlite_master WHERE type IN ('table', 'view') AND name = ?
+	`, q.Table).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("query sqlite table: %w", err)
+	}
+	if exists == 0 {
+		return nil, apperr.New(apperr.CodeNotFound, fmt.Sprintf("table not found: %s", q.Table))
+	}
+	cols, err := artifactpreview.TableColumns(ctx, db, q.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = defaultRowLimit
+	}
+	if q.Limit > maxRowLimit {
+		q.Limit = maxRowLimit
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	page := &RowPage{Table: q.Table, Columns: cols, Rows: [][]any{}, Offset: q.Offset, Limit: q.Limit}
+	table := artifactpreview.QuoteIdent(q.Table)
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("count sqlite rows: %w", err)
+	}
+
+	stmt := `SELECT * FROM ` + table
+	if q.OrderBy != "" {
+		if !contains(cols, q.OrderBy) {
+			return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unknown column: %s", q.OrderBy))
+		}
+		stmt += ` ORDER BY ` + artifactpreview.QuoteIdent(q.OrderBy)
+		if q.Desc {
+			stmt += ` DESC`
+		}
+		page.OrderBy, page.Desc = q.OrderBy, q.Desc
+	}
+	stmt += ` LIMIT ? OFFSET ?`
+
+	rows, err := db.QueryContext(ctx, stmt, q.Limit, q.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("query sqlite rows: %w", err)
+	}
+	defer rows.Close()
+	// SELECT * 的实际列数以结果集为准（例如 WITHOUT ROWID / 视图）。
+	rcols, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("read sqlite columns: %w", err)
+	}
+	page.Columns = rcols
+	for rows.Next() {
+		vals := make([]any, len(rcols))
+		ptrs := make([]any, len(rcols))
+		for i := range vals {
+			ptrs[i] = &vals[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("scan sqlite row: %w", err)
+		}
+		for i, v := range vals {
+			vals[i] = jsonValue(v)
+		}
+		page.Rows = append(page.Rows, vals)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate sqlite rows: %w", err)
+	}
+	return page, nil
+}
+
+func (b *Browser) open(src Source, entry string) (*sql.DB, error) {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		if filetype.DetectFile(src.SnapshotPath) != filetype.MimeSQLite {
+			return nil, apperr.New(apperr.CodeInvalidArgument, "db entry is required for zip snapshots")
+		}
+		return artifactpreview.OpenSQLiteReadOnly(src.SnapshotPath)
+	}
+
+	path, err := b.extract(src, entry)
+	if err != nil {
+		return nil, err
+	}
+	uri, err := artifactpreview.SQLiteURI(path, "mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", uri)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	return db, nil
+}
+
+// extract 把 zip 内的 DB（及 -wal）解压到缓存目录，返回 DB 副本路径。
+func (b *Browser) extract(src Source, entry string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := src.SHA256
+	if len(key) > 16 {
+		key = key[:16]
+	}
+	if key == "" {
+		key = "nohash"
+	}
+	eh := sha256.Sum256([]byte(entry))
+	dir := filepath.Join(b.cacheDir, key, hex.EncodeToString(eh[:6]))
+	dbPath := filepath.Join(dir, "db.sqlite")
+	marker := filepath.Join(dir, ".complete")
+	if _, err := os.Stat(marker); err == nil {
+		return dbPath, nil
+	}
+
+	zr, err := zip.OpenReader(src.SnapshotPath)
+	if err != nil {
+		return "", fmt.Errorf("open zip: %w", err)
+	}
+	defer zr.Close()
+
+	var dbFile, walFile *zip.File
+	for _, f := range zr.File {
+		switch f.Name {
+		case entry:
+			dbFile = f
+		case entry + "-wal":
+			walFile = f
+		}
+	}
+	if dbFile == nil || isSidecar(entry) || !isSQLiteEntry(dbFile) {
+		return "", apperr.New(apperr.CodeNotFound, fmt.Sprintf("sqlite entry not found in snapshot: %s", entry))
+	}
+
+	// 重新解压前清理残留（上次解压中断时没有 .complete 标记）。
+	_ = os.RemoveAll(dir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create sqlite cache dir: %w", err)
+	}
+	if err := extractFile(dbFile, dbPath); err != nil {
+		return "", err
+	}
+	if walFile != nil {
+		if err := extractFile(walFile, dbPath+"-wal"); err != nil {
+			return "", err
+		}
+	}
+	if err := os.WriteFile(marker, []byte(entry), 0o644); err != nil {
+		return "", fmt.Errorf("write sqlite cache marker: %w", err)
+	}
+	return dbPath, nil
+}
+
+func extractFile(f *zip.File, dst string) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("open zip entry %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", dst, err)
+	}
+	// archive/zip 在读到末尾时校验 CRC32，损坏的条目会在这里报错。
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return fmt.Errorf("extract zip entry %s: %w", f.Name, err)
+	}
+	return out.Close()
+}
+
+func isSQLiteEntry(f *zip.File) bool {
+	rc, err := f.Open()
+	if err != nil {
+		return false
+	}
+	defer rc.Close()
+	head := make([]byte, 16)
+	n, _ := io.ReadFull(rc, head)
+	return filetype.Detect(head[:n], f.Name) == filetype.MimeSQLite
+}
+
+func isSidecar(name string) bool {
+	for _, s := range []string{"-wal", "-shm", "-journal"} {
+		if strings.HasSuffix(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonValue 把 SQLite 值转换为 JSON 友好的形式：
+// 文本型 BLOB 转字符串，二进制 BLOB 返回截断的 hex 与长度。
+func jsonValue(v any) any {
+	b, ok := v.([]byte)
+	if !ok {
+		return v
+	}
+	if utf8.Valid(b) {
+		return string(b)
+	}
+	head := b
+	if len(head) > maxBlobPreview {
+		head = head[:maxBlobPreview]
+	}
+	return map[string]any{
+		"blob_hex":  hex.EncodeToString(head),
+		"blob_size": len(b),
+	}
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
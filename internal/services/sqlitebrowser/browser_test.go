@@ -0,0 +1,96 @@
+package sqlitebrowser
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"crypto-inspector/internal/domain/apperr"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestBrowser_ZipSnapshotWithWAL(t *testing.T) {
+	ctx := context.Background()
+	tmp := t.TempDir()
+
+	// 构造 WAL 模式的 History：数据只在 -wal 中（不 checkpoint），验证解压副本能读到。
+	dbPath := filepath.Join(tmp, "History")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	for _, stmt := range []string{
+		`PRAGMA journal_mode = WAL`,
+		`PRAGMA wal_autocheckpoint = 0`,
+		`CREATE TABLE urls(id INTEGER PRIMARY KEY, url TEXT, favicon BLOB)`,
+		`INSERT INTO urls(url, favicon) VALUES('https://a.example', x'00ff'), ('https://b.example', NULL), ('https://c.example', NULL)`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	zipPath := filepath.Join(tmp, "browser_history_db.zip")
+	zf, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("create zip: %v", err)
+	}
+	zw := zip.NewWriter(zf)
+	for _, name := range []string{"History", "History-wal"} {
+		raw, err := os.ReadFile(filepath.Join(tmp, name))
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		w, _ := zw.Create(name)
+		_, _ = w.Write(raw)
+	}
+	zw.Close()
+	zf.Close()
+
+	b := New(filepath.Join(tmp, "cache"))
+	src := Source{SnapshotPath: zipPath, SHA256: "abcdef0123456789abcdef"}
+
+	dbs, err := b.Databases(src)
+	if err != nil {
+		t.Fatalf("databases: %v", err)
+	}
+	if len(dbs) != 1 || dbs[0].Entry != "History" || !dbs[0].HasWAL {
+		t.Fatalf("unexpected databases: %+v", dbs)
+	}
+
+	sum, err := b.Tables(ctx, src, "History")
+	if err != nil {
+		t.Fatalf("tables: %v", err)
+	}
+	if len(sum.Tables) != 1 || sum.Tables[0].Name != "urls" || sum.Tables[0].Rows != 3 {
+		t.Fatalf("unexpected tables: %+v", sum.Tables)
+	}
+
+	page, err := b.Rows(ctx, src, "History", RowQuery{Table: "urls", Limit: 2, Offset: 1, OrderBy: "id", Desc: true})
+	if err != nil {
+		t.Fatalf("rows: %v", err)
+	}
+	if page.Total != 3 || len(page.Rows) != 2 || page.Rows[0][1] != "https://b.example" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	page, err = b.Rows(ctx, src, "History", RowQuery{Table: "urls", OrderBy: "id", Limit: 1})
+	if err != nil {
+		t.Fatalf("rows: %v", err)
+	}
+	if blob, ok := page.Rows[0][2].(map[string]any); !ok || blob["blob_hex"] != "00ff" {
+		t.Fatalf("binary blob should be hex encoded: %#v", page.Rows[0][2])
+	}
+
+	if _, err := b.Rows(ctx, src, "History", RowQuery{Table: `urls"; DROP TABLE urls; --`}); apperr.CodeOf(err) != apperr.CodeNotFound {
+		t.Fatalf("unknown table: want ERR_NOT_FOUND, got %v", err)
+	}
+	if _, err := b.Rows(ctx, src, "History", RowQuery{Table: "urls", OrderBy: "nope"}); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("unknown column: want ERR_INVALID_ARGUMENT, got %v", err)
+	}
+}
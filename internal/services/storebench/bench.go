@@ -0,0 +1,521 @@
+package storebench
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+
+	_ "modernc.org/sqlite"
+)
+
+// 存储层基准测试
+//
+// 按给定规模（浏览记录条数、命中条数）向全新的 SQLite 库灌入数据，分阶段统计写入、分页查询与导出读取的耗时，
+// 并在多组 SQLite 设置（journal_mode / synchronous / 连接数）之间对比。
+// 用于评估对性能敏感的改动（例如单连接约束、索引、payload 外置）前后的差异；数据为固定种子生成，结果可复现。
+
+// Setting 是一组 SQLite 连接设置。
+type Setting struct {
+	Name         string `json:"name"`
+	JournalMode  string `json:"journal_mode"` // delete|wal
+	Synchronous  string `json:"synchronous"`  // full|normal|off
+	MaxOpenConns int    `json:"max_open_conns"`
+}
+
+// 预置设置。default 与 webapp/CLI 实际使用的配置一致（单连接、SQLite 默认日志模式）。
+var presets = []Setting{
+	{Name: "default", JournalMode: "delete", Synchronous: "full", MaxOpenConns: 1},
+	{Name: "wal", JournalMode: "wal", Synchronous: "normal", MaxOpenConns: 1},
+	{Name: "wal_pool4", JournalMode: "wal", Synchronous: "normal", MaxOpenConns: 4},
+}
+
+// Presets 返回全部预置设置。
+func Presets() []Setting {
+	return append([]Setting(nil), presets...)
+}
+
+// ParseSettings 按逗号分隔的预置名称解析设置（空为 default）。
+func ParseSettings(names string) ([]Setting, error) {
+	if strings.TrimSpace(names) == "" {
+		return []Setting{presets[0]}, nil
+	}
+	var out []Setting
+	for _, n := range strings.Split(names, ",") {
+		n = strings.ToLower(strings.TrimSpace(n))
+		if n == "" {
+			continue
+		}
+		found := false
+		for _, p := range presets {
+			if p.Name == n {
+				out = append(out, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unknown bench setting %q (default|wal|wal_pool4)", n))
+		}
+	}
+	return out, nil
+}
+
+// Options 是基准参数。
+type Options struct {
+	// Dir 存放基准数据库（每组设置一个文件）；为空时使用临时目录并在结束后删除。
+	Dir string
+	// Keep 为 true 时保留基准数据库文件（仅 Dir 非空时生效）。
+	Keep bool
+
+	Visits            int // 浏览记录总条数
+	Hits              int // 命中总条数
+	VisitsPerArtifact int // 每条 browser_history 证据包含的浏览记录数
+	QueryRepeat       int // 每个查询阶段的重复次数（统计 p50/p95）
+	Settings          []Setting
+}
+
+// 默认规模：100 万条浏览记录、10 万条命中。
+const (
+	DefaultVisits            = 1000000
+	DefaultHits              = 100000
+	DefaultVisitsPerArtifact = 10000
+	DefaultQueryRepeat       = 20
+
+	hitBatch = 1000
+	caseID   = "case_bench"
+)
+
+// Phase 是一个阶段的耗时统计（毫秒）。
+type Phase struct {
+	Name       string  `json:"name"`
+	Ops        int     `json:"ops"`
+	Rows       int     `json:"rows"`
+	TotalMS    float64 `json:"total_ms"`
+	P50MS      float64 `json:"p50_ms"`
+	P95MS      float64 `json:"p95_ms"`
+	MaxMS      float64 `json:"max_ms"`
+	RowsPerSec float64 `json:"rows_per_sec"`
+}
+
+// SettingResult 是一组设置的全部阶段结果。
+type SettingResult struct {
+	Setting Setting `json:"setting"`
+	DBPath  string  `json:"db_path,omitempty"`
+	DBBytes int64   `json:"db_bytes"`
+	Phases  []Phase `json:"phases"`
+}
+
+// Report 是一次基准运行的结果。
+type Report struct {
+	Visits    int             `json:"visits"`
+	Hits      int             `json:"hits"`
+	Artifacts int             `json:"artifacts"`
+	StartedAt int64           `json:"started_at"`
+	Results   []SettingResult `json:"results"`
+}
+
+// Run 依次在每组设置下执行基准。
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if opts.Visits <= 0 {
+		opts.Visits = DefaultVisits
+	}
+	if opts.Hits < 0 {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "hits must be >= 0")
+	}
+	if opts.VisitsPerArtifact <= 0 {
+		opts.VisitsPerArtifact = DefaultVisitsPerArtifact
+	}
+	if opts.QueryRepeat <= 0 {
+		opts.QueryRepeat = DefaultQueryRepeat
+	}
+	if len(opts.Settings) == 0 {
+		opts.Settings = []Setting{presets[0]}
+	}
+	dir := opts.Dir
+	keep := opts.Keep
+	if dir == "" {
+		tmp, err := os.MkdirTemp("", "inspector-bench-*")
+		if err != nil {
+			return nil, fmt.Errorf("create bench dir: %w", err)
+		}
+		dir, keep = tmp, false
+		defer os.RemoveAll(tmp)
+	} else if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create bench dir: %w", err)
+	}
+
+	rep := &Report{
+		Visits:    opts.Visits,
+		Hits:      opts.Hits,
+		Artifacts: (opts.Visits + opts.VisitsPerArtifact - 1) / opts.VisitsPerArtifact,
+		StartedAt: time.Now().Unix(),
+	}
+	for _, st := range opts.Settings {
+		dbPath := filepath.Join(dir, fmt.Sprintf("bench_%s.db", st.Name))
+		res, err := runSetting(ctx, dbPath, st, opts)
+		if err != nil {
+			return nil, fmt.Errorf("bench %s: %w", st.Name, err)
+		}
+		if keep {
+			res.DBPath = dbPath
+		} else {
+			removeDB(dbPath)
+		}
+		rep.Results = append(rep.Results, *res)
+	}
+	return rep, nil
+}
+
+// Open 按设置打开（并迁移）一个 SQLite 库；基准与 Go benchmark 共用。
+func Open(ctx context.Context, dbPath string, st Setting) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	conns := st.MaxOpenConns
+	if conns <= 0 {
+		conns = 1
+	}
+	db.SetMaxOpenConns(conns)
+	pragmas := []string{`PRAGMA busy_timeout = 5000`}
+	if st.JournalMode != "" {
+		pragmas = append(pragmas, "PRAGMA journal_mode = "+st.JournalMode)
+	}
+	if st.Synchronous != "" {
+		pragmas = append(pragmas, "PRAGMA synchronous = "+st.Synchronous)
+	}
+	for _, p := range pragmas {
+		if _, err := db.ExecContext(ctx, p); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("apply migrations: %w", err)
+	}
+	return db, nil
+}
+
+func runSetting(ctx context.Context, dbPath string, st Setting, opts Options) (*SettingResult, error) {
+	removeDB(dbPath)
+	res := &SettingResult{Setting: st}
+
+	start := time.Now()
+	db, err := Open(ctx, dbPath, st)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	res.Phases = append(res.Phases, summarize("migrate", 0, []time.Duration{time.Since(start)}))
+
+	store := sqliteadapter.NewStore(db)
+	gen := NewGenerator(1)
+	if err := gen.SeedCase(ctx, store); err != nil {
+		return nil, err
+	}
+
+	// 写入证据（浏览历史，每条证据一个事务）。
+	var artifactIDs []string
+	var durs []time.Duration
+	for written := 0; written < opts.Visits; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n := opts.VisitsPerArtifact
+		if rest := opts.Visits - written; rest < n {
+			n = rest
+		}
+		a := gen.HistoryArtifact(n)
+		t := time.Now()
+		if err := store.SaveArtifacts(ctx, []model.Artifact{a}); err != nil {
+			return nil, err
+		}
+		durs = append(durs, time.Since(t))
+		artifactIDs = append(artifactIDs, a.ID)
+		written += n
+	}
+	res.Phases = append(res.Phases, summarize("insert_artifacts", opts.Visits, durs))
+
+	// 写入命中（每批一个事务）。
+	durs = nil
+	for written := 0; written < opts.Hits; {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n := hitBatch
+		if rest := opts.Hits - written; rest < n {
+			n = rest
+		}
+		hits := gen.Hits(n, artifactIDs)
+		t := time.Now()
+		if err := store.SaveRuleHits(ctx, hits); err != nil {
+			return nil, err
+		}
+		durs = append(durs, time.Since(t))
+		written += n
+	}
+	res.Phases = append(res.Phases, summarize("insert_hits", opts.Hits, durs))
+
+	queries := []struct {
+		name string
+		fn   func() (int, error)
+	}{
+		{"query_hits_first_page", func() (int, error) {
+			rows, _, err := store.QueryCaseHits(ctx, caseID, model.HitQuery{Page: model.Page{Limit: 200}})
+			return len(rows), err
+		}},
+		{"query_hits_filtered", func() (int, error) {
+			rows, _, err := store.QueryCaseHits(ctx, caseID, model.HitQuery{Page: model.Page{Limit: 200}, Verdict: "confirmed", MinConfidence: 0.9})
+			return len(rows), err
+		}},
+		{"query_hits_cursor_10_pages", func() (int, error) {
+			total, cursor := 0, ""
+			for i := 0; i < 10; i++ {
+				rows, next, err := store.QueryCaseHits(ctx, caseID, model.HitQuery{Page: model.Page{Limit: 200, Cursor: cursor}})
+				if err != nil {
+					return total, err
+				}
+				total += len(rows)
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+			return total, nil
+		}},
+		{"query_artifacts_page", func() (int, error) {
+			rows, _, err := store.QueryArtifacts(ctx, caseID, model.ArtifactQuery{Page: model.Page{Limit: 100}})
+			return len(rows), err
+		}},
+	}
+	for _, q := range queries {
+		p, err := repeat(ctx, q.name, opts.QueryRepeat, q.fn)
+		if err != nil {
+			return nil, err
+		}
+		res.Phases = append(res.Phases, p)
+	}
+
+	// 导出读取：全量命中明细 + 全部浏览历史 payload（导出与重新匹配走的路径），各执行一次。
+	exports := []struct {
+		name string
+		fn   func() (int, error)
+	}{
+		{"export_hit_details", func() (int, error) {
+			rows, err := store.ListCaseHitDetails(ctx, caseID, "")
+			if err != nil {
+				return 0, err
+			}
+			return len(rows), json.NewEncoder(io.Discard).Encode(rows)
+		}},
+		{"export_history_payloads", func() (int, error) {
+			payloads, err := store.ListArtifactPayloadsByType(ctx, caseID, string(model.ArtifactBrowserHistory))
+			if err != nil {
+				return 0, err
+			}
+			total := 0
+			for _, raw := range payloads {
+				var visits []model.VisitRecord
+				if err := json.Unmarshal(raw, &visits); err != nil {
+					return total, err
+				}
+				total += len(visits)
+			}
+			return total, nil
+		}},
+	}
+	for _, e := range exports {
+		p, err := repeat(ctx, e.name, 1, e.fn)
+		if err != nil {
+			return nil, err
+		}
+		res.Phases = append(res.Phases, p)
+	}
+
+	// WAL 模式先把日志合并回主库，库文件大小才可比。
+	if st.JournalMode == "wal" {
+		if _, err := db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+			return nil, fmt.Errorf("checkpoint: %w", err)
+		}
+	}
+	if fi, err := os.Stat(dbPath); err == nil {
+		res.DBBytes = fi.Size()
+	}
+	return res, nil
+}
+
+func repeat(ctx context.Context, name string, n int, fn func() (int, error)) (Phase, error) {
+	durs := make([]time.Duration, 0, n)
+	rows := 0
+	for i := 0; i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			return Phase{}, err
+		}
+		t := time.Now()
+		got, err := fn()
+		if err != nil {
+			return Phase{}, fmt.Errorf("%s: %w", name, err)
+		}
+		durs = append(durs, time.Since(t))
+		rows += got
+	}
+	return summarize(name, rows, durs), nil
+}
+
+// summarize 汇总一个阶段的耗时分布。
+func summarize(name string, rows int, durs []time.Duration) Phase {
+	p := Phase{Name: name, Ops: len(durs), Rows: rows}
+	if len(durs) == 0 {
+		return p
+	}
+	var total time.Duration
+	sorted := append([]time.Duration(nil), durs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for _, d := range sorted {
+		total += d
+	}
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	p.TotalMS = ms(total)
+	p.P50MS = ms(sorted[(len(sorted)-1)/2])
+	p.P95MS = ms(sorted[(len(sorted)*95+99)/100-1])
+	p.MaxMS = ms(sorted[len(sorted)-1])
+	if total > 0 && rows > 0 {
+		p.RowsPerSec = float64(rows) / total.Seconds()
+	}
+	return p
+}
+
+func removeDB(dbPath string) {
+	for _, suffix := range []string{"", "-wal", "-shm", "-journal"} {
+		_ = os.Remove(dbPath + suffix)
+	}
+}
+
+// Generator 以固定种子生成基准数据（案件、浏览历史证据、命中）。
+type Generator struct {
+	rng     *rand.Rand
+	counter int
+	base    int64
+}
+
+// NewGenerator 创建生成器；相同 seed 生成相同数据。
+func NewGenerator(seed int64) *Generator {
+	return &Generator{rng: rand.New(rand.NewSource(seed)), base: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix()}
+}
+
+var benchDomains = []string{
+	"www.binance.com", "www.okx.com", "etherscan.io", "github.com", "www.baidu.com",
+	"news.qq.com", "www.bilibili.com", "mail.163.com", "www.zhihu.com", "www.coinbase.com",
+}
+
+// SeedCase 创建基准案件与设备。
+func (g *Generator) SeedCase(ctx context.Context, store *sqliteadapter.Store) error {
+	if _, err := store.EnsureCase(ctx, caseID, "", "store bench", "bench", "synthetic load for store benchmarks"); err != nil {
+		return err
+	}
+	return store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_bench", Name: "bench", OS: model.OSWindows, Identifier: "bench"}, true, "")
+}
+
+func (g *Generator) nextID(prefix string) string {
+	g.counter++
+	return fmt.Sprintf("%s_bench_%09d", prefix, g.counter)
+}
+
+// HistoryArtifact 生成一条包含 n 条浏览记录的 browser_history 证据（快照路径为占位，不落盘）。
+func (g *Generator) HistoryArtifact(n int) model.Artifact {
+	visits := make([]model.VisitRecord, n)
+	for i := range visits {
+		d := benchDomains[g.rng.Intn(len(benchDomains))]
+		visits[i] = model.VisitRecord{
+			Browser:   "chrome",
+			Profile:   "Default",
+			URL:       fmt.Sprintf("https://%s/page/%d?ref=%x", d, g.rng.Intn(100000), g.rng.Uint32()),
+			Domain:    d,
+			Title:     d,
+			VisitedAt: g.base + g.rng.Int63n(365*86400),
+		}
+	}
+	raw, _ := json.MarshalIndent(visits, "", "  ")
+	id := g.nextID("art")
+	sum := hash.Bytes(raw)
+	return model.Artifact{
+		ID:                id,
+		CaseID:            caseID,
+		DeviceID:          "dev_bench",
+		Type:              model.ArtifactBrowserHistory,
+		SourceRef:         "bench",
+		SnapshotPath:      filepath.Join("bench", id+".json"),
+		SHA256:            sum,
+		SizeBytes:         int64(len(raw)),
+		CollectedAt:       g.base,
+		CollectorName:     "store_bench",
+		CollectorVersion:  "0.1.0",
+		ParserVersion:     "0.1.0",
+		AcquisitionMethod: "synthetic",
+		PayloadJSON:       raw,
+		RecordHash:        hash.Text(id, sum),
+	}
+}
+
+// Hits 生成 n 条命中，每条随机关联 1~3 条证据。
+func (g *Generator) Hits(n int, artifactIDs []string) []model.RuleHit {
+	types := []model.HitType{model.HitExchangeVisited, model.HitWalletAddress, model.HitWalletInstalled}
+	out := make([]model.RuleHit, n)
+	for i := range out {
+		id := g.nextID("hit")
+		conf := 0.5 + float64(g.rng.Intn(50))/100
+		verdict := "suspected"
+		if conf >= 0.85 {
+			verdict = "confirmed"
+		}
+		first := g.base + g.rng.Int63n(365*86400)
+		var arts []string
+		for k := 0; k < 1+g.rng.Intn(3) && len(artifactIDs) > 0; k++ {
+			arts = append(arts, artifactIDs[g.rng.Intn(len(artifactIDs))])
+		}
+		out[i] = model.RuleHit{
+			ID:           id,
+			CaseID:       caseID,
+			DeviceID:     "dev_bench",
+			Type:         types[g.rng.Intn(len(types))],
+			RuleID:       fmt.Sprintf("rule_%02d", g.rng.Intn(40)),
+			RuleName:     "bench rule",
+			RuleVersion:  "bench",
+			MatchedValue: id,
+			FirstSeenAt:  first,
+			LastSeenAt:   first + g.rng.Int63n(30*86400),
+			Confidence:   conf,
+			Verdict:      verdict,
+			DetailJSON:   []byte(`{"bench":true}`),
+			ArtifactIDs:  dedupe(arts),
+		}
+	}
+	return out
+}
+
+func dedupe(items []string) []string {
+	seen := map[string]struct{}{}
+	out := items[:0]
+	for _, it := range items {
+		if _, ok := seen[it]; ok {
+			continue
+		}
+		seen[it] = struct{}{}
+		out = append(out, it)
+	}
+	return out
+}
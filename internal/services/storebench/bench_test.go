@@ -0,0 +1,113 @@
+package storebench
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+)
+
+func TestRun_SmallVolumeAllPresets(t *testing.T) {
+	rep, err := Run(context.Background(), Options{
+		Visits:            2500,
+		Hits:              1500,
+		VisitsPerArtifact: 1000,
+		QueryRepeat:       3,
+		Settings:          Presets(),
+	})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if rep.Artifacts != 3 || len(rep.Results) != len(Presets()) {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	for _, r := range rep.Results {
+		rows := map[string]int{}
+		for _, p := range r.Phases {
+			rows[p.Name] = p.Rows
+		}
+		if rows["insert_artifacts"] != 2500 || rows["insert_hits"] != 1500 || rows["export_hit_details"] != 1500 ||
+			rows["export_history_payloads"] != 2500 || rows["query_hits_first_page"] != 3*200 || rows["query_artifacts_page"] != 3*3 {
+			t.Fatalf("%s phases=%+v", r.Setting.Name, r.Phases)
+		}
+		if r.DBBytes <= 0 || r.DBPath != "" {
+			t.Fatalf("%s db bytes=%d path=%q", r.Setting.Name, r.DBBytes, r.DBPath)
+		}
+	}
+
+	if _, err := ParseSettings("default,bogus"); apperr.CodeOf(err) != apperr.CodeInvalidArgument {
+		t.Fatalf("unknown setting err=%v", err)
+	}
+}
+
+// benchStore 按设置打开全新的库并写入 visits 条浏览记录与 hits 条命中。
+func benchStore(b *testing.B, st Setting, visits, hits int) *sqliteadapter.Store {
+	b.Helper()
+	ctx := context.Background()
+	db, err := Open(ctx, filepath.Join(b.TempDir(), "bench.db"), st)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+	store := sqliteadapter.NewStore(db)
+	gen := NewGenerator(1)
+	if err := gen.SeedCase(ctx, store); err != nil {
+		b.Fatal(err)
+	}
+	var ids []string
+	for written := 0; written < visits; written += 1000 {
+		a := gen.HistoryArtifact(1000)
+		if err := store.SaveArtifacts(ctx, []model.Artifact{a}); err != nil {
+			b.Fatal(err)
+		}
+		ids = append(ids, a.ID)
+	}
+	for written := 0; written < hits; written += hitBatch {
+		if err := store.SaveRuleHits(ctx, gen.Hits(hitBatch, ids)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return store
+}
+
+func BenchmarkSaveRuleHits(b *testing.B) {
+	for _, st := range Presets() {
+		b.Run(st.Name, func(b *testing.B) {
+			store := benchStore(b, st, 1000, 0)
+			gen := NewGenerator(2)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := store.SaveRuleHits(context.Background(), gen.Hits(hitBatch, []string{"art_bench_000000001"})); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkQueryCaseHits(b *testing.B) {
+	for _, st := range Presets() {
+		b.Run(st.Name, func(b *testing.B) {
+			store := benchStore(b, st, 10000, 20000)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := store.QueryCaseHits(context.Background(), caseID, model.HitQuery{Page: model.Page{Limit: 200}, Verdict: "confirmed"}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkListCaseHitDetails(b *testing.B) {
+	store := benchStore(b, presets[0], 10000, 20000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.ListCaseHitDetails(context.Background(), caseID, ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package training
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/hostscan"
+
+	_ "modernc.org/sqlite"
+)
+
+// 演练模式（操作员培训）
+//
+// 演练使用独立的数据库与证据目录（默认 data/training/），库内写入 schema_meta.training_mode=1，
+// 之后任何命令打开该库生成的报告/导出都带“演练/TRAINING”水印（页眉、PDF 斜向水印、文件名前缀 TRAINING_、
+// 清单 organization.training 与 ZIP 注释）。扫描走完整的离线采集流程，但数据源是本包内置的合成 macOS 目录
+// （钱包应用、钱包扩展与交易所浏览记录），不触碰本机或真实设备。
+
+const (
+	// DefaultDBPath 是演练库默认路径（与正式库 data/inspector.db 分开）。
+	DefaultDBPath = "data/training/inspector.db"
+	// DefaultEvidenceRoot 是演练证据默认目录。
+	DefaultEvidenceRoot = "data/training/evidence"
+	// DeviceName 是合成数据源的设备显示名。
+	DeviceName = "TRAINING-MacBook"
+)
+
+// SourcesDir 返回演练库对应的合成数据源目录（与库文件同级）。
+func SourcesDir(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), "training_sources")
+}
+
+// Prepare 创建并迁移演练库，写入演练标记；dbPath 指向已有案件的正式库时拒绝。
+func Prepare(ctx context.Context, dbPath string) error {
+	if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return fmt.Errorf("create training db directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return fmt.Errorf("open sqlite: %w", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if _, err := db.ExecContext(ctx, `PRAGMA busy_timeout = 5000`); err != nil {
+		return fmt.Errorf("set busy_timeout: %w", err)
+	}
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		return fmt.Errorf("apply migrations: %w", err)
+	}
+	return sqliteadapter.NewStore(db).MarkTrainingDB(ctx)
+}
+
+// Options 是一次演练扫描的参数。
+type Options struct {
+	DBPath           string
+	EvidenceRoot     string
+	WalletRulePath   string
+	ExchangeRulePath string
+	RegexRulePath    string
+	CaseID           string
+	Operator         string
+	Note             string
+}
+
+// Scan 准备演练库、生成合成数据源并执行一次完整的离线主机扫描。
+func Scan(ctx context.Context, opts Options) (*hostscan.Result, error) {
+	if strings.TrimSpace(opts.DBPath) == "" {
+		opts.DBPath = DefaultDBPath
+	}
+	if strings.TrimSpace(opts.EvidenceRoot) == "" {
+		opts.EvidenceRoot = DefaultEvidenceRoot
+	}
+	if err := Prepare(ctx, opts.DBPath); err != nil {
+		return nil, err
+	}
+	src := SourcesDir(opts.DBPath)
+	if err := WriteSources(src); err != nil {
+		return nil, err
+	}
+	return hostscan.Run(ctx, HostScanOptions(hostscan.Options{
+		DBPath:           opts.DBPath,
+		EvidenceRoot:     opts.EvidenceRoot,
+		WalletRulePath:   opts.WalletRulePath,
+		ExchangeRulePath: opts.ExchangeRulePath,
+		RegexRulePath:    opts.RegexRulePath,
+		CaseID:           opts.CaseID,
+		Operator:         opts.Operator,
+		Note:             opts.Note,
+	}, src))
+}
+
+// HostScanOptions 把主机扫描参数改写为对合成数据源的离线扫描（serve --training 的主机扫描任务也走这里）。
+func HostScanOptions(opts hostscan.Options, sourcesDir string) hostscan.Options {
+	opts.OfflineInputDir = sourcesDir
+	opts.OfflineOS = string(model.OSMacOS)
+	opts.DeviceName = DeviceName
+	opts.ParentDeviceID = ""
+	if note := strings.TrimSpace(opts.Note); note == "" {
+		opts.Note = model.TrainingWatermark
+	} else if !strings.Contains(note, model.TrainingWatermark) {
+		opts.Note = model.TrainingWatermark + " " + note
+	}
+	return opts
+}
+
+// 合成数据源内容：两个钱包应用、MetaMask 扩展与交易所/区块浏览器访问记录。
+var (
+	syntheticApps = []struct{ name, bundleID, version string }{
+		{"Exodus", "com.exodus-movement.exodus", "24.1.1"},
+		{"Ledger Live", "com.ledger.live", "2.80.0"},
+	}
+	syntheticExtensions = []struct{ id, name, version string }{
+		{"nkbihfbeogaeaoehlefnkodbefgpgknn", "MetaMask", "11.16.0"},
+	}
+	syntheticVisits = []struct {
+		url, title string
+		unix       int64
+	}{
+		{"https://www.binance.com/en/my/wallet/account/main", "Binance - Wallet", 1767225600},
+		{"https://www.okx.com/account/users", "OKX - Account", 1767229200},
+		{"https://etherscan.io/address/0x742d35Cc6634C0532925a3b844Bc454e4438f44e", "Address 0x742d35Cc | Etherscan", 1767232800},
+	}
+)
+
+// chromiumEpochOffset 是 1601-01-01 到 1970-01-01 的秒数（Chromium visit_time 以 1601 年起的微秒计）。
+const chromiumEpochOffset = 11644473600
+
+// WriteSources 在 dir 下生成合成的 macOS 数据源目录；已存在的文件保持不变，重复调用得到同样的目录摘要。
+func WriteSources(dir string) error {
+	for _, a := range syntheticApps {
+		p := filepath.Join(dir, "Applications", a.name+".app", "Contents", "Info.plist")
+		plist := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>CFBundleName</key><string>%s</string>
+	<key>CFBundleIdentifier</key><string>%s</string>
+	<key>CFBundleShortVersionString</key><string>%s</string>
+</dict>
+</plist>
+`, a.name, a.bundleID, a.version)
+		if err := writeIfMissing(p, []byte(plist)); err != nil {
+			return err
+		}
+	}
+
+	profile := filepath.Join(dir, "Library", "Application Support", "Google", "Chrome", "Default")
+	for _, e := range syntheticExtensions {
+		manifest, _ := json.MarshalIndent(map[string]any{"manifest_version": 3, "name": e.name, "version": e.version}, "", "  ")
+		if err := writeIfMissing(filepath.Join(profile, "Extensions", e.id, e.version+"_0", "manifest.json"), manifest); err != nil {
+			return err
+		}
+	}
+	return writeChromiumHistory(filepath.Join(profile, "History"))
+}
+
+func writeIfMissing(path string, data []byte) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create training source dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write training source: %w", err)
+	}
+	return nil
+}
+
+// writeChromiumHistory 生成只含 urls/visits 两张表的 Chromium History 库。
+func writeChromiumHistory(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create training source dir: %w", err)
+	}
+	// 先写临时文件再改名，中途失败不会留下半成品（下次调用会重新生成）。
+	tmp := path + ".tmp"
+	_ = os.Remove(tmp)
+	if err := fillChromiumHistory(tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write training history: %w", err)
+	}
+	return nil
+}
+
+func fillChromiumHistory(path string) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("create training history: %w", err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		`CREATE TABLE urls (id INTEGER PRIMARY KEY, url TEXT, title TEXT)`,
+		`CREATE TABLE visits (id INTEGER PRIMARY KEY, url INTEGER, visit_time INTEGER)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("create training history: %w", err)
+		}
+	}
+	for i, v := range syntheticVisits {
+		if _, err := db.Exec(`INSERT INTO urls (id, url, title) VALUES (?, ?, ?)`, i+1, v.url, v.title); err != nil {
+			return fmt.Errorf("write training history: %w", err)
+		}
+		if _, err := db.Exec(`INSERT INTO visits (url, visit_time) VALUES (?, ?)`, i+1, (v.unix+chromiumEpochOffset)*1_000_000); err != nil {
+			return fmt.Errorf("write training history: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package training
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/orgprofile"
+)
+
+func TestScanWatermarksTrainingDB(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "training", "inspector.db")
+	res, err := Scan(ctx, Options{
+		DBPath:           dbPath,
+		EvidenceRoot:     filepath.Join(dir, "training", "evidence"),
+		WalletRulePath:   "../../../rules/wallet_signatures.template.yaml",
+		ExchangeRulePath: "../../../rules/exchange_domains.template.yaml",
+		Operator:         "trainee",
+	})
+	if err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if res.DeviceName != DeviceName || res.WalletHits == 0 || res.ExchangeHits == 0 {
+		t.Fatalf("result=%+v", res)
+	}
+	if !strings.HasPrefix(filepath.Base(res.ReportPath), "TRAINING_") {
+		t.Fatalf("report path not watermarked: %s", res.ReportPath)
+	}
+	raw, err := os.ReadFile(res.ReportPath)
+	if err != nil || !strings.Contains(string(raw), model.TrainingWatermark) {
+		t.Fatalf("report content not watermarked: err=%v", err)
+	}
+
+	// 再次扫描复用同一份合成数据源与演练库。
+	if _, err := Scan(ctx, Options{
+		DBPath:           dbPath,
+		EvidenceRoot:     filepath.Join(dir, "training", "evidence"),
+		WalletRulePath:   "../../../rules/wallet_signatures.template.yaml",
+		ExchangeRulePath: "../../../rules/exchange_domains.template.yaml",
+		CaseID:           res.CaseID,
+	}); err != nil {
+		t.Fatalf("second Scan: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	st, err := orgprofile.Issue(ctx, sqliteadapter.NewStore(db))
+	if err != nil || st.Watermark() != model.TrainingWatermark || st.FileName("a.zip") != "TRAINING_a.zip" || st.Fields()["training"] != true {
+		t.Fatalf("stamp=%+v err=%v", st, err)
+	}
+}
+
+func TestPrepareRefusesCaseDB(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "inspector.db")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	if _, err := store.EnsureCase(ctx, "", "", "real case", "op", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := Prepare(ctx, dbPath); err == nil {
+		t.Fatal("Prepare accepted a database with real cases")
+	}
+	if training, err := store.IsTrainingDB(ctx); err != nil || training {
+		t.Fatalf("training=%v err=%v", training, err)
+	}
+}
@@ -0,0 +1,315 @@
+package vmscan
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// guestPaths 是需要从客体系统取出的路径（相对分区根，"/" 分隔，支持 * 通配），
+// 覆盖离线采集器能解析的来源：注册表 hive、Chromium/Firefox profile、Safari 历史、macOS 应用。
+var guestPaths = []string{
+	// Windows
+	"Windows/System32/config/SOFTWARE",
+	"Users/*/NTUSER.DAT",
+	"Users/*/AppData/Local/Google/Chrome/User Data",
+	"Users/*/AppData/Local/Microsoft/Edge/User Data",
+	"Users/*/AppData/Local/BraveSoftware/Brave-Browser/User Data",
+	"Users/*/AppData/Roaming/Mozilla/Firefox/Profiles",
+	// macOS
+	"Users/*/Library/Application Support/Google/Chrome",
+	"Users/*/Library/Application Support/Microsoft Edge",
+	"Users/*/Library/Application Support/Firefox/Profiles",
+	"Users/*/Library/Safari/History.db",
+	"Applications/*.app/Contents/Info.plist",
+}
+
+// skipDirNames 是 profile 中与证据无关、体积较大的缓存目录，拷贝时跳过。
+var skipDirNames = map[string]bool{
+	"Cache":          true,
+	"Code Cache":     true,
+	"GPUCache":       true,
+	"Service Worker": true,
+	"cache2":         true,
+}
+
+// Extractor 以只读方式把镜像中的用户数据目录取出到 dest（dest 之后作为离线扫描输入）。
+type Extractor interface {
+	Name() string
+	Available() bool
+	Extract(ctx context.Context, image, dest string) error
+}
+
+// Extractors 返回内置的取出方式（auto 时按顺序选第一个可用的）：
+// - guestmount：libguestfs 只读挂载（--ro），按 guestPaths 拷贝后卸载
+// - 7z：7-Zip 直接读取镜像中的分区与文件系统（本身不写镜像）
+func Extractors() []Extractor {
+	return []Extractor{guestmountExtractor{}, sevenZipExtractor{}}
+}
+
+// pickExtractor 按名称选择取出方式；name 为空或 auto 时选第一个可用的。
+func pickExtractor(name string, list []Extractor) (Extractor, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	var names []string
+	for _, e := range list {
+		names = append(names, e.Name())
+		if name != "" && name != "auto" && e.Name() != name {
+			continue
+		}
+		if e.Available() {
+			return e, nil
+		}
+		if name != "" && name != "auto" {
+			return nil, fmt.Errorf("image extractor %s is not available on this host", name)
+		}
+	}
+	if name != "" && name != "auto" {
+		return nil, fmt.Errorf("unknown image extractor %q (%s)", name, strings.Join(names, "|"))
+	}
+	return nil, errors.New("no image extractor available (install libguestfs guestmount or 7-Zip)")
+}
+
+type guestmountExtractor struct{}
+
+func (guestmountExtractor) Name() string { return "guestmount" }
+
+func (guestmountExtractor) Available() bool {
+	_, err := exec.LookPath("guestmount")
+	return err == nil
+}
+
+func (guestmountExtractor) Extract(ctx context.Context, image, dest string) error {
+	mnt, err := os.MkdirTemp("", "inspector-vm-mnt-")
+	if err != nil {
+		return fmt.Errorf("create mount point: %w", err)
+	}
+	defer os.Remove(mnt)
+	if err := runTool(ctx, "guestmount", "-a", image, "-i", "--ro", mnt); err != nil {
+		return err
+	}
+	defer func() { _ = exec.Command("guestunmount", mnt).Run() }()
+	return copyGuestPaths(ctx, mnt, dest)
+}
+
+type sevenZipExtractor struct{}
+
+func (sevenZipExtractor) Name() string { return "7z" }
+
+func (sevenZipExtractor) bin() string {
+	for _, b := range []string{"7zz", "7z", "7za"} {
+		if p, err := exec.LookPath(b); err == nil {
+			return p
+		}
+	}
+	return ""
+}
+
+func (e sevenZipExtractor) Available() bool { return e.bin() != "" }
+
+// Extract 先列出镜像内容：镜像中含分区（*.ntfs / *.hfs / *.apfs / *.img ...）时，把最大的分区导出到临时目录，
+// 再从分区中按 guestPaths 取出文件；否则直接从镜像取出。
+func (e sevenZipExtractor) Extract(ctx context.Context, image, dest string) error {
+	bin := e.bin()
+	listing, err := runToolOutput(ctx, bin, "l", "-slt", image)
+	if err != nil {
+		return err
+	}
+	source := image
+	if part, ok := largestPartition(parseSevenZipListing(listing)); ok {
+		tmp, err := os.MkdirTemp("", "inspector-vm-part-")
+		if err != nil {
+			return fmt.Errorf("create partition temp dir: %w", err)
+		}
+		defer os.RemoveAll(tmp)
+		if err := runTool(ctx, bin, "x", "-y", "-o"+tmp, image, part.Path); err != nil {
+			return err
+		}
+		source = filepath.Join(tmp, part.Path)
+	}
+	args := []string{"x", "-y", "-o" + dest, source}
+	for _, p := range guestPaths {
+		args = append(args, filepath.FromSlash(p))
+	}
+	for name := range skipDirNames {
+		args = append(args, "-xr!"+name)
+	}
+	// 7z 退出码 1 表示警告（例如部分路径不存在），视为成功。
+	if err := runTool(ctx, bin, args...); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return err
+		}
+	}
+	return nil
+}
+
+// sevenZipEntry 是 `7z l -slt` 输出中的一项。
+type sevenZipEntry struct {
+	Path string
+	Size int64
+}
+
+// parseSevenZipListing 解析 `7z l -slt` 输出（"----------" 之后按空行分隔的 key = value 块）。
+func parseSevenZipListing(raw string) []sevenZipEntry {
+	var out []sevenZipEntry
+	s := bufio.NewScanner(strings.NewReader(raw))
+	body := false
+	var cur sevenZipEntry
+	flush := func() {
+		if cur.Path != "" {
+			out = append(out, cur)
+		}
+		cur = sevenZipEntry{}
+	}
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if !body {
+			body = strings.HasPrefix(line, "----------")
+			continue
+		}
+		if line == "" {
+			flush()
+			continue
+		}
+		key, value, ok := strings.Cut(line, " = ")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Path":
+			cur.Path = value
+		case "Size":
+			cur.Size, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	flush()
+	return out
+}
+
+// partitionExts 是 7-Zip 把镜像分区展示成的文件扩展名。
+var partitionExts = map[string]bool{".ntfs": true, ".hfs": true, ".hfsx": true, ".apfs": true, ".img": true, ".fat": true, ".ext": true}
+
+func largestPartition(entries []sevenZipEntry) (sevenZipEntry, bool) {
+	var best sevenZipEntry
+	found := false
+	for _, e := range entries {
+		if strings.Contains(e.Path, "/") || strings.Contains(e.Path, `\`) || !partitionExts[strings.ToLower(filepath.Ext(e.Path))] {
+			continue
+		}
+		if !found || e.Size > best.Size {
+			best, found = e, true
+		}
+	}
+	return best, found
+}
+
+// copyGuestPaths 把 root（已挂载的客体文件系统）中匹配 guestPaths 的文件与目录拷到 dest，保持相对路径。
+func copyGuestPaths(ctx context.Context, root, dest string) error {
+	copied := 0
+	for _, p := range guestPaths {
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(p)))
+		if err != nil {
+			continue
+		}
+		for _, m := range matches {
+			rel, err := filepath.Rel(root, m)
+			if err != nil {
+				continue
+			}
+			n, err := copyTree(ctx, m, filepath.Join(dest, rel))
+			if err != nil {
+				return err
+			}
+			copied += n
+		}
+	}
+	if copied == 0 {
+		return errors.New("no browser profiles / registry hives / applications found in image")
+	}
+	return nil
+}
+
+// copyTree 拷贝文件或目录（跳过符号链接与缓存目录），返回拷贝的文件数。
+func copyTree(ctx context.Context, src, dst string) (int, error) {
+	n := 0
+	err := filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if path != src && skipDirNames[d.Name()] {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return nil
+		}
+		target := filepath.Join(dst, rel)
+		if rel == "." {
+			target = dst
+		}
+		if err := copyFile(path, target); err != nil {
+			return err
+		}
+		n++
+		return nil
+	})
+	return n, err
+}
+
+func copyFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return fmt.Errorf("create dir: %w", err)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", src, err)
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("copy %s: %w", src, err)
+	}
+	return out.Close()
+}
+
+func runTool(ctx context.Context, name string, args ...string) error {
+	_, err := runToolOutput(ctx, name, args...)
+	return err
+}
+
+func runToolOutput(ctx context.Context, name string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if len(msg) > 512 {
+			msg = msg[len(msg)-512:]
+		}
+		return "", fmt.Errorf("%s %s: %w: %s", filepath.Base(name), args[0], err, msg)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,204 @@
+package vmscan
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/hostscan"
+
+	_ "modernc.org/sqlite"
+)
+
+// VM 磁盘镜像二次扫描
+//
+// 宿主机扫描只记录发现的镜像（virtualization 证据），本流程在其基础上：
+// 1) 以只读方式（guestmount --ro 或 7-Zip 读取）从镜像中取出浏览器 profile / 注册表 hive / 应用清单到暂存目录
+// 2) 在暂存目录写入 vm_image.json（镜像路径、大小、修改时间、取出方式），随目录摘要一起固定
+// 3) 对暂存目录执行离线扫描，设备登记为宿主机设备的子设备（case_devices.parent_device_id）
+// 离线采集器只支持 Windows / macOS 客体，WSL（Linux）发行版盘记为跳过。
+
+// supportedFormats 是可以取出的镜像格式（Parallels .hdd 为目录型镜像，暂不支持）。
+var supportedFormats = map[string]bool{"vmdk": true, "vdi": true, "vhd": true, "vhdx": true, "qcow2": true}
+
+// Options 定义一次镜像二次扫描的参数。
+type Options struct {
+	// Scan 是离线扫描的公共参数（DB、规则、案件、授权等）；OfflineInputDir/DeviceName/ParentDeviceID 由本流程填写。
+	Scan hostscan.Options
+
+	ImagePath      string
+	ParentDeviceID string // 发现该镜像的宿主机设备（可为空）
+	GuestOS        string // windows|macos，为空时按取出内容推断
+	Extractor      string // auto|guestmount|7z
+}
+
+// Result 是一次镜像二次扫描的结果。
+type Result struct {
+	ImagePath       string           `json:"image_path"`
+	Format          string           `json:"format"`
+	Extractor       string           `json:"extractor"`
+	StagingDir      string           `json:"staging_dir"`
+	ImageSizeBytes  int64            `json:"image_size_bytes"`
+	ImageModifiedAt int64            `json:"image_modified_at"`
+	Scan            *hostscan.Result `json:"scan,omitempty"`
+}
+
+// imageManifest 是写入暂存目录的镜像来源说明。
+type imageManifest struct {
+	ImagePath       string `json:"image_path"`
+	Format          string `json:"format"`
+	SizeBytes       int64  `json:"size_bytes"`
+	ModifiedAt      int64  `json:"modified_at"`
+	Extractor       string `json:"extractor"`
+	ParentDeviceID  string `json:"parent_device_id,omitempty"`
+	ExtractedAt     int64  `json:"extracted_at"`
+	ReadOnlyExtract bool   `json:"read_only_extract"`
+}
+
+// Run 取出单个镜像的用户数据并作为子设备执行离线扫描。
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	if strings.TrimSpace(opts.Scan.CaseID) == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "case id is required for vm image scan")
+	}
+	image := strings.TrimSpace(opts.ImagePath)
+	if image == "" {
+		return nil, apperr.New(apperr.CodeInvalidArgument, "image path is required")
+	}
+	info, err := os.Stat(image)
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "stat vm image")
+	}
+	if info.IsDir() {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("vm image is a directory: %s", image))
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(image)), ".")
+	if !supportedFormats[format] {
+		return nil, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unsupported vm image format: %s (vmdk|vdi|vhd|vhdx|qcow2)", format))
+	}
+	ex, err := pickExtractor(opts.Extractor, Extractors())
+	if err != nil {
+		return nil, apperr.Wrap(apperr.CodeInvalidArgument, err, "select image extractor")
+	}
+
+	name := strings.TrimSuffix(filepath.Base(image), filepath.Ext(image))
+	staging := filepath.Join(opts.Scan.EvidenceRoot, opts.Scan.CaseID, "vm_images", fmt.Sprintf("%s_%d", name, time.Now().Unix()))
+	if err := os.MkdirAll(staging, 0o755); err != nil {
+		return nil, fmt.Errorf("create staging directory: %w", err)
+	}
+	if err := ex.Extract(ctx, image, staging); err != nil {
+		return nil, fmt.Errorf("extract vm image %s via %s: %w", image, ex.Name(), err)
+	}
+	manifest, err := json.MarshalIndent(imageManifest{
+		ImagePath:       image,
+		Format:          format,
+		SizeBytes:       info.Size(),
+		ModifiedAt:      info.ModTime().Unix(),
+		Extractor:       ex.Name(),
+		ParentDeviceID:  opts.ParentDeviceID,
+		ExtractedAt:     time.Now().Unix(),
+		ReadOnlyExtract: true,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal vm image manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(staging, "vm_image.json"), manifest, 0o644); err != nil {
+		return nil, fmt.Errorf("write vm image manifest: %w", err)
+	}
+
+	scanOpts := opts.Scan
+	scanOpts.OfflineInputDir = staging
+	scanOpts.OfflineOS = opts.GuestOS
+	scanOpts.DeviceName = "vm:" + name
+	scanOpts.ParentDeviceID = opts.ParentDeviceID
+	scan, err := hostscan.Run(ctx, scanOpts)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		ImagePath:       image,
+		Format:          format,
+		Extractor:       ex.Name(),
+		StagingDir:      staging,
+		ImageSizeBytes:  info.Size(),
+		ImageModifiedAt: info.ModTime().Unix(),
+		Scan:            scan,
+	}, nil
+}
+
+// ScanDiscovered 对宿主机设备 hostDeviceID 的 virtualization 证据中记录的镜像逐个执行二次扫描。
+// 单个镜像失败不影响其他镜像，失败原因以 warnings 返回。
+func ScanDiscovered(ctx context.Context, opts Options, hostDeviceID string) ([]Result, []string) {
+	images, err := discoveredImages(ctx, opts.Scan.DBPath, opts.Scan.CaseID, hostDeviceID)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("vm image scan skipped: %v", err)}
+	}
+	out := []Result{}
+	var warnings []string
+	for _, rec := range images {
+		if rec.Kind == "wsl_distro" {
+			warnings = append(warnings, fmt.Sprintf("vm image skipped (linux guest not supported by offline collectors): %s", rec.Path))
+			continue
+		}
+		if !supportedFormats[rec.Format] {
+			warnings = append(warnings, fmt.Sprintf("vm image skipped (format %s not supported): %s", rec.Format, rec.Path))
+			continue
+		}
+		one := opts
+		one.ImagePath = rec.Path
+		one.ParentDeviceID = hostDeviceID
+		res, err := Run(ctx, one)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("vm image scan failed: %s: %v", rec.Path, err))
+			continue
+		}
+		out = append(out, *res)
+	}
+	return out, warnings
+}
+
+// discoveredImages 读取案件中该设备 virtualization 证据里的磁盘镜像（按路径去重）。
+func discoveredImages(ctx context.Context, dbPath, caseID, deviceID string) ([]model.VMRecord, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, `PRAGMA busy_timeout = 5000`); err != nil {
+		return nil, fmt.Errorf("set busy_timeout: %w", err)
+	}
+
+	payloads, err := sqliteadapter.NewStore(db).ListArtifactPayloadsWithIDByType(ctx, caseID, string(model.ArtifactVirtualization))
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	var out []model.VMRecord
+	for _, p := range payloads {
+		if deviceID != "" && p.DeviceID != deviceID {
+			continue
+		}
+		var recs []model.VMRecord
+		if err := json.Unmarshal(p.PayloadJSON, &recs); err != nil {
+			continue
+		}
+		for _, r := range recs {
+			if r.Kind != "disk_image" && r.Kind != "wsl_distro" {
+				continue
+			}
+			if _, ok := seen[r.Path]; ok {
+				continue
+			}
+			seen[r.Path] = struct{}{}
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
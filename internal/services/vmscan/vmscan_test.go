@@ -0,0 +1,85 @@
+package vmscan
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLargestPartitionFromSevenZipListing(t *testing.T) {
+	raw := `7-Zip [64] 17.05
+
+Listing archive: win10.vhdx
+
+--
+Path = win10.vhdx
+Type = VHDX
+
+----------
+Path = 0.fat
+Size = 104857600
+
+Path = 1.ntfs
+Size = 64317161472
+
+Path = 1.ntfs/Windows
+Size = 0
+
+Path = 2.img
+Size = 16777216
+`
+	entries := parseSevenZipListing(raw)
+	if len(entries) != 4 {
+		t.Fatalf("entries=%+v", entries)
+	}
+	part, ok := largestPartition(entries)
+	if !ok || part.Path != "1.ntfs" || part.Size != 64317161472 {
+		t.Fatalf("partition=%+v ok=%v", part, ok)
+	}
+	if _, ok := largestPartition(parseSevenZipListing("----------\nPath = Users/a/NTUSER.DAT\nSize = 10\n")); ok {
+		t.Fatalf("plain file must not be treated as partition")
+	}
+}
+
+func TestCopyGuestPathsSkipsCaches(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("Users/alice/NTUSER.DAT", "hive")
+	write("Users/alice/AppData/Local/Google/Chrome/User Data/Default/History", "sqlite")
+	write("Users/alice/AppData/Local/Google/Chrome/User Data/Default/Cache/data_0", "cache")
+	write("Users/alice/Documents/notes.txt", "unrelated")
+
+	dest := t.TempDir()
+	if err := copyGuestPaths(context.Background(), root, dest); err != nil {
+		t.Fatal(err)
+	}
+	for _, rel := range []string{
+		"Users/alice/NTUSER.DAT",
+		"Users/alice/AppData/Local/Google/Chrome/User Data/Default/History",
+	} {
+		if _, err := os.Stat(filepath.Join(dest, filepath.FromSlash(rel))); err != nil {
+			t.Fatalf("missing %s: %v", rel, err)
+		}
+	}
+	for _, rel := range []string{
+		"Users/alice/AppData/Local/Google/Chrome/User Data/Default/Cache/data_0",
+		"Users/alice/Documents/notes.txt",
+	} {
+		if _, err := os.Stat(filepath.Join(dest, filepath.FromSlash(rel))); !os.IsNotExist(err) {
+			t.Fatalf("%s should not be copied (err=%v)", rel, err)
+		}
+	}
+
+	if err := copyGuestPaths(context.Background(), t.TempDir(), t.TempDir()); err == nil {
+		t.Fatalf("empty guest filesystem should return error")
+	}
+}
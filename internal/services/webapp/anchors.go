@@ -0,0 +1,68 @@
+package webapp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/i18n"
+	"crypto-inspector/internal/services/anchor"
+)
+
+// handleCaseAnchors 报告哈希外部锚定：
+// - GET /api/cases/{case_id}/anchors[?report_id=]：锚定是否启用、适配器、端点与已保存的回执
+// - POST /api/cases/{case_id}/anchors：把报告（默认最新 forensic_zip）的 sha256 登记到锚定服务，body 可带 report_id / operator
+//
+// 只向外部发送哈希，不涉及命中原文，partial 隐私模式下不要求 unmask。
+// 未配置 serve --anchor-config 时 POST 返回 400（ERR_INVALID_ARGUMENT）。
+func (s *Server) handleCaseAnchors(w http.ResponseWriter, r *http.Request, caseID string) {
+	switch r.Method {
+	case http.MethodGet:
+		rows, err := s.store.ListEvidenceAnchors(r.Context(), caseID, strings.TrimSpace(r.URL.Query().Get("report_id")))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		out := map[string]any{"enabled": s.anchor != nil, "anchors": rows}
+		if s.anchor != nil {
+			out["adapter"] = s.anchor.Adapter
+			out["endpoint"] = s.anchor.Endpoint
+			out["anchor_on_export"] = s.anchor.AnchorOnExport
+		}
+		writeJSON(w, http.StatusOK, out)
+	case http.MethodPost:
+		var req struct {
+			ReportID string `json:"report_id,omitempty"`
+			Operator string `json:"operator,omitempty"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req) // 允许空 body
+		if s.anchor == nil {
+			writeError(w, http.StatusBadRequest, apperr.T(apperr.CodeInvalidArgument, "anchor.not_configured"))
+			return
+		}
+		row, err := anchor.Anchor(r.Context(), s.store, s.anchor, caseID, req.ReportID, req.Operator)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "anchor": row})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// anchorAfterExport 在导出完成后按 anchor_on_export 自动锚定；失败只返回告警（按请求语言），不影响导出结果。
+func (s *Server) anchorAfterExport(ctx context.Context, caseID, kind, reportID, operator string) (*model.EvidenceAnchor, string) {
+	if !s.anchor.AnchorOnExportKind(kind) {
+		return nil, ""
+	}
+	row, err := anchor.Anchor(ctx, s.store, s.anchor, caseID, reportID, operator)
+	if err != nil {
+		loc := i18n.FromContext(ctx)
+		return nil, loc.T("anchor.export_failed", apperr.Localize(loc, err))
+	}
+	return row, ""
+}
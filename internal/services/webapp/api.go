@@ -1,6 +1,8 @@
 package webapp
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,11 +11,28 @@ import (
 	"strings"
 	"time"
 
+	"crypto-inspector/internal/adapters/host"
+	"crypto-inspector/internal/adapters/rules"
+	"crypto-inspector/internal/domain/apperr"
 	"crypto-inspector/internal/domain/model"
-	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/i18n"
+	"crypto-inspector/internal/platform/snapshot"
+	"crypto-inspector/internal/services/addrcluster"
+	"crypto-inspector/internal/services/artifactpreview"
+	"crypto-inspector/internal/services/artifactverify"
 	"crypto-inspector/internal/services/auditverify"
+	"crypto-inspector/internal/services/checklist"
+	"crypto-inspector/internal/services/correlation"
+	"crypto-inspector/internal/services/exporter"
+	_ "crypto-inspector/internal/services/exporter/builtin"
 	"crypto-inspector/internal/services/forensicexport"
-	"crypto-inspector/internal/services/forensicpdf"
+	"crypto-inspector/internal/services/hitrollup"
+	"crypto-inspector/internal/services/holdings"
+	"crypto-inspector/internal/services/manualhit"
+	"crypto-inspector/internal/services/phishcheck"
+	"crypto-inspector/internal/services/privacy"
+	"crypto-inspector/internal/services/reportdiff"
+	"crypto-inspector/internal/services/xrefverify"
 )
 
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -22,19 +41,42 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]any{
-		"ok":      true,
-		"service": "webapp",
-		"time":    time.Now().Unix(),
+		"ok":               true,
+		"service":          "webapp",
+		"time":             time.Now().Unix(),
+		"full_disk_access": healthFullDiskAccess(host.CheckFullDiskAccess()),
 	})
 }
 
+// healthFullDiskAccess 是健康检查中的 macOS 完全磁盘访问权限摘要。
+// /api/health 无需登录，因此只返回探测名称与状态，不返回本机路径。
+func healthFullDiskAccess(st host.FullDiskAccessStatus) map[string]any {
+	probes := make(map[string]string, len(st.Probes))
+	for _, p := range st.Probes {
+		probes[p.Name] = p.Status
+	}
+	out := map[string]any{
+		"applicable": st.Applicable,
+		"status":     st.Status,
+	}
+	if st.Applicable {
+		out["probes"] = probes
+	}
+	if st.Status == host.FullDiskAccessDenied {
+		out["hint"] = "grant Full Disk Access to the inspector binary (and the terminal that launches it) in System Settings > Privacy & Security, then restart it"
+	}
+	return out
+}
+
 func (s *Server) handleCases(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
 		limit := parseInt(r.URL.Query().Get("limit"), 50)
 		offset := parseInt(r.URL.Query().Get("offset"), 0)
+		// owner=操作员名：只看该操作员当前负责的案件（“我的案件”）。
+		owner := strings.TrimSpace(r.URL.Query().Get("owner"))
 
-		rows, err := s.store.ListCases(r.Context(), limit, offset)
+		rows, err := s.store.ListCases(r.Context(), owner, limit, offset)
 		if err != nil {
 			writeError(w, http.StatusInternalServerError, err)
 			return
@@ -102,12 +144,41 @@ func (s *Server) handleCaseRoutes(w http.ResponseWriter, r *http.Request) {
 	}
 
 	switch action {
+	case "":
+		// PATCH /api/cases/{case_id}
+		s.handleCasePatch(w, r, caseID)
 	case "overview":
 		s.handleCaseOverview(w, r, caseID)
 	case "devices":
+		// /api/cases/{case_id}/devices[/{device_id}[/photos[/{photo_id}/download]]]
+		if len(parts) > 2 {
+			s.handleCaseDevice(w, r, caseID, parts[2:])
+			return
+		}
 		s.handleCaseDevices(w, r, caseID)
 	case "hits":
+		// /api/cases/{case_id}/hits[/manual|/rollup]
+		if len(parts) > 2 && parts[2] == "manual" {
+			s.handleCaseManualHit(w, r, caseID)
+			return
+		}
+		if len(parts) > 2 && parts[2] == "rollup" {
+			s.handleCaseHitRollup(w, r, caseID)
+			return
+		}
 		s.handleCaseHits(w, r, caseID)
+	case "holdings":
+		s.handleCaseHoldings(w, r, caseID)
+	case "address-clusters":
+		s.handleCaseAddressClusters(w, r, caseID)
+	case "addresses":
+		s.handleCaseAddresses(w, r, caseID)
+	case "device-correlation":
+		s.handleCaseDeviceCorrelation(w, r, caseID)
+	case "phishing-check":
+		s.handleCasePhishingCheck(w, r, caseID)
+	case "name-resolutions":
+		s.handleCaseNameResolutions(w, r, caseID)
 	case "chain":
 		// /api/cases/{case_id}/chain/{action}
 		//
@@ -121,28 +192,84 @@ func (s *Server) handleCaseRoutes(w http.ResponseWriter, r *http.Request) {
 		s.handleCaseReports(w, r, caseID)
 	case "report":
 		s.handleCaseReport(w, r, caseID)
+	case "report-diff":
+		s.handleCaseReportDiff(w, r, caseID)
+	case "redactions":
+		// /api/cases/{case_id}/redactions[/{redaction_id}]
+		redactionID := ""
+		if len(parts) > 2 {
+			redactionID = parts[2]
+		}
+		s.handleCaseRedactions(w, r, caseID, redactionID)
+	case "comments":
+		// /api/cases/{case_id}/comments
+		s.handleCaseComments(w, r, caseID)
+	case "watchlist":
+		// /api/cases/{case_id}/watchlist[/{term_id}]
+		termID := ""
+		if len(parts) > 2 {
+			termID = parts[2]
+		}
+		s.handleCaseWatchlist(w, r, caseID, termID)
 	case "exports":
-		// /api/cases/{case_id}/exports/{kind}
+		// /api/cases/{case_id}/exports[/{kind}]
 		//
-		// 目前支持：
-		// - POST /api/cases/{case_id}/exports/forensic-zip
-		// - POST /api/cases/{case_id}/exports/forensic-pdf
+		// - GET：列出已注册的导出格式
+		// - POST /{kind}：按 exporter 注册表分发（forensic-zip / forensic-pdf / disclosure-zip / graph-zip / ...）
 		restParts := []string{}
 		if len(parts) > 2 {
 			restParts = parts[2:]
 		}
 		s.handleCaseExports(w, r, caseID, restParts)
+	case "case-management":
+		// /api/cases/{case_id}/case-management[/push]
+		restParts := []string{}
+		if len(parts) > 2 {
+			restParts = parts[2:]
+		}
+		s.handleCaseManagement(w, r, caseID, restParts)
+	case "anchors":
+		// /api/cases/{case_id}/anchors
+		s.handleCaseAnchors(w, r, caseID)
 	case "verify":
 		// /api/cases/{case_id}/verify/{kind}
 		//
 		// - POST /api/cases/{case_id}/verify/artifacts
+		// - POST /api/cases/{case_id}/verify/audits
+		// - POST /api/cases/{case_id}/verify/xref
 		restParts := []string{}
 		if len(parts) > 2 {
 			restParts = parts[2:]
 		}
 		s.handleCaseVerify(w, r, caseID, restParts)
+	case "imports":
+		s.handleCaseImports(w, r, caseID)
+	case "storage":
+		s.handleCaseStorage(w, r, caseID)
+	case "handover":
+		s.handleCaseHandover(w, r, caseID)
+	case "authorization":
+		// /api/cases/{case_id}/authorization[/{attachment_id}/download]
+		restParts := []string{}
+		if len(parts) > 2 {
+			restParts = parts[2:]
+		}
+		s.handleCaseAuthorization(w, r, caseID, restParts)
 	case "prechecks":
 		s.handleCasePrechecks(w, r, caseID)
+	case "scan-runs":
+		s.handleCaseScanRuns(w, r, caseID)
+	case "download":
+		s.handleCaseDownload(w, r, caseID)
+	case "checklist":
+		// /api/cases/{case_id}/checklist[/{item_id}]
+		itemID := ""
+		if len(parts) > 2 {
+			itemID = parts[2]
+		}
+		s.handleCaseChecklist(w, r, caseID, itemID)
+	case "close":
+		s.handleCaseClose(w, r, caseID)
 	case "audits":
 		s.handleCaseAudits(w, r, caseID)
 	case "artifacts":
@@ -163,6 +290,8 @@ func (s *Server) handleCaseVerify(w http.ResponseWriter, r *http.Request, caseID
 		s.handleCaseVerifyArtifacts(w, r, caseID)
 	case "audits":
 		s.handleCaseVerifyAudits(w, r, caseID)
+	case "xref":
+		s.handleCaseVerifyXref(w, r, caseID)
 	default:
 		w.WriteHeader(http.StatusNotFound)
 	}
@@ -229,11 +358,48 @@ func (s *Server) handleCaseVerifyAudits(w http.ResponseWriter, r *http.Request,
 	})
 }
 
+// handleCaseVerifyXref 命中/证据交叉引用校验：命中引用的证据记录与快照文件是否存在、孤立关联、跨案件关联。
+// 导出前会自动执行同样的检查（有问题时拒绝导出，ERR_EVIDENCE_INTEGRITY）。
+func (s *Server) handleCaseVerifyXref(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Operator string `json:"operator,omitempty"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	operator := strings.TrimSpace(req.Operator)
+	if operator == "" {
+		operator = "system"
+	}
+
+	res, err := xrefverify.VerifyCase(r.Context(), s.store, caseID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	status := "success"
+	if !res.OK {
+		status = "failed"
+	}
+	_ = s.store.AppendAudit(r.Context(), caseID, "", "verify", "hit_evidence_xref", status, operator, "webapp.handleCaseVerifyXref", map[string]any{
+		"hits":      res.Hits,
+		"links":     res.Links,
+		"artifacts": res.Artifacts,
+		"failed":    res.Failed,
+		"warnings":  len(res.Warnings),
+	})
+	writeJSON(w, http.StatusOK, res.Localize(i18n.FromContext(r.Context())))
+}
+
 // handleCaseVerifyArtifacts 对案件下的证据快照进行 sha256 复核：
-// - 复算 snapshot_path 文件 sha256
+// - 复算 snapshot_path 文件 sha256（worker pool 并行）
 // - 对比入库 sha256/size_bytes
 // - 输出 ok/mismatch/missing/error 明细
 //
+// 请求体 async=true 时转为后台 job，立即返回 job_id，进度（files/sec、ETA）通过 GET /api/jobs/{job_id}
+// 的 verify_progress 字段轮询；resume=true 时沿用上次中断留下的续跑标记。
 // 该接口用于内测阶段快速发现“证据目录被清理/被改动”的情况。
 func (s *Server) handleCaseVerifyArtifacts(w http.ResponseWriter, r *http.Request, caseID string) {
 	if r.Method != http.MethodPost {
@@ -245,6 +411,9 @@ func (s *Server) handleCaseVerifyArtifacts(w http.ResponseWriter, r *http.Reques
 		Operator   string `json:"operator,omitempty"`
 		ArtifactID string `json:"artifact_id,omitempty"`
 		Note       string `json:"note,omitempty"`
+		Workers    int    `json:"workers,omitempty"`
+		Resume     bool   `json:"resume,omitempty"`
+		Async      bool   `json:"async,omitempty"`
 	}
 	var req reqBody
 	_ = json.NewDecoder(r.Body).Decode(&req)
@@ -255,19 +424,9 @@ func (s *Server) handleCaseVerifyArtifacts(w http.ResponseWriter, r *http.Reques
 	}
 	artifactID := strings.TrimSpace(req.ArtifactID)
 
-	type item struct {
-		ArtifactID     string `json:"artifact_id"`
-		SnapshotPath   string `json:"snapshot_path"`
-		ExpectedSHA256 string `json:"expected_sha256"`
-		ActualSHA256   string `json:"actual_sha256,omitempty"`
-		ExpectedSize   int64  `json:"expected_size_bytes"`
-		ActualSize     int64  `json:"actual_size_bytes,omitempty"`
-		Status         string `json:"status"` // ok|mismatch|missing|error
-		Error          string `json:"error,omitempty"`
-	}
-
 	// 构造校验目标
 	var targets []model.ArtifactInfo
+	opts := artifactverify.Options{Workers: req.Workers, Resume: req.Resume}
 	if artifactID != "" {
 		info, err := s.store.GetArtifactInfo(r.Context(), artifactID)
 		if err != nil {
@@ -290,65 +449,54 @@ func (s *Server) handleCaseVerifyArtifacts(w http.ResponseWriter, r *http.Reques
 			return
 		}
 		targets = rows
+		opts.MarkerPath = artifactverify.DefaultMarkerPath(s.opts.DBPath, caseID)
 	}
 
-	out := make([]item, 0, len(targets))
-	okCount := 0
-	mismatchCount := 0
-	missingCount := 0
-	errorCount := 0
-	for _, t := range targets {
-		it := item{
-			ArtifactID:     t.ArtifactID,
-			SnapshotPath:   t.SnapshotPath,
-			ExpectedSHA256: t.SHA256,
-			ExpectedSize:   t.SizeBytes,
+	audit := func(ctx context.Context, res *artifactverify.Result, runErr error) string {
+		status := "success"
+		if runErr != nil || res.Interrupted || res.Failed() > 0 {
+			status = "failed"
 		}
+		_ = s.store.AppendAudit(ctx, caseID, "", "verify", "artifacts_sha256", status, operator, "webapp.handleCaseVerifyArtifacts", map[string]any{
+			"note":            strings.TrimSpace(req.Note),
+			"total":           res.Total,
+			"ok":              res.OKCount,
+			"mismatch":        res.MismatchCount,
+			"missing":         res.MissingCount,
+			"error":           res.ErrorCount,
+			"resumed":         res.ResumedCount,
+			"interrupted":     res.Interrupted,
+			"workers":         res.Workers,
+			"single_artifact": artifactID,
+		})
+		return status
+	}
 
-		sum, size, err := hash.File(t.SnapshotPath)
-		if err != nil {
-			it.Status = "missing"
-			it.Error = err.Error()
-			missingCount++
-			out = append(out, it)
-			continue
-		}
-		it.ActualSHA256 = sum
-		it.ActualSize = size
-		if !strings.EqualFold(strings.TrimSpace(sum), strings.TrimSpace(t.SHA256)) || size != t.SizeBytes {
-			it.Status = "mismatch"
-			mismatchCount++
-			out = append(out, it)
-			continue
-		}
-		it.Status = "ok"
-		okCount++
-		out = append(out, it)
+	if req.Async {
+		s.startVerifyArtifactsJob(w, r, caseID, targets, opts, audit)
+		return
 	}
 
-	status := "success"
-	if mismatchCount > 0 || missingCount > 0 || errorCount > 0 {
-		status = "failed"
+	res, err := artifactverify.Run(r.Context(), targets, opts)
+	if res == nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
 	}
-	_ = s.store.AppendAudit(r.Context(), caseID, "", "verify", "artifacts_sha256", status, operator, "webapp.handleCaseVerifyArtifacts", map[string]any{
-		"note":            strings.TrimSpace(req.Note),
-		"total":           len(out),
-		"ok":              okCount,
-		"mismatch":        mismatchCount,
-		"missing":         missingCount,
-		"error":           errorCount,
-		"single_artifact": artifactID,
-	})
+	status := audit(r.Context(), res, err)
 
 	writeJSON(w, http.StatusOK, map[string]any{
-		"ok":             status == "success",
-		"case_id":        caseID,
-		"total":          len(out),
-		"ok_count":       okCount,
-		"mismatch_count": mismatchCount,
-		"missing_count":  missingCount,
-		"error_count":    errorCount,
-		"results":        out,
+		"ok":              status == "success",
+		"case_id":         caseID,
+		"total":           res.Total,
+		"ok_count":        res.OKCount,
+		"mismatch_count":  res.MismatchCount,
+		"missing_count":   res.MissingCount,
+		"error_count":     res.ErrorCount,
+		"resumed_count":   res.ResumedCount,
+		"interrupted":     res.Interrupted,
+		"workers":         res.Workers,
+		"elapsed_seconds": res.ElapsedSec,
+		"results":         res.Items,
 	})
 }
 
@@ -379,6 +527,12 @@ func (s *Server) handleCaseOverview(w http.ResponseWriter, r *http.Request, case
 		writeError(w, http.StatusNotFound, fmt.Errorf("case not found: %s", caseID))
 		return
 	}
+	items, err := checklist.Get(r.Context(), s.store, caseID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	ov.Checklist = items
 	writeJSON(w, http.StatusOK, ov)
 }
 
@@ -387,13 +541,328 @@ func (s *Server) handleCaseHits(w http.ResponseWriter, r *http.Request, caseID s
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	hitType := strings.TrimSpace(r.URL.Query().Get("hit_type"))
-	rows, err := s.store.ListCaseHitDetails(r.Context(), caseID, hitType)
+	q, err := parseHitQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	groupBy := strings.TrimSpace(r.URL.Query().Get("group_by"))
+	if groupBy != "" && groupBy != "wallet_category" {
+		writeError(w, http.StatusBadRequest, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid group_by: %q (want wallet_category)", groupBy)))
+		return
+	}
+	masked, handled := s.maskedView(w, r, caseID, "hits")
+	if handled {
+		return
+	}
+	rows, next, err := s.store.QueryCaseHits(r.Context(), caseID, q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if masked {
+		rows = privacy.MaskHitDetails(rows)
+	}
+	resp := map[string]any{"hits": rows, "next_cursor": next, "masked": masked}
+	if groupBy == "wallet_category" {
+		// 分组覆盖全部符合筛选条件的命中（不受分页影响），hits 仍为当前页。
+		all := q
+		all.Page = model.Page{Sort: q.Sort, Order: q.Order}
+		matched, _, err := s.store.QueryCaseHits(r.Context(), caseID, all)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		resp["wallet_categories"] = hitrollup.ByWalletCategory(matched)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleCaseHitRollup：GET 案件级命中汇总（按规则 + 命中值跨设备合并，可选 hit_type 过滤）。
+func (s *Server) handleCaseHitRollup(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	masked, handled := s.maskedView(w, r, caseID, "hit_rollup")
+	if handled {
+		return
+	}
+	rows, err := hitrollup.ForCase(r.Context(), s.store, caseID, strings.TrimSpace(r.URL.Query().Get("hit_type")))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if masked {
+		rows = privacy.MaskHitRollups(rows)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"rollup": rows, "masked": masked})
+}
+
+// handleCaseHoldings：GET 已识别持有汇总（token_balance 命中按价格来源折算合计；未配置价格来源时 configured=false）。
+// 每次调用都会查询价格来源并写 price_quote 审计。
+func (s *Server) handleCaseHoldings(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	operator := strings.TrimSpace(r.URL.Query().Get("operator"))
+	if operator == "" {
+		operator = "system"
+	}
+	// 界面查看不固化 price_snapshot 证据（报告导出时才固化），避免每次刷新都新增证据。
+	sum, err := holdings.ForCase(r.Context(), s.store, caseID, holdings.Options{Operator: operator, Source: "webapp.handleCaseHoldings"})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"configured": sum != nil, "holdings": sum})
+}
+
+// handleCaseManualHit：POST 人工录入命中（必须填写 justification；附件以 base64 上传并落库为证据）。
+func (s *Server) handleCaseManualHit(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	type reqBody struct {
+		Operator         string  `json:"operator,omitempty"`
+		DeviceID         string  `json:"device_id,omitempty"`
+		HitType          string  `json:"hit_type,omitempty"`
+		Value            string  `json:"value"`
+		Justification    string  `json:"justification"`
+		RuleName         string  `json:"rule_name,omitempty"`
+		Confidence       float64 `json:"confidence,omitempty"`
+		Verdict          string  `json:"verdict,omitempty"`
+		ObservedAt       int64   `json:"observed_at,omitempty"`
+		AttachmentName   string  `json:"attachment_name,omitempty"`
+		AttachmentBase64 string  `json:"attachment_base64,omitempty"`
+	}
+	// base64 膨胀约 4/3，再留一些 JSON 字段余量。
+	r.Body = http.MaxBytesReader(w, r.Body, manualhit.MaxAttachmentBytes/3*4+(1<<20))
+	var req reqBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+		return
+	}
+	var attachment []byte
+	if strings.TrimSpace(req.AttachmentBase64) != "" {
+		b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(req.AttachmentBase64))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid attachment_base64: %w", err))
+			return
+		}
+		attachment = b
+	}
+	res, err := manualhit.Create(r.Context(), s.store, manualhit.Input{
+		CaseID:         caseID,
+		DeviceID:       req.DeviceID,
+		HitType:        req.HitType,
+		Value:          req.Value,
+		Justification:  req.Justification,
+		Operator:       req.Operator,
+		RuleName:       req.RuleName,
+		Confidence:     req.Confidence,
+		Verdict:        req.Verdict,
+		ObservedAt:     req.ObservedAt,
+		AttachmentName: req.AttachmentName,
+		Attachment:     attachment,
+		EvidenceRoot:   s.opts.EvidenceRoot,
+	})
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"hits": rows})
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "hit": res})
+}
+
+// handleCaseAddressClusters：
+// - GET：返回已保存的地址聚类
+// - POST：按案件当前命中/浏览历史重新聚类（覆盖旧结果）
+func (s *Server) handleCaseAddressClusters(w http.ResponseWriter, r *http.Request, caseID string) {
+	switch r.Method {
+	case http.MethodGet:
+		masked, handled := s.maskedView(w, r, caseID, "address_clusters")
+		if handled {
+			return
+		}
+		rows, err := s.store.ListAddressClusters(r.Context(), caseID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if masked {
+			rows = privacy.MaskAddressClusters(rows)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"clusters": rows, "masked": masked})
+	case http.MethodPost:
+		masked, handled := s.maskedView(w, r, caseID, "address_clusters")
+		if handled {
+			return
+		}
+		type reqBody struct {
+			Operator      string `json:"operator,omitempty"`
+			WindowSeconds int    `json:"window_seconds,omitempty"`
+		}
+		var req reqBody
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		operator := strings.TrimSpace(req.Operator)
+		if operator == "" {
+			operator = "system"
+		}
+		rows, err := addrcluster.RunForCase(r.Context(), s.store, caseID, addrcluster.Options{
+			Window: time.Duration(req.WindowSeconds) * time.Second,
+		})
+		if err != nil {
+			_ = s.store.AppendAudit(r.Context(), caseID, "", "analysis", "address_clusters", "failed", operator, "webapp.handleCaseAddressClusters", map[string]any{
+				"error":      err.Error(),
+				"error_code": apperr.CodeOf(err),
+			})
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		_ = s.store.AppendAudit(r.Context(), caseID, "", "analysis", "address_clusters", "success", operator, "webapp.handleCaseAddressClusters", map[string]any{
+			"clusters": len(rows),
+		})
+		if masked {
+			rows = privacy.MaskAddressClusters(rows)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"clusters": rows, "masked": masked})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCaseDeviceCorrelation：
+// - GET：返回最近一次落库的多设备关联分析（没有时 correlation 为 null）
+// - POST：按案件当前命中重新计算，并落库为 analysis 证据
+//
+// partial 模式下两者默认返回脱敏视图（见 privacy.MaskCorrelation），库内证据保留完整结果。
+func (s *Server) handleCaseDeviceCorrelation(w http.ResponseWriter, r *http.Request, caseID string) {
+	switch r.Method {
+	case http.MethodGet:
+		masked, handled := s.maskedView(w, r, caseID, "device_correlation")
+		if handled {
+			return
+		}
+		res, err := correlation.Latest(r.Context(), s.store, caseID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if masked {
+			res = privacy.MaskCorrelation(res)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"correlation": res, "masked": masked})
+	case http.MethodPost:
+		masked, handled := s.maskedView(w, r, caseID, "device_correlation")
+		if handled {
+			return
+		}
+		type reqBody struct {
+			Operator string `json:"operator,omitempty"`
+		}
+		var req reqBody
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		operator := strings.TrimSpace(req.Operator)
+		if operator == "" {
+			operator = "system"
+		}
+		res, err := correlation.RunForCase(r.Context(), s.store, s.opts.EvidenceRoot, caseID, operator)
+		if err != nil {
+			_ = s.store.AppendAudit(r.Context(), caseID, "", "analysis", correlation.AnalysisKind, "failed", operator, "webapp.handleCaseDeviceCorrelation", map[string]any{
+				"error":      err.Error(),
+				"error_code": apperr.CodeOf(err),
+			})
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if masked {
+			res = privacy.MaskCorrelation(res)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"correlation": res, "masked": masked})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCasePhishingCheck：POST 对形近/拼写仿冒交易所域名读取 TLS 证书，证书不属于交易所时输出 phishing_suspected 命中。
+// partial 模式下响应默认脱敏（见 privacy.MaskPhishingCheck）。
+func (s *Server) handleCasePhishingCheck(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	masked, handled := s.maskedView(w, r, caseID, "phishing_check")
+	if handled {
+		return
+	}
+	type reqBody struct {
+		Operator string `json:"operator,omitempty"`
+	}
+	var req reqBody
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	walletPath, exchangePath := s.activeRulePaths(r.Context())
+	loaded, err := s.rulesCache.Load(r.Context(), rules.NewLoader(walletPath, exchangePath))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	res, err := phishcheck.Run(r.Context(), s.store, caseID, phishcheck.Options{
+		Exchange: loaded.Exchange,
+		Operator: req.Operator,
+		Source:   "webapp.handleCasePhishingCheck",
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if masked {
+		res = privacy.MaskPhishingCheck(res)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"phishing": res, "masked": masked})
+}
+
+func (s *Server) handleCaseAddresses(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	masked, handled := s.maskedView(w, r, caseID, "addresses")
+	if handled {
+		return
+	}
+	rows, err := s.store.ListCaseAddresses(r.Context(), caseID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if masked {
+		rows = privacy.MaskCaseAddresses(rows)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"addresses": rows, "masked": masked})
+}
+
+func (s *Server) handleCaseNameResolutions(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	masked, handled := s.maskedView(w, r, caseID, "name_resolutions")
+	if handled {
+		return
+	}
+	rows, err := s.store.ListNameResolutions(r.Context(), caseID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if masked {
+		for i := range rows {
+			rows[i].ResolvedAddress = privacy.MaskAddress(rows[i].ResolvedAddress)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"resolutions": rows, "masked": masked})
 }
 
 func (s *Server) handleCaseReports(w http.ResponseWriter, r *http.Request, caseID string) {
@@ -435,6 +904,11 @@ func (s *Server) handleCaseReport(w http.ResponseWriter, r *http.Request, caseID
 	}
 
 	out := map[string]any{"report": report}
+	if includeContent && s.partialPrivacy() {
+		if _, ok := s.requireUnmask(w, r, caseID, "report_content"); !ok {
+			return
+		}
+	}
 	// 只有文本类报告才允许内联内容。ZIP/PDF 属于二进制产物，只能走 download。
 	if includeContent && (report.ReportType == "internal_json" || report.ReportType == "internal_html") {
 		raw, err := os.ReadFile(report.FilePath)
@@ -454,44 +928,92 @@ func (s *Server) handleCaseReport(w http.ResponseWriter, r *http.Request, caseID
 	writeJSON(w, http.StatusOK, out)
 }
 
+// handleCaseReportDiff 对比两份 internal_json 报告（report_a/report_b 都不传时对比最近两次扫描）。
+func (s *Server) handleCaseReportDiff(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if _, ok := s.requireUnmask(w, r, caseID, "report_diff"); !ok {
+		return
+	}
+	q := r.URL.Query()
+	res, err := reportdiff.Run(r.Context(), s.store, caseID, q.Get("report_a"), q.Get("report_b"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
 // handleCaseExports 负责导出/取证产物生成入口（内测模式先走同步生成，后续可升级为后台任务）。
 func (s *Server) handleCaseExports(w http.ResponseWriter, r *http.Request, caseID string, parts []string) {
 	if len(parts) < 1 {
-		w.WriteHeader(http.StatusNotFound)
+		// GET /api/cases/{case_id}/exports：列出可用导出格式
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"exports": exporter.List()})
 		return
 	}
-	kind := strings.TrimSpace(parts[0])
-
-	switch kind {
-	case "forensic-zip":
-		s.handleCaseExportForensicZip(w, r, caseID)
-	case "forensic-pdf":
-		s.handleCaseExportForensicPDF(w, r, caseID)
-	default:
+	e, ok := exporter.Lookup(parts[0])
+	if !ok {
 		w.WriteHeader(http.StatusNotFound)
+		return
 	}
+	s.handleCaseExport(w, r, caseID, e)
 }
 
-func (s *Server) handleCaseExportForensicZip(w http.ResponseWriter, r *http.Request, caseID string) {
+// handleCaseExport 执行一种已注册的导出格式，并返回报告索引。
+//
+// 响应字段：{file_key}_path / {file_key}_sha256（例如 zip_path、pdf_sha256）+ 格式特有的统计字段。
+func (s *Server) handleCaseExport(w http.ResponseWriter, r *http.Request, caseID string, e exporter.Exporter) {
 	if r.Method != http.MethodPost {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
+	// 浏览器路径不从请求体接收（避免通过 API 指定任意可执行文件），由服务端自动探测或 CRYPTO_INSPECTOR_BROWSER 指定。
+	// async=true 时转为后台 job（大案件生成耗时较长），立即返回 job_id：
+	// 进度见 GET /api/jobs/{job_id} 的 export_progress，POST /api/jobs/{job_id}/cancel 取消。
+	// 同步模式下客户端断开连接同样会取消生成。
 	type reqBody struct {
-		Operator string `json:"operator,omitempty"`
-		Note     string `json:"note,omitempty"`
+		Operator        string `json:"operator,omitempty"`
+		Note            string `json:"note,omitempty"`
+		UIScreenshots   bool   `json:"ui_screenshots,omitempty"`
+		IncludeComments bool   `json:"include_comments,omitempty"`
+		Async           bool   `json:"async,omitempty"`
+		// forensic-pdf 正文列表上限（0 使用默认值，负数不限）。
+		MaxDevices   int `json:"max_devices,omitempty"`
+		MaxPrechecks int `json:"max_prechecks,omitempty"`
+		MaxHits      int `json:"max_hits,omitempty"`
+		MaxArtifacts int `json:"max_artifacts,omitempty"`
+		// forensic-zip / disclosure-zip：非空时生成 AES-256 加密 ZIP（不回显、不落库）。
+		Password string `json:"password,omitempty"`
 	}
 	var req reqBody
 	_ = json.NewDecoder(r.Body).Decode(&req) // 允许空 body
+	if req.Password != "" && !exporter.SupportsEncryption(e) {
+		writeError(w, http.StatusBadRequest, apperr.New(apperr.CodeInvalidArgument, "password is not supported for "+e.Kind()+" exports"))
+		return
+	}
 
+	// partial 模式：导出产物为完整数据，只允许持有 unmask 权限的操作员执行，操作员以权限表为准。
+	grantee, ok := s.requireUnmask(w, r, caseID, "export:"+e.Kind())
+	if !ok {
+		return
+	}
 	operator := strings.TrimSpace(req.Operator)
+	if grantee != "" {
+		operator = grantee
+	}
 	if operator == "" {
 		operator = "system"
 	}
 
 	walletRulePath, exchangeRulePath := s.activeRulePaths(r.Context())
-	res, err := forensicexport.GenerateForensicZip(r.Context(), s.store, forensicexport.ZipOptions{
+	exReq := exporter.Request{
 		CaseID:           caseID,
 		DBPath:           s.opts.DBPath,
 		EvidenceRoot:     s.opts.EvidenceRoot,
@@ -499,100 +1021,220 @@ func (s *Server) handleCaseExportForensicZip(w http.ResponseWriter, r *http.Requ
 		ExchangeRulePath: exchangeRulePath,
 		Operator:         operator,
 		Note:             strings.TrimSpace(req.Note),
-	})
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
+		UIScreenshots:    req.UIScreenshots,
+		IncludeComments:  req.IncludeComments,
+		MaxDevices:       req.MaxDevices,
+		MaxPrechecks:     req.MaxPrechecks,
+		MaxHits:          req.MaxHits,
+		MaxArtifacts:     req.MaxArtifacts,
+		Password:         req.Password,
+	}
+	if req.Async {
+		s.startExportJob(w, r, e, exReq)
 		return
 	}
-
-	info, err := s.store.GetReportByID(r.Context(), res.ReportID)
+	out, err := s.runCaseExport(r.Context(), e, exReq)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-
-	writeJSON(w, http.StatusOK, map[string]any{
-		"ok":         true,
-		"case_id":    caseID,
-		"report_id":  res.ReportID,
-		"zip_path":   res.ZipPath,
-		"zip_sha256": res.ZipSHA256,
-		"warnings":   res.Warnings,
-		"report":     info,
-	})
+	writeJSON(w, http.StatusOK, out)
 }
 
-func (s *Server) handleCaseExportForensicPDF(w http.ResponseWriter, r *http.Request, caseID string) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
+// runCaseExport 执行导出并组装响应（同步接口与导出 job 共用）。
+func (s *Server) runCaseExport(ctx context.Context, e exporter.Exporter, req exporter.Request) (map[string]any, error) {
+	caseID, operator := req.CaseID, req.Operator
+	res, err := e.Export(ctx, s.store, req)
+	if err != nil {
+		return nil, err
 	}
 
-	type reqBody struct {
-		Operator string `json:"operator,omitempty"`
-		Note     string `json:"note,omitempty"`
+	info, err := s.store.GetReportByID(ctx, res.ReportID)
+	if err != nil {
+		return nil, err
 	}
-	var req reqBody
-	_ = json.NewDecoder(r.Body).Decode(&req) // 允许空 body
 
-	operator := strings.TrimSpace(req.Operator)
-	if operator == "" {
-		operator = "system"
+	out := map[string]any{}
+	for k, v := range res.Extra {
+		out[k] = v
+	}
+	out["ok"] = true
+	out["kind"] = e.Kind()
+	out["case_id"] = caseID
+	out["report_id"] = res.ReportID
+	out["report_no"] = res.ReportNo
+	out[res.FileKey+"_path"] = res.Path
+	out[res.FileKey+"_sha256"] = res.SHA256
+	if rec, warning := s.pushAfterExport(ctx, caseID, e.Kind(), res.ReportID, operator); rec != nil {
+		out["case_management"] = rec
+	} else if warning != "" {
+		res.Warnings = append(res.Warnings, warning)
+	}
+	if row, warning := s.anchorAfterExport(ctx, caseID, e.Kind(), res.ReportID, operator); row != nil {
+		out["anchor"] = row
+	} else if warning != "" {
+		res.Warnings = append(res.Warnings, warning)
+	}
+	out["warnings"] = res.Warnings
+	out["report"] = info
+	return out, nil
+}
+
+// handleCaseRedactions 管理对外披露遮盖标记：
+// - GET：列出
+// - POST：新增（target_type=artifact|hit，pointer 为 JSON Pointer，空串表示整条）
+// - DELETE /{redaction_id}：撤销
+func (s *Server) handleCaseRedactions(w http.ResponseWriter, r *http.Request, caseID, redactionID string) {
+	switch {
+	case r.Method == http.MethodGet && redactionID == "":
+		rows, err := s.store.ListRedactions(r.Context(), caseID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"redactions": rows})
+	case r.Method == http.MethodPost && redactionID == "":
+		type reqBody struct {
+			Operator   string `json:"operator,omitempty"`
+			TargetType string `json:"target_type"`
+			TargetID   string `json:"target_id"`
+			Pointer    string `json:"pointer,omitempty"`
+			Reason     string `json:"reason,omitempty"`
+		}
+		var req reqBody
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		operator := strings.TrimSpace(req.Operator)
+		if operator == "" {
+			operator = "system"
+		}
+		item := model.Redaction{
+			CaseID:     caseID,
+			TargetType: strings.TrimSpace(req.TargetType),
+			TargetID:   strings.TrimSpace(req.TargetID),
+			Pointer:    req.Pointer,
+			Reason:     strings.TrimSpace(req.Reason),
+			Operator:   operator,
+		}
+		if item.TargetID == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("target_id is required"))
+			return
+		}
+		if err := forensicexport.ValidateRedaction(r.Context(), s.store, item); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		saved, err := s.store.AddRedaction(r.Context(), item)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		_ = s.store.AppendAudit(r.Context(), caseID, "", "redaction", "add", "success", operator, "webapp.handleCaseRedactions", map[string]any{
+			"redaction_id": saved.RedactionID,
+			"target_type":  saved.TargetType,
+			"target_id":    saved.TargetID,
+			"pointer":      saved.Pointer,
+			"reason":       saved.Reason,
+		})
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "redaction": saved})
+	case r.Method == http.MethodDelete && redactionID != "":
+		operator := strings.TrimSpace(r.URL.Query().Get("operator"))
+		if operator == "" {
+			operator = "system"
+		}
+		found, err := s.store.DeleteRedaction(r.Context(), caseID, redactionID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !found {
+			writeError(w, http.StatusNotFound, fmt.Errorf("redaction not found: %s", redactionID))
+			return
+		}
+		_ = s.store.AppendAudit(r.Context(), caseID, "", "redaction", "remove", "success", operator, "webapp.handleCaseRedactions", map[string]any{
+			"redaction_id": redactionID,
+		})
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
 	}
+}
 
-	res, err := forensicpdf.GenerateForensicPDF(r.Context(), s.store, forensicpdf.Options{
-		CaseID:   caseID,
-		DBPath:   s.opts.DBPath,
-		Operator: operator,
-		Note:     strings.TrimSpace(req.Note),
-	})
+func (s *Server) handleCasePrechecks(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	rows, err := s.store.ListPrecheckResults(r.Context(), caseID)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
+	writeJSON(w, http.StatusOK, map[string]any{"prechecks": rows})
+}
 
-	info, err := s.store.GetReportByID(r.Context(), res.ReportID)
+func (s *Server) handleCaseScanRuns(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	rows, err := s.store.ListScanRuns(r.Context(), caseID)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-
-	writeJSON(w, http.StatusOK, map[string]any{
-		"ok":         true,
-		"case_id":    caseID,
-		"report_id":  res.ReportID,
-		"pdf_path":   res.PDFPath,
-		"pdf_sha256": res.PDFSHA256,
-		"warnings":   res.Warnings,
-		"report":     info,
-	})
+	writeJSON(w, http.StatusOK, map[string]any{"scan_runs": rows})
 }
 
-func (s *Server) handleCasePrechecks(w http.ResponseWriter, r *http.Request, caseID string) {
+func (s *Server) handleCaseAudits(w http.ResponseWriter, r *http.Request, caseID string) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	rows, err := s.store.ListPrecheckResults(r.Context(), caseID)
+	limit := parseInt(r.URL.Query().Get("limit"), 500)
+	if traceID := strings.TrimSpace(r.URL.Query().Get("trace_id")); traceID != "" {
+		rows, err := s.store.ListAuditLogsByTrace(r.Context(), traceID, caseID, limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"audits": rows})
+		return
+	}
+	q, err := parseAuditQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	rows, next, err := s.store.QueryAuditLogs(r.Context(), caseID, q)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"prechecks": rows})
+	writeJSON(w, http.StatusOK, map[string]any{"audits": rows, "next_cursor": next})
 }
 
-func (s *Server) handleCaseAudits(w http.ResponseWriter, r *http.Request, caseID string) {
+// handleAuditsByTrace 按 trace_id 跨案件查询审计日志（GET /api/audits?trace_id=...）。
+//
+// 用于远程排查：UI 报错时响应头里的 X-Request-ID 即 trace_id。
+func (s *Server) handleAuditsByTrace(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	limit := parseInt(r.URL.Query().Get("limit"), 500)
-	rows, err := s.store.ListAuditLogs(r.Context(), caseID, limit)
+	traceID := strings.TrimSpace(r.URL.Query().Get("trace_id"))
+	if traceID == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("trace_id is required"))
+		return
+	}
+	rows, err := s.store.ListAuditLogsByTrace(r.Context(), traceID, "", parseInt(r.URL.Query().Get("limit"), 500))
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"audits": rows})
+	writeJSON(w, http.StatusOK, map[string]any{"trace_id": traceID, "audits": rows})
 }
 
 func (s *Server) handleCaseArtifacts(w http.ResponseWriter, r *http.Request, caseID string) {
@@ -600,12 +1242,17 @@ func (s *Server) handleCaseArtifacts(w http.ResponseWriter, r *http.Request, cas
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
-	rows, err := s.store.ListArtifactsByCase(r.Context(), caseID)
+	q, err := parseArtifactQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	rows, next, err := s.store.QueryArtifacts(r.Context(), caseID, q)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]any{"artifacts": rows})
+	writeJSON(w, http.StatusOK, map[string]any{"artifacts": rows, "next_cursor": next})
 }
 
 func (s *Server) handleReportRoutes(w http.ResponseWriter, r *http.Request) {
@@ -636,6 +1283,9 @@ func (s *Server) handleReportRoutes(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, fmt.Errorf("report not found: %s", reportID))
 		return
 	}
+	if _, ok := s.requireUnmask(w, r, info.CaseID, "report_download"); !ok {
+		return
+	}
 	serveFile(w, r, info.FilePath, "report_"+reportID)
 }
 
@@ -672,11 +1322,34 @@ func (s *Server) handleArtifactRoutes(w http.ResponseWriter, r *http.Request) {
 		}
 		out := map[string]any{"artifact": info}
 		if includeContent {
-			raw, err := os.ReadFile(info.SnapshotPath)
-			if err != nil {
-				writeError(w, http.StatusInternalServerError, err)
+			if _, ok := s.requireUnmask(w, r, info.CaseID, "artifact_content"); !ok {
 				return
 			}
+			// 压缩快照透明解压；下载接口仍返回存储原件（与入库 sha256 对应）。
+			// 分片证据返回整组拼接后的内容，下载接口仍只返回当前分片。
+			var raw []byte
+			if info.PartGroup != "" {
+				parts, err := s.store.ListArtifactParts(r.Context(), info.PartGroup)
+				if err != nil {
+					writeError(w, http.StatusInternalServerError, err)
+					return
+				}
+				raw, err = readArtifactParts(parts)
+				if err != nil {
+					writeError(w, http.StatusInternalServerError, err)
+					return
+				}
+				out["parts"] = parts
+				if len(parts) != info.PartCount {
+					out["content_warning"] = fmt.Sprintf("artifact parts incomplete: found %d of %d", len(parts), info.PartCount)
+				}
+			} else {
+				raw, err = snapshot.ReadFile(info.SnapshotPath)
+				if err != nil {
+					writeError(w, http.StatusInternalServerError, err)
+					return
+				}
+			}
 			out["content"] = string(raw)
 			out["content_length"] = len(raw)
 		}
@@ -695,12 +1368,60 @@ func (s *Server) handleArtifactRoutes(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, fmt.Errorf("artifact not found: %s", artifactID))
 			return
 		}
+		if _, ok := s.requireUnmask(w, r, info.CaseID, "artifact_download"); !ok {
+			return
+		}
 		serveFile(w, r, info.SnapshotPath, "artifact_"+artifactID)
+	case "preview":
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		// 按快照真实类型返回结构化预览（zip 清单 / sqlite 表清单 / 图片缩略图 / 文本）。
+		info, err := s.store.GetArtifactInfo(r.Context(), artifactID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if info == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("artifact not found: %s", artifactID))
+			return
+		}
+		if _, ok := s.requireUnmask(w, r, info.CaseID, "artifact_preview"); !ok {
+			return
+		}
+		preview, err := artifactpreview.Build(r.Context(), info.SnapshotPath, artifactpreview.Options{
+			MaxZipEntries: parseInt(r.URL.Query().Get("max_entries"), 0),
+			MaxTextBytes:  parseInt(r.URL.Query().Get("max_bytes"), 0),
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"artifact": info,
+			"preview":  preview,
+		})
+	case "sqlite":
+		s.handleArtifactSQLite(w, r, artifactID, strings.Join(parts[2:], "/"))
 	default:
 		w.WriteHeader(http.StatusNotFound)
 	}
 }
 
+// readArtifactParts 按分片序号读取分片快照并拼接为完整 JSON 数组。
+func readArtifactParts(parts []model.ArtifactInfo) ([]byte, error) {
+	chunks := make([][]byte, 0, len(parts))
+	for _, p := range parts {
+		raw, err := snapshot.ReadFile(p.SnapshotPath)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, raw)
+	}
+	return snapshot.JoinJSONArrays(chunks)
+}
+
 // --- helpers ---
 
 func writeJSON(w http.ResponseWriter, status int, v any) {
@@ -711,9 +1432,21 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	_ = enc.Encode(v)
 }
 
+// writeError 输出统一错误体 {"error": msg, "code": ERR_xxx}。
+//
+// 错误链上带有明确错误码（apperr）时，以错误码映射的 HTTP 状态为准；
+// 否则保留 handler 传入的状态码，并按状态码给出兜底错误码。
 func writeError(w http.ResponseWriter, status int, err error) {
+	code := apperr.CodeOf(err)
+	if code == apperr.CodeInternal {
+		code = apperr.CodeForStatus(status)
+	} else {
+		status = apperr.HTTPStatus(code)
+	}
+	lang, _ := i18n.Parse(w.Header().Get("Content-Language"))
 	writeJSON(w, status, map[string]any{
-		"error": err.Error(),
+		"error": apperr.Localize(i18n.New(lang), err),
+		"code":  code,
 	})
 }
 
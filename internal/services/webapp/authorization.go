@@ -0,0 +1,87 @@
+package webapp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/services/authdoc"
+)
+
+// handleCaseAuthorization 执法授权文书（扫描版 PDF）。
+//
+// - GET /api/cases/{case_id}/authorization：已上传的授权文书（最新在前）
+// - POST /api/cases/{case_id}/authorization：{"file_name","content_base64","authorization_order","agency","operator"}
+// - GET /api/cases/{case_id}/authorization/{attachment_id}/download：下载原文件
+func (s *Server) handleCaseAuthorization(w http.ResponseWriter, r *http.Request, caseID string, parts []string) {
+	if len(parts) > 0 {
+		if len(parts) != 2 || parts[1] != "download" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		docs, err := s.store.ListCaseAttachments(r.Context(), caseID, authdoc.KindAuthorizationDocument)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		for _, d := range docs {
+			if d.AttachmentID == parts[0] {
+				serveFile(w, r, d.FilePath, "authorization_"+d.AttachmentID)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, fmt.Errorf("authorization document not found: %s", parts[0]))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		docs, err := s.store.ListCaseAttachments(r.Context(), caseID, authdoc.KindAuthorizationDocument)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"documents": docs})
+	case http.MethodPost:
+		var req struct {
+			FileName           string `json:"file_name"`
+			ContentBase64      string `json:"content_base64"`
+			AuthorizationOrder string `json:"authorization_order,omitempty"`
+			Agency             string `json:"agency,omitempty"`
+			Operator           string `json:"operator,omitempty"`
+		}
+		// base64 膨胀约 4/3，再留一些 JSON 字段余量。
+		r.Body = http.MaxBytesReader(w, r.Body, authdoc.MaxDocumentBytes/3*4+(1<<20))
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(req.ContentBase64))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid content_base64: %w", err))
+			return
+		}
+		doc, err := authdoc.Attach(r.Context(), s.store, authdoc.Input{
+			CaseID:             caseID,
+			FileName:           req.FileName,
+			Content:            content,
+			AuthorizationOrder: req.AuthorizationOrder,
+			Agency:             req.Agency,
+			Operator:           req.Operator,
+			EvidenceRoot:       s.opts.EvidenceRoot,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "document": doc})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
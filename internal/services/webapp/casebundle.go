@@ -0,0 +1,73 @@
+package webapp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/services/casebundle"
+)
+
+// handleCaseDownload：GET /api/cases/{case_id}/download?kind=all|evidence|reports 下载案件证据与报告的 tar 包。
+//
+// 支持 Range / If-Range 断点续传（ETag 为整包 sha256）；整包 sha256 同时放在 X-Content-SHA256 与 Digest 头，
+// 包内最后一个条目 SHA256SUMS 列出每个文件的 sha256。?info=1 只返回打包信息，不下载。
+func (s *Server) handleCaseDownload(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	operator, ok := s.requireUnmask(w, r, caseID, "case_download")
+	if !ok {
+		return
+	}
+	b, err := casebundle.Build(r.Context(), s.store, caseID, casebundle.Options{
+		Kind:     r.URL.Query().Get("kind"),
+		CacheDir: filepath.Join(filepath.Dir(s.opts.DBPath), "cache", "case_bundles"),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if parseBool(r.URL.Query().Get("info"), false) {
+		writeJSON(w, http.StatusOK, map[string]any{"bundle": b})
+		return
+	}
+
+	f, err := os.Open(b.Path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer f.Close()
+
+	// 只在从头下载时记审计，续传的分段请求不重复记录。
+	if rng := strings.TrimSpace(r.Header.Get("Range")); r.Method == http.MethodGet && (rng == "" || strings.HasPrefix(rng, "bytes=0-")) {
+		if operator == "" {
+			operator = strings.TrimSpace(r.URL.Query().Get("operator"))
+		}
+		if operator == "" {
+			operator = "system"
+		}
+		_ = s.store.AppendAudit(r.Context(), caseID, "", "export", "case_bundle", "success", operator, "webapp.handleCaseDownload", map[string]any{
+			"kind":       b.Kind,
+			"sha256":     b.SHA256,
+			"size_bytes": b.SizeBytes,
+			"file_count": b.FileCount,
+			"warnings":   b.Warnings,
+		})
+	}
+
+	sum, _ := hex.DecodeString(b.SHA256)
+	w.Header().Set("Content-Type", "application/x-tar")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("case_%s_%s.tar", caseID, b.Kind)))
+	w.Header().Set("ETag", `"`+b.SHA256+`"`)
+	w.Header().Set("X-Content-SHA256", b.SHA256)
+	w.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sum))
+	http.ServeContent(w, r, "", time.Unix(b.ModTime, 0), f)
+}
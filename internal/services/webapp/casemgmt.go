@@ -0,0 +1,72 @@
+package webapp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/services/casemgmt"
+)
+
+// handleCaseManagement 对接外部案件管理系统：
+// - GET /api/cases/{case_id}/case-management：对接是否启用、适配器、端点与自动推送的导出格式
+// - POST /api/cases/{case_id}/case-management/push：手动推送案件摘要、命中与报告哈希
+//
+// 未配置 serve --cms-config 时 POST 返回 400（ERR_INVALID_ARGUMENT）。
+func (s *Server) handleCaseManagement(w http.ResponseWriter, r *http.Request, caseID string, parts []string) {
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		out := map[string]any{"enabled": s.cms != nil}
+		if s.cms != nil {
+			out["adapter"] = s.cms.Adapter
+			out["endpoint"] = s.cms.Endpoint
+			out["push_on_export"] = s.cms.PushOnExport
+		}
+		writeJSON(w, http.StatusOK, out)
+	case len(parts) == 1 && parts[0] == "push" && r.Method == http.MethodPost:
+		var req struct {
+			Operator string `json:"operator,omitempty"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req) // 允许空 body
+		if s.cms == nil {
+			writeError(w, http.StatusBadRequest, apperr.New(apperr.CodeInvalidArgument, "case management integration is not configured (start serve with --cms-config)"))
+			return
+		}
+		// 推送内容含完整命中值，partial 模式下与导出同等对待。
+		grantee, ok := s.requireUnmask(w, r, caseID, "case_management_push")
+		if !ok {
+			return
+		}
+		operator := strings.TrimSpace(req.Operator)
+		if grantee != "" {
+			operator = grantee
+		}
+		rec, err := casemgmt.Push(r.Context(), s.store, s.cms, caseID, casemgmt.Trigger{Kind: "manual", Operator: operator})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "receipt": rec})
+	case len(parts) <= 1:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// pushAfterExport 在导出完成后按 push_on_export 自动推送；失败只返回告警，不影响导出结果。
+func (s *Server) pushAfterExport(ctx context.Context, caseID, kind, reportID, operator string) (*casemgmt.Receipt, string) {
+	if !s.cms.PushOnExportKind(kind) {
+		return nil, ""
+	}
+	rec, err := casemgmt.Push(ctx, s.store, s.cms, caseID, casemgmt.Trigger{
+		Kind: "export", ExportKind: kind, ReportID: reportID, Operator: operator,
+	})
+	if err != nil {
+		return nil, fmt.Sprintf("case management push failed: %v", err)
+	}
+	return rec, ""
+}
@@ -6,12 +6,15 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"crypto-inspector/internal/adapters/host"
 	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/apperr"
 	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/filetype"
 	"crypto-inspector/internal/platform/hash"
 	"crypto-inspector/internal/platform/id"
 	"crypto-inspector/internal/services/chainbalance"
@@ -27,6 +30,7 @@ import (
 // - EVM 原生币余额：eth_getBalance
 // - EVM ERC20 余额：eth_call balanceOf(address)
 // - BTC 地址余额：Blockstream API（可配置 base_url）
+// - 按 kind 查询注册表（chainbalance.Registry）：列出提供方、查询地址交易历史
 func (s *Server) handleChainRoutes(w http.ResponseWriter, r *http.Request) {
 	rest := strings.TrimPrefix(r.URL.Path, "/api/chain/")
 	rest = strings.Trim(rest, "/")
@@ -55,6 +59,15 @@ func (s *Server) handleChainRoutes(w http.ResponseWriter, r *http.Request) {
 		default:
 			w.WriteHeader(http.StatusNotFound)
 		}
+	case "tokens":
+		// /api/chain/tokens?chain=
+		s.handleChainTokens(w, r)
+	case "providers":
+		// /api/chain/providers
+		s.handleChainProviders(w, r)
+	case "history":
+		// /api/chain/history
+		s.handleChainHistory(w, r)
 	case "btc":
 		// /api/chain/btc/balances
 		if len(parts) >= 2 && parts[1] == "balances" {
@@ -85,6 +98,75 @@ func (s *Server) handleCaseChain(w http.ResponseWriter, r *http.Request, caseID
 	}
 }
 
+// handleChainProviders 返回链上查询注册表中的全部 kind（内置 + 配置文件）。
+func (s *Server) handleChainProviders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"providers": s.chains.List(),
+	})
+}
+
+// handleChainHistory 按 kind 查询单个地址的交易历史（直接查询，不留痕）。
+func (s *Server) handleChainHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	type reqBody struct {
+		Kind              string   `json:"kind,omitempty"`
+		Address           string   `json:"address,omitempty"`
+		Endpoint          string   `json:"endpoint,omitempty"`
+		FallbackEndpoints []string `json:"fallback_endpoints,omitempty"`
+		Symbol            string   `json:"symbol,omitempty"`
+	}
+	var req reqBody
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+		return
+	}
+	provider, ok := s.chains.Lookup(req.Kind)
+	if !ok {
+		writeError(w, http.StatusBadRequest, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unknown kind: %s", req.Kind)))
+		return
+	}
+	res, err := provider.QueryHistory(r.Context(), chainbalance.Query{
+		Endpoint:          req.Endpoint,
+		FallbackEndpoints: req.FallbackEndpoints,
+		Symbol:            req.Symbol,
+		Breaker:           s.chainBreaker,
+	}, req.Address)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":     true,
+		"kind":   provider.ChainInfo().Name,
+		"result": res,
+	})
+}
+
+// handleChainTokens 返回预置代币清单（供 UI 按 symbol + chain 选择）。
+func (s *Server) handleChainTokens(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	chain := strings.TrimSpace(r.URL.Query().Get("chain"))
+	if chain != "" && chainbalance.NormalizeChain(chain) == "" {
+		writeError(w, http.StatusBadRequest, apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("unknown chain: %s", chain)))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"version": chainbalance.TokenRegistryVersion,
+		"tokens":  chainbalance.ListTokens(chain),
+	})
+}
+
 func (s *Server) handleChainEVMBalances(w http.ResponseWriter, r *http.Request) {
 	// 统一用 POST，避免地址列表太长导致 URL 超长。
 	if r.Method != http.MethodPost {
@@ -93,9 +175,10 @@ func (s *Server) handleChainEVMBalances(w http.ResponseWriter, r *http.Request)
 	}
 
 	type reqBody struct {
-		RPCURL    string   `json:"rpc_url,omitempty"`
-		Symbol    string   `json:"symbol,omitempty"`
-		Addresses []string `json:"addresses,omitempty"`
+		RPCURL          string   `json:"rpc_url,omitempty"`
+		FallbackRPCURLs []string `json:"fallback_rpc_urls,omitempty"`
+		Symbol          string   `json:"symbol,omitempty"`
+		Addresses       []string `json:"addresses,omitempty"`
 	}
 	var req reqBody
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -138,19 +221,19 @@ func (s *Server) handleChainEVMBalances(w http.ResponseWriter, r *http.Request)
 
 	p := chainbalance.NewEVMProvider(rpcURL)
 	p.Symbol = symbol
+	p.FallbackRPCURLs = req.FallbackRPCURLs
+	p.Breaker = s.chainBreaker
 
-	bal, err := p.QueryBalances(r.Context(), addrs)
+	res, err := p.QueryBalancesPartial(r.Context(), addrs)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
-		"ok":         true,
+	s.writeChainPartial(w, res, append([]string{rpcURL}, req.FallbackRPCURLs...), map[string]any{
 		"chain":      "evm",
 		"rpc_url":    rpcURL,
 		"symbol":     symbol,
-		"balances":   bal,
 		"warnings":   warnings,
 		"addr_count": len(addrs),
 	})
@@ -164,11 +247,13 @@ func (s *Server) handleChainEVMERC20Balances(w http.ResponseWriter, r *http.Requ
 	}
 
 	type reqBody struct {
-		RPCURL    string   `json:"rpc_url,omitempty"`
-		Symbol    string   `json:"symbol,omitempty"`
-		Contract  string   `json:"contract,omitempty"`
-		Decimals  int      `json:"decimals,omitempty"`
-		Addresses []string `json:"addresses,omitempty"`
+		RPCURL          string   `json:"rpc_url,omitempty"`
+		FallbackRPCURLs []string `json:"fallback_rpc_urls,omitempty"`
+		Chain           string   `json:"chain,omitempty"` // ethereum|bsc|polygon|tron（按预置清单选择合约）
+		Symbol          string   `json:"symbol,omitempty"`
+		Contract        string   `json:"contract,omitempty"`
+		Decimals        int      `json:"decimals,omitempty"`
+		Addresses       []string `json:"addresses,omitempty"`
 	}
 	var req reqBody
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -176,35 +261,12 @@ func (s *Server) handleChainEVMERC20Balances(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	rpcURL := strings.TrimSpace(req.RPCURL)
-	warnings := []string{}
-	if rpcURL == "" {
-		// 内部试用默认走公共 RPC，方便开箱即用。
-		// 对外/正式环境建议改为“强制配置私有 RPC”，并做访问控制与审计。
-		rpcURL = chainbalance.DefaultPublicEVMRPC
-		warnings = append(warnings, "rpc_url not provided; fallback to default public rpc")
-	}
-
-	symbol := strings.TrimSpace(req.Symbol)
-	if symbol == "" {
-		symbol = "USDT"
-	}
-	contract := strings.TrimSpace(req.Contract)
-	if contract == "" && strings.EqualFold(symbol, "USDT") {
-		// 内测默认值（Ethereum Mainnet USDT）
-		contract = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
-		warnings = append(warnings, "contract not provided; fallback to Ethereum mainnet USDT contract")
-	}
-	if contract == "" {
-		writeError(w, http.StatusBadRequest, fmt.Errorf("contract is required"))
+	t, warnings, err := chainbalance.ResolveERC20Target(req.Chain, req.Symbol, req.Contract, req.Decimals, req.RPCURL)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
 		return
 	}
-	decimals := req.Decimals
-	if decimals == 0 && strings.EqualFold(symbol, "USDT") {
-		// USDT 在以太坊主网常用 decimals=6。
-		decimals = 6
-		warnings = append(warnings, "decimals not provided; fallback to 6 for USDT")
-	}
+	rpcURL, symbol, contract, decimals := t.RPCURL, t.Symbol, t.Contract, t.Decimals
 
 	// 清洗地址列表：去空、去重、限流。
 	addrSet := map[string]struct{}{}
@@ -230,25 +292,28 @@ func (s *Server) handleChainEVMERC20Balances(w http.ResponseWriter, r *http.Requ
 	p.Symbol = symbol
 	p.Contract = contract
 	p.Decimals = decimals
+	p.Chain = t.Chain
+	p.FallbackRPCURLs = req.FallbackRPCURLs
+	p.Breaker = s.chainBreaker
 
-	bal, err := p.QueryBalances(r.Context(), addrs)
+	res, err := p.QueryBalancesPartial(r.Context(), addrs)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
-		"ok":         true,
+	body := map[string]any{
 		"chain":      "evm",
 		"token_type": "erc20",
 		"rpc_url":    rpcURL,
 		"symbol":     symbol,
 		"contract":   contract,
 		"decimals":   decimals,
-		"balances":   bal,
 		"warnings":   warnings,
 		"addr_count": len(addrs),
-	})
+	}
+	t.Annotate(body)
+	s.writeChainPartial(w, res, append([]string{rpcURL}, req.FallbackRPCURLs...), body)
 }
 
 func (s *Server) handleChainBTCBalances(w http.ResponseWriter, r *http.Request) {
@@ -259,9 +324,10 @@ func (s *Server) handleChainBTCBalances(w http.ResponseWriter, r *http.Request)
 	}
 
 	type reqBody struct {
-		BaseURL   string   `json:"base_url,omitempty"`
-		Symbol    string   `json:"symbol,omitempty"`
-		Addresses []string `json:"addresses,omitempty"`
+		BaseURL          string   `json:"base_url,omitempty"`
+		FallbackBaseURLs []string `json:"fallback_base_urls,omitempty"`
+		Symbol           string   `json:"symbol,omitempty"`
+		Addresses        []string `json:"addresses,omitempty"`
 	}
 	var req reqBody
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -303,19 +369,19 @@ func (s *Server) handleChainBTCBalances(w http.ResponseWriter, r *http.Request)
 
 	p := chainbalance.NewBTCProvider(baseURL)
 	p.Symbol = symbol
+	p.FallbackBaseURLs = req.FallbackBaseURLs
+	p.Breaker = s.chainBreaker
 
-	bal, err := p.QueryBalances(r.Context(), addrs)
+	res, err := p.QueryBalancesPartial(r.Context(), addrs)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]any{
-		"ok":         true,
+	s.writeChainPartial(w, res, append([]string{baseURL}, req.FallbackBaseURLs...), map[string]any{
 		"chain":      "btc",
 		"base_url":   baseURL,
 		"symbol":     symbol,
-		"balances":   bal,
 		"warnings":   warnings,
 		"addr_count": len(addrs),
 	})
@@ -336,16 +402,25 @@ func (s *Server) handleCaseChainBalance(w http.ResponseWriter, r *http.Request,
 	type reqBody struct {
 		Operator string `json:"operator,omitempty"`
 		Note     string `json:"note,omitempty"`
-		Kind     string `json:"kind,omitempty"` // evm_native|evm_erc20|btc
+		Kind     string `json:"kind,omitempty"` // 注册表中的 kind：evm_native|evm_erc20|evm_nft|btc|配置文件新增条目
 
 		// EVM / ERC20
-		RPCURL   string `json:"rpc_url,omitempty"`
-		Symbol   string `json:"symbol,omitempty"`
-		Contract string `json:"contract,omitempty"`
-		Decimals int    `json:"decimals,omitempty"`
+		RPCURL          string   `json:"rpc_url,omitempty"`
+		FallbackRPCURLs []string `json:"fallback_rpc_urls,omitempty"`
+		Chain           string   `json:"chain,omitempty"` // evm_erc20：ethereum|bsc|polygon|tron（按预置清单选择合约）
+		Symbol          string   `json:"symbol,omitempty"`
+		Contract        string   `json:"contract,omitempty"`
+		Decimals        int      `json:"decimals,omitempty"`
+
+		// NFT（ERC-721 / ERC-1155）
+		Standard  string   `json:"standard,omitempty"` // erc721|erc1155
+		Contracts []string `json:"contracts,omitempty"`
+		TokenIDs  []string `json:"token_ids,omitempty"`
+		MaxTokens int      `json:"max_tokens,omitempty"`
 
 		// BTC
-		BaseURL string `json:"base_url,omitempty"`
+		BaseURL          string   `json:"base_url,omitempty"`
+		FallbackBaseURLs []string `json:"fallback_base_urls,omitempty"`
 
 		Addresses []string `json:"addresses,omitempty"`
 	}
@@ -424,9 +499,11 @@ func (s *Server) handleCaseChainBalance(w http.ResponseWriter, r *http.Request,
 		deviceID = dev.ID
 	}
 
-	// 执行链上查询
+	// 执行链上查询（单地址重试 + 备用端点；失败地址不影响其他地址留痕）
 	now := time.Now().Unix()
 	balances := map[string]map[string]string{}
+	var addrErrors map[string]chainbalance.AddressError
+	var nftHoldings map[string][]chainbalance.NFTHolding
 	queryMeta := map[string]any{
 		"kind":       kind,
 		"case_id":    caseID,
@@ -434,105 +511,46 @@ func (s *Server) handleCaseChainBalance(w http.ResponseWriter, r *http.Request,
 		"queried_at": now,
 	}
 
-	switch kind {
-	case "evm_native":
-		rpcURL := strings.TrimSpace(req.RPCURL)
-		if rpcURL == "" {
-			rpcURL = chainbalance.DefaultPublicEVMRPC
-			warnings = append(warnings, "rpc_url not provided; fallback to default public rpc")
-		}
-		symbol := strings.TrimSpace(req.Symbol)
-		if symbol == "" {
-			symbol = "ETH"
-		}
-		p := chainbalance.NewEVMProvider(rpcURL)
-		p.Symbol = symbol
-		out, err := p.QueryBalances(r.Context(), addrs)
-		if err != nil {
-			_ = s.store.AppendAudit(r.Context(), caseID, deviceID, "chain_balance", "query", "failed", operator, "webapp.chain_balance", map[string]any{
-				"kind":  kind,
-				"error": err.Error(),
-			})
-			writeError(w, http.StatusInternalServerError, err)
-			return
-		}
-		balances = out
-		queryMeta["chain"] = "evm"
-		queryMeta["rpc_url"] = rpcURL
-		queryMeta["symbol"] = symbol
-	case "evm_erc20":
-		rpcURL := strings.TrimSpace(req.RPCURL)
-		if rpcURL == "" {
-			rpcURL = chainbalance.DefaultPublicEVMRPC
-			warnings = append(warnings, "rpc_url not provided; fallback to default public rpc")
-		}
-		symbol := strings.TrimSpace(req.Symbol)
-		if symbol == "" {
-			symbol = "USDT"
-		}
-		contract := strings.TrimSpace(req.Contract)
-		if contract == "" && strings.EqualFold(symbol, "USDT") {
-			contract = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
-			warnings = append(warnings, "contract not provided; fallback to Ethereum mainnet USDT contract")
-		}
-		if contract == "" {
-			writeError(w, http.StatusBadRequest, fmt.Errorf("contract is required"))
-			return
-		}
-		decimals := req.Decimals
-		if decimals == 0 && strings.EqualFold(symbol, "USDT") {
-			decimals = 6
-			warnings = append(warnings, "decimals not provided; fallback to 6 for USDT")
-		}
-		p := chainbalance.NewERC20Provider(rpcURL)
-		p.Symbol = symbol
-		p.Contract = contract
-		p.Decimals = decimals
-		out, err := p.QueryBalances(r.Context(), addrs)
-		if err != nil {
-			_ = s.store.AppendAudit(r.Context(), caseID, deviceID, "chain_balance", "query", "failed", operator, "webapp.chain_balance", map[string]any{
-				"kind":  kind,
-				"error": err.Error(),
-			})
-			writeError(w, http.StatusInternalServerError, err)
-			return
-		}
-		balances = out
-		queryMeta["chain"] = "evm"
-		queryMeta["token_type"] = "erc20"
-		queryMeta["rpc_url"] = rpcURL
-		queryMeta["symbol"] = symbol
-		queryMeta["contract"] = contract
-		queryMeta["decimals"] = decimals
-	case "btc":
-		baseURL := strings.TrimSpace(req.BaseURL)
-		if baseURL == "" {
-			baseURL = chainbalance.DefaultPublicBTCAPI
-			warnings = append(warnings, "base_url not provided; fallback to default public btc api")
-		}
-		symbol := strings.TrimSpace(req.Symbol)
-		if symbol == "" {
-			symbol = "BTC"
-		}
-		p := chainbalance.NewBTCProvider(baseURL)
-		p.Symbol = symbol
-		out, err := p.QueryBalances(r.Context(), addrs)
-		if err != nil {
-			_ = s.store.AppendAudit(r.Context(), caseID, deviceID, "chain_balance", "query", "failed", operator, "webapp.chain_balance", map[string]any{
-				"kind":  kind,
-				"error": err.Error(),
-			})
-			writeError(w, http.StatusInternalServerError, err)
-			return
-		}
-		balances = out
-		queryMeta["chain"] = "btc"
-		queryMeta["base_url"] = baseURL
-		queryMeta["symbol"] = symbol
-	default:
+	provider, ok := s.chains.Lookup(kind)
+	if !ok {
 		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown kind: %s", kind))
 		return
 	}
+	// EVM 类请求用 rpc_url，BTC 类请求用 base_url；由驱动按自身协议解释。
+	endpoint := strings.TrimSpace(req.RPCURL)
+	if endpoint == "" {
+		endpoint = strings.TrimSpace(req.BaseURL)
+	}
+	qres, err := provider.QueryBalances(r.Context(), chainbalance.Query{
+		Endpoint:          endpoint,
+		FallbackEndpoints: append(append([]string{}, req.FallbackRPCURLs...), req.FallbackBaseURLs...),
+		Chain:             req.Chain,
+		Symbol:            req.Symbol,
+		Contract:          req.Contract,
+		Decimals:          req.Decimals,
+		Standard:          req.Standard,
+		Contracts:         req.Contracts,
+		TokenIDs:          req.TokenIDs,
+		MaxTokens:         req.MaxTokens,
+		Breaker:           s.chainBreaker,
+	}, addrs)
+	if qres == nil && apperr.CodeOf(err) == apperr.CodeInvalidArgument {
+		// 参数校验失败（尚未发起查询）：直接 400，不写查询失败审计。
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var res *chainbalance.PartialResult
+	if qres != nil {
+		res = qres.PartialResult
+	}
+	if !s.checkCaseChainResult(w, r, res, err, caseID, deviceID, operator, kind) {
+		return
+	}
+	warnings = append(warnings, qres.Warnings...)
+	balances, addrErrors, nftHoldings = res.Balances, res.Errors, qres.Holdings
+	for k, v := range qres.Meta {
+		queryMeta[k] = v
+	}
 
 	// --- 写入 chain_balance artifact（证据快照） ---
 	artifactID := id.New("art")
@@ -541,6 +559,11 @@ func (s *Server) handleCaseChainBalance(w http.ResponseWriter, r *http.Request,
 		"note":     strings.TrimSpace(req.Note),
 		"warnings": warnings,
 		"balances": balances,
+		// errors 记录本次未能查到的地址及原因（证据快照中如实保留“未查到”的事实）。
+		"errors": addrErrors,
+	}
+	if nftHoldings != nil {
+		payload["holdings"] = nftHoldings
 	}
 	raw, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
@@ -594,6 +617,7 @@ func (s *Server) handleCaseChainBalance(w http.ResponseWriter, r *http.Request,
 		SnapshotPath:      snapshotPath,
 		SHA256:            sum,
 		SizeBytes:         size,
+		MimeType:          filetype.MimeJSON,
 		CollectedAt:       now,
 		CollectorName:     collectorName,
 		CollectorVersion:  collectorVer,
@@ -612,12 +636,64 @@ func (s *Server) handleCaseChainBalance(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	// --- 写入 token_balance 命中 ---
+	// --- 写入 token_balance / nft_holdings 命中 ---
+	var hits []model.RuleHit
+	if nftHoldings != nil {
+		hits = nftHoldingHits(caseID, deviceID, kind, artifactID, now, nftHoldings, queryMeta)
+	} else {
+		hits = tokenBalanceHits(caseID, deviceID, kind, artifactID, now, balances, queryMeta, strings.TrimSpace(req.Symbol))
+	}
+	if err := s.store.SaveRuleHits(r.Context(), hits); err != nil {
+		_ = s.store.AppendAudit(r.Context(), caseID, deviceID, "chain_balance", "save_hits", "failed", operator, "webapp.chain_balance", map[string]any{
+			"artifact_id": artifactID,
+			"error":       err.Error(),
+		})
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	_ = s.store.AppendAudit(r.Context(), caseID, deviceID, "chain_balance", "query_and_persist", "success", operator, "webapp.chain_balance", map[string]any{
+		"kind":        kind,
+		"artifact_id": artifactID,
+		"addr_count":  len(addrs),
+		"hit_count":   len(hits),
+		"failed":      failedAddresses(addrErrors),
+		"warnings":    warnings,
+	})
+
+	hitIDs := make([]string, 0, len(hits))
+	for _, h := range hits {
+		hitIDs = append(hitIDs, h.ID)
+	}
+
+	resp := map[string]any{
+		"ok":            true,
+		"case_id":       caseID,
+		"device_id":     deviceID,
+		"kind":          kind,
+		"artifact_id":   artifactID,
+		"snapshot_path": snapshotPath,
+		"sha256":        sum,
+		"size_bytes":    size,
+		"balances":      balances,
+		"errors":        addrErrors,
+		"partial":       len(addrErrors) > 0,
+		"hit_ids":       hitIDs,
+		"warnings":      warnings,
+	}
+	if nftHoldings != nil {
+		resp["holdings"] = nftHoldings
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// tokenBalanceHits 把余额查询结果固化为 token_balance 命中（每个地址一条）。
+func tokenBalanceHits(caseID, deviceID, kind, artifactID string, now int64, balances map[string]map[string]string, queryMeta map[string]any, fallbackSymbol string) []model.RuleHit {
 	hits := make([]model.RuleHit, 0, len(balances))
 	for addr, m := range balances {
 		symbol, _ := queryMeta["symbol"].(string)
 		if symbol == "" {
-			symbol = strings.TrimSpace(req.Symbol)
+			symbol = fallbackSymbol
 		}
 		matchedValue := addr
 		if symbol != "" {
@@ -646,41 +722,110 @@ func (s *Server) handleCaseChainBalance(w http.ResponseWriter, r *http.Request,
 			ArtifactIDs: []string{artifactID},
 		})
 	}
-	if err := s.store.SaveRuleHits(r.Context(), hits); err != nil {
-		_ = s.store.AppendAudit(r.Context(), caseID, deviceID, "chain_balance", "save_hits", "failed", operator, "webapp.chain_balance", map[string]any{
-			"artifact_id": artifactID,
-			"error":       err.Error(),
-		})
-		writeError(w, http.StatusInternalServerError, err)
-		return
+	return hits
+}
+
+// nftHoldingHits 把 NFT 持有明细固化为 nft_holdings 命中（每个地址 + 合约一条）。
+func nftHoldingHits(caseID, deviceID, kind, artifactID string, now int64, holdings map[string][]chainbalance.NFTHolding, queryMeta map[string]any) []model.RuleHit {
+	addrs := make([]string, 0, len(holdings))
+	for addr := range holdings {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	var hits []model.RuleHit
+	for _, addr := range addrs {
+		for _, h := range holdings[addr] {
+			hits = append(hits, model.RuleHit{
+				ID:           id.New("hit"),
+				CaseID:       caseID,
+				DeviceID:     deviceID,
+				Type:         model.HitNFTHoldings,
+				RuleID:       "chain_balance_" + kind,
+				RuleName:     "链上 NFT 持有查询结果",
+				RuleVersion:  "chainbalance-0.1.0",
+				MatchedValue: addr + "|" + h.Contract,
+				FirstSeenAt:  now,
+				LastSeenAt:   now,
+				Confidence:   0.95,
+				Verdict:      "confirmed",
+				DetailJSON: mustJSON(map[string]any{
+					"kind":       kind,
+					"address":    addr,
+					"standard":   h.Standard,
+					"contract":   h.Contract,
+					"name":       h.Name,
+					"symbol":     h.Symbol,
+					"balance":    h.Balance,
+					"token_ids":  h.TokenIDs,
+					"amounts":    h.Amounts,
+					"enumerable": h.Enumerable,
+					"truncated":  h.Truncated,
+					"query":      queryMeta,
+				}),
+				ArtifactIDs: []string{artifactID},
+			})
+		}
 	}
+	return hits
+}
 
-	_ = s.store.AppendAudit(r.Context(), caseID, deviceID, "chain_balance", "query_and_persist", "success", operator, "webapp.chain_balance", map[string]any{
-		"kind":        kind,
-		"artifact_id": artifactID,
-		"addr_count":  len(addrs),
-		"hit_count":   len(hits),
-		"warnings":    warnings,
-	})
+// checkCaseChainResult 处理留痕查询的失败分支：
+// - 查询本身出错，或所有地址都失败：写失败审计并返回错误（不生成空证据）
+// - 部分地址失败：放行，由调用方把失败地址写入证据快照与审计
+func (s *Server) checkCaseChainResult(w http.ResponseWriter, r *http.Request, res *chainbalance.PartialResult, err error, caseID, deviceID, operator, kind string) bool {
+	if err == nil && len(res.Balances) == 0 && len(res.Errors) > 0 {
+		err = apperr.Wrap(apperr.CodeUpstreamUnavailable, res.Err(), "all addresses failed")
+	}
+	if err == nil {
+		return true
+	}
+	detail := map[string]any{
+		"kind":  kind,
+		"error": err.Error(),
+	}
+	if res != nil && len(res.Errors) > 0 {
+		detail["failed"] = failedAddresses(res.Errors)
+	}
+	_ = s.store.AppendAudit(r.Context(), caseID, deviceID, "chain_balance", "query", "failed", operator, "webapp.chain_balance", detail)
+	writeError(w, http.StatusInternalServerError, err)
+	return false
+}
 
-	hitIDs := make([]string, 0, len(hits))
-	for _, h := range hits {
-		hitIDs = append(hitIDs, h.ID)
+// writeChainPartial 输出直接查询接口的部分结果：
+// - 至少一个地址成功：200，partial=true 时 errors 中列出失败地址
+// - 全部失败：502（ERR_UPSTREAM_UNAVAILABLE），同样带上 errors 便于 UI 逐条展示
+func (s *Server) writeChainPartial(w http.ResponseWriter, res *chainbalance.PartialResult, endpoints []string, body map[string]any) {
+	breakers := make(map[string]string, len(endpoints))
+	for _, ep := range endpoints {
+		if ep = strings.TrimSpace(ep); ep != "" {
+			breakers[ep] = s.chainBreaker.State(ep)
+		}
+	}
+	body["balances"] = res.Balances
+	body["errors"] = res.Errors
+	body["endpoints"] = res.Endpoints
+	body["breakers"] = breakers
+	body["partial"] = len(res.Errors) > 0
+
+	if len(res.Balances) == 0 && len(res.Errors) > 0 {
+		body["ok"] = false
+		body["error"] = res.Err().Error()
+		body["code"] = apperr.CodeUpstreamUnavailable
+		writeJSON(w, apperr.HTTPStatus(apperr.CodeUpstreamUnavailable), body)
+		return
 	}
+	body["ok"] = true
+	writeJSON(w, http.StatusOK, body)
+}
 
-	writeJSON(w, http.StatusOK, map[string]any{
-		"ok":            true,
-		"case_id":       caseID,
-		"device_id":     deviceID,
-		"kind":          kind,
-		"artifact_id":   artifactID,
-		"snapshot_path": snapshotPath,
-		"sha256":        sum,
-		"size_bytes":    size,
-		"balances":      balances,
-		"hit_ids":       hitIDs,
-		"warnings":      warnings,
-	})
+// failedAddresses 把失败地址整理为 address -> error 的精简形式（用于审计 detail）。
+func failedAddresses(errs map[string]chainbalance.AddressError) map[string]string {
+	out := make(map[string]string, len(errs))
+	for addr, e := range errs {
+		out[addr] = e.Error
+	}
+	return out
 }
 
 func mustJSON(v any) []byte {
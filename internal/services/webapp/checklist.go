@@ -0,0 +1,132 @@
+package webapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/services/casemeta"
+	"crypto-inspector/internal/services/checklist"
+)
+
+// handleCaseChecklist 管理案件侦查清单：
+// - GET：按生效模板返回清单（满足 auto 条件的清单项自动标记完成）
+// - POST /{item_id}：{"status","responsible","note","operator"} 修改状态/负责人；not_applicable 必须填写 note
+func (s *Server) handleCaseChecklist(w http.ResponseWriter, r *http.Request, caseID, itemID string) {
+	switch {
+	case r.Method == http.MethodGet && itemID == "":
+		items, err := checklist.Get(r.Context(), s.store, caseID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"checklist": items, "pending_required": len(checklist.Pending(items))})
+	case r.Method == http.MethodPost && itemID != "":
+		var req struct {
+			Status      string `json:"status,omitempty"`
+			Responsible string `json:"responsible,omitempty"`
+			Note        string `json:"note,omitempty"`
+			Operator    string `json:"operator,omitempty"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		item, err := checklist.SetItem(r.Context(), s.store, caseID, itemID, checklist.Update{
+			Status:      req.Status,
+			Responsible: req.Responsible,
+			Note:        req.Note,
+			Operator:    req.Operator,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "item": item})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCaseClose：POST {"profile":"internal|external","operator"} 结案。
+// external profile 下 required 清单项未全部完成时返回 409（ERR_CHECKLIST_INCOMPLETE），案件状态不变。
+func (s *Server) handleCaseClose(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Profile  string `json:"profile,omitempty"`
+		Operator string `json:"operator,omitempty"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	res, err := checklist.Close(r.Context(), s.store, caseID, req.Profile, req.Operator)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "close": res})
+}
+
+// handleCaseChecklistTemplate：GET 返回生效清单模板（未配置时为内置默认模板），POST {"yaml"} 校验并替换；yaml 为空时恢复默认。
+func (s *Server) handleCaseChecklistTemplate(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			YAML string `json:"yaml"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		if strings.TrimSpace(req.YAML) == "" {
+			if err := checklist.Reset(r.Context(), s.store); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		} else if _, err := checklist.Save(r.Context(), s.store, []byte(req.YAML)); err != nil {
+			writeError(w, http.StatusBadRequest, apperr.Wrap(apperr.CodeInvalidArgument, err, "invalid case checklist"))
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg, err := checklist.Load(r.Context(), s.store)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	raw, _ := s.store.GetSchemaMetaValue(r.Context(), checklist.SchemaKeyConfig)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":        true,
+		"builtin":   strings.TrimSpace(raw) == "",
+		"checklist": cfg,
+		"yaml":      raw,
+	})
+}
+
+// handleCasePatch 修改案件元数据：PATCH /api/cases/{case_id}
+// body：{"title"?, "case_no"?, "status"?, "profile"?, "operator"?}，只修改给出的字段（case_no 为 "" 表示清除）。
+// 状态只能 open → closed → archived；改为 closed 与 POST /close 相同，按 profile 检查清单。
+func (s *Server) handleCasePatch(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodPatch {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req casemeta.Patch
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, apperr.Wrap(apperr.CodeInvalidArgument, err, "invalid json"))
+		return
+	}
+	res, err := casemeta.Update(r.Context(), s.store, caseID, req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "case": res.Case, "changes": res.Changes})
+}
@@ -0,0 +1,70 @@
+package webapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/comments"
+)
+
+// handleCaseComments 管理证据/命中的分析评论（只追加，不提供修改/删除）：
+// - GET：列出（可选 ?target_type=artifact|hit&target_id=...）
+// - POST：新增（target_type/target_id 指定目标；parent_id 非空时为回复，目标继承主题首条）
+func (s *Server) handleCaseComments(w http.ResponseWriter, r *http.Request, caseID string) {
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		rows, err := s.store.ListComments(r.Context(), caseID, strings.TrimSpace(q.Get("target_type")), strings.TrimSpace(q.Get("target_id")))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"comments": rows})
+	case http.MethodPost:
+		var req struct {
+			TargetType string `json:"target_type,omitempty"`
+			TargetID   string `json:"target_id,omitempty"`
+			ParentID   string `json:"parent_id,omitempty"`
+			Author     string `json:"author,omitempty"`
+			Text       string `json:"text"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		ov, err := s.store.GetCaseOverview(r.Context(), caseID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if ov == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("case not found: %s", caseID))
+			return
+		}
+		saved, err := comments.Add(r.Context(), s.store, model.Comment{
+			CaseID:     caseID,
+			TargetType: req.TargetType,
+			TargetID:   req.TargetID,
+			ParentID:   req.ParentID,
+			Author:     req.Author,
+			Text:       req.Text,
+		})
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		_ = s.store.AppendAudit(r.Context(), caseID, "", "comment", "add", "success", saved.Author, "webapp.handleCaseComments", map[string]any{
+			"comment_id":  saved.CommentID,
+			"target_type": saved.TargetType,
+			"target_id":   saved.TargetID,
+			"parent_id":   saved.ParentID,
+			"text":        saved.Text,
+		})
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "comment": saved})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,120 @@
+package webapp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/services/devicecustody"
+)
+
+// handleCaseDevice 单台设备的保管信息与照片。
+//
+// - GET /api/cases/{case_id}/devices/{device_id}：设备信息（含保管字段与照片）
+// - PATCH /api/cases/{case_id}/devices/{device_id}：{"physical_label","imei","seizure_location","custody_officer","custody_note","operator"}，省略的字段不变，空字符串清除
+// - GET /api/cases/{case_id}/devices/{device_id}/photos：设备照片
+// - POST /api/cases/{case_id}/devices/{device_id}/photos：{"file_name","content_base64","caption","operator"}（PNG/JPEG）
+// - GET /api/cases/{case_id}/devices/{device_id}/photos/{photo_id}/download：下载原图
+func (s *Server) handleCaseDevice(w http.ResponseWriter, r *http.Request, caseID string, parts []string) {
+	deviceID := strings.TrimSpace(parts[0])
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			d, err := devicecustody.Get(r.Context(), s.store, caseID, deviceID)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"device": d})
+		case http.MethodPatch:
+			var p devicecustody.Patch
+			r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+			if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+				return
+			}
+			res, err := devicecustody.Update(r.Context(), s.store, caseID, deviceID, p)
+			if err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{"ok": true, "device": res.Device, "changes": res.Changes})
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	if parts[1] != "photos" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if len(parts) > 2 {
+		if len(parts) != 4 || parts[3] != "download" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		photos, err := s.store.ListDevicePhotos(r.Context(), caseID, deviceID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		for _, p := range photos {
+			if p.PhotoID == parts[2] {
+				serveFile(w, r, p.FilePath, "device_photo_"+p.PhotoID)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, fmt.Errorf("device photo not found: %s", parts[2]))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		photos, err := s.store.ListDevicePhotos(r.Context(), caseID, deviceID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"photos": photos})
+	case http.MethodPost:
+		var req struct {
+			FileName      string `json:"file_name"`
+			ContentBase64 string `json:"content_base64"`
+			Caption       string `json:"caption,omitempty"`
+			Operator      string `json:"operator,omitempty"`
+		}
+		// base64 膨胀约 4/3，再留一些 JSON 字段余量。
+		r.Body = http.MaxBytesReader(w, r.Body, devicecustody.MaxPhotoBytes/3*4+(1<<20))
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		content, err := base64.StdEncoding.DecodeString(strings.TrimSpace(req.ContentBase64))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid content_base64: %w", err))
+			return
+		}
+		photo, err := devicecustody.AttachPhoto(r.Context(), s.store, devicecustody.PhotoInput{
+			CaseID:       caseID,
+			DeviceID:     deviceID,
+			FileName:     req.FileName,
+			Content:      content,
+			Caption:      req.Caption,
+			Operator:     req.Operator,
+			EvidenceRoot: s.opts.EvidenceRoot,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "photo": photo})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,111 @@
+package webapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/services/devicedup"
+)
+
+// sseKeepAlive 是 SSE 连接的心跳间隔（防止代理/浏览器判定空闲断开）。
+const sseKeepAlive = 15 * time.Second
+
+// handleDevices 返回实时监测的当前设备快照：GET /api/devices。
+//
+// 仅在 serve --monitor 时可用；否则返回 404（ERR_NOT_FOUND）。
+func (s *Server) handleDevices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if s.monitor == nil {
+		writeError(w, http.StatusNotFound, apperr.New(apperr.CodeNotFound, "device monitor is not enabled (start serve with --monitor)"))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.monitor.Snapshot())
+}
+
+// handleDeviceEvents 以 SSE 推送设备接入/拔出/授权状态变化：GET /api/devices/events。
+//
+// 连接建立后先发送一条 snapshot 事件（当前设备列表），之后每个变化一条事件，
+// event 名即事件类型（device_connected / device_disconnected / authorization_changed）。
+func (s *Server) handleDeviceEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if s.monitor == nil {
+		writeError(w, http.StatusNotFound, apperr.New(apperr.CodeNotFound, "device monitor is not enabled (start serve with --monitor)"))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	events, cancel := s.monitor.Subscribe()
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if err := writeSSE(w, "snapshot", s.monitor.Snapshot()); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev := <-events:
+			if err := writeSSE(w, ev.Type, ev); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// handleDeviceCases 按设备标识查询其他未结案件：GET /api/devices/cases?identifier=...&exclude_case_id=...。
+//
+// UI 在开始扫描前（或设备监测发现新设备时）调用，提示操作员改用已有案件，避免同一设备并行建案。
+func (s *Server) handleDeviceCases(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	identifier := strings.TrimSpace(q.Get("identifier"))
+	if identifier == "" {
+		writeError(w, http.StatusBadRequest, apperr.New(apperr.CodeInvalidArgument, "identifier is required"))
+		return
+	}
+	refs, err := devicedup.Lookup(r.Context(), s.store, identifier, strings.TrimSpace(q.Get("exclude_case_id")))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"identifier": identifier, "cases": refs})
+}
+
+func writeSSE(w http.ResponseWriter, event string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, raw)
+	return err
+}
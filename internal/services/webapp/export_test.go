@@ -0,0 +1,52 @@
+package webapp
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestCaseExportRejectsPasswordForUnencryptedKinds(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "inspector.db")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{opts: Options{DBPath: dbPath, EvidenceRoot: t.TempDir()}, store: store}
+
+	// graph-zip / forensic-pdf 不读取密码：带密码的请求必须被拒绝，而不是生成明文文件并报告成功。
+	for _, kind := range []string{"graph-zip", "forensic-pdf"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/cases/"+caseID+"/exports/"+kind, strings.NewReader(`{"password":"s3cret-pass"}`))
+		rec := httptest.NewRecorder()
+		s.handleCaseRoutes(rec, req)
+		var body map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		if rec.Code != http.StatusBadRequest || body["code"] != "ERR_INVALID_ARGUMENT" {
+			t.Fatalf("%s: code=%d body=%v", kind, rec.Code, body)
+		}
+	}
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(1) FROM reports WHERE case_id = ?`, caseID).Scan(&n); err != nil || n != 0 {
+		t.Fatalf("reports=%d err=%v", n, err)
+	}
+}
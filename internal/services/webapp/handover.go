@@ -0,0 +1,86 @@
+package webapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// handleCaseHandover 案件负责人交接。
+//
+// - GET /api/cases/{case_id}/handover：当前负责人 + 交接历史（最新在前）
+// - POST /api/cases/{case_id}/handover：{"to_operator","note","operator"}，note 为交接说明（必填）
+func (s *Server) handleCaseHandover(w http.ResponseWriter, r *http.Request, caseID string) {
+	switch r.Method {
+	case http.MethodGet:
+		ov, err := s.store.GetCaseOverview(r.Context(), caseID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if ov == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("case not found: %s", caseID))
+			return
+		}
+		rows, err := s.store.ListCaseAssignments(r.Context(), caseID, "", 0)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"owner": ov.Owner, "assignments": rows})
+	case http.MethodPost:
+		var req struct {
+			ToOperator string `json:"to_operator"`
+			Note       string `json:"note"`
+			Operator   string `json:"operator,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		to := strings.TrimSpace(req.ToOperator)
+		note := strings.TrimSpace(req.Note)
+		if to == "" || note == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("to_operator and note are required"))
+			return
+		}
+		operator := strings.TrimSpace(req.Operator)
+		if operator == "" {
+			operator = "system"
+		}
+		a, err := s.store.HandoverCase(r.Context(), caseID, to, note, operator)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if a == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("case not found: %s", caseID))
+			return
+		}
+		_ = s.store.AppendAudit(r.Context(), caseID, "", "case", "handover", "success", operator, "webapp.handleCaseHandover", map[string]any{
+			"assignment_id": a.AssignmentID,
+			"from_operator": a.FromOperator,
+			"to_operator":   a.ToOperator,
+			"note":          a.Note,
+		})
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "assignment": a})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handleHandovers 操作员交接班日志：GET /api/handovers?operator=NAME 返回该操作员交出或接收的交接记录。
+func (s *Server) handleHandovers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	operator := strings.TrimSpace(r.URL.Query().Get("operator"))
+	rows, err := s.store.ListCaseAssignments(r.Context(), "", operator, parseInt(r.URL.Query().Get("limit"), 200))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"assignments": rows})
+}
@@ -0,0 +1,82 @@
+package webapp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"crypto-inspector/internal/services/forensicimport"
+)
+
+// maxImportBytes 第三方取证报告上传上限（UFED report.xml 可能较大）。
+const maxImportBytes = 512 << 20
+
+// handleCaseImports 导入第三方取证工具导出的报告（Cellebrite UFED / Magnet AXIOM / Plaso / Autopsy）。
+//
+// POST /api/cases/{case_id}/imports?file_name=report.xml&format=&os=&device_id=&device_name=&operator=&note=
+// 请求体为原始文件内容；先落盘到证据目录下的临时文件，再交给 forensicimport 解析、固化与规则匹配。
+func (s *Server) handleCaseImports(w http.ResponseWriter, r *http.Request, caseID string) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	operator := strings.TrimSpace(q.Get("operator"))
+	if operator == "" {
+		operator = "system"
+	}
+
+	if err := os.MkdirAll(s.opts.EvidenceRoot, 0o755); err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("create evidence root: %w", err))
+		return
+	}
+	tmp, err := os.CreateTemp(s.opts.EvidenceRoot, ".import-*")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("create temp file: %w", err))
+		return
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBytes)
+	n, err := io.Copy(tmp, r.Body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("read upload: %w", err))
+		return
+	}
+	if n == 0 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("empty upload body"))
+		return
+	}
+
+	fileName := filepath.Base(strings.TrimSpace(q.Get("file_name")))
+	if fileName == "." || fileName == string(filepath.Separator) {
+		fileName = ""
+	}
+	walletRulePath, exchangeRulePath := s.activeRulePaths(r.Context())
+	res, err := forensicimport.Run(r.Context(), s.store, forensicimport.Input{
+		CaseID:           caseID,
+		SourcePath:       tmpPath,
+		FileName:         fileName,
+		Format:           q.Get("format"),
+		OS:               q.Get("os"),
+		DeviceID:         q.Get("device_id"),
+		DeviceName:       q.Get("device_name"),
+		Operator:         operator,
+		Note:             q.Get("note"),
+		EvidenceRoot:     s.opts.EvidenceRoot,
+		WalletRulePath:   walletRulePath,
+		ExchangeRulePath: exchangeRulePath,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "import": res})
+}
@@ -9,24 +9,36 @@ import (
 	"sync"
 	"time"
 
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/budget"
+	"crypto-inspector/internal/platform/i18n"
 	"crypto-inspector/internal/platform/id"
+	"crypto-inspector/internal/platform/progress"
+	"crypto-inspector/internal/platform/trace"
+	"crypto-inspector/internal/services/artifactverify"
+	"crypto-inspector/internal/services/exporter"
 	"crypto-inspector/internal/services/hostscan"
 	"crypto-inspector/internal/services/mobilescan"
+	"crypto-inspector/internal/services/privacy"
+	"crypto-inspector/internal/services/training"
 )
 
 type jobManager struct {
 	mu   sync.Mutex
 	jobs map[string]*scanAllJob
+	// cancels 保存可取消 job（目前为导出 job）的 cancel 函数，job 结束后移除。
+	cancels map[string]context.CancelFunc
 }
 
 func newJobManager() *jobManager {
-	return &jobManager{jobs: make(map[string]*scanAllJob)}
+	return &jobManager{jobs: make(map[string]*scanAllJob), cancels: make(map[string]context.CancelFunc)}
 }
 
 type scanAllJob struct {
 	JobID      string `json:"job_id"`
 	Kind       string `json:"kind"`
-	Status     string `json:"status"` // running|success|failed
+	Status     string `json:"status"` // running|success|failed|canceled
 	CreatedAt  int64  `json:"created_at"`
 	StartedAt  int64  `json:"started_at"`
 	FinishedAt int64  `json:"finished_at"`
@@ -40,11 +52,25 @@ type scanAllJob struct {
 
 	CaseID string `json:"case_id,omitempty"`
 
-	Host      *hostscan.Result `json:"host,omitempty"`
-	HostError string           `json:"host_error,omitempty"`
+	// TraceID 与发起请求的 X-Request-ID 一致，job 内所有审计日志都带同一个 trace_id。
+	TraceID string `json:"trace_id,omitempty"`
 
-	Mobile      *mobilescan.Result `json:"mobile,omitempty"`
-	MobileError string             `json:"mobile_error,omitempty"`
+	Host          *hostscan.Result `json:"host,omitempty"`
+	HostError     string           `json:"host_error,omitempty"`
+	HostErrorCode apperr.Code      `json:"host_error_code,omitempty"`
+
+	Mobile          *mobilescan.Result `json:"mobile,omitempty"`
+	MobileError     string             `json:"mobile_error,omitempty"`
+	MobileErrorCode apperr.Code        `json:"mobile_error_code,omitempty"`
+
+	// VerifyProgress/Verify 仅用于 verify_artifacts job（证据快照批量哈希复核）。
+	VerifyProgress *artifactverify.Progress `json:"verify_progress,omitempty"`
+	Verify         *artifactverify.Result   `json:"verify,omitempty"`
+
+	// ExportKind/ExportProgress/Export 仅用于 export job（报告/导出包后台生成），Export 与同步导出接口的响应相同。
+	ExportKind     string           `json:"export_kind,omitempty"`
+	ExportProgress *progress.Update `json:"export_progress,omitempty"`
+	Export         map[string]any   `json:"export,omitempty"`
 
 	Error string `json:"error,omitempty"`
 }
@@ -60,6 +86,47 @@ func (m *jobManager) put(job *scanAllJob) {
 	m.jobs[job.JobID] = job
 }
 
+// countRunning 返回 kind 类型的运行中 job 数。
+func (m *jobManager) countRunning(kind string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, j := range m.jobs {
+		if j != nil && j.Kind == kind && j.Status == "running" {
+			n++
+		}
+	}
+	return n
+}
+
+// setCancel 登记 job 的 cancel 函数；cancel 为 nil 时移除。
+func (m *jobManager) setCancel(jobID string, cancel context.CancelFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if cancel == nil {
+		delete(m.cancels, jobID)
+		return
+	}
+	m.cancels[jobID] = cancel
+}
+
+// cancel 取消运行中的 job；job 不存在返回 false，不可取消或已结束时返回错误。
+func (m *jobManager) cancel(jobID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[jobID]
+	if !ok || j == nil {
+		return false, nil
+	}
+	cancel, ok := m.cancels[jobID]
+	if !ok || j.Status != "running" {
+		return true, apperr.T(apperr.CodeConflict, "job.not_cancelable", jobID, j.Kind, j.Status)
+	}
+	cancel()
+	j.Logs = append(j.Logs, jobLogLine{Time: time.Now().Unix(), Message: "cancel requested"})
+	return true, nil
+}
+
 func (m *jobManager) getCopy(jobID string) (scanAllJob, bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -103,14 +170,26 @@ type scanAllRequest struct {
 	CaseID        string `json:"case_id,omitempty"`
 	AuthOrder     string `json:"auth_order,omitempty"`
 	AuthBasis     string `json:"auth_basis,omitempty"`
-	PrivacyMode   string `json:"privacy_mode,omitempty"` // off|masked（预留）
+	PrivacyMode   string `json:"privacy_mode,omitempty"` // off|masked（partial 按 off 扫描）
 	IOSFullBackup *bool  `json:"ios_full_backup,omitempty"`
 
+	// 链上域名解析 RPC（为空则只记录 .eth/.bnb 域名，不做解析）
+	ETHRPCURL string `json:"eth_rpc_url,omitempty"`
+	BNBRPCURL string `json:"bnb_rpc_url,omitempty"`
+
 	// 采集范围控制（UI 勾选项对齐）
 	EnableHost    *bool `json:"enable_host,omitempty"`
 	EnableMobile  *bool `json:"enable_mobile,omitempty"`
 	EnableAndroid *bool `json:"enable_android,omitempty"`
 	EnableIOS     *bool `json:"enable_ios,omitempty"`
+	EnableHarmony *bool `json:"enable_harmony,omitempty"`
+
+	// ScanMessengers 开启 Telegram/Discord 痕迹采集（可选，默认关闭）。
+	ScanMessengers bool `json:"scan_messengers,omitempty"`
+
+	// 采集预算：主机历史库副本与 iOS 完整备份共享（0 表示不限）；SkipHistoryDB 取消勾选原始历史库复制。
+	MaxBytes      int64 `json:"max_bytes,omitempty"`
+	SkipHistoryDB bool  `json:"skip_history_db,omitempty"`
 }
 
 func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
@@ -153,14 +232,14 @@ func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
 	if privacyMode == "" {
 		privacyMode = s.opts.PrivacyMode
 	}
-	if privacyMode != "off" && privacyMode != "masked" {
-		privacyMode = "off"
-	}
+	// partial 只作用于界面展示，扫描报告保留完整数据。
+	privacyMode = privacy.ScanMode(privacyMode)
 
 	jobID := id.New("job")
 	now := time.Now().Unix()
 	job := &scanAllJob{
 		JobID:     jobID,
+		TraceID:   trace.IDFromContext(r.Context()),
 		Kind:      "scan_all",
 		Status:    "running",
 		CreatedAt: now,
@@ -176,9 +255,12 @@ func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
 
 	// 先返回一份拷贝，避免后台 goroutine 修改同一对象导致数据竞争。
 	resp := *job
+	jobCtx := jobContext(r, job.TraceID)
 
 	go func() {
-		ctx := context.Background()
+		// 后台任务不能继承请求 context（请求结束即取消），但要沿用同一个 trace_id 与请求语言。
+		ctx, span := trace.Start(jobCtx, "job.scan_all")
+		defer span.End(nil)
 
 		// 每个 job 启动时读取一次“当前启用的规则文件路径”，保证：
 		// - UI 中导入/切换规则后，下一次扫描能立刻生效
@@ -194,6 +276,11 @@ func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
 		if req.EnableMobile != nil {
 			enableMobile = *req.EnableMobile
 		}
+		// 演练模式没有合成的移动端数据源，移动端扫描不执行（不连接真实设备）。
+		trainingMobileSkipped := s.opts.Training && enableMobile
+		if trainingMobileSkipped {
+			enableMobile = false
+		}
 		enableAndroid := true
 		if req.EnableAndroid != nil {
 			enableAndroid = *req.EnableAndroid
@@ -202,6 +289,10 @@ func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
 		if req.EnableIOS != nil {
 			enableIOS = *req.EnableIOS
 		}
+		enableHarmony := true
+		if req.EnableHarmony != nil {
+			enableHarmony = *req.EnableHarmony
+		}
 
 		// 内部辅助：追加一条 job 日志并更新 stage/progress（带锁，避免 data race）
 		update := func(stage string, progress int, msg string) {
@@ -222,25 +313,48 @@ func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
 		}
 
 		caseID := strings.TrimSpace(req.CaseID)
+		var scanBudget *budget.Budget
+		if req.MaxBytes > 0 || req.SkipHistoryDB || !enableBackup {
+			scanBudget = budget.New(req.MaxBytes)
+		}
 
 		// --- host scan ---
 		var hostRes *hostscan.Result
 		var hostErr error
 		if enableHost {
 			update("host_scan", 5, "host scan starting")
-			hostRes, hostErr = hostscan.Run(ctx, hostscan.Options{
-				DBPath:             s.opts.DBPath,
-				EvidenceRoot:       s.opts.EvidenceRoot,
-				WalletRulePath:     walletRulePath,
-				ExchangeRulePath:   exchangeRulePath,
-				CaseID:             caseID,
-				Operator:           operator,
-				Note:               strings.TrimSpace(req.Note),
-				AuthorizationOrder: strings.TrimSpace(req.AuthOrder),
-				AuthorizationBasis: strings.TrimSpace(req.AuthBasis),
-				RequireAuthOrder:   requireAuthOrder,
-				PrivacyMode:        privacyMode,
-			})
+			hostOpts := hostscan.Options{
+				DBPath:              s.opts.DBPath,
+				EvidenceRoot:        s.opts.EvidenceRoot,
+				WalletRulePath:      walletRulePath,
+				ExchangeRulePath:    exchangeRulePath,
+				RulesCache:          s.rulesCache,
+				CaseID:              caseID,
+				Operator:            operator,
+				Note:                strings.TrimSpace(req.Note),
+				AuthorizationOrder:  strings.TrimSpace(req.AuthOrder),
+				AuthorizationBasis:  strings.TrimSpace(req.AuthBasis),
+				RequireAuthOrder:    requireAuthOrder,
+				Profile:             profile,
+				PrivacyMode:         privacyMode,
+				SnapshotCompression: s.opts.SnapshotCompression,
+				ETHRPCURL:           strings.TrimSpace(req.ETHRPCURL),
+				BNBRPCURL:           strings.TrimSpace(req.BNBRPCURL),
+				ScanMessengers:      req.ScanMessengers,
+				Budget:              scanBudget,
+				SkipHistoryDB:       req.SkipHistoryDB,
+			}
+			if s.opts.Training {
+				// 演练模式：对内置合成数据源做离线扫描，不采集本机。
+				src := training.SourcesDir(s.opts.DBPath)
+				if hostErr = training.WriteSources(src); hostErr == nil {
+					update("", -1, "training mode: scanning synthetic sources "+src)
+					hostOpts = training.HostScanOptions(hostOpts, src)
+				}
+			}
+			if hostErr == nil {
+				hostRes, hostErr = hostscan.Run(ctx, hostOpts)
+			}
 			if hostRes != nil && strings.TrimSpace(hostRes.CaseID) != "" {
 				caseID = strings.TrimSpace(hostRes.CaseID)
 			}
@@ -248,6 +362,7 @@ func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
 			job.Host = hostRes
 			if hostErr != nil {
 				job.HostError = hostErr.Error()
+				job.HostErrorCode = apperr.CodeOf(hostErr)
 				job.Logs = append(job.Logs, jobLogLine{Time: time.Now().Unix(), Message: "host scan failed: " + hostErr.Error()})
 			} else {
 				job.Logs = append(job.Logs, jobLogLine{Time: time.Now().Unix(), Message: "host scan finished"})
@@ -270,17 +385,22 @@ func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
 				IOSBackupDir:        s.opts.IOSBackupDir,
 				WalletRulePath:      walletRulePath,
 				ExchangeRulePath:    exchangeRulePath,
+				RulesCache:          s.rulesCache,
 				CaseID:              caseID,
 				Operator:            operator,
 				Note:                strings.TrimSpace(req.Note),
 				AuthorizationOrder:  strings.TrimSpace(req.AuthOrder),
 				AuthorizationBasis:  strings.TrimSpace(req.AuthBasis),
 				RequireAuthOrder:    requireAuthOrder,
+				Profile:             profile,
 				RequireAuthorized:   requireAuthorized,
 				EnableIOSFullBackup: enableBackup,
 				EnableAndroid:       enableAndroid,
 				EnableIOS:           enableIOS,
+				EnableHarmony:       enableHarmony,
 				PrivacyMode:         privacyMode,
+				SnapshotCompression: s.opts.SnapshotCompression,
+				Budget:              scanBudget,
 			})
 			if mobileRes != nil && strings.TrimSpace(mobileRes.CaseID) != "" {
 				caseID = strings.TrimSpace(mobileRes.CaseID)
@@ -289,6 +409,7 @@ func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
 			job.Mobile = mobileRes
 			if mobileErr != nil {
 				job.MobileError = mobileErr.Error()
+				job.MobileErrorCode = apperr.CodeOf(mobileErr)
 				job.Logs = append(job.Logs, jobLogLine{Time: time.Now().Unix(), Message: "mobile scan failed: " + mobileErr.Error()})
 			} else {
 				job.Logs = append(job.Logs, jobLogLine{Time: time.Now().Unix(), Message: "mobile scan finished"})
@@ -296,6 +417,8 @@ func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
 			job.CaseID = caseID
 			job.Progress = 90
 			s.jobs.mu.Unlock()
+		} else if trainingMobileSkipped {
+			update("mobile_scan", 60, "mobile scan skipped (training mode has no synthetic mobile sources)")
 		} else {
 			update("mobile_scan", 60, "mobile scan skipped")
 		}
@@ -310,7 +433,8 @@ func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
 
 		if enableHost && hostErr != nil && enableMobile && mobileErr != nil {
 			job.Status = "failed"
-			job.Error = fmt.Sprintf("host=%v; mobile=%v", hostErr, mobileErr)
+			loc := i18n.FromContext(ctx)
+			job.Error = fmt.Sprintf("host=%s; mobile=%s", apperr.Localize(loc, hostErr), apperr.Localize(loc, mobileErr))
 			job.Logs = append(job.Logs, jobLogLine{Time: time.Now().Unix(), Message: "job failed"})
 			return
 		}
@@ -322,14 +446,162 @@ func (s *Server) handleJobScanAll(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, resp)
 }
 
+// startVerifyArtifactsJob 以后台 job 执行证据快照哈希复核，进度写入 job.VerifyProgress 供轮询。
+func (s *Server) startVerifyArtifactsJob(w http.ResponseWriter, r *http.Request, caseID string, targets []model.ArtifactInfo, opts artifactverify.Options, audit func(context.Context, *artifactverify.Result, error) string) {
+	now := time.Now().Unix()
+	job := &scanAllJob{
+		JobID:     id.New("job"),
+		TraceID:   trace.IDFromContext(r.Context()),
+		Kind:      "verify_artifacts",
+		Status:    "running",
+		CreatedAt: now,
+		StartedAt: now,
+		Stage:     "verify",
+		CaseID:    caseID,
+		Logs: []jobLogLine{{
+			Time:    now,
+			Message: fmt.Sprintf("job created: %d artifacts", len(targets)),
+		}},
+	}
+	s.jobs.put(job)
+	resp := *job
+	jobCtx := jobContext(r, job.TraceID)
+
+	go func() {
+		ctx, span := trace.Start(jobCtx, "job.verify_artifacts")
+		defer span.End(nil)
+
+		opts.Progress = func(p artifactverify.Progress) {
+			s.jobs.mu.Lock()
+			defer s.jobs.mu.Unlock()
+			job.VerifyProgress = &p
+			job.Progress = p.Percent()
+		}
+		res, err := artifactverify.Run(ctx, targets, opts)
+		status := "failed"
+		if res != nil {
+			status = audit(ctx, res, err)
+		}
+
+		s.jobs.mu.Lock()
+		defer s.jobs.mu.Unlock()
+		job.Stage = "finished"
+		job.FinishedAt = time.Now().Unix()
+		job.Status = status
+		if res == nil {
+			job.Error = apperr.Localize(i18n.FromContext(ctx), err)
+			job.Logs = append(job.Logs, jobLogLine{Time: job.FinishedAt, Message: "job failed: " + err.Error()})
+			return
+		}
+		job.Verify = res
+		job.Progress = 100
+		if err != nil {
+			job.Error = apperr.Localize(i18n.FromContext(ctx), err)
+		}
+		job.Logs = append(job.Logs, jobLogLine{
+			Time:    job.FinishedAt,
+			Message: fmt.Sprintf("verify finished: ok=%d failed=%d resumed=%d elapsed=%.1fs", res.OKCount, res.Failed(), res.ResumedCount, res.ElapsedSec),
+		})
+	}()
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// startExportJob 以后台 job 执行导出，进度写入 job.ExportProgress，可通过 POST /api/jobs/{job_id}/cancel 取消。
+// job 使用独立 context（不随发起请求结束而取消）；运行中的导出 job 数受 RateLimit.MaxExports 约束（请求返回后限流闸门已释放）。
+func (s *Server) startExportJob(w http.ResponseWriter, r *http.Request, e exporter.Exporter, req exporter.Request) {
+	if limit := s.opts.RateLimit.withDefaults().MaxExports; !s.opts.RateLimit.Disabled && s.jobs.countRunning("export") >= limit {
+		writeRateLimited(w, 30*time.Second, i18n.M("ratelimit.export_jobs", limit))
+		return
+	}
+	now := time.Now().Unix()
+	job := &scanAllJob{
+		JobID:      id.New("job"),
+		TraceID:    trace.IDFromContext(r.Context()),
+		Kind:       "export",
+		ExportKind: e.Kind(),
+		Status:     "running",
+		CreatedAt:  now,
+		StartedAt:  now,
+		Stage:      "load",
+		CaseID:     req.CaseID,
+		Logs: []jobLogLine{{
+			Time:    now,
+			Message: "job created: export " + e.Kind(),
+		}},
+	}
+	ctx, cancel := context.WithCancel(jobContext(r, job.TraceID))
+	s.jobs.put(job)
+	s.jobs.setCancel(job.JobID, cancel)
+	resp := *job
+
+	go func() {
+		defer cancel()
+		defer s.jobs.setCancel(job.JobID, nil)
+		ctx, span := trace.Start(ctx, "job.export")
+		defer span.End(nil)
+
+		ctx = progress.WithReporter(ctx, func(u progress.Update) {
+			s.jobs.mu.Lock()
+			defer s.jobs.mu.Unlock()
+			job.ExportProgress = &u
+			job.Stage = u.Stage
+			job.Progress = u.Percent
+		})
+		out, err := s.runCaseExport(ctx, e, req)
+
+		s.jobs.mu.Lock()
+		defer s.jobs.mu.Unlock()
+		job.FinishedAt = time.Now().Unix()
+		switch {
+		case err != nil && ctx.Err() != nil:
+			job.Status = "canceled"
+			job.Error = apperr.Localize(i18n.FromContext(ctx), err)
+			job.Logs = append(job.Logs, jobLogLine{Time: job.FinishedAt, Message: "job canceled"})
+		case err != nil:
+			job.Status = "failed"
+			job.Error = apperr.Localize(i18n.FromContext(ctx), err)
+			job.Logs = append(job.Logs, jobLogLine{Time: job.FinishedAt, Message: "job failed: " + err.Error()})
+		default:
+			job.Status = "success"
+			job.Stage = "finished"
+			job.Progress = 100
+			job.Export = out
+			job.Logs = append(job.Logs, jobLogLine{Time: job.FinishedAt, Message: fmt.Sprintf("export finished: report_id=%v", out["report_id"])})
+		}
+	}()
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleJobRoutes 处理：
+// - GET /api/jobs/：全部 job
+// - GET /api/jobs/{job_id}：单个 job
+// - POST /api/jobs/{job_id}/cancel：取消运行中的可取消 job（导出 job）
 func (s *Server) handleJobRoutes(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/api/jobs/")
+	rest = strings.Trim(rest, "/")
+	if jobID, ok := strings.CutSuffix(rest, "/cancel"); ok {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		found, err := s.jobs.cancel(jobID)
+		switch {
+		case !found:
+			writeError(w, http.StatusNotFound, fmt.Errorf("job not found: %s", jobID))
+		case err != nil:
+			writeError(w, http.StatusConflict, err)
+		default:
+			writeJSON(w, http.StatusAccepted, map[string]any{"ok": true, "job_id": jobID})
+		}
+		return
+	}
 	if r.Method != http.MethodGet {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
 
-	rest := strings.TrimPrefix(r.URL.Path, "/api/jobs/")
-	rest = strings.Trim(rest, "/")
 	if rest == "" {
 		// 简单返回全部 job（内测用，后续可加 limit/排序）
 		writeJSON(w, http.StatusOK, map[string]any{
@@ -345,3 +617,8 @@ func (s *Server) handleJobRoutes(w http.ResponseWriter, r *http.Request) {
 	}
 	writeJSON(w, http.StatusOK, job)
 }
+
+// jobContext 是后台任务的 context：不继承请求的取消，但沿用 trace_id 与请求语言（任务错误按发起请求的语言渲染）。
+func jobContext(r *http.Request, traceID string) context.Context {
+	return i18n.WithLocalizer(trace.WithTraceID(context.Background(), traceID), i18n.FromContext(r.Context()))
+}
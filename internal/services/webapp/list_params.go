@@ -0,0 +1,127 @@
+package webapp
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+)
+
+// 列表接口的公共查询参数
+//
+// hits / artifacts / audits 共用：cursor、limit、sort、order、since、until（unix 秒），
+// 各接口另有自己的筛选字段。响应中的 next_cursor 非空时表示还有下一页。
+// 数值参数格式错误时返回 400，避免静默忽略筛选条件导致结果被误读。
+
+// listParams 解析查询参数中的数值字段，并记录第一个格式错误。
+type listParams struct {
+	q   url.Values
+	err error
+}
+
+func (p *listParams) str(key string) string {
+	return strings.TrimSpace(p.q.Get(key))
+}
+
+func (p *listParams) int64(key string) int64 {
+	v := p.str(key)
+	if v == "" || p.err != nil {
+		return 0
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		p.err = apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid %s: %q (want a non-negative integer)", key, v))
+		return 0
+	}
+	return n
+}
+
+func (p *listParams) float(key string) float64 {
+	v := p.str(key)
+	if v == "" || p.err != nil {
+		return 0
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 || f > 1 {
+		p.err = apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid %s: %q (want 0..1)", key, v))
+		return 0
+	}
+	return f
+}
+
+func (p *listParams) page() model.Page {
+	return model.Page{
+		Cursor: p.str("cursor"),
+		Limit:  int(p.int64("limit")),
+		Sort:   p.str("sort"),
+		Order:  p.str("order"),
+	}
+}
+
+func parseHitQuery(q url.Values) (model.HitQuery, error) {
+	p := &listParams{q: q}
+	out := model.HitQuery{
+		Page:           p.page(),
+		HitType:        p.str("hit_type"),
+		Verdict:        p.str("verdict"),
+		DeviceID:       p.str("device_id"),
+		WalletCategory: p.walletCategory("wallet_category"),
+		MinConfidence:  p.float("min_confidence"),
+		MaxConfidence:  p.float("max_confidence"),
+		Since:          p.int64("since"),
+		Until:          p.int64("until"),
+	}
+	return out, p.err
+}
+
+// walletCategory 解析钱包托管类型筛选（合法类型或 uncategorized）。
+func (p *listParams) walletCategory(key string) string {
+	v := p.str(key)
+	if v == "" || p.err != nil || v == model.WalletCategoryUncategorized || model.IsWalletCategory(v) {
+		return v
+	}
+	p.err = apperr.New(apperr.CodeInvalidArgument, fmt.Sprintf("invalid %s: %q (want one of %s, %s)", key, v, strings.Join(model.WalletCategories(), ", "), model.WalletCategoryUncategorized))
+	return ""
+}
+
+func parseArtifactQuery(q url.Values) (model.ArtifactQuery, error) {
+	p := &listParams{q: q}
+	out := model.ArtifactQuery{
+		Page:         p.page(),
+		ArtifactType: p.str("artifact_type"),
+		DeviceID:     p.str("device_id"),
+		Since:        p.int64("since"),
+		Until:        p.int64("until"),
+	}
+	return out, p.err
+}
+
+func parseAuditQuery(q url.Values) (model.AuditQuery, error) {
+	p := &listParams{q: q}
+	out := model.AuditQuery{
+		Page:      p.page(),
+		EventType: p.str("event_type"),
+		Status:    p.str("status"),
+		Actor:     p.str("actor"),
+		DeviceID:  p.str("device_id"),
+		Since:     p.int64("since"),
+		Until:     p.int64("until"),
+	}
+	return out, p.err
+}
+
+// parseRuleHitStatsQuery 解析规则命中统计的筛选参数（/api/rules 与 /api/rules/stats 共用）。
+func parseRuleHitStatsQuery(q url.Values) (model.RuleHitStatsQuery, error) {
+	p := &listParams{q: q}
+	out := model.RuleHitStatsQuery{
+		HitType:       p.str("hit_type"),
+		RuleID:        p.str("rule_id"),
+		BundleVersion: p.str("bundle_version"),
+		Since:         p.int64("since"),
+		Until:         p.int64("until"),
+	}
+	return out, p.err
+}
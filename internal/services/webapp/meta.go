@@ -1,6 +1,7 @@
 package webapp
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -19,8 +20,10 @@ func (s *Server) handleMeta(w http.ResponseWriter, r *http.Request) {
 	schemaName, _ := s.store.GetSchemaMetaValue(r.Context(), "schema_name")
 
 	walletPath, exchangePath := s.activeRulePaths(r.Context())
-	loader := rules.NewLoader(walletPath, exchangePath)
-	loaded, err := loader.Load(r.Context())
+	// 正则规则不走规则管理（上传/切换），扫描始终使用默认路径。
+	regexPath := app.DefaultConfig().RegexRulePath
+	loader := rules.NewLoader(walletPath, exchangePath).WithRegexFile(regexPath)
+	loaded, err := s.rulesCache.Load(r.Context(), loader)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err)
 		return
@@ -33,7 +36,14 @@ func (s *Server) handleMeta(w http.ResponseWriter, r *http.Request) {
 			"version":    app.Version,
 			"commit":     app.Commit,
 			"build_time": app.BuildTime,
+			"components": app.Components(),
 		},
+		"privacy": map[string]any{
+			"mode":          s.opts.PrivacyMode,
+			"unmask_header": unmaskHeader,
+		},
+		// training：演练模式（serve --training）或当前库为演练库时为 true，界面据此显示演练标识。
+		"training": s.isTraining(r.Context()),
 		"db": map[string]any{
 			"schema_version": schemaVersion,
 			"schema_name":    schemaName,
@@ -54,6 +64,15 @@ func (s *Server) handleMeta(w http.ResponseWriter, r *http.Request) {
 				"enabled": countEnabledExchanges(loaded.Exchange.Exchanges),
 				"sha256":  loaded.ExchangeSHA256,
 			},
+			"regex": map[string]any{
+				"path":    regexPath,
+				"loaded":  loaded.RegexSHA256 != "",
+				"version": loaded.Regex.Version,
+				"total":   len(loaded.Regex.Rules),
+				"enabled": len(loaded.RegexRules),
+				"sha256":  loaded.RegexSHA256,
+			},
+			"cache": s.rulesCache.Stats(),
 		},
 	})
 }
@@ -77,3 +96,12 @@ func countEnabledExchanges(exchanges []model.ExchangeDomain) int {
 	}
 	return total
 }
+
+// isTraining 判断当前是否处于演练模式（启动参数或库内演练标记）。
+func (s *Server) isTraining(ctx context.Context) bool {
+	if s.opts.Training {
+		return true
+	}
+	training, _ := s.store.IsTrainingDB(ctx)
+	return training
+}
@@ -0,0 +1,51 @@
+package webapp
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"crypto-inspector/internal/platform/i18n"
+	"crypto-inspector/internal/platform/trace"
+)
+
+// reRequestID 限制外部传入的 X-Request-ID 格式，避免把任意字符串写进审计日志。
+var reRequestID = regexp.MustCompile(`^[0-9A-Za-z_\-]{8,64}$`)
+
+// withTracing 为每个请求分配 trace_id：
+// - 优先沿用 W3C traceparent 的 trace-id，其次沿用 X-Request-ID
+// - 都没有时生成新的 trace_id
+// - 通过响应头 X-Request-ID 回传，方便现场把 UI 报错与服务端审计日志对应起来
+func withTracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID := trace.ParseTraceparent(r.Header.Get("traceparent"))
+		if traceID == "" {
+			if v := strings.TrimSpace(r.Header.Get("X-Request-ID")); reRequestID.MatchString(v) {
+				traceID = v
+			}
+		}
+		ctx := trace.WithTraceID(r.Context(), traceID)
+		ctx, span := trace.Start(ctx, "http "+r.Method+" "+r.URL.Path)
+		defer span.End(nil)
+
+		w.Header().Set("X-Request-ID", span.TraceID())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// withLocale 确定请求的界面语言（?lang= 优先，其次 Accept-Language，最后是服务默认语言），
+// 把 Localizer 放入请求 context 供服务层使用，并通过 Content-Language 回传；
+// writeError 按该响应头渲染 error 字段，code 字段不受语言影响。
+func withLocale(next http.Handler, def i18n.Lang) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang, ok := i18n.Parse(r.URL.Query().Get("lang"))
+		if !ok {
+			lang, ok = i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"))
+		}
+		if !ok {
+			lang = def
+		}
+		w.Header().Set("Content-Language", string(lang))
+		next.ServeHTTP(w, r.WithContext(i18n.WithLocalizer(r.Context(), i18n.New(lang))))
+	})
+}
@@ -0,0 +1,47 @@
+package webapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"crypto-inspector/internal/platform/trace"
+)
+
+func TestWithTracing(t *testing.T) {
+	t.Parallel()
+
+	var seen string
+	h := withTracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = trace.IDFromContext(r.Context())
+	}))
+	for name, tc := range map[string]struct {
+		hdr  map[string]string
+		want string // 为空表示应生成新的 trace_id
+	}{
+		"traceparent wins": {map[string]string{
+			"traceparent":  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"X-Request-ID": "req-12345678",
+		}, "4bf92f3577b34da6a3ce929d0e0e4736"},
+		"request id":         {map[string]string{"X-Request-ID": "req-12345678"}, "req-12345678"},
+		"invalid request id": {map[string]string{"X-Request-ID": "bad id; drop table"}, ""},
+		"none":               {nil, ""},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/cases", nil)
+		for k, v := range tc.hdr {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		got := rec.Header().Get("X-Request-ID")
+		if got != seen {
+			t.Fatalf("%s: response id %q != context id %q", name, got, seen)
+		}
+		if tc.want != "" && got != tc.want {
+			t.Fatalf("%s: got %q want %q", name, got, tc.want)
+		}
+		if tc.want == "" && len(got) != 32 {
+			t.Fatalf("%s: expected generated trace id, got %q", name, got)
+		}
+	}
+}
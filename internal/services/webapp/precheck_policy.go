@@ -0,0 +1,54 @@
+package webapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/services/precheckpolicy"
+)
+
+// handlePrecheckPolicy 前置检查策略（profile → 必需检查码 + block/warn）。
+//
+// - GET /api/precheck-policy：当前生效策略（未配置时 configured=false，沿用内置检查）
+// - POST /api/precheck-policy：{"yaml": "..."} 校验并替换策略；yaml 为空时清除策略
+func (s *Server) handlePrecheckPolicy(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			YAML string `json:"yaml"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		if strings.TrimSpace(req.YAML) == "" {
+			if err := precheckpolicy.Reset(r.Context(), s.store); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		} else if _, err := precheckpolicy.Save(r.Context(), s.store, []byte(req.YAML)); err != nil {
+			writeError(w, http.StatusBadRequest, apperr.Wrap(apperr.CodeInvalidArgument, err, "invalid precheck policy"))
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	policy, err := precheckpolicy.Load(r.Context(), s.store)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	raw, _ := s.store.GetSchemaMetaValue(r.Context(), precheckpolicy.SchemaKeyPolicy)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":         true,
+		"configured": policy.Configured(),
+		"policy":     policy,
+		"yaml":       raw,
+	})
+}
@@ -0,0 +1,69 @@
+package webapp
+
+import (
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/services/privacy"
+)
+
+// unmaskHeader 携带 unmask 令牌（serve --privacy-mode partial 时生效）。
+const unmaskHeader = "X-Unmask-Token"
+
+// partialPrivacy 表示界面默认脱敏、导出/下载需 unmask 权限。
+func (s *Server) partialPrivacy() bool {
+	return s.opts.PrivacyMode == privacy.ModePartial
+}
+
+// unmask 判断本次请求能否拿到完整数据：
+// - 非 partial 模式：始终可以（不记审计）
+// - 未携带令牌：不可以（调用方按脱敏视图响应，或拒绝导出）
+// - 令牌无效：返回 CodeUnmaskDenied，并记一条 failed 审计
+// - 令牌有效：返回持有权限的操作员，并记一条 success 审计（每次解除脱敏都留痕）
+func (s *Server) unmask(r *http.Request, caseID, action string) (string, bool, error) {
+	if !s.partialPrivacy() {
+		return "", true, nil
+	}
+	token := strings.TrimSpace(r.Header.Get(unmaskHeader))
+	if token == "" {
+		return "", false, nil
+	}
+	operator, ok := s.opts.UnmaskGrants.Operator(token)
+	detail := map[string]any{
+		"action": action,
+		"method": r.Method,
+		"path":   r.URL.Path,
+	}
+	if !ok {
+		detail["error"] = "invalid unmask token"
+		_ = s.store.AppendAudit(r.Context(), caseID, "", "privacy", "unmask", "failed", "unknown", "webapp.unmask", detail)
+		return "", false, apperr.T(apperr.CodeUnmaskDenied, "unmask.invalid_token")
+	}
+	_ = s.store.AppendAudit(r.Context(), caseID, "", "privacy", "unmask", "success", operator, "webapp.unmask", detail)
+	return operator, true, nil
+}
+
+// requireUnmask 用于导出/下载原件等操作：partial 模式下没有 unmask 权限时直接返回 403。
+func (s *Server) requireUnmask(w http.ResponseWriter, r *http.Request, caseID, action string) (string, bool) {
+	operator, ok, err := s.unmask(r, caseID, action)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return "", false
+	}
+	if !ok {
+		writeError(w, http.StatusForbidden, apperr.T(apperr.CodeUnmaskDenied, "unmask.required", unmaskHeader))
+		return "", false
+	}
+	return operator, true
+}
+
+// maskedView 用于界面列表接口：返回 true 时调用方应以脱敏视图响应；令牌无效时已写出 403。
+func (s *Server) maskedView(w http.ResponseWriter, r *http.Request, caseID, action string) (masked bool, handled bool) {
+	_, ok, err := s.unmask(r, caseID, action)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return false, true
+	}
+	return !ok, false
+}
@@ -0,0 +1,204 @@
+package webapp
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crypto-inspector/internal/adapters/rules"
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/services/privacy"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestPartialPrivacyUnmask(t *testing.T) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		opts:  Options{PrivacyMode: privacy.ModePartial, UnmaskGrants: privacy.Grants{"alice": "alice-secret-token"}},
+		store: store,
+	}
+
+	do := func(method, path, token string) (int, map[string]any) {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set(unmaskHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		s.handleCaseRoutes(rec, req)
+		var body map[string]any
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec.Code, body
+	}
+
+	rollup := "/api/cases/" + caseID + "/hits/rollup"
+	if code, body := do(http.MethodGet, rollup, ""); code != http.StatusOK || body["masked"] != true {
+		t.Fatalf("default view: code=%d body=%v", code, body)
+	}
+	if code, body := do(http.MethodGet, rollup, "wrong-token-value"); code != http.StatusForbidden || body["code"] != "ERR_UNMASK_DENIED" {
+		t.Fatalf("bad token: code=%d body=%v", code, body)
+	}
+	if code, body := do(http.MethodGet, rollup, "alice-secret-token"); code != http.StatusOK || body["masked"] != false {
+		t.Fatalf("unmasked view: code=%d body=%v", code, body)
+	}
+	if code, body := do(http.MethodPost, "/api/cases/"+caseID+"/exports/forensic-zip", ""); code != http.StatusForbidden || body["code"] != "ERR_UNMASK_DENIED" {
+		t.Fatalf("export without permission: code=%d body=%v", code, body)
+	}
+
+	logs, err := store.ListAuditLogs(ctx, caseID, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := map[string]string{}
+	for _, l := range logs {
+		if l.EventType == "privacy" {
+			statuses[l.Status] = l.Actor
+		}
+	}
+	if len(statuses) != 2 || statuses["success"] != "alice" || statuses["failed"] != "unknown" {
+		t.Fatalf("unmask audits=%v", statuses)
+	}
+}
+
+func TestPartialPrivacyAnalysisEndpoints(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []model.Device{
+		{ID: "dev_pc", Name: "pc", OS: model.OSWindows, Identifier: "pc"},
+		{ID: "dev_phone", Name: "phone", OS: model.OSAndroid, Identifier: "phone"},
+	} {
+		if err := store.UpsertDevice(ctx, caseID, d, true, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	addrA := "0x" + strings.Repeat("1a", 20)
+	addrB := "0x" + strings.Repeat("2b", 20)
+	lookalike := "blnance-login.com"
+	pageURL := "https://explorer.example/tx?from=" + addrA + "&to=" + addrB
+	visits, _ := json.Marshal([]model.VisitRecord{{Browser: "chrome", URL: pageURL, Domain: "explorer.example", VisitedAt: 100}})
+	snap := filepath.Join(dir, "history.json")
+	if err := os.WriteFile(snap, visits, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum, size, err := hash.File(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveArtifacts(ctx, []model.Artifact{{
+		ID: "art_history", CaseID: caseID, DeviceID: "dev_pc", Type: model.ArtifactBrowserHistory,
+		SourceRef: "chrome", SnapshotPath: snap, SHA256: sum, SizeBytes: size, CollectedAt: 100,
+		CollectorName: "test", CollectorVersion: "1", ParserVersion: "1", AcquisitionMethod: "test",
+		PayloadJSON: visits, RecordHash: strings.Repeat("0", 64),
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	hit := func(id, dev string, ht model.HitType, ruleID, value, detail string) model.RuleHit {
+		return model.RuleHit{
+			ID: id, CaseID: caseID, DeviceID: dev, Type: ht, RuleID: ruleID, MatchedValue: value,
+			FirstSeenAt: 100, LastSeenAt: 200, Confidence: 0.9, Verdict: "confirmed", DetailJSON: []byte(detail),
+		}
+	}
+	if err := store.SaveRuleHits(ctx, []model.RuleHit{
+		hit("hit_a_pc", "dev_pc", model.HitWalletAddress, "evm", addrA, `{}`),
+		hit("hit_b_pc", "dev_pc", model.HitWalletAddress, "evm", addrB, `{}`),
+		hit("hit_a_phone", "dev_phone", model.HitWalletAddress, "evm", addrA, `{}`),
+		hit("hit_ex_pc", "dev_pc", model.HitExchangeVisited, "binance", lookalike, `{"url":"https://`+lookalike+`/login","lookalike_of":"binance.com"}`),
+		hit("hit_ex_phone", "dev_phone", model.HitExchangeVisited, "binance", lookalike, `{"url":"https://`+lookalike+`/","lookalike_of":"binance.com"}`),
+		// 已检测过的来源命中：仿冒检测直接跳过，不会发起证书请求。
+		hit("hit_phish_pc", "dev_pc", model.HitPhishingSuspected, "binance", lookalike, `{"source_hit_id":"hit_ex_pc"}`),
+		hit("hit_phish_phone", "dev_phone", model.HitPhishingSuspected, "binance", lookalike, `{"source_hit_id":"hit_ex_phone"}`),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Server{
+		opts: Options{
+			PrivacyMode:      privacy.ModePartial,
+			UnmaskGrants:     privacy.Grants{"alice": "alice-secret-token"},
+			DBPath:           filepath.Join(dir, "inspector.db"),
+			EvidenceRoot:     filepath.Join(dir, "evidence"),
+			WalletRulePath:   "../../../rules/wallet_signatures.template.yaml",
+			ExchangeRulePath: "../../../rules/exchange_domains.template.yaml",
+		},
+		store:      store,
+		rulesCache: rules.NewCache(),
+	}
+	do := func(method, path, token string) (int, string) {
+		req := httptest.NewRequest(method, "/api/cases/"+caseID+"/"+path, nil)
+		if token != "" {
+			req.Header.Set(unmaskHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		s.handleCaseRoutes(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	for _, tc := range []struct {
+		method, path string
+		secrets      []string // 脱敏视图中不得出现、unmask 后应出现的内容
+	}{
+		{http.MethodPost, "address-clusters", []string{addrA, addrB}},
+		{http.MethodPost, "device-correlation", []string{addrA, lookalike}},
+		{http.MethodGet, "device-correlation", []string{addrA, lookalike}},
+		{http.MethodPost, "phishing-check", []string{lookalike}},
+	} {
+		code, body := do(tc.method, tc.path, "")
+		if code != http.StatusOK || !strings.Contains(body, `"masked":true`) {
+			t.Fatalf("%s %s masked: code=%d body=%s", tc.method, tc.path, code, body)
+		}
+		for _, secret := range tc.secrets {
+			if strings.Contains(body, secret) {
+				t.Fatalf("%s %s leaks %q: %s", tc.method, tc.path, secret, body)
+			}
+		}
+		if code, body := do(tc.method, tc.path, "wrong-token-value"); code != http.StatusForbidden || !strings.Contains(body, "ERR_UNMASK_DENIED") {
+			t.Fatalf("%s %s bad token: code=%d body=%s", tc.method, tc.path, code, body)
+		}
+		code, body = do(tc.method, tc.path, "alice-secret-token")
+		if code != http.StatusOK || !strings.Contains(body, `"masked":false`) {
+			t.Fatalf("%s %s unmasked: code=%d body=%s", tc.method, tc.path, code, body)
+		}
+		for _, secret := range tc.secrets {
+			if !strings.Contains(body, secret) {
+				t.Fatalf("%s %s unmasked view misses %q: %s", tc.method, tc.path, secret, body)
+			}
+		}
+	}
+}
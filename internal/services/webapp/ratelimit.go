@@ -0,0 +1,238 @@
+package webapp
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/platform/hash"
+	"crypto-inspector/internal/platform/i18n"
+)
+
+// API 限流与滥用防护
+//
+// 背景：serve 模式在局域网暴露后，任何人都可以反复触发扫描/导出/链上查询，
+// 既拖慢现场取证，也可能把公共 RPC 打到封禁。
+//
+// 策略：
+// - 按来源 IP 的令牌桶（所有 /api/* 请求，/api/health 除外）
+// - 请求携带 token（Authorization: Bearer / X-API-Token）时额外按 token 限流
+//   （鉴权上线前 token 未经校验，因此 IP 限额始终生效，避免换 token 绕过）
+// - 昂贵接口（导出、链上查询）限制全局并发，超出直接拒绝而不是排队
+// - 被拒绝时返回 429 + Retry-After（秒）
+
+// RateLimitOptions 定义限流参数；数值 <=0 的字段使用默认值。
+type RateLimitOptions struct {
+	Disabled bool
+
+	PerIPRate       float64 // 每个 IP 每秒补充的请求数
+	PerIPBurst      int     // 每个 IP 的突发容量
+	PerTokenRate    float64 // 每个 token 每秒补充的请求数
+	PerTokenBurst   int     // 每个 token 的突发容量
+	MaxExports      int     // 导出类接口（forensic-zip/pdf）全局并发上限
+	MaxChainQueries int     // 链上查询接口全局并发上限
+}
+
+// DefaultRateLimitOptions 返回默认限流参数（面向单机/小型局域网现场）。
+func DefaultRateLimitOptions() RateLimitOptions {
+	return RateLimitOptions{
+		PerIPRate:       10,
+		PerIPBurst:      40,
+		PerTokenRate:    5,
+		PerTokenBurst:   20,
+		MaxExports:      2,
+		MaxChainQueries: 4,
+	}
+}
+
+func (o RateLimitOptions) withDefaults() RateLimitOptions {
+	def := DefaultRateLimitOptions()
+	if o.PerIPRate <= 0 {
+		o.PerIPRate = def.PerIPRate
+	}
+	if o.PerIPBurst <= 0 {
+		o.PerIPBurst = def.PerIPBurst
+	}
+	if o.PerTokenRate <= 0 {
+		o.PerTokenRate = def.PerTokenRate
+	}
+	if o.PerTokenBurst <= 0 {
+		o.PerTokenBurst = def.PerTokenBurst
+	}
+	if o.MaxExports <= 0 {
+		o.MaxExports = def.MaxExports
+	}
+	if o.MaxChainQueries <= 0 {
+		o.MaxChainQueries = def.MaxChainQueries
+	}
+	return o
+}
+
+// tokenBucket 是单个 key 的令牌桶状态。
+type tokenBucket struct {
+	tokens   float64
+	updated  time.Time
+	lastSeen time.Time
+}
+
+// bucketLimiter 按 key 维护令牌桶（并发安全，空闲 key 定期清理）。
+type bucketLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+const bucketIdleTTL = 10 * time.Minute
+
+func newBucketLimiter(rate float64, burst int) *bucketLimiter {
+	return &bucketLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow 尝试消耗一个令牌；不允许时返回建议等待时间。
+func (l *bucketLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > time.Minute {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) > bucketIdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(l.burst, b.tokens+elapsed*l.rate)
+		b.updated = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+// rateLimiter 组合 IP/token 令牌桶与昂贵接口并发闸门。
+type rateLimiter struct {
+	opts    RateLimitOptions
+	byIP    *bucketLimiter
+	byToken *bucketLimiter
+	exports chan struct{}
+	chain   chan struct{}
+}
+
+func newRateLimiter(opts RateLimitOptions) *rateLimiter {
+	opts = opts.withDefaults()
+	return &rateLimiter{
+		opts:    opts,
+		byIP:    newBucketLimiter(opts.PerIPRate, opts.PerIPBurst),
+		byToken: newBucketLimiter(opts.PerTokenRate, opts.PerTokenBurst),
+		exports: make(chan struct{}, opts.MaxExports),
+		chain:   make(chan struct{}, opts.MaxChainQueries),
+	}
+}
+
+// withRateLimit 对 /api/* 请求做限流（UI 静态资源与 /api/health 不限流）。
+func withRateLimit(next http.Handler, rl *rateLimiter) http.Handler {
+	if rl == nil || rl.opts.Disabled {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/api/") || r.URL.Path == "/api/health" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if ok, wait := rl.byIP.allow("ip:" + clientIP(r)); !ok {
+			writeRateLimited(w, wait, i18n.M("ratelimit.address"))
+			return
+		}
+		if tok := requestToken(r); tok != "" {
+			if ok, wait := rl.byToken.allow("tok:" + hash.Text(tok)); !ok {
+				writeRateLimited(w, wait, i18n.M("ratelimit.token"))
+				return
+			}
+		}
+
+		if gate := rl.gateFor(r); gate != nil {
+			select {
+			case gate <- struct{}{}:
+				defer func() { <-gate }()
+			default:
+				writeRateLimited(w, 5*time.Second, i18n.M("ratelimit.concurrent"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// gateFor 返回昂贵接口对应的并发闸门（普通接口返回 nil）。
+//
+// - POST /api/cases/{case_id}/exports/*
+// - POST /api/chain/*、POST /api/cases/{case_id}/chain/*
+func (rl *rateLimiter) gateFor(r *http.Request) chan struct{} {
+	if r.Method != http.MethodPost {
+		return nil
+	}
+	p := r.URL.Path
+	switch {
+	case strings.HasPrefix(p, "/api/chain/"):
+		return rl.chain
+	case strings.HasPrefix(p, "/api/cases/") && strings.Contains(p, "/chain/"):
+		return rl.chain
+	case strings.HasPrefix(p, "/api/cases/") && strings.Contains(p, "/exports/"):
+		return rl.exports
+	default:
+		return nil
+	}
+}
+
+func writeRateLimited(w http.ResponseWriter, wait time.Duration, msg i18n.Message) {
+	secs := int(math.Ceil(wait.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(secs))
+	writeError(w, http.StatusTooManyRequests, apperr.T(apperr.CodeRateLimited, "ratelimit.retry_after", msg, secs))
+}
+
+// clientIP 取 TCP 对端地址（不信任 X-Forwarded-For：本工具不应部署在反向代理之后）。
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		return strings.TrimSpace(r.RemoteAddr)
+	}
+	return host
+}
+
+// requestToken 提取调用方 token（Authorization: Bearer xxx 或 X-API-Token）。
+func requestToken(r *http.Request) string {
+	if v := strings.TrimSpace(r.Header.Get("Authorization")); len(v) > 7 && strings.EqualFold(v[:7], "bearer ") {
+		return strings.TrimSpace(v[7:])
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Token"))
+}
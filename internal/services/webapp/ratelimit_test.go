@@ -0,0 +1,76 @@
+package webapp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit_PerIPAndConcurrency(t *testing.T) {
+	t.Parallel()
+
+	rl := newRateLimiter(RateLimitOptions{PerIPRate: 1, PerIPBurst: 2, MaxExports: 1})
+	now := time.Unix(1700000000, 0)
+	rl.byIP.now = func() time.Time { return now }
+
+	release := make(chan struct{})
+	entered := make(chan struct{}, 1)
+	h := withRateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/cases/c1/exports/forensic-zip" {
+			entered <- struct{}{}
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+	}), rl)
+
+	do := func(method, path, remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 突发容量 2：第 3 个请求被拒绝，并带 Retry-After。
+	for i := 0; i < 2; i++ {
+		if rec := do(http.MethodGet, "/api/cases", "10.0.0.1:5000"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: want 200, got %d", i, rec.Code)
+		}
+	}
+	rec := do(http.MethodGet, "/api/cases", "10.0.0.1:5001")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "1" {
+		t.Fatalf("want 429 with Retry-After=1, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	// 其他 IP 与健康检查不受影响。
+	if rec := do(http.MethodGet, "/api/cases", "10.0.0.2:5000"); rec.Code != http.StatusOK {
+		t.Fatalf("other ip: want 200, got %d", rec.Code)
+	}
+	if rec := do(http.MethodGet, "/api/health", "10.0.0.1:5000"); rec.Code != http.StatusOK {
+		t.Fatalf("health: want 200, got %d", rec.Code)
+	}
+
+	// 令牌按时间补充。
+	now = now.Add(time.Second)
+	if rec := do(http.MethodGet, "/api/cases", "10.0.0.1:5000"); rec.Code != http.StatusOK {
+		t.Fatalf("after refill: want 200, got %d", rec.Code)
+	}
+
+	// 导出并发上限 1：第一个导出未结束时，第二个直接 429。
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		do(http.MethodPost, "/api/cases/c1/exports/forensic-zip", "10.0.0.3:5000")
+	}()
+	<-entered
+	if rec := do(http.MethodPost, "/api/cases/c2/exports/forensic-pdf", "10.0.0.4:5000"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("concurrent export: want 429, got %d", rec.Code)
+	}
+	close(release)
+	wg.Wait()
+	if rec := do(http.MethodPost, "/api/cases/c2/exports/forensic-pdf", "10.0.0.4:5000"); rec.Code != http.StatusOK {
+		t.Fatalf("export after release: want 200, got %d", rec.Code)
+	}
+}
@@ -66,6 +66,11 @@ func (s *Server) handleRulesList(w http.ResponseWriter, r *http.Request) {
 	rulesDir := s.rulesDir()
 	_ = os.MkdirAll(rulesDir, 0o755)
 
+	statsQuery, err := parseRuleHitStatsQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
 	walletPath, exchangePath := s.activeRulePaths(r.Context())
 
 	// 收集候选文件：
@@ -107,7 +112,7 @@ func (s *Server) handleRulesList(w http.ResponseWriter, r *http.Request) {
 	sort.Slice(walletFiles, func(i, j int) bool { return walletFiles[i].Filename < walletFiles[j].Filename })
 	sort.Slice(exchangeFiles, func(i, j int) bool { return exchangeFiles[i].Filename < exchangeFiles[j].Filename })
 
-	writeJSON(w, http.StatusOK, map[string]any{
+	resp := map[string]any{
 		"ok": true,
 		"active": map[string]any{
 			"wallet_path":   walletPath,
@@ -116,7 +121,14 @@ func (s *Server) handleRulesList(w http.ResponseWriter, r *http.Request) {
 		"rules_dir": rulesDir,
 		"wallet":    walletFiles,
 		"exchange":  exchangeFiles,
-	})
+	}
+	// 规则命中统计：加载失败不影响文件列表（例如 active 文件已被删除），只回传错误信息。
+	if stats, err := s.activeRuleStats(r.Context(), walletPath, exchangePath, statsQuery); err != nil {
+		resp["rules_error"] = err.Error()
+	} else {
+		resp["rules"] = stats
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 func (s *Server) handleRulesPost(w http.ResponseWriter, r *http.Request) {
@@ -222,6 +234,8 @@ func (s *Server) handleRulesImport(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	s.rulesCache.Invalidate()
+
 	writeJSON(w, http.StatusOK, map[string]any{
 		"ok":   true,
 		"kind": kind,
@@ -268,6 +282,8 @@ func (s *Server) handleRulesActivate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	s.rulesCache.Invalidate()
+
 	writeJSON(w, http.StatusOK, map[string]any{
 		"ok": true,
 		"active": map[string]any{
@@ -277,6 +293,22 @@ func (s *Server) handleRulesActivate(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleRulesCacheInvalidate：POST 清空规则加载缓存，下次扫描/请求从磁盘重新加载。
+//
+// 缓存按文件 mtime+哈希自动失效；规则文件被外部工具覆盖且保留了原 mtime 时使用该接口强制重新加载。
+func (s *Server) handleRulesCacheInvalidate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cleared := s.rulesCache.Invalidate()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":      true,
+		"cleared": cleared,
+		"cache":   s.rulesCache.Stats(),
+	})
+}
+
 func sanitizeRuleFilename(in string) string {
 	in = strings.TrimSpace(in)
 	if in == "" {
@@ -0,0 +1,163 @@
+package webapp
+
+import (
+	"context"
+	"net/http"
+
+	"crypto-inspector/internal/adapters/rules"
+	"crypto-inspector/internal/domain/model"
+)
+
+// ruleHitSummary 是规则浏览页中单条规则的展示项：规则基础信息 + 全部案件的命中统计。
+//
+// Dead 表示“启用但从未命中”，供规则维护者识别长期不触发的签名。
+// Versions 按规则包版本拆分同一组命中，规则大版本更新后可以分别查看新旧版本的命中量。
+type ruleHitSummary struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Enabled   bool     `json:"enabled"`
+	Aliases   []string `json:"aliases,omitempty"`
+	HitCount  int64    `json:"hit_count"`
+	CaseCount int64    `json:"case_count"`
+	LastHitAt int64    `json:"last_hit_at,omitempty"`
+	Dead      bool     `json:"dead"`
+	// HitTypes 是各命中类型的命中数（例如钱包规则的 wallet_installed / wallet_executed）。
+	HitTypes map[string]int64 `json:"hit_types,omitempty"`
+
+	Versions []model.RuleHitBundleStat `json:"versions,omitempty"`
+}
+
+// activeRuleStats 加载当前生效的钱包/交易所规则，并合并 rule_hits 的聚合统计。
+//
+// 钱包规则按 rule_id 合并 model.WalletRuleHitTypes（安装、运行痕迹等，主机与移动端共用规则 ID），
+// 交易所规则合并 model.ExchangeRuleHitTypes（访问、客户端安装、表单记录等）；只经由其中一种途径命中的规则不算 dead。
+// q 的规则包版本 / 时间窗口同时作用于汇总与分段；hit_type、rule_id 由规则列表决定，这里忽略。
+func (s *Server) activeRuleStats(ctx context.Context, walletPath, exchangePath string, q model.RuleHitStatsQuery) (map[string]any, error) {
+	loaded, err := s.rulesCache.Load(ctx, rules.NewLoader(walletPath, exchangePath))
+	if err != nil {
+		return nil, err
+	}
+	q.HitType, q.RuleID = "", ""
+	walletStats, err := s.ruleFamilyStats(ctx, q, model.WalletRuleHitTypes)
+	if err != nil {
+		return nil, err
+	}
+	exchangeStats, err := s.ruleFamilyStats(ctx, q, model.ExchangeRuleHitTypes)
+	if err != nil {
+		return nil, err
+	}
+	summarize := func(fs *ruleFamily, id, name string, enabled bool, aliases []string) ruleHitSummary {
+		st := fs.byRule[id]
+		return ruleHitSummary{
+			ID:        id,
+			Name:      name,
+			Enabled:   enabled,
+			Aliases:   aliases,
+			HitCount:  st.HitCount,
+			CaseCount: st.CaseCount,
+			LastHitAt: st.LastHitAt,
+			Dead:      enabled && st.HitCount == 0,
+			HitTypes:  fs.types[id],
+			Versions:  fs.versions[id],
+		}
+	}
+
+	wallets := make([]ruleHitSummary, 0, len(loaded.Wallet.Wallets))
+	deadWallets := 0
+	for _, wr := range loaded.Wallet.Wallets {
+		item := summarize(walletStats, wr.ID, wr.Name, wr.Enabled, wr.Aliases)
+		if item.Dead {
+			deadWallets++
+		}
+		wallets = append(wallets, item)
+	}
+	exchanges := make([]ruleHitSummary, 0, len(loaded.Exchange.Exchanges))
+	deadExchanges := 0
+	for _, exr := range loaded.Exchange.Exchanges {
+		item := summarize(exchangeStats, exr.ID, exr.Name, exr.Enabled, exr.Aliases)
+		if item.Dead {
+			deadExchanges++
+		}
+		exchanges = append(exchanges, item)
+	}
+
+	return map[string]any{
+		"wallet_version":   loaded.Wallet.Version,
+		"exchange_version": loaded.Exchange.Version,
+		"wallet":           wallets,
+		"exchange":         exchanges,
+		"dead_wallet":      deadWallets,
+		"dead_exchange":    deadExchanges,
+		"bundle_version":   q.BundleVersion,
+		"since":            q.Since,
+		"until":            q.Until,
+	}, nil
+}
+
+// ruleFamily 是一类规则（钱包或交易所）按 rule_id 合并各命中类型后的统计。
+type ruleFamily struct {
+	byRule   map[string]model.RuleHitStat
+	types    map[string]map[string]int64 // rule_id -> hit_type -> 命中数
+	versions map[string][]model.RuleHitBundleStat
+}
+
+// ruleFamilyStats 统计 hitTypes 范围内的命中：汇总与规则包分段均按 rule_id 合并命中类型。
+func (s *Server) ruleFamilyStats(ctx context.Context, q model.RuleHitStatsQuery, hitTypes []model.HitType) (*ruleFamily, error) {
+	q.HitTypes = make([]string, 0, len(hitTypes))
+	for _, t := range hitTypes {
+		q.HitTypes = append(q.HitTypes, string(t))
+	}
+	perType, err := s.store.ListRuleHitStats(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	fs := &ruleFamily{
+		byRule:   map[string]model.RuleHitStat{},
+		types:    map[string]map[string]int64{},
+		versions: map[string][]model.RuleHitBundleStat{},
+	}
+	for _, st := range perType {
+		if fs.types[st.RuleID] == nil {
+			fs.types[st.RuleID] = map[string]int64{}
+		}
+		fs.types[st.RuleID][st.HitType] = st.HitCount
+	}
+	q.MergeHitTypes = true
+	merged, err := s.store.ListRuleHitStats(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range merged {
+		fs.byRule[st.RuleID] = st
+	}
+	segments, err := s.store.ListRuleHitBundleStats(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range segments {
+		fs.versions[seg.RuleID] = append(fs.versions[seg.RuleID], seg)
+	}
+	return fs, nil
+}
+
+// handleRulesStats 返回按规则包版本分段的命中统计（GET /api/rules/stats）。
+//
+// 查询参数：hit_type、rule_id、bundle_version、since、until（命中入库时间，unix 秒）。
+// 与 /api/rules 不同，这里不依赖当前生效的规则文件，已停用或已删除规则的历史命中同样返回。
+func (s *Server) handleRulesStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q, err := parseRuleHitStatsQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	items, err := s.store.ListRuleHitBundleStats(r.Context(), q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "items": items})
+}
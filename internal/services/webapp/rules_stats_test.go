@@ -0,0 +1,159 @@
+package webapp
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"crypto-inspector/internal/adapters/rules"
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/model"
+
+	_ "modernc.org/sqlite"
+)
+
+// newRulesStatsServer 创建带迁移后数据库与规则模板的 Server，并返回一个已登记设备 dev_1 的案件。
+func newRulesStatsServer(t *testing.T) (*Server, *sqliteadapter.Store, string) {
+	t.Helper()
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "inspector.db")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, err := store.EnsureCase(ctx, "", "", "t", "op", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "d", OS: model.OSWindows, Identifier: "id-1"}, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		opts: Options{
+			DBPath:           dbPath,
+			WalletRulePath:   "../../../rules/wallet_signatures.template.yaml",
+			ExchangeRulePath: "../../../rules/exchange_domains.template.yaml",
+		},
+		store:      store,
+		rulesCache: rules.NewCache(),
+	}
+	return s, store, caseID
+}
+
+// rulesListStats 是 GET /api/rules 响应中的规则命中统计部分。
+type rulesListStats struct {
+	Wallet       []ruleHitSummary `json:"wallet"`
+	Exchange     []ruleHitSummary `json:"exchange"`
+	DeadWallet   int              `json:"dead_wallet"`
+	DeadExchange int              `json:"dead_exchange"`
+}
+
+func getRulesListStats(t *testing.T, s *Server) rulesListStats {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.handleRulesList(rec, httptest.NewRequest(http.MethodGet, "/api/rules", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code=%d body=%s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		RulesError string         `json:"rules_error"`
+		Rules      rulesListStats `json:"rules"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body.RulesError != "" {
+		t.Fatalf("decode: %v rules_error=%q", err, body.RulesError)
+	}
+	return body.Rules
+}
+
+func findRuleSummary(t *testing.T, items []ruleHitSummary, id string) ruleHitSummary {
+	t.Helper()
+	for _, it := range items {
+		if it.ID == id {
+			return it
+		}
+	}
+	t.Fatalf("rule %s not listed", id)
+	return ruleHitSummary{}
+}
+
+func TestRulesListHitStats(t *testing.T) {
+	ctx := context.Background()
+	s, store, caseID := newRulesStatsServer(t)
+	var hits []model.RuleHit
+	add := func(ht model.HitType, ruleID string) {
+		hits = append(hits, model.RuleHit{
+			ID: fmt.Sprintf("hit_%d", len(hits)), CaseID: caseID, DeviceID: "dev_1", Type: ht, RuleID: ruleID,
+			MatchedValue: ruleID, Confidence: 0.9, Verdict: "confirmed", DetailJSON: []byte(`{}`),
+		})
+	}
+	add(model.HitWalletInstalled, "wallet_metamask")
+	add(model.HitWalletExecuted, "wallet_metamask")
+	add(model.HitExchangeAppInstalled, "binance")
+	if err := store.SaveRuleHits(ctx, hits); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := getRulesListStats(t, s)
+	mm := findRuleSummary(t, stats.Wallet, "wallet_metamask")
+	if mm.Dead || mm.HitCount != 2 || mm.CaseCount != 1 ||
+		mm.HitTypes[string(model.HitWalletInstalled)] != 1 || mm.HitTypes[string(model.HitWalletExecuted)] != 1 {
+		t.Fatalf("wallet_metamask=%+v", mm)
+	}
+	bn := findRuleSummary(t, stats.Exchange, "binance")
+	if bn.Dead || bn.HitCount != 1 || bn.CaseCount != 1 {
+		t.Fatalf("binance=%+v", bn)
+	}
+	if stats.DeadWallet != len(stats.Wallet)-1 || stats.DeadExchange != len(stats.Exchange)-1 {
+		t.Fatalf("dead wallet=%d/%d exchange=%d/%d", stats.DeadWallet, len(stats.Wallet), stats.DeadExchange, len(stats.Exchange))
+	}
+}
+
+func TestRulesListWalletExecutedOnly(t *testing.T) {
+	ctx := context.Background()
+	s, store, caseID := newRulesStatsServer(t)
+	v1, err := store.EnsureRuleBundle(ctx, "wallet_signatures", "2024.01", fmt.Sprintf("%064d", 1), "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v2, err := store.EnsureRuleBundle(ctx, "wallet_signatures", "2024.06", fmt.Sprintf("%064d", 2), "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// wallet_imtoken 只有运行痕迹命中（没有安装记录），分属两个规则包版本。
+	var hits []model.RuleHit
+	for i, bundle := range []struct{ id, version string }{{v1, "2024.01"}, {v2, "2024.06"}, {v2, "2024.06"}} {
+		hits = append(hits, model.RuleHit{
+			ID: fmt.Sprintf("hit_%d", i), CaseID: caseID, DeviceID: "dev_1", Type: model.HitWalletExecuted,
+			RuleID: "wallet_imtoken", RuleBundleID: bundle.id, RuleVersion: bundle.version,
+			MatchedValue: "imToken", Confidence: 0.8, Verdict: "suspected", DetailJSON: []byte(`{}`),
+		})
+	}
+	if err := store.SaveRuleHits(ctx, hits); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := getRulesListStats(t, s)
+	it := findRuleSummary(t, stats.Wallet, "wallet_imtoken")
+	if it.Dead || it.HitCount != 3 || it.HitTypes[string(model.HitWalletExecuted)] != 3 {
+		t.Fatalf("wallet_imtoken=%+v", it)
+	}
+	if len(it.Versions) != 2 ||
+		it.Versions[0].BundleVersion != "2024.01" || it.Versions[0].HitCount != 1 ||
+		it.Versions[1].BundleVersion != "2024.06" || it.Versions[1].HitCount != 2 {
+		t.Fatalf("versions=%+v", it.Versions)
+	}
+	if stats.DeadWallet != len(stats.Wallet)-1 {
+		t.Fatalf("dead wallet=%d/%d", stats.DeadWallet, len(stats.Wallet))
+	}
+}
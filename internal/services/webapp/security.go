@@ -0,0 +1,174 @@
+package webapp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"crypto-inspector/internal/domain/apperr"
+)
+
+// 浏览器侧安全防护（共享分析员电脑/浏览器场景）
+//
+// - 安全响应头：CSP / X-Content-Type-Options / X-Frame-Options / Referrer-Policy，TLS 下追加 HSTS
+// - CSRF：双提交 cookie（ci_csrf cookie + X-CSRF-Token 请求头），SameSite=Strict
+//   兼容模式（默认）下，同源 Origin / Sec-Fetch-Site 也视为合法，避免已打包的旧版 UI 失效；
+//   严格模式下所有浏览器写请求都必须带 X-CSRF-Token
+// - 携带 API token（Authorization / X-API-Token）的请求不走 CSRF：浏览器不会自动附带这些头
+
+type csrfCtxKey struct{}
+
+const (
+	csrfCookieName = "ci_csrf"
+	csrfHeaderName = "X-CSRF-Token"
+)
+
+// SecurityOptions 定义安全中间件参数。
+type SecurityOptions struct {
+	// CSRFStrict=true 时，所有未携带 API token 的写请求都必须带 X-CSRF-Token。
+	CSRFStrict bool
+	// ForceHSTS=true 时即使当前连接不是 TLS 也下发 HSTS（部署在 TLS 终结代理之后时使用）。
+	ForceHSTS bool
+}
+
+// contentSecurityPolicy 只允许加载同源资源（UI 为内嵌静态文件，无外部 CDN 依赖）。
+const contentSecurityPolicy = "default-src 'self'; " +
+	"script-src 'self'; " +
+	"style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data: blob:; " +
+	"font-src 'self' data:; " +
+	"connect-src 'self'; " +
+	"object-src 'none'; " +
+	"base-uri 'self'; " +
+	"form-action 'self'; " +
+	"frame-ancestors 'none'"
+
+// withSecurity 设置安全响应头、下发 CSRF cookie，并校验写请求的来源。
+func withSecurity(next http.Handler, opts SecurityOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Content-Security-Policy", contentSecurityPolicy)
+		h.Set("X-Content-Type-Options", "nosniff")
+		h.Set("X-Frame-Options", "DENY")
+		h.Set("Referrer-Policy", "no-referrer")
+		h.Set("Cross-Origin-Opener-Policy", "same-origin")
+		if r.TLS != nil || opts.ForceHSTS {
+			h.Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		}
+
+		cookieToken := ""
+		if c, err := r.Cookie(csrfCookieName); err == nil && isCSRFToken(c.Value) {
+			cookieToken = c.Value
+		} else {
+			cookieToken = newCSRFToken()
+			setCSRFCookie(w, r, cookieToken)
+		}
+
+		if isStateChanging(r.Method) && strings.HasPrefix(r.URL.Path, "/api/") {
+			if err := checkCSRF(r, cookieToken, opts.CSRFStrict); err != nil {
+				writeError(w, http.StatusForbidden, err)
+				return
+			}
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), csrfCtxKey{}, cookieToken)))
+	})
+}
+
+// handleCSRF 返回当前会话的 CSRF token（GET /api/csrf）。
+//
+// UI 也可以直接读取 ci_csrf cookie（非 HttpOnly）；该接口用于脚本/调试。
+func (s *Server) handleCSRF(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	token, _ := r.Context().Value(csrfCtxKey{}).(string)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"csrf_token": token,
+		"header":     csrfHeaderName,
+	})
+}
+
+// checkCSRF 校验写请求：
+// 1) 携带 API token：放行（非浏览器调用方）
+// 2) X-CSRF-Token 与 cookie 一致：放行
+// 3) 非严格模式：同源 Origin 或 Sec-Fetch-Site=same-origin/none 放行；无任何来源信息（curl/脚本）放行
+// 4) 其他（跨站来源、token 不匹配）：拒绝
+func checkCSRF(r *http.Request, cookieToken string, strict bool) error {
+	if requestToken(r) != "" {
+		return nil
+	}
+	if v := strings.TrimSpace(r.Header.Get(csrfHeaderName)); v != "" {
+		if subtle.ConstantTimeCompare([]byte(v), []byte(cookieToken)) == 1 {
+			return nil
+		}
+		return apperr.T(apperr.CodeCSRF, "csrf.mismatch")
+	}
+	if strict {
+		return apperr.T(apperr.CodeCSRF, "csrf.missing_header", csrfHeaderName)
+	}
+
+	origin := strings.TrimSpace(r.Header.Get("Origin"))
+	site := strings.ToLower(strings.TrimSpace(r.Header.Get("Sec-Fetch-Site")))
+	switch {
+	case origin != "":
+		if sameOrigin(origin, r.Host) {
+			return nil
+		}
+		return apperr.T(apperr.CodeCSRF, "csrf.cross_origin")
+	case site != "":
+		if site == "same-origin" || site == "none" {
+			return nil
+		}
+		return apperr.T(apperr.CodeCSRF, "csrf.cross_site")
+	default:
+		return nil
+	}
+}
+
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		// 包括 "null"（沙箱 iframe / file:// 页面）。
+		return false
+	}
+	return strings.EqualFold(u.Host, strings.TrimSpace(host))
+}
+
+func isStateChanging(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
+
+func setCSRFCookie(w http.ResponseWriter, r *http.Request, token string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     csrfCookieName,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: false, // UI 需要读取后放入 X-CSRF-Token 请求头
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func newCSRFToken() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func isCSRFToken(v string) bool {
+	if len(v) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(v)
+	return err == nil
+}
@@ -0,0 +1,105 @@
+package webapp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"crypto-inspector/internal/platform/i18n"
+)
+
+func TestWithSecurity_HeadersAndCSRF(t *testing.T) {
+	t.Parallel()
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	compat := withSecurity(ok, SecurityOptions{})
+	strict := withSecurity(ok, SecurityOptions{CSRFStrict: true})
+
+	// GET：下发安全头与 CSRF cookie。
+	rec := httptest.NewRecorder()
+	compat.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://inspector.local/", nil))
+	if rec.Header().Get("X-Content-Type-Options") != "nosniff" || !strings.Contains(rec.Header().Get("Content-Security-Policy"), "frame-ancestors 'none'") {
+		t.Fatalf("missing security headers: %v", rec.Header())
+	}
+	if rec.Header().Get("Strict-Transport-Security") != "" {
+		t.Fatalf("HSTS must not be sent over plain http")
+	}
+	var token string
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == csrfCookieName {
+			token = c.Value
+			if c.SameSite != http.SameSiteStrictMode {
+				t.Fatalf("csrf cookie: want SameSite=Strict, got %v", c.SameSite)
+			}
+		}
+	}
+	if !isCSRFToken(token) {
+		t.Fatalf("csrf cookie not issued: %q", token)
+	}
+
+	post := func(h http.Handler, hdr map[string]string) int {
+		req := httptest.NewRequest(http.MethodPost, "http://inspector.local/api/cases", nil)
+		req.AddCookie(&http.Cookie{Name: csrfCookieName, Value: token})
+		for k, v := range hdr {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	cases := []struct {
+		name string
+		h    http.Handler
+		hdr  map[string]string
+		want int
+	}{
+		{"matching token", strict, map[string]string{csrfHeaderName: token}, http.StatusOK},
+		{"mismatched token", compat, map[string]string{csrfHeaderName: strings.Repeat("0", 64)}, http.StatusForbidden},
+		{"same origin (compat)", compat, map[string]string{"Origin": "http://inspector.local"}, http.StatusOK},
+		{"cross origin", compat, map[string]string{"Origin": "http://evil.example"}, http.StatusForbidden},
+		{"cross site fetch", compat, map[string]string{"Sec-Fetch-Site": "cross-site"}, http.StatusForbidden},
+		{"no header (strict)", strict, map[string]string{"Origin": "http://inspector.local"}, http.StatusForbidden},
+		{"api token bypass", strict, map[string]string{"Authorization": "Bearer abc"}, http.StatusOK},
+	}
+	for _, tc := range cases {
+		if got := post(tc.h, tc.hdr); got != tc.want {
+			t.Fatalf("%s: want %d, got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestWithLocale_LocalizesErrors(t *testing.T) {
+	t.Parallel()
+
+	h := withLocale(withSecurity(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), SecurityOptions{}), i18n.EN)
+	post := func(target, acceptLang string) (*httptest.ResponseRecorder, map[string]string) {
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		req.Header.Set("Origin", "http://evil.example")
+		if acceptLang != "" {
+			req.Header.Set("Accept-Language", acceptLang)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		var body map[string]string
+		_ = json.Unmarshal(rec.Body.Bytes(), &body)
+		return rec, body
+	}
+
+	rec, body := post("http://inspector.local/api/cases", "")
+	if rec.Header().Get("Content-Language") != "en" || body["error"] != "cross-origin request rejected" || body["code"] != "ERR_CSRF" {
+		t.Fatalf("default: %v %v", rec.Header(), body)
+	}
+	rec, body = post("http://inspector.local/api/cases", "zh-CN,zh;q=0.9")
+	if rec.Header().Get("Content-Language") != "zh-CN" || body["error"] != "已拒绝跨源请求" || body["code"] != "ERR_CSRF" {
+		t.Fatalf("accept-language: %v %v", rec.Header(), body)
+	}
+	// ?lang= 优先于 Accept-Language。
+	if _, body = post("http://inspector.local/api/cases?lang=en", "zh-CN"); body["error"] != "cross-origin request rejected" {
+		t.Fatalf("query override: %v", body)
+	}
+}
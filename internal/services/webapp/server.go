@@ -6,7 +6,13 @@ import (
 	"net/http"
 	"strings"
 
+	"crypto-inspector/internal/adapters/rules"
 	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/services/anchor"
+	"crypto-inspector/internal/services/casemgmt"
+	"crypto-inspector/internal/services/chainbalance"
+	"crypto-inspector/internal/services/devicemonitor"
+	"crypto-inspector/internal/services/sqlitebrowser"
 )
 
 // Server 是内置 Web UI/API 的运行时对象。
@@ -17,20 +23,48 @@ type Server struct {
 
 	ui   fs.FS
 	jobs *jobManager
+
+	// chainBreaker 在所有链上查询请求间共享，按 RPC/API 端点熔断。
+	chainBreaker *chainbalance.CircuitBreaker
+	// chains 是按 kind 索引的链上查询提供方（内置 + Options.ChainProvidersPath）。
+	chains *chainbalance.Registry
+	// cms 为案件管理系统对接配置（Options.CaseMgmtConfigPath 为空时为 nil）。
+	cms *casemgmt.Config
+	// anchor 为报告哈希外部锚定配置（Options.AnchorConfigPath 为空时为 nil）。
+	anchor *anchor.Config
+
+	// sqlite 提供证据快照中 SQLite 副本的只读浏览（解压缓存在 data 目录下）。
+	sqlite *sqlitebrowser.Browser
+
+	// rulesCache 在扫描任务与规则相关接口间共享，避免每次请求重新解析规则文件。
+	rulesCache *rules.Cache
+
+	// monitor 为移动设备实时监测（serve --monitor 时启用，否则为 nil）。
+	monitor *devicemonitor.Monitor
 }
 
 func (s *Server) registerRoutes(mux *http.ServeMux) {
 	// API
 	mux.HandleFunc("/api/health", s.handleHealth)
 	mux.HandleFunc("/api/meta", s.handleMeta)
+	mux.HandleFunc("/api/csrf", s.handleCSRF)
 	mux.HandleFunc("/api/rules", s.handleRules)
+	mux.HandleFunc("/api/rules/cache/invalidate", s.handleRulesCacheInvalidate)
+	mux.HandleFunc("/api/rules/stats", s.handleRulesStats)
+	mux.HandleFunc("/api/precheck-policy", s.handlePrecheckPolicy)
+	mux.HandleFunc("/api/settings/", s.handleSettingsRoutes)
 	mux.HandleFunc("/api/cases", s.handleCases)
 	mux.HandleFunc("/api/cases/", s.handleCaseRoutes)
+	mux.HandleFunc("/api/handovers", s.handleHandovers)
 	mux.HandleFunc("/api/reports/", s.handleReportRoutes)
 	mux.HandleFunc("/api/artifacts/", s.handleArtifactRoutes)
 	mux.HandleFunc("/api/chain/", s.handleChainRoutes)
 	mux.HandleFunc("/api/jobs/scan-all", s.handleJobScanAll)
 	mux.HandleFunc("/api/jobs/", s.handleJobRoutes)
+	mux.HandleFunc("/api/audits", s.handleAuditsByTrace)
+	mux.HandleFunc("/api/devices", s.handleDevices)
+	mux.HandleFunc("/api/devices/events", s.handleDeviceEvents)
+	mux.HandleFunc("/api/devices/cases", s.handleDeviceCases)
 
 	// UI（单页应用 + 静态资源）
 	//
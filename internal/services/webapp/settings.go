@@ -0,0 +1,101 @@
+package webapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/holdings"
+	"crypto-inspector/internal/services/orgprofile"
+)
+
+// handleSettingsRoutes 全局设置：
+//   - GET /api/settings/org-profile：单位信息（报告页眉页脚、编号前缀与当前流水号）
+//   - POST /api/settings/org-profile：{"agency_name","unit","address","logo_path","contact","report_prefix","operator"} 整体替换
+//     logo_path 为服务端本机的 PNG/JPEG 文件路径；report_seq 只随报告签发递增，不能通过接口修改。
+//   - GET /api/settings/price-source：持有汇总使用的价格来源（未配置时 configured=false，报告不输出持有汇总）
+//   - POST /api/settings/price-source：{"yaml": "..."} 校验并替换配置；yaml 为空时清除配置
+//   - GET/POST /api/settings/case-checklist：案件侦查清单模板（yaml 为空时恢复内置默认模板）
+func (s *Server) handleSettingsRoutes(w http.ResponseWriter, r *http.Request) {
+	switch strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/settings/"), "/") {
+	case "org-profile":
+		s.handleOrgProfile(w, r)
+	case "price-source":
+		s.handlePriceSource(w, r)
+	case "case-checklist":
+		s.handleCaseChecklistTemplate(w, r)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *Server) handleOrgProfile(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		p, err := orgprofile.Load(r.Context(), s.store)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "org_profile": p})
+	case http.MethodPost:
+		var req struct {
+			model.OrgProfile
+			Operator string `json:"operator,omitempty"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		p, err := orgprofile.Save(r.Context(), s.store, req.OrgProfile, req.Operator)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "org_profile": p})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handlePriceSource(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var req struct {
+			YAML string `json:"yaml"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		if strings.TrimSpace(req.YAML) == "" {
+			if err := holdings.Reset(r.Context(), s.store); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		} else if _, err := holdings.Save(r.Context(), s.store, []byte(req.YAML)); err != nil {
+			writeError(w, http.StatusBadRequest, apperr.Wrap(apperr.CodeInvalidArgument, err, "invalid price source"))
+			return
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg, err := holdings.Load(r.Context(), s.store)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	raw, _ := s.store.GetSchemaMetaValue(r.Context(), holdings.SchemaKeyConfig)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":           true,
+		"configured":   cfg.Configured(),
+		"price_source": cfg,
+		"yaml":         raw,
+	})
+}
@@ -0,0 +1,78 @@
+package webapp
+
+import (
+	"fmt"
+	"net/http"
+
+	"crypto-inspector/internal/services/sqlitebrowser"
+)
+
+// handleArtifactSQLite 提供证据快照内 SQLite 副本的只读浏览：
+// - GET /api/artifacts/{id}/sqlite：列出快照中的数据库（zip 内条目）
+// - GET /api/artifacts/{id}/sqlite/tables?db=ENTRY：表/视图清单
+// - GET /api/artifacts/{id}/sqlite/rows?db=ENTRY&table=T&offset=0&limit=100&order_by=C&desc=true：分页查询
+func (s *Server) handleArtifactSQLite(w http.ResponseWriter, r *http.Request, artifactID, sub string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	info, err := s.store.GetArtifactInfo(r.Context(), artifactID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if info == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("artifact not found: %s", artifactID))
+		return
+	}
+	if _, ok := s.requireUnmask(w, r, info.CaseID, "artifact_sqlite"); !ok {
+		return
+	}
+	src := sqlitebrowser.Source{SnapshotPath: info.SnapshotPath, SHA256: info.SHA256}
+	q := r.URL.Query()
+
+	switch sub {
+	case "":
+		dbs, err := s.sqlite.Databases(src)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"artifact_id": artifactID, "databases": dbs})
+	case "tables":
+		sum, err := s.sqlite.Tables(r.Context(), src, q.Get("db"))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"artifact_id": artifactID,
+			"db":          q.Get("db"),
+			"tables":      sum.Tables,
+			"truncated":   sum.Truncated,
+		})
+	case "rows":
+		if q.Get("table") == "" {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("table is required"))
+			return
+		}
+		page, err := s.sqlite.Rows(r.Context(), src, q.Get("db"), sqlitebrowser.RowQuery{
+			Table:   q.Get("table"),
+			Offset:  parseInt(q.Get("offset"), 0),
+			Limit:   parseInt(q.Get("limit"), 0),
+			OrderBy: q.Get("order_by"),
+			Desc:    parseBool(q.Get("desc"), false),
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"artifact_id": artifactID,
+			"db":          q.Get("db"),
+			"page":        page,
+		})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
@@ -0,0 +1,63 @@
+package webapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/services/casestorage"
+)
+
+// handleCaseStorage 案件存储占用与配额。
+//
+// - GET /api/cases/{case_id}/storage：证据字节 / 报告字节 / 各表行数 + 生效配额与状态
+// - POST /api/cases/{case_id}/storage：设置案件级配额 {"quota_bytes": N}；quota_bytes 为 null 时回退到全局默认
+func (s *Server) handleCaseStorage(w http.ResponseWriter, r *http.Request, caseID string) {
+	switch r.Method {
+	case http.MethodGet:
+		usage, err := casestorage.Usage(r.Context(), s.store, caseID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"storage": usage})
+	case http.MethodPost:
+		var req struct {
+			QuotaBytes *int64 `json:"quota_bytes"`
+			Operator   string `json:"operator,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		if req.QuotaBytes != nil && *req.QuotaBytes < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("quota_bytes must be >= 0"))
+			return
+		}
+		operator := strings.TrimSpace(req.Operator)
+		if operator == "" {
+			operator = "system"
+		}
+		found, err := s.store.SetCaseQuota(r.Context(), caseID, req.QuotaBytes)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !found {
+			writeError(w, http.StatusNotFound, fmt.Errorf("case not found: %s", caseID))
+			return
+		}
+		_ = s.store.AppendAudit(r.Context(), caseID, "", "case_storage", "set_quota", "success", operator, "webapp.handleCaseStorage", map[string]any{
+			"quota_bytes": req.QuotaBytes,
+		})
+		usage, err := casestorage.Usage(r.Context(), s.store, caseID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "storage": usage})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
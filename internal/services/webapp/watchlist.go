@@ -0,0 +1,93 @@
+package webapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/services/matcher"
+)
+
+// handleCaseWatchlist 管理案件关注词（别名/地址/手机号等，下次扫描或导入时检索并生成 watchlist_match 命中）：
+// - GET：列出
+// - POST：新增（term_type=keyword|alias|address|phone，默认 keyword）
+// - DELETE /{term_id}：删除（已产生的命中保留）
+func (s *Server) handleCaseWatchlist(w http.ResponseWriter, r *http.Request, caseID, termID string) {
+	switch {
+	case r.Method == http.MethodGet && termID == "":
+		rows, err := s.store.ListWatchlistTerms(r.Context(), caseID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"terms": rows})
+	case r.Method == http.MethodPost && termID == "":
+		var req struct {
+			Term     string `json:"term"`
+			TermType string `json:"term_type,omitempty"`
+			Note     string `json:"note,omitempty"`
+			Operator string `json:"operator,omitempty"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid json: %w", err))
+			return
+		}
+		term, termType, err := matcher.NormalizeWatchlistTerm(req.Term, req.TermType)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		ov, err := s.store.GetCaseOverview(r.Context(), caseID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if ov == nil {
+			writeError(w, http.StatusNotFound, fmt.Errorf("case not found: %s", caseID))
+			return
+		}
+		operator := strings.TrimSpace(req.Operator)
+		if operator == "" {
+			operator = "system"
+		}
+		saved, err := s.store.AddWatchlistTerm(r.Context(), model.WatchlistTerm{
+			CaseID:   caseID,
+			Term:     term,
+			TermType: termType,
+			Note:     strings.TrimSpace(req.Note),
+			Operator: operator,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		_ = s.store.AppendAudit(r.Context(), caseID, "", "watchlist", "add", "success", operator, "webapp.handleCaseWatchlist", map[string]any{
+			"term_id":   saved.TermID,
+			"term":      saved.Term,
+			"term_type": saved.TermType,
+		})
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "term": saved})
+	case r.Method == http.MethodDelete && termID != "":
+		operator := strings.TrimSpace(r.URL.Query().Get("operator"))
+		if operator == "" {
+			operator = "system"
+		}
+		found, err := s.store.DeleteWatchlistTerm(r.Context(), caseID, termID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if !found {
+			writeError(w, http.StatusNotFound, fmt.Errorf("watchlist term not found: %s", termID))
+			return
+		}
+		_ = s.store.AppendAudit(r.Context(), caseID, "", "watchlist", "remove", "success", operator, "webapp.handleCaseWatchlist", map[string]any{
+			"term_id": termID,
+		})
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
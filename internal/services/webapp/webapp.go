@@ -9,10 +9,25 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"crypto-inspector/internal/adapters/rules"
 	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
 	"crypto-inspector/internal/app"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/i18n"
+	"crypto-inspector/internal/platform/snapshot"
+	"crypto-inspector/internal/platform/trace"
+	"crypto-inspector/internal/services/anchor"
+	"crypto-inspector/internal/services/casemgmt"
+	"crypto-inspector/internal/services/chainbalance"
+	"crypto-inspector/internal/services/dbreplica"
+	"crypto-inspector/internal/services/devicemonitor"
+	"crypto-inspector/internal/services/privacy"
+	"crypto-inspector/internal/services/siemforward"
+	"crypto-inspector/internal/services/sqlitebrowser"
+	"crypto-inspector/internal/services/training"
 
 	_ "modernc.org/sqlite"
 )
@@ -33,10 +48,48 @@ type Options struct {
 	IOSBackupDir     string
 	WalletRulePath   string
 	ExchangeRulePath string
+	// ChainProvidersPath 为链上查询提供方配置（YAML，可选）；为空时只使用内置 kind。
+	ChainProvidersPath string
+	// CaseMgmtConfigPath 为案件管理系统对接配置（YAML，可选）；为空时不启用推送。
+	CaseMgmtConfigPath string
+	// AnchorConfigPath 为报告哈希外部锚定配置（YAML，可选）；为空时不启用锚定。
+	AnchorConfigPath string
 
 	ListenAddr          string
 	EnableIOSFullBackup bool
-	PrivacyMode         string // 预留：off|masked（当前仅记录，不做脱敏）
+	// PrivacyMode 为 off|masked|partial：masked 传给扫描任务生成脱敏报告；
+	// partial 时界面默认脱敏，导出/下载原件需 unmask 权限（见 privacy.go）。
+	PrivacyMode string
+	// UnmaskGrants 为 partial 模式下持有 unmask 权限的操作员及其令牌。
+	UnmaskGrants privacy.Grants
+	// SnapshotCompression 是扫描任务写 JSON 证据快照的压缩方式（空/none/gzip）。
+	SnapshotCompression string
+
+	// TraceLog=true 时把每个请求/服务 span 的耗时输出到 stderr（排查现场慢扫描用）。
+	TraceLog bool
+
+	// RateLimit 为 API 限流参数（零值使用默认限额；Disabled=true 关闭限流）。
+	RateLimit RateLimitOptions
+	// Security 为 CSRF/安全响应头参数（安全头始终下发）。
+	Security SecurityOptions
+
+	// SIEM.Endpoint 非空时，后台把审计日志实时转发到 SIEM（CEF/Syslog）。
+	SIEM siemforward.Options
+
+	// Monitor=true 时后台轮询 adb/idevice_id，并通过 /api/devices/events（SSE）推送设备接入与授权状态变化。
+	Monitor bool
+	// MonitorInterval 为设备轮询间隔（零值使用 devicemonitor.DefaultInterval）。
+	MonitorInterval time.Duration
+	// Replica.Path 非空时，后台把数据库定期同步到该热备副本（见 dbreplica）。
+	Replica dbreplica.Options
+
+	// Training=true 时为演练模式：库/证据目录默认改为 data/training/，库被标记为演练库（报告与导出加水印），
+	// 主机扫描任务改为扫描内置合成数据源，移动端扫描不执行（见 training 包）。
+	Training bool
+
+	// Lang 是错误消息的默认语言（en|zh-CN；为空时取 CRYPTO_INSPECTOR_LANG，再缺省为英文），
+	// 单个请求可用 ?lang= 或 Accept-Language 覆盖（见 withLocale）。
+	Lang string
 }
 
 // Run 启动内置 Web UI：
@@ -44,11 +97,17 @@ type Options struct {
 // - 提供“一键 scan all”后台任务接口（内测用）
 func Run(ctx context.Context, opts Options) error {
 	defaults := app.DefaultConfig()
+	if opts.Training {
+		defaults.DBPath = training.DefaultDBPath
+	}
 	if opts.DBPath == "" {
 		opts.DBPath = defaults.DBPath
 	}
 	if opts.EvidenceRoot == "" {
 		opts.EvidenceRoot = "data/evidence"
+		if opts.Training {
+			opts.EvidenceRoot = training.DefaultEvidenceRoot
+		}
 	}
 	if opts.IOSBackupDir == "" {
 		opts.IOSBackupDir = filepath.Join(opts.EvidenceRoot, "ios_backups")
@@ -62,8 +121,17 @@ func Run(ctx context.Context, opts Options) error {
 	if opts.ListenAddr == "" {
 		opts.ListenAddr = "127.0.0.1:8787"
 	}
-	if opts.PrivacyMode == "" {
-		opts.PrivacyMode = "off"
+	mode, err := privacy.NormalizeMode(opts.PrivacyMode)
+	if err != nil {
+		return err
+	}
+	opts.PrivacyMode = mode
+	if _, err := snapshot.ParseCompression(opts.SnapshotCompression); err != nil {
+		return err
+	}
+	lang, err := i18n.Resolve(opts.Lang)
+	if err != nil {
+		return err
 	}
 
 	if err := os.MkdirAll(filepath.Dir(opts.DBPath), 0o755); err != nil {
@@ -90,10 +158,29 @@ func Run(ctx context.Context, opts Options) error {
 		return fmt.Errorf("ping sqlite: %w", err)
 	}
 
+	chains, err := chainbalance.LoadRegistry(opts.ChainProvidersPath)
+	if err != nil {
+		return fmt.Errorf("load chain providers: %w", err)
+	}
+	cms, err := casemgmt.LoadConfig(opts.CaseMgmtConfigPath)
+	if err != nil {
+		return fmt.Errorf("load case management config: %w", err)
+	}
+	anchorCfg, err := anchor.LoadConfig(opts.AnchorConfigPath)
+	if err != nil {
+		return fmt.Errorf("load anchor config: %w", err)
+	}
+
 	migrator := sqliteadapter.NewMigrator(db)
 	if err := migrator.Up(ctx); err != nil {
 		return fmt.Errorf("apply migrations: %w", err)
 	}
+	if opts.Training {
+		if err := sqliteadapter.NewStore(db).MarkTrainingDB(ctx); err != nil {
+			return err
+		}
+		fmt.Printf("training mode: db=%s (outputs watermarked %s)\n", opts.DBPath, model.TrainingWatermark)
+	}
 
 	sub, err := fs.Sub(uiFS, "ui_dist")
 	if err != nil {
@@ -106,6 +193,68 @@ func Run(ctx context.Context, opts Options) error {
 		store: sqliteadapter.NewStore(db),
 		ui:    sub,
 		jobs:  newJobManager(),
+
+		chainBreaker: chainbalance.NewCircuitBreaker(5, 30*time.Second),
+		chains:       chains,
+		cms:          cms,
+		anchor:       anchorCfg,
+		rulesCache:   rules.NewCache(),
+		sqlite:       sqlitebrowser.New(filepath.Join(filepath.Dir(opts.DBPath), "cache", "sqlite_browser")),
+	}
+
+	if opts.TraceLog {
+		trace.SetSink(trace.WriterSink(os.Stderr))
+	}
+
+	if strings.TrimSpace(opts.SIEM.Endpoint) != "" {
+		siemOpts := opts.SIEM
+		if siemOpts.Logf == nil {
+			siemOpts.Logf = func(format string, args ...any) {
+				fmt.Fprintf(os.Stderr, format+"\n", args...)
+			}
+		}
+		fwd, err := siemforward.New(s.store, siemOpts)
+		if err != nil {
+			return fmt.Errorf("init siem forwarder: %w", err)
+		}
+		defer fwd.Close()
+		go func() { _ = fwd.Run(ctx) }()
+		fmt.Printf("siem forwarding enabled: endpoint=%s format=%s\n", siemOpts.Endpoint, siemOpts.Format)
+	}
+
+	if strings.TrimSpace(opts.Replica.Path) != "" {
+		replicaOpts := opts.Replica
+		if replicaOpts.Logf == nil {
+			replicaOpts.Logf = func(format string, args ...any) {
+				fmt.Fprintf(os.Stderr, format+"\n", args...)
+			}
+		}
+		rep, err := dbreplica.New(db, opts.DBPath, replicaOpts)
+		if err != nil {
+			return fmt.Errorf("init db replica: %w", err)
+		}
+		replicaCtx, stopReplica := context.WithCancel(ctx)
+		replicaDone := make(chan struct{})
+		go func() {
+			defer close(replicaDone)
+			rep.Run(replicaCtx)
+		}()
+		// 等待退出前的最后一次同步完成后再关闭数据库。
+		defer func() {
+			stopReplica()
+			<-replicaDone
+		}()
+		fmt.Printf("db replica enabled: path=%s\n", replicaOpts.Path)
+	}
+
+	if opts.PrivacyMode == privacy.ModePartial {
+		fmt.Printf("privacy mode partial: ui masked, %d operator(s) hold unmask permission\n", len(opts.UnmaskGrants))
+	}
+
+	if opts.Monitor {
+		s.monitor = devicemonitor.New(opts.MonitorInterval, nil)
+		go s.monitor.Run(ctx)
+		fmt.Printf("device monitor enabled: interval=%s\n", time.Duration(s.monitor.Snapshot().IntervalMS)*time.Millisecond)
 	}
 
 	mux := http.NewServeMux()
@@ -113,7 +262,7 @@ func Run(ctx context.Context, opts Options) error {
 
 	httpServer := &http.Server{
 		Addr:              opts.ListenAddr,
-		Handler:           mux,
+		Handler:           withTracing(withLocale(withSecurity(withRateLimit(mux, newRateLimiter(opts.RateLimit)), opts.Security), lang)),
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
@@ -0,0 +1,236 @@
+package xrefverify
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strings"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/i18n"
+)
+
+// 命中 ↔ 证据交叉引用校验
+//
+// 每条命中引用的 artifact_id 必须有证据记录，被引用的证据快照文件必须存在，关联两端必须属于同一案件；
+// 命中记录已不存在的残留关联（孤立关联）同样报告。外键只在连接开启 foreign_keys 时生效，外部工具改库、
+// 旧库恢复或证据文件被移走后都可能出现这些情况。
+// 没有任何证据关联的命中只记告警（不阻断）。
+//
+// 同一套规则用于库内校验（verify xref / verify artifacts / 导出前检查）与导出包 manifest 校验（verify forensic-zip）。
+
+// 问题类型。
+const (
+	KindMissingArtifact = "missing_artifact" // 关联的证据记录不存在
+	KindMissingFile     = "missing_file"     // 证据记录存在但快照文件缺失
+	KindOrphanLink      = "orphan_link"      // 关联的命中记录不存在
+	KindCrossCase       = "cross_case"       // 命中与证据属于不同案件
+	KindUnlinkedHit     = "unlinked_hit"     // 命中没有任何证据关联（告警）
+)
+
+// Issue 是一条交叉引用问题。Message 为英文（写入审计）；面向操作员输出前用 Result.Localize 按语言重写。
+type Issue struct {
+	Kind       string `json:"kind"`
+	HitID      string `json:"hit_id,omitempty"`
+	ArtifactID string `json:"artifact_id,omitempty"`
+	Path       string `json:"path,omitempty"`
+	Message    string `json:"message"`
+
+	msg i18n.Message
+}
+
+func newIssue(kind, hitID, artifactID, path string, msg i18n.Message) Issue {
+	return Issue{Kind: kind, HitID: hitID, ArtifactID: artifactID, Path: path, Message: msg.String(), msg: msg}
+}
+
+// Localize 返回按 l 的语言重写 Message 的副本（CLI/HTTP 输出用）；英文时原样返回。
+func (r *Result) Localize(l *i18n.Localizer) *Result {
+	if r == nil || l.Lang() == i18n.EN {
+		return r
+	}
+	out := *r
+	out.Issues = localizeIssues(l, r.Issues)
+	out.Warnings = localizeIssues(l, r.Warnings)
+	return &out
+}
+
+func localizeIssues(l *i18n.Localizer, in []Issue) []Issue {
+	out := make([]Issue, len(in))
+	for i, it := range in {
+		if it.msg.Key != "" {
+			it.Message = it.msg.Localize(l)
+		}
+		out[i] = it
+	}
+	return out
+}
+
+// Result 是交叉引用校验结果；OK 只看 Issues，Warnings 不影响结论。
+type Result struct {
+	OK        bool    `json:"ok"`
+	CaseID    string  `json:"case_id,omitempty"`
+	Hits      int     `json:"hits"`
+	Links     int     `json:"links"`
+	Artifacts int     `json:"artifacts"` // 被命中引用的不同证据数
+	Failed    int     `json:"failed"`
+	Issues    []Issue `json:"issues"`
+	Warnings  []Issue `json:"warnings"`
+}
+
+// Err 在存在问题时返回 ERR_EVIDENCE_INTEGRITY（消息列出前几条问题），否则返回 nil。
+func (r *Result) Err() error {
+	if r == nil || r.OK {
+		return nil
+	}
+	return apperr.T(apperr.CodeEvidenceIntegrity, "xref.failed", r.Failed, issueSummary(r.Issues))
+}
+
+// issueSummary 是错误消息里的问题摘要（前 5 条），渲染时按目标语言展开每条问题。
+type issueSummary []Issue
+
+func (s issueSummary) Localize(l *i18n.Localizer) string {
+	const show = 5
+	msgs := make([]string, 0, show+1)
+	for i, it := range s {
+		if i == show {
+			msgs = append(msgs, l.T("xref.more", len(s)-show))
+			break
+		}
+		if it.msg.Key != "" {
+			msgs = append(msgs, it.msg.Localize(l))
+		} else {
+			msgs = append(msgs, it.Message)
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Check 对 links 做交叉引用校验；fileExists 判断证据快照是否存在（库内为磁盘路径，导出包内为 ZIP 路径）。
+// unlinkedHits 是没有任何关联的命中（只记告警）。
+func Check(caseID string, hits int, links []model.HitArtifactLink, unlinkedHits []string, fileExists func(path string) bool) *Result {
+	res := &Result{CaseID: caseID, Hits: hits, Links: len(links), Issues: []Issue{}, Warnings: []Issue{}}
+	artifacts := map[string]bool{}
+	for _, l := range links {
+		if l.ArtifactExists {
+			artifacts[l.ArtifactID] = true
+		}
+		switch {
+		case !l.HitExists:
+			res.Issues = append(res.Issues, newIssue(KindOrphanLink, l.HitID, l.ArtifactID, "",
+				i18n.M("xref.orphan_link", l.HitID, l.ArtifactID)))
+		case !l.ArtifactExists:
+			res.Issues = append(res.Issues, newIssue(KindMissingArtifact, l.HitID, l.ArtifactID, "",
+				i18n.M("xref.missing_artifact", l.HitID, l.ArtifactID)))
+		case l.HitCaseID != "" && l.ArtifactCaseID != "" && l.HitCaseID != l.ArtifactCaseID:
+			res.Issues = append(res.Issues, newIssue(KindCrossCase, l.HitID, l.ArtifactID, "",
+				i18n.M("xref.cross_case", l.HitID, l.HitCaseID, l.ArtifactID, l.ArtifactCaseID)))
+		}
+	}
+	// 文件按证据去重检查，一个文件缺失只报一次。
+	checked := map[string]bool{}
+	for _, l := range links {
+		if !l.ArtifactExists || checked[l.ArtifactID] {
+			continue
+		}
+		checked[l.ArtifactID] = true
+		if p := strings.TrimSpace(l.SnapshotPath); p == "" || !fileExists(p) {
+			res.Issues = append(res.Issues, newIssue(KindMissingFile, l.HitID, l.ArtifactID, p,
+				i18n.M("xref.missing_file", l.ArtifactID, p)))
+		}
+	}
+	for _, h := range unlinkedHits {
+		res.Warnings = append(res.Warnings, newIssue(KindUnlinkedHit, h, "", "", i18n.M("xref.unlinked_hit", h)))
+	}
+	res.Artifacts = len(artifacts)
+	res.Failed = len(res.Issues)
+	res.OK = res.Failed == 0
+	return res
+}
+
+// VerifyCase 校验库内一个案件的命中与证据交叉引用（快照文件按磁盘路径检查）。
+func VerifyCase(ctx context.Context, store *sqliteadapter.Store, caseID string) (*Result, error) {
+	hits, err := store.CountCaseHits(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := store.ListHitArtifactLinks(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	unlinked, err := store.ListUnlinkedHitIDs(ctx, caseID)
+	if err != nil {
+		return nil, err
+	}
+	return Check(caseID, hits, links, unlinked, func(p string) bool {
+		st, err := os.Stat(p)
+		return err == nil && st.Mode().IsRegular()
+	}), nil
+}
+
+// ManifestArtifact 是导出包 manifest 中一条证据的最小字段。
+type ManifestArtifact struct {
+	ArtifactID string
+	CaseID     string
+	ZipPath    string
+}
+
+// VerifyManifest 校验导出包 manifest 内命中引用的证据都在 manifest 中，且其 ZIP 路径在包内存在。
+func VerifyManifest(hits []model.HitDetail, artifacts []ManifestArtifact, zipHas func(path string) bool) *Result {
+	byID := make(map[string]ManifestArtifact, len(artifacts))
+	for _, a := range artifacts {
+		byID[a.ArtifactID] = a
+	}
+	var links []model.HitArtifactLink
+	var unlinked []string
+	caseID := ""
+	for _, h := range hits {
+		if caseID == "" {
+			caseID = h.CaseID
+		}
+		if len(h.ArtifactIDs) == 0 {
+			unlinked = append(unlinked, h.HitID)
+			continue
+		}
+		ids := append([]string(nil), h.ArtifactIDs...)
+		sort.Strings(ids)
+		for _, aid := range ids {
+			a, ok := byID[aid]
+			links = append(links, model.HitArtifactLink{
+				HitID:          h.HitID,
+				ArtifactID:     aid,
+				HitExists:      true,
+				HitCaseID:      h.CaseID,
+				ArtifactExists: ok,
+				ArtifactCaseID: a.CaseID,
+				SnapshotPath:   a.ZipPath,
+			})
+		}
+	}
+	return Check(caseID, len(hits), links, unlinked, zipHas)
+}
+
+// PreExport 是导出前检查：交叉引用有问题时写一条失败审计（event_type=export, action=kind）并返回 ERR_EVIDENCE_INTEGRITY，
+// 保证导出包/报告不会引用缺失的证据。
+func PreExport(ctx context.Context, store *sqliteadapter.Store, caseID, kind, operator, source string) error {
+	res, err := VerifyCase(ctx, store, caseID)
+	if err != nil {
+		return err
+	}
+	if res.OK {
+		return nil
+	}
+	issues := res.Issues
+	if len(issues) > 20 {
+		issues = issues[:20]
+	}
+	_ = store.AppendAudit(ctx, caseID, "", "export", kind, "failed", operator, source, map[string]any{
+		"error_code":   apperr.CodeEvidenceIntegrity,
+		"xref_failed":  res.Failed,
+		"xref_issues":  issues,
+		"xref_links":   res.Links,
+		"xref_summary": "hit/evidence cross-reference check failed; export refused",
+	})
+	return res.Err()
+}
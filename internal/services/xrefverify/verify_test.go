@@ -0,0 +1,111 @@
+package xrefverify
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sqliteadapter "crypto-inspector/internal/adapters/store/sqlite"
+	"crypto-inspector/internal/domain/apperr"
+	"crypto-inspector/internal/domain/model"
+	"crypto-inspector/internal/platform/hash"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestVerifyCase(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := sql.Open("sqlite", filepath.Join(dir, "inspector.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	if err := sqliteadapter.NewMigrator(db).Up(ctx); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	store := sqliteadapter.NewStore(db)
+	caseID, _ := store.EnsureCase(ctx, "", "", "xref", "op", "")
+	if err := store.UpsertDevice(ctx, caseID, model.Device{ID: "dev_1", Name: "host", OS: model.OSWindows, Identifier: "h"}, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	var arts []model.Artifact
+	for _, id := range []string{"art_1", "art_2"} {
+		snap := filepath.Join(dir, "evidence", id+".json")
+		_ = os.MkdirAll(filepath.Dir(snap), 0o755)
+		_ = os.WriteFile(snap, []byte(`[]`), 0o644)
+		sum, size, _ := hash.File(snap)
+		arts = append(arts, model.Artifact{
+			ID: id, CaseID: caseID, DeviceID: "dev_1", Type: model.ArtifactInstalledApps, SnapshotPath: snap, SHA256: sum, SizeBytes: size,
+			CollectedAt: 1, CollectorName: "test", CollectorVersion: "0", PayloadJSON: []byte(`[]`), RecordHash: strings.Repeat("0", 64),
+		})
+	}
+	if err := store.SaveArtifacts(ctx, arts); err != nil {
+		t.Fatal(err)
+	}
+	hits := []model.RuleHit{
+		{ID: "hit_1", ArtifactIDs: []string{"art_1"}},
+		{ID: "hit_2", ArtifactIDs: []string{"art_2"}},
+		{ID: "hit_3"},
+	}
+	for i := range hits {
+		hits[i].CaseID, hits[i].DeviceID, hits[i].Type = caseID, "dev_1", model.HitWalletAddress
+		hits[i].RuleID, hits[i].RuleName, hits[i].RuleVersion, hits[i].MatchedValue = "addr", "address", "1", hits[i].ID
+		hits[i].Confidence, hits[i].Verdict, hits[i].DetailJSON = 0.9, "confirmed", []byte(`{}`)
+	}
+	if err := store.SaveRuleHits(ctx, hits); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := VerifyCase(ctx, store, caseID)
+	if err != nil || !res.OK || res.Links != 2 || len(res.Warnings) != 1 || res.Warnings[0].HitID != "hit_3" {
+		t.Fatalf("clean case: %+v err=%v", res, err)
+	}
+
+	// 快照文件缺失、证据记录缺失、命中已删除的残留关联。
+	_ = os.Remove(arts[1].SnapshotPath)
+	// 模拟外部工具改库（不经外键约束）。
+	for _, stmt := range []string{
+		`PRAGMA foreign_keys = OFF`,
+		`INSERT INTO hit_artifact_links(hit_id, artifact_id, created_at) VALUES ('hit_1', 'art_gone', 1)`,
+		`INSERT INTO hit_artifact_links(hit_id, artifact_id, created_at) VALUES ('hit_gone', 'art_1', 1)`,
+	} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err = VerifyCase(ctx, store, caseID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]int{}
+	for _, it := range res.Issues {
+		kinds[it.Kind]++
+	}
+	if res.OK || kinds[KindMissingFile] != 1 || kinds[KindMissingArtifact] != 1 || kinds[KindOrphanLink] != 1 || res.Failed != 3 {
+		t.Fatalf("issues=%+v", res.Issues)
+	}
+	if err := PreExport(ctx, store, caseID, "forensic_zip", "op", "test"); apperr.CodeOf(err) != apperr.CodeEvidenceIntegrity {
+		t.Fatalf("PreExport err=%v", err)
+	}
+}
+
+func TestVerifyManifest(t *testing.T) {
+	hits := []model.HitDetail{
+		{HitID: "h1", CaseID: "c1", ArtifactIDs: []string{"a1", "a2"}},
+		{HitID: "h2", CaseID: "c1", ArtifactIDs: []string{"a3"}},
+	}
+	arts := []ManifestArtifact{
+		{ArtifactID: "a1", CaseID: "c1", ZipPath: "evidence/a1.json"},
+		{ArtifactID: "a3", CaseID: "c1", ZipPath: "evidence/a3.json"},
+	}
+	zip := map[string]bool{"evidence/a1.json": true}
+	res := VerifyManifest(hits, arts, func(p string) bool { return zip[p] })
+	if res.OK || res.Failed != 2 || res.Issues[0].Kind != KindMissingArtifact || res.Issues[1].Kind != KindMissingFile {
+		t.Fatalf("result=%+v", res)
+	}
+}